@@ -2,7 +2,6 @@ package client
 
 import (
 	"bufio"
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -21,6 +20,11 @@ import (
 //    404: 4
 //    500+: 5
 func HandleResponse(c *Client, resp *http.Response, pretty bool) {
+	HandleResponseOutput(c, resp, &Output{Pretty: pretty})
+}
+
+// HandleResponseOutput is HandleResponse with the response body printed according to out.
+func HandleResponseOutput(c *Client, resp *http.Response, out *Output) {
 	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -35,25 +39,12 @@ func HandleResponse(c *Client, resp *http.Response, pretty bool) {
 		}
 		fmt.Printf("error: %d%s", resp.StatusCode, sbody)
 	} else if !c.Dump && len(body) > 0 {
-		var out string
-		if pretty {
-			var jbody interface{}
-			err = json.Unmarshal(body, &jbody)
-			if err != nil {
-				out = string(body)
-			} else {
-				var b []byte
-				b, err = json.MarshalIndent(jbody, "", "    ")
-				if err == nil {
-					out = string(b)
-				} else {
-					out = string(body)
-				}
-			}
-		} else {
-			out = string(body)
+		text, err := out.Render(body)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(-1)
 		}
-		fmt.Print(out)
+		fmt.Print(text)
 	}
 
 	// Figure out exit code
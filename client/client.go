@@ -33,6 +33,12 @@ type (
 		UserAgent string
 		// Dump indicates whether to dump request response.
 		Dump bool
+		// Retry is the policy used by DoWithRetry to retry failed requests, requests are not
+		// retried if nil.
+		Retry *RetryPolicy
+		// Reconnect is the policy used by Stream to reconnect the streams, streams are not
+		// reconnected if nil.
+		Reconnect *ReconnectPolicy
 	}
 )
 
@@ -0,0 +1,252 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Output configures how the CLI tools print the response bodies.
+type Output struct {
+	// Format is the output format, one of "json" (default), "yaml" or "table".
+	Format string
+	// Query is a JSONPath expression selecting the part of the response body that is printed,
+	// e.g. "$.items[*].name". Queries selecting a single string print the raw string.
+	Query string
+	// Pretty indents the JSON output.
+	Pretty bool
+	// Columns lists the table columns, typically the attributes of the response media type
+	// default view. The columns default to the keys of the rows when empty or when Query is set.
+	Columns []string
+}
+
+// Render returns the text printed for the given response body. The body is returned as is if
+// the output is neither pretty printed, queried nor converted, or if it is not JSON and no query
+// is given.
+func (o *Output) Render(body []byte) (string, error) {
+	var val interface{}
+	if err := json.Unmarshal(body, &val); err != nil {
+		if o.Query != "" || o.Format == "yaml" || o.Format == "table" {
+			return "", fmt.Errorf("response body is not JSON: %s", err)
+		}
+		return string(body), nil
+	}
+	if o.Query != "" {
+		var err error
+		if val, err = QueryJSON(o.Query, val); err != nil {
+			return "", err
+		}
+	}
+	switch o.Format {
+	case "", "json":
+		if o.Query == "" && !o.Pretty {
+			return string(body), nil
+		}
+		if s, ok := val.(string); ok && o.Query != "" {
+			return s + "\n", nil
+		}
+		var b []byte
+		var err error
+		if o.Pretty {
+			b, err = json.MarshalIndent(val, "", "    ")
+		} else {
+			b, err = json.Marshal(val)
+		}
+		if err != nil {
+			return "", err
+		}
+		if o.Query != "" {
+			b = append(b, '\n')
+		}
+		return string(b), nil
+	case "yaml":
+		b, err := yaml.Marshal(val)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	case "table":
+		columns := o.Columns
+		if o.Query != "" {
+			columns = nil
+		}
+		return renderTable(val, columns), nil
+	default:
+		return "", fmt.Errorf("unknown output format %q, must be one of json, yaml or table", o.Format)
+	}
+}
+
+// renderTable renders val as a table with a row per element if val is an array or a single row
+// otherwise.
+func renderTable(val interface{}, columns []string) string {
+	var rows []interface{}
+	if arr, ok := val.([]interface{}); ok {
+		rows = arr
+	} else {
+		rows = []interface{}{val}
+	}
+	if len(columns) == 0 {
+		keys := make(map[string]bool)
+		for _, row := range rows {
+			if m, ok := row.(map[string]interface{}); ok {
+				for k := range m {
+					if !keys[k] {
+						keys[k] = true
+						columns = append(columns, k)
+					}
+				}
+			}
+		}
+		sort.Strings(columns)
+	}
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	if len(columns) == 0 {
+		fmt.Fprintln(w, "VALUE")
+		for _, row := range rows {
+			fmt.Fprintln(w, cell(row))
+		}
+		w.Flush()
+		return buf.String()
+	}
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		m, _ := row.(map[string]interface{})
+		cells := make([]string, len(columns))
+		for i, c := range columns {
+			cells[i] = cell(m[c])
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// cell returns the text of a table cell, nested values are rendered as compact JSON.
+func cell(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		b, _ := json.Marshal(v)
+		return string(b)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// QueryJSON evaluates the JSONPath expression expr against the decoded JSON value doc. The
+// supported syntax consists of the root "$", the child operators ".name" and "['name']", the
+// index operator "[n]" where negative indices count from the end and the wildcards ".*" and
+// "[*]". The leading "$" is optional and the expression may be enclosed in braces. QueryJSON
+// returns the selected value or, if the expression contains wildcards, the array of the selected
+// values.
+func QueryJSON(expr string, doc interface{}) (interface{}, error) {
+	path := strings.TrimSpace(expr)
+	if strings.HasPrefix(path, "{") && strings.HasSuffix(path, "}") {
+		path = path[1 : len(path)-1]
+	}
+	path = strings.TrimPrefix(path, "$")
+	if path != "" && path[0] != '.' && path[0] != '[' {
+		path = "." + path
+	}
+	nodes := []interface{}{doc}
+	wildcard := false
+	for path != "" {
+		var (
+			key   string
+			index *int
+			all   bool
+		)
+		switch {
+		case strings.HasPrefix(path, "["):
+			end := strings.Index(path, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid query %q: missing ]", expr)
+			}
+			sel := strings.TrimSpace(path[1:end])
+			path = path[end+1:]
+			switch {
+			case sel == "*":
+				all = true
+			case len(sel) > 1 && (sel[0] == '\'' || sel[0] == '"') && sel[len(sel)-1] == sel[0]:
+				key = sel[1 : len(sel)-1]
+			default:
+				var i int
+				if _, err := fmt.Sscanf(sel, "%d", &i); err != nil || fmt.Sprint(i) != sel {
+					return nil, fmt.Errorf("invalid query %q: invalid index %q", expr, sel)
+				}
+				index = &i
+			}
+		case strings.HasPrefix(path, "."):
+			path = path[1:]
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			key = path[:end]
+			path = path[end:]
+			if key == "" {
+				return nil, fmt.Errorf("invalid query %q: missing name after .", expr)
+			}
+			if key == "*" {
+				all = true
+			}
+		default:
+			return nil, fmt.Errorf("invalid query %q: unexpected %q", expr, path)
+		}
+		wildcard = wildcard || all
+		var next []interface{}
+		for _, n := range nodes {
+			switch v := n.(type) {
+			case map[string]interface{}:
+				if all {
+					keys := make([]string, 0, len(v))
+					for k := range v {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+					for _, k := range keys {
+						next = append(next, v[k])
+					}
+				} else if val, ok := v[key]; ok && index == nil {
+					next = append(next, val)
+				}
+			case []interface{}:
+				if all {
+					next = append(next, v...)
+				} else if index != nil {
+					i := *index
+					if i < 0 {
+						i += len(v)
+					}
+					if i >= 0 && i < len(v) {
+						next = append(next, v[i])
+					}
+				}
+			}
+		}
+		nodes = next
+	}
+	if wildcard {
+		if nodes == nil {
+			nodes = []interface{}{}
+		}
+		return nodes, nil
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("query %q matches nothing", expr)
+	}
+	return nodes[0], nil
+}
@@ -0,0 +1,140 @@
+package client_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/client"
+)
+
+var _ = Describe("Output", func() {
+	const body = `[{"id":1,"name":"red","tags":["a","b"]},{"id":22,"name":"white"}]`
+
+	var output *client.Output
+	var text string
+	var err error
+
+	BeforeEach(func() {
+		output = new(client.Output)
+	})
+
+	JustBeforeEach(func() {
+		text, err = output.Render([]byte(body))
+	})
+
+	It("prints the body as is by default", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(text).Should(Equal(body))
+	})
+
+	Context("with the yaml format", func() {
+		BeforeEach(func() {
+			output.Format = "yaml"
+		})
+
+		It("converts the body to YAML", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(text).Should(Equal("- id: 1\n  name: red\n  tags:\n  - a\n  - b\n- id: 22\n  name: white\n"))
+		})
+	})
+
+	Context("with the table format", func() {
+		BeforeEach(func() {
+			output.Format = "table"
+			output.Columns = []string{"id", "name"}
+		})
+
+		It("prints the columns of each element", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(text).Should(Equal("ID  NAME\n1   red\n22  white\n"))
+		})
+
+		Context("and no columns", func() {
+			BeforeEach(func() {
+				output.Columns = nil
+			})
+
+			It("prints all the keys", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(text).Should(Equal("ID  NAME   TAGS\n1   red    [\"a\",\"b\"]\n22  white  \n"))
+			})
+		})
+	})
+
+	Context("with a query selecting a string", func() {
+		BeforeEach(func() {
+			output.Query = "$[1].name"
+		})
+
+		It("prints the raw string", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(text).Should(Equal("white\n"))
+		})
+	})
+
+	Context("with a query using wildcards", func() {
+		BeforeEach(func() {
+			output.Query = "$[*].id"
+		})
+
+		It("prints the selected values", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(text).Should(Equal("[1,22]\n"))
+		})
+	})
+
+	Context("with a query matching nothing", func() {
+		BeforeEach(func() {
+			output.Query = "$[2]"
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(MatchError(`query "$[2]" matches nothing`))
+		})
+	})
+
+	Context("with an unknown format", func() {
+		BeforeEach(func() {
+			output.Format = "xml"
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("QueryJSON", func() {
+	doc := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"name": "a", "size": 1.0},
+			map[string]interface{}{"name": "b", "size": 2.0},
+		},
+		"total size": 3.0,
+	}
+
+	It("evaluates the supported expressions", func() {
+		cases := map[string]interface{}{
+			"$":                  doc,
+			"$.items[0].name":    "a",
+			"items[-1].name":     "b",
+			"{.items[*].name}":   []interface{}{"a", "b"},
+			"$['total size']":    3.0,
+			`$.items[1]["size"]`: 2.0,
+			"$.items[0].*":       []interface{}{"a", 1.0},
+			"$.missing[*]":       []interface{}{},
+		}
+		for expr, expected := range cases {
+			val, err := client.QueryJSON(expr, doc)
+			Ω(err).ShouldNot(HaveOccurred(), expr)
+			Ω(val).Should(Equal(expected), expr)
+		}
+	})
+
+	It("rejects invalid expressions", func() {
+		for _, expr := range []string{"$.items[", "$.items[x]", "$..name", "$.items[0]name"} {
+			_, err := client.QueryJSON(expr, doc)
+			Ω(err).Should(HaveOccurred(), expr)
+		}
+	})
+})
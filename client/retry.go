@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kyokomi/goa-v1"
+)
+
+// RetryPolicy configures how the client retries the requests that fail with a transport error or
+// with a response whose status code is retryable. The delay between two attempts grows
+// exponentially starting with InitialBackoff. The delay given by the Retry-After header of the
+// response overrides the computed backoff.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one, values lower
+	// than 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts including the delays given by the
+	// Retry-After headers, there is no limit if zero.
+	MaxBackoff time.Duration
+	// Multiplier is the factor applied to the backoff after each attempt, defaults to 2.
+	Multiplier float64
+	// Jitter is the randomization factor applied to the backoff, e.g. 0.2 makes the delay vary
+	// by up to 20% either way.
+	Jitter float64
+	// StatusClasses lists the classes of the response status codes that are retried, e.g. 5
+	// retries all the 5xx responses.
+	StatusClasses []int
+	// Statuses lists additional response status codes that are retried, e.g. 429.
+	Statuses []int
+	// RetryErrors indicates whether the requests that fail with a transport error are retried.
+	RetryErrors bool
+	// NonIdempotent indicates whether the POST and PATCH requests are retried as well. The
+	// other requests are idempotent and always retried.
+	NonIdempotent bool
+}
+
+// DefaultRetryPolicy returns a retry policy that makes up to 3 attempts and retries the transport
+// errors, the 5xx responses and the 429 (Too Many Requests) responses of the idempotent requests.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		StatusClasses:  []int{5},
+		Statuses:       []int{http.StatusTooManyRequests},
+		RetryErrors:    true,
+	}
+}
+
+// DoWithRetry sends the request created by newRequest using Do and retries it according to the
+// client Retry policy. newRequest is called for each attempt so that the request body and
+// signature are built anew. DoWithRetry returns the response or error of the last attempt, it
+// stops waiting and returns the context error if the context is canceled between two attempts.
+func (c *Client) DoWithRetry(ctx context.Context, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	p := c.Retry
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.Do(ctx, req)
+		if p == nil || attempt >= p.MaxAttempts || !p.retryable(req, resp, err) {
+			return resp, err
+		}
+		delay := p.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = after
+				if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+					delay = p.MaxBackoff
+				}
+			}
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		goa.LogInfo(ctx, "retrying", "attempt", attempt+1, "delay", delay.String())
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable returns true if the attempt that sent req and got resp or err should be retried.
+func (p *RetryPolicy) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if !p.NonIdempotent && (req.Method == "POST" || req.Method == "PATCH") {
+		return false
+	}
+	if err != nil {
+		return p.RetryErrors && req.Context().Err() == nil
+	}
+	for _, class := range p.StatusClasses {
+		if resp.StatusCode/100 == class {
+			return true
+		}
+	}
+	for _, status := range p.Statuses {
+		if resp.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before the attempt following the given one.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	mult := p.Multiplier
+	if mult == 0 {
+		mult = 2
+	}
+	delay := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		delay *= mult
+		if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+			break
+		}
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	return time.Duration(delay)
+}
+
+// retryAfter parses the value of a Retry-After header, either a number of seconds or a HTTP date.
+func retryAfter(val string) (time.Duration, bool) {
+	if val == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(val); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(val)
+	if err != nil {
+		return 0, false
+	}
+	delay := time.Until(t)
+	if delay < 0 {
+		delay = 0
+	}
+	return delay, true
+}
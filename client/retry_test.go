@@ -0,0 +1,143 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/client"
+)
+
+var _ = Describe("DoWithRetry", func() {
+	var (
+		statuses   []int
+		retryAfter string
+		attempts   int
+		bodies     []string
+		server     *httptest.Server
+		method     string
+		policy     *client.RetryPolicy
+		resp       *http.Response
+		err        error
+	)
+
+	BeforeEach(func() {
+		statuses = []int{503, 503, 200}
+		retryAfter = ""
+		attempts = 0
+		bodies = nil
+		method = "PUT"
+		policy = &client.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			StatusClasses:  []int{5},
+			Statuses:       []int{429},
+			RetryErrors:    true,
+		}
+		server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			buf := new(bytes.Buffer)
+			buf.ReadFrom(req.Body)
+			bodies = append(bodies, buf.String())
+			status := statuses[attempts]
+			attempts++
+			if retryAfter != "" {
+				rw.Header().Set("Retry-After", retryAfter)
+			}
+			rw.WriteHeader(status)
+		}))
+	})
+
+	JustBeforeEach(func() {
+		c := client.New(nil)
+		c.Retry = policy
+		resp, err = c.DoWithRetry(context.Background(), func() (*http.Request, error) {
+			return http.NewRequest(method, server.URL, strings.NewReader("body"))
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("retries the retryable responses", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(200))
+		Ω(attempts).Should(Equal(3))
+		Ω(bodies).Should(Equal([]string{"body", "body", "body"}))
+	})
+
+	Context("with too many failures", func() {
+		BeforeEach(func() {
+			statuses = []int{429, 500, 502}
+		})
+
+		It("returns the last response", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(resp.StatusCode).Should(Equal(502))
+			Ω(attempts).Should(Equal(3))
+		})
+	})
+
+	Context("with a status that is not retryable", func() {
+		BeforeEach(func() {
+			statuses = []int{404}
+		})
+
+		It("does not retry", func() {
+			Ω(resp.StatusCode).Should(Equal(404))
+			Ω(attempts).Should(Equal(1))
+		})
+	})
+
+	Context("with a non idempotent request", func() {
+		BeforeEach(func() {
+			method = "POST"
+		})
+
+		It("does not retry", func() {
+			Ω(resp.StatusCode).Should(Equal(503))
+			Ω(attempts).Should(Equal(1))
+		})
+
+		Context("and a policy retrying non idempotent requests", func() {
+			BeforeEach(func() {
+				policy.NonIdempotent = true
+			})
+
+			It("retries", func() {
+				Ω(resp.StatusCode).Should(Equal(200))
+				Ω(attempts).Should(Equal(3))
+			})
+		})
+	})
+
+	Context("with a Retry-After header", func() {
+		BeforeEach(func() {
+			statuses = []int{503, 200}
+			retryAfter = "1"
+			policy.MaxBackoff = 20 * time.Millisecond
+		})
+
+		It("waits for the given delay capped by the maximum backoff", func() {
+			Ω(resp.StatusCode).Should(Equal(200))
+			Ω(attempts).Should(Equal(2))
+		})
+	})
+
+	Context("with no policy", func() {
+		BeforeEach(func() {
+			policy = nil
+		})
+
+		It("does not retry", func() {
+			Ω(resp.StatusCode).Should(Equal(503))
+			Ω(attempts).Should(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,275 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kyokomi/goa-v1"
+)
+
+// ReconnectPolicy configures how the client reconnects the streams opened with Stream when the
+// connection fails or when the server closes it. The delay between two attempts grows
+// exponentially starting with InitialBackoff.
+type ReconnectPolicy struct {
+	// MaxAttempts is the maximum number of consecutive reconnection attempts, there is no limit
+	// if zero.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first reconnection attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts, there is no limit if zero.
+	MaxBackoff time.Duration
+	// Multiplier is the factor applied to the backoff after each attempt, defaults to 2.
+	Multiplier float64
+	// Jitter is the randomization factor applied to the backoff, e.g. 0.2 makes the delay vary
+	// by up to 20% either way.
+	Jitter float64
+	// OnDisconnect is called before each reconnection attempt with the number of the attempt
+	// and the error that ended the previous connection, the error is io.EOF if the server
+	// closed the stream.
+	OnDisconnect func(ctx context.Context, attempt int, err error)
+	// OnReconnect is called when a reconnection attempt succeeds with the number of the attempt.
+	OnReconnect func(ctx context.Context, attempt int)
+}
+
+// DefaultReconnectPolicy returns a reconnect policy that reconnects the streams indefinitely
+// with a delay growing from 100ms to 30s.
+func DefaultReconnectPolicy() *ReconnectPolicy {
+	return &ReconnectPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// Stream calls open to connect to a stream and receive to read the messages of the connection.
+// receive is called once per connection and returns when the connection ends, Stream closes the
+// connection afterwards. The stream is reconnected according to the client Reconnect policy
+// until ctx is canceled. Stream returns the error that ended the last connection, nil if the
+// server closed the stream and the client does not reconnect, or the context error.
+func (c *Client) Stream(ctx context.Context, open func() (io.ReadCloser, error), receive func(io.ReadCloser) error) error {
+	p := c.Reconnect
+	for attempt := 0; ; attempt++ {
+		conn, err := open()
+		if err == nil {
+			if attempt > 0 && p.OnReconnect != nil {
+				p.OnReconnect(ctx, attempt)
+			}
+			attempt = 0
+			err = c.receive(ctx, conn, receive)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil {
+			err = io.EOF
+		}
+		if p == nil || (p.MaxAttempts > 0 && attempt >= p.MaxAttempts) {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if p.OnDisconnect != nil {
+			p.OnDisconnect(ctx, attempt+1, err)
+		}
+		delay := p.backoff(attempt + 1)
+		goa.LogInfo(ctx, "reconnecting", "attempt", attempt+1, "delay", delay.String(), "err", err.Error())
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// receive calls receive with the connection and closes it when receive returns or when ctx is
+// canceled so that pending reads are interrupted.
+func (c *Client) receive(ctx context.Context, conn io.ReadCloser, receive func(io.ReadCloser) error) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	err := receive(conn)
+	conn.Close()
+	return err
+}
+
+// backoff returns the delay before the given reconnection attempt.
+func (p *ReconnectPolicy) backoff(attempt int) time.Duration {
+	r := RetryPolicy{
+		InitialBackoff: p.InitialBackoff,
+		MaxBackoff:     p.MaxBackoff,
+		Multiplier:     p.Multiplier,
+		Jitter:         p.Jitter,
+	}
+	return r.backoff(attempt)
+}
+
+// Event is a server-sent event read from a text/event-stream response body.
+type Event struct {
+	// ID is the last event ID set by the stream.
+	ID string
+	// Name is the event type, "message" unless set by the stream.
+	Name string
+	// Data is the event data, the lines of the data fields are joined with new lines.
+	Data string
+	// Retry is the reconnection time set by the stream, zero if not set.
+	Retry time.Duration
+}
+
+// EventReader reads the server-sent events of a text/event-stream response body.
+type EventReader struct {
+	r     *bufio.Reader
+	id    string
+	retry time.Duration
+}
+
+// NewEventReader returns a reader that reads the events from r.
+func NewEventReader(r io.Reader) *EventReader {
+	return &EventReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next event of the stream, it returns io.EOF when the stream ends. The fields
+// of an event that is not terminated by an empty line are discarded.
+func (r *EventReader) Next() (*Event, error) {
+	var (
+		name string
+		data bytes.Buffer
+		has  bool
+	)
+	for {
+		line, err := r.r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line != "" {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if line == "" {
+			if !has {
+				name = ""
+				continue
+			}
+			if name == "" {
+				name = "message"
+			}
+			return &Event{
+				ID:    r.id,
+				Name:  name,
+				Data:  strings.TrimSuffix(data.String(), "\n"),
+				Retry: r.retry,
+			}, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value := line, ""
+		if i := strings.Index(line, ":"); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "event":
+			name = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			has = true
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				r.id = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				r.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// OpenEventStream sends the request created by newRequest using DoWithRetry and returns the
+// text/event-stream body of the response. It returns an error if the response status is not 200
+// (OK).
+func (c *Client) OpenEventStream(ctx context.Context, newRequest func() (*http.Request, error)) (io.ReadCloser, error) {
+	return c.openEventStream(ctx, newRequest, "")
+}
+
+// EventStream reads the server-sent events of the stream opened with the request created by
+// newRequest, the stream is reconnected according to the client Reconnect policy and the
+// reconnection requests resume the stream with the Last-Event-ID header. The events channel is
+// closed when the stream ends, the errors channel then receives the error returned by Stream if
+// any.
+func (c *Client) EventStream(ctx context.Context, newRequest func() (*http.Request, error)) (<-chan *Event, <-chan error) {
+	events := make(chan *Event)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(events)
+		defer close(errs)
+		var lastID string
+		err := c.Stream(ctx, func() (io.ReadCloser, error) {
+			return c.openEventStream(ctx, newRequest, lastID)
+		}, func(body io.ReadCloser) error {
+			r := NewEventReader(body)
+			for {
+				ev, err := r.Next()
+				if err != nil {
+					return err
+				}
+				lastID = ev.ID
+				select {
+				case events <- ev:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		})
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return events, errs
+}
+
+// openEventStream opens the event stream, it sets the Last-Event-ID header unless lastID is
+// empty.
+func (c *Client) openEventStream(ctx context.Context, newRequest func() (*http.Request, error), lastID string) (io.ReadCloser, error) {
+	resp, err := c.DoWithRetry(ctx, func() (*http.Request, error) {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		if lastID != "" {
+			req.Header.Set("Last-Event-ID", lastID)
+		}
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		if len(b) > 0 {
+			return nil, fmt.Errorf("%s: %s", resp.Status, b)
+		}
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,125 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/client"
+)
+
+var _ = Describe("EventReader", func() {
+	It("reads the events", func() {
+		r := client.NewEventReader(strings.NewReader(": comment\n" +
+			"retry: 3000\n\n" +
+			"data: first\r\n\r\n" +
+			"id: 2\nevent: update\ndata:line 1\ndata: line 2\n\n" +
+			"data: third\n\n" +
+			"data: incomplete\n"))
+		ev, err := r.Next()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ev).Should(Equal(&client.Event{Name: "message", Data: "first", Retry: 3 * time.Second}))
+		ev, err = r.Next()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ev).Should(Equal(&client.Event{ID: "2", Name: "update", Data: "line 1\nline 2", Retry: 3 * time.Second}))
+		ev, err = r.Next()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ev.ID).Should(Equal("2"))
+		Ω(ev.Data).Should(Equal("third"))
+		_, err = r.Next()
+		Ω(err).Should(Equal(io.EOF))
+	})
+})
+
+var _ = Describe("EventStream", func() {
+	var (
+		server       *httptest.Server
+		lastIDs      []string
+		policy       *client.ReconnectPolicy
+		disconnects  []error
+		reconnects   []int
+		events       []*client.Event
+		streamErr    error
+		eventsPerReq int
+	)
+
+	BeforeEach(func() {
+		lastIDs = nil
+		disconnects = nil
+		reconnects = nil
+		events = nil
+		eventsPerReq = 2
+		policy = &client.ReconnectPolicy{
+			MaxAttempts:    1,
+			InitialBackoff: time.Millisecond,
+			OnDisconnect: func(_ context.Context, _ int, err error) {
+				disconnects = append(disconnects, err)
+			},
+			OnReconnect: func(_ context.Context, attempt int) {
+				reconnects = append(reconnects, attempt)
+			},
+		}
+		requests := 0
+		server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			lastIDs = append(lastIDs, req.Header.Get("Last-Event-ID"))
+			requests++
+			if requests > 2 {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			rw.Header().Set("Content-Type", "text/event-stream")
+			for i := 0; i < eventsPerReq; i++ {
+				fmt.Fprintf(rw, "id: %d-%d\ndata: event\n\n", requests, i)
+			}
+		}))
+	})
+
+	JustBeforeEach(func() {
+		c := client.New(nil)
+		c.Reconnect = policy
+		evs, errs := c.EventStream(context.Background(), func() (*http.Request, error) {
+			return http.NewRequest("GET", server.URL, nil)
+		})
+		for ev := range evs {
+			events = append(events, ev)
+		}
+		streamErr = <-errs
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("reconnects with the last event ID", func() {
+		var ids []string
+		for _, ev := range events {
+			ids = append(ids, ev.ID)
+		}
+		Ω(ids).Should(Equal([]string{"1-0", "1-1", "2-0", "2-1"}))
+		Ω(lastIDs).Should(Equal([]string{"", "1-1", "2-1"}))
+		Ω(reconnects).Should(Equal([]int{1}))
+		Ω(disconnects).Should(HaveLen(2))
+		Ω(disconnects[0]).Should(Equal(io.EOF))
+		Ω(streamErr).Should(HaveOccurred())
+		Ω(streamErr.Error()).Should(ContainSubstring("503"))
+	})
+
+	Context("with no policy", func() {
+		BeforeEach(func() {
+			policy = nil
+		})
+
+		It("does not reconnect", func() {
+			Ω(events).Should(HaveLen(2))
+			Ω(lastIDs).Should(HaveLen(1))
+			Ω(streamErr).ShouldNot(HaveOccurred())
+		})
+	})
+})
@@ -19,6 +19,9 @@ const (
 	logContextKey
 	errKey
 	securityScopesKey
+	allowedMethodsKey
+	uninstrumentedKey
+	listenerKey
 )
 
 type (
@@ -45,6 +48,8 @@ type (
 		Status int
 		// Length is the response body length.
 		Length int
+
+		uninstrumented bool // Skips the response metrics
 	}
 
 	// key is the type used to store internal values in the context.
@@ -59,7 +64,7 @@ func NewContext(ctx context.Context, rw http.ResponseWriter, req *http.Request,
 		ctx = context.Background()
 	}
 	request := &RequestData{Request: req, Params: params}
-	response := &ResponseData{ResponseWriter: rw}
+	response := &ResponseData{ResponseWriter: rw, uninstrumented: !ContextInstrumented(ctx)}
 	ctx = context.WithValue(ctx, respKey, response)
 	ctx = context.WithValue(ctx, reqKey, request)
 
@@ -92,6 +97,12 @@ func WithError(ctx context.Context, err error) context.Context {
 	return context.WithValue(ctx, errKey, err)
 }
 
+// WithoutInstrumentation creates a context whose requests are skipped by the access logging and
+// metrics middleware and by the goa response metrics, e.g. the requests sent by health probes.
+func WithoutInstrumentation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, uninstrumentedKey, true)
+}
+
 // ContextController extracts the controller name from the given context.
 func ContextController(ctx context.Context) string {
 	if c := ctx.Value(ctrlKey); c != nil {
@@ -140,6 +151,11 @@ func ContextError(ctx context.Context) error {
 	return nil
 }
 
+// ContextInstrumented returns false if the given context was created with WithoutInstrumentation.
+func ContextInstrumented(ctx context.Context) bool {
+	return ctx.Value(uninstrumentedKey) == nil
+}
+
 // SwitchWriter overrides the underlying response writer. It returns the response
 // writer that was previously set.
 func (r *ResponseData) SwitchWriter(rw http.ResponseWriter) http.ResponseWriter {
@@ -155,11 +171,39 @@ func (r *ResponseData) Written() bool {
 
 // WriteHeader records the response status code and calls the underlying writer.
 func (r *ResponseData) WriteHeader(status int) {
-	go IncrCounter([]string{"goa", "response", strconv.Itoa(status)}, 1.0)
+	if !r.uninstrumented {
+		go IncrCounter([]string{"goa", "response", strconv.Itoa(status)}, 1.0)
+	}
 	r.Status = status
 	r.ResponseWriter.WriteHeader(status)
 }
 
+// Push initiates a HTTP/2 server push of the given target, see http.Pusher. It returns
+// http.ErrNotSupported if the underlying writer does not support server push, e.g. because the
+// client connection does not use HTTP/2. Push should be called before the response is written.
+// The generated action contexts embed ResponseData so that controllers may call Push directly:
+//
+//	if err := ctx.Push("/css/app.css", nil); err != nil && err != http.ErrNotSupported {
+//		return err
+//	}
+func (r *ResponseData) Push(target string, opts *http.PushOptions) error {
+	if p, ok := r.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Flush sends the buffered response data to the client if the underlying writer implements
+// http.Flusher.
+func (r *ResponseData) Flush() {
+	if !r.Written() {
+		r.WriteHeader(http.StatusOK)
+	}
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // Write records the amount of data written and calls the underlying writer.
 func (r *ResponseData) Write(b []byte) (int, error) {
 	if !r.Written() {
@@ -56,4 +56,30 @@ var _ = Describe("ResponseData", func() {
 			Ω(data.Status).Should(Equal(status))
 		})
 	})
+
+	Context("Push", func() {
+		It("returns ErrNotSupported if the writer does not support server push", func() {
+			Ω(data.Push("/app.js", nil)).Should(Equal(http.ErrNotSupported))
+		})
+
+		It("pushes the target if the writer supports server push", func() {
+			p := &testPusher{ResponseWriter: rw}
+			data.SwitchWriter(p)
+			opts := &http.PushOptions{Method: "GET"}
+			Ω(data.Push("/app.js", opts)).ShouldNot(HaveOccurred())
+			Ω(p.target).Should(Equal("/app.js"))
+			Ω(p.opts).Should(BeIdenticalTo(opts))
+		})
+	})
 })
+
+type testPusher struct {
+	http.ResponseWriter
+	target string
+	opts   *http.PushOptions
+}
+
+func (p *testPusher) Push(target string, opts *http.PushOptions) error {
+	p.target, p.opts = target, opts
+	return nil
+}
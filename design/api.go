@@ -88,31 +88,42 @@ var (
 	// KnownEncoders contains the list of encoding packages and factories known by goa indexed
 	// by MIME type.
 	KnownEncoders = map[string]string{
-		"application/json":      "github.com/kyokomi/goa-v1",
-		"application/xml":       "github.com/kyokomi/goa-v1",
-		"application/gob":       "github.com/kyokomi/goa-v1",
-		"application/x-gob":     "github.com/kyokomi/goa-v1",
-		"application/binc":      "github.com/kyokomi/goa-v1/encoding/binc",
-		"application/x-binc":    "github.com/kyokomi/goa-v1/encoding/binc",
-		"application/cbor":      "github.com/kyokomi/goa-v1/encoding/cbor",
-		"application/x-cbor":    "github.com/kyokomi/goa-v1/encoding/cbor",
-		"application/msgpack":   "github.com/kyokomi/goa-v1/encoding/msgpack",
-		"application/x-msgpack": "github.com/kyokomi/goa-v1/encoding/msgpack",
+		"application/json":       "github.com/kyokomi/goa-v1",
+		"application/xml":        "github.com/kyokomi/goa-v1",
+		"application/gob":        "github.com/kyokomi/goa-v1",
+		"application/x-gob":      "github.com/kyokomi/goa-v1",
+		"application/binc":       "github.com/kyokomi/goa-v1/encoding/binc",
+		"application/x-binc":     "github.com/kyokomi/goa-v1/encoding/binc",
+		"application/cbor":       "github.com/kyokomi/goa-v1/encoding/cbor",
+		"application/x-cbor":     "github.com/kyokomi/goa-v1/encoding/cbor",
+		"application/msgpack":    "github.com/kyokomi/goa-v1/encoding/msgpack",
+		"application/x-msgpack":  "github.com/kyokomi/goa-v1/encoding/msgpack",
+		"application/protobuf":   "github.com/kyokomi/goa-v1/encoding/protobuf",
+		"application/x-protobuf": "github.com/kyokomi/goa-v1/encoding/protobuf",
+		"application/yaml":       "github.com/kyokomi/goa-v1/encoding/yaml",
+		"application/x-yaml":     "github.com/kyokomi/goa-v1/encoding/yaml",
+		"multipart/form-data":    "github.com/kyokomi/goa-v1/encoding/multipart",
 	}
 
 	// KnownEncoderFunctions contains the list of encoding encoder and decoder functions known
-	// by goa indexed by MIME type.
+	// by goa indexed by MIME type. The encoder function is empty for the MIME types that can
+	// only be decoded.
 	KnownEncoderFunctions = map[string][2]string{
-		"application/json":      {"NewJSONEncoder", "NewJSONDecoder"},
-		"application/xml":       {"NewXMLEncoder", "NewXMLDecoder"},
-		"application/gob":       {"NewGobEncoder", "NewGobDecoder"},
-		"application/x-gob":     {"NewGobEncoder", "NewGobDecoder"},
-		"application/binc":      {"NewEncoder", "NewDecoder"},
-		"application/x-binc":    {"NewEncoder", "NewDecoder"},
-		"application/cbor":      {"NewEncoder", "NewDecoder"},
-		"application/x-cbor":    {"NewEncoder", "NewDecoder"},
-		"application/msgpack":   {"NewEncoder", "NewDecoder"},
-		"application/x-msgpack": {"NewEncoder", "NewDecoder"},
+		"application/json":       {"NewJSONEncoder", "NewJSONDecoder"},
+		"application/xml":        {"NewXMLEncoder", "NewXMLDecoder"},
+		"application/gob":        {"NewGobEncoder", "NewGobDecoder"},
+		"application/x-gob":      {"NewGobEncoder", "NewGobDecoder"},
+		"application/binc":       {"NewEncoder", "NewDecoder"},
+		"application/x-binc":     {"NewEncoder", "NewDecoder"},
+		"application/cbor":       {"NewEncoder", "NewDecoder"},
+		"application/x-cbor":     {"NewEncoder", "NewDecoder"},
+		"application/msgpack":    {"NewEncoder", "NewDecoder"},
+		"application/x-msgpack":  {"NewEncoder", "NewDecoder"},
+		"application/protobuf":   {"NewEncoder", "NewDecoder"},
+		"application/x-protobuf": {"NewEncoder", "NewDecoder"},
+		"application/yaml":       {"NewEncoder", "NewDecoder"},
+		"application/x-yaml":     {"NewEncoder", "NewDecoder"},
+		"multipart/form-data":    {"", "NewDecoder"},
 	}
 
 	// JSONContentTypes list the Content-Type header values that cause goa to encode or decode
@@ -179,6 +190,10 @@ var (
 			Description: "a meta object containing non-standard meta-information about the error.",
 			Example:     map[string]interface{}{"timestamp": 1458609066},
 		},
+		"details": &AttributeDefinition{
+			Type:        Any,
+			Description: "the structured details of the error, their type depends on the error code.",
+		},
 	}
 
 	errorMediaView = &ViewDefinition{
@@ -239,7 +254,7 @@ func (r MediaTypeRoot) DSLName() string {
 
 // DependsOn return the DSL roots the generated media types DSL root depends on, that's the API DSL.
 func (r MediaTypeRoot) DependsOn() []dslengine.Root {
-	return []dslengine.Root{Design}
+	return []dslengine.Root{CurrentDesign()}
 }
 
 // IterateSets iterates over the one generated media type definition set.
@@ -248,14 +263,14 @@ func (r MediaTypeRoot) IterateSets(iterator dslengine.SetIterator) {
 	i := 0
 	for _, mt := range r {
 		canonicalID := CanonicalIdentifier(mt.Identifier)
-		Design.MediaTypes[canonicalID] = mt
+		CurrentDesign().MediaTypes[canonicalID] = mt
 		canonicalIDs[i] = canonicalID
 		i++
 	}
 	sort.Strings(canonicalIDs)
 	set := make([]dslengine.Definition, len(canonicalIDs))
 	for i, cid := range canonicalIDs {
-		set[i] = Design.MediaTypes[cid]
+		set[i] = CurrentDesign().MediaTypes[cid]
 	}
 	iterator(set)
 }
@@ -22,7 +22,9 @@ import (
 //
 // returns the content of the file "/www/data/assets/x/y/z" when requests are sent to
 // "/assets/x/y/z".
-// The file path may be specified as a relative path to the current path of the process.
+// The file path may be specified as a relative path to the current path of the process. It is
+// a path in the file system of the controller if the controller FileSystem is set with
+// goa.FSFileSystem, e.g. to serve files bundled in the binary with embed.FS.
 // Files support setting a description, security scheme and doc links via additional DSL:
 //
 //    Files("/index.html", "/www/data/index.html", func() {
@@ -414,7 +416,7 @@ func payload(isOptional bool, p interface{}, dsls ...func()) {
 		case *design.MediaTypeDefinition:
 			att = design.DupAtt(actual.AttributeDefinition)
 		case string:
-			ut, ok := design.Design.Types[actual]
+			ut, ok := design.CurrentDesign().Types[actual]
 			if !ok {
 				dslengine.ReportError("unknown payload type %s", actual)
 			}
@@ -464,7 +466,7 @@ func MultipartForm() {
 // as base type.
 func newAttribute(baseMT string) *design.AttributeDefinition {
 	var base design.DataType
-	if mt := design.Design.MediaTypeWithIdentifier(baseMT); mt != nil {
+	if mt := design.CurrentDesign().MediaTypeWithIdentifier(baseMT); mt != nil {
 		base = mt.Type
 	}
 	return &design.AttributeDefinition{Reference: base}
@@ -2,6 +2,7 @@ package apidsl
 
 import (
 	"fmt"
+	"net/url"
 	"reflect"
 	"regexp"
 	"strings"
@@ -62,6 +63,10 @@ import (
 //			MediaType(arg2)
 //		})
 //              NoExample()                             // Prevent automatic generation of examples
+//		ProblemDetails(func() {			// Render error responses as RFC 7807 problem details
+//			ProblemType("invalid_request", "https://goa.design/problems/invalid-request", "Invalid request")
+//		})
+//		ErrorClass("out_of_stock", 409, StockDetails)	// Error class with typed details
 //		Trait("Authenticated", func() {		// Traits define DSL that can be run anywhere
 //			Headers(func() {
 //				Header("header")
@@ -71,7 +76,7 @@ import (
 //	}
 //
 func API(name string, dsl func()) *design.APIDefinition {
-	if design.Design.Name != "" {
+	if design.CurrentDesign().Name != "" {
 		dslengine.ReportError("multiple API definitions, only one is allowed")
 		return nil
 	}
@@ -83,9 +88,9 @@ func API(name string, dsl func()) *design.APIDefinition {
 	if name == "" {
 		dslengine.ReportError("API name cannot be empty")
 	}
-	design.Design.Name = name
-	design.Design.DSLFunc = dsl
-	return design.Design
+	design.CurrentDesign().Name = name
+	design.CurrentDesign().DSLFunc = dsl
+	return design.CurrentDesign()
 }
 
 // Version specifies the API version. One design describes one version.
@@ -132,7 +137,7 @@ func BasePath(val string) {
 	case *design.ResourceDefinition:
 		def.BasePath = val
 		if !strings.HasPrefix(val, "//") {
-			awcs := design.ExtractWildcards(design.Design.BasePath)
+			awcs := design.ExtractWildcards(design.CurrentDesign().BasePath)
 			wcs := design.ExtractWildcards(val)
 			for _, awc := range awcs {
 				for _, wc := range wcs {
@@ -310,6 +315,92 @@ func Docs(dsl func()) {
 	}
 }
 
+// ProblemDetails renders the error responses of the API as problem details (RFC 7807) with the
+// application/problem+json media type instead of the goa error media type. The optional DSL
+// lists the problem types of the error classes with ProblemType. The generated app package
+// exposes a UseProblemDetails function that configures the service accordingly.
+//
+//	ProblemDetails(func() {
+//		ProblemType("invalid_request", "https://goa.design/problems/invalid-request", "Invalid request")
+//		ProblemType("not_found", "https://goa.design/problems/not-found")
+//	})
+func ProblemDetails(dsl ...func()) {
+	a, ok := apiDefinition()
+	if !ok {
+		return
+	}
+	pd := new(design.ProblemDetailsDefinition)
+	if len(dsl) > 0 && !dslengine.Execute(dsl[0], pd) {
+		return
+	}
+	a.ProblemDetails = pd
+}
+
+// ProblemType maps the code of an error class to the URI of a problem type, title is the
+// optional short summary of the problem type. ProblemType must appear in a ProblemDetails DSL.
+func ProblemType(code, uri string, title ...string) {
+	pd, ok := dslengine.CurrentDefinition().(*design.ProblemDetailsDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+		return
+	}
+	if code == "" {
+		dslengine.ReportError("problem type error class code cannot be empty")
+		return
+	}
+	if _, err := url.Parse(uri); err != nil || uri == "" {
+		dslengine.ReportError("invalid problem type URI %#v for error class %#v", uri, code)
+		return
+	}
+	for _, t := range pd.Types {
+		if t.Code == code {
+			dslengine.ReportError("multiple problem types for error class %#v", code)
+			return
+		}
+	}
+	t := &design.ProblemTypeDefinition{Code: code, URI: uri}
+	if len(title) > 0 {
+		t.Title = title[0]
+	}
+	pd.Types = append(pd.Types, t)
+}
+
+// ErrorClass declares a class of errors produced by the API. code identifies the class and status
+// is the HTTP status of the responses that carry the errors. The optional details argument is the
+// type of the structured details of the errors. The generated app package exposes the error class
+// as well as functions that create errors with typed details and retrieve them.
+//
+//	ErrorClass("out_of_stock", 409, StockDetails)
+func ErrorClass(code string, status int, details ...design.DataType) {
+	a, ok := apiDefinition()
+	if !ok {
+		return
+	}
+	if code == "" {
+		dslengine.ReportError("error class code cannot be empty")
+		return
+	}
+	if status < 400 || status > 599 {
+		dslengine.ReportError("invalid status %d for error class %#v, must be a 4xx or 5xx status", status, code)
+		return
+	}
+	if len(details) > 1 {
+		dslengine.ReportError("too many arguments given to ErrorClass")
+		return
+	}
+	for _, e := range a.ErrorClasses {
+		if e.Code == code {
+			dslengine.ReportError("multiple error classes with code %#v", code)
+			return
+		}
+	}
+	e := &design.ErrorClassDefinition{Code: code, Status: status}
+	if len(details) > 0 {
+		e.Details = details[0]
+	}
+	a.ErrorClasses = append(a.ErrorClasses, e)
+}
+
 // Name sets the contact or license name.
 func Name(name string) {
 	switch def := dslengine.CurrentDefinition().(type) {
@@ -542,8 +633,8 @@ func Trait(name string, val ...func()) {
 			dslengine.ReportError("too many arguments given to Trait")
 			return
 		}
-		if _, ok := design.Design.Traits[name]; ok {
-			dslengine.ReportError("multiple definitions for trait %s%s", name, design.Design.Context())
+		if _, ok := design.CurrentDesign().Traits[name]; ok {
+			dslengine.ReportError("multiple definitions for trait %s%s", name, design.CurrentDesign().Context())
 			return
 		}
 		trait := &dslengine.TraitDefinition{Name: name, DSLFunc: val[0]}
@@ -575,7 +666,7 @@ func UseTrait(names ...string) {
 
 	if def != nil {
 		for _, name := range names {
-			if trait, ok := design.Design.Traits[name]; ok {
+			if trait, ok := design.CurrentDesign().Traits[name]; ok {
 				dslengine.Execute(trait.DSLFunc, def)
 			} else {
 				dslengine.ReportError("unknown trait %s", name)
@@ -57,6 +57,49 @@ var _ = Describe("API", func() {
 		})
 	})
 
+	Context("with duplicate problem types", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				apidsl.ProblemDetails(func() {
+					apidsl.ProblemType("not_found", "https://goa.design/problems/not-found")
+					apidsl.ProblemType("not_found", "https://goa.design/problems/missing")
+				})
+			}
+		})
+
+		It("returns an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with duplicate error classes", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				apidsl.ErrorClass("out_of_stock", 409)
+				apidsl.ErrorClass("out_of_stock", 410)
+			}
+		})
+
+		It("returns an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with an error class with an invalid status", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				apidsl.ErrorClass("out_of_stock", 200)
+			}
+		})
+
+		It("returns an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
 	Context("with valid DSL", func() {
 		JustBeforeEach(func() {
 			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
@@ -164,6 +207,42 @@ var _ = Describe("API", func() {
 			})
 		})
 
+		Context("with problem details", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					apidsl.ProblemDetails(func() {
+						apidsl.ProblemType("invalid_request", "https://goa.design/problems/invalid-request", "Invalid request")
+						apidsl.ProblemType("not_found", "https://goa.design/problems/not-found")
+					})
+				}
+			})
+
+			It("sets the API problem types", func() {
+				Ω(Design.ProblemDetails).Should(Equal(&ProblemDetailsDefinition{
+					Types: []*ProblemTypeDefinition{
+						{Code: "invalid_request", URI: "https://goa.design/problems/invalid-request", Title: "Invalid request"},
+						{Code: "not_found", URI: "https://goa.design/problems/not-found"},
+					},
+				}))
+			})
+		})
+
+		Context("with error classes", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					apidsl.ErrorClass("out_of_stock", 409, String)
+					apidsl.ErrorClass("unavailable", 503)
+				}
+			})
+
+			It("sets the API error classes", func() {
+				Ω(Design.ErrorClasses).Should(Equal([]*ErrorClassDefinition{
+					{Code: "out_of_stock", Status: 409, Details: String},
+					{Code: "unavailable", Status: 503},
+				}))
+			})
+		})
+
 		Context("with Consumes", func() {
 			const consumesMT = "application/json"
 
@@ -452,3 +531,106 @@ var _ = Describe("API", func() {
 	})
 
 })
+
+var _ = Describe("Eval", func() {
+	var global *APIDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		apidsl.API("global", nil)
+		global = Design
+	})
+
+	It("evaluates independent designs", func() {
+		design := func(name string) func() {
+			return func() {
+				apidsl.API(name, nil)
+				bottle := apidsl.MediaType("application/vnd.bottle", func() {
+					apidsl.Attributes(func() {
+						apidsl.Attribute("id", Integer)
+					})
+					apidsl.View("default", func() {
+						apidsl.Attribute("id")
+					})
+				})
+				apidsl.Resource("bottle", func() {
+					apidsl.Action("show", func() {
+						apidsl.Routing(apidsl.GET("/:id"))
+						apidsl.Response(OK, bottle)
+					})
+					apidsl.Action("list", func() {
+						apidsl.Routing(apidsl.GET(""))
+						apidsl.Response(OK, apidsl.CollectionOf(bottle))
+					})
+				})
+			}
+		}
+		projected := len(ProjectedMediaTypes)
+		foo, err := Eval(design("foo"))
+		Ω(err).ShouldNot(HaveOccurred())
+		bar, err := Eval(design("bar"))
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(foo.Name).Should(Equal("foo"))
+		Ω(bar.Name).Should(Equal("bar"))
+		Ω(foo.Resources["bottle"]).ShouldNot(BeIdenticalTo(bar.Resources["bottle"]))
+		Ω(foo.Resources["bottle"].Actions).Should(HaveKey("show"))
+		Ω(foo.MediaTypes).Should(HaveKey("application/vnd.bottle; type=collection"))
+		Ω(foo.MediaTypes["application/vnd.bottle"]).ShouldNot(BeIdenticalTo(bar.MediaTypes["application/vnd.bottle"]))
+		Ω(Design).Should(BeIdenticalTo(global))
+		Ω(Design.Name).Should(Equal("global"))
+		Ω(Design.Resources).Should(BeEmpty())
+		Ω(Design.MediaTypes).Should(BeEmpty())
+		Ω(GeneratedMediaTypes).Should(BeEmpty())
+		Ω(ProjectedMediaTypes).Should(HaveLen(projected))
+	})
+
+	It("binds the evaluated definitions to their design", func() {
+		design := func(name string) func() {
+			return func() {
+				apidsl.API(name, func() {
+					apidsl.BasePath("/" + name)
+					apidsl.Params(func() {
+						apidsl.Param(name+"_version", String)
+					})
+				})
+				bottle := apidsl.MediaType("application/vnd.bottle", func() {
+					apidsl.Attributes(func() {
+						apidsl.Attribute(name+"_id", Integer)
+					})
+					apidsl.View("default", func() {
+						apidsl.Attribute(name + "_id")
+					})
+				})
+				apidsl.Resource("bottle", func() {
+					apidsl.BasePath("/bottles")
+					apidsl.Action("show", func() {
+						apidsl.Routing(apidsl.GET("/:id"))
+						apidsl.Response(OK, bottle)
+					})
+				})
+			}
+		}
+		foo, err := Eval(design("foo"))
+		Ω(err).ShouldNot(HaveOccurred())
+		bar, err := Eval(design("bar"))
+		Ω(err).ShouldNot(HaveOccurred())
+
+		for _, api := range []*APIDefinition{foo, bar} {
+			show := api.Resources["bottle"].Actions["show"]
+			Ω(show.Routes[0].FullPath()).Should(Equal("/" + api.Name + "/bottles/:id"))
+			Ω(show.AllParams().Type.ToObject()).Should(HaveKey(api.Name + "_version"))
+			p, _, err := api.MediaTypes["application/vnd.bottle"].Project("default")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(p.Type.ToObject()).Should(HaveKey(api.Name + "_id"))
+		}
+	})
+
+	It("returns the DSL errors", func() {
+		_, err := Eval(func() {
+			apidsl.API("", nil)
+		})
+		Ω(err).Should(HaveOccurred())
+		Ω(dslengine.Errors).Should(BeEmpty())
+	})
+})
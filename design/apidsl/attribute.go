@@ -183,9 +183,11 @@ func parseAttributeArgs(baseAttr *design.AttributeDefinition, args ...interface{
 	parseDataType := func(expected string, index int) {
 		if name, ok2 := args[index].(string); ok2 {
 			// Lookup type by name
-			if dataType, ok = design.Design.Types[name]; !ok {
+			if dataType, ok = design.CurrentDesign().Types[name]; !ok {
 				var mt *design.MediaTypeDefinition
-				if mt = design.Design.MediaTypeWithIdentifier(name); mt == nil {
+				if p := design.LookupPrimitive(name); p != nil {
+					dataType = design.Primitive(p.Kind)
+				} else if mt = design.CurrentDesign().MediaTypeWithIdentifier(name); mt == nil {
 					dataType = design.String // not nil to avoid panics
 					dslengine.InvalidArgError(expected, args[index])
 				} else {
@@ -329,6 +331,25 @@ func ReadOnly() {
 	}
 }
 
+// Nullable can be used in: Attribute
+//
+// Nullable specifies that the attribute value may be null. The Go struct fields generated for
+// nullable attributes are always pointers (or other types whose zero value is nil) and their tags
+// do not use omitempty so that nil values are encoded as explicit nulls rather than omitted.
+// Nullable is equivalent to setting the "schema:nullable" metadata, see Metadata.
+//
+// Required nullable attributes accept null values: the generated code cannot tell a value that is
+// absent from a null value when decoding the attribute.
+//
+//	Attribute("nickname", String, func() {
+//		Nullable()
+//	})
+func Nullable() {
+	if a, ok := attributeDefinition(); ok {
+		a.SetNullable()
+	}
+}
+
 // NoExample can be used in: Attribute, Header, Param, HashOf, ArrayOf
 //
 // NoExample sets the example of an attribute to be blank for the documentation. It is used when
@@ -571,6 +592,49 @@ func MaxLength(val int) {
 
 // Required can be used in: Attributes, Headers, Payload, Type, Params
 //
+// Precision can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// Precision adds a validation that limits the total number of significant digits of a Decimal
+// attribute value. Example:
+//
+//	Attribute("price", Decimal, func() {
+//		Precision(10)
+//		Scale(2)
+//	})
+func Precision(val int) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.DecimalKind {
+			incompatibleAttributeType("precision", qualifiedTypeName(a.Type), "a decimal")
+		} else if val <= 0 {
+			dslengine.ReportError("invalid precision %d, precision must be strictly positive", val)
+		} else {
+			if a.Validation == nil {
+				a.Validation = &dslengine.ValidationDefinition{}
+			}
+			a.Validation.Precision = &val
+		}
+	}
+}
+
+// Scale can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// Scale adds a validation that limits the number of digits after the decimal point of a Decimal
+// attribute value.
+func Scale(val int) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.DecimalKind {
+			incompatibleAttributeType("scale", qualifiedTypeName(a.Type), "a decimal")
+		} else if val < 0 {
+			dslengine.ReportError("invalid scale %d, scale must be positive", val)
+		} else {
+			if a.Validation == nil {
+				a.Validation = &dslengine.ValidationDefinition{}
+			}
+			a.Validation.Scale = &val
+		}
+	}
+}
+
 // Required adds a "required" validation to the attribute.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor61.
 func Required(names ...string) {
@@ -610,6 +674,8 @@ func qualifiedTypeName(t design.DataType) string {
 	switch t.Kind() {
 	case design.DateTimeKind:
 		return "datetime"
+	case design.DecimalKind:
+		return "decimal"
 	case design.ArrayKind:
 		return fmt.Sprintf("%s<%s>", t.Name(), qualifiedTypeName(t.ToArray().ElemType.Type))
 	case design.HashKind:
@@ -620,5 +686,8 @@ func qualifiedTypeName(t design.DataType) string {
 			qualifiedTypeName(h.ElemType.Type),
 		)
 	}
+	if c := design.CustomPrimitive(t); c != nil {
+		return c.Name
+	}
 	return t.Name()
 }
@@ -327,6 +327,39 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 
+	Context("with a name, type decimal and a DSL defining precision and scale", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = Decimal
+			dsl = func() {
+				apidsl.Precision(10)
+				apidsl.Scale(2)
+			}
+		})
+
+		It("produces an attribute of type decimal with validations", func() {
+			t := parent.Type
+			Ω(t).ShouldNot(BeNil())
+			Ω(t).Should(BeAssignableToTypeOf(Object{}))
+			o := t.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].Type).Should(Equal(Decimal))
+			Ω(o[name].Validation).ShouldNot(BeNil())
+			Ω(*o[name].Validation.Precision).Should(Equal(10))
+			Ω(*o[name].Validation.Scale).Should(Equal(2))
+		})
+
+		Context("on an attribute that is not a decimal", func() {
+			BeforeEach(func() {
+				dataType = Number
+			})
+
+			It("fails", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+	})
+
 	Context("with a name and a type defined by name", func() {
 		var Foo *UserTypeDefinition
 
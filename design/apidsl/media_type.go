@@ -65,8 +65,8 @@ var mediaTypeCount int
 //
 // This function returns the media type definition so it can be referred to throughout the apidsl.
 func MediaType(identifier string, apidsl func()) *design.MediaTypeDefinition {
-	if design.Design.MediaTypes == nil {
-		design.Design.MediaTypes = make(map[string]*design.MediaTypeDefinition)
+	if design.CurrentDesign().MediaTypes == nil {
+		design.CurrentDesign().MediaTypes = make(map[string]*design.MediaTypeDefinition)
 	}
 
 	if !dslengine.IsTopLevelDefinition() {
@@ -85,7 +85,7 @@ func MediaType(identifier string, apidsl func()) *design.MediaTypeDefinition {
 	}
 	canonicalID := design.CanonicalIdentifier(identifier)
 	// Validate that media type identifier doesn't clash
-	if _, ok := design.Design.MediaTypes[canonicalID]; ok {
+	if _, ok := design.CurrentDesign().MediaTypes[canonicalID]; ok {
 		dslengine.ReportError("media type %#v with canonical identifier %#v is defined twice", identifier, canonicalID)
 		return nil
 	}
@@ -111,7 +111,7 @@ func MediaType(identifier string, apidsl func()) *design.MediaTypeDefinition {
 	}
 	// Now save the type in the API media types map
 	mt := design.NewMediaTypeDefinition(typeName, identifier, apidsl)
-	design.Design.MediaTypes[canonicalID] = mt
+	design.CurrentDesign().MediaTypes[canonicalID] = mt
 	return mt
 }
 
@@ -389,7 +389,7 @@ func CollectionOf(v interface{}, paramAndDSL ...interface{}) *design.MediaTypeDe
 	m, ok = v.(*design.MediaTypeDefinition)
 	if !ok {
 		if id, ok := v.(string); ok {
-			m = design.Design.MediaTypes[design.CanonicalIdentifier(id)]
+			m = design.CurrentDesign().MediaTypes[design.CanonicalIdentifier(id)]
 		}
 	}
 	if m == nil {
@@ -420,7 +420,7 @@ func CollectionOf(v interface{}, paramAndDSL ...interface{}) *design.MediaTypeDe
 		id = p
 	}
 	canonical := design.CanonicalIdentifier(id)
-	if mt, ok := design.GeneratedMediaTypes[canonical]; ok {
+	if mt, ok := design.CurrentGeneratedMediaTypes()[canonical]; ok {
 		// Already have a type for this collection, reuse it.
 		return mt
 	}
@@ -445,7 +445,7 @@ func CollectionOf(v interface{}, paramAndDSL ...interface{}) *design.MediaTypeDe
 	})
 	// Do not execute the apidsl right away, will be done last to make sure the element apidsl has run
 	// first.
-	design.GeneratedMediaTypes[canonical] = mt
+	design.CurrentGeneratedMediaTypes()[canonical] = mt
 	return mt
 }
 
@@ -26,6 +26,21 @@ import (
 //        Metadata("struct:field:type", "json.RawMessage", "encoding/json")
 //        Metadata("struct:field:type", "mypackage.MyType", "github.com/me/mypackage")
 //
+// `struct:type`: maps the type or attribute to an existing Go type given by its package import
+// path and name. goagen does not generate the user types that map to existing Go types and uses
+// the existing type wherever the user type is referenced, for example in payloads and media
+// types. The Go type is responsible for its own JSON encoding and must implement a
+// "Validate() error" method when the design defines validations for the type.
+// Applicable to types and attributes.
+//
+//        Metadata("struct:type", "github.com/org/pkg.Money")
+//
+// `struct:field:sensitive`: marks the attribute value as sensitive. The String methods generated
+// for the user and media types redact sensitive values so that they do not leak into logs.
+// Applicable to attributes only.
+//
+//        Metadata("struct:field:sensitive")
+//
 // `struct:tag:xxx`: sets the struct field tag xxx on generated Go structs.  Overrides tags that
 // goagen would otherwise set.  If the metadata value is a slice then the strings are joined with
 // the space character as separator.
@@ -34,6 +49,31 @@ import (
 //        Metadata("struct:tag:json", "myName,omitempty")
 //        Metadata("struct:tag:xml", "myName,attr")
 //
+// `struct:field:omitempty`: specifies whether the tags of the generated Go struct field use the
+// omitempty option, by default only the tags of non-required fields do. Setting the value to
+// "false" causes nil fields to be encoded as explicit null values. Setting the metadata on a type
+// applies it to all the fields of the type that do not set it.
+// Applicable to attributes and types.
+//
+//        Metadata("struct:field:omitempty", "false")
+//
+// `struct:tags`: lists additional struct field tags set on all the fields of the generated Go
+// struct, e.g. to derive persistence structs from the design types. The tags are added to the
+// tags goagen sets by default: "gorm" tags default to "column:name", "db" tags to "name" and
+// other tags to "name,omitempty" for non-required fields. The value of an additional tag may be
+// overridden with `struct:tag:xxx` on the attribute without dropping the other tags.
+// Applicable to types and media types.
+//
+//        Metadata("struct:tags", "gorm", "bson")
+//
+// `proto:message`: makes the generated media type structs convert into protocol buffer messages
+// so that the media type can be encoded with the encoding/protobuf package, e.g. when the
+// requests accept "application/x-protobuf". goagen generates a XxxToProto function variable for
+// each view of the media type which the service sets to the conversion function.
+// Applicable to media types.
+//
+//        Metadata("proto:message")
+//
 // `swagger:generate`: specifies whether Swagger specification should be generated. Defaults to
 // true.
 // Applicable to resources, actions and file servers.
@@ -52,6 +92,7 @@ import (
 //        Metadata("swagger:tag:Backend:desc", "Quick description of what 'Backend' is")
 //        Metadata("swagger:tag:Backend:url", "http://example.com")
 //        Metadata("swagger:tag:Backend:url:desc", "See more docs here")
+//        Metadata("swagger:tag:Backend:extension:x-displayName", "Backend services")
 //
 // `swagger:extension:xxx`: sets the Swagger extensions xxx. It can have any valid JSON format value.
 // Applicable to
@@ -60,12 +101,75 @@ import (
 // action as within the path-item object,
 // route as within the operation object,
 // param as within the parameter object,
-// response as within the response object
+// attribute and type as within the schema object,
+// response as within the response object,
+// response header as within the header object
 // and security as within the security-scheme object.
+// Tags get the extensions of their definition as well as the extensions set with
+// `swagger:tag:xxx:extension:yyy`.
 // See https://github.com/OAI/OpenAPI-Specification/blob/master/guidelines/EXTENSIONS.md.
 //
 //        Metadata("swagger:extension:x-api", `{"foo":"bar"}`)
 //
+// `swagger:deprecated`: marks the action or attribute as deprecated. Operations get the
+// deprecated field, attribute schemas the "deprecated" keyword and parameters the x-deprecated
+// extension as Swagger 2.0 parameters cannot be deprecated. The Deprecated DSL sets this metadata.
+// Applicable to actions and attributes.
+//
+//        Metadata("swagger:deprecated")
+//
+// `swagger:externaldocs:url` and `swagger:externaldocs:description`: set the externalDocs field
+// of the operation or attribute schema, the action metadata override the Docs DSL.
+// Applicable to actions and attributes.
+//
+//        Metadata("swagger:externaldocs:url", "https://docs.example.com/bottles")
+//        Metadata("swagger:externaldocs:description", "Bottle guide")
+//
+// `apigateway:integration:xxx`: sets the field xxx of the x-amazon-apigateway-integration
+// extensions generated by goagen swagger --apigateway. The value may be any valid JSON. Action
+// metadata override resource metadata which override API metadata.
+// Applicable to api, resources and actions.
+//
+//        Metadata("apigateway:integration:uri", "arn:aws:apigateway:us-east-1:lambda:path/2015-03-31/functions/arn:aws:lambda:us-east-1:123456789012:function:show/invocations")
+//        Metadata("apigateway:integration:timeoutInMillis", "3000")
+//
+// `schema:nullable`: specifies that the attribute value may be null. The JSON Schema 2020-12 and
+// OpenAPI 3.1 documents describe nullable values with a type array that includes "null".
+// The Nullable DSL sets this metadata.
+// Applicable to attributes only.
+//
+//        Metadata("schema:nullable")
+//
+// `asyncapi:webhook`: declares the action as a webhook, the API sends the action payload to the
+// subscribers by making the request described by the action route. Webhooks are described in the
+// AsyncAPI specification together with the WebSocket and server-sent events actions.
+// Applicable to actions only.
+//
+//        Metadata("asyncapi:webhook")
+//
+// `pact:state`: sets the provider state of the interactions generated by goagen pact. The state
+// of the action applies to the interaction of its success response, responses that set a state get
+// an interaction of their own.
+// Applicable to actions and responses.
+//
+//        Metadata("pact:state", "bottle 1 exists")
+//
+// `proxy:timeout`: sets the timeout of the edge proxy routes generated by goagen proxy. The value
+// is a Go duration. Action and file server metadata override resource metadata which override API
+// metadata.
+// Applicable to api, resources, actions and file servers.
+//
+//        Metadata("proxy:timeout", "30s")
+//
+// `grpc:service`, `grpc:rpc`, `grpc:field` and `grpc:package`: expose resources and actions as
+// gRPC services and methods, set message field numbers and the protocol buffer package, see the
+// goagen/gen_proto package documentation.
+// Applicable to resources, actions, attributes and the API respectively.
+//
+//        Metadata("grpc:service", "Cellar")
+//        Metadata("grpc:rpc", "GetBottle")
+//        Metadata("grpc:field", "3")
+//
 // The special key names listed above may be used as follows:
 //
 //        var Account = Type("Account", func() {
@@ -110,3 +214,21 @@ func Metadata(name string, value ...string) {
 		dslengine.IncompatibleDSL()
 	}
 }
+
+// Deprecated can be used in: Action, Attribute, Header, Param
+//
+// Deprecated marks the action or attribute as deprecated in the generated specifications.
+// Deprecated is equivalent to setting the "swagger:deprecated" metadata, see Metadata.
+//
+//	Action("list", func() {
+//		Deprecated()
+//		Routing(GET("/bottles"))
+//	})
+func Deprecated() {
+	switch dslengine.CurrentDefinition().(type) {
+	case *design.ActionDefinition, *design.AttributeDefinition:
+		Metadata("swagger:deprecated")
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
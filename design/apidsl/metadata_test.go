@@ -151,3 +151,35 @@ var _ = Describe("Metadata", func() {
 	})
 
 })
+
+var _ = Describe("Deprecated", func() {
+	var rd *ResourceDefinition
+	var mtd *MediaTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		rd = apidsl.Resource("bottle", func() {
+			apidsl.Action("list", func() {
+				apidsl.Deprecated()
+				apidsl.Routing(apidsl.GET("/bottles"))
+			})
+		})
+		mtd = apidsl.MediaType("application/vnd.bottle", func() {
+			apidsl.Attributes(func() {
+				apidsl.Attribute("vintage", Integer, func() {
+					apidsl.Deprecated()
+				})
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("vintage")
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("sets the swagger:deprecated metadata", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(rd.Actions["list"].Metadata).Should(HaveKey("swagger:deprecated"))
+		Ω(mtd.Type.ToObject()["vintage"].Metadata).Should(HaveKey("swagger:deprecated"))
+	})
+})
@@ -47,20 +47,20 @@ import (
 //		})
 //	})
 func Resource(name string, dsl func()) *design.ResourceDefinition {
-	if design.Design.Resources == nil {
-		design.Design.Resources = make(map[string]*design.ResourceDefinition)
+	if design.CurrentDesign().Resources == nil {
+		design.CurrentDesign().Resources = make(map[string]*design.ResourceDefinition)
 	}
 	if !dslengine.IsTopLevelDefinition() {
 		dslengine.IncompatibleDSL()
 		return nil
 	}
 
-	if _, ok := design.Design.Resources[name]; ok {
+	if _, ok := design.CurrentDesign().Resources[name]; ok {
 		dslengine.ReportError("resource %#v is defined twice", name)
 		return nil
 	}
 	resource := design.NewResourceDefinition(name, dsl)
-	design.Design.Resources[name] = resource
+	design.CurrentDesign().Resources[name] = resource
 	return resource
 }
 
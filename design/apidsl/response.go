@@ -145,18 +145,18 @@ func executeResponseDSL(name string, paramsAndDSL ...interface{}) *design.Respon
 	}
 	var resp *design.ResponseDefinition
 	if len(params) > 0 {
-		if tmpl, ok := design.Design.ResponseTemplates[name]; ok {
+		if tmpl, ok := design.CurrentDesign().ResponseTemplates[name]; ok {
 			resp = tmpl.Template(params...)
-		} else if tmpl, ok := design.Design.DefaultResponseTemplates[name]; ok {
+		} else if tmpl, ok := design.CurrentDesign().DefaultResponseTemplates[name]; ok {
 			resp = tmpl.Template(params...)
 		} else {
 			dslengine.ReportError("no response template named %#v", name)
 			return nil
 		}
 	} else {
-		if ar, ok := design.Design.Responses[name]; ok {
+		if ar, ok := design.CurrentDesign().Responses[name]; ok {
 			resp = ar.Dup()
-		} else if ar, ok := design.Design.DefaultResponses[name]; ok {
+		} else if ar, ok := design.CurrentDesign().DefaultResponses[name]; ok {
 			resp = ar.Dup()
 			resp.Standard = true
 		} else {
@@ -24,7 +24,7 @@ func Security(scheme interface{}, dsl ...func()) {
 	switch val := scheme.(type) {
 	case string:
 		def = &design.SecurityDefinition{}
-		for _, scheme := range design.Design.SecuritySchemes {
+		for _, scheme := range design.CurrentDesign().SecuritySchemes {
 			if scheme.SchemeName == val {
 				def.Scheme = scheme
 			}
@@ -113,13 +113,13 @@ func BasicAuthSecurity(name string, dsl ...func()) *design.SecuritySchemeDefinit
 		def.DSLFunc = dsl[0]
 	}
 
-	design.Design.SecuritySchemes = append(design.Design.SecuritySchemes, def)
+	design.CurrentDesign().SecuritySchemes = append(design.CurrentDesign().SecuritySchemes, def)
 
 	return def
 }
 
 func securitySchemeRedefined(name string) bool {
-	for _, previousScheme := range design.Design.SecuritySchemes {
+	for _, previousScheme := range design.CurrentDesign().SecuritySchemes {
 		if previousScheme.SchemeName == name {
 			dslengine.ReportError("cannot redefine SecurityScheme with name %q", name)
 			return true
@@ -159,7 +159,7 @@ func APIKeySecurity(name string, dsl ...func()) *design.SecuritySchemeDefinition
 		def.DSLFunc = dsl[0]
 	}
 
-	design.Design.SecuritySchemes = append(design.Design.SecuritySchemes, def)
+	design.CurrentDesign().SecuritySchemes = append(design.CurrentDesign().SecuritySchemes, def)
 
 	return def
 }
@@ -209,7 +209,7 @@ func OAuth2Security(name string, dsl ...func()) *design.SecuritySchemeDefinition
 		def.DSLFunc = dsl[0]
 	}
 
-	design.Design.SecuritySchemes = append(design.Design.SecuritySchemes, def)
+	design.CurrentDesign().SecuritySchemes = append(design.CurrentDesign().SecuritySchemes, def)
 
 	return def
 }
@@ -251,7 +251,7 @@ func JWTSecurity(name string, dsl ...func()) *design.SecuritySchemeDefinition {
 		def.DSLFunc = dsl[0]
 	}
 
-	design.Design.SecuritySchemes = append(design.Design.SecuritySchemes, def)
+	design.CurrentDesign().SecuritySchemes = append(design.CurrentDesign().SecuritySchemes, def)
 
 	return def
 }
@@ -28,9 +28,9 @@ import (
 //
 // This function returns the newly defined type so the value can be used throughout the dsl.
 func Type(name string, dsl func()) *design.UserTypeDefinition {
-	if design.Design.Types == nil {
-		design.Design.Types = make(map[string]*design.UserTypeDefinition)
-	} else if _, ok := design.Design.Types[name]; ok {
+	if design.CurrentDesign().Types == nil {
+		design.CurrentDesign().Types = make(map[string]*design.UserTypeDefinition)
+	} else if _, ok := design.CurrentDesign().Types[name]; ok {
 		dslengine.ReportError("type %#v defined twice", name)
 		return nil
 	}
@@ -49,7 +49,7 @@ func Type(name string, dsl func()) *design.UserTypeDefinition {
 	} else {
 		t.Type = make(design.Object)
 	}
-	design.Design.Types[name] = t
+	design.CurrentDesign().Types[name] = t
 	return t
 }
 
@@ -166,10 +166,10 @@ func resolveType(v interface{}) design.DataType {
 		return t
 	}
 	if name, ok := v.(string); ok {
-		if ut, ok := design.Design.Types[name]; ok {
+		if ut, ok := design.CurrentDesign().Types[name]; ok {
 			return ut
 		}
-		if mt, ok := design.Design.MediaTypes[name]; ok {
+		if mt, ok := design.CurrentDesign().MediaTypes[name]; ok {
 			return mt
 		}
 	}
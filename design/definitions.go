@@ -35,7 +35,8 @@ type (
 		Consumes []*EncodingDefinition
 		// Produces lists the mime types generated by the API controllers
 		Produces []*EncodingDefinition
-		// Origins defines the CORS policies that apply to this API.
+		// Origins defines the CORS policies that apply to this API. Use IterateOrigins to
+		// iterate over the policies in a deterministic order.
 		Origins map[string]*CORSDefinition
 		// TermsOfService describes or links to the API terms of service
 		TermsOfService string
@@ -45,15 +46,19 @@ type (
 		License *LicenseDefinition
 		// Docs points to the API external documentation
 		Docs *DocsDefinition
-		// Resources is the set of exposed resources indexed by name
+		// Resources is the set of exposed resources indexed by name. Use IterateResources to
+		// iterate over the resources in a deterministic order.
 		Resources map[string]*ResourceDefinition
-		// Types indexes the user defined types by name
+		// Types indexes the user defined types by name. Use IterateUserTypes to iterate over
+		// the types in a deterministic order.
 		Types map[string]*UserTypeDefinition
-		// MediaTypes indexes the API media types by canonical identifier
+		// MediaTypes indexes the API media types by canonical identifier. Use
+		// IterateMediaTypes to iterate over the media types in a deterministic order.
 		MediaTypes map[string]*MediaTypeDefinition
 		// Traits available to all API resources and actions indexed by name
 		Traits map[string]*dslengine.TraitDefinition
-		// Responses available to all API actions indexed by name
+		// Responses available to all API actions indexed by name. Use IterateResponses to
+		// iterate over the responses in a deterministic order.
 		Responses map[string]*ResponseDefinition
 		// Response template factories available to all API actions indexed by name
 		ResponseTemplates map[string]*ResponseTemplateDefinition
@@ -74,9 +79,17 @@ type (
 		Security *SecurityDefinition
 		// NoExamples indicates whether to bypass automatic example generation.
 		NoExamples bool
+		// ProblemDetails configures the rendering of the error responses as problem details
+		// (RFC 7807), the error responses use the goa error media type if nil.
+		ProblemDetails *ProblemDetailsDefinition
+		// ErrorClasses lists the error classes declared in the design.
+		ErrorClasses []*ErrorClassDefinition
 
 		// rand is the random generator used to generate examples.
 		rand *RandomGenerator
+		// projected caches the media types projected while the API is evaluated by Eval,
+		// ProjectedMediaTypes is used if nil.
+		projected MediaTypeRoot
 	}
 
 	// ContactDefinition contains the API contact information.
@@ -105,6 +118,33 @@ type (
 		URL string `json:"url,omitempty"`
 	}
 
+	// ProblemDetailsDefinition configures the rendering of the error responses as problem
+	// details (RFC 7807).
+	ProblemDetailsDefinition struct {
+		// Types lists the problem types of the error classes.
+		Types []*ProblemTypeDefinition
+	}
+
+	// ProblemTypeDefinition maps the code of an error class to a problem type.
+	ProblemTypeDefinition struct {
+		// Code is the error class code.
+		Code string
+		// URI identifies the problem type.
+		URI string
+		// Title is the short summary of the problem type.
+		Title string
+	}
+
+	// ErrorClassDefinition describes a class of errors produced by the API.
+	ErrorClassDefinition struct {
+		// Code identifies the class of errors.
+		Code string
+		// Status is the HTTP status of the responses that carry the errors.
+		Status int
+		// Details is the type of the structured details of the errors if any.
+		Details DataType
+	}
+
 	// ResourceDefinition describes a REST resource.
 	// It defines both a media type and a set of actions that can be executed through HTTP
 	// requests.
@@ -125,17 +165,20 @@ type (
 		MediaType string
 		// Default view name if default media type is MediaTypeDefinition
 		DefaultViewName string
-		// Exposed resource actions indexed by name
+		// Exposed resource actions indexed by name. Use IterateActions to iterate over the
+		// actions in a deterministic order.
 		Actions map[string]*ActionDefinition
 		// FileServers is the list of static asset serving endpoints
 		FileServers []*FileServerDefinition
 		// Action with canonical resource path
 		CanonicalActionName string
-		// Map of response definitions that apply to all actions indexed by name.
+		// Map of response definitions that apply to all actions indexed by name. Use
+		// IterateResponses to iterate over the responses in a deterministic order.
 		Responses map[string]*ResponseDefinition
 		// Request headers that apply to all actions.
 		Headers *AttributeDefinition
-		// Origins defines the CORS policies that apply to this resource.
+		// Origins defines the CORS policies that apply to this resource. Use IterateOrigins
+		// to iterate over the policies in a deterministic order.
 		Origins map[string]*CORSDefinition
 		// DSLFunc contains the DSL used to create this definition if any.
 		DSLFunc func()
@@ -144,6 +187,9 @@ type (
 		// Security defines security requirements for the Resource,
 		// for actions that don't define one themselves.
 		Security *SecurityDefinition
+
+		// api is the API evaluated by Eval the resource belongs to, see CurrentDesign.
+		api *APIDefinition
 	}
 
 	// CORSDefinition contains the definition for a specific origin CORS policy.
@@ -364,6 +410,9 @@ type (
 
 	// ResponseIterator is the type of functions given to IterateResponses.
 	ResponseIterator func(r *ResponseDefinition) error
+
+	// CORSIterator is the type of functions given to IterateOrigins.
+	CORSIterator func(o *CORSDefinition) error
 )
 
 // NewAPIDefinition returns a new design with built-in response templates.
@@ -590,6 +639,13 @@ func (a *APIDefinition) IterateResponses(it ResponseIterator) error {
 	return nil
 }
 
+// IterateOrigins calls the given iterator passing in each CORS policy sorted by origin.
+// Iteration stops if an iterator returns an error and in this case IterateOrigins returns that
+// error.
+func (a *APIDefinition) IterateOrigins(it CORSIterator) error {
+	return iterateOrigins(a.Origins, it)
+}
+
 // RandomGenerator is seeded after the API name. It's used to generate examples.
 func (a *APIDefinition) RandomGenerator() *RandomGenerator {
 	if a.rand == nil {
@@ -768,6 +824,32 @@ func (r *ResourceDefinition) IterateHeaders(it HeaderIterator) error {
 	return iterateHeaders(r.Headers, r.Headers.IsRequired, it)
 }
 
+// IterateResponses calls the given iterator passing in each response sorted in alphabetical order.
+// Iteration stops if an iterator returns an error and in this case IterateResponses returns that
+// error.
+func (r *ResourceDefinition) IterateResponses(it ResponseIterator) error {
+	names := make([]string, len(r.Responses))
+	i := 0
+	for n := range r.Responses {
+		names[i] = n
+		i++
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if err := it(r.Responses[n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IterateOrigins calls the given iterator passing in each CORS policy of the resource sorted by
+// origin. The API policies are not included, see AllOrigins. Iteration stops if an iterator
+// returns an error and in this case IterateOrigins returns that error.
+func (r *ResourceDefinition) IterateOrigins(it CORSIterator) error {
+	return iterateOrigins(r.Origins, it)
+}
+
 // CanonicalAction returns the canonical action of the resource if any.
 // The canonical action is used to compute hrefs to resources.
 func (r *ResourceDefinition) CanonicalAction() *ActionDefinition {
@@ -807,7 +889,7 @@ func (r *ResourceDefinition) FullPath() string {
 			}
 		}
 	} else {
-		basePath = Design.BasePath
+		basePath = r.design().BasePath
 	}
 	return httppath.Clean(path.Join(basePath, r.BasePath))
 }
@@ -815,7 +897,7 @@ func (r *ResourceDefinition) FullPath() string {
 // Parent returns the parent resource if any, nil otherwise.
 func (r *ResourceDefinition) Parent() *ResourceDefinition {
 	if r.ParentName != "" {
-		if parent, ok := Design.Resources[r.ParentName]; ok {
+		if parent, ok := r.design().Resources[r.ParentName]; ok {
 			return parent
 		}
 	}
@@ -826,7 +908,7 @@ func (r *ResourceDefinition) Parent() *ResourceDefinition {
 // The result is sorted alphabetically by policy origin.
 func (r *ResourceDefinition) AllOrigins() []*CORSDefinition {
 	all := make(map[string]*CORSDefinition)
-	for n, o := range Design.Origins {
+	for n, o := range r.design().Origins {
 		all[n] = o
 	}
 	for n, o := range r.Origins {
@@ -1055,6 +1137,9 @@ func (a *AttributeDefinition) IsPrimitivePointer(attName string) bool {
 		return false
 	}
 	if att.Type.IsPrimitive() {
+		if att.IsNullable() && !a.IsInterface(attName) {
+			return true
+		}
 		return (!a.IsRequired(attName) && !a.HasDefaultValue(attName) && !a.IsNonZero(attName) && !a.IsInterface(attName)) || a.IsFile(attName)
 	}
 	return false
@@ -1103,10 +1188,16 @@ func (a *AttributeDefinition) SetExample(example interface{}) bool {
 // GenerateExample returns the value of the Example field if not nil. Otherwise it traverses the
 // attribute type and recursively generates an example. The result is saved in the Example field.
 func (a *AttributeDefinition) GenerateExample(rand *RandomGenerator, seen []string) interface{} {
+	return a.generateExample(rand, seen, "")
+}
+
+// generateExample implements GenerateExample, path is the path to the attribute used to look up
+// the example generators registered via RegisterAttributeExample.
+func (a *AttributeDefinition) generateExample(rand *RandomGenerator, seen []string, path string) interface{} {
 	if a.Example != nil {
 		return a.Example
 	}
-	if Design.NoExamples {
+	if rand.design().NoExamples {
 		return nil
 	}
 
@@ -1133,21 +1224,36 @@ func (a *AttributeDefinition) GenerateExample(rand *RandomGenerator, seen []stri
 
 	switch {
 	case a.Type.IsArray():
-		a.Example = a.arrayExample(rand, seen)
+		a.Example = a.arrayExample(rand, seen, path)
 
 	case a.Type.IsHash():
-		a.Example = a.hashExample(rand, seen)
+		a.Example = a.hashExample(rand, seen, path)
 
 	case a.Type.IsObject():
-		a.Example = a.objectExample(rand, seen)
+		a.Example = a.objectExample(rand, seen, path)
 
 	default:
-		a.Example = newExampleGenerator(a, rand).Generate(seen)
+		a.Example = newExampleGenerator(a, rand, path).Generate(seen)
 	}
 
 	return a.Example
 }
 
+// SetNullable marks the attribute value as nullable.
+func (a *AttributeDefinition) SetNullable() {
+	if a.Metadata == nil {
+		a.Metadata = map[string][]string{}
+	}
+	a.Metadata["schema:nullable"] = nil
+}
+
+// IsNullable returns true if the attribute value may be null (set using the SetNullable method or
+// the "schema:nullable" metadata).
+func (a *AttributeDefinition) IsNullable() bool {
+	m, ok := a.Metadata["schema:nullable"]
+	return ok && (len(m) == 0 || m[0] != "false")
+}
+
 // SetReadOnly sets the attribute's ReadOnly field as true.
 func (a *AttributeDefinition) SetReadOnly() {
 	if a.Metadata == nil {
@@ -1164,12 +1270,12 @@ func (a *AttributeDefinition) IsReadOnly() bool {
 	return false
 }
 
-func (a *AttributeDefinition) arrayExample(rand *RandomGenerator, seen []string) interface{} {
+func (a *AttributeDefinition) arrayExample(rand *RandomGenerator, seen []string, path string) interface{} {
 	ary := a.Type.ToArray()
-	ln := newExampleGenerator(a, rand).ExampleLength()
+	ln := newExampleGenerator(a, rand, path).ExampleLength()
 	var res []interface{}
 	for i := 0; i < ln; i++ {
-		ex := ary.ElemType.GenerateExample(rand, seen)
+		ex := ary.ElemType.generateExample(rand, seen, path)
 		if ex != nil {
 			res = append(res, ex)
 		}
@@ -1180,9 +1286,9 @@ func (a *AttributeDefinition) arrayExample(rand *RandomGenerator, seen []string)
 	return ary.MakeSlice(res)
 }
 
-func (a *AttributeDefinition) hashExample(rand *RandomGenerator, seen []string) interface{} {
+func (a *AttributeDefinition) hashExample(rand *RandomGenerator, seen []string, path string) interface{} {
 	h := a.Type.ToHash()
-	ln := newExampleGenerator(a, rand).ExampleLength()
+	ln := newExampleGenerator(a, rand, path).ExampleLength()
 	res := make(map[interface{}]interface{})
 	for i := 0; i < ln; i++ {
 		k := h.KeyType.GenerateExample(rand, seen)
@@ -1197,7 +1303,15 @@ func (a *AttributeDefinition) hashExample(rand *RandomGenerator, seen []string)
 	return h.MakeMap(res)
 }
 
-func (a *AttributeDefinition) objectExample(rand *RandomGenerator, seen []string) interface{} {
+func (a *AttributeDefinition) objectExample(rand *RandomGenerator, seen []string, path string) interface{} {
+	// attribute paths start with the name of the enclosing user type
+	switch t := a.Type.(type) {
+	case *UserTypeDefinition:
+		path = t.TypeName
+	case *MediaTypeDefinition:
+		path = t.TypeName
+	}
+
 	// project media types
 	actual := a
 	if mt, ok := a.Type.(*MediaTypeDefinition); ok {
@@ -1225,7 +1339,11 @@ func (a *AttributeDefinition) objectExample(rand *RandomGenerator, seen []string
 	res := make(map[string]interface{})
 	for _, n := range keys {
 		att := aObj[n]
-		if ex := att.GenerateExample(rand, seen); ex != nil {
+		p := n
+		if path != "" {
+			p = path + "." + n
+		}
+		if ex := att.generateExample(rand, seen, p); ex != nil {
 			res[n] = ex
 		}
 	}
@@ -1370,7 +1488,17 @@ func (l *LicenseDefinition) Context() string {
 
 // Context returns the generic definition name used in error messages.
 func (d *DocsDefinition) Context() string {
-	return fmt.Sprintf("documentation for %s", Design.Name)
+	return fmt.Sprintf("documentation for %s", CurrentDesign().Name)
+}
+
+// Context returns the generic definition name used in error messages.
+func (p *ProblemDetailsDefinition) Context() string {
+	return fmt.Sprintf("problem details of %s", CurrentDesign().Name)
+}
+
+// Context returns the generic definition name used in error messages.
+func (e *ErrorClassDefinition) Context() string {
+	return fmt.Sprintf("error class %#v", e.Code)
 }
 
 // Context returns the generic definition name used in error messages.
@@ -1518,7 +1646,7 @@ func (a *ActionDefinition) AllParams() *AttributeDefinition {
 	} else {
 		res = res.Merge(a.Parent.PathParams())
 	}
-	return res.Merge(Design.Params)
+	return res.Merge(a.Parent.design().Params)
 }
 
 // HasAbsoluteRoutes returns true if all the action routes are absolute.
@@ -1563,7 +1691,7 @@ func (a *ActionDefinition) EffectiveSchemes() []string {
 			parent = parent.Parent()
 		}
 		if len(schemes) == 0 {
-			schemes = Design.Schemes
+			schemes = res.design().Schemes
 		}
 	}
 	return schemes
@@ -1590,7 +1718,7 @@ func (a *ActionDefinition) Finalize() {
 	if a.Security == nil {
 		a.Security = a.Parent.Security // ResourceDefinition
 		if a.Security == nil {
-			a.Security = Design.Security
+			a.Security = a.Parent.design().Security
 		}
 	}
 
@@ -1618,7 +1746,7 @@ func (a *ActionDefinition) UserTypes() map[string]*UserTypeDefinition {
 		types[n] = ut
 	}
 	for _, r := range a.Responses {
-		if mt := Design.MediaTypeWithIdentifier(r.MediaType); mt != nil {
+		if mt := a.Parent.design().MediaTypeWithIdentifier(r.MediaType); mt != nil {
 			types[mt.TypeName] = mt.UserTypeDefinition
 			for n, ut := range UserTypes(mt.UserTypeDefinition) {
 				types[n] = ut
@@ -1680,10 +1808,10 @@ func (a *ActionDefinition) mergeResponses() {
 		if pr, ok := a.Parent.Responses[name]; ok {
 			resp.Merge(pr)
 		}
-		if ar, ok := Design.Responses[name]; ok {
+		if ar, ok := a.Parent.design().Responses[name]; ok {
 			resp.Merge(ar)
 		}
-		if dr, ok := Design.DefaultResponses[name]; ok {
+		if dr, ok := a.Parent.design().DefaultResponses[name]; ok {
 			resp.Merge(dr)
 		}
 	}
@@ -1717,7 +1845,7 @@ func (a *ActionDefinition) initImplicitParams() {
 			if found {
 				continue
 			}
-			search(Design.Params)
+			search(a.Parent.design().Params)
 			if found {
 				continue
 			}
@@ -1779,7 +1907,7 @@ func (f *FileServerDefinition) Finalize() {
 	if f.Security == nil {
 		f.Security = f.Parent.Security // ResourceDefinition
 		if f.Security == nil {
-			f.Security = Design.Security
+			f.Security = f.Parent.design().Security
 		}
 	}
 	if f.Security != nil && f.Security.Scheme.Kind == NoSecurityKind {
@@ -1902,3 +2030,19 @@ func iterateHeaders(headers *AttributeDefinition, isRequired func(name string) b
 	}
 	return nil
 }
+
+func iterateOrigins(origins map[string]*CORSDefinition, it CORSIterator) error {
+	names := make([]string, len(origins))
+	i := 0
+	for n := range origins {
+		names[i] = n
+		i++
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if err := it(origins[n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -126,6 +126,44 @@ var _ = Describe("IsRequired", func() {
 	})
 })
 
+var _ = Describe("IsPrimitivePointer", func() {
+	var nullable bool
+	var attribute *design.AttributeDefinition
+	var res bool
+
+	JustBeforeEach(func() {
+		integer := &design.AttributeDefinition{Type: design.Integer}
+		if nullable {
+			integer.SetNullable()
+		}
+		attribute = &design.AttributeDefinition{
+			Type:       design.Object{"required": integer},
+			Validation: &dslengine.ValidationDefinition{Required: []string{"required"}},
+		}
+		res = attribute.IsPrimitivePointer("required")
+	})
+
+	Context("called on a required field", func() {
+		BeforeEach(func() {
+			nullable = false
+		})
+
+		It("returns false", func() {
+			Ω(res).Should(BeFalse())
+		})
+	})
+
+	Context("called on a required nullable field", func() {
+		BeforeEach(func() {
+			nullable = true
+		})
+
+		It("returns true", func() {
+			Ω(res).Should(BeTrue())
+		})
+	})
+})
+
 var _ = Describe("IterateHeaders", func() {
 	It("works when Parent.Headers is nil", func() {
 		// create a Resource with no headers, Action with one header
@@ -149,6 +187,25 @@ var _ = Describe("IterateHeaders", func() {
 	})
 
 })
+var _ = Describe("IterateOrigins", func() {
+	It("sorts the policies by origin", func() {
+		resource := &design.ResourceDefinition{
+			Origins: map[string]*design.CORSDefinition{
+				"http://b.com": {Origin: "http://b.com"},
+				"http://a.com": {Origin: "http://a.com"},
+				"*":            {Origin: "*"},
+			},
+		}
+		var origins []string
+		it := func(o *design.CORSDefinition) error {
+			origins = append(origins, o.Origin)
+			return nil
+		}
+		Ω(resource.IterateOrigins(it)).Should(Succeed())
+		Ω(origins).Should(Equal([]string{"*", "http://a.com", "http://b.com"}))
+	})
+})
+
 var _ = Describe("Finalize ActionDefinition", func() {
 	Context("with an action with no response", func() {
 		var action *design.ActionDefinition
@@ -98,3 +98,594 @@ func (d *dupper) DupType(t DataType) DataType {
 	}
 	panic("unknown type " + t.Name())
 }
+
+// DeepCopy returns a copy of the API definition that shares no state with a. All the child
+// definitions (resources, actions, types, media types etc.) are copied recursively and the
+// parent relationships of the copies point to the copied parents. This makes it possible to
+// transform a copy of the design without altering the original.
+func (a *APIDefinition) DeepCopy() *APIDefinition {
+	return newDeepCopier().API(a)
+}
+
+// DeepCopy returns a copy of the resource definition and all its child definitions. The
+// copied actions, file servers and responses have the copied resource as parent.
+func (r *ResourceDefinition) DeepCopy() *ResourceDefinition {
+	return newDeepCopier().Resource(r)
+}
+
+// DeepCopy returns a copy of the action definition and all its child definitions. The copy
+// has the same parent resource as a.
+func (a *ActionDefinition) DeepCopy() *ActionDefinition {
+	return newDeepCopier().Action(a)
+}
+
+// DeepCopy returns a copy of the response definition including its type and headers. Unlike
+// Dup the type and headers of the copy share no state with r.
+func (r *ResponseDefinition) DeepCopy() *ResponseDefinition {
+	return newDeepCopier().Response(r)
+}
+
+// DeepCopy returns a copy of the route definition. The copy has the same parent action as r.
+func (r *RouteDefinition) DeepCopy() *RouteDefinition {
+	return newDeepCopier().Route(r)
+}
+
+// DeepCopy returns a copy of the file server definition. The copy has the same parent
+// resource as f.
+func (f *FileServerDefinition) DeepCopy() *FileServerDefinition {
+	return newDeepCopier().FileServer(f)
+}
+
+// DeepCopy returns a copy of the attribute definition including its type. Unlike DupAtt the
+// user types and media types used by the attribute are copied as well.
+func (a *AttributeDefinition) DeepCopy() *AttributeDefinition {
+	return newDeepCopier().Attribute(a)
+}
+
+// DeepCopy returns a copy of the user type and of all the types it uses.
+func (u *UserTypeDefinition) DeepCopy() *UserTypeDefinition {
+	return newDeepCopier().UserType(u)
+}
+
+// DeepCopy returns a copy of the media type including its links and views and all the types
+// it uses.
+func (m *MediaTypeDefinition) DeepCopy() *MediaTypeDefinition {
+	return newDeepCopier().MediaType(m)
+}
+
+// DeepCopy returns a copy of the security definition and of its scheme.
+func (s *SecurityDefinition) DeepCopy() *SecurityDefinition {
+	return newDeepCopier().Security(s)
+}
+
+// DeepCopy returns a copy of the security scheme.
+func (s *SecuritySchemeDefinition) DeepCopy() *SecuritySchemeDefinition {
+	return newDeepCopier().SecurityScheme(s)
+}
+
+// deepCopier implements recursive and cycle safe deep copies of design definitions. It keeps
+// track of the definitions already copied so that definitions referred to multiple times are
+// copied once and parent relationships point to the copies.
+type deepCopier struct {
+	apis      map[*APIDefinition]*APIDefinition
+	resources map[*ResourceDefinition]*ResourceDefinition
+	actions   map[*ActionDefinition]*ActionDefinition
+	uts       map[*UserTypeDefinition]*UserTypeDefinition
+	mts       map[*MediaTypeDefinition]*MediaTypeDefinition
+	schemes   map[*SecuritySchemeDefinition]*SecuritySchemeDefinition
+}
+
+// newDeepCopier returns a new initialized deepCopier.
+func newDeepCopier() *deepCopier {
+	return &deepCopier{
+		apis:      make(map[*APIDefinition]*APIDefinition),
+		resources: make(map[*ResourceDefinition]*ResourceDefinition),
+		actions:   make(map[*ActionDefinition]*ActionDefinition),
+		uts:       make(map[*UserTypeDefinition]*UserTypeDefinition),
+		mts:       make(map[*MediaTypeDefinition]*MediaTypeDefinition),
+		schemes:   make(map[*SecuritySchemeDefinition]*SecuritySchemeDefinition),
+	}
+}
+
+// API copies the given API definition.
+func (c *deepCopier) API(a *APIDefinition) *APIDefinition {
+	if a == nil {
+		return nil
+	}
+	if dup, ok := c.apis[a]; ok {
+		return dup
+	}
+	dup := &APIDefinition{
+		Name:           a.Name,
+		Title:          a.Title,
+		Description:    a.Description,
+		Version:        a.Version,
+		Host:           a.Host,
+		Schemes:        copyStrings(a.Schemes),
+		BasePath:       a.BasePath,
+		TermsOfService: a.TermsOfService,
+		DSLFunc:        a.DSLFunc,
+		Metadata:       copyMetadata(a.Metadata),
+		NoExamples:     a.NoExamples,
+	}
+	c.apis[a] = dup
+	dup.Params = c.Attribute(a.Params)
+	dup.Consumes = copyEncodings(a.Consumes)
+	dup.Produces = copyEncodings(a.Produces)
+	dup.Origins = c.origins(a.Origins)
+	if a.Contact != nil {
+		contact := *a.Contact
+		dup.Contact = &contact
+	}
+	if a.License != nil {
+		license := *a.License
+		dup.License = &license
+	}
+	dup.Docs = copyDocs(a.Docs)
+	if a.Types != nil {
+		dup.Types = make(map[string]*UserTypeDefinition, len(a.Types))
+		for n, ut := range a.Types {
+			dup.Types[n] = c.UserType(ut)
+		}
+	}
+	if a.MediaTypes != nil {
+		dup.MediaTypes = make(map[string]*MediaTypeDefinition, len(a.MediaTypes))
+		for n, mt := range a.MediaTypes {
+			dup.MediaTypes[n] = c.MediaType(mt)
+		}
+	}
+	if a.Resources != nil {
+		dup.Resources = make(map[string]*ResourceDefinition, len(a.Resources))
+		for n, r := range a.Resources {
+			dup.Resources[n] = c.Resource(r)
+		}
+	}
+	if a.Traits != nil {
+		dup.Traits = make(map[string]*dslengine.TraitDefinition, len(a.Traits))
+		for n, t := range a.Traits {
+			trait := *t
+			dup.Traits[n] = &trait
+		}
+	}
+	dup.Responses = c.responses(a.Responses)
+	dup.DefaultResponses = c.responses(a.DefaultResponses)
+	dup.ResponseTemplates = copyResponseTemplates(a.ResponseTemplates)
+	dup.DefaultResponseTemplates = copyResponseTemplates(a.DefaultResponseTemplates)
+	if a.SecuritySchemes != nil {
+		dup.SecuritySchemes = make([]*SecuritySchemeDefinition, len(a.SecuritySchemes))
+		for i, s := range a.SecuritySchemes {
+			dup.SecuritySchemes[i] = c.SecurityScheme(s)
+		}
+	}
+	dup.Security = c.Security(a.Security)
+	return dup
+}
+
+// Resource copies the given resource definition.
+func (c *deepCopier) Resource(r *ResourceDefinition) *ResourceDefinition {
+	if r == nil {
+		return nil
+	}
+	if dup, ok := c.resources[r]; ok {
+		return dup
+	}
+	dup := &ResourceDefinition{
+		Name:                r.Name,
+		Schemes:             copyStrings(r.Schemes),
+		BasePath:            r.BasePath,
+		ParentName:          r.ParentName,
+		Description:         r.Description,
+		MediaType:           r.MediaType,
+		DefaultViewName:     r.DefaultViewName,
+		CanonicalActionName: r.CanonicalActionName,
+		DSLFunc:             r.DSLFunc,
+		Metadata:            copyMetadata(r.Metadata),
+	}
+	c.resources[r] = dup
+	dup.Params = c.Attribute(r.Params)
+	dup.Headers = c.Attribute(r.Headers)
+	if r.Actions != nil {
+		dup.Actions = make(map[string]*ActionDefinition, len(r.Actions))
+		for n, a := range r.Actions {
+			dup.Actions[n] = c.Action(a)
+		}
+	}
+	if r.FileServers != nil {
+		dup.FileServers = make([]*FileServerDefinition, len(r.FileServers))
+		for i, f := range r.FileServers {
+			dup.FileServers[i] = c.FileServer(f)
+		}
+	}
+	dup.Responses = c.responses(r.Responses)
+	dup.Origins = c.origins(r.Origins)
+	dup.Security = c.Security(r.Security)
+	return dup
+}
+
+// Action copies the given action definition.
+func (c *deepCopier) Action(a *ActionDefinition) *ActionDefinition {
+	if a == nil {
+		return nil
+	}
+	if dup, ok := c.actions[a]; ok {
+		return dup
+	}
+	dup := &ActionDefinition{
+		Name:             a.Name,
+		Description:      a.Description,
+		Docs:             copyDocs(a.Docs),
+		Parent:           c.parentResource(a.Parent),
+		Schemes:          copyStrings(a.Schemes),
+		PayloadOptional:  a.PayloadOptional,
+		PayloadMultipart: a.PayloadMultipart,
+		Metadata:         copyMetadata(a.Metadata),
+	}
+	c.actions[a] = dup
+	if a.Routes != nil {
+		dup.Routes = make([]*RouteDefinition, len(a.Routes))
+		for i, r := range a.Routes {
+			dup.Routes[i] = c.Route(r)
+		}
+	}
+	dup.Responses = c.responses(a.Responses)
+	dup.Params = c.Attribute(a.Params)
+	dup.QueryParams = c.Attribute(a.QueryParams)
+	dup.Payload = c.UserType(a.Payload)
+	dup.Headers = c.Attribute(a.Headers)
+	dup.Security = c.Security(a.Security)
+	return dup
+}
+
+// Route copies the given route definition.
+func (c *deepCopier) Route(r *RouteDefinition) *RouteDefinition {
+	if r == nil {
+		return nil
+	}
+	parent := r.Parent
+	if p, ok := c.actions[parent]; ok {
+		parent = p
+	}
+	return &RouteDefinition{
+		Verb:     r.Verb,
+		Path:     r.Path,
+		Parent:   parent,
+		Metadata: copyMetadata(r.Metadata),
+	}
+}
+
+// FileServer copies the given file server definition.
+func (c *deepCopier) FileServer(f *FileServerDefinition) *FileServerDefinition {
+	if f == nil {
+		return nil
+	}
+	return &FileServerDefinition{
+		Parent:      c.parentResource(f.Parent),
+		Description: f.Description,
+		Docs:        copyDocs(f.Docs),
+		FilePath:    f.FilePath,
+		RequestPath: f.RequestPath,
+		Metadata:    copyMetadata(f.Metadata),
+		Security:    c.Security(f.Security),
+	}
+}
+
+// Response copies the given response definition.
+func (c *deepCopier) Response(r *ResponseDefinition) *ResponseDefinition {
+	if r == nil {
+		return nil
+	}
+	dup := &ResponseDefinition{
+		Name:        r.Name,
+		Status:      r.Status,
+		Description: r.Description,
+		MediaType:   r.MediaType,
+		ViewName:    r.ViewName,
+		Parent:      c.parent(r.Parent),
+		Metadata:    copyMetadata(r.Metadata),
+		Standard:    r.Standard,
+	}
+	if r.Type != nil {
+		dup.Type = c.Type(r.Type)
+	}
+	dup.Headers = c.Attribute(r.Headers)
+	return dup
+}
+
+// Security copies the given security definition.
+func (c *deepCopier) Security(s *SecurityDefinition) *SecurityDefinition {
+	if s == nil {
+		return nil
+	}
+	return &SecurityDefinition{
+		Scheme: c.SecurityScheme(s.Scheme),
+		Scopes: copyStrings(s.Scopes),
+	}
+}
+
+// SecurityScheme copies the given security scheme definition.
+func (c *deepCopier) SecurityScheme(s *SecuritySchemeDefinition) *SecuritySchemeDefinition {
+	if s == nil {
+		return nil
+	}
+	if dup, ok := c.schemes[s]; ok {
+		return dup
+	}
+	dup := *s
+	dup.Metadata = copyMetadata(s.Metadata)
+	if s.Scopes != nil {
+		dup.Scopes = make(map[string]string, len(s.Scopes))
+		for k, v := range s.Scopes {
+			dup.Scopes[k] = v
+		}
+	}
+	c.schemes[s] = &dup
+	return &dup
+}
+
+// Attribute copies the given attribute definition.
+func (c *deepCopier) Attribute(att *AttributeDefinition) *AttributeDefinition {
+	if att == nil {
+		return nil
+	}
+	dup := &AttributeDefinition{
+		Description:  att.Description,
+		Metadata:     copyMetadata(att.Metadata),
+		DefaultValue: copyValue(att.DefaultValue),
+		Example:      copyValue(att.Example),
+		View:         att.View,
+		DSLFunc:      att.DSLFunc,
+	}
+	if att.Type != nil {
+		dup.Type = c.Type(att.Type)
+	}
+	if att.Reference != nil {
+		dup.Reference = c.Type(att.Reference)
+	}
+	if att.Validation != nil {
+		dup.Validation = att.Validation.DeepCopy()
+	}
+	if att.NonZeroAttributes != nil {
+		dup.NonZeroAttributes = make(map[string]bool, len(att.NonZeroAttributes))
+		for n, nz := range att.NonZeroAttributes {
+			dup.NonZeroAttributes[n] = nz
+		}
+	}
+	return dup
+}
+
+// UserType copies the given user type definition.
+func (c *deepCopier) UserType(ut *UserTypeDefinition) *UserTypeDefinition {
+	if ut == nil {
+		return nil
+	}
+	if dup, ok := c.uts[ut]; ok {
+		return dup
+	}
+	dup := &UserTypeDefinition{TypeName: ut.TypeName}
+	c.uts[ut] = dup
+	dup.AttributeDefinition = c.Attribute(ut.AttributeDefinition)
+	return dup
+}
+
+// MediaType copies the given media type definition.
+func (c *deepCopier) MediaType(mt *MediaTypeDefinition) *MediaTypeDefinition {
+	if mt == nil {
+		return nil
+	}
+	if dup, ok := c.mts[mt]; ok {
+		return dup
+	}
+	dup := &MediaTypeDefinition{
+		Identifier:  mt.Identifier,
+		ContentType: mt.ContentType,
+	}
+	c.mts[mt] = dup
+	dup.UserTypeDefinition = c.UserType(mt.UserTypeDefinition)
+	if mt.Links != nil {
+		dup.Links = make(map[string]*LinkDefinition, len(mt.Links))
+		for n, l := range mt.Links {
+			link := *l
+			link.Parent = dup
+			dup.Links[n] = &link
+		}
+	}
+	if mt.Views != nil {
+		dup.Views = make(map[string]*ViewDefinition, len(mt.Views))
+		for n, v := range mt.Views {
+			dup.Views[n] = &ViewDefinition{
+				AttributeDefinition: c.Attribute(v.AttributeDefinition),
+				Name:                v.Name,
+				Parent:              dup,
+			}
+		}
+	}
+	dup.Resource = mt.Resource
+	if r, ok := c.resources[mt.Resource]; ok {
+		dup.Resource = r
+	}
+	return dup
+}
+
+// Type copies the given data type.
+func (c *deepCopier) Type(t DataType) DataType {
+	switch actual := t.(type) {
+	case Primitive:
+		return t
+	case *Array:
+		return &Array{ElemType: c.Attribute(actual.ElemType)}
+	case Object:
+		res := make(Object, len(actual))
+		for n, att := range actual {
+			res[n] = c.Attribute(att)
+		}
+		return res
+	case *Hash:
+		return &Hash{
+			KeyType:  c.Attribute(actual.KeyType),
+			ElemType: c.Attribute(actual.ElemType),
+		}
+	case *UserTypeDefinition:
+		return c.UserType(actual)
+	case *MediaTypeDefinition:
+		return c.MediaType(actual)
+	}
+	panic("unknown type " + t.Name())
+}
+
+// parentResource returns the copy of the given resource if it has been copied, the resource
+// itself otherwise.
+func (c *deepCopier) parentResource(r *ResourceDefinition) *ResourceDefinition {
+	if dup, ok := c.resources[r]; ok {
+		return dup
+	}
+	return r
+}
+
+// parent returns the copy of the given parent definition if it has been copied, the parent
+// itself otherwise.
+func (c *deepCopier) parent(p dslengine.Definition) dslengine.Definition {
+	switch actual := p.(type) {
+	case *APIDefinition:
+		if dup, ok := c.apis[actual]; ok {
+			return dup
+		}
+	case *ResourceDefinition:
+		if dup, ok := c.resources[actual]; ok {
+			return dup
+		}
+	case *ActionDefinition:
+		if dup, ok := c.actions[actual]; ok {
+			return dup
+		}
+	}
+	return p
+}
+
+// responses copies the given response definitions.
+func (c *deepCopier) responses(resps map[string]*ResponseDefinition) map[string]*ResponseDefinition {
+	if resps == nil {
+		return nil
+	}
+	dup := make(map[string]*ResponseDefinition, len(resps))
+	for n, r := range resps {
+		dup[n] = c.Response(r)
+	}
+	return dup
+}
+
+// origins copies the given CORS definitions.
+func (c *deepCopier) origins(origins map[string]*CORSDefinition) map[string]*CORSDefinition {
+	if origins == nil {
+		return nil
+	}
+	dup := make(map[string]*CORSDefinition, len(origins))
+	for n, o := range origins {
+		dup[n] = &CORSDefinition{
+			Parent:      c.parent(o.Parent),
+			Origin:      o.Origin,
+			Headers:     copyStrings(o.Headers),
+			Methods:     copyStrings(o.Methods),
+			Exposed:     copyStrings(o.Exposed),
+			MaxAge:      o.MaxAge,
+			Credentials: o.Credentials,
+			Regexp:      o.Regexp,
+		}
+	}
+	return dup
+}
+
+// copyEncodings copies the given encoding definitions.
+func copyEncodings(encs []*EncodingDefinition) []*EncodingDefinition {
+	if encs == nil {
+		return nil
+	}
+	dup := make([]*EncodingDefinition, len(encs))
+	for i, e := range encs {
+		dup[i] = &EncodingDefinition{
+			MIMETypes:   copyStrings(e.MIMETypes),
+			PackagePath: e.PackagePath,
+			Function:    e.Function,
+			Encoder:     e.Encoder,
+		}
+	}
+	return dup
+}
+
+// copyResponseTemplates copies the given response template definitions.
+func copyResponseTemplates(tmpls map[string]*ResponseTemplateDefinition) map[string]*ResponseTemplateDefinition {
+	if tmpls == nil {
+		return nil
+	}
+	dup := make(map[string]*ResponseTemplateDefinition, len(tmpls))
+	for n, t := range tmpls {
+		tmpl := *t
+		dup[n] = &tmpl
+	}
+	return dup
+}
+
+// copyDocs copies the given docs definition.
+func copyDocs(docs *DocsDefinition) *DocsDefinition {
+	if docs == nil {
+		return nil
+	}
+	dup := *docs
+	return &dup
+}
+
+// copyStrings copies the given string slice.
+func copyStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string{}, s...)
+}
+
+// copyMetadata copies the given metadata.
+func copyMetadata(md dslengine.MetadataDefinition) dslengine.MetadataDefinition {
+	if md == nil {
+		return nil
+	}
+	dup := make(dslengine.MetadataDefinition, len(md))
+	for k, v := range md {
+		dup[k] = copyStrings(v)
+	}
+	return dup
+}
+
+// copyValue copies the given default or example value.
+func copyValue(val interface{}) interface{} {
+	switch actual := val.(type) {
+	case []interface{}:
+		dup := make([]interface{}, len(actual))
+		for i, v := range actual {
+			dup[i] = copyValue(v)
+		}
+		return dup
+	case ArrayVal:
+		dup := make(ArrayVal, len(actual))
+		for i, v := range actual {
+			dup[i] = copyValue(v)
+		}
+		return dup
+	case map[string]interface{}:
+		dup := make(map[string]interface{}, len(actual))
+		for k, v := range actual {
+			dup[k] = copyValue(v)
+		}
+		return dup
+	case map[interface{}]interface{}:
+		dup := make(map[interface{}]interface{}, len(actual))
+		for k, v := range actual {
+			dup[k] = copyValue(v)
+		}
+		return dup
+	case HashVal:
+		dup := make(HashVal, len(actual))
+		for k, v := range actual {
+			dup[k] = copyValue(v)
+		}
+		return dup
+	}
+	return val
+}
@@ -5,6 +5,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/dslengine"
 )
 
 var _ = Describe("Dup", func() {
@@ -166,3 +167,85 @@ var _ = Describe("DupAtt", func() {
 		})
 	})
 })
+
+var _ = Describe("DeepCopy", func() {
+	var api *APIDefinition
+	var dup *APIDefinition
+
+	BeforeEach(func() {
+		mt := &MediaTypeDefinition{
+			UserTypeDefinition: &UserTypeDefinition{
+				AttributeDefinition: &AttributeDefinition{Type: Object{}},
+				TypeName:            "Bottle",
+			},
+			Identifier: "application/vnd.bottle",
+		}
+		mt.Type.(Object)["child"] = &AttributeDefinition{Type: mt}
+		mt.Type.(Object)["name"] = &AttributeDefinition{
+			Type:       String,
+			Example:    []interface{}{"a", "b"},
+			Validation: &dslengine.ValidationDefinition{Values: []interface{}{"a"}},
+		}
+		res := &ResourceDefinition{Name: "bottles", Metadata: dslengine.MetadataDefinition{"k": {"v"}}}
+		act := &ActionDefinition{Name: "show", Parent: res}
+		act.Routes = []*RouteDefinition{{Verb: "GET", Path: "/:id", Parent: act}}
+		act.Responses = map[string]*ResponseDefinition{"OK": {Name: "OK", Type: mt, Parent: act}}
+		res.Actions = map[string]*ActionDefinition{"show": act}
+		api = &APIDefinition{
+			Name:       "test",
+			Schemes:    []string{"http"},
+			Resources:  map[string]*ResourceDefinition{"bottles": res},
+			MediaTypes: map[string]*MediaTypeDefinition{mt.Identifier: mt},
+		}
+	})
+
+	JustBeforeEach(func() {
+		dup = api.DeepCopy()
+	})
+
+	It("copies all the definitions", func() {
+		Ω(dup).ShouldNot(BeIdenticalTo(api))
+		Ω(dup.Name).Should(Equal("test"))
+		Ω(dup.Resources).Should(HaveKey("bottles"))
+		Ω(dup.Resources["bottles"]).ShouldNot(BeIdenticalTo(api.Resources["bottles"]))
+		Ω(dup.Resources["bottles"].Actions).Should(HaveKey("show"))
+		Ω(dup.MediaTypes["application/vnd.bottle"]).ShouldNot(BeIdenticalTo(api.MediaTypes["application/vnd.bottle"]))
+	})
+
+	It("sets the parents to the copies", func() {
+		r := dup.Resources["bottles"]
+		a := r.Actions["show"]
+		Ω(a.Parent).Should(BeIdenticalTo(r))
+		Ω(a.Routes[0].Parent).Should(BeIdenticalTo(a))
+		Ω(a.Responses["OK"].Parent).Should(BeIdenticalTo(a))
+	})
+
+	It("preserves shared and recursive types", func() {
+		mt := dup.MediaTypes["application/vnd.bottle"]
+		Ω(dup.Resources["bottles"].Actions["show"].Responses["OK"].Type).Should(BeIdenticalTo(mt))
+		Ω(mt.Type.(Object)["child"].Type).Should(BeIdenticalTo(mt))
+	})
+
+	It("does not share state with the original", func() {
+		dup.Schemes[0] = "https"
+		dup.Resources["bottles"].Metadata["k"][0] = "w"
+		name := dup.MediaTypes["application/vnd.bottle"].Type.(Object)["name"]
+		name.Example.([]interface{})[0] = "c"
+		name.Validation.Values[0] = "c"
+		Ω(api.Schemes[0]).Should(Equal("http"))
+		Ω(api.Resources["bottles"].Metadata["k"][0]).Should(Equal("v"))
+		orig := api.MediaTypes["application/vnd.bottle"].Type.(Object)["name"]
+		Ω(orig.Example.([]interface{})[0]).Should(Equal("a"))
+		Ω(orig.Validation.Values[0]).Should(Equal("a"))
+	})
+
+	Context("with an action", func() {
+		It("keeps the original parent", func() {
+			act := api.Resources["bottles"].Actions["show"]
+			dupAct := act.DeepCopy()
+			Ω(dupAct).ShouldNot(BeIdenticalTo(act))
+			Ω(dupAct.Parent).Should(BeIdenticalTo(act.Parent))
+			Ω(dupAct.Routes[0].Parent).Should(BeIdenticalTo(dupAct))
+		})
+	})
+})
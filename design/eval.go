@@ -0,0 +1,100 @@
+package design
+
+import "github.com/kyokomi/goa-v1/dslengine"
+
+// Eval evaluates the design defined by dsl into a new API definition and returns it. dsl must call
+// the top level DSL functions (API, Resource, MediaType, Type etc.) that make up the design, for
+// example:
+//
+//	api, err := design.Eval(func() {
+//		apidsl.API("cellar", func() { ... })
+//		apidsl.Resource("bottle", func() { ... })
+//	})
+//
+// The new API definition and its generated media types are the roots evaluated by
+// dslengine.RunRoots, the DSL functions retrieve them with CurrentDesign and
+// CurrentGeneratedMediaTypes. The package level Design, GeneratedMediaTypes and
+// ProjectedMediaTypes variables are left untouched so that multiple designs may be evaluated in
+// the same process without interfering with each other or with the design registered by the apidsl
+// package. Once evaluated the resources and media types of the API refer to it rather than to
+// Design, the API can be given to the generators, e.g. via the genapp.API option.
+//
+// The DSL functions are package level functions that find the definition they build through the
+// evaluation state of the dslengine package so that state remains global: concurrent calls to
+// Eval are serialized.
+func Eval(dsl func()) (*APIDefinition, error) {
+	api := NewAPIDefinition()
+	api.projected = make(MediaTypeRoot)
+	if err := dslengine.RunRoots(dsl, api, make(MediaTypeRoot)); err != nil {
+		return nil, err
+	}
+	api.bind()
+	return api, nil
+}
+
+// bind associates the resources, media types and random generator of the API with it.
+func (a *APIDefinition) bind() {
+	for _, r := range a.Resources {
+		r.api = a
+	}
+	for _, mt := range a.MediaTypes {
+		mt.api = a
+	}
+	a.RandomGenerator().api = a
+}
+
+// CurrentDesign returns the API definition being evaluated by Eval if any, Design otherwise.
+// Definitions that belong to an API evaluated by Eval use that API instead once the evaluation
+// is over.
+func CurrentDesign() *APIDefinition {
+	for _, r := range dslengine.CurrentRoots() {
+		if api, ok := r.(*APIDefinition); ok {
+			return api
+		}
+	}
+	return Design
+}
+
+// CurrentGeneratedMediaTypes returns the media types generated by the design being evaluated by
+// Eval if any, GeneratedMediaTypes otherwise.
+func CurrentGeneratedMediaTypes() MediaTypeRoot {
+	for _, r := range dslengine.CurrentRoots() {
+		if mts, ok := r.(MediaTypeRoot); ok {
+			return mts
+		}
+	}
+	return GeneratedMediaTypes
+}
+
+// currentProjectedMediaTypes returns the cache of the media types projected while evaluating the
+// design.
+func currentProjectedMediaTypes() MediaTypeRoot {
+	if api := CurrentDesign(); api != nil && api.projected != nil {
+		return api.projected
+	}
+	return ProjectedMediaTypes
+}
+
+// design returns the API the resource belongs to.
+func (r *ResourceDefinition) design() *APIDefinition {
+	if r.api != nil {
+		return r.api
+	}
+	return CurrentDesign()
+}
+
+// projections returns the cache of the projections of the media type.
+func (m *MediaTypeDefinition) projections() MediaTypeRoot {
+	if m.api != nil {
+		return m.api.projected
+	}
+	return currentProjectedMediaTypes()
+}
+
+// design returns the API whose settings apply to the generated examples.
+func (r *RandomGenerator) design() *APIDefinition {
+	if r != nil && r.api != nil {
+		return r.api
+	}
+	return CurrentDesign()
+}
@@ -4,20 +4,167 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"strings"
 	"time"
 
-	regen "github.com/zach-klippenstein/goregen"
+	"github.com/manveru/faker"
 )
 
+// ExampleFunc produces an example value for the given attribute. path is the path of the
+// attribute as described in RegisterAttributeExample or the empty string if the attribute is not
+// the field of an object.
+type ExampleFunc func(r *RandomGenerator, path string, att *AttributeDefinition) interface{}
+
+// nameExample is a built-in example generator used for attributes whose name matches.
+type nameExample struct {
+	kind Kind
+	fn   ExampleFunc
+}
+
+var (
+	// attributeExamples lists the user registered example generators indexed by normalized
+	// attribute path.
+	attributeExamples = make(map[string]ExampleFunc)
+
+	// formatExamples lists the example generators indexed by validation format.
+	formatExamples = map[string]ExampleFunc{
+		"email":     fakerExample((*faker.Faker).Email),
+		"hostname":  randExample(func(r *RandomGenerator) interface{} { return r.faker.DomainName() + "." + r.faker.DomainSuffix() }),
+		"date":      timeExample("2006-01-02"),
+		"date-time": timeExample(time.RFC3339),
+		"ipv4":      randExample(func(r *RandomGenerator) interface{} { return r.faker.IPv4Address().String() }),
+		"ipv6":      randExample(func(r *RandomGenerator) interface{} { return r.faker.IPv6Address().String() }),
+		"ip":        randExample(func(r *RandomGenerator) interface{} { return r.faker.IPv4Address().String() }),
+		"uri":       fakerExample((*faker.Faker).URL),
+		"mac": randExample(func(r *RandomGenerator) interface{} {
+			res, err := r.Regexp(`([0-9A-F]{2}-){5}[0-9A-F]{2}`)
+			if err != nil {
+				return "12-34-56-78-9A-BC"
+			}
+			return res
+		}),
+		"cidr":    randExample(func(r *RandomGenerator) interface{} { return "192.168.100.14/24" }),
+		"regexp":  randExample(func(r *RandomGenerator) interface{} { return r.faker.Characters(3) + ".*" }),
+		"rfc1123": timeExample(time.RFC1123),
+		"uuid":    randExample(func(r *RandomGenerator) interface{} { return r.UUID().String() }),
+	}
+
+	// nameExamples lists the built-in example generators used for string and number
+	// attributes that have no format or pattern validation, indexed by normalized attribute
+	// name.
+	nameExamples = map[string]nameExample{
+		"email":         {StringKind, fakerExample((*faker.Faker).Email)},
+		"emailaddress":  {StringKind, fakerExample((*faker.Faker).Email)},
+		"name":          {StringKind, fakerExample((*faker.Faker).Name)},
+		"fullname":      {StringKind, fakerExample((*faker.Faker).Name)},
+		"firstname":     {StringKind, fakerExample((*faker.Faker).FirstName)},
+		"givenname":     {StringKind, fakerExample((*faker.Faker).FirstName)},
+		"lastname":      {StringKind, fakerExample((*faker.Faker).LastName)},
+		"familyname":    {StringKind, fakerExample((*faker.Faker).LastName)},
+		"surname":       {StringKind, fakerExample((*faker.Faker).LastName)},
+		"username":      {StringKind, fakerExample((*faker.Faker).UserName)},
+		"login":         {StringKind, fakerExample((*faker.Faker).UserName)},
+		"nickname":      {StringKind, fakerExample((*faker.Faker).UserName)},
+		"url":           {StringKind, fakerExample((*faker.Faker).URL)},
+		"uri":           {StringKind, fakerExample((*faker.Faker).URL)},
+		"website":       {StringKind, fakerExample((*faker.Faker).URL)},
+		"homepage":      {StringKind, fakerExample((*faker.Faker).URL)},
+		"domain":        {StringKind, fakerExample((*faker.Faker).DomainName)},
+		"hostname":      {StringKind, fakerExample((*faker.Faker).DomainName)},
+		"address":       {StringKind, numerifiedExample((*faker.Faker).StreetAddress)},
+		"street":        {StringKind, numerifiedExample((*faker.Faker).StreetAddress)},
+		"streetaddress": {StringKind, numerifiedExample((*faker.Faker).StreetAddress)},
+		"city":          {StringKind, fakerExample((*faker.Faker).City)},
+		"state":         {StringKind, fakerExample((*faker.Faker).State)},
+		"country":       {StringKind, fakerExample((*faker.Faker).Country)},
+		"zip":           {StringKind, numerifiedExample((*faker.Faker).PostCode)},
+		"zipcode":       {StringKind, numerifiedExample((*faker.Faker).PostCode)},
+		"postcode":      {StringKind, numerifiedExample((*faker.Faker).PostCode)},
+		"postalcode":    {StringKind, numerifiedExample((*faker.Faker).PostCode)},
+		"phone":         {StringKind, numerifiedExample((*faker.Faker).PhoneNumber)},
+		"phonenumber":   {StringKind, numerifiedExample((*faker.Faker).PhoneNumber)},
+		"telephone":     {StringKind, numerifiedExample((*faker.Faker).PhoneNumber)},
+		"mobile":        {StringKind, numerifiedExample((*faker.Faker).CellPhoneNumber)},
+		"company":       {StringKind, fakerExample((*faker.Faker).CompanyName)},
+		"companyname":   {StringKind, fakerExample((*faker.Faker).CompanyName)},
+		"organization":  {StringKind, fakerExample((*faker.Faker).CompanyName)},
+		"jobtitle":      {StringKind, fakerExample((*faker.Faker).JobTitle)},
+		"latitude":      {NumberKind, randExample(func(r *RandomGenerator) interface{} { return r.faker.Latitude() })},
+		"lat":           {NumberKind, randExample(func(r *RandomGenerator) interface{} { return r.faker.Latitude() })},
+		"longitude":     {NumberKind, randExample(func(r *RandomGenerator) interface{} { return r.faker.Longitude() })},
+		"lng":           {NumberKind, randExample(func(r *RandomGenerator) interface{} { return r.faker.Longitude() })},
+	}
+)
+
+// RegisterFormatExample registers the function used to generate examples for attributes that
+// define the given format validation, overriding any built-in generator for that format.
+func RegisterFormatExample(format string, fn ExampleFunc) {
+	formatExamples[format] = fn
+}
+
+// RegisterAttributeExample registers the function used to generate examples for the attributes
+// matching path. path is either the name of an attribute (e.g. "email") or the path to an
+// attribute starting with the name of the user type or media type that defines it (e.g.
+// "User.email" or "User.address.city" for attributes of inline objects). Matching ignores case,
+// dashes and underscores so that "createdAt" also matches "created_at".
+// Registered functions take precedence over any other example generation method.
+func RegisterAttributeExample(path string, fn ExampleFunc) {
+	attributeExamples[normalizeExamplePath(path)] = fn
+}
+
+// normalizeExamplePath returns the canonical form of the given attribute path used to index the
+// example generators.
+func normalizeExamplePath(path string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(path))
+}
+
+// fakerExample returns an example function that uses the given faker method.
+func fakerExample(f func(*faker.Faker) string) ExampleFunc {
+	return func(r *RandomGenerator, _ string, _ *AttributeDefinition) interface{} {
+		return f(r.faker)
+	}
+}
+
+// numerifiedExample returns an example function that uses the given faker method producing
+// values with digits (addresses, phone numbers etc.). faker draws these digits from the global
+// random source so numerifiedExample draws them again from the generator random source to keep
+// the examples consistent for a given seed.
+func numerifiedExample(f func(*faker.Faker) string) ExampleFunc {
+	return func(r *RandomGenerator, _ string, _ *AttributeDefinition) interface{} {
+		return strings.Map(func(c rune) rune {
+			if c >= '0' && c <= '9' {
+				return rune('0' + r.rand.Intn(10))
+			}
+			return c
+		}, f(r.faker))
+	}
+}
+
+// randExample returns an example function that only requires the random generator.
+func randExample(f func(*RandomGenerator) interface{}) ExampleFunc {
+	return func(r *RandomGenerator, _ string, _ *AttributeDefinition) interface{} {
+		return f(r)
+	}
+}
+
+// timeExample returns an example function that produces dates using the given layout.
+func timeExample(layout string) ExampleFunc {
+	return func(r *RandomGenerator, _ string, _ *AttributeDefinition) interface{} {
+		return r.DateTime().Format(layout)
+	}
+}
+
 // exampleGenerator generates a random example based on the given validations on the definition.
 type exampleGenerator struct {
-	a *AttributeDefinition
-	r *RandomGenerator
+	a    *AttributeDefinition
+	r    *RandomGenerator
+	path string
 }
 
 // newExampleGenerator returns an example generator that uses the given random generator.
-func newExampleGenerator(a *AttributeDefinition, r *RandomGenerator) *exampleGenerator {
-	return &exampleGenerator{a, r}
+// path is the path of the attribute used to look up registered example generators.
+func newExampleGenerator(a *AttributeDefinition, r *RandomGenerator, path string) *exampleGenerator {
+	return &exampleGenerator{a, r, path}
 }
 
 // Maximum number of tries for generating example.
@@ -25,6 +172,10 @@ const maxAttempts = 500
 
 // Generate generates a random value based on the given validations.
 func (eg *exampleGenerator) Generate(seen []string) interface{} {
+	// Registered generators win, the user knows best
+	if fn := eg.registeredExample(); fn != nil {
+		return fn(eg.r, eg.path, eg.a)
+	}
 	// Randomize array length first, since that's from higher level
 	if eg.hasLengthValidation() {
 		return eg.generateValidatedLengthExample(seen)
@@ -33,8 +184,20 @@ func (eg *exampleGenerator) Generate(seen []string) interface{} {
 	if eg.hasEnumValidation() {
 		return eg.generateValidatedEnumExample()
 	}
+	// Decimal precision and scale fully determine the shape of the example
+	if eg.hasDecimalValidation() {
+		return eg.generateValidatedDecimalExample()
+	}
 	// loop until a satisified example is generated
 	hasFormat, hasPattern, hasMinMax := eg.hasFormatValidation(), eg.hasPatternValidation(), eg.hasMinMaxValidation()
+	if !hasFormat && !hasPattern {
+		if fn := eg.nameExample(); fn != nil {
+			ex := fn(eg.r, eg.path, eg.a)
+			if !hasMinMax || eg.checkMinMaxValueValidation(ex) {
+				return ex
+			}
+		}
+	}
 	attempts := 0
 	for attempts < maxAttempts {
 		attempts++
@@ -66,6 +229,41 @@ func (eg *exampleGenerator) Generate(seen []string) interface{} {
 	return eg.a.Type.GenerateExample(eg.r, seen)
 }
 
+// registeredExample returns the user registered example function that matches the generator
+// attribute path if any. The most qualified path wins.
+func (eg *exampleGenerator) registeredExample() ExampleFunc {
+	if eg.path == "" || len(attributeExamples) == 0 {
+		return nil
+	}
+	path := normalizeExamplePath(eg.path)
+	for {
+		if fn, ok := attributeExamples[path]; ok {
+			return fn
+		}
+		idx := strings.Index(path, ".")
+		if idx == -1 {
+			return nil
+		}
+		path = path[idx+1:]
+	}
+}
+
+// nameExample returns the built-in example function that matches the generator attribute name
+// if any.
+func (eg *exampleGenerator) nameExample() ExampleFunc {
+	if eg.path == "" {
+		return nil
+	}
+	name := eg.path
+	if idx := strings.LastIndex(name, "."); idx != -1 {
+		name = name[idx+1:]
+	}
+	if ne, ok := nameExamples[normalizeExamplePath(name)]; ok && ne.kind == eg.a.Type.Kind() {
+		return ne.fn
+	}
+	return nil
+}
+
 func (eg *exampleGenerator) ExampleLength() int {
 	if eg.hasLengthValidation() {
 		minlength, maxlength := math.Inf(1), math.Inf(-1)
@@ -139,6 +337,41 @@ func (eg *exampleGenerator) generateValidatedEnumExample() interface{} {
 	return values[i]
 }
 
+func (eg *exampleGenerator) hasDecimalValidation() bool {
+	return eg.a.Type.Kind() == DecimalKind && eg.a.Validation != nil &&
+		(eg.a.Validation.Precision != nil || eg.a.Validation.Scale != nil)
+}
+
+// generateValidatedDecimalExample returns a random decimal value that has at most the precision
+// and scale defined by the validations.
+func (eg *exampleGenerator) generateValidatedDecimalExample() interface{} {
+	precision, scale := 6, 2
+	if p := eg.a.Validation.Precision; p != nil {
+		precision = *p
+	}
+	if s := eg.a.Validation.Scale; s != nil {
+		scale = *s
+	}
+	if scale > precision {
+		scale = precision
+	}
+	digits := func(n int) string {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = byte('0' + eg.r.rand.Intn(10))
+		}
+		return string(b)
+	}
+	intPart := strings.TrimLeft(digits(precision-scale), "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+	if scale == 0 {
+		return intPart
+	}
+	return intPart + "." + digits(scale)
+}
+
 func (eg *exampleGenerator) hasFormatValidation() bool {
 	return eg.a.Validation != nil && eg.a.Validation.Format != ""
 }
@@ -149,27 +382,8 @@ func (eg *exampleGenerator) generateFormatExample() interface{} {
 		return nil
 	}
 	format := eg.a.Validation.Format
-	if res, ok := map[string]interface{}{
-		"email":     eg.r.faker.Email(),
-		"hostname":  eg.r.faker.DomainName() + "." + eg.r.faker.DomainSuffix(),
-		"date":      time.Unix(int64(eg.r.Int())%1454957045, 0).Format("2006-01-02"), // to obtain a "fixed" rand
-		"date-time": time.Unix(int64(eg.r.Int())%1454957045, 0).Format(time.RFC3339), // to obtain a "fixed" rand
-		"ipv4":      eg.r.faker.IPv4Address().String(),
-		"ipv6":      eg.r.faker.IPv6Address().String(),
-		"ip":        eg.r.faker.IPv4Address().String(),
-		"uri":       eg.r.faker.URL(),
-		"mac": func() string {
-			res, err := regen.Generate(`([0-9A-F]{2}-){5}[0-9A-F]{2}`)
-			if err != nil {
-				return "12-34-56-78-9A-BC"
-			}
-			return res
-		}(),
-		"cidr":    "192.168.100.14/24",
-		"regexp":  eg.r.faker.Characters(3) + ".*",
-		"rfc1123": time.Unix(int64(eg.r.Int())%1454957045, 0).Format(time.RFC1123), // to obtain a "fixed" rand
-	}[format]; ok {
-		return res
+	if fn, ok := formatExamples[format]; ok {
+		return fn(eg.r, eg.path, eg.a)
 	}
 	panic("Validation: unknown format '" + format + "'") // bug
 }
@@ -200,7 +414,7 @@ func (eg *exampleGenerator) generateValidatedPatternExample() interface{} {
 		return false
 	}
 	pattern := eg.a.Validation.Pattern
-	example, err := regen.Generate(pattern)
+	example, err := eg.r.Regexp(pattern)
 	if err != nil {
 		return eg.r.faker.Name()
 	}
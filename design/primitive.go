@@ -0,0 +1,127 @@
+package design
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MinCustomKind is the smallest kind value that can be used to register custom primitives.
+const MinCustomKind Kind = 100
+
+// PrimitiveDefinition describes a custom primitive type registered with RegisterPrimitive.
+type PrimitiveDefinition struct {
+	// Kind is the unique kind of the primitive.
+	Kind Kind
+	// Name is the design name of the primitive (e.g. "ulid"), it may be used to refer to the
+	// primitive in attribute definitions.
+	Name string
+	// GoType is the Go type generated for the primitive qualified with its package name
+	// (e.g. "ulid.ULID").
+	GoType string
+	// GoPackage is the import path of the package that defines GoType if any
+	// (e.g. "github.com/oklog/ulid").
+	GoPackage string
+	// JSONType is the JSON type of the serialized values, one of "string", "integer",
+	// "number" or "boolean".
+	JSONType string
+	// ParseFunc is the name of the function called by the generated code to parse values
+	// from their string representation (path and query string parameters, headers, CLI
+	// flags), e.g. "time.ParseDuration". The function must accept a string and return a
+	// GoType value and an error. If empty the generated code calls UnmarshalText on a
+	// GoType value.
+	ParseFunc string
+	// FormatFunc is the name of the function called by the generated clients to format
+	// values into their string representation, e.g. "ulid.ULID.String". The function must
+	// accept a GoType value and return a string. If empty the generated code uses
+	// fmt.Sprintf("%v").
+	FormatFunc string
+}
+
+// customPrimitives lists the registered custom primitives indexed by kind.
+var customPrimitives = make(map[Kind]*PrimitiveDefinition)
+
+// RegisterPrimitive registers a custom primitive type and returns it. The returned value can
+// be used in the design like any other primitive type (e.g. String or UUID).
+//
+// kind must be unique and greater than or equal to MinCustomKind. name is the design name of
+// the primitive. goType is the Go type used to represent the values in the generated code,
+// qualified with the full import path of its package if it isn't a builtin type (e.g.
+// "github.com/oklog/ulid.ULID" or "time.Duration"). jsonType is the JSON type used to
+// serialize the values, one of "string", "integer", "number" or "boolean".
+//
+// The Go type is responsible for marshaling and unmarshaling itself to and from JSON. Values
+// read from strings such as parameters and headers are parsed with the function set in the
+// ParseFunc field of the primitive definition (see LookupPrimitive) or via UnmarshalText if
+// none is set.
+//
+// RegisterPrimitive panics if the kind or name is already used or if jsonType is invalid, it
+// is meant to be called from init functions:
+//
+//	var ULID = design.RegisterPrimitive(design.MinCustomKind, "ulid", "github.com/oklog/ulid.ULID", "string")
+//
+//	func init() {
+//		design.LookupPrimitive("ulid").ParseFunc = "ulid.Parse"
+//	}
+func RegisterPrimitive(kind Kind, name, goType, jsonType string) Primitive {
+	if kind < MinCustomKind {
+		panic(fmt.Sprintf("invalid primitive kind %d, custom primitive kinds must be at least %d", kind, MinCustomKind))
+	}
+	if p, ok := customPrimitives[kind]; ok {
+		panic(fmt.Sprintf("primitive kind %d is already registered for %#v", kind, p.Name))
+	}
+	if LookupPrimitive(name) != nil {
+		panic(fmt.Sprintf("primitive %#v is already registered", name))
+	}
+	switch jsonType {
+	case "string", "integer", "number", "boolean":
+	default:
+		panic(fmt.Sprintf("invalid JSON type %#v for primitive %#v, must be one of string, integer, number or boolean", jsonType, name))
+	}
+	gt, pkg := goType, ""
+	if dot := strings.LastIndex(goType, "."); dot > 0 {
+		pkg = goType[:dot]
+		gt = goType[strings.LastIndex(pkg, "/")+1:]
+	}
+	customPrimitives[kind] = &PrimitiveDefinition{
+		Kind:      kind,
+		Name:      name,
+		GoType:    gt,
+		GoPackage: pkg,
+		JSONType:  jsonType,
+	}
+	return Primitive(kind)
+}
+
+// LookupPrimitive returns the custom primitive registered with the given name, nil if there
+// isn't one.
+func LookupPrimitive(name string) *PrimitiveDefinition {
+	for _, p := range customPrimitives {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// CustomPrimitive returns the definition of the custom primitive t if t is one, nil otherwise.
+func CustomPrimitive(t DataType) *PrimitiveDefinition {
+	if p, ok := t.(Primitive); ok {
+		return customPrimitives[p.Kind()]
+	}
+	return nil
+}
+
+// jsonPrimitive returns the builtin primitive that corresponds to the JSON type of the custom
+// primitive.
+func (p *PrimitiveDefinition) jsonPrimitive() Primitive {
+	switch p.JSONType {
+	case "integer":
+		return Integer
+	case "number":
+		return Number
+	case "boolean":
+		return Boolean
+	default:
+		return String
+	}
+}
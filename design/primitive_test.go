@@ -0,0 +1,46 @@
+package design_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+)
+
+var testULID = RegisterPrimitive(MinCustomKind, "ulid", "github.com/oklog/ulid.ULID", "string")
+
+var _ = Describe("RegisterPrimitive", func() {
+	It("registers the primitive definition", func() {
+		p := CustomPrimitive(testULID)
+		Ω(p).ShouldNot(BeNil())
+		Ω(p.Name).Should(Equal("ulid"))
+		Ω(p.GoType).Should(Equal("ulid.ULID"))
+		Ω(p.GoPackage).Should(Equal("github.com/oklog/ulid"))
+		Ω(LookupPrimitive("ulid")).Should(Equal(p))
+	})
+
+	It("uses the JSON type of the primitive", func() {
+		Ω(testULID.Kind()).Should(Equal(MinCustomKind))
+		Ω(testULID.Name()).Should(Equal("string"))
+		Ω(testULID.IsCompatible("01ARZ3NDEKTSV4RRFFQ69G5FAV")).Should(BeTrue())
+		Ω(testULID.IsCompatible(42)).Should(BeFalse())
+		Ω(testULID.GenerateExample(NewRandomGenerator("foo"), nil)).Should(BeAssignableToTypeOf(""))
+	})
+
+	It("does not consider builtin primitives as custom", func() {
+		Ω(CustomPrimitive(String)).Should(BeNil())
+		Ω(CustomPrimitive(Decimal)).Should(BeNil())
+	})
+
+	It("panics when the kind is already registered", func() {
+		Ω(func() { RegisterPrimitive(MinCustomKind, "other", "string", "string") }).Should(Panic())
+	})
+
+	It("panics when the kind is reserved", func() {
+		Ω(func() { RegisterPrimitive(StringKind, "other", "string", "string") }).Should(Panic())
+	})
+
+	It("panics when the JSON type is invalid", func() {
+		Ω(func() { RegisterPrimitive(MinCustomKind+1, "other", "string", "object") }).Should(Panic())
+	})
+})
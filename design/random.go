@@ -9,6 +9,7 @@ import (
 
 	"github.com/gofrs/uuid"
 	"github.com/manveru/faker"
+	regen "github.com/zach-klippenstein/goregen"
 )
 
 // RandomGenerator generates consistent random values of different types given a seed.
@@ -18,6 +19,7 @@ type RandomGenerator struct {
 	Seed  string
 	faker *faker.Faker
 	rand  *rand.Rand
+	api   *APIDefinition
 }
 
 // NewRandomGenerator returns a random value generator seeded from the given string value.
@@ -50,6 +52,23 @@ func (r *RandomGenerator) String() string {
 
 }
 
+// Faker returns the generator used to produce realistic values (names, emails, addresses etc.).
+// It shares the random source of r so that values it produces are consistent for a given seed.
+func (r *RandomGenerator) Faker() *faker.Faker {
+	return r.faker
+}
+
+// Regexp produces a random string that matches the given regular expression.
+func (r *RandomGenerator) Regexp(pattern string) (string, error) {
+	g, err := regen.NewGenerator(pattern, &regen.GeneratorArgs{
+		RngSource: rand.NewSource(r.rand.Int63()),
+	})
+	if err != nil {
+		return "", err
+	}
+	return g.Generate(), nil
+}
+
 // DateTime produces a random date.
 func (r *RandomGenerator) DateTime() time.Time {
 	// Use a constant max value to make sure the same pseudo random
@@ -59,9 +78,13 @@ func (r *RandomGenerator) DateTime() time.Time {
 	return time.Unix(unix, 0).UTC()
 }
 
-// UUID produces a random UUID.
+// UUID produces a random (version 4) UUID.
 func (r *RandomGenerator) UUID() uuid.UUID {
-	return uuid.Must(uuid.NewV4())
+	var u uuid.UUID
+	r.rand.Read(u[:])
+	u.SetVersion(uuid.V4)
+	u.SetVariant(uuid.VariantRFC4122)
+	return u
 }
 
 // Bool produces a random boolean.
@@ -74,6 +97,11 @@ func (r *RandomGenerator) Float64() float64 {
 	return r.rand.Float64()
 }
 
+// Decimal produces a random decimal number value with two digits after the decimal point.
+func (r *RandomGenerator) Decimal() string {
+	return fmt.Sprintf("%d.%02d", r.rand.Intn(10000), r.rand.Intn(100))
+}
+
 // File produces a random file.
 func (r *RandomGenerator) File() string {
 	return fmt.Sprintf("%sjpg", r.faker.Sentence(1, false))
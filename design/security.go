@@ -117,17 +117,17 @@ func (s *SecuritySchemeDefinition) Finalize() {
 		return
 	}
 	var scheme string
-	if len(Design.Schemes) > 0 {
-		scheme = Design.Schemes[0]
+	if len(CurrentDesign().Schemes) > 0 {
+		scheme = CurrentDesign().Schemes[0]
 	}
 	if !tokenOK {
 		tu.Scheme = scheme
-		tu.Host = Design.Host
+		tu.Host = CurrentDesign().Host
 		s.TokenURL = tu.String()
 	}
 	if !authOK {
 		au.Scheme = scheme
-		au.Host = Design.Host
+		au.Host = CurrentDesign().Host
 		s.AuthorizationURL = au.String()
 	}
 }
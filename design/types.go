@@ -19,6 +19,7 @@ import (
 
 	"github.com/gofrs/uuid"
 	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/validate"
 )
 
 // DefaultView is the name of the default view.
@@ -129,12 +130,17 @@ type (
 		// ContentType identifies the value written to the response "Content-Type" header.
 		// Defaults to Identifier.
 		ContentType string
-		// Links list the rendered links indexed by name.
+		// Links list the rendered links indexed by name. Use IterateLinks to iterate over the
+		// links in a deterministic order.
 		Links map[string]*LinkDefinition
-		// Views list the supported views indexed by name.
+		// Views list the supported views indexed by name. Use IterateViews to iterate over the
+		// views in a deterministic order.
 		Views map[string]*ViewDefinition
 		// Resource this media type is the canonical representation for if any
 		Resource *ResourceDefinition
+
+		// api is the API evaluated by Eval the media type belongs to, see CurrentDesign.
+		api *APIDefinition
 	}
 )
 
@@ -165,6 +171,8 @@ const (
 	MediaTypeKind
 	// FileKind represents a file.
 	FileKind
+	// DecimalKind represents a JSON string that holds an arbitrary precision decimal number.
+	DecimalKind
 )
 
 const (
@@ -193,6 +201,11 @@ const (
 
 	// File is the type for a file. This type can only be used in a multipart definition.
 	File = Primitive(FileKind)
+
+	// Decimal is the type for an arbitrary precision decimal number serialized as a JSON
+	// string (e.g. "12.345"). The generated Go type is string unless overridden with the
+	// "struct:field:type" metadata (e.g. to use github.com/shopspring/decimal).
+	Decimal = Primitive(DecimalKind)
 )
 
 // DataType implementation
@@ -209,13 +222,16 @@ func (p Primitive) Name() string {
 		return "integer"
 	case Number:
 		return "number"
-	case String, DateTime, UUID:
+	case String, DateTime, UUID, Decimal:
 		return "string"
 	case Any:
 		return "any"
 	case File:
 		return "file"
 	default:
+		if c := CustomPrimitive(p); c != nil {
+			return c.JSONType
+		}
 		panic("unknown primitive type") // bug
 	}
 }
@@ -247,15 +263,20 @@ func (p Primitive) ToHash() *Hash { return nil }
 // CanHaveDefault returns whether the primitive can have a default value.
 func (p Primitive) CanHaveDefault() (ok bool) {
 	switch p {
-	case Boolean, Integer, Number, String, DateTime:
+	case Boolean, Integer, Number, String, DateTime, Decimal:
 		ok = true
+	default:
+		ok = CustomPrimitive(p) != nil
 	}
 	return
 }
 
 // IsCompatible returns true if val is compatible with p.
 func (p Primitive) IsCompatible(val interface{}) bool {
-	if p != Boolean && p != Integer && p != Number && p != String && p != DateTime && p != UUID && p != Any {
+	if c := CustomPrimitive(p); c != nil {
+		return c.jsonPrimitive().IsCompatible(val)
+	}
+	if p != Boolean && p != Integer && p != Number && p != String && p != DateTime && p != UUID && p != Decimal && p != Any {
 		panic("unknown primitive type") // bug
 	}
 	if p == Any {
@@ -280,6 +301,9 @@ func (p Primitive) IsCompatible(val interface{}) bool {
 			_, err := uuid.FromString(val.(string))
 			return err == nil
 		}
+		if p == Decimal {
+			return validate.Decimal(val.(string), -1, -1) == nil
+		}
 	}
 	return false
 }
@@ -301,12 +325,17 @@ func (p Primitive) GenerateExample(r *RandomGenerator, seen []string) interface{
 		return r.DateTime()
 	case UUID:
 		return r.UUID().String() // Generate string to can be JSON marshaled
+	case Decimal:
+		return r.Decimal()
 	case Any:
 		// to not make it too complicated, pick one of the primitive types
 		return anyPrimitive[r.Int()%len(anyPrimitive)].GenerateExample(r, seen)
 	case File:
 		return r.File()
 	default:
+		if c := CustomPrimitive(p); c != nil {
+			return c.jsonPrimitive().GenerateExample(r, seen)
+		}
 		panic("unknown primitive type") // bug
 	}
 }
@@ -721,6 +750,13 @@ func (u *UserTypeDefinition) IsCompatible(val interface{}) bool {
 	return u.Type == nil || u.Type.IsCompatible(val)
 }
 
+// GenerateExample returns the value of the Example field if not nil. Otherwise it traverses the
+// user type attributes and recursively generates an example using the type name as root of the
+// attribute paths given to the registered example generators.
+func (u *UserTypeDefinition) GenerateExample(rand *RandomGenerator, seen []string) interface{} {
+	return u.generateExample(rand, seen, u.TypeName)
+}
+
 // Finalize merges base type attributes.
 func (u *UserTypeDefinition) Finalize() {
 	if u.Reference != nil {
@@ -729,7 +765,7 @@ func (u *UserTypeDefinition) Finalize() {
 		}
 	}
 
-	u.GenerateExample(Design.RandomGenerator(), nil)
+	u.GenerateExample(CurrentDesign().RandomGenerator(), nil)
 }
 
 // NewMediaTypeDefinition creates a media type definition but does not
@@ -804,15 +840,37 @@ func (m *MediaTypeDefinition) IterateViews(it ViewIterator) error {
 	return nil
 }
 
+// LinkIterator is the type of the function given to IterateLinks.
+type LinkIterator func(*LinkDefinition) error
+
+// IterateLinks calls the given iterator passing in each link sorted in alphabetical order.
+// Iteration stops if an iterator returns an error and in this case IterateLinks returns that
+// error.
+func (m *MediaTypeDefinition) IterateLinks(it LinkIterator) error {
+	names := make([]string, len(m.Links))
+	i := 0
+	for n := range m.Links {
+		names[i] = n
+		i++
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if err := it(m.Links[n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Project creates a MediaTypeDefinition containing the fields defined in the given view.  The
 // resuling media type only defines the default view and its identifier is modified to indicate that
 // it was projected by adding the view as id parameter.  links is a user type of type Object where
 // each key corresponds to a linked media type as defined by the media type "links" attribute.
 func (m *MediaTypeDefinition) Project(view string) (*MediaTypeDefinition, *UserTypeDefinition, error) {
 	canonical := m.projectCanonical(view)
-	if p, ok := ProjectedMediaTypes[canonical]; ok {
+	if p, ok := m.projections()[canonical]; ok {
 		var links *UserTypeDefinition
-		mLinks := ProjectedMediaTypes[canonical+"; links"]
+		mLinks := m.projections()[canonical+"; links"]
 		if mLinks != nil {
 			links = mLinks.UserTypeDefinition
 		}
@@ -854,6 +912,7 @@ func (m *MediaTypeDefinition) projectSingle(view, canonical string) (p *MediaTyp
 
 	p = &MediaTypeDefinition{
 		Identifier: m.projectIdentifier(view),
+		api:        m.api,
 		UserTypeDefinition: &UserTypeDefinition{
 			TypeName: m.projectTypeName(view),
 			AttributeDefinition: &AttributeDefinition{
@@ -863,13 +922,33 @@ func (m *MediaTypeDefinition) projectSingle(view, canonical string) (p *MediaTyp
 			},
 		},
 	}
+	if tags, ok := m.Metadata["struct:tags"]; ok {
+		// The projected media type struct carries the additional struct tags of the media type
+		p.Metadata = dslengine.MetadataDefinition{"struct:tags": tags}
+	}
+	if v, ok := m.Metadata["proto:message"]; ok {
+		// The projected media type struct converts into protocol buffer messages
+		if p.Metadata == nil {
+			p.Metadata = make(dslengine.MetadataDefinition)
+		}
+		p.Metadata["proto:message"] = v
+	}
+	for k, v := range m.Metadata {
+		if strings.HasPrefix(k, "swagger:extension:") {
+			// The projected media type schema carries the swagger extensions of the media type
+			if p.Metadata == nil {
+				p.Metadata = make(dslengine.MetadataDefinition)
+			}
+			p.Metadata[k] = v
+		}
+	}
 	p.Views = map[string]*ViewDefinition{"default": {
 		Name:                "default",
 		AttributeDefinition: DupAtt(v.AttributeDefinition),
 		Parent:              p,
 	}}
 
-	ProjectedMediaTypes[canonical] = p
+	m.projections()[canonical] = p
 	projectedObj := p.Type.ToObject()
 	mtObj := m.Type.ToObject()
 	_, hasAttNamedLinks := mtObj["links"]
@@ -901,7 +980,7 @@ func (m *MediaTypeDefinition) projectSingle(view, canonical string) (p *MediaTyp
 				TypeName: lTypeName,
 			}
 			projectedObj[n] = &AttributeDefinition{Type: links, Description: "Links to related resources"}
-			ProjectedMediaTypes[canonical+"; links"] = &MediaTypeDefinition{UserTypeDefinition: links}
+			m.projections()[canonical+"; links"] = &MediaTypeDefinition{UserTypeDefinition: links}
 		} else {
 			if at := mtObj[n]; at != nil {
 				at = DupAtt(at)
@@ -942,6 +1021,7 @@ func (m *MediaTypeDefinition) projectCollection(view string) (*MediaTypeDefiniti
 	desc := m.TypeName + " is the media type for an array of " + e.TypeName + " (" + view + " view)"
 	p := &MediaTypeDefinition{
 		Identifier: m.projectIdentifier(view),
+		api:        m.api,
 		UserTypeDefinition: &UserTypeDefinition{
 			AttributeDefinition: &AttributeDefinition{
 				Description: desc,
@@ -959,7 +1039,7 @@ func (m *MediaTypeDefinition) projectCollection(view string) (*MediaTypeDefiniti
 
 	// Run the DSL that was created by the CollectionOf function
 	if !dslengine.Execute(p.DSL(), p) {
-		return nil, nil, dslengine.Errors
+		return nil, nil, dslengine.CurrentErrors()
 	}
 
 	// Build the links user type
@@ -1080,7 +1160,7 @@ func toReflectType(dtype DataType) reflect.Type {
 		return reflect.TypeOf(int(0))
 	case NumberKind:
 		return reflect.TypeOf(float64(0))
-	case UUIDKind, StringKind:
+	case UUIDKind, StringKind, DecimalKind:
 		return reflect.TypeOf("")
 	case DateTimeKind:
 		return reflect.TypeOf(time.Time{})
@@ -1099,6 +1179,9 @@ func toReflectType(dtype DataType) reflect.Type {
 		}
 		return reflect.MapOf(ktype, toReflectType(hash.ElemType.Type))
 	default:
+		if c := CustomPrimitive(dtype); c != nil {
+			return toReflectType(c.jsonPrimitive())
+		}
 		return reflect.TypeOf([]interface{}{}).Elem()
 	}
 }
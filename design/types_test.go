@@ -449,6 +449,33 @@ var _ = Describe("MediaTypeDefinition", func() {
 			})
 		})
 	})
+
+	Describe("IterateLinks", func() {
+		var (
+			m  *MediaTypeDefinition
+			it LinkIterator
+
+			iteratedLinks []string
+		)
+		BeforeEach(func() {
+			m = &MediaTypeDefinition{
+				Links: map[string]*LinkDefinition{
+					"c": {Name: "c"},
+					"a": {Name: "a"},
+					"b": {Name: "b"},
+				},
+			}
+			iteratedLinks = []string{}
+			it = func(l *LinkDefinition) error {
+				iteratedLinks = append(iteratedLinks, l.Name)
+				return nil
+			}
+		})
+		It("sorts links", func() {
+			Expect(m.IterateLinks(it)).To(Succeed())
+			Expect(iteratedLinks).To(Equal([]string{"a", "b", "c"}))
+		})
+	})
 })
 
 var _ = Describe("Walk", func() {
@@ -590,6 +617,77 @@ var _ = Describe("GenerateExample", func() {
 		})
 	})
 
+	Context("Given a UUID and a seed", func() {
+		It("generates the same example for the same seed", func() {
+			ex := UUID.GenerateExample(NewRandomGenerator("foo"), nil)
+			Ω(UUID.GenerateExample(NewRandomGenerator("foo"), nil)).Should(Equal(ex))
+			Ω(UUID.GenerateExample(NewRandomGenerator("bar"), nil)).ShouldNot(Equal(ex))
+		})
+	})
+
+	Context("Given a decimal", func() {
+		It("generates a decimal string example", func() {
+			rand := NewRandomGenerator("foo")
+			ex := Decimal.GenerateExample(rand, nil)
+			Ω(ex).Should(BeAssignableToTypeOf("foo"))
+			Ω(Decimal.IsCompatible(ex)).Should(BeTrue())
+		})
+	})
+
+	Context("Given a decimal attribute with precision and scale", func() {
+		It("generates examples that validate", func() {
+			precision, scale := 4, 3
+			att := &AttributeDefinition{
+				Type:       Decimal,
+				Validation: &dslengine.ValidationDefinition{Precision: &precision, Scale: &scale},
+			}
+			rand := NewRandomGenerator("foo")
+			for i := 0; i < 10; i++ {
+				Ω(att.GenerateExample(rand, nil)).Should(MatchRegexp(`^[0-9]\.[0-9]{3}$`))
+			}
+		})
+	})
+
+	Context("Given a user type with well known attribute names", func() {
+		var example interface{}
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			apidsl.Type("Account", func() {
+				apidsl.Attribute("email", String)
+				apidsl.Attribute("first_name", String)
+				apidsl.Attribute("nickname", String)
+				apidsl.Attribute("code", String, func() {
+					apidsl.Format("ipv4")
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			example = Design.Types["Account"].GenerateExample(Design.RandomGenerator(), nil)
+		})
+
+		BeforeEach(func() {
+			RegisterAttributeExample("Account.nickname", func(_ *RandomGenerator, path string, _ *AttributeDefinition) interface{} {
+				return "custom " + path
+			})
+		})
+
+		It("generates realistic examples", func() {
+			Ω(example).Should(HaveKey("email"))
+			Ω(example.(map[string]interface{})["email"]).Should(ContainSubstring("@"))
+			Ω(example).Should(HaveKey("first_name"))
+			Ω(example.(map[string]interface{})["first_name"]).ShouldNot(ContainSubstring(" "))
+		})
+
+		It("uses the registered generators", func() {
+			Ω(example).Should(HaveKeyWithValue("nickname", "custom Account.nickname"))
+		})
+
+		It("gives precedence to format validations over attribute names", func() {
+			Ω(example).Should(HaveKey("code"))
+			Ω(example.(map[string]interface{})["code"]).Should(MatchRegexp(`^(?:[0-9]{1,3}\.){3}[0-9]{1,3}$`))
+		})
+	})
+
 	Context("Given a Hash keyed by UUIDs", func() {
 		var h *Hash
 		BeforeEach(func() {
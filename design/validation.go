@@ -7,11 +7,14 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/validate"
 )
 
 type routeInfo struct {
@@ -37,7 +40,7 @@ func newRouteInfo(resource *ResourceDefinition, action *ActionDefinition, route
 		} else if strings.Contains(resource.BasePath, v) {
 			orig = resource
 		} else {
-			orig = Design
+			orig = CurrentDesign()
 		}
 		wi[i] = &wildCardInfo{Name: v, Orig: orig}
 	}
@@ -71,7 +74,7 @@ func (a *APIDefinition) Validate() error {
 	// attribute defined on a non generated media type uses a generated mediatype (i.e.
 	// CollectionOf(Foo)) with a specific view that hasn't been set yet.
 	// TBD: Maybe GeneratedMediaTypes should not be a separate DSL root.
-	for _, mt := range GeneratedMediaTypes {
+	for _, mt := range CurrentGeneratedMediaTypes() {
 		dslengine.Execute(mt.DSLFunc, mt)
 		mt.DSLFunc = nil // So that it doesn't run again when the generated media types DSL root is executed
 	}
@@ -248,7 +251,7 @@ func (r *ResourceDefinition) validateActions(verr *dslengine.ValidationErrors) {
 }
 
 func (r *ResourceDefinition) validateParent(verr *dslengine.ValidationErrors) {
-	p, ok := Design.Resources[r.ParentName]
+	p, ok := r.design().Resources[r.ParentName]
 	if !ok {
 		verr.Add(r, "Parent resource named %#v not found", r.ParentName)
 	} else {
@@ -310,6 +313,8 @@ func (enc *EncodingDefinition) Validate() *dslengine.ValidationErrors {
 				sort.Strings(knownMIMETypes)
 				verr.Add(enc, "Encoders not known for all MIME types, use Package to specify encoder Go package. MIME types with known encoders are %s",
 					strings.Join(knownMIMETypes, ", "))
+			} else if enc.Encoder && KnownEncoderFunctions[m][0] == "" {
+				verr.Add(enc, "No known encoder for MIME type %#v, it can only be used with Consumes", m)
 			}
 		}
 	}
@@ -481,6 +486,9 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 			verr.Add(parent, "%sdefault value %#v is not one of the accepted values: %#v", ctx, a.DefaultValue, a.Validation.Values)
 		}
 	}
+	if a.Type.IsPrimitive() && a.Example != nil && a.Validation != nil {
+		a.validateExample(ctx, parent, verr)
+	}
 	o := a.Type.ToObject()
 	if o != nil {
 		for _, n := range a.AllRequired() {
@@ -509,6 +517,77 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 	return verr.AsError()
 }
 
+// validateExample makes sure the user provided example of a primitive attribute satisfies the
+// attribute validations (enum, format, pattern, length and min/max values).
+func (a *AttributeDefinition) validateExample(ctx string, parent dslengine.Definition, verr *dslengine.ValidationErrors) {
+	if a.Example == "-" {
+		// NoExample
+		return
+	}
+	ex, val := a.Example, a.Validation
+	if len(val.Values) > 0 {
+		var found bool
+		for _, e := range val.Values {
+			if e == ex {
+				found = true
+				break
+			}
+		}
+		if !found {
+			verr.Add(parent, "%sexample value %#v is not one of the accepted values: %#v", ctx, ex, val.Values)
+		}
+	}
+	if s, ok := ex.(string); ok {
+		if val.Format != "" {
+			if err := validate.Format(val.Format, s); err != nil {
+				verr.Add(parent, "%sexample value %#v does not match format %#v: %s", ctx, ex, val.Format, err)
+			}
+		}
+		if val.Pattern != "" {
+			if re, err := regexp.Compile(val.Pattern); err == nil && !re.MatchString(s) {
+				verr.Add(parent, "%sexample value %#v does not match pattern %#v", ctx, ex, val.Pattern)
+			}
+		}
+		l := utf8.RuneCountInString(s)
+		if val.MinLength != nil && l < *val.MinLength {
+			verr.Add(parent, "%sexample value %#v is shorter than the minimum length %d", ctx, ex, *val.MinLength)
+		}
+		if val.MaxLength != nil && l > *val.MaxLength {
+			verr.Add(parent, "%sexample value %#v is longer than the maximum length %d", ctx, ex, *val.MaxLength)
+		}
+		if val.Precision != nil || val.Scale != nil {
+			precision, scale := -1, -1
+			if val.Precision != nil {
+				precision = *val.Precision
+			}
+			if val.Scale != nil {
+				scale = *val.Scale
+			}
+			if err := validate.Decimal(s, precision, scale); err != nil {
+				verr.Add(parent, "%sexample value %#v is not a valid decimal: %s", ctx, ex, err)
+			}
+		}
+	}
+	if f, ok := toFloat64(ex); ok {
+		if val.Minimum != nil && f < *val.Minimum {
+			verr.Add(parent, "%sexample value %#v is lower than the minimum %v", ctx, ex, *val.Minimum)
+		}
+		if val.Maximum != nil && f > *val.Maximum {
+			verr.Add(parent, "%sexample value %#v is greater than the maximum %v", ctx, ex, *val.Maximum)
+		}
+	}
+}
+
+// toFloat64 converts numeric values to float64, the second return value is false if val is not
+// a number.
+func toFloat64(val interface{}) (float64, bool) {
+	switch val.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return reflect.ValueOf(val).Convert(reflect.TypeOf(float64(0))).Float(), true
+	}
+	return 0, false
+}
+
 // Validate checks that the response definition is consistent: its status is set and the media
 // type definition if any is valid.
 func (r *ResponseDefinition) Validate() *dslengine.ValidationErrors {
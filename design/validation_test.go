@@ -84,6 +84,87 @@ var _ = Describe("Validation", func() {
 			})
 		})
 
+		Context("with an example that doesn't exist in enum", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					apidsl.Attribute(attName, Integer, func() {
+						apidsl.Enum(1, 2, 3)
+						apidsl.Example(4)
+					})
+				}
+			})
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`type "bar": field attName - example value 4 is not one of the accepted values: []interface {}{1, 2, 3}`))
+			})
+		})
+
+		Context("with an example that doesn't match the format", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					apidsl.Attribute(attName, String, func() {
+						apidsl.Format("email")
+						apidsl.Example("not an email")
+					})
+				}
+			})
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(
+					`field attName - example value "not an email" does not match format "email"`))
+			})
+		})
+
+		Context("with an example that doesn't match the pattern", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					apidsl.Attribute(attName, String, func() {
+						apidsl.Pattern("^foo")
+						apidsl.Example("bar")
+					})
+				}
+			})
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`type "bar": field attName - example value "bar" does not match pattern "^foo"`))
+			})
+		})
+
+		Context("with an example out of the min/max range", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					apidsl.Attribute(attName, Number, func() {
+						apidsl.Minimum(1)
+						apidsl.Maximum(10)
+						apidsl.Example(10.5)
+					})
+				}
+			})
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`type "bar": field attName - example value 10.5 is greater than the maximum 10`))
+			})
+		})
+
+		Context("with an example that satisfies the validations", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					apidsl.Attribute(attName, String, func() {
+						apidsl.Format("email")
+						apidsl.Pattern("@goa.design$")
+						apidsl.MaxLength(20)
+						apidsl.Example("raphael@goa.design")
+					})
+				}
+			})
+			It("does not produce an error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+
 		Context("with a valid format validation", func() {
 			BeforeEach(func() {
 				dsl = func() {
@@ -483,5 +564,21 @@ var _ = Describe("Validation", func() {
 				Ω(enc.Validate().Errors).Should(BeNil())
 			})
 		})
+
+		Context("with a known MIME type that can only be decoded", func() {
+			BeforeEach(func() {
+				enc = &EncodingDefinition{MIMETypes: []string{"multipart/form-data"}}
+			})
+
+			It("validates decoders", func() {
+				Ω(enc.Validate().Errors).Should(BeNil())
+			})
+
+			It("returns a validation error for encoders", func() {
+				enc.Encoder = true
+				Ω(len(enc.Validate().Errors)).Should(Equal(1))
+				Ω(enc.Validate().Errors[0].Error()).Should(ContainSubstring("it can only be used with Consumes"))
+			})
+		})
 	})
 })
@@ -0,0 +1,106 @@
+package design
+
+import "github.com/kyokomi/goa-v1/dslengine"
+
+// WalkFunc is the type of the function called by Walk for each visited definition. Returning an
+// error stops the traversal.
+type WalkFunc func(def dslengine.Definition) error
+
+// Walk traverses the design graph rooted at Design, see APIDefinition.Walk.
+func Walk(walker WalkFunc) error {
+	if Design == nil {
+		return nil
+	}
+	return Design.Walk(walker)
+}
+
+// Walk calls walker for each definition of the API in a stable order. Parent definitions are
+// always visited before their children so that the parent context of a definition (as given by
+// its Parent field) has been visited by the time walker is called with the definition.
+//
+// The traversal visits:
+//
+//   - the API definition itself, then its responses sorted by name,
+//   - the security schemes in order of declaration,
+//   - the user types sorted by name,
+//   - the media types sorted by identifier each followed by its views then its links sorted
+//     by name,
+//   - the resources, parent resources first then by name, each followed by its responses
+//     sorted by name, its file servers sorted by file path and its actions sorted by name.
+//     Each action is followed by its routes in order of declaration then its responses
+//     sorted by name.
+//
+// Walk stops and returns the error returned by walker if any.
+func (a *APIDefinition) Walk(walker WalkFunc) error {
+	if err := walker(a); err != nil {
+		return err
+	}
+	if err := a.IterateResponses(func(resp *ResponseDefinition) error {
+		return walker(resp)
+	}); err != nil {
+		return err
+	}
+	for _, s := range a.SecuritySchemes {
+		if err := walker(s); err != nil {
+			return err
+		}
+	}
+	if err := a.IterateUserTypes(func(ut *UserTypeDefinition) error {
+		return walker(ut)
+	}); err != nil {
+		return err
+	}
+	if err := a.IterateMediaTypes(func(mt *MediaTypeDefinition) error {
+		return walkMediaType(mt, walker)
+	}); err != nil {
+		return err
+	}
+	return a.IterateResources(func(r *ResourceDefinition) error {
+		return walkResource(r, walker)
+	})
+}
+
+// walkMediaType visits the media type, its views and its links.
+func walkMediaType(mt *MediaTypeDefinition, walker WalkFunc) error {
+	if err := walker(mt); err != nil {
+		return err
+	}
+	if err := mt.IterateViews(func(v *ViewDefinition) error {
+		return walker(v)
+	}); err != nil {
+		return err
+	}
+	return mt.IterateLinks(func(l *LinkDefinition) error {
+		return walker(l)
+	})
+}
+
+// walkResource visits the resource and all its children definitions.
+func walkResource(r *ResourceDefinition, walker WalkFunc) error {
+	if err := walker(r); err != nil {
+		return err
+	}
+	if err := r.IterateResponses(func(resp *ResponseDefinition) error {
+		return walker(resp)
+	}); err != nil {
+		return err
+	}
+	if err := r.IterateFileServers(func(f *FileServerDefinition) error {
+		return walker(f)
+	}); err != nil {
+		return err
+	}
+	return r.IterateActions(func(a *ActionDefinition) error {
+		if err := walker(a); err != nil {
+			return err
+		}
+		for _, route := range a.Routes {
+			if err := walker(route); err != nil {
+				return err
+			}
+		}
+		return a.IterateResponses(func(resp *ResponseDefinition) error {
+			return walker(resp)
+		})
+	})
+}
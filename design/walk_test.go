@@ -0,0 +1,84 @@
+package design_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/dslengine"
+)
+
+var _ = Describe("APIDefinition Walk", func() {
+	var api *APIDefinition
+	var walker WalkFunc
+	var visited []string
+	var err error
+
+	BeforeEach(func() {
+		visited = nil
+		walker = func(def dslengine.Definition) error {
+			visited = append(visited, def.Context())
+			return nil
+		}
+		mt := &MediaTypeDefinition{
+			UserTypeDefinition: &UserTypeDefinition{
+				AttributeDefinition: &AttributeDefinition{Type: Object{}},
+				TypeName:            "Bottle",
+			},
+			Identifier: "application/vnd.bottle",
+		}
+		mt.Views = map[string]*ViewDefinition{"default": {Name: "default", Parent: mt, AttributeDefinition: &AttributeDefinition{}}}
+		mt.Links = map[string]*LinkDefinition{"account": {Name: "account", Parent: mt}}
+		res := &ResourceDefinition{Name: "bottles"}
+		act := &ActionDefinition{Name: "show", Parent: res}
+		act.Routes = []*RouteDefinition{{Verb: "GET", Path: "/:id", Parent: act}}
+		act.Responses = map[string]*ResponseDefinition{"OK": {Name: "OK", Parent: act}}
+		res.Actions = map[string]*ActionDefinition{"show": act}
+		res.FileServers = []*FileServerDefinition{{Parent: res, FilePath: "index.html", RequestPath: "/"}}
+		api = &APIDefinition{
+			Name:       "test",
+			Resources:  map[string]*ResourceDefinition{"bottles": res},
+			MediaTypes: map[string]*MediaTypeDefinition{mt.Identifier: mt},
+			Types:      map[string]*UserTypeDefinition{"Payload": {TypeName: "Payload", AttributeDefinition: &AttributeDefinition{Type: Object{}}}},
+		}
+	})
+
+	JustBeforeEach(func() {
+		err = api.Walk(walker)
+	})
+
+	It("visits all the definitions in order", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(visited).Should(Equal([]string{
+			`API "test"`,
+			`type "Payload"`,
+			`type "Bottle"`,
+			`view "default" of type "Bottle"`,
+			`link "account" of type "Bottle"`,
+			`resource "bottles"`,
+			`resource "bottles" file server index.html`,
+			`resource "bottles" action "show"`,
+			`route GET "/:id" of resource "bottles" action "show"`,
+			`response "OK" of resource "bottles" action "show"`,
+		}))
+	})
+
+	Context("with a walker returning an error", func() {
+		BeforeEach(func() {
+			walker = func(def dslengine.Definition) error {
+				visited = append(visited, def.Context())
+				if _, ok := def.(*MediaTypeDefinition); ok {
+					return errors.New("stop")
+				}
+				return nil
+			}
+		})
+
+		It("stops the traversal", func() {
+			Ω(err).Should(MatchError("stop"))
+			Ω(visited).Should(HaveLen(3))
+		})
+	})
+})
@@ -100,6 +100,11 @@ type (
 		// MaxLength represents an maximum length validation as described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor26.
 		MaxLength *int
+		// Precision represents the maximum number of significant digits of a decimal value.
+		Precision *int
+		// Scale represents the maximum number of digits after the decimal point of a decimal
+		// value.
+		Scale *int
 		// Required list the required fields of object attributes as described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor61.
 		Required []string
@@ -147,6 +152,12 @@ func (v *ValidationDefinition) Merge(other *ValidationDefinition) {
 	if v.MaxLength == nil || (other.MaxLength != nil && *v.MaxLength < *other.MaxLength) {
 		v.MaxLength = other.MaxLength
 	}
+	if v.Precision == nil || (other.Precision != nil && *v.Precision < *other.Precision) {
+		v.Precision = other.Precision
+	}
+	if v.Scale == nil || (other.Scale != nil && *v.Scale < *other.Scale) {
+		v.Scale = other.Scale
+	}
 	v.AddRequired(other.Required)
 }
 
@@ -177,9 +188,47 @@ func (v *ValidationDefinition) HasRequiredOnly() bool {
 	if (v.Minimum != nil) || (v.Maximum != nil) || (v.MaxLength != nil) {
 		return false
 	}
+	if (v.Precision != nil) || (v.Scale != nil) {
+		return false
+	}
 	return true
 }
 
+// DeepCopy makes a copy of the validation that shares no state with v.
+func (v *ValidationDefinition) DeepCopy() *ValidationDefinition {
+	intPtr := func(i *int) *int {
+		if i == nil {
+			return nil
+		}
+		j := *i
+		return &j
+	}
+	floatPtr := func(f *float64) *float64 {
+		if f == nil {
+			return nil
+		}
+		g := *f
+		return &g
+	}
+	dup := &ValidationDefinition{
+		Format:    v.Format,
+		Pattern:   v.Pattern,
+		Minimum:   floatPtr(v.Minimum),
+		Maximum:   floatPtr(v.Maximum),
+		MinLength: intPtr(v.MinLength),
+		MaxLength: intPtr(v.MaxLength),
+		Precision: intPtr(v.Precision),
+		Scale:     intPtr(v.Scale),
+	}
+	if v.Values != nil {
+		dup.Values = append([]interface{}{}, v.Values...)
+	}
+	if v.Required != nil {
+		dup.Required = append([]string{}, v.Required...)
+	}
+	return dup
+}
+
 // Dup makes a shallow dup of the validation.
 func (v *ValidationDefinition) Dup() *ValidationDefinition {
 	return &ValidationDefinition{
@@ -190,6 +239,8 @@ func (v *ValidationDefinition) Dup() *ValidationDefinition {
 		Maximum:   v.Maximum,
 		MinLength: v.MinLength,
 		MaxLength: v.MaxLength,
+		Precision: v.Precision,
+		Scale:     v.Scale,
 		Required:  v.Required,
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 )
 
 var (
@@ -19,6 +20,13 @@ var (
 	// Registered DSL roots
 	roots []Root
 
+	// State of the RunRoots call in progress if any, the DSL functions have no receiver and
+	// retrieve the roots they build through it.
+	current *evaluation
+
+	// runMu serializes the calls to RunRoots.
+	runMu sync.Mutex
+
 	// DSL package paths used to compute error locations (skip the frames in these packages)
 	dslPackages map[string]bool
 )
@@ -38,6 +46,13 @@ type (
 
 	// DSL evaluation contexts stack
 	contextStack []Definition
+
+	// evaluation holds the roots evaluated by RunRoots and the errors reported while evaluating
+	// them.
+	evaluation struct {
+		roots  []Root
+		errors MultiError
+	}
 )
 
 func init() {
@@ -79,14 +94,20 @@ func Reset() {
 // definitions and finally finalize them. The executed DSL may register new
 // roots to have them be executed (last) in the same run.
 func Run() error {
-	if len(roots) == 0 {
+	return run(roots)
+}
+
+// run runs the given root definitions, see Run.
+func run(rs []Root) error {
+	if len(rs) == 0 {
 		return nil
 	}
-	roots, err := SortRoots()
+	roots, err := sortRoots(rs)
 	if err != nil {
 		return err
 	}
-	Errors = nil
+	errs := currentErrors()
+	*errs = nil
 	executed := 0
 	recursed := 0
 	for executed < len(roots) {
@@ -101,14 +122,14 @@ func Run() error {
 			return fmt.Errorf("too many generated roots, infinite loop?")
 		}
 	}
-	if Errors != nil {
-		return Errors
+	if *errs != nil {
+		return *errs
 	}
 	for _, root := range roots {
 		root.IterateSets(validateSet)
 	}
-	if Errors != nil {
-		return Errors
+	if *errs != nil {
+		return *errs
 	}
 	for _, root := range roots {
 		root.IterateSets(finalizeSet)
@@ -117,6 +138,47 @@ func Run() error {
 	return nil
 }
 
+// RunRoots executes dsl and runs the given root definitions like Run does but ignoring the
+// registered roots. dsl declares the definitions of the roots, the DSL functions retrieve the roots
+// being evaluated with CurrentRoots. The errors reported while RunRoots executes are returned and
+// are not added to Errors so that multiple independent designs may be evaluated in the same
+// process. Concurrent calls to RunRoots are serialized.
+func RunRoots(dsl func(), rs ...Root) error {
+	runMu.Lock()
+	defer runMu.Unlock()
+	current = &evaluation{roots: rs}
+	defer func() { current = nil }()
+	if dsl != nil {
+		dsl()
+		if current.errors != nil {
+			return current.errors
+		}
+	}
+	return run(rs)
+}
+
+// CurrentRoots returns the roots being evaluated by RunRoots, nil if RunRoots is not running.
+func CurrentRoots() []Root {
+	if current == nil {
+		return nil
+	}
+	return current.roots
+}
+
+// CurrentErrors returns the errors reported by the DSL being executed, that is the errors of the
+// RunRoots call in progress if any, Errors otherwise.
+func CurrentErrors() MultiError {
+	return *currentErrors()
+}
+
+// currentErrors returns a pointer to the errors the DSL execution errors are appended to.
+func currentErrors() *MultiError {
+	if current != nil {
+		return &current.errors
+	}
+	return &Errors
+}
+
 // Execute runs the given DSL to initialize the given definition. It returns true on success.
 // It returns false and appends to Errors on failure.
 // Note that `Run` takes care of calling `Execute` on all definitions that implement Source.
@@ -127,11 +189,12 @@ func Execute(dsl func(), def Definition) bool {
 	if dsl == nil {
 		return true
 	}
-	initCount := len(Errors)
+	errs := currentErrors()
+	initCount := len(*errs)
 	ctxStack = append(ctxStack, def)
 	dsl()
 	ctxStack = ctxStack[:len(ctxStack)-1]
-	return len(Errors) <= initCount
+	return len(*errs) <= initCount
 }
 
 // CurrentDefinition returns the definition whose initialization DSL is currently being executed.
@@ -171,7 +234,8 @@ func ReportError(fm string, vals ...interface{}) {
 	}
 	err := fmt.Errorf(fm+suffix, vals...)
 	file, line := computeErrorLocation()
-	Errors = append(Errors, &Error{
+	errs := currentErrors()
+	*errs = append(*errs, &Error{
 		GoError: err,
 		File:    file,
 		Line:    line,
@@ -318,7 +382,8 @@ func validateSet(set DefinitionSet) error {
 	}
 	err := errors.AsError()
 	if err != nil {
-		Errors = append(Errors, &Error{GoError: err})
+		errs := currentErrors()
+		*errs = append(*errs, &Error{GoError: err})
 	}
 	return err
 }
@@ -336,6 +401,11 @@ func finalizeSet(set DefinitionSet) error {
 // SortRoots orders the DSL roots making sure dependencies are last. It returns an error if there
 // is a dependency cycle.
 func SortRoots() ([]Root, error) {
+	return sortRoots(roots)
+}
+
+// sortRoots orders the given roots, see SortRoots.
+func sortRoots(roots []Root) ([]Root, error) {
 	if len(roots) == 0 {
 		return nil, nil
 	}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"mime"
+	"net/http"
 	"sync"
 	"time"
 )
@@ -27,6 +28,14 @@ type (
 		Reset(r io.Reader)
 	}
 
+	// RequestDecoder is implemented by decoders that need the HTTP request to decode its body,
+	// e.g. to read the boundary parameter of multipart content types. Service.DecodeRequest
+	// calls DecodeRequest instead of Decode on such decoders.
+	RequestDecoder interface {
+		Decoder
+		DecodeRequest(req *http.Request, v interface{}) error
+	}
+
 	// decoderPool smartly determines whether to instantiate a new Decoder or reuse one from a
 	// sync.Pool.
 	decoderPool struct {
@@ -70,11 +79,60 @@ type (
 	}
 )
 
-// NewJSONEncoder is an adapter for the encoding package JSON encoder.
-func NewJSONEncoder(w io.Writer) Encoder { return json.NewEncoder(w) }
+// NewJSONEncoder is an adapter for the JSON encoder of the codec set with SetJSONCodec, the
+// encoding package JSON encoder by default.
+func NewJSONEncoder(w io.Writer) Encoder { return GetJSONCodec().NewEncoder(w) }
+
+// NewJSONDecoder is an adapter for the JSON decoder of the codec set with SetJSONCodec, the
+// encoding package JSON decoder by default.
+func NewJSONDecoder(r io.Reader) Decoder { return GetJSONCodec().NewDecoder(r) }
+
+// NewStrictJSONDecoder is a DecoderFunc that returns JSON decoders that reject the objects with
+// keys that do not match the fields of the decoded structs. The decoding error names the first
+// unknown key so that the requests carrying undeclared payload attributes are rejected with a 400
+// response that names it. Register it in place of NewJSONDecoder to enable strict decoding:
+//
+//	service.Decoder.Register(goa.NewStrictJSONDecoder, "application/json")
+//
+// or in the design:
+//
+//	Consumes("application/json", func() {
+//		Package("github.com/kyokomi/goa-v1")
+//		Function("NewStrictJSONDecoder")
+//	})
+//
+// NewStrictJSONDecoder uses the decoders of the codec set with SetJSONCodec if they implement
+// DisallowUnknownFields, the encoding package JSON decoder otherwise.
+func NewStrictJSONDecoder(r io.Reader) Decoder {
+	if d, ok := GetJSONCodec().NewDecoder(r).(interface {
+		Decoder
+		DisallowUnknownFields()
+	}); ok {
+		d.DisallowUnknownFields()
+		return d
+	}
+	d := json.NewDecoder(r)
+	d.DisallowUnknownFields()
+	return d
+}
 
-// NewJSONDecoder is an adapter for the encoding package JSON decoder.
-func NewJSONDecoder(r io.Reader) Decoder { return json.NewDecoder(r) }
+// NewJSONNumberDecoder is a DecoderFunc that returns JSON decoders that decode the numbers held
+// in interface{} values, e.g. the values of Any attributes, as json.Number instead of float64 so
+// that large integer identifiers and monetary amounts are not rounded. The numbers decoded into
+// integer fields never go through float64. NewJSONNumberDecoder uses the decoders of the codec set
+// with SetJSONCodec if they implement UseNumber, the encoding package JSON decoder otherwise.
+func NewJSONNumberDecoder(r io.Reader) Decoder {
+	if d, ok := GetJSONCodec().NewDecoder(r).(interface {
+		Decoder
+		UseNumber()
+	}); ok {
+		d.UseNumber()
+		return d
+	}
+	d := json.NewDecoder(r)
+	d.UseNumber()
+	return d
+}
 
 // NewXMLEncoder is an adapter for the encoding package XML encoder.
 func NewXMLEncoder(w io.Writer) Encoder { return xml.NewEncoder(w) }
@@ -104,6 +162,12 @@ func NewHTTPDecoder() *HTTPDecoder {
 
 // Decode uses registered Decoders to unmarshal a body based on the contentType.
 func (decoder *HTTPDecoder) Decode(v interface{}, body io.Reader, contentType string) error {
+	return decoder.decode(v, body, contentType, nil)
+}
+
+// decode decodes the body, it uses DecodeRequest if req is not nil and the decoder registered for
+// the content type is a RequestDecoder.
+func (decoder *HTTPDecoder) decode(v interface{}, body io.Reader, contentType string, req *http.Request) error {
 	now := time.Now()
 	defer MeasureSince([]string{"goa", "decode", contentType}, now)
 	var p *decoderPool
@@ -126,6 +190,9 @@ func (decoder *HTTPDecoder) Decode(v interface{}, body io.Reader, contentType st
 	// the decoderPool will handle whether or not a pool is actually in use
 	d := p.Get(body)
 	defer p.Put(d)
+	if rd, ok := d.(RequestDecoder); ok && req != nil {
+		return rd.DecodeRequest(req, v)
+	}
 	return d.Decode(v)
 }
 
@@ -186,6 +253,36 @@ func (p *decoderPool) Put(d Decoder) {
 // using the given writer.
 func (encoder *HTTPEncoder) Encode(v interface{}, resp io.Writer, accept string) error {
 	now := time.Now()
+	p, contentType := encoder.negotiate(accept)
+	defer MeasureSince([]string{"goa", "encode", contentType}, now)
+	if p == nil {
+		return fmt.Errorf("No encoder registered for %s and no default encoder", contentType)
+	}
+
+	// the encoderPool will handle whether or not a pool is actually in use
+	e := p.Get(resp)
+	if err := e.Encode(v); err != nil {
+		return err
+	}
+	p.Put(e)
+
+	return nil
+}
+
+// NewEncoder returns the encoder registered for the given Accept header value that writes to
+// the given writer, it also returns the negotiated content type. Use NewEncoder to encode
+// multiple values to the same writer, Encode otherwise.
+func (encoder *HTTPEncoder) NewEncoder(w io.Writer, accept string) (Encoder, string, error) {
+	p, contentType := encoder.negotiate(accept)
+	if p == nil {
+		return nil, "", fmt.Errorf("No encoder registered for %s and no default encoder", contentType)
+	}
+	return p.fn(w), contentType, nil
+}
+
+// negotiate returns the encoder pool and the content type matching the given Accept header
+// value.
+func (encoder *HTTPEncoder) negotiate(accept string) (*encoderPool, string) {
 	if accept == "" {
 		accept = "*/*"
 	}
@@ -196,23 +293,11 @@ func (encoder *HTTPEncoder) Encode(v interface{}, resp io.Writer, accept string)
 			break
 		}
 	}
-	defer MeasureSince([]string{"goa", "encode", contentType}, now)
 	p := encoder.pools[contentType]
 	if p == nil && contentType != "*/*" {
 		p = encoder.pools["*/*"]
 	}
-	if p == nil {
-		return fmt.Errorf("No encoder registered for %s and no default encoder", contentType)
-	}
-
-	// the encoderPool will handle whether or not a pool is actually in use
-	e := p.Get(resp)
-	if err := e.Encode(v); err != nil {
-		return err
-	}
-	p.Put(e)
-
-	return nil
+	return p, contentType
 }
 
 // Register sets a specific encoder to be used for the specified content types. If an encoder is
@@ -2,13 +2,16 @@ package cbor
 
 import (
 	"io"
+	"reflect"
 
 	"github.com/kyokomi/goa-v1"
 	"github.com/ugorji/go/codec"
 )
 
 var (
-	// Handle used by encoder and decoder.
+	// Handle used by encoder and decoder. It decodes the maps of schema-less values (Any and
+	// Hash attributes) into map[string]interface{} values so that they can be validated and
+	// re-encoded like the values decoded from JSON.
 	Handle codec.CborHandle
 
 	// Enforce that codec.Decoder satisfies goa.ResettableDecoder at compile time
@@ -16,6 +19,10 @@ var (
 	_ goa.ResettableEncoder = (*codec.Encoder)(nil)
 )
 
+func init() {
+	Handle.MapType = reflect.TypeOf(map[string]interface{}(nil))
+}
+
 // NewDecoder returns a cbor decoder.
 func NewDecoder(r io.Reader) goa.Decoder {
 	return codec.NewDecoder(r, &Handle)
@@ -0,0 +1,13 @@
+package cbor_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestCborEncoding(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cbor Encoding Suite")
+}
@@ -0,0 +1,77 @@
+package cbor_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1"
+	"github.com/kyokomi/goa-v1/encoding/cbor"
+)
+
+var _ = Describe("CborEncoding", func() {
+	type Payload struct {
+		Name  *string     `json:"name,omitempty"`
+		Count int         `json:"count"`
+		Data  []byte      `json:"data,omitempty"`
+		Meta  interface{} `json:"meta,omitempty"`
+	}
+
+	name := "bottle"
+	data := Payload{
+		Name:  &name,
+		Count: 42,
+		Data:  []byte{0x00, 0xff},
+		Meta:  map[string]interface{}{"vintage": "2012"},
+	}
+
+	var b bytes.Buffer
+
+	BeforeEach(func() {
+		b.Reset()
+		Ω(cbor.NewEncoder(&b).Encode(data)).ShouldNot(HaveOccurred())
+	})
+
+	It("round trips", func() {
+		var payload Payload
+		Ω(cbor.NewDecoder(&b).Decode(&payload)).ShouldNot(HaveOccurred())
+		Ω(*payload.Name).Should(Equal(name))
+		Ω(payload.Count).Should(Equal(42))
+		Ω(payload.Data).Should(Equal([]byte{0x00, 0xff}))
+	})
+
+	It("uses the json field names", func() {
+		var raw map[string]interface{}
+		Ω(cbor.NewDecoder(&b).Decode(&raw)).ShouldNot(HaveOccurred())
+		Ω(raw).Should(HaveKey("name"))
+		Ω(raw).Should(HaveKey("count"))
+	})
+
+	It("decodes schema-less values like JSON", func() {
+		var payload Payload
+		Ω(cbor.NewDecoder(&b).Decode(&payload)).ShouldNot(HaveOccurred())
+		Ω(payload.Meta).Should(Equal(map[string]interface{}{"vintage": "2012"}))
+	})
+
+	It("decodes the data encoded by other clients", func() {
+		// {"count": 7, "name": "iot"} as defined in RFC 8949
+		raw := []byte{0xa2, 0x65, 'c', 'o', 'u', 'n', 't', 0x07, 0x64, 'n', 'a', 'm', 'e', 0x63, 'i', 'o', 't'}
+		var payload Payload
+		Ω(cbor.NewDecoder(bytes.NewReader(raw)).Decode(&payload)).ShouldNot(HaveOccurred())
+		Ω(payload.Count).Should(Equal(7))
+		Ω(*payload.Name).Should(Equal("iot"))
+	})
+
+	It("integrates with the HTTP encoder and decoder", func() {
+		enc := goa.NewHTTPEncoder()
+		enc.Register(cbor.NewEncoder, "application/cbor", "application/x-cbor")
+		dec := goa.NewHTTPDecoder()
+		dec.Register(cbor.NewDecoder, "application/cbor", "application/x-cbor")
+		var buf bytes.Buffer
+		Ω(enc.Encode(data, &buf, "application/x-cbor")).ShouldNot(HaveOccurred())
+		var payload Payload
+		Ω(dec.Decode(&payload, &buf, "application/cbor")).ShouldNot(HaveOccurred())
+		Ω(payload.Count).Should(Equal(42))
+	})
+})
@@ -18,6 +18,9 @@ The built-in encoder and decoder media types are:
 	- application/msgpack and application/x-msgpack
 	- application/binc and application/x-binc
 	- application/cbor and application/x-cbor
+	- application/protobuf and application/x-protobuf
+	- application/yaml and application/x-yaml
+	- multipart/form-data (decoding only)
 
 External encoders and decoders can also be specified via the DSL:
 
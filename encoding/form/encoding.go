@@ -2,16 +2,37 @@
 Package form provides a "application/x-www-form-encoding" encoder and decoder.  It uses
 github.com/ajg/form for the actual implementation which can be used directly as well.  The goal of
 this package is to raise awareness of the package above and its direct compatibility with goa.
+
+The decoder also accepts the bracket notation used by HTML forms and most client libraries for
+nested objects and arrays:
+
+	user[name]=joe&user[address][city]=Paris      // {"user": {"name": "joe", "address": {"city": "Paris"}}}
+	ids[]=1&ids[]=2                                // {"ids": [1, 2]}
+	items[0][name]=a&items[1][name]=b              // {"items": [{"name": "a"}, {"name": "b"}]}
+	items[][name]=a&items[][qty]=1&items[][name]=b // {"items": [{"name": "a", "qty": 1}, {"name": "b"}]}
+
+With empty brackets followed by a key a new element starts when the key is already set in the
+current element.
 */
 package form
 
 import (
+	"bytes"
 	"io"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/ajg/form"
 	"github.com/kyokomi/goa-v1"
 )
 
+// Decoder is a form decoder that supports both the dot notation of github.com/ajg/form and the
+// bracket notation for nested values.
+type Decoder struct {
+	r io.Reader
+}
+
 // NewEncoder returns a form encoder that writes to w.
 func NewEncoder(w io.Writer) goa.Encoder {
 	return form.NewEncoder(w)
@@ -19,5 +40,88 @@ func NewEncoder(w io.Writer) goa.Encoder {
 
 // NewDecoder returns a form decoder that reads from r.
 func NewDecoder(r io.Reader) goa.Decoder {
-	return form.NewDecoder(r)
+	return &Decoder{r: r}
+}
+
+// Decode reads the form from the underlying reader and decodes it into v.
+func (d *Decoder) Decode(v interface{}) error {
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(d.r); err != nil {
+		return err
+	}
+	vs, err := parse(buf.String())
+	if err != nil {
+		return err
+	}
+	return form.NewDecoder(nil).DecodeValues(v, vs)
+}
+
+// Reset sets the reader the decoder reads from.
+func (d *Decoder) Reset(r io.Reader) {
+	d.r = r
+}
+
+// element tracks the implicit indices of the values whose keys contain empty brackets.
+type element struct {
+	index int
+	keys  map[string]bool
+}
+
+// parse parses the form and translates the keys using the bracket notation to the dot notation
+// of github.com/ajg/form. The pairs are processed in order so that the implicit indices follow
+// the order of the form.
+func parse(s string) (url.Values, error) {
+	vs := url.Values{}
+	elems := make(map[string]*element)
+	for _, pair := range strings.FieldsFunc(s, func(r rune) bool { return r == '&' || r == ';' }) {
+		k, v := pair, ""
+		if i := strings.Index(pair, "="); i >= 0 {
+			k, v = pair[:i], pair[i+1:]
+		}
+		k, err := url.QueryUnescape(k)
+		if err != nil {
+			return nil, err
+		}
+		if v, err = url.QueryUnescape(v); err != nil {
+			return nil, err
+		}
+		vs.Add(translate(k, elems), v)
+	}
+	return vs, nil
+}
+
+// translate returns the dot notation of the given key, e.g. "user.address.city" for
+// "user[address][city]". Empty brackets are replaced with implicit indices.
+func translate(key string, elems map[string]*element) string {
+	i := strings.Index(key, "[")
+	if i <= 0 || !strings.HasSuffix(key, "]") {
+		return key
+	}
+	path := key[:i]
+	segs := strings.Split(key[i+1:len(key)-1], "][")
+	for j, seg := range segs {
+		if seg != "" {
+			path += "." + escape(seg)
+			continue
+		}
+		e, ok := elems[path]
+		if !ok {
+			e = &element{index: -1}
+			elems[path] = e
+		}
+		rest := strings.Join(segs[j+1:], "][")
+		if rest == "" || e.index < 0 || e.keys[rest] && !strings.Contains("]["+rest+"][", "][][") {
+			e.index++
+			e.keys = make(map[string]bool)
+		}
+		e.keys[rest] = true
+		path += "." + strconv.Itoa(e.index)
+	}
+	return path
+}
+
+// escape escapes the delimiter and escape characters of github.com/ajg/form in a key segment.
+func escape(seg string) string {
+	seg = strings.Replace(seg, `\`, `\\`, -1)
+	return strings.Replace(seg, ".", `\.`, -1)
 }
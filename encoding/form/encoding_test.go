@@ -0,0 +1,79 @@
+package form_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/encoding/form"
+)
+
+var _ = Describe("FormDecoder", func() {
+	type Address struct {
+		City    *string `form:"city,omitempty"`
+		Country *string `form:"country,omitempty"`
+	}
+	type Item struct {
+		Name *string `form:"name,omitempty"`
+		Qty  *int    `form:"qty,omitempty"`
+	}
+	type User struct {
+		Name    *string  `form:"name,omitempty"`
+		Address *Address `form:"address,omitempty"`
+	}
+	type Payload struct {
+		User  *User    `form:"user,omitempty"`
+		IDs   []int    `form:"ids,omitempty"`
+		Items []*Item  `form:"items,omitempty"`
+		Tags  []string `form:"tags,omitempty"`
+	}
+
+	var payload *Payload
+
+	decode := func(body string) error {
+		payload = &Payload{}
+		return form.NewDecoder(strings.NewReader(body)).Decode(payload)
+	}
+
+	It("decodes nested objects", func() {
+		Ω(decode("user[name]=joe&user[address][city]=Paris&user[address][country]=FR")).ShouldNot(HaveOccurred())
+		Ω(*payload.User.Name).Should(Equal("joe"))
+		Ω(*payload.User.Address.City).Should(Equal("Paris"))
+		Ω(*payload.User.Address.Country).Should(Equal("FR"))
+	})
+
+	It("decodes arrays with empty brackets in order", func() {
+		Ω(decode("ids[]=3&ids[]=1&ids[]=2")).ShouldNot(HaveOccurred())
+		Ω(payload.IDs).Should(Equal([]int{3, 1, 2}))
+	})
+
+	It("decodes arrays with explicit indices", func() {
+		Ω(decode("items[1][name]=b&items[0][name]=a&items[0][qty]=2")).ShouldNot(HaveOccurred())
+		Ω(payload.Items).Should(HaveLen(2))
+		Ω(*payload.Items[0].Name).Should(Equal("a"))
+		Ω(*payload.Items[0].Qty).Should(Equal(2))
+		Ω(*payload.Items[1].Name).Should(Equal("b"))
+	})
+
+	It("starts a new element when a key repeats after empty brackets", func() {
+		Ω(decode("items[][name]=a&items[][qty]=1&items[][name]=b")).ShouldNot(HaveOccurred())
+		Ω(payload.Items).Should(HaveLen(2))
+		Ω(*payload.Items[0].Name).Should(Equal("a"))
+		Ω(*payload.Items[0].Qty).Should(Equal(1))
+		Ω(*payload.Items[1].Name).Should(Equal("b"))
+		Ω(payload.Items[1].Qty).Should(BeNil())
+	})
+
+	It("decodes escaped brackets and the dot notation", func() {
+		Ω(decode("user%5Baddress%5D%5Bcity%5D=Lyon&user.name=ann&tags.0=x%20y")).ShouldNot(HaveOccurred())
+		Ω(*payload.User.Address.City).Should(Equal("Lyon"))
+		Ω(*payload.User.Name).Should(Equal("ann"))
+		Ω(payload.Tags).Should(Equal([]string{"x y"}))
+	})
+
+	It("reports invalid values", func() {
+		Ω(decode("ids[]=a")).Should(HaveOccurred())
+		Ω(decode("user[name]=%zz")).Should(HaveOccurred())
+	})
+})
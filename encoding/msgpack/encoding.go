@@ -2,6 +2,7 @@ package msgpack
 
 import (
 	"io"
+	"reflect"
 
 	"github.com/kyokomi/goa-v1"
 	"github.com/ugorji/go/codec"
@@ -12,9 +13,19 @@ var (
 	_ goa.ResettableDecoder = (*codec.Decoder)(nil)
 	_ goa.ResettableEncoder = (*codec.Encoder)(nil)
 
+	// Handle used by encoder and decoder. It encodes strings and byte slices with the str and
+	// bin types of the current msgpack spec. It decodes the maps and strings of schema-less
+	// values (Any and Hash attributes) into map[string]interface{} and string values so that
+	// they can be validated and re-encoded like the values decoded from JSON.
 	Handle codec.MsgpackHandle
 )
 
+func init() {
+	Handle.WriteExt = true
+	Handle.RawToString = true
+	Handle.MapType = reflect.TypeOf(map[string]interface{}(nil))
+}
+
 // NewDecoder returns a msgpack decoder.
 func NewDecoder(r io.Reader) goa.Decoder {
 	return codec.NewDecoder(r, &Handle)
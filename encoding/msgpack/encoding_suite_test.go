@@ -0,0 +1,13 @@
+package msgpack_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestMsgpackEncoding(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Msgpack Encoding Suite")
+}
@@ -0,0 +1,68 @@
+package msgpack_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1"
+	"github.com/kyokomi/goa-v1/encoding/msgpack"
+)
+
+var _ = Describe("MsgpackEncoding", func() {
+	type Payload struct {
+		Name  *string     `json:"name,omitempty"`
+		Count int         `json:"count"`
+		Data  []byte      `json:"data,omitempty"`
+		Meta  interface{} `json:"meta,omitempty"`
+	}
+
+	name := "bottle"
+	data := Payload{
+		Name:  &name,
+		Count: 42,
+		Data:  []byte{0x00, 0xff},
+		Meta:  map[string]interface{}{"vintage": "2012"},
+	}
+
+	var b bytes.Buffer
+
+	BeforeEach(func() {
+		b.Reset()
+		Ω(msgpack.NewEncoder(&b).Encode(data)).ShouldNot(HaveOccurred())
+	})
+
+	It("round trips", func() {
+		var payload Payload
+		Ω(msgpack.NewDecoder(&b).Decode(&payload)).ShouldNot(HaveOccurred())
+		Ω(*payload.Name).Should(Equal(name))
+		Ω(payload.Count).Should(Equal(42))
+		Ω(payload.Data).Should(Equal([]byte{0x00, 0xff}))
+	})
+
+	It("uses the json field names", func() {
+		var raw map[string]interface{}
+		Ω(msgpack.NewDecoder(&b).Decode(&raw)).ShouldNot(HaveOccurred())
+		Ω(raw).Should(HaveKey("name"))
+		Ω(raw).Should(HaveKey("count"))
+	})
+
+	It("decodes schema-less values like JSON", func() {
+		var payload Payload
+		Ω(msgpack.NewDecoder(&b).Decode(&payload)).ShouldNot(HaveOccurred())
+		Ω(payload.Meta).Should(Equal(map[string]interface{}{"vintage": "2012"}))
+	})
+
+	It("integrates with the HTTP encoder and decoder", func() {
+		enc := goa.NewHTTPEncoder()
+		enc.Register(msgpack.NewEncoder, "application/msgpack", "application/x-msgpack")
+		dec := goa.NewHTTPDecoder()
+		dec.Register(msgpack.NewDecoder, "application/msgpack", "application/x-msgpack")
+		var buf bytes.Buffer
+		Ω(enc.Encode(data, &buf, "application/x-msgpack")).ShouldNot(HaveOccurred())
+		var payload Payload
+		Ω(dec.Decode(&payload, &buf, "application/msgpack")).ShouldNot(HaveOccurred())
+		Ω(payload.Count).Should(Equal(42))
+	})
+})
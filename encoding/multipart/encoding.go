@@ -0,0 +1,236 @@
+/*
+Package multipart provides a "multipart/form-data" decoder that maps the parts of multipart forms
+onto the request payloads so that the generated code can select it by content type like the
+other decoders:
+
+	service.Decoder.Register(multipart.NewDecoder, "multipart/form-data")
+
+The values of the parts that are not files are decoded like form-urlencoded values with
+github.com/kyokomi/goa-v1/encoding/form, including the bracket notation for nested objects and
+arrays, so that they are converted to the types of the payload attributes. The file parts are
+assigned to the payload fields of type *multipart.FileHeader or []*multipart.FileHeader whose
+form tag matches the part name, their content is stored in memory or in temporary files that are
+removed once the request is served.
+
+Use NewDecoderFunc with HandleFiles to stream the file parts to a handler instead, e.g. to upload
+them to an object storage without buffering them.
+
+The decoder implements goa.RequestDecoder as it needs the request content type boundary, it
+cannot decode bodies outside of Service.DecodeRequest.
+*/
+package multipart
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/kyokomi/goa-v1"
+	"github.com/kyokomi/goa-v1/encoding/form"
+)
+
+type (
+	// Decoder is a multipart form decoder.
+	Decoder struct {
+		r         io.Reader
+		maxMemory int64
+		handler   FileHandler
+	}
+
+	// FileHandler is called by the decoder configured with HandleFiles for each file part with
+	// the part name and content. The value it returns, e.g. the location of the stored file, is
+	// decoded into the payload attribute with the part name unless it is empty.
+	FileHandler func(name string, part *multipart.Part) (string, error)
+
+	// DecoderOption is a NewDecoderFunc option.
+	DecoderOption func(*Decoder) *Decoder
+)
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+// NewDecoder returns a multipart form decoder that reads from r and keeps up to 32MB of the
+// form in memory.
+func NewDecoder(r io.Reader) goa.Decoder {
+	return &Decoder{r: r, maxMemory: 32 << 20}
+}
+
+// NewDecoderFunc returns a function that creates decoders configured with the given options.
+//
+//	service.Decoder.Register(multipart.NewDecoderFunc(multipart.MaxMemory(1<<20)), "multipart/form-data")
+func NewDecoderFunc(options ...DecoderOption) goa.DecoderFunc {
+	return func(r io.Reader) goa.Decoder {
+		d := NewDecoder(r).(*Decoder)
+		for _, option := range options {
+			d = option(d)
+		}
+		return d
+	}
+}
+
+// MaxMemory sets the maximum number of bytes of the form kept in memory, the rest of the file
+// parts is stored in temporary files. When the files are handled with HandleFiles it is the
+// maximum size of each of the other parts.
+func MaxMemory(n int64) DecoderOption {
+	return func(d *Decoder) *Decoder {
+		d.maxMemory = n
+		return d
+	}
+}
+
+// HandleFiles makes the decoder stream the file parts to h as they are read.
+func HandleFiles(h FileHandler) DecoderOption {
+	return func(d *Decoder) *Decoder {
+		d.handler = h
+		return d
+	}
+}
+
+// Decode returns an error: decoding multipart forms requires the request, see DecodeRequest.
+func (d *Decoder) Decode(v interface{}) error {
+	return fmt.Errorf("multipart: cannot decode a form without its request")
+}
+
+// DecodeRequest decodes the multipart form of the given request into v.
+func (d *Decoder) DecodeRequest(req *http.Request, v interface{}) error {
+	var (
+		keys   []string
+		values = make(url.Values)
+		files  map[string][]*multipart.FileHeader
+	)
+	if d.handler == nil {
+		if err := req.ParseMultipartForm(d.maxMemory); err != nil {
+			return err
+		}
+		for k := range req.MultipartForm.Value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		values, files = req.MultipartForm.Value, req.MultipartForm.File
+	} else {
+		mr, err := req.MultipartReader()
+		if err != nil {
+			return err
+		}
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			name := part.FormName()
+			if name == "" {
+				part.Close()
+				continue
+			}
+			val, err := d.readPart(name, part)
+			part.Close()
+			if err != nil {
+				return err
+			}
+			if part.FileName() != "" && val == "" {
+				continue
+			}
+			if _, ok := values[name]; !ok {
+				keys = append(keys, name)
+			}
+			values.Add(name, val)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, k := range keys {
+		for _, val := range values[k] {
+			if buf.Len() > 0 {
+				buf.WriteByte('&')
+			}
+			buf.WriteString(url.QueryEscape(k))
+			buf.WriteByte('=')
+			buf.WriteString(url.QueryEscape(val))
+		}
+	}
+	if err := form.NewDecoder(&buf).Decode(v); err != nil {
+		return err
+	}
+	return setFiles(v, files)
+}
+
+// Reset sets the reader the decoder reads from.
+func (d *Decoder) Reset(r io.Reader) {
+	d.r = r
+}
+
+// readPart returns the value of the given part, the value returned by the file handler for file
+// parts.
+func (d *Decoder) readPart(name string, part *multipart.Part) (string, error) {
+	if part.FileName() != "" {
+		return d.handler(name, part)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(part, d.maxMemory+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(b)) > d.maxMemory {
+		return "", fmt.Errorf("multipart: value of %q is too large", name)
+	}
+	return string(b), nil
+}
+
+// setFiles assigns the file headers to the fields of the struct v points to.
+func setFiles(v interface{}, files map[string][]*multipart.FileHeader) error {
+	if len(files) == 0 {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("multipart: cannot decode files into %s", rv.Type())
+	}
+	for name, fhs := range files {
+		f, ok := field(rv, strings.TrimSuffix(name, "[]"))
+		if !ok {
+			return fmt.Errorf("multipart: %q doesn't exist in %s", name, rv.Type())
+		}
+		switch f.Type() {
+		case fileHeaderType:
+			f.Set(reflect.ValueOf(fhs[0]))
+		case fileHeadersType:
+			f.Set(reflect.ValueOf(fhs))
+		default:
+			return fmt.Errorf("multipart: %q is not a file field in %s", name, rv.Type())
+		}
+	}
+	return nil
+}
+
+// field returns the field of the struct value v with the given form name.
+func field(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		n := strings.Split(sf.Tag.Get("form"), ",")[0]
+		if n == "-" || sf.PkgPath != "" {
+			continue
+		}
+		if n == "" {
+			n = sf.Name
+		}
+		if n == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
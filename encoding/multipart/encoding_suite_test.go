@@ -0,0 +1,13 @@
+package multipart_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestMultipartEncoding(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Multipart Encoding Suite")
+}
@@ -0,0 +1,176 @@
+package multipart_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1"
+	mpart "github.com/kyokomi/goa-v1/encoding/multipart"
+)
+
+var _ = Describe("MultipartDecoder", func() {
+	type Payload struct {
+		Name     *string                 `form:"name,omitempty"`
+		Age      *int                    `form:"age,omitempty"`
+		Tags     []string                `form:"tags,omitempty"`
+		Avatar   *multipart.FileHeader   `form:"avatar,omitempty"`
+		Docs     []*multipart.FileHeader `form:"docs,omitempty"`
+		Location *string                 `form:"location,omitempty"`
+	}
+
+	var (
+		service *goa.Service
+		req     *http.Request
+		payload *Payload
+		err     error
+	)
+
+	newRequest := func(build func(w *multipart.Writer)) *http.Request {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		build(w)
+		Ω(w.Close()).ShouldNot(HaveOccurred())
+		r, err := http.NewRequest("POST", "/", &body)
+		Ω(err).ShouldNot(HaveOccurred())
+		r.Header.Set("Content-Type", w.FormDataContentType())
+		return r
+	}
+
+	writeFile := func(w *multipart.Writer, name, filename, content string) {
+		fw, err := w.CreateFormFile(name, filename)
+		Ω(err).ShouldNot(HaveOccurred())
+		fw.Write([]byte(content))
+	}
+
+	readFile := func(fh *multipart.FileHeader) string {
+		f, err := fh.Open()
+		Ω(err).ShouldNot(HaveOccurred())
+		defer f.Close()
+		b, err := ioutil.ReadAll(f)
+		Ω(err).ShouldNot(HaveOccurred())
+		return string(b)
+	}
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.Decoder.Register(mpart.NewDecoder, "multipart/form-data")
+		req = newRequest(func(w *multipart.Writer) {
+			w.WriteField("name", "joe")
+			w.WriteField("age", "42")
+			w.WriteField("tags[]", "a")
+			w.WriteField("tags[]", "b")
+			writeFile(w, "avatar", "avatar.png", "png")
+			writeFile(w, "docs", "a.txt", "doc a")
+			writeFile(w, "docs", "b.txt", "doc b")
+		})
+		payload = &Payload{}
+	})
+
+	JustBeforeEach(func() {
+		err = service.DecodeRequest(req, payload)
+	})
+
+	It("decodes the values and the files", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(*payload.Name).Should(Equal("joe"))
+		Ω(*payload.Age).Should(Equal(42))
+		Ω(payload.Tags).Should(Equal([]string{"a", "b"}))
+		Ω(payload.Avatar.Filename).Should(Equal("avatar.png"))
+		Ω(readFile(payload.Avatar)).Should(Equal("png"))
+		Ω(payload.Docs).Should(HaveLen(2))
+		Ω(readFile(payload.Docs[1])).Should(Equal("doc b"))
+		Ω(req.MultipartForm).ShouldNot(BeNil())
+	})
+
+	Context("with a value of the wrong type", func() {
+		BeforeEach(func() {
+			req = newRequest(func(w *multipart.Writer) {
+				w.WriteField("age", "old")
+			})
+		})
+
+		It("fails", func() {
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("with an unknown file", func() {
+		BeforeEach(func() {
+			req = newRequest(func(w *multipart.Writer) {
+				writeFile(w, "unknown", "a.txt", "a")
+			})
+		})
+
+		It("fails", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring(`"unknown" doesn't exist`))
+		})
+	})
+
+	Context("with a file handler", func() {
+		var (
+			handled  map[string]string
+			location bool
+		)
+
+		BeforeEach(func() {
+			handled = make(map[string]string)
+			location = false
+			handler := func(name string, part *multipart.Part) (string, error) {
+				b, err := ioutil.ReadAll(part)
+				if err != nil {
+					return "", err
+				}
+				handled[part.FileName()] = string(b)
+				if location {
+					return "s3://bucket/" + part.FileName(), nil
+				}
+				return "", nil
+			}
+			service.Decoder.Register(mpart.NewDecoderFunc(mpart.HandleFiles(handler)), "multipart/form-data")
+			req = newRequest(func(w *multipart.Writer) {
+				w.WriteField("name", "joe")
+				writeFile(w, "avatar", "avatar.png", "png")
+				writeFile(w, "docs", "a.txt", "doc a")
+			})
+			payload = &Payload{}
+		})
+
+		It("streams the files to the handler", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(handled).Should(Equal(map[string]string{"avatar.png": "png", "a.txt": "doc a"}))
+			Ω(*payload.Name).Should(Equal("joe"))
+			Ω(payload.Avatar).Should(BeNil())
+			Ω(payload.Docs).Should(BeEmpty())
+		})
+
+		Context("that returns a value", func() {
+			type LocationPayload struct {
+				Name   *string `form:"name,omitempty"`
+				Avatar *string `form:"avatar,omitempty"`
+				Docs   *string `form:"docs,omitempty"`
+			}
+
+			It("decodes the value", func() {
+				location = true
+				req = newRequest(func(w *multipart.Writer) {
+					writeFile(w, "avatar", "avatar.png", "png")
+				})
+				var p LocationPayload
+				Ω(service.DecodeRequest(req, &p)).ShouldNot(HaveOccurred())
+				Ω(*p.Avatar).Should(Equal("s3://bucket/avatar.png"))
+			})
+		})
+	})
+
+	It("cannot decode without the request", func() {
+		var body bytes.Buffer
+		err := service.Decoder.Decode(payload, &body, "multipart/form-data; boundary=x")
+		Ω(err).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,93 @@
+package protobuf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/kyokomi/goa-v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// Enforce that Decoder and Encoder satisfy goa.ResettableDecoder and goa.ResettableEncoder at
+// compile time
+var (
+	_ goa.ResettableDecoder = (*Decoder)(nil)
+	_ goa.ResettableEncoder = (*Encoder)(nil)
+)
+
+type (
+	// Converter is implemented by the values that convert into protocol buffer messages. The
+	// media types annotated with the "proto:message" metadata implement it, see the ToProto
+	// functions generated for them.
+	Converter interface {
+		// ToProto returns the protocol buffer message of the value, nil if the value
+		// cannot be converted.
+		ToProto() proto.Message
+	}
+
+	// Decoder decodes protocol buffer messages.
+	Decoder struct {
+		buf bytes.Buffer
+		r   io.Reader
+	}
+
+	// Encoder encodes protocol buffer messages.
+	Encoder struct {
+		w io.Writer
+	}
+)
+
+// NewDecoder returns a protocol buffer decoder that reads a single message from r.
+func NewDecoder(r io.Reader) goa.Decoder {
+	return &Decoder{r: r}
+}
+
+// Decode reads the whole reader and unmarshals it into v which must be a proto.Message.
+func (dec *Decoder) Decode(v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("cannot decode protocol buffer into %T, it does not implement proto.Message", v)
+	}
+	dec.buf.Reset()
+	if _, err := dec.buf.ReadFrom(dec.r); err != nil {
+		return err
+	}
+	return proto.Unmarshal(dec.buf.Bytes(), msg)
+}
+
+// Reset sets the reader of the decoder.
+func (dec *Decoder) Reset(r io.Reader) {
+	dec.buf.Reset()
+	dec.r = r
+}
+
+// NewEncoder returns a protocol buffer encoder that writes to w.
+func NewEncoder(w io.Writer) goa.Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the protocol buffer encoding of v which must be a proto.Message or a Converter.
+func (enc *Encoder) Encode(v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		c, ok := v.(Converter)
+		if !ok {
+			return fmt.Errorf("cannot encode %T as protocol buffer, it does not implement proto.Message", v)
+		}
+		if msg = c.ToProto(); msg == nil {
+			return fmt.Errorf("no protocol buffer conversion for %T", v)
+		}
+	}
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = enc.w.Write(b)
+	return err
+}
+
+// Reset sets the writer of the encoder.
+func (enc *Encoder) Reset(w io.Writer) {
+	enc.w = w
+}
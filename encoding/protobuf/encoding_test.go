@@ -0,0 +1,70 @@
+package protobuf_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/kyokomi/goa-v1"
+	"github.com/kyokomi/goa-v1/encoding/protobuf"
+)
+
+// bottle is a media type that converts into a protocol buffer message.
+type bottle struct {
+	Name string
+}
+
+func (b *bottle) ToProto() proto.Message {
+	if b.Name == "" {
+		return nil
+	}
+	return wrapperspb.String(b.Name)
+}
+
+var _ = Describe("ProtobufEncoding", func() {
+	var b bytes.Buffer
+
+	BeforeEach(func() {
+		b.Reset()
+	})
+
+	It("round trips messages", func() {
+		Ω(protobuf.NewEncoder(&b).Encode(wrapperspb.Int64(42))).ShouldNot(HaveOccurred())
+		var msg wrapperspb.Int64Value
+		Ω(protobuf.NewDecoder(&b).Decode(&msg)).ShouldNot(HaveOccurred())
+		Ω(msg.Value).Should(Equal(int64(42)))
+	})
+
+	It("encodes the values that convert into messages", func() {
+		Ω(protobuf.NewEncoder(&b).Encode(&bottle{Name: "Chateau"})).ShouldNot(HaveOccurred())
+		var msg wrapperspb.StringValue
+		Ω(proto.Unmarshal(b.Bytes(), &msg)).ShouldNot(HaveOccurred())
+		Ω(msg.Value).Should(Equal("Chateau"))
+	})
+
+	It("rejects the values with no conversion", func() {
+		Ω(protobuf.NewEncoder(&b).Encode(&bottle{})).Should(HaveOccurred())
+		Ω(protobuf.NewEncoder(&b).Encode(map[string]string{})).Should(HaveOccurred())
+		Ω(protobuf.NewDecoder(&b).Decode(&bottle{})).Should(HaveOccurred())
+	})
+
+	It("is selected by content negotiation", func() {
+		enc := goa.NewHTTPEncoder()
+		enc.Register(goa.NewJSONEncoder, "application/json")
+		enc.Register(protobuf.NewEncoder, "application/x-protobuf")
+		Ω(enc.Encode(&bottle{Name: "Chateau"}, &b, "application/x-protobuf")).ShouldNot(HaveOccurred())
+		var msg wrapperspb.StringValue
+		Ω(proto.Unmarshal(b.Bytes(), &msg)).ShouldNot(HaveOccurred())
+		Ω(msg.Value).Should(Equal("Chateau"))
+
+		dec := goa.NewHTTPDecoder()
+		dec.Register(protobuf.NewDecoder, "application/x-protobuf")
+		b.Reset()
+		Ω(enc.Encode(wrapperspb.String("Merlot"), &b, "application/x-protobuf")).ShouldNot(HaveOccurred())
+		Ω(dec.Decode(&msg, &b, "application/x-protobuf")).ShouldNot(HaveOccurred())
+		Ω(msg.Value).Should(Equal("Merlot"))
+	})
+})
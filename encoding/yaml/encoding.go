@@ -0,0 +1,154 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/kyokomi/goa-v1"
+	"gopkg.in/yaml.v2"
+)
+
+// Enforce that Decoder and Encoder satisfy goa.ResettableDecoder and goa.ResettableEncoder at
+// compile time
+var (
+	_ goa.ResettableDecoder = (*Decoder)(nil)
+	_ goa.ResettableEncoder = (*Encoder)(nil)
+)
+
+type (
+	// Decoder decodes YAML documents. The documents are converted to JSON before being
+	// decoded with the encoding/json package so that the generated types decode from YAML
+	// using the field names of their JSON tags.
+	Decoder struct {
+		buf bytes.Buffer
+		r   io.Reader
+	}
+
+	// Encoder encodes values to YAML documents. The values are encoded to JSON first so that
+	// the field names and the values of the generated types are the same as in their JSON
+	// representation, the fields keep their order.
+	Encoder struct {
+		w io.Writer
+	}
+)
+
+// NewDecoder returns a YAML decoder that reads a single document from r.
+func NewDecoder(r io.Reader) goa.Decoder {
+	return &Decoder{r: r}
+}
+
+// Decode reads the YAML document and decodes it into v.
+func (dec *Decoder) Decode(v interface{}) error {
+	dec.buf.Reset()
+	if _, err := dec.buf.ReadFrom(dec.r); err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := yaml.Unmarshal(dec.buf.Bytes(), &doc); err != nil {
+		return err
+	}
+	js, err := json.Marshal(jsonValue(doc))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(js, v)
+}
+
+// Reset sets the reader of the decoder.
+func (dec *Decoder) Reset(r io.Reader) {
+	dec.buf.Reset()
+	dec.r = r
+}
+
+// NewEncoder returns a YAML encoder that writes to w.
+func NewEncoder(w io.Writer) goa.Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the YAML document of v.
+func (enc *Encoder) Encode(v interface{}) error {
+	js, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(js))
+	d.UseNumber()
+	doc, err := yamlValue(d)
+	if err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	_, err = enc.w.Write(b)
+	return err
+}
+
+// Reset sets the writer of the encoder.
+func (enc *Encoder) Reset(w io.Writer) {
+	enc.w = w
+}
+
+// jsonValue converts the maps decoded by the yaml package into maps with string keys.
+func jsonValue(v interface{}) interface{} {
+	switch actual := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(actual))
+		for k, e := range actual {
+			m[fmt.Sprint(k)] = jsonValue(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range actual {
+			actual[i] = jsonValue(e)
+		}
+	}
+	return v
+}
+
+// yamlValue reads the next JSON value from d and converts it into a value encoded by the yaml
+// package, objects are converted into yaml.MapSlice values to preserve the order of the keys.
+func yamlValue(d *json.Decoder) (interface{}, error) {
+	t, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch actual := t.(type) {
+	case json.Delim:
+		if actual == '[' {
+			s := []interface{}{}
+			for d.More() {
+				e, err := yamlValue(d)
+				if err != nil {
+					return nil, err
+				}
+				s = append(s, e)
+			}
+			_, err = d.Token()
+			return s, err
+		}
+		m := yaml.MapSlice{}
+		for d.More() {
+			k, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			e, err := yamlValue(d)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, yaml.MapItem{Key: k, Value: e})
+		}
+		_, err = d.Token()
+		return m, err
+	case json.Number:
+		if i, err := actual.Int64(); err == nil {
+			return i, nil
+		}
+		return actual.Float64()
+	}
+	return t, nil
+}
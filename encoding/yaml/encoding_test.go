@@ -0,0 +1,66 @@
+package yaml_test
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1"
+	"github.com/kyokomi/goa-v1/encoding/yaml"
+)
+
+var _ = Describe("YamlEncoding", func() {
+	type Config struct {
+		Name      string                 `json:"name"`
+		MaxConns  int                    `json:"max_conns"`
+		Ratio     float64                `json:"ratio,omitempty"`
+		UpdatedAt *time.Time             `json:"updated_at,omitempty"`
+		Tags      []string               `json:"tags,omitempty"`
+		Extra     map[string]interface{} `json:"extra,omitempty"`
+	}
+
+	var b bytes.Buffer
+
+	BeforeEach(func() {
+		b.Reset()
+	})
+
+	It("encodes with the JSON field names in order", func() {
+		updated := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		cfg := &Config{Name: "db", MaxConns: 10, Ratio: 0.5, UpdatedAt: &updated, Tags: []string{"a"}}
+		Ω(yaml.NewEncoder(&b).Encode(cfg)).ShouldNot(HaveOccurred())
+		Ω(b.String()).Should(Equal("name: db\nmax_conns: 10\nratio: 0.5\nupdated_at: \"2020-01-02T03:04:05Z\"\ntags:\n- a\n"))
+	})
+
+	It("decodes with the JSON field names", func() {
+		b.WriteString("name: db\nmax_conns: 10\nupdated_at: 2020-01-02T03:04:05Z\nextra:\n  retries: 3\n  backoff:\n    max: 1s\n")
+		var cfg Config
+		Ω(yaml.NewDecoder(&b).Decode(&cfg)).ShouldNot(HaveOccurred())
+		Ω(cfg.Name).Should(Equal("db"))
+		Ω(cfg.MaxConns).Should(Equal(10))
+		Ω(cfg.UpdatedAt.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))).Should(BeTrue())
+		Ω(cfg.Extra).Should(Equal(map[string]interface{}{
+			"retries": float64(3),
+			"backoff": map[string]interface{}{"max": "1s"},
+		}))
+	})
+
+	It("reports invalid documents", func() {
+		b.WriteString("name: [")
+		var cfg Config
+		Ω(yaml.NewDecoder(&b).Decode(&cfg)).Should(HaveOccurred())
+	})
+
+	It("integrates with the HTTP encoder and decoder", func() {
+		enc := goa.NewHTTPEncoder()
+		enc.Register(yaml.NewEncoder, "application/yaml", "application/x-yaml")
+		dec := goa.NewHTTPDecoder()
+		dec.Register(yaml.NewDecoder, "application/yaml", "application/x-yaml")
+		Ω(enc.Encode(&Config{Name: "db"}, &b, "application/x-yaml")).ShouldNot(HaveOccurred())
+		var cfg Config
+		Ω(dec.Decode(&cfg, &b, "application/yaml")).ShouldNot(HaveOccurred())
+		Ω(cfg.Name).Should(Equal("db"))
+	})
+})
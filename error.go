@@ -2,12 +2,14 @@
 Package goa standardizes on structured error responses: a request that fails because of an
 invalid input or an unexpected condition produces a response that contains a structured error.
 
-The error data structures returned to clients contains five fields: an ID, a code, a status, a
-detail and metadata. The ID is unique for the occurrence of the error, it helps correlate the
-content of the response with the content of the service logs. The code defines the class of error
-(e.g.  "invalid_parameter_type") and the status the corresponding HTTP status (e.g. 400). The detail
-contains a message specific to the error occurrence. The metadata contains key/value pairs that
-provide contextual information (name of parameters, value of invalid parameter etc.).
+The error data structures returned to clients contains six fields: an ID, a code, a status, a
+detail, metadata and details. The ID is unique for the occurrence of the error, it helps correlate
+the content of the response with the content of the service logs. The code defines the class of
+error (e.g.  "invalid_parameter_type") and the status the corresponding HTTP status (e.g. 400). The
+detail contains a message specific to the error occurrence. The metadata contains key/value pairs
+that provide contextual information (name of parameters, value of invalid parameter etc.). The
+details contain a structured value whose type is specific to the class of error, see
+WithErrorDetails and ErrorResponse.DecodeDetails.
 
 Instances of Error can be created via Error Class functions.
 See http://goa.design/implement/error_handling.html
@@ -26,14 +28,21 @@ previously called middleware or action handler into HTTP responses. If the error
 error class then the corresponding content including the HTTP status is used otherwise an internal
 error is returned. Errors that bubble up all the way to the top (i.e. not handled by the error
 middleware) also generate an internal error response.
+
+Services that set a ProblemRegistry render the error responses as problem details (RFC 7807) with
+the application/problem+json media type. The registry maps the codes of the error classes to the
+problem type URIs.
 */
 package goa
 
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"reflect"
 	"strings"
 )
 
@@ -82,10 +91,13 @@ type (
 	// It accepts a message and optional key value pairs and produces errors that implement
 	// ServiceError.
 	// If the message is a string or a fmt.Stringer then the string value is used.
-	// If the message is an error then the string returned by Error() is used.
+	// If the message is an error then the string returned by Error() is used and the error is
+	// wrapped so that it can be retrieved with errors.Unwrap.
 	// Otherwise the string produced using fmt.Sprintf("%v") is used.
 	// The optional key value pairs are intended to provide additional contextual information
 	// and are returned to the client.
+	// ErrorClass implements error so that it may be used as target of errors.Is, e.g.
+	// errors.Is(err, goa.ErrBadRequest) returns true if err was created by ErrBadRequest.
 	ErrorClass func(message interface{}, keyvals ...interface{}) error
 
 	// ServiceError is the interface implemented by all errors created using a ErrorClass
@@ -122,6 +134,12 @@ type (
 		Detail string `json:"detail" yaml:"detail" xml:"detail" form:"detail"`
 		// Meta contains additional key/value pairs useful to clients.
 		Meta map[string]interface{} `json:"meta,omitempty" yaml:"meta,omitempty" xml:"meta,omitempty" form:"meta,omitempty"`
+		// Details contains the structured details of the error, its type depends on the
+		// class of errors.
+		Details interface{} `json:"details,omitempty" yaml:"details,omitempty" xml:"details,omitempty" form:"details,omitempty"`
+
+		// cause is the error given as message to the error class if any.
+		cause error
 	}
 )
 
@@ -129,12 +147,17 @@ type (
 // It is the responsibility of the client to guarantee uniqueness of code.
 func NewErrorClass(code string, status int) ErrorClass {
 	return func(message interface{}, keyvals ...interface{}) error {
+		if message == queryCode {
+			return classCode(code)
+		}
 		var msg string
+		var cause error
 		switch actual := message.(type) {
 		case string:
 			msg = actual
 		case error:
 			msg = actual.Error()
+			cause = actual
 		case fmt.Stringer:
 			msg = actual.String()
 		default:
@@ -153,10 +176,48 @@ func NewErrorClass(code string, status int) ErrorClass {
 			}
 			meta[fmt.Sprintf("%v", k)] = v
 		}
-		return &ErrorResponse{ID: newErrorID(), Code: code, Status: status, Detail: msg, Meta: meta}
+		return &ErrorResponse{ID: newErrorID(), Code: code, Status: status, Detail: msg, Meta: meta, cause: cause}
 	}
 }
 
+// Code returns the code of the errors produced by the error class, the empty string if the class
+// does not produce instances of ErrorResponse.
+func (c ErrorClass) Code() string {
+	switch e := c(queryCode).(type) {
+	case classCode:
+		return string(e)
+	case *ErrorResponse:
+		return e.Code
+	}
+	return ""
+}
+
+// queryCode is the message given to an error class to retrieve its code. The error classes
+// created with NewErrorClass return their code as a classCode instead of creating an error
+// response with a new ID.
+var queryCode = &struct{ name string }{"code"}
+
+// classCode is the code of an error class returned when the class is given queryCode.
+type classCode string
+
+// Error returns the code.
+func (c classCode) Error() string { return string(c) }
+
+// Error returns the code of the error class.
+func (c ErrorClass) Error() string {
+	return c.Code()
+}
+
+// WithErrorDetails sets the details of err and returns it. err must be a ErrorResponse or wrap one
+// for the details to be set, it is returned unchanged otherwise.
+func WithErrorDetails(err error, details interface{}) error {
+	var e *ErrorResponse
+	if errors.As(err, &e) {
+		e.Details = details
+	}
+	return err
+}
+
 // MissingPayloadError is the error produced when a request is missing a required payload.
 func MissingPayloadError() error {
 	return ErrInvalidRequest("missing required payload")
@@ -275,6 +336,44 @@ func (e *ErrorResponse) ResponseStatus() int { return e.Status }
 // Token is the unique error occurrence identifier.
 func (e *ErrorResponse) Token() string { return e.ID }
 
+// Is returns true if target is the error class that produced the error or an error with the same
+// code. It makes it possible to use errors.Is to test for the class of an error.
+func (e *ErrorResponse) Is(target error) bool {
+	switch t := target.(type) {
+	case ErrorClass:
+		return e.Code != "" && e.Code == t.Code()
+	case *ErrorResponse:
+		return t.ID == "" && e.Code != "" && e.Code == t.Code
+	}
+	return false
+}
+
+// DecodeDetails stores the details of the error in the value pointed to by v. The details are
+// assigned directly if their type matches, they are converted using a JSON round trip otherwise
+// which is the case when the error was decoded from a response body. DecodeDetails returns an
+// error if the error has no details or if they cannot be converted.
+func (e *ErrorResponse) DecodeDetails(v interface{}) error {
+	if e.Details == nil {
+		return fmt.Errorf("error %s has no details", e.ID)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("invalid details target %T, must be a non-nil pointer", v)
+	}
+	if dv := reflect.ValueOf(e.Details); dv.Type().AssignableTo(rv.Elem().Type()) {
+		rv.Elem().Set(dv)
+		return nil
+	}
+	b, err := json.Marshal(e.Details)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// Unwrap returns the error given as message to the error class that produced the error if any.
+func (e *ErrorResponse) Unwrap() error { return e.cause }
+
 // MergeErrors updates an error by merging another into it. It first converts other into a
 // ServiceError if not already one - producing an internal error in that case. The merge algorithm
 // is:
@@ -287,7 +386,8 @@ func (e *ErrorResponse) Token() string { return e.ID }
 //
 // The Detail field is updated by concatenating the Detail fields of e and other separated
 // by a semi-colon. The MetaValues field of is updated by merging the map of other MetaValues
-// into e's where values in e with identical keys to values in other get overwritten.
+// into e's where values in e with identical keys to values in other get overwritten. The Details
+// field of e is kept if set, it is set to the Details field of other otherwise.
 //
 // Merge returns the updated error. This is useful in case the error was initially nil in
 // which case other is returned.
@@ -330,21 +430,24 @@ func MergeErrors(err, other error) error {
 	for k, v := range o.Meta {
 		e.Meta[k] = v
 	}
+	if e.Details == nil {
+		e.Details = o.Details
+	}
 	return e
 }
 
 func asServiceError(err error) ServiceError {
-	e, ok := err.(ServiceError)
-	if !ok {
+	var e ServiceError
+	if !errors.As(err, &e) {
 		return asErrorResponse(err)
 	}
 	return e
 }
 
 func asErrorResponse(err error) *ErrorResponse {
-	e, ok := err.(*ErrorResponse)
-	if !ok {
-		return &ErrorResponse{Status: 500, Code: "internal_error", Detail: err.Error()}
+	var e *ErrorResponse
+	if !errors.As(err, &e) {
+		return &ErrorResponse{Status: 500, Code: "internal_error", Detail: err.Error(), cause: err}
 	}
 	return e
 }
@@ -30,6 +30,82 @@ var _ = Describe("Error", func() {
 		Ω(err).ShouldNot(HaveOccurred())
 		Ω(string(b)).Should(Equal(`{"id":"foo","code":"invalid","status":400,"detail":"error","meta":{"what":42}}`))
 	})
+
+	It("matches its error class", func() {
+		err := ErrBadRequest("foo")
+		Ω(errors.Is(err, ErrBadRequest)).Should(BeTrue())
+		Ω(errors.Is(err, ErrInvalidRequest)).Should(BeFalse())
+		Ω(errors.Is(fmt.Errorf("wrapped: %w", err), ErrBadRequest)).Should(BeTrue())
+		Ω(errors.Is(err, &ErrorResponse{Code: "bad_request"})).Should(BeTrue())
+		Ω(errors.Is(err, &ErrorResponse{ID: "foo", Code: "bad_request"})).Should(BeFalse())
+	})
+
+	It("unwraps the error given as message", func() {
+		cause := errors.New("cause")
+		err := ErrInternal(cause)
+		Ω(errors.Unwrap(err)).Should(Equal(cause))
+		Ω(errors.Is(err, cause)).Should(BeTrue())
+		Ω(errors.Unwrap(ErrInternal("foo"))).Should(BeNil())
+	})
+
+	It("can be retrieved with errors.As", func() {
+		var e *ErrorResponse
+		Ω(errors.As(fmt.Errorf("wrapped: %w", ErrNotFound("foo")), &e)).Should(BeTrue())
+		Ω(e.Code).Should(Equal("not_found"))
+		Ω(e.ResponseStatus()).Should(Equal(404))
+	})
+})
+
+var _ = Describe("ErrorDetails", func() {
+	type details struct {
+		Available int `json:"available"`
+	}
+
+	It("sets and retrieves typed details", func() {
+		err := WithErrorDetails(ErrBadRequest("out of stock"), &details{Available: 2})
+		var d *details
+		Ω(err.(*ErrorResponse).DecodeDetails(&d)).ShouldNot(HaveOccurred())
+		Ω(d).Should(Equal(&details{Available: 2}))
+	})
+
+	It("serializes the details", func() {
+		err := &ErrorResponse{ID: "foo", Code: "bad_request", Status: 400, Detail: "out of stock", Details: &details{Available: 2}}
+		b, jerr := json.Marshal(err)
+		Ω(jerr).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"id":"foo","code":"bad_request","status":400,"detail":"out of stock","details":{"available":2}}`))
+	})
+
+	It("decodes the details of decoded errors", func() {
+		var err ErrorResponse
+		Ω(json.Unmarshal([]byte(`{"code":"bad_request","details":{"available":2}}`), &err)).ShouldNot(HaveOccurred())
+		var d details
+		Ω(err.DecodeDetails(&d)).ShouldNot(HaveOccurred())
+		Ω(d.Available).Should(Equal(2))
+	})
+
+	It("reports missing details", func() {
+		var d details
+		Ω(ErrBadRequest("foo").(*ErrorResponse).DecodeDetails(&d)).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("ErrorClass", func() {
+	It("returns its code", func() {
+		Ω(ErrNotFound.Code()).Should(Equal("not_found"))
+		Ω(ErrNotFound.Error()).Should(Equal("not_found"))
+	})
+
+	It("returns the code of custom error classes", func() {
+		custom := ErrorClass(func(message interface{}, keyvals ...interface{}) error {
+			return ErrNotFound(message, keyvals...)
+		})
+		Ω(custom.Code()).Should(Equal("not_found"))
+	})
+
+	It("does not create an error to compute its code", func() {
+		Ω(ErrNotFound.Code()).Should(Equal("not_found"))
+		Ω(ErrNotFound(queryCode)).ShouldNot(BeAssignableToTypeOf(&ErrorResponse{}))
+	})
 })
 
 var _ = Describe("InvalidParamTypeError", func() {
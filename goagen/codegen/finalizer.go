@@ -61,6 +61,11 @@ func (f *Finalizer) recurse(root, att *design.AttributeDefinition, target string
 		f.seen[root] = map[*design.AttributeDefinition]*bytes.Buffer{att: buf}
 	}
 
+	if tname, _ := ExternalType(att); tname != "" {
+		// Existing Go types are responsible for their own default values
+		return buf
+	}
+
 	if o := att.Type.ToObject(); o != nil {
 		o.IterateAttributes(func(n string, catt *design.AttributeDefinition) error {
 			if att.HasDefaultValue(n) {
@@ -124,6 +129,9 @@ func PrintVal(t design.DataType, val interface{}) string {
 		case design.DateTime:
 			s = fmt.Sprintf("time.Parse(time.RFC3339, %s)", s)
 		}
+		if c := design.CustomPrimitive(t); c != nil {
+			s = fmt.Sprintf("%s(%s)", c.GoType, s)
+		}
 		return s
 	case t.IsHash():
 		// The input is a hash
@@ -0,0 +1,101 @@
+package codegen
+
+import "sync"
+
+// formatter formats source files in the background, see FormatConcurrently.
+type formatter struct {
+	// sem limits the number of files formatted at the same time.
+	sem chan struct{}
+	// wg tracks the queued files.
+	wg sync.WaitGroup
+	// mu protects pending and err.
+	mu sync.Mutex
+	// pending indexes the channels closed once the queued files are formatted by absolute path.
+	pending map[string]chan struct{}
+	// err is the first formatting error.
+	err error
+}
+
+// bgFormatter is the formatter used by FormatCode, nil if the files are formatted synchronously.
+var bgFormatter *formatter
+
+// FormatConcurrently makes FormatCode return immediately and format the source files in the
+// background, up to jobs files at a time. Formatting is the most expensive part of rendering
+// large designs, doing it in the background lets the generators render the next files in the
+// meantime and use all the CPU cores. Rendering itself stays sequential so that the generated
+// code does not depend on scheduling (e.g. the names returned by Tempvar).
+//
+// Opening or creating a source file waits for the pending formatting of the same file. The
+// generated files are complete once WaitFormat returns. A jobs value lower than 2 restores
+// synchronous formatting.
+func FormatConcurrently(jobs int) {
+	if jobs < 2 {
+		bgFormatter = nil
+		return
+	}
+	bgFormatter = &formatter{
+		sem:     make(chan struct{}, jobs),
+		pending: make(map[string]chan struct{}),
+	}
+}
+
+// WaitFormat waits for the source files queued by FormatCode to be formatted and returns the
+// first error that occurred, if any.
+func WaitFormat() error {
+	fm := bgFormatter
+	if fm == nil {
+		return nil
+	}
+	fm.wg.Wait()
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	err := fm.err
+	fm.err = nil
+	return err
+}
+
+// queue formats the file at path in the background using format. Files queued more than once
+// are formatted in order.
+func (fm *formatter) queue(path string, format func() error) {
+	done := make(chan struct{})
+	fm.mu.Lock()
+	prev := fm.pending[path]
+	fm.pending[path] = done
+	fm.mu.Unlock()
+	fm.wg.Add(1)
+	go func() {
+		defer fm.wg.Done()
+		if prev != nil {
+			<-prev
+		}
+		fm.sem <- struct{}{}
+		err := format()
+		<-fm.sem
+		fm.mu.Lock()
+		if err != nil && fm.err == nil {
+			fm.err = err
+		}
+		if fm.pending[path] == done {
+			delete(fm.pending, path)
+		}
+		fm.mu.Unlock()
+		close(done)
+	}()
+}
+
+// wait waits for the pending formatting of the file at path if any.
+func (fm *formatter) wait(path string) {
+	fm.mu.Lock()
+	done := fm.pending[path]
+	fm.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+// waitFormatted waits for the background formatting of the file at path if any.
+func waitFormatted(path string) {
+	if fm := bgFormatter; fm != nil {
+		fm.wait(path)
+	}
+}
@@ -0,0 +1,108 @@
+package codegen_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+var _ = Describe("FormatConcurrently", func() {
+	var (
+		workspace *codegen.Workspace
+		source    string
+		path      string
+	)
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("format")
+		Ω(err).ShouldNot(HaveOccurred())
+		dir, err := ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+		Ω(err).ShouldNot(HaveOccurred())
+		path = filepath.Join(dir, "file.go")
+		source = "package foo\nimport \"fmt\"\nfunc  Foo( ) {}\n"
+		codegen.FormatConcurrently(4)
+	})
+
+	JustBeforeEach(func() {
+		file, err := codegen.SourceFileFor(path)
+		Ω(err).ShouldNot(HaveOccurred())
+		_, err = file.Write([]byte(source))
+		Ω(err).ShouldNot(HaveOccurred())
+		file.Close()
+		Ω(file.FormatCode()).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		codegen.FormatConcurrently(0)
+		workspace.Delete()
+	})
+
+	It("formats the files once WaitFormat returns", func() {
+		Ω(codegen.WaitFormat()).Should(Succeed())
+		content, err := ioutil.ReadFile(path)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(Equal("package foo\n\nfunc Foo() {}\n"))
+	})
+
+	It("waits for the pending formatting when the file is opened again", func() {
+		file, err := codegen.SourceFileFor(path)
+		Ω(err).ShouldNot(HaveOccurred())
+		_, err = file.Write([]byte("func Bar() {}\n"))
+		Ω(err).ShouldNot(HaveOccurred())
+		file.Close()
+		Ω(codegen.WaitFormat()).Should(Succeed())
+		content, err := ioutil.ReadFile(path)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(Equal("package foo\n\nfunc Foo() {}\nfunc Bar() {}\n"))
+	})
+
+	Context("with invalid code", func() {
+		BeforeEach(func() {
+			source = "package foo\nfunc {"
+		})
+
+		It("returns the error from WaitFormat", func() {
+			Ω(codegen.WaitFormat()).Should(MatchError(ContainSubstring("expected")))
+			Ω(codegen.WaitFormat()).Should(Succeed())
+		})
+	})
+})
+
+var _ = Describe("FormatCode", func() {
+	var workspace *codegen.Workspace
+	var path string
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("format")
+		Ω(err).ShouldNot(HaveOccurred())
+		dir, err := ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+		Ω(err).ShouldNot(HaveOccurred())
+		path = filepath.Join(dir, "file.go")
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("keeps the used imports whose package name differs from the last path element", func() {
+		file, err := codegen.SourceFileFor(path)
+		Ω(err).ShouldNot(HaveOccurred())
+		_, err = file.Write([]byte("package foo\nimport (\n\"github.com/kyokomi/goa-v1\"\n\"github.com/go-openapi/loads\"\n\"gopkg.in/yaml.v2\"\n\"github.com/foo/bar/v2\"\n\"fmt\"\n)\nvar _ = goa.New\nvar _ = loads.Spec\nvar _ = yaml.Marshal\nvar _ = bar.Bar\n"))
+		Ω(err).ShouldNot(HaveOccurred())
+		file.Close()
+		Ω(file.FormatCode()).Should(Succeed())
+		content, err := ioutil.ReadFile(path)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring(`"github.com/kyokomi/goa-v1"`))
+		Ω(string(content)).Should(ContainSubstring(`"github.com/go-openapi/loads"`))
+		Ω(string(content)).Should(ContainSubstring(`"gopkg.in/yaml.v2"`))
+		Ω(string(content)).Should(ContainSubstring(`"github.com/foo/bar/v2"`))
+		Ω(string(content)).ShouldNot(ContainSubstring(`"fmt"`))
+	})
+})
@@ -6,6 +6,8 @@ import (
 	"go/build"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"unicode"
 
@@ -140,6 +142,30 @@ func CanonicalParams(r *design.ResourceDefinition) []string {
 	return params
 }
 
+// PathParamRegex matches the path parameters and wildcards of a route path, the first submatch is
+// the name of the parameter.
+var PathParamRegex = regexp.MustCompile(`[:*]([a-zA-Z0-9_]+)`)
+
+// SortedNames returns the names of the attributes of the object in alphabetical order.
+func SortedNames(obj design.Object) []string {
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ContainsString returns true if s is in strs.
+func ContainsString(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
+
 // Casing exceptions
 var toLower = map[string]string{"OAuth": "oauth"}
 
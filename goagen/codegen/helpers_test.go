@@ -10,6 +10,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/kyokomi/goa-v1/design"
 	"github.com/kyokomi/goa-v1/goagen/codegen"
 )
 
@@ -35,6 +36,20 @@ var _ = Describe("Helpers", func() {
 		})
 	})
 
+	Describe("SortedNames", func() {
+		It("returns the attribute names in alphabetical order", func() {
+			obj := design.Object{"b": {Type: design.String}, "c": {Type: design.String}, "a": {Type: design.String}}
+			Expect(codegen.SortedNames(obj)).To(Equal([]string{"a", "b", "c"}))
+		})
+	})
+
+	Describe("PathParamRegex", func() {
+		It("matches the path parameters and wildcards", func() {
+			matches := codegen.PathParamRegex.FindAllStringSubmatch("/bottles/:id/files/*path", -1)
+			Expect(matches).To(Equal([][]string{{":id", "id"}, {"*path", "path"}}))
+		})
+	})
+
 	Describe("CommandLine", func() {
 		Context("with exported GOPATH", func() {
 			oldGOPATH, oldArgs := build.Default.GOPATH, os.Args
@@ -31,7 +31,7 @@ func (s *ImportSpec) Code() string {
 }
 
 // AttributeImports constructs a new ImportsSpec slice from an existing slice and adds in imports specified in
-// struct:field:type Metadata tags.
+// struct:field:type and struct:type Metadata tags and the imports of the custom primitive types.
 func AttributeImports(att *design.AttributeDefinition, imports []*ImportSpec, seen []*design.AttributeDefinition) []*ImportSpec {
 
 	for _, a := range seen {
@@ -49,6 +49,17 @@ func AttributeImports(att *design.AttributeDefinition, imports []*ImportSpec, se
 		}
 	}
 
+	if c := design.CustomPrimitive(att.Type); c != nil && c.GoPackage != "" {
+		imports = appendImports(imports, []*ImportSpec{SimpleImport(c.GoPackage)})
+	}
+
+	if tname, path := ExternalType(att); tname != "" {
+		if path != "" {
+			imports = appendImports(imports, []*ImportSpec{SimpleImport(path)})
+		}
+		return imports
+	}
+
 	switch t := att.Type.(type) {
 	case *design.UserTypeDefinition:
 		return appendImports(imports, AttributeImports(t.AttributeDefinition, imports, seen))
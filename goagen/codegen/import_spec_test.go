@@ -157,6 +157,30 @@ var _ = Describe("AttributeImports", func() {
 			})
 		})
 
+		Context("of UserTypeDefinition mapped to an existing Go type", func() {
+			It("produces the import slice", func() {
+				var imports []*codegen.ImportSpec
+				object = Object{
+					"bar": &AttributeDefinition{Type: String},
+				}
+				object["bar"].Metadata = dslengine.MetadataDefinition{
+					"struct:field:type": []string{"json.RawMessage", "encoding/json"},
+				}
+				u := &UserTypeDefinition{
+					AttributeDefinition: &AttributeDefinition{Type: object},
+				}
+				u.Metadata = dslengine.MetadataDefinition{
+					"struct:type": []string{"github.com/org/pkg.Money"},
+				}
+
+				att = &AttributeDefinition{Type: u}
+				imports = codegen.AttributeImports(att, imports, nil)
+
+				Ω(imports).Should(HaveLen(1))
+				Ω(imports[0].Path).Should(Equal("github.com/org/pkg"))
+			})
+		})
+
 		Context("of MediaTypeDefinition", func() {
 			It("produces the import slice", func() {
 				var imports []*codegen.ImportSpec
@@ -0,0 +1,83 @@
+package codegen
+
+import (
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// removedFile records the content checksum and modification time of a generated file deleted by
+// RemoveAll.
+type removedFile struct {
+	sum     [sha256.Size]byte
+	modTime time.Time
+}
+
+var (
+	// removed records the generated files deleted by RemoveAll indexed by absolute path.
+	removed = make(map[string]*removedFile)
+	// removedMu protects removed.
+	removedMu sync.Mutex
+)
+
+// RemoveAll deletes path and any children it contains like os.RemoveAll. It also records the
+// content checksum and modification time of the deleted files so that regenerating a file with
+// identical content leaves its modification time unchanged, see KeepModTime. This keeps the
+// build caches of tools relying on modification times (e.g. "go build") warm when regenerating
+// large trees.
+func RemoveAll(path string) error {
+	filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return nil
+		}
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			return nil
+		}
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil
+		}
+		removedMu.Lock()
+		removed[abs] = &removedFile{sum: sha256.Sum256(b), modTime: info.ModTime()}
+		removedMu.Unlock()
+		return nil
+	})
+	return os.RemoveAll(path)
+}
+
+// WriteFile writes data to the file named by path like ioutil.WriteFile. The modification time
+// of the file is preserved if it was deleted by RemoveAll and data is identical to its previous
+// content.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	if err := ioutil.WriteFile(path, data, perm); err != nil {
+		return err
+	}
+	KeepModTime(path)
+	return nil
+}
+
+// KeepModTime restores the modification time the file at path had when it was deleted by
+// RemoveAll if its content did not change since. It returns true if the modification time was
+// restored, false if the file was not deleted by RemoveAll or if its content differs.
+func KeepModTime(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	removedMu.Lock()
+	prev, ok := removed[abs]
+	delete(removed, abs)
+	removedMu.Unlock()
+	if !ok {
+		return false
+	}
+	b, err := ioutil.ReadFile(abs)
+	if err != nil || sha256.Sum256(b) != prev.sum {
+		return false
+	}
+	return os.Chtimes(abs, time.Now(), prev.modTime) == nil
+}
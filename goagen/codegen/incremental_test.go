@@ -0,0 +1,100 @@
+package codegen_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+var _ = Describe("RemoveAll", func() {
+	var (
+		dir     string
+		file    string
+		modTime time.Time
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "incremental")
+		Ω(err).ShouldNot(HaveOccurred())
+		file = filepath.Join(dir, "out", "file.txt")
+		Ω(os.MkdirAll(filepath.Dir(file), 0755)).Should(Succeed())
+		Ω(ioutil.WriteFile(file, []byte("content"), 0644)).Should(Succeed())
+		modTime = time.Now().Add(-time.Hour).Truncate(time.Second)
+		Ω(os.Chtimes(file, modTime, modTime)).Should(Succeed())
+		Ω(codegen.RemoveAll(filepath.Join(dir, "out"))).Should(Succeed())
+		Ω(os.MkdirAll(filepath.Dir(file), 0755)).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("deletes the directory", func() {
+		Ω(codegen.RemoveAll(filepath.Join(dir, "out"))).Should(Succeed())
+		_, err := os.Stat(filepath.Join(dir, "out"))
+		Ω(os.IsNotExist(err)).Should(BeTrue())
+	})
+
+	It("preserves the modification time of files regenerated with identical content", func() {
+		Ω(codegen.WriteFile(file, []byte("content"), 0644)).Should(Succeed())
+		info, err := os.Stat(file)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(info.ModTime().Equal(modTime)).Should(BeTrue())
+	})
+
+	It("updates the modification time of files regenerated with different content", func() {
+		Ω(codegen.WriteFile(file, []byte("changed"), 0644)).Should(Succeed())
+		info, err := os.Stat(file)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(info.ModTime().After(modTime)).Should(BeTrue())
+	})
+
+	It("preserves the modification time of formatted source files", func() {
+		workspace, err := codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		defer workspace.Delete()
+		pkg, err := workspace.NewPackage("out")
+		Ω(err).ShouldNot(HaveOccurred())
+		generate := func() string {
+			file, err := pkg.CreateSourceFile("main.go")
+			Ω(err).ShouldNot(HaveOccurred())
+			_, err = file.Write([]byte("package main\nfunc main() {\n}\n"))
+			Ω(err).ShouldNot(HaveOccurred())
+			file.Close()
+			Ω(file.FormatCode()).Should(Succeed())
+			return file.Abs()
+		}
+		gofile := generate()
+		Ω(os.Chtimes(gofile, modTime, modTime)).Should(Succeed())
+		Ω(codegen.RemoveAll(pkg.Abs())).Should(Succeed())
+		Ω(os.MkdirAll(pkg.Abs(), 0755)).Should(Succeed())
+
+		generate()
+		info, err := os.Stat(gofile)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(info.ModTime().Equal(modTime)).Should(BeTrue())
+	})
+
+	It("restores the modification time of a removed file only once", func() {
+		Ω(ioutil.WriteFile(file, []byte("content"), 0644)).Should(Succeed())
+		Ω(codegen.KeepModTime(file)).Should(BeTrue())
+		Ω(codegen.KeepModTime(file)).Should(BeFalse())
+	})
+
+	It("does not restore the modification time of files that were not removed", func() {
+		other := filepath.Join(dir, "other.txt")
+		Ω(ioutil.WriteFile(other, []byte("content"), 0644)).Should(Succeed())
+		Ω(os.Chtimes(other, modTime, modTime)).Should(Succeed())
+		Ω(codegen.WriteFile(other, []byte("content"), 0644)).Should(Succeed())
+		info, err := os.Stat(other)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(info.ModTime().After(modTime)).Should(BeTrue())
+	})
+})
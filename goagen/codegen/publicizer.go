@@ -81,8 +81,10 @@ func Publicizer(att *design.AttributeDefinition, sourceField, targetField string
 		"dereference": dereference,
 		"init":        init,
 	}
+	tname, _ := ExternalType(att)
 	switch {
-	case att.Type.IsPrimitive():
+	case att.Type.IsPrimitive(), tname != "":
+		// Existing Go types are used as is in the private and public data structures
 		publication = RunTemplate(simplePublicizeT, data)
 	case att.Type.IsObject():
 		if _, ok := att.Type.(*design.MediaTypeDefinition); ok {
@@ -0,0 +1,56 @@
+package codegen
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// templateExt is the extension of the files overriding built-in templates.
+const templateExt = ".tmpl"
+
+// templates maps the qualified names of the templates loaded by LoadTemplates to their source.
+var templates = make(map[string]string)
+
+// LoadTemplates loads the template overrides stored in dir. Each file with the ".tmpl" extension
+// overrides the built-in template whose qualified name is the path of the file relative to dir
+// minus the extension, e.g. the file "app/context.tmpl" overrides the "context" template of the
+// "app" generator. Built-in templates that are not overridden are used as is.
+func LoadTemplates(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("invalid templates directory: %s", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("invalid templates directory: %s is not a directory", dir)
+	}
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != templateExt {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		templates[strings.TrimSuffix(filepath.ToSlash(rel), templateExt)] = string(b)
+		return nil
+	})
+}
+
+// Template returns the source of the template with the given name used by the given generator:
+// the override loaded by LoadTemplates if any, source otherwise.
+func Template(generator, name, source string) string {
+	if t, ok := templates[generator+"/"+name]; ok {
+		return t
+	}
+	return source
+}
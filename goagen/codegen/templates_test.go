@@ -0,0 +1,59 @@
+package codegen_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+var _ = Describe("LoadTemplates", func() {
+	var (
+		root, dir string
+		err       error
+	)
+
+	BeforeEach(func() {
+		root, err = ioutil.TempDir("", "templates")
+		Ω(err).ShouldNot(HaveOccurred())
+		dir = root
+		Ω(os.MkdirAll(filepath.Join(dir, "app"), 0755)).Should(Succeed())
+		Ω(ioutil.WriteFile(filepath.Join(dir, "app", "context.tmpl"), []byte("override"), 0644)).Should(Succeed())
+		Ω(ioutil.WriteFile(filepath.Join(dir, "app", "README.md"), []byte("ignored"), 0644)).Should(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		err = codegen.LoadTemplates(dir)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(root)
+	})
+
+	It("overrides the built-in templates", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(codegen.Template("app", "context", "built-in")).Should(Equal("override"))
+	})
+
+	It("falls back to the built-in templates", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(codegen.Template("app", "controller", "built-in")).Should(Equal("built-in"))
+		Ω(codegen.Template("client", "context", "built-in")).Should(Equal("built-in"))
+		Ω(codegen.Template("app", "README", "built-in")).Should(Equal("built-in"))
+	})
+
+	Context("with a missing directory", func() {
+		BeforeEach(func() {
+			dir = filepath.Join(dir, "missing")
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("invalid templates directory"))
+		})
+	})
+})
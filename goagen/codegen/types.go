@@ -16,10 +16,18 @@ import (
 // generating the code that transforms one data structure into another.
 const TransformMapKey = "transform:key"
 
+// ExternalTypeKey is the name of the metadata used to map a user type or an attribute to an existing
+// Go type instead of generating one.
+const ExternalTypeKey = "struct:type"
+
 var (
 	// TempCount holds the value appended to variable names to make them unique.
 	TempCount int
 
+	// ValidateTags controls whether GoTypeDef adds go-playground/validator "validate" tags
+	// derived from the design validations to the fields of the public data structures.
+	ValidateTags bool
+
 	// Templates used by GoTypeTransform
 	transformT       *template.Template
 	transformArrayT  *template.Template
@@ -70,6 +78,9 @@ func GoTypeDef(ds design.DataStructure, tabs int, jsonTags, private bool) string
 			return tname[0]
 		}
 	}
+	if tname, _ := ExternalType(def); tname != "" {
+		return tname
+	}
 	t := def.Type
 	switch actual := t.(type) {
 	case design.Primitive:
@@ -139,6 +150,8 @@ func goTypeDefObject(obj design.Object, def *design.AttributeDefinition, tabs in
 // attributeTags computes the struct field tags.
 func attributeTags(parent, att *design.AttributeDefinition, name string, private bool) string {
 	var elems []string
+	extra := parent.Metadata["struct:tags"]
+	overrides := make(map[string]string)
 	keys := make([]string, len(att.Metadata))
 	i := 0
 	for k := range att.Metadata {
@@ -151,19 +164,76 @@ func attributeTags(parent, att *design.AttributeDefinition, name string, private
 		if strings.HasPrefix(key, "struct:tag:") {
 			name := key[11:]
 			value := strings.Join(val, ",")
+			if contains(extra, name) {
+				overrides[name] = value
+				continue
+			}
 			elems = append(elems, fmt.Sprintf("%s:\"%s\"", name, value))
 		}
 	}
-	if len(elems) > 0 {
-		return " `" + strings.Join(elems, " ") + "`"
-	}
 	// Default algorithm
 	var omit string
-	if private || (!parent.IsRequired(name) && !parent.HasDefaultValue(name)) {
+	if omitEmpty(parent, att, name, private) {
 		omit = ",omitempty"
 	}
-	return fmt.Sprintf(" `form:\"%s%s\" json:\"%s%s\" yaml:\"%s%s\" xml:\"%s%s\"`",
-		name, omit, name, omit, name, omit, name, omit)
+	if len(elems) == 0 {
+		elems = append(elems, fmt.Sprintf("form:\"%s%s\" json:\"%s%s\" yaml:\"%s%s\" xml:\"%s%s\"",
+			name, omit, name, omit, name, omit, name, omit))
+	}
+	for _, tag := range extra {
+		value, ok := overrides[tag]
+		if !ok {
+			value = extraTagValue(tag, name, omit)
+		}
+		elems = append(elems, fmt.Sprintf("%s:\"%s\"", tag, value))
+	}
+	if _, ok := att.Metadata["struct:tag:validate"]; ValidateTags && !private && !ok {
+		if value := validateTag(parent, att, name); value != "" {
+			elems = append(elems, fmt.Sprintf("validate:\"%s\"", value))
+		}
+	}
+	return " `" + strings.Join(elems, " ") + "`"
+}
+
+// omitEmpty returns true if the default struct field tags of the attribute with the given name use
+// omitempty. The "struct:field:omitempty" metadata set on the attribute or on its parent overrides
+// the default which is to omit the empty values of the optional attributes that are not nullable.
+func omitEmpty(parent, att *design.AttributeDefinition, name string, private bool) bool {
+	if private {
+		return true
+	}
+	for _, a := range []*design.AttributeDefinition{att, parent} {
+		if v, ok := a.Metadata["struct:field:omitempty"]; ok {
+			return len(v) == 0 || v[0] != "false"
+		}
+	}
+	if att.IsNullable() {
+		return false
+	}
+	return !parent.IsRequired(name) && !parent.HasDefaultValue(name)
+}
+
+// extraTagValue returns the default value of the additional struct tag with the given name listed
+// in the "struct:tags" metadata of a type.
+func extraTagValue(tag, name, omit string) string {
+	switch tag {
+	case "gorm":
+		return "column:" + name
+	case "db":
+		return name
+	default:
+		return name + omit
+	}
+}
+
+// contains returns true if the given slice contains the given string.
+func contains(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
 }
 
 // GoTypeRef returns the Go code that refers to the Go type which matches the given data type
@@ -212,6 +282,9 @@ func GoTypeName(t design.DataType, required []string, tabs int, private bool) st
 			GoTypeRef(actual.ElemType.Type, actual.ElemType.AllRequired(), tabs+1, private),
 		)
 	case *design.UserTypeDefinition:
+		if tname, _ := ExternalType(actual.AttributeDefinition); tname != "" {
+			return tname
+		}
 		return Goify(actual.TypeName, !private)
 	case *design.MediaTypeDefinition:
 		if actual.IsError() {
@@ -223,6 +296,28 @@ func GoTypeName(t design.DataType, required []string, tabs int, private bool) st
 	}
 }
 
+// ExternalType returns the name and the import path of the existing Go type that the given
+// attribute maps to via the "struct:type" metadata set on the attribute or on its user type, e.g.
+// "pkg.Money" and "github.com/org/pkg" for "github.com/org/pkg.Money". ExternalType returns empty
+// strings if the attribute does not map to an existing Go type.
+func ExternalType(att *design.AttributeDefinition) (string, string) {
+	tname := att.Metadata[ExternalTypeKey]
+	if len(tname) == 0 {
+		if ut, ok := att.Type.(*design.UserTypeDefinition); ok {
+			tname = ut.Metadata[ExternalTypeKey]
+		}
+	}
+	if len(tname) == 0 || tname[0] == "" {
+		return "", ""
+	}
+	dot := strings.LastIndex(tname[0], ".")
+	if dot <= 0 {
+		return tname[0], ""
+	}
+	path := tname[0][:dot]
+	return tname[0][strings.LastIndex(path, "/")+1:], path
+}
+
 // GoNativeType returns the Go built-in type from which instances of t can be initialized.
 func GoNativeType(t design.DataType) string {
 	switch actual := t.(type) {
@@ -234,7 +329,7 @@ func GoNativeType(t design.DataType) string {
 			return "int"
 		case design.NumberKind:
 			return "float64"
-		case design.StringKind:
+		case design.StringKind, design.DecimalKind:
 			return "string"
 		case design.DateTimeKind:
 			return "time.Time"
@@ -245,6 +340,9 @@ func GoNativeType(t design.DataType) string {
 		case design.FileKind:
 			return "multipart.FileHeader"
 		default:
+			if c := design.CustomPrimitive(actual); c != nil {
+				return c.GoType
+			}
 			panic(fmt.Sprintf("goa bug: unknown primitive type %#v", actual))
 		}
 	case *design.Array:
@@ -262,6 +360,33 @@ func GoNativeType(t design.DataType) string {
 	}
 }
 
+// PrimitiveParser returns the Go expression that parses the string value held by the variable
+// named val into a value of the custom primitive t. The expression evaluates to the parsed value
+// and an error.
+func PrimitiveParser(t design.DataType, val string) string {
+	c := design.CustomPrimitive(t)
+	if c == nil {
+		panic(fmt.Sprintf("goa bug: %s is not a custom primitive", t.Name()))
+	}
+	if c.ParseFunc != "" {
+		return fmt.Sprintf("%s(%s)", c.ParseFunc, val)
+	}
+	return fmt.Sprintf("func(s string) (v %s, err error) { err = v.UnmarshalText([]byte(s)); return }(%s)", c.GoType, val)
+}
+
+// PrimitiveFormatter returns the Go expression that formats the value of the custom primitive t
+// held by the variable named val into a string.
+func PrimitiveFormatter(t design.DataType, val string) string {
+	c := design.CustomPrimitive(t)
+	if c == nil {
+		panic(fmt.Sprintf("goa bug: %s is not a custom primitive", t.Name()))
+	}
+	if c.FormatFunc != "" {
+		return fmt.Sprintf("%s(%s)", c.FormatFunc, val)
+	}
+	return fmt.Sprintf("fmt.Sprintf(\"%%v\", %s)", val)
+}
+
 // GoTypeDesc returns the description of a type.  If no description is defined
 // for the type, one will be generated.
 func GoTypeDesc(t design.DataType, upper bool) string {
@@ -181,11 +181,17 @@ var _ = Describe("code generation", func() {
 			var att *AttributeDefinition
 			var object Object
 			var required *dslengine.ValidationDefinition
+			var metadata dslengine.MetadataDefinition
 			var st string
 
+			BeforeEach(func() {
+				metadata = nil
+			})
+
 			JustBeforeEach(func() {
 				att = new(AttributeDefinition)
 				att.Type = object
+				att.Metadata = metadata
 				if required != nil {
 					att.Validation = required
 				}
@@ -241,6 +247,82 @@ var _ = Describe("code generation", func() {
 					})
 				})
 
+				Context("using additional struct tags metadata", func() {
+					BeforeEach(func() {
+						metadata = dslengine.MetadataDefinition{"struct:tags": []string{"gorm", "db", "bson"}}
+						required = &dslengine.ValidationDefinition{Required: []string{"bar"}}
+					})
+
+					AfterEach(func() {
+						required = nil
+					})
+
+					It("adds the struct tags", func() {
+						Ω(st).Should(ContainSubstring("	Bar string `form:\"bar\" json:\"bar\" yaml:\"bar\" xml:\"bar\" gorm:\"column:bar\" db:\"bar\" bson:\"bar\"`\n"))
+						Ω(st).Should(ContainSubstring("	Foo *int `form:\"foo,omitempty\" json:\"foo,omitempty\" yaml:\"foo,omitempty\" xml:\"foo,omitempty\" gorm:\"column:foo\" db:\"foo\" bson:\"foo,omitempty\"`\n"))
+					})
+
+					Context("with an attribute overriding an additional tag", func() {
+						BeforeEach(func() {
+							object["foo"].Metadata = dslengine.MetadataDefinition{"struct:tag:gorm": []string{"primaryKey"}}
+						})
+
+						AfterEach(func() {
+							object["foo"].Metadata = nil
+						})
+
+						It("keeps the other tags", func() {
+							Ω(st).Should(ContainSubstring("	Foo *int `form:\"foo,omitempty\" json:\"foo,omitempty\" yaml:\"foo,omitempty\" xml:\"foo,omitempty\" gorm:\"primaryKey\" db:\"foo\" bson:\"foo,omitempty\"`\n"))
+						})
+					})
+				})
+
+				Context("with validate tags", func() {
+					BeforeEach(func() {
+						codegen.ValidateTags = true
+						min, maxLength := 1.0, 10
+						object["foo"].Validation = &dslengine.ValidationDefinition{Minimum: &min}
+						object["bar"].Validation = &dslengine.ValidationDefinition{
+							Values:    []interface{}{"a", "b"},
+							MaxLength: &maxLength,
+						}
+						object["quz"].Validation = &dslengine.ValidationDefinition{Pattern: "^a"}
+						required = &dslengine.ValidationDefinition{Required: []string{"bar", "quz"}}
+					})
+
+					AfterEach(func() {
+						codegen.ValidateTags = false
+						required = nil
+					})
+
+					It("adds the validate tags", func() {
+						Ω(st).Should(ContainSubstring("	Bar string `form:\"bar\" json:\"bar\" yaml:\"bar\" xml:\"bar\" validate:\"oneof=a b,max=10\"`\n"))
+						Ω(st).Should(ContainSubstring("	Foo *int `form:\"foo,omitempty\" json:\"foo,omitempty\" yaml:\"foo,omitempty\" xml:\"foo,omitempty\" validate:\"omitempty,min=1\"`\n"))
+						Ω(st).Should(ContainSubstring("	Quz interface{} `form:\"quz\" json:\"quz\" yaml:\"quz\" xml:\"quz\" validate:\"required\"`\n"))
+						Ω(st).Should(ContainSubstring("	Baz *time.Time `form:\"baz,omitempty\" json:\"baz,omitempty\" yaml:\"baz,omitempty\" xml:\"baz,omitempty\"`\n"))
+					})
+				})
+
+				Context("with nullable attributes", func() {
+					BeforeEach(func() {
+						object["bar"].SetNullable()
+						object["foo"].Metadata = dslengine.MetadataDefinition{
+							"struct:field:omitempty": []string{"false"},
+						}
+						required = &dslengine.ValidationDefinition{Required: []string{"bar"}}
+					})
+
+					AfterEach(func() {
+						required = nil
+					})
+
+					It("produces pointer fields encoding nil as null", func() {
+						Ω(st).Should(ContainSubstring("	Bar *string `form:\"bar\" json:\"bar\" yaml:\"bar\" xml:\"bar\"`\n"))
+						Ω(st).Should(ContainSubstring("	Foo *int `form:\"foo\" json:\"foo\" yaml:\"foo\" xml:\"foo\"`\n"))
+						Ω(st).Should(ContainSubstring("	Baz *time.Time `form:\"baz,omitempty\" json:\"baz,omitempty\" yaml:\"baz,omitempty\" xml:\"baz,omitempty\"`\n"))
+					})
+				})
+
 				Context("using struct field name metadata", func() {
 					BeforeEach(func() {
 						object["foo"].Metadata = dslengine.MetadataDefinition{
@@ -279,6 +361,35 @@ var _ = Describe("code generation", func() {
 					})
 				})
 
+				Context("using struct type metadata", func() {
+					BeforeEach(func() {
+						money := &UserTypeDefinition{
+							AttributeDefinition: &AttributeDefinition{
+								Type: Object{"amount": &AttributeDefinition{Type: Integer}},
+								Metadata: dslengine.MetadataDefinition{
+									"struct:type": []string{"github.com/org/pkg.Money"},
+								},
+							},
+							TypeName: "Money",
+						}
+						object["foo"] = &AttributeDefinition{Type: money}
+						object["qux"].Metadata = dslengine.MetadataDefinition{
+							"struct:type": []string{"github.com/org/pkg.ID"},
+						}
+					})
+
+					It("uses the existing Go types", func() {
+						expected := "struct {\n" +
+							"	Bar *string `form:\"bar,omitempty\" json:\"bar,omitempty\" yaml:\"bar,omitempty\" xml:\"bar,omitempty\"`\n" +
+							"	Baz *time.Time `form:\"baz,omitempty\" json:\"baz,omitempty\" yaml:\"baz,omitempty\" xml:\"baz,omitempty\"`\n" +
+							"	Foo *pkg.Money `form:\"foo,omitempty\" json:\"foo,omitempty\" yaml:\"foo,omitempty\" xml:\"foo,omitempty\"`\n" +
+							"	Qux *pkg.ID `form:\"qux,omitempty\" json:\"qux,omitempty\" yaml:\"qux,omitempty\" xml:\"qux,omitempty\"`\n" +
+							"	Quz interface{} `form:\"quz,omitempty\" json:\"quz,omitempty\" yaml:\"quz,omitempty\" xml:\"quz,omitempty\"`\n" +
+							"}"
+						Ω(st).Should(Equal(expected))
+					})
+				})
+
 				Context("that are required", func() {
 					BeforeEach(func() {
 						required = &dslengine.ValidationDefinition{
@@ -745,6 +856,40 @@ var _ = Describe("GoTypeTransform", func() {
 	})
 })
 
+var customDuration = RegisterPrimitive(MinCustomKind, "duration", "time.Duration", "integer")
+
+var _ = Describe("custom primitives", func() {
+	BeforeEach(func() {
+		CustomPrimitive(customDuration).ParseFunc = ""
+	})
+
+	It("maps to the registered Go type", func() {
+		Ω(codegen.GoNativeType(customDuration)).Should(Equal("time.Duration"))
+		Ω(codegen.GoTypeRef(customDuration, nil, 0, false)).Should(Equal("time.Duration"))
+	})
+
+	It("parses values with UnmarshalText by default", func() {
+		Ω(codegen.PrimitiveParser(customDuration, "raw")).Should(Equal(
+			"func(s string) (v time.Duration, err error) { err = v.UnmarshalText([]byte(s)); return }(raw)"))
+	})
+
+	It("parses values with the registered parse function", func() {
+		CustomPrimitive(customDuration).ParseFunc = "time.ParseDuration"
+		Ω(codegen.PrimitiveParser(customDuration, "raw")).Should(Equal("time.ParseDuration(raw)"))
+	})
+
+	It("formats values", func() {
+		Ω(codegen.PrimitiveFormatter(customDuration, "d")).Should(Equal(`fmt.Sprintf("%v", d)`))
+	})
+
+	It("adds the Go type package to the imports", func() {
+		att := &AttributeDefinition{Type: Object{"timeout": &AttributeDefinition{Type: customDuration}}}
+		imports := codegen.AttributeImports(att, nil, nil)
+		Ω(imports).Should(HaveLen(1))
+		Ω(imports[0].Path).Should(Equal("time"))
+	})
+})
+
 var _ = Describe("GoTypeDesc", func() {
 	Context("With a type with a description", func() {
 		var description string
@@ -0,0 +1,101 @@
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+)
+
+// validateFormats maps the design formats to the corresponding go-playground/validator tags.
+// Formats that have no equivalent (e.g. "regexp") are not listed.
+var validateFormats = map[string]string{
+	"cidr":      "cidr",
+	"date":      "datetime=2006-01-02",
+	"date-time": "datetime=2006-01-02T15:04:05Z07:00",
+	"email":     "email",
+	"hostname":  "hostname",
+	"ip":        "ip",
+	"ipv4":      "ipv4",
+	"ipv6":      "ipv6",
+	"mac":       "mac",
+	"uri":       "uri",
+}
+
+// validateTag computes the value of the go-playground/validator "validate" struct tag of the
+// field generated for the attribute with the given name. The value is derived from the design
+// validations, validations that cannot be expressed with the validator tags (e.g. patterns) are
+// ignored. validateTag returns the empty string if there is no validation to express.
+func validateTag(parent, att *design.AttributeDefinition, name string) string {
+	rules := validateRules(att)
+	if a := att.Type.ToArray(); a != nil {
+		if elem := validateRules(a.ElemType); len(elem) > 0 {
+			rules = append(append(rules, "dive"), elem...)
+		}
+	}
+	// The validator "required" rule rejects zero values, only use it for fields that may be nil.
+	// Fields holding values of primitive types are never nil and are always validated.
+	nilable := !att.Type.IsPrimitive() || att.Type.Kind() == design.AnyKind || parent.IsPrimitivePointer(name)
+	switch {
+	case nilable && parent.IsRequired(name) && !parent.HasDefaultValue(name) && !att.IsNullable():
+		rules = append([]string{"required"}, rules...)
+	case nilable && len(rules) > 0:
+		rules = append([]string{"omitempty"}, rules...)
+	}
+	return strings.Join(rules, ",")
+}
+
+// validateRules returns the validator rules that express the validations of the given attribute
+// value.
+func validateRules(att *design.AttributeDefinition) []string {
+	v := att.Validation
+	if v == nil {
+		return nil
+	}
+	if tname, _ := ExternalType(att); tname != "" {
+		return nil
+	}
+	if _, ok := att.Metadata["struct:field:type"]; ok {
+		return nil
+	}
+	var rules []string
+	if len(v.Values) > 0 {
+		if oneof := validateOneOf(v.Values); oneof != "" {
+			rules = append(rules, "oneof="+oneof)
+		}
+	}
+	if tag, ok := validateFormats[v.Format]; ok {
+		rules = append(rules, tag)
+	}
+	// The validator min and max rules check the length of strings, decimals cannot be checked.
+	if k := att.Type.Kind(); k == design.IntegerKind || k == design.NumberKind {
+		if v.Minimum != nil {
+			rules = append(rules, "min="+strconv.FormatFloat(*v.Minimum, 'f', -1, 64))
+		}
+		if v.Maximum != nil {
+			rules = append(rules, "max="+strconv.FormatFloat(*v.Maximum, 'f', -1, 64))
+		}
+	}
+	if v.MinLength != nil {
+		rules = append(rules, "min="+strconv.Itoa(*v.MinLength))
+	}
+	if v.MaxLength != nil {
+		rules = append(rules, "max="+strconv.Itoa(*v.MaxLength))
+	}
+	return rules
+}
+
+// validateOneOf returns the list of values used by the validator "oneof" rule, the empty string
+// if a value cannot be listed (e.g. because it contains a space).
+func validateOneOf(values []interface{}) string {
+	vals := make([]string, len(values))
+	for i, v := range values {
+		s := fmt.Sprint(v)
+		if s == "" || strings.ContainsAny(s, " ,|`\"") {
+			return ""
+		}
+		vals[i] = s
+	}
+	return strings.Join(vals, " ")
+}
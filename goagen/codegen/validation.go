@@ -18,6 +18,7 @@ var (
 	minMaxValT   *template.Template
 	lengthValT   *template.Template
 	requiredValT *template.Template
+	decimalValT  *template.Template
 )
 
 //  init instantiates the templates.
@@ -49,6 +50,9 @@ func init() {
 	if requiredValT, err = template.New("required").Funcs(fm).Parse(requiredValTmpl); err != nil {
 		panic(err)
 	}
+	if decimalValT, err = template.New("decimal").Funcs(fm).Parse(decimalValTmpl); err != nil {
+		panic(err)
+	}
 }
 
 // Validator is the code generator for the 'Validate' type methods.
@@ -260,6 +264,10 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 		hasValidations := false
 		done := errors.New("done")
 		ds.Walk(func(a *design.AttributeDefinition) error {
+			if isStringDecimal(a) {
+				hasValidations = true
+				return done
+			}
 			if a.Validation != nil {
 				if private {
 					hasValidations = true
@@ -324,11 +332,11 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 // error. It initializes that variable in case a validation fails.
 // Note: we do not want to recurse here, recursion is done by the marshaler/unmarshaler code.
 func ValidationChecker(att *design.AttributeDefinition, nonzero, required, hasDefault bool, target, context string, depth int, private bool) string {
-	if att.Validation == nil {
+	if att.Validation == nil && !isStringDecimal(att) {
 		return ""
 	}
 	t := target
-	isPointer := private || (!required && !hasDefault && !nonzero)
+	isPointer := private || (!required && !hasDefault && !nonzero) || (att.IsNullable() && att.Type.Kind() != design.AnyKind)
 	if isPointer && att.Type.IsPrimitive() {
 		t = "*" + t
 	}
@@ -350,7 +358,24 @@ func ValidationChecker(att *design.AttributeDefinition, nonzero, required, hasDe
 }
 
 func validationsCode(att *design.AttributeDefinition, data map[string]interface{}) (res []string) {
+	if isStringDecimal(att) {
+		precision, scale := -1, -1
+		if att.Validation != nil && att.Validation.Precision != nil {
+			precision = *att.Validation.Precision
+		}
+		if att.Validation != nil && att.Validation.Scale != nil {
+			scale = *att.Validation.Scale
+		}
+		data["precision"] = precision
+		data["scale"] = scale
+		if val := RunTemplate(decimalValT, data); val != "" {
+			res = append(res, val)
+		}
+	}
 	validation := att.Validation
+	if validation == nil {
+		return
+	}
 	if values := validation.Values; values != nil {
 		data["values"] = values
 		if val := RunTemplate(enumValT, data); val != "" {
@@ -423,6 +448,17 @@ func validationsCode(att *design.AttributeDefinition, data map[string]interface{
 	return
 }
 
+// isStringDecimal returns true if att is a Decimal attribute whose Go type is the default string.
+// Decimal attributes that use a custom Go type (via the "struct:field:type" metadata) rely on that
+// type to validate values on unmarshal.
+func isStringDecimal(att *design.AttributeDefinition) bool {
+	if att.Type.Kind() != design.DecimalKind {
+		return false
+	}
+	_, ok := att.Metadata["struct:field:type"]
+	return !ok
+}
+
 // renderInteger renders a max or min value properly, taking into account
 // overflows due to casting from a float value.
 func renderInteger(f float64) string {
@@ -510,6 +546,13 @@ const (
 {{ end }}{{ tabs $depth }}if err2 := goa.ValidateFormat({{ constant .format }}, {{ .targetVal }}); err2 != nil {
 {{ tabs $depth }}		err = goa.MergeErrors(err, goa.InvalidFormatError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ constant .format }}, err2))
 {{ if .isPointer }}{{ tabs $depth }}}
+{{ end }}{{ tabs .depth }}}`
+
+	decimalValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
+*/}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
+{{ end }}{{ tabs $depth }}if err2 := goa.ValidateDecimal({{ .targetVal }}, {{ .precision }}, {{ .scale }}); err2 != nil {
+{{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidFormatError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, goa.FormatDecimal, err2))
+{{ if .isPointer }}{{ tabs $depth }}}
 {{ end }}{{ tabs .depth }}}`
 
 	minMaxValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
@@ -528,7 +571,8 @@ const (
 {{end}}{{tabs .depth}}}`
 
 	requiredValTmpl = `{{ $att := index $.attribute.Type.ToObject .required }}{{/*
-*/}}{{ if and (not $.private) (eq $att.Type.Kind 4) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == "" {
+*/}}{{ if $att.IsNullable }}{{/* null values of nullable attributes cannot be told from absent values
+*/}}{{ else if and (not $.private) (eq $att.Type.Kind 4) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == "" {
 {{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{  .required  }}"))
 {{ tabs $.depth }}}{{ else if or $.private (not $att.Type.IsPrimitive) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == nil {
 {{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{ .required }}"))
@@ -119,6 +119,21 @@ var _ = Describe("validation code generation", func() {
 				})
 			})
 
+			Context("of decimal precision and scale", func() {
+				BeforeEach(func() {
+					attType = design.Decimal
+					precision, scale := 10, 2
+					validation = &dslengine.ValidationDefinition{
+						Precision: &precision,
+						Scale:     &scale,
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(decimalValCode))
+				})
+			})
+
 			Context("of array elements", func() {
 				BeforeEach(func() {
 					attType = &design.Array{
@@ -349,6 +364,12 @@ const (
 		}
 	}`
 
+	decimalValCode = `	if val != nil {
+		if err2 := goa.ValidateDecimal(*val, 10, 2); err2 != nil {
+			err = goa.MergeErrors(err, goa.InvalidFormatError(` + "`context`" + `, *val, goa.FormatDecimal, err2))
+		}
+	}`
+
 	minValCode = `	if val != nil {
 		if *val < 0 {
 			err = goa.MergeErrors(err, goa.InvalidRangeError(` + "`" + `context` + "`" + `, *val, 0, true))
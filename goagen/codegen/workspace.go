@@ -17,6 +17,7 @@ import (
 	"strconv"
 	"strings"
 	"text/template"
+	"unicode/utf8"
 
 	"github.com/kyokomi/goa-v1/version"
 
@@ -203,6 +204,7 @@ func (p *Package) Abs() string {
 // CreateSourceFile creates a Go source file in the given package. If the file
 // already exists it is overwritten.
 func (p *Package) CreateSourceFile(name string) (*SourceFile, error) {
+	waitFormatted(filepath.Join(p.Abs(), name))
 	os.RemoveAll(filepath.Join(p.Abs(), name))
 	return p.OpenSourceFile(name)
 }
@@ -211,6 +213,7 @@ func (p *Package) CreateSourceFile(name string) (*SourceFile, error) {
 // exist OpenSourceFile creates it.
 func (p *Package) OpenSourceFile(name string) (*SourceFile, error) {
 	f := &SourceFile{Name: name, Package: p}
+	waitFormatted(f.Abs())
 	file, err := os.OpenFile(f.Abs(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
 		return nil, err
@@ -283,8 +286,18 @@ func (f *SourceFile) Close() {
 	}
 }
 
-// FormatCode performs the equivalent of "goimports -w" on the source file.
+// FormatCode performs the equivalent of "goimports -w" on the source file. The file is formatted
+// in the background if FormatConcurrently was called, see WaitFormat.
 func (f *SourceFile) FormatCode() error {
+	if fm := bgFormatter; fm != nil {
+		fm.queue(f.Abs(), f.formatCode)
+		return nil
+	}
+	return f.formatCode()
+}
+
+// formatCode implements FormatCode.
+func (f *SourceFile) formatCode() error {
 	// Parse file into AST
 	fset := token.NewFileSet()
 	file, err := parser.ParseFile(fset, f.Abs(), nil, parser.ParseComments)
@@ -299,7 +312,7 @@ func (f *SourceFile) FormatCode() error {
 	for _, group := range imports {
 		for _, imp := range group {
 			path := strings.Trim(imp.Path.Value, `"`)
-			if !astutil.UsesImport(file, path) {
+			if !usesImport(file, imp, path) {
 				if imp.Name != nil {
 					astutil.DeleteNamedImport(fset, file, imp.Name.Name, path)
 				} else {
@@ -314,9 +327,55 @@ func (f *SourceFile) FormatCode() error {
 	if err != nil {
 		return err
 	}
-	defer w.Close()
 	// Write formatted code without unused imports
-	return format.Node(w, fset, file)
+	if err := format.Node(w, fset, file); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	KeepModTime(f.Abs())
+	return nil
+}
+
+// usesImport returns true if the file uses the given import. The package name of unnamed imports
+// is assumed from the import path like goimports does, e.g. "goa" for
+// "github.com/kyokomi/goa-v1" where astutil.UsesImport would assume "goa-v1".
+func usesImport(file *ast.File, imp *ast.ImportSpec, path string) bool {
+	if imp.Name != nil {
+		return astutil.UsesImport(file, path)
+	}
+	name := assumedPackageName(path)
+	used := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Name == name && id.Obj == nil {
+				used = true
+			}
+		}
+		return !used
+	})
+	return used
+}
+
+// assumedPackageName returns the package name assumed for the given import path: the last
+// element of the path without the major version suffix, the "go-" prefix and anything after the
+// first character that is not valid in an identifier.
+func assumedPackageName(path string) string {
+	base := filepath.Base(path)
+	if strings.HasPrefix(base, "v") && strings.Contains(path, "/") {
+		if _, err := strconv.Atoi(base[1:]); err == nil {
+			base = filepath.Base(filepath.Dir(path))
+		}
+	}
+	base = strings.TrimPrefix(base, "go-")
+	if i := strings.IndexFunc(base, func(r rune) bool {
+		return !(r == '_' || r >= utf8.RuneSelf || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	}); i >= 0 {
+		base = base[:i]
+	}
+	return base
 }
 
 // Abs returne the source file absolute filename
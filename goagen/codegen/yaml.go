@@ -0,0 +1,96 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+)
+
+// JSONToYAML converts the given JSON document to YAML. The keys of the YAML mappings are sorted
+// using natural ordering (digit sequences compare numerically). The yaml package sorts map keys
+// using a comparison that is not transitive for keys that only differ by their numbers (e.g.
+// "Res2Payload", "Res3Payload" and "Res20Payload") which makes the output depend on the map
+// iteration order, JSONToYAML sorts the keys itself so that the output is stable.
+func JSONToYAML(rawJSON []byte) ([]byte, error) {
+	var yamlSource interface{}
+	if err := yaml.Unmarshal(rawJSON, &yamlSource); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(sortedYAML(yamlSource))
+}
+
+// sortedYAML recursively replaces the maps contained in v with map slices sorted by keys.
+func sortedYAML(v interface{}) interface{} {
+	switch actual := v.(type) {
+	case map[interface{}]interface{}:
+		items := make(yaml.MapSlice, 0, len(actual))
+		for k, val := range actual {
+			items = append(items, yaml.MapItem{Key: k, Value: sortedYAML(val)})
+		}
+		sort.Slice(items, func(i, j int) bool {
+			return naturalLess(fmt.Sprint(items[i].Key), fmt.Sprint(items[j].Key))
+		})
+		return items
+	case []interface{}:
+		res := make([]interface{}, len(actual))
+		for i, val := range actual {
+			res[i] = sortedYAML(val)
+		}
+		return res
+	default:
+		return v
+	}
+}
+
+// naturalLess reports whether a sorts before b. Sequences of digits compare numerically,
+// non-letters sort before letters and strings that compare equal otherwise are ordered
+// lexically.
+func naturalLess(a, b string) bool {
+	ar, br := []rune(a), []rune(b)
+	i, j := 0, 0
+	for i < len(ar) && j < len(br) {
+		if unicode.IsDigit(ar[i]) && unicode.IsDigit(br[j]) {
+			ai, bj := digitsEnd(ar, i), digitsEnd(br, j)
+			an, bn := trimZeros(ar[i:ai]), trimZeros(br[j:bj])
+			if len(an) != len(bn) {
+				return len(an) < len(bn)
+			}
+			if s, t := string(an), string(bn); s != t {
+				return s < t
+			}
+			i, j = ai, bj
+			continue
+		}
+		if ar[i] != br[j] {
+			al, bl := unicode.IsLetter(ar[i]), unicode.IsLetter(br[j])
+			if al != bl {
+				return bl
+			}
+			return ar[i] < br[j]
+		}
+		i++
+		j++
+	}
+	if len(ar)-i != len(br)-j {
+		return len(ar)-i < len(br)-j
+	}
+	return a < b
+}
+
+// digitsEnd returns the index of the first non-digit rune in r starting at i.
+func digitsEnd(r []rune, i int) int {
+	for i < len(r) && unicode.IsDigit(r[i]) {
+		i++
+	}
+	return i
+}
+
+// trimZeros removes the leading zeros of the given digits.
+func trimZeros(digits []rune) []rune {
+	for len(digits) > 1 && digits[0] == '0' {
+		digits = digits[1:]
+	}
+	return digits
+}
@@ -0,0 +1,40 @@
+package codegen_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+var _ = Describe("JSONToYAML", func() {
+	var rawJSON string
+	var rawYAML []byte
+	var err error
+
+	JustBeforeEach(func() {
+		rawYAML, err = codegen.JSONToYAML([]byte(rawJSON))
+	})
+
+	Context("with keys that differ by their numbers", func() {
+		BeforeEach(func() {
+			rawJSON = `{"Res20Payload":1,"Res3Payload":2,"Res2Payload":3,"Res10":4,"Res02Payload":5,"Res":6}`
+		})
+
+		It("sorts the keys using natural ordering", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(rawYAML)).Should(Equal("Res: 6\nRes02Payload: 5\nRes2Payload: 3\nRes3Payload: 2\nRes10: 4\nRes20Payload: 1\n"))
+		})
+	})
+
+	Context("with nested mappings", func() {
+		BeforeEach(func() {
+			rawJSON = `{"paths":{"/b":{"get":{}},"/a":[{"z":1,"y":2}]},"info":{"title":"t"}}`
+		})
+
+		It("sorts the keys of all the mappings", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(rawYAML)).Should(Equal("info:\n  title: t\npaths:\n  /a:\n  - \"y\": 2\n    z: 1\n  /b:\n    get: {}\n"))
+		})
+	})
+})
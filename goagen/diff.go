@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/spf13/cobra"
+)
+
+// runDiff implements the "diff" command. The --against flag is either the path to a snapshot
+// written with --snapshot or a git revision. In the latter case the design package files are
+// extracted from the revision into a temporary package whose snapshot is compared with the
+// current design. Only the files of the design package are extracted, the packages it imports
+// are used at their current version.
+func runDiff(c *cobra.Command) ([]string, error) {
+	against := c.Flag("against").Value.String()
+	if against == "" || c.Flag("snapshot").Value.String() != "" {
+		return run("gendiff", c)
+	}
+	if fi, err := os.Stat(against); err == nil && !fi.IsDir() {
+		return run("gendiff", c)
+	}
+	designPkg := c.Flag("design").Value.String()
+	if designPkg == "" {
+		return nil, fmt.Errorf("missing design package path, specify it with --design")
+	}
+	dir, err := codegen.PackageSourcePath(designPkg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid design package import path: %s", err)
+	}
+	tmpDir, err := extractDesign(dir, against)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	tmpPkg, err := codegen.PackagePath(tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	f, err := ioutil.TempFile("", "goagen_snapshot")
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	snapshot := f.Name()
+	defer os.Remove(snapshot)
+
+	// Snapshot the design extracted from the revision then compare the current design with it.
+	defer c.Flags().Set("against", against)
+	defer c.Flags().Set("design", designPkg)
+	c.Flags().Set("design", tmpPkg)
+	c.Flags().Set("snapshot", snapshot)
+	if _, err := run("gendiff", c); err != nil {
+		return nil, fmt.Errorf("failed to evaluate design at %s: %s", against, err)
+	}
+	c.Flags().Set("design", designPkg)
+	c.Flags().Set("snapshot", "")
+	c.Flags().Set("against", snapshot)
+	return run("gendiff", c)
+}
+
+// extractDesign copies the Go files of the design package located in dir as of the given git
+// revision into a new temporary directory created under dir so that the package resolves its
+// imports the same way. It returns the path to the temporary directory.
+func extractDesign(dir, rev string) (string, error) {
+	out, err := git(dir, "ls-tree", "--name-only", rev, "./")
+	if err != nil {
+		return "", fmt.Errorf("%#v is neither a snapshot file nor a git revision: %s", rev, err)
+	}
+	var names []string
+	for _, name := range strings.Split(out, "\n") {
+		if strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no design file found in %s at %s", dir, rev)
+	}
+	tmpDir, err := ioutil.TempDir(dir, "goagen_against")
+	if err != nil {
+		return "", err
+	}
+	for _, name := range names {
+		content, err := git(dir, "show", rev+":./"+name)
+		if err == nil {
+			err = ioutil.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644)
+		}
+		if err != nil {
+			os.RemoveAll(tmpDir)
+			return "", err
+		}
+	}
+	return tmpDir, nil
+}
+
+// git runs the git command with the given arguments in dir and returns its standard output.
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return "", fmt.Errorf("%s", strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,209 @@
+package genapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+// BenchTarget describes the benchmark generated for an action.
+type BenchTarget struct {
+	Name         string        // Name of the benchmark function
+	ResourceName string        // Name of the resource in the design
+	ActionName   string        // Name of the action in the design
+	Context      string        // Name of the action context type
+	Unmarshal    string        // Name of the payload unmarshal function, empty if no body is sent
+	PayloadType  string        // Go type of the payload
+	Body         string        // Go expression of the example request body
+	Method       string        // HTTP method of the requests
+	Path         string        // Path of the requests
+	Params       []*BenchInput // Example path and query string parameters
+	Headers      []*BenchInput // Example request headers
+	Status       int           // Status code of the response
+	ResponseType string        // Go type of the response media type, empty if the response has no body
+	ResponseBody string        // Go expression of the JSON example response body
+}
+
+// BenchInput describes a request parameter or header sent by a benchmark.
+type BenchInput struct {
+	Name  string // Name of the parameter or header
+	Value string // Go expression of the example value
+}
+
+// generateBenchmarks generates the benchmarks that measure the request decoding, validation and
+// response encoding code generated for each action.
+func (g *Generator) generateBenchmarks() (err error) {
+	var targets []*BenchTarget
+	rand := g.API.RandomGenerator()
+	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if len(a.Routes) == 0 || a.WebSocket() {
+				return nil
+			}
+			t, err := g.benchTarget(r, a, rand)
+			if err != nil {
+				return err
+			}
+			if t != nil {
+				targets = append(targets, t)
+			}
+			return nil
+		})
+	})
+	if err != nil || len(targets) == 0 {
+		return
+	}
+
+	benchFile := filepath.Join(g.OutDir, "bench_test.go")
+	file, err := codegen.SourceFileFor(benchFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Action Benchmarks", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("bytes"),
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("net/http/httptest"),
+		codegen.SimpleImport("net/url"),
+		codegen.SimpleImport("testing"),
+		codegen.NewImport("goa", "github.com/kyokomi/goa-v1"),
+	}
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, benchFile)
+
+	return file.ExecuteTemplate("bench", codegen.Template("app", "bench", benchT), nil, targets)
+}
+
+// benchTarget builds the benchmark of the given action. The requests and responses are the
+// examples generated from the design, the response is the success response with the lowest
+// status code. benchTarget returns nil if the action has no success response.
+func (g *Generator) benchTarget(r *design.ResourceDefinition, a *design.ActionDefinition, rand *design.RandomGenerator) (*BenchTarget, error) {
+	var resp *design.ResponseDefinition
+	a.IterateResponses(func(rd *design.ResponseDefinition) error {
+		if rd.Status < 400 && (resp == nil || rd.Status < resp.Status) {
+			resp = rd
+		}
+		return nil
+	})
+	if resp == nil {
+		return nil, nil
+	}
+	actionName := codegen.Goify(a.Name, true)
+	resName := codegen.Goify(r.Name, true)
+	t := &BenchTarget{
+		Name:         "Benchmark" + actionName + resName,
+		ResourceName: r.Name,
+		ActionName:   a.Name,
+		Context:      g.contextName(a),
+		Method:       a.Routes[0].Verb,
+		Path:         a.Routes[0].FullPath(),
+		Status:       resp.Status,
+	}
+	// Multipart bodies have no example
+	if a.Payload != nil && !a.PayloadMultipart {
+		if ex := a.Payload.GenerateExample(rand, nil); ex != nil {
+			b, err := json.Marshal(ex)
+			if err != nil {
+				return nil, fmt.Errorf("%s: payload example: %s", a.Context(), err)
+			}
+			t.Unmarshal = fmt.Sprintf("unmarshal%s%sPayload", actionName, resName)
+			t.PayloadType = codegen.GoTypeRef(a.Payload, nil, 1, false)
+			t.Body = fmt.Sprintf("[]byte(%s)", strconv.Quote(string(b)))
+		}
+	}
+	if params := a.AllParams(); params != nil {
+		obj := params.Type.ToObject()
+		for _, n := range codegen.SortedNames(obj) {
+			t.Params = append(t.Params, &BenchInput{
+				Name:  n,
+				Value: strconv.Quote(exampleString(obj[n].GenerateExample(rand, nil))),
+			})
+		}
+	}
+	headers := &design.AttributeDefinition{Type: design.Object{}}
+	if r.Headers != nil {
+		headers.Merge(r.Headers)
+	}
+	if a.Headers != nil {
+		headers.Merge(a.Headers)
+	}
+	obj := headers.Type.ToObject()
+	for _, n := range codegen.SortedNames(obj) {
+		t.Headers = append(t.Headers, &BenchInput{
+			Name:  http.CanonicalHeaderKey(n),
+			Value: strconv.Quote(exampleString(obj[n].GenerateExample(rand, nil))),
+		})
+	}
+	if mt := g.API.MediaTypeWithIdentifier(resp.MediaType); mt != nil && !mt.IsError() {
+		view := resp.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return nil, fmt.Errorf("%s: response %s: %s", a.Context(), resp.Name, err)
+		}
+		b, err := json.Marshal(p.GenerateExample(rand, nil))
+		if err != nil {
+			return nil, fmt.Errorf("%s: response %s example: %s", a.Context(), resp.Name, err)
+		}
+		t.ResponseType = codegen.GoTypeName(p, nil, 0, false)
+		t.ResponseBody = fmt.Sprintf("[]byte(%s)", strconv.Quote(string(b)))
+	}
+	return t, nil
+}
+
+// benchT generates the benchmarks.
+// template input: []*BenchTarget
+const benchT = `{{ range . }}
+// {{ .Name }} measures decoding and validating the example request of the {{ .ActionName }}
+// action of the {{ .ResourceName }} resource and encoding the example response.
+func {{ .Name }}(b *testing.B) {
+	service := goa.New("bench")
+	service.Decoder.Register(goa.NewJSONDecoder, "application/json")
+	service.Encoder.Register(goa.NewJSONEncoder, "application/json")
+	initService(service)
+{{ if .Body }}	body := {{ .Body }}
+{{ end }}{{ if .ResponseType }}	resp := new({{ .ResponseType }})
+	if err := json.Unmarshal({{ .ResponseBody }}, resp); err != nil {
+		b.Fatal(err)
+	}
+{{ end }}	params := url.Values{}
+{{ range .Params }}	params.Set({{ printf "%q" .Name }}, {{ .Value }})
+{{ end }}	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest({{ printf "%q" .Method }}, {{ printf "%q" .Path }}, {{ if .Body }}bytes.NewReader(body){{ else }}nil{{ end }})
+{{ if .Body }}		req.Header.Set("Content-Type", "application/json")
+{{ end }}		req.Header.Set("Accept", "application/json")
+{{ range .Headers }}		req.Header[{{ printf "%q" .Name }}] = []string{ {{ .Value }} }
+{{ end }}		ctx := goa.NewContext(context.Background(), httptest.NewRecorder(), req, params)
+{{ if .Body }}		if err := {{ .Unmarshal }}(ctx, service, req); err != nil {
+			b.Fatal(err)
+		}
+{{ end }}		rctx, err := New{{ .Context }}(ctx, req, service)
+		if err != nil {
+			b.Fatal(err)
+		}
+{{ if .Body }}		rctx.Payload = goa.ContextRequest(ctx).Payload.({{ .PayloadType }})
+{{ end }}{{ if .ResponseType }}		if err := rctx.ResponseData.Service.Send(ctx, {{ .Status }}, resp); err != nil {
+			b.Fatal(err)
+		}
+{{ else }}		rctx.ResponseData.WriteHeader({{ .Status }})
+{{ end }}	}
+}
+{{ end }}`
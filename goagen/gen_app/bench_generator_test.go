@@ -0,0 +1,127 @@
+package genapp_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	genapp "github.com/kyokomi/goa-v1/goagen/gen_app"
+)
+
+var _ = Describe("Generate benchmarks", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var bench bool
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+		Ω(err).ShouldNot(HaveOccurred())
+		bench = true
+
+		design.Design = registeredDesign
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {})
+		bottle := apidsl.MediaType("application/vnd.bottle", func() {
+			apidsl.Attributes(func() {
+				apidsl.Attribute("name", design.String, func() {
+					apidsl.Example("Number 8")
+				})
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("name")
+			})
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.BasePath("/bottles")
+			apidsl.Action("update", func() {
+				apidsl.Routing(apidsl.PUT("/:id"))
+				apidsl.Params(func() {
+					apidsl.Param("id", design.Integer, func() {
+						apidsl.Example(1)
+					})
+				})
+				apidsl.Headers(func() {
+					apidsl.Header("x-trace", design.String, func() {
+						apidsl.Example("abc")
+					})
+				})
+				apidsl.Payload(func() {
+					apidsl.Member("name", design.String, func() {
+						apidsl.Example("Number 8")
+					})
+				})
+				apidsl.Response(design.NoContent)
+				apidsl.Response(design.BadRequest)
+			})
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/:id"))
+				apidsl.Response(design.OK, bottle)
+			})
+			apidsl.Action("delete", func() {
+				apidsl.Routing(apidsl.DELETE("/:id"))
+				apidsl.Response(design.NotFound)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genapp.NewGenerator(
+			genapp.API(design.Design),
+			genapp.OutDir(filepath.Join(outDir, "app")),
+			genapp.Target("app"),
+			genapp.NoTest(true),
+			genapp.Bench(bench),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+		delete(codegen.Reserved, "app")
+	})
+
+	It("generates a benchmark per action with a success response", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		benchFile := filepath.Join(outDir, "app", "bench_test.go")
+		Ω(files).Should(ContainElement(benchFile))
+		content, err := ioutil.ReadFile(benchFile)
+		Ω(err).ShouldNot(HaveOccurred())
+		code := string(content)
+		Ω(code).Should(ContainSubstring("func BenchmarkUpdateBottle(b *testing.B) {"))
+		Ω(code).Should(ContainSubstring("func BenchmarkShowBottle(b *testing.B) {"))
+		Ω(code).ShouldNot(ContainSubstring("BenchmarkDeleteBottle"))
+		Ω(code).Should(ContainSubstring(`body := []byte("{\"name\":\"Number 8\"}")`))
+		Ω(code).Should(ContainSubstring(`params.Set("id", "1")`))
+		Ω(code).Should(ContainSubstring(`req.Header["X-Trace"] = []string{"abc"}`))
+		Ω(code).Should(ContainSubstring("if err := unmarshalUpdateBottlePayload(ctx, service, req); err != nil {"))
+		Ω(code).Should(ContainSubstring("rctx, err := NewUpdateBottleContext(ctx, req, service)"))
+		Ω(code).Should(ContainSubstring("rctx.Payload = goa.ContextRequest(ctx).Payload.(*UpdateBottlePayload)"))
+		Ω(code).Should(ContainSubstring("rctx.ResponseData.WriteHeader(204)"))
+		Ω(code).Should(ContainSubstring("resp := new(Bottle)"))
+		Ω(code).Should(ContainSubstring(`json.Unmarshal([]byte("{\"name\":\"Number 8\"}"), resp)`))
+		Ω(code).Should(ContainSubstring("rctx.ResponseData.Service.Send(ctx, 200, resp)"))
+	})
+
+	Context("with benchmarks disabled", func() {
+		BeforeEach(func() {
+			bench = false
+		})
+
+		It("does not generate benchmarks", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(filepath.Join(outDir, "app", "bench_test.go")).ShouldNot(BeAnExistingFile())
+		})
+	})
+})
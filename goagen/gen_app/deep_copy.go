@@ -0,0 +1,327 @@
+package genapp
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+// sensitiveKey is the metadata key that marks the attributes whose values are redacted by the
+// generated String methods.
+const sensitiveKey = "struct:field:sensitive"
+
+// redactedValue is the value that replaces the sensitive string values in the string
+// representations of the generated types.
+const redactedValue = "[REDACTED]"
+
+// copyFuncMap lists the functions used by the "copy_methods" template.
+var copyFuncMap = template.FuncMap{
+	"deepCopier":   deepCopier,
+	"objectCopier": objectCopier,
+	"redacter":     redacter,
+	"redactNested": redactNested,
+	"hasField":     hasField,
+	"hasSensitive": func(att *design.AttributeDefinition) bool { return hasSensitive(att, nil) },
+}
+
+// writeCopyMethods writes the DeepCopy, String and redact methods of the given user or media type.
+// receiver is the name of the method receivers and kind describes the type in the comments.
+func writeCopyMethods(file *codegen.SourceFile, t design.DataStructure, receiver, kind string) error {
+	data := map[string]interface{}{
+		"Type":      t,
+		"Attribute": t.Definition(),
+		"Receiver":  receiver,
+		"Kind":      kind,
+	}
+	return file.ExecuteTemplate("copy", codegen.Template("app", "copy_methods", copyMethodsT), copyFuncMap, data)
+}
+
+// deepCopier produces the code that sets target to a deep copy of source. source and target hold
+// values of the Go type generated for att.
+func deepCopier(att *design.AttributeDefinition, source, target string, depth int) string {
+	if customType(att) != "" {
+		return fmt.Sprintf("%s%s = %s", codegen.Tabs(depth), target, source)
+	}
+	switch actual := att.Type.(type) {
+	case design.Primitive:
+		return fmt.Sprintf("%s%s = %s", codegen.Tabs(depth), target, source)
+	case *design.MediaTypeDefinition:
+		if actual.IsError() {
+			return fmt.Sprintf("%s%s = %s", codegen.Tabs(depth), target, source)
+		}
+		return fmt.Sprintf("%s%s = %s.DeepCopy()", codegen.Tabs(depth), target, source)
+	case *design.UserTypeDefinition:
+		return fmt.Sprintf("%s%s = %s.DeepCopy()", codegen.Tabs(depth), target, source)
+	case design.Object:
+		return fmt.Sprintf("%sif %s != nil {\n%s%s = &%s{}\n%s\n%s}",
+			codegen.Tabs(depth), source,
+			codegen.Tabs(depth+1), target, codegen.GoTypeDef(att, depth+1, true, false),
+			objectCopier(att, source, target, depth+1),
+			codegen.Tabs(depth))
+	case *design.Array:
+		elem := actual.ElemType
+		var body string
+		if elem.Type.IsPrimitive() && customType(elem) == "" {
+			body = fmt.Sprintf("%scopy(%s, %s)", codegen.Tabs(depth+1), target, source)
+		} else {
+			i, e := fmt.Sprintf("i%d", depth), fmt.Sprintf("elem%d", depth)
+			body = fmt.Sprintf("%sfor %s, %s := range %s {\n%s\n%s}",
+				codegen.Tabs(depth+1), i, e, source,
+				deepCopier(elem, e, fmt.Sprintf("%s[%s]", target, i), depth+2),
+				codegen.Tabs(depth+1))
+		}
+		return fmt.Sprintf("%sif %s != nil {\n%s%s = make(%s, len(%s))\n%s\n%s}",
+			codegen.Tabs(depth), source,
+			codegen.Tabs(depth+1), target, codegen.GoTypeDef(att, depth+1, true, false), source,
+			body,
+			codegen.Tabs(depth))
+	case *design.Hash:
+		k, v := fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth)
+		ck, ckCode := hashEntryCopier(actual.KeyType, k, depth+2)
+		cv, cvCode := hashEntryCopier(actual.ElemType, v, depth+2)
+		return fmt.Sprintf("%sif %s != nil {\n%s%s = make(%s, len(%s))\n%sfor %s, %s := range %s {\n%s%s%s%s[%s] = %s\n%s}\n%s}",
+			codegen.Tabs(depth), source,
+			codegen.Tabs(depth+1), target, codegen.GoTypeDef(att, depth+1, true, false), source,
+			codegen.Tabs(depth+1), k, v, source,
+			ckCode, cvCode, codegen.Tabs(depth+2), target, ck, cv,
+			codegen.Tabs(depth+1),
+			codegen.Tabs(depth))
+	}
+	panic(fmt.Sprintf("goa bug: unknown type %#v", att.Type)) // bug
+}
+
+// hashEntryCopier returns the name of the variable holding the copy of the given hash key or
+// value and the code that initializes it.
+func hashEntryCopier(att *design.AttributeDefinition, source string, depth int) (string, string) {
+	if att.Type.IsPrimitive() || externalType(att) != "" {
+		return source, ""
+	}
+	if _, ok := att.Type.(*design.UserTypeDefinition); ok {
+		return source + ".DeepCopy()", ""
+	}
+	if mt, ok := att.Type.(*design.MediaTypeDefinition); ok && !mt.IsError() {
+		return source + ".DeepCopy()", ""
+	}
+	target := "c" + source
+	typedef := codegen.GoTypeDef(att, depth, true, false)
+	if att.Type.IsObject() {
+		typedef = "*" + typedef
+	}
+	return target, fmt.Sprintf("%svar %s %s\n%s\n",
+		codegen.Tabs(depth), target, typedef, deepCopier(att, source, target, depth))
+}
+
+// objectCopier produces the code that copies the fields of the source struct into the target
+// struct.
+func objectCopier(att *design.AttributeDefinition, source, target string, depth int) string {
+	var copies []string
+	if o := att.Type.ToObject(); o != nil {
+		if ds, ok := att.Type.(design.DataStructure); ok {
+			att = ds.Definition()
+		}
+		o.IterateAttributes(func(n string, catt *design.AttributeDefinition) error {
+			field := codegen.GoifyAtt(catt, n, true)
+			src := fmt.Sprintf("%s.%s", source, field)
+			tgt := fmt.Sprintf("%s.%s", target, field)
+			if catt.Type.IsPrimitive() && att.IsPrimitivePointer(n) {
+				copies = append(copies, fmt.Sprintf("%sif %s != nil {\n%sv := *%s\n%s%s = &v\n%s}",
+					codegen.Tabs(depth), src,
+					codegen.Tabs(depth+1), src,
+					codegen.Tabs(depth+1), tgt,
+					codegen.Tabs(depth)))
+				return nil
+			}
+			copies = append(copies, deepCopier(catt, src, tgt, depth))
+			return nil
+		})
+	}
+	return strings.Join(copies, "\n")
+}
+
+// redacter produces the code that replaces the values of the sensitive attributes of the target
+// struct, including the attributes of nested inline structs, arrays and hashes.
+func redacter(att *design.AttributeDefinition, target string, depth int) string {
+	var redactions []string
+	if o := att.Type.ToObject(); o != nil {
+		if ds, ok := att.Type.(design.DataStructure); ok {
+			att = ds.Definition()
+		}
+		o.IterateAttributes(func(n string, catt *design.AttributeDefinition) error {
+			tgt := fmt.Sprintf("%s.%s", target, codegen.GoifyAtt(catt, n, true))
+			if hasMetadata(catt, sensitiveKey) {
+				redactions = append(redactions, redactField(catt, tgt, att.IsPrimitivePointer(n), depth))
+			} else if hasSensitive(catt, nil) {
+				redactions = append(redactions, redactNested(catt, tgt, depth))
+			}
+			return nil
+		})
+	}
+	return strings.Join(redactions, "\n")
+}
+
+// redactField produces the code that replaces the value of a sensitive field. String values are
+// replaced with a placeholder, other values are reset to their zero value.
+func redactField(att *design.AttributeDefinition, target string, pointer bool, depth int) string {
+	tabs := codegen.Tabs(depth)
+	custom := customType(att)
+	isString := custom == "" && att.Type.IsPrimitive() && codegen.GoNativeType(att.Type) == "string"
+	switch {
+	case isString && pointer:
+		return fmt.Sprintf("%sif %s != nil {\n%s\tredacted := %q\n%s\t%s = &redacted\n%s}",
+			tabs, target, tabs, redactedValue, tabs, target, tabs)
+	case isString:
+		return fmt.Sprintf("%s%s = %q", tabs, target, redactedValue)
+	case pointer || att.Type.IsObject() || !att.Type.IsPrimitive() && custom == "":
+		return fmt.Sprintf("%s%s = nil", tabs, target)
+	case custom != "":
+		return fmt.Sprintf("%s%s = *new(%s)", tabs, target, custom)
+	}
+	switch att.Type.Kind() {
+	case design.BooleanKind:
+		return fmt.Sprintf("%s%s = false", tabs, target)
+	case design.IntegerKind, design.NumberKind:
+		return fmt.Sprintf("%s%s = 0", tabs, target)
+	case design.AnyKind:
+		return fmt.Sprintf("%s%s = nil", tabs, target)
+	}
+	return fmt.Sprintf("%s%s = *new(%s)", tabs, target, codegen.GoNativeType(att.Type))
+}
+
+// redactNested produces the code that redacts the sensitive attributes of the values held by a
+// field that is not itself sensitive.
+func redactNested(att *design.AttributeDefinition, target string, depth int) string {
+	switch actual := att.Type.(type) {
+	case *design.MediaTypeDefinition, *design.UserTypeDefinition:
+		return fmt.Sprintf("%s%s.redact()", codegen.Tabs(depth), target)
+	case design.Object:
+		return fmt.Sprintf("%sif %s != nil {\n%s\n%s}",
+			codegen.Tabs(depth), target, redacter(att, target, depth+1), codegen.Tabs(depth))
+	case *design.Array:
+		e := fmt.Sprintf("elem%d", depth)
+		return fmt.Sprintf("%sfor _, %s := range %s {\n%s\n%s}",
+			codegen.Tabs(depth), e, target, redactNested(actual.ElemType, e, depth+1), codegen.Tabs(depth))
+	case *design.Hash:
+		k, v := fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth)
+		var code []string
+		if hasSensitive(actual.KeyType, nil) {
+			code = append(code, redactNested(actual.KeyType, k, depth+1))
+		} else {
+			k = "_"
+		}
+		if hasSensitive(actual.ElemType, nil) {
+			code = append(code, redactNested(actual.ElemType, v, depth+1))
+		} else {
+			v = "_"
+		}
+		return fmt.Sprintf("%sfor %s, %s := range %s {\n%s\n%s}",
+			codegen.Tabs(depth), k, v, target, strings.Join(code, "\n"), codegen.Tabs(depth))
+	}
+	return ""
+}
+
+// customType returns the Go type that the design maps the given attribute to via the
+// "struct:field:type" or "struct:type" metadata, the empty string if there isn't one.
+func customType(att *design.AttributeDefinition) string {
+	if tname := att.Metadata["struct:field:type"]; len(tname) > 0 {
+		return tname[0]
+	}
+	return externalType(att)
+}
+
+// hasSensitive returns true if the values of the given attribute hold sensitive attributes. seen
+// records the user types already visited to handle recursive types.
+func hasSensitive(att *design.AttributeDefinition, seen map[string]bool) bool {
+	if seen == nil {
+		seen = make(map[string]bool)
+	}
+	if customType(att) != "" {
+		return false
+	}
+	switch actual := att.Type.(type) {
+	case *design.MediaTypeDefinition:
+		if actual.IsError() || seen[actual.TypeName] {
+			return false
+		}
+		seen[actual.TypeName] = true
+		return hasSensitive(actual.AttributeDefinition, seen)
+	case *design.UserTypeDefinition:
+		if seen[actual.TypeName] {
+			return false
+		}
+		seen[actual.TypeName] = true
+		return hasSensitive(actual.AttributeDefinition, seen)
+	case design.Object:
+		for _, catt := range actual {
+			if hasMetadata(catt, sensitiveKey) || hasSensitive(catt, seen) {
+				return true
+			}
+		}
+	case *design.Array:
+		return hasSensitive(actual.ElemType, seen)
+	case *design.Hash:
+		return hasSensitive(actual.KeyType, seen) || hasSensitive(actual.ElemType, seen)
+	}
+	return false
+}
+
+// hasField returns true if the struct generated for the given attribute has a field with the
+// given name. It is used to avoid generating methods that clash with fields.
+func hasField(att *design.AttributeDefinition, name string) bool {
+	o := att.Type.ToObject()
+	if o == nil {
+		return false
+	}
+	for n, catt := range o {
+		if codegen.GoifyAtt(catt, n, true) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMetadata returns true if the given attribute defines the metadata with the given key.
+func hasMetadata(att *design.AttributeDefinition, key string) bool {
+	_, ok := att.Metadata[key]
+	return ok
+}
+
+// copyMethodsT generates the DeepCopy, String and redact methods of a user or media type.
+// template input: map[string]interface{}
+const copyMethodsT = `{{ $name := gotypename .Type .Attribute.AllRequired 0 false }}{{/*
+*/}}{{ $ref := gotyperef .Type .Attribute.AllRequired 0 false }}{{ $recv := .Receiver }}{{/*
+*/}}{{ $sensitive := hasSensitive .Attribute }}{{ if not (hasField .Attribute "DeepCopy") }}
+// DeepCopy returns a copy of the {{ $name }} {{ .Kind }} instance that shares no memory with it.
+func ({{ $recv }} {{ $ref }}) DeepCopy() {{ $ref }} {
+{{ if .Attribute.Type.IsObject }}	if {{ $recv }} == nil {
+		return nil
+	}
+	res := &{{ $name }}{}
+{{ objectCopier .Attribute $recv "res" 1 }}
+{{ else }}	var res {{ $name }}
+{{ deepCopier .Attribute $recv "res" 1 }}
+{{ end }}	return res
+}
+{{ if not (hasField .Attribute "String") }}
+// String returns the JSON representation of the {{ $name }} {{ .Kind }} instance{{ if $sensitive }}, the values
+// of the sensitive attributes are redacted{{ end }}.
+func ({{ $recv }} {{ $ref }}) String() string {
+	res := {{ $recv }}.DeepCopy()
+{{ if $sensitive }}	res.redact()
+{{ end }}	b, err := json.Marshal(res)
+	if err != nil {
+		return fmt.Sprintf("%T: %s", res, err)
+	}
+	return string(b)
+}
+{{ end }}{{ end }}{{ if $sensitive }}
+// redact replaces the values of the sensitive attributes of the {{ $name }} {{ .Kind }} instance.
+func ({{ $recv }} {{ $ref }}) redact() {
+{{ if .Attribute.Type.IsObject }}	if {{ $recv }} == nil {
+		return
+	}
+{{ redacter .Attribute $recv 1 }}{{ else }}{{ redactNested .Attribute $recv 1 }}{{ end }}
+}
+{{ end }}`
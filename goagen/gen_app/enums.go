@@ -0,0 +1,262 @@
+package genapp
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+type (
+	// EnumsWriter generate code for the Go types of the enum attributes.
+	EnumsWriter struct {
+		*codegen.SourceFile
+	}
+
+	// EnumTemplateData contains the information used by the template to render the Go type of
+	// an enum attribute.
+	EnumTemplateData struct {
+		Name        string                   // Name of the Go type, e.g. "BottleColor"
+		Base        string                   // Underlying Go type, "string" or "int"
+		Description string                   // Description of the attribute
+		Values      []*EnumValueTemplateData // Enum values in design order
+	}
+
+	// EnumValueTemplateData describes the constant generated for an enum value.
+	EnumValueTemplateData struct {
+		Name  string // Name of the constant, e.g. "BottleColorRed"
+		Value string // Go literal of the value, e.g. `"red"`
+	}
+)
+
+// NewEnumsWriter returns an enums code writer.
+func NewEnumsWriter(filename string) (*EnumsWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &EnumsWriter{SourceFile: file}, nil
+}
+
+// Execute writes the code for the enum types to the writer.
+func (w *EnumsWriter) Execute(enums []*EnumTemplateData) error {
+	return w.ExecuteTemplate("enums", codegen.Template("app", "enums", enumsT), nil, enums)
+}
+
+// TypedEnums registers a custom primitive for each string or integer attribute of the API user
+// types, media types and action parameters that declares an enum validation and changes the type
+// of the attribute to that primitive so that the generated code uses a named Go type for the
+// attribute values. The Go types are named after the type or action and the attribute, e.g.
+// "BottleColor" or "ListBottleSort". TypedEnums returns the data needed to render the Go types in
+// the order they should be generated.
+//
+// Attributes that also declare a format, a pattern or length validations keep their builtin type
+// as the generated validation code requires string values.
+func TypedEnums(api *design.APIDefinition) []*EnumTemplateData {
+	b := &enumsBuilder{names: make(map[string]bool)}
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		b.names[codegen.Goify(ut.TypeName, true)] = true
+		return nil
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		b.names[codegen.Goify(mt.TypeName, true)] = true
+		return nil
+	})
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		b.collect(ut.AttributeDefinition, codegen.Goify(ut.TypeName, true))
+		return nil
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if !mt.IsError() {
+			b.collect(mt.AttributeDefinition, codegen.Goify(mt.TypeName, true))
+		}
+		return nil
+	})
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		resName := codegen.Goify(r.Name, true)
+		if r.Params != nil {
+			b.collect(r.Params, resName)
+		}
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Params != nil {
+				b.collect(a.Params, codegen.Goify(a.Name, true)+resName)
+			}
+			// The query params are copies of the action params made when the design
+			// is finalized.
+			share(a.AllParams(), a.QueryParams)
+			return nil
+		})
+	})
+	return b.enums
+}
+
+// QualifyEnums qualifies the Go types and parse functions of the custom primitives registered by
+// TypedEnums with the given package name so that code generated in other packages may refer to
+// them. Calling QualifyEnums with an empty package name removes the qualification.
+func QualifyEnums(enums []*EnumTemplateData, pkg string) {
+	for _, e := range enums {
+		p := design.LookupPrimitive(e.Name)
+		if p == nil {
+			continue
+		}
+		p.GoType, p.ParseFunc = e.Name, "Parse"+e.Name
+		if pkg != "" {
+			p.GoType, p.ParseFunc = pkg+"."+p.GoType, pkg+"."+p.ParseFunc
+		}
+	}
+}
+
+// enumsBuilder collects the enum attributes of a design.
+type enumsBuilder struct {
+	enums []*EnumTemplateData
+	names map[string]bool // Go type names already in use
+}
+
+// collect registers the primitives of the enum attributes of att. name is the prefix of the
+// generated Go type names. collect does not recurse into user and media types, they are collected
+// separately.
+func (b *enumsBuilder) collect(att *design.AttributeDefinition, name string) {
+	if externalType(att) != "" {
+		return
+	}
+	switch actual := att.Type.(type) {
+	case design.Primitive:
+		if isTypedEnum(att) {
+			b.register(att, name)
+		}
+	case design.Object:
+		keys := make([]string, 0, len(actual))
+		for n := range actual {
+			keys = append(keys, n)
+		}
+		sort.Strings(keys)
+		for _, n := range keys {
+			b.collect(actual[n], name+codegen.Goify(n, true))
+		}
+	case *design.Array:
+		b.collect(actual.ElemType, name)
+	case *design.Hash:
+		b.collect(actual.KeyType, name+"Key")
+		b.collect(actual.ElemType, name+"Value")
+	}
+}
+
+// register registers the custom primitive of the given enum attribute and changes the attribute
+// type. Registering the primitive of an attribute a second time reuses the first registration.
+func (b *enumsBuilder) register(att *design.AttributeDefinition, name string) {
+	base, jsonType := "string", "string"
+	if att.Type.Kind() == design.IntegerKind {
+		base, jsonType = "int", "integer"
+	}
+	for b.names[name] {
+		name += "Enum"
+	}
+	b.names[name] = true
+
+	p := design.LookupPrimitive(name)
+	if p == nil {
+		kind := design.MinCustomKind
+		for design.CustomPrimitive(design.Primitive(kind)) != nil {
+			kind++
+		}
+		design.RegisterPrimitive(kind, name, name, jsonType)
+		p = design.LookupPrimitive(name)
+		p.ParseFunc = "Parse" + name
+	}
+	att.Type = design.Primitive(p.Kind)
+
+	enum := &EnumTemplateData{Name: name, Base: base, Description: att.Description}
+	used := make(map[string]bool)
+	for i, v := range att.Validation.Values {
+		suffix := codegen.Goify(fmt.Sprint(v), true)
+		if suffix == "" {
+			suffix = "Value" + strconv.Itoa(i)
+		}
+		cname := name + suffix
+		for used[cname] {
+			cname += "_"
+		}
+		used[cname] = true
+		enum.Values = append(enum.Values, &EnumValueTemplateData{Name: cname, Value: fmt.Sprintf("%#v", v)})
+	}
+	b.enums = append(b.enums, enum)
+}
+
+// share sets the type of the attributes of dst to the custom primitives registered for the
+// corresponding attributes of src.
+func share(src, dst *design.AttributeDefinition) {
+	if src == nil || dst == nil {
+		return
+	}
+	if design.CustomPrimitive(src.Type) != nil {
+		dst.Type = src.Type
+		return
+	}
+	switch actual := src.Type.(type) {
+	case design.Object:
+		o := dst.Type.ToObject()
+		for n, att := range actual {
+			share(att, o[n])
+		}
+	case *design.Array:
+		if a := dst.Type.ToArray(); a != nil {
+			share(actual.ElemType, a.ElemType)
+		}
+	case *design.Hash:
+		if h := dst.Type.ToHash(); h != nil {
+			share(actual.KeyType, h.KeyType)
+			share(actual.ElemType, h.ElemType)
+		}
+	}
+}
+
+// isTypedEnum returns true if a Go type should be generated for the values of the given
+// attribute.
+func isTypedEnum(att *design.AttributeDefinition) bool {
+	if _, ok := att.Metadata["struct:field:type"]; ok {
+		return false
+	}
+	v := att.Validation
+	if v == nil || len(v.Values) == 0 {
+		return false
+	}
+	switch att.Type {
+	case design.Integer:
+		return true
+	case design.String:
+		return v.Format == "" && v.Pattern == "" && v.MinLength == nil && v.MaxLength == nil
+	}
+	return false
+}
+
+// enumsT generates the code for the enum types.
+// template input: []*EnumTemplateData
+const enumsT = `{{ range . }}{{ $enum := . }}
+// {{ .Name }} is an enum type.{{ if .Description }}
+{{ comment .Description }}{{ end }}
+type {{ .Name }} {{ .Base }}
+
+// {{ .Name }} values
+const (
+{{ range .Values }}	{{ .Name }} {{ $enum.Name }} = {{ .Value }}
+{{ end }})
+
+// IsValid returns true if v is one of the {{ .Name }} values.
+func (v {{ .Name }}) IsValid() bool {
+	switch v {
+	case {{ range $i, $v := .Values }}{{ if $i }}, {{ end }}{{ $v.Name }}{{ end }}:
+		return true
+	}
+	return false
+}
+
+// Parse{{ .Name }} parses the string representation of a {{ .Name }} value, it does not check that
+// the value is valid.
+func Parse{{ .Name }}(s string) ({{ .Name }}, error) {
+{{ if eq .Base "int" }}	v, err := strconv.Atoi(s)
+	return {{ .Name }}(v), err
+{{ else }}	return {{ .Name }}(s), nil
+{{ end }}}
+{{ end }}`
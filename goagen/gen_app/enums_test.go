@@ -0,0 +1,160 @@
+package genapp_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	genapp "github.com/kyokomi/goa-v1/goagen/gen_app"
+)
+
+var _ = Describe("TypedEnums", func() {
+	var enums []*genapp.EnumTemplateData
+
+	BeforeEach(func() {
+		design.Design = registeredDesign
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {})
+		apidsl.Type("vintage", func() {
+			apidsl.Attribute("color", design.String, "Color of the wine", func() {
+				apidsl.Enum("red", "white")
+			})
+			apidsl.Attribute("rating", design.Integer, func() {
+				apidsl.Enum(1, 2)
+			})
+			apidsl.Attribute("code", design.String, func() {
+				apidsl.Enum("x", "y")
+				apidsl.Pattern("^[a-z]$")
+			})
+		})
+		apidsl.Resource("vintage", func() {
+			apidsl.Action("list", func() {
+				apidsl.Routing(apidsl.GET("/vintages"))
+				apidsl.Params(func() {
+					apidsl.Param("sort", design.String, func() {
+						apidsl.Enum("name", "year")
+					})
+				})
+				apidsl.Response(design.NoContent)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		enums = genapp.TypedEnums(design.Design)
+	})
+
+	AfterEach(func() {
+		design.Design = registeredDesign
+		dslengine.Reset()
+	})
+
+	It("registers a primitive for each enum attribute", func() {
+		Ω(enums).Should(HaveLen(3))
+		Ω(enums[0].Name).Should(Equal("VintageColor"))
+		Ω(enums[0].Base).Should(Equal("string"))
+		Ω(enums[0].Description).Should(Equal("Color of the wine"))
+		Ω(enums[0].Values).Should(HaveLen(2))
+		Ω(enums[0].Values[0].Name).Should(Equal("VintageColorRed"))
+		Ω(enums[0].Values[0].Value).Should(Equal(`"red"`))
+		Ω(enums[1].Name).Should(Equal("VintageRating"))
+		Ω(enums[1].Base).Should(Equal("int"))
+		Ω(enums[1].Values[1].Value).Should(Equal("2"))
+		Ω(enums[2].Name).Should(Equal("ListVintageSort"))
+	})
+
+	It("changes the type of the enum attributes", func() {
+		o := design.Design.Types["vintage"].Type.ToObject()
+		Ω(design.CustomPrimitive(o["color"].Type).GoType).Should(Equal("VintageColor"))
+		Ω(design.CustomPrimitive(o["rating"].Type).ParseFunc).Should(Equal("ParseVintageRating"))
+		Ω(o["code"].Type).Should(Equal(design.String))
+		q := design.Design.Resources["vintage"].Actions["list"].QueryParams.Type.ToObject()
+		Ω(design.CustomPrimitive(q["sort"].Type).GoType).Should(Equal("ListVintageSort"))
+	})
+
+	It("qualifies the Go types", func() {
+		genapp.QualifyEnums(enums, "app")
+		defer genapp.QualifyEnums(enums, "")
+		p := design.LookupPrimitive("VintageColor")
+		Ω(p.GoType).Should(Equal("app.VintageColor"))
+		Ω(p.ParseFunc).Should(Equal("app.ParseVintageColor"))
+	})
+})
+
+var _ = Describe("EnumsWriter", func() {
+	var writer *genapp.EnumsWriter
+	var filename string
+	var workspace *codegen.Workspace
+
+	JustBeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		pkg, err := workspace.NewPackage("enums")
+		Ω(err).ShouldNot(HaveOccurred())
+		src, err := pkg.CreateSourceFile("test.go")
+		Ω(err).ShouldNot(HaveOccurred())
+		defer src.Close()
+		filename = src.Abs()
+		writer, err = genapp.NewEnumsWriter(filename)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("writes the enum types", func() {
+		enums := []*genapp.EnumTemplateData{
+			{
+				Name: "BottleRating",
+				Base: "int",
+				Values: []*genapp.EnumValueTemplateData{
+					{Name: "BottleRating1", Value: "1"},
+					{Name: "BottleRating2", Value: "2"},
+				},
+			},
+		}
+		err := writer.Execute(enums)
+		Ω(err).ShouldNot(HaveOccurred())
+		b, err := ioutil.ReadFile(filename)
+		Ω(err).ShouldNot(HaveOccurred())
+		written := string(b)
+		Ω(written).ShouldNot(BeEmpty())
+		Ω(written).Should(Equal(enumsCode))
+	})
+})
+
+const enumsCode = `
+// BottleRating is an enum type.
+type BottleRating int
+
+// BottleRating values
+const (
+	BottleRating1 BottleRating = 1
+	BottleRating2 BottleRating = 2
+)
+
+// IsValid returns true if v is one of the BottleRating values.
+func (v BottleRating) IsValid() bool {
+	switch v {
+	case BottleRating1, BottleRating2:
+		return true
+	}
+	return false
+}
+
+// ParseBottleRating parses the string representation of a BottleRating value, it does not check that
+// the value is valid.
+func ParseBottleRating(s string) (BottleRating, error) {
+	v, err := strconv.Atoi(s)
+	return BottleRating(v), err
+}
+`
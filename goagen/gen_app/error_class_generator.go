@@ -0,0 +1,59 @@
+package genapp
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+// generateErrorClasses generates the error classes declared in the design together with the
+// functions that create errors with typed details and retrieve them.
+func (g *Generator) generateErrorClasses() (err error) {
+	if len(g.API.ErrorClasses) == 0 {
+		return nil
+	}
+	errorsFile := filepath.Join(g.OutDir, "errors.go")
+	file, err := codegen.SourceFileFor(errorsFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Error Classes", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("errors"),
+		codegen.NewImport("goa", "github.com/kyokomi/goa-v1"),
+	}
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, errorsFile)
+
+	return file.ExecuteTemplate("errors", codegen.Template("app", "errors", errorsT), nil, g.API.ErrorClasses)
+}
+
+const errorsT = `{{ range . }}{{ $name := goify .Code true }}{{ $code := printf "%q" .Code }}// Err{{ $name }} is the class of {{ $code }} errors.
+var Err{{ $name }} = goa.NewErrorClass({{ $code }}, {{ .Status }})
+{{ if .Details }}{{ $ref := gotyperef .Details nil 0 false }}
+// New{{ $name }}Error creates a {{ $code }} error with the given details.
+func New{{ $name }}Error(message interface{}, details {{ $ref }}, keyvals ...interface{}) error {
+	return goa.WithErrorDetails(Err{{ $name }}(message, keyvals...), details)
+}
+
+// {{ $name }}ErrorDetails returns the details of err, false if err is not a {{ $code }} error or
+// if it has no details.
+func {{ $name }}ErrorDetails(err error) ({{ $ref }}, bool) {
+	var details {{ $ref }}
+	var e *goa.ErrorResponse
+	if !errors.As(err, &e) || e.Code != {{ $code }} || e.DecodeDetails(&details) != nil {
+		return details, false
+	}
+	return details, true
+}
+{{ end }}
+{{ end }}`
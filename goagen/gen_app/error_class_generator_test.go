@@ -0,0 +1,90 @@
+package genapp_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	genapp "github.com/kyokomi/goa-v1/goagen/gen_app"
+)
+
+var _ = Describe("Generate error classes", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var classes bool
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+		Ω(err).ShouldNot(HaveOccurred())
+		classes = true
+	})
+
+	JustBeforeEach(func() {
+		design.Design = registeredDesign
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		stock := apidsl.Type("StockDetails", func() {
+			apidsl.Attribute("available", design.Integer)
+		})
+		apidsl.API("cellar", func() {
+			if classes {
+				apidsl.ErrorClass("out_of_stock", 409, stock)
+				apidsl.ErrorClass("unavailable", 503)
+			}
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Response(design.NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genapp.NewGenerator(
+			genapp.API(design.Design),
+			genapp.OutDir(filepath.Join(outDir, "app")),
+			genapp.Target("app"),
+			genapp.NoTest(true),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+		delete(codegen.Reserved, "app")
+	})
+
+	It("generates the error classes", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		errorsFile := filepath.Join(outDir, "app", "errors.go")
+		Ω(files).Should(ContainElement(errorsFile))
+		content, err := ioutil.ReadFile(errorsFile)
+		Ω(err).ShouldNot(HaveOccurred())
+		code := string(content)
+		Ω(code).Should(ContainSubstring(`var ErrOutOfStock = goa.NewErrorClass("out_of_stock", 409)`))
+		Ω(code).Should(ContainSubstring(`var ErrUnavailable = goa.NewErrorClass("unavailable", 503)`))
+		Ω(code).Should(ContainSubstring("func NewOutOfStockError(message interface{}, details *StockDetails, keyvals ...interface{}) error {\n\treturn goa.WithErrorDetails(ErrOutOfStock(message, keyvals...), details)\n}"))
+		Ω(code).Should(ContainSubstring("func OutOfStockErrorDetails(err error) (*StockDetails, bool) {"))
+		Ω(code).ShouldNot(ContainSubstring("NewUnavailableError"))
+	})
+
+	Context("without error classes", func() {
+		BeforeEach(func() {
+			classes = false
+		})
+
+		It("does not generate the error classes", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(filepath.Join(outDir, "app", "errors.go")).ShouldNot(BeAnExistingFile())
+		})
+	})
+})
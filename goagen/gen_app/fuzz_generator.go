@@ -0,0 +1,215 @@
+package genapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+// FuzzTarget describes the fuzz target generated for an action.
+type FuzzTarget struct {
+	Name         string       // Name of the fuzz function
+	ResourceName string       // Name of the resource in the design
+	ActionName   string       // Name of the action in the design
+	Context      string       // Name of the action context type
+	Unmarshal    string       // Name of the payload unmarshal function, empty if none
+	ContentType  string       // Content type of the fuzzed request bodies
+	Method       string       // HTTP method of the fuzzed requests
+	Path         string       // Path of the fuzzed requests
+	Inputs       []*FuzzInput // Fuzzed request body, parameters and headers
+}
+
+// FuzzInput describes a value mutated by a fuzz target.
+type FuzzInput struct {
+	Name    string // Name of the parameter or header, empty for the request body
+	VarName string // Name of the fuzz function argument
+	Type    string // Go type of the argument, []byte for the body and string otherwise
+	Header  bool   // Whether the input is a request header
+	Seed    string // Go expression of the valid example used as seed
+	Zero    string // Go expression of the empty value used as seed
+}
+
+// generateFuzzTargets generates the fuzz targets that check the request decoding and validation
+// code generated for each action.
+func (g *Generator) generateFuzzTargets() (err error) {
+	var targets []*FuzzTarget
+	rand := g.API.RandomGenerator()
+	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if len(a.Routes) == 0 {
+				return nil
+			}
+			if t := g.fuzzTarget(r, a, rand); len(t.Inputs) > 0 {
+				targets = append(targets, t)
+			}
+			return nil
+		})
+	})
+	if err != nil || len(targets) == 0 {
+		return
+	}
+
+	fuzzFile := filepath.Join(g.OutDir, "fuzz_test.go")
+	file, err := codegen.SourceFileFor(fuzzFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Request Decoding Fuzz Targets", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("bytes"),
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("net/http/httptest"),
+		codegen.SimpleImport("net/url"),
+		codegen.SimpleImport("testing"),
+		codegen.SimpleImport("github.com/kyokomi/goa-v1"),
+	}
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, fuzzFile)
+
+	return file.ExecuteTemplate("fuzz", codegen.Template("app", "fuzz", fuzzT), nil, targets)
+}
+
+// fuzzTarget builds the fuzz target of the given action. The seeds are the examples generated
+// from the design and empty values.
+func (g *Generator) fuzzTarget(r *design.ResourceDefinition, a *design.ActionDefinition, rand *design.RandomGenerator) *FuzzTarget {
+	actionName := codegen.Goify(a.Name, true)
+	resName := codegen.Goify(r.Name, true)
+	t := &FuzzTarget{
+		Name:         "Fuzz" + actionName + resName,
+		ResourceName: r.Name,
+		ActionName:   a.Name,
+		Context:      g.contextName(a),
+		ContentType:  "application/json",
+		Method:       a.Routes[0].Verb,
+		Path:         a.Routes[0].FullPath(),
+	}
+	if len(g.API.Consumes) > 0 && len(g.API.Consumes[0].MIMETypes) > 0 {
+		t.ContentType = g.API.Consumes[0].MIMETypes[0]
+	}
+	if a.Payload != nil {
+		t.Unmarshal = fmt.Sprintf("unmarshal%s%sPayload", actionName, resName)
+		if a.PayloadMultipart {
+			t.ContentType = "multipart/form-data; boundary=fuzz"
+		}
+		seed := "[]byte(nil)"
+		if ex := a.Payload.GenerateExample(rand, nil); ex != nil && !a.PayloadMultipart {
+			if b, err := json.Marshal(ex); err == nil {
+				seed = fmt.Sprintf("[]byte(%s)", strconv.Quote(string(b)))
+			}
+		}
+		t.Inputs = append(t.Inputs, &FuzzInput{VarName: "body", Type: "[]byte", Seed: seed, Zero: "[]byte(nil)"})
+	}
+	if params := a.AllParams(); params != nil {
+		obj := params.Type.ToObject()
+		for _, n := range codegen.SortedNames(obj) {
+			t.Inputs = append(t.Inputs, &FuzzInput{
+				Name:    n,
+				VarName: "param" + codegen.Goify(n, true),
+				Type:    "string",
+				Seed:    strconv.Quote(exampleString(obj[n].GenerateExample(rand, nil))),
+				Zero:    `""`,
+			})
+		}
+	}
+	headers := &design.AttributeDefinition{Type: design.Object{}}
+	if r.Headers != nil {
+		headers.Merge(r.Headers)
+	}
+	if a.Headers != nil {
+		headers.Merge(a.Headers)
+	}
+	obj := headers.Type.ToObject()
+	for _, n := range codegen.SortedNames(obj) {
+		t.Inputs = append(t.Inputs, &FuzzInput{
+			Name:    http.CanonicalHeaderKey(n),
+			VarName: "header" + codegen.Goify(n, true),
+			Type:    "string",
+			Header:  true,
+			Seed:    strconv.Quote(exampleString(obj[n].GenerateExample(rand, nil))),
+			Zero:    `""`,
+		})
+	}
+	return t
+}
+
+// exampleString returns the string representation of the given example value as it appears in
+// a request parameter or header. Arrays are represented by their first element.
+func exampleString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case time.Time:
+		return val.Format(time.RFC3339)
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+		if rv.Len() == 0 {
+			return ""
+		}
+		return exampleString(rv.Index(0).Interface())
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// fuzzT generates the fuzz targets.
+// template input: []*FuzzTarget
+const fuzzT = `// checkFuzzError fails the test if err is not a goa error describing a bad request.
+func checkFuzzError(t *testing.T, err error) {
+	serr, ok := err.(goa.ServiceError)
+	if !ok {
+		t.Fatalf("unexpected error type %T: %s", err, err)
+	}
+	if status := serr.ResponseStatus(); status < 400 || status >= 500 {
+		t.Fatalf("unexpected error status %d: %s", status, err)
+	}
+}
+{{ range . }}
+// {{ .Name }} checks that decoding and validating the requests sent to the {{ .ActionName }}
+// action of the {{ .ResourceName }} resource never panics and only fails with bad request errors.
+func {{ .Name }}(f *testing.F) {
+	f.Add({{ range $i, $in := .Inputs }}{{ if $i }}, {{ end }}{{ $in.Seed }}{{ end }})
+	f.Add({{ range $i, $in := .Inputs }}{{ if $i }}, {{ end }}{{ $in.Zero }}{{ end }})
+	service := goa.New("fuzz")
+	initService(service)
+	f.Fuzz(func(t *testing.T{{ range .Inputs }}, {{ .VarName }} {{ .Type }}{{ end }}) {
+		req := httptest.NewRequest({{ printf "%q" .Method }}, {{ printf "%q" .Path }}, {{ if .Unmarshal }}bytes.NewReader(body){{ else }}nil{{ end }})
+{{ if .Unmarshal }}		req.Header.Set("Content-Type", {{ printf "%q" .ContentType }})
+{{ end }}{{ range .Inputs }}{{ if .Header }}		req.Header[{{ printf "%q" .Name }}] = []string{ {{ .VarName }} }
+{{ end }}{{ end }}		params := url.Values{}
+{{ range .Inputs }}{{ if and .Name (not .Header) }}		params.Set({{ printf "%q" .Name }}, {{ .VarName }})
+{{ end }}{{ end }}		ctx := goa.NewContext(context.Background(), httptest.NewRecorder(), req, params)
+{{ if .Unmarshal }}		if len(body) > 0 {
+			if err := {{ .Unmarshal }}(ctx, service, req); err != nil {
+				if _, ok := err.(goa.ServiceError); !ok {
+					// The controller reports the decoding errors as bad requests
+					err = goa.ErrBadRequest(err)
+				}
+				checkFuzzError(t, err)
+			}
+		}
+{{ end }}		if _, err := New{{ .Context }}(ctx, req, service); err != nil {
+			checkFuzzError(t, err)
+		}
+	})
+}
+{{ end }}`
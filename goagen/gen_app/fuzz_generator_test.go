@@ -0,0 +1,113 @@
+package genapp_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	genapp "github.com/kyokomi/goa-v1/goagen/gen_app"
+)
+
+// registeredDesign is the API definition registered with the DSL engine, other tests replace
+// design.Design with definitions that are not.
+var registeredDesign = design.Design
+
+var _ = Describe("Generate fuzz targets", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var fuzz bool
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+		Ω(err).ShouldNot(HaveOccurred())
+		fuzz = true
+
+		design.Design = registeredDesign
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {})
+		apidsl.Resource("bottle", func() {
+			apidsl.BasePath("/bottles")
+			apidsl.Action("update", func() {
+				apidsl.Routing(apidsl.PUT("/:id"))
+				apidsl.Params(func() {
+					apidsl.Param("id", design.Integer, func() {
+						apidsl.Example(1)
+					})
+				})
+				apidsl.Headers(func() {
+					apidsl.Header("x-trace", design.String, func() {
+						apidsl.Example("abc")
+					})
+				})
+				apidsl.Payload(func() {
+					apidsl.Member("name", design.String, func() {
+						apidsl.Example("Number 8")
+					})
+				})
+				apidsl.Response(design.NoContent)
+			})
+			apidsl.Action("list", func() {
+				apidsl.Routing(apidsl.GET(""))
+				apidsl.Response(design.NoContent)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genapp.NewGenerator(
+			genapp.API(design.Design),
+			genapp.OutDir(filepath.Join(outDir, "app")),
+			genapp.Target("app"),
+			genapp.NoTest(true),
+			genapp.Fuzz(fuzz),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+		delete(codegen.Reserved, "app")
+	})
+
+	It("generates a fuzz target per action with inputs", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		fuzzFile := filepath.Join(outDir, "app", "fuzz_test.go")
+		Ω(files).Should(ContainElement(fuzzFile))
+		content, err := ioutil.ReadFile(fuzzFile)
+		Ω(err).ShouldNot(HaveOccurred())
+		code := string(content)
+		Ω(code).Should(ContainSubstring("func checkFuzzError(t *testing.T, err error) {"))
+		Ω(code).Should(ContainSubstring("func FuzzUpdateBottle(f *testing.F) {"))
+		Ω(code).ShouldNot(ContainSubstring("FuzzListBottle"))
+		Ω(code).Should(ContainSubstring(`f.Add([]byte("{\"name\":\"Number 8\"}"), "1", "abc")`))
+		Ω(code).Should(ContainSubstring(`f.Add([]byte(nil), "", "")`))
+		Ω(code).Should(ContainSubstring("f.Fuzz(func(t *testing.T, body []byte, paramID string, headerXTrace string) {"))
+		Ω(code).Should(ContainSubstring(`req.Header["X-Trace"] = []string{headerXTrace}`))
+		Ω(code).Should(ContainSubstring(`params.Set("id", paramID)`))
+		Ω(code).Should(ContainSubstring("if err := unmarshalUpdateBottlePayload(ctx, service, req); err != nil {"))
+		Ω(code).Should(ContainSubstring("if _, err := NewUpdateBottleContext(ctx, req, service); err != nil {"))
+	})
+
+	Context("with fuzz targets disabled", func() {
+		BeforeEach(func() {
+			fuzz = false
+		})
+
+		It("does not generate fuzz targets", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(filepath.Join(outDir, "app", "fuzz_test.go")).ShouldNot(BeAnExistingFile())
+		})
+	})
+})
@@ -26,19 +26,29 @@ func NewGenerator(options ...Option) *Generator {
 
 // Generator is the application code generator.
 type Generator struct {
-	API       *design.APIDefinition // The API definition
-	OutDir    string                // Path to output directory
-	Target    string                // Name of generated package
-	NoTest    bool                  // Whether to skip test generation
-	genfiles  []string              // Generated files
-	validator *codegen.Validator    // Validation code generator
+	API          *design.APIDefinition // The API definition
+	OutDir       string                // Path to output directory
+	Target       string                // Name of generated package
+	NoTest       bool                  // Whether to skip test generation
+	Tracing      bool                  // Whether to instrument the action handlers with OpenTelemetry
+	Fuzz         bool                  // Whether to generate fuzz targets for the request decoding code
+	Bench        bool                  // Whether to generate benchmarks for the request decoding and response encoding code
+	Props        bool                  // Whether to generate property tests for the validation code of the types
+	Enums        bool                  // Whether to generate named Go types for the enum attributes
+	ValidateTags bool                  // Whether to add go-playground/validator struct tags to the types
+	ContextFirst bool                  // Whether the actions take a context.Context and request data instead of an action context
+	genfiles     []string              // Generated files
+	enums        []*EnumTemplateData   // Generated enum types
+	validator    *codegen.Validator    // Validation code generator
 }
 
 // Generate is the generator entry point called by the meta generator.
 func Generate() (files []string, err error) {
 	var (
 		outDir, toolDir, target, ver string
-		notest, notool, regen        bool
+		notest, notool, regen, otel  bool
+		fuzz, bench, props, enums    bool
+		validateTags, contextFirst   bool
 	)
 
 	set := flag.NewFlagSet("app", flag.PanicOnError)
@@ -50,6 +60,13 @@ func Generate() (files []string, err error) {
 	set.BoolVar(&notest, "notest", false, "")
 	set.BoolVar(&notool, "notool", false, "")
 	set.BoolVar(&regen, "regen", false, "")
+	set.BoolVar(&otel, "otel", false, "")
+	set.BoolVar(&fuzz, "fuzz", false, "")
+	set.BoolVar(&bench, "bench", false, "")
+	set.BoolVar(&props, "props", false, "")
+	set.BoolVar(&enums, "enums", false, "")
+	set.BoolVar(&validateTags, "validate-tags", false, "")
+	set.BoolVar(&contextFirst, "context-first", false, "")
 	set.Bool("force", false, "")
 	set.Parse(os.Args[1:])
 	outDir = filepath.Join(outDir, target)
@@ -59,7 +76,7 @@ func Generate() (files []string, err error) {
 	}
 
 	target = codegen.Goify(target, false)
-	g := &Generator{OutDir: outDir, Target: target, NoTest: notest, API: design.Design, validator: codegen.NewValidator()}
+	g := &Generator{OutDir: outDir, Target: target, NoTest: notest, Tracing: otel, Fuzz: fuzz, Bench: bench, Props: props, Enums: enums, ValidateTags: validateTags, ContextFirst: contextFirst, API: design.Design, validator: codegen.NewValidator()}
 
 	return g.Generate()
 }
@@ -79,13 +96,20 @@ func (g *Generator) Generate() (_ []string, err error) {
 	}()
 
 	codegen.Reserved[g.Target] = true
+	codegen.ValidateTags = g.ValidateTags
+	defer func() { codegen.ValidateTags = false }()
 
-	os.RemoveAll(g.OutDir)
+	codegen.RemoveAll(g.OutDir)
 
 	if err := os.MkdirAll(g.OutDir, 0755); err != nil {
 		return nil, err
 	}
 	g.genfiles = []string{g.OutDir}
+	if g.Enums {
+		if err := g.generateEnums(); err != nil {
+			return nil, err
+		}
+	}
 	if err := g.generateContexts(); err != nil {
 		return nil, err
 	}
@@ -104,8 +128,33 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if err := g.generateUserTypes(); err != nil {
 		return nil, err
 	}
+	if err := g.generateProblems(); err != nil {
+		return nil, err
+	}
+	if err := g.generateErrorClasses(); err != nil {
+		return nil, err
+	}
+	if g.Fuzz {
+		if err := g.generateFuzzTargets(); err != nil {
+			return nil, err
+		}
+	}
+	if g.Bench {
+		if err := g.generateBenchmarks(); err != nil {
+			return nil, err
+		}
+	}
+	if g.Props {
+		if err := g.generatePropTests(); err != nil {
+			return nil, err
+		}
+	}
 	if !g.NoTest {
-		if err := g.generateResourceTest(); err != nil {
+		// The test helpers live in a different package
+		QualifyEnums(g.enums, g.Target)
+		err := g.generateResourceTest()
+		QualifyEnums(g.enums, "")
+		if err != nil {
 			return nil, err
 		}
 	}
@@ -122,6 +171,54 @@ func (g *Generator) Cleanup() {
 	g.genfiles = nil
 }
 
+// generateEnums generates the named Go types of the enum attributes. It must run before the other
+// files are generated as it changes the types of the enum attributes.
+func (g *Generator) generateEnums() (err error) {
+	g.enums = TypedEnums(g.API)
+	if len(g.enums) == 0 {
+		return nil
+	}
+
+	var (
+		enumsFile string
+		enumsWr   *EnumsWriter
+	)
+	{
+		enumsFile = filepath.Join(g.OutDir, "enums.go")
+		enumsWr, err = NewEnumsWriter(enumsFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		enumsWr.Close()
+		if err == nil {
+			err = enumsWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Enum Types", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("strconv"),
+	}
+	if err = enumsWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, enumsFile)
+
+	return enumsWr.Execute(g.enums)
+}
+
+// contextName returns the name of the data type built from the requests of the given action and
+// passed to the controller, "<Action><Resource>Request" if the actions take a context.Context and
+// the request data as separate arguments, "<Action><Resource>Context" otherwise.
+func (g *Generator) contextName(a *design.ActionDefinition) string {
+	suffix := "Context"
+	if g.ContextFirst {
+		suffix = "Request"
+	}
+	return codegen.Goify(a.Name, true) + codegen.Goify(a.Parent.Name, true) + suffix
+}
+
 // generateContexts iterates through the API resources and actions and generates the action
 // contexts.
 func (g *Generator) generateContexts() (err error) {
@@ -146,6 +243,7 @@ func (g *Generator) generateContexts() (err error) {
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("net/url"),
 		codegen.SimpleImport("strconv"),
 		codegen.SimpleImport("strings"),
 		codegen.SimpleImport("time"),
@@ -159,6 +257,12 @@ func (g *Generator) generateContexts() (err error) {
 			if a.Payload != nil {
 				imports = codegen.AttributeImports(a.Payload.AttributeDefinition, imports, nil)
 			}
+			if a.Params != nil {
+				imports = codegen.AttributeImports(a.Params, imports, nil)
+			}
+			if a.Headers != nil {
+				imports = codegen.AttributeImports(a.Headers, imports, nil)
+			}
 			return nil
 		})
 	})
@@ -169,7 +273,7 @@ func (g *Generator) generateContexts() (err error) {
 	}
 	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
 		return r.IterateActions(func(a *design.ActionDefinition) error {
-			ctxName := codegen.Goify(a.Name, true) + codegen.Goify(a.Parent.Name, true) + "Context"
+			ctxName := g.contextName(a)
 			headers := &design.AttributeDefinition{
 				Type: design.Object{},
 			}
@@ -207,6 +311,7 @@ func (g *Generator) generateContexts() (err error) {
 				API:          g.API,
 				DefaultPkg:   g.Target,
 				Security:     a.Security,
+				ContextFirst: g.ContextFirst,
 			}
 			return ctxWr.Execute(&ctxData)
 		})
@@ -271,12 +376,37 @@ func (g *Generator) generateControllers() (err error) {
 	for _, packagePath := range packagePaths {
 		imports = append(imports, codegen.SimpleImport(packagePath))
 	}
+	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload != nil {
+				if _, path := codegen.ExternalType(a.Payload.AttributeDefinition); path != "" {
+					imports = append(imports, codegen.SimpleImport(path))
+				}
+			}
+			return nil
+		})
+	})
+	if g.Tracing {
+		imports = append(imports,
+			codegen.SimpleImport("errors"),
+			codegen.SimpleImport("go.opentelemetry.io/otel"),
+			codegen.SimpleImport("go.opentelemetry.io/otel/attribute"),
+			codegen.SimpleImport("go.opentelemetry.io/otel/codes"),
+			codegen.SimpleImport("go.opentelemetry.io/otel/propagation"),
+			codegen.SimpleImport("go.opentelemetry.io/otel/trace"),
+		)
+	}
 	if err = ctlWr.WriteHeader(title, g.Target, imports); err != nil {
 		return err
 	}
 	if err = ctlWr.WriteInitService(encoders, decoders); err != nil {
 		return err
 	}
+	if g.Tracing {
+		if err = ctlWr.WriteTracing(); err != nil {
+			return err
+		}
+	}
 
 	g.genfiles = append(g.genfiles, ctlFile)
 	var controllersData []*ControllerTemplateData
@@ -301,11 +431,14 @@ func (g *Generator) generateControllers() (err error) {
 		data := &ControllerTemplateData{
 			API:            g.API,
 			Resource:       codegen.Goify(r.Name, true),
+			DesignName:     r.Name,
 			PreflightPaths: r.PreflightPaths(),
 			FileServers:    fileServers,
+			Tracing:        g.Tracing,
+			ContextFirst:   g.ContextFirst,
 		}
 		r.IterateActions(func(a *design.ActionDefinition) error {
-			context := fmt.Sprintf("%s%sContext", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
+			context := g.contextName(a)
 			unmarshal := fmt.Sprintf("unmarshal%s%sPayload", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
 			action := map[string]interface{}{
 				"Name":             codegen.Goify(a.Name, true),
@@ -368,7 +501,7 @@ func (g *Generator) generateSecurity() (err error) {
 		return err
 	}
 	g.genfiles = append(g.genfiles, secFile)
-	err = secWr.Execute(design.Design.SecuritySchemes)
+	err = secWr.Execute(g.API.SecuritySchemes)
 
 	return
 }
@@ -445,14 +578,17 @@ func (g *Generator) generateMediaTypes() (err error) {
 	title := fmt.Sprintf("%s: Application Media Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("github.com/kyokomi/goa-v1"),
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
 		codegen.NewImport("uuid", "github.com/gofrs/uuid"),
+		codegen.SimpleImport("google.golang.org/protobuf/proto"),
 	}
-	for _, v := range g.API.MediaTypes {
-		imports = codegen.AttributeImports(v.AttributeDefinition, imports, nil)
-	}
+	g.API.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		imports = codegen.AttributeImports(mt.AttributeDefinition, imports, nil)
+		return nil
+	})
 	if err = mtWr.WriteHeader(title, g.Target, imports); err != nil {
 		return err
 	}
@@ -491,6 +627,7 @@ func (g *Generator) generateUserTypes() (err error) {
 	}()
 	title := fmt.Sprintf("%s: Application User Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("mime/multipart"),
 		codegen.SimpleImport("time"),
@@ -498,14 +635,19 @@ func (g *Generator) generateUserTypes() (err error) {
 		codegen.SimpleImport("github.com/kyokomi/goa-v1"),
 		codegen.NewImport("uuid", "github.com/gofrs/uuid"),
 	}
-	for _, v := range g.API.Types {
-		imports = codegen.AttributeImports(v.AttributeDefinition, imports, nil)
-	}
+	g.API.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		imports = codegen.AttributeImports(ut.AttributeDefinition, imports, nil)
+		return nil
+	})
 	if err = utWr.WriteHeader(title, g.Target, imports); err != nil {
 		return err
 	}
 	g.genfiles = append(g.genfiles, utFile)
 	err = g.API.IterateUserTypes(func(t *design.UserTypeDefinition) error {
+		if tname, _ := codegen.ExternalType(t.AttributeDefinition); tname != "" {
+			// The design maps the type to an existing Go type
+			return nil
+		}
 		return utWr.Execute(t)
 	})
 	return
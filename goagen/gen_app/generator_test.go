@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"text/template"
@@ -12,6 +13,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
 	"github.com/kyokomi/goa-v1/dslengine"
 	"github.com/kyokomi/goa-v1/goagen/codegen"
 	genapp "github.com/kyokomi/goa-v1/goagen/gen_app"
@@ -275,20 +277,120 @@ var _ = Describe("Generate", func() {
 	})
 })
 
+var _ = Describe("Generate with context first actions", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var contextFirst bool
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+		Ω(err).ShouldNot(HaveOccurred())
+		contextFirst = true
+
+		design.Design = registeredDesign
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {})
+		bottle := apidsl.MediaType("application/vnd.bottle", func() {
+			apidsl.Attributes(func() {
+				apidsl.Attribute("name", design.String)
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("name")
+			})
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Params(func() {
+					apidsl.Param("id", design.Integer)
+				})
+				apidsl.Response(design.OK, bottle)
+				apidsl.Response(design.NotFound)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		_, genErr = genapp.NewGenerator(
+			genapp.API(design.Design),
+			genapp.OutDir(filepath.Join(outDir, "app")),
+			genapp.Target("app"),
+			genapp.ContextFirst(contextFirst),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+		delete(codegen.Reserved, "app")
+	})
+
+	It("generates actions taking a context and the request data", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		code := string(content)
+		Ω(code).Should(ContainSubstring("type ShowBottleRequest struct {\n\t*goa.ResponseData\n\t*goa.RequestData\n\tID int\n}"))
+		Ω(code).Should(ContainSubstring("func NewShowBottleRequest(ctx context.Context, r *http.Request, service *goa.Service) (*ShowBottleRequest, error) {"))
+		Ω(code).Should(ContainSubstring("rctx := ShowBottleRequest{ResponseData: resp, RequestData: req}"))
+		Ω(code).Should(ContainSubstring("func (req *ShowBottleRequest) OK(ctx context.Context, r *Bottle) error {"))
+		Ω(code).Should(ContainSubstring("return req.ResponseData.Service.Send(ctx, 200, r)"))
+		Ω(code).Should(ContainSubstring("func (req *ShowBottleRequest) NotFound(ctx context.Context) error {"))
+		Ω(code).ShouldNot(ContainSubstring("context.Context\n"))
+		content, err = ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		code = string(content)
+		Ω(code).Should(ContainSubstring("Show(context.Context, *ShowBottleRequest) error"))
+		Ω(code).Should(ContainSubstring("rctx, err := NewShowBottleRequest(ctx, req, service)"))
+		Ω(code).Should(ContainSubstring("return ctrl.Show(ctx, rctx)"))
+		content, err = ioutil.ReadFile(filepath.Join(outDir, "app", "test", "bottle_testing.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		code = string(content)
+		Ω(code).Should(ContainSubstring("showCtx, _err := app.NewShowBottleRequest(goaCtx, req, service)"))
+		Ω(code).Should(ContainSubstring("_err = ctrl.Show(goaCtx, showCtx)"))
+	})
+
+	Context("with context first actions disabled", func() {
+		BeforeEach(func() {
+			contextFirst = false
+		})
+
+		It("generates actions taking the action context", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring("Show(*ShowBottleContext) error"))
+			Ω(code).Should(ContainSubstring("return ctrl.Show(rctx)"))
+		})
+	})
+})
+
 var _ = Describe("NewGenerator", func() {
 	var generator *genapp.Generator
 
 	var args = struct {
-		api    *design.APIDefinition
-		outDir string
-		target string
-		noTest bool
+		api          *design.APIDefinition
+		outDir       string
+		target       string
+		noTest       bool
+		tracing      bool
+		fuzz         bool
+		contextFirst bool
 	}{
 		api: &design.APIDefinition{
 			Name: "test api",
 		},
-		target: "app",
-		noTest: true,
+		target:       "app",
+		noTest:       true,
+		tracing:      true,
+		fuzz:         true,
+		contextFirst: true,
 	}
 
 	Context("with options all options set", func() {
@@ -299,6 +401,9 @@ var _ = Describe("NewGenerator", func() {
 				genapp.OutDir(args.outDir),
 				genapp.Target(args.target),
 				genapp.NoTest(args.noTest),
+				genapp.Tracing(args.tracing),
+				genapp.Fuzz(args.fuzz),
+				genapp.ContextFirst(args.contextFirst),
 			)
 		})
 
@@ -308,6 +413,9 @@ var _ = Describe("NewGenerator", func() {
 			Ω(generator.OutDir).Should(Equal(args.outDir))
 			Ω(generator.Target).Should(Equal(args.target))
 			Ω(generator.NoTest).Should(Equal(args.noTest))
+			Ω(generator.Tracing).Should(Equal(args.tracing))
+			Ω(generator.Fuzz).Should(Equal(args.fuzz))
+			Ω(generator.ContextFirst).Should(Equal(args.contextFirst))
 		})
 
 	})
@@ -413,6 +521,7 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		}
 		return ctrl.Get(rctx)
 	}
+	ctrl.NameRoute("GET", "/:id", "get")
 	service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("get", h, nil))
 	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
 }
@@ -479,6 +588,7 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		}
 		return ctrl.Get(rctx)
 	}
+	ctrl.NameRoute("GET", "/:id", "get")
 	service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload))
 	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
 }
@@ -516,6 +626,7 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		}
 		return ctrl.Get(rctx)
 	}
+	ctrl.NameRoute("GET", "/:id", "get")
 	service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload))
 	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
 }
@@ -555,6 +666,7 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		}
 		return ctrl.Get(rctx)
 	}
+	ctrl.NameRoute("GET", "/:id", "get")
 	service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload))
 	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
 }
@@ -584,3 +696,222 @@ func unmarshalGetWidgetPayload(ctx context.Context, service *goa.Service, req *h
 	return nil
 }
 `
+
+var _ = Describe("Generate a buildable app", func() {
+	var outDir string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		// The output directory is inside the goa module so that the generated code builds
+		// against this tree, the leading underscore excludes it from "./..." patterns.
+		outDir, err = ioutil.TempDir(".", "_build")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		design.Design = registeredDesign
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {})
+		bottle := apidsl.MediaType("application/vnd.bottle", func() {
+			apidsl.Attributes(func() {
+				apidsl.Attribute("name", design.String)
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("name")
+			})
+		})
+		apidsl.Resource("swagger", func() {
+			apidsl.Files("/swagger.json", "swagger/swagger.json")
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.BasePath("/bottles")
+			apidsl.Origin("http://swagger.goa.design", func() {
+				apidsl.Methods("GET", "PUT")
+			})
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/:id"))
+				apidsl.Params(func() {
+					apidsl.Param("id", design.Integer)
+				})
+				apidsl.Response(design.OK, bottle)
+				apidsl.Response(design.NotFound)
+			})
+			apidsl.Action("update", func() {
+				apidsl.Routing(apidsl.PUT("/:id"))
+				apidsl.Params(func() {
+					apidsl.Param("id", design.Integer)
+				})
+				apidsl.Payload(func() {
+					apidsl.Member("name", design.String)
+					apidsl.Required("name")
+				})
+				apidsl.Response(design.NoContent)
+				apidsl.Response(design.BadRequest, design.ErrorMedia)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		_, genErr = genapp.NewGenerator(
+			genapp.API(design.Design),
+			genapp.OutDir(filepath.Join(outDir, "app")),
+			genapp.Target("app"),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+		delete(codegen.Reserved, "app")
+	})
+
+	It("generates code that compiles", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		cmd := exec.Command("go", "build", "./...")
+		cmd.Dir = outDir
+		out, err := cmd.CombinedOutput()
+		Ω(err).ShouldNot(HaveOccurred(), string(out))
+	})
+
+	Context("with resources that register the same route", func() {
+		BeforeEach(func() {
+			apidsl.Resource("wine", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", design.Integer)
+					})
+					apidsl.Response(design.NoContent)
+				})
+			})
+		})
+
+		It("reports the conflict with the names of both routes when mounting", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(ioutil.WriteFile(filepath.Join(outDir, "app", "mount_test.go"), []byte(mountConflictTest), 0644)).Should(Succeed())
+			cmd := exec.Command("go", "test", "./app")
+			cmd.Dir = outDir
+			out, err := cmd.CombinedOutput()
+			Ω(err).ShouldNot(HaveOccurred(), string(out))
+		})
+	})
+})
+
+var _ = Describe("Generate a design evaluated by Eval", func() {
+	var outDir string
+	var api *design.APIDefinition
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		// The output directory is inside the goa module so that the generated code builds
+		// against this tree, the leading underscore excludes it from "./..." patterns.
+		outDir, err = ioutil.TempDir(".", "_build")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		design.Design = registeredDesign
+		dslengine.Reset()
+		apidsl.API("registered", nil)
+		api, err = design.Eval(func() {
+			apidsl.API("cellar", func() {
+				apidsl.BasePath("/cellar")
+			})
+			apidsl.BasicAuthSecurity("basic_auth")
+			bottle := apidsl.MediaType("application/vnd.bottle", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("name", design.String)
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("name")
+				})
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Security("basic_auth")
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", design.Integer)
+					})
+					apidsl.Response(design.OK, bottle)
+				})
+			})
+		})
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		_, genErr = genapp.NewGenerator(
+			genapp.API(api),
+			genapp.OutDir(filepath.Join(outDir, "app")),
+			genapp.Target("app"),
+			genapp.NoTest(true),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+		delete(codegen.Reserved, "app")
+	})
+
+	It("generates the app of the evaluated design rather than of Design", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring(`service.Mux.Handle("GET", "/cellar/bottles/:id"`))
+		content, err = ioutil.ReadFile(filepath.Join(outDir, "app", "security.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring("func UseBasicAuthMiddleware(service *goa.Service, middleware goa.Middleware) {"))
+		cmd := exec.Command("go", "build", "./...")
+		cmd.Dir = outDir
+		out, err := cmd.CombinedOutput()
+		Ω(err).ShouldNot(HaveOccurred(), string(out))
+		Ω(design.Design.Name).Should(Equal("registered"))
+		Ω(design.Design.Resources).Should(BeEmpty())
+	})
+})
+
+// mountConflictTest mounts the generated bottle and wine controllers which register the same
+// route.
+const mountConflictTest = `package app
+
+import (
+	"testing"
+
+	"github.com/kyokomi/goa-v1"
+)
+
+type bottleController struct{ *goa.Controller }
+
+func (c *bottleController) Show(*ShowBottleContext) error { return nil }
+
+func (c *bottleController) Update(*UpdateBottleContext) error { return nil }
+
+type wineController struct{ *goa.Controller }
+
+func (c *wineController) Show(*ShowWineContext) error { return nil }
+
+func TestMountConflict(t *testing.T) {
+	service := goa.New("cellar")
+	MountBottleController(service, &bottleController{service.NewController("bottle")})
+	defer func() {
+		err, ok := recover().(*goa.RouteConflictError)
+		if !ok {
+			t.Fatalf("got %v, want a *goa.RouteConflictError", err)
+		}
+		if err.Existing.Resource != "bottle" || err.Existing.Action != "show" {
+			t.Errorf("got existing route %s#%s, want bottle#show", err.Existing.Resource, err.Existing.Action)
+		}
+		if err.Route.Resource != "wine" || err.Route.Action != "show" {
+			t.Errorf("got route %s#%s, want wine#show", err.Route.Resource, err.Route.Action)
+		}
+		for _, r := range service.Mux.(goa.RouteLister).Routes() {
+			if r.Method == "GET" && r.Path == "/bottles/:id" && r.Resource != "bottle" {
+				t.Errorf("got route owned by %s, want bottle", r.Resource)
+			}
+		}
+	}()
+	MountWineController(service, &wineController{service.NewController("wine")})
+}
+`
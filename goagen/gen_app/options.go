@@ -32,3 +32,52 @@ func NoTest(noTest bool) Option {
 		g.NoTest = noTest
 	}
 }
+
+//Tracing Whether to instrument the action handlers with OpenTelemetry
+func Tracing(tracing bool) Option {
+	return func(g *Generator) {
+		g.Tracing = tracing
+	}
+}
+
+//Fuzz Whether to generate fuzz targets for the request decoding code
+func Fuzz(fuzz bool) Option {
+	return func(g *Generator) {
+		g.Fuzz = fuzz
+	}
+}
+
+//Bench Whether to generate benchmarks for the request decoding and response encoding code
+func Bench(bench bool) Option {
+	return func(g *Generator) {
+		g.Bench = bench
+	}
+}
+
+//Props Whether to generate property tests for the validation code of the types
+func Props(props bool) Option {
+	return func(g *Generator) {
+		g.Props = props
+	}
+}
+
+//Enums Whether to generate named Go types for the enum attributes
+func Enums(enums bool) Option {
+	return func(g *Generator) {
+		g.Enums = enums
+	}
+}
+
+//ValidateTags Whether to add go-playground/validator struct tags to the generated types
+func ValidateTags(validateTags bool) Option {
+	return func(g *Generator) {
+		g.ValidateTags = validateTags
+	}
+}
+
+//ContextFirst Whether the controller actions take a context.Context and the request data as separate arguments
+func ContextFirst(contextFirst bool) Option {
+	return func(g *Generator) {
+		g.ContextFirst = contextFirst
+	}
+}
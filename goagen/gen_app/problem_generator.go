@@ -0,0 +1,51 @@
+package genapp
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+// generateProblems generates the functions that configure the service to render the error
+// responses as problem details (RFC 7807) when the design uses ProblemDetails.
+func (g *Generator) generateProblems() (err error) {
+	if g.API.ProblemDetails == nil {
+		return nil
+	}
+	problemsFile := filepath.Join(g.OutDir, "problems.go")
+	file, err := codegen.SourceFileFor(problemsFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Problem Details", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.NewImport("goa", "github.com/kyokomi/goa-v1"),
+	}
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, problemsFile)
+
+	return file.ExecuteTemplate("problems", codegen.Template("app", "problems", problemsT), nil, g.API.ProblemDetails)
+}
+
+const problemsT = `// NewProblemRegistry returns the registry of the problem types declared in the design.
+func NewProblemRegistry() *goa.ProblemRegistry {
+	r := goa.NewProblemRegistry()
+{{ range .Types }}	r.Register({{ printf "%q" .Code }}, {{ printf "%q" .URI }}{{ if .Title }}, {{ printf "%q" .Title }}{{ end }})
+{{ end }}	return r
+}
+
+// UseProblemDetails configures the service to render the error responses as problem details
+// (RFC 7807) using the problem types declared in the design.
+func UseProblemDetails(service *goa.Service) {
+	service.Problems = NewProblemRegistry()
+}
+`
@@ -0,0 +1,89 @@
+package genapp_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	genapp "github.com/kyokomi/goa-v1/goagen/gen_app"
+)
+
+var _ = Describe("Generate problems", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var problems bool
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+		Ω(err).ShouldNot(HaveOccurred())
+		problems = true
+	})
+
+	JustBeforeEach(func() {
+		design.Design = registeredDesign
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {
+			if problems {
+				apidsl.ProblemDetails(func() {
+					apidsl.ProblemType("invalid_request", "https://example.com/problems/invalid-request", "Invalid request")
+					apidsl.ProblemType("not_found", "https://example.com/problems/not-found")
+				})
+			}
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Response(design.NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genapp.NewGenerator(
+			genapp.API(design.Design),
+			genapp.OutDir(filepath.Join(outDir, "app")),
+			genapp.Target("app"),
+			genapp.NoTest(true),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+		delete(codegen.Reserved, "app")
+	})
+
+	It("generates the problem registry", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		problemsFile := filepath.Join(outDir, "app", "problems.go")
+		Ω(files).Should(ContainElement(problemsFile))
+		content, err := ioutil.ReadFile(problemsFile)
+		Ω(err).ShouldNot(HaveOccurred())
+		code := string(content)
+		Ω(code).Should(ContainSubstring("func NewProblemRegistry() *goa.ProblemRegistry {"))
+		Ω(code).Should(ContainSubstring(`r.Register("invalid_request", "https://example.com/problems/invalid-request", "Invalid request")`))
+		Ω(code).Should(ContainSubstring(`r.Register("not_found", "https://example.com/problems/not-found")` + "\n"))
+		Ω(code).Should(ContainSubstring("func UseProblemDetails(service *goa.Service) {\n\tservice.Problems = NewProblemRegistry()\n}"))
+		Ω(code).Should(ContainSubstring(`goa "github.com/kyokomi/goa-v1"`))
+	})
+
+	Context("without problem details", func() {
+		BeforeEach(func() {
+			problems = false
+		})
+
+		It("does not generate the problem registry", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(filepath.Join(outDir, "app", "problems.go")).ShouldNot(BeAnExistingFile())
+		})
+	})
+})
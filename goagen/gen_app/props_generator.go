@@ -0,0 +1,416 @@
+package genapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1"
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+// propExamples is the maximum number of valid example values generated for each attribute.
+const propExamples = 5
+
+// PropTarget describes the property test generated for a type.
+type PropTarget struct {
+	Name       string           // Name of the test function
+	TypeName   string           // Name of the type in the design
+	GoType     string           // Go type of the tested values
+	Attributes []*PropAttribute // Attributes of the type
+	Mutations  []*PropMutation  // Changes that break the type validations
+}
+
+// PropAttribute describes the values used to build the values of a type attribute.
+type PropAttribute struct {
+	Name     string   // Name of the attribute in the JSON representation
+	Required bool     // Whether the attribute is required
+	Examples []string // JSON encoding of valid values
+}
+
+// PropMutation describes a change of a valid value that breaks one of the type validations.
+type PropMutation struct {
+	Attribute  string // Name of the attribute in the JSON representation
+	Value      string // JSON encoding of the invalid value, empty to remove the attribute
+	Validation string // Description of the broken validation
+}
+
+// generatePropTests generates the property tests that check the validation code generated for the
+// user types and the action payloads against the validations defined in the design.
+func (g *Generator) generatePropTests() (err error) {
+	var targets []*PropTarget
+	seen := make(map[string]bool)
+	rand := g.API.RandomGenerator()
+	add := func(ut *design.UserTypeDefinition) error {
+		t, err := g.propTarget(ut, rand)
+		if err != nil || t == nil || seen[t.GoType] {
+			return err
+		}
+		seen[t.GoType] = true
+		targets = append(targets, t)
+		return nil
+	}
+	if err = g.API.IterateUserTypes(add); err != nil {
+		return
+	}
+	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload == nil || a.PayloadMultipart {
+				return nil
+			}
+			return add(a.Payload)
+		})
+	})
+	if err != nil || len(targets) == 0 {
+		return
+	}
+
+	propsFile := filepath.Join(g.OutDir, "properties_test.go")
+	file, err := codegen.SourceFileFor(propsFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Validation Property Tests", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("math/rand"),
+		codegen.SimpleImport("testing"),
+		codegen.SimpleImport("testing/quick"),
+	}
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, propsFile)
+
+	if err = file.ExecuteTemplate("propsHelpers", codegen.Template("app", "propsHelpers", propsHelpersT), nil, nil); err != nil {
+		return err
+	}
+	fn := template.FuncMap{"literal": goLiteral}
+	return file.ExecuteTemplate("props", codegen.Template("app", "props", propsT), fn, targets)
+}
+
+// propTarget builds the property test of the given type. propTarget returns nil if the type is
+// not an object, has no validation, maps to an existing Go type or has file attributes.
+func (g *Generator) propTarget(ut *design.UserTypeDefinition, rand *design.RandomGenerator) (*PropTarget, error) {
+	obj := ut.Type.ToObject()
+	if obj == nil || externalType(ut.AttributeDefinition) != "" {
+		return nil, nil
+	}
+	if g.validator.Code(ut.AttributeDefinition, false, false, false, "ut", "type", 1, false) == "" {
+		return nil, nil
+	}
+	goType := codegen.GoTypeName(ut, nil, 0, false)
+	t := &PropTarget{
+		Name:     "Test" + goType + "Properties",
+		TypeName: ut.TypeName,
+		GoType:   goType,
+	}
+	for _, n := range codegen.SortedNames(obj) {
+		att := obj[n]
+		if att.Type.Kind() == design.FileKind {
+			return nil, nil
+		}
+		name := n
+		if tags, ok := att.Metadata["struct:tag:json"]; ok && len(tags) > 0 {
+			name = strings.Split(tags[0], ",")[0]
+		}
+		pa := &PropAttribute{Name: name, Required: ut.IsRequired(n)}
+		dups := make(map[string]bool)
+		for i := 0; i < propExamples; i++ {
+			// The first example is the one documented by the design, the others are
+			// generated from copies as the attributes cache their example.
+			ex := att
+			if i > 0 {
+				ex = design.DupAtt(att)
+				ex.Example = nil
+			}
+			b, err := json.Marshal(ex.GenerateExample(rand, nil))
+			if err != nil {
+				return nil, fmt.Errorf("%s: attribute %s example: %s", ut.Context(), n, err)
+			}
+			if !dups[string(b)] {
+				dups[string(b)] = true
+				pa.Examples = append(pa.Examples, string(b))
+			}
+		}
+		t.Attributes = append(t.Attributes, pa)
+		mutations, err := propMutations(name, att, pa.Required, rand)
+		if err != nil {
+			return nil, fmt.Errorf("%s: attribute %s: %s", ut.Context(), n, err)
+		}
+		t.Mutations = append(t.Mutations, mutations...)
+	}
+	return t, nil
+}
+
+// propMutations returns the changes of the attribute value that break its validations. Only the
+// validations checked by the Validate method of the public types are considered: missing required
+// values are only detected for strings and non-primitive types.
+func propMutations(name string, att *design.AttributeDefinition, required bool, rand *design.RandomGenerator) ([]*PropMutation, error) {
+	var mutations []*PropMutation
+	add := func(val interface{}, validation string) error {
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Errorf("invalid %s value: %s", validation, err)
+		}
+		mutations = append(mutations, &PropMutation{Attribute: name, Value: string(b), Validation: validation})
+		return nil
+	}
+	kind := att.Type.Kind()
+	if required && !att.IsNullable() && (kind == design.StringKind || !att.Type.IsPrimitive()) {
+		mutations = append(mutations, &PropMutation{Attribute: name, Validation: "required"})
+	}
+	v := att.Validation
+	if v == nil {
+		return mutations, nil
+	}
+	numeric := kind == design.IntegerKind || kind == design.NumberKind
+	if len(v.Values) > 0 {
+		if val := invalidEnumValue(kind, v.Values); val != nil {
+			if err := add(val, "enum"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if numeric && v.Minimum != nil {
+		var val interface{} = *v.Minimum - 1
+		if kind == design.IntegerKind {
+			val = int(math.Ceil(*v.Minimum)) - 1
+		}
+		if err := add(val, "minimum"); err != nil {
+			return nil, err
+		}
+	}
+	if numeric && v.Maximum != nil {
+		var val interface{} = *v.Maximum + 1
+		if kind == design.IntegerKind {
+			val = int(math.Floor(*v.Maximum)) + 1
+		}
+		if err := add(val, "maximum"); err != nil {
+			return nil, err
+		}
+	}
+	if v.MinLength != nil && *v.MinLength > 0 {
+		if val := lengthValue(att, *v.MinLength-1, rand); val != nil {
+			if err := add(val, "minimum length"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if v.MaxLength != nil {
+		if val := lengthValue(att, *v.MaxLength+1, rand); val != nil {
+			if err := add(val, "maximum length"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if kind == design.StringKind && v.Pattern != "" {
+		if re, err := regexp.Compile(v.Pattern); err == nil {
+			if val, ok := firstString(func(s string) bool { return !re.MatchString(s) }); ok {
+				if err := add(val, "pattern"); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if kind == design.StringKind && v.Format != "" {
+		invalid := func(s string) bool { return goa.ValidateFormat(goa.Format(v.Format), s) != nil }
+		if val, ok := firstString(invalid); ok {
+			if err := add(val, "format"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return mutations, nil
+}
+
+// invalidEnumValue returns a value of the given kind that is not one of the enum values, nil if
+// the kind is neither a string nor a number.
+func invalidEnumValue(kind design.Kind, values []interface{}) interface{} {
+	switch kind {
+	case design.StringKind:
+		val := "invalid"
+		for containsValue(values, val) {
+			val += "!"
+		}
+		return val
+	case design.IntegerKind, design.NumberKind:
+		max := math.Inf(-1)
+		for _, v := range values {
+			if f, ok := toFloat(v); ok && f > max {
+				max = f
+			}
+		}
+		if math.IsInf(max, -1) {
+			return nil
+		}
+		if kind == design.IntegerKind {
+			return int(math.Floor(max)) + 1
+		}
+		return max + 1
+	}
+	return nil
+}
+
+// containsValue returns true if values contains val.
+func containsValue(values []interface{}, val interface{}) bool {
+	for _, v := range values {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+// toFloat returns the float64 value of the given numeric enum value.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// lengthValue returns a string or an array of the given length, nil if the attribute is neither.
+func lengthValue(att *design.AttributeDefinition, length int, rand *design.RandomGenerator) interface{} {
+	switch att.Type.Kind() {
+	case design.StringKind:
+		return strings.Repeat("a", length)
+	case design.ArrayKind:
+		elem := att.Type.ToArray().ElemType
+		val := make([]interface{}, length)
+		for i := range val {
+			val[i] = design.DupAtt(elem).GenerateExample(rand, nil)
+		}
+		return val
+	}
+	return nil
+}
+
+// firstString returns the first of a few candidate strings for which invalid returns true.
+func firstString(invalid func(string) bool) (string, bool) {
+	for _, s := range []string{"!", " ", "a", "Z", "0", "-", "invalid value", ""} {
+		if invalid(s) {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// goLiteral returns the Go raw string literal of s, the interpreted string literal if s contains
+// backquotes.
+func goLiteral(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+// propsHelpersT generates the functions shared by the property tests.
+const propsHelpersT = `
+// validatable is implemented by the types that define validations.
+type validatable interface {
+	Validate() error
+}
+
+// propertyAttribute describes the JSON values used to build the values of a type attribute.
+type propertyAttribute struct {
+	Name     string   // Name of the attribute in the JSON representation
+	Required bool     // Whether the attribute is always set
+	Examples []string // JSON encoding of valid values
+}
+
+// propertyMutation describes a change that breaks one of the validations of a type.
+type propertyMutation struct {
+	Attribute  string // Name of the mutated attribute
+	Value      string // JSON encoding of the invalid value, empty to remove the attribute
+	Validation string // Broken validation
+}
+
+// checkProperties checks that the values built from random combinations of the attribute examples
+// pass Validate and that applying any of the mutations to them makes Validate fail. newValue
+// returns a new value of the tested type.
+func checkProperties(t *testing.T, newValue func() validatable, attributes []*propertyAttribute, mutations []*propertyMutation) {
+	build := func(seed int64) map[string]json.RawMessage {
+		r := rand.New(rand.NewSource(seed))
+		fields := make(map[string]json.RawMessage, len(attributes))
+		for _, a := range attributes {
+			if a.Required || r.Intn(2) == 0 {
+				fields[a.Name] = json.RawMessage(a.Examples[r.Intn(len(a.Examples))])
+			}
+		}
+		return fields
+	}
+	validate := func(fields map[string]json.RawMessage) (string, error) {
+		b, err := json.Marshal(fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v := newValue()
+		if err := json.Unmarshal(b, v); err != nil {
+			t.Fatalf("failed to decode %s: %s", b, err)
+		}
+		return string(b), v.Validate()
+	}
+
+	var failure string
+	valid := func(seed int64) bool {
+		val, err := validate(build(seed))
+		if err != nil {
+			failure = val + ": " + err.Error()
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(valid, nil); err != nil {
+		t.Errorf("valid value failed validation: %s", failure)
+	}
+	for _, m := range mutations {
+		invalid := func(seed int64) bool {
+			fields := build(seed)
+			if m.Value == "" {
+				delete(fields, m.Attribute)
+			} else {
+				fields[m.Attribute] = json.RawMessage(m.Value)
+			}
+			val, err := validate(fields)
+			failure = val
+			return err != nil
+		}
+		if err := quick.Check(invalid, nil); err != nil {
+			t.Errorf("value breaking the %s validation of %s passed validation: %s", m.Validation, m.Attribute, failure)
+		}
+	}
+}
+`
+
+// propsT generates the property tests.
+// template input: []*PropTarget
+const propsT = `{{ range . }}
+// {{ .Name }} checks the {{ .TypeName }} validation code against the design validations.
+func {{ .Name }}(t *testing.T) {
+	attributes := []*propertyAttribute{
+{{ range .Attributes }}		{Name: {{ printf "%q" .Name }}, Required: {{ .Required }}, Examples: []string{ {{ range $i, $e := .Examples }}{{ if $i }}, {{ end }}{{ literal $e }}{{ end }} }},
+{{ end }}	}
+	mutations := []*propertyMutation{
+{{ range .Mutations }}		{Attribute: {{ printf "%q" .Attribute }}, Value: {{ literal .Value }}, Validation: {{ printf "%q" .Validation }}},
+{{ end }}	}
+	checkProperties(t, func() validatable { return new({{ .GoType }}) }, attributes, mutations)
+}
+{{ end }}`
@@ -0,0 +1,127 @@
+package genapp_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	genapp "github.com/kyokomi/goa-v1/goagen/gen_app"
+)
+
+var _ = Describe("Generate property tests", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var props bool
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+		Ω(err).ShouldNot(HaveOccurred())
+		props = true
+
+		design.Design = registeredDesign
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {})
+		bottle := apidsl.Type("bottle", func() {
+			apidsl.Attribute("name", design.String, func() {
+				apidsl.Pattern("^[a-z]+$")
+			})
+			apidsl.Attribute("vintage", design.Integer, func() {
+				apidsl.Minimum(1900)
+				apidsl.Maximum(2020)
+			})
+			apidsl.Attribute("color", design.String, func() {
+				apidsl.Enum("red", "white")
+			})
+			apidsl.Attribute("email", design.String, func() {
+				apidsl.Format("email")
+			})
+			apidsl.Attribute("tags", apidsl.ArrayOf(design.String), func() {
+				apidsl.MinLength(1)
+				apidsl.MaxLength(3)
+			})
+			apidsl.Required("name", "vintage")
+		})
+		apidsl.Type("plain", func() {
+			apidsl.Attribute("name", design.String)
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("create", func() {
+				apidsl.Routing(apidsl.POST("/bottles"))
+				apidsl.Payload(bottle)
+				apidsl.Response(design.NoContent)
+			})
+			apidsl.Action("rate", func() {
+				apidsl.Routing(apidsl.PUT("/bottles/:id"))
+				apidsl.Payload(func() {
+					apidsl.Member("rating", design.Number, func() {
+						apidsl.Maximum(5)
+					})
+				})
+				apidsl.Response(design.NoContent)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genapp.NewGenerator(
+			genapp.API(design.Design),
+			genapp.OutDir(filepath.Join(outDir, "app")),
+			genapp.Target("app"),
+			genapp.NoTest(true),
+			genapp.Props(props),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+		delete(codegen.Reserved, "app")
+	})
+
+	It("generates a property test per type with validations", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		propsFile := filepath.Join(outDir, "app", "properties_test.go")
+		Ω(files).Should(ContainElement(propsFile))
+		content, err := ioutil.ReadFile(propsFile)
+		Ω(err).ShouldNot(HaveOccurred())
+		code := string(content)
+		Ω(code).Should(ContainSubstring("func checkProperties(t *testing.T, newValue func() validatable, attributes []*propertyAttribute, mutations []*propertyMutation) {"))
+		Ω(code).Should(ContainSubstring("func TestBottleProperties(t *testing.T) {"))
+		Ω(code).Should(ContainSubstring("func TestRateBottlePayloadProperties(t *testing.T) {"))
+		Ω(code).ShouldNot(ContainSubstring("TestPlainProperties"))
+		Ω(code).Should(ContainSubstring(`{Name: "name", Required: true, Examples: []string{`))
+		Ω(code).Should(ContainSubstring(`{Attribute: "name", Value: ` + "``" + `, Validation: "required"},`))
+		Ω(code).Should(ContainSubstring(`{Attribute: "name", Value: ` + "`\"!\"`" + `, Validation: "pattern"},`))
+		Ω(code).Should(ContainSubstring(`{Attribute: "vintage", Value: ` + "`1899`" + `, Validation: "minimum"},`))
+		Ω(code).Should(ContainSubstring(`{Attribute: "vintage", Value: ` + "`2021`" + `, Validation: "maximum"},`))
+		Ω(code).Should(ContainSubstring(`{Attribute: "color", Value: ` + "`\"invalid\"`" + `, Validation: "enum"},`))
+		Ω(code).Should(ContainSubstring(`{Attribute: "email", Value: ` + "`\"!\"`" + `, Validation: "format"},`))
+		Ω(code).Should(ContainSubstring(`{Attribute: "tags", Value: ` + "`[]`" + `, Validation: "minimum length"},`))
+		Ω(code).Should(ContainSubstring(`Validation: "maximum length"},`))
+		Ω(code).Should(ContainSubstring(`{Attribute: "rating", Value: ` + "`6`" + `, Validation: "maximum"},`))
+		Ω(code).Should(ContainSubstring("checkProperties(t, func() validatable { return new(Bottle) }, attributes, mutations)"))
+	})
+
+	Context("with property tests disabled", func() {
+		BeforeEach(func() {
+			props = false
+		})
+
+		It("does not generate property tests", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(filepath.Join(outDir, "app", "properties_test.go")).ShouldNot(BeAnExistingFile())
+		})
+	})
+})
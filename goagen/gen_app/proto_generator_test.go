@@ -0,0 +1,105 @@
+package genapp_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	genapp "github.com/kyokomi/goa-v1/goagen/gen_app"
+)
+
+var _ = Describe("Generate protocol buffer conversions", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var proto bool
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+		Ω(err).ShouldNot(HaveOccurred())
+		proto = true
+	})
+
+	JustBeforeEach(func() {
+		design.Design = registeredDesign
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {
+			apidsl.Produces("application/json", "application/x-protobuf")
+		})
+		bottle := apidsl.MediaType("application/vnd.bottle", func() {
+			if proto {
+				apidsl.Metadata("proto:message")
+			}
+			apidsl.Attributes(func() {
+				apidsl.Attribute("id", design.Integer)
+				apidsl.Attribute("name", design.String)
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("id")
+				apidsl.Attribute("name")
+			})
+			apidsl.View("tiny", func() {
+				apidsl.Attribute("id")
+			})
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Response(design.OK, bottle)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		_, genErr = genapp.NewGenerator(
+			genapp.API(design.Design),
+			genapp.OutDir(filepath.Join(outDir, "app")),
+			genapp.Target("app"),
+			genapp.NoTest(true),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+		delete(codegen.Reserved, "app")
+	})
+
+	It("generates the conversion hooks of all the views", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "media_types.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		code := string(content)
+		Ω(code).Should(ContainSubstring(`"google.golang.org/protobuf/proto"`))
+		Ω(code).Should(ContainSubstring("var BottleToProto func(*Bottle) proto.Message"))
+		Ω(code).Should(ContainSubstring("func (mt *Bottle) ToProto() proto.Message {"))
+		Ω(code).Should(ContainSubstring("var BottleTinyToProto func(*BottleTiny) proto.Message"))
+	})
+
+	It("registers the protobuf encoder", func() {
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring(`service.Encoder.Register(protobuf.NewEncoder, "application/x-protobuf")`))
+	})
+
+	Context("without proto metadata", func() {
+		BeforeEach(func() {
+			proto = false
+		})
+
+		It("does not generate conversion hooks", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "media_types.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).ShouldNot(ContainSubstring("ToProto"))
+			Ω(string(content)).ShouldNot(ContainSubstring("protobuf"))
+		})
+	})
+})
@@ -16,7 +16,7 @@ import (
 
 func makeTestDir(g *Generator, apiName string) (outDir string, err error) {
 	outDir = filepath.Join(g.OutDir, "test")
-	if err = os.RemoveAll(outDir); err != nil {
+	if err = codegen.RemoveAll(outDir); err != nil {
 		return
 	}
 	if err = os.MkdirAll(outDir, 0755); err != nil {
@@ -35,6 +35,7 @@ type TestMethod struct {
 	ControllerName    string
 	ContextVarName    string
 	ContextType       string
+	ContextFirst      bool
 	RouteVerb         string
 	FullPath          string
 	Status            int
@@ -44,6 +45,10 @@ type TestMethod struct {
 	QueryParams       []*ObjectType
 	Headers           []*ObjectType
 	Payload           *ObjectType
+	Multipart         bool
+	Fields            []*ObjectType
+	Files             []*FileParam
+	MountFunc         string
 	reservedNames     map[string]bool
 }
 
@@ -65,6 +70,13 @@ type ObjectType struct {
 	Validatable bool
 }
 
+// FileParam describes a file sent in the multipart request body built by a test method.
+type FileParam struct {
+	Label string
+	Name  string
+	Field string
+}
+
 func (g *Generator) generateResourceTest() error {
 	if len(g.API.Resources) == 0 {
 		return nil
@@ -72,7 +84,8 @@ func (g *Generator) generateResourceTest() error {
 	funcs := template.FuncMap{
 		"isSlice": isSlice,
 	}
-	testTmpl := template.Must(template.New("test").Funcs(funcs).Parse(testTmpl))
+	testTmpl := template.Must(template.New("test").Funcs(funcs).Parse(codegen.Template("app", "test", testTmpl)))
+	serveTmpl := template.Must(template.New("serve").Funcs(funcs).Parse(codegen.Template("app", "serve", serveTmpl)))
 	outDir, err := makeTestDir(g, g.API.Name)
 	if err != nil {
 		return err
@@ -113,24 +126,38 @@ func (g *Generator) generateResourceTest() error {
 			}
 		}()
 		title := fmt.Sprintf("%s: %s TestHelpers", g.API.Context(), res.Name)
-		if err = file.WriteHeader(title, "test", imports); err != nil {
+		resImports := append([]*codegen.ImportSpec{}, imports...)
+		res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload != nil {
+				if _, path := codegen.ExternalType(a.Payload.AttributeDefinition); path != "" {
+					resImports = append(resImports, codegen.SimpleImport(path))
+				}
+			}
+			return nil
+		})
+		if err = file.WriteHeader(title, "test", resImports); err != nil {
 			return err
 		}
 
-		var methods []*TestMethod
+		var methods, serveMethods []*TestMethod
 
 		if err = res.IterateActions(func(action *design.ActionDefinition) error {
+			if !action.WebSocket() {
+				for routeIndex, route := range action.Routes {
+					serveMethods = append(serveMethods, g.createServeMethod(res, action, route, routeIndex))
+				}
+			}
 			if err := action.IterateResponses(func(response *design.ResponseDefinition) error {
 				if response.Status == 101 { // SwitchingProtocols, Don't currently handle WebSocket endpoints
 					return nil
 				}
 				for routeIndex, route := range action.Routes {
-					mediaType := design.Design.MediaTypeWithIdentifier(response.MediaType)
+					mediaType := g.API.MediaTypeWithIdentifier(response.MediaType)
 					if mediaType == nil {
-						methods = append(methods, g.createTestMethod(res, action, response, route, routeIndex, nil, nil))
+						methods = appendTestMethod(methods, action, g.createTestMethod(res, action, response, route, routeIndex, nil, nil))
 					} else {
 						if err := mediaType.IterateViews(func(view *design.ViewDefinition) error {
-							methods = append(methods, g.createTestMethod(res, action, response, route, routeIndex, mediaType, view))
+							methods = appendTestMethod(methods, action, g.createTestMethod(res, action, response, route, routeIndex, mediaType, view))
 							return nil
 						}); err != nil {
 							return err
@@ -146,7 +173,10 @@ func (g *Generator) generateResourceTest() error {
 			return err
 		}
 		g.genfiles = append(g.genfiles, filename)
-		err = testTmpl.Execute(file, methods)
+		if err = testTmpl.Execute(file, methods); err != nil {
+			return
+		}
+		err = serveTmpl.Execute(file, serveMethods)
 		return
 	})
 }
@@ -208,19 +238,7 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 	query = queryParams(action)
 	header = headers(action, resource.Headers)
 
-	if action.Payload != nil {
-		payload = &ObjectType{}
-		payload.Name = "payload"
-		payload.Type = fmt.Sprintf("%s.%s", g.Target, codegen.Goify(action.Payload.TypeName, true))
-		if !action.Payload.IsPrimitive() && !action.Payload.IsArray() && !action.Payload.IsHash() {
-			payload.Pointer = "*"
-		}
-
-		validate := g.validator.Code(action.Payload.AttributeDefinition, false, false, false, "payload", "raw", 1, false)
-		if validate != "" {
-			payload.Validatable = true
-		}
-	}
+	payload = g.payloadObject(action)
 
 	return &TestMethod{
 		Name:              fmt.Sprintf("%s%s%s%s%s", actionName, ctrlName, respQualifier, routeQualifier, viewQualifier),
@@ -235,7 +253,8 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 		ReturnsErrorMedia: mediaType == design.ErrorMedia,
 		ControllerName:    fmt.Sprintf("%s.%sController", g.Target, ctrlName),
 		ContextVarName:    fmt.Sprintf("%sCtx", varName),
-		ContextType:       fmt.Sprintf("%s.New%s%sContext", g.Target, actionName, ctrlName),
+		ContextType:       fmt.Sprintf("%s.New%s", g.Target, g.contextName(action)),
+		ContextFirst:      g.ContextFirst,
 		RouteVerb:         route.Verb,
 		Status:            response.Status,
 		FullPath:          goPathFormat(route.FullPath()),
@@ -243,6 +262,125 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 	}
 }
 
+// payloadObject builds the template data structure of the payload of the given action, nil if the
+// action has no payload.
+func (g *Generator) payloadObject(action *design.ActionDefinition) *ObjectType {
+	if action.Payload == nil {
+		return nil
+	}
+	payload := &ObjectType{}
+	payload.Name = "payload"
+	payload.Type = fmt.Sprintf("%s.%s", g.Target, codegen.Goify(action.Payload.TypeName, true))
+	if tname, _ := codegen.ExternalType(action.Payload.AttributeDefinition); tname != "" {
+		payload.Type = tname
+	}
+	if !action.Payload.IsPrimitive() && !action.Payload.IsArray() && !action.Payload.IsHash() {
+		payload.Pointer = "*"
+	}
+
+	validate := g.validator.Code(action.Payload.AttributeDefinition, false, false, false, "payload", "raw", 1, false)
+	if validate != "" {
+		payload.Validatable = true
+	}
+	return payload
+}
+
+// createServeMethod builds the template data structure of the Serve test method of the given
+// action route.
+func (g *Generator) createServeMethod(resource *design.ResourceDefinition, action *design.ActionDefinition,
+	route *design.RouteDefinition, routeIndex int) *TestMethod {
+
+	actionName := codegen.Goify(action.Name, true)
+	ctrlName := codegen.Goify(resource.Name, true)
+	path := pathParams(action, route)
+	query := queryParams(action)
+	header := headers(action, resource.Headers)
+	payload := g.payloadObject(action)
+	comment := "mounts the given controller on a new mux of the service and serves a request\n" +
+		"// to the " + actionName + " action with the given parameters"
+	if payload != nil {
+		comment += " and payload"
+	}
+	comment += ".\n// The request goes through the service and controller middleware, it returns the response\n" +
+		"// recorder so it's possible to inspect the status code, headers and body written by them."
+	m := &TestMethod{
+		Name:           fmt.Sprintf("Serve%s%s%s", actionName, ctrlName, suffixRoute(action.Routes, routeIndex)),
+		ActionName:     actionName,
+		ResourceName:   ctrlName,
+		Comment:        comment,
+		Params:         path,
+		QueryParams:    query,
+		Headers:        header,
+		Payload:        payload,
+		ControllerName: fmt.Sprintf("%s.%sController", g.Target, ctrlName),
+		MountFunc:      fmt.Sprintf("%s.Mount%sController", g.Target, ctrlName),
+		RouteVerb:      route.Verb,
+		FullPath:       goPathFormat(route.FullPath()),
+		reservedNames:  reservedNames(path, query, header, payload, nil),
+	}
+	if action.PayloadMultipart && payload != nil && payload.Pointer != "" {
+		setMultipartFields(m, action)
+	}
+	return m
+}
+
+// appendTestMethod appends the test method m and its multipart variant if any to methods.
+func appendTestMethod(methods []*TestMethod, action *design.ActionDefinition, m *TestMethod) []*TestMethod {
+	methods = append(methods, m)
+	if mp := multipartTestMethod(action, m); mp != nil {
+		methods = append(methods, mp)
+	}
+	return methods
+}
+
+// multipartTestMethod returns the variant of the test method m that sends the payload and the
+// content of its files in a multipart request body, nil if the action payload is not a multipart
+// form.
+func multipartTestMethod(action *design.ActionDefinition, m *TestMethod) *TestMethod {
+	if !action.PayloadMultipart || m.Payload == nil || m.Payload.Pointer == "" {
+		return nil
+	}
+	mp := *m
+	mp.Name += "Multipart"
+	mp.Comment = strings.Replace(m.Comment, " and payload.", " and payload sent with the content of\n// the files in a multipart request body.", 1)
+	mp.reservedNames = make(map[string]bool, len(m.reservedNames))
+	for n := range m.reservedNames {
+		mp.reservedNames[n] = true
+	}
+	setMultipartFields(&mp, action)
+	return &mp
+}
+
+// setMultipartFields makes the test method m send the payload of the action and the content of
+// its files in a multipart request body.
+func setMultipartFields(m *TestMethod, action *design.ActionDefinition) {
+	m.Multipart = true
+	obj := action.Payload.ToObject()
+	var names []string
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		att := obj[n]
+		field := fmt.Sprintf("%s.%s", m.Payload.Name, codegen.GoifyAtt(att, n, true))
+		switch {
+		case att.Type.Kind() == design.FileKind:
+			name := m.Escape(codegen.Goify(n, false))
+			m.Files = append(m.Files, &FileParam{Label: n, Name: name, Field: field})
+		case att.Type.IsPrimitive():
+			f := attToObject(n, action.Payload.AttributeDefinition, att)
+			f.Name = field
+			m.Fields = append(m.Fields, f)
+		case att.Type.IsArray() && att.Type.ToArray().ElemType.Type.IsPrimitive():
+			f := attToObject(n, action.Payload.AttributeDefinition, att)
+			f.Label = n + "[]"
+			f.Name = field
+			m.Fields = append(m.Fields, f)
+		}
+	}
+}
+
 // pathParams returns the path params for the given action and route.
 func pathParams(action *design.ActionDefinition, route *design.RouteDefinition) []*ObjectType {
 	return paramFromNames(action, route.Params())
@@ -355,7 +493,41 @@ var convertParamTmpl = `{{ if eq .Type "string" }}		sliceVal := []string{ {{ if
 */}}{{ else if eq .Type "time.Time" }}		sliceVal := []string{ {{ if .Pointer }}(*{{ end }}{{ .Name }}{{ if .Pointer }}){{ end }}.Format(time.RFC3339)}{{/*
 */}}{{ else }}		sliceVal := []string{fmt.Sprintf("%v", {{ if .Pointer }}*{{ end }}{{ .Name }})}{{ end }}`
 
-var testTmpl = `{{ define "convertParam" }}` + convertParamTmpl + `{{ end }}` + `
+// multipartBodyTmpl generates the code that encodes the payload and files of a multipart test
+// method, it expects the $test, $body and $contentType variables to be defined.
+var multipartBodyTmpl = `	// Encode multipart body
+	{{ $p := $test.Escape "p" }}{{ $p }} := {{ $test.Payload.Type }}{}
+	if {{ $test.Payload.Name }} != nil {
+		{{ $p }} = *{{ $test.Payload.Name }}
+	}
+	{{ $test.Payload.Name }} = &{{ $p }}
+	{{ $values := $test.Escape "values" }}{{ $values }} := url.Values{}
+{{ range $field := $test.Fields }}{{ if $field.Pointer }}	if {{ $field.Name }} != nil {{ end }}{
+{{ template "convertParam" $field }}
+		{{ $values }}[{{ printf "%q" $field.Label }}] = sliceVal
+	}
+{{ end }}	{{ $body }}, {{ $contentType }}, {{ $err := $test.Escape "err" }}{{ $err }} := goatest.MultipartBody({{ $values }}, map[string]io.Reader{ {{ range $file := $test.Files }}
+		{{ printf "%q" $file.Label }}: {{ $file.Name }},{{ end }}
+	})
+	if {{ $err }} != nil {
+		panic("invalid test " + {{ $err }}.Error()) // bug
+	}
+`
+
+var validatePayloadTmpl = `{{ $test := . }}
+	// Validate payload
+	{{ $err := $test.Escape "err" }}{{ $err }} := {{ $test.Payload.Name }}.Validate()
+	if {{ $err }} != nil {
+		{{ $e := $test.Escape "e" }}{{ $e }}, {{ $ok := $test.Escape "ok" }}{{ $ok }} := {{ $err }}.(goa.ServiceError)
+		if !{{ $ok }} {
+			panic({{ $err }}) // bug
+		}
+{{ if not $test.ReturnsErrorMedia }}		t.Errorf("unexpected payload validation error: %+v", {{ $e }})
+{{ end }}{{ if $test.ReturnType }}		return nil, {{ if $test.ReturnsErrorMedia }}{{ $e }}{{ else }}nil{{ end }}{{ else }}return nil{{ end }}
+	}
+`
+
+var testTmpl = `{{ define "convertParam" }}` + convertParamTmpl + `{{ end }}` + `{{ define "validatePayload" }}{{ if .Payload.Validatable }}` + validatePayloadTmpl + `{{ end }}{{ end }}` + `
 {{ range $test := . }}
 // {{ $test.Name }} {{ $test.Comment }}
 // If ctx is nil then context.Background() is used.
@@ -364,7 +536,8 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 */}}{{ range $param := $test.Params }}, {{ $param.Name }} {{ $param.Pointer }}{{ $param.Type }}{{ end }}{{/*
 */}}{{ range $param := $test.QueryParams }}, {{ $param.Name }} {{ $param.Pointer }}{{ $param.Type }}{{ end }}{{/*
 */}}{{ range $header := $test.Headers }}, {{ $header.Name }} {{ $header.Pointer }}{{ $header.Type }}{{ end }}{{/*
-*/}}{{ if $test.Payload }}, {{ $test.Payload.Name }} {{ $test.Payload.Pointer }}{{ $test.Payload.Type }}{{ end }}){{/*
+*/}}{{ if $test.Payload }}, {{ $test.Payload.Name }} {{ $test.Payload.Pointer }}{{ $test.Payload.Type }}{{ end }}{{/*
+*/}}{{ range $file := $test.Files }}, {{ $file.Name }} io.Reader{{ end }}){{/*
 */}} (http.ResponseWriter{{ if $test.ReturnType }}, {{ $test.ReturnType.Pointer }}{{ $test.ReturnType.Type }}{{ end }}) {
 	// Setup service
 	var (
@@ -382,18 +555,12 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
 		service.Encoder.Register({{ $newEncoder }}, "*/*")
 	}
-{{ if $test.Payload }}{{ if $test.Payload.Validatable }}
-	// Validate payload
-	{{ $err := $test.Escape "err" }}{{ $err }} := {{ $test.Payload.Name }}.Validate()
-	if {{ $err }} != nil {
-		{{ $e := $test.Escape "e" }}{{ $e }}, {{ $ok := $test.Escape "ok" }}{{ $ok }} := {{ $err }}.(goa.ServiceError)
-		if !{{ $ok }} {
-			panic({{ $err }}) // bug
-		}
-{{ if not $test.ReturnsErrorMedia }}		t.Errorf("unexpected payload validation error: %+v", {{ $e }})
-{{ end }}{{ if $test.ReturnType }}		return nil, {{ if $test.ReturnsErrorMedia }}{{ $e }}{{ else }}nil{{ end }}{{ else }}return nil{{ end }}
+	{{ $codec := $test.Escape "codec" }}{{ $codec }} := goatest.ContextCodec(ctx)
+	if {{ $codec }} != nil {
+		{{ $codec }}.Register(service)
 	}
-{{ end }}{{ end }}
+{{ if and $test.Payload (not $test.Multipart) }}{{ template "validatePayload" $test }}{{ end }}{{ $body := $test.Escape "body" }}{{ $contentType := $test.Escape "contentType" }}{{ if $test.Multipart }}
+` + multipartBodyTmpl + `{{ end }}
 	// Setup request context
 	{{ $rw := $test.Escape "rw" }}{{ $rw }} := httptest.NewRecorder()
 {{ $query := $test.Escape "query" }}{{ if $test.QueryParams}}	{{ $query }} := url.Values{}
@@ -405,11 +572,23 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 		Path: fmt.Sprintf({{ printf "%q" $test.FullPath }}{{ range $param := $test.Params }}, {{ $param.Name }}{{ end }}),
 {{ if $test.QueryParams }}		RawQuery: {{ $query }}.Encode(),
 {{ end }}	}
-	{{ $req := $test.Escape "req" }}{{ $req }}, {{ $err := $test.Escape "err" }}{{ $err }}:= http.NewRequest("{{ $test.RouteVerb }}", {{ $u }}.String(), nil)
+	{{ $req := $test.Escape "req" }}{{ $req }}, {{ $err := $test.Escape "err" }}{{ $err }}:= http.NewRequest("{{ $test.RouteVerb }}", {{ $u }}.String(), {{ if $test.Multipart }}{{ $body }}{{ else }}nil{{ end }})
 	if {{ $err }} != nil {
 		panic("invalid test " + {{ $err }}.Error()) // bug
 	}
-{{ range $header := $test.Headers }}{{ if $header.Pointer }}	if {{ $header.Name }} != nil {{ end }}{
+{{ if $test.Multipart }}	{{ $req }}.Header.Set("Content-Type", {{ $contentType }})
+	if {{ $err := $test.Escape "err" }}{{ $err }} := {{ $req }}.ParseMultipartForm(32 << 20); {{ $err }} != nil {
+		panic("invalid test " + {{ $err }}.Error()) // bug
+	}
+{{ range $file := $test.Files }}	if _, {{ $fh := $test.Escape "fh" }}{{ $fh }}, {{ $err := $test.Escape "err" }}{{ $err }} := {{ $req }}.FormFile({{ printf "%q" $file.Label }}); {{ $err }} == nil {
+		{{ $file.Field }} = {{ $fh }}
+	}
+{{ end }}{{ template "validatePayload" $test }}{{ else if $test.Payload }}	if {{ $codec }} != nil {
+		if {{ $err := $test.Escape "err" }}{{ $err }} := {{ $codec }}.EncodeRequest({{ $req }}, {{ $test.Payload.Name }}); {{ $err }} != nil {
+			t.Fatalf("failed to encode payload: %s", {{ $err }})
+		}
+	}
+{{ end }}{{ range $header := $test.Headers }}{{ if $header.Pointer }}	if {{ $header.Name }} != nil {{ end }}{
 {{ template "convertParam" $header }}
 		{{ $req }}.Header[{{ printf "%q" $header.Label }}] = sliceVal
 	}
@@ -432,10 +611,17 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 {{ if not $test.ReturnsErrorMedia }}		t.Errorf("unexpected parameter validation error: %+v", {{ $e }})
 {{ end }}{{ if $test.ReturnType }}		return nil, {{ if $test.ReturnsErrorMedia }}{{ $e }}{{ else }}nil{{ end }}{{ else }}return nil{{ end }}
 	}
-	{{ if $test.Payload }}{{ $test.ContextVarName }}.Payload = {{ $test.Payload.Name }}{{ end }}
+	{{ if $test.Payload }}{{ $test.ContextVarName }}.Payload = {{ $test.Payload.Name }}{{ if not $test.Multipart }}
+	if {{ $codec }} != nil {
+		{{ $decoded := $test.Escape "decoded" }}var {{ $decoded }} {{ $test.Payload.Pointer }}{{ $test.Payload.Type }}
+		if {{ $err := $test.Escape "err" }}{{ $err }} := service.DecodeRequest({{ $req }}, &{{ $decoded }}); {{ $err }} != nil {
+			t.Fatalf("failed to decode payload: %s", {{ $err }})
+		}
+		{{ $test.ContextVarName }}.Payload = {{ $decoded }}
+	}{{ end }}{{ end }}
 
 	// Perform action
-	{{ $err }} = ctrl.{{ $test.ActionName}}({{ $test.ContextVarName }})
+	{{ $err }} = ctrl.{{ $test.ActionName}}({{ if $test.ContextFirst }}{{ $goaCtx }}, {{ end }}{{ $test.ContextVarName }})
 
 	// Validate response
 	if {{ $err }} != nil {
@@ -451,7 +637,14 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 		if !{{ $ok }} {
 			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of {{ $test.ReturnType.Type }}", {{ $resp }}, {{ $resp }})
 		}
-{{ if $test.ReturnType.Validatable }}		{{ $err }} = mt.Validate()
+{{ if not $test.ReturnsErrorMedia }}		if {{ $codec }} != nil {
+			{{ $decoded := $test.Escape "decoded" }}var {{ $decoded }} {{ $test.ReturnType.Pointer }}{{ $test.ReturnType.Type }}
+			if {{ $err := $test.Escape "err" }}{{ $err }} := {{ $codec }}.RoundTrip(mt, &{{ $decoded }}); {{ $err }} != nil {
+				t.Fatalf("failed to encode response media: %s", {{ $err }})
+			}
+			mt = {{ $decoded }}
+		}
+{{ end }}{{ if $test.ReturnType.Validatable }}		{{ $err }} = mt.Validate()
 		if {{ $err }} != nil {
 			t.Errorf("invalid response media type: %s", {{ $err }})
 		}
@@ -461,3 +654,64 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 	return {{ $rw }}{{ if $test.ReturnType }}, mt{{ end }}
 }
 {{ end }}`
+
+var serveTmpl = `{{ define "convertParam" }}` + convertParamTmpl + `{{ end }}` + `
+{{ range $test := . }}
+// {{ $test.Name }} {{ $test.Comment }}
+// ctx is only used to retrieve the codec set with goatest.WithCodec, JSON is used by default.
+// If service is nil then a default service is created.
+func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl {{ $test.ControllerName}}{{/*
+*/}}{{ range $param := $test.Params }}, {{ $param.Name }} {{ $param.Pointer }}{{ $param.Type }}{{ end }}{{/*
+*/}}{{ range $param := $test.QueryParams }}, {{ $param.Name }} {{ $param.Pointer }}{{ $param.Type }}{{ end }}{{/*
+*/}}{{ range $header := $test.Headers }}, {{ $header.Name }} {{ $header.Pointer }}{{ $header.Type }}{{ end }}{{/*
+*/}}{{ if $test.Payload }}, {{ $test.Payload.Name }} {{ $test.Payload.Pointer }}{{ $test.Payload.Type }}{{ end }}{{/*
+*/}}{{ range $file := $test.Files }}, {{ $file.Name }} io.Reader{{ end }}) *httptest.ResponseRecorder {
+	// Setup service
+	if service == nil {
+		service = goa.New("test")
+		service.WithLogger(goa.NewLogger(log.New(io.Discard, "", log.Ltime)))
+	}
+	{{ $mux := $test.Escape "mux" }}{{ $mux }} := service.Mux
+	service.Mux = goa.NewMux()
+	defer func() { service.Mux = {{ $mux }} }()
+	{{ $test.MountFunc }}(service, ctrl)
+{{ $body := $test.Escape "body" }}{{ $contentType := $test.Escape "contentType" }}{{ if $test.Multipart }}
+` + multipartBodyTmpl + `{{ end }}
+	// Setup request
+{{ $query := $test.Escape "query" }}{{ if $test.QueryParams}}	{{ $query }} := url.Values{}
+{{ range $param := $test.QueryParams }}{{ if $param.Pointer }}	if {{ $param.Name }} != nil {{ end }}{
+{{ template "convertParam" $param }}
+		{{ $query }}[{{ printf "%q" $param.Label }}] = sliceVal
+	}
+{{ end }}{{ end }}	{{ $u := $test.Escape "u" }}{{ $u }}:= &url.URL{
+		Path: fmt.Sprintf({{ printf "%q" $test.FullPath }}{{ range $param := $test.Params }}, {{ $param.Name }}{{ end }}),
+{{ if $test.QueryParams }}		RawQuery: {{ $query }}.Encode(),
+{{ end }}	}
+	{{ $req := $test.Escape "req" }}{{ $req }}, {{ $err := $test.Escape "err" }}{{ $err }}:= http.NewRequest("{{ $test.RouteVerb }}", {{ $u }}.String(), {{ if $test.Multipart }}{{ $body }}{{ else }}nil{{ end }})
+	if {{ $err }} != nil {
+		panic("invalid test " + {{ $err }}.Error()) // bug
+	}
+{{ range $header := $test.Headers }}{{ if $header.Pointer }}	if {{ $header.Name }} != nil {{ end }}{
+{{ template "convertParam" $header }}
+		{{ $req }}.Header[{{ printf "%q" $header.Label }}] = sliceVal
+	}
+{{ end }}{{ $codec := $test.Escape "codec" }}{{ if $test.Multipart }}	{{ $req }}.Header.Set("Content-Type", {{ $contentType }})
+{{ else if $test.Payload }}	{{ $codec }} := goatest.ContextCodec(ctx)
+	if {{ $codec }} == nil {
+		{{ $codec }} = goatest.JSONCodec
+	}
+	{{ if $test.Payload.Pointer }}if {{ $test.Payload.Name }} != nil {{ end }}{
+		if {{ $err := $test.Escape "err" }}{{ $err }} := {{ $codec }}.EncodeRequest({{ $req }}, {{ $test.Payload.Name }}); {{ $err }} != nil {
+			t.Fatalf("failed to encode payload: %s", {{ $err }})
+		}
+	}
+{{ else }}	if {{ $codec }} := goatest.ContextCodec(ctx); {{ $codec }} != nil {
+		{{ $req }}.Header.Set("Accept", {{ $codec }}.ContentType)
+	}
+{{ end }}
+	// Serve request
+	{{ $rw := $test.Escape "rw" }}{{ $rw }} := httptest.NewRecorder()
+	service.Mux.ServeHTTP({{ $rw }}, {{ $req }})
+	return {{ $rw }}
+}
+{{ end }}`
@@ -63,6 +63,17 @@ var _ = Describe("Generate", func() {
 				TypeName:            "CustomName",
 			}
 
+			uploadType := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{
+						"file": &design.AttributeDefinition{Type: design.File},
+						"name": &design.AttributeDefinition{Type: design.String},
+					},
+					Validation: &dslengine.ValidationDefinition{Required: []string{"file"}},
+				},
+				TypeName: "UploadPayload",
+			}
+
 			intAttr := &design.AttributeDefinition{
 				Type:       design.Object{"foo": &design.AttributeDefinition{Type: design.Integer}},
 				Validation: &dslengine.ValidationDefinition{Required: []string{"foo"}},
@@ -173,6 +184,24 @@ var _ = Describe("Generate", func() {
 									},
 								},
 							},
+							"upload": {
+								Name:   "upload",
+								Params: &design.AttributeDefinition{Type: design.Object{}},
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "POST",
+										Path: "/upload",
+									},
+								},
+								Payload:          uploadType,
+								PayloadMultipart: true,
+								Responses: map[string]*design.ResponseDefinition{
+									"noContent": {
+										Name:   "noContent",
+										Status: 204,
+									},
+								},
+							},
 						},
 					},
 				},
@@ -258,6 +287,44 @@ var _ = Describe("Generate", func() {
 			Ω(content).Should(ContainSubstring(", payload app.CustomName)"))
 		})
 
+		It("generates multipart variants of the test methods of multipart actions", func() {
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(content).Should(ContainSubstring("UploadFooNoContent(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.FooController, optionalResourceHeader *int, requiredResourceHeader string, payload *app.UploadPayload) http.ResponseWriter"))
+			Ω(content).Should(ContainSubstring("UploadFooNoContentMultipart(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.FooController, optionalResourceHeader *int, requiredResourceHeader string, payload *app.UploadPayload, file io.Reader) http.ResponseWriter"))
+			Ω(content).Should(ContainSubstring(`values["name"] = sliceVal`))
+			Ω(content).Should(ContainSubstring(`"file": file,`))
+			Ω(content).Should(ContainSubstring(`req.Header.Set("Content-Type", contentType)`))
+			Ω(content).Should(ContainSubstring(`req.FormFile("file")`))
+			Ω(content).Should(ContainSubstring("payload.File = fh"))
+			Ω(content).ShouldNot(ContainSubstring("GetFooOKMultipart"))
+		})
+
+		It("generates Serve test methods running the requests through the mounted controller", func() {
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(content).Should(ContainSubstring("func ServeShowFoo(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.FooController, param *int, uuid *uuid.UUID, required time.Time"))
+			Ω(content).Should(ContainSubstring("func ServeShowFoo1("))
+			Ω(content).Should(ContainSubstring("func ServeGetFoo("))
+			Ω(content).Should(ContainSubstring("func ServeUploadFoo(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.FooController, optionalResourceHeader *int, requiredResourceHeader string, payload *app.UploadPayload, file io.Reader) *httptest.ResponseRecorder"))
+			Ω(content).Should(ContainSubstring("service.Mux = goa.NewMux()"))
+			Ω(content).Should(ContainSubstring("app.MountFooController(service, ctrl)"))
+			Ω(content).Should(ContainSubstring("codec = goatest.JSONCodec"))
+			Ω(content).Should(ContainSubstring("service.Mux.ServeHTTP(rw, req)"))
+		})
+
+		It("encodes payloads with the codec of the context", func() {
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(content).Should(ContainSubstring("codec := goatest.ContextCodec(ctx)"))
+			Ω(content).Should(ContainSubstring("codec.EncodeRequest(req, payload)"))
+			Ω(content).Should(ContainSubstring("var decoded app.CustomName"))
+			Ω(content).Should(ContainSubstring("service.DecodeRequest(req, &decoded)"))
+		})
+
 		It("generates header compliant with https://github.com/golang/go/issues/13560", func() {
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
 			Ω(err).ShouldNot(HaveOccurred())
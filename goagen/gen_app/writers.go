@@ -2,6 +2,7 @@ package genapp
 
 import (
 	"fmt"
+	"mime"
 	"net/http"
 	"regexp"
 	"strings"
@@ -85,18 +86,37 @@ type (
 		API          *design.APIDefinition
 		DefaultPkg   string
 		Security     *design.SecurityDefinition
+		ContextFirst bool // Whether the action takes a context.Context and the request data, e.g. "ListBottleRequest"
+	}
+
+	// PaginatorTemplateData contains the information used by the template to render the
+	// paginator of an action that declares the "page" and "per_page" query string parameters.
+	PaginatorTemplateData struct {
+		Name           string // e.g. "ListBottlePaginator"
+		Context        string // e.g. "ListBottleContext"
+		Receiver       string // Name of the Paginator method receiver, "ctx" or "req"
+		ActionName     string // e.g. "list"
+		Page           string // Name of the context field holding the page number, e.g. "Page"
+		PagePointer    bool   // Whether the page number field is a pointer
+		PerPage        string // Name of the context field holding the page size, e.g. "PerPage"
+		PerPagePointer bool   // Whether the page size field is a pointer
+		DefaultPerPage int    // Page size used when the request does not specify one
+		MaxPerPage     int    // Maximum page size, 0 if the design does not declare one
 	}
 
 	// ControllerTemplateData contains the information required to generate an action handler.
 	ControllerTemplateData struct {
 		API            *design.APIDefinition          // API definition
 		Resource       string                         // Lower case plural resource name, e.g. "bottles"
+		DesignName     string                         // Resource name as defined in the design, e.g. "bottle"
 		Actions        []map[string]interface{}       // Array of actions, each action has keys "Name", "DesignName", "Routes", "Context" and "Unmarshal"
 		FileServers    []*design.FileServerDefinition // File servers
 		Encoders       []*EncoderTemplateData         // Encoder data
 		Decoders       []*EncoderTemplateData         // Decoder data
 		Origins        []*design.CORSDefinition       // CORS policies
 		PreflightPaths []string
+		Tracing        bool // Whether to instrument the action handlers with OpenTelemetry
+		ContextFirst   bool // Whether the actions take a context.Context and the request data
 	}
 
 	// ResourceData contains the information required to generate the resource GoGenerator
@@ -166,6 +186,60 @@ func (c *ContextTemplateData) HasParamAndHeader(name string) bool {
 	return false
 }
 
+// defaultPerPage is the page size used by the paginators when the design does not declare a
+// default value or a maximum for the "per_page" parameter.
+const defaultPerPage = 20
+
+// Paginator returns the data used to render the paginator of the action, nil if the action does
+// not declare integer "page" and "per_page" query string parameters.
+func (c *ContextTemplateData) Paginator() *PaginatorTemplateData {
+	if c.Params == nil {
+		return nil
+	}
+	params := c.Params.Type.ToObject()
+	page, perPage := params["page"], params["per_page"]
+	if page == nil || perPage == nil || page.Type.Kind() != design.IntegerKind || perPage.Type.Kind() != design.IntegerKind {
+		return nil
+	}
+	if c.IsPathParam("page") || c.IsPathParam("per_page") {
+		return nil
+	}
+	name := strings.TrimSuffix(strings.TrimSuffix(c.Name, "Context"), "Request")
+	data := &PaginatorTemplateData{
+		Name:           name + "Paginator",
+		Context:        c.Name,
+		Receiver:       c.Receiver(),
+		ActionName:     c.ActionName,
+		Page:           codegen.GoifyAtt(page, "page", true),
+		PagePointer:    c.Params.IsPrimitivePointer("page"),
+		PerPage:        codegen.GoifyAtt(perPage, "per_page", true),
+		PerPagePointer: c.Params.IsPrimitivePointer("per_page"),
+		DefaultPerPage: defaultPerPage,
+	}
+	if v := perPage.Validation; v != nil && v.Maximum != nil {
+		data.MaxPerPage = int(*v.Maximum)
+		if data.MaxPerPage < data.DefaultPerPage {
+			data.DefaultPerPage = data.MaxPerPage
+		}
+	}
+	switch def := perPage.DefaultValue.(type) {
+	case int:
+		data.DefaultPerPage = def
+	case float64:
+		data.DefaultPerPage = int(def)
+	}
+	return data
+}
+
+// Receiver returns the name of the receiver of the generated methods, "req" if the action takes
+// a context.Context and the request data as separate arguments, "ctx" otherwise.
+func (c *ContextTemplateData) Receiver() string {
+	if c.ContextFirst {
+		return "req"
+	}
+	return "ctx"
+}
+
 // MustValidate returns true if code that checks for the presence of the given param must be
 // generated.
 func (c *ContextTemplateData) MustValidate(name string) bool {
@@ -206,7 +280,7 @@ func NewContextsWriter(filename string) (*ContextsWriter, error) {
 
 // Execute writes the code for the context types to the writer.
 func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
-	if err := w.ExecuteTemplate("context", ctxT, nil, data); err != nil {
+	if err := w.ExecuteTemplate("context", codegen.Template("app", "context", ctxT), nil, data); err != nil {
 		return err
 	}
 	fn := template.FuncMap{
@@ -217,13 +291,21 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 		"isPathParam":        data.IsPathParam,
 		"valueTypeOf":        valueTypeOf,
 		"fromString":         fromString,
+		"customPrimitive":    design.CustomPrimitive,
+		"primitiveParser":    codegen.PrimitiveParser,
 	}
-	if err := w.ExecuteTemplate("new", ctxNewT, fn, data); err != nil {
+	if err := w.ExecuteTemplate("new", codegen.Template("app", "context_new", ctxNewT), fn, data); err != nil {
 		return err
 	}
+	if p := data.Paginator(); p != nil {
+		if err := w.ExecuteTemplate("paginator", codegen.Template("app", "paginator", paginatorT), nil, p); err != nil {
+			return err
+		}
+	}
 	if data.Payload != nil {
-		found := false
-		for _, t := range design.Design.Types {
+		// Payloads that map to existing Go types are not generated
+		found := externalType(data.Payload.AttributeDefinition) != ""
+		for _, t := range data.API.Types {
 			if t.TypeName == data.Payload.TypeName {
 				found = true
 				break
@@ -234,7 +316,7 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 				"finalizeCode":   w.Finalizer.Code,
 				"validationCode": w.Validator.Code,
 			}
-			if err := w.ExecuteTemplate("payload", payloadT, fn, data); err != nil {
+			if err := w.ExecuteTemplate("payload", codegen.Template("app", "payload", payloadT), fn, data); err != nil {
 				return err
 			}
 		}
@@ -250,10 +332,10 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			if mt, ok = resp.Type.(*design.MediaTypeDefinition); !ok {
 				respData["Type"] = resp.Type
 				respData["ContentType"] = resp.MediaType
-				return w.ExecuteTemplate("response", ctxTRespT, nil, respData)
+				return w.ExecuteTemplate("response", codegen.Template("app", "response_type", ctxTRespT), nil, respData)
 			}
 		} else {
-			mt = design.Design.MediaTypeWithIdentifier(resp.MediaType)
+			mt = data.API.MediaTypeWithIdentifier(resp.MediaType)
 		}
 		if mt != nil {
 			var views []string
@@ -283,13 +365,16 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 					base := fmt.Sprintf("%s%s", resp.Name, strings.Title(view))
 					respData["RespName"] = codegen.Goify(base, true)
 				}
-				if err := w.ExecuteTemplate("response", ctxMTRespT, fn, respData); err != nil {
+				if err := w.ExecuteTemplate("response", codegen.Template("app", "response_media_type", ctxMTRespT), fn, respData); err != nil {
 					return err
 				}
 			}
 			return nil
 		}
-		return w.ExecuteTemplate("response", ctxNoMTRespT, nil, respData)
+		if isEventStream(resp.MediaType) {
+			return w.ExecuteTemplate("response", codegen.Template("app", "response_event_stream", ctxSSERespT), nil, respData)
+		}
+		return w.ExecuteTemplate("response", codegen.Template("app", "response_no_media_type", ctxNoMTRespT), nil, respData)
 	})
 }
 
@@ -310,11 +395,16 @@ func NewControllersWriter(filename string) (*ControllersWriter, error) {
 // WriteInitService writes the initService function
 func (w *ControllersWriter) WriteInitService(encoders, decoders []*EncoderTemplateData) error {
 	ctx := map[string]interface{}{
-		"API":      design.Design,
 		"Encoders": encoders,
 		"Decoders": decoders,
 	}
-	return w.ExecuteTemplate("service", serviceT, nil, ctx)
+	return w.ExecuteTemplate("service", codegen.Template("app", "service", serviceT), nil, ctx)
+}
+
+// WriteTracing writes the helper function that instruments the action handlers with
+// OpenTelemetry.
+func (w *ControllersWriter) WriteTracing() error {
+	return w.ExecuteTemplate("tracing", codegen.Template("app", "tracing", tracingT), nil, nil)
 }
 
 // Execute writes the handlers GoGenerator
@@ -323,26 +413,29 @@ func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
 		return nil
 	}
 	for _, d := range data {
-		if err := w.ExecuteTemplate("controller", ctrlT, nil, d); err != nil {
+		if err := w.ExecuteTemplate("controller", codegen.Template("app", "controller", ctrlT), nil, d); err != nil {
 			return err
 		}
-		if err := w.ExecuteTemplate("mount", mountT, nil, d); err != nil {
+		if err := w.ExecuteTemplate("mount", codegen.Template("app", "mount", mountT), nil, d); err != nil {
 			return err
 		}
 		if len(d.Origins) > 0 {
-			if err := w.ExecuteTemplate("handleCORS", handleCORST, nil, d); err != nil {
+			if err := w.ExecuteTemplate("handleCORS", codegen.Template("app", "handle_cors", handleCORST), nil, d); err != nil {
 				return err
 			}
 		}
 		fn := template.FuncMap{
-			"newCoerceData":  newCoerceData,
-			"finalizeCode":   w.Finalizer.Code,
-			"arrayAttribute": arrayAttribute,
-			"validationCode": w.Validator.Code,
-			"valueTypeOf":    valueTypeOf,
-			"fromString":     fromString,
+			"newCoerceData":   newCoerceData,
+			"finalizeCode":    w.Finalizer.Code,
+			"externalType":    externalType,
+			"arrayAttribute":  arrayAttribute,
+			"validationCode":  w.Validator.Code,
+			"valueTypeOf":     valueTypeOf,
+			"fromString":      fromString,
+			"customPrimitive": design.CustomPrimitive,
+			"primitiveParser": codegen.PrimitiveParser,
 		}
-		if err := w.ExecuteTemplate("unmarshal", unmarshalT, fn, d); err != nil {
+		if err := w.ExecuteTemplate("unmarshal", codegen.Template("app", "unmarshal", unmarshalT), fn, d); err != nil {
 			return err
 		}
 	}
@@ -361,7 +454,7 @@ func NewSecurityWriter(filename string) (*SecurityWriter, error) {
 
 // Execute adds the different security schemes and middleware supporting functions.
 func (w *SecurityWriter) Execute(schemes []*design.SecuritySchemeDefinition) error {
-	return w.ExecuteTemplate("security_schemes", securitySchemesT, nil, schemes)
+	return w.ExecuteTemplate("security_schemes", codegen.Template("app", "security_schemes", securitySchemesT), nil, schemes)
 }
 
 // NewResourcesWriter returns a contexts code writer.
@@ -376,7 +469,7 @@ func NewResourcesWriter(filename string) (*ResourcesWriter, error) {
 
 // Execute writes the code for the context types to the writer.
 func (w *ResourcesWriter) Execute(data *ResourceData) error {
-	return w.ExecuteTemplate("resource", resourceT, nil, data)
+	return w.ExecuteTemplate("resource", codegen.Template("app", "resource", resourceT), nil, data)
 }
 
 // NewMediaTypesWriter returns a contexts code writer.
@@ -393,7 +486,10 @@ func NewMediaTypesWriter(filename string) (*MediaTypesWriter, error) {
 func (w *MediaTypesWriter) Execute(mt *design.MediaTypeDefinition) error {
 	var (
 		mLinks *design.UserTypeDefinition
-		fn     = template.FuncMap{"validationCode": w.Validator.Code}
+		fn     = template.FuncMap{
+			"validationCode":  w.Validator.Code,
+			"hasProtoMessage": hasProtoMessage,
+		}
 	)
 	err := mt.IterateViews(func(view *design.ViewDefinition) error {
 		p, links, err := mt.Project(view.Name)
@@ -403,13 +499,19 @@ func (w *MediaTypesWriter) Execute(mt *design.MediaTypeDefinition) error {
 		if err != nil {
 			return err
 		}
-		return w.ExecuteTemplate("mediatype", mediaTypeT, fn, p)
+		if err := w.ExecuteTemplate("mediatype", codegen.Template("app", "media_type", mediaTypeT), fn, p); err != nil {
+			return err
+		}
+		return writeCopyMethods(w.SourceFile, p, "mt", "media type")
 	})
 	if err != nil {
 		return err
 	}
 	if mLinks != nil {
-		if err := w.ExecuteTemplate("mediatypelink", mediaTypeLinkT, fn, mLinks); err != nil {
+		if err := w.ExecuteTemplate("mediatypelink", codegen.Template("app", "media_type_link", mediaTypeLinkT), fn, mLinks); err != nil {
+			return err
+		}
+		if err := writeCopyMethods(w.SourceFile, mLinks, "ut", "type"); err != nil {
 			return err
 		}
 	}
@@ -436,7 +538,10 @@ func (w *UserTypesWriter) Execute(t *design.UserTypeDefinition) error {
 		"finalizeCode":   w.Finalizer.Code,
 		"validationCode": w.Validator.Code,
 	}
-	return w.ExecuteTemplate("types", userTypeT, fn, t)
+	if err := w.ExecuteTemplate("types", codegen.Template("app", "user_type", userTypeT), fn, t); err != nil {
+		return err
+	}
+	return writeCopyMethods(w.SourceFile, t, "ut", "type")
 }
 
 // newCoerceData is a helper function that creates a map that can be given to the "Coerce" template.
@@ -451,6 +556,13 @@ func newCoerceData(name string, att *design.AttributeDefinition, pointer bool, p
 	}
 }
 
+// externalType returns the name of the existing Go type that the given attribute maps to, the
+// empty string if there isn't one.
+func externalType(att *design.AttributeDefinition) string {
+	tname, _ := codegen.ExternalType(att)
+	return tname
+}
+
 // arrayAttribute returns the array element attribute definition.
 func arrayAttribute(a *design.AttributeDefinition) *design.AttributeDefinition {
 	return a.Type.(*design.Array).ElemType
@@ -470,7 +582,7 @@ func valueTypeOf(prefix string, att *design.AttributeDefinition) string {
 		return prefix + "int"
 	case design.NumberKind:
 		return prefix + "float"
-	case design.StringKind:
+	case design.StringKind, design.DecimalKind:
 		return prefix + "string"
 	case design.ArrayKind:
 		return valueTypeOf(prefix+"[]", arrayAttribute(att))
@@ -478,6 +590,9 @@ func valueTypeOf(prefix string, att *design.AttributeDefinition) string {
 		key, elm := hashAttribute(att)
 		return valueTypeOf(prefix+"map["+valueTypeOf("", key)+"]", elm)
 	}
+	if c := design.CustomPrimitive(att.Type); c != nil {
+		return prefix + c.GoType
+	}
 	return prefix + "interface{}"
 }
 
@@ -490,22 +605,40 @@ func fromString(att *design.AttributeDefinition, varName string) string {
 		return "strconv.Atoi(" + varName + ")"
 	case design.NumberKind:
 		return "strconv.ParseFloat(" + varName + ")"
-	case design.StringKind:
+	case design.StringKind, design.DecimalKind:
 		return varName + ", (error)(nil)"
 	case design.ArrayKind:
 	case design.HashKind:
 		return valueTypeOf("", att) + "{}, (error)(nil)"
 	}
+	if design.CustomPrimitive(att.Type) != nil {
+		return codegen.PrimitiveParser(att.Type, varName)
+	}
 	return "(" + valueTypeOf("", att) + ")(nil), (error)(nil)"
 }
 
+// hasProtoMessage returns true if the given media type converts into protocol buffer messages.
+func hasProtoMessage(mt *design.MediaTypeDefinition) bool {
+	_, ok := mt.Metadata["proto:message"]
+	return ok
+}
+
+// isEventStream returns true if the given response media type identifier is the Server-Sent
+// Events content type.
+func isEventStream(identifier string) bool {
+	mt, _, err := mime.ParseMediaType(identifier)
+	return err == nil && mt == "text/event-stream"
+}
+
 const (
 	// ctxT generates the code for the context data type.
 	// template input: *ContextTemplateData
-	ctxT = `// {{ .Name }} provides the {{ .ResourceName }} {{ .ActionName }} action context.
+	ctxT = `{{ if .ContextFirst }}// {{ .Name }} provides the {{ .ResourceName }} {{ .ActionName }} action request data.
+type {{ .Name }} struct {
+{{ else }}// {{ .Name }} provides the {{ .ResourceName }} {{ .ActionName }} action context.
 type {{ .Name }} struct {
 	context.Context
-	*goa.ResponseData
+{{ end }}	*goa.ResponseData
 	*goa.RequestData
 {{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}{{ if not ($.HasParamAndHeader $name) }}{{/*
 */}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Headers.IsPrimitivePointer $name) }}*{{ end }}{{ gotyperef .Type nil 0 false }}
@@ -603,6 +736,20 @@ type {{ .Name }} struct {
 {{ tabs .Depth }}} else {
 {{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", "{{ .Name }}", "file"))
 {{ tabs .Depth }}}
+{{ else if eq .Attribute.Type.Kind 14 }}{{/*
+
+*/}}{{/* DecimalType */}}{{/*
+*/}}{{ tabs .Depth }}{{ .Pkg }} = {{ if .Pointer }}&{{ end }}raw{{ goify .Name true }}
+{{ else if customPrimitive .Attribute.Type }}{{/*
+
+*/}}{{/* CustomPrimitiveType */}}{{/*
+*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := {{ primitiveParser .Attribute.Type (printf "raw%s" (goify .Name true)) }}; err2 == nil {
+{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
+{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
+{{ tabs .Depth }}} else {
+{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "{{ (customPrimitive .Attribute.Type).Name }}"))
+{{ tabs .Depth }}}
 {{ end }}`
 
 	// ctxNewT generates the code for the context factory method.
@@ -616,7 +763,7 @@ func New{{ .Name }}(ctx context.Context, r *http.Request, service *goa.Service)
 	resp.Service = service
 	req := goa.ContextRequest(ctx)
 	req.Request = r
-	rctx := {{ .Name }}{Context: ctx, ResponseData: resp, RequestData: req}{{/*
+	rctx := {{ .Name }}{ {{- if not .ContextFirst }}Context: ctx, {{ end }}ResponseData: resp, RequestData: req}{{/*
 */}}
 {{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}	header{{ goify $name true }} := req.Header["{{ canonicalHeaderKey $name }}"]
 {{ $mustValidate := $.Headers.IsRequired $name }}{{ if $mustValidate }}	if len(header{{ goify $name true }}) == 0 {
@@ -664,46 +811,144 @@ func New{{ .Name }}(ctx context.Context, r *http.Request, service *goa.Service)
 */}}{{ if $validation }}{{ $validation }}{{ end }}{{ end }}	}
 {{ end }}{{ end }}{{/* if .Params */}}	return &rctx, err
 }
+`
+
+	// paginatorT generates the paginator of an action.
+	// template input: *PaginatorTemplateData
+	paginatorT = `// {{ .Name }} computes the range of items of the page requested by the {{ .ActionName }} action and
+// the URLs of the adjacent pages.
+type {{ .Name }} struct {
+	// Page is the requested page number, starting at 1.
+	Page int
+	// PerPage is the number of items per page.
+	PerPage int
+	url     *url.URL
+}
+
+// Paginator returns the paginator of the requested page. The page size defaults to {{ .DefaultPerPage }}{{ if .MaxPerPage }} and
+// is clamped to {{ .MaxPerPage }}{{ end }}.
+func ({{ .Receiver }} *{{ .Context }}) Paginator() *{{ .Name }} {
+	p := &{{ .Name }}{Page: 1, PerPage: {{ .DefaultPerPage }}, url: &url.URL{}}
+	if {{ .Receiver }}.Request != nil {
+		p.url = {{ .Receiver }}.Request.URL
+	}
+{{ if .PagePointer }}	if {{ .Receiver }}.{{ .Page }} != nil && *{{ .Receiver }}.{{ .Page }} > 1 {
+		p.Page = *{{ .Receiver }}.{{ .Page }}
+	}
+{{ else }}	if {{ .Receiver }}.{{ .Page }} > 1 {
+		p.Page = {{ .Receiver }}.{{ .Page }}
+	}
+{{ end }}{{ if .PerPagePointer }}	if {{ .Receiver }}.{{ .PerPage }} != nil && *{{ .Receiver }}.{{ .PerPage }} > 0 {
+		p.PerPage = *{{ .Receiver }}.{{ .PerPage }}
+	}
+{{ else }}	if {{ .Receiver }}.{{ .PerPage }} > 0 {
+		p.PerPage = {{ .Receiver }}.{{ .PerPage }}
+	}
+{{ end }}{{ if .MaxPerPage }}	if p.PerPage > {{ .MaxPerPage }} {
+		p.PerPage = {{ .MaxPerPage }}
+	}
+{{ end }}	return p
+}
+
+// Offset returns the index of the first item of the page.
+func (p *{{ .Name }}) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+// Limit returns the maximum number of items of the page.
+func (p *{{ .Name }}) Limit() int {
+	return p.PerPage
+}
+
+// NextURL returns the URL of the next page given the total number of items, it returns an empty
+// string if the page is the last one. A negative total means that the total is unknown, NextURL
+// then always returns the URL of the next page.
+func (p *{{ .Name }}) NextURL(total int) string {
+	if total >= 0 && p.Page*p.PerPage >= total {
+		return ""
+	}
+	return p.pageURL(p.Page + 1)
+}
+
+// PrevURL returns the URL of the previous page, it returns an empty string if the page is the
+// first one.
+func (p *{{ .Name }}) PrevURL() string {
+	if p.Page <= 1 {
+		return ""
+	}
+	return p.pageURL(p.Page - 1)
+}
+
+// pageURL returns the URL of the given page, the other query string parameters of the request are
+// preserved.
+func (p *{{ .Name }}) pageURL(page int) string {
+	q := p.url.Query()
+	q.Set("page", strconv.Itoa(page))
+	q.Set("per_page", strconv.Itoa(p.PerPage))
+	u := url.URL{Path: p.url.Path, RawQuery: q.Encode()}
+	return u.String()
+}
 `
 
 	// ctxMTRespT generates the response helpers for responses with media types.
 	// template input: map[string]interface{}
-	ctxMTRespT = `// {{ goify .RespName true }} sends a HTTP response with status code {{ .Response.Status }}.
-func (ctx *{{ .Context.Name }}) {{ goify .RespName true }}(r {{ gotyperef .Projected .Projected.AllRequired 0 false }}) error {
-	if ctx.ResponseData.Header().Get("Content-Type") == "" {
-		ctx.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
+	ctxMTRespT = `{{ $recv := .Context.Receiver }}// {{ goify .RespName true }} sends a HTTP response with status code {{ .Response.Status }}.
+func ({{ $recv }} *{{ .Context.Name }}) {{ goify .RespName true }}({{ if .Context.ContextFirst }}ctx context.Context, {{ end }}r {{ gotyperef .Projected .Projected.AllRequired 0 false }}) error {
+	if {{ $recv }}.ResponseData.Header().Get("Content-Type") == "" {
+		{{ $recv }}.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
 	}
 {{ if .Projected.Type.IsArray }}	if r == nil {
 		r = {{ gotyperef .Projected .Projected.AllRequired 0 false }}{}
 	}
-{{ end }}	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
+{{ end }}	return {{ $recv }}.ResponseData.Service.Send({{ if .Context.ContextFirst }}ctx{{ else }}ctx.Context{{ end }}, {{ .Response.Status }}, r)
 }
-`
+{{ if .Projected.Type.IsArray }}
+// {{ goify .RespName true }}Stream starts a HTTP response with status code {{ .Response.Status }} that sends the collection
+// as a JSON array encoded element by element, see goa.StreamArray. The returned writer must be
+// closed before the action returns.
+func ({{ $recv }} *{{ .Context.Name }}) {{ goify .RespName true }}Stream({{ if .Context.ContextFirst }}ctx context.Context, {{ end }}options ...goa.StreamOption) (*goa.StreamWriter, error) {
+	if {{ $recv }}.ResponseData.Header().Get("Content-Type") == "" {
+		{{ $recv }}.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
+	}
+	options = append(options, goa.StreamArray(), goa.StreamStatus({{ .Response.Status }}))
+	return goa.Stream({{ if .Context.ContextFirst }}ctx{{ else }}ctx.Context{{ end }}, {{ $recv }}.ResponseData, options...)
+}
+{{ end }}`
 
 	// ctxTRespT generates the response helpers for responses with overridden types.
 	// template input: map[string]interface{}
-	ctxTRespT = `// {{ goify .Response.Name true }} sends a HTTP response with status code {{ .Response.Status }}.
-func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}(r {{ gotyperef .Type nil 0 false }}) error {
-	if ctx.ResponseData.Header().Get("Content-Type") == "" {
-		ctx.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
+	ctxTRespT = `{{ $recv := .Context.Receiver }}// {{ goify .Response.Name true }} sends a HTTP response with status code {{ .Response.Status }}.
+func ({{ $recv }} *{{ .Context.Name }}) {{ goify .Response.Name true }}({{ if .Context.ContextFirst }}ctx context.Context, {{ end }}r {{ gotyperef .Type nil 0 false }}) error {
+	if {{ $recv }}.ResponseData.Header().Get("Content-Type") == "" {
+		{{ $recv }}.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
 	}
-	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
+	return {{ $recv }}.ResponseData.Service.Send({{ if .Context.ContextFirst }}ctx{{ else }}ctx.Context{{ end }}, {{ .Response.Status }}, r)
 }
 `
 
 	// ctxNoMTRespT generates the response helpers for responses with no known media type.
 	// template input: *ContextTemplateData
-	ctxNoMTRespT = `
+	ctxNoMTRespT = `{{ $recv := .Context.Receiver }}
 // {{ goify .Response.Name true }} sends a HTTP response with status code {{ .Response.Status }}.
-func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}({{ if .Response.MediaType }}resp []byte{{ end }}) error {
-{{ if .Response.MediaType }}	if ctx.ResponseData.Header().Get("Content-Type") == "" {
-		ctx.ResponseData.Header().Set("Content-Type", "{{ .Response.MediaType }}")
+func ({{ $recv }} *{{ .Context.Name }}) {{ goify .Response.Name true }}({{ if .Context.ContextFirst }}ctx context.Context{{ if .Response.MediaType }}, {{ end }}{{ end }}{{ if .Response.MediaType }}resp []byte{{ end }}) error {
+{{ if .Response.MediaType }}	if {{ $recv }}.ResponseData.Header().Get("Content-Type") == "" {
+		{{ $recv }}.ResponseData.Header().Set("Content-Type", "{{ .Response.MediaType }}")
 	}
-{{ end }}	ctx.ResponseData.WriteHeader({{ .Response.Status }}){{ if .Response.MediaType }}
-	_, err := ctx.ResponseData.Write(resp)
+{{ end }}	{{ $recv }}.ResponseData.WriteHeader({{ .Response.Status }}){{ if .Response.MediaType }}
+	_, err := {{ $recv }}.ResponseData.Write(resp)
 	return err{{ else }}
 	return nil{{ end }}
 }
+`
+
+	// ctxSSERespT generates the response helpers for Server-Sent Events responses.
+	// template input: *ContextTemplateData
+	ctxSSERespT = `{{ $recv := .Context.Receiver }}
+// {{ goify .Response.Name true }} starts a Server-Sent Events response with status code {{ .Response.Status }}.
+// The returned writer must be closed before the action returns.
+func ({{ $recv }} *{{ .Context.Name }}) {{ goify .Response.Name true }}({{ if .Context.ContextFirst }}ctx context.Context, {{ end }}options ...goa.SSEOption) (*goa.SSEWriter, error) {
+	return goa.NewSSEWriter({{ if .Context.ContextFirst }}ctx{{ else }}ctx.Context{{ end }}, {{ .Response.Status }}, options...)
+}
 `
 
 	// payloadT generates the payload type definition GoGenerator
@@ -745,7 +990,7 @@ func (payload {{ gotyperef .Payload .Payload.AllRequired 0 false }}) Validate()
 type {{ .Resource }}Controller interface {
 	goa.Muxer
 {{ if .FileServers }}	goa.FileServer
-{{ end }}{{ range .Actions }}	{{ .Name }}(*{{ .Context }}) error
+{{ end }}{{ range .Actions }}	{{ .Name }}({{ if $.ContextFirst }}context.Context, {{ end }}*{{ .Context }}) error
 {{ end }}}
 `
 
@@ -767,6 +1012,62 @@ func initService(service *goa.Service) {
 {{ end }}{{ end }}{{ range .Decoders }}{{ if .Default }}{{/*
 */}}	service.Decoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
 {{ end }}{{ end }}}
+`
+
+	// tracingT generates the helper function that wraps the action handlers with OpenTelemetry
+	// spans.
+	tracingT = `
+// handleTracing wraps the handler of the given action with an OpenTelemetry server span named
+// after the resource and action. The span records the route template, the response status code
+// and the class of the error returned by the handler if any. The trace context of the incoming
+// request is extracted using the global propagator.
+func handleTracing(resource, action, route string, h goa.Handler) goa.Handler {
+	tracer := otel.Tracer("github.com/kyokomi/goa-v1")
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(req.Header))
+		ctx, span := tracer.Start(ctx, resource+"/"+action,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", req.Method),
+				attribute.String("http.route", route),
+				attribute.String("goa.resource", resource),
+				attribute.String("goa.action", action),
+			),
+		)
+		defer span.End()
+
+		err := h(ctx, rw, req)
+
+		var status int
+		if resp := goa.ContextResponse(ctx); resp != nil {
+			status = resp.Status
+		}
+		if err != nil {
+			class := fmt.Sprintf("%T", err)
+			var resp *goa.ErrorResponse
+			if errors.As(err, &resp) && resp.Code != "" {
+				class = resp.Code
+			}
+			if status == 0 {
+				// The error response is written by the error handler middleware.
+				status = http.StatusInternalServerError
+				var serr goa.ServiceError
+				if errors.As(err, &serr) {
+					status = serr.ResponseStatus()
+				}
+			}
+			span.RecordError(err)
+			span.SetAttributes(attribute.String("error.type", class))
+		}
+		if status != 0 {
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+		}
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		return err
+	}
+}
 `
 
 	// mountT generates the code for a resource "Mount" function.
@@ -777,7 +1078,8 @@ func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Co
 	initService(service)
 	var h goa.Handler
 {{ $res := .Resource }}{{ if .Origins }}{{ range .PreflightPaths }}{{/*
-*/}}	service.Mux.Handle("OPTIONS", {{ printf "%q" . }}, ctrl.MuxHandler("preflight", handle{{ $res }}Origin(cors.HandlePreflight()), nil))
+*/}}	ctrl.NameRoute("OPTIONS", {{ printf "%q" . }}, "preflight")
+	service.Mux.Handle("OPTIONS", {{ printf "%q" . }}, ctrl.MuxHandler("preflight", handle{{ $res }}Origin(cors.HandlePreflight()), nil))
 {{ end }}{{ end }}{{ range .Actions }}{{ $action := . }}
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Check if there was an error loading the request
@@ -795,17 +1097,19 @@ func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Co
 {{ if not .PayloadOptional }}		} else {
 			return goa.MissingPayloadError()
 {{ end }}		}
-{{ end }}		return ctrl.{{ .Name }}(rctx)
+{{ end }}		return ctrl.{{ .Name }}({{ if $.ContextFirst }}ctx, {{ end }}rctx)
 	}
 {{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
 {{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
-{{ end }}{{ range .Routes }}	service.Mux.Handle("{{ .Verb }}", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}))
+{{ end }}{{ range .Routes }}	ctrl.NameRoute("{{ .Verb }}", {{ printf "%q" .FullPath }}, {{ printf "%q" $action.DesignName }})
+	service.Mux.Handle("{{ .Verb }}", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, {{ if $.Tracing }}handleTracing({{ printf "%q" $.DesignName }}, {{ printf "%q" $action.DesignName }}, {{ printf "%q" .FullPath }}, h){{ else }}h{{ end }}, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}))
 	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" $action.Name }}, "route", {{ printf "%q" (printf "%s %s" .Verb .FullPath) }}{{ with $action.Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
 {{ end }}{{ end }}{{ range .FileServers }}
 	h = ctrl.FileHandler({{ printf "%q" .RequestPath }}, {{ printf "%q" .FilePath }})
 {{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
 {{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
-{{ end }}	service.Mux.Handle("GET", "{{ .RequestPath }}", ctrl.MuxHandler("serve", h, nil))
+{{ end }}	ctrl.NameRoute("GET", "{{ .RequestPath }}", "serve")
+	service.Mux.Handle("GET", "{{ .RequestPath }}", ctrl.MuxHandler("serve", h, nil))
 	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "files", {{ printf "%q" .FilePath }}, "route", {{ printf "%q" (printf "GET %s" .RequestPath) }}{{ with .Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
 {{ end }}}
 `
@@ -869,7 +1173,7 @@ func {{ .Unmarshal }}(ctx context.Context, service *goa.Service, req *http.Reque
 		goa.ContextRequest(ctx).Payload = payload
 		return err
 	}{{ end }}
-	goa.ContextRequest(ctx).Payload = payload{{ if .Payload.IsObject }}.Publicize(){{ end }}
+	goa.ContextRequest(ctx).Payload = payload{{ if and .Payload.IsObject (not (externalType .Payload.AttributeDefinition)) }}.Publicize(){{ end }}
 	return nil
 }
 {{ end }}
@@ -897,6 +1201,18 @@ func (mt {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
 }
+{{ end }}{{ if hasProtoMessage . }}
+// {{ $typeName }}ToProto converts {{ $typeName }} values into protocol buffer messages. The
+// protobuf encoder uses it to encode the media type, it must be set by the service.
+var {{ $typeName }}ToProto func({{ gotyperef . .AllRequired 0 false }}) proto.Message
+
+// ToProto returns the protocol buffer message of the media type, see {{ $typeName }}ToProto.
+func (mt {{ gotyperef . .AllRequired 0 false }}) ToProto() proto.Message {
+	if {{ $typeName }}ToProto == nil {
+		return nil
+	}
+	return {{ $typeName }}ToProto(mt)
+}
 {{ end }}
 `
 
@@ -14,6 +14,8 @@ import (
 	genapp "github.com/kyokomi/goa-v1/goagen/gen_app"
 )
 
+var customPrimitive = design.RegisterPrimitive(design.MinCustomKind, "id", "example.com/ids.ID", "string")
+
 var _ = Describe("ContextsWriter", func() {
 	var writer *genapp.ContextsWriter
 	var filename string
@@ -137,6 +139,26 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with a Server-Sent Events response", func() {
+				BeforeEach(func() {
+					design.Design = new(design.APIDefinition)
+					responses = map[string]*design.ResponseDefinition{"OK": {
+						Name:      "OK",
+						Status:    200,
+						MediaType: "text/event-stream",
+					}}
+				})
+
+				It("the generated code returns a SSE writer", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(sseResponse))
+				})
+			})
+
 			Context("with a collection media type", func() {
 				BeforeEach(func() {
 					elemType := &design.MediaTypeDefinition{
@@ -179,6 +201,17 @@ var _ = Describe("ContextsWriter", func() {
 	}
 	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)`))
 				})
+
+				It("the generated code streams the collection as a JSON array", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`func (ctx *ListBottleContext) OKStream(options ...goa.StreamOption) (*goa.StreamWriter, error) {`))
+					Ω(written).Should(ContainSubstring(`	options = append(options, goa.StreamArray(), goa.StreamStatus(200))
+	return goa.Stream(ctx.Context, ctx.ResponseData, options...)`))
+				})
 			})
 
 			Context("with an integer param", func() {
@@ -263,6 +296,86 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with page and per_page params", func() {
+				var perPage *design.AttributeDefinition
+
+				BeforeEach(func() {
+					perPage = &design.AttributeDefinition{Type: design.Integer}
+					params = &design.AttributeDefinition{
+						Type: design.Object{
+							"page":     &design.AttributeDefinition{Type: design.Integer},
+							"per_page": perPage,
+						},
+						Validation: &dslengine.ValidationDefinition{},
+					}
+				})
+
+				It("writes the paginator code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("type ListBottlePaginator struct {"))
+					Ω(written).Should(ContainSubstring(paginatorFactory))
+					Ω(written).Should(ContainSubstring("func (p *ListBottlePaginator) Offset() int {"))
+					Ω(written).Should(ContainSubstring("func (p *ListBottlePaginator) NextURL(total int) string {"))
+					Ω(written).Should(ContainSubstring("func (p *ListBottlePaginator) PrevURL() string {"))
+				})
+
+				Context("with a maximum page size", func() {
+					BeforeEach(func() {
+						max := 50.0
+						perPage.Validation = &dslengine.ValidationDefinition{Maximum: &max}
+						perPage.SetDefault(10)
+					})
+
+					It("clamps the page size", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(paginatorMaxFactory))
+					})
+				})
+
+				Context("with a page param that is not an integer", func() {
+					BeforeEach(func() {
+						params.Type.ToObject()["page"] = &design.AttributeDefinition{Type: design.String}
+					})
+
+					It("does not write a paginator", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						Ω(string(b)).ShouldNot(ContainSubstring("Paginator"))
+					})
+				})
+			})
+
+			Context("with a custom primitive param", func() {
+				BeforeEach(func() {
+					params = &design.AttributeDefinition{
+						Type: design.Object{
+							"param": &design.AttributeDefinition{Type: customPrimitive},
+						},
+					}
+				})
+
+				It("writes the custom primitive contexts code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(customPrimitiveContext))
+					Ω(written).Should(ContainSubstring(customPrimitiveContextFactory))
+				})
+			})
+
 			Context("with an string param", func() {
 				var (
 					strParam   *design.AttributeDefinition
@@ -995,7 +1108,7 @@ var _ = Describe("ControllersWriter", func() {
 		})
 
 		Context("with data", func() {
-			var multipart bool
+			var multipart, tracing bool
 			var actions, verbs, paths, contexts, unmarshals []string
 			var payloads []*design.UserTypeDefinition
 			var encoders, decoders []*genapp.EncoderTemplateData
@@ -1005,6 +1118,7 @@ var _ = Describe("ControllersWriter", func() {
 
 			BeforeEach(func() {
 				multipart = false
+				tracing = false
 				actions = nil
 				verbs = nil
 				paths = nil
@@ -1020,8 +1134,10 @@ var _ = Describe("ControllersWriter", func() {
 				codegen.TempCount = 0
 				api := &design.APIDefinition{}
 				d := &genapp.ControllerTemplateData{
-					Resource: "Bottles",
-					Origins:  origins,
+					Resource:   "Bottles",
+					DesignName: "bottle",
+					Origins:    origins,
+					Tracing:    tracing,
 				}
 				as := make([]map[string]interface{}, len(actions))
 				for i, a := range actions {
@@ -1089,6 +1205,39 @@ var _ = Describe("ControllersWriter", func() {
 				})
 			})
 
+			Context("with tracing", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					tracing = true
+				})
+
+				It("wraps the action handlers with a span", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", handleTracing("bottle", "list", "/accounts/:accountID/bottles", h), nil))`))
+				})
+
+				It("writes the tracing helper", func() {
+					err := writer.WriteTracing()
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("func handleTracing(resource, action, route string, h goa.Handler) goa.Handler {"))
+					Ω(written).Should(ContainSubstring("otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(req.Header))"))
+					Ω(written).Should(ContainSubstring(`tracer.Start(ctx, resource+"/"+action,`))
+					Ω(written).Should(ContainSubstring(`attribute.Int("http.response.status_code", status)`))
+					Ω(written).Should(ContainSubstring("errors.As(err, &resp)"))
+					Ω(written).Should(ContainSubstring("errors.As(err, &serr)"))
+				})
+			})
+
 			Context("with actions that take a payload", func() {
 				BeforeEach(func() {
 					actions = []string{"list"}
@@ -1587,6 +1736,33 @@ var _ = Describe("UserTypesWriter", func() {
 				})
 			})
 
+			Context("with a user type including sensitive attributes", func() {
+				BeforeEach(func() {
+					attDef = &design.AttributeDefinition{
+						Type: design.Object{
+							"name": &design.AttributeDefinition{
+								Type: design.String,
+							},
+							"password": &design.AttributeDefinition{
+								Type:     design.String,
+								Metadata: dslengine.MetadataDefinition{"struct:field:sensitive": nil},
+							},
+						},
+					}
+					typeName = "Credentials"
+				})
+				It("writes the deep copy and redacting string methods", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(userTypeDeepCopy))
+					Ω(written).Should(ContainSubstring(userTypeString))
+					Ω(written).Should(ContainSubstring(userTypeRedact))
+				})
+			})
+
 			Context("with a user type including hash", func() {
 				BeforeEach(func() {
 					attDef = &design.AttributeDefinition{
@@ -1633,6 +1809,14 @@ var _ = Describe("UserTypesWriter", func() {
 })
 
 const (
+	sseResponse = `
+// OK starts a Server-Sent Events response with status code 200.
+// The returned writer must be closed before the action returns.
+func (ctx *ListBottleContext) OK(options ...goa.SSEOption) (*goa.SSEWriter, error) {
+	return goa.NewSSEWriter(ctx.Context, 200, options...)
+}
+`
+
 	emptyContext = `
 type ListBottleContext struct {
 	context.Context
@@ -1651,6 +1835,61 @@ func NewListBottleContext(ctx context.Context, r *http.Request, service *goa.Ser
 	rctx := ListBottleContext{Context: ctx, ResponseData: resp, RequestData: req}
 	return &rctx, err
 }
+`
+
+	customPrimitiveContext = `
+type ListBottleContext struct {
+	context.Context
+	*goa.ResponseData
+	*goa.RequestData
+	Param *ids.ID
+}
+`
+
+	customPrimitiveContextFactory = `
+	paramParam := req.Params["param"]
+	if len(paramParam) > 0 {
+		rawParam := paramParam[0]
+		if param, err2 := func(s string) (v ids.ID, err error) { err = v.UnmarshalText([]byte(s)); return }(rawParam); err2 == nil {
+			tmp1 := &param
+			rctx.Param = tmp1
+		} else {
+			err = goa.MergeErrors(err, goa.InvalidParamTypeError("param", rawParam, "id"))
+		}
+	}
+`
+
+	paginatorFactory = `func (ctx *ListBottleContext) Paginator() *ListBottlePaginator {
+	p := &ListBottlePaginator{Page: 1, PerPage: 20, url: &url.URL{}}
+	if ctx.Request != nil {
+		p.url = ctx.Request.URL
+	}
+	if ctx.Page != nil && *ctx.Page > 1 {
+		p.Page = *ctx.Page
+	}
+	if ctx.PerPage != nil && *ctx.PerPage > 0 {
+		p.PerPage = *ctx.PerPage
+	}
+	return p
+}
+`
+
+	paginatorMaxFactory = `func (ctx *ListBottleContext) Paginator() *ListBottlePaginator {
+	p := &ListBottlePaginator{Page: 1, PerPage: 10, url: &url.URL{}}
+	if ctx.Request != nil {
+		p.url = ctx.Request.URL
+	}
+	if ctx.Page != nil && *ctx.Page > 1 {
+		p.Page = *ctx.Page
+	}
+	if ctx.PerPage > 0 {
+		p.PerPage = ctx.PerPage
+	}
+	if p.PerPage > 50 {
+		p.PerPage = 50
+	}
+	return p
+}
 `
 
 	intContext = `
@@ -2507,7 +2746,7 @@ type BottlesController interface {
 
 	originsIntegration = `}
 	h = handleBottlesOrigin(h)
-	service.Mux.Handle`
+	ctrl.NameRoute`
 
 	originsHandler = `// handleBottlesOrigin applies the CORS response headers corresponding to the origin.
 func handleBottlesOrigin(h goa.Handler) goa.Handler {
@@ -2608,6 +2847,7 @@ func MountBottlesController(service *goa.Service, ctrl BottlesController) {
 		}
 		return ctrl.List(rctx)
 	}
+	ctrl.NameRoute("GET", "/accounts/:accountID/bottles", "list")
 	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
 	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles")
 }
@@ -2629,6 +2869,7 @@ func MountBottlesController(service *goa.Service, ctrl BottlesController) {
 		}
 		return ctrl.List(rctx)
 	}
+	ctrl.NameRoute("GET", "/accounts/:accountID/bottles", "list")
 	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
 	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles")
 }
@@ -2658,6 +2899,7 @@ type BottlesController interface {
 		}
 		return ctrl.List(rctx)
 	}
+	ctrl.NameRoute("GET", "/accounts/:accountID/bottles", "list")
 	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
 	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles")
 
@@ -2673,6 +2915,7 @@ type BottlesController interface {
 		}
 		return ctrl.Show(rctx)
 	}
+	ctrl.NameRoute("GET", "/accounts/:accountID/bottles/:id", "show")
 	service.Mux.Handle("GET", "/accounts/:accountID/bottles/:id", ctrl.MuxHandler("show", h, nil))
 	service.LogInfo("mount", "ctrl", "Bottles", "action", "Show", "route", "GET /accounts/:accountID/bottles/:id")
 }
@@ -2743,5 +2986,45 @@ type ComplexPayload struct {
 	Misc map[int]*MiscPayload ` + "`" + `form:"misc,omitempty" json:"misc,omitempty" yaml:"misc,omitempty" xml:"misc,omitempty"` + "`" + `
 	Name *string ` + "`" + `form:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty" xml:"name,omitempty"` + "`" + `
 }
+`
+
+	userTypeDeepCopy = `// DeepCopy returns a copy of the Credentials type instance that shares no memory with it.
+func (ut *Credentials) DeepCopy() *Credentials {
+	if ut == nil {
+		return nil
+	}
+	res := &Credentials{}
+	if ut.Name != nil {
+		v := *ut.Name
+		res.Name = &v
+	}
+	if ut.Password != nil {
+		v := *ut.Password
+		res.Password = &v
+	}
+	return res
+}
+`
+
+	userTypeString = `func (ut *Credentials) String() string {
+	res := ut.DeepCopy()
+	res.redact()
+	b, err := json.Marshal(res)
+	if err != nil {
+		return fmt.Sprintf("%T: %s", res, err)
+	}
+	return string(b)
+}
+`
+
+	userTypeRedact = `func (ut *Credentials) redact() {
+	if ut == nil {
+		return
+	}
+	if ut.Password != nil {
+		redacted := "[REDACTED]"
+		ut.Password = &redacted
+	}
+}
 `
 )
@@ -0,0 +1,551 @@
+package genasyncapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genschema "github.com/kyokomi/goa-v1/goagen/gen_schema"
+)
+
+type (
+	// AsyncAPI represents an instance of an AsyncAPI 2.6 document.
+	// See https://www.asyncapi.com/docs/reference/specification/v2.6.0
+	AsyncAPI struct {
+		AsyncAPI           string              `json:"asyncapi"`
+		Info               *Info               `json:"info"`
+		Servers            map[string]*Server  `json:"servers,omitempty"`
+		DefaultContentType string              `json:"defaultContentType,omitempty"`
+		Channels           map[string]*Channel `json:"channels"`
+		Components         *Components         `json:"components,omitempty"`
+		Tags               []*Tag              `json:"tags,omitempty"`
+	}
+
+	// Info provides metadata about the API.
+	Info struct {
+		Title          string                    `json:"title"`
+		Version        string                    `json:"version"`
+		Description    string                    `json:"description,omitempty"`
+		TermsOfService string                    `json:"termsOfService,omitempty"`
+		Contact        *design.ContactDefinition `json:"contact,omitempty"`
+		License        *design.LicenseDefinition `json:"license,omitempty"`
+	}
+
+	// Server represents a message broker or server the clients connect to.
+	Server struct {
+		// URL of the server.
+		URL string `json:"url"`
+		// Protocol used to connect to the server, e.g. "ws" or "https".
+		Protocol string `json:"protocol"`
+		// Description of the server.
+		Description string `json:"description,omitempty"`
+	}
+
+	// Channel describes the messages exchanged on a single path.
+	Channel struct {
+		// Description of the channel.
+		Description string `json:"description,omitempty"`
+		// Parameters lists the path parameters of the channel indexed by name.
+		Parameters map[string]*Parameter `json:"parameters,omitempty"`
+		// Subscribe describes the messages sent by the API to the clients.
+		Subscribe *Operation `json:"subscribe,omitempty"`
+		// Publish describes the messages sent by the clients to the API.
+		Publish *Operation `json:"publish,omitempty"`
+		// Bindings contains the protocol specific information of the channel.
+		Bindings *ChannelBindings `json:"bindings,omitempty"`
+	}
+
+	// Parameter describes a channel path parameter.
+	Parameter struct {
+		// Description of the parameter.
+		Description string `json:"description,omitempty"`
+		// Schema defines the type used for the parameter.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+	}
+
+	// Operation describes a publish or subscribe operation.
+	Operation struct {
+		// OperationID is a unique string used to identify the operation.
+		OperationID string `json:"operationId,omitempty"`
+		// Summary is a short summary of what the operation does.
+		Summary string `json:"summary,omitempty"`
+		// Description is a verbose explanation of the operation behavior.
+		Description string `json:"description,omitempty"`
+		// Tags is a list of tags for API documentation control.
+		Tags []*Tag `json:"tags,omitempty"`
+		// Bindings contains the protocol specific information of the operation.
+		Bindings *OperationBindings `json:"bindings,omitempty"`
+		// Message describes the messages exchanged by the operation.
+		Message *Message `json:"message,omitempty"`
+	}
+
+	// Message describes a message exchanged on a channel.
+	Message struct {
+		// Name is a machine-friendly name for the message.
+		Name string `json:"name,omitempty"`
+		// Summary is a short summary of the message.
+		Summary string `json:"summary,omitempty"`
+		// ContentType is the media type of the message payload.
+		ContentType string `json:"contentType,omitempty"`
+		// Payload defines the type of the message payload.
+		Payload *genschema.JSONSchema `json:"payload,omitempty"`
+		// OneOf lists the possible messages if there are more than one.
+		OneOf []*Message `json:"oneOf,omitempty"`
+	}
+
+	// ChannelBindings contains the protocol specific information of a channel.
+	ChannelBindings struct {
+		// WS describes the WebSocket handshake request.
+		WS *WebSocketChannelBinding `json:"ws,omitempty"`
+	}
+
+	// WebSocketChannelBinding describes the HTTP request used to establish a WebSocket
+	// connection.
+	WebSocketChannelBinding struct {
+		// Method is the HTTP method used for the handshake request.
+		Method string `json:"method,omitempty"`
+		// Query defines the query string parameters of the handshake request.
+		Query *genschema.JSONSchema `json:"query,omitempty"`
+		// Headers defines the headers of the handshake request.
+		Headers *genschema.JSONSchema `json:"headers,omitempty"`
+		// BindingVersion is the version of the binding.
+		BindingVersion string `json:"bindingVersion"`
+	}
+
+	// OperationBindings contains the protocol specific information of an operation.
+	OperationBindings struct {
+		// HTTP describes the HTTP request of the operation.
+		HTTP *HTTPOperationBinding `json:"http,omitempty"`
+	}
+
+	// HTTPOperationBinding describes the HTTP request of an operation.
+	HTTPOperationBinding struct {
+		// Type of the operation, always "request".
+		Type string `json:"type"`
+		// Method is the HTTP method of the request.
+		Method string `json:"method,omitempty"`
+		// Query defines the query string parameters of the request.
+		Query *genschema.JSONSchema `json:"query,omitempty"`
+		// BindingVersion is the version of the binding.
+		BindingVersion string `json:"bindingVersion"`
+	}
+
+	// Components holds the reusable objects referenced by the document.
+	Components struct {
+		// Schemas lists the types and media types indexed by name.
+		Schemas map[string]*genschema.JSONSchema `json:"schemas,omitempty"`
+	}
+
+	// Tag adds metadata to a tag used by the operations.
+	Tag struct {
+		// Name of the tag.
+		Name string `json:"name"`
+		// Description is a short description of the tag.
+		Description string `json:"description,omitempty"`
+	}
+)
+
+const (
+	// WebhookMetadata is the name of the action metadata that declares the action as a
+	// webhook: the API sends the action payload to the subscribers by making the request
+	// described by the action route.
+	WebhookMetadata = "asyncapi:webhook"
+
+	// EventStreamMediaType is the media type of the responses that stream server-sent events.
+	EventStreamMediaType = "text/event-stream"
+)
+
+// New creates an AsyncAPI document from an API definition. The document describes the
+// WebSocket actions, the actions that have a response with the EventStreamMediaType media type
+// and the actions declared as webhooks with the WebhookMetadata metadata.
+func New(api *design.APIDefinition) (*AsyncAPI, error) {
+	if api == nil {
+		return nil, nil
+	}
+	s := &AsyncAPI{
+		AsyncAPI: "2.6.0",
+		Info: &Info{
+			Title:          api.Title,
+			Version:        api.Version,
+			Description:    api.Description,
+			TermsOfService: api.TermsOfService,
+			Contact:        api.Contact,
+			License:        api.License,
+		},
+		DefaultContentType: "application/json",
+		Channels:           make(map[string]*Channel),
+	}
+	if s.Info.Version == "" {
+		s.Info.Version = "1.0"
+	}
+	schemes := make(map[string]bool)
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		generated := false
+		err := res.IterateActions(func(a *design.ActionDefinition) error {
+			if !mustGenerate(a.Metadata) {
+				return nil
+			}
+			var build func(*AsyncAPI, *design.APIDefinition, *design.RouteDefinition) error
+			switch {
+			case a.WebSocket():
+				build = buildWebSocketChannel
+			case isWebhook(a):
+				build = buildWebhookChannel
+			case eventStream(a) != nil:
+				build = buildEventStreamChannel
+			default:
+				return nil
+			}
+			generated = true
+			if !isWebhook(a) {
+				for _, scheme := range a.EffectiveSchemes() {
+					schemes[scheme] = true
+				}
+			}
+			for _, route := range a.Routes {
+				if err := build(s, api, route); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if generated {
+			s.Tags = append(s.Tags, &Tag{Name: res.Name, Description: res.Description})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.Servers = serversFromDefinition(api, schemes)
+	if len(genschema.Definitions) > 0 {
+		s.Components = &Components{
+			Schemas: make(map[string]*genschema.JSONSchema, len(genschema.Definitions)),
+		}
+		for n, d := range genschema.Definitions {
+			s.Components.Schemas[n] = toAsyncAPISchema(d)
+		}
+	}
+	return s, nil
+}
+
+// mustGenerate returns true if the metadata indicates that the AsyncAPI specification should be
+// generated, false otherwise.
+func mustGenerate(meta dslengine.MetadataDefinition) bool {
+	m, ok := meta["asyncapi:generate"]
+	return !ok || len(m) == 0 || m[0] != "false"
+}
+
+// isWebhook returns true if the action is declared as a webhook.
+func isWebhook(a *design.ActionDefinition) bool {
+	m, ok := a.Metadata[WebhookMetadata]
+	return ok && (len(m) == 0 || m[0] != "false")
+}
+
+// eventStream returns the first response of the action that streams server-sent events, nil if
+// there isn't any.
+func eventStream(a *design.ActionDefinition) *design.ResponseDefinition {
+	var res *design.ResponseDefinition
+	a.IterateResponses(func(r *design.ResponseDefinition) error {
+		if res == nil && design.CanonicalIdentifier(r.MediaType) == EventStreamMediaType {
+			res = r
+		}
+		return nil
+	})
+	return res
+}
+
+// serversFromDefinition returns one server per scheme used by the event endpoints.
+func serversFromDefinition(api *design.APIDefinition, schemes map[string]bool) map[string]*Server {
+	if api.Host == "" || len(schemes) == 0 {
+		return nil
+	}
+	servers := make(map[string]*Server, len(schemes))
+	for scheme := range schemes {
+		servers[scheme] = &Server{
+			URL:      api.Host + api.BasePath,
+			Protocol: scheme,
+		}
+	}
+	return servers
+}
+
+// buildWebSocketChannel describes the messages exchanged on the WebSocket connection
+// established by the route. The messages sent by the clients are described by the action
+// payload and the messages sent by the API by the action response media types.
+func buildWebSocketChannel(s *AsyncAPI, api *design.APIDefinition, route *design.RouteDefinition) error {
+	action := route.Parent
+	ch, err := channel(s, api, route)
+	if err != nil {
+		return err
+	}
+	ch.Bindings = &ChannelBindings{
+		WS: &WebSocketChannelBinding{
+			Method:         route.Verb,
+			Query:          querySchema(api, route),
+			Headers:        headersSchema(api, action),
+			BindingVersion: "0.1.0",
+		},
+	}
+	if action.Payload != nil {
+		op := operation(action, route, "publish")
+		op.Message = payloadMessage(api, action)
+		ch.Publish = op
+	}
+	var msgs []*Message
+	action.IterateResponses(func(r *design.ResponseDefinition) error {
+		if m := responseMessage(api, r); m != nil {
+			msgs = append(msgs, m)
+		}
+		return nil
+	})
+	if len(msgs) > 0 {
+		op := operation(action, route, "subscribe")
+		op.Message = oneOf(msgs)
+		ch.Subscribe = op
+	}
+	return nil
+}
+
+// buildEventStreamChannel describes the events streamed by the route.
+func buildEventStreamChannel(s *AsyncAPI, api *design.APIDefinition, route *design.RouteDefinition) error {
+	action := route.Parent
+	ch, err := channel(s, api, route)
+	if err != nil {
+		return err
+	}
+	op := operation(action, route, "subscribe")
+	op.Bindings = &OperationBindings{
+		HTTP: &HTTPOperationBinding{
+			Type:           "request",
+			Method:         route.Verb,
+			Query:          querySchema(api, route),
+			BindingVersion: "0.1.0",
+		},
+	}
+	op.Message = responseMessage(api, eventStream(action))
+	ch.Subscribe = op
+	return nil
+}
+
+// buildWebhookChannel describes the requests made by the API to deliver the webhook payload.
+func buildWebhookChannel(s *AsyncAPI, api *design.APIDefinition, route *design.RouteDefinition) error {
+	action := route.Parent
+	ch, err := channel(s, api, route)
+	if err != nil {
+		return err
+	}
+	op := operation(action, route, "subscribe")
+	op.Bindings = &OperationBindings{
+		HTTP: &HTTPOperationBinding{
+			Type:           "request",
+			Method:         route.Verb,
+			Query:          querySchema(api, route),
+			BindingVersion: "0.1.0",
+		},
+	}
+	if action.Payload != nil {
+		op.Message = payloadMessage(api, action)
+	}
+	ch.Subscribe = op
+	return nil
+}
+
+// channel returns the channel for the given route, creating it if needed.
+func channel(s *AsyncAPI, api *design.APIDefinition, route *design.RouteDefinition) (*Channel, error) {
+	action := route.Parent
+	key := design.WildcardRegex.ReplaceAllStringFunc(
+		route.FullPath(),
+		func(w string) string {
+			return fmt.Sprintf("/{%s}", w[2:])
+		},
+	)
+	if key == "" {
+		key = "/"
+	}
+	if ch, ok := s.Channels[key]; ok {
+		return ch, nil
+	}
+	ch := &Channel{Description: action.Description}
+	params := action.AllParams()
+	for _, w := range design.ExtractWildcards(route.FullPath()) {
+		if ch.Parameters == nil {
+			ch.Parameters = make(map[string]*Parameter)
+		}
+		p := &Parameter{Schema: &genschema.JSONSchema{Type: genschema.JSONString}}
+		if params != nil {
+			if obj := params.Type.ToObject(); obj != nil {
+				if at, ok := obj[w]; ok {
+					p.Description = at.Description
+					p.Schema = toAsyncAPISchema(genschema.AttributeSchema(api, at))
+				}
+			}
+		}
+		ch.Parameters[w] = p
+	}
+	s.Channels[key] = ch
+	return ch, nil
+}
+
+// operation initializes the publish or subscribe operation of the given route.
+func operation(action *design.ActionDefinition, route *design.RouteDefinition, kind string) *Operation {
+	operationID := fmt.Sprintf("%s#%s", action.Parent.Name, action.Name)
+	for i, rt := range action.Routes {
+		if rt == route {
+			if i > 0 {
+				operationID = fmt.Sprintf("%s#%d", operationID, i)
+			}
+			break
+		}
+	}
+	if action.WebSocket() {
+		operationID = fmt.Sprintf("%s#%s", operationID, kind)
+	}
+	return &Operation{
+		OperationID: operationID,
+		Summary:     summaryFromDefinition(action.Name+" "+action.Parent.Name, action.Metadata),
+		Description: action.Description,
+		Tags:        []*Tag{{Name: action.Parent.Name}},
+	}
+}
+
+// payloadMessage returns the message described by the action payload.
+func payloadMessage(api *design.APIDefinition, action *design.ActionDefinition) *Message {
+	return &Message{
+		Name:    action.Payload.TypeName,
+		Summary: action.Payload.Description,
+		Payload: toAsyncAPISchema(genschema.TypeSchema(api, action.Payload)),
+	}
+}
+
+// responseMessage returns the message described by the response type or media type, nil if
+// the response does not have a body.
+func responseMessage(api *design.APIDefinition, r *design.ResponseDefinition) *Message {
+	if mt, ok := api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]; ok && r.MediaType != "" {
+		view := r.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		return &Message{
+			Name:        mt.TypeName,
+			Summary:     r.Description,
+			ContentType: r.MediaType,
+			Payload:     &genschema.JSONSchema{Ref: componentRef(genschema.MediaTypeRef(api, mt, view))},
+		}
+	}
+	if r.Type != nil {
+		m := &Message{
+			Summary:     r.Description,
+			ContentType: r.MediaType,
+			Payload:     toAsyncAPISchema(genschema.TypeSchema(api, r.Type)),
+		}
+		if ut, ok := r.Type.(*design.UserTypeDefinition); ok {
+			m.Name = ut.TypeName
+		}
+		return m
+	}
+	if r.MediaType == EventStreamMediaType {
+		return &Message{
+			Summary:     r.Description,
+			ContentType: r.MediaType,
+			Payload:     &genschema.JSONSchema{Type: genschema.JSONString},
+		}
+	}
+	return nil
+}
+
+// oneOf returns the message if there is only one or a message that lists all the messages.
+func oneOf(msgs []*Message) *Message {
+	if len(msgs) == 1 {
+		return msgs[0]
+	}
+	return &Message{OneOf: msgs}
+}
+
+// querySchema returns the object schema that describes the query string parameters of the
+// route, nil if there isn't any.
+func querySchema(api *design.APIDefinition, route *design.RouteDefinition) *genschema.JSONSchema {
+	params := route.Parent.AllParams()
+	if params == nil {
+		return nil
+	}
+	obj := params.Type.ToObject()
+	if obj == nil {
+		return nil
+	}
+	wildcards := design.ExtractWildcards(route.FullPath())
+	s := &genschema.JSONSchema{Type: genschema.JSONObject}
+	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		for _, w := range wildcards {
+			if n == w {
+				return nil
+			}
+		}
+		if s.Properties == nil {
+			s.Properties = make(map[string]*genschema.JSONSchema)
+		}
+		s.Properties[n] = toAsyncAPISchema(genschema.AttributeSchema(api, at))
+		if params.IsRequired(n) {
+			s.Required = append(s.Required, n)
+		}
+		return nil
+	})
+	if s.Properties == nil {
+		return nil
+	}
+	return toAsyncAPISchema(s)
+}
+
+// headersSchema returns the object schema that describes the action headers, nil if there
+// isn't any.
+func headersSchema(api *design.APIDefinition, action *design.ActionDefinition) *genschema.JSONSchema {
+	var s *genschema.JSONSchema
+	action.IterateHeaders(func(name string, required bool, header *design.AttributeDefinition) error {
+		if s == nil {
+			s = &genschema.JSONSchema{
+				Type:       genschema.JSONObject,
+				Properties: make(map[string]*genschema.JSONSchema),
+			}
+		}
+		s.Properties[name] = toAsyncAPISchema(genschema.AttributeSchema(api, header))
+		if required {
+			s.Required = append(s.Required, name)
+		}
+		return nil
+	})
+	if s != nil {
+		sort.Strings(s.Required)
+		s = toAsyncAPISchema(s)
+	}
+	return s
+}
+
+func summaryFromDefinition(name string, metadata dslengine.MetadataDefinition) string {
+	for _, key := range []string{"asyncapi:summary", "swagger:summary"} {
+		if mdata, ok := metadata[key]; ok && len(mdata) > 0 {
+			return mdata[0]
+		}
+	}
+	return name
+}
+
+// toAsyncAPISchema converts the schema into a JSON Schema 2020-12 schema whose references point
+// to the components schemas. AsyncAPI schemas are a superset of JSON Schema draft 7 which
+// supports the same const, examples and type array keywords.
+func toAsyncAPISchema(s *genschema.JSONSchema) *genschema.JSONSchema {
+	return genschema.ToDraft202012(s, "#/components/schemas/")
+}
+
+// componentRef converts a reference to a JSON schema definition into a reference to the
+// corresponding components schema.
+func componentRef(ref string) string {
+	if strings.HasPrefix(ref, "#/definitions/") {
+		return "#/components/schemas/" + strings.TrimPrefix(ref, "#/definitions/")
+	}
+	return ref
+}
@@ -0,0 +1,157 @@
+package genasyncapi_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genasyncapi "github.com/kyokomi/goa-v1/goagen/gen_asyncapi"
+	genschema "github.com/kyokomi/goa-v1/goagen/gen_schema"
+)
+
+var _ = Describe("New", func() {
+	var doc *genasyncapi.AsyncAPI
+	var newErr error
+
+	BeforeEach(func() {
+		doc = nil
+		newErr = nil
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		doc, newErr = genasyncapi.New(Design)
+	})
+
+	Context("with an API that has no event endpoint", func() {
+		BeforeEach(func() {
+			apidsl.API("test", func() {
+				apidsl.Title("test API")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/bottles"))
+					apidsl.Response(OK)
+				})
+			})
+		})
+
+		It("does not generate any channel", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(doc.AsyncAPI).Should(Equal("2.6.0"))
+			Ω(doc.Info.Title).Should(Equal("test API"))
+			Ω(doc.Channels).Should(BeEmpty())
+			Ω(doc.Tags).Should(BeEmpty())
+		})
+	})
+
+	Context("with event endpoints", func() {
+		BeforeEach(func() {
+			event := apidsl.MediaType("application/vnd.event", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer)
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+				})
+			})
+			stream := apidsl.MediaType(genasyncapi.EventStreamMediaType, func() {
+				apidsl.TypeName("Tick")
+				apidsl.Attributes(func() {
+					apidsl.Attribute("at", DateTime)
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("at")
+				})
+			})
+			command := apidsl.Type("Command", func() {
+				apidsl.Attribute("name", String)
+			})
+			apidsl.API("test", func() {
+				apidsl.Host("example.com")
+				apidsl.Scheme("https")
+			})
+			apidsl.Resource("events", func() {
+				apidsl.BasePath("/events")
+				apidsl.Action("watch", func() {
+					apidsl.Routing(apidsl.GET("/:id/watch"))
+					apidsl.Scheme("wss")
+					apidsl.Params(func() {
+						apidsl.Param("id", Integer, "Event ID")
+						apidsl.Param("since", DateTime)
+					})
+					apidsl.Payload(command)
+					apidsl.Response(OK, event)
+				})
+				apidsl.Action("ticks", func() {
+					apidsl.Routing(apidsl.GET("/ticks"))
+					apidsl.Response(OK, stream)
+				})
+				apidsl.Action("notify", func() {
+					apidsl.Routing(apidsl.POST("/hook"))
+					apidsl.Metadata(genasyncapi.WebhookMetadata)
+					apidsl.Payload(event)
+				})
+			})
+		})
+
+		It("generates the servers", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(doc.Servers).Should(HaveLen(2))
+			Ω(doc.Servers).Should(HaveKey("wss"))
+			Ω(doc.Servers["wss"].URL).Should(Equal("example.com"))
+			Ω(doc.Servers["wss"].Protocol).Should(Equal("wss"))
+			Ω(doc.Servers).Should(HaveKey("https"))
+		})
+
+		It("generates the WebSocket channels", func() {
+			ch := doc.Channels["/events/{id}/watch"]
+			Ω(ch).ShouldNot(BeNil())
+			Ω(ch.Parameters).Should(HaveKey("id"))
+			Ω(ch.Parameters["id"].Description).Should(Equal("Event ID"))
+			Ω(string(ch.Parameters["id"].Schema.Type)).Should(Equal("integer"))
+			Ω(ch.Bindings.WS.Method).Should(Equal("GET"))
+			Ω(ch.Bindings.WS.Query.Properties).Should(HaveKey("since"))
+			Ω(ch.Bindings.WS.Query.Properties).ShouldNot(HaveKey("id"))
+			Ω(ch.Publish).ShouldNot(BeNil())
+			Ω(ch.Publish.OperationID).Should(Equal("events#watch#publish"))
+			Ω(ch.Publish.Message.Name).Should(Equal("Command"))
+			Ω(ch.Publish.Message.Payload.Ref).Should(Equal("#/components/schemas/Command"))
+			Ω(ch.Subscribe).ShouldNot(BeNil())
+			Ω(ch.Subscribe.Message.ContentType).Should(Equal("application/vnd.event"))
+			Ω(ch.Subscribe.Message.Payload.Ref).Should(Equal("#/components/schemas/Event"))
+			Ω(doc.Components.Schemas).Should(HaveKey("Command"))
+			Ω(doc.Components.Schemas).Should(HaveKey("Event"))
+		})
+
+		It("generates the server-sent events channels", func() {
+			ch := doc.Channels["/events/ticks"]
+			Ω(ch).ShouldNot(BeNil())
+			Ω(ch.Publish).Should(BeNil())
+			Ω(ch.Subscribe).ShouldNot(BeNil())
+			Ω(ch.Subscribe.OperationID).Should(Equal("events#ticks"))
+			Ω(ch.Subscribe.Bindings.HTTP.Method).Should(Equal("GET"))
+			Ω(ch.Subscribe.Message.ContentType).Should(Equal(genasyncapi.EventStreamMediaType))
+			Ω(ch.Subscribe.Message.Payload.Ref).Should(Equal("#/components/schemas/Tick"))
+		})
+
+		It("generates the webhooks channels", func() {
+			ch := doc.Channels["/events/hook"]
+			Ω(ch).ShouldNot(BeNil())
+			Ω(ch.Subscribe).ShouldNot(BeNil())
+			Ω(ch.Subscribe.Bindings.HTTP.Method).Should(Equal("POST"))
+			Ω(ch.Subscribe.Message.Payload.Ref).Should(Equal("#/components/schemas/NotifyEventsPayload"))
+		})
+
+		It("generates the tags", func() {
+			Ω(doc.Tags).Should(HaveLen(1))
+			Ω(doc.Tags[0].Name).Should(Equal("events"))
+		})
+	})
+})
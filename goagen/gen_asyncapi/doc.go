@@ -0,0 +1,9 @@
+/*
+Package genasyncapi provides a generator for the AsyncAPI 2.6 specification of the API event
+endpoints. The generated documents describe the channels, messages and payload schemas of the
+WebSocket actions, of the actions that stream server-sent events and of the webhooks declared
+in the design. No document is generated if the API does not define any such endpoint.
+See https://www.asyncapi.com/docs/reference/specification/v2.6.0 for more information on the
+AsyncAPI specification.
+*/
+package genasyncapi
@@ -0,0 +1,119 @@
+package genasyncapi
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of an AsyncAPI Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the AsyncAPI specification generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, ver string
+
+	set := flag.NewFlagSet("asyncapi", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the AsyncAPI JSON and YAML documents. It does not generate any file if the
+// API does not define any event endpoint.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	s, err := New(g.API)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Channels) == 0 {
+		return nil, nil
+	}
+
+	asyncapiDir := filepath.Join(g.OutDir, "asyncapi")
+	codegen.RemoveAll(asyncapiDir)
+	if err = os.MkdirAll(asyncapiDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, asyncapiDir)
+
+	// JSON
+	rawJSON, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	asyncapiFile := filepath.Join(asyncapiDir, "asyncapi.json")
+	if err := codegen.WriteFile(asyncapiFile, rawJSON, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, asyncapiFile)
+
+	// YAML
+	rawYAML, err := jsonToYAML(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+	asyncapiFile = filepath.Join(asyncapiDir, "asyncapi.yaml")
+	if err := codegen.WriteFile(asyncapiFile, rawYAML, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, asyncapiFile)
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+func jsonToYAML(rawJSON []byte) ([]byte, error) {
+	return codegen.JSONToYAML(rawJSON)
+}
@@ -0,0 +1,38 @@
+package genasyncapi_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	genasyncapi "github.com/kyokomi/goa-v1/goagen/gen_asyncapi"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genasyncapi.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genasyncapi.NewGenerator(
+				genasyncapi.API(args.api),
+				genasyncapi.OutDir(args.outDir),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+		})
+	})
+})
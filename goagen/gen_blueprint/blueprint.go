@@ -0,0 +1,373 @@
+package genblueprint
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+type (
+	// Blueprint describes the content of the generated API Blueprint document.
+	Blueprint struct {
+		// Title of the API, the API name if the design does not define a title.
+		Title string
+		// Description of the API.
+		Description string
+		// Host is the URL of the API computed from its scheme and host.
+		Host string
+		// Groups lists the groups of resources, one per design resource.
+		Groups []*Group
+	}
+
+	// Group describes the resources of a design resource.
+	Group struct {
+		// Name of the design resource.
+		Name string
+		// Description of the design resource.
+		Description string
+		// Resources lists the resources in order of definition of their first action.
+		Resources []*Resource
+	}
+
+	// Resource describes the actions that share a path.
+	Resource struct {
+		// Name of the resource.
+		Name string
+		// URITemplate is the path of the resource, e.g. "/bottles/{id}".
+		URITemplate string
+		// Actions lists the actions of the resource.
+		Actions []*Action
+	}
+
+	// Action describes an action route together with its example request and responses.
+	Action struct {
+		// Name of the action.
+		Name string
+		// Description of the action.
+		Description string
+		// Method is the HTTP method of the route.
+		Method string
+		// URITemplate is the path of the route followed by its query string parameters, e.g.
+		// "/bottles/{id}{?verbose}".
+		URITemplate string
+		// Params lists the path parameters followed by the query string parameters, sorted
+		// by name.
+		Params []*Param
+		// Request is the example request, nil if the action has no payload and no headers.
+		Request *Message
+		// Responses lists the responses sorted by status.
+		Responses []*Response
+	}
+
+	// Param describes a path or query string parameter.
+	Param struct {
+		// Name of the parameter.
+		Name string
+		// Example is the example value of the parameter.
+		Example string
+		// Type is the API Blueprint type of the parameter, e.g. "number" or "enum[string]".
+		Type string
+		// Required is true if the parameter must be set.
+		Required bool
+		// Description of the parameter.
+		Description string
+		// Default is the default value of the parameter, empty if there is none.
+		Default string
+		// Members lists the enum values of the parameter.
+		Members []string
+	}
+
+	// Message describes the headers and body of an example request or response.
+	Message struct {
+		// ContentType is the value of the Content-Type header, empty if there is no body.
+		ContentType string
+		// Headers lists the example headers sorted by name.
+		Headers []*Header
+		// Body is the example body, empty if there is none.
+		Body string
+	}
+
+	// Header is an example header.
+	Header struct {
+		// Name of the header.
+		Name string
+		// Value of the header.
+		Value string
+	}
+
+	// Response describes an example response.
+	Response struct {
+		// Status is the HTTP status code of the response.
+		Status int
+		// Description of the response, empty if it is the default description, i.e. the
+		// status text.
+		Description string
+		*Message
+	}
+)
+
+// New builds the description of the API Blueprint document of the API. Each action route is
+// described with an example request built from the design examples and one example response per
+// response definition. The examples are generated with the random generator of the API so that
+// the document does not change unless the design does. WebSocket actions are skipped.
+func New(api *design.APIDefinition) (*Blueprint, error) {
+	b := &Blueprint{
+		Title:       api.Title,
+		Description: api.Description,
+		Host:        host(api),
+	}
+	if b.Title == "" {
+		b.Title = api.Name
+	}
+	rand := api.RandomGenerator()
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		g := &Group{Name: res.Name, Description: res.Description}
+		resources := make(map[string]*Resource)
+		err := res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() {
+				return nil
+			}
+			for _, route := range a.Routes {
+				act, err := action(api, a, route, rand)
+				if err != nil {
+					return fmt.Errorf("%s: %s", a.Context(), err)
+				}
+				path := uriTemplate(route.FullPath())
+				r, ok := resources[path]
+				if !ok {
+					r = &Resource{Name: res.Name, URITemplate: path}
+					resources[path] = r
+					g.Resources = append(g.Resources, r)
+				}
+				r.Actions = append(r.Actions, act)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(g.Resources) > 0 {
+			b.Groups = append(b.Groups, g)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// action builds the description of the given action route.
+func action(api *design.APIDefinition, a *design.ActionDefinition, route *design.RouteDefinition, rand *design.RandomGenerator) (*Action, error) {
+	act := &Action{
+		Name:        a.Name,
+		Description: a.Description,
+		Method:      route.Verb,
+		URITemplate: uriTemplate(route.FullPath()),
+	}
+	if a.Security != nil && a.Security.Scheme != nil {
+		sec := "Security: " + a.Security.Scheme.SchemeName
+		if len(a.Security.Scopes) > 0 {
+			sec += " (scopes: " + strings.Join(a.Security.Scopes, ", ") + ")"
+		}
+		if act.Description != "" {
+			act.Description += "\n\n"
+		}
+		act.Description += sec
+	}
+	pathParams := make(map[string]bool)
+	for _, n := range route.Params() {
+		pathParams[n] = true
+	}
+	params := a.AllParams()
+	if obj := params.Type.ToObject(); obj != nil {
+		var query, names []string
+		for _, n := range codegen.SortedNames(obj) {
+			if pathParams[n] {
+				act.Params = append(act.Params, param(n, obj[n], true, rand))
+			} else {
+				query = append(query, n)
+			}
+		}
+		for _, n := range query {
+			act.Params = append(act.Params, param(n, obj[n], params.IsRequired(n), rand))
+			names = append(names, n)
+		}
+		if len(names) > 0 {
+			act.URITemplate += "{?" + strings.Join(names, ",") + "}"
+		}
+	}
+	req := &Message{}
+	err := a.IterateHeaders(func(name string, _ bool, h *design.AttributeDefinition) error {
+		req.Headers = append(req.Headers, &Header{Name: name, Value: format(h.GenerateExample(rand, nil))})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if a.Payload != nil {
+		req.ContentType = "application/json"
+		if a.PayloadMultipart {
+			req.ContentType = "multipart/form-data"
+		} else if req.Body, err = example(a.Payload.AttributeDefinition, rand); err != nil {
+			return nil, fmt.Errorf("payload example: %s", err)
+		}
+	}
+	if req.ContentType != "" || len(req.Headers) > 0 {
+		act.Request = req
+	}
+	err = a.IterateResponses(func(r *design.ResponseDefinition) error {
+		resp, err := response(api, r, rand)
+		if err != nil {
+			return fmt.Errorf("response %#v: %s", r.Name, err)
+		}
+		act.Responses = append(act.Responses, resp)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(act.Responses, func(i, j int) bool { return act.Responses[i].Status < act.Responses[j].Status })
+	return act, nil
+}
+
+// response builds the example response of the given response definition, media types are
+// rendered with the view used by the response.
+func response(api *design.APIDefinition, r *design.ResponseDefinition, rand *design.RandomGenerator) (*Response, error) {
+	resp := &Response{Status: r.Status, Message: &Message{}}
+	if r.Description != http.StatusText(r.Status) {
+		resp.Description = r.Description
+	}
+	if r.Status == http.StatusNoContent || r.Status == http.StatusNotModified {
+		return resp, nil
+	}
+	var att *design.AttributeDefinition
+	if r.MediaType != "" {
+		resp.ContentType = r.MediaType
+		mt := api.MediaTypeWithIdentifier(r.MediaType)
+		if mt == nil && design.CanonicalIdentifier(r.MediaType) == design.CanonicalIdentifier(design.ErrorMedia.Identifier) {
+			mt = design.ErrorMedia
+		}
+		if mt == nil {
+			return resp, nil
+		}
+		resp.ContentType = mt.Identifier
+		view := design.DefaultView
+		if r.ViewName != "" {
+			view = r.ViewName
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return nil, err
+		}
+		att = p.AttributeDefinition
+	} else if r.Type != nil {
+		resp.ContentType = "application/json"
+		att = &design.AttributeDefinition{Type: r.Type}
+	}
+	if att != nil {
+		var err error
+		if resp.Body, err = example(att, rand); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// param builds the description of the parameter with the given name.
+func param(name string, att *design.AttributeDefinition, required bool, rand *design.RandomGenerator) *Param {
+	p := &Param{
+		Name:        name,
+		Example:     format(att.GenerateExample(rand, nil)),
+		Type:        typeName(att.Type),
+		Required:    required,
+		Description: strings.Replace(strings.TrimSpace(att.Description), "\n", " ", -1),
+	}
+	if att.DefaultValue != nil {
+		p.Default = format(att.DefaultValue)
+	}
+	if v := att.Validation; v != nil && len(v.Values) > 0 {
+		p.Type = "enum[" + p.Type + "]"
+		for _, val := range v.Values {
+			p.Members = append(p.Members, format(val))
+		}
+	}
+	return p
+}
+
+// example returns the indented JSON representation of an example of the given attribute.
+func example(att *design.AttributeDefinition, rand *design.RandomGenerator) (string, error) {
+	ex := att.GenerateExample(rand, nil)
+	if ex == nil {
+		return "", nil
+	}
+	js, err := json.MarshalIndent(ex, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	return string(js), nil
+}
+
+// typeName returns the API Blueprint type of a parameter of the given type.
+func typeName(dt design.DataType) string {
+	switch dt.Kind() {
+	case design.BooleanKind:
+		return "boolean"
+	case design.IntegerKind, design.NumberKind:
+		return "number"
+	case design.ArrayKind:
+		return "array"
+	case design.ObjectKind, design.HashKind, design.UserTypeKind, design.MediaTypeKind:
+		return "object"
+	}
+	return "string"
+}
+
+// uriTemplate returns the URI template of the given route path, wildcards use the reserved
+// expansion so that their values may contain slashes.
+func uriTemplate(path string) string {
+	return design.WildcardRegex.ReplaceAllStringFunc(path, func(w string) string {
+		if w[1] == '*' {
+			return "/{+" + w[2:] + "}"
+		}
+		return "/{" + w[2:] + "}"
+	})
+}
+
+// host returns the URL of the API host, it is empty if the design does not define the API host.
+func host(api *design.APIDefinition) string {
+	if api.Host == "" {
+		return ""
+	}
+	scheme := "http"
+	if len(api.Schemes) > 0 {
+		scheme = api.Schemes[0]
+	}
+	return scheme + "://" + api.Host
+}
+
+// format returns the string representation of the given example value, strings are returned
+// as is, slices as comma separated values and other values as JSON.
+func format(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+		vals := make([]string, rv.Len())
+		for i := range vals {
+			vals[i] = format(rv.Index(i).Interface())
+		}
+		return strings.Join(vals, ",")
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
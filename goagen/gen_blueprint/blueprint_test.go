@@ -0,0 +1,146 @@
+package genblueprint_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genblueprint "github.com/kyokomi/goa-v1/goagen/gen_blueprint"
+)
+
+var _ = Describe("New", func() {
+	var bp *genblueprint.Blueprint
+	var newErr error
+
+	BeforeEach(func() {
+		bp = nil
+		newErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		bp, newErr = genblueprint.New(Design)
+	})
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer, func() {
+						apidsl.Example(1)
+					})
+					apidsl.Attribute("name", String, func() {
+						apidsl.Example("Number 8")
+					})
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+				})
+			})
+			apidsl.API("cellar", func() {
+				apidsl.Title("Cellar")
+				apidsl.BasePath("/api")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"), apidsl.GET("/name/*name"))
+					apidsl.Params(func() {
+						apidsl.Param("id", Integer, "Bottle ID", func() {
+							apidsl.Example(1)
+						})
+						apidsl.Param("name", String)
+						apidsl.Param("sort", String, func() {
+							apidsl.Enum("asc", "desc")
+							apidsl.Default("asc")
+						})
+					})
+					apidsl.Headers(func() {
+						apidsl.Header("X-Trace", String, func() {
+							apidsl.Example("abc")
+						})
+					})
+					apidsl.Response(NotFound)
+					apidsl.Response(OK, bottle)
+				})
+				apidsl.Action("update", func() {
+					apidsl.Routing(apidsl.PUT("/:id"))
+					apidsl.Payload(func() {
+						apidsl.Attribute("name", String, func() {
+							apidsl.Example("Number 9")
+						})
+					})
+					apidsl.Response(NoContent)
+				})
+			})
+		})
+
+		It("groups the actions by resource and path", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(bp.Title).Should(Equal("Cellar"))
+			Ω(bp.Groups).Should(HaveLen(1))
+			g := bp.Groups[0]
+			Ω(g.Name).Should(Equal("bottle"))
+			Ω(g.Resources).Should(HaveLen(2))
+			var templates []string
+			for _, r := range g.Resources {
+				templates = append(templates, r.URITemplate)
+			}
+			Ω(templates).Should(ConsistOf("/api/bottles/{id}", "/api/bottles/name/{+name}"))
+		})
+
+		It("describes the parameters and example request", func() {
+			var show *genblueprint.Action
+			for _, r := range bp.Groups[0].Resources {
+				if r.URITemplate == "/api/bottles/{id}" {
+					for _, a := range r.Actions {
+						if a.Name == "show" {
+							show = a
+						}
+					}
+				}
+			}
+			Ω(show).ShouldNot(BeNil())
+			Ω(show.Method).Should(Equal("GET"))
+			Ω(show.URITemplate).Should(Equal("/api/bottles/{id}{?name,sort}"))
+			Ω(show.Params).Should(HaveLen(3))
+			Ω(show.Params[0].Name).Should(Equal("id"))
+			Ω(show.Params[0].Example).Should(Equal("1"))
+			Ω(show.Params[0].Type).Should(Equal("number"))
+			Ω(show.Params[0].Required).Should(BeTrue())
+			Ω(show.Params[0].Description).Should(Equal("Bottle ID"))
+			Ω(show.Params[2].Type).Should(Equal("enum[string]"))
+			Ω(show.Params[2].Default).Should(Equal("asc"))
+			Ω(show.Params[2].Members).Should(Equal([]string{"asc", "desc"}))
+			Ω(show.Request).ShouldNot(BeNil())
+			Ω(show.Request.Headers).Should(HaveLen(1))
+			Ω(show.Request.Headers[0].Value).Should(Equal("abc"))
+
+			Ω(show.Responses).Should(HaveLen(2))
+			Ω(show.Responses[0].Status).Should(Equal(200))
+			Ω(show.Responses[0].ContentType).Should(Equal("application/vnd.bottle+json"))
+			Ω(show.Responses[0].Body).Should(ContainSubstring(`"name": "Number 8"`))
+			Ω(show.Responses[1].Status).Should(Equal(404))
+			Ω(show.Responses[1].Body).Should(BeEmpty())
+		})
+
+		It("renders the document", func() {
+			b, err := bp.Write()
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(b)
+			Ω(content).Should(HavePrefix("FORMAT: 1A\n"))
+			Ω(content).Should(ContainSubstring("# Cellar\n"))
+			Ω(content).Should(ContainSubstring("## bottle [/api/bottles/{id}]\n"))
+			Ω(content).Should(ContainSubstring("### update [PUT /api/bottles/{id}]\n"))
+			Ω(content).Should(ContainSubstring("    + id: `1` (number, required) - Bottle ID\n"))
+			Ω(content).Should(ContainSubstring("+ Request (application/json)\n\n    + Body\n\n            {\n                \"name\": \"Number 9\"\n            }\n"))
+			Ω(content).Should(ContainSubstring("+ Response 204\n"))
+		})
+	})
+})
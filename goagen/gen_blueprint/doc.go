@@ -0,0 +1,15 @@
+/*
+Package genblueprint provides a generator for the API Blueprint document of the API. The generator
+produces a "blueprint/api.apib" file with one group per resource and one action section per action
+route. Each action lists its path and query string parameters, an example request built from the
+design examples and an example response per response definition.
+
+The document can be imported into Apiary or tested against a running implementation with Dredd:
+
+	dredd blueprint/api.apib http://localhost:8080
+
+Media type responses are rendered with the view used by the response. The examples are generated
+deterministically so that the document only changes when the design does. WebSocket actions are not
+described.
+*/
+package genblueprint
@@ -0,0 +1,13 @@
+package genblueprint_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenBlueprint(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenBlueprint Suite")
+}
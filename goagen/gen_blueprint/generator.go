@@ -0,0 +1,100 @@
+package genblueprint
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of an API Blueprint Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the API Blueprint generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, ver string
+
+	set := flag.NewFlagSet("blueprint", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the API Blueprint document.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	b, err := New(g.API)
+	if err != nil {
+		return nil, err
+	}
+
+	blueprintDir := filepath.Join(g.OutDir, "blueprint")
+	if err = codegen.RemoveAll(blueprintDir); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(blueprintDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, blueprintDir)
+
+	content, err := b.Write()
+	if err != nil {
+		return nil, err
+	}
+	blueprintFile := filepath.Join(blueprintDir, "api.apib")
+	if err = codegen.WriteFile(blueprintFile, content, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, blueprintFile)
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
@@ -0,0 +1,101 @@
+package genblueprint_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	genblueprint "github.com/kyokomi/goa-v1/goagen/gen_blueprint"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genblueprint.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genblueprint.NewGenerator(
+				genblueprint.API(args.api),
+				genblueprint.OutDir(args.outDir),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(workspace.Path, "")
+		Ω(err).ShouldNot(HaveOccurred())
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {
+			apidsl.Host("cellar.example.com")
+			apidsl.BasePath("/api")
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Params(func() {
+					apidsl.Param("id", design.Integer, func() {
+						apidsl.Example(1)
+					})
+				})
+				apidsl.Response(design.OK, func() {
+					apidsl.Media(design.ErrorMedia)
+				})
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genblueprint.NewGenerator(
+			genblueprint.API(design.Design),
+			genblueprint.OutDir(outDir),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("generates the API Blueprint document", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(HaveLen(2))
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "blueprint", "api.apib"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(HavePrefix("FORMAT: 1A\nHOST: http://cellar.example.com\n"))
+		Ω(string(content)).Should(ContainSubstring("# Group bottle"))
+		Ω(string(content)).Should(ContainSubstring("### show [GET /api/bottles/{id}]"))
+		Ω(string(content)).Should(ContainSubstring("+ Response 200 (application/vnd.goa.error)"))
+	})
+})
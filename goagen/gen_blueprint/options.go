@@ -0,0 +1,20 @@
+package genblueprint
+
+import "github.com/kyokomi/goa-v1/design"
+
+// Option a generator option definition
+type Option func(*Generator)
+
+// API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+// OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
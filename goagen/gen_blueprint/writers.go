@@ -0,0 +1,82 @@
+package genblueprint
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/version"
+)
+
+// funcMap lists the functions available to the templates.
+var funcMap = template.FuncMap{
+	"indent": indent,
+}
+
+// Write renders the API Blueprint document.
+func (b *Blueprint) Write() ([]byte, error) {
+	tmpl, err := template.New("blueprint").Funcs(funcMap).Parse(codegen.Template("blueprint", "blueprint", blueprintT))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"Blueprint":   b,
+		"ToolVersion": version.String(),
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// indent prefixes each line of s with n spaces so that it renders as a code block nested in
+// a list item.
+func indent(n int, s string) string {
+	prefix := strings.Repeat(" ", n)
+	return prefix + strings.Replace(s, "\n", "\n"+prefix, -1)
+}
+
+// blueprintT renders the API Blueprint document.
+// input: map[string]interface{}{"Blueprint": *Blueprint, "ToolVersion": string}
+const blueprintT = `FORMAT: 1A
+{{ $bp := .Blueprint }}{{ if $bp.Host }}HOST: {{ $bp.Host }}
+{{ end }}
+<!-- Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT. -->
+
+# {{ $bp.Title }}
+{{ if $bp.Description }}
+{{ $bp.Description }}
+{{ end }}{{ range $bp.Groups }}
+# Group {{ .Name }}
+{{ if .Description }}
+{{ .Description }}
+{{ end }}{{ range .Resources }}
+## {{ .Name }} [{{ .URITemplate }}]
+{{ range .Actions }}
+### {{ .Name }} [{{ .Method }} {{ .URITemplate }}]
+{{ if .Description }}
+{{ .Description }}
+{{ end }}{{ if .Params }}
++ Parameters
+{{ range .Params }}    + {{ .Name }}: ` + "`{{ .Example }}`" + ` ({{ .Type }}, {{ if .Required }}required{{ else }}optional{{ end }}){{ if .Description }} - {{ .Description }}{{ end }}
+{{ if .Default }}        + Default: ` + "`{{ .Default }}`" + `
+{{ end }}{{ if .Members }}        + Members
+{{ range .Members }}            + ` + "`{{ . }}`" + `
+{{ end }}{{ end }}{{ end }}{{ end }}{{ with .Request }}
++ Request{{ if .ContentType }} ({{ .ContentType }}){{ end }}
+{{ template "message" . }}{{ end }}{{ range .Responses }}
++ Response {{ .Status }}{{ if .ContentType }} ({{ .ContentType }}){{ end }}
+{{ if .Description }}
+{{ indent 4 .Description }}
+{{ end }}{{ template "message" .Message }}{{ end }}{{ end }}{{ end }}{{ end }}
+{{- define "message" }}{{ if .Headers }}
+    + Headers
+
+{{ range .Headers }}            {{ .Name }}: {{ .Value }}
+{{ end }}{{ end }}{{ if .Body }}
+    + Body
+
+{{ indent 12 .Body }}
+{{ end }}{{ end }}`
@@ -7,6 +7,7 @@ import (
 	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -50,7 +51,7 @@ func (g *Generator) generateMain(mainFile string, clientPkg, cliPkg string, func
 	funcs["signerArgs"] = signerArgs
 
 	g.genfiles = append(g.genfiles, mainFile)
-	version := design.Design.Version
+	version := g.API.Version
 	if version == "" {
 		version = "0"
 	}
@@ -90,7 +91,7 @@ func (g *Generator) generateMain(mainFile string, clientPkg, cliPkg string, func
 		HasAPIKeySigners:    hasAPIKeySigners,
 		HasTokenSigners:     hasTokenSigners,
 	}
-	err = file.ExecuteTemplate("main", mainTmpl, funcs, data)
+	err = file.ExecuteTemplate("main", codegen.Template("client", "cli_main", mainTmpl), funcs, data)
 	return
 }
 
@@ -114,16 +115,18 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 	funcs["routes"] = routes
 	funcs["flagType"] = flagType
 	funcs["defaultVal"] = defaultVal
+	funcs["flagEnum"] = flagEnum
+	funcs["tableColumns"] = func(a *design.ActionDefinition) string { return tableColumns(g.API, a) }
 	funcs["cmdFieldType"] = cmdFieldTypeString
 	funcs["formatExample"] = formatExample
 	funcs["shouldAddExample"] = shouldAddExample
 	funcs["kebabCase"] = codegen.KebabCase
 
-	commandTypesTmpl := template.Must(template.New("commandTypes").Funcs(funcs).Parse(commandTypesTmpl))
-	commandsTmpl := template.Must(template.New("commands").Funcs(funcs).Parse(commandsTmpl))
-	commandsTmplWS := template.Must(template.New("commandsWS").Funcs(funcs).Parse(commandsTmplWS))
-	downloadCommandTmpl := template.Must(template.New("download").Funcs(funcs).Parse(downloadCommandTmpl))
-	registerTmpl := template.Must(template.New("register").Funcs(funcs).Parse(registerTmpl))
+	commandTypesTmpl := template.Must(template.New("commandTypes").Funcs(funcs).Parse(codegen.Template("client", "command_types", commandTypesTmpl)))
+	commandsTmpl := template.Must(template.New("commands").Funcs(funcs).Parse(codegen.Template("client", "commands", commandsTmpl)))
+	commandsTmplWS := template.Must(template.New("commandsWS").Funcs(funcs).Parse(codegen.Template("client", "commands_ws", commandsTmplWS)))
+	downloadCommandTmpl := template.Must(template.New("download").Funcs(funcs).Parse(codegen.Template("client", "download_command", downloadCommandTmpl)))
+	registerTmpl := template.Must(template.New("register").Funcs(funcs).Parse(codegen.Template("client", "register", registerTmpl)))
 
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("encoding/json"),
@@ -146,6 +149,10 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 	if len(g.API.Resources) > 0 {
 		imports = append(imports, codegen.NewImport("goaclient", "github.com/kyokomi/goa-v1/client"))
 	}
+	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		imports = actionImports(imports, res)
+		return nil
+	})
 	title := fmt.Sprintf("%s: CLI Commands", g.API.Context())
 	if err = file.WriteHeader(title, "cli", imports); err != nil {
 		return err
@@ -184,15 +191,17 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 		})
 	})
 	data := struct {
+		Name         string
 		Actions      map[string][]*design.ActionDefinition
 		Package      string
 		HasDownloads bool
 	}{
+		Name:         g.API.Name,
 		Actions:      actions,
 		Package:      g.Target,
 		HasDownloads: hasDownloads,
 	}
-	if err = file.ExecuteTemplate("registerCmds", registerCmdsT, funcs, data); err != nil {
+	if err = file.ExecuteTemplate("registerCmds", codegen.Template("client", "register_commands", registerCmdsT), funcs, data); err != nil {
 		return err
 	}
 
@@ -259,6 +268,77 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 }
 
 // defaultRouteParams returns the parameters needed to build the first route of the given action.
+func (g *Generator) generateConfig(configFile string, funcs template.FuncMap) (err error) {
+	var file *codegen.SourceFile
+	file, err = codegen.SourceFileFor(configFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("io/ioutil"),
+		codegen.SimpleImport("os"),
+		codegen.SimpleImport("path/filepath"),
+		codegen.SimpleImport("sort"),
+		codegen.SimpleImport("strings"),
+		codegen.SimpleImport("github.com/spf13/cobra"),
+	}
+	title := fmt.Sprintf("%s: CLI Configuration", g.API.Context())
+	if err = file.WriteHeader(title, "cli", imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, configFile)
+
+	data := struct {
+		Tool      string
+		EnvPrefix string
+		Settings  []string
+	}{
+		Tool:      g.Tool,
+		EnvPrefix: strings.ToUpper(codegen.SnakeCase(codegen.Goify(g.API.Name, true))) + "_",
+		Settings:  configSettings(g.API),
+	}
+	err = file.ExecuteTemplate("config", codegen.Template("client", "config", configTmpl), funcs, data)
+	return
+}
+
+// configSettings returns the names of the global flags of the generated tool whose default
+// value can be set in the configuration file or via environment variables.
+func configSettings(api *design.APIDefinition) []string {
+	settings := []string{"scheme", "host", "timeout", "retries"}
+	var hasBasic, hasAPIKey, hasToken bool
+	for _, s := range api.SecuritySchemes {
+		if signerType(s) == "" {
+			continue
+		}
+		switch s.Type {
+		case "basic":
+			hasBasic = true
+		case "apiKey":
+			hasAPIKey = true
+		case "jwt", "oauth2":
+			hasToken = true
+		}
+	}
+	if hasBasic {
+		settings = append(settings, "user", "pass")
+	}
+	if hasAPIKey {
+		settings = append(settings, "key", "format")
+	}
+	if hasToken {
+		settings = append(settings, "token", "token-type")
+	}
+	return settings
+}
+
 func defaultRouteParams(a *design.ActionDefinition) *design.AttributeDefinition {
 	r := a.Routes[0]
 	params := r.Params()
@@ -347,10 +427,13 @@ func joinNames(useNil bool, atts ...*design.AttributeDefinition) string {
 // resolve non required, non array Param/QueryParam for access via CII flags.
 // Some types need convertion from string to 'Type' before calling rich client Commands.
 func flagTypeVal(a *design.AttributeDefinition, key string, field string) string {
+	if design.CustomPrimitive(a.Type) != nil {
+		return "%s"
+	}
 	switch a.Type {
 	case design.Integer:
 		return `intFlagVal("` + key + `", ` + field + ")"
-	case design.String:
+	case design.String, design.Decimal:
 		return `stringFlagVal("` + key + `", ` + field + ")"
 	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Any:
 		return "%s"
@@ -364,6 +447,9 @@ func flagTypeVal(a *design.AttributeDefinition, key string, field string) string
 // Special types like Number/UUID need to be converted from String
 // %s maps to specialTypeResult.Temps
 func flagRequiredTypeVal(a *design.AttributeDefinition, field string) string {
+	if design.CustomPrimitive(a.Type) != nil {
+		return "*%s"
+	}
 	switch a.Type {
 	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Any:
 		return "*%s"
@@ -376,6 +462,9 @@ func flagRequiredTypeVal(a *design.AttributeDefinition, field string) string {
 // Special types like Number/UUID need to be converted from String
 // %s maps to specialTypeResult.Temps
 func flagTypeArrayVal(a *design.AttributeDefinition, field string) string {
+	if design.CustomPrimitive(a.Type.ToArray().ElemType.Type) != nil {
+		return "%s"
+	}
 	switch a.Type.ToArray().ElemType.Type {
 	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Any:
 		return "%s"
@@ -460,6 +549,22 @@ func handleSpecialTypes(atts ...*design.AttributeDefinition) specialTypeResult {
 					typeHandler = "jsonArray"
 				}
 			}
+			if typeHandler == "" && isCustomPrimitive(a.Type) {
+				tmpVar := codegen.Tempvar()
+				if att.IsRequired(n) {
+					names = append(names, tmpVar)
+				} else {
+					optNames = append(optNames, tmpVar)
+				}
+				result.Output += customPrimitiveCode(a, tmpVar, typ, field, nilVal, n)
+				if att.IsRequired(n) {
+					result.Output += fmt.Sprintf(`
+	if %s == nil {
+		goa.LogError(ctx, "required flag is missing", "flag", "--%s")
+		return fmt.Errorf("required flag %s is missing")
+	}`, tmpVar, n, n)
+				}
+			}
 			if typeHandler != "" {
 				tmpVar := codegen.Tempvar()
 				if att.IsRequired(n) {
@@ -494,6 +599,47 @@ func handleSpecialTypes(atts ...*design.AttributeDefinition) specialTypeResult {
 	return result
 }
 
+// isCustomPrimitive returns true if t is a custom primitive or an array of custom primitives.
+func isCustomPrimitive(t design.DataType) bool {
+	if t.IsArray() {
+		return design.CustomPrimitive(t.ToArray().ElemType.Type) != nil
+	}
+	return design.CustomPrimitive(t) != nil
+}
+
+// customPrimitiveCode returns the code that parses the string flag field into the tmpVar
+// variable of type typ. a must be a custom primitive or an array of custom primitives.
+func customPrimitiveCode(a *design.AttributeDefinition, tmpVar, typ, field, nilVal, flag string) string {
+	if a.Type.IsArray() {
+		elem := a.Type.ToArray().ElemType.Type
+		return fmt.Sprintf(`
+	var %s %s
+	if %s != %s {
+		%s = make(%s, len(%s))
+		for i, rv := range %s {
+			v, err := %s
+			if err != nil {
+				goa.LogError(ctx, "failed to parse flag into %s value", "flag", "--%s", "err", err)
+				return err
+			}
+			%s[i] = v
+		}
+	}`, tmpVar, typ, field, nilVal, tmpVar, typ, field, field,
+			codegen.PrimitiveParser(elem, "rv"), typ, flag, tmpVar)
+	}
+	return fmt.Sprintf(`
+	var %s %s
+	if %s != %s {
+		v, err := %s
+		if err != nil {
+			goa.LogError(ctx, "failed to parse flag into %s value", "flag", "--%s", "err", err)
+			return err
+		}
+		%s = &v
+	}`, tmpVar, typ, field, nilVal, codegen.PrimitiveParser(a.Type, field),
+		codegen.GoNativeType(a.Type), flag, tmpVar)
+}
+
 // routes create the action command "Use" suffix.
 func routes(action *design.ActionDefinition) string {
 	var buf bytes.Buffer
@@ -569,6 +715,8 @@ func flagType(att *design.AttributeDefinition) string {
 		return "String"
 	case design.UUIDKind:
 		return "String"
+	case design.DecimalKind:
+		return "String"
 	case design.AnyKind:
 		return "String"
 	case design.ArrayKind:
@@ -585,10 +733,75 @@ func flagType(att *design.AttributeDefinition) string {
 	case design.MediaTypeKind:
 		return flagType(att.Type.(*design.MediaTypeDefinition).AttributeDefinition)
 	default:
+		if design.CustomPrimitive(att.Type) != nil {
+			return "String"
+		}
 		panic("invalid flag attribute type " + att.Type.Name())
 	}
 }
 
+// flagEnum returns the quoted values accepted by the flag corresponding to att separated with
+// commas, empty if att has no enum validation. The values of array flags are the element values.
+func flagEnum(att *design.AttributeDefinition) string {
+	if arr := att.Type.ToArray(); arr != nil {
+		att = arr.ElemType
+	}
+	if att.Validation == nil || len(att.Validation.Values) == 0 {
+		return ""
+	}
+	vals := make([]string, len(att.Validation.Values))
+	for i, v := range att.Validation.Values {
+		vals[i] = strconv.Quote(fmt.Sprintf("%v", v))
+	}
+	return strings.Join(vals, ", ")
+}
+
+// tableColumns returns the Go literal listing the primitive attributes of the view of the media
+// type used by the first successful response of a, these are the columns of the table output.
+// The attributes of the elements are used for collections.
+func tableColumns(api *design.APIDefinition, a *design.ActionDefinition) string {
+	var resp *design.ResponseDefinition
+	a.IterateResponses(func(r *design.ResponseDefinition) error {
+		if r.Status >= 200 && r.Status < 300 && r.MediaType != "" &&
+			(resp == nil || r.Status < resp.Status) {
+			resp = r
+		}
+		return nil
+	})
+	if resp == nil {
+		return "nil"
+	}
+	mt := api.MediaTypeWithIdentifier(resp.MediaType)
+	if mt == nil {
+		return "nil"
+	}
+	view := resp.ViewName
+	if view == "" {
+		view = design.DefaultView
+	}
+	projected, _, err := mt.Project(view)
+	if err != nil {
+		return "nil"
+	}
+	var obj design.Object
+	if arr := projected.ToArray(); arr != nil {
+		obj = arr.ElemType.Type.ToObject()
+	} else {
+		obj = projected.ToObject()
+	}
+	var cols []string
+	for n, att := range obj {
+		if att.Type.IsPrimitive() {
+			cols = append(cols, strconv.Quote(n))
+		}
+	}
+	if len(cols) == 0 {
+		return "nil"
+	}
+	sort.Strings(cols)
+	return "[]string{" + strings.Join(cols, ", ") + "}"
+}
+
 func defaultVal(att *design.AttributeDefinition) string {
 	if att.Type.Kind() == design.IntegerKind {
 		return fmt.Sprintf("%v", att.DefaultValue)
@@ -628,6 +841,9 @@ func main() {
 	app.PersistentFlags().StringVarP(&c.Host, "host", "H", "{{ .API.Host }}", "API hostname")
 	app.PersistentFlags().DurationVarP(&httpClient.Timeout, "timeout", "t", time.Duration(20) * time.Second, "Set the request timeout")
 	app.PersistentFlags().BoolVar(&c.Dump, "dump", false, "Dump HTTP request and response.")
+	var retries int
+	app.PersistentFlags().IntVar(&retries, "retries", 0, "Number of times the idempotent requests failing with a transport error, a 5xx or a 429 response are retried")
+	app.PersistentFlags().String("profile", "", "Name of the configuration profile providing the flag defaults")
 
 {{ if .HasSigners }}	// Register signer flags
 {{ if .HasBasicAuthSigners }} var user, pass string
@@ -639,13 +855,21 @@ func main() {
 {{ end }}{{ if .HasTokenSigners }} var token, typ string
 	app.PersistentFlags().StringVar(&token, "token", "", "Token used for authentication")
 	app.PersistentFlags().StringVar(&typ, "token-type", "Bearer", "Token type used for authentication")
-{{ end }}
-	// Parse flags and setup signers
+{{ end }}{{ end }}
+	// Parse flags, apply configuration{{ if .HasSigners }} and setup signers{{ end }}
 	app.ParseFlags(os.Args)
+	if err := cli.ApplyConfig(app); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(-1)
+	}
+	if retries > 0 {
+		c.Retry = goaclient.DefaultRetryPolicy()
+		c.Retry.MaxAttempts = retries + 1
+	}
 {{ if .HasTokenSigners }}	source := &goaclient.StaticTokenSource{
 		StaticToken: &goaclient.StaticToken{Type: typ, Value: token},
 	}
-{{ end }}{{ end }}{{ range $security := .API.SecuritySchemes }}{{ $signer := signerType $security }}{{ if $signer }}{{/*
+{{ end }}{{ range $security := .API.SecuritySchemes }}{{ $signer := signerType $security }}{{ if $signer }}{{/*
 */}}	{{ goify $security.SchemeName false }}Signer := new{{ goify $security.SchemeName true }}Signer({{ signerArgs $security }}){{ end }}
 {{ end }}
 
@@ -654,8 +878,10 @@ func main() {
 */}}	c.Set{{ goify $security.SchemeName true }}Signer({{ goify $security.SchemeName false }}Signer)
 {{ end }}{{ end }} c.UserAgent = "{{ .API.Name }}-cli/{{ .Version }}"
 
-	// Register API commands
+	// Register API, configuration and completion commands
 	cli.RegisterCommands(app, c)
+	cli.RegisterConfigCommands(app)
+	cli.RegisterCompletionCommand(app)
 
 	// Execute!
 	if err := app.Execute(); err != nil {
@@ -707,6 +933,8 @@ const commandTypesTmpl = `{{ $cmdName := goify (printf "%s%sCommand" .Name (titl
 {{ end }}{{ end }}{{ $headers := .Headers }}{{ if $headers }}{{ range $name, $att := $headers.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
 {{ end }}		{{ goify $name true }} {{ cmdFieldType $att.Type false}}
 {{ end }}{{ end }}		PrettyPrint bool
+		Output string
+		Query string
 	}
 
 `
@@ -801,14 +1029,17 @@ func (cmd *{{ $cmdName }}) RegisterFlags(cc *cobra.Command, c *{{ .Package }}.Cl
 */}}{{ if not $pparam.DefaultValue }}	var {{ $tmp }} {{ cmdFieldType $pparam.Type false }}
 {{ end }}	cc.Flags().{{ flagType $pparam }}Var(&cmd.{{ goify $pname true }}, "{{ $pname }}", {{/*
 */}}{{ if $pparam.DefaultValue }}{{ defaultVal $pparam }}{{ else }}{{ $tmp }}{{ end }}, ` + "`" + `{{ escapeBackticks $pparam.Description }}` + "`" + `)
-{{ end }}{{ end }}{{ $params := .Action.QueryParams }}{{ if $params }}{{ range $name, $param := $params.Type.ToObject }}{{ $tmp := goify $name false }}{{/*
+{{ $enum := flagEnum $pparam }}{{ if $enum }}	cc.RegisterFlagCompletionFunc("{{ $pname }}", enumCompletion({{ $enum }}))
+{{ end }}{{ end }}{{ end }}{{ $params := .Action.QueryParams }}{{ if $params }}{{ range $name, $param := $params.Type.ToObject }}{{ $tmp := goify $name false }}{{/*
 */}}{{ if not $param.DefaultValue }}	var {{ $tmp }} {{ cmdFieldType $param.Type false }}
 {{ end }}	cc.Flags().{{ flagType $param }}Var(&cmd.{{ goify $name true }}, "{{ $name }}", {{/*
 */}}{{ if $param.DefaultValue }}{{ defaultVal $param }}{{ else }}{{ $tmp }}{{ end }}, ` + "`" + `{{ escapeBackticks $param.Description }}` + "`" + `)
-{{ end }}{{ end }}{{ $headers := .Action.Headers }}{{ if $headers }}{{ range $name, $header := $headers.Type.ToObject }}{{/*
+{{ $enum := flagEnum $param }}{{ if $enum }}	cc.RegisterFlagCompletionFunc("{{ $name }}", enumCompletion({{ $enum }}))
+{{ end }}{{ end }}{{ end }}{{ $headers := .Action.Headers }}{{ if $headers }}{{ range $name, $header := $headers.Type.ToObject }}{{/*
 */}} cc.Flags().StringVar(&cmd.{{ goify $name true }}, "{{ $name }}", {{/*
 */}}{{ if $header.DefaultValue }}{{ defaultVal $header }}{{ else }}""{{ end }}, ` + "`" + `{{ escapeBackticks $header.Description }}` + "`" + `)
-{{ end }}{{ end }}}`
+{{ $enum := flagEnum $header }}{{ if $enum }}	cc.RegisterFlagCompletionFunc("{{ $name }}", enumCompletion({{ $enum }}))
+{{ end }}{{ end }}{{ end }}}`
 
 const commandsTmpl = `
 {{ $cmdName := goify (printf "%s%sCommand" .Action.Name (title (kebabCase .Resource.Name))) true }}// Run makes the HTTP request corresponding to the {{ $cmdName }} command.
@@ -839,7 +1070,12 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 		return err
 	}
 
-	goaclient.HandleResponse(c.Client, resp, cmd.PrettyPrint)
+	goaclient.HandleResponseOutput(c.Client, resp, &goaclient.Output{
+		Format:  cmd.Output,
+		Query:   cmd.Query,
+		Pretty:  cmd.PrettyPrint,
+		Columns: {{ tableColumns .Action }},
+	})
 	return nil
 }
 `
@@ -866,7 +1102,10 @@ Payload example:
 	}
 	{{ $tmp }}.RegisterFlags(sub, c)
 	sub.PersistentFlags().BoolVar(&{{ $tmp }}.PrettyPrint, "pp", false, "Pretty print response body")
-	command.AddCommand(sub)
+{{ if not $action.WebSocket }}	sub.PersistentFlags().StringVarP(&{{ $tmp }}.Output, "output", "o", "json", "Output format, one of json, yaml or table")
+	sub.RegisterFlagCompletionFunc("output", enumCompletion("json", "yaml", "table"))
+	sub.PersistentFlags().StringVar(&{{ $tmp }}.Query, "query", "", "JSONPath expression selecting the printed part of the response body, e.g. '$.items[*].name'")
+{{ end }}	command.AddCommand(sub)
 {{ end }}app.AddCommand(command)
 {{ end }}{{ end }}{{ if .HasDownloads }}
 	dl := new(DownloadCommand)
@@ -881,6 +1120,43 @@ Payload example:
 	app.AddCommand(dlc)
 {{ end }}}
 
+// RegisterCompletionCommand registers the command that prints the shell completion scripts.
+// The scripts complete the commands, the flags and the values of the flags that accept a fixed set
+// of values.
+func RegisterCompletionCommand(app *cobra.Command) {
+	app.AddCommand(&cobra.Command{
+		Use:   "completion [bash|zsh|fish]",
+		Short: "Print the shell completion script",
+		Long: ` + "`" + `Print the shell completion script.
+
+To load the completions in the current shell session run:
+
+	bash: source <({{ .Name }}-cli completion bash)
+	zsh:  source <({{ .Name }}-cli completion zsh)
+	fish: {{ .Name }}-cli completion fish | source` + "`" + `,
+		ValidArgs:             []string{"bash", "zsh", "fish"},
+		Args:                  cobra.ExactValidArgs(1),
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch args[0] {
+			case "bash":
+				return app.GenBashCompletionV2(cmd.OutOrStdout(), true)
+			case "zsh":
+				return app.GenZshCompletion(cmd.OutOrStdout())
+			default:
+				return app.GenFishCompletion(cmd.OutOrStdout(), true)
+			}
+		},
+	})
+}
+
+// enumCompletion returns the completion function of a flag that accepts the given values only.
+func enumCompletion(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
 func intFlagVal(name string, parsed int) *int {
 	if hasFlag(name) {
 		return &parsed
@@ -1033,3 +1309,291 @@ func boolArray(ins []string) ([]bool, error) {
 	}
 	return vals, nil
 }`
+
+const configTmpl = `// Settings lists the global flags whose default value can be set in a configuration profile or
+// via environment variables. Environment variables are named after the settings prefixed with
+// {{ .EnvPrefix }}, e.g. {{ .EnvPrefix }}HOST overrides the "host" setting. Flags given on the
+// command line take precedence over the environment variables which take precedence over the
+// configuration profile.
+var Settings = []string{ {{ range .Settings }}"{{ . }}", {{ end }} }
+
+// secretSettings lists the settings whose values are not displayed by "config list".
+var secretSettings = map[string]bool{"pass": true, "key": true, "token": true}
+
+// Config is the content of the configuration file.
+type Config struct {
+	// Current is the name of the profile used when none is given via the --profile flag or
+	// the {{ .EnvPrefix }}PROFILE environment variable.
+	Current string ` + "`" + `json:"current,omitempty"` + "`" + `
+	// Profiles maps the profile names to their settings.
+	Profiles map[string]map[string]string ` + "`" + `json:"profiles,omitempty"` + "`" + `
+}
+
+// ConfigPath returns the path to the configuration file. The path may be overridden with the
+// {{ .EnvPrefix }}CONFIG environment variable.
+func ConfigPath() (string, error) {
+	if p := os.Getenv("{{ .EnvPrefix }}CONFIG"); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "{{ .Tool }}", "config.json"), nil
+}
+
+// LoadConfig reads the configuration file, it returns an empty configuration if the file does
+// not exist.
+func LoadConfig() (*Config, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	b, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(b, cfg); err != nil {
+			return nil, fmt.Errorf("invalid configuration file %s: %s", path, err)
+		}
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]map[string]string)
+	}
+	return cfg, nil
+}
+
+// Save writes the configuration file.
+func (cfg *Config) Save() error {
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0600)
+}
+
+// ActiveProfile returns the name of the profile given via the --profile flag, the
+// {{ .EnvPrefix }}PROFILE environment variable, the current profile of the configuration or
+// "default".
+func (cfg *Config) ActiveProfile(app *cobra.Command) string {
+	if f := app.PersistentFlags().Lookup("profile"); f != nil && f.Changed {
+		return f.Value.String()
+	}
+	if p := os.Getenv("{{ .EnvPrefix }}PROFILE"); p != "" {
+		return p
+	}
+	if cfg.Current != "" {
+		return cfg.Current
+	}
+	return "default"
+}
+
+// ApplyConfig sets the global flags that are not given on the command line from the
+// environment variables and the active configuration profile. It must be called after the
+// flags are parsed.
+func ApplyConfig(app *cobra.Command) error {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	profile := cfg.Profiles[cfg.ActiveProfile(app)]
+	flags := app.PersistentFlags()
+	for _, name := range Settings {
+		f := flags.Lookup(name)
+		if f == nil || f.Changed {
+			continue
+		}
+		val, ok := os.LookupEnv(envVar(name))
+		if !ok {
+			val, ok = profile[name]
+		}
+		if !ok {
+			continue
+		}
+		if err := flags.Set(name, val); err != nil {
+			return fmt.Errorf("invalid value %q for setting %s: %s", val, name, err)
+		}
+	}
+	return nil
+}
+
+// RegisterConfigCommands registers the commands that manage the configuration profiles.
+func RegisterConfigCommands(app *cobra.Command) {
+	command := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the configuration profiles",
+		Long: "Manage the configuration profiles stored in the configuration file.\n\nSettings: " +
+			strings.Join(Settings, ", "),
+	}
+	command.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the configuration profiles and their settings",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := LoadConfig()
+			if err != nil {
+				return err
+			}
+			active := cfg.ActiveProfile(app)
+			for _, name := range sortedKeys(cfg.Profiles) {
+				marker := " "
+				if name == active {
+					marker = "*"
+				}
+				fmt.Printf("%s %s\n", marker, name)
+				profile := cfg.Profiles[name]
+				for _, key := range sortedKeys(profile) {
+					val := profile[key]
+					if secretSettings[key] && val != "" {
+						val = "********"
+					}
+					fmt.Printf("    %s = %s\n", key, val)
+				}
+			}
+			return nil
+		},
+	})
+	command.AddCommand(&cobra.Command{
+		Use:   "get SETTING",
+		Short: "Print the value of a setting of the active profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkSetting(args[0]); err != nil {
+				return err
+			}
+			cfg, err := LoadConfig()
+			if err != nil {
+				return err
+			}
+			fmt.Println(cfg.Profiles[cfg.ActiveProfile(app)][args[0]])
+			return nil
+		},
+	})
+	command.AddCommand(&cobra.Command{
+		Use:   "set SETTING VALUE",
+		Short: "Set the value of a setting of the active profile",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkSetting(args[0]); err != nil {
+				return err
+			}
+			cfg, err := LoadConfig()
+			if err != nil {
+				return err
+			}
+			name := cfg.ActiveProfile(app)
+			if cfg.Profiles[name] == nil {
+				cfg.Profiles[name] = make(map[string]string)
+			}
+			cfg.Profiles[name][args[0]] = args[1]
+			return cfg.Save()
+		},
+	})
+	command.AddCommand(&cobra.Command{
+		Use:   "unset SETTING",
+		Short: "Remove a setting from the active profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkSetting(args[0]); err != nil {
+				return err
+			}
+			cfg, err := LoadConfig()
+			if err != nil {
+				return err
+			}
+			delete(cfg.Profiles[cfg.ActiveProfile(app)], args[0])
+			return cfg.Save()
+		},
+	})
+	command.AddCommand(&cobra.Command{
+		Use:   "use PROFILE",
+		Short: "Make the given profile the current profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := LoadConfig()
+			if err != nil {
+				return err
+			}
+			if _, ok := cfg.Profiles[args[0]]; !ok {
+				cfg.Profiles[args[0]] = make(map[string]string)
+			}
+			cfg.Current = args[0]
+			return cfg.Save()
+		},
+	})
+	command.AddCommand(&cobra.Command{
+		Use:   "delete PROFILE",
+		Short: "Delete a configuration profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := LoadConfig()
+			if err != nil {
+				return err
+			}
+			if _, ok := cfg.Profiles[args[0]]; !ok {
+				return fmt.Errorf("unknown profile %q", args[0])
+			}
+			delete(cfg.Profiles, args[0])
+			if cfg.Current == args[0] {
+				cfg.Current = ""
+			}
+			return cfg.Save()
+		},
+	})
+	command.AddCommand(&cobra.Command{
+		Use:   "path",
+		Short: "Print the path to the configuration file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := ConfigPath()
+			if err != nil {
+				return err
+			}
+			fmt.Println(path)
+			return nil
+		},
+	})
+	app.AddCommand(command)
+}
+
+// envVar returns the name of the environment variable that overrides the given setting.
+func envVar(setting string) string {
+	return "{{ .EnvPrefix }}" + strings.ToUpper(strings.Replace(setting, "-", "_", -1))
+}
+
+// checkSetting returns an error if name is not the name of a setting.
+func checkSetting(name string) error {
+	for _, s := range Settings {
+		if s == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown setting %q, valid settings are %s", name, strings.Join(Settings, ", "))
+}
+
+// sortedKeys returns the keys of the given map in alphabetical order.
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch v := m.(type) {
+	case map[string]map[string]string:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]string:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+`
@@ -55,7 +55,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates a dummy app", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(8))
+			Ω(files).Should(HaveLen(9))
 			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "testapi-cli", "main.go"))
 			content := string(c)
 			Ω(err).ShouldNot(HaveOccurred())
@@ -64,6 +64,18 @@ var _ = Describe("Generate", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 		})
 
+		It("applies the configuration before setting up the client", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "testapi-cli", "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(c)).Should(ContainSubstring("if err := cli.ApplyConfig(app); err != nil {"))
+			Ω(string(c)).Should(ContainSubstring("cli.RegisterConfigCommands(app)"))
+			c, err = ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "config.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(c)).Should(ContainSubstring(`var Settings = []string{"scheme", "host", "timeout", "retries"}`))
+			Ω(string(c)).Should(ContainSubstring(`return filepath.Join(dir, "testapi-cli", "config.json"), nil`))
+		})
+
 		Context("generated commands.go", func() {
 			var commandHeader string
 
@@ -362,7 +374,7 @@ var _ = Describe("Generate", func() {
 
 			It("properly escapes the multi-line string used in the short description", func() {
 				Ω(genErr).Should(BeNil())
-				Ω(files).Should(HaveLen(9))
+				Ω(files).Should(HaveLen(10))
 				c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
 				content := string(c)
 				Ω(err).ShouldNot(HaveOccurred())
@@ -380,7 +392,7 @@ var _ = Describe("Generate", func() {
 
 			It("properly escapes the multi-line string used in the short description", func() {
 				Ω(genErr).Should(BeNil())
-				Ω(files).Should(HaveLen(9))
+				Ω(files).Should(HaveLen(10))
 				c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
 				content := string(c)
 				Ω(err).ShouldNot(HaveOccurred())
@@ -427,7 +439,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates direct access to Command field when resolving path", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(10))
 			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
 			content := string(c)
 			Ω(err).ShouldNot(HaveOccurred())
@@ -484,7 +496,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates registers the signer flags from main", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(10))
 			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "testapi-cli", "main.go"))
 			content := string(c)
 			Ω(err).ShouldNot(HaveOccurred())
@@ -492,4 +504,139 @@ var _ = Describe("Generate", func() {
 			Ω(content).Should(ContainSubstring("c.SetJWT1Signer(jwt1Signer)"))
 		})
 	})
+
+	Context("with an action with enum parameters", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.Design = &design.APIDefinition{
+				Name:        "testapi",
+				Title:       "dummy API with no resource",
+				Description: "I told you it's dummy",
+				Consumes:    design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name: "list",
+								QueryParams: &design.AttributeDefinition{
+									Type: design.Object{
+										"color": &design.AttributeDefinition{
+											Type:       design.String,
+											Validation: &dslengine.ValidationDefinition{Values: []interface{}{"red", "white"}},
+										},
+										"years": &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{
+											Type:       design.Integer,
+											Validation: &dslengine.ValidationDefinition{Values: []interface{}{2019, 2020}},
+										}}},
+										"name": &design.AttributeDefinition{Type: design.String},
+									},
+								},
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			listAct := fooRes.Actions["list"]
+			listAct.Parent = fooRes
+			listAct.Routes[0].Parent = listAct
+		})
+
+		It("registers the completion of the enum flag values", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
+			content := string(c)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`cc.RegisterFlagCompletionFunc("color", enumCompletion("red", "white"))`))
+			Ω(content).Should(ContainSubstring(`cc.RegisterFlagCompletionFunc("years", enumCompletion("2019", "2020"))`))
+			Ω(content).ShouldNot(ContainSubstring(`RegisterFlagCompletionFunc("name"`))
+			Ω(content).Should(ContainSubstring("func RegisterCompletionCommand(app *cobra.Command) {"))
+			Ω(content).Should(ContainSubstring("source <(testapi-cli completion zsh)"))
+			c, err = ioutil.ReadFile(filepath.Join(outDir, "tool", "testapi-cli", "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(c)).Should(ContainSubstring("cli.RegisterCompletionCommand(app)"))
+		})
+	})
+
+	Context("with an action with a media type response", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+			bottle := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{
+							"id":      &design.AttributeDefinition{Type: design.Integer},
+							"name":    &design.AttributeDefinition{Type: design.String},
+							"tags":    &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}},
+							"vintage": &design.AttributeDefinition{Type: design.Integer},
+						},
+					},
+					TypeName: "Bottle",
+				},
+				Identifier: "application/vnd.bottle+json",
+			}
+			bottle.Views = map[string]*design.ViewDefinition{
+				"default": {
+					Name: "default",
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{
+							"id":   &design.AttributeDefinition{Type: design.Integer},
+							"name": &design.AttributeDefinition{Type: design.String},
+							"tags": &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}},
+						},
+					},
+					Parent: bottle,
+				},
+			}
+			design.Design = &design.APIDefinition{
+				Name:        "testapi",
+				Title:       "dummy API with no resource",
+				Description: "I told you it's dummy",
+				Consumes:    design.DefaultEncoders,
+				MediaTypes:  map[string]*design.MediaTypeDefinition{bottle.Identifier: bottle},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name: "show",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+								Responses: map[string]*design.ResponseDefinition{
+									"OK": {Name: "OK", Status: 200, MediaType: bottle.Identifier},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("prints the response according to the output flags", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
+			content := string(c)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`"output", "o", "json", "Output format, one of json, yaml or table")`))
+			Ω(content).Should(ContainSubstring(`.Query, "query", "",`))
+			Ω(content).Should(ContainSubstring("goaclient.HandleResponseOutput(c.Client, resp, &goaclient.Output{"))
+			Ω(content).Should(ContainSubstring(`Columns: []string{"id", "name"},`))
+		})
+	})
 })
@@ -6,9 +6,14 @@ service.
 The generated code includes a client package with:
 
     * One client method per resource action
+    * Methods that receive the typed messages of the websocket actions and the events of the
+      actions that stream server-sent events, the streams reconnect following the client
+      Reconnect policy
     * Helper functions to build the corresponding request paths
     * Structs for the action payloads and dependent types
     * Structs for the action media types and corresponding decoder functions
+    * One interface per resource listing the resource client methods
+    * Mock implementations of the resource interfaces when the --mock flag is set
 
 The generated code also includes a CLI tool with commands for each action and sub-commands for
 each resource.
@@ -20,7 +20,10 @@ import (
 // Filename used to generate all data types (without the ".go" extension)
 const typesFileName = "datatypes"
 
-//NewGenerator returns an initialized instance of a Go Client Generator
+// eventStreamMediaType is the media type of the responses that stream server-sent events.
+const eventStreamMediaType = "text/event-stream"
+
+// NewGenerator returns an initialized instance of a Go Client Generator
 func NewGenerator(options ...Option) *Generator {
 	g := &Generator{}
 
@@ -39,6 +42,9 @@ type Generator struct {
 	ToolDirName    string                // Name of tool directory where CLI main is generated once
 	Tool           string                // Name of CLI tool
 	NoTool         bool                  // Whether to skip tool generation
+	Enums          bool                  // Whether to generate named Go types for the enum attributes
+	ValidateTags   bool                  // Whether to add go-playground/validator struct tags to the types
+	Mock           bool                  // Whether to generate mock implementations of the resource clients
 	genfiles       []string
 	encoders       []*genapp.EncoderTemplateData
 	decoders       []*genapp.EncoderTemplateData
@@ -49,7 +55,8 @@ type Generator struct {
 func Generate() (files []string, err error) {
 	var (
 		outDir, target, toolDir, tool, ver string
-		notool, regen                      bool
+		notool, regen, enums, validateTags bool
+		mock                               bool
 	)
 	dtool := defaultToolName(design.Design)
 
@@ -61,6 +68,10 @@ func Generate() (files []string, err error) {
 	set.StringVar(&ver, "version", "", "")
 	set.BoolVar(&notool, "notool", false, "")
 	set.BoolVar(&regen, "regen", false, "")
+	set.BoolVar(&enums, "enums", false, "")
+	set.BoolVar(&validateTags, "validate-tags", false, "")
+	set.BoolVar(&mock, "mock", false, "")
+	set.String("language", "go", "")
 	set.String("design", "", "")
 	set.Bool("force", false, "")
 	set.Bool("notest", false, "")
@@ -73,7 +84,7 @@ func Generate() (files []string, err error) {
 
 	// Now proceed
 	target = codegen.Goify(target, false)
-	g := &Generator{OutDir: outDir, Target: target, ToolDirName: toolDir, Tool: tool, NoTool: notool, API: design.Design}
+	g := &Generator{OutDir: outDir, Target: target, ToolDirName: toolDir, Tool: tool, NoTool: notool, Enums: enums, ValidateTags: validateTags, Mock: mock, API: design.Design}
 
 	return g.Generate()
 }
@@ -106,6 +117,8 @@ func (g *Generator) Generate() (_ []string, err error) {
 	g.Tool = firstNonEmpty(g.Tool, defaultToolName(g.API))
 
 	codegen.Reserved[g.Target] = true
+	codegen.ValidateTags = g.ValidateTags
+	defer func() { codegen.ValidateTags = false }()
 
 	// Setup output directories as needed
 	var pkgDir, toolDir, cliDir string
@@ -119,7 +132,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 			}
 
 			cliDir = filepath.Join(g.OutDir, g.ToolDirName, "cli")
-			if err = os.RemoveAll(cliDir); err != nil {
+			if err = codegen.RemoveAll(cliDir); err != nil {
 				return
 			}
 			if err = os.MkdirAll(cliDir, 0755); err != nil {
@@ -128,7 +141,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 		}
 
 		pkgDir = filepath.Join(g.OutDir, g.Target)
-		if err = os.RemoveAll(pkgDir); err != nil {
+		if err = codegen.RemoveAll(pkgDir); err != nil {
 			return
 		}
 		if err = os.MkdirAll(pkgDir, 0755); err != nil {
@@ -169,10 +182,20 @@ func (g *Generator) Generate() (_ []string, err error) {
 		if err != nil {
 			return
 		}
-		arrayToStringTmpl = template.Must(template.New("client").Funcs(funcs).Parse(arrayToStringT))
+		arrayToStringTmpl = template.Must(template.New("client").Funcs(funcs).Parse(codegen.Template("client", "array_to_string", arrayToStringT)))
+	}
+
+	// Setup the enum types, they must be registered before any code is generated
+	var enums []*genapp.EnumTemplateData
+	if g.Enums {
+		enums = genapp.TypedEnums(g.API)
 	}
 
 	if !g.NoTool {
+		// The CLI refers to the enum types of the client package
+		genapp.QualifyEnums(enums, g.Target)
+		defer genapp.QualifyEnums(enums, "")
+
 		var cliPkg string
 		cliPkg, err = codegen.PackagePath(cliDir)
 		if err != nil {
@@ -193,8 +216,15 @@ func (g *Generator) Generate() (_ []string, err error) {
 		if err = g.generateCommands(filepath.Join(cliDir, "commands.go"), clientPkg, funcs); err != nil {
 			return
 		}
+
+		// Generate tool/cli/config.go
+		if err = g.generateConfig(filepath.Join(cliDir, "config.go"), funcs); err != nil {
+			return
+		}
 	}
 
+	genapp.QualifyEnums(enums, "")
+
 	// Generate client/client.go
 	g.genfiles = append(g.genfiles, pkgDir)
 	if err = g.generateClient(filepath.Join(pkgDir, "client.go"), clientPkg, funcs); err != nil {
@@ -206,6 +236,13 @@ func (g *Generator) Generate() (_ []string, err error) {
 		return
 	}
 
+	// Generate client/enums.go
+	if len(enums) > 0 {
+		if err = g.generateEnums(filepath.Join(pkgDir, "enums.go"), enums); err != nil {
+			return
+		}
+	}
+
 	return g.genfiles, nil
 }
 
@@ -224,6 +261,33 @@ func (g *Generator) Cleanup() {
 	g.genfiles = nil
 }
 
+// generateEnums generates the named Go types of the enum attributes.
+func (g *Generator) generateEnums(enumsFile string, enums []*genapp.EnumTemplateData) (err error) {
+	var enumsWr *genapp.EnumsWriter
+	{
+		enumsWr, err = genapp.NewEnumsWriter(enumsFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		enumsWr.Close()
+		if err == nil {
+			err = enumsWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Enum Types", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("strconv"),
+	}
+	if err = enumsWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, enumsFile)
+
+	return enumsWr.Execute(enums)
+}
+
 func (g *Generator) generateClient(clientFile string, clientPkg string, funcs template.FuncMap) (err error) {
 	var file *codegen.SourceFile
 	{
@@ -238,7 +302,7 @@ func (g *Generator) generateClient(clientFile string, clientPkg string, funcs te
 			err = file.FormatCode()
 		}
 	}()
-	clientTmpl := template.Must(template.New("client").Funcs(funcs).Parse(clientTmpl))
+	clientTmpl := template.Must(template.New("client").Funcs(funcs).Parse(codegen.Template("client", "client", clientTmpl)))
 
 	// Compute list of encoders and decoders
 	encoders, err := genapp.BuildEncoders(g.API.Produces, true)
@@ -295,12 +359,20 @@ func (g *Generator) generateClient(clientFile string, clientPkg string, funcs te
 }
 
 func (g *Generator) generateClientResources(pkgDir, clientPkg string, funcs template.FuncMap) error {
+	var clients []*resourceClient
 	err := g.API.IterateResources(func(res *design.ResourceDefinition) error {
-		return g.generateResourceClient(pkgDir, res, funcs)
+		rc, err := g.generateResourceClient(pkgDir, res, funcs)
+		clients = append(clients, rc)
+		return err
 	})
 	if err != nil {
 		return err
 	}
+	if g.Mock {
+		if err := g.generateMocks(filepath.Join(pkgDir, "mocks.go"), clients); err != nil {
+			return err
+		}
+	}
 	if err := g.generateUserTypes(pkgDir); err != nil {
 		return err
 	}
@@ -308,9 +380,10 @@ func (g *Generator) generateClientResources(pkgDir, clientPkg string, funcs temp
 	return g.generateMediaTypes(pkgDir, funcs)
 }
 
-func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDefinition, funcs template.FuncMap) (err error) {
-	payloadTmpl := template.Must(template.New("payload").Funcs(funcs).Parse(payloadTmpl))
-	pathTmpl := template.Must(template.New("pathTemplate").Funcs(funcs).Parse(pathTmpl))
+func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDefinition, funcs template.FuncMap) (rc *resourceClient, err error) {
+	payloadTmpl := template.Must(template.New("payload").Funcs(funcs).Parse(codegen.Template("client", "payload", payloadTmpl)))
+	pathTmpl := template.Must(template.New("pathTemplate").Funcs(funcs).Parse(codegen.Template("client", "path", pathTmpl)))
+	interfaceTmpl := template.Must(template.New("interface").Funcs(funcs).Parse(codegen.Template("client", "interface", interfaceTmpl)))
 
 	resFilename := codegen.SnakeCase(res.Name)
 	if resFilename == typesFileName {
@@ -322,7 +395,7 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 	var file *codegen.SourceFile
 	file, err = codegen.SourceFileFor(filename)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() {
 		file.Close()
@@ -347,26 +420,35 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("context"),
 		codegen.SimpleImport("golang.org/x/net/websocket"),
+		codegen.NewImport("goaclient", "github.com/kyokomi/goa-v1/client"),
 		codegen.NewImport("uuid", "github.com/kyokomi/goa-v1/uuid"),
 	}
+	imports = actionImports(imports, res)
 	title := fmt.Sprintf("%s: %s Resource Client", g.API.Context(), res.Name)
 	if err = file.WriteHeader(title, g.Target, imports); err != nil {
-		return err
+		return nil, err
 	}
 	g.genfiles = append(g.genfiles, filename)
 
+	rc = &resourceClient{Name: codegen.Goify(res.Name, true) + "Client", Resource: res.Name}
 	err = res.IterateFileServers(func(fs *design.FileServerDefinition) error {
-		return g.generateFileServer(file, fs, funcs)
+		m, err := g.generateFileServer(file, fs, funcs)
+		if err == nil {
+			rc.Methods = append(rc.Methods, m)
+		}
+		return err
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	err = res.IterateActions(func(action *design.ActionDefinition) error {
 		if action.Payload != nil {
-			found := false
+			// Payloads that map to existing Go types are not generated
+			tname, _ := codegen.ExternalType(action.Payload.AttributeDefinition)
+			found := tname != ""
 			typeName := action.Payload.TypeName
-			for _, t := range design.Design.Types {
+			for _, t := range g.API.Types {
 				if t.TypeName == typeName {
 					found = true
 					break
@@ -407,16 +489,24 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 				return err
 			}
 		}
-		return g.generateActionClient(action, file, funcs)
+		ms, err := g.generateActionClient(action, file, funcs)
+		if err == nil {
+			rc.Methods = append(rc.Methods, ms...)
+		}
+		return err
 	})
+	if err != nil {
+		return nil, err
+	}
+	err = interfaceTmpl.Execute(file, rc)
 	return
 }
 
-func (g *Generator) generateFileServer(file *codegen.SourceFile, fs *design.FileServerDefinition, funcs template.FuncMap) error {
+func (g *Generator) generateFileServer(file *codegen.SourceFile, fs *design.FileServerDefinition, funcs template.FuncMap) (*clientMethod, error) {
 	var (
 		dir string
 
-		fsTmpl = template.Must(template.New("fileserver").Funcs(funcs).Parse(fsTmpl))
+		fsTmpl = template.Must(template.New("fileserver").Funcs(funcs).Parse(codegen.Template("client", "file_server", fsTmpl)))
 		name   = g.fileServerMethod(fs)
 		wcs    = design.ExtractWildcards(fs.RequestPath)
 		scheme = "http"
@@ -429,8 +519,8 @@ func (g *Generator) generateFileServer(file *codegen.SourceFile, fs *design.File
 			dir = fileElems[len(fileElems)-2]
 		}
 	}
-	if len(design.Design.Schemes) > 0 {
-		scheme = design.Design.Schemes[0]
+	if len(g.API.Schemes) > 0 {
+		scheme = g.API.Schemes[0]
 	}
 	requestDir, _ := path.Split(fs.RequestPath)
 
@@ -451,19 +541,32 @@ func (g *Generator) generateFileServer(file *codegen.SourceFile, fs *design.File
 		RequestDir:      requestDir,
 		CanonicalScheme: scheme,
 	}
-	return fsTmpl.Execute(file, data)
+	m := &clientMethod{
+		Name:    name,
+		Params:  "ctx context.Context, dest string",
+		Args:    "ctx, dest",
+		Results: "(int64, error)",
+		Zero:    "0",
+	}
+	if dir != "" {
+		m.Params = "ctx context.Context, filename, dest string"
+		m.Args = "ctx, filename, dest"
+	}
+	return m, fsTmpl.Execute(file, data)
 }
 
-func (g *Generator) generateActionClient(action *design.ActionDefinition, file *codegen.SourceFile, funcs template.FuncMap) error {
+func (g *Generator) generateActionClient(action *design.ActionDefinition, file *codegen.SourceFile, funcs template.FuncMap) ([]*clientMethod, error) {
 	var (
-		params        []string
-		names         []string
-		queryParams   []*paramData
-		headers       []*paramData
-		signer        string
-		clientsTmpl   = template.Must(template.New("clients").Funcs(funcs).Parse(clientsTmpl))
-		requestsTmpl  = template.Must(template.New("requests").Funcs(funcs).Parse(requestsTmpl))
-		clientsWSTmpl = template.Must(template.New("clientsws").Funcs(funcs).Parse(clientsWSTmpl))
+		params          []string
+		names           []string
+		queryParams     []*paramData
+		headers         []*paramData
+		signer          string
+		clientsTmpl     = template.Must(template.New("clients").Funcs(funcs).Parse(codegen.Template("client", "clients", clientsTmpl)))
+		requestsTmpl    = template.Must(template.New("requests").Funcs(funcs).Parse(codegen.Template("client", "requests", requestsTmpl)))
+		clientsWSTmpl   = template.Must(template.New("clientsws").Funcs(funcs).Parse(codegen.Template("client", "clients_ws", clientsWSTmpl)))
+		wsMessagesTmpl  = template.Must(template.New("wsmessages").Funcs(funcs).Parse(codegen.Template("client", "ws_messages", wsMessagesTmpl)))
+		eventStreamTmpl = template.Must(template.New("eventstream").Funcs(funcs).Parse(codegen.Template("client", "event_stream", eventStreamTmpl)))
 	)
 	if action.Payload != nil {
 		params = append(params, "payload "+codegen.GoTypeRef(action.Payload, action.Payload.AllRequired(), 1, false))
@@ -509,6 +612,7 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Signer             string
 		QueryParams        []*paramData
 		Headers            []*paramData
+		MessageType        string
 	}{
 		Name:               action.Name,
 		ResourceName:       action.Parent.Name,
@@ -517,8 +621,8 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Payload:            action.Payload,
 		PayloadMultipart:   action.PayloadMultipart,
 		HasPayload:         action.Payload != nil,
-		HasMultiContent:    len(design.Design.Consumes) > 1,
-		DefaultContentType: design.Design.Consumes[0].MIMETypes[0],
+		HasMultiContent:    len(g.API.Consumes) > 1,
+		DefaultContentType: g.API.Consumes[0].MIMETypes[0],
 		Params:             strings.Join(params, ", "),
 		ParamNames:         strings.Join(names, ", "),
 		CanonicalScheme:    action.CanonicalScheme(),
@@ -526,13 +630,85 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		QueryParams:        queryParams,
 		Headers:            headers,
 	}
+	m := &clientMethod{
+		Name:    codegen.Goify(action.Name+strings.Title(action.Parent.Name), true),
+		Params:  "ctx context.Context, path string",
+		Args:    "ctx, path",
+		Results: "(*http.Response, error)",
+		Zero:    "nil",
+	}
+	if len(params) > 0 {
+		m.Params += ", " + data.Params
+		m.Args += ", " + data.ParamNames
+	}
 	if action.WebSocket() {
-		return clientsWSTmpl.Execute(file, data)
+		m.Results = "(*websocket.Conn, error)"
+		if err := clientsWSTmpl.Execute(file, data); err != nil {
+			return nil, err
+		}
+		data.MessageType = messageType(g.API, action)
+		return []*clientMethod{m}, wsMessagesTmpl.Execute(file, data)
+	}
+	if data.HasPayload && data.HasMultiContent {
+		m.Params += ", contentType string"
+		m.Args += ", contentType"
 	}
 	if err := clientsTmpl.Execute(file, data); err != nil {
-		return err
+		return nil, err
+	}
+	if err := requestsTmpl.Execute(file, data); err != nil {
+		return nil, err
+	}
+	if !eventStream(action) {
+		return []*clientMethod{m}, nil
 	}
-	return requestsTmpl.Execute(file, data)
+	stream := &clientMethod{
+		Name:    m.Name + "Stream",
+		Params:  m.Params,
+		Args:    m.Args,
+		Results: "(io.ReadCloser, error)",
+		Zero:    "nil",
+	}
+	return []*clientMethod{m, stream}, eventStreamTmpl.Execute(file, data)
+}
+
+// eventStream returns true if the action has a response that streams server-sent events.
+func eventStream(action *design.ActionDefinition) bool {
+	found := false
+	action.IterateResponses(func(r *design.ResponseDefinition) error {
+		if design.CanonicalIdentifier(r.MediaType) == eventStreamMediaType {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
+// messageType returns the Go type of the messages received on the websocket connection of the
+// action, that is the type of the media type of the first response that has one. The messages
+// are raw JSON values if there isn't any.
+func messageType(api *design.APIDefinition, action *design.ActionDefinition) string {
+	typ := "json.RawMessage"
+	action.IterateResponses(func(r *design.ResponseDefinition) error {
+		if typ != "json.RawMessage" || r.MediaType == "" {
+			return nil
+		}
+		mt, ok := api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]
+		if !ok {
+			return nil
+		}
+		view := r.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		projected, _, err := mt.Project(view)
+		if err != nil {
+			return nil
+		}
+		typ = decodeGoTypeRef(projected, projected.AllRequired(), 0, false)
+		return nil
+	})
+	return typ
 }
 
 // fileServerMethod returns the name of the client method for downloading assets served by the given
@@ -570,7 +746,7 @@ func (g *Generator) fileServerMethod(fs *design.FileServerDefinition) string {
 func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) (err error) {
 	funcs["decodegotyperef"] = decodeGoTypeRef
 	funcs["decodegotypename"] = decodeGoTypeName
-	typeDecodeTmpl := template.Must(template.New("typeDecode").Funcs(funcs).Parse(typeDecodeTmpl))
+	typeDecodeTmpl := template.Must(template.New("typeDecode").Funcs(funcs).Parse(codegen.Template("client", "type_decode", typeDecodeTmpl)))
 	var (
 		mtFile string
 		mtWr   *genapp.MediaTypesWriter
@@ -591,15 +767,17 @@ func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) (e
 	title := fmt.Sprintf("%s: Application Media Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("github.com/kyokomi/goa-v1"),
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
 		codegen.NewImport("uuid", "github.com/kyokomi/goa-v1/uuid"),
 	}
-	for _, v := range g.API.MediaTypes {
-		imports = codegen.AttributeImports(v.AttributeDefinition, imports, nil)
-	}
+	g.API.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		imports = codegen.AttributeImports(mt.AttributeDefinition, imports, nil)
+		return nil
+	})
 	if err = mtWr.WriteHeader(title, g.Target, imports); err != nil {
 		return err
 	}
@@ -645,19 +823,25 @@ func (g *Generator) generateUserTypes(pkgDir string) (err error) {
 	title := fmt.Sprintf("%s: Application User Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("github.com/kyokomi/goa-v1"),
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
 		codegen.NewImport("uuid", "github.com/kyokomi/goa-v1/uuid"),
 	}
-	for _, v := range g.API.Types {
-		imports = codegen.AttributeImports(v.AttributeDefinition, imports, nil)
-	}
+	g.API.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		imports = codegen.AttributeImports(ut.AttributeDefinition, imports, nil)
+		return nil
+	})
 	if err = utWr.WriteHeader(title, g.Target, imports); err != nil {
 		return err
 	}
 	g.genfiles = append(g.genfiles, utFile)
 	err = g.API.IterateUserTypes(func(t *design.UserTypeDefinition) error {
+		if tname, _ := codegen.ExternalType(t.AttributeDefinition); tname != "" {
+			// The design maps the type to an existing Go type
+			return nil
+		}
 		o := t.Type.ToObject()
 		for _, att := range o {
 			if att.Type.Kind() == design.FileKind {
@@ -669,6 +853,45 @@ func (g *Generator) generateUserTypes(pkgDir string) (err error) {
 	return
 }
 
+// generateMocks generates the mock implementations of the resource client interfaces.
+func (g *Generator) generateMocks(mocksFile string, clients []*resourceClient) (err error) {
+	var file *codegen.SourceFile
+	file, err = codegen.SourceFileFor(mocksFile)
+	if err != nil {
+		return
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	mocksTmpl := template.Must(template.New("mocks").Parse(codegen.Template("client", "mocks", mocksTmpl)))
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("bytes"),
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("io"),
+		codegen.SimpleImport("io/ioutil"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("time"),
+		codegen.SimpleImport("golang.org/x/net/websocket"),
+		codegen.NewImport("uuid", "github.com/kyokomi/goa-v1/uuid"),
+	}
+	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		imports = actionImports(imports, res)
+		return nil
+	})
+	title := fmt.Sprintf("%s: Client Mocks", g.API.Context())
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return
+	}
+	g.genfiles = append(g.genfiles, mocksFile)
+	err = mocksTmpl.Execute(file, clients)
+	return
+}
+
 // join is a code generation helper function that generates a function signature built from
 // concatenating the properties (name type) of the given attribute type (assuming it's an object).
 // join accepts an optional slice of strings which indicates the order in which the parameters
@@ -721,6 +944,12 @@ func multiComment(text string) string {
 // gotTypeRefExt computes the type reference for a type in a different package.
 func goTypeRefExt(t design.DataType, tabs int, pkg string) string {
 	ref := codegen.GoTypeRef(t, nil, tabs, false)
+	if ut, ok := t.(*design.UserTypeDefinition); ok {
+		if tname, _ := codegen.ExternalType(ut.AttributeDefinition); tname != "" {
+			// Existing Go types are already qualified with their package
+			return strings.TrimPrefix(ref, "*")
+		}
+	}
 	if strings.HasPrefix(ref, "*") {
 		return fmt.Sprintf("%s.%s", pkg, ref[1:])
 	}
@@ -745,6 +974,26 @@ func decodeGoTypeName(t design.DataType, required []string, tabs int, private bo
 	return codegen.GoTypeName(t, required, tabs, private)
 }
 
+// actionImports adds the imports needed by the types of the parameters, headers and payloads of
+// the actions of the given resources.
+func actionImports(imports []*codegen.ImportSpec, resources ...*design.ResourceDefinition) []*codegen.ImportSpec {
+	for _, res := range resources {
+		res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Params != nil {
+				imports = codegen.AttributeImports(a.Params, imports, nil)
+			}
+			if a.Headers != nil {
+				imports = codegen.AttributeImports(a.Headers, imports, nil)
+			}
+			if a.Payload != nil {
+				imports = codegen.AttributeImports(a.Payload.AttributeDefinition, imports, nil)
+			}
+			return nil
+		})
+	}
+	return imports
+}
+
 // cmdFieldType computes the Go type name used to store command flags of the given design type.
 func cmdFieldType(t design.DataType, point bool) string {
 	var pointer, suffix string
@@ -761,9 +1010,9 @@ func cmdFieldTypeString(t design.DataType, point bool) string {
 	if point && !t.IsArray() {
 		pointer = "*"
 	}
-	if t.Kind() == design.UUIDKind || t.Kind() == design.DateTimeKind || t.Kind() == design.AnyKind || t.Kind() == design.NumberKind || t.Kind() == design.BooleanKind {
+	if t.Kind() == design.UUIDKind || t.Kind() == design.DateTimeKind || t.Kind() == design.AnyKind || t.Kind() == design.NumberKind || t.Kind() == design.BooleanKind || design.CustomPrimitive(t) != nil {
 		suffix = "string"
-	} else if isArrayOfType(t, design.UUIDKind, design.DateTimeKind, design.AnyKind, design.NumberKind, design.BooleanKind) {
+	} else if isArrayOfType(t, design.UUIDKind, design.DateTimeKind, design.AnyKind, design.NumberKind, design.BooleanKind) || (t.IsArray() && design.CustomPrimitive(t.ToArray().ElemType.Type) != nil) {
 		suffix = "[]string"
 	} else {
 		suffix = codegen.GoNativeType(t)
@@ -799,7 +1048,7 @@ func toString(name, target string, att *design.AttributeDefinition) string {
 			return fmt.Sprintf("%s := strconv.FormatBool(%s)", target, name)
 		case design.NumberKind:
 			return fmt.Sprintf("%s := strconv.FormatFloat(%s, 'f', -1, 64)", target, name)
-		case design.StringKind:
+		case design.StringKind, design.DecimalKind:
 			return fmt.Sprintf("%s := %s", target, name)
 		case design.DateTimeKind:
 			return fmt.Sprintf("%s := %s.Format(time.RFC3339)", target, strings.Replace(name, "*", "", -1)) // remove pointer if present
@@ -810,6 +1059,9 @@ func toString(name, target string, att *design.AttributeDefinition) string {
 		case design.FileKind:
 			return fmt.Sprintf("%s := fmt.Sprintf(\"%%v\", %s)", target, name)
 		default:
+			if design.CustomPrimitive(actual) != nil {
+				return fmt.Sprintf("%s := %s", target, codegen.PrimitiveFormatter(actual, name))
+			}
 			panic("unknown primitive type")
 		}
 	case *design.Array:
@@ -893,7 +1145,7 @@ func initParams(att *design.AttributeDefinition) ([]*paramData, []*paramData) {
 	obj := att.Type.ToObject()
 	var reqParamData []*paramData
 	var optParamData []*paramData
-	for n, q := range obj {
+	obj.IterateAttributes(func(n string, q *design.AttributeDefinition) error {
 		varName := codegen.Goify(n, false)
 		param := &paramData{
 			Name:      n,
@@ -901,7 +1153,7 @@ func initParams(att *design.AttributeDefinition) ([]*paramData, []*paramData) {
 			Attribute: q,
 		}
 		if q.Type.IsPrimitive() {
-			param.MustToString = q.Type.Kind() != design.StringKind
+			param.MustToString = q.Type.Kind() != design.StringKind && q.Type.Kind() != design.DecimalKind
 			param.ValueName = toValueTypeName(varName, n, att)
 			if att.IsRequired(n) {
 				reqParamData = append(reqParamData, param)
@@ -923,7 +1175,8 @@ func initParams(att *design.AttributeDefinition) ([]*paramData, []*paramData) {
 				optParamData = append(optParamData, param)
 			}
 		}
-	}
+		return nil
+	})
 
 	return reqParamData, optParamData
 }
@@ -941,6 +1194,23 @@ type paramData struct {
 	CheckNil      bool
 }
 
+// resourceClient is the data structure holding the information needed to generate the client
+// interface of a resource and its mock implementation.
+type resourceClient struct {
+	Name     string          // Name of the interface
+	Resource string          // Name of the resource
+	Methods  []*clientMethod // Methods of the interface
+}
+
+// clientMethod describes a method of a resource client interface.
+type clientMethod struct {
+	Name    string // Method name
+	Params  string // Method parameters
+	Args    string // Method parameter names used to forward the call
+	Results string // Method results
+	Zero    string // Zero value of the first result
+}
+
 type byParamName []*paramData
 
 func (b byParamName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
@@ -981,11 +1251,9 @@ func {{ $funcName }}({{ pathParams .Route }}) string {
 */}}{{ if $desc }}{{ multiComment $desc }}{{ else }}{{/*
 */}}// {{ $funcName }} makes a request to the {{ .Name }} action endpoint of the {{ .ResourceName }} resource{{ end }}
 func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType string{{ end }}) (*http.Response, error) {
-	req, err := c.New{{ $funcName }}Request(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType{{ end }})
-	if err != nil {
-		return nil, err
-	}
-	return c.Client.Do(ctx, req)
+	return c.Client.DoWithRetry(ctx, func() (*http.Request, error) {
+		return c.New{{ $funcName }}Request(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType{{ end }})
+	})
 }
 `
 
@@ -1026,6 +1294,65 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	cfg.Header["{{ $header.Name }}"] = []string{ {{ $tmp }} }
 {{ end }}	return websocket.DialConfig(cfg)
 }
+`
+
+	wsMessagesTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{/*
+*/}}// {{ $funcName }}Messages receives the messages sent on the websocket connection of the {{ .Name }} action
+// endpoint of the {{ .ResourceName }} resource. The connection is re-established according to the
+// client Reconnect policy. The messages channel is closed when the stream ends, the errors channel
+// then receives the error that ended it if any.
+func (c *Client) {{ $funcName }}Messages(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}) (<-chan {{ .MessageType }}, <-chan error) {
+	msgs := make(chan {{ .MessageType }})
+	errs := make(chan error, 1)
+	go func() {
+		defer close(msgs)
+		defer close(errs)
+		err := c.Client.Stream(ctx, func() (io.ReadCloser, error) {
+			ws, err := c.{{ $funcName }}(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }})
+			if err != nil {
+				return nil, err
+			}
+			return ws, nil
+		}, func(conn io.ReadCloser) error {
+			ws := conn.(*websocket.Conn)
+			for {
+				var msg {{ .MessageType }}
+				if err := websocket.JSON.Receive(ws, &msg); err != nil {
+					return err
+				}
+				select {
+				case msgs <- msg:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		})
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return msgs, errs
+}
+`
+
+	eventStreamTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{/*
+*/}}// {{ $funcName }}Stream opens the event stream of the {{ .Name }} action endpoint of the {{ .ResourceName }} resource
+// and returns the response body, it returns an error if the response status is not 200 (OK).
+func (c *Client) {{ $funcName }}Stream(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType string{{ end }}) (io.ReadCloser, error) {
+	return c.Client.OpenEventStream(ctx, func() (*http.Request, error) {
+		return c.New{{ $funcName }}Request(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType{{ end }})
+	})
+}
+
+// {{ $funcName }}Events reads the server-sent events of the {{ .Name }} action endpoint of the
+// {{ .ResourceName }} resource. The stream is reconnected according to the client Reconnect policy
+// and resumed from the last event received. The events channel is closed when the stream ends, the
+// errors channel then receives the error that ended it if any.
+func (c *Client) {{ $funcName }}Events(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType string{{ end }}) (<-chan *goaclient.Event, <-chan error) {
+	return c.Client.EventStream(ctx, func() (*http.Request, error) {
+		return c.New{{ $funcName }}Request(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType{{ end }})
+	})
+}
 `
 
 	fsTmpl = `// {{ .Name }} downloads {{ if .DirName }}{{ .DirName }}files with the given filename{{ else }}{{ .FileName }}{{ end }} and writes it to the file dest.
@@ -1037,11 +1364,9 @@ func (c * Client) {{ .Name }}(ctx context.Context, {{ if .DirName }}filename, {{
 	}
 {{ if .DirName }}	p := path.Join("{{ .RequestDir }}", filename)
 {{ end }}	u := url.URL{Host: c.Host, Scheme: scheme, Path: {{ if .DirName }}p{{ else }}"{{ .RequestPath }}"{{ end }}}
-	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
-	if err != nil {
-		return 0, err
-	}
-	resp, err := c.Client.Do(ctx, req)
+	resp, err := c.Client.DoWithRetry(ctx, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	})
 	if err != nil {
 		return 0, err
 	}
@@ -1173,6 +1498,57 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 }
 `
 
+	interfaceTmpl = `{{ if .Methods }}// {{ .Name }} is the interface implemented by Client for the {{ .Resource }} resource actions, code
+// that depends on {{ .Name }} rather than Client can be tested without making HTTP requests.
+type {{ .Name }} interface {
+{{ range .Methods }}	{{ .Name }}({{ .Params }}) {{ .Results }}
+{{ end }}}
+
+var _ {{ .Name }} = (*Client)(nil)
+{{ end }}`
+
+	mocksTmpl = `// NewMockResponse returns a response with the given status code and the JSON encoding of body, the
+// response body is empty if body is nil. The mock functions may use it to return programmed
+// responses that the decoding methods of Client can decode.
+func NewMockResponse(status int, body interface{}) (*http.Response, error) {
+	var b []byte
+	header := make(http.Header)
+	if body != nil {
+		var err error
+		if b, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+		header.Set("Content-Type", "application/json")
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(b)),
+		ContentLength: int64(len(b)),
+	}, nil
+}
+{{ range . }}{{ if .Methods }}{{ $client := .Name }}
+// Mock{{ $client }} is a mock implementation of {{ $client }}. Each method calls the function
+// field with the same name suffixed with "Func", methods whose function is not set return an error.
+type Mock{{ $client }} struct {
+{{ range .Methods }}	{{ .Name }}Func func({{ .Params }}) {{ .Results }}
+{{ end }}}
+{{ range .Methods }}
+// {{ .Name }} calls {{ .Name }}Func.
+func (m *Mock{{ $client }}) {{ .Name }}({{ .Params }}) {{ .Results }} {
+	if m.{{ .Name }}Func == nil {
+		return {{ .Zero }}, fmt.Errorf("Mock{{ $client }}: {{ .Name }} is not implemented")
+	}
+	return m.{{ .Name }}Func({{ .Args }})
+}
+{{ end }}
+var _ {{ $client }} = (*Mock{{ $client }})(nil)
+{{ end }}{{ end }}`
+
 	clientTmpl = `// Client is the {{ .API.Name }} service client.
 type Client struct {
 	*goaclient.Client{{range $security := .API.SecuritySchemes }}{{ $signer := signerType $security }}{{ if $signer }}
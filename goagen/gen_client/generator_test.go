@@ -167,7 +167,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates header initialization code that compiles", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(10))
 			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			content := string(c)
@@ -213,7 +213,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates path initialization code that uses all defined URL params in proper format", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(10))
 			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			content := string(c)
@@ -269,7 +269,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates param initialization code that uses the param name given in the design", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(10))
 			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			content := string(c)
@@ -296,7 +296,7 @@ var _ = Describe("Generate", func() {
 
 			It("should not return an error", func() {
 				Ω(genErr).Should(BeNil())
-				Ω(files).Should(HaveLen(5)) // 9, minus 4 entries for tool paths
+				Ω(files).Should(HaveLen(5)) // 10, minus 5 entries for tool paths
 			})
 		})
 	})
@@ -340,7 +340,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates param initialization code that uses the param name given in the design", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(10))
 			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			content := string(c)
@@ -366,6 +366,17 @@ var _ = Describe("Generate", func() {
 `))
 		})
 
+		It("generates the typed message stream method", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (c *Client) ShowFooMessages(ctx context.Context, path string, "))
+			Ω(content).Should(ContainSubstring(") (<-chan json.RawMessage, <-chan error) {"))
+			Ω(content).Should(ContainSubstring("err := c.Client.Stream(ctx, func() (io.ReadCloser, error) {"))
+			Ω(content).Should(ContainSubstring("websocket.JSON.Receive(ws, &msg)"))
+		})
+
 		Context("with --notool", func() {
 			BeforeEach(func() {
 				os.Args = append(os.Args, "--notool")
@@ -373,11 +384,66 @@ var _ = Describe("Generate", func() {
 
 			It("should not return an error", func() {
 				Ω(genErr).Should(BeNil())
-				Ω(files).Should(HaveLen(5)) // 9, minus 4 entries for tool paths
+				Ω(files).Should(HaveLen(5)) // 10, minus 5 entries for tool paths
 			})
 		})
 	})
 
+	Context("with an action streaming server-sent events", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"watch": {
+								Name: "watch",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "/watch",
+									},
+								},
+								Responses: map[string]*design.ResponseDefinition{
+									"OK": {
+										Name:      "OK",
+										Status:    200,
+										MediaType: "text/event-stream",
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			watchAct := fooRes.Actions["watch"]
+			watchAct.Parent = fooRes
+			watchAct.Routes[0].Parent = watchAct
+		})
+
+		It("generates the event stream methods", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (c *Client) WatchFoo(ctx context.Context, path string) (*http.Response, error) {"))
+			Ω(content).Should(ContainSubstring(`func (c *Client) WatchFooStream(ctx context.Context, path string) (io.ReadCloser, error) {
+	return c.Client.OpenEventStream(ctx, func() (*http.Request, error) {
+		return c.NewWatchFooRequest(ctx, path)
+	})
+}`))
+			Ω(content).Should(ContainSubstring(`func (c *Client) WatchFooEvents(ctx context.Context, path string) (<-chan *goaclient.Event, <-chan error) {
+	return c.Client.EventStream(ctx, func() (*http.Request, error) {
+		return c.NewWatchFooRequest(ctx, path)
+	})
+}`))
+			Ω(content).Should(ContainSubstring("\tWatchFooStream(ctx context.Context, path string) (io.ReadCloser, error)\n"))
+		})
+	})
+
 	Context("with an action with multiple routes", func() {
 		BeforeEach(func() {
 			design.Design = &design.APIDefinition{
@@ -413,7 +479,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates Path function with unique names", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(10))
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(content).Should(ContainSubstring("func ShowFooPath("))
@@ -436,12 +502,37 @@ var _ = Describe("Generate", func() {
 
 			It("generates a Download function", func() {
 				Ω(genErr).Should(BeNil())
-				Ω(files).Should(HaveLen(9))
+				Ω(files).Should(HaveLen(10))
 				content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
 				Ω(err).ShouldNot(HaveOccurred())
 				Ω(content).Should(ContainSubstring("func (c *Client) DownloadSwaggerJSON("))
 			})
 
+			Context("with mocks", func() {
+				BeforeEach(func() {
+					os.Args = append(os.Args, "--mock")
+				})
+
+				It("generates the resource client interface and mock", func() {
+					Ω(genErr).Should(BeNil())
+					Ω(files).Should(HaveLen(11))
+					content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(string(content)).Should(ContainSubstring("type FooClient interface {\n" +
+						"	DownloadSwaggerJSON(ctx context.Context, dest string) (int64, error)\n" +
+						"	ShowFoo(ctx context.Context, path string) (*http.Response, error)\n" +
+						"}\n"))
+					content, err = ioutil.ReadFile(filepath.Join(outDir, "client", "mocks.go"))
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(string(content)).Should(ContainSubstring("func NewMockResponse(status int, body interface{}) (*http.Response, error) {"))
+					Ω(string(content)).Should(ContainSubstring(`func (m *MockFooClient) ShowFoo(ctx context.Context, path string) (*http.Response, error) {
+	if m.ShowFooFunc == nil {
+		return nil, fmt.Errorf("MockFooClient: ShowFoo is not implemented")
+	}
+	return m.ShowFooFunc(ctx, path)
+}`))
+				})
+			})
 		})
 	})
 
@@ -495,7 +586,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates the correct client Fields", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(10))
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(content).Should(ContainSubstring("JWT1Signer goaclient.Signer"))
@@ -504,7 +595,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates the Signer.Sign call from Action", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(10))
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(content).Should(ContainSubstring(`		if err := c.JWT1Signer.Sign(req); err != nil {
@@ -560,7 +651,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates the user type imports", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(10))
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "user_types.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(content).Should(ContainSubstring("uuid \"github.com/kyokomi/goa-v1/uuid\""))
@@ -618,7 +709,7 @@ var _ = Describe("Generate", func() {
 
 		It("treat non-required param as pointer type", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(10))
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(string(content)).Should(ContainSubstring("tmp_Param := *payload.Param"))
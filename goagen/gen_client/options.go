@@ -48,3 +48,24 @@ func NoTool(noTool bool) Option {
 		g.NoTool = noTool
 	}
 }
+
+//Enums Whether to generate named Go types for the enum attributes
+func Enums(enums bool) Option {
+	return func(g *Generator) {
+		g.Enums = enums
+	}
+}
+
+//ValidateTags Whether to add go-playground/validator struct tags to the generated types
+func ValidateTags(validateTags bool) Option {
+	return func(g *Generator) {
+		g.ValidateTags = validateTags
+	}
+}
+
+//Mock Whether to generate mock implementations of the resource clients
+func Mock(mock bool) Option {
+	return func(g *Generator) {
+		g.Mock = mock
+	}
+}
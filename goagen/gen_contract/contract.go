@@ -0,0 +1,607 @@
+package gencontract
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+type (
+	// Suite describes the generated contract test suite.
+	Suite struct {
+		// BaseURL is the default URL of the tested implementation.
+		BaseURL string
+		// Actions lists the tested actions sorted by resource and action name.
+		Actions []*Action
+	}
+
+	// Action describes the test cases of an action.
+	Action struct {
+		// Name is the name of the action prefixed with the name of its resource, e.g.
+		// "bottle#show".
+		Name string
+		// Responses lists the declared responses sorted by status.
+		Responses []*Response
+		// Cases lists the requests sent to the action, the first case is the valid request.
+		Cases []*Case
+	}
+
+	// Response describes a declared response.
+	Response struct {
+		// Status is the HTTP status code of the response.
+		Status int
+		// ContentType is the media type identifier of the response body, empty if the
+		// response body is not a media type.
+		ContentType string
+		// Schema is the JSON representation of the schema of the response body, empty if
+		// the response has no body.
+		Schema string
+	}
+
+	// Case describes a request sent to the tested implementation.
+	Case struct {
+		// Name describes the request, e.g. "query limit above maximum".
+		Name string
+		// Method is the HTTP method of the request.
+		Method string
+		// Path is the request path including the API base path.
+		Path string
+		// Query is the encoded query string.
+		Query string
+		// Headers maps the request header names to their value.
+		Headers map[string]string
+		// Body is the JSON request body, empty if the request has no body.
+		Body string
+		// Status is the expected response status, zero if the response may have any of
+		// the declared statuses.
+		Status int
+	}
+
+	// Schema is the subset of JSON schema used to check the response bodies.
+	Schema struct {
+		Type       string             `json:"type,omitempty"`
+		Required   []string           `json:"required,omitempty"`
+		Properties map[string]*Schema `json:"properties,omitempty"`
+		Items      *Schema            `json:"items,omitempty"`
+		Values     *Schema            `json:"values,omitempty"`
+		Enum       []interface{}      `json:"enum,omitempty"`
+		Minimum    *float64           `json:"minimum,omitempty"`
+		Maximum    *float64           `json:"maximum,omitempty"`
+		MinLength  *int               `json:"minLength,omitempty"`
+		MaxLength  *int               `json:"maxLength,omitempty"`
+		Pattern    string             `json:"pattern,omitempty"`
+	}
+
+	// invalid describes a value that violates the definition of an attribute.
+	invalid struct {
+		reason string
+		value  interface{}
+	}
+
+	// request holds the parts of a request that the cases alter.
+	request struct {
+		path    map[string]string
+		query   map[string][]string
+		headers map[string]string
+		payload interface{}
+	}
+)
+
+// New builds the description of the contract test suite of the API. Each action is tested
+// with a valid request built from the design examples followed by requests that violate the
+// validations of the parameters, headers and payload members one at a time. WebSocket actions
+// and actions with multipart payloads are not tested.
+func New(api *design.APIDefinition) (*Suite, error) {
+	s := &Suite{BaseURL: baseURL(api)}
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() || a.PayloadMultipart || len(a.Routes) == 0 {
+				return nil
+			}
+			ac, err := action(api, res, a)
+			if err != nil {
+				return err
+			}
+			s.Actions = append(s.Actions, ac)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// action builds the description of the test cases of the given action.
+func action(api *design.APIDefinition, res *design.ResourceDefinition, a *design.ActionDefinition) (*Action, error) {
+	ac := &Action{Name: res.Name + "#" + a.Name}
+	err := a.IterateResponses(func(r *design.ResponseDefinition) error {
+		resp, err := response(api, r)
+		if err != nil {
+			return fmt.Errorf("%s: response %#v: %s", a.Context(), r.Name, err)
+		}
+		ac.Responses = append(ac.Responses, resp)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(ac.Responses, func(i, j int) bool { return ac.Responses[i].Status < ac.Responses[j].Status })
+
+	route := a.Routes[0]
+	rand := api.RandomGenerator()
+	valid := &request{
+		path:    make(map[string]string),
+		query:   make(map[string][]string),
+		headers: make(map[string]string),
+	}
+	var pathParams, queryParams, headers design.Object
+	all := a.AllParams().Type.ToObject()
+	pathParams = make(design.Object)
+	for _, n := range route.Params() {
+		at, ok := all[n]
+		if !ok {
+			at = &design.AttributeDefinition{Type: design.String}
+		}
+		pathParams[n] = at
+		valid.path[n] = format(at.GenerateExample(rand, nil))
+	}
+	if a.QueryParams != nil {
+		queryParams = a.QueryParams.Type.ToObject()
+		for _, n := range codegen.SortedNames(queryParams) {
+			if _, ok := pathParams[n]; !ok {
+				valid.query[n] = values(queryParams[n].GenerateExample(rand, nil))
+			}
+		}
+	}
+	if a.Headers != nil {
+		headers = a.Headers.Type.ToObject()
+		for _, n := range codegen.SortedNames(headers) {
+			valid.headers[n] = format(headers[n].GenerateExample(rand, nil))
+		}
+	}
+	if a.Payload != nil {
+		valid.payload = a.Payload.GenerateExample(rand, nil)
+	}
+
+	c, err := valid.newCase("valid", route, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", a.Context(), err)
+	}
+	ac.Cases = append(ac.Cases, c)
+	add := func(name string, req *request) error {
+		c, err := req.newCase(name, route, 400)
+		if err != nil {
+			return fmt.Errorf("%s: %s", a.Context(), err)
+		}
+		ac.Cases = append(ac.Cases, c)
+		return nil
+	}
+
+	for _, n := range codegen.SortedNames(pathParams) {
+		for _, inv := range invalidValues(pathParams[n], false) {
+			v := format(inv.value)
+			if v == "" || strings.Contains(v, "/") {
+				continue
+			}
+			req := valid.dup()
+			req.path[n] = v
+			if err := add(fmt.Sprintf("path %s %s", n, inv.reason), req); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, n := range codegen.SortedNames(queryParams) {
+		if _, ok := pathParams[n]; ok {
+			continue
+		}
+		if a.QueryParams.IsRequired(n) {
+			req := valid.dup()
+			delete(req.query, n)
+			if err := add(fmt.Sprintf("query %s missing", n), req); err != nil {
+				return nil, err
+			}
+		}
+		for _, inv := range invalidValues(queryParams[n], false) {
+			req := valid.dup()
+			req.query[n] = values(inv.value)
+			if err := add(fmt.Sprintf("query %s %s", n, inv.reason), req); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, n := range codegen.SortedNames(headers) {
+		if a.Headers.IsRequired(n) {
+			req := valid.dup()
+			delete(req.headers, n)
+			if err := add(fmt.Sprintf("header %s missing", n), req); err != nil {
+				return nil, err
+			}
+		}
+		for _, inv := range invalidValues(headers[n], false) {
+			req := valid.dup()
+			req.headers[n] = format(inv.value)
+			if err := add(fmt.Sprintf("header %s %s", n, inv.reason), req); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if a.Payload != nil {
+		if !a.PayloadOptional {
+			req := valid.dup()
+			req.payload = nil
+			if err := add("payload missing", req); err != nil {
+				return nil, err
+			}
+		}
+		if members, ok := valid.payload.(map[string]interface{}); ok {
+			obj := a.Payload.Type.ToObject()
+			for _, n := range codegen.SortedNames(obj) {
+				if a.Payload.IsRequired(n) {
+					req := valid.dup()
+					req.payload = without(members, n)
+					if err := add(fmt.Sprintf("payload %s missing", n), req); err != nil {
+						return nil, err
+					}
+				}
+				for _, inv := range invalidValues(obj[n], true) {
+					req := valid.dup()
+					m := without(members, n)
+					m[n] = inv.value
+					req.payload = m
+					if err := add(fmt.Sprintf("payload %s %s", n, inv.reason), req); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	return ac, nil
+}
+
+// response builds the description of the given response.
+func response(api *design.APIDefinition, r *design.ResponseDefinition) (*Response, error) {
+	resp := &Response{Status: r.Status}
+	var at *design.AttributeDefinition
+	if r.MediaType != "" {
+		mt := api.MediaTypeWithIdentifier(r.MediaType)
+		if mt == nil && design.CanonicalIdentifier(r.MediaType) == design.CanonicalIdentifier(design.ErrorMedia.Identifier) {
+			mt = design.ErrorMedia
+		}
+		if mt == nil {
+			return resp, nil
+		}
+		resp.ContentType = mt.Identifier
+		view := r.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return nil, err
+		}
+		at = p.AttributeDefinition
+	} else if r.Type != nil {
+		at = &design.AttributeDefinition{Type: r.Type}
+	}
+	if at == nil {
+		return resp, nil
+	}
+	b, err := json.Marshal(schema(at, make(map[string]bool)))
+	if err != nil {
+		return nil, err
+	}
+	resp.Schema = string(b)
+	return resp, nil
+}
+
+// schema returns the schema of the given attribute, seen records the user types being
+// described to stop the recursion of recursive types.
+func schema(at *design.AttributeDefinition, seen map[string]bool) *Schema {
+	s := &Schema{}
+	if v := at.Validation; v != nil {
+		s.Enum = v.Values
+		s.Minimum = v.Minimum
+		s.Maximum = v.Maximum
+		s.MinLength = v.MinLength
+		s.MaxLength = v.MaxLength
+		s.Pattern = v.Pattern
+	}
+	required := at.AllRequired()
+	var id string
+	switch t := at.Type.(type) {
+	case *design.UserTypeDefinition:
+		id = t.TypeName
+		if len(required) == 0 {
+			required = t.AllRequired()
+		}
+	case *design.MediaTypeDefinition:
+		id = t.Identifier
+		if len(required) == 0 {
+			required = t.AllRequired()
+		}
+	}
+	if id != "" {
+		if seen[id] {
+			s.Type = "object"
+			return s
+		}
+		seen[id] = true
+		defer delete(seen, id)
+	}
+	switch kind(at.Type) {
+	case design.BooleanKind:
+		s.Type = "boolean"
+	case design.IntegerKind:
+		s.Type = "integer"
+	case design.NumberKind:
+		s.Type = "number"
+	case design.StringKind, design.DateTimeKind, design.UUIDKind, design.DecimalKind:
+		s.Type = "string"
+	case design.ArrayKind:
+		s.Type = "array"
+		s.Items = schema(at.Type.ToArray().ElemType, seen)
+	case design.HashKind:
+		s.Type = "object"
+		s.Values = schema(at.Type.ToHash().ElemType, seen)
+	case design.ObjectKind:
+		s.Type = "object"
+		s.Required = required
+		if obj := at.Type.ToObject(); len(obj) > 0 {
+			s.Properties = make(map[string]*Schema, len(obj))
+			for n, att := range obj {
+				s.Properties[n] = schema(att, seen)
+			}
+		}
+	}
+	return s
+}
+
+// invalidValues returns values that violate the definition of the given attribute, body
+// indicates whether the values are rendered in a JSON body or in the request URL and headers.
+func invalidValues(at *design.AttributeDefinition, body bool) []*invalid {
+	var res []*invalid
+	kind := kind(at.Type)
+	switch kind {
+	case design.IntegerKind, design.NumberKind, design.BooleanKind:
+		res = append(res, &invalid{"not a " + at.Type.Name(), "invalid"})
+	case design.StringKind:
+		if body {
+			res = append(res, &invalid{"not a string", 42})
+		}
+	}
+	v := at.Validation
+	if v == nil {
+		return res
+	}
+	if len(v.Values) > 0 {
+		if val, ok := notIn(v.Values, kind); ok {
+			res = append(res, &invalid{"not in enum", val})
+		}
+	}
+	if v.Minimum != nil {
+		if kind == design.IntegerKind {
+			res = append(res, &invalid{"below minimum", int(math.Ceil(*v.Minimum)) - 1})
+		} else if kind == design.NumberKind {
+			res = append(res, &invalid{"below minimum", *v.Minimum - 1})
+		}
+	}
+	if v.Maximum != nil {
+		if kind == design.IntegerKind {
+			res = append(res, &invalid{"above maximum", int(math.Floor(*v.Maximum)) + 1})
+		} else if kind == design.NumberKind {
+			res = append(res, &invalid{"above maximum", *v.Maximum + 1})
+		}
+	}
+	if v.MinLength != nil && *v.MinLength > 0 {
+		if val, ok := ofLength(at, *v.MinLength-1); ok {
+			res = append(res, &invalid{"shorter than minimum length", val})
+		}
+	}
+	if v.MaxLength != nil {
+		if val, ok := ofLength(at, *v.MaxLength+1); ok {
+			res = append(res, &invalid{"longer than maximum length", val})
+		}
+	}
+	if v.Pattern != "" && kind == design.StringKind {
+		if re, err := regexp.Compile(v.Pattern); err == nil {
+			for _, val := range []string{"", "!", "~ ~"} {
+				if !re.MatchString(val) {
+					res = append(res, &invalid{"not matching pattern", val})
+					break
+				}
+			}
+		}
+	}
+	return res
+}
+
+// notIn returns a value of the given kind that is not one of the given enum values.
+func notIn(vals []interface{}, kind design.Kind) (interface{}, bool) {
+	switch kind {
+	case design.StringKind:
+		val := "invalid-enum-value"
+		for contains(vals, val) {
+			val += "-"
+		}
+		return val, true
+	case design.IntegerKind, design.NumberKind:
+		max := math.Inf(-1)
+		for _, v := range vals {
+			if f, ok := toFloat(v); ok && f > max {
+				max = f
+			}
+		}
+		if math.IsInf(max, -1) {
+			return nil, false
+		}
+		if kind == design.IntegerKind {
+			return int(math.Floor(max)) + 1, true
+		}
+		return max + 1, true
+	}
+	return nil, false
+}
+
+// ofLength returns a string or an array of the given length compatible with the type of the
+// attribute.
+func ofLength(at *design.AttributeDefinition, n int) (interface{}, bool) {
+	switch kind(at.Type) {
+	case design.StringKind:
+		return strings.Repeat("a", n), true
+	case design.ArrayKind:
+		elem := at.Type.ToArray().ElemType
+		rand := design.NewRandomGenerator("length")
+		arr := make([]interface{}, n)
+		for i := range arr {
+			arr[i] = elem.GenerateExample(rand, nil)
+		}
+		return arr, true
+	}
+	return nil, false
+}
+
+// newCase builds the test case that sends the request.
+func (r *request) newCase(name string, route *design.RouteDefinition, status int) (*Case, error) {
+	c := &Case{Name: name, Method: route.Verb, Path: route.FullPath(), Headers: r.headers, Status: status}
+	c.Path = codegen.PathParamRegex.ReplaceAllStringFunc(c.Path, func(p string) string {
+		v := r.path[p[1:]]
+		if p[0] == '*' {
+			return (&url.URL{Path: v}).EscapedPath()
+		}
+		return url.PathEscape(v)
+	})
+	q := make(url.Values)
+	for n, vals := range r.query {
+		q[n] = vals
+	}
+	c.Query = q.Encode()
+	if r.payload != nil {
+		b, err := json.Marshal(r.payload)
+		if err != nil {
+			return nil, fmt.Errorf("payload example: %s", err)
+		}
+		c.Body = string(b)
+	}
+	return c, nil
+}
+
+// dup returns a copy of the request that can be altered without affecting r.
+func (r *request) dup() *request {
+	d := &request{
+		path:    make(map[string]string, len(r.path)),
+		query:   make(map[string][]string, len(r.query)),
+		headers: make(map[string]string, len(r.headers)),
+		payload: r.payload,
+	}
+	for k, v := range r.path {
+		d.path[k] = v
+	}
+	for k, v := range r.query {
+		d.query[k] = v
+	}
+	for k, v := range r.headers {
+		d.headers[k] = v
+	}
+	return d
+}
+
+// baseURL returns the default URL of the API implementation.
+func baseURL(api *design.APIDefinition) string {
+	scheme := "http"
+	if len(api.Schemes) > 0 {
+		scheme = api.Schemes[0]
+		for _, s := range api.Schemes {
+			if s == "http" {
+				scheme = s
+				break
+			}
+		}
+	}
+	host := api.Host
+	if host == "" {
+		host = "localhost:8080"
+	}
+	return scheme + "://" + host
+}
+
+// values returns the query string values of the given example.
+func values(v interface{}) []string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return []string{format(v)}
+	}
+	vals := make([]string, rv.Len())
+	for i := range vals {
+		vals[i] = format(rv.Index(i).Interface())
+	}
+	return vals
+}
+
+// format returns the string representation of the given example value, strings are returned
+// as is and other values as JSON.
+func format(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// without returns a copy of the map without the given key.
+func without(m map[string]interface{}, key string) map[string]interface{} {
+	res := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if k != key {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// contains returns true if vals contains the string s.
+func contains(vals []interface{}, s string) bool {
+	for _, v := range vals {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// toFloat returns the float64 value of the numeric value v.
+func toFloat(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// kind returns the kind of the given data type, user types and media types return the kind of
+// their underlying type.
+func kind(dt design.DataType) design.Kind {
+	switch t := dt.(type) {
+	case *design.UserTypeDefinition:
+		return kind(t.Type)
+	case *design.MediaTypeDefinition:
+		return kind(t.Type)
+	}
+	return dt.Kind()
+}
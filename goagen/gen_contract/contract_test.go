@@ -0,0 +1,146 @@
+package gencontract_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	gencontract "github.com/kyokomi/goa-v1/goagen/gen_contract"
+)
+
+var _ = Describe("New", func() {
+	var suite *gencontract.Suite
+	var newErr error
+
+	BeforeEach(func() {
+		suite = nil
+		newErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		suite, newErr = gencontract.New(Design)
+	})
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer)
+					apidsl.Attribute("name", String, func() {
+						apidsl.MinLength(2)
+					})
+					apidsl.Required("id")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+				})
+			})
+			apidsl.API("cellar", func() {
+				apidsl.Host("cellar.example.com")
+				apidsl.BasePath("/api")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET(""))
+					apidsl.Params(func() {
+						apidsl.Param("limit", Integer, func() {
+							apidsl.Minimum(1)
+							apidsl.Maximum(100)
+							apidsl.Example(10)
+						})
+						apidsl.Param("sort", String, func() {
+							apidsl.Enum("name", "id")
+							apidsl.Example("name")
+						})
+						apidsl.Required("limit")
+					})
+					apidsl.Response(OK, func() {
+						apidsl.Media(apidsl.CollectionOf(bottle))
+					})
+					apidsl.Response(BadRequest, ErrorMedia)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Headers(func() {
+						apidsl.Header("X-Request-Id", String, func() {
+							apidsl.Pattern("^[a-z]+$")
+							apidsl.Example("abc")
+						})
+					})
+					apidsl.Payload(func() {
+						apidsl.Member("name", String, func() {
+							apidsl.MaxLength(3)
+							apidsl.Example("ab")
+						})
+						apidsl.Required("name")
+					})
+					apidsl.Response(Created)
+				})
+			})
+		})
+
+		It("uses the API host as base URL", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(suite.BaseURL).Should(Equal("http://cellar.example.com"))
+			Ω(suite.Actions).Should(HaveLen(2))
+		})
+
+		It("generates the cases of the parameters", func() {
+			list := suite.Actions[1]
+			Ω(list.Name).Should(Equal("bottle#list"))
+			var cases []string
+			for _, c := range list.Cases {
+				cases = append(cases, c.Name+" "+c.Query)
+			}
+			Ω(cases).Should(Equal([]string{
+				"valid limit=10&sort=name",
+				"query limit missing sort=name",
+				"query limit not a integer limit=invalid&sort=name",
+				"query limit below minimum limit=0&sort=name",
+				"query limit above maximum limit=101&sort=name",
+				"query sort not in enum limit=10&sort=invalid-enum-value",
+			}))
+			Ω(list.Cases[0].Path).Should(Equal("/api/bottles"))
+			Ω(list.Cases[0].Status).Should(Equal(0))
+			Ω(list.Cases[1].Status).Should(Equal(400))
+		})
+
+		It("generates the cases of the headers and payload", func() {
+			create := suite.Actions[0]
+			Ω(create.Name).Should(Equal("bottle#create"))
+			var cases []string
+			for _, c := range create.Cases {
+				cases = append(cases, c.Name+" "+c.Headers["X-Request-Id"]+" "+c.Body)
+			}
+			Ω(cases).Should(Equal([]string{
+				`valid abc {"name":"ab"}`,
+				`header X-Request-Id not matching pattern  {"name":"ab"}`,
+				`payload missing abc `,
+				`payload name missing abc {}`,
+				`payload name not a string abc {"name":42}`,
+				`payload name longer than maximum length abc {"name":"aaaa"}`,
+			}))
+		})
+
+		It("describes the schema of the responses", func() {
+			list := suite.Actions[1]
+			Ω(list.Responses).Should(HaveLen(2))
+			Ω(list.Responses[0].Status).Should(Equal(200))
+			Ω(list.Responses[0].ContentType).Should(Equal("application/vnd.bottle+json; type=collection"))
+			Ω(list.Responses[0].Schema).Should(Equal(`{"type":"array","items":{"type":"object","required":["id"],"properties":{"id":{"type":"integer"},"name":{"type":"string","minLength":2}}}}`))
+			Ω(list.Responses[1].ContentType).Should(Equal("application/vnd.goa.error"))
+
+			create := suite.Actions[0]
+			Ω(create.Responses[0].Status).Should(Equal(201))
+			Ω(create.Responses[0].Schema).Should(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,17 @@
+/*
+Package gencontract provides a generator for a contract test suite of the API. The generated
+package contains a single Go test file that depends on the standard library only and exercises a
+running implementation of the API over HTTP.
+
+Each action is sent a valid request built from the design examples followed by requests that
+violate the definition of a parameter, header or payload member: missing required values, values
+of the wrong type, values outside of the minimum, maximum, length or enum validations and values
+that do not match patterns. The valid request must get one of the declared responses while the
+invalid requests must get a 400 response. Response bodies are checked against the content type and
+schema of the matching response definition.
+
+The tests target the URL given via the -contract.url flag which defaults to the API host, e.g.:
+
+	go test ./contract -args -contract.url=http://localhost:8080 -contract.auth="Bearer token"
+*/
+package gencontract
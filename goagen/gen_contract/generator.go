@@ -0,0 +1,414 @@
+package gencontract
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of a Contract Test Suite Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the contract test suite generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Target   string                // Name of generated contract test package, defaults to "contract"
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, target, ver string
+
+	set := flag.NewFlagSet("contract", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&target, "pkg", "contract", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	// First check compatibility
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	// Now proceed
+	g := &Generator{OutDir: outDir, Target: target, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the contract test package.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.Target == "" {
+		g.Target = "contract"
+	}
+	s, err := New(g.API)
+	if err != nil {
+		return nil, err
+	}
+
+	contractDir := filepath.Join(g.OutDir, g.Target)
+	if err = codegen.RemoveAll(contractDir); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(contractDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, contractDir)
+
+	testFile := filepath.Join(contractDir, "contract_test.go")
+	file, err := codegen.SourceFileFor(testFile)
+	if err != nil {
+		return nil, err
+	}
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("bytes"),
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("flag"),
+		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("io"),
+		codegen.SimpleImport("io/ioutil"),
+		codegen.SimpleImport("mime"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("regexp"),
+		codegen.SimpleImport("strings"),
+		codegen.SimpleImport("testing"),
+		codegen.SimpleImport("unicode/utf8"),
+	}
+	title := fmt.Sprintf("%s: Contract Tests", g.API.Context())
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		file.Close()
+		return nil, err
+	}
+	funcs := template.FuncMap{"quote": strconv.Quote}
+	if err = file.ExecuteTemplate("contract", contractT, funcs, s); err != nil {
+		file.Close()
+		return nil, err
+	}
+	file.Close()
+	if err = file.FormatCode(); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, testFile)
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+const contractT = `var (
+	baseURL = flag.String("contract.url", {{ quote .BaseURL }}, "Base URL of the tested API implementation")
+	auth    = flag.String("contract.auth", "", "Value of the Authorization header sent with every request")
+)
+
+// action describes the declared responses of an action and the requests sent to it.
+type action struct {
+	name      string
+	responses []*response
+	cases     []*testCase
+}
+
+// response describes a declared response.
+type response struct {
+	status      int
+	contentType string
+	schema      string
+}
+
+// testCase describes a request, status is the expected response status or zero if the
+// response may have any of the declared statuses.
+type testCase struct {
+	name    string
+	method  string
+	path    string
+	query   string
+	headers map[string]string
+	body    string
+	status  int
+}
+
+// actions lists the tested actions.
+var actions = []*action{
+{{ range .Actions }}	{
+		name: {{ quote .Name }},
+		responses: []*response{
+{{ range .Responses }}			{status: {{ .Status }}, contentType: {{ quote .ContentType }}, schema: {{ quote .Schema }}},
+{{ end }}		},
+		cases: []*testCase{
+{{ range .Cases }}			{
+				name:   {{ quote .Name }},
+				method: {{ quote .Method }},
+				path:   {{ quote .Path }},
+				query:  {{ quote .Query }},
+				headers: map[string]string{
+{{ range $k, $v := .Headers }}					{{ quote $k }}: {{ quote $v }},
+{{ end }}				},
+				body:   {{ quote .Body }},
+				status: {{ .Status }},
+			},
+{{ end }}		},
+	},
+{{ end }}}
+
+// TestContract sends the requests of each action to the tested implementation and checks that
+// the responses match the design.
+func TestContract(t *testing.T) {
+	for _, a := range actions {
+		a := a
+		t.Run(a.name, func(t *testing.T) {
+			for _, c := range a.cases {
+				c := c
+				t.Run(c.name, func(t *testing.T) { runCase(t, a, c) })
+			}
+		})
+	}
+}
+
+// runCase sends the request described by c and checks the response.
+func runCase(t *testing.T, a *action, c *testCase) {
+	u := strings.TrimRight(*baseURL, "/") + c.path
+	if c.query != "" {
+		u += "?" + c.query
+	}
+	var body io.Reader
+	if c.body != "" {
+		body = strings.NewReader(c.body)
+	}
+	req, err := http.NewRequest(c.method, u, body)
+	if err != nil {
+		t.Fatalf("invalid request: %s", err)
+	}
+	if c.body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	if *auth != "" {
+		req.Header.Set("Authorization", *auth)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %s", err)
+	}
+
+	if c.status != 0 && resp.StatusCode != c.status {
+		t.Fatalf("got status %d, expected %d, body: %s", resp.StatusCode, c.status, b)
+	}
+	var expected *response
+	for _, r := range a.responses {
+		if r.status == resp.StatusCode {
+			expected = r
+			break
+		}
+	}
+	if expected == nil {
+		if c.status == 0 {
+			t.Fatalf("got status %d which is not declared in the design, body: %s", resp.StatusCode, b)
+		}
+		return
+	}
+	if expected.schema == "" {
+		return
+	}
+	if expected.contentType != "" {
+		got, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		want, _, _ := mime.ParseMediaType(expected.contentType)
+		if !strings.EqualFold(got, want) {
+			t.Errorf("got content type %q, expected %q", resp.Header.Get("Content-Type"), expected.contentType)
+		}
+	}
+	var s schema
+	dec := json.NewDecoder(strings.NewReader(expected.schema))
+	dec.UseNumber()
+	if err := dec.Decode(&s); err != nil {
+		t.Fatalf("invalid schema: %s", err)
+	}
+	var v interface{}
+	dec = json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("invalid JSON body: %s, body: %s", err, b)
+	}
+	for _, e := range s.validate("body", v, true) {
+		t.Error(e)
+	}
+}
+
+// schema describes the expected structure of a response body.
+type schema struct {
+	Type       string             ` + "`" + `json:"type"` + "`" + `
+	Required   []string           ` + "`" + `json:"required"` + "`" + `
+	Properties map[string]*schema ` + "`" + `json:"properties"` + "`" + `
+	Items      *schema            ` + "`" + `json:"items"` + "`" + `
+	Values     *schema            ` + "`" + `json:"values"` + "`" + `
+	Enum       []interface{}      ` + "`" + `json:"enum"` + "`" + `
+	Minimum    *json.Number       ` + "`" + `json:"minimum"` + "`" + `
+	Maximum    *json.Number       ` + "`" + `json:"maximum"` + "`" + `
+	MinLength  *int               ` + "`" + `json:"minLength"` + "`" + `
+	MaxLength  *int               ` + "`" + `json:"maxLength"` + "`" + `
+	Pattern    string             ` + "`" + `json:"pattern"` + "`" + `
+}
+
+// validate returns the errors describing how v does not conform to the schema, path is the
+// location of v in the body. required is false for optional object properties which may be
+// null.
+func (s *schema) validate(path string, v interface{}, required bool) []string {
+	if v == nil {
+		if required && s.Type != "" {
+			return []string{fmt.Sprintf("%s: got null, expected %s", path, s.Type)}
+		}
+		return nil
+	}
+	var errs []string
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, path+": "+fmt.Sprintf(format, args...))
+	}
+	switch s.Type {
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail("got %v, expected a boolean", v)
+		}
+	case "integer", "number":
+		n, ok := v.(json.Number)
+		if !ok {
+			fail("got %v, expected %s", v, s.Type)
+			break
+		}
+		if _, err := n.Int64(); s.Type == "integer" && err != nil {
+			fail("got %s, expected an integer", n)
+		}
+		f, _ := n.Float64()
+		if s.Minimum != nil {
+			if min, _ := s.Minimum.Float64(); f < min {
+				fail("got %s, expected value greater or equal to %s", n, *s.Minimum)
+			}
+		}
+		if s.Maximum != nil {
+			if max, _ := s.Maximum.Float64(); f > max {
+				fail("got %s, expected value lesser or equal to %s", n, *s.Maximum)
+			}
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail("got %v, expected a string", v)
+			break
+		}
+		s.validateLength(utf8.RuneCountInString(str), fail)
+		if s.Pattern != "" {
+			if ok, err := regexp.MatchString(s.Pattern, str); err == nil && !ok {
+				fail("got %q, expected value matching %s", str, s.Pattern)
+			}
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			fail("got %v, expected an array", v)
+			break
+		}
+		s.validateLength(len(arr), fail)
+		if s.Items != nil {
+			for i, e := range arr {
+				errs = append(errs, s.Items.validate(fmt.Sprintf("%s[%d]", path, i), e, true)...)
+			}
+		}
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			fail("got %v, expected an object", v)
+			break
+		}
+		for _, n := range s.Required {
+			if _, ok := obj[n]; !ok {
+				fail("missing required field %q", n)
+			}
+		}
+		for n, p := range s.Properties {
+			if pv, ok := obj[n]; ok {
+				errs = append(errs, p.validate(path+"."+n, pv, contains(s.Required, n))...)
+			}
+		}
+		if s.Values != nil {
+			for n, e := range obj {
+				errs = append(errs, s.Values.validate(path+"."+n, e, true)...)
+			}
+		}
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if fmt.Sprint(e) == fmt.Sprint(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("got %v, expected one of %v", v, s.Enum)
+		}
+	}
+	return errs
+}
+
+// validateLength checks the length of a string or array against the schema.
+func (s *schema) validateLength(n int, fail func(string, ...interface{})) {
+	if s.MinLength != nil && n < *s.MinLength {
+		fail("got length %d, expected length greater or equal to %d", n, *s.MinLength)
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		fail("got length %d, expected length lesser or equal to %d", n, *s.MaxLength)
+	}
+}
+
+// contains returns true if vals contains s.
+func contains(vals []string, s string) bool {
+	for _, v := range vals {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+`
@@ -0,0 +1,104 @@
+package gencontract_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	gencontract "github.com/kyokomi/goa-v1/goagen/gen_contract"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *gencontract.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+		target string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+		target: "contract_tests",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = gencontract.NewGenerator(
+				gencontract.API(args.api),
+				gencontract.OutDir(args.outDir),
+				gencontract.Target(args.target),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Target).Should(Equal(args.target))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(workspace.Path, "")
+		Ω(err).ShouldNot(HaveOccurred())
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {
+			apidsl.BasePath("/api")
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Params(func() {
+					apidsl.Param("id", design.Integer, func() {
+						apidsl.Example(1)
+					})
+				})
+				apidsl.Response(design.OK, func() {
+					apidsl.Media(design.ErrorMedia)
+				})
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = gencontract.NewGenerator(
+			gencontract.API(design.Design),
+			gencontract.OutDir(outDir),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("generates the contract test package", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(HaveLen(2))
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "contract", "contract_test.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring("package contract"))
+		Ω(string(content)).Should(ContainSubstring(`name: "bottle#show"`))
+		Ω(string(content)).Should(ContainSubstring(`path:    "/api/bottles/1"`))
+		Ω(string(content)).Should(ContainSubstring("func TestContract(t *testing.T)"))
+	})
+})
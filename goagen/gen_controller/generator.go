@@ -27,22 +27,23 @@ func NewGenerator(options ...Option) *Generator {
 
 // Generator is the application code generator.
 type Generator struct {
-	API       *design.APIDefinition // The API definition
-	OutDir    string                // Path to output directory
-	DesignPkg string                // Path to design package, only used to mark generated files.
-	AppPkg    string                // Name of generated "app" package
-	Force     bool                  // Whether to override existing files
-	Regen     bool                  // Whether to regenerate scaffolding in place, retaining controller impls
-	Pkg       string                // Name of the generated package
-	Resource  string                // Name of the generated file
-	genfiles  []string              // Generated files
+	API          *design.APIDefinition // The API definition
+	OutDir       string                // Path to output directory
+	DesignPkg    string                // Path to design package, only used to mark generated files.
+	AppPkg       string                // Name of generated "app" package
+	Force        bool                  // Whether to override existing files
+	Regen        bool                  // Whether to regenerate scaffolding in place, retaining controller impls
+	ContextFirst bool                  // Whether the actions take a context.Context and the request data instead of an action context
+	Pkg          string                // Name of the generated package
+	Resource     string                // Name of the generated file
+	genfiles     []string              // Generated files
 }
 
 // Generate is the generator entry point called by the meta generator.
 func Generate() (files []string, err error) {
 	var (
 		outDir, designPkg, appPkg, ver, res, pkg string
-		force, regen                             bool
+		force, regen, ctxFirst                   bool
 	)
 
 	set := flag.NewFlagSet("controller", flag.PanicOnError)
@@ -54,6 +55,7 @@ func Generate() (files []string, err error) {
 	set.StringVar(&ver, "version", "", "")
 	set.BoolVar(&force, "force", false, "")
 	set.BoolVar(&regen, "regen", false, "")
+	set.BoolVar(&ctxFirst, "context-first", false, "")
 	set.Bool("notest", false, "")
 	set.Parse(os.Args[1:])
 
@@ -61,7 +63,7 @@ func Generate() (files []string, err error) {
 		return nil, err
 	}
 
-	g := &Generator{OutDir: outDir, DesignPkg: designPkg, AppPkg: appPkg, Force: force, Regen: regen, API: design.Design, Pkg: pkg, Resource: res}
+	g := &Generator{OutDir: outDir, DesignPkg: designPkg, AppPkg: appPkg, Force: force, Regen: regen, ContextFirst: ctxFirst, API: design.Design, Pkg: pkg, Resource: res}
 
 	return g.Generate()
 }
@@ -94,7 +96,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 			err      error
 		)
 		if g.Resource == "" || g.Resource == r.Name {
-			filename, err = genmain.GenerateController(g.Force, g.Regen, g.AppPkg, g.OutDir, g.Pkg, r.Name, r)
+			filename, err = genmain.GenerateController(g.Force, g.Regen, g.AppPkg, g.OutDir, g.Pkg, r.Name, r, g.API, g.ContextFirst)
 		}
 
 		if err != nil {
@@ -60,3 +60,10 @@ func Resource(res string) Option {
 		g.Resource = res
 	}
 }
+
+//ContextFirst Whether the actions take a context.Context and the request data as separate arguments
+func ContextFirst(contextFirst bool) Option {
+	return func(g *Generator) {
+		g.ContextFirst = contextFirst
+	}
+}
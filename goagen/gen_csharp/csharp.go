@@ -0,0 +1,837 @@
+package gencsharp
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+type (
+	// Package describes the content of the generated C# project.
+	Package struct {
+		// Namespace of the generated code, e.g. "CellarClient".
+		Namespace string
+		// Version is the version of the assembly.
+		Version string
+		// BaseURL is the default base URL of the client.
+		BaseURL string
+		// Records lists the DTO records sorted by name.
+		Records []*Record
+		// Enums lists the enums generated for the string attributes that declare an Enum
+		// validation sorted by name.
+		Enums []*Enum
+		// Methods lists the client methods sorted by name.
+		Methods []*Method
+	}
+
+	// Record describes a DTO record.
+	Record struct {
+		// Name of the record.
+		Name string
+		// Description of the record.
+		Description string
+		// Properties lists the record properties sorted by attribute name.
+		Properties []*Property
+		// DataType is the goa type described by the record.
+		DataType design.DataType
+	}
+
+	// Property describes a record property.
+	Property struct {
+		// Name of the property.
+		Name string
+		// AttName is the name of the attribute in the JSON representation.
+		AttName string
+		// Type of the property, optional properties use a nullable type.
+		Type string
+		// Required is true if the attribute is required.
+		Required bool
+		// Description of the property.
+		Description string
+	}
+
+	// Enum describes an enum generated for a string attribute that declares an Enum validation.
+	Enum struct {
+		// Name of the enum.
+		Name string
+		// Description of the enum.
+		Description string
+		// Members lists the enum members in order of declaration.
+		Members []*EnumMember
+	}
+
+	// EnumMember describes an enum member.
+	EnumMember struct {
+		// Name of the member.
+		Name string
+		// Value is the serialized value of the member.
+		Value string
+	}
+
+	// Method describes an async client method that sends requests to an action route.
+	Method struct {
+		// Name of the method, e.g. "ShowBottleAsync".
+		Name string
+		// Description of the method.
+		Description string
+		// Verb is the HTTP method of the requests.
+		Verb string
+		// Path is the C# expression that builds the request path.
+		Path string
+		// Args lists the method arguments, required arguments first.
+		Args []*Arg
+		// Query lists the statements that add the query string parameters to _query.
+		Query []string
+		// Headers lists the statements that add the request headers to _headers.
+		Headers []string
+		// Body lists the statements that declare the _content value, empty if the action has
+		// no payload.
+		Body []string
+		// Responses lists the declared responses sorted by status.
+		Responses []*Response
+		// Returns is the type of the value returned by the task, empty if it returns nothing.
+		Returns string
+		// Result is the abstract record returned by the methods that declare successful
+		// responses with different bodies, empty otherwise.
+		Result string
+		// Error is the name of the abstract exception class thrown for the declared error
+		// responses, empty if there is none.
+		Error string
+	}
+
+	// Arg describes a method argument.
+	Arg struct {
+		// Name of the argument.
+		Name string
+		// Type of the argument.
+		Type string
+		// Optional is true if the argument defaults to null.
+		Optional bool
+	}
+
+	// Response describes a declared response.
+	Response struct {
+		// Status is the HTTP status code of the response.
+		Status int
+		// Class is the name of the nested type of the result record or exception class that
+		// describes the response.
+		Class string
+		// Body is the type of the decoded body, empty if the response has no body.
+		Body string
+		// Text is true if the body is read as text instead of being decoded from JSON.
+		Text bool
+		// Error is true if the response is not a 2xx response.
+		Error bool
+	}
+)
+
+// reservedWords lists the C# keywords, identifiers that use one of these are prefixed with @.
+var reservedWords = map[string]bool{
+	"abstract": true, "as": true, "base": true, "bool": true, "break": true, "byte": true,
+	"case": true, "catch": true, "char": true, "checked": true, "class": true, "const": true,
+	"continue": true, "decimal": true, "default": true, "delegate": true, "do": true,
+	"double": true, "else": true, "enum": true, "event": true, "explicit": true, "extern": true,
+	"false": true, "finally": true, "fixed": true, "float": true, "for": true, "foreach": true,
+	"goto": true, "if": true, "implicit": true, "in": true, "int": true, "interface": true,
+	"internal": true, "is": true, "lock": true, "long": true, "namespace": true, "new": true,
+	"null": true, "object": true, "operator": true, "out": true, "override": true,
+	"params": true, "private": true, "protected": true, "public": true, "readonly": true,
+	"ref": true, "return": true, "sbyte": true, "sealed": true, "short": true, "sizeof": true,
+	"stackalloc": true, "static": true, "string": true, "struct": true, "switch": true,
+	"this": true, "throw": true, "true": true, "try": true, "typeof": true, "uint": true,
+	"ulong": true, "unchecked": true, "unsafe": true, "ushort": true, "using": true,
+	"virtual": true, "void": true, "volatile": true, "while": true,
+}
+
+// reservedTypeNames lists the names of the .NET and generated types that cannot be used by the
+// generated records, records that use one of these names get the "Type" suffix.
+var reservedTypeNames = map[string]bool{
+	"ApiException": true, "Client": true, "Dictionary": true, "Exception": true,
+	"HttpClient": true, "JsonElement": true, "List": true, "Object": true, "String": true,
+	"Task": true, "UnexpectedResponseException": true, "Uri": true,
+}
+
+// invalidNameChars matches the characters that cannot be used in C# identifiers.
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// New builds the description of the C# client of the API. The client contains a record for
+// each user type, media type view and payload, an enum for each string attribute that declares
+// an Enum validation and an async method for each action route.
+func New(api *design.APIDefinition, namespace, baseURL string) (*Package, error) {
+	b := &builder{api: api, records: make(map[string]*Record), enums: make(map[string]*Enum)}
+	err := api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		_, err := b.recordName(ut)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() {
+			return nil
+		}
+		return mt.IterateViews(func(v *design.ViewDefinition) error {
+			p, _, err := mt.Project(v.Name)
+			if err != nil {
+				return err
+			}
+			_, err = b.recordName(p)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	p := &Package{Namespace: namespace, Version: assemblyVersion(api.Version), BaseURL: baseURL}
+	err = api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() {
+				return nil
+			}
+			for i, r := range a.Routes {
+				m, err := b.method(a, r, i)
+				if err != nil {
+					return fmt.Errorf("%s: %s", a.Context(), err)
+				}
+				p.Methods = append(p.Methods, m)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(p.Methods, func(i, j int) bool { return p.Methods[i].Name < p.Methods[j].Name })
+	for _, r := range b.records {
+		p.Records = append(p.Records, r)
+	}
+	sort.Slice(p.Records, func(i, j int) bool { return p.Records[i].Name < p.Records[j].Name })
+	for _, e := range b.enums {
+		p.Enums = append(p.Enums, e)
+	}
+	sort.Slice(p.Enums, func(i, j int) bool { return p.Enums[i].Name < p.Enums[j].Name })
+	for _, m := range p.Methods {
+		for _, n := range []string{m.Result, m.Error} {
+			if _, ok := b.records[n]; ok {
+				return nil, fmt.Errorf("type %s is already defined", n)
+			}
+			if _, ok := b.enums[n]; ok {
+				return nil, fmt.Errorf("type %s is already defined", n)
+			}
+		}
+	}
+	return p, nil
+}
+
+// builder computes the records, enums and methods.
+type builder struct {
+	api     *design.APIDefinition
+	records map[string]*Record
+	enums   map[string]*Enum
+}
+
+// method builds the client method that sends requests to the i-th route of the action.
+func (b *builder) method(a *design.ActionDefinition, r *design.RouteDefinition, i int) (*Method, error) {
+	typeName := pascalCase(a.Name) + pascalCase(a.Parent.Name)
+	if i > 0 {
+		typeName += strconv.Itoa(i + 1)
+	}
+	m := &Method{
+		Name:        typeName + "Async",
+		Description: a.Description,
+		Verb:        r.Verb,
+	}
+	if m.Description == "" {
+		m.Description = fmt.Sprintf("%s calls the %s action of the %s resource.", m.Name, a.Name, a.Parent.Name)
+	}
+
+	var optional []*Arg
+	all := a.AllParams()
+	pathParams := r.Params()
+	interpolations := make(map[string]string)
+	for _, p := range pathParams {
+		var at *design.AttributeDefinition
+		if all != nil {
+			at = all.Type.ToObject()[p]
+		}
+		if at == nil {
+			at = &design.AttributeDefinition{Type: design.String}
+		}
+		typ, err := b.csType(at, typeName+pascalCase(p))
+		if err != nil {
+			return nil, fmt.Errorf("parameter %#v: %s", p, err)
+		}
+		arg := &Arg{Name: identifier(p), Type: typ}
+		m.Args = append(m.Args, arg)
+		interpolations[p] = fmt.Sprintf("{PathEscape(%s, %t)}",
+			paramString(at, arg.Name), strings.Contains(r.FullPath(), "*"+p))
+	}
+	m.Path = pathTemplate(r.FullPath(), interpolations)
+
+	addParams := func(params *design.AttributeDefinition, header bool) error {
+		if params == nil {
+			return nil
+		}
+		for _, n := range codegen.SortedNames(params.Type.ToObject()) {
+			if !header && codegen.ContainsString(pathParams, n) {
+				continue
+			}
+			at := params.Type.ToObject()[n]
+			typ, err := b.csType(at, typeName+pascalCase(n))
+			if err != nil {
+				return fmt.Errorf("parameter %#v: %s", n, err)
+			}
+			arg := &Arg{Name: identifier(n), Type: typ}
+			v := arg.Name
+			if params.IsRequired(n) {
+				m.Args = append(m.Args, arg)
+			} else {
+				arg.Type += "?"
+				arg.Optional = true
+				optional = append(optional, arg)
+				v = "_" + strings.TrimPrefix(arg.Name, "@")
+			}
+			target := "_query"
+			if header {
+				target = "_headers"
+			}
+			var stmt string
+			switch {
+			case at.Type.IsArray() && header:
+				elem := at.Type.ToArray().ElemType
+				values := v
+				if s := paramString(elem, "_v"); s != "_v" {
+					values = fmt.Sprintf("%s.Select(_v => %s)", v, s)
+				}
+				stmt = fmt.Sprintf("%s.Add(new(%s, string.Join(\",\", %s)));", target, quote(n), values)
+			case at.Type.IsArray():
+				elem := at.Type.ToArray().ElemType
+				stmt = fmt.Sprintf("foreach (var _v in %s) %s.Add(new(%s, %s));", v, target, quote(n), paramString(elem, "_v"))
+			default:
+				stmt = fmt.Sprintf("%s.Add(new(%s, %s));", target, quote(n), paramString(at, v))
+			}
+			if arg.Optional {
+				stmt = fmt.Sprintf("if (%s is { } %s) %s", arg.Name, v, stmt)
+			}
+			if header {
+				m.Headers = append(m.Headers, stmt)
+			} else {
+				m.Query = append(m.Query, stmt)
+			}
+		}
+		return nil
+	}
+	if err := addParams(a.QueryParams, false); err != nil {
+		return nil, err
+	}
+	if err := addParams(a.Headers, true); err != nil {
+		return nil, err
+	}
+
+	if a.Payload != nil {
+		typ, err := b.recordName(a.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("payload: %s", err)
+		}
+		arg := &Arg{Name: "payload", Type: typ}
+		if a.PayloadOptional {
+			arg.Type += "?"
+			arg.Optional = true
+			optional = append([]*Arg{arg}, optional...)
+		} else {
+			m.Args = append(m.Args, arg)
+		}
+		switch {
+		case a.PayloadMultipart:
+			stmts, err := b.multipart(a.Payload, arg.Optional)
+			if err != nil {
+				return nil, fmt.Errorf("payload: %s", err)
+			}
+			m.Body = stmts
+		case arg.Optional:
+			m.Body = []string{"HttpContent? _content = payload is null ? null : JsonBody(payload);"}
+		default:
+			m.Body = []string{"HttpContent? _content = JsonBody(payload);"}
+		}
+	}
+	m.Args = append(m.Args, optional...)
+
+	err := a.IterateResponses(func(resp *design.ResponseDefinition) error {
+		res, err := b.response(resp)
+		if err != nil {
+			return fmt.Errorf("response %#v: %s", resp.Name, err)
+		}
+		m.Responses = append(m.Responses, res)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(m.Responses, func(i, j int) bool { return m.Responses[i].Status < m.Responses[j].Status })
+	var successes []*Response
+	bodies := make(map[string]bool)
+	for _, res := range m.Responses {
+		if res.Error {
+			m.Error = typeName + "Exception"
+			continue
+		}
+		successes = append(successes, res)
+		bodies[res.Body] = true
+	}
+	switch {
+	case len(bodies) > 1:
+		m.Result = typeName + "Result"
+		m.Returns = m.Result
+	case len(successes) > 0 && successes[0].Body != "":
+		m.Returns = successes[0].Body
+	}
+	return m, nil
+}
+
+// response builds the description of the given action response.
+func (b *builder) response(r *design.ResponseDefinition) (*Response, error) {
+	res := &Response{
+		Status: r.Status,
+		Class:  typeName(r.Name),
+		Error:  r.Status < http.StatusOK || r.Status >= http.StatusMultipleChoices,
+	}
+	if r.Status == http.StatusNoContent {
+		return res, nil
+	}
+	if r.MediaType != "" {
+		mt, ok := b.api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]
+		if !ok {
+			res.Body, res.Text = "string", true
+			return res, nil
+		}
+		view := r.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return nil, err
+		}
+		name, err := b.recordName(p)
+		if err != nil {
+			return nil, err
+		}
+		res.Body = name
+		return res, nil
+	}
+	if r.Type != nil {
+		name, err := b.recordName(r.Type)
+		if err != nil {
+			return nil, err
+		}
+		res.Body = name
+	}
+	return res, nil
+}
+
+// recordName registers the record that corresponds to the given user type or media type and
+// returns its name. User types and media types that are not objects do not produce a record,
+// recordName returns the C# type of their values instead, e.g. "List<Bottle>".
+func (b *builder) recordName(t design.DataType) (string, error) {
+	t, err := projected(&design.AttributeDefinition{Type: t})
+	if err != nil {
+		return "", err
+	}
+	ut, ok := userType(t)
+	if !ok {
+		return "", fmt.Errorf("%s is not a user type", t.Name())
+	}
+	name := typeName(codegen.GoTypeName(t, nil, 0, false))
+	if !ut.Type.IsObject() {
+		typ, err := b.csType(ut.AttributeDefinition, name+"Item")
+		if err != nil {
+			return "", fmt.Errorf("type %s: %s", name, err)
+		}
+		return typ, nil
+	}
+	if r, ok := b.records[name]; ok {
+		if r.DataType.Name() != t.Name() {
+			return "", fmt.Errorf("record %s is already defined", name)
+		}
+		return name, nil
+	}
+	r := &Record{Name: name, Description: ut.Description, DataType: t}
+	b.records[name] = r
+	obj := ut.Type.ToObject()
+	for _, n := range codegen.SortedNames(obj) {
+		at := obj[n]
+		typ, err := b.csType(at, name+pascalCase(n))
+		if err != nil {
+			delete(b.records, name)
+			return "", fmt.Errorf("type %s: attribute %#v: %s", name, n, err)
+		}
+		prop := &Property{
+			Name:        propertyName(n, name),
+			AttName:     n,
+			Type:        typ,
+			Required:    ut.IsRequired(n),
+			Description: at.Description,
+		}
+		if !prop.Required {
+			prop.Type += "?"
+		}
+		r.Properties = append(r.Properties, prop)
+	}
+	return name, nil
+}
+
+// csType returns the C# type of the values of the given attribute. enumName is the name of the
+// enum generated if the attribute is a string that declares an Enum validation.
+func (b *builder) csType(at *design.AttributeDefinition, enumName string) (string, error) {
+	switch actual := at.Type.(type) {
+	case design.Primitive:
+		typ := primitiveType(actual)
+		if !isEnum(at) {
+			return typ, nil
+		}
+		return b.enum(typeName(enumName), at)
+	case *design.Array:
+		elem, err := b.csType(actual.ElemType, enumName)
+		if err != nil {
+			return "", err
+		}
+		return "List<" + elem + ">", nil
+	case *design.Hash:
+		elem, err := b.csType(actual.ElemType, enumName)
+		if err != nil {
+			return "", err
+		}
+		return "Dictionary<string, " + elem + ">", nil
+	case design.Object:
+		return "Dictionary<string, JsonElement>", nil
+	case *design.MediaTypeDefinition, *design.UserTypeDefinition:
+		t, err := projected(at)
+		if err != nil {
+			return "", err
+		}
+		return b.recordName(t)
+	}
+	return "", fmt.Errorf("unsupported type %s", at.Type.Name())
+}
+
+// enum registers the enum that lists the values of the given attribute and returns its name.
+func (b *builder) enum(name string, at *design.AttributeDefinition) (string, error) {
+	e := &Enum{Name: name, Description: at.Description}
+	used := make(map[string]bool)
+	for _, v := range at.Validation.Values {
+		val, ok := v.(string)
+		if !ok {
+			return "", fmt.Errorf("unsupported enum value %#v", v)
+		}
+		member := &EnumMember{Name: memberName(val), Value: val}
+		base := member.Name
+		for i := 2; used[member.Name]; i++ {
+			member.Name = fmt.Sprintf("%s%d", base, i)
+		}
+		used[member.Name] = true
+		e.Members = append(e.Members, member)
+	}
+	if existing, ok := b.enums[name]; ok {
+		if !sameMembers(existing, e) {
+			return "", fmt.Errorf("enum %s is already defined", name)
+		}
+		return name, nil
+	}
+	if _, ok := b.records[name]; ok {
+		return "", fmt.Errorf("record %s is already defined", name)
+	}
+	b.enums[name] = e
+	return name, nil
+}
+
+// sameMembers returns true if the two enums define the same members.
+func sameMembers(e1, e2 *Enum) bool {
+	if len(e1.Members) != len(e2.Members) {
+		return false
+	}
+	for i, m := range e1.Members {
+		if *m != *e2.Members[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// multipart returns the statements that encode the given payload as multipart form data.
+func (b *builder) multipart(payload *design.UserTypeDefinition, optional bool) ([]string, error) {
+	if !payload.Type.IsObject() {
+		return nil, fmt.Errorf("multipart payloads must be objects")
+	}
+	name, err := b.recordName(payload)
+	if err != nil {
+		return nil, err
+	}
+	stmts := []string{"var _form = new MultipartFormDataContent();"}
+	obj := payload.Type.ToObject()
+	for _, n := range codegen.SortedNames(obj) {
+		at := obj[n]
+		v := "payload." + propertyName(n, name)
+		if !payload.IsRequired(n) {
+			v = "_" + strings.TrimPrefix(identifier(n), "@")
+		}
+		var stmt string
+		switch {
+		case at.Type.Kind() == design.FileKind:
+			stmt = fmt.Sprintf("_form.Add(new ByteArrayContent(%s), %s, %s);", v, quote(n), quote(n))
+		case at.Type.IsPrimitive():
+			stmt = fmt.Sprintf("_form.Add(new StringContent(%s), %s);", paramString(at, v), quote(n))
+		default:
+			stmt = fmt.Sprintf("_form.Add(JsonBody(%s), %s);", v, quote(n))
+		}
+		if !payload.IsRequired(n) {
+			stmt = fmt.Sprintf("if (payload.%s is { } %s) %s", propertyName(n, name), v, stmt)
+		}
+		stmts = append(stmts, stmt)
+	}
+	if !optional {
+		return append(stmts, "HttpContent? _content = _form;"), nil
+	}
+	body := []string{"HttpContent? _content = null;", "if (payload is not null)", "{"}
+	for _, stmt := range stmts {
+		body = append(body, "    "+stmt)
+	}
+	return append(body, "    _content = _form;", "}"), nil
+}
+
+// primitiveType returns the C# type used to represent values of the given primitive. Date
+// times, UUIDs and decimals are represented with their string representation.
+func primitiveType(p design.Primitive) string {
+	switch p.Kind() {
+	case design.BooleanKind:
+		return "bool"
+	case design.IntegerKind:
+		return "long"
+	case design.NumberKind:
+		return "double"
+	case design.StringKind, design.DateTimeKind, design.UUIDKind, design.DecimalKind:
+		return "string"
+	case design.FileKind:
+		return "byte[]"
+	case design.AnyKind:
+		return "JsonElement"
+	}
+	if c := design.CustomPrimitive(p); c != nil {
+		switch c.JSONType {
+		case "integer":
+			return "long"
+		case "number":
+			return "double"
+		case "boolean":
+			return "bool"
+		}
+		return "string"
+	}
+	return "JsonElement"
+}
+
+// isEnum returns true if the attribute is a string that declares an Enum validation.
+func isEnum(at *design.AttributeDefinition) bool {
+	return at.Type.Kind() == design.StringKind && at.Validation != nil && len(at.Validation.Values) > 0
+}
+
+// paramString returns the expression that converts the value v of the given attribute into
+// its string representation.
+func paramString(at *design.AttributeDefinition, v string) string {
+	if isEnum(at) {
+		return v + ".ToValue()"
+	}
+	switch primitiveType(primitive(at)) {
+	case "string":
+		return v
+	case "bool":
+		return fmt.Sprintf("%s ? \"true\" : \"false\"", v)
+	case "long", "double":
+		return v + ".ToString(CultureInfo.InvariantCulture)"
+	}
+	return v + ".ToString()"
+}
+
+// primitive returns the primitive type of the attribute, non primitive types are treated as
+// Any.
+func primitive(at *design.AttributeDefinition) design.Primitive {
+	if p, ok := at.Type.(design.Primitive); ok {
+		return p
+	}
+	return design.Any
+}
+
+// projected returns the type of the attribute, media types are projected using the view of the
+// attribute or the default view.
+func projected(at *design.AttributeDefinition) (design.DataType, error) {
+	mt, ok := at.Type.(*design.MediaTypeDefinition)
+	if !ok || strings.Contains(mt.Identifier, "view=") {
+		return at.Type, nil
+	}
+	view := at.View
+	if view == "" {
+		view = design.DefaultView
+	}
+	p, _, err := mt.Project(view)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// userType returns the user type definition of the given user type or media type.
+func userType(t design.DataType) (*design.UserTypeDefinition, bool) {
+	switch actual := t.(type) {
+	case *design.MediaTypeDefinition:
+		return actual.UserTypeDefinition, true
+	case *design.UserTypeDefinition:
+		return actual, true
+	}
+	return nil, false
+}
+
+// identifier returns the camel case C# identifier that corresponds to the given name.
+func identifier(n string) string {
+	name := pascalCase(n)
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	} else {
+		name = strings.ToLower(name[:1]) + name[1:]
+	}
+	if reservedWords[name] {
+		return "@" + name
+	}
+	return name
+}
+
+// propertyName returns the Pascal case name of the property of the record with the given name
+// that corresponds to the attribute n. Properties cannot have the name of their record, these
+// get the "Value" suffix.
+func propertyName(n, record string) string {
+	name := pascalCase(n)
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	if name == record {
+		name += "Value"
+	}
+	return name
+}
+
+// memberName returns the Pascal case name of the enum member that corresponds to the given
+// value.
+func memberName(v string) string {
+	name := pascalCase(v)
+	if name == "" {
+		return "Empty"
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "Value" + name
+	}
+	return name
+}
+
+// pascalCase returns the Pascal case identifier made of the words of n, the words are
+// separated by the characters that are not letters or digits.
+func pascalCase(n string) string {
+	var b strings.Builder
+	for _, w := range wordSeparators.Split(n, -1) {
+		if w != "" {
+			b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+		}
+	}
+	return b.String()
+}
+
+// wordSeparators matches the separators of the words of a name.
+var wordSeparators = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// typeName returns the C# type name that corresponds to the given name.
+func typeName(n string) string {
+	name := pascalCase(n)
+	if reservedTypeNames[name] {
+		name += "Type"
+	}
+	return name
+}
+
+// assemblyVersion returns the version of the assembly that corresponds to the given API
+// version, it defaults to "1.0.0".
+func assemblyVersion(v string) string {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if !versionRegex.MatchString(v) {
+		return "1.0.0"
+	}
+	if !strings.Contains(v, ".") {
+		return v + ".0.0"
+	}
+	return v
+}
+
+// versionRegex matches the versions accepted by the .NET SDK.
+var versionRegex = regexp.MustCompile(`^[0-9]+(\.[0-9]+){0,3}(-[a-zA-Z0-9.-]+)?$`)
+
+// pathTemplate returns the C# string that builds the given route path, interpolations maps the
+// names of the path parameters to the interpolation that replaces them. The string is an
+// interpolated string if the path has parameters.
+func pathTemplate(path string, interpolations map[string]string) string {
+	var b strings.Builder
+	last := 0
+	interpolated := false
+	for _, loc := range codegen.PathParamRegex.FindAllStringSubmatchIndex(path, -1) {
+		b.WriteString(escape(path[last:loc[0]], true))
+		if interpolation, ok := interpolations[path[loc[2]:loc[3]]]; ok {
+			b.WriteString(interpolation)
+			interpolated = true
+		} else {
+			b.WriteString(escape(path[loc[0]:loc[1]], true))
+		}
+		last = loc[1]
+	}
+	b.WriteString(escape(path[last:], true))
+	if !interpolated {
+		return quote(path)
+	}
+	return `$"` + b.String() + `"`
+}
+
+// quote returns the C# string literal of s.
+func quote(s string) string {
+	return `"` + escape(s, false) + `"`
+}
+
+// escape escapes the characters of s that cannot appear as is in a C# string literal, braces
+// are doubled if interpolated is true.
+func escape(s string, interpolated bool) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '{', '}':
+			b.WriteRune(r)
+			if interpolated {
+				b.WriteRune(r)
+			}
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,186 @@
+package gencsharp_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	gencsharp "github.com/kyokomi/goa-v1/goagen/gen_csharp"
+)
+
+var _ = Describe("New", func() {
+	var pkg *gencsharp.Package
+	var newErr error
+
+	BeforeEach(func() {
+		pkg = nil
+		newErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		pkg, newErr = gencsharp.New(Design, "CellarClient", "https://api.example.com")
+	})
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			winery := apidsl.Type("Winery", func() {
+				apidsl.Attribute("name", String)
+				apidsl.Required("name")
+			})
+			bottle := apidsl.MediaType("application/vnd.bottle", func() {
+				apidsl.Description("A bottle of wine")
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer, "ID of bottle")
+					apidsl.Attribute("color", String, func() {
+						apidsl.Enum("red", "white", "rosé", "2nd")
+					})
+					apidsl.Attribute("winery", winery)
+					apidsl.Attribute("created_at", DateTime)
+					apidsl.Required("id", "color")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("color")
+					apidsl.Attribute("winery")
+					apidsl.Attribute("created_at")
+				})
+			})
+			apidsl.API("cellar", func() {
+				apidsl.BasePath("/api")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Description("Get bottle by id")
+					apidsl.Routing(apidsl.GET("/:bottleID"))
+					apidsl.Params(func() {
+						apidsl.Param("bottleID", Integer)
+						apidsl.Param("fields", apidsl.ArrayOf(String))
+					})
+					apidsl.Headers(func() {
+						apidsl.Header("X-Request-Id")
+					})
+					apidsl.Response(OK, bottle)
+					apidsl.Response(NotFound)
+					apidsl.Response(BadRequest, ErrorMedia)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(func() {
+						apidsl.Member("name", String)
+						apidsl.Member("winery", winery)
+						apidsl.Required("name")
+					})
+					apidsl.Response(Created, bottle)
+					apidsl.Response(NoContent)
+				})
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET(""))
+					apidsl.Response(OK, apidsl.CollectionOf(bottle))
+				})
+				apidsl.Action("upload", func() {
+					apidsl.Routing(apidsl.POST("/:bottleID/label"))
+					apidsl.MultipartForm()
+					apidsl.Payload(func() {
+						apidsl.Member("label", File)
+						apidsl.Member("note", String)
+						apidsl.Required("label")
+					})
+					apidsl.Response(NoContent)
+				})
+			})
+		})
+
+		It("generates the records", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			var names []string
+			for _, r := range pkg.Records {
+				names = append(names, r.Name)
+			}
+			Ω(names).Should(Equal([]string{"Bottle", "CreateBottlePayload", "Error", "UploadBottlePayload", "Winery"}))
+			bottle := pkg.Records[0]
+			Ω(bottle.Properties).Should(HaveLen(4))
+			Ω(bottle.Properties[0].Declaration()).Should(Equal("[JsonPropertyName(\"color\")]\n    public required BottleColor Color { get; init; }"))
+			Ω(bottle.Properties[1].Name).Should(Equal("CreatedAt"))
+			Ω(bottle.Properties[1].Declaration()).Should(ContainSubstring("    public string? CreatedAt { get; init; }"))
+			Ω(bottle.Properties[2].Declaration()).Should(HaveSuffix("    public required long Id { get; init; }"))
+			Ω(bottle.Properties[3].Type).Should(Equal("Winery?"))
+			Ω(pkg.Records[3].Properties[0].Type).Should(Equal("byte[]"))
+		})
+
+		It("generates the enums", func() {
+			Ω(pkg.Enums).Should(HaveLen(1))
+			color := pkg.Enums[0]
+			Ω(color.Name).Should(Equal("BottleColor"))
+			var members []string
+			for _, m := range color.Members {
+				members = append(members, m.Name+" = "+m.Value)
+			}
+			Ω(members).Should(Equal([]string{"Red = red", "White = white", "Ros = rosé", "Value2nd = 2nd"}))
+		})
+
+		It("generates the methods", func() {
+			Ω(pkg.Methods).Should(HaveLen(4))
+			create := pkg.Methods[0]
+			Ω(create.Name).Should(Equal("CreateBottleAsync"))
+			Ω(create.Signature()).Should(Equal("CreateBottlePayload payload, CancellationToken cancellationToken = default"))
+			Ω(create.Body).Should(Equal([]string{"HttpContent? _content = JsonBody(payload);"}))
+			Ω(create.Task()).Should(Equal("Task<CreateBottleResult>"))
+			Ω(create.Handle(create.Responses[0])).Should(Equal("return new CreateBottleResult.Created(await ReadJsonAsync<Bottle>(_response, cancellationToken).ConfigureAwait(false));"))
+			Ω(create.Handle(create.Responses[1])).Should(Equal("return new CreateBottleResult.NoContent();"))
+			list := pkg.Methods[1]
+			Ω(list.Task()).Should(Equal("Task<List<Bottle>>"))
+			Ω(list.Handle(list.Responses[0])).Should(Equal("return await ReadJsonAsync<List<Bottle>>(_response, cancellationToken).ConfigureAwait(false);"))
+			show := pkg.Methods[2]
+			Ω(show.Name).Should(Equal("ShowBottleAsync"))
+			Ω(show.Path).Should(Equal(`$"/api/bottles/{PathEscape(bottleID.ToString(CultureInfo.InvariantCulture), false)}"`))
+			Ω(show.Signature()).Should(Equal("long bottleID, List<string>? fields = null, string? xRequestId = null, CancellationToken cancellationToken = default"))
+			Ω(show.Query).Should(Equal([]string{`if (fields is { } _fields) foreach (var _v in _fields) _query.Add(new("fields", _v));`}))
+			Ω(show.Headers).Should(Equal([]string{`if (xRequestId is { } _xRequestId) _headers.Add(new("X-Request-Id", _xRequestId));`}))
+			Ω(show.Task()).Should(Equal("Task<Bottle>"))
+			Ω(show.Error).Should(Equal("ShowBottleException"))
+			Ω(show.Responses).Should(HaveLen(3))
+			Ω(show.Handle(show.Responses[1])).Should(Equal("throw new ShowBottleException.BadRequest(await ReadJsonAsync<Error>(_response, cancellationToken).ConfigureAwait(false));"))
+			Ω(show.Handle(show.Responses[2])).Should(Equal("throw new ShowBottleException.NotFound();"))
+			upload := pkg.Methods[3]
+			Ω(upload.Task()).Should(Equal("Task"))
+			Ω(upload.Path).Should(Equal(`$"/api/bottles/{PathEscape(bottleID, false)}/label"`))
+			Ω(upload.Body).Should(Equal([]string{
+				"var _form = new MultipartFormDataContent();",
+				`_form.Add(new ByteArrayContent(payload.Label), "label", "label");`,
+				`if (payload.Note is { } _note) _form.Add(new StringContent(_note), "note");`,
+				"HttpContent? _content = _form;",
+			}))
+			Ω(upload.Handle(upload.Responses[0])).Should(Equal("return;"))
+		})
+
+		It("renders the files", func() {
+			Ω(pkg.Files()).Should(Equal([]string{"CellarClient.csproj", "Models.cs", "Client.cs"}))
+			project, err := pkg.Write("CellarClient.csproj", "cellar: C# Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(project)).Should(ContainSubstring("    <RootNamespace>CellarClient</RootNamespace>\n    <Version>1.0.0</Version>\n"))
+			models, err := pkg.Write("Models.cs", "cellar: C# Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(models)).Should(ContainSubstring("#nullable enable\n"))
+			Ω(string(models)).Should(ContainSubstring("public enum BottleColor\n{\n    Red,\n"))
+			Ω(string(models)).Should(ContainSubstring("        BottleColor.Ros => \"rosé\",\n"))
+			Ω(string(models)).Should(ContainSubstring("/// <summary>A bottle of wine (default view)</summary>\npublic sealed record Bottle\n{\n"))
+			Ω(string(models)).Should(ContainSubstring("    [JsonPropertyName(\"created_at\")]\n    [JsonIgnore(Condition = JsonIgnoreCondition.WhenWritingNull)]\n    public string? CreatedAt { get; init; }\n"))
+			client, err := pkg.Write("Client.cs", "cellar: C# Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(client)).Should(ContainSubstring("namespace CellarClient;\n"))
+			Ω(string(client)).Should(ContainSubstring(`    public const string DefaultBaseUrl = "https://api.example.com";`))
+			Ω(string(client)).Should(ContainSubstring("    public async Task<Bottle> ShowBottleAsync(long bottleID, List<string>? fields = null, string? xRequestId = null, CancellationToken cancellationToken = default)\n"))
+			Ω(string(client)).Should(ContainSubstring("    public sealed record NoContent : CreateBottleResult;\n"))
+			Ω(string(client)).Should(ContainSubstring("public abstract class ShowBottleException : ApiException\n"))
+			_, err = pkg.Write("Other.cs", "cellar: C# Client")
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
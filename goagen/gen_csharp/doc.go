@@ -0,0 +1,19 @@
+/*
+Package gencsharp provides a generator for a .NET client of the API built on HttpClient and
+System.Text.Json. The generator is invoked with "goagen client --language=csharp" and produces an
+SDK style project that contains:
+
+  - <namespace>.csproj: the project file that targets net8.0 with nullable reference types
+    enabled,
+  - Models.cs: a record with nullable annotated properties for each user type, media type view
+    and payload and an enum with its JSON converter for each string attribute that declares an
+    Enum validation,
+  - Client.cs: a Client class with one async method per action route.
+
+The methods complete with the decoded body of the successful responses or with an abstract
+result record that has one nested record per response when the successful responses have
+different bodies. The declared error responses are thrown as the nested classes of an abstract
+exception class generated for each method, the statuses that are not described in the design
+are thrown as UnexpectedResponseException.
+*/
+package gencsharp
@@ -0,0 +1,13 @@
+package gencsharp_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenCSharp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenCSharp Suite")
+}
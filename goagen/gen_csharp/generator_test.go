@@ -0,0 +1,42 @@
+package gencsharp_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	gencsharp "github.com/kyokomi/goa-v1/goagen/gen_csharp"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *gencsharp.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+		target string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+		target: "target",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = gencsharp.NewGenerator(
+				gencsharp.API(args.api),
+				gencsharp.OutDir(args.outDir),
+				gencsharp.Target(args.target),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Target).Should(Equal(args.target))
+		})
+	})
+})
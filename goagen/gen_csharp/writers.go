@@ -0,0 +1,410 @@
+package gencsharp
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1/version"
+)
+
+// Files returns the paths of the generated files relative to the project directory in order of
+// generation.
+func (p *Package) Files() []string {
+	return []string{p.Namespace + ".csproj", "Models.cs", "Client.cs"}
+}
+
+var (
+	projectTmpl = newTemplate("project", projectT)
+	modelsTmpl  = newTemplate("models", modelsT)
+	clientTmpl  = newTemplate("client", clientT)
+)
+
+// newTemplate parses the given template text.
+func newTemplate(name, text string) *template.Template {
+	return template.Must(template.New(name).Funcs(template.FuncMap{
+		"xmldoc": xmldoc,
+		"xml":    xmlEscaper.Replace,
+		"quote":  quote,
+	}).Parse(text))
+}
+
+// Write renders the file with the given path, title is written in the header comment.
+func (p *Package) Write(file, title string) ([]byte, error) {
+	var tmpl *template.Template
+	switch file {
+	case p.Namespace + ".csproj":
+		tmpl = projectTmpl
+	case "Models.cs":
+		tmpl = modelsTmpl
+	case "Client.cs":
+		tmpl = clientTmpl
+	default:
+		return nil, fmt.Errorf("unknown file %s", file)
+	}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"Title":       title,
+		"ToolVersion": version.String(),
+		"Package":     p,
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Declaration returns the declaration of the property.
+func (p *Property) Declaration() string {
+	decl := fmt.Sprintf("[JsonPropertyName(%s)]\n    ", quote(p.AttName))
+	if p.Required {
+		return decl + fmt.Sprintf("public required %s %s { get; init; }", p.Type, p.Name)
+	}
+	return decl + fmt.Sprintf("[JsonIgnore(Condition = JsonIgnoreCondition.WhenWritingNull)]\n    public %s %s { get; init; }", p.Type, p.Name)
+}
+
+// Task returns the return type of the method.
+func (m *Method) Task() string {
+	if m.Returns == "" {
+		return "Task"
+	}
+	return "Task<" + m.Returns + ">"
+}
+
+// Signature returns the list of arguments of the method, optional arguments default to null
+// and the last argument is the cancellation token.
+func (m *Method) Signature() string {
+	args := make([]string, len(m.Args), len(m.Args)+1)
+	for i, a := range m.Args {
+		args[i] = a.Type + " " + a.Name
+		if a.Optional {
+			args[i] += " = null"
+		}
+	}
+	args = append(args, "CancellationToken cancellationToken = default")
+	return strings.Join(args, ", ")
+}
+
+// Doc returns the XML documentation comment of the method, it lists the exceptions thrown by
+// the method.
+func (m *Method) Doc() string {
+	doc := xmldoc("    ", m.Description)
+	if m.Error != "" {
+		doc += fmt.Sprintf("    /// <exception cref=\"%s\">Thrown for the declared error responses.</exception>\n", m.Error)
+	}
+	return doc + "    /// <exception cref=\"UnexpectedResponseException\">Thrown for the statuses that are not described in the design.</exception>\n"
+}
+
+// Handle returns the statement of the switch section that handles the given response.
+func (m *Method) Handle(r *Response) string {
+	var value string
+	switch {
+	case r.Text:
+		value = "await ReadTextAsync(_response, cancellationToken).ConfigureAwait(false)"
+	case r.Body != "":
+		value = fmt.Sprintf("await ReadJsonAsync<%s>(_response, cancellationToken).ConfigureAwait(false)", r.Body)
+	}
+	switch {
+	case r.Error:
+		return fmt.Sprintf("throw new %s.%s(%s);", m.Error, r.Class, value)
+	case m.Result != "" && value == "":
+		return fmt.Sprintf("return new %s.%s();", m.Result, r.Class)
+	case m.Result != "":
+		return fmt.Sprintf("return new %s.%s(%s);", m.Result, r.Class, value)
+	case value == "":
+		return "return;"
+	}
+	return "return " + value + ";"
+}
+
+// Successes returns the 2xx responses of the method.
+func (m *Method) Successes() []*Response {
+	var res []*Response
+	for _, r := range m.Responses {
+		if !r.Error {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// Errors returns the non 2xx responses of the method.
+func (m *Method) Errors() []*Response {
+	var res []*Response
+	for _, r := range m.Responses {
+		if r.Error {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// ResultDeclaration returns the declaration of the nested record of the result record that
+// describes the response.
+func (m *Method) ResultDeclaration(r *Response) string {
+	if r.Body == "" {
+		return fmt.Sprintf("public sealed record %s : %s;", r.Class, m.Result)
+	}
+	return fmt.Sprintf("public sealed record %s(%s Body) : %s;", r.Class, r.Body, m.Result)
+}
+
+// StatusText returns the text of the response status.
+func (r *Response) StatusText() string {
+	return fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status))
+}
+
+// xmlEscaper escapes the text of the XML documentation comments.
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// xmldoc renders the given text as the summary of an XML documentation comment followed by a
+// new line, indent is prepended to each line.
+func xmldoc(indent, text string) string {
+	text = strings.TrimSpace(xmlEscaper.Replace(text))
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		return indent + "/// <summary>" + lines[0] + "</summary>\n"
+	}
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(indent+"/// "+l, " ")
+	}
+	return indent + "/// <summary>\n" + strings.Join(lines, "\n") + "\n" + indent + "/// </summary>\n"
+}
+
+const headerT = `// <auto-generated>
+// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .Title }}
+// </auto-generated>
+
+#nullable enable
+`
+
+const projectT = `<!--
+  Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+
+  {{ xml .Title }}
+-->
+<Project Sdk="Microsoft.NET.Sdk">
+
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <LangVersion>latest</LangVersion>
+    <Nullable>enable</Nullable>
+    <RootNamespace>{{ .Package.Namespace }}</RootNamespace>
+    <Version>{{ .Package.Version }}</Version>
+  </PropertyGroup>
+
+</Project>
+`
+
+const modelsT = headerT + `
+using System;
+using System.Collections.Generic;
+using System.Text.Json;
+using System.Text.Json.Serialization;
+
+namespace {{ .Package.Namespace }};
+{{ range .Package.Enums }}
+{{ xmldoc "" .Description }}[JsonConverter(typeof({{ .Name }}JsonConverter))]
+public enum {{ .Name }}
+{
+{{ range .Members }}    {{ .Name }},
+{{ end }}}
+
+/// <summary>{{ .Name }}Extensions converts the {{ .Name }} values from and to their serialized value.</summary>
+public static class {{ .Name }}Extensions
+{
+    /// <summary>ToValue returns the serialized value.</summary>
+    public static string ToValue(this {{ .Name }} value) => value switch
+    {
+{{ $e := . }}{{ range .Members }}        {{ $e.Name }}.{{ .Name }} => {{ quote .Value }},
+{{ end }}        _ => throw new ArgumentOutOfRangeException(nameof(value)),
+    };
+
+    /// <summary>FromValue returns the {{ .Name }} with the given serialized value.</summary>
+    public static {{ .Name }} FromValue(string? value) => value switch
+    {
+{{ range .Members }}        {{ quote .Value }} => {{ $e.Name }}.{{ .Name }},
+{{ end }}        _ => throw new JsonException("unknown {{ .Name }} value " + value),
+    };
+}
+
+/// <summary>{{ .Name }}JsonConverter serializes the {{ .Name }} values.</summary>
+public sealed class {{ .Name }}JsonConverter : JsonConverter<{{ .Name }}>
+{
+    public override {{ .Name }} Read(ref Utf8JsonReader reader, Type typeToConvert, JsonSerializerOptions options) =>
+        {{ .Name }}Extensions.FromValue(reader.GetString());
+
+    public override void Write(Utf8JsonWriter writer, {{ .Name }} value, JsonSerializerOptions options) =>
+        writer.WriteStringValue(value.ToValue());
+}
+{{ end }}{{ range .Package.Records }}
+{{ xmldoc "" .Description }}public sealed record {{ .Name }}
+{
+{{ range $i, $p := .Properties }}{{ if $i }}
+{{ end }}{{ xmldoc "    " $p.Description }}    {{ $p.Declaration }}
+{{ end }}}
+{{ end }}`
+
+const clientT = headerT + `
+using System;
+using System.Collections.Generic;
+using System.Globalization;
+using System.Linq;
+using System.Net.Http;
+using System.Text;
+using System.Text.Json;
+using System.Threading;
+using System.Threading.Tasks;
+
+namespace {{ .Package.Namespace }};
+
+/// <summary>ApiException is the base class of the exceptions thrown when the API returns an error response.</summary>
+public class ApiException : Exception
+{
+    public ApiException(int status, string message) : base(message)
+    {
+        Status = status;
+    }
+
+    /// <summary>Status is the HTTP status code of the response.</summary>
+    public int Status { get; }
+}
+
+/// <summary>UnexpectedResponseException is thrown when the API returns a status that is not described in the design.</summary>
+public class UnexpectedResponseException : ApiException
+{
+    public UnexpectedResponseException(int status, string body) : base(status, "unexpected response status " + status)
+    {
+        Body = body;
+    }
+
+    /// <summary>Body is the response body.</summary>
+    public string Body { get; }
+}
+
+/// <summary>
+/// Client gives access to the API.
+///
+/// The requests are sent with httpClient to baseUrl, the request bodies are encoded and the
+/// response bodies decoded with jsonOptions.
+/// </summary>
+public partial class Client
+{
+    /// <summary>DefaultBaseUrl is the base URL used when none is given.</summary>
+    public const string DefaultBaseUrl = {{ quote .Package.BaseURL }};
+
+    private readonly HttpClient _httpClient;
+    private readonly string _baseUrl;
+    private readonly JsonSerializerOptions _jsonOptions;
+
+    public Client(HttpClient httpClient, string baseUrl = DefaultBaseUrl, JsonSerializerOptions? jsonOptions = null)
+    {
+        _httpClient = httpClient ?? throw new ArgumentNullException(nameof(httpClient));
+        _baseUrl = baseUrl.TrimEnd('/');
+        _jsonOptions = jsonOptions ?? new JsonSerializerOptions();
+    }
+
+    /// <summary>Headers lists the headers sent with every request.</summary>
+    public IDictionary<string, string> Headers { get; } = new Dictionary<string, string>();
+{{ range $m := .Package.Methods }}
+{{ .Doc }}    public async {{ .Task }} {{ .Name }}({{ .Signature }})
+    {
+        var _query = new List<KeyValuePair<string, string>>();
+{{ range .Query }}        {{ . }}
+{{ end }}        var _headers = new List<KeyValuePair<string, string>>();
+{{ range .Headers }}        {{ . }}
+{{ end }}{{ range .Body }}        {{ . }}
+{{ else }}        HttpContent? _content = null;
+{{ end }}        using var _response = await SendAsync({{ quote .Verb }}, {{ .Path }}, _query, _headers, _content, cancellationToken).ConfigureAwait(false);
+        switch ((int)_response.StatusCode)
+        {
+{{ range .Responses }}            case {{ .Status }}:
+                {{ $m.Handle . }}
+{{ end }}            default:
+                throw new UnexpectedResponseException((int)_response.StatusCode, await ReadTextAsync(_response, cancellationToken).ConfigureAwait(false));
+        }
+    }
+{{ end }}
+    private async Task<HttpResponseMessage> SendAsync(string method, string path, List<KeyValuePair<string, string>> query,
+        List<KeyValuePair<string, string>> headers, HttpContent? content, CancellationToken cancellationToken)
+    {
+        var url = new StringBuilder(_baseUrl).Append(path);
+        var separator = path.Contains('?') ? '&' : '?';
+        foreach (var (key, value) in query)
+        {
+            url.Append(separator).Append(Uri.EscapeDataString(key)).Append('=').Append(Uri.EscapeDataString(value));
+            separator = '&';
+        }
+        using var request = new HttpRequestMessage(new HttpMethod(method), url.ToString());
+        foreach (var (key, value) in Headers)
+        {
+            request.Headers.TryAddWithoutValidation(key, value);
+        }
+        foreach (var (key, value) in headers)
+        {
+            request.Headers.TryAddWithoutValidation(key, value);
+        }
+        request.Content = content;
+        return await _httpClient.SendAsync(request, HttpCompletionOption.ResponseHeadersRead, cancellationToken).ConfigureAwait(false);
+    }
+
+    private HttpContent JsonBody<T>(T value) =>
+        new StringContent(JsonSerializer.Serialize(value, _jsonOptions), Encoding.UTF8, "application/json");
+
+    private async Task<T> ReadJsonAsync<T>(HttpResponseMessage response, CancellationToken cancellationToken)
+    {
+        using var stream = await response.Content.ReadAsStreamAsync(cancellationToken).ConfigureAwait(false);
+        return await JsonSerializer.DeserializeAsync<T>(stream, _jsonOptions, cancellationToken).ConfigureAwait(false)
+            ?? throw new JsonException("unexpected null response body");
+    }
+
+    private static Task<string> ReadTextAsync(HttpResponseMessage response, CancellationToken cancellationToken) =>
+        response.Content.ReadAsStringAsync(cancellationToken);
+
+    private static string PathEscape(string value, bool keepSlashes)
+    {
+        var escaped = Uri.EscapeDataString(value);
+        return keepSlashes ? escaped.Replace("%2F", "/") : escaped;
+    }
+}
+{{ range $m := .Package.Methods }}{{ if .Result }}
+/// <summary>{{ .Result }} lists the successful responses of {{ .Name }}.</summary>
+public abstract record {{ .Result }}
+{
+    private {{ .Result }}()
+    {
+    }
+{{ range .Successes }}
+    /// <summary>{{ .Class }} describes the {{ .StatusText }} response.</summary>
+    {{ $m.ResultDeclaration . }}
+{{ end }}}
+{{ end }}{{ if .Error }}
+/// <summary>{{ .Error }} lists the error responses of {{ .Name }}.</summary>
+public abstract class {{ .Error }} : ApiException
+{
+    private {{ .Error }}(int status) : base(status, "HTTP " + status)
+    {
+    }
+{{ range .Errors }}
+    /// <summary>{{ .Class }} is thrown when the API returns the {{ .StatusText }} response.</summary>
+    public sealed class {{ .Class }} : {{ $m.Error }}
+    {
+{{ if .Body }}        public {{ .Class }}({{ .Body }} body) : base({{ .Status }})
+        {
+            Body = body;
+        }
+
+        /// <summary>Body is the decoded response body.</summary>
+        public {{ .Body }} Body { get; }
+{{ else }}        public {{ .Class }}() : base({{ .Status }})
+        {
+        }
+{{ end }}    }
+{{ end }}}
+{{ end }}{{ end }}`
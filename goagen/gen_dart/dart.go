@@ -0,0 +1,855 @@
+package gendart
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+type (
+	// Package describes the content of the generated Dart package.
+	Package struct {
+		// Name of the Dart package, e.g. "cellar_client".
+		Name string
+		// Description of the package written in the pubspec.
+		Description string
+		// Version of the package written in the pubspec.
+		Version string
+		// BaseURL is the default base URL of the client.
+		BaseURL string
+		// Classes lists the serializable classes and type aliases sorted by name.
+		Classes []*Class
+		// Enums lists the enums generated for the string attributes that declare an Enum
+		// validation sorted by name.
+		Enums []*Enum
+		// Methods lists the client methods sorted by name.
+		Methods []*Method
+	}
+
+	// Class describes a serializable class or a type alias.
+	Class struct {
+		// Name of the class.
+		Name string
+		// Description of the class.
+		Description string
+		// Fields lists the class fields sorted by name.
+		Fields []*Field
+		// Alias is the aliased type, empty for classes.
+		Alias string
+		// DataType is the goa type described by the class.
+		DataType design.DataType
+	}
+
+	// Field describes a class field.
+	Field struct {
+		// Name of the field.
+		Name string
+		// AttName is the name of the attribute in the JSON representation.
+		AttName string
+		// Type of the field, optional fields use a nullable type.
+		Type string
+		// Required is true if the attribute is required.
+		Required bool
+		// Description of the field.
+		Description string
+	}
+
+	// Enum describes an enum generated for a string attribute that declares an Enum validation.
+	Enum struct {
+		// Name of the enum.
+		Name string
+		// Description of the enum.
+		Description string
+		// Values lists the enum values in order of declaration.
+		Values []*EnumValue
+	}
+
+	// EnumValue describes an enum value.
+	EnumValue struct {
+		// Name of the value.
+		Name string
+		// Value is the serialized value.
+		Value string
+	}
+
+	// Method describes a client method that sends requests to an action route.
+	Method struct {
+		// Name of the method.
+		Name string
+		// Description of the method.
+		Description string
+		// Verb is the HTTP method of the requests.
+		Verb string
+		// Path is the Dart string literal that builds the request path.
+		Path string
+		// Args lists the positional arguments.
+		Args []*Arg
+		// Optional lists the named arguments, they default to null.
+		Optional []*Arg
+		// Query lists the statements that add the query string parameters to _query.
+		Query []string
+		// Headers lists the statements that add the request headers to _headers.
+		Headers []string
+		// Body lists the statements that declare the _body or _form value, empty if the action
+		// has no payload.
+		Body []string
+		// Multipart is true if the payload is sent as multipart form data.
+		Multipart bool
+		// Responses lists the declared responses sorted by status.
+		Responses []*Response
+		// Returns is the type of the value of the returned Future, "void" if it returns
+		// nothing.
+		Returns string
+		// Result is the sealed class returned by the methods that declare successful responses
+		// with different bodies, empty otherwise.
+		Result string
+		// Error is the name of the sealed exception class thrown for the declared error
+		// responses, empty if there is none.
+		Error string
+	}
+
+	// Arg describes a method argument.
+	Arg struct {
+		// Name of the argument.
+		Name string
+		// Type of the argument.
+		Type string
+	}
+
+	// Response describes a declared response.
+	Response struct {
+		// Status is the HTTP status code of the response.
+		Status int
+		// Class is the name of the subclass of the sealed result or exception class that
+		// describes the response.
+		Class string
+		// Body is the type of the decoded body, empty if the response has no body.
+		Body string
+		// Decode is the expression that decodes the body of _response, empty if the response
+		// has no body.
+		Decode string
+		// Error is true if the response is not a 2xx response.
+		Error bool
+	}
+)
+
+// reservedWords lists the Dart reserved words and the members of the generated classes and
+// enums, identifiers that use one of these get the "_" suffix.
+var reservedWords = map[string]bool{
+	"assert": true, "break": true, "case": true, "catch": true, "class": true, "const": true,
+	"continue": true, "default": true, "do": true, "else": true, "enum": true, "extends": true,
+	"false": true, "final": true, "finally": true, "for": true, "if": true, "in": true,
+	"is": true, "new": true, "null": true, "rethrow": true, "return": true, "super": true,
+	"switch": true, "this": true, "throw": true, "true": true, "try": true, "var": true,
+	"void": true, "while": true, "with": true, "await": true, "yield": true,
+	"fromJson": true, "hashCode": true, "noSuchMethod": true, "runtimeType": true,
+	"toJson": true, "toString": true,
+}
+
+// reservedValueNames lists the members of the Dart enums, enum values that use one of these get
+// the "_" suffix.
+var reservedValueNames = map[string]bool{
+	"index": true, "name": true, "value": true, "values": true,
+}
+
+// reservedTypeNames lists the names of the Dart core library and generated types that cannot be
+// used by the generated classes, classes that use one of these names get the "Type" suffix.
+var reservedTypeNames = map[string]bool{
+	"ApiException": true, "BigInt": true, "Client": true, "DateTime": true, "Duration": true,
+	"Enum": true, "Error": true, "Exception": true, "Function": true, "Future": true,
+	"Iterable": true, "JsonKey": true, "JsonSerializable": true, "JsonValue": true, "List": true,
+	"Map": true, "MapEntry": true, "Never": true, "Null": true, "Object": true, "Record": true,
+	"Set": true, "Stream": true, "String": true, "Symbol": true, "Type": true,
+	"UnexpectedResponseException": true, "Uri": true,
+}
+
+// invalidNameChars matches the characters that cannot be used in Dart identifiers.
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// New builds the description of the Dart client package of the API. The package contains a
+// serializable class for each user type, media type view and payload, an enum for each string
+// attribute that declares an Enum validation and a client method returning a Future for each
+// action route.
+func New(api *design.APIDefinition, name, baseURL string) (*Package, error) {
+	b := &builder{api: api, classes: make(map[string]*Class), enums: make(map[string]*Enum)}
+	err := api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		_, err := b.className(ut)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() {
+			return nil
+		}
+		return mt.IterateViews(func(v *design.ViewDefinition) error {
+			p, _, err := mt.Project(v.Name)
+			if err != nil {
+				return err
+			}
+			_, err = b.className(p)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	p := &Package{
+		Name:        name,
+		Description: fmt.Sprintf("Client of the %s API.", api.Name),
+		Version:     pubVersion(api.Version),
+		BaseURL:     baseURL,
+	}
+	err = api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() {
+				return nil
+			}
+			for i, r := range a.Routes {
+				m, err := b.method(a, r, i)
+				if err != nil {
+					return fmt.Errorf("%s: %s", a.Context(), err)
+				}
+				p.Methods = append(p.Methods, m)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(p.Methods, func(i, j int) bool { return p.Methods[i].Name < p.Methods[j].Name })
+	for _, c := range b.classes {
+		p.Classes = append(p.Classes, c)
+	}
+	sort.Slice(p.Classes, func(i, j int) bool { return p.Classes[i].Name < p.Classes[j].Name })
+	for _, e := range b.enums {
+		p.Enums = append(p.Enums, e)
+	}
+	sort.Slice(p.Enums, func(i, j int) bool { return p.Enums[i].Name < p.Enums[j].Name })
+	return p, nil
+}
+
+// builder computes the classes, enums and methods.
+type builder struct {
+	api     *design.APIDefinition
+	classes map[string]*Class
+	enums   map[string]*Enum
+}
+
+// method builds the client method that sends requests to the i-th route of the action.
+func (b *builder) method(a *design.ActionDefinition, r *design.RouteDefinition, i int) (*Method, error) {
+	name := codegen.Goify(a.Name, false) + codegen.Goify(a.Parent.Name, true)
+	if i > 0 {
+		name += strconv.Itoa(i + 1)
+	}
+	typeName := codegen.Goify(name, true)
+	m := &Method{
+		Name:        name,
+		Description: a.Description,
+		Verb:        r.Verb,
+		Returns:     "void",
+	}
+	if m.Description == "" {
+		m.Description = fmt.Sprintf("%s calls the %s action of the %s resource.", name, a.Name, a.Parent.Name)
+	}
+
+	all := a.AllParams()
+	pathParams := r.Params()
+	interpolations := make(map[string]string)
+	for _, p := range pathParams {
+		var at *design.AttributeDefinition
+		if all != nil {
+			at = all.Type.ToObject()[p]
+		}
+		if at == nil {
+			at = &design.AttributeDefinition{Type: design.String}
+		}
+		typ, err := b.dartType(at, typeName+codegen.Goify(p, true))
+		if err != nil {
+			return nil, fmt.Errorf("parameter %#v: %s", p, err)
+		}
+		arg := &Arg{Name: identifier(p), Type: typ}
+		m.Args = append(m.Args, arg)
+		interpolations[p] = fmt.Sprintf("${_pathEscape(%s, %t)}",
+			paramString(at, arg.Name), strings.Contains(r.FullPath(), "*"+p))
+	}
+	m.Path = pathTemplate(r.FullPath(), interpolations)
+
+	addParams := func(params *design.AttributeDefinition, header bool) error {
+		if params == nil {
+			return nil
+		}
+		for _, n := range codegen.SortedNames(params.Type.ToObject()) {
+			if !header && codegen.ContainsString(pathParams, n) {
+				continue
+			}
+			at := params.Type.ToObject()[n]
+			typ, err := b.dartType(at, typeName+codegen.Goify(n, true))
+			if err != nil {
+				return fmt.Errorf("parameter %#v: %s", n, err)
+			}
+			arg := &Arg{Name: identifier(n), Type: typ}
+			required := params.IsRequired(n)
+			if required {
+				m.Args = append(m.Args, arg)
+			} else {
+				arg.Type = nullable(arg.Type)
+				m.Optional = append(m.Optional, arg)
+			}
+			var val string
+			if at.Type.IsArray() {
+				val = arg.Name
+				if elem := paramString(at.Type.ToArray().ElemType, "e"); elem != "e" {
+					val = fmt.Sprintf("[for (final e in %s) %s]", arg.Name, elem)
+				}
+				if header {
+					val += ".join(',')"
+				}
+			} else {
+				val = paramString(at, arg.Name)
+				if !header {
+					val = "[" + val + "]"
+				}
+			}
+			var stmt string
+			if header {
+				stmt = fmt.Sprintf("_headers[%s] = %s;", quote(n), val)
+			} else {
+				stmt = fmt.Sprintf("_query[%s] = %s;", quote(n), val)
+			}
+			if !required {
+				stmt = fmt.Sprintf("if (%s != null) %s", arg.Name, stmt)
+			}
+			if header {
+				m.Headers = append(m.Headers, stmt)
+			} else {
+				m.Query = append(m.Query, stmt)
+			}
+		}
+		return nil
+	}
+	if err := addParams(a.QueryParams, false); err != nil {
+		return nil, err
+	}
+	if err := addParams(a.Headers, true); err != nil {
+		return nil, err
+	}
+
+	if a.Payload != nil {
+		typ, err := b.className(a.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("payload: %s", err)
+		}
+		arg := &Arg{Name: "payload", Type: typ}
+		if a.PayloadOptional {
+			arg.Type = nullable(arg.Type)
+			m.Optional = append([]*Arg{arg}, m.Optional...)
+		} else {
+			m.Args = append(m.Args, arg)
+		}
+		switch {
+		case a.PayloadMultipart:
+			stmts, err := multipart(a.Payload, a.PayloadOptional)
+			if err != nil {
+				return nil, fmt.Errorf("payload: %s", err)
+			}
+			m.Body = stmts
+			m.Multipart = true
+		case a.PayloadOptional:
+			m.Body = []string{"final _body = payload == null ? null : jsonEncode(payload);"}
+		default:
+			m.Body = []string{"final _body = jsonEncode(payload);"}
+		}
+	}
+
+	err := a.IterateResponses(func(resp *design.ResponseDefinition) error {
+		res, err := b.response(resp, typeName)
+		if err != nil {
+			return fmt.Errorf("response %#v: %s", resp.Name, err)
+		}
+		m.Responses = append(m.Responses, res)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(m.Responses, func(i, j int) bool { return m.Responses[i].Status < m.Responses[j].Status })
+	var successes []*Response
+	bodies := make(map[string]bool)
+	for _, res := range m.Responses {
+		if res.Error {
+			m.Error = typeName + "Exception"
+			continue
+		}
+		successes = append(successes, res)
+		bodies[res.Body] = true
+	}
+	switch {
+	case len(bodies) > 1:
+		m.Result = typeName + "Result"
+		m.Returns = m.Result
+	case len(successes) > 0 && successes[0].Body != "":
+		m.Returns = successes[0].Body
+	}
+	return m, nil
+}
+
+// response builds the description of the given action response, prefix is the prefix of the
+// name of the class that describes the response.
+func (b *builder) response(r *design.ResponseDefinition, prefix string) (*Response, error) {
+	res := &Response{
+		Status: r.Status,
+		Class:  prefix + typeName(r.Name),
+		Error:  r.Status < http.StatusOK || r.Status >= http.StatusMultipleChoices,
+	}
+	if res.Error {
+		res.Class += "Exception"
+	}
+	if r.Status == http.StatusNoContent {
+		return res, nil
+	}
+	var t design.DataType
+	if r.MediaType != "" {
+		mt, ok := b.api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]
+		if !ok {
+			res.Body, res.Decode = "String", "_response.body"
+			return res, nil
+		}
+		view := r.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return nil, err
+		}
+		t = p
+	} else if r.Type != nil {
+		t = r.Type
+	}
+	if t == nil {
+		return res, nil
+	}
+	name, err := b.className(t)
+	if err != nil {
+		return nil, err
+	}
+	res.Body = name
+	res.Decode = b.decoder(t, "jsonDecode(_response.body)")
+	return res, nil
+}
+
+// className registers the class that corresponds to the given user type or media type and
+// returns its name.
+func (b *builder) className(t design.DataType) (string, error) {
+	t, err := projected(&design.AttributeDefinition{Type: t})
+	if err != nil {
+		return "", err
+	}
+	ut, ok := userType(t)
+	if !ok {
+		return "", fmt.Errorf("%s is not a user type", t.Name())
+	}
+	name := typeName(codegen.GoTypeName(t, nil, 0, false))
+	if c, ok := b.classes[name]; ok {
+		if c.DataType.Name() != t.Name() {
+			return "", fmt.Errorf("class %s is already defined", name)
+		}
+		return name, nil
+	}
+	c := &Class{Name: name, Description: ut.Description, DataType: t}
+	b.classes[name] = c
+	if !ut.Type.IsObject() {
+		alias, err := b.dartType(ut.AttributeDefinition, name+"Item")
+		if err != nil {
+			delete(b.classes, name)
+			return "", fmt.Errorf("type %s: %s", name, err)
+		}
+		c.Alias = alias
+		return name, nil
+	}
+	obj := ut.Type.ToObject()
+	for _, n := range codegen.SortedNames(obj) {
+		at := obj[n]
+		typ, err := b.dartType(at, name+codegen.Goify(n, true))
+		if err != nil {
+			delete(b.classes, name)
+			return "", fmt.Errorf("type %s: attribute %#v: %s", name, n, err)
+		}
+		field := &Field{
+			Name:        identifier(n),
+			AttName:     n,
+			Type:        typ,
+			Required:    ut.IsRequired(n),
+			Description: at.Description,
+		}
+		if !field.Required {
+			field.Type = nullable(field.Type)
+		}
+		c.Fields = append(c.Fields, field)
+	}
+	return name, nil
+}
+
+// dartType returns the Dart type of the values of the given attribute. enumName is the name of
+// the enum generated if the attribute is a string that declares an Enum validation.
+func (b *builder) dartType(at *design.AttributeDefinition, enumName string) (string, error) {
+	switch actual := at.Type.(type) {
+	case design.Primitive:
+		typ := primitiveType(actual)
+		if !isEnum(at) {
+			return typ, nil
+		}
+		return b.enum(typeName(enumName), at)
+	case *design.Array:
+		elem, err := b.dartType(actual.ElemType, enumName)
+		if err != nil {
+			return "", err
+		}
+		return "List<" + elem + ">", nil
+	case *design.Hash:
+		elem, err := b.dartType(actual.ElemType, enumName)
+		if err != nil {
+			return "", err
+		}
+		return "Map<String, " + elem + ">", nil
+	case design.Object:
+		return "Map<String, dynamic>", nil
+	case *design.MediaTypeDefinition, *design.UserTypeDefinition:
+		t, err := projected(at)
+		if err != nil {
+			return "", err
+		}
+		return b.className(t)
+	}
+	return "", fmt.Errorf("unsupported type %s", at.Type.Name())
+}
+
+// decoder returns the expression that converts the decoded JSON value v into a value of the
+// given type. The type must have been registered with className or dartType.
+func (b *builder) decoder(t design.DataType, v string) string {
+	if t, err := projected(&design.AttributeDefinition{Type: t}); err == nil {
+		if ut, ok := userType(t); ok {
+			if ut.Type.IsObject() {
+				name := typeName(codegen.GoTypeName(t, nil, 0, false))
+				return fmt.Sprintf("%s.fromJson(%s as Map<String, dynamic>)", name, v)
+			}
+			return b.attributeDecoder(ut.AttributeDefinition, typeName(codegen.GoTypeName(t, nil, 0, false))+"Item", v)
+		}
+	}
+	return b.attributeDecoder(&design.AttributeDefinition{Type: t}, "", v)
+}
+
+// attributeDecoder returns the expression that converts the decoded JSON value v into a value
+// of the given attribute, enumName is the name of the enum of the string attributes that
+// declare an Enum validation.
+func (b *builder) attributeDecoder(at *design.AttributeDefinition, enumName, v string) string {
+	switch actual := at.Type.(type) {
+	case design.Primitive:
+		if isEnum(at) {
+			return fmt.Sprintf("%s.fromValue(%s as String)", typeName(enumName), v)
+		}
+		switch primitiveType(actual) {
+		case "int":
+			return fmt.Sprintf("(%s as num).toInt()", v)
+		case "double":
+			return fmt.Sprintf("(%s as num).toDouble()", v)
+		case "dynamic":
+			return v
+		case "List<int>":
+			return fmt.Sprintf("(%s as List<dynamic>).cast<int>()", v)
+		}
+		return fmt.Sprintf("%s as %s", v, primitiveType(actual))
+	case *design.Array:
+		return fmt.Sprintf("(%s as List<dynamic>).map((e) => %s).toList()", v, b.attributeDecoder(actual.ElemType, enumName, "e"))
+	case *design.Hash:
+		return fmt.Sprintf("(%s as Map<String, dynamic>).map((k, e) => MapEntry(k, %s))", v, b.attributeDecoder(actual.ElemType, enumName, "e"))
+	case design.Object:
+		return fmt.Sprintf("%s as Map<String, dynamic>", v)
+	}
+	t, err := projected(at)
+	if err != nil {
+		return v
+	}
+	return b.decoder(t, v)
+}
+
+// enum registers the enum that lists the values of the given attribute and returns its name.
+func (b *builder) enum(name string, at *design.AttributeDefinition) (string, error) {
+	e := &Enum{Name: name, Description: at.Description}
+	used := make(map[string]bool)
+	for _, v := range at.Validation.Values {
+		val, ok := v.(string)
+		if !ok {
+			return "", fmt.Errorf("unsupported enum value %#v", v)
+		}
+		value := &EnumValue{Name: valueName(val), Value: val}
+		base := value.Name
+		for i := 2; used[value.Name]; i++ {
+			value.Name = fmt.Sprintf("%s%d", base, i)
+		}
+		used[value.Name] = true
+		e.Values = append(e.Values, value)
+	}
+	if existing, ok := b.enums[name]; ok {
+		if !sameValues(existing, e) {
+			return "", fmt.Errorf("enum %s is already defined", name)
+		}
+		return name, nil
+	}
+	b.enums[name] = e
+	return name, nil
+}
+
+// sameValues returns true if the two enums define the same values.
+func sameValues(e1, e2 *Enum) bool {
+	if len(e1.Values) != len(e2.Values) {
+		return false
+	}
+	for i, v := range e1.Values {
+		if *v != *e2.Values[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// multipart returns the statements that encode the given payload as multipart form data in
+// _form.
+func multipart(payload *design.UserTypeDefinition, optional bool) ([]string, error) {
+	if !payload.Type.IsObject() {
+		return nil, fmt.Errorf("multipart payloads must be objects")
+	}
+	form := "_form"
+	if optional {
+		form = "form"
+	}
+	var stmts []string
+	obj := payload.Type.ToObject()
+	for _, n := range codegen.SortedNames(obj) {
+		at := obj[n]
+		v := "payload." + identifier(n)
+		required := payload.IsRequired(n)
+		if !required {
+			v += "!"
+		}
+		var stmt string
+		switch {
+		case at.Type.Kind() == design.FileKind:
+			stmt = fmt.Sprintf("%s.files.add(http.MultipartFile.fromBytes(%s, %s, filename: %s));", form, quote(n), v, quote(n))
+		case at.Type.IsPrimitive():
+			stmt = fmt.Sprintf("%s.fields[%s] = %s;", form, quote(n), paramString(at, v))
+		default:
+			stmt = fmt.Sprintf("%s.fields[%s] = jsonEncode(%s);", form, quote(n), v)
+		}
+		if !required {
+			stmt = fmt.Sprintf("if (payload.%s != null) %s", identifier(n), stmt)
+		}
+		stmts = append(stmts, stmt)
+	}
+	if !optional {
+		return append([]string{"final _form = _Form();"}, stmts...), nil
+	}
+	for i, stmt := range stmts {
+		stmts[i] = "  " + stmt
+	}
+	stmts = append([]string{"_Form? _form;", "if (payload != null) {", "  final form = _form = _Form();"}, stmts...)
+	return append(stmts, "}"), nil
+}
+
+// primitiveType returns the Dart type used to represent values of the given primitive. Date
+// times, UUIDs and decimals are represented with their string representation.
+func primitiveType(p design.Primitive) string {
+	switch p.Kind() {
+	case design.BooleanKind:
+		return "bool"
+	case design.IntegerKind:
+		return "int"
+	case design.NumberKind:
+		return "double"
+	case design.StringKind, design.DateTimeKind, design.UUIDKind, design.DecimalKind:
+		return "String"
+	case design.FileKind:
+		return "List<int>"
+	case design.AnyKind:
+		return "dynamic"
+	}
+	if c := design.CustomPrimitive(p); c != nil {
+		switch c.JSONType {
+		case "integer":
+			return "int"
+		case "number":
+			return "double"
+		case "boolean":
+			return "bool"
+		}
+		return "String"
+	}
+	return "dynamic"
+}
+
+// nullable returns the nullable version of the given type, dynamic is already nullable.
+func nullable(typ string) string {
+	if typ == "dynamic" {
+		return typ
+	}
+	return typ + "?"
+}
+
+// isEnum returns true if the attribute is a string that declares an Enum validation.
+func isEnum(at *design.AttributeDefinition) bool {
+	return at.Type.Kind() == design.StringKind && at.Validation != nil && len(at.Validation.Values) > 0
+}
+
+// paramString returns the expression that converts the value v of the given attribute into
+// its string representation.
+func paramString(at *design.AttributeDefinition, v string) string {
+	if isEnum(at) {
+		return v + ".value"
+	}
+	if at.Type.Kind() == design.StringKind {
+		return v
+	}
+	return v + ".toString()"
+}
+
+// projected returns the type of the attribute, media types are projected using the view of the
+// attribute or the default view.
+func projected(at *design.AttributeDefinition) (design.DataType, error) {
+	mt, ok := at.Type.(*design.MediaTypeDefinition)
+	if !ok || strings.Contains(mt.Identifier, "view=") {
+		return at.Type, nil
+	}
+	view := at.View
+	if view == "" {
+		view = design.DefaultView
+	}
+	p, _, err := mt.Project(view)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// userType returns the user type definition of the given user type or media type.
+func userType(t design.DataType) (*design.UserTypeDefinition, bool) {
+	switch actual := t.(type) {
+	case *design.MediaTypeDefinition:
+		return actual.UserTypeDefinition, true
+	case *design.UserTypeDefinition:
+		return actual, true
+	}
+	return nil, false
+}
+
+// identifier returns the lower camel case Dart identifier that corresponds to the given name.
+// Names starting with a digit get the "value" prefix since a leading underscore would make the
+// identifier private.
+func identifier(n string) string {
+	name := codegen.Goify(invalidNameChars.ReplaceAllString(n, "_"), false)
+	name = strings.TrimLeft(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "value" + name
+	}
+	if reservedWords[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// valueName returns the lower camel case name of the enum value that corresponds to the given
+// serialized value.
+func valueName(v string) string {
+	name := identifier(v)
+	if reservedValueNames[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// typeName returns the Dart type name that corresponds to the given name.
+func typeName(n string) string {
+	name := invalidNameChars.ReplaceAllString(codegen.Goify(n, true), "")
+	name = strings.TrimLeft(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "Type" + name
+	}
+	if reservedTypeNames[name] {
+		name += "Type"
+	}
+	return name
+}
+
+// pubVersionRegex matches the versions that can be used as is in a pubspec.
+var pubVersionRegex = regexp.MustCompile(`^\d+\.\d+\.\d+([-+][0-9A-Za-z.-]+)?$`)
+
+// pubVersion returns the semantic version of the package that corresponds to the API version.
+func pubVersion(v string) string {
+	v = strings.TrimPrefix(v, "v")
+	if pubVersionRegex.MatchString(v) {
+		return v
+	}
+	if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+		return fmt.Sprintf("%d.0.0", n)
+	}
+	return "1.0.0"
+}
+
+// pathTemplate returns the Dart string literal that builds the given route path, interpolations
+// maps the names of the path parameters to the expression that replaces them.
+func pathTemplate(path string, interpolations map[string]string) string {
+	var b strings.Builder
+	b.WriteString(`'`)
+	last := 0
+	for _, loc := range codegen.PathParamRegex.FindAllStringSubmatchIndex(path, -1) {
+		b.WriteString(escape(path[last:loc[0]]))
+		if interpolation, ok := interpolations[path[loc[2]:loc[3]]]; ok {
+			b.WriteString(interpolation)
+		} else {
+			b.WriteString(escape(path[loc[0]:loc[1]]))
+		}
+		last = loc[1]
+	}
+	b.WriteString(escape(path[last:]))
+	b.WriteString(`'`)
+	return b.String()
+}
+
+// quote returns the Dart string literal of s.
+func quote(s string) string {
+	return `'` + escape(s) + `'`
+}
+
+// escape escapes the characters of s that cannot appear as is in a single quoted Dart string
+// literal.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\'', '\\', '$':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u{%x}`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
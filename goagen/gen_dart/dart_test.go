@@ -0,0 +1,197 @@
+package gendart_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	gendart "github.com/kyokomi/goa-v1/goagen/gen_dart"
+)
+
+var _ = Describe("New", func() {
+	var pkg *gendart.Package
+	var newErr error
+
+	BeforeEach(func() {
+		pkg = nil
+		newErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		pkg, newErr = gendart.New(Design, "cellar_client", "https://api.example.com")
+	})
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			winery := apidsl.Type("Winery", func() {
+				apidsl.Attribute("name", String)
+				apidsl.Required("name")
+			})
+			bottle := apidsl.MediaType("application/vnd.bottle", func() {
+				apidsl.Description("A bottle of wine")
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer, "ID of bottle")
+					apidsl.Attribute("color", String, func() {
+						apidsl.Enum("red", "white", "rosé", "2nd")
+					})
+					apidsl.Attribute("winery", winery)
+					apidsl.Attribute("created_at", DateTime)
+					apidsl.Required("id", "color")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("color")
+					apidsl.Attribute("winery")
+					apidsl.Attribute("created_at")
+				})
+			})
+			apidsl.API("cellar", func() {
+				apidsl.BasePath("/api")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Description("Get bottle by id")
+					apidsl.Routing(apidsl.GET("/:bottleID"))
+					apidsl.Params(func() {
+						apidsl.Param("bottleID", Integer)
+						apidsl.Param("fields", apidsl.ArrayOf(String))
+					})
+					apidsl.Headers(func() {
+						apidsl.Header("X-Request-Id")
+					})
+					apidsl.Response(OK, bottle)
+					apidsl.Response(NotFound)
+					apidsl.Response(BadRequest, ErrorMedia)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(func() {
+						apidsl.Member("name", String)
+						apidsl.Member("winery", winery)
+						apidsl.Required("name")
+					})
+					apidsl.Response(Created, bottle)
+					apidsl.Response(NoContent)
+				})
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET(""))
+					apidsl.Response(OK, apidsl.CollectionOf(bottle))
+				})
+				apidsl.Action("upload", func() {
+					apidsl.Routing(apidsl.POST("/:bottleID/label"))
+					apidsl.MultipartForm()
+					apidsl.Payload(func() {
+						apidsl.Member("label", File)
+						apidsl.Member("note", String)
+						apidsl.Required("label")
+					})
+					apidsl.Response(NoContent)
+				})
+			})
+		})
+
+		It("generates the classes", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			var names []string
+			for _, c := range pkg.Classes {
+				names = append(names, c.Name)
+			}
+			Ω(names).Should(Equal([]string{"Bottle", "BottleCollection", "CreateBottlePayload", "ErrorType", "UploadBottlePayload", "Winery"}))
+			bottle := pkg.Classes[0]
+			Ω(bottle.Parameters()).Should(Equal("{required this.color, this.createdAt, required this.id, this.winery}"))
+			Ω(bottle.Fields).Should(HaveLen(4))
+			Ω(bottle.Fields[0].Declaration()).Should(Equal("final BottleColor color;"))
+			Ω(bottle.Fields[1].Declaration()).Should(Equal("@JsonKey(name: 'created_at')\n  final String? createdAt;"))
+			Ω(bottle.Fields[2].Type).Should(Equal("int"))
+			Ω(bottle.Fields[3].Type).Should(Equal("Winery?"))
+			Ω(pkg.Classes[1].Alias).Should(Equal("List<Bottle>"))
+			Ω(pkg.Classes[4].Fields[0].Type).Should(Equal("List<int>"))
+		})
+
+		It("generates the enums", func() {
+			Ω(pkg.Enums).Should(HaveLen(1))
+			color := pkg.Enums[0]
+			Ω(color.Name).Should(Equal("BottleColor"))
+			var values []string
+			for _, v := range color.Values {
+				values = append(values, v.Name+" = "+v.Value)
+			}
+			Ω(values).Should(Equal([]string{"red = red", "white = white", "ros = rosé", "value2nd = 2nd"}))
+		})
+
+		It("generates the methods", func() {
+			Ω(pkg.Methods).Should(HaveLen(4))
+			create := pkg.Methods[0]
+			Ω(create.Name).Should(Equal("createBottle"))
+			Ω(create.Signature()).Should(Equal("CreateBottlePayload payload"))
+			Ω(create.Body).Should(Equal([]string{"final _body = jsonEncode(payload);"}))
+			Ω(create.Returns).Should(Equal("CreateBottleResult"))
+			Ω(create.Error).Should(BeEmpty())
+			Ω(create.Handle(create.Responses[0])).Should(Equal("return CreateBottleCreated(Bottle.fromJson(jsonDecode(_response.body) as Map<String, dynamic>));"))
+			Ω(create.Handle(create.Responses[1])).Should(Equal("return const CreateBottleNoContent();"))
+			list := pkg.Methods[1]
+			Ω(list.Returns).Should(Equal("BottleCollection"))
+			Ω(list.Handle(list.Responses[0])).Should(Equal("return (jsonDecode(_response.body) as List<dynamic>).map((e) => Bottle.fromJson(e as Map<String, dynamic>)).toList();"))
+			show := pkg.Methods[2]
+			Ω(show.Name).Should(Equal("showBottle"))
+			Ω(show.Path).Should(Equal(`'/api/bottles/${_pathEscape(bottleID.toString(), false)}'`))
+			Ω(show.Signature()).Should(Equal("int bottleID, {List<String>? fields, String? xRequestID}"))
+			Ω(show.Query).Should(Equal([]string{`if (fields != null) _query['fields'] = fields;`}))
+			Ω(show.Headers).Should(Equal([]string{`if (xRequestID != null) _headers['X-Request-Id'] = xRequestID;`}))
+			Ω(show.Returns).Should(Equal("Bottle"))
+			Ω(show.Error).Should(Equal("ShowBottleException"))
+			Ω(show.Responses).Should(HaveLen(3))
+			Ω(show.Handle(show.Responses[0])).Should(Equal("return Bottle.fromJson(jsonDecode(_response.body) as Map<String, dynamic>);"))
+			Ω(show.Handle(show.Responses[1])).Should(Equal("throw ShowBottleBadRequestException(ErrorType.fromJson(jsonDecode(_response.body) as Map<String, dynamic>));"))
+			Ω(show.Handle(show.Responses[2])).Should(Equal("throw ShowBottleNotFoundException();"))
+			upload := pkg.Methods[3]
+			Ω(upload.Multipart).Should(BeTrue())
+			Ω(upload.Returns).Should(Equal("void"))
+			Ω(upload.Body).Should(Equal([]string{
+				"final _form = _Form();",
+				"_form.files.add(http.MultipartFile.fromBytes('label', payload.label, filename: 'label'));",
+				"if (payload.note != null) _form.fields['note'] = payload.note!;",
+			}))
+			Ω(upload.Handle(upload.Responses[0])).Should(Equal("return;"))
+		})
+
+		It("renders the files", func() {
+			Ω(pkg.Files()).Should(Equal([]string{"pubspec.yaml", "lib/cellar_client.dart", "lib/src/models.dart", "lib/src/client.dart"}))
+			pubspec, err := pkg.Write("pubspec.yaml", "cellar: Dart Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(pubspec)).Should(ContainSubstring("name: cellar_client\n"))
+			Ω(string(pubspec)).Should(ContainSubstring("  json_serializable: ^6.7.1\n"))
+			library, err := pkg.Write("lib/cellar_client.dart", "cellar: Dart Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(library)).Should(ContainSubstring("export 'src/models.dart';\n"))
+			models, err := pkg.Write("lib/src/models.dart", "cellar: Dart Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(models)).Should(ContainSubstring("part 'models.g.dart';\n"))
+			Ω(string(models)).Should(ContainSubstring("enum BottleColor {\n  @JsonValue('red')\n  red('red'),\n"))
+			Ω(string(models)).Should(ContainSubstring("  value2nd('2nd');\n"))
+			Ω(string(models)).Should(ContainSubstring("/// A bottle of wine (default view)\n@JsonSerializable(includeIfNull: false)\nclass Bottle {\n"))
+			Ω(string(models)).Should(ContainSubstring("  factory Bottle.fromJson(Map<String, dynamic> json) => _$BottleFromJson(json);\n"))
+			Ω(string(models)).Should(ContainSubstring("  /// ID of bottle\n  final int id;\n"))
+			Ω(string(models)).Should(ContainSubstring("typedef BottleCollection = List<Bottle>;\n"))
+			client, err := pkg.Write("lib/src/client.dart", "cellar: Dart Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(client)).Should(ContainSubstring("  static const defaultBaseUrl = 'https://api.example.com';\n"))
+			Ω(string(client)).Should(ContainSubstring("  Future<Bottle> showBottle(int bottleID, {List<String>? fields, String? xRequestID}) async {\n"))
+			Ω(string(client)).Should(ContainSubstring("    final _response = await _send('POST', '/api/bottles', _query, _headers, body: _body);\n"))
+			Ω(string(client)).Should(ContainSubstring("      case 404:\n        throw ShowBottleNotFoundException();\n"))
+			Ω(string(client)).Should(ContainSubstring("sealed class ShowBottleException extends ApiException {\n"))
+			Ω(string(client)).Should(ContainSubstring("class ShowBottleBadRequestException extends ShowBottleException {\n  ShowBottleBadRequestException(this.body) : super(400);\n"))
+			Ω(string(client)).Should(ContainSubstring("class CreateBottleNoContent extends CreateBottleResult {\n  const CreateBottleNoContent();\n}\n"))
+			Ω(string(client)).Should(ContainSubstring("class _Form {\n"))
+			_, err = pkg.Write("lib/src/other.dart", "cellar: Dart Client")
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
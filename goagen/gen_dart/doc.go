@@ -0,0 +1,20 @@
+/*
+Package gendart provides a generator for a Dart client of the API targeting Flutter and the Dart
+VM. The generator is invoked with "goagen client --language=dart" and produces a pub package
+that contains:
+
+  - pubspec.yaml: the package description that declares the http and json_annotation
+    dependencies and the build_runner and json_serializable development dependencies,
+  - lib/<package>.dart: the library that exports the package sources,
+  - lib/src/models.dart: a json_serializable class for each user type, media type view and
+    payload and an enum for each string attribute that declares an Enum validation,
+  - lib/src/client.dart: a Client class with one method returning a Future per action route.
+
+The serialization code of the classes is generated in lib/src/models.g.dart by running
+"dart run build_runner build" in the package directory. The methods complete with the decoded
+body of the successful responses or with a sealed result class when the successful responses
+have different bodies. The declared error responses are thrown as the subclasses of a sealed
+exception class generated for each method, the statuses that are not described in the design
+are thrown as UnexpectedResponseException.
+*/
+package gendart
@@ -0,0 +1,13 @@
+package gendart_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenDart(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenDart Suite")
+}
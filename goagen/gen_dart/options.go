@@ -0,0 +1,27 @@
+package gendart
+
+import "github.com/kyokomi/goa-v1/design"
+
+// Option a generator option definition
+type Option func(*Generator)
+
+// API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+// OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+// Target Name of generated Dart package
+func Target(target string) Option {
+	return func(g *Generator) {
+		g.Target = target
+	}
+}
@@ -0,0 +1,405 @@
+package gendart
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1/version"
+)
+
+// Files returns the paths of the generated files relative to the package directory in order of
+// generation.
+func (p *Package) Files() []string {
+	return []string{
+		"pubspec.yaml",
+		filepath.Join("lib", p.Name+".dart"),
+		filepath.Join("lib", "src", "models.dart"),
+		filepath.Join("lib", "src", "client.dart"),
+	}
+}
+
+// templates maps the generated files other than the library file to their template.
+var templates = map[string]*template.Template{
+	"pubspec.yaml": newTemplate("pubspec", pubspecT),
+	filepath.Join("lib", "src", "models.dart"): newTemplate("models", modelsT),
+	filepath.Join("lib", "src", "client.dart"): newTemplate("client", clientT),
+}
+
+// libraryTmpl is the template of the library file that exports the package sources.
+var libraryTmpl = newTemplate("library", libraryT)
+
+// newTemplate parses the given template text.
+func newTemplate(name, text string) *template.Template {
+	return template.Must(template.New(name).Funcs(template.FuncMap{
+		"dartdoc": dartdoc,
+		"quote":   quote,
+	}).Parse(text))
+}
+
+// Write renders the file with the given path, title is written in the header comment.
+func (p *Package) Write(file, title string) ([]byte, error) {
+	tmpl, ok := templates[file]
+	if file == filepath.Join("lib", p.Name+".dart") {
+		tmpl, ok = libraryTmpl, true
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown file %s", file)
+	}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"Title":       title,
+		"ToolVersion": version.String(),
+		"Package":     p,
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// HasSerializable returns true if the package defines classes that json_serializable generates
+// the serialization code of.
+func (p *Package) HasSerializable() bool {
+	for _, c := range p.Classes {
+		if c.Alias == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasMultipart returns true if one of the methods sends multipart form data.
+func (p *Package) HasMultipart() bool {
+	for _, m := range p.Methods {
+		if m.Multipart {
+			return true
+		}
+	}
+	return false
+}
+
+// Terminator returns the punctuation that follows the i-th value of the enum.
+func (e *Enum) Terminator(i int) string {
+	if i == len(e.Values)-1 {
+		return ";"
+	}
+	return ","
+}
+
+// Parameters returns the named parameters of the class constructor.
+func (c *Class) Parameters() string {
+	if len(c.Fields) == 0 {
+		return ""
+	}
+	params := make([]string, len(c.Fields))
+	for i, f := range c.Fields {
+		params[i] = "this." + f.Name
+		if f.Required {
+			params[i] = "required " + params[i]
+		}
+	}
+	return "{" + strings.Join(params, ", ") + "}"
+}
+
+// Declaration returns the declaration of the field.
+func (f *Field) Declaration() string {
+	decl := fmt.Sprintf("final %s %s;", f.Type, f.Name)
+	if f.Name != f.AttName {
+		decl = fmt.Sprintf("@JsonKey(name: %s)\n  %s", quote(f.AttName), decl)
+	}
+	return decl
+}
+
+// Signature returns the list of arguments of the method, the optional arguments are named.
+func (m *Method) Signature() string {
+	args := make([]string, len(m.Args))
+	for i, a := range m.Args {
+		args[i] = a.Type + " " + a.Name
+	}
+	if len(m.Optional) > 0 {
+		opts := make([]string, len(m.Optional))
+		for i, a := range m.Optional {
+			opts[i] = a.Type + " " + a.Name
+		}
+		args = append(args, "{"+strings.Join(opts, ", ")+"}")
+	}
+	return strings.Join(args, ", ")
+}
+
+// Doc returns the documentation comment of the method, it lists the exceptions thrown by the
+// method.
+func (m *Method) Doc() string {
+	text := m.Description + "\n\nThrows "
+	if m.Error != "" {
+		text += "[" + m.Error + "] for the declared error responses and\n"
+	}
+	text += "[UnexpectedResponseException] for the statuses that are not described in the design."
+	return dartdoc("  ", text)
+}
+
+// Handle returns the statement of the switch case that handles the given response.
+func (m *Method) Handle(r *Response) string {
+	switch {
+	case r.Error && r.Decode == "":
+		return fmt.Sprintf("throw %s();", r.Class)
+	case r.Error:
+		return fmt.Sprintf("throw %s(%s);", r.Class, r.Decode)
+	case m.Result != "" && r.Decode == "":
+		return fmt.Sprintf("return const %s();", r.Class)
+	case m.Result != "":
+		return fmt.Sprintf("return %s(%s);", r.Class, r.Decode)
+	case r.Decode == "":
+		return "return;"
+	}
+	return "return " + r.Decode + ";"
+}
+
+// Successes returns the 2xx responses of the method.
+func (m *Method) Successes() []*Response {
+	var res []*Response
+	for _, r := range m.Responses {
+		if !r.Error {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// Errors returns the non 2xx responses of the method.
+func (m *Method) Errors() []*Response {
+	var res []*Response
+	for _, r := range m.Responses {
+		if r.Error {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// StatusText returns the text of the response status.
+func (r *Response) StatusText() string {
+	return fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status))
+}
+
+// dartdoc renders the given text as a Dart documentation comment followed by a new line, indent
+// is prepended to each line.
+func dartdoc(indent, text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(indent+"/// "+l, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+const headerT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .Title }}
+`
+
+const pubspecT = `# Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+#
+# {{ .Title }}
+
+name: {{ .Package.Name }}
+description: {{ printf "%q" .Package.Description }}
+version: {{ .Package.Version }}
+
+environment:
+  sdk: ">=3.0.0 <4.0.0"
+
+dependencies:
+  http: ^1.1.0
+  json_annotation: ^4.8.1
+
+dev_dependencies:
+  build_runner: ^2.4.6
+  json_serializable: ^6.7.1
+`
+
+const libraryT = headerT + `
+{{ dartdoc "" .Package.Description }}library;
+
+export 'src/client.dart';
+export 'src/models.dart';
+`
+
+const modelsT = headerT + `
+import 'package:json_annotation/json_annotation.dart';
+{{ if .Package.HasSerializable }}
+part 'models.g.dart';
+{{ end }}{{ range $e := .Package.Enums }}
+{{ dartdoc "" .Description }}enum {{ .Name }} {
+{{ range $i, $v := .Values }}  @JsonValue({{ quote .Value }})
+  {{ .Name }}({{ quote .Value }}){{ $e.Terminator $i }}
+{{ end }}
+  const {{ .Name }}(this.value);
+
+  /// value is the serialized value.
+  final String value;
+
+  /// fromValue returns the {{ .Name }} with the given serialized value.
+  static {{ .Name }} fromValue(String value) =>
+      values.firstWhere((e) => e.value == value, orElse: () => throw ArgumentError.value(value, 'value', 'unknown {{ .Name }}'));
+
+  /// toJson returns the serialized value.
+  String toJson() => value;
+}
+{{ end }}{{ range .Package.Classes }}{{ if .Alias }}
+{{ dartdoc "" .Description }}typedef {{ .Name }} = {{ .Alias }};
+{{ else }}
+{{ dartdoc "" .Description }}@JsonSerializable(includeIfNull: false)
+class {{ .Name }} {
+  const {{ .Name }}({{ .Parameters }});
+
+  /// fromJson creates the {{ .Name }} described by the given JSON representation.
+  factory {{ .Name }}.fromJson(Map<String, dynamic> json) => _${{ .Name }}FromJson(json);
+{{ range .Fields }}
+{{ dartdoc "  " .Description }}  {{ .Declaration }}
+{{ end }}
+  /// toJson returns the JSON representation of the {{ .Name }}.
+  Map<String, dynamic> toJson() => _${{ .Name }}ToJson(this);
+}
+{{ end }}{{ end }}`
+
+const clientT = headerT + `
+// ignore_for_file: no_leading_underscores_for_local_identifiers
+
+import 'dart:convert';
+
+import 'package:http/http.dart' as http;
+
+import 'models.dart';
+
+/// ApiException is the base class of the exceptions thrown when the API returns an error response.
+class ApiException implements Exception {
+  ApiException(this.status);
+
+  /// status is the HTTP status code of the response.
+  final int status;
+
+  @override
+  String toString() => '$runtimeType: HTTP $status';
+}
+
+/// UnexpectedResponseException is thrown when the API returns a status that is not described in the design.
+class UnexpectedResponseException extends ApiException {
+  UnexpectedResponseException(super.status, this.body);
+
+  /// body is the response body.
+  final String body;
+}
+
+/// Client gives access to the API.
+///
+/// baseUrl is prepended to the request paths, httpClient sends the requests and headers are
+/// sent with every request.
+class Client {
+  Client({this.baseUrl = defaultBaseUrl, http.Client? httpClient, this.headers = const {}})
+      : _httpClient = httpClient ?? http.Client();
+
+  /// defaultBaseUrl is the base URL used when none is given.
+  static const defaultBaseUrl = {{ quote .Package.BaseURL }};
+
+  /// baseUrl is prepended to the request paths.
+  final String baseUrl;
+
+  /// headers are sent with every request.
+  final Map<String, String> headers;
+
+  final http.Client _httpClient;
+
+  /// close closes the HTTP client.
+  void close() => _httpClient.close();
+{{ range $m := .Package.Methods }}
+{{ .Doc }}  Future<{{ .Returns }}> {{ .Name }}({{ .Signature }}) async {
+    final _query = <String, List<String>>{};
+{{ range .Query }}    {{ . }}
+{{ end }}    final _headers = <String, String>{};
+{{ range .Headers }}    {{ . }}
+{{ end }}{{ range .Body }}    {{ . }}
+{{ end }}    final _response = await _send({{ quote .Verb }}, {{ .Path }}, _query, _headers{{ if .Multipart }}, form: _form{{ else if .Body }}, body: _body{{ end }});
+    switch (_response.statusCode) {
+{{ range .Responses }}      case {{ .Status }}:
+        {{ $m.Handle . }}
+{{ end }}      default:
+        throw UnexpectedResponseException(_response.statusCode, _response.body);
+    }
+  }
+{{ end }}
+  Future<http.Response> _send(String method, String path, Map<String, List<String>> query, Map<String, String> headers,
+      {String? body{{ if .Package.HasMultipart }}, _Form? form{{ end }}}) async {
+    final base = baseUrl.endsWith('/') ? baseUrl.substring(0, baseUrl.length - 1) : baseUrl;
+    var url = Uri.parse(base + path);
+    if (query.isNotEmpty) {
+      url = url.replace(queryParameters: {...url.queryParametersAll, ...query});
+    }
+{{ if .Package.HasMultipart }}    final http.BaseRequest request;
+    if (form != null) {
+      request = http.MultipartRequest(method, url)
+        ..fields.addAll(form.fields)
+        ..files.addAll(form.files);
+    } else {
+      final req = http.Request(method, url);
+      if (body != null) {
+        req.headers['Content-Type'] = 'application/json';
+        req.body = body;
+      }
+      request = req;
+    }
+{{ else }}    final request = http.Request(method, url);
+    if (body != null) {
+      request.headers['Content-Type'] = 'application/json';
+      request.body = body;
+    }
+{{ end }}    request.headers.addAll(this.headers);
+    request.headers.addAll(headers);
+    return http.Response.fromStream(await _httpClient.send(request));
+  }
+}
+{{ range $m := .Package.Methods }}{{ if .Result }}
+/// {{ .Result }} lists the successful responses of {{ .Name }}.
+sealed class {{ .Result }} {
+  const {{ .Result }}();
+}
+{{ range .Successes }}
+/// {{ .Class }} describes the {{ .StatusText }} response.
+class {{ .Class }} extends {{ $m.Result }} {
+{{ if .Body }}  const {{ .Class }}(this.body);
+
+  /// body is the decoded response body.
+  final {{ .Body }} body;
+{{ else }}  const {{ .Class }}();
+{{ end }}}
+{{ end }}{{ end }}{{ if .Error }}
+/// {{ .Error }} lists the error responses of {{ .Name }}.
+sealed class {{ .Error }} extends ApiException {
+  {{ .Error }}(super.status);
+}
+{{ range .Errors }}
+/// {{ .Class }} is thrown when the API returns the {{ .StatusText }} response.
+class {{ .Class }} extends {{ $m.Error }} {
+{{ if .Body }}  {{ .Class }}(this.body) : super({{ .Status }});
+
+  /// body is the decoded response body.
+  final {{ .Body }} body;
+{{ else }}  {{ .Class }}() : super({{ .Status }});
+{{ end }}}
+{{ end }}{{ end }}{{ end }}{{ if .Package.HasMultipart }}
+class _Form {
+  final fields = <String, String>{};
+  final files = <http.MultipartFile>[];
+}
+{{ end }}
+String _pathEscape(String value, bool keepSlashes) {
+  final escaped = Uri.encodeComponent(value);
+  return keepSlashes ? escaped.replaceAll('%2F', '/') : escaped;
+}
+`
@@ -0,0 +1,307 @@
+package gendiff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+)
+
+// Change describes a difference between two snapshots.
+type Change struct {
+	// Breaking is true if the change may break existing clients.
+	Breaking bool
+	// Endpoint is the name of the endpoint the change applies to.
+	Endpoint string
+	// Message describes the change.
+	Message string
+}
+
+// differ accumulates the changes found while comparing two snapshots.
+type differ struct {
+	changes  []*Change
+	endpoint string
+}
+
+// Diff returns the changes made to the contract described by old to obtain the contract
+// described by snap, sorted by endpoint. Changes to requests are breaking if they restrict the
+// accepted values (new required field, narrowed enum, stricter validation) while changes to
+// responses are breaking if they extend the values clients may receive (removed field, widened
+// enum, field no longer required).
+func Diff(old, snap *Snapshot) []*Change {
+	d := &differ{}
+	for _, name := range endpointNames(old, snap) {
+		d.endpoint = name
+		o, n := old.Endpoints[name], snap.Endpoints[name]
+		switch {
+		case n == nil:
+			d.add(true, "removed endpoint")
+		case o == nil:
+			d.add(false, "added endpoint")
+		default:
+			d.endpoints(o, n)
+		}
+	}
+	return d.changes
+}
+
+// endpoints compares the given versions of an endpoint.
+func (d *differ) endpoints(o, n *Endpoint) {
+	oldRoutes, newRoutes := routeKeys(o.Routes), routeKeys(n.Routes)
+	for _, r := range o.Routes {
+		if !newRoutes[routeKey(r)] {
+			d.add(true, "removed route %s", r)
+		}
+	}
+	for _, r := range n.Routes {
+		if !oldRoutes[routeKey(r)] {
+			d.add(false, "added route %s", r)
+		}
+	}
+	d.attributeMaps("param", o.Params, n.Params, true)
+	d.attributeMaps("header", o.Headers, n.Headers, true)
+	switch {
+	case o.Payload == nil && n.Payload != nil:
+		d.add(n.Payload.Required, "added %s payload", requiredness(n.Payload))
+	case o.Payload != nil && n.Payload == nil:
+		d.add(true, "removed payload")
+	case o.Payload != nil:
+		d.attributes("payload", o.Payload, n.Payload, true)
+	}
+	for _, s := range sortedKeys(o.Responses, n.Responses) {
+		or, nr := o.Responses[s], n.Responses[s]
+		switch {
+		case nr == nil:
+			d.add(true, "removed response %s", s)
+		case or == nil:
+			d.add(false, "added response %s", s)
+		default:
+			ctx := "response " + s
+			if design.CanonicalIdentifier(or.MediaType) != design.CanonicalIdentifier(nr.MediaType) {
+				d.add(true, "%s: media type changed from %#v to %#v", ctx, or.MediaType, nr.MediaType)
+			}
+			d.attributeMaps(ctx+" header", or.Headers, nr.Headers, false)
+			switch {
+			case or.Body == nil && nr.Body != nil:
+				d.add(false, "%s: added body", ctx)
+			case or.Body != nil && nr.Body == nil:
+				d.add(true, "%s: removed body", ctx)
+			case or.Body != nil:
+				d.attributes(ctx+" body", or.Body, nr.Body, false)
+			}
+		}
+	}
+}
+
+// attributeMaps compares the given parameters, headers or object fields. request is true if the
+// attributes are part of the request.
+func (d *differ) attributeMaps(ctx string, o, n map[string]*Attribute, request bool) {
+	for _, name := range sortedKeys(o, n) {
+		oa, na := o[name], n[name]
+		c := fmt.Sprintf("%s %s", ctx, name)
+		switch {
+		case na == nil:
+			// Servers ignore unknown request fields, clients may rely on response fields.
+			d.add(!request, "removed %s", c)
+		case oa == nil:
+			d.add(request && na.Required, "added %s %s", requiredness(na), c)
+		default:
+			d.attributes(c, oa, na, request)
+		}
+	}
+}
+
+// attributes compares the given versions of an attribute.
+func (d *differ) attributes(ctx string, o, n *Attribute, request bool) {
+	if o.Type != n.Type {
+		d.add(true, "%s: type changed from %s to %s", ctx, o.Type, n.Type)
+		return
+	}
+	if o.Required != n.Required {
+		d.restrict(request, n.Required, "%s: %s", ctx, map[bool]string{true: "now required", false: "no longer required"}[n.Required])
+	}
+	d.enums(ctx, o.Enum, n.Enum, request)
+	if o.Format != n.Format {
+		d.restrict(request, n.Format != "", "%s: format changed from %#v to %#v", ctx, o.Format, n.Format)
+	}
+	if o.Pattern != n.Pattern {
+		d.restrict(request, n.Pattern != "", "%s: pattern changed from %#v to %#v", ctx, o.Pattern, n.Pattern)
+	}
+	d.bound(ctx, "minimum", o.Minimum, n.Minimum, request, true)
+	d.bound(ctx, "maximum", o.Maximum, n.Maximum, request, false)
+	d.bound(ctx, "minimum length", intVal(o.MinLength), intVal(n.MinLength), request, true)
+	d.bound(ctx, "maximum length", intVal(o.MaxLength), intVal(n.MaxLength), request, false)
+	if o.Recursive || n.Recursive {
+		return
+	}
+	d.attributeMaps(ctx+" field", o.Attributes, n.Attributes, request)
+	if o.Key != nil && n.Key != nil {
+		d.attributes(ctx+" key", o.Key, n.Key, request)
+	}
+	if o.Elem != nil && n.Elem != nil {
+		d.attributes(ctx+" element", o.Elem, n.Elem, request)
+	}
+}
+
+// enums compares the given enum validations.
+func (d *differ) enums(ctx string, o, n []string, request bool) {
+	switch {
+	case len(o) == 0 && len(n) == 0:
+		return
+	case len(o) == 0:
+		d.restrict(request, true, "%s: added enum %s", ctx, strings.Join(n, ", "))
+		return
+	case len(n) == 0:
+		d.restrict(request, false, "%s: removed enum", ctx)
+		return
+	}
+	if removed := missing(o, n); len(removed) > 0 {
+		d.restrict(request, true, "%s: narrowed enum, removed %s", ctx, strings.Join(removed, ", "))
+	}
+	if added := missing(n, o); len(added) > 0 {
+		d.restrict(request, false, "%s: widened enum, added %s", ctx, strings.Join(added, ", "))
+	}
+}
+
+// bound compares the given minimum (lower is true) or maximum validations.
+func (d *differ) bound(ctx, name string, o, n *float64, request, lower bool) {
+	switch {
+	case o == nil && n == nil:
+	case o == nil:
+		d.restrict(request, true, "%s: added %s %v", ctx, name, *n)
+	case n == nil:
+		d.restrict(request, false, "%s: removed %s %v", ctx, name, *o)
+	case *o != *n:
+		d.restrict(request, (*n > *o) == lower, "%s: %s changed from %v to %v", ctx, name, *o, *n)
+	}
+}
+
+// restrict records a change that restricts (restricts is true) or extends the set of accepted
+// values. Restricting requests and extending responses break clients.
+func (d *differ) restrict(request, restricts bool, format string, args ...interface{}) {
+	d.add(request == restricts, format, args...)
+}
+
+// add records a change of the current endpoint.
+func (d *differ) add(breaking bool, format string, args ...interface{}) {
+	d.changes = append(d.changes, &Change{
+		Breaking: breaking,
+		Endpoint: d.endpoint,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Report returns the text report of the changes, breaking changes are listed first.
+func Report(changes []*Change) string {
+	if len(changes) == 0 {
+		return "No changes"
+	}
+	var breaking, compatible []string
+	for _, c := range changes {
+		line := fmt.Sprintf("  - %s: %s", c.Endpoint, c.Message)
+		if c.Breaking {
+			breaking = append(breaking, line)
+		} else {
+			compatible = append(compatible, line)
+		}
+	}
+	var sections []string
+	if len(breaking) > 0 {
+		sections = append(sections, fmt.Sprintf("Breaking changes (%d):\n%s", len(breaking), strings.Join(breaking, "\n")))
+	}
+	if len(compatible) > 0 {
+		sections = append(sections, fmt.Sprintf("Compatible changes (%d):\n%s", len(compatible), strings.Join(compatible, "\n")))
+	}
+	return strings.Join(sections, "\n")
+}
+
+// requiredness returns "required" or "optional".
+func requiredness(a *Attribute) string {
+	if a.Required {
+		return "required"
+	}
+	return "optional"
+}
+
+// routeKey returns the route with the wildcard names removed so that renaming a path parameter
+// is not reported as a change of route.
+func routeKey(route string) string {
+	return design.WildcardRegex.ReplaceAllStringFunc(route, func(w string) string { return w[:2] })
+}
+
+// routeKeys returns the set of keys of the given routes.
+func routeKeys(routes []string) map[string]bool {
+	keys := make(map[string]bool, len(routes))
+	for _, r := range routes {
+		keys[routeKey(r)] = true
+	}
+	return keys
+}
+
+// missing returns the values of vals that are not in others.
+func missing(vals, others []string) []string {
+	set := make(map[string]bool, len(others))
+	for _, o := range others {
+		set[o] = true
+	}
+	var res []string
+	for _, v := range vals {
+		if !set[v] {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// endpointNames returns the names of the endpoints of both snapshots in alphabetical order.
+func endpointNames(old, snap *Snapshot) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, s := range []*Snapshot{old, snap} {
+		for n := range s.Endpoints {
+			if !seen[n] {
+				seen[n] = true
+				names = append(names, n)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedKeys returns the keys of both maps in alphabetical order, the maps must be
+// map[string]*Attribute or map[string]*Response.
+func sortedKeys(maps ...interface{}) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	addKey := func(k string) {
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	for _, m := range maps {
+		switch t := m.(type) {
+		case map[string]*Attribute:
+			for k := range t {
+				addKey(k)
+			}
+		case map[string]*Response:
+			for k := range t {
+				addKey(k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// intVal returns a pointer to the float64 value of v, nil if v is nil.
+func intVal(v *int) *float64 {
+	if v == nil {
+		return nil
+	}
+	f := float64(*v)
+	return &f
+}
@@ -0,0 +1,196 @@
+package gendiff_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	gendiff "github.com/kyokomi/goa-v1/goagen/gen_diff"
+)
+
+// snapshot evaluates the given design and returns its snapshot.
+func snapshot(dsl func()) *gendiff.Snapshot {
+	dslengine.Reset()
+	design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+	apidsl.API("cellar", func() {})
+	dsl()
+	Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	snap, err := gendiff.NewSnapshot(design.Design)
+	Ω(err).ShouldNot(HaveOccurred())
+	return snap
+}
+
+// bottleDesign defines a bottle resource, color lists the accepted colors and required the
+// required payload fields.
+func bottleDesign(colors []interface{}, required ...string) func() {
+	return func() {
+		bottle := apidsl.MediaType("application/vnd.bottle", func() {
+			apidsl.Attributes(func() {
+				apidsl.Attribute("id", design.Integer)
+				apidsl.Attribute("color", design.String, func() {
+					apidsl.Enum(colors...)
+				})
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("id")
+				apidsl.Attribute("color")
+			})
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("create", func() {
+				apidsl.Routing(apidsl.POST("/bottles"))
+				apidsl.Payload(func() {
+					apidsl.Attribute("name", design.String)
+					apidsl.Attribute("color", design.String, func() {
+						apidsl.Enum(colors...)
+					})
+					apidsl.Attribute("vintage", design.Integer)
+					apidsl.Required(required...)
+				})
+				apidsl.Response(design.Created, bottle)
+			})
+		})
+	}
+}
+
+var _ = Describe("Diff", func() {
+	var old, snap *gendiff.Snapshot
+	var changes []*gendiff.Change
+
+	// messages returns the messages of the breaking (breaking is true) or compatible changes.
+	messages := func(breaking bool) []string {
+		var msgs []string
+		for _, c := range changes {
+			if c.Breaking == breaking {
+				msgs = append(msgs, c.Endpoint+": "+c.Message)
+			}
+		}
+		return msgs
+	}
+
+	BeforeEach(func() {
+		old = snapshot(bottleDesign([]interface{}{"red", "white"}, "name"))
+	})
+
+	JustBeforeEach(func() {
+		changes = gendiff.Diff(old, snap)
+	})
+
+	Context("with the same design", func() {
+		BeforeEach(func() {
+			snap = snapshot(bottleDesign([]interface{}{"red", "white"}, "name"))
+		})
+
+		It("reports no change", func() {
+			Ω(changes).Should(BeEmpty())
+			Ω(gendiff.Report(changes)).Should(Equal("No changes"))
+		})
+	})
+
+	Context("with a removed endpoint", func() {
+		BeforeEach(func() {
+			snap = snapshot(func() {
+				apidsl.Resource("wine", func() {
+					apidsl.Action("list", func() {
+						apidsl.Routing(apidsl.GET("/wines"))
+						apidsl.Response(design.OK)
+					})
+				})
+			})
+		})
+
+		It("reports a breaking change", func() {
+			Ω(messages(true)).Should(Equal([]string{"bottle.create: removed endpoint"}))
+			Ω(messages(false)).Should(Equal([]string{"wine.list: added endpoint"}))
+		})
+	})
+
+	Context("with a narrowed enum", func() {
+		BeforeEach(func() {
+			snap = snapshot(bottleDesign([]interface{}{"red"}, "name"))
+		})
+
+		It("breaks requests but not responses", func() {
+			Ω(messages(true)).Should(Equal([]string{
+				`bottle.create: payload field color: narrowed enum, removed "white"`,
+			}))
+			Ω(messages(false)).Should(Equal([]string{
+				`bottle.create: response 201 body field color: narrowed enum, removed "white"`,
+			}))
+		})
+	})
+
+	Context("with a widened enum", func() {
+		BeforeEach(func() {
+			snap = snapshot(bottleDesign([]interface{}{"red", "rose", "white"}, "name"))
+		})
+
+		It("breaks responses but not requests", func() {
+			Ω(messages(true)).Should(Equal([]string{
+				`bottle.create: response 201 body field color: widened enum, added "rose"`,
+			}))
+			Ω(messages(false)).Should(Equal([]string{
+				`bottle.create: payload field color: widened enum, added "rose"`,
+			}))
+		})
+	})
+
+	Context("with a new required field", func() {
+		BeforeEach(func() {
+			snap = snapshot(bottleDesign([]interface{}{"red", "white"}, "name", "vintage"))
+		})
+
+		It("reports a breaking change", func() {
+			Ω(messages(true)).Should(Equal([]string{"bottle.create: payload field vintage: now required"}))
+			Ω(messages(false)).Should(BeEmpty())
+		})
+	})
+
+	Context("with a field no longer required", func() {
+		BeforeEach(func() {
+			snap = snapshot(bottleDesign([]interface{}{"red", "white"}))
+		})
+
+		It("reports a compatible change", func() {
+			Ω(messages(true)).Should(BeEmpty())
+			Ω(messages(false)).Should(Equal([]string{"bottle.create: payload field name: no longer required"}))
+		})
+	})
+
+	Context("with a renamed path parameter", func() {
+		BeforeEach(func() {
+			route := func(path string) func() {
+				return func() {
+					apidsl.Resource("bottle", func() {
+						apidsl.Action("show", func() {
+							apidsl.Routing(apidsl.GET(path))
+							apidsl.Response(design.OK)
+						})
+					})
+				}
+			}
+			old = snapshot(route("/bottles/:id"))
+			snap = snapshot(route("/bottles/:bottleID"))
+		})
+
+		It("reports the parameter changes but not a route change", func() {
+			Ω(messages(true)).Should(Equal([]string{"bottle.show: added required param bottleID"}))
+			Ω(messages(false)).Should(Equal([]string{"bottle.show: removed param id"}))
+		})
+	})
+})
+
+var _ = Describe("Report", func() {
+	It("lists the breaking changes first", func() {
+		report := gendiff.Report([]*gendiff.Change{
+			{Endpoint: "bottle.show", Message: "added response 404"},
+			{Breaking: true, Endpoint: "bottle.list", Message: "removed endpoint"},
+		})
+		Ω(report).Should(Equal(`Breaking changes (1):
+  - bottle.list: removed endpoint
+Compatible changes (1):
+  - bottle.show: added response 404`))
+	})
+})
@@ -0,0 +1,23 @@
+/*
+Package gendiff implements the goagen diff command. The command evaluates the design and compares
+its contract with a previous version of the design, it then prints the list of changes grouped
+in breaking changes that may break existing clients and compatible changes:
+
+	goagen diff -d example.com/design --against=v1.2.0
+
+The --against flag is either a git revision or the path to a snapshot file. When given a
+revision the design package files are extracted from the git history and evaluated first. A
+snapshot describes the contract of a design in JSON, it is written with --snapshot:
+
+	goagen diff -d example.com/design --snapshot=api.json
+
+The contract consists of the routes, parameters, headers and payload of each action as well as
+the status, headers and body of each response. Changes to requests are breaking when they
+restrict the accepted values: removed endpoint or route, new required field, narrowed enum or
+stricter validation. Changes to responses are breaking when they extend the values clients may
+receive: removed field or response, widened enum or field no longer required.
+
+--fail-on=breaking makes the command exit with a non zero status when breaking changes are found
+so that CI pipelines can guard the API contract, --fail-on=any fails on any change.
+*/
+package gendiff
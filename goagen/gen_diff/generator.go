@@ -0,0 +1,135 @@
+package gendiff
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+// NewGenerator returns an initialized instance of a design diff Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator compares the design with a previous snapshot of its contract.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	Against  string                // Path to the snapshot the design is compared with
+	Snapshot string                // Path to the file the design snapshot is written to instead of diffing
+	FailOn   string                // "breaking" or "any" to fail when such changes are found, "none" by default
+	Output   io.Writer             // Writer the report is printed to, os.Stdout by default
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, ver, against, snapshot, failOn string
+	)
+
+	set := flag.NewFlagSet("diff", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.StringVar(&against, "against", "", "")
+	set.StringVar(&snapshot, "snapshot", "", "")
+	set.StringVar(&failOn, "fail-on", "none", "")
+	set.String("design", "", "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{Against: against, Snapshot: snapshot, FailOn: failOn, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate writes the snapshot of the design if Snapshot is set. It otherwise compares the design
+// with the Against snapshot, prints the report of the changes and returns an error if changes
+// selected by FailOn are found.
+func (g *Generator) Generate() ([]string, error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+	switch g.FailOn {
+	case "", "none", "breaking", "any":
+	default:
+		return nil, fmt.Errorf(`invalid fail-on value %#v, must be "none", "breaking" or "any"`, g.FailOn)
+	}
+	snap, err := NewSnapshot(g.API)
+	if err != nil {
+		return nil, err
+	}
+	if g.Snapshot != "" {
+		return g.writeSnapshot(snap)
+	}
+	if g.Against == "" {
+		return nil, fmt.Errorf("missing snapshot to compare the design with, specify it with --against")
+	}
+	old, err := ReadSnapshot(g.Against)
+	if err != nil {
+		return nil, err
+	}
+	changes := Diff(old, snap)
+	out := g.Output
+	if out == nil {
+		out = os.Stdout
+	}
+	fmt.Fprintln(out, Report(changes))
+	breaking := 0
+	for _, c := range changes {
+		if c.Breaking {
+			breaking++
+		}
+	}
+	switch {
+	case g.FailOn == "breaking" && breaking > 0:
+		return nil, fmt.Errorf("%d breaking change(s) found", breaking)
+	case g.FailOn == "any" && len(changes) > 0:
+		return nil, fmt.Errorf("%d change(s) found", len(changes))
+	}
+	return nil, nil
+}
+
+// ReadSnapshot loads the snapshot written to the given file by goagen diff --snapshot.
+func ReadSnapshot(path string) (*Snapshot, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %s", path, err)
+	}
+	return &snap, nil
+}
+
+// writeSnapshot writes the JSON representation of snap to the Snapshot file.
+func (g *Generator) writeSnapshot(snap *Snapshot) ([]string, error) {
+	b, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(g.Snapshot), 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(g.Snapshot, append(b, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	return []string{g.Snapshot}, nil
+}
@@ -0,0 +1,125 @@
+package gendiff_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	gendiff "github.com/kyokomi/goa-v1/goagen/gen_diff"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *gendiff.Generator
+
+	var args = struct {
+		api      *design.APIDefinition
+		against  string
+		snapshot string
+		failOn   string
+		output   *bytes.Buffer
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		against:  "old.json",
+		snapshot: "new.json",
+		failOn:   "breaking",
+		output:   new(bytes.Buffer),
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = gendiff.NewGenerator(
+				gendiff.API(args.api),
+				gendiff.Against(args.against),
+				gendiff.SnapshotFile(args.snapshot),
+				gendiff.FailOn(args.failOn),
+				gendiff.Output(args.output),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.Against).Should(Equal(args.against))
+			Ω(generator.Snapshot).Should(Equal(args.snapshot))
+			Ω(generator.FailOn).Should(Equal(args.failOn))
+			Ω(generator.Output).Should(Equal(args.output))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var dir, snapshotFile, failOn string
+	var output *bytes.Buffer
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "gendiff")
+		Ω(err).ShouldNot(HaveOccurred())
+		snapshotFile = filepath.Join(dir, "cellar.json")
+		snapshot(bottleDesign([]interface{}{"red", "white"}, "name"))
+		files, err = gendiff.NewGenerator(
+			gendiff.API(design.Design),
+			gendiff.SnapshotFile(snapshotFile),
+		).Generate()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(files).Should(Equal([]string{snapshotFile}))
+		failOn = ""
+		output = new(bytes.Buffer)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	JustBeforeEach(func() {
+		snapshot(bottleDesign([]interface{}{"red"}, "name"))
+		files, genErr = gendiff.NewGenerator(
+			gendiff.API(design.Design),
+			gendiff.Against(snapshotFile),
+			gendiff.FailOn(failOn),
+			gendiff.Output(output),
+		).Generate()
+	})
+
+	It("prints the report", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(BeEmpty())
+		Ω(output.String()).Should(Equal(`Breaking changes (1):
+  - bottle.create: payload field color: narrowed enum, removed "white"
+Compatible changes (1):
+  - bottle.create: response 201 body field color: narrowed enum, removed "white"
+`))
+	})
+
+	Context("failing on breaking changes", func() {
+		BeforeEach(func() {
+			failOn = "breaking"
+		})
+
+		It("fails", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(Equal("1 breaking change(s) found"))
+			Ω(output.String()).Should(ContainSubstring("Breaking changes (1):"))
+		})
+	})
+
+	Context("with an invalid fail-on value", func() {
+		BeforeEach(func() {
+			failOn = "compatible"
+		})
+
+		It("fails", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(ContainSubstring("invalid fail-on value"))
+		})
+	})
+})
@@ -0,0 +1,45 @@
+package gendiff
+
+import (
+	"io"
+
+	"github.com/kyokomi/goa-v1/design"
+)
+
+// Option a generator option definition
+type Option func(*Generator)
+
+// API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+// Against Path to the snapshot the design is compared with
+func Against(path string) Option {
+	return func(g *Generator) {
+		g.Against = path
+	}
+}
+
+// SnapshotFile Path to the file the design snapshot is written to
+func SnapshotFile(path string) Option {
+	return func(g *Generator) {
+		g.Snapshot = path
+	}
+}
+
+// FailOn Kind of changes that make the generator fail
+func FailOn(kind string) Option {
+	return func(g *Generator) {
+		g.FailOn = kind
+	}
+}
+
+// Output Writer the report is printed to
+func Output(w io.Writer) Option {
+	return func(g *Generator) {
+		g.Output = w
+	}
+}
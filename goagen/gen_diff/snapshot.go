@@ -0,0 +1,269 @@
+package gendiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/kyokomi/goa-v1/design"
+)
+
+type (
+	// Snapshot is the description of the contract of an API compared by goagen diff. Snapshots
+	// are serialized in JSON so that the contract of a design can be saved and compared later.
+	Snapshot struct {
+		// API is the name of the API.
+		API string `json:"api"`
+		// Endpoints describes the actions indexed by "<resource>.<action>".
+		Endpoints map[string]*Endpoint `json:"endpoints"`
+	}
+
+	// Endpoint describes the requests and responses of an action.
+	Endpoint struct {
+		// Routes lists the action routes, e.g. "GET /bottles/:id".
+		Routes []string `json:"routes"`
+		// Params describes the path and query string parameters indexed by name.
+		Params map[string]*Attribute `json:"params,omitempty"`
+		// Headers describes the request headers indexed by name.
+		Headers map[string]*Attribute `json:"headers,omitempty"`
+		// Payload describes the request body, nil if the action has no payload.
+		Payload *Attribute `json:"payload,omitempty"`
+		// Responses describes the responses indexed by status code.
+		Responses map[string]*Response `json:"responses,omitempty"`
+	}
+
+	// Response describes a response of an action.
+	Response struct {
+		// MediaType is the identifier of the response media type if any.
+		MediaType string `json:"mediaType,omitempty"`
+		// Headers describes the response headers indexed by name.
+		Headers map[string]*Attribute `json:"headers,omitempty"`
+		// Body describes the response body rendered with the response view, nil if the
+		// response has no body or if its media type is not defined in the design.
+		Body *Attribute `json:"body,omitempty"`
+	}
+
+	// Attribute describes the type and validations of a parameter, header or body field.
+	Attribute struct {
+		// Type is the name of the type, e.g. "string", "uuid", "array" or "object".
+		Type string `json:"type"`
+		// TypeName is the name of the user type or media type if any.
+		TypeName string `json:"typeName,omitempty"`
+		// Required is true if the attribute must be set.
+		Required bool `json:"required,omitempty"`
+		// Recursive is true if the attribute type is a user type already described by a
+		// parent attribute, the fields of recursive attributes are not described.
+		Recursive bool `json:"recursive,omitempty"`
+		// Enum lists the JSON representation of the accepted values.
+		Enum []string `json:"enum,omitempty"`
+		// Format is the format validation.
+		Format string `json:"format,omitempty"`
+		// Pattern is the pattern validation.
+		Pattern string `json:"pattern,omitempty"`
+		// Minimum is the minimum value validation.
+		Minimum *float64 `json:"minimum,omitempty"`
+		// Maximum is the maximum value validation.
+		Maximum *float64 `json:"maximum,omitempty"`
+		// MinLength is the minimum length validation.
+		MinLength *int `json:"minLength,omitempty"`
+		// MaxLength is the maximum length validation.
+		MaxLength *int `json:"maxLength,omitempty"`
+		// Attributes describes the fields of objects indexed by name.
+		Attributes map[string]*Attribute `json:"attributes,omitempty"`
+		// Elem describes the elements of arrays and the values of hashes.
+		Elem *Attribute `json:"elem,omitempty"`
+		// Key describes the keys of hashes.
+		Key *Attribute `json:"key,omitempty"`
+	}
+)
+
+// NewSnapshot returns the snapshot of the contract of the API.
+func NewSnapshot(api *design.APIDefinition) (*Snapshot, error) {
+	s := &Snapshot{API: api.Name, Endpoints: make(map[string]*Endpoint)}
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			e, err := endpoint(api, a)
+			if err != nil {
+				return fmt.Errorf("%s: %s", a.Context(), err)
+			}
+			s.Endpoints[res.Name+"."+a.Name] = e
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// endpoint builds the description of the given action.
+func endpoint(api *design.APIDefinition, a *design.ActionDefinition) (*Endpoint, error) {
+	e := &Endpoint{}
+	for _, r := range a.Routes {
+		e.Routes = append(e.Routes, r.Verb+" "+r.FullPath())
+	}
+	sort.Strings(e.Routes)
+	params := a.AllParams()
+	if obj := params.Type.ToObject(); len(obj) > 0 {
+		pathParams := make(map[string]bool)
+		for _, r := range a.Routes {
+			for _, n := range r.Params() {
+				pathParams[n] = true
+			}
+		}
+		e.Params = make(map[string]*Attribute)
+		for n, att := range obj {
+			e.Params[n] = attribute(att, params.IsRequired(n) || pathParams[n], nil)
+		}
+	}
+	e.Headers = headers(a.IterateHeaders)
+	if a.Payload != nil {
+		e.Payload = attribute(&design.AttributeDefinition{Type: a.Payload}, !a.PayloadOptional, nil)
+	}
+	err := a.IterateResponses(func(r *design.ResponseDefinition) error {
+		resp := &Response{MediaType: r.MediaType}
+		if r.Headers != nil {
+			obj := r.Headers.Type.ToObject()
+			resp.Headers = make(map[string]*Attribute, len(obj))
+			for n, h := range obj {
+				resp.Headers[n] = attribute(h, r.Headers.IsRequired(n), nil)
+			}
+		}
+		if mt := api.MediaTypeWithIdentifier(r.MediaType); mt != nil {
+			view := design.DefaultView
+			if r.ViewName != "" {
+				view = r.ViewName
+			}
+			p, _, err := mt.Project(view)
+			if err != nil {
+				return fmt.Errorf("response %s: %s", r.Name, err)
+			}
+			resp.MediaType = mt.Identifier
+			resp.Body = attribute(&design.AttributeDefinition{Type: p}, true, nil)
+		} else if r.Type != nil {
+			resp.Body = attribute(&design.AttributeDefinition{Type: r.Type}, true, nil)
+		}
+		if e.Responses == nil {
+			e.Responses = make(map[string]*Response)
+		}
+		e.Responses[strconv.Itoa(r.Status)] = resp
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// headers describes the headers iterated by it.
+func headers(it func(design.HeaderIterator) error) map[string]*Attribute {
+	var res map[string]*Attribute
+	it(func(name string, required bool, h *design.AttributeDefinition) error {
+		if res == nil {
+			res = make(map[string]*Attribute)
+		}
+		res[name] = attribute(h, required, nil)
+		return nil
+	})
+	return res
+}
+
+// attribute describes the given attribute, seen lists the user types described by the parent
+// attributes.
+func attribute(att *design.AttributeDefinition, required bool, seen map[string]bool) *Attribute {
+	a := &Attribute{Required: required}
+	if v := att.Validation; v != nil {
+		for _, val := range v.Values {
+			b, _ := json.Marshal(val)
+			a.Enum = append(a.Enum, string(b))
+		}
+		sort.Strings(a.Enum)
+		a.Format = v.Format
+		a.Pattern = v.Pattern
+		a.Minimum, a.Maximum = v.Minimum, v.Maximum
+		a.MinLength, a.MaxLength = v.MinLength, v.MaxLength
+	}
+	dt := att.Type
+	switch t := dt.(type) {
+	case *design.UserTypeDefinition:
+		a.TypeName = t.TypeName
+		dt = t.Type
+	case *design.MediaTypeDefinition:
+		a.TypeName = t.Identifier
+		dt = t.Type
+	}
+	if a.TypeName != "" {
+		if seen[a.TypeName] {
+			a.Type = typeName(dt)
+			a.Recursive = true
+			return a
+		}
+		child := make(map[string]bool, len(seen)+1)
+		for k := range seen {
+			child[k] = true
+		}
+		child[a.TypeName] = true
+		seen = child
+	}
+	a.Type = typeName(dt)
+	switch t := dt.(type) {
+	case design.Object:
+		a.Attributes = make(map[string]*Attribute, len(t))
+		for n, child := range t {
+			a.Attributes[n] = attribute(child, isRequired(att, n), seen)
+		}
+	case *design.Array:
+		a.Elem = attribute(t.ElemType, true, seen)
+	case *design.Hash:
+		a.Key = attribute(t.KeyType, true, seen)
+		a.Elem = attribute(t.ElemType, true, seen)
+	}
+	return a
+}
+
+// isRequired returns true if the field n of the object attribute att or of its user type is
+// required.
+func isRequired(att *design.AttributeDefinition, n string) bool {
+	if att.IsRequired(n) {
+		return true
+	}
+	switch t := att.Type.(type) {
+	case *design.UserTypeDefinition:
+		return t.IsRequired(n)
+	case *design.MediaTypeDefinition:
+		return t.IsRequired(n)
+	}
+	return false
+}
+
+// typeName returns the name of the given type, user types are described by the name of their
+// underlying type.
+func typeName(dt design.DataType) string {
+	switch t := dt.(type) {
+	case design.Primitive:
+		if c := design.CustomPrimitive(t); c != nil {
+			return c.Name
+		}
+		switch t {
+		case design.DateTime:
+			return "datetime"
+		case design.UUID:
+			return "uuid"
+		case design.Decimal:
+			return "decimal"
+		}
+		return t.Name()
+	case *design.Array:
+		return "array"
+	case *design.Hash:
+		return "hash"
+	case design.Object:
+		return "object"
+	case *design.UserTypeDefinition:
+		return typeName(t.Type)
+	case *design.MediaTypeDefinition:
+		return typeName(t.Type)
+	}
+	return "any"
+}
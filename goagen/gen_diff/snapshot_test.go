@@ -0,0 +1,111 @@
+package gendiff_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	gendiff "github.com/kyokomi/goa-v1/goagen/gen_diff"
+)
+
+var _ = Describe("NewSnapshot", func() {
+	var snap *gendiff.Snapshot
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {
+			apidsl.BasePath("/api")
+		})
+		bottle := apidsl.MediaType("application/vnd.bottle", func() {
+			apidsl.Attributes(func() {
+				apidsl.Attribute("id", design.Integer)
+				apidsl.Attribute("color", design.String, func() {
+					apidsl.Enum("red", "white")
+				})
+				apidsl.Attribute("note", design.String)
+				apidsl.Required("id")
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("id")
+				apidsl.Attribute("color")
+			})
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("update", func() {
+				apidsl.Routing(apidsl.PUT("/bottles/:id"))
+				apidsl.Params(func() {
+					apidsl.Param("id", design.Integer)
+					apidsl.Param("dry", design.Boolean)
+				})
+				apidsl.Headers(func() {
+					apidsl.Header("X-Request-Id", design.String, func() {
+						apidsl.Format("hostname")
+					})
+					apidsl.Required("X-Request-Id")
+				})
+				apidsl.Payload(func() {
+					apidsl.Attribute("color", design.String)
+					apidsl.Attribute("rating", design.Integer, func() {
+						apidsl.Minimum(1)
+						apidsl.Maximum(5)
+					})
+					apidsl.Required("rating")
+				})
+				apidsl.Response(design.OK, bottle)
+				apidsl.Response(design.NotFound)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		var err error
+		snap, err = gendiff.NewSnapshot(design.Design)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("describes the endpoints", func() {
+		Ω(snap.API).Should(Equal("cellar"))
+		Ω(snap.Endpoints).Should(HaveLen(1))
+		e := snap.Endpoints["bottle.update"]
+		Ω(e).ShouldNot(BeNil())
+		Ω(e.Routes).Should(Equal([]string{"PUT /api/bottles/:id"}))
+	})
+
+	It("describes the parameters and headers", func() {
+		e := snap.Endpoints["bottle.update"]
+		Ω(e.Params).Should(HaveLen(2))
+		Ω(e.Params["id"].Type).Should(Equal("integer"))
+		Ω(e.Params["id"].Required).Should(BeTrue())
+		Ω(e.Params["dry"].Required).Should(BeFalse())
+		Ω(e.Headers).Should(HaveLen(1))
+		Ω(e.Headers["X-Request-Id"].Required).Should(BeTrue())
+		Ω(e.Headers["X-Request-Id"].Format).Should(Equal("hostname"))
+	})
+
+	It("describes the payload", func() {
+		p := snap.Endpoints["bottle.update"].Payload
+		Ω(p).ShouldNot(BeNil())
+		Ω(p.Type).Should(Equal("object"))
+		Ω(p.Required).Should(BeTrue())
+		Ω(p.Attributes["color"].Required).Should(BeFalse())
+		Ω(p.Attributes["rating"].Required).Should(BeTrue())
+		Ω(*p.Attributes["rating"].Minimum).Should(Equal(1.0))
+		Ω(*p.Attributes["rating"].Maximum).Should(Equal(5.0))
+	})
+
+	It("describes the responses with their views", func() {
+		resps := snap.Endpoints["bottle.update"].Responses
+		Ω(resps).Should(HaveLen(2))
+		ok := resps["200"]
+		Ω(ok.MediaType).Should(Equal("application/vnd.bottle"))
+		Ω(ok.Body).ShouldNot(BeNil())
+		Ω(ok.Body.Attributes).Should(HaveLen(2))
+		Ω(ok.Body.Attributes["id"].Required).Should(BeTrue())
+		Ω(ok.Body.Attributes["color"].Enum).Should(Equal([]string{`"red"`, `"white"`}))
+		Ω(resps["404"].Body).Should(BeNil())
+	})
+})
@@ -0,0 +1,18 @@
+/*
+Package gengraphql provides a generator for a GraphQL schema that fronts the API. The generator
+produces the schema in the GraphQL schema definition language together with the Go resolvers of
+its fields. The GET and HEAD actions become fields of the Query type and the other actions fields
+of the Mutation type, their parameters, headers and payload become the field arguments. The
+response media types map to object types and the payload types to input types. Each resolver
+serves the request of its action with the handlers mounted on the goa service so that the
+existing controllers resolve the query or mutation.
+
+The following metadata annotate the design:
+
+	// Sets the name of the Query or Mutation field of the action, the name defaults to the
+	// action name followed by the resource name, e.g. "showBottle".
+	Metadata("graphql:field", "bottle")
+
+WebSocket actions and actions with multipart payloads are not exposed.
+*/
+package gengraphql
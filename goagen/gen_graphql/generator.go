@@ -0,0 +1,155 @@
+package gengraphql
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of a GraphQL Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{Target: "app"}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the GraphQL schema and resolver generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Target   string                // Name of generated "app" package
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, target, ver string
+
+	set := flag.NewFlagSet("graphql", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&target, "pkg", "app", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	target = codegen.Goify(target, false)
+	g := &Generator{OutDir: outDir, Target: target, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the GraphQL schema and the resolvers in the "graphql" directory. It does not
+// generate any file if the API has no action that can be exposed as a query or a mutation.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	s, err := New(g.API)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Queries) == 0 && len(s.Mutations) == 0 {
+		return nil, nil
+	}
+
+	outPkg, err := codegen.PackagePath(g.OutDir)
+	if err != nil {
+		return nil, err
+	}
+
+	graphqlDir := filepath.Join(g.OutDir, "graphql")
+	codegen.RemoveAll(graphqlDir)
+	if err = os.MkdirAll(graphqlDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, graphqlDir)
+
+	title := fmt.Sprintf("%s: GraphQL Schema", g.API.Context())
+	content, err := s.WriteSchema(title)
+	if err != nil {
+		return nil, err
+	}
+	schemaFile := filepath.Join(graphqlDir, "schema.graphql")
+	if err = codegen.WriteFile(schemaFile, content, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, schemaFile)
+
+	if err = g.generateResolvers(s, graphqlDir, path.Join(outPkg, g.Target)); err != nil {
+		return nil, err
+	}
+
+	return g.genfiles, nil
+}
+
+// generateResolvers generates the resolvers that serve the requests of the actions.
+func (g *Generator) generateResolvers(s *Schema, graphqlDir, appPkg string) (err error) {
+	resolversFile := filepath.Join(graphqlDir, "resolvers.go")
+	file, err := codegen.SourceFileFor(resolversFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("bytes"),
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("io"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("net/http/httptest"),
+		codegen.SimpleImport("net/url"),
+		codegen.SimpleImport("time"),
+		codegen.NewImport("goa", "github.com/kyokomi/goa-v1"),
+		codegen.NewImport("uuid", "github.com/gofrs/uuid"),
+		codegen.SimpleImport(appPkg),
+	}
+	title := fmt.Sprintf("%s: GraphQL Resolvers", g.API.Context())
+	if err = file.WriteHeader(title, "graphql", imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, resolversFile)
+	if _, err = file.Write([]byte(resolverT)); err != nil {
+		return err
+	}
+	_, err = file.Write([]byte(s.Resolvers(path.Base(appPkg))))
+	return err
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
@@ -0,0 +1,481 @@
+package gengraphql
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+type (
+	// Schema describes the GraphQL schema of the API.
+	Schema struct {
+		// Queries lists the fields of the Query type in order of name.
+		Queries []*Field
+		// Mutations lists the fields of the Mutation type in order of name.
+		Mutations []*Field
+		// Types lists the object and input types in order of name.
+		Types []*Type
+		// Scalars lists the custom scalars used by the schema in order of name.
+		Scalars []string
+	}
+
+	// Type describes a GraphQL object or input type.
+	Type struct {
+		// Name of the type.
+		Name string
+		// Description of the type.
+		Description string
+		// Input is true for input types.
+		Input bool
+		// Fields lists the fields of the type in order of attribute name.
+		Fields []*Field
+		// UserType is the goa user type or media type described by the type.
+		UserType design.DataType
+	}
+
+	// Field describes a field of an object or input type or of the Query and Mutation types.
+	Field struct {
+		// Name of the field.
+		Name string
+		// Description of the field.
+		Description string
+		// Type is the GraphQL type of the field, e.g. "[Bottle!]" or "Int!".
+		Type string
+		// Args lists the arguments of the Query and Mutation fields.
+		Args []*Arg
+		// Action is the goa action that resolves the Query and Mutation fields.
+		Action *design.ActionDefinition
+		// Result is the type of the body of the response that resolves the Query and Mutation
+		// fields, nil if the response has no body.
+		Result design.DataType
+	}
+
+	// Arg describes an argument of a Query or Mutation field.
+	Arg struct {
+		// Name of the argument.
+		Name string
+		// Description of the argument.
+		Description string
+		// Type is the GraphQL type of the argument.
+		Type string
+		// Default is the GraphQL literal of the default value of the argument if any.
+		Default string
+		// In is where the argument is sent: "path", "query", "header" or "body".
+		In string
+		// AttName is the name of the goa parameter, header or "payload" for the body.
+		AttName string
+		// Attribute is the goa attribute described by the argument.
+		Attribute *design.AttributeDefinition
+		// Required is true if the argument cannot be null.
+		Required bool
+	}
+)
+
+// Scalars used to represent the goa primitives and types that have no GraphQL equivalent.
+const (
+	// DateTimeScalar represents date times in RFC3339 format.
+	DateTimeScalar = "DateTime"
+	// UUIDScalar represents UUIDs.
+	UUIDScalar = "UUID"
+	// JSONScalar represents hashes, inline objects and Any values.
+	JSONScalar = "JSON"
+)
+
+// New builds the GraphQL schema of the API. The GET and HEAD actions become fields of the
+// Query type and the other actions fields of the Mutation type, the fields are named after the
+// action and its resource unless the action sets the "graphql:field" metadata. The response
+// media types become object types and the payloads input types. WebSocket actions and actions
+// with multipart payloads are skipped.
+func New(api *design.APIDefinition) (*Schema, error) {
+	b := &builder{api: api, types: make(map[string]*Type), scalars: make(map[string]bool)}
+	s := &Schema{}
+	fields := make(map[string]*design.ActionDefinition)
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() || a.PayloadMultipart || len(a.Routes) == 0 {
+				return nil
+			}
+			f, err := b.field(a)
+			if err != nil {
+				return fmt.Errorf("%s: %s", a.Context(), err)
+			}
+			if other, ok := fields[f.Name]; ok {
+				return fmt.Errorf("%s: field %s is already defined by %s", a.Context(), f.Name, other.Context())
+			}
+			fields[f.Name] = a
+			switch a.Routes[0].Verb {
+			case "GET", "HEAD":
+				s.Queries = append(s.Queries, f)
+			default:
+				s.Mutations = append(s.Mutations, f)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(s.Queries, func(i, j int) bool { return s.Queries[i].Name < s.Queries[j].Name })
+	sort.Slice(s.Mutations, func(i, j int) bool { return s.Mutations[i].Name < s.Mutations[j].Name })
+	for _, t := range b.types {
+		s.Types = append(s.Types, t)
+	}
+	sort.Slice(s.Types, func(i, j int) bool { return s.Types[i].Name < s.Types[j].Name })
+	for sc := range b.scalars {
+		s.Scalars = append(s.Scalars, sc)
+	}
+	sort.Strings(s.Scalars)
+	return s, nil
+}
+
+// builder computes the object and input types.
+type builder struct {
+	api     *design.APIDefinition
+	types   map[string]*Type
+	scalars map[string]bool
+}
+
+// field builds the Query or Mutation field resolved by the given action.
+func (b *builder) field(a *design.ActionDefinition) (*Field, error) {
+	name := codegen.Goify(a.Name, false) + codegen.Goify(a.Parent.Name, true)
+	if n, ok := a.Metadata["graphql:field"]; ok && len(n) > 0 && n[0] != "" {
+		name = n[0]
+	}
+	name = fieldName(name)
+	f := &Field{Name: name, Description: a.Description, Action: a}
+
+	route := a.Routes[0]
+	params := a.AllParams()
+	obj := params.Type.ToObject()
+	pathParams := make(map[string]bool)
+	args := make(map[string]*Arg)
+	add := func(arg *Arg) error {
+		if other, ok := args[arg.Name]; ok {
+			return fmt.Errorf("%s %#v and %s %#v use the same argument name %s", other.In, other.AttName, arg.In, arg.AttName, arg.Name)
+		}
+		args[arg.Name] = arg
+		f.Args = append(f.Args, arg)
+		return nil
+	}
+	for _, n := range route.Params() {
+		pathParams[n] = true
+		at, ok := obj[n]
+		if !ok {
+			continue
+		}
+		arg, err := b.arg(n, "path", at, true)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(arg); err != nil {
+			return nil, err
+		}
+	}
+	if a.QueryParams != nil {
+		query := a.QueryParams.Type.ToObject()
+		for _, n := range codegen.SortedNames(query) {
+			if pathParams[n] {
+				continue
+			}
+			arg, err := b.arg(n, "query", query[n], a.QueryParams.IsRequired(n))
+			if err != nil {
+				return nil, err
+			}
+			if err := add(arg); err != nil {
+				return nil, err
+			}
+		}
+	}
+	err := a.IterateHeaders(func(n string, required bool, at *design.AttributeDefinition) error {
+		arg, err := b.arg(n, "header", at, required)
+		if err != nil {
+			return err
+		}
+		return add(arg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if a.Payload != nil {
+		typ, err := b.inputType(&design.AttributeDefinition{Type: a.Payload})
+		if err != nil {
+			return nil, fmt.Errorf("payload: %s", err)
+		}
+		arg := &Arg{
+			Name:        "payload",
+			Description: a.Payload.Description,
+			Type:        typ,
+			In:          "body",
+			AttName:     "payload",
+			Attribute:   &design.AttributeDefinition{Type: a.Payload},
+			Required:    !a.PayloadOptional,
+		}
+		if arg.Required {
+			arg.Type += "!"
+		}
+		if err := add(arg); err != nil {
+			return nil, err
+		}
+	}
+
+	f.Type = "Boolean"
+	if f.Result = b.result(a); f.Result != nil {
+		if f.Type, err = b.outputType(&design.AttributeDefinition{Type: f.Result}); err != nil {
+			return nil, fmt.Errorf("response: %s", err)
+		}
+	}
+	return f, nil
+}
+
+// arg builds the argument that corresponds to the given parameter or header.
+func (b *builder) arg(n, in string, at *design.AttributeDefinition, required bool) (*Arg, error) {
+	typ, err := b.inputType(at)
+	if err != nil {
+		return nil, fmt.Errorf("%s %#v: %s", in, n, err)
+	}
+	arg := &Arg{
+		Name:        fieldName(codegen.Goify(n, false)),
+		Description: at.Description,
+		Type:        typ,
+		In:          in,
+		AttName:     n,
+		Attribute:   at,
+		Required:    required,
+	}
+	if required {
+		arg.Type += "!"
+	}
+	if at.DefaultValue != nil {
+		arg.Default = literal(at.DefaultValue)
+	}
+	return arg, nil
+}
+
+// result returns the type of the body of the response with the lowest success status that has a
+// body, nil if there is no such response. Media types are projected using the response view.
+func (b *builder) result(a *design.ActionDefinition) design.DataType {
+	var responses []*design.ResponseDefinition
+	a.IterateResponses(func(r *design.ResponseDefinition) error {
+		if r.Status >= http.StatusOK && r.Status < http.StatusMultipleChoices && (r.Type != nil || r.MediaType != "") {
+			responses = append(responses, r)
+		}
+		return nil
+	})
+	sort.Slice(responses, func(i, j int) bool { return responses[i].Status < responses[j].Status })
+	for _, r := range responses {
+		if r.MediaType != "" {
+			mt := b.api.MediaTypeWithIdentifier(r.MediaType)
+			if mt == nil || mt.IsError() {
+				continue
+			}
+			view := r.ViewName
+			if view == "" {
+				view = design.DefaultView
+			}
+			p, _, err := mt.Project(view)
+			if err != nil {
+				continue
+			}
+			return p
+		}
+		if ut, ok := r.Type.(*design.UserTypeDefinition); ok {
+			return ut
+		}
+	}
+	return nil
+}
+
+// outputType returns the GraphQL output type of the given attribute without the non-null
+// marker.
+func (b *builder) outputType(at *design.AttributeDefinition) (string, error) {
+	switch actual := at.Type.(type) {
+	case design.Primitive:
+		return b.scalar(actual)
+	case *design.Array:
+		elem, err := b.outputType(actual.ElemType)
+		if err != nil {
+			return "", err
+		}
+		return "[" + elem + "!]", nil
+	case *design.Hash, design.Object:
+		b.scalars[JSONScalar] = true
+		return JSONScalar, nil
+	case *design.MediaTypeDefinition:
+		t := design.DataType(actual)
+		if !isProjected(actual) {
+			view := at.View
+			if view == "" {
+				view = design.DefaultView
+			}
+			p, _, err := actual.Project(view)
+			if err != nil {
+				return "", err
+			}
+			t = p
+		}
+		return b.objectType(t, false)
+	case *design.UserTypeDefinition:
+		return b.objectType(actual, false)
+	}
+	return "", fmt.Errorf("unknown type %s", at.Type.Name())
+}
+
+// inputType returns the GraphQL input type of the given attribute without the non-null marker.
+func (b *builder) inputType(at *design.AttributeDefinition) (string, error) {
+	switch actual := at.Type.(type) {
+	case design.Primitive:
+		return b.scalar(actual)
+	case *design.Array:
+		elem, err := b.inputType(actual.ElemType)
+		if err != nil {
+			return "", err
+		}
+		return "[" + elem + "!]", nil
+	case *design.Hash, design.Object:
+		b.scalars[JSONScalar] = true
+		return JSONScalar, nil
+	case *design.MediaTypeDefinition:
+		return b.objectType(actual.UserTypeDefinition, true)
+	case *design.UserTypeDefinition:
+		return b.objectType(actual, true)
+	}
+	return "", fmt.Errorf("unknown type %s", at.Type.Name())
+}
+
+// objectType builds the object or input type that corresponds to the given user type or media
+// type and returns its name. User types and media types that are not objects are represented by
+// the GraphQL type of their underlying type.
+func (b *builder) objectType(t design.DataType, input bool) (string, error) {
+	ut := userType(t)
+	if !ut.Type.IsObject() {
+		if input {
+			return b.inputType(ut.AttributeDefinition)
+		}
+		return b.outputType(ut.AttributeDefinition)
+	}
+	name := typeName(t, input)
+	if existing, ok := b.types[name]; ok {
+		if existing.UserType != t && userType(existing.UserType) != ut {
+			return "", fmt.Errorf("type %s is already defined", name)
+		}
+		return name, nil
+	}
+	typ := &Type{Name: name, Description: ut.Description, Input: input, UserType: t}
+	b.types[name] = typ
+	obj := ut.Type.ToObject()
+	for _, n := range codegen.SortedNames(obj) {
+		at := obj[n]
+		var (
+			ft  string
+			err error
+		)
+		if input {
+			ft, err = b.inputType(at)
+		} else {
+			ft, err = b.outputType(at)
+		}
+		if err != nil {
+			delete(b.types, name)
+			return "", fmt.Errorf("attribute %#v: %s", n, err)
+		}
+		if ut.IsRequired(n) {
+			ft += "!"
+		}
+		typ.Fields = append(typ.Fields, &Field{Name: fieldName(n), Description: at.Description, Type: ft})
+	}
+	return name, nil
+}
+
+// scalar returns the GraphQL scalar used to represent values of the given primitive.
+func (b *builder) scalar(p design.Primitive) (string, error) {
+	switch p.Kind() {
+	case design.BooleanKind:
+		return "Boolean", nil
+	case design.IntegerKind:
+		return "Int", nil
+	case design.NumberKind:
+		return "Float", nil
+	case design.StringKind, design.DecimalKind:
+		return "String", nil
+	case design.DateTimeKind:
+		b.scalars[DateTimeScalar] = true
+		return DateTimeScalar, nil
+	case design.UUIDKind:
+		b.scalars[UUIDScalar] = true
+		return UUIDScalar, nil
+	case design.AnyKind:
+		b.scalars[JSONScalar] = true
+		return JSONScalar, nil
+	case design.FileKind:
+		return "", fmt.Errorf("File attributes are not supported")
+	}
+	if c := design.CustomPrimitive(p); c != nil {
+		return "String", nil
+	}
+	return "", fmt.Errorf("unknown primitive type %s", p.Name())
+}
+
+// typeName returns the name of the GraphQL type that corresponds to the given user type or
+// media type. Input types get the "Input" suffix unless they describe a payload.
+func typeName(t design.DataType, input bool) string {
+	name := codegen.GoTypeName(t, nil, 0, false)
+	if mt, ok := t.(*design.MediaTypeDefinition); ok && input {
+		name = codegen.GoTypeName(mt.UserTypeDefinition, nil, 0, false)
+	}
+	if input && !strings.HasSuffix(name, "Payload") {
+		name += "Input"
+	}
+	return name
+}
+
+// userType returns the user type definition of the given user type or media type.
+func userType(t design.DataType) *design.UserTypeDefinition {
+	if mt, ok := t.(*design.MediaTypeDefinition); ok {
+		return mt.UserTypeDefinition
+	}
+	return t.(*design.UserTypeDefinition)
+}
+
+// isProjected returns true if the media type is the result of a projection.
+func isProjected(mt *design.MediaTypeDefinition) bool {
+	return strings.Contains(mt.Identifier, "view=")
+}
+
+// fieldName returns the GraphQL name that corresponds to the given name, the characters that
+// cannot appear in names are replaced with underscores.
+func fieldName(n string) string {
+	name := invalidNameChars.ReplaceAllString(n, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// literal returns the GraphQL literal of the given default value.
+func literal(v interface{}) string {
+	switch actual := v.(type) {
+	case string:
+		return quote(actual)
+	case []interface{}:
+		elems := make([]string, len(actual))
+		for i, e := range actual {
+			elems[i] = literal(e)
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	}
+	return fmt.Sprint(v)
+}
+
+// quote returns the GraphQL string literal of s.
+func quote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// invalidNameChars matches the characters that cannot be used in GraphQL names.
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
@@ -0,0 +1,206 @@
+package gengraphql_test
+
+import (
+	"go/parser"
+	"go/token"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	gengraphql "github.com/kyokomi/goa-v1/goagen/gen_graphql"
+)
+
+var _ = Describe("New", func() {
+	var schema *gengraphql.Schema
+	var newErr error
+
+	BeforeEach(func() {
+		schema = nil
+		newErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		schema, newErr = gengraphql.New(Design)
+	})
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			winery := apidsl.Type("Winery", func() {
+				apidsl.Attribute("name", String)
+				apidsl.Required("name")
+			})
+			bottle := apidsl.MediaType("application/vnd.bottle", func() {
+				apidsl.Description("A bottle of wine")
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer, "ID of bottle")
+					apidsl.Attribute("winery", winery)
+					apidsl.Attribute("created_at", DateTime)
+					apidsl.Attribute("ratings", apidsl.HashOf(String, Integer))
+					apidsl.Required("id")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("winery")
+					apidsl.Attribute("created_at")
+					apidsl.Attribute("ratings")
+				})
+			})
+			apidsl.API("cellar", func() {
+				apidsl.BasePath("/api")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Description("Get bottle by id")
+					apidsl.Routing(apidsl.GET("/:bottleID"))
+					apidsl.Params(func() {
+						apidsl.Param("bottleID", Integer, "ID of bottle")
+						apidsl.Param("fields", apidsl.ArrayOf(String))
+						apidsl.Param("limit", Integer, func() {
+							apidsl.Default(10)
+						})
+					})
+					apidsl.Headers(func() {
+						apidsl.Header("X-Request-Id")
+					})
+					apidsl.Response(OK, bottle)
+					apidsl.Response(NotFound)
+				})
+				apidsl.Action("list", func() {
+					apidsl.Metadata("graphql:field", "bottles")
+					apidsl.Routing(apidsl.GET(""))
+					apidsl.Response(OK, apidsl.CollectionOf(bottle))
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(func() {
+						apidsl.Member("name", String)
+						apidsl.Member("winery", winery)
+						apidsl.Required("name")
+					})
+					apidsl.Response(Created)
+					apidsl.Response(BadRequest, ErrorMedia)
+				})
+				apidsl.Action("upload", func() {
+					apidsl.Routing(apidsl.POST("/:bottleID/label"))
+					apidsl.MultipartForm()
+					apidsl.Payload(func() {
+						apidsl.Member("label", File)
+					})
+					apidsl.Response(NoContent)
+				})
+			})
+		})
+
+		It("generates the queries and mutations", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(schema.Queries).Should(HaveLen(2))
+			list := schema.Queries[0]
+			Ω(list.Name).Should(Equal("bottles"))
+			Ω(list.Type).Should(Equal("[Bottle!]"))
+			show := schema.Queries[1]
+			Ω(show.Name).Should(Equal("showBottle"))
+			Ω(show.Type).Should(Equal("Bottle"))
+			var args []string
+			for _, a := range show.Args {
+				args = append(args, a.Name+": "+a.Type+" in "+a.In)
+			}
+			Ω(args).Should(Equal([]string{
+				"bottleID: Int! in path",
+				"fields: [String!] in query",
+				"limit: Int in query",
+				"xRequestID: String in header",
+			}))
+			Ω(show.Args[2].Default).Should(Equal("10"))
+			Ω(schema.Mutations).Should(HaveLen(1))
+			create := schema.Mutations[0]
+			Ω(create.Name).Should(Equal("createBottle"))
+			Ω(create.Type).Should(Equal("Boolean"))
+			Ω(create.Args).Should(HaveLen(1))
+			Ω(create.Args[0].Type).Should(Equal("CreateBottlePayload!"))
+		})
+
+		It("generates the types", func() {
+			var names []string
+			for _, t := range schema.Types {
+				names = append(names, t.Name)
+			}
+			Ω(names).Should(Equal([]string{"Bottle", "CreateBottlePayload", "Winery", "WineryInput"}))
+			bottle := schema.Types[0]
+			Ω(bottle.Input).Should(BeFalse())
+			var fields []string
+			for _, f := range bottle.Fields {
+				fields = append(fields, f.Name+": "+f.Type)
+			}
+			Ω(fields).Should(Equal([]string{"created_at: DateTime", "id: Int!", "ratings: JSON", "winery: Winery"}))
+			Ω(schema.Types[1].Input).Should(BeTrue())
+			Ω(schema.Types[1].Fields[1].Type).Should(Equal("WineryInput"))
+			Ω(schema.Scalars).Should(Equal([]string{"DateTime", "JSON"}))
+		})
+
+		It("renders the schema", func() {
+			content, err := schema.WriteSchema("cellar: GraphQL Schema")
+			Ω(err).ShouldNot(HaveOccurred())
+			sdl := string(content)
+			Ω(sdl).Should(ContainSubstring("\nscalar DateTime\n"))
+			Ω(sdl).Should(ContainSubstring("type Query {\n  bottles: [Bottle!]\n"))
+			Ω(sdl).Should(ContainSubstring(`  "Get bottle by id"
+  showBottle(
+    "ID of bottle"
+    bottleID: Int!
+    fields: [String!]
+    limit: Int = 10
+    xRequestID: String
+  ): Bottle
+`))
+			Ω(sdl).Should(ContainSubstring("type Mutation {\n  createBottle(payload: CreateBottlePayload!): Boolean\n}\n"))
+			Ω(sdl).Should(ContainSubstring("\"A bottle of wine (default view)\"\ntype Bottle {\n"))
+			Ω(sdl).Should(ContainSubstring("input WineryInput {\n  name: String!\n}\n"))
+		})
+
+		It("generates the resolvers", func() {
+			code := schema.Resolvers("app")
+			Ω(code).Should(ContainSubstring("func (r *Resolver) ShowBottle(ctx context.Context, bottleID int, fields []string, limit *int, xRequestID *string) (*app.Bottle, error) {"))
+			Ω(code).Should(ContainSubstring(`path := "/api/bottles/" + url.PathEscape(format(bottleID))`))
+			Ω(code).Should(ContainSubstring("\tfor _, v := range fields {\n\t\tquery.Add(\"fields\", format(v))\n\t}\n"))
+			Ω(code).Should(ContainSubstring("\tif xRequestID != nil {\n\t\theader.Add(\"X-Request-Id\", format(*xRequestID))\n\t}\n"))
+			Ω(code).Should(ContainSubstring(`err := r.serve(ctx, "GET", path, query, header, nil, &res)`))
+			Ω(code).Should(ContainSubstring("func (r *Resolver) Bottles(ctx context.Context) (app.BottleCollection, error) {"))
+			Ω(code).Should(ContainSubstring("func (r *Resolver) CreateBottle(ctx context.Context, payload *app.CreateBottlePayload) (bool, error) {"))
+			Ω(code).Should(ContainSubstring(`if err := r.serve(ctx, "POST", path, query, header, body, nil); err != nil {`))
+			Ω(code).ShouldNot(ContainSubstring("Upload"))
+			_, err := parser.ParseFile(token.NewFileSet(), "resolvers.go", "package graphql\n"+code, 0)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("with conflicting argument names", func() {
+		BeforeEach(func() {
+			apidsl.API("cellar", nil)
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/bottles"))
+					apidsl.Params(func() {
+						apidsl.Param("x-trace", String)
+					})
+					apidsl.Headers(func() {
+						apidsl.Header("X-Trace")
+					})
+					apidsl.Response(NoContent)
+				})
+			})
+		})
+
+		It("returns an error", func() {
+			Ω(newErr).Should(HaveOccurred())
+			Ω(newErr.Error()).Should(ContainSubstring(`query "x-trace" and header "X-Trace" use the same argument name xTrace`))
+		})
+	})
+})
@@ -0,0 +1,322 @@
+package gengraphql
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/version"
+)
+
+// schemaTmpl is the template used to render the SDL schema.
+var schemaTmpl = template.Must(template.New("schema").Funcs(template.FuncMap{
+	"description": description,
+	"args":        args,
+}).Parse(schemaT))
+
+// WriteSchema renders the schema in the GraphQL schema definition language, title is written in
+// the header comment.
+func (s *Schema) WriteSchema(title string) ([]byte, error) {
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"Title":       title,
+		"ToolVersion": version.String(),
+		"Schema":      s,
+	}
+	if err := schemaTmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Resolvers returns the Go code of the Resolver methods that resolve the Query and Mutation
+// fields. appPkg is the name of the goa "app" package. The code assumes that it belongs to the
+// package that defines the Resolver type.
+func (s *Schema) Resolvers(appPkg string) string {
+	w := &resolverWriter{appPkg: appPkg}
+	for _, f := range s.Queries {
+		w.resolver(f, "query")
+	}
+	for _, f := range s.Mutations {
+		w.resolver(f, "mutation")
+	}
+	return w.buf.String()
+}
+
+// description renders the given text as a GraphQL description followed by a new line, indent
+// is prepended to each line.
+func description(indent, text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+	if !strings.Contains(text, "\n") {
+		return indent + quote(text) + "\n"
+	}
+	lines := strings.Split(strings.Replace(text, `"""`, `\"""`, -1), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(indent+l, " ")
+	}
+	return indent + `"""` + "\n" + strings.Join(lines, "\n") + "\n" + indent + `"""` + "\n"
+}
+
+// args renders the arguments of a Query or Mutation field, the arguments are written on
+// separate lines when at least one of them has a description.
+func args(as []*Arg) string {
+	if len(as) == 0 {
+		return ""
+	}
+	multiline := false
+	for _, a := range as {
+		if a.Description != "" {
+			multiline = true
+			break
+		}
+	}
+	defs := make([]string, len(as))
+	for i, a := range as {
+		defs[i] = a.Name + ": " + a.Type
+		if a.Default != "" {
+			defs[i] += " = " + a.Default
+		}
+		if multiline {
+			defs[i] = description("    ", a.Description) + "    " + defs[i]
+		}
+	}
+	if !multiline {
+		return "(" + strings.Join(defs, ", ") + ")"
+	}
+	return "(\n" + strings.Join(defs, "\n") + "\n  )"
+}
+
+// resolverWriter generates the resolver methods.
+type resolverWriter struct {
+	appPkg string
+	buf    bytes.Buffer
+}
+
+// resolver writes the method that resolves the given Query or Mutation field by serving the
+// request of its action with the service.
+func (w *resolverWriter) resolver(f *Field, kind string) {
+	a := f.Action
+	method := codegen.Goify(f.Name, true)
+	params := []string{"ctx context.Context"}
+	names := make(map[*Arg]string, len(f.Args))
+	for _, arg := range f.Args {
+		names[arg] = paramName(arg.Name)
+		params = append(params, names[arg]+" "+w.argType(arg))
+	}
+	returns := "bool"
+	if f.Result != nil {
+		returns = w.goType(f.Result)
+	}
+
+	fmt.Fprintf(&w.buf, "// %s resolves the %s %s with the %s action of the %s resource.\n", method, f.Name, kind, a.Name, a.Parent.Name)
+	fmt.Fprintf(&w.buf, "func (r *Resolver) %s(%s) (%s, error) {\n", method, strings.Join(params, ", "), returns)
+
+	path := codegen.PathParamRegex.ReplaceAllStringFunc(a.Routes[0].FullPath(), func(p string) string {
+		for _, arg := range f.Args {
+			if arg.In == "path" && arg.AttName == p[1:] {
+				v := fmt.Sprintf("format(%s)", names[arg])
+				if p[0] == ':' {
+					v = fmt.Sprintf("url.PathEscape(%s)", v)
+				}
+				return `" + ` + v + ` + "`
+			}
+		}
+		return p
+	})
+	path = strings.TrimSuffix(`"`+path+`"`, ` + ""`)
+	w.line(1, "path := %s", path)
+
+	w.line(1, "query := url.Values{}")
+	for _, arg := range f.Args {
+		if arg.In == "query" {
+			w.set(arg, names[arg], fmt.Sprintf("query.Add(%q, %%s)", arg.AttName))
+		}
+	}
+	w.line(1, "header := http.Header{}")
+	for _, arg := range f.Args {
+		if arg.In == "header" {
+			w.set(arg, names[arg], fmt.Sprintf("header.Add(%q, %%s)", arg.AttName))
+		}
+	}
+	body := "nil"
+	for _, arg := range f.Args {
+		if arg.In == "body" {
+			body = "body"
+			w.line(1, "var body interface{}")
+			w.line(1, "if %s != nil {", names[arg])
+			w.line(2, "body = %s", names[arg])
+			w.line(1, "}")
+		}
+	}
+	verb := fmt.Sprintf("%q", a.Routes[0].Verb)
+	if f.Result == nil {
+		w.line(1, "if err := r.serve(ctx, %s, path, query, header, %s, nil); err != nil {", verb, body)
+		w.line(2, "return false, err")
+		w.line(1, "}")
+		w.line(1, "return true, nil")
+	} else {
+		w.line(1, "var res %s", returns)
+		w.line(1, "err := r.serve(ctx, %s, path, query, header, %s, &res)", verb, body)
+		w.line(1, "return res, err")
+	}
+	w.buf.WriteString("}\n\n")
+}
+
+// set writes the code that calls add with the formatted values of the argument, add is a format
+// string with one verb for the value.
+func (w *resolverWriter) set(arg *Arg, name, add string) {
+	switch {
+	case arg.Attribute.Type.IsArray():
+		w.line(1, "for _, v := range %s {", name)
+		w.line(2, add, "format(v)")
+		w.line(1, "}")
+	case !arg.Required && arg.Attribute.Type.IsPrimitive():
+		w.line(1, "if %s != nil {", name)
+		w.line(2, add, "format(*"+name+")")
+		w.line(1, "}")
+	default:
+		w.line(1, add, "format("+name+")")
+	}
+}
+
+// argType returns the Go type of the parameter of the resolver method that corresponds to the
+// argument, optional primitive values are pointers.
+func (w *resolverWriter) argType(arg *Arg) string {
+	typ := w.goType(arg.Attribute.Type)
+	if !arg.Required && arg.Attribute.Type.IsPrimitive() {
+		return "*" + typ
+	}
+	return typ
+}
+
+// goType returns the goa Go type of the values of the given type qualified with the app package.
+func (w *resolverWriter) goType(t design.DataType) string {
+	switch actual := t.(type) {
+	case design.Primitive:
+		return codegen.GoNativeType(actual)
+	case *design.Array:
+		return "[]" + w.goType(actual.ElemType.Type)
+	case *design.Hash:
+		return fmt.Sprintf("map[%s]%s", w.goType(actual.KeyType.Type), w.goType(actual.ElemType.Type))
+	case design.Object:
+		return "map[string]interface{}"
+	default:
+		name := w.appPkg + "." + codegen.GoTypeName(t, nil, 0, false)
+		if userType(t).Type.IsObject() {
+			return "*" + name
+		}
+		return name
+	}
+}
+
+// line writes a line of code indented with the given number of tabs.
+func (w *resolverWriter) line(tabs int, format string, args ...interface{}) {
+	w.buf.WriteString(strings.Repeat("\t", tabs))
+	fmt.Fprintf(&w.buf, format, args...)
+	w.buf.WriteString("\n")
+}
+
+// paramName returns the name of the Go parameter that corresponds to the given argument name,
+// the names used by the receiver and by the local variables of the resolvers get the "Arg"
+// suffix.
+func paramName(n string) string {
+	name := codegen.Goify(n, false)
+	switch name {
+	case "r", "ctx", "path", "query", "header", "body", "res", "err", "v":
+		name += "Arg"
+	}
+	return name
+}
+
+const schemaT = `# Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+#
+# {{ .Title }}
+{{ range .Schema.Scalars }}
+scalar {{ . }}
+{{ end }}{{ if .Schema.Queries }}
+type Query {
+{{ range .Schema.Queries }}{{ description "  " .Description }}  {{ .Name }}{{ args .Args }}: {{ .Type }}
+{{ end }}}
+{{ end }}{{ if .Schema.Mutations }}
+type Mutation {
+{{ range .Schema.Mutations }}{{ description "  " .Description }}  {{ .Name }}{{ args .Args }}: {{ .Type }}
+{{ end }}}
+{{ end }}{{ range .Schema.Types }}
+{{ description "" .Description }}{{ if .Input }}input{{ else }}type{{ end }} {{ .Name }} {
+{{ range .Fields }}{{ description "  " .Description }}  {{ .Name }}: {{ .Type }}
+{{ end }}}
+{{ end }}`
+
+// resolverT is the template of the Resolver type and of the helpers used by the resolvers.
+const resolverT = `// Resolver resolves the fields of the Query and Mutation types of the GraphQL schema. Each
+// resolver serves the request of the corresponding action with the handlers mounted on the
+// service so that the existing controllers, middlewares and validations process it. A resolver
+// returns a *ResponseError when the action responds with a status other than a success status.
+type Resolver struct {
+	// Service is the goa service the controllers are mounted on.
+	Service *goa.Service
+}
+
+// NewResolver returns a resolver that serves the requests with the given service.
+func NewResolver(service *goa.Service) *Resolver {
+	return &Resolver{Service: service}
+}
+
+// ResponseError is the error returned by the resolvers when the action responds with a status
+// other than a success status.
+type ResponseError struct {
+	// Status is the status of the response.
+	Status int
+	// Body is the body of the response.
+	Body string
+}
+
+// Error returns the status and body of the response.
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, http.StatusText(e.Status), e.Body)
+}
+
+// serve serves the request made of the given method, path, query, header and JSON payload with
+// the service and decodes the JSON body of the response into res unless res is nil.
+func (r *Resolver) serve(ctx context.Context, method, path string, query url.Values, header http.Header, payload, res interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+		header.Set("Content-Type", "application/json")
+	}
+	u := url.URL{Path: path, RawQuery: query.Encode()}
+	req, err := http.NewRequestWithContext(ctx, method, u.RequestURI(), body)
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	rec := httptest.NewRecorder()
+	r.Service.Mux.ServeHTTP(rec, req)
+	if rec.Code < http.StatusOK || rec.Code >= http.StatusMultipleChoices {
+		return &ResponseError{Status: rec.Code, Body: rec.Body.String()}
+	}
+	if res == nil || rec.Body.Len() == 0 {
+		return nil
+	}
+	return json.Unmarshal(rec.Body.Bytes(), res)
+}
+
+// format returns the string representation of the given parameter or header value.
+func format(v interface{}) string {
+	if t, ok := v.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
+
+`
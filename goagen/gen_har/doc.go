@@ -0,0 +1,9 @@
+/*
+Package genhar provides a generator for a HAR (HTTP Archive) file of the API. The generated
+archive follows the HAR 1.2 format and contains one entry per action made of an example request
+and of the example response with the lowest success status. The requests and responses are built
+from the examples of the design so that tools that ingest HAR files such as mock proxies, traffic
+replayers and API diff services can use the archive directly. WebSocket actions are skipped.
+See http://www.softwareishard.com/blog/har-12-spec for more information on the format.
+*/
+package genhar
@@ -0,0 +1,13 @@
+package genhar_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenHAR(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenHAR Suite")
+}
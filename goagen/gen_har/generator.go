@@ -0,0 +1,104 @@
+package genhar
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of a HAR Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the HAR generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, ver string
+
+	set := flag.NewFlagSet("har", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the HAR file.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	h, err := New(g.API)
+	if err != nil {
+		return nil, err
+	}
+
+	harDir := filepath.Join(g.OutDir, "har")
+	codegen.RemoveAll(harDir)
+	if err = os.MkdirAll(harDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, harDir)
+
+	// do not escape the HTML characters so that the URLs and bodies of the archive stay readable
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(h); err != nil {
+		return nil, err
+	}
+	file := filepath.Join(harDir, fileName(g.API))
+	if err := codegen.WriteFile(file, buf.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, file)
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
@@ -0,0 +1,426 @@
+package genhar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/version"
+)
+
+const (
+	// Version is the version of the HAR format of the generated archives.
+	Version = "1.2"
+
+	// StartedDateTime is the start time of all the entries, a fixed time keeps the generated
+	// archives reproducible.
+	StartedDateTime = "1970-01-01T00:00:00.000Z"
+
+	// Boundary is the boundary of the example multipart bodies.
+	Boundary = "goagen-har-example-boundary"
+)
+
+type (
+	// HAR represents a HTTP Archive.
+	HAR struct {
+		Log *Log `json:"log"`
+	}
+
+	// Log is the root of the archive.
+	Log struct {
+		// Version of the format.
+		Version string `json:"version"`
+		// Creator describes the application that created the archive.
+		Creator *Creator `json:"creator"`
+		// Comment describes the archive.
+		Comment string `json:"comment,omitempty"`
+		// Entries lists the recorded requests.
+		Entries []*Entry `json:"entries"`
+	}
+
+	// Creator describes the application that created the archive.
+	Creator struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	// Entry is a request and its response.
+	Entry struct {
+		// StartedDateTime is the date and time of the request in ISO 8601 format.
+		StartedDateTime string `json:"startedDateTime"`
+		// Time is the total duration of the request in milliseconds.
+		Time int `json:"time"`
+		// Request describes the request.
+		Request *Request `json:"request"`
+		// Response describes the response.
+		Response *Response `json:"response"`
+		// Cache describes the cache usage, the archives do not record any.
+		Cache struct{} `json:"cache"`
+		// Timings details the duration of the request.
+		Timings *Timings `json:"timings"`
+		// Comment identifies the action of the entry.
+		Comment string `json:"comment,omitempty"`
+	}
+
+	// Request describes a HTTP request.
+	Request struct {
+		Method      string       `json:"method"`
+		URL         string       `json:"url"`
+		HTTPVersion string       `json:"httpVersion"`
+		Cookies     []*NameValue `json:"cookies"`
+		Headers     []*NameValue `json:"headers"`
+		QueryString []*NameValue `json:"queryString"`
+		PostData    *PostData    `json:"postData,omitempty"`
+		// HeadersSize is always -1 as the headers are not serialized.
+		HeadersSize int `json:"headersSize"`
+		// BodySize is the size of the body in bytes.
+		BodySize int `json:"bodySize"`
+	}
+
+	// Response describes a HTTP response.
+	Response struct {
+		Status      int          `json:"status"`
+		StatusText  string       `json:"statusText"`
+		HTTPVersion string       `json:"httpVersion"`
+		Cookies     []*NameValue `json:"cookies"`
+		Headers     []*NameValue `json:"headers"`
+		Content     *Content     `json:"content"`
+		RedirectURL string       `json:"redirectURL"`
+		// HeadersSize is always -1 as the headers are not serialized.
+		HeadersSize int `json:"headersSize"`
+		// BodySize is the size of the body in bytes.
+		BodySize int `json:"bodySize"`
+	}
+
+	// NameValue describes a header, a query string parameter or a cookie.
+	NameValue struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	// PostData describes the body of a request.
+	PostData struct {
+		MimeType string `json:"mimeType"`
+		// Text is the serialized body.
+		Text string `json:"text"`
+		// Params lists the parts of multipart bodies.
+		Params []*Param `json:"params,omitempty"`
+	}
+
+	// Param describes a part of a multipart body.
+	Param struct {
+		Name        string `json:"name"`
+		Value       string `json:"value,omitempty"`
+		FileName    string `json:"fileName,omitempty"`
+		ContentType string `json:"contentType,omitempty"`
+	}
+
+	// Content describes the body of a response.
+	Content struct {
+		Size     int    `json:"size"`
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text,omitempty"`
+	}
+
+	// Timings details the duration of a request in milliseconds.
+	Timings struct {
+		Send    int `json:"send"`
+		Wait    int `json:"wait"`
+		Receive int `json:"receive"`
+	}
+)
+
+// New builds the HTTP archive of the API. Each action with at least one response gets an entry
+// made of the example request of its first route and of the example response with the lowest
+// success status, or with the lowest status if the action defines no success response.
+// WebSocket actions are skipped.
+func New(api *design.APIDefinition) (*HAR, error) {
+	h := &HAR{Log: &Log{
+		Version: Version,
+		Creator: &Creator{Name: "goagen", Version: version.String()},
+		Comment: api.Title,
+		Entries: []*Entry{},
+	}}
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() || len(a.Routes) == 0 {
+				return nil
+			}
+			r := exampleResponse(a)
+			if r == nil {
+				return nil
+			}
+			req, err := request(api, a)
+			if err != nil {
+				return fmt.Errorf("%s: %s", a.Context(), err)
+			}
+			resp, err := response(api, r)
+			if err != nil {
+				return fmt.Errorf("%s: response %#v: %s", a.Context(), r.Name, err)
+			}
+			h.Log.Entries = append(h.Log.Entries, &Entry{
+				StartedDateTime: StartedDateTime,
+				Request:         req,
+				Response:        resp,
+				Timings:         &Timings{},
+				Comment:         fmt.Sprintf("%s %s", a.Name, res.Name),
+			})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// exampleResponse returns the response of the entry of the given action, nil if the action has
+// no response.
+func exampleResponse(a *design.ActionDefinition) *design.ResponseDefinition {
+	var responses []*design.ResponseDefinition
+	a.IterateResponses(func(r *design.ResponseDefinition) error {
+		responses = append(responses, r)
+		return nil
+	})
+	if len(responses) == 0 {
+		return nil
+	}
+	sort.Slice(responses, func(i, j int) bool { return responses[i].Status < responses[j].Status })
+	for _, r := range responses {
+		if r.Status >= 200 && r.Status < 300 {
+			return r
+		}
+	}
+	return responses[0]
+}
+
+// request builds the example request of the given action from the design examples.
+func request(api *design.APIDefinition, a *design.ActionDefinition) (*Request, error) {
+	route := a.Routes[0]
+	rand := api.RandomGenerator()
+	req := &Request{
+		Method:      route.Verb,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []*NameValue{},
+		Headers:     []*NameValue{},
+		QueryString: []*NameValue{},
+		HeadersSize: -1,
+	}
+	all := a.AllParams().Type.ToObject()
+	pathParams := make(map[string]bool)
+	path := make(map[string]string)
+	for _, n := range route.Params() {
+		pathParams[n] = true
+		if at, ok := all[n]; ok {
+			path[n] = format(at.GenerateExample(rand, nil))
+		}
+	}
+	u := baseURL(api) + codegen.PathParamRegex.ReplaceAllStringFunc(route.FullPath(), func(p string) string {
+		v := path[p[1:]]
+		if p[0] == '*' {
+			return (&url.URL{Path: v}).EscapedPath()
+		}
+		return url.PathEscape(v)
+	})
+	if a.QueryParams != nil {
+		query := url.Values{}
+		obj := a.QueryParams.Type.ToObject()
+		for _, n := range codegen.SortedNames(obj) {
+			if pathParams[n] {
+				continue
+			}
+			for _, v := range values(obj[n].GenerateExample(rand, nil)) {
+				query.Add(n, v)
+				req.QueryString = append(req.QueryString, &NameValue{Name: n, Value: v})
+			}
+		}
+		if len(query) > 0 {
+			u += "?" + query.Encode()
+		}
+	}
+	req.URL = u
+	a.IterateHeaders(func(n string, _ bool, at *design.AttributeDefinition) error {
+		req.Headers = append(req.Headers, &NameValue{Name: n, Value: strings.Join(values(at.GenerateExample(rand, nil)), ",")})
+		return nil
+	})
+	if a.Payload == nil {
+		return req, nil
+	}
+	var err error
+	if a.PayloadMultipart {
+		req.PostData, err = multipartBody(a.Payload, rand)
+	} else {
+		req.PostData, err = jsonBody(a.Payload, rand)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("payload example: %s", err)
+	}
+	req.Headers = append(req.Headers, &NameValue{Name: "Content-Type", Value: req.PostData.MimeType})
+	req.BodySize = len(req.PostData.Text)
+	return req, nil
+}
+
+// jsonBody returns the JSON body made of the example of the given payload.
+func jsonBody(payload *design.UserTypeDefinition, rand *design.RandomGenerator) (*PostData, error) {
+	raw, err := json.Marshal(payload.GenerateExample(rand, nil))
+	if err != nil {
+		return nil, err
+	}
+	return &PostData{MimeType: "application/json", Text: string(raw)}, nil
+}
+
+// multipartBody returns the multipart body made of the examples of the attributes of the given
+// payload, the file parts are empty and named after the file examples. The attributes without
+// example are omitted.
+func multipartBody(payload *design.UserTypeDefinition, rand *design.RandomGenerator) (*PostData, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := w.SetBoundary(Boundary); err != nil {
+		return nil, err
+	}
+	body := &PostData{MimeType: w.FormDataContentType()}
+	obj := payload.ToObject()
+	for _, n := range codegen.SortedNames(obj) {
+		at := obj[n]
+		ex := at.GenerateExample(rand, nil)
+		if ex == nil {
+			continue
+		}
+		v := format(ex)
+		if at.Type.Kind() == design.FileKind {
+			if _, err := w.CreateFormFile(n, v); err != nil {
+				return nil, err
+			}
+			body.Params = append(body.Params, &Param{Name: n, FileName: v, ContentType: "application/octet-stream"})
+			continue
+		}
+		if err := w.WriteField(n, v); err != nil {
+			return nil, err
+		}
+		body.Params = append(body.Params, &Param{Name: n, Value: v})
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	body.Text = buf.String()
+	return body, nil
+}
+
+// response builds the example response for the given response definition. The body is the
+// example of the rendered view of the response media type.
+func response(api *design.APIDefinition, r *design.ResponseDefinition) (*Response, error) {
+	rand := api.RandomGenerator()
+	resp := &Response{
+		Status:      r.Status,
+		StatusText:  http.StatusText(r.Status),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []*NameValue{},
+		Headers:     []*NameValue{},
+		Content:     &Content{},
+		HeadersSize: -1,
+	}
+	if r.Headers != nil {
+		obj := r.Headers.Type.ToObject()
+		for _, n := range codegen.SortedNames(obj) {
+			resp.Headers = append(resp.Headers, &NameValue{Name: n, Value: strings.Join(values(obj[n].GenerateExample(rand, nil)), ",")})
+		}
+	}
+	var (
+		at          *design.AttributeDefinition
+		contentType string
+	)
+	if r.MediaType != "" {
+		mt := api.MediaTypeWithIdentifier(r.MediaType)
+		if mt == nil && design.CanonicalIdentifier(r.MediaType) == design.CanonicalIdentifier(design.ErrorMedia.Identifier) {
+			mt = design.ErrorMedia
+		}
+		if mt == nil {
+			return resp, nil
+		}
+		contentType = mt.Identifier
+		view := r.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return nil, err
+		}
+		at = p.AttributeDefinition
+	} else if r.Type != nil {
+		at = &design.AttributeDefinition{Type: r.Type}
+		contentType = "application/json"
+	}
+	if at == nil {
+		return resp, nil
+	}
+	raw, err := json.Marshal(at.GenerateExample(rand, nil))
+	if err != nil {
+		return nil, fmt.Errorf("example: %s", err)
+	}
+	resp.Headers = append(resp.Headers, &NameValue{Name: "Content-Type", Value: contentType})
+	resp.Content = &Content{Size: len(raw), MimeType: contentType, Text: string(raw)}
+	resp.BodySize = len(raw)
+	return resp, nil
+}
+
+// fileName returns the name of the archive of the given API, the API name in lower case with
+// dashes in place of the characters that cannot appear in file names.
+func fileName(api *design.APIDefinition) string {
+	name := strings.Trim(nonNameRegex.ReplaceAllString(strings.ToLower(api.Name), "-"), "-")
+	if name == "" {
+		name = "api"
+	}
+	return name + ".har"
+}
+
+// baseURL returns the URL of the API used by the example requests.
+func baseURL(api *design.APIDefinition) string {
+	scheme := "http"
+	if len(api.Schemes) > 0 {
+		scheme = api.Schemes[0]
+	}
+	host := api.Host
+	if host == "" {
+		host = "localhost:8080"
+	}
+	return scheme + "://" + host
+}
+
+// values returns the query string values of the given example.
+func values(v interface{}) []string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return []string{format(v)}
+	}
+	vals := make([]string, rv.Len())
+	for i := range vals {
+		vals[i] = format(rv.Index(i).Interface())
+	}
+	return vals
+}
+
+// format returns the string representation of the given example value, strings are returned
+// as is and other values as JSON.
+func format(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// nonNameRegex matches the characters that are replaced in archive file names.
+var nonNameRegex = regexp.MustCompile(`[^a-z0-9_.]+`)
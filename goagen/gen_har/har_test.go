@@ -0,0 +1,170 @@
+package genhar_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genhar "github.com/kyokomi/goa-v1/goagen/gen_har"
+)
+
+var _ = Describe("New", func() {
+	var har *genhar.HAR
+	var newErr error
+
+	BeforeEach(func() {
+		har = nil
+		newErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		har, newErr = genhar.New(Design)
+	})
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer, func() {
+						apidsl.Example(1)
+					})
+					apidsl.Attribute("name", String, func() {
+						apidsl.Example("Number 8")
+					})
+					apidsl.Required("id")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+				})
+			})
+			apidsl.API("cellar", func() {
+				apidsl.Title("The cellar API")
+				apidsl.Host("cellar.example.com")
+				apidsl.Scheme("https")
+				apidsl.BasePath("/api")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", Integer, func() {
+							apidsl.Example(1)
+						})
+						apidsl.Param("fields", apidsl.ArrayOf(String), func() {
+							apidsl.Example([]string{"id", "name"})
+						})
+					})
+					apidsl.Headers(func() {
+						apidsl.Header("X-Request-Id", func() {
+							apidsl.Example("a&b")
+						})
+					})
+					apidsl.Response(OK, bottle)
+					apidsl.Response(NotFound)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(func() {
+						apidsl.Attribute("name", String, func() {
+							apidsl.Example("Number 8")
+						})
+					})
+					apidsl.Response(BadRequest)
+					apidsl.Response(Created, func() {
+						apidsl.Headers(func() {
+							apidsl.Header("Location", func() {
+								apidsl.Example("/api/bottles/1")
+							})
+						})
+					})
+				})
+				apidsl.Action("fail", func() {
+					apidsl.Routing(apidsl.DELETE("/:id"))
+					apidsl.Response(NotFound)
+					apidsl.Response(BadRequest)
+				})
+				apidsl.Action("upload", func() {
+					apidsl.Routing(apidsl.POST("/:id/label"))
+					apidsl.MultipartForm()
+					apidsl.Payload(func() {
+						apidsl.Member("label", File)
+						apidsl.Member("note", String, func() {
+							apidsl.Example("front")
+						})
+					})
+					apidsl.Response(NoContent)
+				})
+				apidsl.Action("none", func() {
+					apidsl.Routing(apidsl.GET("/none"))
+				})
+			})
+		})
+
+		It("describes the archive", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(har.Log.Version).Should(Equal("1.2"))
+			Ω(har.Log.Creator.Name).Should(Equal("goagen"))
+			Ω(har.Log.Comment).Should(Equal("The cellar API"))
+			Ω(har.Log.Entries).Should(HaveLen(4))
+			var comments []string
+			for _, e := range har.Log.Entries {
+				comments = append(comments, e.Comment)
+				Ω(e.StartedDateTime).Should(Equal(genhar.StartedDateTime))
+			}
+			Ω(comments).Should(Equal([]string{"create bottle", "fail bottle", "show bottle", "upload bottle"}))
+		})
+
+		It("builds the requests from the examples", func() {
+			req := har.Log.Entries[2].Request
+			Ω(req.Method).Should(Equal("GET"))
+			Ω(req.URL).Should(Equal("https://cellar.example.com/api/bottles/1?fields=id&fields=name"))
+			Ω(req.QueryString).Should(Equal([]*genhar.NameValue{{Name: "fields", Value: "id"}, {Name: "fields", Value: "name"}}))
+			Ω(req.Headers).Should(Equal([]*genhar.NameValue{{Name: "X-Request-Id", Value: "a&b"}}))
+			Ω(req.PostData).Should(BeNil())
+			Ω(req.HeadersSize).Should(Equal(-1))
+
+			create := har.Log.Entries[0].Request
+			Ω(create.Headers).Should(Equal([]*genhar.NameValue{{Name: "Content-Type", Value: "application/json"}}))
+			Ω(create.PostData.Text).Should(Equal(`{"name":"Number 8"}`))
+			Ω(create.BodySize).Should(Equal(len(`{"name":"Number 8"}`)))
+		})
+
+		It("builds the multipart requests", func() {
+			body := har.Log.Entries[3].Request.PostData
+			Ω(body.MimeType).Should(Equal("multipart/form-data; boundary=" + genhar.Boundary))
+			Ω(body.Params).Should(HaveLen(2))
+			label := body.Params[0]
+			Ω(label.Name).Should(Equal("label"))
+			Ω(label.FileName).Should(HaveSuffix("jpg"))
+			Ω(label.ContentType).Should(Equal("application/octet-stream"))
+			Ω(body.Params[1]).Should(Equal(&genhar.Param{Name: "note", Value: "front"}))
+			Ω(body.Text).Should(ContainSubstring("Content-Disposition: form-data; name=\"label\"; filename=\"" + label.FileName + "\"\r\n"))
+			Ω(body.Text).Should(HaveSuffix("\r\nfront\r\n--" + genhar.Boundary + "--\r\n"))
+		})
+
+		It("picks the lowest success response", func() {
+			show := har.Log.Entries[2].Response
+			Ω(show.Status).Should(Equal(200))
+			Ω(show.StatusText).Should(Equal("OK"))
+			Ω(show.Headers).Should(Equal([]*genhar.NameValue{{Name: "Content-Type", Value: "application/vnd.bottle+json"}}))
+			Ω(show.Content.MimeType).Should(Equal("application/vnd.bottle+json"))
+			Ω(show.Content.Text).Should(Equal(`{"id":1,"name":"Number 8"}`))
+			Ω(show.Content.Size).Should(Equal(show.BodySize))
+
+			create := har.Log.Entries[0].Response
+			Ω(create.Status).Should(Equal(201))
+			Ω(create.Headers).Should(Equal([]*genhar.NameValue{{Name: "Location", Value: "/api/bottles/1"}}))
+			Ω(create.Content.Text).Should(BeEmpty())
+
+			Ω(har.Log.Entries[1].Response.Status).Should(Equal(400))
+		})
+	})
+})
@@ -0,0 +1,17 @@
+/*
+Package genjava provides a generator for a Java 11+ client of the API built on java.net.http and
+Jackson. The generator is invoked with "goagen client --language=java" and produces a Maven
+project that contains:
+
+  - pom.xml: the build file that declares the jackson-databind dependency,
+  - an immutable model class with a builder for each user type, media type view and payload and
+    an enum for each string attribute that declares an Enum validation,
+  - Client.java: a Client class with one method per action route.
+
+The methods return the decoded body of the successful responses or an abstract result class with
+one nested class per response when the successful responses have different bodies. The declared
+error responses are thrown as the nested classes of an abstract exception class generated for
+each method, the statuses that are not described in the design are thrown as
+UnexpectedResponseException.
+*/
+package genjava
@@ -0,0 +1,155 @@
+package genjava
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of a Java Client Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the Java client generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Target   string                // Name of generated Java package, defaults to "<API name>.client"
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, target, ver string
+
+	set := flag.NewFlagSet("client", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&target, "pkg", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("language", "java", "")
+	set.String("tooldir", "", "")
+	set.String("tool", "", "")
+	set.Bool("notool", false, "")
+	set.Bool("regen", false, "")
+	set.String("design", "", "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Bool("enums", false, "")
+	set.Bool("validate-tags", false, "")
+	set.Bool("mock", false, "")
+	set.Parse(os.Args[1:])
+
+	// First check compatibility
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	// Now proceed
+	g := &Generator{OutDir: outDir, Target: target, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the Java client project.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.Target == "" {
+		g.Target = strings.ToLower(codegen.Goify(g.API.Name, true)) + ".client"
+	}
+	p, err := New(g.API, packageName(g.Target), g.baseURL())
+	if err != nil {
+		return nil, err
+	}
+
+	projectDir := filepath.Join(g.OutDir, "java")
+	if err = codegen.RemoveAll(projectDir); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, projectDir)
+
+	title := fmt.Sprintf("%s: Java Client", g.API.Context())
+	for _, name := range p.Files() {
+		content, err := p.Write(name, title)
+		if err != nil {
+			return nil, err
+		}
+		file := filepath.Join(projectDir, name)
+		if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return nil, err
+		}
+		if err = codegen.WriteFile(file, content, 0644); err != nil {
+			return nil, err
+		}
+		g.genfiles = append(g.genfiles, file)
+	}
+
+	return g.genfiles, nil
+}
+
+// packageName returns the Java package name that corresponds to the given target, each
+// segment is made of lower case letters, digits and underscores.
+func packageName(target string) string {
+	var segments []string
+	for _, s := range strings.Split(target, ".") {
+		s = strings.ToLower(strings.Trim(invalidNameChars.ReplaceAllString(s, "_"), "_"))
+		if s == "" {
+			continue
+		}
+		if s[0] >= '0' && s[0] <= '9' {
+			s = "_" + s
+		}
+		if reservedWords[s] {
+			s += "_"
+		}
+		segments = append(segments, s)
+	}
+	if len(segments) == 0 {
+		return "client"
+	}
+	return strings.Join(segments, ".")
+}
+
+// baseURL returns the default base URL of the client.
+func (g *Generator) baseURL() string {
+	host := g.API.Host
+	if host == "" {
+		host = "localhost:8080"
+	}
+	scheme := "http"
+	if len(g.API.Schemes) > 0 {
+		scheme = g.API.Schemes[0]
+	}
+	return scheme + "://" + host
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
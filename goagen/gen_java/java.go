@@ -0,0 +1,810 @@
+package genjava
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+type (
+	// Package describes the content of the generated Java package.
+	Package struct {
+		// Name of the Java package, e.g. "cellar.client".
+		Name string
+		// Version is the version of the Maven artifact.
+		Version string
+		// BaseURL is the default base URL of the client.
+		BaseURL string
+		// Classes lists the model classes sorted by name.
+		Classes []*Class
+		// Enums lists the enums generated for the string attributes that declare an Enum
+		// validation sorted by name.
+		Enums []*Enum
+		// Methods lists the client methods sorted by name.
+		Methods []*Method
+	}
+
+	// Class describes an immutable model class.
+	Class struct {
+		// Name of the class.
+		Name string
+		// Description of the class.
+		Description string
+		// Fields lists the class fields sorted by name.
+		Fields []*Field
+		// DataType is the goa type described by the class.
+		DataType design.DataType
+	}
+
+	// Field describes a class field.
+	Field struct {
+		// Name of the field.
+		Name string
+		// AttName is the name of the attribute in the JSON representation.
+		AttName string
+		// Type of the field, primitive types are boxed.
+		Type string
+		// Required is true if the attribute is required.
+		Required bool
+		// Description of the field.
+		Description string
+	}
+
+	// Enum describes an enum generated for a string attribute that declares an Enum validation.
+	Enum struct {
+		// Name of the enum.
+		Name string
+		// Description of the enum.
+		Description string
+		// Constants lists the enum constants in order of declaration.
+		Constants []*EnumConstant
+	}
+
+	// EnumConstant describes an enum constant.
+	EnumConstant struct {
+		// Name of the constant.
+		Name string
+		// Value is the serialized value of the constant.
+		Value string
+	}
+
+	// Method describes a client method that sends requests to an action route.
+	Method struct {
+		// Name of the method.
+		Name string
+		// Description of the method.
+		Description string
+		// Verb is the HTTP method of the requests.
+		Verb string
+		// Path is the Java expression that builds the request path.
+		Path string
+		// Args lists the method arguments, required arguments first.
+		Args []*Arg
+		// Query lists the statements that add the query string parameters to _query.
+		Query []string
+		// Headers lists the statements that add the request headers to _headers.
+		Headers []string
+		// Body lists the statements that declare the _body value, empty if the action has no
+		// payload.
+		Body []string
+		// Multipart is true if the payload is encoded as multipart form data.
+		Multipart bool
+		// Responses lists the declared responses sorted by status.
+		Responses []*Response
+		// Returns is the return type of the method, "void" if it returns nothing.
+		Returns string
+		// Result is the abstract class returned by the methods that declare successful
+		// responses with different bodies, empty otherwise.
+		Result string
+		// Error is the name of the abstract exception class thrown for the declared error
+		// responses, empty if there is none.
+		Error string
+	}
+
+	// Arg describes a method argument.
+	Arg struct {
+		// Name of the argument.
+		Name string
+		// Type of the argument.
+		Type string
+		// Optional is true if the argument may be null.
+		Optional bool
+	}
+
+	// Response describes a declared response.
+	Response struct {
+		// Status is the HTTP status code of the response.
+		Status int
+		// Class is the name of the nested class of the result or exception class that
+		// describes the response.
+		Class string
+		// Body is the type of the decoded body, empty if the response has no body.
+		Body string
+		// Text is true if the body is read as text instead of being decoded from JSON.
+		Text bool
+		// Error is true if the response is not a 2xx response.
+		Error bool
+	}
+)
+
+// reservedWords lists the Java keywords and literals, identifiers that use one of these get
+// the "_" suffix.
+var reservedWords = map[string]bool{
+	"abstract": true, "assert": true, "boolean": true, "break": true, "byte": true, "case": true,
+	"catch": true, "char": true, "class": true, "const": true, "continue": true, "default": true,
+	"do": true, "double": true, "else": true, "enum": true, "extends": true, "false": true,
+	"final": true, "finally": true, "float": true, "for": true, "goto": true, "if": true,
+	"implements": true, "import": true, "instanceof": true, "int": true, "interface": true,
+	"long": true, "native": true, "new": true, "null": true, "package": true, "private": true,
+	"protected": true, "public": true, "return": true, "short": true, "static": true,
+	"strictfp": true, "super": true, "switch": true, "synchronized": true, "this": true,
+	"throw": true, "throws": true, "transient": true, "true": true, "try": true, "void": true,
+	"volatile": true, "while": true, "var": true, "record": true, "yield": true,
+}
+
+// reservedTypeNames lists the names of the Java standard library and generated types that
+// cannot be used by the generated classes, classes that use one of these names get the "Type"
+// suffix.
+var reservedTypeNames = map[string]bool{
+	"ApiException": true, "Boolean": true, "Builder": true, "Client": true, "Double": true,
+	"Error": true, "Exception": true, "Integer": true, "List": true, "Long": true, "Map": true,
+	"MultipartForm": true, "Object": true, "Objects": true, "Override": true, "String": true,
+	"UnexpectedResponseException": true, "Void": true,
+}
+
+// invalidNameChars matches the characters that cannot be used in Java identifiers.
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// camelBoundary matches the boundaries between the words of camel case names.
+var camelBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
+// New builds the description of the Java client package of the API. The package contains a
+// model class for each user type, media type view and payload, an enum for each string
+// attribute that declares an Enum validation and a client method for each action route.
+func New(api *design.APIDefinition, name, baseURL string) (*Package, error) {
+	b := &builder{api: api, classes: make(map[string]*Class), enums: make(map[string]*Enum)}
+	err := api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		_, err := b.className(ut)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() {
+			return nil
+		}
+		return mt.IterateViews(func(v *design.ViewDefinition) error {
+			p, _, err := mt.Project(v.Name)
+			if err != nil {
+				return err
+			}
+			_, err = b.className(p)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	p := &Package{Name: name, Version: artifactVersion(api.Version), BaseURL: baseURL}
+	err = api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() {
+				return nil
+			}
+			for i, r := range a.Routes {
+				m, err := b.method(a, r, i)
+				if err != nil {
+					return fmt.Errorf("%s: %s", a.Context(), err)
+				}
+				p.Methods = append(p.Methods, m)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(p.Methods, func(i, j int) bool { return p.Methods[i].Name < p.Methods[j].Name })
+	for _, c := range b.classes {
+		p.Classes = append(p.Classes, c)
+	}
+	sort.Slice(p.Classes, func(i, j int) bool { return p.Classes[i].Name < p.Classes[j].Name })
+	for _, e := range b.enums {
+		p.Enums = append(p.Enums, e)
+	}
+	sort.Slice(p.Enums, func(i, j int) bool { return p.Enums[i].Name < p.Enums[j].Name })
+	for _, m := range p.Methods {
+		for _, n := range []string{m.Result, m.Error} {
+			if _, ok := b.classes[n]; ok {
+				return nil, fmt.Errorf("class %s is already defined", n)
+			}
+			if _, ok := b.enums[n]; ok {
+				return nil, fmt.Errorf("class %s is already defined", n)
+			}
+		}
+	}
+	return p, nil
+}
+
+// builder computes the classes, enums and methods.
+type builder struct {
+	api     *design.APIDefinition
+	classes map[string]*Class
+	enums   map[string]*Enum
+}
+
+// method builds the client method that sends requests to the i-th route of the action.
+func (b *builder) method(a *design.ActionDefinition, r *design.RouteDefinition, i int) (*Method, error) {
+	name := codegen.Goify(a.Name, false) + codegen.Goify(a.Parent.Name, true)
+	if i > 0 {
+		name += strconv.Itoa(i + 1)
+	}
+	typeName := codegen.Goify(name, true)
+	m := &Method{
+		Name:        name,
+		Description: a.Description,
+		Verb:        r.Verb,
+		Returns:     "void",
+	}
+	if m.Description == "" {
+		m.Description = fmt.Sprintf("%s calls the %s action of the %s resource.", name, a.Name, a.Parent.Name)
+	}
+
+	var optional []*Arg
+	all := a.AllParams()
+	pathParams := r.Params()
+	interpolations := make(map[string]string)
+	for _, p := range pathParams {
+		var at *design.AttributeDefinition
+		if all != nil {
+			at = all.Type.ToObject()[p]
+		}
+		if at == nil {
+			at = &design.AttributeDefinition{Type: design.String}
+		}
+		typ, err := b.javaType(at, typeName+codegen.Goify(p, true))
+		if err != nil {
+			return nil, fmt.Errorf("parameter %#v: %s", p, err)
+		}
+		arg := &Arg{Name: identifier(p), Type: unboxed(typ)}
+		m.Args = append(m.Args, arg)
+		interpolations[p] = fmt.Sprintf("pathEscape(%s, %t)",
+			paramString(at, arg.Name), strings.Contains(r.FullPath(), "*"+p))
+	}
+	m.Path = pathExpression(r.FullPath(), interpolations)
+
+	addParams := func(params *design.AttributeDefinition, header bool) error {
+		if params == nil {
+			return nil
+		}
+		for _, n := range codegen.SortedNames(params.Type.ToObject()) {
+			if !header && codegen.ContainsString(pathParams, n) {
+				continue
+			}
+			at := params.Type.ToObject()[n]
+			typ, err := b.javaType(at, typeName+codegen.Goify(n, true))
+			if err != nil {
+				return fmt.Errorf("parameter %#v: %s", n, err)
+			}
+			arg := &Arg{Name: identifier(n), Type: typ}
+			if params.IsRequired(n) {
+				arg.Type = unboxed(typ)
+				m.Args = append(m.Args, arg)
+			} else {
+				arg.Optional = true
+				optional = append(optional, arg)
+			}
+			var stmt string
+			switch {
+			case at.Type.IsArray() && header:
+				elem := at.Type.ToArray().ElemType
+				v := arg.Name
+				if elem.Type.Kind() != design.StringKind || isEnum(elem) {
+					v = fmt.Sprintf("%s.stream().map(_v -> %s).toArray(String[]::new)", arg.Name, paramString(elem, "_v"))
+				}
+				stmt = fmt.Sprintf("_headers.put(%s, String.join(\",\", %s));", quote(n), v)
+			case at.Type.IsArray():
+				elem := at.Type.ToArray().ElemType
+				stmt = fmt.Sprintf("%s.forEach(_v -> _query.add(Map.entry(%s, %s)));", arg.Name, quote(n), paramString(elem, "_v"))
+			case header:
+				stmt = fmt.Sprintf("_headers.put(%s, %s);", quote(n), paramString(at, arg.Name))
+			default:
+				stmt = fmt.Sprintf("_query.add(Map.entry(%s, %s));", quote(n), paramString(at, arg.Name))
+			}
+			if arg.Optional {
+				stmt = fmt.Sprintf("if (%s != null) %s", arg.Name, stmt)
+			}
+			if header {
+				m.Headers = append(m.Headers, stmt)
+			} else {
+				m.Query = append(m.Query, stmt)
+			}
+		}
+		return nil
+	}
+	if err := addParams(a.QueryParams, false); err != nil {
+		return nil, err
+	}
+	if err := addParams(a.Headers, true); err != nil {
+		return nil, err
+	}
+
+	if a.Payload != nil {
+		typ, err := b.className(a.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("payload: %s", err)
+		}
+		arg := &Arg{Name: "payload", Type: typ}
+		if a.PayloadOptional {
+			arg.Optional = true
+			optional = append([]*Arg{arg}, optional...)
+		} else {
+			m.Args = append(m.Args, arg)
+		}
+		var stmts []string
+		if a.PayloadMultipart {
+			stmts, err = multipart(a.Payload)
+			if err != nil {
+				return nil, fmt.Errorf("payload: %s", err)
+			}
+			m.Multipart = true
+		} else {
+			stmts = []string{
+				`_headers.put("Content-Type", "application/json");`,
+				"_body = mapper.writeValueAsBytes(payload);",
+			}
+		}
+		if arg.Optional {
+			for i, stmt := range stmts {
+				stmts[i] = "    " + stmt
+			}
+			stmts = append([]string{"if (payload != null) {"}, stmts...)
+			stmts = append(stmts, "}")
+			m.Body = append([]string{"byte[] _body = null;"}, stmts...)
+		} else {
+			last := len(stmts) - 1
+			stmts[last] = "byte[] " + stmts[last]
+			m.Body = stmts
+		}
+	}
+	m.Args = append(m.Args, optional...)
+
+	err := a.IterateResponses(func(resp *design.ResponseDefinition) error {
+		res, err := b.response(resp)
+		if err != nil {
+			return fmt.Errorf("response %#v: %s", resp.Name, err)
+		}
+		m.Responses = append(m.Responses, res)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(m.Responses, func(i, j int) bool { return m.Responses[i].Status < m.Responses[j].Status })
+	var successes []*Response
+	bodies := make(map[string]bool)
+	for _, res := range m.Responses {
+		if res.Error {
+			m.Error = typeName + "Exception"
+			continue
+		}
+		successes = append(successes, res)
+		bodies[res.Body] = true
+	}
+	switch {
+	case len(bodies) > 1:
+		m.Result = typeName + "Result"
+		m.Returns = m.Result
+	case len(successes) > 0 && successes[0].Body != "":
+		m.Returns = successes[0].Body
+	}
+	return m, nil
+}
+
+// response builds the description of the given action response.
+func (b *builder) response(r *design.ResponseDefinition) (*Response, error) {
+	res := &Response{
+		Status: r.Status,
+		Class:  typeName(r.Name),
+		Error:  r.Status < http.StatusOK || r.Status >= http.StatusMultipleChoices,
+	}
+	if r.Status == http.StatusNoContent {
+		return res, nil
+	}
+	if r.MediaType != "" {
+		mt, ok := b.api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]
+		if !ok {
+			res.Body, res.Text = "String", true
+			return res, nil
+		}
+		view := r.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return nil, err
+		}
+		name, err := b.className(p)
+		if err != nil {
+			return nil, err
+		}
+		res.Body = name
+		return res, nil
+	}
+	if r.Type != nil {
+		name, err := b.className(r.Type)
+		if err != nil {
+			return nil, err
+		}
+		res.Body = name
+	}
+	return res, nil
+}
+
+// className registers the class that corresponds to the given user type or media type and
+// returns its name. User types and media types that are not objects do not produce a class,
+// className returns the Java type of their values instead, e.g. "List<Bottle>".
+func (b *builder) className(t design.DataType) (string, error) {
+	t, err := projected(&design.AttributeDefinition{Type: t})
+	if err != nil {
+		return "", err
+	}
+	ut, ok := userType(t)
+	if !ok {
+		return "", fmt.Errorf("%s is not a user type", t.Name())
+	}
+	name := typeName(codegen.GoTypeName(t, nil, 0, false))
+	if !ut.Type.IsObject() {
+		typ, err := b.javaType(ut.AttributeDefinition, name+"Item")
+		if err != nil {
+			return "", fmt.Errorf("type %s: %s", name, err)
+		}
+		return typ, nil
+	}
+	if c, ok := b.classes[name]; ok {
+		if c.DataType.Name() != t.Name() {
+			return "", fmt.Errorf("class %s is already defined", name)
+		}
+		return name, nil
+	}
+	c := &Class{Name: name, Description: ut.Description, DataType: t}
+	b.classes[name] = c
+	obj := ut.Type.ToObject()
+	for _, n := range codegen.SortedNames(obj) {
+		at := obj[n]
+		typ, err := b.javaType(at, name+codegen.Goify(n, true))
+		if err != nil {
+			delete(b.classes, name)
+			return "", fmt.Errorf("type %s: attribute %#v: %s", name, n, err)
+		}
+		c.Fields = append(c.Fields, &Field{
+			Name:        identifier(n),
+			AttName:     n,
+			Type:        typ,
+			Required:    ut.IsRequired(n),
+			Description: at.Description,
+		})
+	}
+	return name, nil
+}
+
+// javaType returns the Java type of the values of the given attribute, primitive types are
+// boxed. enumName is the name of the enum generated if the attribute is a string that declares
+// an Enum validation.
+func (b *builder) javaType(at *design.AttributeDefinition, enumName string) (string, error) {
+	switch actual := at.Type.(type) {
+	case design.Primitive:
+		typ := primitiveType(actual)
+		if !isEnum(at) {
+			return typ, nil
+		}
+		return b.enum(typeName(enumName), at)
+	case *design.Array:
+		elem, err := b.javaType(actual.ElemType, enumName)
+		if err != nil {
+			return "", err
+		}
+		return "List<" + elem + ">", nil
+	case *design.Hash:
+		elem, err := b.javaType(actual.ElemType, enumName)
+		if err != nil {
+			return "", err
+		}
+		return "Map<String, " + elem + ">", nil
+	case design.Object:
+		return "Map<String, Object>", nil
+	case *design.MediaTypeDefinition, *design.UserTypeDefinition:
+		t, err := projected(at)
+		if err != nil {
+			return "", err
+		}
+		return b.className(t)
+	}
+	return "", fmt.Errorf("unsupported type %s", at.Type.Name())
+}
+
+// enum registers the enum that lists the values of the given attribute and returns its name.
+func (b *builder) enum(name string, at *design.AttributeDefinition) (string, error) {
+	e := &Enum{Name: name, Description: at.Description}
+	used := make(map[string]bool)
+	for _, v := range at.Validation.Values {
+		val, ok := v.(string)
+		if !ok {
+			return "", fmt.Errorf("unsupported enum value %#v", v)
+		}
+		c := &EnumConstant{Name: constantName(val), Value: val}
+		base := c.Name
+		for i := 2; used[c.Name]; i++ {
+			c.Name = fmt.Sprintf("%s_%d", base, i)
+		}
+		used[c.Name] = true
+		e.Constants = append(e.Constants, c)
+	}
+	if existing, ok := b.enums[name]; ok {
+		if !sameConstants(existing, e) {
+			return "", fmt.Errorf("enum %s is already defined", name)
+		}
+		return name, nil
+	}
+	if _, ok := b.classes[name]; ok {
+		return "", fmt.Errorf("class %s is already defined", name)
+	}
+	b.enums[name] = e
+	return name, nil
+}
+
+// sameConstants returns true if the two enums define the same constants.
+func sameConstants(e1, e2 *Enum) bool {
+	if len(e1.Constants) != len(e2.Constants) {
+		return false
+	}
+	for i, c := range e1.Constants {
+		if *c != *e2.Constants[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// multipart returns the statements that encode the given payload as multipart form data, the
+// last statement assigns _body.
+func multipart(payload *design.UserTypeDefinition) ([]string, error) {
+	if !payload.Type.IsObject() {
+		return nil, fmt.Errorf("multipart payloads must be objects")
+	}
+	stmts := []string{"MultipartForm _form = new MultipartForm();"}
+	obj := payload.Type.ToObject()
+	for _, n := range codegen.SortedNames(obj) {
+		at := obj[n]
+		v := "payload." + getter(identifier(n)) + "()"
+		var stmt string
+		switch {
+		case at.Type.Kind() == design.FileKind:
+			stmt = fmt.Sprintf(`_form.addPart(%s, %s, "application/octet-stream", %s);`, quote(n), quote(n), v)
+		case at.Type.IsPrimitive():
+			stmt = fmt.Sprintf("_form.addField(%s, %s);", quote(n), paramString(at, v))
+		default:
+			stmt = fmt.Sprintf(`_form.addPart(%s, null, "application/json", mapper.writeValueAsBytes(%s));`, quote(n), v)
+		}
+		if !payload.IsRequired(n) {
+			stmt = fmt.Sprintf("if (%s != null) %s", v, stmt)
+		}
+		stmts = append(stmts, stmt)
+	}
+	return append(stmts,
+		`_headers.put("Content-Type", _form.contentType());`,
+		"_body = _form.toByteArray();",
+	), nil
+}
+
+// primitiveType returns the boxed Java type used to represent values of the given primitive.
+// Date times, UUIDs and decimals are represented with their string representation.
+func primitiveType(p design.Primitive) string {
+	switch p.Kind() {
+	case design.BooleanKind:
+		return "Boolean"
+	case design.IntegerKind:
+		return "Long"
+	case design.NumberKind:
+		return "Double"
+	case design.StringKind, design.DateTimeKind, design.UUIDKind, design.DecimalKind:
+		return "String"
+	case design.FileKind:
+		return "byte[]"
+	case design.AnyKind:
+		return "Object"
+	}
+	if c := design.CustomPrimitive(p); c != nil {
+		switch c.JSONType {
+		case "integer":
+			return "Long"
+		case "number":
+			return "Double"
+		case "boolean":
+			return "Boolean"
+		}
+		return "String"
+	}
+	return "Object"
+}
+
+// unboxed returns the primitive type that corresponds to the given boxed type, other types are
+// returned as is.
+func unboxed(typ string) string {
+	switch typ {
+	case "Boolean":
+		return "boolean"
+	case "Long":
+		return "long"
+	case "Double":
+		return "double"
+	}
+	return typ
+}
+
+// isEnum returns true if the attribute is a string that declares an Enum validation.
+func isEnum(at *design.AttributeDefinition) bool {
+	return at.Type.Kind() == design.StringKind && at.Validation != nil && len(at.Validation.Values) > 0
+}
+
+// paramString returns the expression that converts the value v of the given attribute into
+// its string representation.
+func paramString(at *design.AttributeDefinition, v string) string {
+	if isEnum(at) {
+		return v + ".getValue()"
+	}
+	if at.Type.Kind() == design.StringKind {
+		return v
+	}
+	return "String.valueOf(" + v + ")"
+}
+
+// projected returns the type of the attribute, media types are projected using the view of the
+// attribute or the default view.
+func projected(at *design.AttributeDefinition) (design.DataType, error) {
+	mt, ok := at.Type.(*design.MediaTypeDefinition)
+	if !ok || strings.Contains(mt.Identifier, "view=") {
+		return at.Type, nil
+	}
+	view := at.View
+	if view == "" {
+		view = design.DefaultView
+	}
+	p, _, err := mt.Project(view)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// userType returns the user type definition of the given user type or media type.
+func userType(t design.DataType) (*design.UserTypeDefinition, bool) {
+	switch actual := t.(type) {
+	case *design.MediaTypeDefinition:
+		return actual.UserTypeDefinition, true
+	case *design.UserTypeDefinition:
+		return actual, true
+	}
+	return nil, false
+}
+
+// identifier returns the lower camel case Java identifier that corresponds to the given name.
+func identifier(n string) string {
+	name := codegen.Goify(invalidNameChars.ReplaceAllString(n, "_"), false)
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	if reservedWords[name] {
+		name += "_"
+	}
+	return name
+}
+
+// getter returns the name of the getter of the field with the given name.
+func getter(name string) string {
+	name = strings.TrimLeft(name, "_")
+	if name == "" {
+		return "get_"
+	}
+	return "get" + strings.ToUpper(name[:1]) + name[1:]
+}
+
+// constantName returns the upper snake case name of the enum constant that corresponds to the
+// given value.
+func constantName(v string) string {
+	v = camelBoundary.ReplaceAllString(v, "${1}_${2}")
+	v = strings.Trim(invalidNameChars.ReplaceAllString(v, "_"), "_")
+	if v == "" {
+		return "EMPTY"
+	}
+	if v[0] >= '0' && v[0] <= '9' {
+		v = "VALUE_" + v
+	}
+	return strings.ToUpper(v)
+}
+
+// typeName returns the Java type name that corresponds to the given name.
+func typeName(n string) string {
+	name := invalidNameChars.ReplaceAllString(codegen.Goify(n, true), "")
+	if reservedTypeNames[name] {
+		name += "Type"
+	}
+	return name
+}
+
+// artifactVersion returns the version of the Maven artifact that corresponds to the given API
+// version, it defaults to "1.0.0".
+func artifactVersion(v string) string {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if v == "" || invalidVersionChars.MatchString(v) {
+		return "1.0.0"
+	}
+	if _, err := strconv.Atoi(v); err == nil {
+		return v + ".0.0"
+	}
+	return v
+}
+
+// invalidVersionChars matches the characters that cannot be used in Maven versions.
+var invalidVersionChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// pathExpression returns the Java expression that builds the given route path, interpolations
+// maps the names of the path parameters to the expression that replaces them.
+func pathExpression(path string, interpolations map[string]string) string {
+	var parts []string
+	var literal string
+	last := 0
+	for _, loc := range codegen.PathParamRegex.FindAllStringSubmatchIndex(path, -1) {
+		literal += path[last:loc[0]]
+		if interpolation, ok := interpolations[path[loc[2]:loc[3]]]; ok {
+			if literal != "" {
+				parts = append(parts, quote(literal))
+			}
+			parts = append(parts, interpolation)
+			literal = ""
+		} else {
+			literal += path[loc[0]:loc[1]]
+		}
+		last = loc[1]
+	}
+	literal += path[last:]
+	if literal != "" || len(parts) == 0 {
+		parts = append(parts, quote(literal))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// quote returns the Java string literal of s.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
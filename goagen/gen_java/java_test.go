@@ -0,0 +1,226 @@
+package genjava_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genjava "github.com/kyokomi/goa-v1/goagen/gen_java"
+)
+
+var _ = Describe("New", func() {
+	var pkg *genjava.Package
+	var newErr error
+
+	BeforeEach(func() {
+		pkg = nil
+		newErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		pkg, newErr = genjava.New(Design, "cellar.client", "https://api.example.com")
+	})
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			winery := apidsl.Type("Winery", func() {
+				apidsl.Attribute("name", String)
+				apidsl.Required("name")
+			})
+			bottle := apidsl.MediaType("application/vnd.bottle", func() {
+				apidsl.Description("A bottle of wine")
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer, "ID of bottle")
+					apidsl.Attribute("color", String, func() {
+						apidsl.Enum("red", "white", "rosé", "2nd")
+					})
+					apidsl.Attribute("winery", winery)
+					apidsl.Attribute("created_at", DateTime)
+					apidsl.Required("id", "color")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("color")
+					apidsl.Attribute("winery")
+					apidsl.Attribute("created_at")
+				})
+			})
+			apidsl.API("cellar", func() {
+				apidsl.BasePath("/api")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Description("Get bottle by id")
+					apidsl.Routing(apidsl.GET("/:bottleID"))
+					apidsl.Params(func() {
+						apidsl.Param("bottleID", Integer)
+						apidsl.Param("fields", apidsl.ArrayOf(String))
+					})
+					apidsl.Headers(func() {
+						apidsl.Header("X-Request-Id")
+					})
+					apidsl.Response(OK, bottle)
+					apidsl.Response(NotFound)
+					apidsl.Response(BadRequest, ErrorMedia)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(func() {
+						apidsl.Member("name", String)
+						apidsl.Member("winery", winery)
+						apidsl.Required("name")
+					})
+					apidsl.Response(Created, bottle)
+					apidsl.Response(NoContent)
+				})
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET(""))
+					apidsl.Response(OK, apidsl.CollectionOf(bottle))
+				})
+				apidsl.Action("upload", func() {
+					apidsl.Routing(apidsl.POST("/:bottleID/label"))
+					apidsl.MultipartForm()
+					apidsl.Payload(func() {
+						apidsl.Member("label", File)
+						apidsl.Member("note", String)
+						apidsl.Required("label")
+					})
+					apidsl.Response(NoContent)
+				})
+			})
+		})
+
+		It("generates the classes", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			var names []string
+			for _, c := range pkg.Classes {
+				names = append(names, c.Name)
+			}
+			Ω(names).Should(Equal([]string{"Bottle", "CreateBottlePayload", "ErrorType", "UploadBottlePayload", "Winery"}))
+			bottle := pkg.Classes[0]
+			Ω(bottle.Fields).Should(HaveLen(4))
+			Ω(bottle.Fields[0].Type).Should(Equal("BottleColor"))
+			Ω(bottle.Fields[0].Assignment()).Should(Equal(`this.color = Objects.requireNonNull(color, "color");`))
+			Ω(bottle.Fields[1].Name).Should(Equal("createdAt"))
+			Ω(bottle.Fields[1].Getter()).Should(Equal("getCreatedAt"))
+			Ω(bottle.Fields[1].Assignment()).Should(Equal("this.createdAt = createdAt;"))
+			Ω(bottle.Fields[2].Type).Should(Equal("Long"))
+			Ω(bottle.Fields[2].Declared()).Should(Equal("long"))
+			Ω(bottle.Fields[3].Declared()).Should(Equal("Winery"))
+			Ω(bottle.ToString()).Should(Equal(`"Bottle{color=" + color + ", createdAt=" + createdAt + ", id=" + id + ", winery=" + winery + "}"`))
+			Ω(pkg.Classes[3].Fields[0].Type).Should(Equal("byte[]"))
+		})
+
+		It("generates the enums", func() {
+			Ω(pkg.Enums).Should(HaveLen(1))
+			color := pkg.Enums[0]
+			Ω(color.Name).Should(Equal("BottleColor"))
+			var constants []string
+			for _, c := range color.Constants {
+				constants = append(constants, c.Name+" = "+c.Value)
+			}
+			Ω(constants).Should(Equal([]string{"RED = red", "WHITE = white", "ROS = rosé", "VALUE_2ND = 2nd"}))
+		})
+
+		It("generates the methods", func() {
+			Ω(pkg.Methods).Should(HaveLen(4))
+			create := pkg.Methods[0]
+			Ω(create.Name).Should(Equal("createBottle"))
+			Ω(create.Signature()).Should(Equal("CreateBottlePayload payload"))
+			Ω(create.Body).Should(Equal([]string{`_headers.put("Content-Type", "application/json");`, "byte[] _body = mapper.writeValueAsBytes(payload);"}))
+			Ω(create.Returns).Should(Equal("CreateBottleResult"))
+			Ω(create.Error).Should(BeEmpty())
+			Ω(create.Handle(create.Responses[0])).Should(Equal("return new CreateBottleResult.Created(mapper.readValue(_response.body(), Bottle.class));"))
+			Ω(create.Handle(create.Responses[1])).Should(Equal("return new CreateBottleResult.NoContent();"))
+			list := pkg.Methods[1]
+			Ω(list.Returns).Should(Equal("List<Bottle>"))
+			Ω(list.Handle(list.Responses[0])).Should(Equal("return mapper.readValue(_response.body(), new TypeReference<List<Bottle>>() {});"))
+			show := pkg.Methods[2]
+			Ω(show.Name).Should(Equal("showBottle"))
+			Ω(show.Path).Should(Equal(`"/api/bottles/" + pathEscape(String.valueOf(bottleID), false)`))
+			Ω(show.Signature()).Should(Equal("long bottleID, List<String> fields, String xRequestID"))
+			Ω(show.RequiredSignature()).Should(Equal("long bottleID"))
+			Ω(show.Forward()).Should(Equal("return showBottle(bottleID, null, null);"))
+			Ω(show.Query).Should(Equal([]string{`if (fields != null) fields.forEach(_v -> _query.add(Map.entry("fields", _v)));`}))
+			Ω(show.Headers).Should(Equal([]string{`if (xRequestID != null) _headers.put("X-Request-Id", xRequestID);`}))
+			Ω(show.Returns).Should(Equal("Bottle"))
+			Ω(show.Error).Should(Equal("ShowBottleException"))
+			Ω(show.Responses).Should(HaveLen(3))
+			Ω(show.Handle(show.Responses[0])).Should(Equal("return mapper.readValue(_response.body(), Bottle.class);"))
+			Ω(show.Handle(show.Responses[1])).Should(Equal("throw new ShowBottleException.BadRequest(mapper.readValue(_response.body(), ErrorType.class));"))
+			Ω(show.Handle(show.Responses[2])).Should(Equal("throw new ShowBottleException.NotFound();"))
+			upload := pkg.Methods[3]
+			Ω(upload.Multipart).Should(BeTrue())
+			Ω(upload.Returns).Should(Equal("void"))
+			Ω(upload.Path).Should(Equal(`"/api/bottles/" + pathEscape(bottleID, false) + "/label"`))
+			Ω(upload.Body).Should(Equal([]string{
+				"MultipartForm _form = new MultipartForm();",
+				`_form.addPart("label", "label", "application/octet-stream", payload.getLabel());`,
+				`if (payload.getNote() != null) _form.addField("note", payload.getNote());`,
+				`_headers.put("Content-Type", _form.contentType());`,
+				"byte[] _body = _form.toByteArray();",
+			}))
+			Ω(upload.Handle(upload.Responses[0])).Should(Equal("return;"))
+		})
+
+		It("renders the files", func() {
+			const dir = "src/main/java/cellar/client/"
+			Ω(pkg.Files()).Should(Equal([]string{
+				"pom.xml",
+				dir + "ApiException.java",
+				dir + "UnexpectedResponseException.java",
+				dir + "BottleColor.java",
+				dir + "Bottle.java",
+				dir + "CreateBottlePayload.java",
+				dir + "ErrorType.java",
+				dir + "UploadBottlePayload.java",
+				dir + "Winery.java",
+				dir + "CreateBottleResult.java",
+				dir + "ShowBottleException.java",
+				dir + "Client.java",
+			}))
+			pom, err := pkg.Write("pom.xml", "cellar: Java Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(pom)).Should(ContainSubstring("  <artifactId>cellar-client</artifactId>\n  <version>1.0.0</version>\n"))
+			Ω(string(pom)).Should(ContainSubstring("    <maven.compiler.release>11</maven.compiler.release>\n"))
+			enum, err := pkg.Write(dir+"BottleColor.java", "cellar: Java Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(enum)).Should(ContainSubstring("public enum BottleColor {\n    RED(\"red\"),\n"))
+			Ω(string(enum)).Should(ContainSubstring("    VALUE_2ND(\"2nd\");\n"))
+			class, err := pkg.Write(dir+"Bottle.java", "cellar: Java Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(class)).Should(ContainSubstring("package cellar.client;\n\nimport com.fasterxml.jackson.annotation.JsonCreator;\n"))
+			Ω(string(class)).Should(ContainSubstring("/** A bottle of wine (default view) */\n@JsonInclude(JsonInclude.Include.NON_NULL)\n@JsonIgnoreProperties(ignoreUnknown = true)\npublic final class Bottle {\n"))
+			Ω(string(class)).Should(ContainSubstring("            @JsonProperty(\"created_at\") String createdAt,\n"))
+			Ω(string(class)).Should(ContainSubstring("    /** ID of bottle */\n    @JsonProperty(\"id\")\n    public long getId() {\n"))
+			Ω(string(class)).Should(ContainSubstring("        public Builder createdAt(String createdAt) {\n"))
+			Ω(string(class)).ShouldNot(ContainSubstring("import java.util.List;"))
+			result, err := pkg.Write(dir+"CreateBottleResult.java", "cellar: Java Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(result)).Should(ContainSubstring("    public static final class NoContent extends CreateBottleResult {\n"))
+			exception, err := pkg.Write(dir+"ShowBottleException.java", "cellar: Java Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(exception)).Should(ContainSubstring("public abstract class ShowBottleException extends ApiException {\n"))
+			Ω(string(exception)).Should(ContainSubstring("        public BadRequest(ErrorType body) {\n            super(400);\n"))
+			client, err := pkg.Write(dir+"Client.java", "cellar: Java Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(client)).Should(ContainSubstring("import com.fasterxml.jackson.core.type.TypeReference;\n"))
+			Ω(string(client)).Should(ContainSubstring(`    public static final String DEFAULT_BASE_URL = "https://api.example.com";`))
+			Ω(string(client)).Should(ContainSubstring("    public Bottle showBottle(long bottleID, List<String> fields, String xRequestID) throws IOException, InterruptedException {\n"))
+			Ω(string(client)).Should(ContainSubstring("    public Bottle showBottle(long bottleID) throws IOException, InterruptedException {\n        return showBottle(bottleID, null, null);\n    }\n"))
+			Ω(string(client)).Should(ContainSubstring("            case 404:\n                throw new ShowBottleException.NotFound();\n"))
+			Ω(string(client)).Should(ContainSubstring("    private static final class MultipartForm {\n"))
+			_, err = pkg.Write(dir+"Other.java", "cellar: Java Client")
+			Ω(err).Should(HaveOccurred())
+			_, err = pkg.Write("Client.java", "cellar: Java Client")
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
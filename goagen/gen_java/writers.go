@@ -0,0 +1,738 @@
+package genjava
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1/version"
+)
+
+// Dir returns the path of the source directory of the package relative to the project
+// directory.
+func (p *Package) Dir() string {
+	return filepath.Join(append([]string{"src", "main", "java"}, strings.Split(p.Name, ".")...)...)
+}
+
+// Files returns the paths of the generated files relative to the project directory in order of
+// generation.
+func (p *Package) Files() []string {
+	dir := p.Dir()
+	files := []string{
+		"pom.xml",
+		filepath.Join(dir, "ApiException.java"),
+		filepath.Join(dir, "UnexpectedResponseException.java"),
+	}
+	for _, e := range p.Enums {
+		files = append(files, filepath.Join(dir, e.Name+".java"))
+	}
+	for _, c := range p.Classes {
+		files = append(files, filepath.Join(dir, c.Name+".java"))
+	}
+	for _, m := range p.Methods {
+		if m.Result != "" {
+			files = append(files, filepath.Join(dir, m.Result+".java"))
+		}
+		if m.Error != "" {
+			files = append(files, filepath.Join(dir, m.Error+".java"))
+		}
+	}
+	return append(files, filepath.Join(dir, "Client.java"))
+}
+
+var (
+	pomTmpl        = newTemplate("pom", pomT)
+	apiErrorTmpl   = newTemplate("apiError", apiErrorT)
+	unexpectedTmpl = newTemplate("unexpected", unexpectedT)
+	enumTmpl       = newTemplate("enum", enumT)
+	classTmpl      = newTemplate("class", classT)
+	resultTmpl     = newTemplate("result", resultT)
+	errorTmpl      = newTemplate("error", errorT)
+	clientTmpl     = newTemplate("client", clientT)
+)
+
+// newTemplate parses the given template text.
+func newTemplate(name, text string) *template.Template {
+	return template.Must(template.New(name).Funcs(template.FuncMap{
+		"javadoc": javadoc,
+		"quote":   quote,
+	}).Parse(text))
+}
+
+// Write renders the file with the given path, title is written in the header comment.
+func (p *Package) Write(file, title string) ([]byte, error) {
+	tmpl, typ, imports := p.source(file)
+	if tmpl == nil {
+		return nil, fmt.Errorf("unknown file %s", file)
+	}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"Title":       title,
+		"ToolVersion": version.String(),
+		"Package":     p,
+		"Type":        typ,
+		"Imports":     imports,
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// source returns the template, the type and the imports of the given file, the template is
+// nil if the package does not generate the file.
+func (p *Package) source(file string) (*template.Template, interface{}, []string) {
+	if file == "pom.xml" {
+		return pomTmpl, nil, nil
+	}
+	if filepath.Dir(file) != p.Dir() || filepath.Ext(file) != ".java" {
+		return nil, nil, nil
+	}
+	name := strings.TrimSuffix(filepath.Base(file), ".java")
+	switch name {
+	case "ApiException":
+		return apiErrorTmpl, nil, []string{"java.io.IOException"}
+	case "UnexpectedResponseException":
+		return unexpectedTmpl, nil, nil
+	case "Client":
+		return clientTmpl, nil, p.clientImports()
+	}
+	for _, e := range p.Enums {
+		if e.Name == name {
+			return enumTmpl, e, []string{
+				"com.fasterxml.jackson.annotation.JsonCreator",
+				"com.fasterxml.jackson.annotation.JsonValue",
+			}
+		}
+	}
+	for _, c := range p.Classes {
+		if c.Name == name {
+			imports := []string{
+				"com.fasterxml.jackson.annotation.JsonCreator",
+				"com.fasterxml.jackson.annotation.JsonIgnoreProperties",
+				"com.fasterxml.jackson.annotation.JsonInclude",
+			}
+			if len(c.Fields) > 0 {
+				imports = append(imports, "com.fasterxml.jackson.annotation.JsonProperty", "java.util.Objects")
+			}
+			types := make([]string, len(c.Fields))
+			for i, f := range c.Fields {
+				types[i] = f.Type
+			}
+			imports = append(imports, utilImports(types...)...)
+			sort.Strings(imports)
+			return classTmpl, c, imports
+		}
+	}
+	for _, m := range p.Methods {
+		var types []string
+		for _, r := range m.Responses {
+			types = append(types, r.Body)
+		}
+		if m.Result == name {
+			return resultTmpl, m, utilImports(types...)
+		}
+		if m.Error == name {
+			return errorTmpl, m, utilImports(types...)
+		}
+	}
+	return nil, nil, nil
+}
+
+// clientImports returns the imports of the client class.
+func (p *Package) clientImports() []string {
+	imports := []string{
+		"com.fasterxml.jackson.databind.ObjectMapper",
+		"com.fasterxml.jackson.databind.SerializationFeature",
+		"java.io.IOException",
+		"java.net.URI",
+		"java.net.URLEncoder",
+		"java.net.http.HttpClient",
+		"java.net.http.HttpRequest",
+		"java.net.http.HttpResponse",
+		"java.nio.charset.StandardCharsets",
+		"java.util.ArrayList",
+		"java.util.LinkedHashMap",
+		"java.util.List",
+		"java.util.Map",
+	}
+	if p.HasMultipart() {
+		imports = append(imports, "java.io.ByteArrayOutputStream", "java.util.UUID")
+	}
+	for _, m := range p.Methods {
+		if m.decodesGenericType() {
+			imports = append(imports, "com.fasterxml.jackson.core.type.TypeReference")
+			break
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// utilImports returns the java.util imports required by the given types.
+func utilImports(types ...string) []string {
+	var list, hash bool
+	for _, t := range types {
+		list = list || strings.Contains(t, "List<")
+		hash = hash || strings.Contains(t, "Map<")
+	}
+	var imports []string
+	if list {
+		imports = append(imports, "java.util.List")
+	}
+	if hash {
+		imports = append(imports, "java.util.Map")
+	}
+	return imports
+}
+
+// HasMultipart returns true if a method of the package sends multipart form data.
+func (p *Package) HasMultipart() bool {
+	for _, m := range p.Methods {
+		if m.Multipart {
+			return true
+		}
+	}
+	return false
+}
+
+// ArtifactID returns the Maven artifact ID of the package.
+func (p *Package) ArtifactID() string {
+	return strings.Replace(strings.Replace(p.Name, ".", "-", -1), "_", "-", -1)
+}
+
+// decodesGenericType returns true if the method decodes a response body with a generic type,
+// e.g. List<Bottle>.
+func (m *Method) decodesGenericType() bool {
+	for _, r := range m.Responses {
+		if !r.Text && strings.Contains(r.Body, "<") {
+			return true
+		}
+	}
+	return false
+}
+
+// Terminator returns the punctuation that follows the i-th enum constant.
+func (e *Enum) Terminator(i int) string {
+	if i == len(e.Constants)-1 {
+		return ";"
+	}
+	return ","
+}
+
+// ToString returns the expression returned by the toString method of the class.
+func (c *Class) ToString() string {
+	if len(c.Fields) == 0 {
+		return quote(c.Name + "{}")
+	}
+	parts := make([]string, len(c.Fields))
+	for i, f := range c.Fields {
+		sep := ", "
+		if i == 0 {
+			sep = c.Name + "{"
+		}
+		parts[i] = quote(sep+f.Name+"=") + " + " + f.Name
+	}
+	return strings.Join(parts, " + ") + ` + "}"`
+}
+
+// Declared returns the declared type of the field, required primitive types are unboxed.
+func (f *Field) Declared() string {
+	if f.Required {
+		return unboxed(f.Type)
+	}
+	return f.Type
+}
+
+// Getter returns the name of the getter of the field.
+func (f *Field) Getter() string {
+	return getter(f.Name)
+}
+
+// Assignment returns the constructor statement that initializes the field, required fields
+// cannot be null.
+func (f *Field) Assignment() string {
+	if f.Required {
+		return fmt.Sprintf("this.%s = Objects.requireNonNull(%s, %s);", f.Name, f.Name, quote(f.AttName))
+	}
+	return fmt.Sprintf("this.%s = %s;", f.Name, f.Name)
+}
+
+// Signature returns the list of arguments of the method.
+func (m *Method) Signature() string {
+	args := make([]string, len(m.Args))
+	for i, a := range m.Args {
+		args[i] = a.Type + " " + a.Name
+	}
+	return strings.Join(args, ", ")
+}
+
+// HasOptional returns true if the method has optional arguments.
+func (m *Method) HasOptional() bool {
+	for _, a := range m.Args {
+		if a.Optional {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiredSignature returns the list of the required arguments of the method.
+func (m *Method) RequiredSignature() string {
+	var args []string
+	for _, a := range m.Args {
+		if !a.Optional {
+			args = append(args, a.Type+" "+a.Name)
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
+// Forward returns the statement of the overload that only takes the required arguments, it
+// calls the method with null optional arguments.
+func (m *Method) Forward() string {
+	args := make([]string, len(m.Args))
+	for i, a := range m.Args {
+		args[i] = a.Name
+		if a.Optional {
+			args[i] = "null"
+		}
+	}
+	call := fmt.Sprintf("%s(%s);", m.Name, strings.Join(args, ", "))
+	if m.Returns == "void" {
+		return call
+	}
+	return "return " + call
+}
+
+// Doc returns the Javadoc comment of the method, it lists the exceptions thrown by the method.
+func (m *Method) Doc() string {
+	text := m.Description + "\n"
+	if m.Error != "" {
+		text += "\n@throws " + m.Error + " for the declared error responses."
+	}
+	text += "\n@throws UnexpectedResponseException for the statuses that are not described in the design." +
+		"\n@throws IOException if the request fails or if the response body cannot be decoded." +
+		"\n@throws InterruptedException if the thread is interrupted while waiting for the response."
+	return javadoc("    ", text)
+}
+
+// Handle returns the statement of the switch case that handles the given response.
+func (m *Method) Handle(r *Response) string {
+	var value string
+	switch {
+	case r.Text:
+		value = "_response.body()"
+	case strings.Contains(r.Body, "<"):
+		value = fmt.Sprintf("mapper.readValue(_response.body(), new TypeReference<%s>() {})", r.Body)
+	case r.Body != "":
+		value = fmt.Sprintf("mapper.readValue(_response.body(), %s.class)", r.Body)
+	}
+	switch {
+	case r.Error:
+		return fmt.Sprintf("throw new %s.%s(%s);", m.Error, r.Class, value)
+	case m.Result != "":
+		return fmt.Sprintf("return new %s.%s(%s);", m.Result, r.Class, value)
+	case value == "":
+		return "return;"
+	}
+	return "return " + value + ";"
+}
+
+// Successes returns the 2xx responses of the method.
+func (m *Method) Successes() []*Response {
+	var res []*Response
+	for _, r := range m.Responses {
+		if !r.Error {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// Errors returns the non 2xx responses of the method.
+func (m *Method) Errors() []*Response {
+	var res []*Response
+	for _, r := range m.Responses {
+		if r.Error {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// StatusText returns the text of the response status.
+func (r *Response) StatusText() string {
+	return fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status))
+}
+
+// javadocEscaper escapes the text of the Javadoc comments.
+var javadocEscaper = strings.NewReplacer("*/", "*&#47;", "&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// javadoc renders the given text as a Javadoc comment followed by a new line, indent is
+// prepended to each line.
+func javadoc(indent, text string) string {
+	text = strings.TrimSpace(javadocEscaper.Replace(text))
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		return indent + "/** " + lines[0] + " */\n"
+	}
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(indent+" * "+l, " ")
+	}
+	return indent + "/**\n" + strings.Join(lines, "\n") + "\n" + indent + " */\n"
+}
+
+const headerT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .Title }}
+
+package {{ .Package.Name }};
+{{ if .Imports }}
+{{ range .Imports }}import {{ . }};
+{{ end }}{{ end }}`
+
+const pomT = `<?xml version="1.0" encoding="UTF-8"?>
+<!--
+  Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+
+  {{ .Title }}
+-->
+<project xmlns="http://maven.apache.org/POM/4.0.0" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+         xsi:schemaLocation="http://maven.apache.org/POM/4.0.0 https://maven.apache.org/xsd/maven-4.0.0.xsd">
+  <modelVersion>4.0.0</modelVersion>
+
+  <groupId>{{ .Package.Name }}</groupId>
+  <artifactId>{{ .Package.ArtifactID }}</artifactId>
+  <version>{{ .Package.Version }}</version>
+  <packaging>jar</packaging>
+
+  <properties>
+    <maven.compiler.release>11</maven.compiler.release>
+    <project.build.sourceEncoding>UTF-8</project.build.sourceEncoding>
+  </properties>
+
+  <dependencies>
+    <dependency>
+      <groupId>com.fasterxml.jackson.core</groupId>
+      <artifactId>jackson-databind</artifactId>
+      <version>2.16.1</version>
+    </dependency>
+  </dependencies>
+</project>
+`
+
+const apiErrorT = headerT + `
+/** ApiException is the base class of the exceptions thrown when the API returns an error response. */
+public class ApiException extends IOException {
+    private static final long serialVersionUID = 1L;
+
+    private final int status;
+
+    public ApiException(int status, String message) {
+        super(message);
+        this.status = status;
+    }
+
+    /** getStatus returns the HTTP status code of the response. */
+    public int getStatus() {
+        return status;
+    }
+}
+`
+
+const unexpectedT = headerT + `
+/** UnexpectedResponseException is thrown when the API returns a status that is not described in the design. */
+public class UnexpectedResponseException extends ApiException {
+    private static final long serialVersionUID = 1L;
+
+    private final String body;
+
+    public UnexpectedResponseException(int status, String body) {
+        super(status, "unexpected response status " + status);
+        this.body = body;
+    }
+
+    /** getBody returns the response body. */
+    public String getBody() {
+        return body;
+    }
+}
+`
+
+const enumT = headerT + `{{ $e := .Type }}
+{{ javadoc "" $e.Description }}public enum {{ $e.Name }} {
+{{ range $i, $c := $e.Constants }}    {{ $c.Name }}({{ quote $c.Value }}){{ $e.Terminator $i }}
+{{ end }}
+    private final String value;
+
+    {{ $e.Name }}(String value) {
+        this.value = value;
+    }
+
+    /** getValue returns the serialized value. */
+    @JsonValue
+    public String getValue() {
+        return value;
+    }
+
+    /** fromValue returns the constant with the given serialized value. */
+    @JsonCreator
+    public static {{ $e.Name }} fromValue(String value) {
+        for ({{ $e.Name }} c : values()) {
+            if (c.value.equals(value)) {
+                return c;
+            }
+        }
+        throw new IllegalArgumentException("unknown {{ $e.Name }} value " + value);
+    }
+
+    @Override
+    public String toString() {
+        return value;
+    }
+}
+`
+
+const classT = headerT + `{{ $c := .Type }}
+{{ javadoc "" $c.Description }}@JsonInclude(JsonInclude.Include.NON_NULL)
+@JsonIgnoreProperties(ignoreUnknown = true)
+public final class {{ $c.Name }} {
+{{ range $c.Fields }}    private final {{ .Declared }} {{ .Name }};
+{{ end }}{{ if $c.Fields }}
+{{ end }}    @JsonCreator
+    public {{ $c.Name }}({{ range $i, $f := $c.Fields }}{{ if $i }},{{ end }}
+            @JsonProperty({{ quote $f.AttName }}) {{ $f.Type }} {{ $f.Name }}{{ end }}) {
+{{ range $c.Fields }}        {{ .Assignment }}
+{{ end }}    }
+{{ range $c.Fields }}
+{{ javadoc "    " .Description }}    @JsonProperty({{ quote .AttName }})
+    public {{ .Declared }} {{ .Getter }}() {
+        return {{ .Name }};
+    }
+{{ end }}
+    /** builder returns a builder of {{ $c.Name }} instances. */
+    public static Builder builder() {
+        return new Builder();
+    }
+
+    @Override
+    public boolean equals(Object o) {
+        if (this == o) {
+            return true;
+        }
+{{ if $c.Fields }}        if (!(o instanceof {{ $c.Name }})) {
+            return false;
+        }
+        {{ $c.Name }} other = ({{ $c.Name }}) o;
+        return {{ range $i, $f := $c.Fields }}{{ if $i }}
+                && {{ end }}Objects.equals({{ $f.Name }}, other.{{ $f.Name }}){{ end }};
+{{ else }}        return o instanceof {{ $c.Name }};
+{{ end }}    }
+
+    @Override
+    public int hashCode() {
+{{ if $c.Fields }}        return Objects.hash({{ range $i, $f := $c.Fields }}{{ if $i }}, {{ end }}{{ $f.Name }}{{ end }});
+{{ else }}        return 0;
+{{ end }}    }
+
+    @Override
+    public String toString() {
+        return {{ $c.ToString }};
+    }
+
+    /** Builder builds {{ $c.Name }} instances. */
+    public static final class Builder {
+{{ range $c.Fields }}        private {{ .Type }} {{ .Name }};
+{{ end }}{{ if $c.Fields }}
+{{ end }}        private Builder() {
+        }
+{{ range $c.Fields }}
+        /** {{ .Name }} sets the {{ .AttName }} attribute{{ if .Required }}, it is required{{ end }}. */
+        public Builder {{ .Name }}({{ .Type }} {{ .Name }}) {
+            this.{{ .Name }} = {{ .Name }};
+            return this;
+        }
+{{ end }}
+        /** build returns the {{ $c.Name }}{{ if $c.Fields }}, it throws NullPointerException if a required attribute is missing{{ end }}. */
+        public {{ $c.Name }} build() {
+            return new {{ $c.Name }}({{ range $i, $f := $c.Fields }}{{ if $i }}, {{ end }}{{ $f.Name }}{{ end }});
+        }
+    }
+}
+`
+
+const resultT = headerT + `{{ $m := .Type }}
+/** {{ $m.Result }} lists the successful responses of {{ $m.Name }}. */
+public abstract class {{ $m.Result }} {
+    private {{ $m.Result }}() {
+    }
+{{ range $m.Successes }}
+    /** {{ .Class }} describes the {{ .StatusText }} response. */
+    public static final class {{ .Class }} extends {{ $m.Result }} {
+{{ if .Body }}        private final {{ .Body }} body;
+
+        public {{ .Class }}({{ .Body }} body) {
+            this.body = body;
+        }
+
+        /** getBody returns the decoded response body. */
+        public {{ .Body }} getBody() {
+            return body;
+        }
+{{ else }}        public {{ .Class }}() {
+        }
+{{ end }}    }
+{{ end }}}
+`
+
+const errorT = headerT + `{{ $m := .Type }}
+/** {{ $m.Error }} lists the error responses of {{ $m.Name }}. */
+public abstract class {{ $m.Error }} extends ApiException {
+    private static final long serialVersionUID = 1L;
+
+    private {{ $m.Error }}(int status) {
+        super(status, "HTTP " + status);
+    }
+{{ range $m.Errors }}
+    /** {{ .Class }} is thrown when the API returns the {{ .StatusText }} response. */
+    public static final class {{ .Class }} extends {{ $m.Error }} {
+        private static final long serialVersionUID = 1L;
+{{ if .Body }}
+        private final transient {{ .Body }} body;
+
+        public {{ .Class }}({{ .Body }} body) {
+            super({{ .Status }});
+            this.body = body;
+        }
+
+        /** getBody returns the decoded response body. */
+        public {{ .Body }} getBody() {
+            return body;
+        }
+{{ else }}
+        public {{ .Class }}() {
+            super({{ .Status }});
+        }
+{{ end }}    }
+{{ end }}}
+`
+
+const clientT = headerT + `
+/**
+ * Client gives access to the API.
+ *
+ * baseUrl is prepended to the request paths, httpClient sends the requests, headers are sent
+ * with every request and mapper encodes the request bodies and decodes the response bodies.
+ */
+public class Client {
+    /** DEFAULT_BASE_URL is the base URL used when none is given. */
+    public static final String DEFAULT_BASE_URL = {{ quote .Package.BaseURL }};
+
+    private final String baseUrl;
+    private final HttpClient httpClient;
+    private final Map<String, String> headers;
+    private final ObjectMapper mapper;
+
+    public Client() {
+        this(DEFAULT_BASE_URL);
+    }
+
+    public Client(String baseUrl) {
+        this(baseUrl, HttpClient.newHttpClient(), Map.of(), new ObjectMapper().disable(SerializationFeature.FAIL_ON_EMPTY_BEANS));
+    }
+
+    public Client(String baseUrl, HttpClient httpClient, Map<String, String> headers, ObjectMapper mapper) {
+        this.baseUrl = baseUrl;
+        this.httpClient = httpClient;
+        this.headers = headers;
+        this.mapper = mapper;
+    }
+{{ range $m := .Package.Methods }}{{ if .HasOptional }}
+{{ .Doc }}    public {{ .Returns }} {{ .Name }}({{ .RequiredSignature }}) throws IOException, InterruptedException {
+        {{ .Forward }}
+    }
+{{ end }}
+{{ .Doc }}    public {{ .Returns }} {{ .Name }}({{ .Signature }}) throws IOException, InterruptedException {
+        List<Map.Entry<String, String>> _query = new ArrayList<>();
+{{ range .Query }}        {{ . }}
+{{ end }}        Map<String, String> _headers = new LinkedHashMap<>();
+{{ range .Headers }}        {{ . }}
+{{ end }}{{ range .Body }}        {{ . }}
+{{ else }}        byte[] _body = null;
+{{ end }}        HttpResponse<String> _response = send({{ quote .Verb }}, {{ .Path }}, _query, _headers, _body);
+        switch (_response.statusCode()) {
+{{ range .Responses }}            case {{ .Status }}:
+                {{ $m.Handle . }}
+{{ end }}            default:
+                throw new UnexpectedResponseException(_response.statusCode(), _response.body());
+        }
+    }
+{{ end }}
+    private HttpResponse<String> send(String method, String path, List<Map.Entry<String, String>> query, Map<String, String> headers, byte[] body)
+            throws IOException, InterruptedException {
+        StringBuilder url = new StringBuilder(baseUrl.replaceAll("/+$", "")).append(path);
+        String separator = url.indexOf("?") < 0 ? "?" : "&";
+        for (Map.Entry<String, String> e : query) {
+            url.append(separator).append(queryEscape(e.getKey())).append('=').append(queryEscape(e.getValue()));
+            separator = "&";
+        }
+        HttpRequest.Builder request = HttpRequest.newBuilder(URI.create(url.toString()));
+        this.headers.forEach(request::setHeader);
+        headers.forEach(request::setHeader);
+        request.method(method, body != null ? HttpRequest.BodyPublishers.ofByteArray(body) : HttpRequest.BodyPublishers.noBody());
+        return httpClient.send(request.build(), HttpResponse.BodyHandlers.ofString(StandardCharsets.UTF_8));
+    }
+
+    private static String queryEscape(String value) {
+        return URLEncoder.encode(value, StandardCharsets.UTF_8);
+    }
+
+    private static String pathEscape(String value, boolean keepSlashes) {
+        String escaped = URLEncoder.encode(value, StandardCharsets.UTF_8).replace("+", "%20");
+        return keepSlashes ? escaped.replace("%2F", "/") : escaped;
+    }
+{{ if .Package.HasMultipart }}
+    /** MultipartForm encodes multipart form data request bodies. */
+    private static final class MultipartForm {
+        private final String boundary = UUID.randomUUID().toString();
+        private final ByteArrayOutputStream out = new ByteArrayOutputStream();
+
+        void addField(String name, String value) {
+            addPart(name, null, null, value.getBytes(StandardCharsets.UTF_8));
+        }
+
+        void addPart(String name, String filename, String contentType, byte[] content) {
+            StringBuilder header = new StringBuilder("--").append(boundary).append("\r\n");
+            header.append("Content-Disposition: form-data; name=\"").append(name).append('"');
+            if (filename != null) {
+                header.append("; filename=\"").append(filename).append('"');
+            }
+            header.append("\r\n");
+            if (contentType != null) {
+                header.append("Content-Type: ").append(contentType).append("\r\n");
+            }
+            header.append("\r\n");
+            out.writeBytes(header.toString().getBytes(StandardCharsets.UTF_8));
+            out.writeBytes(content);
+            out.writeBytes("\r\n".getBytes(StandardCharsets.UTF_8));
+        }
+
+        String contentType() {
+            return "multipart/form-data; boundary=" + boundary;
+        }
+
+        byte[] toByteArray() {
+            out.writeBytes(("--" + boundary + "--\r\n").getBytes(StandardCharsets.UTF_8));
+            return out.toByteArray();
+        }
+    }
+{{ end }}}
+`
@@ -0,0 +1,212 @@
+package genjs
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+type (
+	// clientAction is the data used to render the client function of an action.
+	clientAction struct {
+		// Action is the action definition.
+		Action *design.ActionDefinition
+		// Name is the name of the function, e.g. "showBottle".
+		Name string
+		// Description describes the function.
+		Description string
+		// Method is the HTTP method of the request.
+		Method string
+		// FullPath is the path of the route used by the function, e.g. "/bottles/:id".
+		FullPath string
+		// Path is the JavaScript template literal that builds the request path.
+		Path string
+		// Params lists the function parameters, the trailing config parameter excluded.
+		Params []*clientParam
+		// Payload is the name of the payload parameter if any.
+		Payload string
+		// Query is the name of the query string parameters object if any.
+		Query string
+		// Result is the TypeScript type of the success response body.
+		Result string
+	}
+
+	// clientParam is a parameter of a client function.
+	clientParam struct {
+		// Name is the JavaScript identifier of the parameter.
+		Name string
+		// Type is the TypeScript type of the parameter.
+		Type string
+		// Optional is true if the parameter may be omitted.
+		Optional bool
+		// Description describes the parameter.
+		Description string
+	}
+)
+
+// reservedNames lists the identifiers that path parameters may not use: the names of the other
+// parameters and of the module functions as well as the JavaScript reserved words.
+var reservedNames = map[string]bool{
+	"data": true, "query": true, "config": true, "request": true, "defaults": true,
+	"await": true, "break": true, "case": true, "catch": true, "class": true, "const": true,
+	"continue": true, "debugger": true, "default": true, "delete": true, "do": true, "else": true,
+	"enum": true, "export": true, "extends": true, "false": true, "finally": true, "for": true,
+	"function": true, "if": true, "import": true, "in": true, "instanceof": true, "new": true,
+	"null": true, "return": true, "super": true, "switch": true, "this": true, "throw": true,
+	"true": true, "try": true, "typeof": true, "var": true, "void": true, "while": true,
+	"with": true, "yield": true, "let": true, "static": true,
+}
+
+// clientActions returns the data used to render the client functions of the API actions sorted
+// by action name then resource name.
+func (g *Generator) clientActions() []*clientAction {
+	g.types = newTSTypes()
+	var all []*design.ActionDefinition
+	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			all = append(all, a)
+			return nil
+		})
+	})
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	actions := make([]*clientAction, 0, len(all))
+	for _, a := range all {
+		if len(a.Routes) == 0 {
+			continue
+		}
+		actions = append(actions, g.clientAction(a))
+	}
+	return actions
+}
+
+// clientAction returns the data used to render the client function of the given action.
+func (g *Generator) clientAction(a *design.ActionDefinition) *clientAction {
+	route := a.Routes[0]
+	name := codegen.Goify(a.Name, false) + codegen.Goify(a.Parent.Name, true)
+	desc := a.Description
+	if desc == "" {
+		desc = name + " calls the " + a.Name + " action of the " + a.Parent.Name + " resource."
+	}
+	ca := &clientAction{
+		Action:      a,
+		Name:        name,
+		Description: oneLine(desc),
+		Method:      route.Verb,
+		FullPath:    route.FullPath(),
+		Result:      g.result(a),
+	}
+
+	// Path parameters
+	params := a.AllParams()
+	var paramObj design.Object
+	if params != nil {
+		paramObj = params.Type.ToObject()
+	}
+	idents := make(map[string]string)
+	for _, n := range route.Params() {
+		ident := codegen.Goify(n, false)
+		if reservedNames[ident] {
+			ident += "Param"
+		}
+		idents[n] = ident
+		p := &clientParam{Name: ident, Type: "string"}
+		if att, ok := paramObj[n]; ok {
+			p.Type = g.types.Ref(att)
+			p.Description = oneLine(att.Description)
+		}
+		ca.Params = append(ca.Params, p)
+	}
+	ca.Path = pathLiteral(ca.FullPath, idents)
+
+	// Query string
+	var query *clientParam
+	if a.QueryParams != nil {
+		if obj := a.QueryParams.Type.ToObject(); len(obj) > 0 {
+			query = &clientParam{
+				Name:        "query",
+				Type:        g.types.Object(obj, a.QueryParams),
+				Optional:    len(a.QueryParams.AllRequired()) == 0,
+				Description: "lists the query string parameters.",
+			}
+		}
+	}
+
+	// Payload
+	if a.Payload != nil {
+		p := &clientParam{
+			Name:        "data",
+			Type:        g.types.Ref(&design.AttributeDefinition{Type: a.Payload}),
+			Optional:    a.PayloadOptional,
+			Description: "is the request body.",
+		}
+		if p.Optional && query != nil && !query.Optional {
+			// Optional parameters may not precede required ones
+			p.Optional = false
+			p.Type += " | undefined"
+		}
+		ca.Params = append(ca.Params, p)
+		ca.Payload = p.Name
+	}
+	if query != nil {
+		ca.Params = append(ca.Params, query)
+		ca.Query = query.Name
+	}
+	return ca
+}
+
+// result returns the TypeScript type of the body of the first success response of the action.
+func (g *Generator) result(a *design.ActionDefinition) string {
+	var ok *design.ResponseDefinition
+	a.IterateResponses(func(r *design.ResponseDefinition) error {
+		if ok == nil && r.Status < 400 {
+			ok = r
+		}
+		return nil
+	})
+	if ok == nil {
+		return "unknown"
+	}
+	if mt := g.API.MediaTypeWithIdentifier(ok.MediaType); mt != nil {
+		view := ok.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		if p, _, err := mt.Project(view); err == nil {
+			return g.types.UserType(p.UserTypeDefinition)
+		}
+	}
+	if ok.Type != nil {
+		return g.types.Ref(&design.AttributeDefinition{Type: ok.Type})
+	}
+	return "unknown"
+}
+
+// pathLiteral returns the JavaScript template literal that builds the given route path with the
+// values of the parameters whose identifiers are given.
+func pathLiteral(path string, idents map[string]string) string {
+	escape := strings.NewReplacer("\\", "\\\\", "`", "\\`", "${", "\\${")
+	var b strings.Builder
+	b.WriteString("`")
+	last := 0
+	for _, loc := range design.WildcardRegex.FindAllStringIndex(path, -1) {
+		// The wildcard match includes the leading slash
+		b.WriteString(escape.Replace(path[last : loc[0]+1]))
+		wildcard := path[loc[0]+1 : loc[1]]
+		encode := "encodeURIComponent"
+		if wildcard[0] == '*' {
+			encode = "encodeURI"
+		}
+		b.WriteString("${" + encode + "(" + idents[wildcard[1:]] + ")}")
+		last = loc[1]
+	}
+	b.WriteString(escape.Replace(path[last:]))
+	b.WriteString("`")
+	return b.String()
+}
+
+// oneLine returns the text on a single line so that it can be used in comments.
+func oneLine(text string) string {
+	return strings.Replace(strings.Join(strings.Fields(text), " "), "*/", "* /", -1)
+}
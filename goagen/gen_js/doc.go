@@ -1,7 +1,10 @@
 /*
 Package genjs provides a goa generator for a javascript client module.
-The module exposes functions for calling the API actions. It relies on the
-axios (https://github.com/mzabriskie/axios) javascript library to perform the actual HTTP requests.
+The module is an ES module exporting one function per action, e.g. showBottle for the "show" action
+of the "bottle" resource. The functions make the HTTP requests with the fetch API and abort them
+with an AbortController once the timeout elapses, they have no dependency. The generator also
+produces a client.d.ts file declaring the functions and the TypeScript types of the payloads and
+media types so that TypeScript projects and editors can type check the calls.
 
 The generator also produces an example controller and index HTML that shows how to use the module.
 The controller simply serves all the files under the "js" directory so that loading "/js" in a
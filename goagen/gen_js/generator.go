@@ -1,19 +1,18 @@
 package genjs
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
-	"text/template"
 	"time"
 
 	"github.com/kyokomi/goa-v1/design"
 	"github.com/kyokomi/goa-v1/goagen/codegen"
 	"github.com/kyokomi/goa-v1/goagen/utils"
+	"github.com/kyokomi/goa-v1/version"
 )
 
 //NewGenerator returns an initialized instance of a JavaScript Client Generator
@@ -36,6 +35,7 @@ type Generator struct {
 	Host      string                // Host addressed by JavaScript client
 	NoExample bool                  // Do not generate an HTML example file
 	genfiles  []string              // Generated files
+	types     *tsTypes              // TypeScript declarations of the types used by the actions
 }
 
 // Generate is the generator entry point called by the meta generator.
@@ -99,7 +99,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 	}
 
 	g.OutDir = filepath.Join(g.OutDir, "js")
-	if err := os.RemoveAll(g.OutDir); err != nil {
+	if err := codegen.RemoveAll(g.OutDir); err != nil {
 		return nil, err
 	}
 	if err := os.MkdirAll(g.OutDir, 0755); err != nil {
@@ -107,17 +107,25 @@ func (g *Generator) Generate() (_ []string, err error) {
 	}
 	g.genfiles = append(g.genfiles, g.OutDir)
 
+	actions := g.clientActions()
+
 	// Generate client.js
-	exampleAction, err := g.generateJS(filepath.Join(g.OutDir, "client.js"))
-	if err != nil {
+	if err = g.generateJS(filepath.Join(g.OutDir, "client.js"), actions); err != nil {
 		return
 	}
 
-	// Generate axios.html
-	if err = g.generateAxiosJS(); err != nil {
+	// Generate client.d.ts
+	if err = g.generateTypes(filepath.Join(g.OutDir, "client.d.ts"), actions); err != nil {
 		return
 	}
 
+	var exampleAction *clientAction
+	for _, a := range actions {
+		if a.Method == "GET" {
+			exampleAction = a
+			break
+		}
+	}
 	if exampleAction != nil && !g.NoExample {
 		// Generate index.html
 		if err = g.generateIndexHTML(filepath.Join(g.OutDir, "index.html"), exampleAction); err != nil {
@@ -133,7 +141,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 	return g.genfiles, nil
 }
 
-func (g *Generator) generateJS(jsFile string) (_ *design.ActionDefinition, err error) {
+func (g *Generator) generateJS(jsFile string, actions []*clientAction) (err error) {
 	file, err := codegen.SourceFileFor(jsFile)
 	if err != nil {
 		return
@@ -142,51 +150,34 @@ func (g *Generator) generateJS(jsFile string) (_ *design.ActionDefinition, err e
 	g.genfiles = append(g.genfiles, jsFile)
 
 	data := map[string]interface{}{
-		"API":     g.API,
-		"Host":    g.Host,
-		"Scheme":  g.Scheme,
-		"Timeout": int64(g.Timeout / time.Millisecond),
+		"API":         g.API,
+		"Host":        g.Host,
+		"Scheme":      g.Scheme,
+		"Timeout":     int64(g.Timeout / time.Millisecond),
+		"ToolVersion": version.String(),
+		"Actions":     actions,
 	}
-	if err = file.ExecuteTemplate("module", moduleT, nil, data); err != nil {
+	return file.ExecuteTemplate("module", moduleT, nil, data)
+}
+
+func (g *Generator) generateTypes(dtsFile string, actions []*clientAction) (err error) {
+	file, err := codegen.SourceFileFor(dtsFile)
+	if err != nil {
 		return
 	}
+	defer file.Close()
+	g.genfiles = append(g.genfiles, dtsFile)
 
-	actions := make(map[string][]*design.ActionDefinition)
-	g.API.IterateResources(func(res *design.ResourceDefinition) error {
-		return res.IterateActions(func(action *design.ActionDefinition) error {
-			if as, ok := actions[action.Name]; ok {
-				actions[action.Name] = append(as, action)
-			} else {
-				actions[action.Name] = []*design.ActionDefinition{action}
-			}
-			return nil
-		})
-	})
-
-	var exampleAction *design.ActionDefinition
-	keys := []string{}
-	for n := range actions {
-		keys = append(keys, n)
-	}
-	sort.Strings(keys)
-	for _, n := range keys {
-		for _, a := range actions[n] {
-			if exampleAction == nil && a.Routes[0].Verb == "GET" {
-				exampleAction = a
-			}
-			data := map[string]interface{}{"Action": a}
-			funcs := template.FuncMap{"params": params}
-			if err = file.ExecuteTemplate("jsFuncs", jsFuncsT, funcs, data); err != nil {
-				return
-			}
-		}
+	data := map[string]interface{}{
+		"API":         g.API,
+		"ToolVersion": version.String(),
+		"Actions":     actions,
+		"Types":       g.types.Declarations(),
 	}
-
-	_, err = file.Write([]byte(moduleTend))
-	return exampleAction, err
+	return file.ExecuteTemplate("declarations", declarationsT, nil, data)
 }
 
-func (g *Generator) generateIndexHTML(htmlFile string, exampleAction *design.ActionDefinition) error {
+func (g *Generator) generateIndexHTML(htmlFile string, exampleAction *clientAction) error {
 	file, err := codegen.SourceFileFor(htmlFile)
 	if err != nil {
 		return err
@@ -194,55 +185,40 @@ func (g *Generator) generateIndexHTML(htmlFile string, exampleAction *design.Act
 	defer file.Close()
 	g.genfiles = append(g.genfiles, htmlFile)
 
-	argNames := params(exampleAction)
-	var args string
-	if len(argNames) > 0 {
-		query := exampleAction.QueryParams.Type.ToObject()
-		argValues := make([]string, len(argNames))
-		for i, n := range argNames {
-			ex := query[n].GenerateExample(g.API.RandomGenerator(), nil)
-			argValues[i] = fmt.Sprintf("%v", ex)
-		}
-		args = strings.Join(argValues, ", ")
+	a := exampleAction.Action
+	var args []string
+	params := a.AllParams().Type.ToObject()
+	for _, n := range a.Routes[0].Params() {
+		args = append(args, g.example(params[n]))
 	}
-	examplePath := exampleAction.Routes[0].FullPath()
-	pathParams := exampleAction.Routes[0].Params()
-	if len(pathParams) > 0 {
-		pathVars := exampleAction.AllParams().Type.ToObject()
-		pathValues := make([]interface{}, len(pathParams))
-		for i, n := range pathParams {
-			ex := pathVars[n].GenerateExample(g.API.RandomGenerator(), nil)
-			pathValues[i] = ex
+	if exampleAction.Query != "" {
+		query := a.QueryParams.Type.ToObject()
+		names := codegen.SortedNames(query)
+		fields := make([]string, len(names))
+		for i, n := range names {
+			fields[i] = tsKey(n) + ": " + g.example(query[n])
 		}
-		format := design.WildcardRegex.ReplaceAllLiteralString(examplePath, "/%v")
-		examplePath = fmt.Sprintf(format, pathValues...)
+		args = append(args, "{ "+strings.Join(fields, ", ")+" }")
 	}
-	if len(argNames) > 0 {
-		args = ", " + args
-	}
-	exampleFunc := fmt.Sprintf(
-		`%s%s ("%s"%s)`,
-		exampleAction.Name,
-		strings.Title(exampleAction.Parent.Name),
-		examplePath,
-		args,
-	)
 	data := map[string]interface{}{
 		"API":         g.API,
-		"ExampleFunc": exampleFunc,
+		"ExampleName": exampleAction.Name,
+		"ExampleCall": fmt.Sprintf("%s(%s)", exampleAction.Name, strings.Join(args, ", ")),
 	}
 
 	return file.ExecuteTemplate("exampleHTML", exampleT, nil, data)
 }
 
-func (g *Generator) generateAxiosJS() error {
-	filePath := filepath.Join(g.OutDir, "axios.min.js")
-	if err := ioutil.WriteFile(filePath, []byte(axios), 0644); err != nil {
-		return err
+// example returns the JavaScript representation of an example value of the attribute.
+func (g *Generator) example(att *design.AttributeDefinition) string {
+	if att == nil {
+		return `"example"`
 	}
-	g.genfiles = append(g.genfiles, filePath)
-
-	return nil
+	b, err := json.Marshal(att.GenerateExample(g.API.RandomGenerator(), nil))
+	if err != nil {
+		return "null"
+	}
+	return string(b)
 }
 
 func (g *Generator) generateExample() error {
@@ -279,99 +255,146 @@ func (g *Generator) Cleanup() {
 	g.genfiles = nil
 }
 
-func params(action *design.ActionDefinition) []string {
-	if action.QueryParams == nil {
-		return nil
-	}
-	params := make([]string, len(action.QueryParams.Type.ToObject()))
-	i := 0
-	for n := range action.QueryParams.Type.ToObject() {
-		params[i] = n
-		i++
-	}
-	sort.Strings(params)
-	return params
+const moduleT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// This ES module exports one function per action of the {{ .API.Name }} API hosted at {{ .Host }}.
+// The functions make the requests with fetch and return a promise resolved with the response
+// status, headers and decoded body. The promise is rejected with a ClientError if the response
+// status is 4xx or 5xx and with an AbortError if the request times out.
+// See client.d.ts for the types of the payloads and responses.
+
+// defaults is the configuration of all the requests, the config argument of the functions
+// overrides it for one request.
+export const defaults = {
+  scheme: '{{ js .Scheme }}',
+  host: '{{ js .Host }}',
+  timeout: {{ .Timeout }},
+  headers: {}
+};
+
+// ClientError is the error raised when the API responds with a 4xx or 5xx status.
+export class ClientError extends Error {
+  constructor(response, body) {
+    super(response.status + ' ' + response.statusText);
+    this.name = 'ClientError';
+    this.status = response.status;
+    this.response = response;
+    this.body = body;
+  }
 }
 
-const moduleT = `// This module exports functions that give access to the {{.API.Name}} API hosted at {{.API.Host}}.
-// It uses the axios javascript library for making the actual HTTP requests.
-define(['axios'] , function (axios) {
-  function merge(obj1, obj2) {
-    var obj3 = {};
-    for (var attrname in obj1) { obj3[attrname] = obj1[attrname]; }
-    for (var attrname in obj2) { obj3[attrname] = obj2[attrname]; }
-    return obj3;
+// request sends the request and decodes the JSON response body.
+async function request(method, path, { body, query, config } = {}) {
+  const cfg = { ...defaults, ...config, headers: { ...defaults.headers, ...(config && config.headers) } };
+  const url = new URL(cfg.scheme + '://' + cfg.host + path);
+  for (const [name, value] of Object.entries(query || {})) {
+    if (value === undefined || value === null) {
+      continue;
+    }
+    for (const v of Array.isArray(value) ? value : [value]) {
+      url.searchParams.append(name, v);
+    }
   }
+  const controller = new AbortController();
+  const timer = cfg.timeout > 0 ? setTimeout(() => controller.abort(), cfg.timeout) : null;
+  if (cfg.signal) {
+    cfg.signal.addEventListener('abort', () => controller.abort());
+  }
+  const init = { method, headers: { Accept: 'application/json', ...cfg.headers }, signal: controller.signal };
+  if (body !== undefined) {
+    init.headers['Content-Type'] = 'application/json';
+    init.body = JSON.stringify(body);
+  }
+  try {
+    const response = await fetch(url, init);
+    const text = await response.text();
+    let data = text;
+    if (text && (response.headers.get('Content-Type') || '').includes('json')) {
+      data = JSON.parse(text);
+    }
+    if (!response.ok) {
+      throw new ClientError(response, data);
+    }
+    return { status: response.status, headers: response.headers, data };
+  } finally {
+    if (timer) {
+      clearTimeout(timer);
+    }
+  }
+}
+{{ range .Actions }}
+/**
+ * {{ .Description }}
+ * The request path is "{{ .FullPath }}".
+ *
+{{ range .Params }} * @param {{ "{" }}{{ .Type }}{{ "}" }} {{ if .Optional }}[{{ .Name }}]{{ else }}{{ .Name }}{{ end }}{{ if .Description }} {{ .Description }}{{ end }}
+{{ end }} * @param {Config} [config] overrides the defaults for this request.
+ * @returns {Promise<Result<{{ .Result }}>>}
+ */
+export function {{ .Name }}({{ range .Params }}{{ .Name }}, {{ end }}config) {
+  return request('{{ .Method }}', {{ .Path }}, { {{ if .Payload }}body: {{ .Payload }}, {{ end }}{{ if .Query }}query: {{ .Query }}, {{ end }}config });
+}
+{{ end }}`
+
+const declarationsT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// TypeScript declarations of the {{ .API.Name }} API client module client.js.
+
+// Config is the configuration of the requests.
+export interface Config {
+  /** scheme is the URL scheme of the requests, e.g. "https". */
+  scheme?: string;
+  /** host is the host and optional port of the API. */
+  host?: string;
+  /** timeout is the request timeout in milliseconds, 0 disables it. */
+  timeout?: number;
+  /** headers lists additional request headers. */
+  headers?: { [name: string]: string };
+  /** signal aborts the request. */
+  signal?: AbortSignal;
+}
 
-  return function (scheme, host, timeout) {
-    scheme = scheme || '{{.Scheme}}';
-    host = host || '{{.Host}}';
-    timeout = timeout || {{.Timeout}};
-
-    // Client is the object returned by this module.
-    var client = axios;
-
-    // URL prefix for all API requests.
-    var urlPrefix = scheme + '://' + host;
-`
+// Result is the value the client functions resolve to.
+export interface Result<T> {
+  status: number;
+  headers: Headers;
+  data: T;
+}
 
-const moduleTend = `  return client;
-  };
-});
-`
+export declare const defaults: Config;
 
-const jsFuncsT = `{{$params := params .Action}}
-  {{$name := printf "%s%s" .Action.Name (title .Action.Parent.Name)}}// {{if .Action.Description}}{{.Action.Description}}{{else}}{{$name}} calls the {{.Action.Name}} action of the {{.Action.Parent.Name}} resource.{{end}}
-  // path is the request path, the format is "{{(index .Action.Routes 0).FullPath}}"
-  {{if .Action.Payload}}// data contains the action payload (request body)
-  {{end}}{{if $params}}// {{join $params ", "}} {{if gt (len $params) 1}}are{{else}}is{{end}} used to build the request query string.
-  {{end}}// config is an optional object to be merged into the config built by the function prior to making the request.
-  // The content of the config object is described here: https://github.com/mzabriskie/axios#request-api
-  // This function returns a promise which raises an error if the HTTP response is a 4xx or 5xx.
-  client.{{$name}} = function (path{{if .Action.Payload}}, data{{end}}{{if $params}}, {{join $params ", "}}{{end}}, config) {
-    var cfg = {
-      timeout: timeout,
-      url: urlPrefix + path,
-      method: '{{toLower (index .Action.Routes 0).Verb}}',
-{{if $params}}      params: {
-{{range $index, $param := $params}}{{if $index}},
-{{end}}        {{$param}}: {{$param}}{{end}}
-      },
-{{end}}{{if .Action.Payload}}    data: data,
-{{end}}      responseType: 'json'
-    };
-    if (config) {
-      cfg = merge(cfg, config);
-    }
-    return client(cfg);
-  }
-`
+// ClientError is the error raised when the API responds with a 4xx or 5xx status.
+export declare class ClientError extends Error {
+  status: number;
+  response: Response;
+  body: unknown;
+}
+{{ range .Types }}
+{{ if .Description }}/** {{ .Description }} */
+{{ end }}{{ if .Interface }}export interface {{ .Name }} {{ .Type }}{{ else }}export type {{ .Name }} = {{ .Type }};{{ end }}
+{{ end }}{{ range .Actions }}
+/** {{ .Description }} */
+export declare function {{ .Name }}({{ range .Params }}{{ .Name }}{{ if .Optional }}?{{ end }}: {{ .Type }}, {{ end }}config?: Config): Promise<Result<{{ .Result }}>>;
+{{ end }}`
 
 const exampleT = `<!doctype html>
 <html>
   <head>
-    <title>goa JavaScript client loader</title>
+    <title>goa JavaScript client example</title>
   </head>
   <body>
-    <h1>{{.API.Name}} Client Test</h1>
+    <h1>{{ .API.Name }} Client Test</h1>
     <div id="response"></div>
-    <script src="https://cdnjs.cloudflare.com/ajax/libs/require.js/2.1.16/require.min.js"></script>
-    <script>
-      requirejs.config({
-        paths: {
-          axios: '/js/axios.min',
-          client: '/js/client'
-        }
-      });
-      requirejs(['client'], function (client) {
-        client().{{.ExampleFunc}}
-          .then(function (resp) {
-            document.getElementById('response').innerHTML = resp.statusText;
-          })
-          .catch(function (resp) {
-            document.getElementById('response').innerHTML = resp.statusText;
-          });
-      });
+    <script type="module">
+      import { {{ .ExampleName }} } from '/js/client.js';
+
+      {{ .ExampleCall }}
+        .then(function (res) {
+          document.getElementById('response').textContent = res.status;
+        })
+        .catch(function (err) {
+          document.getElementById('response').textContent = err.message;
+        });
     </script>
   </body>
 </html>
@@ -387,6 +410,3 @@ func MountController(service *goa.Service) {
 	service.LogInfo("mount", "ctrl", "JS", "action", "ServeFiles", "route", "GET /js/*")
 }
 `
-
-const axios = `/* axios v0.7.0 | (c) 2015 by Matt Zabriskie */
-!function(e,t){"object"==typeof exports&&"object"==typeof module?module.exports=t():"function"==typeof define&&define.amd?define([],t):"object"==typeof exports?exports.axios=t():e.axios=t()}(this,function(){return function(e){function t(r){if(n[r])return n[r].exports;var o=n[r]={exports:{},id:r,loaded:!1};return e[r].call(o.exports,o,o.exports,t),o.loaded=!0,o.exports}var n={};return t.m=e,t.c=n,t.p="",t(0)}([function(e,t,n){e.exports=n(1)},function(e,t,n){"use strict";var r=n(2),o=n(3),i=n(4),s=n(12),u=e.exports=function(e){"string"==typeof e&&(e=o.merge({url:arguments[0]},arguments[1])),e=o.merge({method:"get",headers:{},timeout:r.timeout,transformRequest:r.transformRequest,transformResponse:r.transformResponse},e),e.withCredentials=e.withCredentials||r.withCredentials;var t=[i,void 0],n=Promise.resolve(e);for(u.interceptors.request.forEach(function(e){t.unshift(e.fulfilled,e.rejected)}),u.interceptors.response.forEach(function(e){t.push(e.fulfilled,e.rejected)});t.length;)n=n.then(t.shift(),t.shift());return n};u.defaults=r,u.all=function(e){return Promise.all(e)},u.spread=n(13),u.interceptors={request:new s,response:new s},function(){function e(){o.forEach(arguments,function(e){u[e]=function(t,n){return u(o.merge(n||{},{method:e,url:t}))}})}function t(){o.forEach(arguments,function(e){u[e]=function(t,n,r){return u(o.merge(r||{},{method:e,url:t,data:n}))}})}e("delete","get","head"),t("post","put","patch")}()},function(e,t,n){"use strict";var r=n(3),o=/^\)\]\}',?\n/,i={"Content-Type":"application/x-www-form-urlencoded"};e.exports={transformRequest:[function(e,t){return r.isFormData(e)?e:r.isArrayBuffer(e)?e:r.isArrayBufferView(e)?e.buffer:!r.isObject(e)||r.isFile(e)||r.isBlob(e)?e:(r.isUndefined(t)||(r.forEach(t,function(e,n){"content-type"===n.toLowerCase()&&(t["Content-Type"]=e)}),r.isUndefined(t["Content-Type"])&&(t["Content-Type"]="application/json")),JSON.stringify(e))}],transformResponse:[function(e){if("string"==typeof e){e=e.replace(o,"");try{e=JSON.parse(e)}catch(t){}}return e}],headers:{common:{Accept:"application/json, text/plain, */*"},patch:r.merge(i),post:r.merge(i),put:r.merge(i)},timeout:0,xsrfCookieName:"XSRF-TOKEN",xsrfHeaderName:"X-XSRF-TOKEN"}},function(e,t){"use strict";function n(e){return"[object Array]"===v.call(e)}function r(e){return"[object ArrayBuffer]"===v.call(e)}function o(e){return"[object FormData]"===v.call(e)}function i(e){return"undefined"!=typeof ArrayBuffer&&ArrayBuffer.isView?ArrayBuffer.isView(e):e&&e.buffer&&e.buffer instanceof ArrayBuffer}function s(e){return"string"==typeof e}function u(e){return"number"==typeof e}function a(e){return"undefined"==typeof e}function f(e){return null!==e&&"object"==typeof e}function c(e){return"[object Date]"===v.call(e)}function p(e){return"[object File]"===v.call(e)}function l(e){return"[object Blob]"===v.call(e)}function d(e){return e.replace(/^\s*/,"").replace(/\s*$/,"")}function h(e){return"[object Arguments]"===v.call(e)}function m(){return"undefined"!=typeof window&&"undefined"!=typeof document&&"function"==typeof document.createElement}function y(e,t){if(null!==e&&"undefined"!=typeof e){var r=n(e)||h(e);if("object"==typeof e||r||(e=[e]),r)for(var o=0,i=e.length;i>o;o++)t.call(null,e[o],o,e);else for(var s in e)e.hasOwnProperty(s)&&t.call(null,e[s],s,e)}}function g(){var e={};return y(arguments,function(t){y(t,function(t,n){e[n]=t})}),e}var v=Object.prototype.toString;e.exports={isArray:n,isArrayBuffer:r,isFormData:o,isArrayBufferView:i,isString:s,isNumber:u,isObject:f,isUndefined:a,isDate:c,isFile:p,isBlob:l,isStandardBrowserEnv:m,forEach:y,merge:g,trim:d}},function(e,t,n){(function(t){"use strict";e.exports=function(e){return new Promise(function(r,o){try{"undefined"!=typeof XMLHttpRequest||"undefined"!=typeof ActiveXObject?n(6)(r,o,e):"undefined"!=typeof t&&n(6)(r,o,e)}catch(i){o(i)}})}}).call(t,n(5))},function(e,t){function n(){f=!1,s.length?a=s.concat(a):c=-1,a.length&&r()}function r(){if(!f){var e=setTimeout(n);f=!0;for(var t=a.length;t;){for(s=a,a=[];++c<t;)s&&s[c].run();c=-1,t=a.length}s=null,f=!1,clearTimeout(e)}}function o(e,t){this.fun=e,this.array=t}function i(){}var s,u=e.exports={},a=[],f=!1,c=-1;u.nextTick=function(e){var t=new Array(arguments.length-1);if(arguments.length>1)for(var n=1;n<arguments.length;n++)t[n-1]=arguments[n];a.push(new o(e,t)),1!==a.length||f||setTimeout(r,0)},o.prototype.run=function(){this.fun.apply(null,this.array)},u.title="browser",u.browser=!0,u.env={},u.argv=[],u.version="",u.versions={},u.on=i,u.addListener=i,u.once=i,u.off=i,u.removeListener=i,u.removeAllListeners=i,u.emit=i,u.binding=function(e){throw new Error("process.binding is not supported")},u.cwd=function(){return"/"},u.chdir=function(e){throw new Error("process.chdir is not supported")},u.umask=function(){return 0}},function(e,t,n){"use strict";var r=n(2),o=n(3),i=n(7),s=n(8),u=n(9);e.exports=function(e,t,a){var f=u(a.data,a.headers,a.transformRequest),c=o.merge(r.headers.common,r.headers[a.method]||{},a.headers||{});o.isFormData(f)&&delete c["Content-Type"];var p=new(XMLHttpRequest||ActiveXObject)("Microsoft.XMLHTTP");if(p.open(a.method.toUpperCase(),i(a.url,a.params),!0),p.timeout=a.timeout,p.onreadystatechange=function(){if(p&&4===p.readyState){var n=s(p.getAllResponseHeaders()),r=-1!==["text",""].indexOf(a.responseType||"")?p.responseText:p.response,o={data:u(r,n,a.transformResponse),status:p.status,statusText:p.statusText,headers:n,config:a};(p.status>=200&&p.status<300?e:t)(o),p=null}},o.isStandardBrowserEnv()){var l=n(10),d=n(11),h=d(a.url)?l.read(a.xsrfCookieName||r.xsrfCookieName):void 0;h&&(c[a.xsrfHeaderName||r.xsrfHeaderName]=h)}if(o.forEach(c,function(e,t){f||"content-type"!==t.toLowerCase()?p.setRequestHeader(t,e):delete c[t]}),a.withCredentials&&(p.withCredentials=!0),a.responseType)try{p.responseType=a.responseType}catch(m){if("json"!==p.responseType)throw m}o.isArrayBuffer(f)&&(f=new DataView(f)),p.send(f)}},function(e,t,n){"use strict";function r(e){return encodeURIComponent(e).replace(/%40/gi,"@").replace(/%3A/gi,":").replace(/%24/g,"$").replace(/%2C/gi,",").replace(/%20/g,"+").replace(/%5B/gi,"[").replace(/%5D/gi,"]")}var o=n(3);e.exports=function(e,t){if(!t)return e;var n=[];return o.forEach(t,function(e,t){null!==e&&"undefined"!=typeof e&&(o.isArray(e)&&(t+="[]"),o.isArray(e)||(e=[e]),o.forEach(e,function(e){o.isDate(e)?e=e.toISOString():o.isObject(e)&&(e=JSON.stringify(e)),n.push(r(t)+"="+r(e))}))}),n.length>0&&(e+=(-1===e.indexOf("?")?"?":"&")+n.join("&")),e}},function(e,t,n){"use strict";var r=n(3);e.exports=function(e){var t,n,o,i={};return e?(r.forEach(e.split("\n"),function(e){o=e.indexOf(":"),t=r.trim(e.substr(0,o)).toLowerCase(),n=r.trim(e.substr(o+1)),t&&(i[t]=i[t]?i[t]+", "+n:n)}),i):i}},function(e,t,n){"use strict";var r=n(3);e.exports=function(e,t,n){return r.forEach(n,function(n){e=n(e,t)}),e}},function(e,t,n){"use strict";var r=n(3);e.exports={write:function(e,t,n,o,i,s){var u=[];u.push(e+"="+encodeURIComponent(t)),r.isNumber(n)&&u.push("expires="+new Date(n).toGMTString()),r.isString(o)&&u.push("path="+o),r.isString(i)&&u.push("domain="+i),s===!0&&u.push("secure"),document.cookie=u.join("; ")},read:function(e){var t=document.cookie.match(new RegExp("(^|;\\s*)("+e+")=([^;]*)"));return t?decodeURIComponent(t[3]):null},remove:function(e){this.write(e,"",Date.now()-864e5)}}},function(e,t,n){"use strict";function r(e){var t=e;return s&&(u.setAttribute("href",t),t=u.href),u.setAttribute("href",t),{href:u.href,protocol:u.protocol?u.protocol.replace(/:$/,""):"",host:u.host,search:u.search?u.search.replace(/^\?/,""):"",hash:u.hash?u.hash.replace(/^#/,""):"",hostname:u.hostname,port:u.port,pathname:"/"===u.pathname.charAt(0)?u.pathname:"/"+u.pathname}}var o,i=n(3),s=/(msie|trident)/i.test(navigator.userAgent),u=document.createElement("a");o=r(window.location.href),e.exports=function(e){var t=i.isString(e)?r(e):e;return t.protocol===o.protocol&&t.host===o.host}},function(e,t,n){"use strict";function r(){this.handlers=[]}var o=n(3);r.prototype.use=function(e,t){return this.handlers.push({fulfilled:e,rejected:t}),this.handlers.length-1},r.prototype.eject=function(e){this.handlers[e]&&(this.handlers[e]=null)},r.prototype.forEach=function(e){o.forEach(this.handlers,function(t){null!==t&&e(t)})},e.exports=r},function(e,t){"use strict";e.exports=function(e){return function(t){return e.apply(null,t)}}}])});`
@@ -98,6 +98,24 @@ var _ = Describe("Generate", func() {
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "js", "index.html"))
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(len(strings.Split(string(content), "\n"))).Should(BeNumerically(">=", 13))
+			Ω(string(content)).Should(ContainSubstring("import { showBottle } from '/js/client.js';"))
+		})
+
+		It("generates an ES module function per action", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "js", "client.js"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("export function showBottle(query, config) {"))
+			Ω(string(content)).Should(ContainSubstring("return request('GET', `/`, { query: query, config });"))
+			Ω(string(content)).Should(ContainSubstring("new AbortController()"))
+			Ω(string(content)).ShouldNot(ContainSubstring("axios"))
+		})
+
+		It("generates the TypeScript declarations", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "js", "client.d.ts"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("export declare function showBottle(query?: { query?: string; }, config?: Config): Promise<Result<unknown>>;"))
 		})
 	})
 })
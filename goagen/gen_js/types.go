@@ -0,0 +1,163 @@
+package genjs
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+type (
+	// tsTypes accumulates the TypeScript declarations of the user types and media types referenced
+	// by the client functions.
+	tsTypes struct {
+		decls map[string]*tsDeclaration
+	}
+
+	// tsDeclaration is the TypeScript declaration of a user type or media type.
+	tsDeclaration struct {
+		// Name is the name of the declared type.
+		Name string
+		// Description describes the type.
+		Description string
+		// Interface is true if the type is declared with an interface, false if it is declared
+		// with a type alias.
+		Interface bool
+		// Type is the body of the interface or the aliased type.
+		Type string
+	}
+)
+
+// identRegex matches the property names that do not need to be quoted.
+var identRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// newTSTypes returns an empty set of declarations.
+func newTSTypes() *tsTypes {
+	return &tsTypes{decls: make(map[string]*tsDeclaration)}
+}
+
+// Declarations returns the declarations of the types referenced so far sorted by name.
+func (t *tsTypes) Declarations() []*tsDeclaration {
+	names := make([]string, 0, len(t.decls))
+	for n := range t.decls {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	decls := make([]*tsDeclaration, len(names))
+	for i, n := range names {
+		decls[i] = t.decls[n]
+	}
+	return decls
+}
+
+// Ref returns the TypeScript type of the attribute. The user types and media types it references
+// are added to the declarations, media types are rendered with their default view.
+func (t *tsTypes) Ref(att *design.AttributeDefinition) string {
+	switch actual := att.Type.(type) {
+	case design.Primitive:
+		return primitiveType(actual)
+	case *design.Array:
+		elem := t.Ref(actual.ElemType)
+		if strings.Contains(elem, " | ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case *design.Hash:
+		return "{ [key: string]: " + t.Ref(actual.ElemType) + " }"
+	case design.Object:
+		return t.Object(actual, att)
+	case *design.MediaTypeDefinition:
+		if p, _, err := actual.Project(design.DefaultView); err == nil {
+			return t.UserType(p.UserTypeDefinition)
+		}
+		return t.UserType(actual.UserTypeDefinition)
+	case *design.UserTypeDefinition:
+		return t.UserType(actual)
+	}
+	return "unknown"
+}
+
+// UserType adds the declaration of the user type if needed and returns its name.
+func (t *tsTypes) UserType(ut *design.UserTypeDefinition) string {
+	name := codegen.Goify(ut.TypeName, true)
+	if _, ok := t.decls[name]; ok {
+		return name
+	}
+	decl := &tsDeclaration{Name: name, Description: oneLine(ut.Description)}
+	// Record the declaration first so that recursive types reference it
+	t.decls[name] = decl
+	if obj, ok := ut.Type.(design.Object); ok {
+		decl.Interface = true
+		decl.Type = t.fields(obj, ut.AttributeDefinition, true)
+	} else {
+		decl.Type = t.Ref(ut.AttributeDefinition)
+	}
+	return name
+}
+
+// Object returns the inline TypeScript type of the object whose fields are given, parent
+// defines the required fields.
+func (t *tsTypes) Object(obj design.Object, parent *design.AttributeDefinition) string {
+	return t.fields(obj, parent, false)
+}
+
+// fields renders the object type with the given fields, multiline renders one field per line
+// preceded by its description.
+func (t *tsTypes) fields(obj design.Object, parent *design.AttributeDefinition, multiline bool) string {
+	names := codegen.SortedNames(obj)
+	if len(names) == 0 {
+		return "{}"
+	}
+	fields := make([]string, len(names))
+	for i, n := range names {
+		att := obj[n]
+		opt := "?"
+		if parent.IsRequired(n) {
+			opt = ""
+		}
+		field := tsKey(n) + opt + ": " + t.Ref(att) + ";"
+		if multiline && att.Description != "" {
+			field = "/** " + oneLine(att.Description) + " */\n  " + field
+		}
+		fields[i] = field
+	}
+	if multiline {
+		return "{\n  " + strings.Join(fields, "\n  ") + "\n}"
+	}
+	return "{ " + strings.Join(fields, " ") + " }"
+}
+
+// primitiveType returns the TypeScript type of the JSON values of the primitive.
+func primitiveType(p design.Primitive) string {
+	if c := design.CustomPrimitive(p); c != nil {
+		switch c.JSONType {
+		case "integer", "number":
+			return "number"
+		case "boolean":
+			return "boolean"
+		}
+		return "string"
+	}
+	switch p.Kind() {
+	case design.BooleanKind:
+		return "boolean"
+	case design.IntegerKind, design.NumberKind:
+		return "number"
+	case design.StringKind, design.DateTimeKind, design.UUIDKind, design.DecimalKind:
+		return "string"
+	case design.FileKind:
+		return "Blob"
+	}
+	return "unknown"
+}
+
+// tsKey returns the property name quoted if it is not a valid identifier.
+func tsKey(name string) string {
+	if identRegex.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
@@ -0,0 +1,101 @@
+package genjs_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	genjs "github.com/kyokomi/goa-v1/goagen/gen_js"
+)
+
+// dslDesign is the API definition run by the DSL engine, the Generate specs replace design.Design.
+var dslDesign = design.Design
+
+var _ = Describe("TypeScript declarations", func() {
+	var workspace *codegen.Workspace
+	var js, dts string
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err := ioutil.TempDir(workspace.Path, "")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		design.Design = dslDesign
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {
+			apidsl.Host("localhost:8080")
+		})
+		payload := apidsl.Type("BottlePayload", func() {
+			apidsl.Attribute("name", design.String, "Name of bottle")
+			apidsl.Attribute("tags", apidsl.ArrayOf(design.String))
+			apidsl.Required("name")
+		})
+		bottle := apidsl.MediaType("application/vnd.bottle", func() {
+			apidsl.Description("A bottle of wine")
+			apidsl.Attributes(func() {
+				apidsl.Attribute("id", design.Integer)
+				apidsl.Attribute("name", design.String)
+				apidsl.Required("id")
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("id")
+				apidsl.Attribute("name")
+			})
+			apidsl.View("tiny", func() {
+				apidsl.Attribute("id")
+			})
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("update", func() {
+				apidsl.Routing(apidsl.PUT("/bottles/:bottleID"))
+				apidsl.Payload(payload)
+				apidsl.Response(design.OK, func() {
+					apidsl.Media(bottle, "tiny")
+				})
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+
+		_, err = genjs.NewGenerator(
+			genjs.API(design.Design),
+			genjs.OutDir(outDir),
+			genjs.NoExample(true),
+		).Generate()
+		Ω(err).ShouldNot(HaveOccurred())
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "js", "client.js"))
+		Ω(err).ShouldNot(HaveOccurred())
+		js = string(content)
+		content, err = ioutil.ReadFile(filepath.Join(outDir, "js", "client.d.ts"))
+		Ω(err).ShouldNot(HaveOccurred())
+		dts = string(content)
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("declares the payload types", func() {
+		Ω(dts).Should(ContainSubstring("export interface BottlePayload {\n  /** Name of bottle */\n  name: string;\n  tags?: string[];\n}"))
+	})
+
+	It("declares the projected response media types", func() {
+		Ω(dts).Should(ContainSubstring("/** A bottle of wine (tiny view) */\nexport interface BottleTiny {\n  id: number;\n}"))
+	})
+
+	It("declares the client functions", func() {
+		Ω(dts).Should(ContainSubstring("export declare function updateBottle(bottleID: string, data: BottlePayload, config?: Config): Promise<Result<BottleTiny>>;"))
+	})
+
+	It("builds the request path from the path parameters", func() {
+		Ω(js).Should(ContainSubstring("return request('PUT', `/bottles/${encodeURIComponent(bottleID)}`, { body: data, config });"))
+	})
+})
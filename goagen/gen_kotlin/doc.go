@@ -0,0 +1,17 @@
+/*
+Package genkotlin provides a generator for a Kotlin client of the API targeting Android and the
+JVM. The generator is invoked with "goagen client --language=kotlin" and produces a Gradle
+project that contains:
+
+  - build.gradle.kts: the build script that declares the OkHttp, kotlinx.coroutines and
+    kotlinx.serialization dependencies,
+  - Models.kt: a serializable data class for each user type, media type view and payload and an
+    enum class for each string attribute that declares an Enum validation,
+  - Client.kt: a Client class with one suspending function per action route.
+
+The functions return the decoded body of the successful responses or a sealed result class when
+the successful responses have different bodies. The declared error responses are thrown as the
+subclasses of a sealed exception class generated for each function, the statuses that are not
+described in the design are thrown as UnexpectedResponseException.
+*/
+package genkotlin
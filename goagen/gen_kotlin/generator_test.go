@@ -0,0 +1,42 @@
+package genkotlin_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	genkotlin "github.com/kyokomi/goa-v1/goagen/gen_kotlin"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genkotlin.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+		target string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+		target: "target",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genkotlin.NewGenerator(
+				genkotlin.API(args.api),
+				genkotlin.OutDir(args.outDir),
+				genkotlin.Target(args.target),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Target).Should(Equal(args.target))
+		})
+	})
+})
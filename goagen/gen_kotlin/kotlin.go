@@ -0,0 +1,753 @@
+package genkotlin
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+type (
+	// Package describes the content of the generated Kotlin package.
+	Package struct {
+		// Name of the Kotlin package, e.g. "cellar.client".
+		Name string
+		// BaseURL is the default base URL of the client.
+		BaseURL string
+		// Classes lists the data classes and type aliases sorted by name.
+		Classes []*Class
+		// Enums lists the enum classes generated for the string attributes that declare an Enum
+		// validation sorted by name.
+		Enums []*Enum
+		// Functions lists the client functions sorted by name.
+		Functions []*Function
+	}
+
+	// Class describes a serializable data class or a type alias.
+	Class struct {
+		// Name of the class.
+		Name string
+		// Description of the class.
+		Description string
+		// Fields lists the class properties sorted by name.
+		Fields []*Field
+		// Alias is the aliased type, empty for classes.
+		Alias string
+		// DataType is the goa type described by the class.
+		DataType design.DataType
+	}
+
+	// Field describes a class property.
+	Field struct {
+		// Name of the property.
+		Name string
+		// AttName is the name of the attribute in the JSON representation.
+		AttName string
+		// Type of the property, optional properties use a nullable type.
+		Type string
+		// Required is true if the attribute is required.
+		Required bool
+		// Description of the property.
+		Description string
+	}
+
+	// Enum describes an enum class generated for a string attribute that declares an Enum
+	// validation.
+	Enum struct {
+		// Name of the enum class.
+		Name string
+		// Description of the enum class.
+		Description string
+		// Entries lists the enum entries in order of declaration.
+		Entries []*EnumEntry
+	}
+
+	// EnumEntry describes an enum entry.
+	EnumEntry struct {
+		// Name of the entry.
+		Name string
+		// Value is the serialized value of the entry.
+		Value string
+	}
+
+	// Function describes a client function that sends requests to an action route.
+	Function struct {
+		// Name of the function.
+		Name string
+		// Description of the function.
+		Description string
+		// Verb is the HTTP method of the requests.
+		Verb string
+		// Path is the Kotlin string template that builds the request path.
+		Path string
+		// Args lists the function arguments, required arguments first.
+		Args []*Arg
+		// Query lists the statements that add the query string parameters to _query.
+		Query []string
+		// Headers lists the statements that add the request headers to _headers.
+		Headers []string
+		// Body lists the statements that declare the _body value, empty if the action has no
+		// payload.
+		Body []string
+		// Responses lists the declared responses sorted by status.
+		Responses []*Response
+		// Returns is the return type of the function, "Unit" if it returns nothing.
+		Returns string
+		// Result is the sealed class returned by the functions that declare successful responses
+		// with different bodies, empty otherwise.
+		Result string
+		// Error is the name of the sealed exception class thrown for the declared error
+		// responses, empty if there is none.
+		Error string
+	}
+
+	// Arg describes a function argument.
+	Arg struct {
+		// Name of the argument.
+		Name string
+		// Type of the argument.
+		Type string
+		// Optional is true if the argument defaults to null.
+		Optional bool
+	}
+
+	// Response describes a declared response.
+	Response struct {
+		// Status is the HTTP status code of the response.
+		Status int
+		// Class is the name of the subclass of the sealed result or exception class that
+		// describes the response.
+		Class string
+		// Body is the type of the decoded body, empty if the response has no body.
+		Body string
+		// Text is true if the body is read as text instead of being decoded from JSON.
+		Text bool
+		// Error is true if the response is not a 2xx response.
+		Error bool
+	}
+)
+
+// reservedWords lists the Kotlin hard keywords, identifiers that use one of these are escaped
+// with backticks.
+var reservedWords = map[string]bool{
+	"as": true, "break": true, "class": true, "continue": true, "do": true, "else": true,
+	"false": true, "for": true, "fun": true, "if": true, "in": true, "interface": true,
+	"is": true, "null": true, "object": true, "package": true, "return": true, "super": true,
+	"this": true, "throw": true, "true": true, "try": true, "typealias": true, "typeof": true,
+	"val": true, "var": true, "when": true, "while": true,
+}
+
+// reservedTypeNames lists the names of the Kotlin standard library and generated types that
+// cannot be used by the generated classes, classes that use one of these names get the "Type"
+// suffix.
+var reservedTypeNames = map[string]bool{
+	"Any": true, "ApiException": true, "Boolean": true, "ByteArray": true, "Client": true,
+	"Double": true, "Error": true, "Exception": true, "Int": true, "JsonElement": true,
+	"JsonObject": true, "List": true, "Long": true, "Map": true, "Nothing": true, "Pair": true,
+	"Result": true, "String": true, "Unit": true, "UnexpectedResponseException": true,
+}
+
+// invalidNameChars matches the characters that cannot be used in Kotlin identifiers.
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// camelBoundary matches the boundaries between the words of camel case names.
+var camelBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
+// New builds the description of the Kotlin client package of the API. The package contains a
+// data class for each user type, media type view and payload, an enum class for each string
+// attribute that declares an Enum validation and a suspending client function for each action
+// route.
+func New(api *design.APIDefinition, name, baseURL string) (*Package, error) {
+	b := &builder{api: api, classes: make(map[string]*Class), enums: make(map[string]*Enum)}
+	err := api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		_, err := b.className(ut)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() {
+			return nil
+		}
+		return mt.IterateViews(func(v *design.ViewDefinition) error {
+			p, _, err := mt.Project(v.Name)
+			if err != nil {
+				return err
+			}
+			_, err = b.className(p)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	p := &Package{Name: name, BaseURL: baseURL}
+	err = api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() {
+				return nil
+			}
+			for i, r := range a.Routes {
+				f, err := b.function(a, r, i)
+				if err != nil {
+					return fmt.Errorf("%s: %s", a.Context(), err)
+				}
+				p.Functions = append(p.Functions, f)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(p.Functions, func(i, j int) bool { return p.Functions[i].Name < p.Functions[j].Name })
+	for _, c := range b.classes {
+		p.Classes = append(p.Classes, c)
+	}
+	sort.Slice(p.Classes, func(i, j int) bool { return p.Classes[i].Name < p.Classes[j].Name })
+	for _, e := range b.enums {
+		p.Enums = append(p.Enums, e)
+	}
+	sort.Slice(p.Enums, func(i, j int) bool { return p.Enums[i].Name < p.Enums[j].Name })
+	return p, nil
+}
+
+// builder computes the classes, enums and functions.
+type builder struct {
+	api     *design.APIDefinition
+	classes map[string]*Class
+	enums   map[string]*Enum
+}
+
+// function builds the client function that sends requests to the i-th route of the action.
+func (b *builder) function(a *design.ActionDefinition, r *design.RouteDefinition, i int) (*Function, error) {
+	name := codegen.Goify(a.Name, false) + codegen.Goify(a.Parent.Name, true)
+	if i > 0 {
+		name += strconv.Itoa(i + 1)
+	}
+	typeName := codegen.Goify(name, true)
+	f := &Function{
+		Name:        name,
+		Description: a.Description,
+		Verb:        r.Verb,
+		Returns:     "Unit",
+	}
+	if f.Description == "" {
+		f.Description = fmt.Sprintf("%s calls the %s action of the %s resource.", name, a.Name, a.Parent.Name)
+	}
+
+	var optional []*Arg
+	all := a.AllParams()
+	pathParams := r.Params()
+	interpolations := make(map[string]string)
+	for _, p := range pathParams {
+		var at *design.AttributeDefinition
+		if all != nil {
+			at = all.Type.ToObject()[p]
+		}
+		if at == nil {
+			at = &design.AttributeDefinition{Type: design.String}
+		}
+		typ, err := b.kotlinType(at, typeName+codegen.Goify(p, true))
+		if err != nil {
+			return nil, fmt.Errorf("parameter %#v: %s", p, err)
+		}
+		arg := &Arg{Name: identifier(p), Type: typ}
+		f.Args = append(f.Args, arg)
+		interpolations[p] = fmt.Sprintf("${pathEscape(%s, %t)}",
+			paramString(at, arg.Name), strings.Contains(r.FullPath(), "*"+p))
+	}
+	f.Path = pathTemplate(r.FullPath(), interpolations)
+
+	addParams := func(params *design.AttributeDefinition, header bool) error {
+		if params == nil {
+			return nil
+		}
+		for _, n := range codegen.SortedNames(params.Type.ToObject()) {
+			if !header && codegen.ContainsString(pathParams, n) {
+				continue
+			}
+			at := params.Type.ToObject()[n]
+			typ, err := b.kotlinType(at, typeName+codegen.Goify(n, true))
+			if err != nil {
+				return fmt.Errorf("parameter %#v: %s", n, err)
+			}
+			arg := &Arg{Name: identifier(n), Type: typ}
+			if params.IsRequired(n) {
+				f.Args = append(f.Args, arg)
+			} else {
+				arg.Type += "?"
+				arg.Optional = true
+				optional = append(optional, arg)
+			}
+			elem, v := at, arg.Name
+			if at.Type.IsArray() {
+				elem = at.Type.ToArray().ElemType
+			}
+			if at.Type.IsArray() || arg.Optional {
+				v = "it"
+			}
+			var stmt string
+			if header {
+				stmt = fmt.Sprintf("_headers[%s] = %s", quote(n), paramString(elem, v))
+			} else {
+				stmt = fmt.Sprintf("_query.add(%s to %s)", quote(n), paramString(elem, v))
+			}
+			switch {
+			case at.Type.IsArray() && arg.Optional:
+				stmt = fmt.Sprintf("%s?.forEach { %s }", arg.Name, stmt)
+			case at.Type.IsArray():
+				stmt = fmt.Sprintf("%s.forEach { %s }", arg.Name, stmt)
+			case arg.Optional:
+				stmt = fmt.Sprintf("%s?.let { %s }", arg.Name, stmt)
+			}
+			if header {
+				f.Headers = append(f.Headers, stmt)
+			} else {
+				f.Query = append(f.Query, stmt)
+			}
+		}
+		return nil
+	}
+	if err := addParams(a.QueryParams, false); err != nil {
+		return nil, err
+	}
+	if err := addParams(a.Headers, true); err != nil {
+		return nil, err
+	}
+
+	if a.Payload != nil {
+		typ, err := b.className(a.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("payload: %s", err)
+		}
+		arg := &Arg{Name: "payload", Type: typ}
+		if a.PayloadOptional {
+			arg.Type += "?"
+			arg.Optional = true
+			optional = append([]*Arg{arg}, optional...)
+		} else {
+			f.Args = append(f.Args, arg)
+		}
+		switch {
+		case a.PayloadMultipart:
+			stmts, err := multipart(a.Payload, arg.Optional)
+			if err != nil {
+				return nil, fmt.Errorf("payload: %s", err)
+			}
+			f.Body = stmts
+		case arg.Optional:
+			f.Body = []string{"val _body: RequestBody? = payload?.let { json.encodeToString(it).toRequestBody(JSON_MEDIA_TYPE) }"}
+		default:
+			f.Body = []string{"val _body: RequestBody? = json.encodeToString(payload).toRequestBody(JSON_MEDIA_TYPE)"}
+		}
+	}
+	f.Args = append(f.Args, optional...)
+
+	err := a.IterateResponses(func(resp *design.ResponseDefinition) error {
+		res, err := b.response(resp)
+		if err != nil {
+			return fmt.Errorf("response %#v: %s", resp.Name, err)
+		}
+		f.Responses = append(f.Responses, res)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(f.Responses, func(i, j int) bool { return f.Responses[i].Status < f.Responses[j].Status })
+	var successes []*Response
+	bodies := make(map[string]bool)
+	for _, res := range f.Responses {
+		if res.Error {
+			f.Error = typeName + "Exception"
+			continue
+		}
+		successes = append(successes, res)
+		bodies[res.Body] = true
+	}
+	switch {
+	case len(bodies) > 1:
+		f.Result = typeName + "Result"
+		f.Returns = f.Result
+	case len(successes) > 0 && successes[0].Body != "":
+		f.Returns = successes[0].Body
+	}
+	return f, nil
+}
+
+// response builds the description of the given action response.
+func (b *builder) response(r *design.ResponseDefinition) (*Response, error) {
+	res := &Response{
+		Status: r.Status,
+		Class:  typeName(r.Name),
+		Error:  r.Status < http.StatusOK || r.Status >= http.StatusMultipleChoices,
+	}
+	if r.Status == http.StatusNoContent {
+		return res, nil
+	}
+	if r.MediaType != "" {
+		mt, ok := b.api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]
+		if !ok {
+			res.Body, res.Text = "String", true
+			return res, nil
+		}
+		view := r.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return nil, err
+		}
+		name, err := b.className(p)
+		if err != nil {
+			return nil, err
+		}
+		res.Body = name
+		return res, nil
+	}
+	if r.Type != nil {
+		name, err := b.className(r.Type)
+		if err != nil {
+			return nil, err
+		}
+		res.Body = name
+	}
+	return res, nil
+}
+
+// className registers the class that corresponds to the given user type or media type and
+// returns its name.
+func (b *builder) className(t design.DataType) (string, error) {
+	t, err := projected(&design.AttributeDefinition{Type: t})
+	if err != nil {
+		return "", err
+	}
+	ut, ok := userType(t)
+	if !ok {
+		return "", fmt.Errorf("%s is not a user type", t.Name())
+	}
+	name := typeName(codegen.GoTypeName(t, nil, 0, false))
+	if c, ok := b.classes[name]; ok {
+		if c.DataType.Name() != t.Name() {
+			return "", fmt.Errorf("class %s is already defined", name)
+		}
+		return name, nil
+	}
+	c := &Class{Name: name, Description: ut.Description, DataType: t}
+	b.classes[name] = c
+	if !ut.Type.IsObject() {
+		alias, err := b.kotlinType(ut.AttributeDefinition, name+"Item")
+		if err != nil {
+			delete(b.classes, name)
+			return "", fmt.Errorf("type %s: %s", name, err)
+		}
+		c.Alias = alias
+		return name, nil
+	}
+	obj := ut.Type.ToObject()
+	for _, n := range codegen.SortedNames(obj) {
+		at := obj[n]
+		typ, err := b.kotlinType(at, name+codegen.Goify(n, true))
+		if err != nil {
+			delete(b.classes, name)
+			return "", fmt.Errorf("type %s: attribute %#v: %s", name, n, err)
+		}
+		field := &Field{
+			Name:        identifier(n),
+			AttName:     n,
+			Type:        typ,
+			Required:    ut.IsRequired(n),
+			Description: at.Description,
+		}
+		if !field.Required {
+			field.Type += "?"
+		}
+		c.Fields = append(c.Fields, field)
+	}
+	return name, nil
+}
+
+// kotlinType returns the Kotlin type of the values of the given attribute. enumName is the name
+// of the enum class generated if the attribute is a string that declares an Enum validation.
+func (b *builder) kotlinType(at *design.AttributeDefinition, enumName string) (string, error) {
+	switch actual := at.Type.(type) {
+	case design.Primitive:
+		typ := primitiveType(actual)
+		if !isEnum(at) {
+			return typ, nil
+		}
+		return b.enum(typeName(enumName), at)
+	case *design.Array:
+		elem, err := b.kotlinType(actual.ElemType, enumName)
+		if err != nil {
+			return "", err
+		}
+		return "List<" + elem + ">", nil
+	case *design.Hash:
+		elem, err := b.kotlinType(actual.ElemType, enumName)
+		if err != nil {
+			return "", err
+		}
+		return "Map<String, " + elem + ">", nil
+	case design.Object:
+		return "JsonObject", nil
+	case *design.MediaTypeDefinition, *design.UserTypeDefinition:
+		t, err := projected(at)
+		if err != nil {
+			return "", err
+		}
+		return b.className(t)
+	}
+	return "", fmt.Errorf("unsupported type %s", at.Type.Name())
+}
+
+// enum registers the enum class that lists the values of the given attribute and returns its
+// name.
+func (b *builder) enum(name string, at *design.AttributeDefinition) (string, error) {
+	e := &Enum{Name: name, Description: at.Description}
+	used := make(map[string]bool)
+	for _, v := range at.Validation.Values {
+		val, ok := v.(string)
+		if !ok {
+			return "", fmt.Errorf("unsupported enum value %#v", v)
+		}
+		entry := &EnumEntry{Name: entryName(val), Value: val}
+		base := entry.Name
+		for i := 2; used[entry.Name]; i++ {
+			entry.Name = fmt.Sprintf("%s_%d", base, i)
+		}
+		used[entry.Name] = true
+		e.Entries = append(e.Entries, entry)
+	}
+	if existing, ok := b.enums[name]; ok {
+		if !sameEntries(existing, e) {
+			return "", fmt.Errorf("enum %s is already defined", name)
+		}
+		return name, nil
+	}
+	b.enums[name] = e
+	return name, nil
+}
+
+// sameEntries returns true if the two enum classes define the same entries.
+func sameEntries(e1, e2 *Enum) bool {
+	if len(e1.Entries) != len(e2.Entries) {
+		return false
+	}
+	for i, entry := range e1.Entries {
+		if *entry != *e2.Entries[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// multipart returns the statements that encode the given payload as multipart form data.
+func multipart(payload *design.UserTypeDefinition, optional bool) ([]string, error) {
+	if !payload.Type.IsObject() {
+		return nil, fmt.Errorf("multipart payloads must be objects")
+	}
+	var stmts []string
+	obj := payload.Type.ToObject()
+	for _, n := range codegen.SortedNames(obj) {
+		at := obj[n]
+		v := "payload." + identifier(n)
+		if !payload.IsRequired(n) {
+			v = "it"
+		}
+		var stmt string
+		switch {
+		case at.Type.Kind() == design.FileKind:
+			stmt = fmt.Sprintf("_form.addFormDataPart(%s, %s, %s.toRequestBody(OCTET_STREAM_MEDIA_TYPE))", quote(n), quote(n), v)
+		case at.Type.IsPrimitive():
+			stmt = fmt.Sprintf("_form.addFormDataPart(%s, %s)", quote(n), paramString(at, v))
+		default:
+			stmt = fmt.Sprintf("_form.addFormDataPart(%s, null, json.encodeToString(%s).toRequestBody(JSON_MEDIA_TYPE))", quote(n), v)
+		}
+		if !payload.IsRequired(n) {
+			stmt = fmt.Sprintf("payload.%s?.let { %s }", identifier(n), stmt)
+		}
+		stmts = append(stmts, stmt)
+	}
+	body := "val _body: RequestBody? = _form.build()"
+	if optional {
+		for i, stmt := range stmts {
+			stmts[i] = "    " + stmt
+		}
+		stmts = append([]string{"if (payload != null) {"}, stmts...)
+		stmts = append(stmts, "}")
+		body = "val _body: RequestBody? = if (payload != null) _form.build() else null"
+	}
+	stmts = append([]string{"val _form = MultipartBody.Builder().setType(MultipartBody.FORM)"}, stmts...)
+	return append(stmts, body), nil
+}
+
+// primitiveType returns the Kotlin type used to represent values of the given primitive.
+// Date times, UUIDs and decimals are represented with their string representation.
+func primitiveType(p design.Primitive) string {
+	switch p.Kind() {
+	case design.BooleanKind:
+		return "Boolean"
+	case design.IntegerKind:
+		return "Long"
+	case design.NumberKind:
+		return "Double"
+	case design.StringKind, design.DateTimeKind, design.UUIDKind, design.DecimalKind:
+		return "String"
+	case design.FileKind:
+		return "ByteArray"
+	case design.AnyKind:
+		return "JsonElement"
+	}
+	if c := design.CustomPrimitive(p); c != nil {
+		switch c.JSONType {
+		case "integer":
+			return "Long"
+		case "number":
+			return "Double"
+		case "boolean":
+			return "Boolean"
+		}
+		return "String"
+	}
+	return "JsonElement"
+}
+
+// isEnum returns true if the attribute is a string that declares an Enum validation.
+func isEnum(at *design.AttributeDefinition) bool {
+	return at.Type.Kind() == design.StringKind && at.Validation != nil && len(at.Validation.Values) > 0
+}
+
+// paramString returns the expression that converts the value v of the given attribute into
+// its string representation.
+func paramString(at *design.AttributeDefinition, v string) string {
+	if isEnum(at) {
+		return v + ".value"
+	}
+	if at.Type.Kind() == design.StringKind {
+		return v
+	}
+	return v + ".toString()"
+}
+
+// projected returns the type of the attribute, media types are projected using the view of the
+// attribute or the default view.
+func projected(at *design.AttributeDefinition) (design.DataType, error) {
+	mt, ok := at.Type.(*design.MediaTypeDefinition)
+	if !ok || strings.Contains(mt.Identifier, "view=") {
+		return at.Type, nil
+	}
+	view := at.View
+	if view == "" {
+		view = design.DefaultView
+	}
+	p, _, err := mt.Project(view)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// userType returns the user type definition of the given user type or media type.
+func userType(t design.DataType) (*design.UserTypeDefinition, bool) {
+	switch actual := t.(type) {
+	case *design.MediaTypeDefinition:
+		return actual.UserTypeDefinition, true
+	case *design.UserTypeDefinition:
+		return actual, true
+	}
+	return nil, false
+}
+
+// identifier returns the lower camel case Kotlin identifier that corresponds to the given name.
+func identifier(n string) string {
+	name := codegen.Goify(invalidNameChars.ReplaceAllString(n, "_"), false)
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	if reservedWords[name] {
+		return "`" + name + "`"
+	}
+	return name
+}
+
+// entryName returns the upper snake case name of the enum entry that corresponds to the given
+// value.
+func entryName(v string) string {
+	v = camelBoundary.ReplaceAllString(v, "${1}_${2}")
+	v = strings.Trim(invalidNameChars.ReplaceAllString(v, "_"), "_")
+	if v == "" {
+		return "EMPTY"
+	}
+	if v[0] >= '0' && v[0] <= '9' {
+		v = "VALUE_" + v
+	}
+	return strings.ToUpper(v)
+}
+
+// typeName returns the Kotlin type name that corresponds to the given name.
+func typeName(n string) string {
+	name := invalidNameChars.ReplaceAllString(codegen.Goify(n, true), "")
+	if reservedTypeNames[name] {
+		name += "Type"
+	}
+	return name
+}
+
+// pathTemplate returns the Kotlin string template that builds the given route path,
+// interpolations maps the names of the path parameters to the expression that replaces them.
+func pathTemplate(path string, interpolations map[string]string) string {
+	var b strings.Builder
+	b.WriteString(`"`)
+	last := 0
+	for _, loc := range codegen.PathParamRegex.FindAllStringSubmatchIndex(path, -1) {
+		b.WriteString(escape(path[last:loc[0]]))
+		if interpolation, ok := interpolations[path[loc[2]:loc[3]]]; ok {
+			b.WriteString(interpolation)
+		} else {
+			b.WriteString(escape(path[loc[0]:loc[1]]))
+		}
+		last = loc[1]
+	}
+	b.WriteString(escape(path[last:]))
+	b.WriteString(`"`)
+	return b.String()
+}
+
+// quote returns the Kotlin string literal of s.
+func quote(s string) string {
+	return `"` + escape(s) + `"`
+}
+
+// escape escapes the characters of s that cannot appear as is in a Kotlin string literal.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '"', '\\', '$':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,159 @@
+package genkotlin_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genkotlin "github.com/kyokomi/goa-v1/goagen/gen_kotlin"
+)
+
+var _ = Describe("New", func() {
+	var pkg *genkotlin.Package
+	var newErr error
+
+	BeforeEach(func() {
+		pkg = nil
+		newErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		pkg, newErr = genkotlin.New(Design, "cellar.client", "https://api.example.com")
+	})
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			winery := apidsl.Type("Winery", func() {
+				apidsl.Attribute("name", String)
+				apidsl.Required("name")
+			})
+			bottle := apidsl.MediaType("application/vnd.bottle", func() {
+				apidsl.Description("A bottle of wine")
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer, "ID of bottle")
+					apidsl.Attribute("color", String, func() {
+						apidsl.Enum("red", "white", "rosé", "2nd")
+					})
+					apidsl.Attribute("winery", winery)
+					apidsl.Attribute("created_at", DateTime)
+					apidsl.Required("id", "color")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("color")
+					apidsl.Attribute("winery")
+					apidsl.Attribute("created_at")
+				})
+			})
+			apidsl.API("cellar", func() {
+				apidsl.BasePath("/api")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Description("Get bottle by id")
+					apidsl.Routing(apidsl.GET("/:bottleID"))
+					apidsl.Params(func() {
+						apidsl.Param("bottleID", Integer)
+						apidsl.Param("fields", apidsl.ArrayOf(String))
+					})
+					apidsl.Headers(func() {
+						apidsl.Header("X-Request-Id")
+					})
+					apidsl.Response(OK, bottle)
+					apidsl.Response(NotFound)
+					apidsl.Response(BadRequest, ErrorMedia)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(func() {
+						apidsl.Member("name", String)
+						apidsl.Member("winery", winery)
+						apidsl.Required("name")
+					})
+					apidsl.Response(Created, bottle)
+					apidsl.Response(NoContent)
+				})
+			})
+		})
+
+		It("generates the classes", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			var names []string
+			for _, c := range pkg.Classes {
+				names = append(names, c.Name)
+			}
+			Ω(names).Should(Equal([]string{"Bottle", "CreateBottlePayload", "ErrorType", "Winery"}))
+			bottle := pkg.Classes[0]
+			Ω(bottle.Fields).Should(HaveLen(4))
+			Ω(bottle.Fields[0].Declaration()).Should(Equal("val color: BottleColor"))
+			Ω(bottle.Fields[1].Declaration()).Should(Equal("@SerialName(\"created_at\")\n    val createdAt: String? = null"))
+			Ω(bottle.Fields[2].Type).Should(Equal("Long"))
+			Ω(bottle.Fields[3].Type).Should(Equal("Winery?"))
+		})
+
+		It("generates the enums", func() {
+			Ω(pkg.Enums).Should(HaveLen(1))
+			color := pkg.Enums[0]
+			Ω(color.Name).Should(Equal("BottleColor"))
+			var entries []string
+			for _, e := range color.Entries {
+				entries = append(entries, e.Name+" = "+e.Value)
+			}
+			Ω(entries).Should(Equal([]string{"RED = red", "WHITE = white", "ROS = rosé", "VALUE_2ND = 2nd"}))
+		})
+
+		It("generates the functions", func() {
+			Ω(pkg.Functions).Should(HaveLen(2))
+			create := pkg.Functions[0]
+			Ω(create.Name).Should(Equal("createBottle"))
+			Ω(create.Signature()).Should(Equal("payload: CreateBottlePayload"))
+			Ω(create.Returns).Should(Equal("CreateBottleResult"))
+			Ω(create.Error).Should(BeEmpty())
+			Ω(create.Handle(create.Responses[0])).Should(Equal("CreateBottleResult.Created(json.decodeFromString<Bottle>(_response.text()))"))
+			Ω(create.Handle(create.Responses[1])).Should(Equal("CreateBottleResult.NoContent"))
+			show := pkg.Functions[1]
+			Ω(show.Name).Should(Equal("showBottle"))
+			Ω(show.Path).Should(Equal(`"/api/bottles/${pathEscape(bottleID.toString(), false)}"`))
+			Ω(show.Signature()).Should(Equal("bottleID: Long, fields: List<String>? = null, xRequestID: String? = null"))
+			Ω(show.Query).Should(Equal([]string{`fields?.forEach { _query.add("fields" to it) }`}))
+			Ω(show.Headers).Should(Equal([]string{`xRequestID?.let { _headers["X-Request-Id"] = it }`}))
+			Ω(show.Returns).Should(Equal("Bottle"))
+			Ω(show.Error).Should(Equal("ShowBottleException"))
+			Ω(show.Responses).Should(HaveLen(3))
+			Ω(show.Handle(show.Responses[0])).Should(Equal("json.decodeFromString<Bottle>(_response.text())"))
+			Ω(show.Handle(show.Responses[1])).Should(Equal("throw ShowBottleException.BadRequest(json.decodeFromString<ErrorType>(_response.text()))"))
+			Ω(show.Handle(show.Responses[2])).Should(Equal("throw ShowBottleException.NotFound()"))
+		})
+
+		It("renders the files", func() {
+			Ω(pkg.Files()).Should(Equal([]string{"build.gradle.kts", "src/main/kotlin/cellar/client/Models.kt", "src/main/kotlin/cellar/client/Client.kt"}))
+			build, err := pkg.Write("build.gradle.kts", "cellar: Kotlin Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(build)).Should(ContainSubstring(`implementation("com.squareup.okhttp3:okhttp:4.12.0")`))
+			models, err := pkg.Write("src/main/kotlin/cellar/client/Models.kt", "cellar: Kotlin Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(models)).Should(ContainSubstring("package cellar.client\n"))
+			Ω(string(models)).Should(ContainSubstring("@Serializable\nenum class BottleColor(val value: String) {\n    @SerialName(\"red\")\n    RED(\"red\"),\n"))
+			Ω(string(models)).Should(ContainSubstring("/** A bottle of wine (default view) */\n@Serializable\ndata class Bottle(\n"))
+			Ω(string(models)).Should(ContainSubstring("    /** ID of bottle */\n    val id: Long,\n"))
+			client, err := pkg.Write("src/main/kotlin/cellar/client/Client.kt", "cellar: Kotlin Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(client)).Should(ContainSubstring(`const val DEFAULT_BASE_URL = "https://api.example.com"`))
+			Ω(string(client)).Should(ContainSubstring("    suspend fun showBottle(bottleID: Long, fields: List<String>? = null, xRequestID: String? = null): Bottle {\n"))
+			Ω(string(client)).Should(ContainSubstring("        val _body: RequestBody? = json.encodeToString(payload).toRequestBody(JSON_MEDIA_TYPE)\n"))
+			Ω(string(client)).Should(ContainSubstring("                404 -> throw ShowBottleException.NotFound()\n"))
+			Ω(string(client)).Should(ContainSubstring("sealed class ShowBottleException(status: Int) : ApiException(status, \"HTTP $status\") {\n"))
+			Ω(string(client)).Should(ContainSubstring("    class BadRequest(val body: ErrorType) : ShowBottleException(400)\n"))
+			Ω(string(client)).Should(ContainSubstring("    object NoContent : CreateBottleResult()\n"))
+			_, err = pkg.Write("src/main/kotlin/cellar/client/Other.kt", "cellar: Kotlin Client")
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
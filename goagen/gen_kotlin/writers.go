@@ -0,0 +1,340 @@
+package genkotlin
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1/version"
+)
+
+// Files returns the paths of the generated files relative to the project directory in order of
+// generation.
+func (p *Package) Files() []string {
+	dir := filepath.Join(append([]string{"src", "main", "kotlin"}, strings.Split(p.Name, ".")...)...)
+	return []string{
+		"build.gradle.kts",
+		filepath.Join(dir, "Models.kt"),
+		filepath.Join(dir, "Client.kt"),
+	}
+}
+
+// templates maps the base names of the generated files to their template.
+var templates = map[string]*template.Template{
+	"build.gradle.kts": newTemplate("build", buildT),
+	"Models.kt":        newTemplate("models", modelsT),
+	"Client.kt":        newTemplate("client", clientT),
+}
+
+// newTemplate parses the given template text.
+func newTemplate(name, text string) *template.Template {
+	return template.Must(template.New(name).Funcs(template.FuncMap{
+		"kdoc":  kdoc,
+		"quote": quote,
+	}).Parse(text))
+}
+
+// Write renders the file with the given path, title is written in the header comment.
+func (p *Package) Write(file, title string) ([]byte, error) {
+	tmpl, ok := templates[filepath.Base(file)]
+	if !ok {
+		return nil, fmt.Errorf("unknown file %s", file)
+	}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"Title":       title,
+		"ToolVersion": version.String(),
+		"Package":     p,
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Declaration returns the declaration of the constructor property.
+func (f *Field) Declaration() string {
+	decl := fmt.Sprintf("val %s: %s", f.Name, f.Type)
+	if !f.Required {
+		decl += " = null"
+	}
+	if f.Name != f.AttName {
+		decl = fmt.Sprintf("@SerialName(%s)\n    %s", quote(f.AttName), decl)
+	}
+	return decl
+}
+
+// Signature returns the list of arguments of the function, optional arguments default to null.
+func (f *Function) Signature() string {
+	args := make([]string, len(f.Args))
+	for i, a := range f.Args {
+		args[i] = fmt.Sprintf("%s: %s", a.Name, a.Type)
+		if a.Optional {
+			args[i] += " = null"
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
+// Doc returns the KDoc comment of the function, it lists the exceptions thrown by the function.
+func (f *Function) Doc() string {
+	text := f.Description + "\n"
+	if f.Error != "" {
+		text += "\n@throws " + f.Error + " for the declared error responses."
+	}
+	text += "\n@throws UnexpectedResponseException for the statuses that are not described in the design."
+	return kdoc("    ", text)
+}
+
+// Handle returns the expression of the when branch that handles the given response.
+func (f *Function) Handle(r *Response) string {
+	var value string
+	switch {
+	case r.Text:
+		value = "_response.text()"
+	case r.Body != "":
+		value = fmt.Sprintf("json.decodeFromString<%s>(_response.text())", r.Body)
+	}
+	switch {
+	case r.Error && value == "":
+		return fmt.Sprintf("throw %s.%s()", f.Error, r.Class)
+	case r.Error:
+		return fmt.Sprintf("throw %s.%s(%s)", f.Error, r.Class, value)
+	case f.Result != "" && value == "":
+		return f.Result + "." + r.Class
+	case f.Result != "":
+		return fmt.Sprintf("%s.%s(%s)", f.Result, r.Class, value)
+	case value == "":
+		return "Unit"
+	}
+	return value
+}
+
+// Successes returns the 2xx responses of the function.
+func (f *Function) Successes() []*Response {
+	var res []*Response
+	for _, r := range f.Responses {
+		if !r.Error {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// Errors returns the non 2xx responses of the function.
+func (f *Function) Errors() []*Response {
+	var res []*Response
+	for _, r := range f.Responses {
+		if r.Error {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// ResultDeclaration returns the declaration of the subclass of the sealed result class that
+// describes the response.
+func (f *Function) ResultDeclaration(r *Response) string {
+	if r.Body == "" {
+		return fmt.Sprintf("object %s : %s()", r.Class, f.Result)
+	}
+	return fmt.Sprintf("data class %s(val body: %s) : %s()", r.Class, r.Body, f.Result)
+}
+
+// ErrorDeclaration returns the declaration of the subclass of the sealed exception class that
+// describes the response.
+func (f *Function) ErrorDeclaration(r *Response) string {
+	if r.Body == "" {
+		return fmt.Sprintf("class %s : %s(%d)", r.Class, f.Error, r.Status)
+	}
+	return fmt.Sprintf("class %s(val body: %s) : %s(%d)", r.Class, r.Body, f.Error, r.Status)
+}
+
+// StatusText returns the text of the response status.
+func (r *Response) StatusText() string {
+	return fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status))
+}
+
+// kdoc renders the given text as a KDoc comment followed by a new line, indent is prepended to
+// each line.
+func kdoc(indent, text string) string {
+	text = strings.TrimSpace(strings.Replace(text, "*/", "*\\/", -1))
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		return indent + "/** " + lines[0] + " */\n"
+	}
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(indent+" * "+l, " ")
+	}
+	return indent + "/**\n" + strings.Join(lines, "\n") + "\n" + indent + " */\n"
+}
+
+const headerT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .Title }}
+`
+
+const buildT = headerT + `
+plugins {
+    kotlin("jvm") version "1.9.22"
+    kotlin("plugin.serialization") version "1.9.22"
+}
+
+repositories {
+    mavenCentral()
+}
+
+dependencies {
+    implementation("com.squareup.okhttp3:okhttp:4.12.0")
+    implementation("org.jetbrains.kotlinx:kotlinx-coroutines-core:1.7.3")
+    implementation("org.jetbrains.kotlinx:kotlinx-serialization-json:1.6.2")
+}
+`
+
+const modelsT = headerT + `
+package {{ .Package.Name }}
+
+import kotlinx.serialization.SerialName
+import kotlinx.serialization.Serializable
+import kotlinx.serialization.json.JsonElement
+import kotlinx.serialization.json.JsonObject
+{{ range .Package.Enums }}
+{{ kdoc "" .Description }}@Serializable
+enum class {{ .Name }}(val value: String) {
+{{ range .Entries }}    @SerialName({{ quote .Value }})
+    {{ .Name }}({{ quote .Value }}),
+{{ end }}}
+{{ end }}{{ range .Package.Classes }}{{ if .Alias }}
+{{ kdoc "" .Description }}typealias {{ .Name }} = {{ .Alias }}
+{{ else if .Fields }}
+{{ kdoc "" .Description }}@Serializable
+data class {{ .Name }}(
+{{ range .Fields }}{{ kdoc "    " .Description }}    {{ .Declaration }},
+{{ end }})
+{{ else }}
+{{ kdoc "" .Description }}@Serializable
+class {{ .Name }}
+{{ end }}{{ end }}`
+
+const clientT = headerT + `
+package {{ .Package.Name }}
+
+import java.io.IOException
+import java.net.URLEncoder
+import kotlin.coroutines.resume
+import kotlin.coroutines.resumeWithException
+import kotlinx.coroutines.suspendCancellableCoroutine
+import kotlinx.serialization.decodeFromString
+import kotlinx.serialization.encodeToString
+import kotlinx.serialization.json.Json
+import okhttp3.Call
+import okhttp3.Callback
+import okhttp3.HttpUrl.Companion.toHttpUrl
+import okhttp3.MediaType.Companion.toMediaType
+import okhttp3.MultipartBody
+import okhttp3.OkHttpClient
+import okhttp3.Request
+import okhttp3.RequestBody
+import okhttp3.RequestBody.Companion.toRequestBody
+import okhttp3.Response
+
+/** ApiException is the base class of the exceptions thrown when the API returns an error response. */
+open class ApiException(val status: Int, message: String) : IOException(message)
+
+/** UnexpectedResponseException is thrown when the API returns a status that is not described in the design. */
+class UnexpectedResponseException(status: Int, val body: String) : ApiException(status, "unexpected response status $status")
+
+/**
+ * Client gives access to the API.
+ *
+ * baseUrl is prepended to the request paths, httpClient sends the requests, headers are sent
+ * with every request and json encodes the request bodies and decodes the response bodies.
+ */
+class Client(
+    private val baseUrl: String = DEFAULT_BASE_URL,
+    private val httpClient: OkHttpClient = OkHttpClient(),
+    private val headers: Map<String, String> = emptyMap(),
+    private val json: Json = Json { ignoreUnknownKeys = true },
+) {
+{{ range $f := .Package.Functions }}
+{{ .Doc }}    suspend fun {{ .Name }}({{ .Signature }}): {{ .Returns }} {
+        val _query = mutableListOf<Pair<String, String>>()
+{{ range .Query }}        {{ . }}
+{{ end }}        val _headers = mutableMapOf<String, String>()
+{{ range .Headers }}        {{ . }}
+{{ end }}{{ range .Body }}        {{ . }}
+{{ else }}        val _body: RequestBody? = null
+{{ end }}        return send({{ quote .Verb }}, {{ .Path }}, _query, _headers, _body).use { _response ->
+            when (_response.code) {
+{{ range .Responses }}                {{ .Status }} -> {{ $f.Handle . }}
+{{ end }}                else -> throw UnexpectedResponseException(_response.code, _response.text())
+            }
+        }
+    }
+{{ end }}
+    private suspend fun send(method: String, path: String, query: List<Pair<String, String>>, headers: Map<String, String>, body: RequestBody?): Response {
+        val url = (baseUrl.trimEnd('/') + path).toHttpUrl().newBuilder()
+        for ((key, value) in query) {
+            url.addQueryParameter(key, value)
+        }
+        val request = Request.Builder().url(url.build())
+        for ((key, value) in this.headers) {
+            request.header(key, value)
+        }
+        for ((key, value) in headers) {
+            request.header(key, value)
+        }
+        request.method(method, body ?: if (method in BODY_METHODS) EMPTY_BODY else null)
+        val call = httpClient.newCall(request.build())
+        return suspendCancellableCoroutine { cont ->
+            cont.invokeOnCancellation { call.cancel() }
+            call.enqueue(object : Callback {
+                override fun onFailure(call: Call, e: IOException) {
+                    cont.resumeWithException(e)
+                }
+
+                override fun onResponse(call: Call, response: Response) {
+                    cont.resume(response)
+                }
+            })
+        }
+    }
+
+    companion object {
+        /** DEFAULT_BASE_URL is the base URL used when none is given. */
+        const val DEFAULT_BASE_URL = {{ quote .Package.BaseURL }}
+
+        private val JSON_MEDIA_TYPE = "application/json".toMediaType()
+        private val OCTET_STREAM_MEDIA_TYPE = "application/octet-stream".toMediaType()
+        private val BODY_METHODS = setOf("POST", "PUT", "PATCH")
+        private val EMPTY_BODY = ByteArray(0).toRequestBody(null)
+    }
+}
+{{ range $f := .Package.Functions }}{{ if .Result }}
+/** {{ .Result }} lists the successful responses of {{ .Name }}. */
+sealed class {{ .Result }} {
+{{ range $i, $r := .Successes }}{{ if $i }}
+{{ end }}    /** {{ $r.Class }} describes the {{ $r.StatusText }} response. */
+    {{ $f.ResultDeclaration $r }}
+{{ end }}}
+{{ end }}{{ if .Error }}
+/** {{ .Error }} lists the error responses of {{ .Name }}. */
+sealed class {{ .Error }}(status: Int) : ApiException(status, "HTTP $status") {
+{{ range $i, $r := .Errors }}{{ if $i }}
+{{ end }}    /** {{ $r.Class }} is thrown when the API returns the {{ $r.StatusText }} response. */
+    {{ $f.ErrorDeclaration $r }}
+{{ end }}}
+{{ end }}{{ end }}
+private fun Response.text(): String = body?.string() ?: ""
+
+private fun pathEscape(value: String, keepSlashes: Boolean): String {
+    val escaped = URLEncoder.encode(value, "UTF-8").replace("+", "%20")
+    return if (keepSlashes) escaped.replace("%2F", "/") else escaped
+}
+`
@@ -0,0 +1,22 @@
+/*
+Package genload provides a generator for load test scenarios of the API. The generator produces a
+k6 script with one scenario per action route and vegeta targets listing sample requests for each
+route. The requests are seeded with the examples of the design and draw the values of the
+parameters, headers and payload fields from distributions derived from their validations: enum
+attributes pick among the enum values, numbers with a minimum or maximum are drawn uniformly from
+the range and optional attributes are only set half of the time.
+
+The k6 script reads the base URL of the API from the BASE_URL environment variable, the number of
+virtual users and the duration of the scenarios from VUS and DURATION and the credentials of the
+security schemes from variables prefixed with the scheme name, e.g. JWT_TOKEN. The SCENARIOS
+variable restricts the run to a comma separated list of scenarios:
+
+	k6 run -e BASE_URL=http://localhost:8080 -e SCENARIOS=show_bottle load/k6.js
+
+The vegeta targets use the JSON format and the host of the API:
+
+	vegeta attack -format=json -targets=load/targets.json -rate=50 -duration=30s | vegeta report
+
+See https://k6.io and https://github.com/tsenart/vegeta for more information on the tools.
+*/
+package genload
@@ -0,0 +1,139 @@
+package genload
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of a load test Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the load test scenarios generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Samples  int                   // Number of vegeta targets generated per action route
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, ver string
+		samples     int
+	)
+
+	set := flag.NewFlagSet("load", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.IntVar(&samples, "samples", 10, "")
+	set.String("design", "", "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Samples: samples, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the k6 script and the vegeta targets.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.Samples <= 0 {
+		g.Samples = 10
+	}
+
+	scenarios, err := Scenarios(g.API)
+	if err != nil {
+		return nil, err
+	}
+
+	loadDir := filepath.Join(g.OutDir, "load")
+	if err = codegen.RemoveAll(loadDir); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(loadDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, loadDir)
+
+	baseURL := g.baseURL()
+	script, err := WriteK6(fmt.Sprintf("%s: k6 Load Test Scenarios", g.API.Context()), baseURL, scenarios)
+	if err != nil {
+		return nil, err
+	}
+	// Seed the targets from the API so that they do not change between generations
+	r := rand.New(rand.NewSource(int64(g.API.RandomGenerator().Int())))
+	targets, err := WriteVegeta(baseURL, scenarios, g.Samples, r)
+	if err != nil {
+		return nil, err
+	}
+	docs := []struct {
+		name    string
+		content []byte
+	}{
+		{"k6.js", script},
+		{"targets.json", targets},
+	}
+	for _, d := range docs {
+		file := filepath.Join(loadDir, d.name)
+		if err = codegen.WriteFile(file, d.content, 0644); err != nil {
+			return nil, err
+		}
+		g.genfiles = append(g.genfiles, file)
+	}
+
+	return g.genfiles, nil
+}
+
+// baseURL returns the base URL of the requests built from the scheme and host of the API.
+func (g *Generator) baseURL() string {
+	scheme := "http"
+	if len(g.API.Schemes) > 0 {
+		scheme = g.API.Schemes[0]
+	}
+	host := g.API.Host
+	if host == "" {
+		host = "localhost:8080"
+	}
+	return scheme + "://" + host
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
@@ -0,0 +1,42 @@
+package genload_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	genload "github.com/kyokomi/goa-v1/goagen/gen_load"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genload.Generator
+
+	var args = struct {
+		api     *design.APIDefinition
+		outDir  string
+		samples int
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir:  "out_dir",
+		samples: 5,
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genload.NewGenerator(
+				genload.API(args.api),
+				genload.OutDir(args.outDir),
+				genload.Samples(args.samples),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Samples).Should(Equal(args.samples))
+		})
+	})
+})
@@ -0,0 +1,332 @@
+package genload
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+// rangeWidth is the width of the ranges drawn for numbers that only define a minimum or a maximum.
+const rangeWidth = 100
+
+type (
+	// Scenario describes the requests made by the load tests for an action route.
+	Scenario struct {
+		// Name of the scenario, e.g. "show_bottle".
+		Name string
+		// Func is the name of the k6 function that runs the scenario.
+		Func string
+		// Action is the action of the route.
+		Action *design.ActionDefinition
+		// Method is the HTTP method of the route.
+		Method string
+		// Path is the full path of the route, e.g. "/bottles/:id".
+		Path string
+		// Params lists the path parameters indexed by name.
+		Params map[string]*Param
+		// Query lists the query string parameters.
+		Query []*Param
+		// Headers lists the request headers.
+		Headers []*Param
+		// Payload lists the payload fields if the payload is an object.
+		Payload []*Param
+		// Body is the distribution of the payload if it is not an object.
+		Body *Distribution
+		// Multipart is true if the payload is encoded as multipart form data.
+		Multipart bool
+		// Statuses lists the success status codes of the action responses.
+		Statuses []int
+	}
+
+	// Param describes a path or query string parameter, a header or a payload field.
+	Param struct {
+		// Name of the parameter.
+		Name string
+		// Required is true if the requests must set the parameter, optional parameters are only
+		// set half of the time.
+		Required bool
+		// File is true for the file parts of multipart payloads.
+		File bool
+		// Values is the distribution of the parameter values.
+		Values *Distribution
+	}
+
+	// Distribution describes the values drawn for an attribute. The values are either picked
+	// among a list or drawn uniformly from a range.
+	Distribution struct {
+		// Values lists the values to pick from if the distribution is not a range.
+		Values []interface{}
+		// Range is true if the values are drawn from the range bounded by Min and Max.
+		Range bool
+		// Min is the lower bound of the range.
+		Min float64
+		// Max is the upper bound of the range.
+		Max float64
+		// Integer is true if the values drawn from the range are integers.
+		Integer bool
+	}
+
+	// Target is a vegeta target in JSON format.
+	Target struct {
+		Method string              `json:"method"`
+		URL    string              `json:"url"`
+		Body   []byte              `json:"body,omitempty"`
+		Header map[string][]string `json:"header,omitempty"`
+	}
+)
+
+// Scenarios returns the load test scenarios of the API, one per action route. WebSocket actions
+// are not load tested.
+func Scenarios(api *design.APIDefinition) ([]*Scenario, error) {
+	rand := api.RandomGenerator()
+	var scenarios []*Scenario
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() {
+				return nil
+			}
+			for i, r := range a.Routes {
+				name := codegen.SnakeCase(a.Name + "_" + res.Name)
+				if i > 0 {
+					name = fmt.Sprintf("%s_%d", name, i+1)
+				}
+				scenarios = append(scenarios, newScenario(name, a, r, rand))
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scenarios, nil
+}
+
+// newScenario builds the scenario of the given action route.
+func newScenario(name string, a *design.ActionDefinition, r *design.RouteDefinition, rand *design.RandomGenerator) *Scenario {
+	s := &Scenario{
+		Name:   name,
+		Func:   codegen.Goify(name, false),
+		Action: a,
+		Method: r.Verb,
+		Path:   r.FullPath(),
+		Params: make(map[string]*Param),
+	}
+	pathParams := r.Params()
+	if all := a.AllParams(); all != nil {
+		obj := all.Type.ToObject()
+		for _, n := range pathParams {
+			if at, ok := obj[n]; ok {
+				s.Params[n] = &Param{Name: n, Required: true, Values: NewDistribution(at, rand)}
+			}
+		}
+	}
+	if a.QueryParams != nil {
+		for _, p := range params(a.QueryParams, rand) {
+			if !codegen.ContainsString(pathParams, p.Name) {
+				s.Query = append(s.Query, p)
+			}
+		}
+	}
+	if a.Headers != nil {
+		s.Headers = params(a.Headers, rand)
+	}
+	if a.Payload != nil {
+		s.Multipart = a.PayloadMultipart
+		if a.Payload.IsObject() {
+			s.Payload = params(a.Payload.AttributeDefinition, rand)
+		} else {
+			s.Body = &Distribution{Values: []interface{}{a.Payload.GenerateExample(rand, nil)}}
+		}
+	}
+	for _, resp := range a.Responses {
+		if resp.Status < 400 {
+			s.Statuses = append(s.Statuses, resp.Status)
+		}
+	}
+	sort.Ints(s.Statuses)
+	return s
+}
+
+// params returns the parameters that correspond to the attributes of the given object.
+func params(att *design.AttributeDefinition, rand *design.RandomGenerator) []*Param {
+	obj := att.Type.ToObject()
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	res := make([]*Param, len(names))
+	for i, n := range names {
+		at := obj[n]
+		res[i] = &Param{
+			Name:     n,
+			Required: att.IsRequired(n),
+			File:     at.Type.Kind() == design.FileKind,
+			Values:   NewDistribution(at, rand),
+		}
+	}
+	return res
+}
+
+// NewDistribution returns the distribution of the values of the given attribute. Enum attributes
+// pick among the enum values, numbers with a minimum or a maximum are drawn from the range and
+// booleans pick between true and false. The other attributes use the example of the design.
+func NewDistribution(at *design.AttributeDefinition, rand *design.RandomGenerator) *Distribution {
+	if v := at.Validation; v != nil {
+		if len(v.Values) > 0 {
+			return &Distribution{Values: v.Values}
+		}
+		k := at.Type.Kind()
+		if (k == design.IntegerKind || k == design.NumberKind) && (v.Minimum != nil || v.Maximum != nil) {
+			d := &Distribution{Range: true, Integer: k == design.IntegerKind}
+			switch {
+			case v.Minimum != nil && v.Maximum != nil:
+				d.Min, d.Max = *v.Minimum, *v.Maximum
+			case v.Minimum != nil:
+				d.Min, d.Max = *v.Minimum, *v.Minimum+rangeWidth
+			default:
+				d.Min, d.Max = *v.Maximum-rangeWidth, *v.Maximum
+			}
+			return d
+		}
+	}
+	if at.Type.Kind() == design.BooleanKind {
+		return &Distribution{Values: []interface{}{true, false}}
+	}
+	return &Distribution{Values: []interface{}{at.GenerateExample(rand, nil)}}
+}
+
+// Sample draws a value from the distribution.
+func (d *Distribution) Sample(r *rand.Rand) interface{} {
+	if d.Range {
+		if d.Integer {
+			if d.Max <= d.Min {
+				return int(d.Min)
+			}
+			return int(d.Min) + r.Intn(int(d.Max-d.Min)+1)
+		}
+		return d.Min + r.Float64()*(d.Max-d.Min)
+	}
+	if len(d.Values) == 0 {
+		return nil
+	}
+	return d.Values[r.Intn(len(d.Values))]
+}
+
+// JS returns the JavaScript expression that draws a value from the distribution, it uses the
+// "pick", "between" and "uniform" functions of the k6 script.
+func (d *Distribution) JS() (string, error) {
+	if d.Range {
+		if d.Integer {
+			return fmt.Sprintf("between(%v, %v)", d.Min, d.Max), nil
+		}
+		return fmt.Sprintf("uniform(%v, %v)", d.Min, d.Max), nil
+	}
+	vals := make([]string, len(d.Values))
+	for i, v := range d.Values {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		vals[i] = string(b)
+	}
+	if len(vals) == 1 {
+		return vals[0], nil
+	}
+	return "pick([" + strings.Join(vals, ", ") + "])", nil
+}
+
+// Targets returns n vegeta targets for the scenario, the targets use the given base URL and
+// draw their values from the distributions of the scenario.
+func (s *Scenario) Targets(baseURL string, n int, r *rand.Rand) ([]*Target, error) {
+	targets := make([]*Target, n)
+	for i := range targets {
+		t := &Target{Method: s.Method, Header: make(map[string][]string)}
+		path := design.WildcardRegex.ReplaceAllStringFunc(s.Path, func(w string) string {
+			p, ok := s.Params[w[2:]]
+			if !ok {
+				return w
+			}
+			v := format(p.Values.Sample(r))
+			if w[1] == '*' {
+				return "/" + (&url.URL{Path: v}).EscapedPath()
+			}
+			return "/" + url.PathEscape(v)
+		})
+		query := url.Values{}
+		for _, p := range s.Query {
+			if !p.Required && r.Intn(2) == 0 {
+				continue
+			}
+			v := p.Values.Sample(r)
+			if vals := reflect.ValueOf(v); vals.Kind() == reflect.Slice {
+				for i := 0; i < vals.Len(); i++ {
+					query.Add(p.Name, format(vals.Index(i).Interface()))
+				}
+				continue
+			}
+			query.Add(p.Name, format(v))
+		}
+		t.URL = baseURL + path
+		if len(query) > 0 {
+			t.URL += "?" + query.Encode()
+		}
+		for _, p := range s.Headers {
+			if p.Required || r.Intn(2) == 1 {
+				t.Header[p.Name] = []string{format(p.Values.Sample(r))}
+			}
+		}
+		// Multipart payloads cannot be expressed as vegeta targets
+		if s.Action.Payload != nil && !s.Multipart {
+			var body interface{}
+			if s.Body != nil {
+				body = s.Body.Sample(r)
+			} else {
+				fields := make(map[string]interface{})
+				for _, p := range s.Payload {
+					if p.Required || r.Intn(2) == 1 {
+						fields[p.Name] = p.Values.Sample(r)
+					}
+				}
+				body = fields
+			}
+			b, err := json.Marshal(body)
+			if err != nil {
+				return nil, fmt.Errorf("%s: payload example: %s", s.Action.Context(), err)
+			}
+			t.Body = b
+			t.Header["Content-Type"] = []string{"application/json"}
+		}
+		targets[i] = t
+	}
+	return targets, nil
+}
+
+// SecurityEnv returns the prefix of the environment variables that hold the credentials of the
+// security scheme of the scenario action, the empty string if the action is not secured.
+func (s *Scenario) SecurityEnv() string {
+	if s.Action.Security == nil || s.Action.Security.Scheme == nil {
+		return ""
+	}
+	return strings.ToUpper(codegen.SnakeCase(s.Action.Security.Scheme.SchemeName))
+}
+
+// format returns the string representation of the given value, strings are returned as is and
+// other values as JSON.
+func format(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,130 @@
+package genload_test
+
+import (
+	"encoding/json"
+	"math/rand"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genload "github.com/kyokomi/goa-v1/goagen/gen_load"
+)
+
+var _ = Describe("Scenarios", func() {
+	var scenarios []*genload.Scenario
+	var scenariosErr error
+
+	BeforeEach(func() {
+		scenarios = nil
+		scenariosErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		scenarios, scenariosErr = genload.Scenarios(Design)
+	})
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			jwt := apidsl.JWTSecurity("jwt", func() {
+				apidsl.Header("Authorization")
+			})
+			apidsl.API("cellar", func() {
+				apidsl.Host("cellar.example.com")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Security(jwt)
+					apidsl.Payload(func() {
+						apidsl.Member("name", String, func() {
+							apidsl.Example("Number 8")
+						})
+						apidsl.Member("color", String, func() {
+							apidsl.Enum("red", "white")
+						})
+						apidsl.Required("name", "color")
+					})
+					apidsl.Response(Created)
+					apidsl.Response(BadRequest)
+				})
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", Integer, func() {
+							apidsl.Minimum(1)
+							apidsl.Maximum(3)
+						})
+						apidsl.Param("sweet", Boolean)
+					})
+					apidsl.Response(OK)
+				})
+				apidsl.Action("watch", func() {
+					apidsl.Routing(apidsl.GET("/watch"))
+					apidsl.Scheme("ws")
+					apidsl.Response(SwitchingProtocols)
+				})
+			})
+		})
+
+		It("generates one scenario per HTTP action route", func() {
+			Ω(scenariosErr).ShouldNot(HaveOccurred())
+			Ω(scenarios).Should(HaveLen(2))
+
+			create := scenarios[0]
+			Ω(create.Name).Should(Equal("create_bottle"))
+			Ω(create.Func).Should(Equal("createBottle"))
+			Ω(create.Statuses).Should(Equal([]int{201}))
+			Ω(create.SecurityEnv()).Should(Equal("JWT"))
+			Ω(create.Payload).Should(HaveLen(2))
+			Ω(create.Payload[0].Values.Values).Should(Equal([]interface{}{"red", "white"}))
+			Ω(create.Payload[1].Values.Values).Should(Equal([]interface{}{"Number 8"}))
+
+			show := scenarios[1]
+			Ω(show.Path).Should(Equal("/bottles/:id"))
+			Ω(show.Params).Should(HaveKey("id"))
+			Ω(show.Params["id"].Values.Range).Should(BeTrue())
+			Ω(show.Query).Should(HaveLen(1))
+			Ω(show.Query[0].Name).Should(Equal("sweet"))
+			Ω(show.Query[0].Required).Should(BeFalse())
+		})
+
+		It("renders the k6 script", func() {
+			b, err := genload.WriteK6("cellar", "http://cellar.example.com", scenarios)
+			Ω(err).ShouldNot(HaveOccurred())
+			script := string(b)
+			Ω(script).Should(ContainSubstring(`  show_bottle: { executor: "constant-vus", vus: vus, duration: duration, exec: "showBottle" },`))
+			Ω(script).Should(ContainSubstring(`  headers["Authorization"] = "Bearer " + __ENV.JWT_TOKEN;`))
+			Ω(script).Should(ContainSubstring(`  fields["color"] = pick(["red", "white"]);`))
+			Ω(script).Should(ContainSubstring(`  if (maybe()) {
+    addQuery(query, "sweet", pick([true, false]));
+  }`))
+			Ω(script).Should(ContainSubstring(`url("/bottles/" + encodeURIComponent(String(between(1, 3))), query)`))
+			Ω(script).Should(ContainSubstring(`check(res, { "status is 201": (r) => r.status === 201 });`))
+		})
+
+		It("renders the vegeta targets", func() {
+			b, err := genload.WriteVegeta("http://cellar.example.com", scenarios, 5, rand.New(rand.NewSource(1)))
+			Ω(err).ShouldNot(HaveOccurred())
+			lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+			Ω(lines).Should(HaveLen(10))
+			var target genload.Target
+			Ω(json.Unmarshal([]byte(lines[0]), &target)).ShouldNot(HaveOccurred())
+			Ω(target.Method).Should(Equal("POST"))
+			Ω(target.URL).Should(Equal("http://cellar.example.com/bottles"))
+			Ω(string(target.Body)).Should(ContainSubstring(`"name":"Number 8"`))
+			for _, l := range lines[5:] {
+				Ω(json.Unmarshal([]byte(l), &target)).ShouldNot(HaveOccurred())
+				Ω(target.URL).Should(MatchRegexp(`^http://cellar.example.com/bottles/[1-3](\?sweet=(true|false))?$`))
+			}
+		})
+	})
+})
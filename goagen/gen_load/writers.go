@@ -0,0 +1,223 @@
+package genload
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/version"
+)
+
+// scriptTmpl is the template used to render the k6 script.
+var scriptTmpl = template.Must(template.New("k6").Funcs(template.FuncMap{
+	"js":   js,
+	"dist": dist,
+}).Parse(scriptT))
+
+// WriteK6 renders the k6 script that runs the given scenarios, title is written in the header
+// comment and baseURL is the default base URL of the requests.
+func WriteK6(title, baseURL string, scenarios []*Scenario) ([]byte, error) {
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"Title":       title,
+		"ToolVersion": version.String(),
+		"BaseURL":     baseURL,
+		"Scenarios":   scenarios,
+	}
+	if err := scriptTmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteVegeta renders n vegeta targets per scenario in the JSON format, one target per line.
+func WriteVegeta(baseURL string, scenarios []*Scenario, n int, r *rand.Rand) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	for _, s := range scenarios {
+		targets, err := s.Targets(baseURL, n, r)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range targets {
+			if err := enc.Encode(t); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// PathJS returns the JavaScript expression that computes the request path of the scenario.
+func (s *Scenario) PathJS() (string, error) {
+	var elems []string
+	last := 0
+	for _, m := range design.WildcardRegex.FindAllStringSubmatchIndex(s.Path, -1) {
+		p, ok := s.Params[s.Path[m[2]:m[3]]]
+		if !ok {
+			continue
+		}
+		v, err := p.Values.JS()
+		if err != nil {
+			return "", err
+		}
+		encode := "encodeURIComponent"
+		if s.Path[m[0]+1] == '*' {
+			encode = "encodeURI"
+		}
+		elems = append(elems, js(s.Path[last:m[0]+1]), fmt.Sprintf("%s(String(%s))", encode, v))
+		last = m[1]
+	}
+	if last < len(s.Path) || len(elems) == 0 {
+		elems = append(elems, js(s.Path[last:]))
+	}
+	return strings.Join(elems, " + "), nil
+}
+
+// AuthJS returns the JavaScript statements that set the credentials of the security scheme of
+// the scenario action, the credentials are read from the environment.
+func (s *Scenario) AuthJS() string {
+	env := s.SecurityEnv()
+	if env == "" {
+		return ""
+	}
+	scheme := s.Action.Security.Scheme
+	switch scheme.Kind {
+	case design.BasicAuthSecurityKind:
+		return fmt.Sprintf("  headers[\"Authorization\"] = \"Basic \" + encoding.b64encode(__ENV.%s_USERNAME + \":\" + __ENV.%s_PASSWORD);\n", env, env)
+	case design.APIKeySecurityKind:
+		if scheme.In == "query" {
+			return fmt.Sprintf("  addQuery(query, %s, __ENV.%s_KEY);\n", js(scheme.Name), env)
+		}
+		return fmt.Sprintf("  headers[%s] = __ENV.%s_KEY;\n", js(scheme.Name), env)
+	case design.JWTSecurityKind, design.OAuth2SecurityKind:
+		return fmt.Sprintf("  headers[\"Authorization\"] = \"Bearer \" + __ENV.%s_TOKEN;\n", env)
+	}
+	return ""
+}
+
+// SuccessJS returns the name and the JavaScript function of the k6 check that verifies the
+// response status.
+func (s *Scenario) SuccessJS() string {
+	if len(s.Statuses) == 0 {
+		return `"status is not an error": (r) => r.status < 400`
+	}
+	codes := make([]string, len(s.Statuses))
+	for i, st := range s.Statuses {
+		codes[i] = strconv.Itoa(st)
+	}
+	if len(codes) == 1 {
+		return fmt.Sprintf(`"status is %s": (r) => r.status === %s`, codes[0], codes[0])
+	}
+	return fmt.Sprintf(`"status is %s": (r) => [%s].indexOf(r.status) >= 0`,
+		strings.Join(codes, " or "), strings.Join(codes, ", "))
+}
+
+// js returns the JavaScript string literal of s.
+func js(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// dist returns the JavaScript expression that draws a value from the given distribution.
+func dist(d *Distribution) (string, error) {
+	return d.JS()
+}
+
+const scriptT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .Title }}
+//
+// Run all the scenarios with:
+//
+//   k6 run -e BASE_URL={{ .BaseURL }} k6.js
+//
+// Set the VUS and DURATION variables to change the number of virtual users and the duration of
+// the scenarios and SCENARIOS to a comma separated list of scenario names to restrict the run.
+import http from "k6/http";
+import encoding from "k6/encoding";
+import { check } from "k6";
+
+const baseURL = __ENV.BASE_URL || {{ js .BaseURL }};
+const vus = parseInt(__ENV.VUS || "1", 10);
+const duration = __ENV.DURATION || "30s";
+const selected = __ENV.SCENARIOS ? __ENV.SCENARIOS.split(",") : null;
+
+// pick returns one of the given values at random.
+function pick(values) {
+  return values[Math.floor(Math.random() * values.length)];
+}
+
+// between returns a random integer between min and max included.
+function between(min, max) {
+  return Math.floor(Math.random() * (max - min + 1)) + min;
+}
+
+// uniform returns a random number between min and max.
+function uniform(min, max) {
+  return Math.random() * (max - min) + min;
+}
+
+// maybe returns true half of the time, it decides whether optional values are set.
+function maybe() {
+  return Math.random() < 0.5;
+}
+
+// addQuery adds the query string parameter with the given name and value(s).
+function addQuery(query, name, value) {
+  [].concat(value).forEach(function (v) {
+    query.push(encodeURIComponent(name) + "=" + encodeURIComponent(String(v)));
+  });
+}
+
+// url returns the URL of the request with the given path and query string parameters.
+function url(path, query) {
+  return baseURL + path + (query.length > 0 ? "?" + query.join("&") : "");
+}
+
+const scenarios = {
+{{ range .Scenarios }}  {{ .Name }}: { executor: "constant-vus", vus: vus, duration: duration, exec: {{ js .Func }} },
+{{ end }}};
+
+export const options = {
+  scenarios: Object.keys(scenarios)
+    .filter(function (name) { return !selected || selected.indexOf(name) >= 0; })
+    .reduce(function (res, name) { res[name] = scenarios[name]; return res; }, {}),
+};
+{{ range .Scenarios }}
+// {{ .Func }} makes a request to the {{ .Action.Name }} action of the {{ .Action.Parent.Name }} resource: {{ .Method }} {{ .Path }}
+export function {{ .Func }}() {
+  const query = [];
+{{ range .Query }}{{ if .Required }}  addQuery(query, {{ js .Name }}, {{ dist .Values }});
+{{ else }}  if (maybe()) {
+    addQuery(query, {{ js .Name }}, {{ dist .Values }});
+  }
+{{ end }}{{ end }}  const headers = {};
+{{ range .Headers }}{{ if .Required }}  headers[{{ js .Name }}] = String({{ dist .Values }});
+{{ else }}  if (maybe()) {
+    headers[{{ js .Name }}] = String({{ dist .Values }});
+  }
+{{ end }}{{ end }}{{ .AuthJS }}{{ if .Body }}  headers["Content-Type"] = "application/json";
+  const body = JSON.stringify({{ dist .Body }});
+{{ else if .Action.Payload }}  const fields = {};
+{{ range .Payload }}{{ if .Required }}  fields[{{ js .Name }}] = {{ if .File }}http.file("content", {{ js .Name }}){{ else }}{{ dist .Values }}{{ end }};
+{{ else }}  if (maybe()) {
+    fields[{{ js .Name }}] = {{ if .File }}http.file("content", {{ js .Name }}){{ else }}{{ dist .Values }}{{ end }};
+  }
+{{ end }}{{ end }}{{ if .Multipart }}  const body = fields;
+{{ else }}  headers["Content-Type"] = "application/json";
+  const body = JSON.stringify(fields);
+{{ end }}{{ else }}  const body = null;
+{{ end }}  const res = http.request({{ js .Method }}, url({{ .PathJS }}, query), body, {
+    headers: headers,
+    tags: { name: {{ js .Name }} },
+  });
+  check(res, { {{ .SuccessJS }} });
+}
+{{ end }}`
@@ -5,5 +5,8 @@ bootstrap new applications.
 The generator creates a main.go file and one file per resource listed in the API metadata.
 If a file already exists it skips its creation unless the flag --force is provided on the command
 line in which case it overrides the content of existing files.
+With --regen the existing controller files are updated instead: the methods of the actions that
+the package does not implement yet are appended to them and the rest of their content is left
+untouched so that the scaffolding can be re-run as the design evolves.
 */
 package genmain
@@ -1,20 +1,23 @@
 package genmain
 
 import (
-	"bufio"
+	"bytes"
 	"flag"
 	"fmt"
 	"go/ast"
+	"go/format"
 	"go/parser"
 	"go/token"
+	"io/ioutil"
 	"net"
 	"os"
 	"path"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"text/template"
 
+	"golang.org/x/tools/go/ast/astutil"
+
 	"github.com/kyokomi/goa-v1/design"
 	"github.com/kyokomi/goa-v1/goagen/codegen"
 	"github.com/kyokomi/goa-v1/goagen/utils"
@@ -33,20 +36,21 @@ func NewGenerator(options ...Option) *Generator {
 
 // Generator is the application code generator.
 type Generator struct {
-	API       *design.APIDefinition // The API definition
-	OutDir    string                // Path to output directory
-	DesignPkg string                // Path to design package, only used to mark generated files.
-	Target    string                // Name of generated "app" package
-	Force     bool                  // Whether to override existing files
-	Regen     bool                  // Whether to regenerate scaffolding in place, maintaining controller implementation
-	genfiles  []string              // Generated files
+	API          *design.APIDefinition // The API definition
+	OutDir       string                // Path to output directory
+	DesignPkg    string                // Path to design package, only used to mark generated files.
+	Target       string                // Name of generated "app" package
+	Force        bool                  // Whether to override existing files
+	Regen        bool                  // Whether to add new actions to existing controllers, maintaining controller implementation
+	ContextFirst bool                  // Whether the actions take a context.Context and the request data instead of an action context
+	genfiles     []string              // Generated files
 }
 
 // Generate is the generator entry point called by the meta generator.
 func Generate() (files []string, err error) {
 	var (
 		outDir, toolDir, designPkg, target, ver string
-		force, notool, regen                    bool
+		force, notool, regen, ctxFirst          bool
 	)
 
 	set := flag.NewFlagSet("main", flag.PanicOnError)
@@ -58,6 +62,7 @@ func Generate() (files []string, err error) {
 	set.BoolVar(&notool, "notool", false, "")
 	set.BoolVar(&force, "force", false, "")
 	set.BoolVar(&regen, "regen", false, "")
+	set.BoolVar(&ctxFirst, "context-first", false, "")
 	set.Bool("notest", false, "")
 	set.Parse(os.Args[1:])
 
@@ -66,76 +71,27 @@ func Generate() (files []string, err error) {
 	}
 
 	target = codegen.Goify(target, false)
-	g := &Generator{OutDir: outDir, DesignPkg: designPkg, Target: target, Force: force, Regen: regen, API: design.Design}
+	g := &Generator{OutDir: outDir, DesignPkg: designPkg, Target: target, Force: force, Regen: regen, ContextFirst: ctxFirst, API: design.Design}
 
 	return g.Generate()
 }
 
-func extractControllerBody(filename string) (map[string]string, []*ast.ImportSpec, error) {
-	// First check if a file is there. If not, return empty results to let generation proceed.
-	if _, e := os.Stat(filename); e != nil {
-		return map[string]string{}, []*ast.ImportSpec{}, nil
-	}
-	fset := token.NewFileSet()
-	pfile, err := parser.ParseFile(fset, filename, nil, parser.ImportsOnly)
-	if err != nil {
-		return nil, nil, err
-	}
-	f, err := os.Open(filename)
-	if err != nil {
-		return nil, nil, err
-	}
-	defer f.Close()
-	var (
-		inBlock bool
-		block   []string
-	)
-	actionImpls := map[string]string{}
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		match := linePattern.FindStringSubmatch(line)
-		if len(match) == 3 {
-			switch match[2] {
-			case "start":
-				inBlock = true
-			case "end":
-				inBlock = false
-				actionImpls[match[1]] = strings.Join(block, "\n")
-				block = []string{}
-			}
-			continue
-		}
-		if inBlock {
-			block = append(block, line)
-		}
-	}
-	if err := scanner.Err(); err != nil {
-		return nil, nil, err
-	}
-	return actionImpls, pfile.Imports, nil
-}
-
 // GenerateController generates the controller corresponding to the given
-// resource and returns the generated filename.
-func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *design.ResourceDefinition) (filename string, err error) {
+// resource and returns the generated filename. If regen is true and the controller file already
+// exists the methods of the actions that the package does not implement yet are appended to it,
+// see MergeController. api is the API the resource belongs to. If ctxFirst is true the action
+// methods take a context.Context and the action request data, see the app generator ContextFirst
+// option.
+func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *design.ResourceDefinition, api *design.APIDefinition, ctxFirst bool) (filename string, err error) {
 	filename = filepath.Join(outDir, codegen.SnakeCase(name)+".go")
-	var (
-		actionImpls      map[string]string
-		extractedImports []*ast.ImportSpec
-	)
-	if regen {
-		actionImpls, extractedImports, err = extractControllerBody(filename)
-		if err != nil {
-			return "", err
-		}
-		os.Remove(filename)
-	}
 	if force {
 		os.Remove(filename)
 	}
 	if _, e := os.Stat(filename); e == nil {
-		return "", nil
+		if !regen {
+			return "", nil
+		}
+		return MergeController(appPkg, outDir, pkg, filename, r, api, ctxFirst)
 	}
 	if err = os.MkdirAll(outDir, 0755); err != nil {
 		return "", err
@@ -153,50 +109,30 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 		}
 	}()
 
-	elems := strings.Split(appPkg, "/")
-	pkgName := elems[len(elems)-1]
-	var imp string
-	if _, err := codegen.PackageSourcePath(appPkg); err == nil {
-		imp = appPkg
-	} else {
-		imp, err = codegen.PackagePath(outDir)
-		if err != nil {
-			return "", err
-		}
-		imp = path.Join(filepath.ToSlash(imp), appPkg)
+	pkgName, imp, err := appImport(appPkg, outDir)
+	if err != nil {
+		return "", err
 	}
-
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
 		codegen.SimpleImport("io"),
 		codegen.SimpleImport("github.com/kyokomi/goa-v1"),
 		codegen.SimpleImport(imp),
 		codegen.SimpleImport("golang.org/x/net/websocket"),
 	}
-	for _, imp := range extractedImports {
-		// This may introduce duplicate imports of the defaults, but
-		// that'll get worked out by Format later.
-		var cgimp *codegen.ImportSpec
-		path := strings.Trim(imp.Path.Value, `"`)
-		if imp.Name != nil {
-			cgimp = codegen.NewImport(imp.Name.Name, path)
-		} else {
-			cgimp = codegen.SimpleImport(path)
-		}
-		imports = append(imports, cgimp)
-	}
 
-	funcs := funcMap(pkgName, actionImpls)
+	funcs := funcMap(pkgName, api, nil, ctxFirst)
 	if err = file.WriteHeader("", pkg, imports); err != nil {
 		return "", err
 	}
-	if err = file.ExecuteTemplate("controller", ctrlT, funcs, r); err != nil {
+	if err = file.ExecuteTemplate("controller", codegen.Template("main", "controller", ctrlT), funcs, r); err != nil {
 		return "", err
 	}
 	err = r.IterateActions(func(a *design.ActionDefinition) error {
 		if a.WebSocket() {
-			return file.ExecuteTemplate("actionWS", actionWST, funcs, a)
+			return file.ExecuteTemplate("actionWS", codegen.Template("main", "action_ws", actionWST), funcs, a)
 		}
-		return file.ExecuteTemplate("action", actionT, funcs, a)
+		return file.ExecuteTemplate("action", codegen.Template("main", "action", actionT), funcs, a)
 	})
 	if err != nil {
 		return "", err
@@ -204,6 +140,161 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 	return
 }
 
+// MergeController appends the methods of the actions of the given resource that the package in
+// outDir does not implement yet to the existing controller file. The rest of the file, including
+// the bodies of the methods already implemented, is left untouched. MergeController also appends
+// the controller type if the package does not declare it. It returns the file name if the file was
+// modified, an empty string otherwise.
+func MergeController(appPkg, outDir, pkg, filename string, r *design.ResourceDefinition, api *design.APIDefinition, ctxFirst bool) (string, error) {
+	ctrlName := codegen.Goify(r.Name, true) + "Controller"
+	methods, hasType, err := controllerDecls(outDir, pkg, ctrlName)
+	if err != nil {
+		return "", err
+	}
+	pkgName, imp, err := appImport(appPkg, outDir)
+	if err != nil {
+		return "", err
+	}
+	funcs := funcMap(pkgName, api, nil, ctxFirst)
+	var code bytes.Buffer
+	execute := func(name, source string, data interface{}) error {
+		tmpl, err := template.New(name).Funcs(codegen.DefaultFuncMap).Funcs(funcs).Parse(source)
+		if err != nil {
+			panic(err) // bug
+		}
+		code.WriteString("\n")
+		return tmpl.Execute(&code, data)
+	}
+	if !hasType {
+		if err := execute("controller", codegen.Template("main", "controller", ctrlT), r); err != nil {
+			return "", err
+		}
+	}
+	err = r.IterateActions(func(a *design.ActionDefinition) error {
+		if methods[codegen.Goify(a.Name, true)] {
+			return nil
+		}
+		if a.WebSocket() {
+			return execute("actionWS", codegen.Template("main", "action_ws", actionWST), a)
+		}
+		return execute("action", codegen.Template("main", "action", actionT), a)
+	})
+	if err != nil {
+		return "", err
+	}
+	if code.Len() == 0 {
+		return "", nil
+	}
+
+	existing, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, append(existing, code.Bytes()...), parser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("io"),
+		codegen.NewImport("goa", "github.com/kyokomi/goa-v1"),
+		codegen.SimpleImport(imp),
+		codegen.SimpleImport("golang.org/x/net/websocket"),
+	}
+	for _, spec := range imports {
+		if hasImport(file, spec.Path) {
+			continue
+		}
+		astutil.AddNamedImport(fset, file, spec.Name, spec.Path)
+		if !astutil.UsesImport(file, spec.Path) {
+			astutil.DeleteNamedImport(fset, file, spec.Name, spec.Path)
+		}
+	}
+	var merged bytes.Buffer
+	if err := format.Node(&merged, fset, file); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(filename, merged.Bytes(), 0644); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// controllerDecls returns the names of the methods of the controller type with the given name
+// declared by the package pkg in dir and whether the package declares the type.
+func controllerDecls(dir, pkg, ctrlName string) (map[string]bool, bool, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, false, err
+	}
+	methods := make(map[string]bool)
+	var hasType bool
+	fset := token.NewFileSet()
+	for _, p := range paths {
+		if strings.HasSuffix(p, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, p, nil, 0)
+		if err != nil {
+			return nil, false, err
+		}
+		if file.Name.Name != pkg {
+			continue
+		}
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv != nil && len(d.Recv.List) == 1 && receiverName(d.Recv.List[0].Type) == ctrlName {
+					methods[d.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok && ts.Name.Name == ctrlName {
+						hasType = true
+					}
+				}
+			}
+		}
+	}
+	return methods, hasType, nil
+}
+
+// receiverName returns the name of the type of a method receiver.
+func receiverName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if id, ok := expr.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+// hasImport returns true if the file imports the package with the given path.
+func hasImport(file *ast.File, path string) bool {
+	for _, imp := range file.Imports {
+		if strings.Trim(imp.Path.Value, `"`) == path {
+			return true
+		}
+	}
+	return false
+}
+
+// appImport returns the name and the import path of the generated app package.
+func appImport(appPkg, outDir string) (string, string, error) {
+	elems := strings.Split(appPkg, "/")
+	pkgName := elems[len(elems)-1]
+	if _, err := codegen.PackageSourcePath(appPkg); err == nil {
+		return pkgName, appPkg, nil
+	}
+	imp, err := codegen.PackagePath(outDir)
+	if err != nil {
+		return "", "", err
+	}
+	return pkgName, path.Join(filepath.ToSlash(imp), appPkg), nil
+}
+
 // Generate produces the skeleton main.
 func (g *Generator) Generate() (_ []string, err error) {
 	if g.API == nil {
@@ -234,13 +325,13 @@ func (g *Generator) Generate() (_ []string, err error) {
 		if err = os.MkdirAll(g.OutDir, 0755); err != nil {
 			return nil, err
 		}
-		if err = g.createMainFile(mainFile, funcMap(g.Target, nil)); err != nil {
+		if err = g.createMainFile(mainFile, funcMap(g.Target, g.API, nil, g.ContextFirst)); err != nil {
 			return nil, err
 		}
 	}
 
 	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
-		filename, err := GenerateController(g.Force, g.Regen, g.Target, g.OutDir, "main", r.Name, r)
+		filename, err := GenerateController(g.Force, g.Regen, g.Target, g.OutDir, "main", r.Name, r, g.API, g.ContextFirst)
 		if err != nil {
 			return err
 		}
@@ -302,7 +393,7 @@ func (g *Generator) createMainFile(mainFile string, funcs template.FuncMap) (err
 		"Name": g.API.Name,
 		"API":  g.API,
 	}
-	err = file.ExecuteTemplate("main", mainT, funcs, data)
+	err = file.ExecuteTemplate("main", codegen.Template("main", "main", mainT), funcs, data)
 	return
 }
 
@@ -318,7 +409,7 @@ func tempvar() string {
 	return fmt.Sprintf("c%d", tempCount)
 }
 
-func okResp(a *design.ActionDefinition, appPkg string) map[string]interface{} {
+func okResp(api *design.APIDefinition, a *design.ActionDefinition, appPkg string) map[string]interface{} {
 	var ok *design.ResponseDefinition
 	for _, resp := range a.Responses {
 		if resp.Status == 200 {
@@ -331,7 +422,7 @@ func okResp(a *design.ActionDefinition, appPkg string) map[string]interface{} {
 	}
 	var mt *design.MediaTypeDefinition
 	var ok2 bool
-	if mt, ok2 = design.Design.MediaTypes[design.CanonicalIdentifier(ok.MediaType)]; !ok2 {
+	if mt, ok2 = api.MediaTypes[design.CanonicalIdentifier(ok.MediaType)]; !ok2 {
 		return nil
 	}
 	view := ok.ViewName
@@ -370,11 +461,14 @@ func okResp(a *design.ActionDefinition, appPkg string) map[string]interface{} {
 }
 
 // funcMap creates the funcMap used to render the controller code.
-func funcMap(appPkg string, actionImpls map[string]string) template.FuncMap {
+func funcMap(appPkg string, api *design.APIDefinition, actionImpls map[string]string, ctxFirst bool) template.FuncMap {
 	return template.FuncMap{
-		"tempvar":   tempvar,
-		"okResp":    okResp,
-		"targetPkg": func() string { return appPkg },
+		"tempvar": tempvar,
+		"okResp": func(a *design.ActionDefinition, appPkg string) map[string]interface{} {
+			return okResp(api, a, appPkg)
+		},
+		"targetPkg":    func() string { return appPkg },
+		"contextFirst": func() bool { return ctxFirst },
 		"actionBody": func(name string) string {
 			body, ok := actionImpls[name]
 			if !ok {
@@ -389,8 +483,6 @@ func funcMap(appPkg string, actionImpls map[string]string) template.FuncMap {
 	}
 }
 
-var linePattern = regexp.MustCompile(`^\s*// ([^:]+): (\w+)_implement\s*$`)
-
 const defaultActionBody = `// Put your logic here`
 
 const ctrlT = `// {{ $ctrlName := printf "%s%s" (goify .Name true) "Controller" }}{{ $ctrlName }} implements the {{ .Name }} resource.
@@ -408,14 +500,15 @@ const actionT = `
 {{- $ctrlName := printf "%s%s" (goify .Parent.Name true) "Controller" -}}
 {{- $actionDescr := printf "%s_%s" $ctrlName (goify .Name true) -}}
 // {{ goify .Name true }} runs the {{ .Name }} action.
-func (c *{{ $ctrlName }}) {{ goify .Name true }}(ctx *{{ targetPkg }}.{{ goify .Name true }}{{ goify .Parent.Name true }}Context) error {
-	// {{ $actionDescr }}: start_implement
+{{ if contextFirst }}func (c *{{ $ctrlName }}) {{ goify .Name true }}(ctx context.Context, req *{{ targetPkg }}.{{ goify .Name true }}{{ goify .Parent.Name true }}Request) error {
+{{ else }}func (c *{{ $ctrlName }}) {{ goify .Name true }}(ctx *{{ targetPkg }}.{{ goify .Name true }}{{ goify .Parent.Name true }}Context) error {
+{{ end }}	// {{ $actionDescr }}: start_implement
 
 	{{ actionBody $actionDescr }}
 
 {{ if printResp $actionDescr }}
 {{ $ok := okResp . targetPkg }}{{ if $ok }} res := {{ $ok.TypeRef }}
-{{ end }} return {{ if $ok }}ctx.{{ $ok.Name }}(res){{ else }}nil{{ end }}
+{{ end }} return {{ if $ok }}{{ if contextFirst }}req.{{ $ok.Name }}(ctx, res){{ else }}ctx.{{ $ok.Name }}(res){{ end }}{{ else }}nil{{ end }}
 {{ end }}	// {{ $actionDescr }}: end_implement
 }
 `
@@ -424,14 +517,21 @@ const actionWST = `
 {{- $ctrlName := printf "%s%s" (goify .Parent.Name true) "Controller" -}}
 {{- $actionDescr := printf "%s_%s" $ctrlName (goify .Name true) -}}
 // {{ goify .Name true }} runs the {{ .Name }} action.
-func (c *{{ $ctrlName }}) {{ goify .Name true }}(ctx *{{ targetPkg }}.{{ goify .Name true }}{{ goify .Parent.Name true }}Context) error {
+{{ if contextFirst }}func (c *{{ $ctrlName }}) {{ goify .Name true }}(ctx context.Context, req *{{ targetPkg }}.{{ goify .Name true }}{{ goify .Parent.Name true }}Request) error {
+	c.{{ goify .Name true }}WSHandler(ctx, req).ServeHTTP(req.ResponseWriter, req.Request)
+	return nil
+}
+
+// {{ goify .Name true }}WSHandler establishes a websocket connection to run the {{ .Name }} action.
+func (c *{{ $ctrlName }}) {{ goify .Name true }}WSHandler(ctx context.Context, req *{{ targetPkg }}.{{ goify .Name true }}{{ goify .Parent.Name true }}Request) websocket.Handler {
+{{ else }}func (c *{{ $ctrlName }}) {{ goify .Name true }}(ctx *{{ targetPkg }}.{{ goify .Name true }}{{ goify .Parent.Name true }}Context) error {
 	c.{{ goify .Name true }}WSHandler(ctx).ServeHTTP(ctx.ResponseWriter, ctx.Request)
 	return nil
 }
 
 // {{ goify .Name true }}WSHandler establishes a websocket connection to run the {{ .Name }} action.
 func (c *{{ $ctrlName }}) {{ goify .Name true }}WSHandler(ctx *{{ targetPkg }}.{{ goify .Name true }}{{ goify .Parent.Name true }}Context) websocket.Handler {
-	return func(ws *websocket.Conn) {
+{{ end }}	return func(ws *websocket.Conn) {
 		// {{ $actionDescr }}: start_implement
 
 		{{ actionBody $actionDescr }}
@@ -453,7 +553,10 @@ func main() {
 	service.Use(middleware.LogRequest(true))
 	service.Use(middleware.ErrorHandler(service, true))
 	service.Use(middleware.Recover())
-{{ $api := .API }}
+{{ if .API.ProblemDetails }}
+	// Render the error responses as problem details
+	{{ targetPkg }}.UseProblemDetails(service)
+{{ end }}{{ $api := .API }}
 {{ range $name, $res := $api.Resources }}{{ $name := goify $res.Name true }} // Mount "{{$res.Name}}" controller
 	{{ $tmp := tempvar }}{{ $tmp }} := New{{ $name }}Controller(service)
 	{{ targetPkg }}.Mount{{ $name }}Controller(service, {{ $tmp }})
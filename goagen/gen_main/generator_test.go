@@ -94,6 +94,33 @@ var _ = Describe("Generate", func() {
 			Ω(content).Should(MatchRegexp(`// FirstController_Alpha: start_implement\s*// Put your logic here\s*return nil\s*// FirstController_Alpha: end_implement`))
 		})
 
+		Context("with problem details", func() {
+			BeforeEach(func() {
+				design.Design.ProblemDetails = &design.ProblemDetailsDefinition{}
+			})
+
+			It("configures the service to render problem details", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "main.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(MatchRegexp(`\s\w+\.UseProblemDetails\(service\)\n`))
+			})
+		})
+
+		Context("with context first actions", func() {
+			BeforeEach(func() {
+				os.Args = append(os.Args, "--context-first")
+			})
+
+			It("generates actions taking a context and the request data", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "first.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(MatchRegexp(`func \(c \*FirstController\) Alpha\(ctx context.Context, req \*\w+\.AlphaFirstRequest\) error {`))
+				Ω(string(content)).Should(MatchRegexp(`import \(\s*"context"`))
+			})
+		})
+
 		Context("regenerated with a new resource", func() {
 			BeforeEach(func() {
 				// Perform a first generation
@@ -104,11 +131,14 @@ var _ = Describe("Generate", func() {
 				Ω(err).ShouldNot(HaveOccurred())
 
 				// First add an import for fmt, to make sure it remains
-				existing = bytes.Replace(existing, []byte("import ("), []byte("import (\n\t\"fmt\")"), 1)
+				existing = bytes.Replace(existing, []byte("import ("), []byte("import (\n\t\"fmt\"\n"), 1)
 
 				// Next add some body that uses fmt
 				existing = bytes.Replace(existing, []byte("// Put your logic here"), []byte("fmt.Println(\"I did it first\")"), 1)
 
+				// Finally add code outside of the generated markers
+				existing = append(existing, []byte("\nfunc helper() string { return \"kept\" }\n")...)
+
 				err = ioutil.WriteFile(filepath.Join(outDir, "first.go"), existing, os.ModePerm)
 				Ω(err).ShouldNot(HaveOccurred())
 
@@ -164,6 +194,31 @@ var _ = Describe("Generate", func() {
 
 				// Check the body is in place
 				Ω(content).Should(MatchRegexp(`// FirstController_Alpha: start_implement\s*fmt.Println\("I did it first"\)\s*return nil\s*// FirstController_Alpha: end_implement`))
+
+				// Check the code outside of the markers is in place
+				Ω(string(content)).Should(ContainSubstring(`func helper() string { return "kept" }`))
+				Ω(strings.Count(string(content), "func (c *FirstController) Alpha(")).Should(Equal(1))
+			})
+
+			Context("with an action implemented in another file", func() {
+				BeforeEach(func() {
+					existing, err := ioutil.ReadFile(filepath.Join(outDir, "first.go"))
+					Ω(err).ShouldNot(HaveOccurred())
+					existing = bytes.Replace(existing, []byte("func (c *FirstController) Alpha("), []byte("func (c *FirstController) alpha("), 1)
+					err = ioutil.WriteFile(filepath.Join(outDir, "first.go"), existing, os.ModePerm)
+					Ω(err).ShouldNot(HaveOccurred())
+					other := "package main\n\nfunc (c *FirstController) Beta(ctx interface{}) error { return nil }\n"
+					err = ioutil.WriteFile(filepath.Join(outDir, "first_beta.go"), []byte(other), os.ModePerm)
+					Ω(err).ShouldNot(HaveOccurred())
+				})
+
+				It("only adds the methods that the package does not implement", func() {
+					Ω(genErr).Should(BeNil())
+					content, err := ioutil.ReadFile(filepath.Join(outDir, "first.go"))
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(string(content)).Should(ContainSubstring("func (c *FirstController) Alpha("))
+					Ω(string(content)).ShouldNot(ContainSubstring("func (c *FirstController) Beta("))
+				})
 			})
 		})
 
@@ -181,6 +236,7 @@ var _ = Describe("NewGenerator", func() {
 		force     bool
 		regen     bool
 		noExample bool
+		ctxFirst  bool
 	}{
 		api: &design.APIDefinition{
 			Name: "test api",
@@ -190,6 +246,7 @@ var _ = Describe("NewGenerator", func() {
 		target:    "app",
 		force:     false,
 		regen:     false,
+		ctxFirst:  true,
 	}
 
 	Context("with options all options set", func() {
@@ -202,6 +259,7 @@ var _ = Describe("NewGenerator", func() {
 				genmain.Target(args.target),
 				genmain.Force(args.force),
 				genmain.Regen(args.regen),
+				genmain.ContextFirst(args.ctxFirst),
 			)
 		})
 
@@ -213,6 +271,7 @@ var _ = Describe("NewGenerator", func() {
 			Ω(generator.Target).Should(Equal(args.target))
 			Ω(generator.Force).Should(Equal(args.force))
 			Ω(generator.Regen).Should(Equal(args.regen))
+			Ω(generator.ContextFirst).Should(Equal(args.ctxFirst))
 		})
 
 	})
@@ -46,3 +46,10 @@ func Regen(regen bool) Option {
 		g.Regen = regen
 	}
 }
+
+//ContextFirst Whether the actions take a context.Context and the request data as separate arguments
+func ContextFirst(contextFirst bool) Option {
+	return func(g *Generator) {
+		g.ContextFirst = contextFirst
+	}
+}
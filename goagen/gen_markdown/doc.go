@@ -0,0 +1,14 @@
+/*
+Package genmarkdown provides a generator for the Markdown documentation of the API. The generator
+produces a "docs" directory suitable for committing to a documentation repository or wiki that
+contains:
+
+  - a README.md index listing the resources,
+  - a page per resource describing its actions: routes, security, path, query string and header
+    parameters, payload and responses. The payload and response bodies are described with a table
+    of their fields, nested fields included, and a JSON example generated from the design.
+
+Media type responses are documented with the view used by the response. The examples are
+generated deterministically so that the documentation only changes when the design does.
+*/
+package genmarkdown
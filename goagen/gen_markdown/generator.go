@@ -0,0 +1,119 @@
+package genmarkdown
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of a Markdown Documentation Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the Markdown documentation generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Target   string                // Name of generated documentation directory, defaults to "docs"
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, target, ver string
+
+	set := flag.NewFlagSet("markdown", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&target, "pkg", "docs", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	// First check compatibility
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	// Now proceed
+	g := &Generator{OutDir: outDir, Target: target, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the Markdown documentation files.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.Target == "" {
+		g.Target = "docs"
+	}
+	d, err := New(g.API)
+	if err != nil {
+		return nil, err
+	}
+
+	docsDir := filepath.Join(g.OutDir, g.Target)
+	if err = codegen.RemoveAll(docsDir); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(docsDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, docsDir)
+
+	content, err := d.WriteIndex()
+	if err != nil {
+		return nil, err
+	}
+	indexFile := filepath.Join(docsDir, "README.md")
+	if err = codegen.WriteFile(indexFile, content, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, indexFile)
+
+	for _, r := range d.Resources {
+		content, err = d.WriteResource(r)
+		if err != nil {
+			return nil, err
+		}
+		resFile := filepath.Join(docsDir, r.File)
+		if err = codegen.WriteFile(resFile, content, 0644); err != nil {
+			return nil, err
+		}
+		g.genfiles = append(g.genfiles, resFile)
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
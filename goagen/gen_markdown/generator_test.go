@@ -0,0 +1,119 @@
+package genmarkdown_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	genmarkdown "github.com/kyokomi/goa-v1/goagen/gen_markdown"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genmarkdown.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+		target string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+		target: "wiki",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genmarkdown.NewGenerator(
+				genmarkdown.API(args.api),
+				genmarkdown.OutDir(args.outDir),
+				genmarkdown.Target(args.target),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Target).Should(Equal(args.target))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(workspace.Path, "")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {
+			apidsl.Title("Cellar API")
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Description("A wine bottle")
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Response(design.OK)
+			})
+		})
+		apidsl.Resource("winery", func() {
+			apidsl.Action("list", func() {
+				apidsl.Routing(apidsl.GET("/wineries"))
+				apidsl.Response(design.OK)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genmarkdown.NewGenerator(
+			genmarkdown.API(design.Design),
+			genmarkdown.OutDir(outDir),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("generates an index and a page per resource", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		docsDir := filepath.Join(outDir, "docs")
+		Ω(files).Should(Equal([]string{
+			docsDir,
+			filepath.Join(docsDir, "README.md"),
+			filepath.Join(docsDir, "bottle.md"),
+			filepath.Join(docsDir, "winery.md"),
+		}))
+
+		index, err := ioutil.ReadFile(files[1])
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(index)).Should(ContainSubstring("# Cellar API\n"))
+		Ω(string(index)).Should(ContainSubstring("| [bottle](bottle.md) | A wine bottle |\n"))
+		Ω(string(index)).Should(ContainSubstring("| [winery](winery.md) |  |\n"))
+
+		page, err := ioutil.ReadFile(files[2])
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(page)).Should(ContainSubstring("# bottle\n"))
+		Ω(string(page)).Should(ContainSubstring("| [show](#show) | `GET /bottles/:id` |\n"))
+		Ω(string(page)).Should(ContainSubstring("## show\n"))
+		Ω(string(page)).Should(ContainSubstring("| `id` | path | string | yes |  |\n"))
+		Ω(string(page)).Should(ContainSubstring("#### 200 OK\n"))
+	})
+})
@@ -0,0 +1,422 @@
+package genmarkdown
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+type (
+	// Docs describes the content of the generated documentation.
+	Docs struct {
+		// Name of the API.
+		Name string
+		// Title of the API.
+		Title string
+		// Description of the API.
+		Description string
+		// Version of the API.
+		Version string
+		// BaseURL is the URL of the API computed from its scheme, host and base path.
+		BaseURL string
+		// Resources lists the resources sorted by name.
+		Resources []*Resource
+	}
+
+	// Resource describes the documentation page of a resource.
+	Resource struct {
+		// Name of the resource.
+		Name string
+		// Description of the resource.
+		Description string
+		// File is the name of the Markdown file documenting the resource.
+		File string
+		// Actions lists the actions of the resource sorted by name.
+		Actions []*Action
+	}
+
+	// Action describes the documentation of an action.
+	Action struct {
+		// Name of the action.
+		Name string
+		// Description of the action.
+		Description string
+		// Routes lists the action routes in order of definition.
+		Routes []*Route
+		// Security describes the security scheme and scopes required by the action, empty if
+		// the action is not secured.
+		Security string
+		// Params lists the path parameters followed by the query string parameters, sorted
+		// by name.
+		Params []*Field
+		// Headers lists the request headers sorted by name.
+		Headers []*Field
+		// Payload describes the request body, nil if the action has no payload.
+		Payload *Body
+		// Responses lists the responses sorted by status.
+		Responses []*Response
+	}
+
+	// Route describes an action route.
+	Route struct {
+		// Method is the HTTP method of the route.
+		Method string
+		// Path is the full path of the route.
+		Path string
+	}
+
+	// Field describes a parameter, header or body field.
+	Field struct {
+		// Name of the field, the names of nested fields are prefixed with the name of their
+		// parent, e.g. "address.city" or "items[].id".
+		Name string
+		// In is the location of a parameter: "path", "query" or "header".
+		In string
+		// Type is the name of the field type.
+		Type string
+		// Required is true if the field must be present.
+		Required bool
+		// Description of the field.
+		Description string
+		// Constraints describes the default value and validations of the field.
+		Constraints string
+	}
+
+	// Body describes a request or response body.
+	Body struct {
+		// Type is the name of the body type.
+		Type string
+		// Fields lists the fields of object bodies sorted by name, with nested fields listed
+		// after their parent.
+		Fields []*Field
+		// Example is the JSON representation of an example of the body, empty if examples
+		// are disabled in the design.
+		Example string
+	}
+
+	// Response describes a response of an action.
+	Response struct {
+		// Name of the response.
+		Name string
+		// Status is the HTTP status code of the response.
+		Status int
+		// ContentType is the value of the Content-Type header, empty if the response has no
+		// body.
+		ContentType string
+		// Description of the response, empty if it is the default description, i.e. the
+		// status text.
+		Description string
+		// Headers lists the response headers sorted by name.
+		Headers []*Field
+		// Body describes the response body rendered with the default view, nil if the
+		// response has no body.
+		Body *Body
+	}
+)
+
+// New builds the description of the documentation of the API. The examples are generated with
+// the random generator of the API so that the documentation does not change unless the design
+// does.
+func New(api *design.APIDefinition) (*Docs, error) {
+	d := &Docs{
+		Name:        api.Name,
+		Title:       api.Title,
+		Description: api.Description,
+		Version:     api.Version,
+		BaseURL:     baseURL(api),
+	}
+	rand := api.RandomGenerator()
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		r := &Resource{
+			Name:        res.Name,
+			Description: res.Description,
+			File:        codegen.SnakeCase(res.Name) + ".md",
+		}
+		err := res.IterateActions(func(a *design.ActionDefinition) error {
+			act, err := action(api, a, rand)
+			if err != nil {
+				return fmt.Errorf("%s: %s", a.Context(), err)
+			}
+			r.Actions = append(r.Actions, act)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		d.Resources = append(d.Resources, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// action builds the documentation of the given action.
+func action(api *design.APIDefinition, a *design.ActionDefinition, rand *design.RandomGenerator) (*Action, error) {
+	act := &Action{Name: a.Name, Description: a.Description}
+	pathParams := make(map[string]bool)
+	for _, r := range a.Routes {
+		act.Routes = append(act.Routes, &Route{Method: r.Verb, Path: r.FullPath()})
+		for _, p := range r.Params() {
+			pathParams[p] = true
+		}
+	}
+	if a.Security != nil && a.Security.Scheme != nil {
+		act.Security = a.Security.Scheme.SchemeName
+		if len(a.Security.Scopes) > 0 {
+			act.Security += " (scopes: " + strings.Join(a.Security.Scopes, ", ") + ")"
+		}
+	}
+	params := a.AllParams()
+	if obj := params.Type.ToObject(); obj != nil {
+		var query []*Field
+		for _, n := range codegen.SortedNames(obj) {
+			f := field(n, obj[n], params.IsRequired(n))
+			if pathParams[n] {
+				f.In = "path"
+				f.Required = true
+				act.Params = append(act.Params, f)
+			} else {
+				f.In = "query"
+				query = append(query, f)
+			}
+		}
+		act.Params = append(act.Params, query...)
+	}
+	err := a.IterateHeaders(func(name string, required bool, h *design.AttributeDefinition) error {
+		f := field(name, h, required)
+		f.In = "header"
+		act.Headers = append(act.Headers, f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if a.Payload != nil {
+		act.Payload, err = body(a.Payload.AttributeDefinition, rand)
+		if err != nil {
+			return nil, err
+		}
+		act.Payload.Type = typeName(a.Payload)
+	}
+	err = a.IterateResponses(func(r *design.ResponseDefinition) error {
+		resp, err := response(api, r, rand)
+		if err != nil {
+			return fmt.Errorf("response %#v: %s", r.Name, err)
+		}
+		act.Responses = append(act.Responses, resp)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(act.Responses, func(i, j int) bool { return act.Responses[i].Status < act.Responses[j].Status })
+	return act, nil
+}
+
+// response builds the documentation of the given response, media type bodies are described
+// with the view used by the response.
+func response(api *design.APIDefinition, r *design.ResponseDefinition, rand *design.RandomGenerator) (*Response, error) {
+	resp := &Response{Name: r.Name, Status: r.Status}
+	if r.Description != http.StatusText(r.Status) {
+		resp.Description = r.Description
+	}
+	if r.Headers != nil {
+		headers := r.Headers.Type.ToObject()
+		for _, n := range codegen.SortedNames(headers) {
+			resp.Headers = append(resp.Headers, field(n, headers[n], r.Headers.IsRequired(n)))
+		}
+	}
+	if r.Status == http.StatusNoContent || r.Status == http.StatusNotModified {
+		return resp, nil
+	}
+	if r.MediaType != "" {
+		resp.ContentType = r.MediaType
+		mt := api.MediaTypeWithIdentifier(r.MediaType)
+		if mt == nil && design.CanonicalIdentifier(r.MediaType) == design.CanonicalIdentifier(design.ErrorMedia.Identifier) {
+			mt = design.ErrorMedia
+		}
+		if mt == nil {
+			return resp, nil
+		}
+		resp.ContentType = mt.Identifier
+		view := design.DefaultView
+		if r.ViewName != "" {
+			view = r.ViewName
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Body, err = body(p.AttributeDefinition, rand); err != nil {
+			return nil, err
+		}
+		resp.Body.Type = typeName(p)
+		return resp, nil
+	}
+	if r.Type != nil {
+		att := &design.AttributeDefinition{Type: r.Type}
+		b, err := body(att, rand)
+		if err != nil {
+			return nil, err
+		}
+		b.Type = typeName(r.Type)
+		resp.ContentType = "application/json"
+		resp.Body = b
+	}
+	return resp, nil
+}
+
+// body builds the description of a body of the given type and generates its example.
+func body(att *design.AttributeDefinition, rand *design.RandomGenerator) (*Body, error) {
+	b := &Body{Type: typeName(att.Type)}
+	b.Fields = fields("", att, make(map[*design.AttributeDefinition]bool))
+	if ex := att.GenerateExample(rand, nil); ex != nil {
+		js, err := json.MarshalIndent(ex, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		b.Example = string(js)
+	}
+	return b, nil
+}
+
+// fields returns the fields of the given attribute if it is an object or an array of objects.
+// prefix is prepended to the names of the fields, seen records the attributes being described to
+// stop at recursive types.
+func fields(prefix string, att *design.AttributeDefinition, seen map[*design.AttributeDefinition]bool) []*Field {
+	switch t := att.Type.(type) {
+	case *design.MediaTypeDefinition:
+		return fields(prefix, t.AttributeDefinition, seen)
+	case *design.UserTypeDefinition:
+		return fields(prefix, t.AttributeDefinition, seen)
+	}
+	if seen[att] {
+		return nil
+	}
+	seen[att] = true
+	defer delete(seen, att)
+	if att.Type.IsArray() {
+		return fields(prefix+"[]", att.Type.ToArray().ElemType, seen)
+	}
+	obj := att.Type.ToObject()
+	if obj == nil {
+		return nil
+	}
+	if prefix != "" {
+		prefix += "."
+	}
+	required := att.AllRequired()
+	var res []*Field
+	for _, n := range codegen.SortedNames(obj) {
+		f := field(prefix+n, obj[n], contains(required, n))
+		res = append(res, f)
+		res = append(res, fields(prefix+n, obj[n], seen)...)
+	}
+	return res
+}
+
+// field builds the description of the attribute with the given name.
+func field(name string, att *design.AttributeDefinition, required bool) *Field {
+	return &Field{
+		Name:        name,
+		Type:        typeName(att.Type),
+		Required:    required,
+		Description: att.Description,
+		Constraints: constraints(att),
+	}
+}
+
+// typeName returns the name of the given type as displayed in the documentation.
+func typeName(dt design.DataType) string {
+	switch t := dt.(type) {
+	case *design.MediaTypeDefinition:
+		return t.TypeName
+	case *design.UserTypeDefinition:
+		return t.TypeName
+	case *design.Array:
+		return "array of " + typeName(t.ElemType.Type)
+	case *design.Hash:
+		return "hash of " + typeName(t.KeyType.Type) + " to " + typeName(t.ElemType.Type)
+	case nil:
+		return ""
+	default:
+		return dt.Name()
+	}
+}
+
+// constraints returns the description of the default value and validations of the attribute.
+func constraints(att *design.AttributeDefinition) string {
+	var res []string
+	if att.DefaultValue != nil {
+		res = append(res, "default: "+jsonValue(att.DefaultValue))
+	}
+	v := att.Validation
+	if v == nil {
+		return strings.Join(res, ", ")
+	}
+	if len(v.Values) > 0 {
+		vals := make([]string, len(v.Values))
+		for i, val := range v.Values {
+			vals[i] = jsonValue(val)
+		}
+		res = append(res, "one of: "+strings.Join(vals, ", "))
+	}
+	if v.Format != "" {
+		res = append(res, "format: "+v.Format)
+	}
+	if v.Pattern != "" {
+		res = append(res, "pattern: `"+v.Pattern+"`")
+	}
+	if v.Minimum != nil {
+		res = append(res, "minimum: "+strconv.FormatFloat(*v.Minimum, 'f', -1, 64))
+	}
+	if v.Maximum != nil {
+		res = append(res, "maximum: "+strconv.FormatFloat(*v.Maximum, 'f', -1, 64))
+	}
+	if v.MinLength != nil {
+		res = append(res, "min length: "+strconv.Itoa(*v.MinLength))
+	}
+	if v.MaxLength != nil {
+		res = append(res, "max length: "+strconv.Itoa(*v.MaxLength))
+	}
+	return strings.Join(res, ", ")
+}
+
+// baseURL returns the URL of the API, it is empty if the design does not define the API host.
+func baseURL(api *design.APIDefinition) string {
+	if api.Host == "" {
+		return ""
+	}
+	scheme := "http"
+	if len(api.Schemes) > 0 {
+		scheme = api.Schemes[0]
+	}
+	return scheme + "://" + api.Host + api.BasePath
+}
+
+// jsonValue returns the JSON representation of the given value.
+func jsonValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// contains returns true if names contains name.
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package genmarkdown_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genmarkdown "github.com/kyokomi/goa-v1/goagen/gen_markdown"
+)
+
+var _ = Describe("New", func() {
+	var docs *genmarkdown.Docs
+	var newErr error
+
+	BeforeEach(func() {
+		docs = nil
+		newErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		docs, newErr = genmarkdown.New(Design)
+	})
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			jwt := apidsl.JWTSecurity("jwt", func() {
+				apidsl.Header("Authorization")
+				apidsl.Scope("api:read")
+			})
+			bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer, "Bottle ID", func() {
+						apidsl.Example(1)
+					})
+					apidsl.Attribute("winery", func() {
+						apidsl.Attribute("name", String, func() {
+							apidsl.Example("Dunn")
+						})
+						apidsl.Required("name")
+					})
+					apidsl.Required("id")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("winery")
+				})
+				apidsl.View("tiny", func() {
+					apidsl.Attribute("id")
+				})
+			})
+			apidsl.API("cellar", func() {
+				apidsl.Host("cellar.example.com")
+				apidsl.Scheme("https")
+				apidsl.BasePath("/api")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("update", func() {
+					apidsl.Description("Update a bottle")
+					apidsl.Routing(apidsl.PUT("/:id"))
+					apidsl.Security(jwt, func() {
+						apidsl.Scope("api:read")
+					})
+					apidsl.Params(func() {
+						apidsl.Param("id", Integer, func() {
+							apidsl.Minimum(1)
+						})
+						apidsl.Param("dry", Boolean, func() {
+							apidsl.Default(false)
+						})
+					})
+					apidsl.Headers(func() {
+						apidsl.Header("X-Request-Id", String)
+						apidsl.Required("X-Request-Id")
+					})
+					apidsl.Payload(func() {
+						apidsl.Member("tags", apidsl.ArrayOf(String), func() {
+							apidsl.MaxLength(3)
+						})
+						apidsl.Member("color", String, func() {
+							apidsl.Enum("red", "white")
+						})
+						apidsl.Required("color")
+					})
+					apidsl.Response(OK, func() {
+						apidsl.Media(bottle, "tiny")
+					})
+					apidsl.Response(NoContent)
+					apidsl.Response(Created, bottle)
+				})
+			})
+		})
+
+		It("documents the API", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(docs.Name).Should(Equal("cellar"))
+			Ω(docs.BaseURL).Should(Equal("https://cellar.example.com/api"))
+			Ω(docs.Resources).Should(HaveLen(1))
+			Ω(docs.Resources[0].File).Should(Equal("bottle.md"))
+		})
+
+		It("documents the action routes, security and parameters", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			a := docs.Resources[0].Actions[0]
+			Ω(a.Name).Should(Equal("update"))
+			Ω(a.Description).Should(Equal("Update a bottle"))
+			Ω(a.Routes).Should(Equal([]*genmarkdown.Route{{Method: "PUT", Path: "/api/bottles/:id"}}))
+			Ω(a.Security).Should(Equal("jwt (scopes: api:read)"))
+
+			Ω(a.Params).Should(HaveLen(2))
+			Ω(*a.Params[0]).Should(Equal(genmarkdown.Field{Name: "id", In: "path", Type: "integer", Required: true, Constraints: "minimum: 1"}))
+			Ω(*a.Params[1]).Should(Equal(genmarkdown.Field{Name: "dry", In: "query", Type: "boolean", Constraints: "default: false"}))
+			Ω(a.Headers).Should(HaveLen(1))
+			Ω(*a.Headers[0]).Should(Equal(genmarkdown.Field{Name: "X-Request-Id", In: "header", Type: "string", Required: true}))
+		})
+
+		It("documents the payload", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			p := docs.Resources[0].Actions[0].Payload
+			Ω(p).ShouldNot(BeNil())
+			Ω(p.Type).Should(Equal("UpdateBottlePayload"))
+			Ω(p.Fields).Should(HaveLen(2))
+			Ω(*p.Fields[0]).Should(Equal(genmarkdown.Field{Name: "color", Type: "string", Required: true, Constraints: `one of: "red", "white"`}))
+			Ω(*p.Fields[1]).Should(Equal(genmarkdown.Field{Name: "tags", Type: "array of string", Constraints: "max length: 3"}))
+			Ω(p.Example).Should(ContainSubstring(`"color": `))
+		})
+
+		It("documents the responses sorted by status with the view they use", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			rs := docs.Resources[0].Actions[0].Responses
+			Ω(rs).Should(HaveLen(3))
+			Ω(rs[0].Status).Should(Equal(200))
+			Ω(rs[0].Description).Should(BeEmpty())
+			Ω(rs[0].ContentType).Should(Equal("application/vnd.bottle+json"))
+			Ω(rs[0].Body.Type).Should(Equal("BottleTiny"))
+			Ω(rs[0].Body.Fields).Should(HaveLen(1))
+			Ω(rs[0].Body.Example).Should(MatchJSON(`{"id": 1}`))
+
+			Ω(rs[1].Status).Should(Equal(201))
+			Ω(rs[1].Body.Type).Should(Equal("Bottle"))
+			Ω(rs[1].Body.Fields).Should(HaveLen(3))
+			Ω(rs[1].Body.Fields[1].Name).Should(Equal("winery"))
+			Ω(*rs[1].Body.Fields[2]).Should(Equal(genmarkdown.Field{Name: "winery.name", Type: "string", Required: true}))
+			Ω(rs[1].Body.Example).Should(MatchJSON(`{"id": 1, "winery": {"name": "Dunn"}}`))
+
+			Ω(rs[2].Status).Should(Equal(204))
+			Ω(rs[2].Body).Should(BeNil())
+		})
+	})
+
+	Context("with a recursive type", func() {
+		BeforeEach(func() {
+			var node *UserTypeDefinition
+			node = apidsl.Type("node", func() {
+				apidsl.Attribute("name", String)
+				apidsl.Attribute("children", apidsl.ArrayOf("node"))
+			})
+			apidsl.API("tree", func() {})
+			apidsl.Resource("node", func() {
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST("/nodes"))
+					apidsl.Payload(node)
+					apidsl.Response(NoContent)
+				})
+			})
+		})
+
+		It("stops at the recursion", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			p := docs.Resources[0].Actions[0].Payload
+			Ω(p.Type).Should(Equal("node"))
+			Ω(p.Fields).Should(HaveLen(2))
+			Ω(p.Fields[0].Name).Should(Equal("children"))
+			Ω(p.Fields[0].Type).Should(Equal("array of node"))
+			Ω(p.Fields[1].Name).Should(Equal("name"))
+		})
+	})
+})
@@ -0,0 +1,150 @@
+package genmarkdown
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/version"
+)
+
+// funcMap lists the functions available to the templates.
+var funcMap = template.FuncMap{
+	"cell":       cell,
+	"anchor":     anchor,
+	"statusText": http.StatusText,
+}
+
+// WriteIndex renders the index page that lists the resources.
+func (d *Docs) WriteIndex() ([]byte, error) {
+	return render("index", indexT, map[string]interface{}{
+		"Docs":        d,
+		"ToolVersion": version.String(),
+	})
+}
+
+// WriteResource renders the page documenting the given resource.
+func (d *Docs) WriteResource(r *Resource) ([]byte, error) {
+	return render("resource", resourceT, map[string]interface{}{
+		"Docs":        d,
+		"Resource":    r,
+		"ToolVersion": version.String(),
+	})
+}
+
+// render executes the template with the given name and built-in source.
+func render(name, source string, data interface{}) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(funcMap).Parse(codegen.Template("markdown", name, source))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// cell escapes the given text so that it can be rendered in a table cell.
+func cell(s string) string {
+	s = strings.Replace(s, "|", `\|`, -1)
+	return strings.Replace(strings.TrimSpace(s), "\n", "<br>", -1)
+}
+
+// anchor returns the fragment of the link to the heading with the given text as computed by
+// GitHub and most Markdown renderers.
+func anchor(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+const (
+	// indexT renders the index page.
+	// input: map[string]interface{}{"Docs": *Docs, "ToolVersion": string}
+	indexT = `<!-- Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT. -->
+{{ $docs := .Docs }}
+# {{ if $docs.Title }}{{ $docs.Title }}{{ else }}{{ $docs.Name }}{{ end }}
+{{ if $docs.Description }}
+{{ $docs.Description }}
+{{ end }}{{ if or $docs.Version $docs.BaseURL }}
+{{ if $docs.Version }}- Version: {{ $docs.Version }}
+{{ end }}{{ if $docs.BaseURL }}- Base URL: ` + "`{{ $docs.BaseURL }}`" + `
+{{ end }}{{ end }}
+## Resources
+
+| Resource | Description |
+| -------- | ----------- |
+{{ range $docs.Resources }}| [{{ .Name }}]({{ .File }}) | {{ cell .Description }} |
+{{ end }}`
+
+	// resourceT renders the page of a resource.
+	// input: map[string]interface{}{"Docs": *Docs, "Resource": *Resource, "ToolVersion": string}
+	resourceT = `<!-- Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT. -->
+{{ $res := .Resource }}
+# {{ $res.Name }}
+{{ if $res.Description }}
+{{ $res.Description }}
+{{ end }}
+[Back to index](README.md)
+
+| Action | Routes |
+| ------ | ------ |
+{{ range $res.Actions }}| [{{ .Name }}](#{{ anchor .Name }}) | {{ range $i, $r := .Routes }}{{ if $i }}<br>{{ end }}` + "`{{ $r.Method }} {{ $r.Path }}`" + `{{ end }} |
+{{ end }}{{ range $res.Actions }}
+## {{ .Name }}
+{{ if .Description }}
+{{ .Description }}
+{{ end }}
+{{ range .Routes }}` + "```" + `
+{{ .Method }} {{ .Path }}
+` + "```" + `
+{{ end }}{{ if .Security }}
+Security: {{ .Security }}
+{{ end }}{{ if or .Params .Headers }}
+### Parameters
+
+| Name | In | Type | Required | Description |
+| ---- | -- | ---- | -------- | ----------- |
+{{ range .Params }}{{ template "param" . }}{{ end }}{{ range .Headers }}{{ template "param" . }}{{ end }}{{ end }}{{ with .Payload }}
+### Payload
+
+{{ template "body" . }}{{ end }}{{ if .Responses }}
+### Responses
+{{ range .Responses }}
+#### {{ .Status }} {{ statusText .Status }}
+{{ if .Description }}
+{{ .Description }}
+{{ end }}{{ if .ContentType }}
+Content-Type: ` + "`{{ .ContentType }}`" + `
+{{ end }}{{ if .Headers }}
+| Header | Type | Required | Description |
+| ------ | ---- | -------- | ----------- |
+{{ range .Headers }}| ` + "`{{ .Name }}`" + ` | {{ .Type }} | {{ if .Required }}yes{{ else }}no{{ end }} | {{ template "description" . }} |
+{{ end }}{{ end }}{{ with .Body }}
+{{ template "body" . }}{{ end }}{{ end }}{{ end }}{{ end }}
+{{- define "param" }}| ` + "`{{ .Name }}`" + ` | {{ .In }} | {{ .Type }} | {{ if .Required }}yes{{ else }}no{{ end }} | {{ template "description" . }} |
+{{ end }}
+{{- define "description" }}{{ cell .Description }}{{ if and .Description .Constraints }}<br>{{ end }}{{ cell .Constraints }}{{ end }}
+{{- define "body" }}Type: ` + "`{{ .Type }}`" + `
+{{ if .Fields }}
+| Field | Type | Required | Description |
+| ----- | ---- | -------- | ----------- |
+{{ range .Fields }}| ` + "`{{ .Name }}`" + ` | {{ .Type }} | {{ if .Required }}yes{{ else }}no{{ end }} | {{ template "description" . }} |
+{{ end }}{{ end }}{{ if .Example }}
+Example:
+
+` + "```json" + `
+{{ .Example }}
+` + "```" + `
+{{ end }}{{ end }}`
+)
@@ -0,0 +1,22 @@
+/*
+Package genmock provides a generator for a mock HTTP server of the API. The generated server is a
+main package that mounts the controllers of its own app package, so requests are decoded and
+validated like they are by the API implementation: invalid parameters, headers and payloads get the
+same 400 error responses. Valid requests are answered with the examples of the declared responses:
+the X-Mock-Status request header selects the response by status and the X-Mock-View header the view
+used to render media types, the server falls back to the first successful response rendered with
+its default view otherwise. The security middleware of the server lets all the requests through.
+
+The server accepts an optional JSON configuration file that injects latency and errors, globally or
+per action:
+
+	{
+		"latency": "150ms",
+		"errorRate": 0.1,
+		"errorStatus": 503,
+		"actions": {
+			"bottle#show": {"latency": "2s"}
+		}
+	}
+*/
+package genmock
@@ -0,0 +1,373 @@
+package genmock
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	genapp "github.com/kyokomi/goa-v1/goagen/gen_app"
+	"github.com/kyokomi/goa-v1/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of a Mock Server Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the mock server generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Target   string                // Name of generated mock server directory, defaults to "mock"
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, target, ver string
+
+	set := flag.NewFlagSet("mock", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&target, "pkg", "mock", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	// First check compatibility
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	// Now proceed
+	g := &Generator{OutDir: outDir, Target: target, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the mock server main package and the app package it mounts.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.Target == "" {
+		g.Target = "mock"
+	}
+	s, err := New(g.API)
+	if err != nil {
+		return nil, err
+	}
+
+	mockDir := filepath.Join(g.OutDir, g.Target)
+	if err = codegen.RemoveAll(mockDir); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(mockDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, mockDir)
+
+	// The mock server mounts the controllers of its own app package so that the requests are
+	// decoded and validated like they are by the API implementation.
+	appDir := filepath.Join(mockDir, "app")
+	appFiles, err := genapp.NewGenerator(
+		genapp.API(g.API),
+		genapp.OutDir(appDir),
+		genapp.Target("app"),
+		genapp.NoTest(true),
+	).Generate()
+	if err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, appFiles...)
+	appPkg, err := codegen.PackagePath(appDir)
+	if err != nil {
+		return nil, err
+	}
+
+	mainFile := filepath.Join(mockDir, "main.go")
+	file, err := codegen.SourceFileFor(mainFile)
+	if err != nil {
+		return nil, err
+	}
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("flag"),
+		codegen.SimpleImport("io/ioutil"),
+		codegen.SimpleImport("log"),
+		codegen.SimpleImport("math/rand"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("strconv"),
+		codegen.SimpleImport("time"),
+		codegen.SimpleImport("github.com/kyokomi/goa-v1"),
+		codegen.SimpleImport("github.com/kyokomi/goa-v1/middleware"),
+		codegen.SimpleImport(filepath.ToSlash(appPkg)),
+	}
+	title := fmt.Sprintf("%s: Mock Server", g.API.Context())
+	if err = file.WriteHeader(title, "main", imports); err != nil {
+		file.Close()
+		return nil, err
+	}
+	data := map[string]interface{}{
+		"Name":   g.API.Name,
+		"Server": s,
+	}
+	funcs := template.FuncMap{"quote": strconv.Quote}
+	if err = file.ExecuteTemplate("mock", mockT, funcs, data); err != nil {
+		file.Close()
+		return nil, err
+	}
+	file.Close()
+	if err = file.FormatCode(); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, mainFile)
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+const mockT = `// response describes a declared response.
+type response struct {
+	status      int
+	contentType string
+	view        string
+	bodies      map[string]string
+	headers     map[string]string
+}
+
+// config describes the latency and errors injected by the server. The actions field overrides
+// the settings for the actions identified by "resource#action".
+type config struct {
+	Latency     duration           ` + "`" + `json:"latency"` + "`" + `
+	ErrorRate   float64            ` + "`" + `json:"errorRate"` + "`" + `
+	ErrorStatus int                ` + "`" + `json:"errorStatus"` + "`" + `
+	Actions     map[string]*config ` + "`" + `json:"actions"` + "`" + `
+}
+
+// duration is a time.Duration read from its string representation, e.g. "150ms".
+type duration time.Duration
+
+// UnmarshalJSON reads the duration from its string representation.
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+// responses maps the actions identified by "resource#action" to their declared responses.
+var responses = map[string][]*response{
+{{ range .Server.Resources }}{{ range .Actions }}{{ if not .WebSocket }}	{{ quote .Key }}: {
+{{ range .Responses }}		{
+			status:      {{ .Status }},
+			contentType: {{ quote .ContentType }},
+			view:        {{ quote .View }},
+			bodies: map[string]string{
+{{ $r := . }}{{ range .Views }}				{{ quote . }}: {{ index $r.Bodies . | quote }},
+{{ end }}			},
+			headers: map[string]string{
+{{ range .HeaderNames }}				{{ quote . }}: {{ index $r.Headers . | quote }},
+{{ end }}			},
+		},
+{{ end }}	},
+{{ end }}{{ end }}{{ end }}}
+
+func main() {
+	var (
+		addr    = flag.String("addr", ":8080", "Listen address")
+		cfgFile = flag.String("config", "", "Path to JSON file configuring the injected latency and errors")
+		cors    = flag.Bool("cors", true, "Allow cross-origin requests from any origin")
+	)
+	flag.Parse()
+
+	cfg := &config{}
+	if *cfgFile != "" {
+		b, err := ioutil.ReadFile(*cfgFile)
+		if err != nil {
+			log.Fatalf("failed to read config: %s", err)
+		}
+		if err := json.Unmarshal(b, cfg); err != nil {
+			log.Fatalf("invalid config %s: %s", *cfgFile, err)
+		}
+	}
+
+	service := newService(cfg)
+	if *cors {
+		service.Server.Handler = allowCORS(service.Mux)
+	}
+	if err := service.ListenAndServe(*addr); err != nil {
+		service.LogError("startup", "err", err)
+	}
+}
+
+// newService creates the mock service and mounts the controllers of the API. The requests are
+// decoded and validated by the app package so that invalid requests get the same error
+// responses as with the API implementation.
+func newService(cfg *config) *goa.Service {
+	service := goa.New({{ quote .Name }})
+
+	service.Use(middleware.ErrorHandler(service, true))
+	service.Use(middleware.Recover())
+	service.Use(inject(cfg))
+{{ if .Server.ProblemDetails }}
+	// Render the error responses as problem details
+	app.UseProblemDetails(service)
+{{ end }}{{ if .Server.SecuritySchemes }}
+	// Let all the requests through the security middleware
+{{ range .Server.SecuritySchemes }}	app.Use{{ . }}Middleware(service, allow)
+{{ end }}{{ end }}
+{{ range .Server.Resources }}	app.Mount{{ .GoName }}Controller(service, &{{ .GoName }}Controller{Controller: service.NewController({{ quote .Name }})})
+{{ end }}
+	return service
+}
+{{ range .Server.Resources }}{{ $res := . }}
+// {{ .GoName }}Controller answers the {{ .Name }} actions with the examples of their responses.
+type {{ .GoName }}Controller struct {
+	*goa.Controller
+}
+{{ range .Actions }}
+// {{ .Name }} answers the {{ .Key }} action.
+func (c *{{ $res.GoName }}Controller) {{ .Name }}(ctx *app.{{ .Context }}) error {
+{{ if .WebSocket }}	return goa.NewErrorClass("not_implemented", http.StatusNotImplemented)("the mock server does not implement websocket actions")
+{{ else }}	return respond(ctx.ResponseData, ctx.Request, {{ quote .Key }})
+{{ end }}}
+{{ end }}{{ end }}
+// respond writes the example of the response of the action selected by the X-Mock-Status request
+// header rendered with the view selected by the X-Mock-View header.
+func respond(rw *goa.ResponseData, req *http.Request, action string) error {
+	resp := selectResponse(responses[action], req.Header.Get("X-Mock-Status"))
+	if resp == nil {
+		rw.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	for k, v := range resp.headers {
+		rw.Header().Set(k, v)
+	}
+	view := req.Header.Get("X-Mock-View")
+	if _, ok := resp.bodies[view]; !ok {
+		view = resp.view
+	}
+	body, ok := resp.bodies[view]
+	if !ok {
+		rw.WriteHeader(resp.status)
+		return nil
+	}
+	rw.Header().Set("Content-Type", resp.contentType)
+	rw.WriteHeader(resp.status)
+	_, err := rw.Write([]byte(body))
+	return err
+}
+
+// selectResponse returns the response with the given status, the first successful response if
+// status is empty or does not match a declared response or nil if the action declares no
+// response.
+func selectResponse(responses []*response, status string) *response {
+	if code, err := strconv.Atoi(status); err == nil {
+		for _, r := range responses {
+			if r.status == code {
+				return r
+			}
+		}
+	}
+	for _, r := range responses {
+		if r.status >= 200 && r.status < 300 {
+			return r
+		}
+	}
+	if len(responses) > 0 {
+		return responses[0]
+	}
+	return nil
+}
+
+// inject returns the middleware that injects the latency and errors configured for the actions.
+func inject(cfg *config) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			latency, errorRate, errorStatus := cfg.settings(goa.ContextController(ctx) + "#" + goa.ContextAction(ctx))
+			if latency > 0 {
+				time.Sleep(latency)
+			}
+			if errorRate > 0 && rand.Float64() < errorRate {
+				return goa.NewErrorClass("injected_error", errorStatus)("error injected by the mock server")
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
+
+// allow is the security middleware of the mock server, it lets all the requests through.
+func allow(h goa.Handler) goa.Handler {
+	return h
+}
+
+// allowCORS allows cross-origin requests from any origin.
+func allowCORS(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		if req.Method == "OPTIONS" && req.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", req.Header.Get("Access-Control-Request-Method"))
+			w.Header().Set("Access-Control-Allow-Headers", req.Header.Get("Access-Control-Request-Headers"))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// settings returns the latency, error rate and error status configured for the given action.
+func (c *config) settings(action string) (time.Duration, float64, int) {
+	latency, errorRate, errorStatus := time.Duration(c.Latency), c.ErrorRate, c.ErrorStatus
+	if a, ok := c.Actions[action]; ok {
+		if a.Latency != 0 {
+			latency = time.Duration(a.Latency)
+		}
+		if a.ErrorRate != 0 {
+			errorRate = a.ErrorRate
+		}
+		if a.ErrorStatus != 0 {
+			errorStatus = a.ErrorStatus
+		}
+	}
+	if errorStatus == 0 {
+		errorStatus = http.StatusInternalServerError
+	}
+	return latency, errorRate, errorStatus
+}
+`
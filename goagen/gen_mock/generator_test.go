@@ -0,0 +1,230 @@
+package genmock_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	genmock "github.com/kyokomi/goa-v1/goagen/gen_mock"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genmock.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+		target string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+		target: "mock_server",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genmock.NewGenerator(
+				genmock.API(args.api),
+				genmock.OutDir(args.outDir),
+				genmock.Target(args.target),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Target).Should(Equal(args.target))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(workspace.Path, "")
+		Ω(err).ShouldNot(HaveOccurred())
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {
+			apidsl.BasePath("/api")
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Response(design.OK, func() {
+					apidsl.Media(design.ErrorMedia)
+				})
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genmock.NewGenerator(
+			genmock.API(design.Design),
+			genmock.OutDir(outDir),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("generates the mock server main package and its app package", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(ContainElement(filepath.Join(outDir, "mock", "main.go")))
+		Ω(files).Should(ContainElement(filepath.Join(outDir, "mock", "app", "controllers.go")))
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "mock", "main.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring("package main"))
+		Ω(string(content)).Should(ContainSubstring(`"bottle#show": {`))
+		Ω(string(content)).Should(ContainSubstring(`contentType: "application/vnd.goa.error"`))
+		Ω(string(content)).Should(ContainSubstring(`app.MountBottleController(service, &BottleController{Controller: service.NewController("bottle")})`))
+		Ω(string(content)).Should(ContainSubstring("func (c *BottleController) Show(ctx *app.ShowBottleContext) error {"))
+	})
+})
+
+var _ = Describe("Generate a buildable mock server", func() {
+	var outDir string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		// The output directory is inside the goa module so that the generated code builds
+		// against this tree, the leading underscore excludes it from "./..." patterns.
+		outDir, err = ioutil.TempDir(".", "_build")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {})
+		apidsl.BasicAuthSecurity("basic_auth")
+		bottle := apidsl.MediaType("application/vnd.bottle", func() {
+			apidsl.Attributes(func() {
+				apidsl.Attribute("name", design.String, func() {
+					apidsl.Example("Number 8")
+				})
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("name")
+			})
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.BasePath("/bottles")
+			apidsl.Security("basic_auth")
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/:id"))
+				apidsl.Params(func() {
+					apidsl.Param("id", design.Integer, func() {
+						apidsl.Minimum(1)
+					})
+				})
+				apidsl.Response(design.OK, bottle)
+				apidsl.Response(design.NotFound)
+			})
+			apidsl.Action("update", func() {
+				apidsl.Routing(apidsl.PUT("/:id"))
+				apidsl.Params(func() {
+					apidsl.Param("id", design.Integer)
+				})
+				apidsl.Payload(func() {
+					apidsl.Member("name", design.String)
+					apidsl.Required("name")
+				})
+				apidsl.Response(design.NoContent)
+				apidsl.Response(design.BadRequest, design.ErrorMedia)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		_, genErr = genmock.NewGenerator(
+			genmock.API(design.Design),
+			genmock.OutDir(outDir),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+		delete(codegen.Reserved, "app")
+	})
+
+	It("generates a server that validates the requests like the API implementation", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(ioutil.WriteFile(filepath.Join(outDir, "mock", "main_test.go"), []byte(mockTest), 0644)).Should(Succeed())
+		cmd := exec.Command("go", "test", "./mock")
+		cmd.Dir = outDir
+		out, err := cmd.CombinedOutput()
+		Ω(err).ShouldNot(HaveOccurred(), string(out))
+	})
+})
+
+// mockTest sends valid and invalid requests to the generated mock server.
+const mockTest = `package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMock(t *testing.T) {
+	cases := []struct {
+		name, method, path, body, mockStatus string
+		cfg                                  *config
+		status                               int
+		contentType                          string
+	}{
+		{"example", "GET", "/bottles/1", "", "", &config{}, 200, "application/vnd.bottle"},
+		{"selected status", "GET", "/bottles/1", "", "404", &config{}, 404, ""},
+		{"invalid param", "GET", "/bottles/0", "", "", &config{}, 400, "application/vnd.goa.error"},
+		{"invalid param type", "GET", "/bottles/one", "", "", &config{}, 400, "application/vnd.goa.error"},
+		{"valid payload", "PUT", "/bottles/1", ` + "`" + `{"name":"Number 9"}` + "`" + `, "", &config{}, 204, ""},
+		{"missing required member", "PUT", "/bottles/1", "{}", "", &config{}, 400, "application/vnd.goa.error"},
+		{"missing payload", "PUT", "/bottles/1", "", "", &config{}, 400, "application/vnd.goa.error"},
+		{"injected error", "GET", "/bottles/1", "", "", &config{ErrorRate: 1, ErrorStatus: 503}, 503, "application/vnd.goa.error"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+			if c.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if c.mockStatus != "" {
+				req.Header.Set("X-Mock-Status", c.mockStatus)
+			}
+			rw := httptest.NewRecorder()
+			newService(c.cfg).Mux.ServeHTTP(rw, req)
+			if rw.Code != c.status {
+				t.Fatalf("got status %d, want %d: %s", rw.Code, c.status, rw.Body)
+			}
+			if ct := rw.Header().Get("Content-Type"); ct != c.contentType {
+				t.Errorf("got content type %q, want %q", ct, c.contentType)
+			}
+			if c.status == http.StatusOK && rw.Body.String() != "{\n  \"name\": \"Number 8\"\n}" {
+				t.Errorf("got body %s", rw.Body)
+			}
+		})
+	}
+}
+`
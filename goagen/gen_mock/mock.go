@@ -0,0 +1,214 @@
+package genmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+type (
+	// Server describes the controllers of the generated mock server.
+	Server struct {
+		// Resources lists the resources of the API.
+		Resources []*Resource
+		// SecuritySchemes lists the names of the security schemes whose middleware lets all
+		// the requests through.
+		SecuritySchemes []string
+		// ProblemDetails is true if the error responses are rendered as problem details.
+		ProblemDetails bool
+	}
+
+	// Resource describes the controller of a resource.
+	Resource struct {
+		// Name is the name of the resource.
+		Name string
+		// GoName is the name of the resource used in the generated app package, e.g. the
+		// Mount<GoName>Controller function mounts the controller.
+		GoName string
+		// Actions lists the actions of the resource sorted by name.
+		Actions []*Action
+	}
+
+	// Action describes a controller action and the examples of its responses.
+	Action struct {
+		// Key is the name of the action prefixed with the name of its resource, e.g.
+		// "bottle#show", it identifies the action in the configuration file.
+		Key string
+		// Name is the name of the controller method implementing the action.
+		Name string
+		// Context is the name of the action context type of the generated app package.
+		Context string
+		// WebSocket is true if the action is a websocket action, the mock server does not
+		// implement websocket actions.
+		WebSocket bool
+		// Responses lists the declared responses sorted by status.
+		Responses []*Response
+	}
+
+	// Response describes a declared response and its examples.
+	Response struct {
+		// Name of the response.
+		Name string
+		// Status is the HTTP status code of the response.
+		Status int
+		// ContentType is the value of the Content-Type header, empty if the response has no
+		// body.
+		ContentType string
+		// View is the view rendered by default.
+		View string
+		// Bodies maps the view names to the JSON example of the body rendered with the view.
+		// Responses whose body is not a media type have a single "default" entry.
+		Bodies map[string]string
+		// Headers maps the names of the response headers to their example value.
+		Headers map[string]string
+	}
+)
+
+// New builds the description of the mock server of the API. Each action is associated with
+// examples of its declared responses generated from the design, media type responses have an
+// example for each view of the media type.
+func New(api *design.APIDefinition) (*Server, error) {
+	s := &Server{ProblemDetails: api.ProblemDetails != nil}
+	for _, scheme := range api.SecuritySchemes {
+		s.SecuritySchemes = append(s.SecuritySchemes, codegen.Goify(scheme.SchemeName, true))
+	}
+	rand := api.RandomGenerator()
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		r := &Resource{Name: res.Name, GoName: codegen.Goify(res.Name, true)}
+		err := res.IterateActions(func(a *design.ActionDefinition) error {
+			action := &Action{
+				Key:       res.Name + "#" + a.Name,
+				Name:      codegen.Goify(a.Name, true),
+				Context:   codegen.Goify(a.Name, true) + codegen.Goify(res.Name, true) + "Context",
+				WebSocket: a.WebSocket(),
+			}
+			r.Actions = append(r.Actions, action)
+			if action.WebSocket {
+				return nil
+			}
+			err := a.IterateResponses(func(rd *design.ResponseDefinition) error {
+				resp, err := response(api, rd, rand)
+				if err != nil {
+					return fmt.Errorf("%s: response %#v: %s", a.Context(), rd.Name, err)
+				}
+				action.Responses = append(action.Responses, resp)
+				return nil
+			})
+			sort.Slice(action.Responses, func(i, j int) bool {
+				return action.Responses[i].Status < action.Responses[j].Status
+			})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		s.Resources = append(s.Resources, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// response builds the description of the given response and generates its examples.
+func response(api *design.APIDefinition, r *design.ResponseDefinition, rand *design.RandomGenerator) (*Response, error) {
+	resp := &Response{
+		Name:   r.Name,
+		Status: r.Status,
+		View:   design.DefaultView,
+		Bodies: make(map[string]string),
+	}
+	if r.Headers != nil {
+		resp.Headers = make(map[string]string)
+		headers := r.Headers.Type.ToObject()
+		for _, n := range codegen.SortedNames(headers) {
+			resp.Headers[n] = format(headers[n].GenerateExample(rand, nil))
+		}
+	}
+	if r.Status == http.StatusNoContent || r.Status == http.StatusNotModified {
+		return resp, nil
+	}
+	if r.MediaType != "" {
+		resp.ContentType = r.MediaType
+		mt := api.MediaTypeWithIdentifier(r.MediaType)
+		if mt == nil && design.CanonicalIdentifier(r.MediaType) == design.CanonicalIdentifier(design.ErrorMedia.Identifier) {
+			mt = design.ErrorMedia
+		}
+		if mt == nil {
+			return resp, nil
+		}
+		resp.ContentType = mt.Identifier
+		if r.ViewName != "" {
+			resp.View = r.ViewName
+		}
+		err := mt.IterateViews(func(v *design.ViewDefinition) error {
+			p, _, err := mt.Project(v.Name)
+			if err != nil {
+				return err
+			}
+			body, err := example(p.AttributeDefinition, rand)
+			if err != nil {
+				return err
+			}
+			resp.Bodies[v.Name] = body
+			return nil
+		})
+		return resp, err
+	}
+	if r.Type != nil {
+		body, err := example(&design.AttributeDefinition{Type: r.Type}, rand)
+		if err != nil {
+			return nil, err
+		}
+		resp.ContentType = "application/json"
+		resp.Bodies[design.DefaultView] = body
+	}
+	return resp, nil
+}
+
+// Views returns the names of the views that have an example sorted by name.
+func (r *Response) Views() []string {
+	views := make([]string, 0, len(r.Bodies))
+	for v := range r.Bodies {
+		views = append(views, v)
+	}
+	sort.Strings(views)
+	return views
+}
+
+// HeaderNames returns the names of the response headers sorted by name.
+func (r *Response) HeaderNames() []string {
+	names := make([]string, 0, len(r.Headers))
+	for n := range r.Headers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// example returns the JSON representation of an example value of the given attribute.
+func example(at *design.AttributeDefinition, rand *design.RandomGenerator) (string, error) {
+	b, err := json.MarshalIndent(at.GenerateExample(rand, nil), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// format returns the string representation of the given example value, strings are returned
+// as is and other values as JSON.
+func format(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,145 @@
+package genmock_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genmock "github.com/kyokomi/goa-v1/goagen/gen_mock"
+)
+
+var _ = Describe("New", func() {
+	var server *genmock.Server
+	var newErr error
+
+	BeforeEach(func() {
+		server = nil
+		newErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		server, newErr = genmock.New(Design)
+	})
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer, func() {
+						apidsl.Example(1)
+					})
+					apidsl.Attribute("name", String, func() {
+						apidsl.Example("Number 8")
+					})
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+				})
+				apidsl.View("tiny", func() {
+					apidsl.Attribute("id")
+				})
+			})
+			apidsl.API("cellar", func() {
+				apidsl.BasePath("/api")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"), apidsl.GET("/by-name/*name"))
+					apidsl.Response(NotFound)
+					apidsl.Response(OK, func() {
+						apidsl.Media(bottle, "tiny")
+						apidsl.Headers(func() {
+							apidsl.Header("X-Request-Id", String, func() {
+								apidsl.Example("abc")
+							})
+						})
+					})
+				})
+				apidsl.Action("delete", func() {
+					apidsl.Routing(apidsl.DELETE("/:id"))
+					apidsl.Response(NoContent)
+				})
+			})
+		})
+
+		It("describes a controller per resource", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(server.Resources).Should(HaveLen(1))
+			res := server.Resources[0]
+			Ω(res.Name).Should(Equal("bottle"))
+			Ω(res.GoName).Should(Equal("Bottle"))
+			var actions []string
+			for _, a := range res.Actions {
+				actions = append(actions, a.Key+" "+a.Name+" "+a.Context)
+			}
+			Ω(actions).Should(Equal([]string{
+				"bottle#delete Delete DeleteBottleContext",
+				"bottle#show Show ShowBottleContext",
+			}))
+		})
+
+		It("generates the examples of the responses", func() {
+			show := server.Resources[0].Actions[1]
+			Ω(show.Key).Should(Equal("bottle#show"))
+			Ω(show.Responses).Should(HaveLen(2))
+
+			ok := show.Responses[0]
+			Ω(ok.Status).Should(Equal(200))
+			Ω(ok.ContentType).Should(Equal("application/vnd.bottle+json"))
+			Ω(ok.View).Should(Equal("tiny"))
+			Ω(ok.Views()).Should(Equal([]string{"default", "tiny"}))
+			Ω(ok.Bodies["default"]).Should(Equal("{\n  \"id\": 1,\n  \"name\": \"Number 8\"\n}"))
+			Ω(ok.Bodies["tiny"]).Should(Equal("{\n  \"id\": 1\n}"))
+			Ω(ok.Headers).Should(Equal(map[string]string{"X-Request-Id": "abc"}))
+
+			notFound := show.Responses[1]
+			Ω(notFound.Status).Should(Equal(404))
+			Ω(notFound.Bodies).Should(BeEmpty())
+		})
+
+		It("does not generate bodies for no content responses", func() {
+			del := server.Resources[0].Actions[0]
+			Ω(del.Responses).Should(HaveLen(1))
+			Ω(del.Responses[0].Status).Should(Equal(204))
+			Ω(del.Responses[0].ContentType).Should(BeEmpty())
+			Ω(del.Responses[0].Bodies).Should(BeEmpty())
+		})
+	})
+
+	Context("with security schemes and problem details", func() {
+		BeforeEach(func() {
+			apidsl.API("cellar", func() {
+				apidsl.ProblemDetails(func() {})
+			})
+			apidsl.BasicAuthSecurity("basic_auth")
+			apidsl.Resource("bottle", func() {
+				apidsl.Security("basic_auth")
+				apidsl.Action("watch", func() {
+					apidsl.Routing(apidsl.GET("/bottles/watch"))
+					apidsl.Scheme("ws")
+					apidsl.Response(SwitchingProtocols)
+				})
+			})
+		})
+
+		It("describes the security middleware and the rendering of errors", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(server.SecuritySchemes).Should(Equal([]string{"BasicAuth"}))
+			Ω(server.ProblemDetails).Should(BeTrue())
+		})
+
+		It("does not generate examples for websocket actions", func() {
+			watch := server.Resources[0].Actions[0]
+			Ω(watch.WebSocket).Should(BeTrue())
+			Ω(watch.Responses).Should(BeEmpty())
+		})
+	})
+})
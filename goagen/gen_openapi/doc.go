@@ -0,0 +1,9 @@
+/*
+Package genopenapi provides a generator for the OpenAPI 3.0 or 3.1 specification of the API.
+The generated documents describe the API paths, operations, request bodies, responses and
+security schemes, with the types and media types listed in the components section. The
+OpenAPI 3.1 documents use JSON Schema 2020-12 schemas.
+See https://spec.openapis.org/oas/v3.0.3 and https://spec.openapis.org/oas/v3.1.0 for more
+information on the OpenAPI specification.
+*/
+package genopenapi
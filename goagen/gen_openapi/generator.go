@@ -0,0 +1,132 @@
+package genopenapi
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of an OpenAPI Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the OpenAPI specification generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Spec     string                // OpenAPI specification version, "3.0" (default) or "3.1"
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, toolDir, target, ver, spec string
+		notool, regen                      bool
+	)
+
+	set := flag.NewFlagSet("openapi", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.StringVar(&spec, "spec", "", "")
+	set.String("design", "", "")
+	set.StringVar(&toolDir, "tooldir", "tool", "")
+	set.BoolVar(&notool, "notool", false, "")
+	set.StringVar(&target, "pkg", "app", "")
+	set.BoolVar(&regen, "regen", false, "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Spec: spec, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the OpenAPI JSON and YAML documents.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	var s *OpenAPI
+	switch g.Spec {
+	case "", "3.0":
+		s, err = New(g.API)
+	case "3.1":
+		s, err = New31(g.API)
+	default:
+		err = fmt.Errorf("unsupported OpenAPI specification version %#v, must be one of 3.0 or 3.1", g.Spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	openapiDir := filepath.Join(g.OutDir, "openapi")
+	codegen.RemoveAll(openapiDir)
+	if err = os.MkdirAll(openapiDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, openapiDir)
+
+	// JSON
+	rawJSON, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	openapiFile := filepath.Join(openapiDir, "openapi.json")
+	if err := codegen.WriteFile(openapiFile, rawJSON, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, openapiFile)
+
+	// YAML
+	rawYAML, err := jsonToYAML(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+	openapiFile = filepath.Join(openapiDir, "openapi.yaml")
+	if err := codegen.WriteFile(openapiFile, rawYAML, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, openapiFile)
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+func jsonToYAML(rawJSON []byte) ([]byte, error) {
+	return codegen.JSONToYAML(rawJSON)
+}
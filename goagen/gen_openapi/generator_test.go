@@ -0,0 +1,42 @@
+package genopenapi_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	genopenapi "github.com/kyokomi/goa-v1/goagen/gen_openapi"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genopenapi.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+		spec   string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+		spec:   "3.1",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genopenapi.NewGenerator(
+				genopenapi.API(args.api),
+				genopenapi.OutDir(args.outDir),
+				genopenapi.Spec(args.spec),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Spec).Should(Equal(args.spec))
+		})
+	})
+})
@@ -0,0 +1,862 @@
+package genopenapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genschema "github.com/kyokomi/goa-v1/goagen/gen_schema"
+)
+
+type (
+	// OpenAPI represents an instance of an OpenAPI 3.0 or 3.1 document.
+	// See https://spec.openapis.org/oas/v3.0.3 and https://spec.openapis.org/oas/v3.1.0
+	OpenAPI struct {
+		OpenAPI      string               `json:"openapi"`
+		Info         *Info                `json:"info"`
+		Servers      []*Server            `json:"servers,omitempty"`
+		Paths        map[string]*PathItem `json:"paths"`
+		Components   *Components          `json:"components,omitempty"`
+		Tags         []*Tag               `json:"tags,omitempty"`
+		ExternalDocs *ExternalDocs        `json:"externalDocs,omitempty"`
+	}
+
+	// Info provides metadata about the API.
+	Info struct {
+		Title          string                    `json:"title"`
+		Description    string                    `json:"description,omitempty"`
+		TermsOfService string                    `json:"termsOfService,omitempty"`
+		Contact        *design.ContactDefinition `json:"contact,omitempty"`
+		License        *design.LicenseDefinition `json:"license,omitempty"`
+		Version        string                    `json:"version"`
+	}
+
+	// Server represents a server hosting the API.
+	Server struct {
+		// URL to the target host, the paths are relative to it.
+		URL string `json:"url"`
+		// Description of the host designated by the URL.
+		Description string `json:"description,omitempty"`
+	}
+
+	// PathItem describes the operations available on a single path.
+	PathItem struct {
+		// Get defines a GET operation on this path.
+		Get *Operation `json:"get,omitempty"`
+		// Put defines a PUT operation on this path.
+		Put *Operation `json:"put,omitempty"`
+		// Post defines a POST operation on this path.
+		Post *Operation `json:"post,omitempty"`
+		// Delete defines a DELETE operation on this path.
+		Delete *Operation `json:"delete,omitempty"`
+		// Options defines a OPTIONS operation on this path.
+		Options *Operation `json:"options,omitempty"`
+		// Head defines a HEAD operation on this path.
+		Head *Operation `json:"head,omitempty"`
+		// Patch defines a PATCH operation on this path.
+		Patch *Operation `json:"patch,omitempty"`
+	}
+
+	// Operation describes a single API operation on a path.
+	Operation struct {
+		// Tags is a list of tags for API documentation control.
+		Tags []string `json:"tags,omitempty"`
+		// Summary is a short summary of what the operation does.
+		Summary string `json:"summary,omitempty"`
+		// Description is a verbose explanation of the operation behavior.
+		Description string `json:"description,omitempty"`
+		// ExternalDocs points to additional external documentation for this operation.
+		ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+		// OperationID is a unique string used to identify the operation.
+		OperationID string `json:"operationId,omitempty"`
+		// Parameters is the list of parameters that are applicable for this operation.
+		Parameters []*Parameter `json:"parameters,omitempty"`
+		// RequestBody describes the request body of the operation if any.
+		RequestBody *RequestBody `json:"requestBody,omitempty"`
+		// Responses is the list of possible responses indexed by HTTP status code.
+		Responses map[string]*Response `json:"responses"`
+		// Deprecated declares this operation to be deprecated.
+		Deprecated bool `json:"deprecated,omitempty"`
+		// Security is a declaration of which security schemes are applied for this operation.
+		Security []map[string][]string `json:"security,omitempty"`
+	}
+
+	// Parameter describes a single operation parameter.
+	Parameter struct {
+		// Name of the parameter. Parameter names are case sensitive.
+		Name string `json:"name"`
+		// In is the location of the parameter.
+		// Possible values are "query", "header", "path" or "cookie".
+		In string `json:"in"`
+		// Description is a brief description of the parameter.
+		Description string `json:"description,omitempty"`
+		// Required determines whether this parameter is mandatory.
+		Required bool `json:"required,omitempty"`
+		// Schema defines the type used for the parameter.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+	}
+
+	// RequestBody describes a single request body.
+	RequestBody struct {
+		// Description is a brief description of the request body.
+		Description string `json:"description,omitempty"`
+		// Content lists the supported representations of the body indexed by media type.
+		Content map[string]*MediaType `json:"content"`
+		// Required determines whether the request body is mandatory.
+		Required bool `json:"required,omitempty"`
+	}
+
+	// MediaType provides the schema for a given media type.
+	MediaType struct {
+		// Schema defines the type used for the content.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+	}
+
+	// Response describes an operation response.
+	Response struct {
+		// Description of the response.
+		Description string `json:"description"`
+		// Headers is a list of headers that are sent with the response.
+		Headers map[string]*Header `json:"headers,omitempty"`
+		// Content lists the possible representations of the response indexed by media type.
+		Content map[string]*MediaType `json:"content,omitempty"`
+	}
+
+	// Header represents a response header.
+	Header struct {
+		// Description is a brief description of the header.
+		Description string `json:"description,omitempty"`
+		// Required determines whether this header is always sent.
+		Required bool `json:"required,omitempty"`
+		// Schema defines the type used for the header.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+	}
+
+	// Components holds the reusable objects referenced by the document.
+	Components struct {
+		// Schemas lists the types and media types indexed by name.
+		Schemas map[string]*genschema.JSONSchema `json:"schemas,omitempty"`
+		// Responses lists the API responses indexed by name.
+		Responses map[string]*Response `json:"responses,omitempty"`
+		// SecuritySchemes lists the security schemes indexed by name.
+		SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+	}
+
+	// SecurityScheme defines a security scheme that can be used by the operations.
+	SecurityScheme struct {
+		// Type of the security scheme. Valid values are "apiKey", "http" or "oauth2".
+		Type string `json:"type"`
+		// Description for security scheme.
+		Description string `json:"description,omitempty"`
+		// Name of the header, query or cookie parameter to be used when type is "apiKey".
+		Name string `json:"name,omitempty"`
+		// In is the location of the API key when type is "apiKey".
+		In string `json:"in,omitempty"`
+		// Scheme is the name of the HTTP Authorization scheme when type is "http".
+		Scheme string `json:"scheme,omitempty"`
+		// BearerFormat is a hint to the client on how the bearer token is formatted.
+		BearerFormat string `json:"bearerFormat,omitempty"`
+		// Flows contains the configuration for the flow types supported when type is
+		// "oauth2".
+		Flows *OAuthFlows `json:"flows,omitempty"`
+	}
+
+	// OAuthFlows lists the supported OAuth2 flows.
+	OAuthFlows struct {
+		Implicit          *OAuthFlow `json:"implicit,omitempty"`
+		Password          *OAuthFlow `json:"password,omitempty"`
+		ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+		AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+	}
+
+	// OAuthFlow describes the configuration of a supported OAuth2 flow.
+	OAuthFlow struct {
+		// AuthorizationURL is the authorization URL to be used for this flow.
+		AuthorizationURL string `json:"authorizationUrl,omitempty"`
+		// TokenURL is the token URL to be used for this flow.
+		TokenURL string `json:"tokenUrl,omitempty"`
+		// Scopes lists the available scopes for the OAuth2 security scheme.
+		Scopes map[string]string `json:"scopes"`
+	}
+
+	// Tag adds metadata to a tag used by the operations.
+	Tag struct {
+		// Name of the tag.
+		Name string `json:"name"`
+		// Description is a short description of the tag.
+		Description string `json:"description,omitempty"`
+		// ExternalDocs is additional external documentation for this tag.
+		ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+	}
+
+	// ExternalDocs allows referencing an external resource for extended documentation.
+	ExternalDocs struct {
+		// Description is a short description of the target documentation.
+		Description string `json:"description,omitempty"`
+		// URL for the target documentation.
+		URL string `json:"url"`
+	}
+)
+
+// New creates an OpenAPI document from an API definition.
+func New(api *design.APIDefinition) (*OpenAPI, error) {
+	if api == nil {
+		return nil, nil
+	}
+	basePath := api.BasePath
+	if hasAbsoluteRoutes(api) || len(design.ExtractWildcards(basePath)) > 0 {
+		basePath = ""
+	}
+	s := &OpenAPI{
+		OpenAPI: "3.0.3",
+		Info: &Info{
+			Title:          api.Title,
+			Description:    api.Description,
+			TermsOfService: api.TermsOfService,
+			Contact:        api.Contact,
+			License:        api.License,
+			Version:        api.Version,
+		},
+		Servers:      serversFromDefinition(api, basePath),
+		Paths:        make(map[string]*PathItem),
+		ExternalDocs: docsFromDefinition(api.Docs),
+		Components: &Components{
+			SecuritySchemes: securitySchemesFromDefinition(api.SecuritySchemes),
+		},
+	}
+
+	err := api.IterateResponses(func(r *design.ResponseDefinition) error {
+		if s.Components.Responses == nil {
+			s.Components.Responses = make(map[string]*Response)
+		}
+		s.Components.Responses[r.Name] = responseFromDefinition(api, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.IterateResources(func(res *design.ResourceDefinition) error {
+		generated := false
+		err := res.IterateFileServers(func(fs *design.FileServerDefinition) error {
+			if !mustGenerate(fs.Metadata) {
+				return nil
+			}
+			generated = true
+			return buildPathFromFileServer(s, api, fs)
+		})
+		if err != nil {
+			return err
+		}
+		err = res.IterateActions(func(a *design.ActionDefinition) error {
+			if !mustGenerate(a.Metadata) {
+				return nil
+			}
+			generated = true
+			for _, route := range a.Routes {
+				if err := buildPathFromDefinition(s, api, route, basePath); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if generated {
+			s.Tags = append(s.Tags, &Tag{Name: res.Name, Description: res.Description})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(genschema.Definitions) > 0 {
+		s.Components.Schemas = make(map[string]*genschema.JSONSchema, len(genschema.Definitions))
+		for n, d := range genschema.Definitions {
+			s.Components.Schemas[n] = toOpenAPISchema(d)
+		}
+	}
+	return s, nil
+}
+
+// New31 creates an OpenAPI 3.1 document from an API definition. The schemas of the document
+// are valid JSON Schema 2020-12 schemas, see genschema.ToDraft202012.
+func New31(api *design.APIDefinition) (*OpenAPI, error) {
+	s, err := New(api)
+	if err != nil || s == nil {
+		return s, err
+	}
+	s.OpenAPI = "3.1.0"
+	for n, schema := range s.Components.Schemas {
+		s.Components.Schemas[n] = toDraft202012(schema)
+	}
+	for _, r := range s.Components.Responses {
+		upgradeResponse(r)
+	}
+	for _, item := range s.Paths {
+		for _, op := range []*Operation{item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch} {
+			if op == nil {
+				continue
+			}
+			for _, p := range op.Parameters {
+				p.Schema = toDraft202012(p.Schema)
+			}
+			if op.RequestBody != nil {
+				upgradeContent(op.RequestBody.Content)
+			}
+			for _, r := range op.Responses {
+				upgradeResponse(r)
+			}
+		}
+	}
+	return s, nil
+}
+
+// upgradeResponse converts the schemas used by the response headers and content to JSON
+// Schema 2020-12.
+func upgradeResponse(r *Response) {
+	for _, h := range r.Headers {
+		h.Schema = toDraft202012(h.Schema)
+	}
+	upgradeContent(r.Content)
+}
+
+// upgradeContent converts the schemas of the given content to JSON Schema 2020-12.
+func upgradeContent(content map[string]*MediaType) {
+	for _, mt := range content {
+		mt.Schema = toDraft202012(mt.Schema)
+	}
+}
+
+// toDraft202012 converts a schema produced by toOpenAPISchema to JSON Schema 2020-12.
+func toDraft202012(s *genschema.JSONSchema) *genschema.JSONSchema {
+	return genschema.ToDraft202012(s, "#/components/schemas/")
+}
+
+// mustGenerate returns true if the metadata indicates that the OpenAPI specification should be
+// generated, false otherwise. The "openapi:generate" metadata takes precedence over the
+// "swagger:generate" metadata.
+func mustGenerate(meta dslengine.MetadataDefinition) bool {
+	m, ok := meta["openapi:generate"]
+	if !ok {
+		m = meta["swagger:generate"]
+	}
+	return len(m) == 0 || m[0] != "false"
+}
+
+// hasAbsoluteRoutes returns true if any action exposed by the API uses an absolute route or if
+// the API has file servers. The base path cannot be used as server URL in this case.
+func hasAbsoluteRoutes(api *design.APIDefinition) bool {
+	for _, res := range api.Resources {
+		for _, fs := range res.FileServers {
+			if mustGenerate(fs.Metadata) {
+				return true
+			}
+		}
+		for _, a := range res.Actions {
+			if !mustGenerate(a.Metadata) {
+				continue
+			}
+			for _, ro := range a.Routes {
+				if ro.IsAbsolute() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// serversFromDefinition computes the server URLs from the API host, schemes and base path.
+func serversFromDefinition(api *design.APIDefinition, basePath string) []*Server {
+	if api.Host == "" {
+		if basePath == "" {
+			return nil
+		}
+		return []*Server{{URL: basePath}}
+	}
+	schemes := api.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	servers := make([]*Server, len(schemes))
+	for i, scheme := range schemes {
+		servers[i] = &Server{URL: fmt.Sprintf("%s://%s%s", scheme, api.Host, basePath)}
+	}
+	return servers
+}
+
+func securitySchemesFromDefinition(schemes []*design.SecuritySchemeDefinition) map[string]*SecurityScheme {
+	if len(schemes) == 0 {
+		return nil
+	}
+	defs := make(map[string]*SecurityScheme)
+	for _, scheme := range schemes {
+		def := &SecurityScheme{Description: scheme.Description}
+		switch scheme.Kind {
+		case design.BasicAuthSecurityKind:
+			def.Type = "http"
+			def.Scheme = "basic"
+		case design.APIKeySecurityKind:
+			def.Type = "apiKey"
+			def.Name = scheme.Name
+			def.In = scheme.In
+		case design.JWTSecurityKind:
+			def.Type = "http"
+			def.Scheme = "bearer"
+			def.BearerFormat = "JWT"
+			if scheme.TokenURL != "" {
+				def.Description += fmt.Sprintf("\n\n**Token URL**: %s", scheme.TokenURL)
+			}
+			if len(scheme.Scopes) != 0 {
+				def.Description += fmt.Sprintf("\n\n**Security Scopes**:\n%s", scopesMapList(scheme.Scopes))
+			}
+		case design.OAuth2SecurityKind:
+			def.Type = "oauth2"
+			def.Flows = flowsFromDefinition(scheme)
+		default:
+			continue
+		}
+		defs[scheme.SchemeName] = def
+	}
+	return defs
+}
+
+func flowsFromDefinition(scheme *design.SecuritySchemeDefinition) *OAuthFlows {
+	scopes := scheme.Scopes
+	if scopes == nil {
+		scopes = make(map[string]string)
+	}
+	flows := &OAuthFlows{}
+	switch scheme.Flow {
+	case "implicit":
+		flows.Implicit = &OAuthFlow{AuthorizationURL: scheme.AuthorizationURL, Scopes: scopes}
+	case "password":
+		flows.Password = &OAuthFlow{TokenURL: scheme.TokenURL, Scopes: scopes}
+	case "application":
+		flows.ClientCredentials = &OAuthFlow{TokenURL: scheme.TokenURL, Scopes: scopes}
+	case "accessCode":
+		flows.AuthorizationCode = &OAuthFlow{
+			AuthorizationURL: scheme.AuthorizationURL,
+			TokenURL:         scheme.TokenURL,
+			Scopes:           scopes,
+		}
+	}
+	return flows
+}
+
+func scopesMapList(scopes map[string]string) string {
+	names := []string{}
+	for name := range scopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := []string{}
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("  * `%s`: %s", name, scopes[name]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// paramsFromDefinition returns the path and query string parameters. The location of a
+// parameter may be overridden with the "openapi:in" metadata, e.g. to describe cookies.
+func paramsFromDefinition(api *design.APIDefinition, params *design.AttributeDefinition, path string) ([]*Parameter, error) {
+	if params == nil {
+		return nil, nil
+	}
+	obj := params.Type.ToObject()
+	if obj == nil {
+		return nil, fmt.Errorf("invalid parameters definition, not an object")
+	}
+	var res []*Parameter
+	wildcards := design.ExtractWildcards(path)
+	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		in := "query"
+		required := params.IsRequired(n)
+		for _, w := range wildcards {
+			if n == w {
+				in = "path"
+				required = true
+				break
+			}
+		}
+		res = append(res, paramFor(api, at, n, in, required))
+		return nil
+	})
+	return res, nil
+}
+
+func paramsFromHeaders(api *design.APIDefinition, action *design.ActionDefinition) []*Parameter {
+	var params []*Parameter
+	action.IterateHeaders(func(name string, required bool, header *design.AttributeDefinition) error {
+		params = append(params, paramFor(api, header, name, "header", required))
+		return nil
+	})
+	return params
+}
+
+func paramFor(api *design.APIDefinition, at *design.AttributeDefinition, name, in string, required bool) *Parameter {
+	if in != "path" {
+		if loc, ok := at.Metadata["openapi:in"]; ok && len(loc) > 0 {
+			in = loc[0]
+		}
+	}
+	return &Parameter{
+		Name:        name,
+		In:          in,
+		Description: at.Description,
+		Required:    required,
+		Schema:      toOpenAPISchema(genschema.AttributeSchema(api, at)),
+	}
+}
+
+func responseFromDefinition(api *design.APIDefinition, r *design.ResponseDefinition) *Response {
+	resp := &Response{
+		Description: r.Description,
+		Headers:     headersFromDefinition(api, r.Headers),
+	}
+	if resp.Description == "" {
+		resp.Description = http.StatusText(r.Status)
+	}
+	var schema *genschema.JSONSchema
+	if mt, ok := api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]; ok && r.MediaType != "" {
+		schema = mediaTypeSchema(api, mt, r.ViewName)
+	} else if r.Type != nil {
+		schema = toOpenAPISchema(genschema.TypeSchema(api, r.Type))
+	}
+	if schema != nil || r.MediaType != "" {
+		mediaType := r.MediaType
+		if mediaType == "" {
+			mediaType = "application/json"
+		}
+		resp.Content = map[string]*MediaType{mediaType: {Schema: schema}}
+	}
+	return resp
+}
+
+// mediaTypeSchema returns the schema of the response media type rendered with the given view.
+// Responses that do not specify a view may render the media type using any of its views, the
+// schema combines the schemas of all the views with oneOf in this case.
+func mediaTypeSchema(api *design.APIDefinition, mt *design.MediaTypeDefinition, view string) *genschema.JSONSchema {
+	if view == "" && len(mt.Views) > 1 {
+		schema := &genschema.JSONSchema{}
+		mt.IterateViews(func(v *design.ViewDefinition) error {
+			ref := componentRef(genschema.MediaTypeRef(api, mt, v.Name))
+			schema.OneOf = append(schema.OneOf, &genschema.JSONSchema{Ref: ref})
+			return nil
+		})
+		return schema
+	}
+	if view == "" {
+		view = design.DefaultView
+	}
+	return &genschema.JSONSchema{Ref: componentRef(genschema.MediaTypeRef(api, mt, view))}
+}
+
+func headersFromDefinition(api *design.APIDefinition, headers *design.AttributeDefinition) map[string]*Header {
+	if headers == nil {
+		return nil
+	}
+	obj := headers.Type.ToObject()
+	if len(obj) == 0 {
+		return nil
+	}
+	res := make(map[string]*Header, len(obj))
+	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		res[n] = &Header{
+			Description: at.Description,
+			Required:    headers.IsRequired(n),
+			Schema:      toOpenAPISchema(genschema.AttributeSchema(api, at)),
+		}
+		return nil
+	})
+	return res
+}
+
+func buildPathFromFileServer(s *OpenAPI, api *design.APIDefinition, fs *design.FileServerDefinition) error {
+	wcs := design.ExtractWildcards(fs.RequestPath)
+	var params []*Parameter
+	if len(wcs) > 0 {
+		params = []*Parameter{{
+			In:          "path",
+			Name:        wcs[0],
+			Description: "Relative file path",
+			Required:    true,
+			Schema:      &genschema.JSONSchema{Type: genschema.JSONString},
+		}}
+	}
+	params = append(params, &Parameter{
+		In:          "header",
+		Name:        "Range",
+		Description: "Byte ranges of the file to download, e.g. bytes=0-1023",
+		Schema:      &genschema.JSONSchema{Type: genschema.JSONString},
+	}, &Parameter{
+		In:          "header",
+		Name:        "If-Range",
+		Description: "ETag or Last-Modified date of the file, the whole file is downloaded if it changed",
+		Schema:      &genschema.JSONSchema{Type: genschema.JSONString},
+	})
+
+	responses := map[string]*Response{
+		"200": {
+			Description: "File downloaded",
+			Content: map[string]*MediaType{
+				"*/*": {Schema: &genschema.JSONSchema{Type: genschema.JSONString, Format: "binary"}},
+			},
+		},
+		"206": {
+			Description: "File ranges downloaded",
+			Content: map[string]*MediaType{
+				"*/*": {Schema: &genschema.JSONSchema{Type: genschema.JSONString, Format: "binary"}},
+			},
+		},
+		"416": {Description: "Requested range not satisfiable"},
+	}
+	if len(wcs) > 0 {
+		schema := toOpenAPISchema(genschema.TypeSchema(api, design.ErrorMedia))
+		responses["404"] = &Response{
+			Description: "File not found",
+			Content:     map[string]*MediaType{design.ErrorMediaIdentifier: {Schema: schema}},
+		}
+	}
+
+	operation := &Operation{
+		Tags:         []string{fs.Parent.Name},
+		Description:  fs.Description,
+		Summary:      summaryFromDefinition(fmt.Sprintf("Download %s", fs.FilePath), fs.Metadata),
+		ExternalDocs: docsFromDefinition(fs.Docs),
+		OperationID:  fmt.Sprintf("%s#%s", fs.Parent.Name, fs.RequestPath),
+		Parameters:   params,
+		Responses:    responses,
+	}
+	applySecurity(operation, fs.Security)
+
+	key := design.WildcardRegex.ReplaceAllStringFunc(
+		fs.RequestPath,
+		func(w string) string {
+			return fmt.Sprintf("/{%s}", w[2:])
+		},
+	)
+	if key == "" {
+		key = "/"
+	}
+	pathItem(s, key).Get = operation
+	return nil
+}
+
+func buildPathFromDefinition(s *OpenAPI, api *design.APIDefinition, route *design.RouteDefinition, basePath string) error {
+	action := route.Parent
+
+	params, err := paramsFromDefinition(api, action.AllParams(), route.FullPath())
+	if err != nil {
+		return err
+	}
+	params = append(params, paramsFromHeaders(api, action)...)
+
+	responses := make(map[string]*Response, len(action.Responses))
+	action.IterateResponses(func(r *design.ResponseDefinition) error {
+		responses[strconv.Itoa(r.Status)] = responseFromDefinition(api, r)
+		return nil
+	})
+
+	var body *RequestBody
+	if action.Payload != nil {
+		schema := toOpenAPISchema(genschema.TypeSchema(api, action.Payload))
+		content := make(map[string]*MediaType)
+		if action.PayloadMultipart {
+			content["multipart/form-data"] = &MediaType{Schema: schema}
+		} else {
+			for _, mime := range consumes(api) {
+				content[mime] = &MediaType{Schema: schema}
+			}
+		}
+		body = &RequestBody{
+			Description: action.Payload.Description,
+			Content:     content,
+			Required:    !action.PayloadOptional,
+		}
+	}
+
+	operationID := fmt.Sprintf("%s#%s", action.Parent.Name, action.Name)
+	for i, rt := range action.Routes {
+		if rt == route {
+			if i > 0 {
+				operationID = fmt.Sprintf("%s#%d", operationID, i)
+			}
+			break
+		}
+	}
+
+	operation := &Operation{
+		Tags:         []string{action.Parent.Name},
+		Description:  action.Description,
+		Summary:      summaryFromDefinition(action.Name+" "+action.Parent.Name, action.Metadata),
+		ExternalDocs: docsFromDefinition(action.Docs),
+		OperationID:  operationID,
+		Parameters:   params,
+		RequestBody:  body,
+		Responses:    responses,
+	}
+	applySecurity(operation, action.Security)
+
+	key := design.WildcardRegex.ReplaceAllStringFunc(
+		route.FullPath(),
+		func(w string) string {
+			return fmt.Sprintf("/{%s}", w[2:])
+		},
+	)
+	if basePath != "" && basePath != "/" {
+		key = strings.TrimPrefix(key, basePath)
+	}
+	if key == "" {
+		key = "/"
+	}
+	p := pathItem(s, key)
+	switch route.Verb {
+	case "GET":
+		p.Get = operation
+	case "PUT":
+		p.Put = operation
+	case "POST":
+		p.Post = operation
+	case "DELETE":
+		p.Delete = operation
+	case "OPTIONS":
+		p.Options = operation
+	case "HEAD":
+		p.Head = operation
+	case "PATCH":
+		p.Patch = operation
+	}
+	return nil
+}
+
+// pathItem returns the path item for the given key, creating it if needed.
+func pathItem(s *OpenAPI, key string) *PathItem {
+	p, ok := s.Paths[key]
+	if !ok {
+		p = new(PathItem)
+		s.Paths[key] = p
+	}
+	return p
+}
+
+// consumes returns the MIME types supported by the API decoders.
+func consumes(api *design.APIDefinition) []string {
+	var mimes []string
+	for _, c := range api.Consumes {
+		mimes = append(mimes, c.MIMETypes...)
+	}
+	if len(mimes) == 0 {
+		mimes = []string{"application/json"}
+	}
+	return mimes
+}
+
+func summaryFromDefinition(name string, metadata dslengine.MetadataDefinition) string {
+	for _, key := range []string{"openapi:summary", "swagger:summary"} {
+		if mdata, ok := metadata[key]; ok && len(mdata) > 0 {
+			return mdata[0]
+		}
+	}
+	return name
+}
+
+func applySecurity(operation *Operation, security *design.SecurityDefinition) {
+	if security == nil || security.Scheme.Kind == design.NoSecurityKind {
+		return
+	}
+	scopes := security.Scopes
+	if security.Scheme.Kind != design.OAuth2SecurityKind {
+		// OpenAPI only supports scopes with OAuth2, list them in the description instead.
+		if len(scopes) > 0 {
+			if operation.Description != "" {
+				operation.Description += "\n\n"
+			}
+			operation.Description += fmt.Sprintf("Required security scopes:\n%s", scopesList(scopes))
+		}
+		scopes = nil
+	}
+	if scopes == nil {
+		scopes = make([]string, 0)
+	}
+	operation.Security = []map[string][]string{{security.Scheme.SchemeName: scopes}}
+}
+
+func scopesList(scopes []string) string {
+	sorted := append([]string{}, scopes...)
+	sort.Strings(sorted)
+
+	var lines []string
+	for _, scope := range sorted {
+		lines = append(lines, fmt.Sprintf("  * `%s`", scope))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func docsFromDefinition(docs *design.DocsDefinition) *ExternalDocs {
+	if docs == nil {
+		return nil
+	}
+	return &ExternalDocs{
+		Description: docs.Description,
+		URL:         docs.URL,
+	}
+}
+
+// toOpenAPISchema returns a copy of the given JSON schema suitable for OpenAPI: the references
+// to definitions point to the components schemas and the hyper-schema fields that OpenAPI does
+// not support are removed.
+func toOpenAPISchema(s *genschema.JSONSchema) *genschema.JSONSchema {
+	if s == nil {
+		return nil
+	}
+	res := *s
+	res.Schema = ""
+	res.ID = ""
+	res.Media = nil
+	res.Links = nil
+	res.PathStart = ""
+	res.Definitions = nil
+	res.Ref = componentRef(s.Ref)
+	if res.Type == genschema.JSONFile {
+		res.Type = genschema.JSONString
+		res.Format = "binary"
+	}
+	res.Items = toOpenAPISchema(s.Items)
+	res.Properties = nil
+	if len(s.Properties) > 0 {
+		res.Properties = make(map[string]*genschema.JSONSchema, len(s.Properties))
+		for n, p := range s.Properties {
+			res.Properties[n] = toOpenAPISchema(p)
+		}
+	}
+	res.AnyOf = toOpenAPISchemas(s.AnyOf)
+	res.OneOf = toOpenAPISchemas(s.OneOf)
+	return &res
+}
+
+func toOpenAPISchemas(schemas []*genschema.JSONSchema) []*genschema.JSONSchema {
+	if schemas == nil {
+		return nil
+	}
+	res := make([]*genschema.JSONSchema, len(schemas))
+	for i, s := range schemas {
+		res[i] = toOpenAPISchema(s)
+	}
+	return res
+}
+
+// componentRef converts a reference to a JSON schema definition into a reference to the
+// corresponding components schema.
+func componentRef(ref string) string {
+	if strings.HasPrefix(ref, "#/definitions/") {
+		return "#/components/schemas/" + strings.TrimPrefix(ref, "#/definitions/")
+	}
+	return ref
+}
@@ -0,0 +1,276 @@
+package genopenapi_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genopenapi "github.com/kyokomi/goa-v1/goagen/gen_openapi"
+	genschema "github.com/kyokomi/goa-v1/goagen/gen_schema"
+)
+
+var _ = Describe("New", func() {
+	var doc *genopenapi.OpenAPI
+	var newErr error
+
+	BeforeEach(func() {
+		doc = nil
+		newErr = nil
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		doc, newErr = genopenapi.New(Design)
+	})
+
+	Context("with a basic API", func() {
+		BeforeEach(func() {
+			apidsl.API("test", func() {
+				apidsl.Title("test API")
+				apidsl.Version("1.0")
+				apidsl.Host("example.com")
+				apidsl.Scheme("https")
+				apidsl.BasePath("/api")
+			})
+		})
+
+		It("sets the version, info and servers", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(doc.OpenAPI).Should(Equal("3.0.3"))
+			Ω(doc.Info.Title).Should(Equal("test API"))
+			Ω(doc.Info.Version).Should(Equal("1.0"))
+			Ω(doc.Servers).Should(HaveLen(1))
+			Ω(doc.Servers[0].URL).Should(Equal("https://example.com/api"))
+		})
+
+		It("serializes into JSON", func() {
+			b, err := json.Marshal(doc)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(ContainSubstring(`"openapi":"3.0.3"`))
+			Ω(string(b)).Should(ContainSubstring(`"paths":{}`))
+		})
+	})
+
+	Context("with actions", func() {
+		BeforeEach(func() {
+			bottle := apidsl.MediaType("application/vnd.bottle", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer)
+					apidsl.Attribute("name", String)
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+				})
+			})
+			payload := apidsl.Type("BottlePayload", func() {
+				apidsl.Attribute("name", String)
+				apidsl.Required("name")
+			})
+			apidsl.JWTSecurity("jwt", func() {
+				apidsl.Header("Authorization")
+			})
+			apidsl.OAuth2Security("oauth2", func() {
+				apidsl.AccessCodeFlow("/authorization", "/token")
+				apidsl.Scope("api:read", "read access")
+			})
+			apidsl.API("test", func() {
+				apidsl.BasePath("/api")
+				apidsl.Security("jwt")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", Integer)
+						apidsl.Param("session", String, func() {
+							apidsl.Metadata("openapi:in", "cookie")
+						})
+					})
+					apidsl.Headers(func() {
+						apidsl.Header("X-Request-Id")
+					})
+					apidsl.Response(OK, bottle)
+					apidsl.Response(NotFound)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(payload)
+					apidsl.Security("oauth2", func() {
+						apidsl.Scope("api:read")
+					})
+					apidsl.Response(Created)
+				})
+			})
+		})
+
+		It("generates the paths", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(doc.Paths).Should(HaveKey("/bottles/{id}"))
+			Ω(doc.Paths).Should(HaveKey("/bottles"))
+			show := doc.Paths["/bottles/{id}"].Get
+			Ω(show).ShouldNot(BeNil())
+			Ω(show.OperationID).Should(Equal("bottle#show"))
+			Ω(show.Tags).Should(Equal([]string{"bottle"}))
+			Ω(doc.Servers[0].URL).Should(Equal("/api"))
+		})
+
+		It("generates the parameters", func() {
+			params := doc.Paths["/bottles/{id}"].Get.Parameters
+			Ω(params).Should(HaveLen(3))
+			Ω(params[0].Name).Should(Equal("id"))
+			Ω(params[0].In).Should(Equal("path"))
+			Ω(params[0].Required).Should(BeTrue())
+			Ω(string(params[0].Schema.Type)).Should(Equal("integer"))
+			Ω(params[1].Name).Should(Equal("session"))
+			Ω(params[1].In).Should(Equal("cookie"))
+			Ω(params[2].Name).Should(Equal("X-Request-Id"))
+			Ω(params[2].In).Should(Equal("header"))
+		})
+
+		It("generates the request body", func() {
+			create := doc.Paths["/bottles"].Post
+			Ω(create.RequestBody).ShouldNot(BeNil())
+			Ω(create.RequestBody.Required).Should(BeTrue())
+			Ω(create.RequestBody.Content).Should(HaveKey("application/json"))
+			Ω(create.RequestBody.Content["application/json"].Schema.Ref).Should(Equal("#/components/schemas/BottlePayload"))
+			Ω(doc.Components.Schemas).Should(HaveKey("BottlePayload"))
+		})
+
+		It("generates the responses", func() {
+			ok := doc.Paths["/bottles/{id}"].Get.Responses["200"]
+			Ω(ok).ShouldNot(BeNil())
+			Ω(ok.Content).Should(HaveKey("application/vnd.bottle"))
+			Ω(ok.Content["application/vnd.bottle"].Schema.Ref).Should(Equal("#/components/schemas/Bottle"))
+			Ω(doc.Paths["/bottles/{id}"].Get.Responses).Should(HaveKey("404"))
+			Ω(doc.Paths["/bottles/{id}"].Get.Responses["404"].Description).Should(Equal("Not Found"))
+		})
+
+		It("generates the security schemes", func() {
+			schemes := doc.Components.SecuritySchemes
+			Ω(schemes).Should(HaveKey("jwt"))
+			Ω(schemes["jwt"].Type).Should(Equal("http"))
+			Ω(schemes["jwt"].Scheme).Should(Equal("bearer"))
+			Ω(schemes).Should(HaveKey("oauth2"))
+			Ω(schemes["oauth2"].Type).Should(Equal("oauth2"))
+			Ω(schemes["oauth2"].Flows.AuthorizationCode).ShouldNot(BeNil())
+			Ω(schemes["oauth2"].Flows.AuthorizationCode.TokenURL).Should(Equal("/token"))
+			Ω(doc.Paths["/bottles/{id}"].Get.Security).Should(Equal([]map[string][]string{{"jwt": {}}}))
+			Ω(doc.Paths["/bottles"].Post.Security).Should(Equal([]map[string][]string{{"oauth2": {"api:read"}}}))
+		})
+	})
+
+	Context("with a response media type with multiple views", func() {
+		BeforeEach(func() {
+			bottle := apidsl.MediaType("application/vnd.bottle", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer)
+					apidsl.Attribute("name", String)
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+				})
+				apidsl.View("tiny", func() {
+					apidsl.Attribute("id")
+				})
+			})
+			apidsl.API("test", nil)
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/"))
+					apidsl.Response(OK, bottle)
+				})
+			})
+		})
+
+		It("combines the views schemas with oneOf", func() {
+			ok := doc.Paths["/"].Get.Responses["200"]
+			Ω(ok.Content).Should(HaveKey("application/vnd.bottle"))
+			schema := ok.Content["application/vnd.bottle"].Schema
+			Ω(schema.OneOf).Should(HaveLen(2))
+			Ω(schema.OneOf[0].Ref).Should(Equal("#/components/schemas/Bottle"))
+			Ω(schema.OneOf[1].Ref).Should(Equal("#/components/schemas/BottleTiny"))
+			Ω(doc.Components.Schemas).Should(HaveKey("BottleTiny"))
+		})
+	})
+
+	Context("with a file server", func() {
+		BeforeEach(func() {
+			apidsl.API("test", nil)
+			apidsl.Resource("public", func() {
+				apidsl.Files("/assets/*filepath", "/www/assets")
+			})
+		})
+
+		It("documents the range requests", func() {
+			get := doc.Paths["/assets/{filepath}"].Get
+			Ω(get.Parameters).Should(HaveLen(3))
+			Ω(get.Parameters[1].In).Should(Equal("header"))
+			Ω(get.Parameters[1].Name).Should(Equal("Range"))
+			Ω(get.Parameters[2].Name).Should(Equal("If-Range"))
+			Ω(get.Responses).Should(HaveKey("206"))
+			Ω(get.Responses).Should(HaveKey("416"))
+		})
+	})
+})
+
+var _ = Describe("New31", func() {
+	var doc *genopenapi.OpenAPI
+	var newErr error
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		ProjectedMediaTypes = make(MediaTypeRoot)
+		payload := apidsl.Type("BottlePayload", func() {
+			apidsl.Attribute("kind", String, func() {
+				apidsl.Enum("wine")
+			})
+			apidsl.Attribute("name", String, func() {
+				apidsl.Example("Chateau")
+				apidsl.Metadata(genschema.NullableMetadata)
+			})
+		})
+		apidsl.API("test", nil)
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("create", func() {
+				apidsl.Routing(apidsl.POST("/"))
+				apidsl.Payload(payload)
+				apidsl.Response(Created)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		doc, newErr = genopenapi.New31(Design)
+	})
+
+	It("generates JSON Schema 2020-12 schemas", func() {
+		Ω(newErr).ShouldNot(HaveOccurred())
+		Ω(doc.OpenAPI).Should(Equal("3.1.0"))
+		js, err := json.Marshal(doc.Components.Schemas["BottlePayload"])
+		Ω(err).ShouldNot(HaveOccurred())
+		var schema map[string]interface{}
+		Ω(json.Unmarshal(js, &schema)).ShouldNot(HaveOccurred())
+		props := schema["properties"].(map[string]interface{})
+		kind := props["kind"].(map[string]interface{})
+		Ω(kind["const"]).Should(Equal("wine"))
+		name := props["name"].(map[string]interface{})
+		Ω(name["type"]).Should(Equal([]interface{}{"string", "null"}))
+		Ω(name["examples"]).Should(Equal([]interface{}{"Chateau"}))
+		create := doc.Paths["/"].Post
+		Ω(create.RequestBody.Content["application/json"].Schema.Ref).Should(Equal("#/components/schemas/BottlePayload"))
+	})
+})
@@ -0,0 +1,27 @@
+package genopenapi
+
+import "github.com/kyokomi/goa-v1/design"
+
+// Option a generator option definition
+type Option func(*Generator)
+
+// API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+// OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+// Spec OpenAPI specification version of the generated documents
+func Spec(spec string) Option {
+	return func(g *Generator) {
+		g.Spec = spec
+	}
+}
@@ -0,0 +1,30 @@
+/*
+Package genpact provides a generator for Pact contracts of the API. The generated package contains
+a pact file that follows version 3 of the Pact specification and a Go test file that depends on the
+standard library only and verifies a running provider against the pact.
+
+The pact file is named after the consumer given via the --consumer flag and the API. Each action
+gets an interaction for its lowest success response with a request built from the design examples.
+The "pact:state" metadata of actions and responses sets the provider state of the interactions,
+responses other than the success response only get an interaction when they set a state, e.g.:
+
+	Action("show", func() {
+		Metadata("pact:state", "bottle 1 exists")
+		Response(OK)
+		Response(NotFound, func() {
+			Metadata("pact:state", "bottle 1 does not exist")
+		})
+	})
+
+Response bodies are matched by type, integers must be integers and strings must match the enum and
+pattern validations of the design. The pact file may be published to a pact broker so that the
+consumer tests use it as their starting point.
+
+The verification test replays the interactions of the pact file given via the -pact.file flag, for
+example a pact downloaded from a broker, against the provider at the URL given via -pact.url. The
+provider states are set up by POSTing {"consumer": ..., "state": ..., "action": "setup"} to the URL
+given via -pact.setup, interactions that require a state are skipped when the flag is not set:
+
+	go test ./pact -args -pact.url=http://localhost:8080 -pact.setup=http://localhost:8080/_pact/setup
+*/
+package genpact
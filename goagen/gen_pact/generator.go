@@ -0,0 +1,492 @@
+package genpact
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of a Pact Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the Pact contract and provider verification generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Target   string                // Name of generated pact package, defaults to "pact"
+	Consumer string                // Name of the consumer of the pact, defaults to "consumer"
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, target, consumer, ver string
+
+	set := flag.NewFlagSet("pact", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&target, "pkg", "pact", "")
+	set.StringVar(&consumer, "consumer", "consumer", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Target: target, Consumer: consumer, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the pact file and the provider verification test.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.Target == "" {
+		g.Target = "pact"
+	}
+	if g.Consumer == "" {
+		g.Consumer = "consumer"
+	}
+	p, err := New(g.API, g.Consumer)
+	if err != nil {
+		return nil, err
+	}
+
+	pactDir := filepath.Join(g.OutDir, g.Target)
+	if err = codegen.RemoveAll(pactDir); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(pactDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, pactDir)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(p); err != nil {
+		return nil, err
+	}
+	pactFile := filepath.Join(pactDir, p.FileName())
+	if err = codegen.WriteFile(pactFile, buf.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, pactFile)
+
+	testFile := filepath.Join(pactDir, "pact_test.go")
+	file, err := codegen.SourceFileFor(testFile)
+	if err != nil {
+		return nil, err
+	}
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("bytes"),
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("flag"),
+		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("io"),
+		codegen.SimpleImport("io/ioutil"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("net/url"),
+		codegen.SimpleImport("reflect"),
+		codegen.SimpleImport("regexp"),
+		codegen.SimpleImport("strings"),
+		codegen.SimpleImport("testing"),
+	}
+	title := fmt.Sprintf("%s: Pact Provider Verification", g.API.Context())
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		file.Close()
+		return nil, err
+	}
+	data := map[string]interface{}{
+		"File":    p.FileName(),
+		"BaseURL": baseURL(g.API),
+	}
+	funcs := template.FuncMap{"quote": strconv.Quote}
+	if err = file.ExecuteTemplate("pact", pactT, funcs, data); err != nil {
+		file.Close()
+		return nil, err
+	}
+	file.Close()
+	if err = file.FormatCode(); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, testFile)
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+const pactT = `var (
+	pactFile = flag.String("pact.file", {{ quote .File }}, "Path to the verified pact file")
+	baseURL  = flag.String("pact.url", {{ quote .BaseURL }}, "Base URL of the verified provider")
+	setupURL = flag.String("pact.setup", "", "URL of the provider endpoint that sets up the provider states")
+	auth     = flag.String("pact.auth", "", "Value of the Authorization header sent with every request")
+)
+
+// pact is the content of a pact file.
+type pact struct {
+	Consumer     struct{ Name string }
+	Provider     struct{ Name string }
+	Interactions []*interaction
+}
+
+// interaction describes a request and the response the consumer expects.
+type interaction struct {
+	Description    string
+	ProviderStates []struct{ Name string }
+	Request        struct {
+		Method  string
+		Path    string
+		Query   map[string][]string
+		Headers map[string]string
+		Body    json.RawMessage
+	}
+	Response struct {
+		Status        int
+		Headers       map[string]string
+		Body          json.RawMessage
+		MatchingRules struct {
+			Header map[string]*rules
+			Body   map[string]*rules
+		}
+	}
+}
+
+// rules lists the matchers that apply to a header or a body path.
+type rules struct {
+	Matchers []*matcher
+}
+
+// matcher describes how an actual value is compared with the expected value.
+type matcher struct {
+	Match string
+	Regex string
+	Min   *int
+	Max   *int
+}
+
+// indexRegex matches the array indices of body paths.
+var indexRegex = regexp.MustCompile(` + "`" + `\[[0-9]+\]` + "`" + `)
+
+// TestPact replays the interactions of the pact file against the provider and checks that the
+// responses match the expectations of the consumer.
+func TestPact(t *testing.T) {
+	b, err := ioutil.ReadFile(*pactFile)
+	if err != nil {
+		t.Fatalf("failed to read pact file: %s", err)
+	}
+	var p pact
+	if err := json.Unmarshal(b, &p); err != nil {
+		t.Fatalf("invalid pact file %s: %s", *pactFile, err)
+	}
+	for _, i := range p.Interactions {
+		i := i
+		t.Run(i.Description, func(t *testing.T) { verify(t, p.Consumer.Name, i) })
+	}
+}
+
+// verify sets up the provider states of the interaction, sends its request and checks the
+// response.
+func verify(t *testing.T, consumer string, i *interaction) {
+	for _, s := range i.ProviderStates {
+		if *setupURL == "" {
+			t.Skipf("provider state %q requires the -pact.setup flag", s.Name)
+		}
+		if err := setup(consumer, s.Name); err != nil {
+			t.Fatalf("failed to set up provider state %q: %s", s.Name, err)
+		}
+	}
+	u := strings.TrimRight(*baseURL, "/") + i.Request.Path
+	if len(i.Request.Query) > 0 {
+		u += "?" + url.Values(i.Request.Query).Encode()
+	}
+	var body io.Reader
+	if len(i.Request.Body) > 0 {
+		body = bytes.NewReader(i.Request.Body)
+	}
+	req, err := http.NewRequest(i.Request.Method, u, body)
+	if err != nil {
+		t.Fatalf("failed to create request: %s", err)
+	}
+	for k, v := range i.Request.Headers {
+		req.Header.Set(k, v)
+	}
+	if *auth != "" {
+		req.Header.Set("Authorization", *auth)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %s", err)
+	}
+	if resp.StatusCode != i.Response.Status {
+		t.Fatalf("got status %d, expected %d, body: %s", resp.StatusCode, i.Response.Status, b)
+	}
+	for k, want := range i.Response.Headers {
+		got := resp.Header.Get(k)
+		if r, ok := i.Response.MatchingRules.Header[k]; ok && len(r.Matchers) > 0 {
+			if err := match(r.Matchers[0], "header "+k, want, got); err != "" {
+				t.Error(err)
+			}
+		} else if got != want {
+			t.Errorf("header %s: got %q, expected %q", k, got, want)
+		}
+	}
+	if len(i.Response.Body) == 0 {
+		return
+	}
+	expected, err := decode(i.Response.Body)
+	if err != nil {
+		t.Fatalf("invalid expected body: %s", err)
+	}
+	actual, err := decode(b)
+	if err != nil {
+		t.Fatalf("response body is not valid JSON: %s, body: %s", err, b)
+	}
+	for _, err := range compare("$", expected, actual, i.Response.MatchingRules.Body) {
+		t.Error(err)
+	}
+}
+
+// setup asks the provider to set up the given state.
+func setup(consumer, state string) error {
+	body, err := json.Marshal(map[string]string{"consumer": consumer, "state": state, "action": "setup"})
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(*setupURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("got status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// decode decodes the given JSON keeping the numbers as json.Number values.
+func decode(b []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// compare returns the differences between the expected and actual values at the given body
+// path. The values are compared with the matcher of the closest path that has rules and must be
+// equal if there is none.
+func compare(path string, expected, actual interface{}, rules map[string]*rules) []string {
+	m := lookup(path, rules)
+	if m == nil || m.Match == "equality" {
+		return equal(path, expected, actual, rules)
+	}
+	if m.Match != "type" {
+		if err := match(m, path, expected, actual); err != "" {
+			return []string{err}
+		}
+		return nil
+	}
+	if kindOf(expected) != kindOf(actual) {
+		return []string{fmt.Sprintf("%s: got %s, expected %s", path, kindOf(actual), kindOf(expected))}
+	}
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a := actual.(map[string]interface{})
+		var errs []string
+		for k, v := range e {
+			av, ok := a[k]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("%s.%s: missing", path, k))
+				continue
+			}
+			errs = append(errs, compare(path+"."+k, v, av, rules)...)
+		}
+		return errs
+	case []interface{}:
+		a := actual.([]interface{})
+		if m.Min != nil && len(a) < *m.Min {
+			return []string{fmt.Sprintf("%s: got %d elements, expected at least %d", path, len(a), *m.Min)}
+		}
+		if m.Max != nil && len(a) > *m.Max {
+			return []string{fmt.Sprintf("%s: got %d elements, expected at most %d", path, len(a), *m.Max)}
+		}
+		if len(e) == 0 {
+			return nil
+		}
+		var errs []string
+		for i, v := range a {
+			errs = append(errs, compare(fmt.Sprintf("%s[%d]", path, i), e[0], v, rules)...)
+		}
+		return errs
+	}
+	return nil
+}
+
+// equal returns the differences between the expected and actual values, the values of objects
+// and arrays are compared with compare so that the rules of their paths apply.
+func equal(path string, expected, actual interface{}, rules map[string]*rules) []string {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: got %s, expected object", path, kindOf(actual))}
+		}
+		var errs []string
+		for k, v := range e {
+			av, ok := a[k]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("%s.%s: missing", path, k))
+				continue
+			}
+			errs = append(errs, compare(path+"."+k, v, av, rules)...)
+		}
+		return errs
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: got %s, expected array", path, kindOf(actual))}
+		}
+		if len(a) != len(e) {
+			return []string{fmt.Sprintf("%s: got %d elements, expected %d", path, len(a), len(e))}
+		}
+		var errs []string
+		for i, v := range e {
+			errs = append(errs, compare(fmt.Sprintf("%s[%d]", path, i), v, a[i], rules)...)
+		}
+		return errs
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		return []string{fmt.Sprintf("%s: got %v, expected %v", path, actual, expected)}
+	}
+	return nil
+}
+
+// match checks the actual value with the given matcher, it returns the description of the
+// mismatch or the empty string.
+func match(m *matcher, path string, expected, actual interface{}) string {
+	switch m.Match {
+	case "type":
+		if kindOf(expected) != kindOf(actual) {
+			return fmt.Sprintf("%s: got %s, expected %s", path, kindOf(actual), kindOf(expected))
+		}
+	case "integer":
+		if n, ok := actual.(json.Number); !ok {
+			return fmt.Sprintf("%s: got %s, expected integer", path, kindOf(actual))
+		} else if _, err := n.Int64(); err != nil {
+			return fmt.Sprintf("%s: got %s, expected integer", path, n)
+		}
+	case "decimal", "number":
+		if _, ok := actual.(json.Number); !ok {
+			return fmt.Sprintf("%s: got %s, expected number", path, kindOf(actual))
+		}
+	case "regex":
+		s, ok := actual.(string)
+		if !ok {
+			s = fmt.Sprint(actual)
+		}
+		re, err := regexp.Compile(m.Regex)
+		if err != nil {
+			return fmt.Sprintf("%s: invalid regex %q: %s", path, m.Regex, err)
+		}
+		if !re.MatchString(s) {
+			return fmt.Sprintf("%s: %q does not match %q", path, s, m.Regex)
+		}
+	case "equality":
+		if !reflect.DeepEqual(expected, actual) {
+			return fmt.Sprintf("%s: got %v, expected %v", path, actual, expected)
+		}
+	default:
+		return fmt.Sprintf("%s: unsupported matcher %q", path, m.Match)
+	}
+	return ""
+}
+
+// lookup returns the matcher of the given body path, paths that have no rules use the matcher of
+// their closest parent.
+func lookup(path string, rules map[string]*rules) *matcher {
+	for p := path; p != ""; p = parent(p) {
+		for _, c := range []string{p, indexRegex.ReplaceAllString(p, "[*]")} {
+			if r, ok := rules[c]; ok && len(r.Matchers) > 0 {
+				return r.Matchers[0]
+			}
+		}
+	}
+	return nil
+}
+
+// parent returns the path of the parent of the value at the given body path, the empty string
+// for the root.
+func parent(path string) string {
+	i := strings.LastIndexAny(path, ".[")
+	if i <= 0 {
+		return ""
+	}
+	return path[:i]
+}
+
+// kindOf returns the JSON kind of the given decoded value.
+func kindOf(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
+`
@@ -0,0 +1,112 @@
+package genpact_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	genpact "github.com/kyokomi/goa-v1/goagen/gen_pact"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genpact.Generator
+
+	var args = struct {
+		api      *design.APIDefinition
+		outDir   string
+		target   string
+		consumer string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir:   "out_dir",
+		target:   "pacts",
+		consumer: "web",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genpact.NewGenerator(
+				genpact.API(args.api),
+				genpact.OutDir(args.outDir),
+				genpact.Target(args.target),
+				genpact.Consumer(args.consumer),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Target).Should(Equal(args.target))
+			Ω(generator.Consumer).Should(Equal(args.consumer))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(workspace.Path, "")
+		Ω(err).ShouldNot(HaveOccurred())
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {
+			apidsl.BasePath("/api")
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Params(func() {
+					apidsl.Param("id", design.Integer, func() {
+						apidsl.Example(1)
+					})
+				})
+				apidsl.Response(design.OK, func() {
+					apidsl.Media(design.ErrorMedia)
+				})
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genpact.NewGenerator(
+			genpact.API(design.Design),
+			genpact.OutDir(outDir),
+			genpact.Consumer("Web App"),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("generates the pact file and the verification test", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(HaveLen(3))
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "pact", "web-app-cellar.json"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring(`"path": "/api/bottles/1"`))
+		Ω(string(content)).Should(ContainSubstring(`"version": "3.0.0"`))
+		content, err = ioutil.ReadFile(filepath.Join(outDir, "pact", "pact_test.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring("package pact"))
+		Ω(string(content)).Should(ContainSubstring(`flag.String("pact.file", "web-app-cellar.json"`))
+		Ω(string(content)).Should(ContainSubstring("func TestPact(t *testing.T)"))
+	})
+})
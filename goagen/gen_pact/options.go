@@ -0,0 +1,34 @@
+package genpact
+
+import "github.com/kyokomi/goa-v1/design"
+
+// Option a generator option definition
+type Option func(*Generator)
+
+// API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+// OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+// Target Name of generated pact package
+func Target(target string) Option {
+	return func(g *Generator) {
+		g.Target = target
+	}
+}
+
+// Consumer Name of the consumer of the pact
+func Consumer(consumer string) Option {
+	return func(g *Generator) {
+		g.Consumer = consumer
+	}
+}
@@ -0,0 +1,410 @@
+package genpact
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+// SpecificationVersion is the version of the Pact specification implemented by the generated
+// pact files.
+const SpecificationVersion = "3.0.0"
+
+// StateKey is the metadata key that sets the provider state of an interaction.
+const StateKey = "pact:state"
+
+type (
+	// Pact is the content of a pact file.
+	Pact struct {
+		Consumer     *Pacticipant   `json:"consumer"`
+		Provider     *Pacticipant   `json:"provider"`
+		Interactions []*Interaction `json:"interactions"`
+		Metadata     *Metadata      `json:"metadata"`
+	}
+
+	// Pacticipant identifies the consumer or the provider of a pact.
+	Pacticipant struct {
+		Name string `json:"name"`
+	}
+
+	// Interaction describes a request sent by the consumer and the response it expects.
+	Interaction struct {
+		Description    string           `json:"description"`
+		ProviderStates []*ProviderState `json:"providerStates,omitempty"`
+		Request        *Request         `json:"request"`
+		Response       *Response        `json:"response"`
+	}
+
+	// ProviderState is a state the provider must be in before the request is sent.
+	ProviderState struct {
+		Name string `json:"name"`
+	}
+
+	// Request is the request of an interaction.
+	Request struct {
+		Method  string              `json:"method"`
+		Path    string              `json:"path"`
+		Query   map[string][]string `json:"query,omitempty"`
+		Headers map[string]string   `json:"headers,omitempty"`
+		Body    interface{}         `json:"body,omitempty"`
+	}
+
+	// Response is the response expected by an interaction.
+	Response struct {
+		Status        int               `json:"status"`
+		Headers       map[string]string `json:"headers,omitempty"`
+		Body          interface{}       `json:"body,omitempty"`
+		MatchingRules *MatchingRules    `json:"matchingRules,omitempty"`
+	}
+
+	// MatchingRules lists the rules used to compare the actual response headers and body with
+	// the expected values, indexed by header name and body path respectively.
+	MatchingRules struct {
+		Header map[string]*Rules `json:"header,omitempty"`
+		Body   map[string]*Rules `json:"body,omitempty"`
+	}
+
+	// Rules lists the matchers that apply to a header or a body path.
+	Rules struct {
+		Matchers []*Matcher `json:"matchers"`
+	}
+
+	// Matcher describes how an actual value is compared with the expected value.
+	Matcher struct {
+		Match string `json:"match"`
+		Regex string `json:"regex,omitempty"`
+		Min   *int   `json:"min,omitempty"`
+		Max   *int   `json:"max,omitempty"`
+	}
+
+	// Metadata describes the pact file.
+	Metadata struct {
+		PactSpecification *Version `json:"pactSpecification"`
+	}
+
+	// Version is a specification version.
+	Version struct {
+		Version string `json:"version"`
+	}
+)
+
+// New builds the pact between the given consumer and the API. Each action gets an interaction
+// for its lowest success response, the "pact:state" metadata of the action sets the provider
+// state of the interaction. The other responses that set a "pact:state" metadata get an
+// interaction of their own. WebSocket actions and actions with multipart payloads are skipped.
+func New(api *design.APIDefinition, consumer string) (*Pact, error) {
+	p := &Pact{
+		Consumer: &Pacticipant{Name: consumer},
+		Provider: &Pacticipant{Name: api.Name},
+		Metadata: &Metadata{PactSpecification: &Version{Version: SpecificationVersion}},
+	}
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() || a.PayloadMultipart || len(a.Routes) == 0 {
+				return nil
+			}
+			is, err := interactions(api, res, a)
+			if err != nil {
+				return err
+			}
+			p.Interactions = append(p.Interactions, is...)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FileName returns the name of the pact file, pact brokers and tools expect the names of the
+// consumer and of the provider separated by a dash.
+func (p *Pact) FileName() string {
+	return fileName(p.Consumer.Name) + "-" + fileName(p.Provider.Name) + ".json"
+}
+
+// interactions builds the interactions of the given action.
+func interactions(api *design.APIDefinition, res *design.ResourceDefinition, a *design.ActionDefinition) ([]*Interaction, error) {
+	var responses []*design.ResponseDefinition
+	a.IterateResponses(func(r *design.ResponseDefinition) error {
+		responses = append(responses, r)
+		return nil
+	})
+	sort.Slice(responses, func(i, j int) bool { return responses[i].Status < responses[j].Status })
+
+	req, err := request(api, a)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", a.Context(), err)
+	}
+	var (
+		is          []*Interaction
+		success     bool
+		actionState = state(a.Metadata)
+	)
+	for _, r := range responses {
+		st := state(r.Metadata)
+		isSuccess := !success && r.Status >= 200 && r.Status < 300
+		if !isSuccess && st == "" {
+			continue
+		}
+		if isSuccess {
+			success = true
+			if st == "" {
+				st = actionState
+			}
+		}
+		resp, err := response(api, r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: response %#v: %s", a.Context(), r.Name, err)
+		}
+		i := &Interaction{
+			Description: fmt.Sprintf("%s %s: %s", a.Name, res.Name, r.Name),
+			Request:     req,
+			Response:    resp,
+		}
+		if st != "" {
+			i.ProviderStates = []*ProviderState{{Name: st}}
+		}
+		is = append(is, i)
+	}
+	return is, nil
+}
+
+// request builds the request of the interactions of the given action from the design examples.
+func request(api *design.APIDefinition, a *design.ActionDefinition) (*Request, error) {
+	route := a.Routes[0]
+	rand := api.RandomGenerator()
+	req := &Request{Method: route.Verb}
+	all := a.AllParams().Type.ToObject()
+	pathParams := make(map[string]bool)
+	path := make(map[string]string)
+	for _, n := range route.Params() {
+		pathParams[n] = true
+		if at, ok := all[n]; ok {
+			path[n] = format(at.GenerateExample(rand, nil))
+		}
+	}
+	req.Path = codegen.PathParamRegex.ReplaceAllStringFunc(route.FullPath(), func(p string) string {
+		v := path[p[1:]]
+		if p[0] == '*' {
+			return (&url.URL{Path: v}).EscapedPath()
+		}
+		return url.PathEscape(v)
+	})
+	if a.QueryParams != nil {
+		obj := a.QueryParams.Type.ToObject()
+		for _, n := range codegen.SortedNames(obj) {
+			if pathParams[n] {
+				continue
+			}
+			if req.Query == nil {
+				req.Query = make(map[string][]string)
+			}
+			req.Query[n] = values(obj[n].GenerateExample(rand, nil))
+		}
+	}
+	if a.Headers != nil {
+		obj := a.Headers.Type.ToObject()
+		for _, n := range codegen.SortedNames(obj) {
+			if req.Headers == nil {
+				req.Headers = make(map[string]string)
+			}
+			req.Headers[n] = format(obj[n].GenerateExample(rand, nil))
+		}
+	}
+	if a.Payload != nil {
+		body := a.Payload.GenerateExample(rand, nil)
+		if _, err := json.Marshal(body); err != nil {
+			return nil, fmt.Errorf("payload example: %s", err)
+		}
+		if req.Headers == nil {
+			req.Headers = make(map[string]string)
+		}
+		req.Headers["Content-Type"] = "application/json"
+		req.Body = body
+	}
+	return req, nil
+}
+
+// response builds the expected response for the given response definition. The body is the
+// example of the rendered view of the response media type.
+func response(api *design.APIDefinition, r *design.ResponseDefinition) (*Response, error) {
+	resp := &Response{Status: r.Status}
+	var (
+		at          *design.AttributeDefinition
+		contentType string
+	)
+	if r.MediaType != "" {
+		mt := api.MediaTypeWithIdentifier(r.MediaType)
+		if mt == nil && design.CanonicalIdentifier(r.MediaType) == design.CanonicalIdentifier(design.ErrorMedia.Identifier) {
+			mt = design.ErrorMedia
+		}
+		if mt == nil {
+			return resp, nil
+		}
+		contentType = mt.Identifier
+		view := r.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return nil, err
+		}
+		at = p.AttributeDefinition
+	} else if r.Type != nil {
+		at = &design.AttributeDefinition{Type: r.Type}
+		contentType = "application/json"
+	}
+	if at == nil {
+		return resp, nil
+	}
+	body := at.GenerateExample(api.RandomGenerator(), nil)
+	if _, err := json.Marshal(body); err != nil {
+		return nil, fmt.Errorf("example: %s", err)
+	}
+	resp.Body = body
+	resp.Headers = map[string]string{"Content-Type": contentType}
+	resp.MatchingRules = &MatchingRules{
+		Header: map[string]*Rules{
+			"Content-Type": matching(&Matcher{Match: "regex", Regex: "^" + regexp.QuoteMeta(contentType) + "(;.*)?$"}),
+		},
+		Body: map[string]*Rules{"$": matching(&Matcher{Match: "type"})},
+	}
+	bodyRules(at, "$", resp.MatchingRules.Body, make(map[string]bool))
+	return resp, nil
+}
+
+// bodyRules adds the matching rules of the given attribute and of its children to rules. The
+// values of the response bodies are matched by type by default, integers must be integers and
+// strings must match the pattern or enum validations of the design. seen records the user types
+// being described to stop the recursion of recursive types.
+func bodyRules(at *design.AttributeDefinition, path string, rules map[string]*Rules, seen map[string]bool) {
+	if ut, ok := at.Type.(*design.UserTypeDefinition); ok {
+		if seen[ut.TypeName] {
+			return
+		}
+		seen[ut.TypeName] = true
+		defer delete(seen, ut.TypeName)
+	} else if mt, ok := at.Type.(*design.MediaTypeDefinition); ok {
+		if seen[mt.TypeName] {
+			return
+		}
+		seen[mt.TypeName] = true
+		defer delete(seen, mt.TypeName)
+	}
+	v := at.Validation
+	switch kind(at.Type) {
+	case design.ObjectKind:
+		obj := at.Type.ToObject()
+		for _, n := range codegen.SortedNames(obj) {
+			bodyRules(obj[n], path+"."+n, rules, seen)
+		}
+	case design.ArrayKind:
+		if v != nil && (v.MinLength != nil || v.MaxLength != nil) {
+			rules[path] = matching(&Matcher{Match: "type", Min: v.MinLength, Max: v.MaxLength})
+		}
+		bodyRules(at.Type.ToArray().ElemType, path+"[*]", rules, seen)
+	case design.IntegerKind:
+		rules[path] = matching(&Matcher{Match: "integer"})
+	case design.StringKind:
+		if v == nil {
+			return
+		}
+		if len(v.Values) > 0 {
+			alts := make([]string, len(v.Values))
+			for i, e := range v.Values {
+				alts[i] = regexp.QuoteMeta(format(e))
+			}
+			rules[path] = matching(&Matcher{Match: "regex", Regex: "^(" + strings.Join(alts, "|") + ")$"})
+		} else if v.Pattern != "" {
+			rules[path] = matching(&Matcher{Match: "regex", Regex: v.Pattern})
+		}
+	}
+}
+
+// matching returns the rules made of the given matcher.
+func matching(m *Matcher) *Rules {
+	return &Rules{Matchers: []*Matcher{m}}
+}
+
+// state returns the provider state set by the given metadata, the empty string if none.
+func state(md map[string][]string) string {
+	if s, ok := md[StateKey]; ok && len(s) > 0 {
+		return s[0]
+	}
+	return ""
+}
+
+// baseURL returns the default URL of the provider.
+func baseURL(api *design.APIDefinition) string {
+	scheme := "http"
+	if len(api.Schemes) > 0 {
+		scheme = api.Schemes[0]
+		for _, s := range api.Schemes {
+			if s == "http" {
+				scheme = s
+				break
+			}
+		}
+	}
+	host := api.Host
+	if host == "" {
+		host = "localhost:8080"
+	}
+	return scheme + "://" + host
+}
+
+// values returns the query string values of the given example.
+func values(v interface{}) []string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return []string{format(v)}
+	}
+	vals := make([]string, rv.Len())
+	for i := range vals {
+		vals[i] = format(rv.Index(i).Interface())
+	}
+	return vals
+}
+
+// format returns the string representation of the given example value, strings are returned
+// as is and other values as JSON.
+func format(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// fileName returns the given pacticipant name in lower case with dashes in place of the
+// characters that cannot appear in file names.
+func fileName(name string) string {
+	return strings.Trim(nonNameRegex.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// kind returns the kind of the given data type, user types and media types return the kind of
+// their underlying type.
+func kind(dt design.DataType) design.Kind {
+	switch t := dt.(type) {
+	case *design.UserTypeDefinition:
+		return kind(t.Type)
+	case *design.MediaTypeDefinition:
+		return kind(t.Type)
+	}
+	return dt.Kind()
+}
+
+// nonNameRegex matches the characters that are replaced in pact file names.
+var nonNameRegex = regexp.MustCompile(`[^a-z0-9_.]+`)
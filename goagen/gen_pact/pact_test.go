@@ -0,0 +1,143 @@
+package genpact_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genpact "github.com/kyokomi/goa-v1/goagen/gen_pact"
+)
+
+var _ = Describe("New", func() {
+	var pact *genpact.Pact
+	var newErr error
+
+	BeforeEach(func() {
+		pact = nil
+		newErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		pact, newErr = genpact.New(Design, "web")
+	})
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer)
+					apidsl.Attribute("color", String, func() {
+						apidsl.Enum("red", "white")
+					})
+					apidsl.Attribute("tags", apidsl.ArrayOf(String), func() {
+						apidsl.MinLength(1)
+					})
+					apidsl.Required("id")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("color")
+					apidsl.Attribute("tags")
+				})
+			})
+			apidsl.API("cellar", func() {
+				apidsl.BasePath("/api")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Metadata("pact:state", "bottle 1 exists")
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", Integer, func() {
+							apidsl.Example(1)
+						})
+					})
+					apidsl.Response(OK, bottle)
+					apidsl.Response(NotFound, func() {
+						apidsl.Metadata("pact:state", "bottle 1 does not exist")
+					})
+					apidsl.Response(BadRequest, ErrorMedia)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(func() {
+						apidsl.Attribute("name", String, func() {
+							apidsl.Example("Number 8")
+						})
+					})
+					apidsl.Response(Created)
+				})
+			})
+		})
+
+		It("describes the pact", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(pact.Consumer.Name).Should(Equal("web"))
+			Ω(pact.Provider.Name).Should(Equal("cellar"))
+			Ω(pact.Metadata.PactSpecification.Version).Should(Equal(genpact.SpecificationVersion))
+			Ω(pact.FileName()).Should(Equal("web-cellar.json"))
+		})
+
+		It("builds one interaction per success response and state", func() {
+			Ω(pact.Interactions).Should(HaveLen(3))
+			var descs []string
+			for _, i := range pact.Interactions {
+				descs = append(descs, i.Description)
+			}
+			Ω(descs).Should(ConsistOf("show bottle: OK", "show bottle: NotFound", "create bottle: Created"))
+		})
+
+		It("builds the interactions of the action", func() {
+			var ok, notFound *genpact.Interaction
+			for _, i := range pact.Interactions {
+				switch i.Description {
+				case "show bottle: OK":
+					ok = i
+				case "show bottle: NotFound":
+					notFound = i
+				}
+			}
+			Ω(ok).ShouldNot(BeNil())
+			Ω(ok.ProviderStates).Should(HaveLen(1))
+			Ω(ok.ProviderStates[0].Name).Should(Equal("bottle 1 exists"))
+			Ω(ok.Request.Method).Should(Equal("GET"))
+			Ω(ok.Request.Path).Should(Equal("/api/bottles/1"))
+			Ω(ok.Response.Status).Should(Equal(200))
+			Ω(ok.Response.Headers).Should(HaveKeyWithValue("Content-Type", "application/vnd.bottle+json"))
+			Ω(ok.Response.Body).Should(HaveKey("id"))
+
+			rules := ok.Response.MatchingRules
+			Ω(rules).ShouldNot(BeNil())
+			Ω(rules.Header["Content-Type"].Matchers[0].Regex).Should(Equal(`^application/vnd\.bottle\+json(;.*)?$`))
+			Ω(rules.Body["$"].Matchers[0].Match).Should(Equal("type"))
+			Ω(rules.Body["$.id"].Matchers[0].Match).Should(Equal("integer"))
+			Ω(rules.Body["$.color"].Matchers[0].Regex).Should(Equal("^(red|white)$"))
+			Ω(*rules.Body["$.tags"].Matchers[0].Min).Should(Equal(1))
+
+			Ω(notFound).ShouldNot(BeNil())
+			Ω(notFound.ProviderStates[0].Name).Should(Equal("bottle 1 does not exist"))
+			Ω(notFound.Response.Status).Should(Equal(404))
+			Ω(notFound.Response.Body).Should(BeNil())
+		})
+
+		It("sends the payload example", func() {
+			var create *genpact.Interaction
+			for _, i := range pact.Interactions {
+				if i.Description == "create bottle: Created" {
+					create = i
+				}
+			}
+			Ω(create).ShouldNot(BeNil())
+			Ω(create.ProviderStates).Should(BeEmpty())
+			Ω(create.Request.Headers).Should(HaveKeyWithValue("Content-Type", "application/json"))
+			Ω(create.Request.Body).Should(HaveKeyWithValue("name", "Number 8"))
+		})
+	})
+})
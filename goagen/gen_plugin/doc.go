@@ -0,0 +1,29 @@
+/*
+Package genplugin runs generator plugins: prebuilt executables that produce artifacts from a
+serialized design. Contrary to the generators run with "goagen gen" plugins do not need to be
+compiled against goagen or the design package and may be written in any language.
+
+goagen runs the plugin with the arguments given after "--" on the command line and writes a JSON
+encoded Input message holding the serialized design to its standard input:
+
+	{
+		"version": 1,
+		"goaVersion": "1.5.0",
+		"args": ["--flag"],
+		"design": {"name": "cellar", "resources": [...], "types": [...], "mediaTypes": [...]}
+	}
+
+The plugin writes a JSON encoded Output message listing the generated files to its standard
+output and exits with status 0:
+
+	{
+		"files": [
+			{"path": "docs/index.md", "content": "# cellar\n"}
+		]
+	}
+
+The file paths are slash separated and relative to the output directory. A plugin that fails
+either exits with a non-zero status, in which case its standard error is reported, or sets the
+"error" field of the output message. No file is written if the plugin fails.
+*/
+package genplugin
@@ -0,0 +1,13 @@
+package genplugin_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenPlugin(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenPlugin Suite")
+}
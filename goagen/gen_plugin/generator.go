@@ -0,0 +1,154 @@
+package genplugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/goagen/utils"
+	"github.com/kyokomi/goa-v1/version"
+)
+
+// NewGenerator returns an initialized instance of a Plugin Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the plugin generator, it runs a prebuilt plugin executable.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Command  string                // Path to plugin executable
+	Args     []string              // Plugin command line arguments
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, command, ver string
+
+	set := flag.NewFlagSet("plugin", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&command, "cmd", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	// First check compatibility
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	// Now proceed
+	g := &Generator{OutDir: outDir, Command: command, Args: set.Args(), API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate runs the plugin and writes the files it generates.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+	if g.Command == "" {
+		return nil, fmt.Errorf("missing plugin executable, specify it with --cmd")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	out, err := g.run()
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate all paths before writing anything
+	paths := make([]string, len(out.Files))
+	for i, f := range out.Files {
+		p, err := g.filePath(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		paths[i] = p
+	}
+	for i, f := range out.Files {
+		if err = os.MkdirAll(filepath.Dir(paths[i]), 0755); err != nil {
+			return nil, err
+		}
+		if err = codegen.WriteFile(paths[i], []byte(f.Content), 0644); err != nil {
+			return nil, err
+		}
+		g.genfiles = append(g.genfiles, paths[i])
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// run runs the plugin executable writing the serialized design to its standard input and returns
+// the output it writes to its standard output.
+func (g *Generator) run() (*Output, error) {
+	in, err := json.Marshal(&Input{
+		Version:    ProtocolVersion,
+		GoaVersion: version.String(),
+		Args:       g.Args,
+		Design:     New(g.API),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(g.Command, g.Args...)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("plugin %s failed: %s\n%s", g.Command, err, stderr.String())
+	}
+	var out Output
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, fmt.Errorf("plugin %s: invalid output: %s", g.Command, err)
+	}
+	if out.Error != "" {
+		return nil, fmt.Errorf("plugin %s: %s", g.Command, out.Error)
+	}
+	return &out, nil
+}
+
+// filePath returns the absolute path of the generated file with the given slash separated path.
+// It returns an error if the path is absolute or points outside of the output directory.
+func (g *Generator) filePath(p string) (string, error) {
+	clean := path.Clean(p)
+	if p == "" || clean == "." || path.IsAbs(clean) || filepath.IsAbs(p) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("plugin %s: invalid file path %#v, must be relative to the output directory", g.Command, p)
+	}
+	return filepath.Join(g.OutDir, filepath.FromSlash(clean)), nil
+}
@@ -0,0 +1,204 @@
+package genplugin_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	genplugin "github.com/kyokomi/goa-v1/goagen/gen_plugin"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genplugin.Generator
+
+	var args = struct {
+		api     *design.APIDefinition
+		outDir  string
+		command string
+		args    []string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir:  "out_dir",
+		command: "plugin",
+		args:    []string{"--flag"},
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genplugin.NewGenerator(
+				genplugin.API(args.api),
+				genplugin.OutDir(args.outDir),
+				genplugin.Command(args.command),
+				genplugin.Args(args.args...),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Command).Should(Equal(args.command))
+			Ω(generator.Args).Should(Equal(args.args))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir, plugin string
+	var pluginArgs []string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(workspace.Path, "")
+		Ω(err).ShouldNot(HaveOccurred())
+		pkg, err := workspace.NewPackage("docplugin")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ioutil.WriteFile(filepath.Join(pkg.Abs(), "go.mod"), []byte("module docplugin\n"), 0644)).Should(Succeed())
+		Ω(ioutil.WriteFile(filepath.Join(pkg.Abs(), "main.go"), []byte(pluginSource), 0644)).Should(Succeed())
+		plugin, err = pkg.Compile("plugin")
+		Ω(err).ShouldNot(HaveOccurred())
+		pluginArgs = nil
+
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Response(design.OK)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genplugin.NewGenerator(
+			genplugin.API(design.Design),
+			genplugin.OutDir(outDir),
+			genplugin.Command(plugin),
+			genplugin.Args(pluginArgs...),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("writes the files generated by the plugin", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(Equal([]string{filepath.Join(outDir, "docs", "index.md")}))
+		content, err := ioutil.ReadFile(files[0])
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(Equal("# cellar\n\n- bottle#show GET /bottles/:id\n"))
+	})
+
+	Context("with a plugin reporting an error", func() {
+		BeforeEach(func() {
+			pluginArgs = []string{"error"}
+		})
+
+		It("returns the error", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(ContainSubstring("design not supported"))
+			Ω(files).Should(BeEmpty())
+		})
+	})
+
+	Context("with a plugin that fails", func() {
+		BeforeEach(func() {
+			pluginArgs = []string{"fail"}
+		})
+
+		It("returns the standard error of the plugin", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(ContainSubstring("failed"))
+			Ω(genErr.Error()).Should(ContainSubstring("something went wrong"))
+		})
+	})
+
+	Context("with a plugin generating a file outside of the output directory", func() {
+		BeforeEach(func() {
+			pluginArgs = []string{"escape"}
+		})
+
+		It("returns an error and writes no file", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(ContainSubstring("invalid file path"))
+			entries, err := ioutil.ReadDir(outDir)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(entries).Should(BeEmpty())
+		})
+	})
+})
+
+// pluginSource is the source code of a plugin that lists the action routes.
+const pluginSource = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type input struct {
+	Version int
+	Args    []string
+	Design  struct {
+		Name      string
+		Resources []struct {
+			Name    string
+			Actions []struct {
+				Name   string
+				Routes []struct{ Method, Path string }
+			}
+		}
+	}
+}
+
+type file struct {
+	Path    string ` + "`json:\"path\"`" + `
+	Content string ` + "`json:\"content\"`" + `
+}
+
+func main() {
+	var in input
+	if err := json.NewDecoder(os.Stdin).Decode(&in); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	out := map[string]interface{}{}
+	switch {
+	case len(os.Args) > 1 && os.Args[1] == "error":
+		out["error"] = "design not supported"
+	case len(os.Args) > 1 && os.Args[1] == "fail":
+		fmt.Fprintln(os.Stderr, "something went wrong")
+		os.Exit(1)
+	case len(os.Args) > 1 && os.Args[1] == "escape":
+		out["files"] = []file{{Path: "ok.md"}, {Path: "../escape.md"}}
+	default:
+		content := "# " + in.Design.Name + "\n\n"
+		for _, r := range in.Design.Resources {
+			for _, a := range r.Actions {
+				for _, rt := range a.Routes {
+					content += "- " + r.Name + "#" + a.Name + " " + rt.Method + " " + rt.Path + "\n"
+				}
+			}
+		}
+		out["files"] = []file{{Path: "docs/index.md", Content: content}}
+	}
+	json.NewEncoder(os.Stdout).Encode(out)
+}
+`
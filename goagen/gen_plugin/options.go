@@ -0,0 +1,34 @@
+package genplugin
+
+import "github.com/kyokomi/goa-v1/design"
+
+// Option a generator option definition
+type Option func(*Generator)
+
+// API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+// OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+// Command Path to plugin executable
+func Command(command string) Option {
+	return func(g *Generator) {
+		g.Command = command
+	}
+}
+
+// Args Plugin command line arguments
+func Args(args ...string) Option {
+	return func(g *Generator) {
+		g.Args = args
+	}
+}
@@ -0,0 +1,432 @@
+package genplugin
+
+import (
+	"sort"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+// ProtocolVersion is the version of the plugin protocol implemented by goagen. It is incremented
+// each time a change that is not backward compatible is made to the messages.
+const ProtocolVersion = 1
+
+type (
+	// Input is the message written by goagen to the standard input of the plugin.
+	Input struct {
+		// Version is the protocol version.
+		Version int `json:"version"`
+		// GoaVersion is the version of goagen running the plugin.
+		GoaVersion string `json:"goaVersion"`
+		// Args lists the arguments given after "--" on the goagen command line, they are also
+		// given to the plugin as command line arguments.
+		Args []string `json:"args,omitempty"`
+		// Design is the serialized design.
+		Design *Design `json:"design"`
+	}
+
+	// Output is the message written by the plugin to its standard output.
+	Output struct {
+		// Files lists the generated files.
+		Files []*File `json:"files,omitempty"`
+		// Error describes the reason why the plugin failed if any, no file is written if
+		// it is not empty.
+		Error string `json:"error,omitempty"`
+	}
+
+	// File is a file generated by the plugin.
+	File struct {
+		// Path is the slash separated path of the file relative to the output directory,
+		// it may not point outside of the output directory.
+		Path string `json:"path"`
+		// Content is the content of the file.
+		Content string `json:"content"`
+	}
+
+	// Design is the serialized API definition.
+	Design struct {
+		Name        string   `json:"name"`
+		Title       string   `json:"title,omitempty"`
+		Description string   `json:"description,omitempty"`
+		Version     string   `json:"version,omitempty"`
+		Host        string   `json:"host,omitempty"`
+		Schemes     []string `json:"schemes,omitempty"`
+		BasePath    string   `json:"basePath,omitempty"`
+		Consumes    []string `json:"consumes,omitempty"`
+		Produces    []string `json:"produces,omitempty"`
+		// Resources lists the resources sorted by name.
+		Resources []*Resource `json:"resources,omitempty"`
+		// Types lists the user types sorted by name.
+		Types []*UserType `json:"types,omitempty"`
+		// MediaTypes lists the media types sorted by identifier.
+		MediaTypes []*MediaType `json:"mediaTypes,omitempty"`
+		// SecuritySchemes lists the security schemes in order of definition.
+		SecuritySchemes []*SecurityScheme `json:"securitySchemes,omitempty"`
+		// Metadata lists the metadata of the API.
+		Metadata map[string][]string `json:"metadata,omitempty"`
+	}
+
+	// Resource is a serialized resource definition.
+	Resource struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+		BasePath    string `json:"basePath,omitempty"`
+		ParentName  string `json:"parentName,omitempty"`
+		// MediaType is the identifier of the default media type of the resource.
+		MediaType string `json:"mediaType,omitempty"`
+		// Actions lists the actions sorted by name.
+		Actions  []*Action           `json:"actions,omitempty"`
+		Metadata map[string][]string `json:"metadata,omitempty"`
+	}
+
+	// Action is a serialized action definition.
+	Action struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description,omitempty"`
+		Routes      []*Route `json:"routes,omitempty"`
+		// Params describes the path and query string parameters, it includes the parameters
+		// inherited from the API and the resource.
+		Params *Attribute `json:"params,omitempty"`
+		// Headers describes the request headers, it includes the headers inherited from the
+		// resource.
+		Headers *Attribute `json:"headers,omitempty"`
+		// Payload describes the request body if any, its definition is inlined.
+		Payload          *Attribute `json:"payload,omitempty"`
+		PayloadOptional  bool       `json:"payloadOptional,omitempty"`
+		PayloadMultipart bool       `json:"payloadMultipart,omitempty"`
+		// Responses lists the responses sorted by status.
+		Responses []*Response `json:"responses,omitempty"`
+		// Security describes the security scheme and scopes required by the action, nil if
+		// the action is not secured.
+		Security *Security           `json:"security,omitempty"`
+		Metadata map[string][]string `json:"metadata,omitempty"`
+	}
+
+	// Route is a serialized action route.
+	Route struct {
+		Method string `json:"method"`
+		// Path is the full path of the route including the API and resource base paths.
+		Path string `json:"path"`
+	}
+
+	// Response is a serialized response definition.
+	Response struct {
+		Name        string `json:"name"`
+		Status      int    `json:"status"`
+		Description string `json:"description,omitempty"`
+		// MediaType is the identifier of the response media type if any.
+		MediaType string `json:"mediaType,omitempty"`
+		// View is the name of the view used to render the response media type if any.
+		View string `json:"view,omitempty"`
+		// Type describes the response body when it is not a media type.
+		Type     *Attribute          `json:"type,omitempty"`
+		Headers  *Attribute          `json:"headers,omitempty"`
+		Metadata map[string][]string `json:"metadata,omitempty"`
+	}
+
+	// Security is a serialized security requirement.
+	Security struct {
+		Scheme string   `json:"scheme"`
+		Scopes []string `json:"scopes,omitempty"`
+	}
+
+	// SecurityScheme is a serialized security scheme definition.
+	SecurityScheme struct {
+		Name string `json:"name"`
+		// Kind is one of "basic", "apiKey", "jwt" or "oauth2".
+		Kind        string `json:"kind"`
+		Description string `json:"description,omitempty"`
+		// In is where the key or token is read from, "header" or "query".
+		In string `json:"in,omitempty"`
+		// KeyName is the name of the header or query string parameter holding the key or
+		// token.
+		KeyName string `json:"keyName,omitempty"`
+	}
+
+	// UserType is a serialized user type definition.
+	UserType struct {
+		Name string `json:"name"`
+		// Attribute describes the type.
+		Attribute *Attribute `json:"attribute"`
+	}
+
+	// MediaType is a serialized media type definition.
+	MediaType struct {
+		Identifier  string `json:"identifier"`
+		TypeName    string `json:"typeName"`
+		ContentType string `json:"contentType,omitempty"`
+		// Attribute describes the media type attributes.
+		Attribute *Attribute `json:"attribute"`
+		// Views maps the view names to the names of the attributes they render.
+		Views map[string][]string `json:"views,omitempty"`
+		// Links lists the names of the links.
+		Links []string `json:"links,omitempty"`
+	}
+
+	// Attribute is a serialized attribute definition. User types and media types are not
+	// inlined: Ref holds the name of the user type or the identifier of the media type whose
+	// definition is listed in the API.
+	Attribute struct {
+		// Type is the name of the attribute type: "boolean", "integer", "number",
+		// "string", "datetime", "uuid", "decimal", "any", "file", "array", "hash",
+		// "object", "user" or "media" and the JSON type of custom primitive types.
+		Type string `json:"type"`
+		// Ref is the name of the user type or identifier of the media type if Type is
+		// "user" or "media".
+		Ref string `json:"ref,omitempty"`
+		// View is the view used to render the media type if Type is "media".
+		View        string `json:"view,omitempty"`
+		Description string `json:"description,omitempty"`
+		// Elem describes the array elements or the hash values.
+		Elem *Attribute `json:"elem,omitempty"`
+		// Key describes the hash keys.
+		Key *Attribute `json:"key,omitempty"`
+		// Fields lists the object fields sorted by name.
+		Fields []*Field `json:"fields,omitempty"`
+		// Required lists the names of the required fields.
+		Required  []string            `json:"required,omitempty"`
+		Default   interface{}         `json:"default,omitempty"`
+		Example   interface{}         `json:"example,omitempty"`
+		Enum      []interface{}       `json:"enum,omitempty"`
+		Format    string              `json:"format,omitempty"`
+		Pattern   string              `json:"pattern,omitempty"`
+		Minimum   *float64            `json:"minimum,omitempty"`
+		Maximum   *float64            `json:"maximum,omitempty"`
+		MinLength *int                `json:"minLength,omitempty"`
+		MaxLength *int                `json:"maxLength,omitempty"`
+		Metadata  map[string][]string `json:"metadata,omitempty"`
+	}
+
+	// Field is a serialized object field.
+	Field struct {
+		Name      string     `json:"name"`
+		Attribute *Attribute `json:"attribute"`
+	}
+)
+
+// New serializes the given API definition.
+func New(api *design.APIDefinition) *Design {
+	a := &Design{
+		Name:        api.Name,
+		Title:       api.Title,
+		Description: api.Description,
+		Version:     api.Version,
+		Host:        api.Host,
+		Schemes:     api.Schemes,
+		BasePath:    api.BasePath,
+		Consumes:    mimeTypes(api.Consumes),
+		Produces:    mimeTypes(api.Produces),
+		Metadata:    api.Metadata,
+	}
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		a.Resources = append(a.Resources, resource(api, r))
+		return nil
+	})
+	api.IterateUserTypes(func(t *design.UserTypeDefinition) error {
+		a.Types = append(a.Types, &UserType{Name: t.TypeName, Attribute: attribute(t.AttributeDefinition)})
+		return nil
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		a.MediaTypes = append(a.MediaTypes, mediaType(mt))
+		return nil
+	})
+	for _, s := range api.SecuritySchemes {
+		a.SecuritySchemes = append(a.SecuritySchemes, securityScheme(s))
+	}
+	return a
+}
+
+// resource serializes the given resource definition.
+func resource(api *design.APIDefinition, r *design.ResourceDefinition) *Resource {
+	res := &Resource{
+		Name:        r.Name,
+		Description: r.Description,
+		BasePath:    r.BasePath,
+		ParentName:  r.ParentName,
+		MediaType:   r.MediaType,
+		Metadata:    r.Metadata,
+	}
+	r.IterateActions(func(a *design.ActionDefinition) error {
+		res.Actions = append(res.Actions, action(api, a))
+		return nil
+	})
+	return res
+}
+
+// action serializes the given action definition.
+func action(api *design.APIDefinition, a *design.ActionDefinition) *Action {
+	act := &Action{
+		Name:             a.Name,
+		Description:      a.Description,
+		Params:           attribute(a.AllParams()),
+		Headers:          attribute(headers(a)),
+		PayloadOptional:  a.PayloadOptional,
+		PayloadMultipart: a.PayloadMultipart,
+		Metadata:         a.Metadata,
+	}
+	for _, r := range a.Routes {
+		act.Routes = append(act.Routes, &Route{Method: r.Verb, Path: r.FullPath()})
+	}
+	if a.Payload != nil {
+		act.Payload = attribute(a.Payload.AttributeDefinition)
+	}
+	a.IterateResponses(func(r *design.ResponseDefinition) error {
+		act.Responses = append(act.Responses, response(r))
+		return nil
+	})
+	sort.SliceStable(act.Responses, func(i, j int) bool { return act.Responses[i].Status < act.Responses[j].Status })
+	if sec := security(api, a); sec != nil && sec.Scheme != nil {
+		act.Security = &Security{Scheme: sec.Scheme.SchemeName, Scopes: sec.Scopes}
+	}
+	return act
+}
+
+// headers returns the request headers of the action including the headers inherited from its
+// resource.
+func headers(a *design.ActionDefinition) *design.AttributeDefinition {
+	headers := a.Headers
+	if a.Parent != nil && a.Parent.Headers != nil {
+		headers = a.Parent.Headers.Merge(headers)
+	}
+	return headers
+}
+
+// security returns the security requirement of the action, nil if the action is not secured.
+func security(api *design.APIDefinition, a *design.ActionDefinition) *design.SecurityDefinition {
+	if a.Security != nil {
+		return a.Security
+	}
+	if a.Parent != nil && a.Parent.Security != nil {
+		return a.Parent.Security
+	}
+	return api.Security
+}
+
+// response serializes the given response definition.
+func response(r *design.ResponseDefinition) *Response {
+	resp := &Response{
+		Name:        r.Name,
+		Status:      r.Status,
+		Description: r.Description,
+		MediaType:   r.MediaType,
+		View:        r.ViewName,
+		Headers:     attribute(r.Headers),
+		Metadata:    r.Metadata,
+	}
+	if r.Type != nil {
+		if mt, ok := r.Type.(*design.MediaTypeDefinition); ok {
+			resp.MediaType = mt.Identifier
+		} else {
+			resp.Type = attribute(&design.AttributeDefinition{Type: r.Type})
+		}
+	}
+	return resp
+}
+
+// securityScheme serializes the given security scheme definition.
+func securityScheme(s *design.SecuritySchemeDefinition) *SecurityScheme {
+	kind := s.Type
+	if s.Kind == design.JWTSecurityKind {
+		kind = "jwt"
+	}
+	return &SecurityScheme{
+		Name:        s.SchemeName,
+		Kind:        kind,
+		Description: s.Description,
+		In:          s.In,
+		KeyName:     s.Name,
+	}
+}
+
+// mediaType serializes the given media type definition.
+func mediaType(mt *design.MediaTypeDefinition) *MediaType {
+	m := &MediaType{
+		Identifier:  mt.Identifier,
+		TypeName:    mt.TypeName,
+		ContentType: mt.ContentType,
+		Attribute:   attribute(mt.AttributeDefinition),
+	}
+	mt.IterateViews(func(v *design.ViewDefinition) error {
+		if m.Views == nil {
+			m.Views = make(map[string][]string)
+		}
+		names := []string{}
+		if o := v.Type.ToObject(); o != nil {
+			names = codegen.SortedNames(o)
+		}
+		m.Views[v.Name] = names
+		return nil
+	})
+	mt.IterateLinks(func(l *design.LinkDefinition) error {
+		m.Links = append(m.Links, l.Name)
+		return nil
+	})
+	return m
+}
+
+// attribute serializes the given attribute definition.
+func attribute(att *design.AttributeDefinition) *Attribute {
+	if att == nil || att.Type == nil {
+		return nil
+	}
+	a := &Attribute{
+		Description: att.Description,
+		Default:     att.DefaultValue,
+		Example:     att.Example,
+		View:        att.View,
+		Metadata:    att.Metadata,
+	}
+	if v := att.Validation; v != nil {
+		a.Required = v.Required
+		a.Enum = v.Values
+		a.Format = v.Format
+		a.Pattern = v.Pattern
+		a.Minimum = v.Minimum
+		a.Maximum = v.Maximum
+		a.MinLength = v.MinLength
+		a.MaxLength = v.MaxLength
+	}
+	switch actual := att.Type.(type) {
+	case *design.MediaTypeDefinition:
+		a.Type = "media"
+		a.Ref = actual.Identifier
+	case *design.UserTypeDefinition:
+		a.Type = "user"
+		a.Ref = actual.TypeName
+	case *design.Array:
+		a.Type = "array"
+		a.Elem = attribute(actual.ElemType)
+	case *design.Hash:
+		a.Type = "hash"
+		a.Key = attribute(actual.KeyType)
+		a.Elem = attribute(actual.ElemType)
+	case design.Object:
+		a.Type = "object"
+		for _, n := range codegen.SortedNames(actual) {
+			a.Fields = append(a.Fields, &Field{Name: n, Attribute: attribute(actual[n])})
+		}
+	case design.Primitive:
+		switch actual.Kind() {
+		case design.DateTimeKind:
+			a.Type = "datetime"
+		case design.UUIDKind:
+			a.Type = "uuid"
+		case design.DecimalKind:
+			a.Type = "decimal"
+		default:
+			a.Type = actual.Name()
+		}
+	default:
+		a.Type = att.Type.Name()
+	}
+	return a
+}
+
+// mimeTypes returns the MIME types of the given encodings.
+func mimeTypes(encs []*design.EncodingDefinition) []string {
+	var mimes []string
+	for _, e := range encs {
+		mimes = append(mimes, e.MIMETypes...)
+	}
+	return mimes
+}
@@ -0,0 +1,142 @@
+package genplugin_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genplugin "github.com/kyokomi/goa-v1/goagen/gen_plugin"
+)
+
+var _ = Describe("New", func() {
+	var api *genplugin.Design
+
+	BeforeEach(func() {
+		api = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		api = genplugin.New(Design)
+	})
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			jwt := apidsl.JWTSecurity("jwt", func() {
+				apidsl.Header("Authorization")
+				apidsl.Scope("api:read")
+			})
+			bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer)
+					apidsl.Attribute("name", String)
+					apidsl.Attribute("tags", apidsl.HashOf(String, Integer))
+					apidsl.Required("id")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+				})
+				apidsl.View("tiny", func() {
+					apidsl.Attribute("id")
+				})
+			})
+			apidsl.API("cellar", func() {
+				apidsl.Title("Cellar API")
+				apidsl.BasePath("/api")
+				apidsl.Consumes("application/json")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("update", func() {
+					apidsl.Routing(apidsl.PUT("/:id"))
+					apidsl.Security(jwt, func() {
+						apidsl.Scope("api:read")
+					})
+					apidsl.Params(func() {
+						apidsl.Param("id", Integer, func() {
+							apidsl.Minimum(1)
+						})
+					})
+					apidsl.Payload(func() {
+						apidsl.Member("name", String, func() {
+							apidsl.Enum("a", "b")
+						})
+						apidsl.Member("vintage", DateTime)
+						apidsl.Required("name")
+					})
+					apidsl.Response(NoContent)
+					apidsl.Response(OK, bottle)
+				})
+			})
+		})
+
+		It("serializes the API and its resources", func() {
+			Ω(api.Name).Should(Equal("cellar"))
+			Ω(api.Title).Should(Equal("Cellar API"))
+			Ω(api.Consumes).Should(Equal([]string{"application/json"}))
+			Ω(api.Resources).Should(HaveLen(1))
+			Ω(api.Resources[0].Actions).Should(HaveLen(1))
+
+			a := api.Resources[0].Actions[0]
+			Ω(a.Name).Should(Equal("update"))
+			Ω(a.Routes).Should(HaveLen(1))
+			Ω(a.Routes[0].Method).Should(Equal("PUT"))
+			Ω(a.Routes[0].Path).Should(Equal("/api/bottles/:id"))
+			Ω(a.Params.Type).Should(Equal("object"))
+			Ω(a.Params.Fields[0].Name).Should(Equal("id"))
+			Ω(*a.Params.Fields[0].Attribute.Minimum).Should(Equal(1.0))
+			Ω(a.Payload.Type).Should(Equal("object"))
+			Ω(a.Payload.Required).Should(Equal([]string{"name"}))
+			Ω(a.Payload.Fields).Should(HaveLen(2))
+			Ω(a.Payload.Fields[0].Attribute.Enum).Should(Equal([]interface{}{"a", "b"}))
+			Ω(a.Payload.Fields[1].Attribute.Type).Should(Equal("datetime"))
+			Ω(a.Security.Scheme).Should(Equal("jwt"))
+			Ω(a.Security.Scopes).Should(Equal([]string{"api:read"}))
+
+			Ω(a.Responses).Should(HaveLen(2))
+			Ω(a.Responses[0].Status).Should(Equal(200))
+			Ω(a.Responses[0].MediaType).Should(Equal("application/vnd.bottle+json"))
+			Ω(a.Responses[1].Status).Should(Equal(204))
+		})
+
+		It("serializes the media types", func() {
+			var mt *genplugin.MediaType
+			for _, m := range api.MediaTypes {
+				if m.Identifier == "application/vnd.bottle+json" {
+					mt = m
+				}
+			}
+			Ω(mt).ShouldNot(BeNil())
+			Ω(mt.TypeName).Should(Equal("Bottle"))
+			Ω(mt.Views).Should(Equal(map[string][]string{"default": {"id", "name"}, "tiny": {"id"}}))
+			Ω(mt.Attribute.Required).Should(Equal([]string{"id"}))
+			tags := mt.Attribute.Fields[2]
+			Ω(tags.Name).Should(Equal("tags"))
+			Ω(tags.Attribute.Type).Should(Equal("hash"))
+			Ω(tags.Attribute.Key.Type).Should(Equal("string"))
+			Ω(tags.Attribute.Elem.Type).Should(Equal("integer"))
+		})
+
+		It("serializes the security schemes", func() {
+			Ω(api.SecuritySchemes).Should(HaveLen(1))
+			Ω(api.SecuritySchemes[0].Name).Should(Equal("jwt"))
+			Ω(api.SecuritySchemes[0].Kind).Should(Equal("jwt"))
+			Ω(api.SecuritySchemes[0].In).Should(Equal("header"))
+			Ω(api.SecuritySchemes[0].KeyName).Should(Equal("Authorization"))
+		})
+
+		It("serializes into JSON", func() {
+			b, err := json.Marshal(api)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(ContainSubstring(`"routes":[{"method":"PUT","path":"/api/bottles/:id"}]`))
+		})
+	})
+})
@@ -0,0 +1,10 @@
+/*
+Package genpostman provides a generator for a Postman collection of the API. The generated
+collection follows the v2.1 schema and contains a folder per resource with one request per action
+route. The requests are pre-filled with the examples of the design and use the "scheme", "host"
+and "basePath" variables so that the same collection can target different deployments, their
+authentication derives from the security schemes of the actions. The generator also produces a
+Postman environment that defines these variables and the credentials of the security schemes.
+See https://schema.postman.com for more information on the collection format.
+*/
+package genpostman
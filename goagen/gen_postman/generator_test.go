@@ -0,0 +1,38 @@
+package genpostman_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	genpostman "github.com/kyokomi/goa-v1/goagen/gen_postman"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genpostman.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genpostman.NewGenerator(
+				genpostman.API(args.api),
+				genpostman.OutDir(args.outDir),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+		})
+	})
+})
@@ -0,0 +1,471 @@
+package genpostman
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+// SchemaURL is the URL of the JSON schema of the generated collections.
+const SchemaURL = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+type (
+	// Collection represents a Postman collection.
+	// See https://schema.postman.com/collection/json/v2.1.0/draft-07/docs/index.html
+	Collection struct {
+		Info     *Info       `json:"info"`
+		Item     []*Item     `json:"item"`
+		Variable []*Variable `json:"variable,omitempty"`
+	}
+
+	// Info provides metadata about the collection.
+	Info struct {
+		// Name of the collection.
+		Name string `json:"name"`
+		// Description of the collection.
+		Description string `json:"description,omitempty"`
+		// Schema is the URL of the collection JSON schema.
+		Schema string `json:"schema"`
+	}
+
+	// Item is either a folder that groups the requests of a resource or a request.
+	Item struct {
+		// Name of the folder or request.
+		Name string `json:"name"`
+		// Description of the folder.
+		Description string `json:"description,omitempty"`
+		// Item lists the items of the folder.
+		Item []*Item `json:"item,omitempty"`
+		// Request describes the request.
+		Request *Request `json:"request,omitempty"`
+	}
+
+	// Request describes a HTTP request.
+	Request struct {
+		// Method is the HTTP method of the request.
+		Method string `json:"method"`
+		// Description of the request.
+		Description string `json:"description,omitempty"`
+		// Header lists the request headers.
+		Header []*KeyValue `json:"header"`
+		// URL of the request.
+		URL *URL `json:"url"`
+		// Body of the request.
+		Body *Body `json:"body,omitempty"`
+		// Auth describes the authentication of the request.
+		Auth *Auth `json:"auth,omitempty"`
+	}
+
+	// URL describes the URL of a request.
+	URL struct {
+		// Raw is the string representation of the URL.
+		Raw string `json:"raw"`
+		// Protocol is the URL scheme.
+		Protocol string `json:"protocol"`
+		// Host lists the host segments.
+		Host []string `json:"host"`
+		// Path lists the path segments, path variables are prefixed with ":".
+		Path []string `json:"path"`
+		// Query lists the query string parameters.
+		Query []*KeyValue `json:"query,omitempty"`
+		// Variable lists the path variables.
+		Variable []*KeyValue `json:"variable,omitempty"`
+	}
+
+	// KeyValue describes a header, query string parameter, path variable, form parameter or
+	// authentication attribute.
+	KeyValue struct {
+		Key         string `json:"key"`
+		Value       string `json:"value"`
+		Type        string `json:"type,omitempty"`
+		Description string `json:"description,omitempty"`
+		Disabled    bool   `json:"disabled,omitempty"`
+	}
+
+	// Body describes a request body.
+	Body struct {
+		// Mode is "raw" for JSON bodies and "formdata" for multipart bodies.
+		Mode string `json:"mode"`
+		// Raw is the content of raw bodies.
+		Raw string `json:"raw,omitempty"`
+		// FormData lists the parts of multipart bodies.
+		FormData []*KeyValue `json:"formdata,omitempty"`
+		// Options describes the language of raw bodies.
+		Options *BodyOptions `json:"options,omitempty"`
+	}
+
+	// BodyOptions describes the language of raw bodies.
+	BodyOptions struct {
+		Raw *RawOptions `json:"raw"`
+	}
+
+	// RawOptions describes the language of raw bodies.
+	RawOptions struct {
+		Language string `json:"language"`
+	}
+
+	// Auth describes the authentication of a request, only the field that corresponds to the
+	// type is set.
+	Auth struct {
+		Type   string      `json:"type"`
+		Basic  []*KeyValue `json:"basic,omitempty"`
+		APIKey []*KeyValue `json:"apikey,omitempty"`
+		Bearer []*KeyValue `json:"bearer,omitempty"`
+		OAuth2 []*KeyValue `json:"oauth2,omitempty"`
+	}
+
+	// Variable describes a collection variable.
+	Variable struct {
+		Key         string `json:"key"`
+		Value       string `json:"value"`
+		Type        string `json:"type"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// Environment represents a Postman environment.
+	Environment struct {
+		Name   string              `json:"name"`
+		Values []*EnvironmentValue `json:"values"`
+		Scope  string              `json:"_postman_variable_scope"`
+	}
+
+	// EnvironmentValue describes an environment variable.
+	EnvironmentValue struct {
+		Key     string `json:"key"`
+		Value   string `json:"value"`
+		Type    string `json:"type"`
+		Enabled bool   `json:"enabled"`
+	}
+)
+
+// New creates the Postman collection of the API. The collection contains a folder per resource
+// with one request per action route, the requests use the "scheme", "host" and "basePath"
+// variables and the authentication variables of the security schemes.
+func New(api *design.APIDefinition) (*Collection, error) {
+	if api == nil {
+		return nil, nil
+	}
+	name := api.Title
+	if name == "" {
+		name = api.Name
+	}
+	c := &Collection{
+		Info: &Info{
+			Name:        name,
+			Description: api.Description,
+			Schema:      SchemaURL,
+		},
+		Item:     []*Item{},
+		Variable: variables(api),
+	}
+	basePath := strings.Trim(api.BasePath, "/")
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		folder := &Item{Name: res.Name, Description: res.Description}
+		err := res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() {
+				return nil
+			}
+			for i, r := range a.Routes {
+				item, err := requestItem(api, a, r, basePath)
+				if err != nil {
+					return err
+				}
+				if len(a.Routes) > 1 {
+					item.Name = fmt.Sprintf("%s (%d)", a.Name, i+1)
+				}
+				folder.Item = append(folder.Item, item)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(folder.Item) > 0 {
+			c.Item = append(c.Item, folder)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewEnvironment creates the Postman environment that defines the variables used by the
+// collection of the API.
+func NewEnvironment(api *design.APIDefinition) *Environment {
+	env := &Environment{Name: api.Name, Scope: "environment"}
+	for _, v := range variables(api) {
+		typ := "default"
+		if v.Value == "" {
+			typ = "secret"
+		}
+		env.Values = append(env.Values, &EnvironmentValue{
+			Key:     v.Key,
+			Value:   v.Value,
+			Type:    typ,
+			Enabled: true,
+		})
+	}
+	return env
+}
+
+// variables returns the collection variables: the scheme, host and base path of the API and
+// the credentials of the security schemes. The credentials have no default value.
+func variables(api *design.APIDefinition) []*Variable {
+	scheme := "http"
+	if len(api.Schemes) > 0 {
+		scheme = api.Schemes[0]
+	}
+	host := api.Host
+	if host == "" {
+		host = "localhost:8080"
+	}
+	vars := []*Variable{
+		{Key: "scheme", Value: scheme, Type: "string", Description: "URL scheme of the API"},
+		{Key: "host", Value: host, Type: "string", Description: "Host and port of the API"},
+	}
+	if basePath := strings.Trim(api.BasePath, "/"); basePath != "" {
+		vars = append(vars, &Variable{Key: "basePath", Value: basePath, Type: "string", Description: "Base path of the API"})
+	}
+	for _, s := range api.SecuritySchemes {
+		for _, key := range credentials(s) {
+			vars = append(vars, &Variable{
+				Key:         variable(s, key),
+				Type:        "string",
+				Description: fmt.Sprintf("%s of the %s security scheme", key, s.SchemeName),
+			})
+		}
+	}
+	return vars
+}
+
+// credentials returns the names of the credentials required by the given security scheme.
+func credentials(s *design.SecuritySchemeDefinition) []string {
+	switch s.Kind {
+	case design.BasicAuthSecurityKind:
+		return []string{"username", "password"}
+	case design.APIKeySecurityKind:
+		return []string{"key"}
+	case design.JWTSecurityKind:
+		return []string{"token"}
+	case design.OAuth2SecurityKind:
+		return []string{"clientId", "clientSecret"}
+	}
+	return nil
+}
+
+// variable returns the name of the variable that holds the given credential of the security
+// scheme.
+func variable(s *design.SecuritySchemeDefinition, key string) string {
+	return s.SchemeName + "_" + key
+}
+
+// requestItem builds the request item that describes the given action route.
+func requestItem(api *design.APIDefinition, a *design.ActionDefinition, r *design.RouteDefinition, basePath string) (*Item, error) {
+	rand := api.RandomGenerator()
+	req := &Request{
+		Method:      r.Verb,
+		Description: a.Description,
+		Header:      []*KeyValue{},
+		Auth:        auth(a.Security),
+	}
+
+	path := r.FullPath()
+	u := &URL{Protocol: "{{scheme}}", Host: []string{"{{host}}"}}
+	if basePath != "" && strings.HasPrefix(path+"/", "/"+basePath+"/") {
+		path = strings.TrimPrefix(path, "/"+basePath)
+		u.Path = append(u.Path, "{{basePath}}")
+	}
+	all := a.AllParams()
+	for _, seg := range strings.Split(strings.Trim(path, "/"), "/") {
+		if seg == "" {
+			continue
+		}
+		if seg[0] == ':' || seg[0] == '*' {
+			name := seg[1:]
+			seg = ":" + name
+			v := &KeyValue{Key: name}
+			if all != nil {
+				if at, ok := all.Type.ToObject()[name]; ok {
+					v.Value = example(at, rand)
+					v.Description = at.Description
+				}
+			}
+			u.Variable = append(u.Variable, v)
+		}
+		u.Path = append(u.Path, seg)
+	}
+	if a.QueryParams != nil {
+		pathParams := r.Params()
+		params := a.QueryParams.Type.ToObject()
+		for _, n := range codegen.SortedNames(params) {
+			if codegen.ContainsString(pathParams, n) {
+				continue
+			}
+			at := params[n]
+			var values []string
+			if at.Type.IsArray() {
+				ex := reflect.ValueOf(at.GenerateExample(rand, nil))
+				for i := 0; ex.Kind() == reflect.Slice && i < ex.Len(); i++ {
+					values = append(values, format(ex.Index(i).Interface()))
+				}
+			} else {
+				values = []string{example(at, rand)}
+			}
+			for _, v := range values {
+				u.Query = append(u.Query, &KeyValue{
+					Key:         n,
+					Value:       v,
+					Description: at.Description,
+					Disabled:    !a.QueryParams.IsRequired(n),
+				})
+			}
+		}
+	}
+	u.Raw = rawURL(u)
+	req.URL = u
+
+	if a.Headers != nil {
+		headers := a.Headers.Type.ToObject()
+		for _, n := range codegen.SortedNames(headers) {
+			at := headers[n]
+			req.Header = append(req.Header, &KeyValue{
+				Key:         n,
+				Value:       example(at, rand),
+				Type:        "text",
+				Description: at.Description,
+				Disabled:    !a.Headers.IsRequired(n),
+			})
+		}
+	}
+
+	if a.Payload != nil {
+		if a.PayloadMultipart {
+			body := &Body{Mode: "formdata"}
+			if obj := a.Payload.Type.ToObject(); obj != nil {
+				for _, n := range codegen.SortedNames(obj) {
+					at := obj[n]
+					part := &KeyValue{Key: n, Type: "text", Description: at.Description}
+					if at.Type.Kind() == design.FileKind {
+						part.Type = "file"
+					} else {
+						part.Value = example(at, rand)
+					}
+					body.FormData = append(body.FormData, part)
+				}
+			}
+			req.Body = body
+		} else {
+			raw, err := json.MarshalIndent(a.Payload.GenerateExample(rand, nil), "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("%s: payload example: %s", a.Context(), err)
+			}
+			req.Header = append(req.Header, &KeyValue{Key: "Content-Type", Value: "application/json", Type: "text"})
+			req.Body = &Body{
+				Mode:    "raw",
+				Raw:     string(raw),
+				Options: &BodyOptions{Raw: &RawOptions{Language: "json"}},
+			}
+		}
+	}
+
+	return &Item{Name: a.Name, Request: req}, nil
+}
+
+// auth returns the authentication that corresponds to the given security requirement.
+func auth(security *design.SecurityDefinition) *Auth {
+	if security == nil || security.Scheme == nil {
+		return &Auth{Type: "noauth"}
+	}
+	s := security.Scheme
+	attr := func(key, value string) *KeyValue {
+		return &KeyValue{Key: key, Value: value, Type: "string"}
+	}
+	ref := func(key string) string {
+		return "{{" + variable(s, key) + "}}"
+	}
+	switch s.Kind {
+	case design.BasicAuthSecurityKind:
+		return &Auth{Type: "basic", Basic: []*KeyValue{
+			attr("username", ref("username")),
+			attr("password", ref("password")),
+		}}
+	case design.APIKeySecurityKind:
+		return &Auth{Type: "apikey", APIKey: []*KeyValue{
+			attr("key", s.Name),
+			attr("value", ref("key")),
+			attr("in", s.In),
+		}}
+	case design.JWTSecurityKind:
+		return &Auth{Type: "bearer", Bearer: []*KeyValue{
+			attr("token", ref("token")),
+		}}
+	case design.OAuth2SecurityKind:
+		attrs := []*KeyValue{
+			attr("grantType", grantType(s.Flow)),
+			attr("clientId", ref("clientId")),
+			attr("clientSecret", ref("clientSecret")),
+			attr("scope", strings.Join(security.Scopes, " ")),
+			attr("addTokenTo", "header"),
+		}
+		if s.AuthorizationURL != "" {
+			attrs = append(attrs, attr("authUrl", s.AuthorizationURL))
+		}
+		if s.TokenURL != "" {
+			attrs = append(attrs, attr("accessTokenUrl", s.TokenURL))
+		}
+		return &Auth{Type: "oauth2", OAuth2: attrs}
+	}
+	return &Auth{Type: "noauth"}
+}
+
+// grantType returns the Postman OAuth2 grant type that corresponds to the given flow.
+func grantType(flow string) string {
+	switch flow {
+	case "implicit":
+		return "implicit"
+	case "password":
+		return "password_credentials"
+	case "application":
+		return "client_credentials"
+	}
+	return "authorization_code"
+}
+
+// example returns the string representation of an example value of the given attribute.
+func example(at *design.AttributeDefinition, rand *design.RandomGenerator) string {
+	return format(at.GenerateExample(rand, nil))
+}
+
+// format returns the string representation of the given example value, strings are returned
+// as is and other values as JSON.
+func format(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// rawURL returns the string representation of the given URL.
+func rawURL(u *URL) string {
+	raw := u.Protocol + "://" + strings.Join(u.Host, ".") + "/" + strings.Join(u.Path, "/")
+	var query []string
+	for _, q := range u.Query {
+		if !q.Disabled {
+			query = append(query, q.Key+"="+q.Value)
+		}
+	}
+	if len(query) > 0 {
+		raw += "?" + strings.Join(query, "&")
+	}
+	return raw
+}
@@ -0,0 +1,155 @@
+package genpostman_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genpostman "github.com/kyokomi/goa-v1/goagen/gen_postman"
+)
+
+var _ = Describe("New", func() {
+	var collection *genpostman.Collection
+	var newErr error
+
+	BeforeEach(func() {
+		collection = nil
+		newErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		collection, newErr = genpostman.New(Design)
+	})
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			basic := apidsl.BasicAuthSecurity("basic")
+			key := apidsl.APIKeySecurity("key", func() {
+				apidsl.Header("X-API-Key")
+			})
+			apidsl.API("cellar", func() {
+				apidsl.Title("Cellar API")
+				apidsl.Host("cellar.example.com")
+				apidsl.Scheme("https")
+				apidsl.BasePath("/api")
+				apidsl.Security(basic)
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.Description("Bottles of wine")
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Description("Get bottle by id")
+					apidsl.Routing(apidsl.GET("/:bottleID"))
+					apidsl.Params(func() {
+						apidsl.Param("bottleID", Integer, func() {
+							apidsl.Example(42)
+						})
+						apidsl.Param("fields", apidsl.ArrayOf(String), func() {
+							apidsl.Example([]string{"name", "vintage"})
+						})
+					})
+					apidsl.Headers(func() {
+						apidsl.Header("X-Request-Id", String, func() {
+							apidsl.Example("abc")
+						})
+					})
+					apidsl.Response(OK)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Security(key)
+					apidsl.Payload(func() {
+						apidsl.Member("name", String, func() {
+							apidsl.Example("Number 8")
+						})
+						apidsl.Required("name")
+					})
+					apidsl.Response(Created)
+				})
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET(""))
+					apidsl.NoSecurity()
+					apidsl.Response(OK)
+				})
+			})
+		})
+
+		It("sets the info and variables", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(collection.Info.Name).Should(Equal("Cellar API"))
+			Ω(collection.Info.Schema).Should(Equal(genpostman.SchemaURL))
+			var vars []string
+			for _, v := range collection.Variable {
+				vars = append(vars, v.Key+"="+v.Value)
+			}
+			Ω(vars).Should(Equal([]string{
+				"scheme=https", "host=cellar.example.com", "basePath=api",
+				"basic_username=", "basic_password=", "key_key=",
+			}))
+		})
+
+		It("generates one request per action", func() {
+			Ω(collection.Item).Should(HaveLen(1))
+			folder := collection.Item[0]
+			Ω(folder.Name).Should(Equal("bottle"))
+			Ω(folder.Description).Should(Equal("Bottles of wine"))
+			Ω(folder.Item).Should(HaveLen(3))
+
+			create := folder.Item[0].Request
+			Ω(folder.Item[0].Name).Should(Equal("create"))
+			Ω(create.Method).Should(Equal("POST"))
+			Ω(create.URL.Raw).Should(Equal("{{scheme}}://{{host}}/{{basePath}}/bottles"))
+			Ω(create.Body.Mode).Should(Equal("raw"))
+			Ω(create.Body.Raw).Should(Equal("{\n  \"name\": \"Number 8\"\n}"))
+			Ω(create.Header[0].Key).Should(Equal("Content-Type"))
+			Ω(create.Auth.Type).Should(Equal("apikey"))
+			Ω(create.Auth.APIKey[0].Value).Should(Equal("X-API-Key"))
+			Ω(create.Auth.APIKey[1].Value).Should(Equal("{{key_key}}"))
+			Ω(create.Auth.APIKey[2].Value).Should(Equal("header"))
+
+			list := folder.Item[1].Request
+			Ω(list.Auth.Type).Should(Equal("noauth"))
+
+			show := folder.Item[2].Request
+			Ω(show.Description).Should(Equal("Get bottle by id"))
+			Ω(show.URL.Path).Should(Equal([]string{"{{basePath}}", "bottles", ":bottleID"}))
+			Ω(show.URL.Variable).Should(HaveLen(1))
+			Ω(show.URL.Variable[0].Value).Should(Equal("42"))
+			Ω(show.URL.Query).Should(HaveLen(2))
+			Ω(show.URL.Query[0].Value).Should(Equal("name"))
+			Ω(show.URL.Query[1].Value).Should(Equal("vintage"))
+			Ω(show.URL.Query[1].Disabled).Should(BeTrue())
+			Ω(show.Header).Should(HaveLen(1))
+			Ω(show.Header[0].Value).Should(Equal("abc"))
+			Ω(show.Auth.Type).Should(Equal("basic"))
+			Ω(show.Auth.Basic[0].Value).Should(Equal("{{basic_username}}"))
+			Ω(show.Body).Should(BeNil())
+		})
+
+		It("serializes into JSON", func() {
+			b, err := json.Marshal(collection)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(ContainSubstring(`"schema":"https://schema.getpostman.com/json/collection/v2.1.0/collection.json"`))
+			Ω(string(b)).Should(ContainSubstring(`"auth":{"type":"noauth"}`))
+		})
+
+		It("generates the environment", func() {
+			env := genpostman.NewEnvironment(Design)
+			Ω(env.Name).Should(Equal("cellar"))
+			Ω(env.Scope).Should(Equal("environment"))
+			Ω(env.Values).Should(HaveLen(6))
+			Ω(env.Values[1].Key).Should(Equal("host"))
+			Ω(env.Values[1].Type).Should(Equal("default"))
+			Ω(env.Values[3].Key).Should(Equal("basic_username"))
+			Ω(env.Values[3].Type).Should(Equal("secret"))
+		})
+	})
+})
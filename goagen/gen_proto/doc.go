@@ -0,0 +1,24 @@
+/*
+Package genproto provides a generator for the gRPC protocol buffer definitions of the API. The
+generator produces a .proto file that describes the services and messages of the resources and
+actions annotated with the gRPC metadata together with adapter code that translates between the
+Go structs generated by protoc for the messages and the goa payload, user type and media type
+structs.
+
+The following metadata annotate the design:
+
+	// Exposes all the actions of the resource as RPCs of a service with the given name.
+	// The name defaults to the resource name.
+	Metadata("grpc:service", "Cellar")
+
+	// Exposes the action as a RPC with the given name, the name defaults to the action name.
+	Metadata("grpc:rpc", "GetBottle")
+
+	// Sets the field number of the attribute in the message, the attributes that do not
+	// define a field number are numbered in alphabetical order.
+	Metadata("grpc:field", "3")
+
+	// Sets the protocol buffer package of the API, defaults to the API name.
+	Metadata("grpc:package", "cellar.v1")
+*/
+package genproto
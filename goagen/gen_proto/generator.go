@@ -0,0 +1,149 @@
+package genproto
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of a gRPC Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{Target: "app"}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the gRPC protocol buffer and adapter code generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Target   string                // Name of generated "app" package
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, target, ver string
+
+	set := flag.NewFlagSet("proto", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&target, "pkg", "app", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	target = codegen.Goify(target, false)
+	g := &Generator{OutDir: outDir, Target: target, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the .proto file and the adapter code in the "proto" directory. It does not
+// generate any file if no resource or action is annotated with the gRPC metadata.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	f, err := New(g.API)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Services) == 0 {
+		return nil, nil
+	}
+
+	outPkg, err := codegen.PackagePath(g.OutDir)
+	if err != nil {
+		return nil, err
+	}
+	f.GoPackage = path.Join(outPkg, "proto")
+
+	protoDir := filepath.Join(g.OutDir, "proto")
+	codegen.RemoveAll(protoDir)
+	if err = os.MkdirAll(protoDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, protoDir)
+
+	title := fmt.Sprintf("%s: gRPC Services", g.API.Context())
+	content, err := f.WriteProto(title)
+	if err != nil {
+		return nil, err
+	}
+	protoFile := filepath.Join(protoDir, codegen.SnakeCase(g.API.Name)+".proto")
+	if err = codegen.WriteFile(protoFile, content, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, protoFile)
+
+	if err = g.generateAdapter(f, protoDir, path.Join(outPkg, g.Target)); err != nil {
+		return nil, err
+	}
+
+	return g.genfiles, nil
+}
+
+// generateAdapter generates the code that converts the goa types to and from the messages.
+func (g *Generator) generateAdapter(f *ProtoFile, protoDir, appPkg string) (err error) {
+	adapterFile := filepath.Join(protoDir, "adapter.go")
+	file, err := codegen.SourceFileFor(adapterFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("time"),
+		codegen.NewImport("uuid", "github.com/gofrs/uuid"),
+		codegen.SimpleImport(appPkg),
+	}
+	for _, m := range f.Messages {
+		if m.Type != nil {
+			imports = codegen.AttributeImports(userType(m.Type).AttributeDefinition, imports, nil)
+		}
+	}
+	title := fmt.Sprintf("%s: gRPC Adapters", g.API.Context())
+	if err = file.WriteHeader(title, "proto", imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, adapterFile)
+	_, err = file.Write([]byte(f.Adapter(path.Base(appPkg))))
+	return err
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
@@ -0,0 +1,441 @@
+package genproto
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+type (
+	// ProtoFile describes the content of a .proto file.
+	ProtoFile struct {
+		// Package is the protocol buffer package.
+		Package string
+		// GoPackage is the import path of the Go package generated by protoc.
+		GoPackage string
+		// Imports lists the imported .proto files.
+		Imports []string
+		// Messages lists the messages sorted by name.
+		Messages []*Message
+		// Services lists the services sorted by name.
+		Services []*Service
+	}
+
+	// Message describes a protocol buffer message.
+	Message struct {
+		// Name of the message.
+		Name string
+		// Description of the message.
+		Description string
+		// Fields lists the message fields sorted by number.
+		Fields []*Field
+		// Type is the goa user type or media type described by the message, nil for request
+		// messages.
+		Type design.DataType
+	}
+
+	// Field describes a protocol buffer message field.
+	Field struct {
+		// Name of the field in snake case.
+		Name string
+		// Number of the field.
+		Number int
+		// Type is the protocol buffer type of the field, e.g. "int64" or "repeated Bottle".
+		Type string
+		// Optional is true if the field has explicit presence.
+		Optional bool
+		// Description of the field.
+		Description string
+		// Attribute is the goa attribute described by the field.
+		Attribute *design.AttributeDefinition
+		// AttName is the name of the goa attribute described by the field.
+		AttName string
+	}
+
+	// Service describes a gRPC service.
+	Service struct {
+		// Name of the service.
+		Name string
+		// Description of the service.
+		Description string
+		// RPCs lists the service methods in order of action name.
+		RPCs []*RPC
+	}
+
+	// RPC describes a gRPC service method.
+	RPC struct {
+		// Name of the method.
+		Name string
+		// Description of the method.
+		Description string
+		// Request is the name of the request message.
+		Request string
+		// Response is the name of the response message.
+		Response string
+		// Action is the goa action exposed by the method.
+		Action *design.ActionDefinition
+	}
+)
+
+// EmptyMessage is the message used by the RPCs that do not return a body.
+const EmptyMessage = "google.protobuf.Empty"
+
+// invalidNameChars matches the characters that cannot be used in protocol buffer identifiers.
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// New builds the description of the .proto file that corresponds to the resources and actions
+// annotated with the "grpc:service" and "grpc:rpc" metadata. The messages describe the request
+// of each RPC and the user types and media types they use.
+func New(api *design.APIDefinition) (*ProtoFile, error) {
+	b := &builder{api: api, messages: make(map[string]*Message)}
+	pkg := codegen.SnakeCase(api.Name)
+	if p, ok := api.Metadata["grpc:package"]; ok && len(p) > 0 {
+		pkg = p[0]
+	}
+	f := &ProtoFile{Package: invalidNameChars.ReplaceAllString(pkg, "_")}
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		svcName, exposed := metadataName(res.Metadata, "grpc:service", res.Name)
+		svc := &Service{Name: svcName, Description: res.Description}
+		err := res.IterateActions(func(a *design.ActionDefinition) error {
+			name, ok := metadataName(a.Metadata, "grpc:rpc", a.Name)
+			if !ok && !exposed {
+				return nil
+			}
+			rpc, err := b.rpc(svc, name, a)
+			if err != nil {
+				return fmt.Errorf("%s: %s", a.Context(), err)
+			}
+			svc.RPCs = append(svc.RPCs, rpc)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(svc.RPCs) > 0 {
+			f.Services = append(f.Services, svc)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(f.Services, func(i, j int) bool { return f.Services[i].Name < f.Services[j].Name })
+	for _, m := range b.messages {
+		f.Messages = append(f.Messages, m)
+	}
+	sort.Slice(f.Messages, func(i, j int) bool { return f.Messages[i].Name < f.Messages[j].Name })
+	if b.empty {
+		f.Imports = append(f.Imports, "google/protobuf/empty.proto")
+	}
+	return f, nil
+}
+
+// metadataName returns the name given by the metadata if set, the Goified default otherwise.
+// The returned boolean is true if the metadata is set.
+func metadataName(meta dslengine.MetadataDefinition, key, def string) (string, bool) {
+	m, ok := meta[key]
+	if ok && len(m) > 0 && m[0] != "" {
+		def = m[0]
+	}
+	return invalidNameChars.ReplaceAllString(codegen.Goify(def, true), ""), ok
+}
+
+// builder computes the messages.
+type builder struct {
+	api      *design.APIDefinition
+	messages map[string]*Message
+	empty    bool
+}
+
+// rpc builds the RPC that exposes the action together with its request and response messages.
+func (b *builder) rpc(svc *Service, name string, a *design.ActionDefinition) (*RPC, error) {
+	rpc := &RPC{
+		Name:        name,
+		Description: a.Description,
+		Request:     svc.Name + name + "Request",
+		Action:      a,
+	}
+	if _, ok := b.messages[rpc.Request]; ok {
+		return nil, fmt.Errorf("message %s is already defined", rpc.Request)
+	}
+	req := &Message{Name: rpc.Request, Description: fmt.Sprintf("%s is the request message of the %s.%s RPC.", rpc.Request, svc.Name, name)}
+	b.messages[req.Name] = req
+	params := a.AllParams()
+	if params == nil {
+		params = &design.AttributeDefinition{Type: design.Object{}}
+	}
+	obj := design.Object{}
+	for n, at := range params.Type.ToObject() {
+		obj[n] = at
+	}
+	if a.Payload != nil {
+		if _, ok := obj["payload"]; ok {
+			return nil, fmt.Errorf(`"payload" cannot be used as parameter name`)
+		}
+		obj["payload"] = &design.AttributeDefinition{Type: a.Payload, Description: a.Payload.Description}
+	}
+	fields, err := b.fields(&design.AttributeDefinition{Type: obj, Validation: params.Validation})
+	if err != nil {
+		return nil, err
+	}
+	req.Fields = fields
+
+	rpc.Response = EmptyMessage
+	var resp *design.ResponseDefinition
+	a.IterateResponses(func(r *design.ResponseDefinition) error {
+		if resp == nil && r.Status >= http.StatusOK && r.Status < http.StatusMultipleChoices {
+			if r.Type != nil || r.MediaType != "" {
+				resp = r
+			}
+		}
+		return nil
+	})
+	var typ design.DataType
+	if resp != nil {
+		if mt, ok := b.api.MediaTypes[design.CanonicalIdentifier(resp.MediaType)]; ok && resp.MediaType != "" && !mt.IsError() {
+			view := resp.ViewName
+			if view == "" {
+				view = design.DefaultView
+			}
+			p, _, err := mt.Project(view)
+			if err != nil {
+				return nil, err
+			}
+			typ = p
+		} else if ut, ok := resp.Type.(*design.UserTypeDefinition); ok {
+			typ = ut
+		}
+	}
+	if typ == nil {
+		b.empty = true
+		return rpc, nil
+	}
+	if rpc.Response, err = b.message(typ); err != nil {
+		return nil, err
+	}
+	return rpc, nil
+}
+
+// message builds the message that corresponds to the given user type or media type and
+// returns its name.
+func (b *builder) message(t design.DataType) (string, error) {
+	var ut *design.UserTypeDefinition
+	switch actual := t.(type) {
+	case *design.MediaTypeDefinition:
+		p, err := messageType(&design.AttributeDefinition{Type: actual})
+		if err != nil {
+			return "", err
+		}
+		t = p
+		ut = p.(*design.MediaTypeDefinition).UserTypeDefinition
+	case *design.UserTypeDefinition:
+		ut = actual
+	default:
+		return "", fmt.Errorf("%s is not a user type", t.Name())
+	}
+	name := codegen.GoTypeName(t, nil, 0, false)
+	if m, ok := b.messages[name]; ok {
+		if m.Type == nil {
+			return "", fmt.Errorf("message %s is already defined", name)
+		}
+		return name, nil
+	}
+	m := &Message{Name: name, Description: ut.Description, Type: t}
+	b.messages[name] = m
+	var err error
+	switch {
+	case ut.Type.IsObject():
+		m.Fields, err = b.fields(ut.AttributeDefinition)
+	case ut.Type.IsArray(), ut.Type.IsHash():
+		var typ string
+		typ, err = b.fieldType(ut.AttributeDefinition)
+		m.Fields = []*Field{{Name: "items", Number: 1, Type: typ, Attribute: ut.AttributeDefinition}}
+	default:
+		err = fmt.Errorf("type %s: only object, array and hash user types are supported", ut.TypeName)
+	}
+	if err != nil {
+		delete(b.messages, name)
+		return "", err
+	}
+	return name, nil
+}
+
+// fields builds the message fields that describe the attributes of the given object.
+func (b *builder) fields(att *design.AttributeDefinition) ([]*Field, error) {
+	obj := att.Type.ToObject()
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	used := make(map[int]string)
+	numbers := make(map[string]int)
+	for _, n := range names {
+		num, ok := obj[n].Metadata["grpc:field"]
+		if !ok || len(num) == 0 {
+			continue
+		}
+		i, err := strconv.Atoi(num[0])
+		if err != nil || i < 1 {
+			return nil, fmt.Errorf("invalid field number %#v for attribute %#v", num[0], n)
+		}
+		if other, ok := used[i]; ok {
+			return nil, fmt.Errorf("attributes %#v and %#v use the same field number %d", other, n, i)
+		}
+		used[i] = n
+		numbers[n] = i
+	}
+	next := 1
+	var fields []*Field
+	for _, n := range names {
+		at := obj[n]
+		num, ok := numbers[n]
+		if !ok {
+			for used[next] != "" {
+				next++
+			}
+			num = next
+			used[num] = n
+		}
+		typ, err := b.fieldType(at)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %#v: %s", n, err)
+		}
+		fields = append(fields, &Field{
+			Name:        fieldName(n),
+			Number:      num,
+			Type:        typ,
+			Optional:    att.IsPrimitivePointer(n),
+			Description: at.Description,
+			Attribute:   at,
+			AttName:     n,
+		})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Number < fields[j].Number })
+	return fields, nil
+}
+
+// fieldType returns the protocol buffer type of a field that describes the given attribute.
+func (b *builder) fieldType(at *design.AttributeDefinition) (string, error) {
+	switch actual := at.Type.(type) {
+	case design.Primitive:
+		return primitiveType(actual)
+	case *design.Array:
+		if actual.ElemType.Type.IsArray() || actual.ElemType.Type.IsHash() {
+			return "", fmt.Errorf("arrays of arrays or hashes are not supported")
+		}
+		elem, err := b.fieldType(actual.ElemType)
+		if err != nil {
+			return "", err
+		}
+		return "repeated " + elem, nil
+	case *design.Hash:
+		key, ok := actual.KeyType.Type.(design.Primitive)
+		if !ok || (key.Kind() != design.StringKind && key.Kind() != design.IntegerKind && key.Kind() != design.BooleanKind) {
+			return "", fmt.Errorf("hash keys must be strings, integers or booleans")
+		}
+		if actual.ElemType.Type.IsArray() || actual.ElemType.Type.IsHash() {
+			return "", fmt.Errorf("hashes of arrays or hashes are not supported")
+		}
+		k, _ := primitiveType(key)
+		elem, err := b.fieldType(actual.ElemType)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("map<%s, %s>", k, elem), nil
+	case *design.UserTypeDefinition, *design.MediaTypeDefinition:
+		t, err := messageType(at)
+		if err != nil {
+			return "", err
+		}
+		return b.message(t)
+	default:
+		return "", fmt.Errorf("inline objects are not supported, use a user type instead")
+	}
+}
+
+// primitiveType returns the protocol buffer scalar type used to represent values of the given
+// primitive. Date times, UUIDs and decimals are represented as strings.
+func primitiveType(p design.Primitive) (string, error) {
+	switch p.Kind() {
+	case design.BooleanKind:
+		return "bool", nil
+	case design.IntegerKind:
+		return "int64", nil
+	case design.NumberKind:
+		return "double", nil
+	case design.StringKind, design.DateTimeKind, design.UUIDKind, design.DecimalKind:
+		return "string", nil
+	case design.AnyKind:
+		return "", fmt.Errorf("Any attributes are not supported")
+	case design.FileKind:
+		return "", fmt.Errorf("File attributes are not supported")
+	}
+	if c := design.CustomPrimitive(p); c != nil {
+		return "string", nil
+	}
+	return "", fmt.Errorf("unknown primitive type %s", p.Name())
+}
+
+// messageType returns the type described by the message used by fields that describe the given
+// attribute. Media types are projected using the attribute view or the default view.
+func messageType(at *design.AttributeDefinition) (design.DataType, error) {
+	mt, ok := at.Type.(*design.MediaTypeDefinition)
+	if !ok || isProjected(mt) {
+		return at.Type, nil
+	}
+	view := at.View
+	if view == "" {
+		view = design.DefaultView
+	}
+	p, _, err := mt.Project(view)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// isProjected returns true if the media type is the result of a projection.
+func isProjected(mt *design.MediaTypeDefinition) bool {
+	return strings.Contains(mt.Identifier, "view=")
+}
+
+// fieldName returns the snake case protocol buffer field name for the given attribute name.
+func fieldName(n string) string {
+	return strings.Trim(invalidNameChars.ReplaceAllString(codegen.SnakeCase(n), "_"), "_")
+}
+
+// goName returns the name of the Go struct field generated by protoc-gen-go for the given
+// protocol buffer field name.
+func goName(n string) string {
+	var b []byte
+	for i := 0; i < len(n); i++ {
+		c := n[i]
+		switch {
+		case c == '_' && i+1 < len(n) && isLower(n[i+1]):
+			// Skip the underscore and capitalize the next letter.
+		case c >= '0' && c <= '9':
+			b = append(b, c)
+		default:
+			if isLower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(n) && isLower(n[i+1]); i++ {
+				b = append(b, n[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+func isLower(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
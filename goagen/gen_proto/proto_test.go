@@ -0,0 +1,179 @@
+package genproto_test
+
+import (
+	"go/parser"
+	"go/token"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genproto "github.com/kyokomi/goa-v1/goagen/gen_proto"
+)
+
+var _ = Describe("New", func() {
+	var file *genproto.ProtoFile
+	var newErr error
+
+	BeforeEach(func() {
+		file = nil
+		newErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		file, newErr = genproto.New(Design)
+	})
+
+	Context("with annotated resources and actions", func() {
+		BeforeEach(func() {
+			bottle := apidsl.MediaType("application/vnd.bottle", func() {
+				apidsl.Description("A bottle of wine")
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer, "ID of bottle")
+					apidsl.Attribute("name", String, func() {
+						apidsl.Metadata("grpc:field", "1")
+					})
+					apidsl.Attribute("created_at", DateTime)
+					apidsl.Attribute("tags", apidsl.ArrayOf(String))
+					apidsl.Attribute("ratings", apidsl.HashOf(String, Integer))
+					apidsl.Required("id")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+					apidsl.Attribute("created_at")
+					apidsl.Attribute("tags")
+					apidsl.Attribute("ratings")
+				})
+			})
+			apidsl.API("cellar", nil)
+			apidsl.Resource("bottle", func() {
+				apidsl.Metadata("grpc:service")
+				apidsl.Description("The bottles")
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", Integer)
+					})
+					apidsl.Response(OK, bottle)
+					apidsl.Response(NotFound)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(func() {
+						apidsl.Member("name", String)
+						apidsl.Required("name")
+					})
+					apidsl.Response(Created)
+				})
+			})
+			apidsl.Resource("account", func() {
+				apidsl.Action("show", func() {
+					apidsl.Metadata("grpc:rpc", "GetAccount")
+					apidsl.Routing(apidsl.GET("/accounts"))
+					apidsl.Response(NoContent)
+				})
+				apidsl.Action("delete", func() {
+					apidsl.Routing(apidsl.DELETE("/accounts"))
+					apidsl.Response(NoContent)
+				})
+			})
+		})
+
+		It("generates the services", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(file.Package).Should(Equal("cellar"))
+			Ω(file.Services).Should(HaveLen(2))
+			Ω(file.Services[0].Name).Should(Equal("Account"))
+			Ω(file.Services[0].RPCs).Should(HaveLen(1))
+			Ω(file.Services[0].RPCs[0].Name).Should(Equal("GetAccount"))
+			Ω(file.Services[0].RPCs[0].Response).Should(Equal(genproto.EmptyMessage))
+			svc := file.Services[1]
+			Ω(svc.Name).Should(Equal("Bottle"))
+			Ω(svc.RPCs).Should(HaveLen(2))
+			Ω(svc.RPCs[0].Name).Should(Equal("Create"))
+			Ω(svc.RPCs[0].Request).Should(Equal("BottleCreateRequest"))
+			Ω(svc.RPCs[1].Name).Should(Equal("Show"))
+			Ω(svc.RPCs[1].Response).Should(Equal("Bottle"))
+			Ω(file.Imports).Should(ConsistOf("google/protobuf/empty.proto"))
+		})
+
+		It("generates the messages", func() {
+			var names []string
+			for _, m := range file.Messages {
+				names = append(names, m.Name)
+			}
+			Ω(names).Should(Equal([]string{"AccountGetAccountRequest", "Bottle", "BottleCreateRequest", "BottleShowRequest", "CreateBottlePayload"}))
+			bottle := file.Messages[1]
+			Ω(bottle.Fields).Should(HaveLen(5))
+			Ω(bottle.Fields[0].Name).Should(Equal("name"))
+			Ω(bottle.Fields[0].Number).Should(Equal(1))
+			Ω(bottle.Fields[0].Optional).Should(BeTrue())
+			Ω(bottle.Fields[1].Name).Should(Equal("created_at"))
+			Ω(bottle.Fields[1].Type).Should(Equal("string"))
+			Ω(bottle.Fields[2].Name).Should(Equal("id"))
+			Ω(bottle.Fields[2].Type).Should(Equal("int64"))
+			Ω(bottle.Fields[2].Optional).Should(BeFalse())
+			Ω(bottle.Fields[3].Type).Should(Equal("map<string, int64>"))
+			Ω(bottle.Fields[4].Type).Should(Equal("repeated string"))
+			req := file.Messages[2]
+			Ω(req.Fields).Should(HaveLen(1))
+			Ω(req.Fields[0].Name).Should(Equal("payload"))
+			Ω(req.Fields[0].Type).Should(Equal("CreateBottlePayload"))
+		})
+
+		It("renders the .proto file", func() {
+			content, err := file.WriteProto("cellar: gRPC Services")
+			Ω(err).ShouldNot(HaveOccurred())
+			proto := string(content)
+			Ω(proto).Should(ContainSubstring(`syntax = "proto3";`))
+			Ω(proto).Should(ContainSubstring("package cellar;"))
+			Ω(proto).Should(ContainSubstring(`import "google/protobuf/empty.proto";`))
+			Ω(proto).Should(ContainSubstring("// The bottles\nservice Bottle {\n"))
+			Ω(proto).Should(ContainSubstring("\trpc Show(BottleShowRequest) returns (Bottle);\n"))
+			Ω(proto).Should(ContainSubstring("\trpc Create(BottleCreateRequest) returns (google.protobuf.Empty);\n"))
+			Ω(proto).Should(ContainSubstring("\toptional string name = 1;\n"))
+			Ω(proto).Should(ContainSubstring("\t// ID of bottle\n\tint64 id = 3;\n"))
+		})
+
+		It("generates the adapter code", func() {
+			code := file.Adapter("app")
+			Ω(code).Should(ContainSubstring("func BottleToProto(v *app.Bottle) *Bottle {"))
+			Ω(code).Should(ContainSubstring("func BottleFromProto(v *Bottle) (res *app.Bottle, err error) {"))
+			Ω(code).Should(ContainSubstring("res.Id = int64(v.ID)"))
+			Ω(code).Should(ContainSubstring("res.Name = v.Name"))
+			Ω(code).Should(ContainSubstring("if tmp2, err = time.Parse(time.RFC3339, (*v.CreatedAt)); err != nil {"))
+			Ω(code).Should(ContainSubstring("func CreateBottlePayloadToProto(v *app.CreateBottlePayload) *CreateBottlePayload {"))
+			Ω(code).ShouldNot(ContainSubstring("BottleShowRequestToProto"))
+			_, err := parser.ParseFile(token.NewFileSet(), "adapter.go", "package proto\n"+code, 0)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("with an unsupported attribute type", func() {
+		BeforeEach(func() {
+			apidsl.API("cellar", nil)
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("create", func() {
+					apidsl.Metadata("grpc:rpc")
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(func() {
+						apidsl.Member("data", Any)
+					})
+					apidsl.Response(Created)
+				})
+			})
+		})
+
+		It("returns an error", func() {
+			Ω(newErr).Should(HaveOccurred())
+			Ω(newErr.Error()).Should(ContainSubstring("Any attributes are not supported"))
+		})
+	})
+})
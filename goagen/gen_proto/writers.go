@@ -0,0 +1,325 @@
+package genproto
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/version"
+)
+
+// protoTmpl is the template used to render the .proto file.
+var protoTmpl = template.Must(template.New("proto").Funcs(template.FuncMap{
+	"comment": comment,
+}).Parse(protoT))
+
+// WriteProto renders the .proto file, title is written in the header comment.
+func (f *ProtoFile) WriteProto(title string) ([]byte, error) {
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"Title":       title,
+		"ToolVersion": version.String(),
+		"File":        f,
+	}
+	if err := protoTmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Adapter returns the Go code of the functions that convert the goa user types and media types
+// to and from the messages generated by protoc. appPkg is the name of the goa "app" package.
+// The code assumes that it belongs to the package generated by protoc.
+func (f *ProtoFile) Adapter(appPkg string) string {
+	a := &adapter{appPkg: appPkg}
+	for _, m := range f.Messages {
+		if m.Type != nil {
+			a.message(m)
+		}
+	}
+	return a.buf.String()
+}
+
+// comment renders the given text as a protocol buffer comment followed by a new line, indent is
+// prepended to each line.
+func comment(indent, text string) string {
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(indent+"// "+l, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// adapter generates the conversion functions.
+type adapter struct {
+	appPkg string
+	buf    bytes.Buffer
+	tmp    int
+}
+
+// message writes the ToProto and FromProto functions of the given message.
+func (a *adapter) message(m *Message) {
+	ut := userType(m.Type)
+	kind := "user type"
+	if _, ok := m.Type.(*design.MediaTypeDefinition); ok {
+		kind = "media type"
+	}
+	goType := a.goType(m.Type)
+
+	fmt.Fprintf(&a.buf, "// %sToProto builds a %s message from the goa %s %s.\n", m.Name, m.Name, m.Name, kind)
+	fmt.Fprintf(&a.buf, "func %sToProto(v %s) *%s {\n", m.Name, goType, m.Name)
+	a.buf.WriteString("\tif v == nil {\n\t\treturn nil\n\t}\n")
+	fmt.Fprintf(&a.buf, "\tres := &%s{}\n", m.Name)
+	if ut.Type.IsObject() {
+		for _, f := range m.Fields {
+			a.toProto(f.Attribute, "res."+goName(f.Name), "v."+codegen.GoifyAtt(f.Attribute, f.AttName, true), f.Optional, 1, 0)
+		}
+	} else {
+		a.toProto(ut.AttributeDefinition, "res.Items", "v", false, 1, 0)
+	}
+	a.buf.WriteString("\treturn res\n}\n\n")
+
+	fmt.Fprintf(&a.buf, "// %sFromProto builds the goa %s %s from a %s message.\n", m.Name, m.Name, kind, m.Name)
+	fmt.Fprintf(&a.buf, "func %sFromProto(v *%s) (res %s, err error) {\n", m.Name, m.Name, goType)
+	a.buf.WriteString("\tif v == nil {\n\t\treturn nil, nil\n\t}\n")
+	if ut.Type.IsObject() {
+		fmt.Fprintf(&a.buf, "\tres = &%s.%s{}\n", a.appPkg, m.Name)
+		for _, f := range m.Fields {
+			a.fromProto(f.Attribute, "res."+codegen.GoifyAtt(f.Attribute, f.AttName, true), "v."+goName(f.Name), f.Optional, 1, 0)
+		}
+	} else {
+		a.fromProto(ut.AttributeDefinition, "res", "v.Items", false, 1, 0)
+	}
+	a.buf.WriteString("\treturn res, nil\n}\n\n")
+}
+
+// toProto writes the code that sets the protocol buffer value target from the goa value
+// source. pointer is true if both values are pointers to primitive values.
+func (a *adapter) toProto(att *design.AttributeDefinition, target, source string, pointer bool, tabs, depth int) {
+	switch actual := att.Type.(type) {
+	case design.Primitive:
+		if !pointer {
+			a.line(tabs, "%s = %s", target, primitiveToProto(actual, source))
+			return
+		}
+		deref := "(*" + source + ")"
+		conv := primitiveToProto(actual, deref)
+		if conv == deref {
+			a.line(tabs, "%s = %s", target, source)
+			return
+		}
+		tmp := a.tempvar()
+		a.line(tabs, "if %s != nil {", source)
+		a.line(tabs+1, "%s := %s", tmp, conv)
+		a.line(tabs+1, "%s = &%s", target, tmp)
+		a.line(tabs, "}")
+	case *design.Array:
+		i, e := fmt.Sprintf("i%d", depth), fmt.Sprintf("e%d", depth)
+		a.line(tabs, "if %s != nil {", source)
+		a.line(tabs+1, "%s = make([]%s, len(%s))", target, a.protoGoType(actual.ElemType), source)
+		a.line(tabs+1, "for %s, %s := range %s {", i, e, source)
+		a.toProto(actual.ElemType, fmt.Sprintf("%s[%s]", target, i), e, false, tabs+2, depth+1)
+		a.line(tabs+1, "}")
+		a.line(tabs, "}")
+	case *design.Hash:
+		k, e := fmt.Sprintf("k%d", depth), fmt.Sprintf("e%d", depth)
+		a.line(tabs, "if %s != nil {", source)
+		a.line(tabs+1, "%s = make(map[%s]%s, len(%s))", target, a.protoGoType(actual.KeyType), a.protoGoType(actual.ElemType), source)
+		a.line(tabs+1, "for %s, %s := range %s {", k, e, source)
+		key := primitiveToProto(actual.KeyType.Type.(design.Primitive), k)
+		a.toProto(actual.ElemType, fmt.Sprintf("%s[%s]", target, key), e, false, tabs+2, depth+1)
+		a.line(tabs+1, "}")
+		a.line(tabs, "}")
+	default:
+		a.line(tabs, "%s = %sToProto(%s)", target, a.messageName(att), source)
+	}
+}
+
+// fromProto writes the code that sets the goa value target from the protocol buffer value
+// source. pointer is true if both values are pointers to primitive values.
+func (a *adapter) fromProto(att *design.AttributeDefinition, target, source string, pointer bool, tabs, depth int) {
+	switch actual := att.Type.(type) {
+	case design.Primitive:
+		conv, fallible := primitiveFromProto(actual, source)
+		if !pointer {
+			if fallible {
+				a.line(tabs, "if %s, err = %s; err != nil {", target, conv)
+				a.line(tabs+1, "return nil, err")
+				a.line(tabs, "}")
+				return
+			}
+			a.line(tabs, "%s = %s", target, conv)
+			return
+		}
+		deref := "(*" + source + ")"
+		conv, fallible = primitiveFromProto(actual, deref)
+		if conv == deref {
+			a.line(tabs, "%s = %s", target, source)
+			return
+		}
+		tmp := a.tempvar()
+		a.line(tabs, "if %s != nil {", source)
+		if fallible {
+			a.line(tabs+1, "var %s %s", tmp, a.goType(actual))
+			a.line(tabs+1, "if %s, err = %s; err != nil {", tmp, conv)
+			a.line(tabs+2, "return nil, err")
+			a.line(tabs+1, "}")
+		} else {
+			a.line(tabs+1, "%s := %s", tmp, conv)
+		}
+		a.line(tabs+1, "%s = &%s", target, tmp)
+		a.line(tabs, "}")
+	case *design.Array:
+		i, e := fmt.Sprintf("i%d", depth), fmt.Sprintf("e%d", depth)
+		a.line(tabs, "if %s != nil {", source)
+		a.line(tabs+1, "%s = make(%s, len(%s))", target, a.goTypeOf(att), source)
+		a.line(tabs+1, "for %s, %s := range %s {", i, e, source)
+		a.fromProto(actual.ElemType, fmt.Sprintf("%s[%s]", target, i), e, false, tabs+2, depth+1)
+		a.line(tabs+1, "}")
+		a.line(tabs, "}")
+	case *design.Hash:
+		k, e := fmt.Sprintf("k%d", depth), fmt.Sprintf("e%d", depth)
+		a.line(tabs, "if %s != nil {", source)
+		a.line(tabs+1, "%s = make(%s, len(%s))", target, a.goTypeOf(att), source)
+		a.line(tabs+1, "for %s, %s := range %s {", k, e, source)
+		key, _ := primitiveFromProto(actual.KeyType.Type.(design.Primitive), k)
+		a.fromProto(actual.ElemType, fmt.Sprintf("%s[%s]", target, key), e, false, tabs+2, depth+1)
+		a.line(tabs+1, "}")
+		a.line(tabs, "}")
+	default:
+		a.line(tabs, "if %s, err = %sFromProto(%s); err != nil {", target, a.messageName(att), source)
+		a.line(tabs+1, "return nil, err")
+		a.line(tabs, "}")
+	}
+}
+
+// messageName returns the name of the message that describes the value of the attribute.
+func (a *adapter) messageName(att *design.AttributeDefinition) string {
+	t, _ := messageType(att) // errors were reported when building the messages
+	return codegen.GoTypeName(t, nil, 0, false)
+}
+
+// goTypeOf returns the goa Go type of the attribute values qualified with the app package.
+func (a *adapter) goTypeOf(att *design.AttributeDefinition) string {
+	switch actual := att.Type.(type) {
+	case *design.Array:
+		return "[]" + a.goTypeOf(actual.ElemType)
+	case *design.Hash:
+		return fmt.Sprintf("map[%s]%s", a.goTypeOf(actual.KeyType), a.goTypeOf(actual.ElemType))
+	case design.Primitive:
+		return a.goType(actual)
+	default:
+		t, _ := messageType(att)
+		return a.goType(t)
+	}
+}
+
+// goType returns the goa Go type of the given primitive, user type or media type qualified with
+// the app package.
+func (a *adapter) goType(t design.DataType) string {
+	switch actual := t.(type) {
+	case design.Primitive:
+		return codegen.GoNativeType(actual)
+	default:
+		name := a.appPkg + "." + codegen.GoTypeName(t, nil, 0, false)
+		if userType(t).Type.IsObject() {
+			return "*" + name
+		}
+		return name
+	}
+}
+
+// protoGoType returns the Go type generated by protoc for values of the attribute when used as
+// array element or map key or value.
+func (a *adapter) protoGoType(att *design.AttributeDefinition) string {
+	if p, ok := att.Type.(design.Primitive); ok {
+		typ, _ := primitiveType(p)
+		switch typ {
+		case "double":
+			return "float64"
+		default:
+			return typ
+		}
+	}
+	return "*" + a.messageName(att)
+}
+
+// line writes a line of code indented with the given number of tabs.
+func (a *adapter) line(tabs int, format string, args ...interface{}) {
+	a.buf.WriteString(strings.Repeat("\t", tabs))
+	fmt.Fprintf(&a.buf, format, args...)
+	a.buf.WriteString("\n")
+}
+
+// tempvar returns a unique temporary variable name.
+func (a *adapter) tempvar() string {
+	a.tmp++
+	return fmt.Sprintf("tmp%d", a.tmp)
+}
+
+// primitiveToProto returns the Go expression that converts the goa primitive value v to the
+// corresponding protocol buffer scalar value.
+func primitiveToProto(p design.Primitive, v string) string {
+	switch p.Kind() {
+	case design.IntegerKind:
+		return fmt.Sprintf("int64(%s)", v)
+	case design.DateTimeKind:
+		return fmt.Sprintf("%s.Format(time.RFC3339)", v)
+	case design.UUIDKind:
+		return fmt.Sprintf("%s.String()", v)
+	case design.BooleanKind, design.NumberKind, design.StringKind, design.DecimalKind:
+		return v
+	}
+	return codegen.PrimitiveFormatter(p, v)
+}
+
+// primitiveFromProto returns the Go expression that converts the protocol buffer scalar value
+// v to the corresponding goa primitive value. The returned boolean is true if the expression
+// evaluates to a value and an error.
+func primitiveFromProto(p design.Primitive, v string) (string, bool) {
+	switch p.Kind() {
+	case design.IntegerKind:
+		return fmt.Sprintf("int(%s)", v), false
+	case design.DateTimeKind:
+		return fmt.Sprintf("time.Parse(time.RFC3339, %s)", v), true
+	case design.UUIDKind:
+		return fmt.Sprintf("uuid.FromString(%s)", v), true
+	case design.BooleanKind, design.NumberKind, design.StringKind, design.DecimalKind:
+		return v, false
+	}
+	return codegen.PrimitiveParser(p, v), true
+}
+
+// userType returns the user type definition of the given user type or media type.
+func userType(t design.DataType) *design.UserTypeDefinition {
+	if mt, ok := t.(*design.MediaTypeDefinition); ok {
+		return mt.UserTypeDefinition
+	}
+	return t.(*design.UserTypeDefinition)
+}
+
+const protoT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .Title }}
+syntax = "proto3";
+
+package {{ .File.Package }};
+{{ if .File.GoPackage }}
+option go_package = "{{ .File.GoPackage }}";
+{{ end }}{{ if .File.Imports }}
+{{ range .File.Imports }}import "{{ . }}";
+{{ end }}{{ end }}{{ range .File.Services }}
+{{ comment "" .Description }}service {{ .Name }} {
+{{ range .RPCs }}{{ comment "\t" .Description }}	rpc {{ .Name }}({{ .Request }}) returns ({{ .Response }});
+{{ end }}}
+{{ end }}{{ range .File.Messages }}
+{{ comment "" .Description }}message {{ .Name }} {
+{{ range .Fields }}{{ comment "\t" .Description }}	{{ if .Optional }}optional {{ end }}{{ .Type }} {{ .Name }} = {{ .Number }};
+{{ end }}}
+{{ end }}`
@@ -0,0 +1,21 @@
+/*
+Package genproxy provides a generator for the edge proxy configuration of the API so that the
+proxy routes stay in lockstep with the design. With --format=envoy (the default) the generator
+produces an Envoy v3 RouteConfiguration with one route per action route and file server, with
+--format=nginx it produces location blocks to include in the server block proxying the API.
+
+Routes match the path and method of the requests, parameters match a single path segment and
+wildcards the rest of the path. The routes are ordered like the API router orders them: static
+segments first, then parameters and finally wildcards. Paths that CORS policies apply to also
+accept OPTIONS requests and WebSocket endpoints enable connection upgrades.
+
+The requests are forwarded to the Envoy cluster or nginx upstream named after --cluster, the API
+name by default, which must be defined separately. The "proxy:timeout" metadata of the API,
+resources, actions and file servers sets the timeout of the routes, --timeout sets the timeout of
+the routes whose design does not:
+
+	goagen proxy -d example.com/design --format nginx --cluster backend --timeout 30s
+
+nginx locations group the routes that share a path and use the longest of their timeouts.
+*/
+package genproxy
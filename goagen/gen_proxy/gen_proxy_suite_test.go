@@ -0,0 +1,13 @@
+package genproxy_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenProxy(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenProxy Suite")
+}
@@ -0,0 +1,129 @@
+package genproxy
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of an edge proxy configuration Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the edge proxy configuration generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Format   string                // Configuration format: "envoy" or "nginx"
+	Cluster  string                // Name of the Envoy cluster or nginx upstream serving the API
+	Timeout  time.Duration         // Timeout of the routes whose design does not set one
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, ver     string
+		format, cluster string
+		timeout         time.Duration
+	)
+
+	set := flag.NewFlagSet("proxy", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.StringVar(&format, "format", "envoy", "")
+	set.StringVar(&cluster, "cluster", "", "")
+	set.DurationVar(&timeout, "timeout", 0, "")
+	set.String("design", "", "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Format: format, Cluster: cluster, Timeout: timeout, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the Envoy route configuration or the nginx location blocks.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+	if g.Format == "" {
+		g.Format = "envoy"
+	}
+	if g.Format != "envoy" && g.Format != "nginx" {
+		return nil, fmt.Errorf(`invalid proxy configuration format %#v, must be "envoy" or "nginx"`, g.Format)
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	routes, err := Routes(g.API, g.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyDir := filepath.Join(g.OutDir, "proxy")
+	codegen.RemoveAll(proxyDir)
+	if err = os.MkdirAll(proxyDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, proxyDir)
+
+	name := codegen.SnakeCase(codegen.Goify(g.API.Name, true))
+	cluster := g.Cluster
+	if cluster == "" {
+		cluster = name
+	}
+	var (
+		content []byte
+		file    string
+	)
+	if g.Format == "nginx" {
+		content, err = WriteNginx(name, cluster, routes)
+		file = filepath.Join(proxyDir, "nginx.conf")
+	} else {
+		content, err = WriteEnvoy(name, g.API.Host, cluster, routes)
+		file = filepath.Join(proxyDir, "envoy.yaml")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = codegen.WriteFile(file, content, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, file)
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
@@ -0,0 +1,134 @@
+package genproxy_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	genproxy "github.com/kyokomi/goa-v1/goagen/gen_proxy"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genproxy.Generator
+
+	var args = struct {
+		api     *design.APIDefinition
+		outDir  string
+		format  string
+		cluster string
+		timeout time.Duration
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir:  "out_dir",
+		format:  "nginx",
+		cluster: "backend",
+		timeout: 10 * time.Second,
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genproxy.NewGenerator(
+				genproxy.API(args.api),
+				genproxy.OutDir(args.outDir),
+				genproxy.Format(args.format),
+				genproxy.Cluster(args.cluster),
+				genproxy.Timeout(args.timeout),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Format).Should(Equal(args.format))
+			Ω(generator.Cluster).Should(Equal(args.cluster))
+			Ω(generator.Timeout).Should(Equal(args.timeout))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir, format string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(workspace.Path, "")
+		Ω(err).ShouldNot(HaveOccurred())
+		format = ""
+
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {
+			apidsl.Host("cellar.example.com")
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Response(design.OK)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genproxy.NewGenerator(
+			genproxy.API(design.Design),
+			genproxy.OutDir(outDir),
+			genproxy.Format(format),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("generates the Envoy route configuration by default", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		file := filepath.Join(outDir, "proxy", "envoy.yaml")
+		Ω(files).Should(ConsistOf(filepath.Join(outDir, "proxy"), file))
+		content, err := ioutil.ReadFile(file)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring("- cellar.example.com"))
+		Ω(string(content)).Should(ContainSubstring("cluster: cellar"))
+	})
+
+	Context("with the nginx format", func() {
+		BeforeEach(func() {
+			format = "nginx"
+		})
+
+		It("generates the nginx location blocks", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "proxy", "nginx.conf"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`location ~ "^/bottles/[^/]+$" {`))
+			Ω(string(content)).Should(ContainSubstring("proxy_pass http://cellar;"))
+		})
+	})
+
+	Context("with an unknown format", func() {
+		BeforeEach(func() {
+			format = "haproxy"
+		})
+
+		It("returns an error", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(ContainSubstring(`invalid proxy configuration format "haproxy"`))
+			Ω(files).Should(BeEmpty())
+		})
+	})
+})
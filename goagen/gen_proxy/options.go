@@ -0,0 +1,45 @@
+package genproxy
+
+import (
+	"time"
+
+	"github.com/kyokomi/goa-v1/design"
+)
+
+// Option a generator option definition
+type Option func(*Generator)
+
+// API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+// OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+// Format Configuration format, "envoy" or "nginx"
+func Format(format string) Option {
+	return func(g *Generator) {
+		g.Format = format
+	}
+}
+
+// Cluster Name of the Envoy cluster or nginx upstream serving the API
+func Cluster(cluster string) Option {
+	return func(g *Generator) {
+		g.Cluster = cluster
+	}
+}
+
+// Timeout Timeout of the routes whose design does not set one
+func Timeout(timeout time.Duration) Option {
+	return func(g *Generator) {
+		g.Timeout = timeout
+	}
+}
@@ -0,0 +1,156 @@
+package genproxy
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/dslengine"
+)
+
+// TimeoutKey is the metadata key that sets the timeout of the requests made to an action or file
+// server. The value is a Go duration, e.g. "5s". Action and file server metadata override resource
+// metadata which override API metadata.
+const TimeoutKey = "proxy:timeout"
+
+// Route describes a route of the edge proxy.
+type Route struct {
+	// Name identifies the route, e.g. "bottle.show".
+	Name string
+	// Path is the path of the route using the design syntax, e.g. "/api/bottles/:id".
+	Path string
+	// Method is the HTTP method of the route.
+	Method string
+	// Timeout is the maximum duration of the requests, zero if the proxy uses its default.
+	Timeout time.Duration
+	// WebSocket is true if the route serves a WebSocket endpoint.
+	WebSocket bool
+}
+
+// Routes returns the routes of the edge proxy of the API: one per action route and file server
+// and one OPTIONS route per path that CORS policies apply to. defaultTimeout is the timeout of the
+// routes whose design does not set one. The routes are ordered so that the first route whose
+// path matches a request is the one the API router would pick: static path segments come before
+// parameters which come before wildcards.
+func Routes(api *design.APIDefinition, defaultTimeout time.Duration) ([]*Route, error) {
+	var routes []*Route
+	cors := make(map[string]bool)
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		hasCORS := len(api.Origins) > 0 || len(res.Origins) > 0
+		err := res.IterateFileServers(func(fs *design.FileServerDefinition) error {
+			timeout, err := timeout(defaultTimeout, api.Metadata, res.Metadata, fs.Metadata)
+			if err != nil {
+				return fmt.Errorf("%s: %s", fs.Context(), err)
+			}
+			routes = append(routes, &Route{
+				Name:    res.Name + ".files",
+				Path:    fs.RequestPath,
+				Method:  "GET",
+				Timeout: timeout,
+			})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			timeout, err := timeout(defaultTimeout, api.Metadata, res.Metadata, a.Metadata)
+			if err != nil {
+				return fmt.Errorf("%s: %s", a.Context(), err)
+			}
+			for _, r := range a.Routes {
+				route := &Route{
+					Name:      res.Name + "." + a.Name,
+					Path:      r.FullPath(),
+					Method:    r.Verb,
+					Timeout:   timeout,
+					WebSocket: a.WebSocket(),
+				}
+				routes = append(routes, route)
+				if hasCORS && !cors[route.Path] {
+					cors[route.Path] = true
+					routes = append(routes, &Route{
+						Name:    route.Name + ".preflight",
+						Path:    route.Path,
+						Method:  "OPTIONS",
+						Timeout: timeout,
+					})
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return precedes(routes[i].Path, routes[j].Path)
+	})
+	return routes, nil
+}
+
+// IsStatic returns true if the route path defines no parameter.
+func (r *Route) IsStatic() bool {
+	return !design.WildcardRegex.MatchString(r.Path)
+}
+
+// Regex returns the regular expression that matches the paths of the route, parameters match
+// one path segment and wildcards the rest of the path. The expression is not anchored.
+func (r *Route) Regex() string {
+	var b strings.Builder
+	last := 0
+	for _, m := range design.WildcardRegex.FindAllStringIndex(r.Path, -1) {
+		b.WriteString(regexp.QuoteMeta(r.Path[last:m[0]]))
+		if r.Path[m[0]+1] == '*' {
+			b.WriteString("/.*")
+		} else {
+			b.WriteString("/[^/]+")
+		}
+		last = m[1]
+	}
+	b.WriteString(regexp.QuoteMeta(r.Path[last:]))
+	return b.String()
+}
+
+// timeout returns the timeout set by the last metadata that defines one, def if there is none.
+func timeout(def time.Duration, mdatas ...dslengine.MetadataDefinition) (time.Duration, error) {
+	res := def
+	for _, mdata := range mdatas {
+		vals, ok := mdata[TimeoutKey]
+		if !ok || len(vals) == 0 {
+			continue
+		}
+		d, err := time.ParseDuration(vals[0])
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s metadata: %s", TimeoutKey, err)
+		}
+		res = d
+	}
+	return res, nil
+}
+
+// precedes returns true if the API router matches path a before path b. Segments are compared
+// in order, static segments precede parameters which precede wildcards.
+func precedes(a, b string) bool {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if ka, kb := segmentKind(as[i]), segmentKind(bs[i]); ka != kb {
+			return ka < kb
+		}
+	}
+	return false
+}
+
+// segmentKind returns 0 for static path segments, 1 for parameters and 2 for wildcards.
+func segmentKind(s string) int {
+	switch {
+	case strings.HasPrefix(s, ":"):
+		return 1
+	case strings.HasPrefix(s, "*"):
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,174 @@
+package genproxy_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genproxy "github.com/kyokomi/goa-v1/goagen/gen_proxy"
+)
+
+var _ = Describe("Routes", func() {
+	var routes []*genproxy.Route
+	var routesErr error
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {
+			apidsl.BasePath("/api")
+			apidsl.Metadata("proxy:timeout", "10s")
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.BasePath("/bottles")
+			apidsl.Origin("*", func() {
+				apidsl.Methods("GET")
+			})
+			apidsl.Files("/files/*filepath", "public/")
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/:id"))
+				apidsl.Response(design.OK)
+			})
+			apidsl.Action("latest", func() {
+				apidsl.Routing(apidsl.GET("/latest"))
+				apidsl.Metadata("proxy:timeout", "1500ms")
+				apidsl.Response(design.OK)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		routes, routesErr = genproxy.Routes(design.Design, 0)
+	})
+
+	It("orders static segments before parameters and wildcards", func() {
+		Ω(routesErr).ShouldNot(HaveOccurred())
+		var names []string
+		for _, r := range routes {
+			names = append(names, r.Name)
+		}
+		Ω(names).Should(Equal([]string{
+			"bottle.latest",
+			"bottle.latest.preflight",
+			"bottle.show",
+			"bottle.show.preflight",
+			"bottle.files",
+		}))
+	})
+
+	It("computes the timeouts and regular expressions", func() {
+		Ω(routesErr).ShouldNot(HaveOccurred())
+		Ω(routes[0].Path).Should(Equal("/api/bottles/latest"))
+		Ω(routes[0].IsStatic()).Should(BeTrue())
+		Ω(routes[0].Timeout).Should(Equal(1500 * time.Millisecond))
+		Ω(routes[2].Regex()).Should(Equal("/api/bottles/[^/]+"))
+		Ω(routes[2].Timeout).Should(Equal(10 * time.Second))
+		Ω(routes[3].Method).Should(Equal("OPTIONS"))
+		Ω(routes[4].Regex()).Should(Equal("/files/.*"))
+	})
+
+	Context("with an invalid timeout", func() {
+		BeforeEach(func() {
+			apidsl.Resource("account", func() {
+				apidsl.Metadata("proxy:timeout", "soon")
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET("/accounts"))
+					apidsl.Response(design.OK)
+				})
+			})
+		})
+
+		It("returns an error", func() {
+			Ω(routesErr).Should(HaveOccurred())
+			Ω(routesErr.Error()).Should(ContainSubstring("invalid proxy:timeout metadata"))
+		})
+	})
+})
+
+var _ = Describe("WriteEnvoy", func() {
+	var routes []*genproxy.Route
+	var content string
+
+	BeforeEach(func() {
+		routes = []*genproxy.Route{
+			{Name: "bottle.list", Path: "/bottles", Method: "GET", Timeout: 1500 * time.Millisecond},
+			{Name: "bottle.show", Path: "/bottles/:id", Method: "GET"},
+			{Name: "bottle.watch", Path: "/bottles/:id/watch", Method: "GET", WebSocket: true},
+		}
+	})
+
+	JustBeforeEach(func() {
+		b, err := genproxy.WriteEnvoy("cellar", "", "backend", routes)
+		Ω(err).ShouldNot(HaveOccurred())
+		content = string(b)
+	})
+
+	It("renders the route configuration", func() {
+		Ω(content).Should(ContainSubstring(`  domains:
+  - '*'`))
+		Ω(content).Should(ContainSubstring(`  - name: bottle.list
+    match:
+      path: /bottles
+      headers:
+      - name: :method
+        string_match:
+          exact: GET
+    route:
+      cluster: backend
+      timeout: 1.5s`))
+		Ω(content).Should(ContainSubstring(`      safe_regex:
+        regex: /bottles/[^/]+
+`))
+		Ω(content).Should(ContainSubstring(`      timeout: 0s
+      upgrade_configs:
+      - upgrade_type: websocket`))
+	})
+})
+
+var _ = Describe("WriteNginx", func() {
+	var routes []*genproxy.Route
+	var content string
+
+	BeforeEach(func() {
+		routes = []*genproxy.Route{
+			{Name: "bottle.list", Path: "/bottles", Method: "GET", Timeout: time.Second},
+			{Name: "bottle.create", Path: "/bottles", Method: "POST", Timeout: 1500 * time.Millisecond},
+			{Name: "bottle.watch", Path: "/bottles/:id/watch", Method: "GET", WebSocket: true},
+		}
+	})
+
+	JustBeforeEach(func() {
+		b, err := genproxy.WriteNginx("cellar", "backend", routes)
+		Ω(err).ShouldNot(HaveOccurred())
+		content = string(b)
+	})
+
+	It("groups the routes that share a path", func() {
+		Ω(content).Should(ContainSubstring(`# bottle.list, bottle.create
+location = /bottles {
+    limit_except GET POST {
+        deny all;
+    }
+    proxy_read_timeout 1500ms;
+    proxy_send_timeout 1500ms;
+    proxy_pass http://backend;
+}`))
+	})
+
+	It("enables WebSocket upgrades", func() {
+		Ω(content).Should(ContainSubstring(`location ~ "^/bottles/[^/]+/watch$" {
+    limit_except GET {
+        deny all;
+    }
+    proxy_http_version 1.1;
+    proxy_set_header Upgrade $http_upgrade;
+    proxy_set_header Connection "upgrade";
+    proxy_pass http://backend;
+}`))
+	})
+})
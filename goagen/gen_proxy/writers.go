@@ -0,0 +1,219 @@
+package genproxy
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/version"
+)
+
+type (
+	// envoyRouteConfiguration is the Envoy v3 RouteConfiguration resource.
+	envoyRouteConfiguration struct {
+		Name         string              `yaml:"name"`
+		VirtualHosts []*envoyVirtualHost `yaml:"virtual_hosts"`
+	}
+
+	// envoyVirtualHost is a virtual host of the route configuration.
+	envoyVirtualHost struct {
+		Name    string        `yaml:"name"`
+		Domains []string      `yaml:"domains"`
+		Routes  []*envoyRoute `yaml:"routes"`
+	}
+
+	// envoyRoute is a route of the virtual host.
+	envoyRoute struct {
+		Name  string           `yaml:"name"`
+		Match *envoyRouteMatch `yaml:"match"`
+		Route *envoyAction     `yaml:"route"`
+	}
+
+	// envoyRouteMatch matches the path and method of the requests.
+	envoyRouteMatch struct {
+		Path      string              `yaml:"path,omitempty"`
+		SafeRegex *envoyRegex         `yaml:"safe_regex,omitempty"`
+		Headers   []*envoyHeaderMatch `yaml:"headers"`
+	}
+
+	// envoyRegex is a RE2 regular expression matcher.
+	envoyRegex struct {
+		Regex string `yaml:"regex"`
+	}
+
+	// envoyHeaderMatch matches the value of a request header.
+	envoyHeaderMatch struct {
+		Name        string            `yaml:"name"`
+		StringMatch map[string]string `yaml:"string_match"`
+	}
+
+	// envoyAction forwards the requests to the cluster.
+	envoyAction struct {
+		Cluster        string          `yaml:"cluster"`
+		Timeout        string          `yaml:"timeout,omitempty"`
+		UpgradeConfigs []*envoyUpgrade `yaml:"upgrade_configs,omitempty"`
+	}
+
+	// envoyUpgrade enables an HTTP upgrade on the route.
+	envoyUpgrade struct {
+		UpgradeType string `yaml:"upgrade_type"`
+	}
+
+	// nginxLocation is a location block, it groups the routes that share a path.
+	nginxLocation struct {
+		// Modifier is "=" for static paths and "~" for regular expressions.
+		Modifier string
+		// Pattern is the path or the quoted anchored regular expression.
+		Pattern string
+		// Names lists the names of the routes.
+		Names []string
+		// Methods lists the methods of the routes.
+		Methods []string
+		// Timeout is the longest timeout of the routes.
+		Timeout time.Duration
+		// WebSocket is true if one of the routes serves a WebSocket endpoint.
+		WebSocket bool
+	}
+)
+
+// WriteEnvoy renders the Envoy RouteConfiguration that forwards the requests matching the routes
+// to the given cluster. name is the name of the configuration and of its virtual host, the virtual
+// host matches the given domain or all domains if host is empty.
+func WriteEnvoy(name, host, cluster string, routes []*Route) ([]byte, error) {
+	vh := &envoyVirtualHost{Name: name, Domains: []string{"*"}}
+	if host != "" {
+		vh.Domains = []string{host}
+	}
+	for _, r := range routes {
+		match := &envoyRouteMatch{
+			Headers: []*envoyHeaderMatch{{
+				Name:        ":method",
+				StringMatch: map[string]string{"exact": r.Method},
+			}},
+		}
+		if r.IsStatic() {
+			match.Path = r.Path
+		} else {
+			match.SafeRegex = &envoyRegex{Regex: r.Regex()}
+		}
+		action := &envoyAction{Cluster: cluster}
+		if r.Timeout > 0 {
+			action.Timeout = envoyDuration(r.Timeout)
+		}
+		if r.WebSocket {
+			action.UpgradeConfigs = []*envoyUpgrade{{UpgradeType: "websocket"}}
+			if r.Timeout == 0 {
+				// Do not close long lived connections
+				action.Timeout = "0s"
+			}
+		}
+		vh.Routes = append(vh.Routes, &envoyRoute{Name: r.Name, Match: match, Route: action})
+	}
+	conf := &envoyRouteConfiguration{Name: name, VirtualHosts: []*envoyVirtualHost{vh}}
+	b, err := yaml.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+	header := fmt.Sprintf("# Code generated by goagen %s, DO NOT EDIT.\n#\n# Envoy route configuration of the %s API, the %s cluster must be defined separately.\n", version.String(), name, cluster)
+	return append([]byte(header), b...), nil
+}
+
+// WriteNginx renders the nginx location blocks that forward the requests matching the routes to
+// the given upstream. The routes that share a path are grouped in one location.
+func WriteNginx(name, upstream string, routes []*Route) ([]byte, error) {
+	var locations []*nginxLocation
+	byPath := make(map[string]*nginxLocation)
+	for _, r := range routes {
+		loc, ok := byPath[r.Path]
+		if !ok {
+			loc = &nginxLocation{Modifier: "=", Pattern: r.Path}
+			if !r.IsStatic() {
+				loc.Modifier = "~"
+				loc.Pattern = strconv.Quote("^" + r.Regex() + "$")
+			}
+			byPath[r.Path] = loc
+			locations = append(locations, loc)
+		}
+		if !contains(loc.Names, r.Name) {
+			loc.Names = append(loc.Names, r.Name)
+		}
+		if !contains(loc.Methods, r.Method) {
+			loc.Methods = append(loc.Methods, r.Method)
+		}
+		if r.Timeout > loc.Timeout {
+			loc.Timeout = r.Timeout
+		}
+		loc.WebSocket = loc.WebSocket || r.WebSocket
+	}
+	tmpl, err := template.New("nginx").Funcs(template.FuncMap{
+		"join":     strings.Join,
+		"duration": nginxDuration,
+	}).Parse(codegen.Template("proxy", "nginx", nginxT))
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]interface{}{
+		"ToolVersion": version.String(),
+		"Name":        name,
+		"Upstream":    upstream,
+		"Locations":   locations,
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// envoyDuration returns the JSON representation of d used by Envoy, e.g. "1.5s".
+func envoyDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// nginxDuration returns the representation of d used by nginx, whole seconds are expressed in
+// seconds and other durations in milliseconds.
+func nginxDuration(d time.Duration) string {
+	if d%time.Second == 0 {
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+	return fmt.Sprintf("%dms", (d+time.Millisecond-1)/time.Millisecond)
+}
+
+// contains returns true if vals contains val.
+func contains(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+const nginxT = `# Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+#
+# nginx locations of the {{ .Name }} API, include this file in the server block proxying the API.
+# The {{ .Upstream }} upstream must be defined separately, e.g.:
+#
+#   upstream {{ .Upstream }} {
+#       server localhost:8080;
+#   }
+{{ range .Locations }}
+# {{ join .Names ", " }}
+location {{ .Modifier }} {{ .Pattern }} {
+    limit_except {{ join .Methods " " }} {
+        deny all;
+    }
+{{ if .Timeout }}    proxy_read_timeout {{ duration .Timeout }};
+    proxy_send_timeout {{ duration .Timeout }};
+{{ end }}{{ if .WebSocket }}    proxy_http_version 1.1;
+    proxy_set_header Upgrade $http_upgrade;
+    proxy_set_header Connection "upgrade";
+{{ end }}    proxy_pass http://{{ $.Upstream }};
+}
+{{ end }}`
@@ -0,0 +1,16 @@
+/*
+Package genpython provides a generator for a Python client package of the API. The generator is
+invoked with "goagen client --language=python" and produces a package that contains:
+
+  - models.py: a dataclass for each user type, media type view and payload with from_dict and
+    to_dict methods that convert to and from the JSON representation,
+  - errors.py: an exception for each declared error response, all exceptions inherit from
+    APIError,
+  - client.py: a Client class with one method per action route.
+
+The client uses a requests.Session by default, any session object that implements the same
+request method such as a httpx.Client may be given instead. The methods return the decoded body
+of the successful responses and raise the exception that corresponds to the declared error
+responses or UnexpectedResponseError for the statuses that are not described in the design.
+*/
+package genpython
@@ -0,0 +1,667 @@
+package genpython
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+type (
+	// Package describes the content of the generated Python package.
+	Package struct {
+		// Name of the Python package.
+		Name string
+		// BaseURL is the default base URL of the client.
+		BaseURL string
+		// Classes lists the dataclasses and type aliases sorted by name.
+		Classes []*Class
+		// Errors lists the exceptions raised for the declared error responses sorted by name.
+		Errors []*Error
+		// Methods lists the client methods sorted by name.
+		Methods []*Method
+	}
+
+	// Class describes a dataclass or a type alias.
+	Class struct {
+		// Name of the class.
+		Name string
+		// Description of the class.
+		Description string
+		// Fields lists the dataclass fields, required fields first then sorted by name.
+		Fields []*Field
+		// Alias is the aliased type annotation, empty for dataclasses.
+		Alias string
+		// DataType is the goa type described by the class.
+		DataType design.DataType
+	}
+
+	// Field describes a dataclass field.
+	Field struct {
+		// Name of the field.
+		Name string
+		// AttName is the name of the attribute in the JSON representation.
+		AttName string
+		// Type is the type annotation of the field.
+		Type string
+		// Required is true if the attribute is required.
+		Required bool
+		// Description of the field.
+		Description string
+		// Decode is the expression that builds the field value from the "data" dictionary.
+		Decode string
+		// Encode is the expression that builds the JSON value from the field value.
+		Encode string
+	}
+
+	// Error describes an exception raised for a declared error response.
+	Error struct {
+		// Name of the exception class.
+		Name string
+		// Response is the name of the response in the design.
+		Response string
+		// Status is the HTTP status code of the response.
+		Status int
+	}
+
+	// Method describes a client method that sends requests to an action route.
+	Method struct {
+		// Name of the method.
+		Name string
+		// Description of the method.
+		Description string
+		// Verb is the HTTP method of the requests.
+		Verb string
+		// Path is the Python f-string that builds the request path.
+		Path string
+		// Args lists the method arguments, required arguments first.
+		Args []*Arg
+		// Query lists the query string parameters.
+		Query []*Param
+		// Headers lists the header parameters.
+		Headers []*Param
+		// Payload is the expression that encodes the payload, empty if there is none.
+		Payload string
+		// Multipart is true if the payload is sent as multipart form data.
+		Multipart bool
+		// Returns is the return type annotation.
+		Returns string
+		// Responses lists the declared responses sorted by status.
+		Responses []*Response
+	}
+
+	// Arg describes a method argument.
+	Arg struct {
+		// Name of the argument.
+		Name string
+		// Type is the type annotation of the argument.
+		Type string
+		// Required is true if the argument has no default value.
+		Required bool
+	}
+
+	// Param describes a query string or header parameter.
+	Param struct {
+		// Name of the parameter in the request.
+		Name string
+		// Arg is the name of the argument that holds the parameter value.
+		Arg string
+	}
+
+	// Response describes a declared response.
+	Response struct {
+		// Status is the HTTP status code of the response.
+		Status int
+		// Type is the type annotation of the decoded body.
+		Type string
+		// Decode is the expression that decodes the body of "resp".
+		Decode string
+		// Error is the name of the exception raised for the response, empty for 2xx responses.
+		Error string
+	}
+)
+
+// reservedNames lists the Python keywords and the names used by the generated code, names that
+// use one of these get a "_" suffix.
+var reservedNames = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true,
+	"async": true, "await": true, "break": true, "class": true, "continue": true, "def": true,
+	"del": true, "elif": true, "else": true, "except": true, "finally": true, "for": true,
+	"from": true, "global": true, "if": true, "import": true, "in": true, "is": true,
+	"lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true, "raise": true,
+	"return": true, "try": true, "while": true, "with": true, "yield": true,
+	"self": true, "resp": true, "data": true, "res": true, "payload": true,
+}
+
+// reservedClassNames lists the names used by the generated module and the typing module,
+// classes that use one of these get the "Type" suffix.
+var reservedClassNames = map[string]bool{
+	"Any": true, "APIError": true, "Client": true, "Dict": true, "List": true,
+	"Optional": true, "UnexpectedResponseError": true, "Union": true,
+}
+
+// invalidNameChars matches the characters that cannot be used in Python identifiers.
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// underscores matches sequences of underscores.
+var underscores = regexp.MustCompile(`_+`)
+
+// camelBoundary matches the boundaries between the words of camel case names.
+var camelBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
+// New builds the description of the Python client package of the API. The package contains a
+// dataclass for each user type, media type view and payload, an exception for each declared
+// error response and a client method for each action route.
+func New(api *design.APIDefinition, name, baseURL string) (*Package, error) {
+	b := &builder{api: api, classes: make(map[string]*Class), errors: make(map[string]*Error)}
+	err := api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		_, err := b.className(ut)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() {
+			return nil
+		}
+		return mt.IterateViews(func(v *design.ViewDefinition) error {
+			p, _, err := mt.Project(v.Name)
+			if err != nil {
+				return err
+			}
+			_, err = b.className(p)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	p := &Package{Name: name, BaseURL: baseURL}
+	err = api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() {
+				return nil
+			}
+			for i, r := range a.Routes {
+				m, err := b.method(a, r, i)
+				if err != nil {
+					return fmt.Errorf("%s: %s", a.Context(), err)
+				}
+				p.Methods = append(p.Methods, m)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(p.Methods, func(i, j int) bool { return p.Methods[i].Name < p.Methods[j].Name })
+	for _, c := range b.classes {
+		p.Classes = append(p.Classes, c)
+	}
+	sort.Slice(p.Classes, func(i, j int) bool { return p.Classes[i].Name < p.Classes[j].Name })
+	for _, e := range b.errors {
+		p.Errors = append(p.Errors, e)
+	}
+	sort.Slice(p.Errors, func(i, j int) bool { return p.Errors[i].Name < p.Errors[j].Name })
+	return p, nil
+}
+
+// builder computes the classes, errors and methods.
+type builder struct {
+	api     *design.APIDefinition
+	classes map[string]*Class
+	errors  map[string]*Error
+}
+
+// method builds the client method that sends requests to the i-th route of the action.
+func (b *builder) method(a *design.ActionDefinition, r *design.RouteDefinition, i int) (*Method, error) {
+	name := pyName(a.Name) + "_" + pyName(a.Parent.Name)
+	if i > 0 {
+		name += strconv.Itoa(i + 1)
+	}
+	m := &Method{
+		Name:        name,
+		Description: a.Description,
+		Verb:        r.Verb,
+		Multipart:   a.PayloadMultipart,
+	}
+	if m.Description == "" {
+		m.Description = fmt.Sprintf("%s calls the %s action of the %s resource.", name, a.Name, a.Parent.Name)
+	}
+
+	var optional []*Arg
+	all := a.AllParams()
+	pathParams := r.Params()
+	path := r.FullPath()
+	for _, p := range pathParams {
+		at := all.Type.ToObject()[p]
+		if at == nil {
+			at = &design.AttributeDefinition{Type: design.String}
+		}
+		typ, err := b.pyType(at)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %#v: %s", p, err)
+		}
+		arg := &Arg{Name: pyName(p), Type: typ, Required: true}
+		m.Args = append(m.Args, arg)
+		path = replaceWildcard(path, p, arg.Name)
+	}
+	m.Path = strconv.Quote(path)
+	if len(pathParams) > 0 {
+		m.Path = "f" + m.Path
+	}
+
+	if a.Payload != nil {
+		typ, err := b.className(a.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("payload: %s", err)
+		}
+		payload := &design.AttributeDefinition{Type: a.Payload}
+		m.Payload = encode(payload, "payload", 0)
+		if a.PayloadOptional {
+			m.Payload = fmt.Sprintf("None if payload is None else %s", m.Payload)
+			optional = append(optional, &Arg{Name: "payload", Type: "Optional[" + typ + "]"})
+		} else {
+			m.Args = append(m.Args, &Arg{Name: "payload", Type: typ, Required: true})
+		}
+	}
+
+	addParams := func(params *design.AttributeDefinition, header bool) error {
+		if params == nil {
+			return nil
+		}
+		for _, n := range codegen.SortedNames(params.Type.ToObject()) {
+			if !header && codegen.ContainsString(pathParams, n) {
+				continue
+			}
+			at := params.Type.ToObject()[n]
+			typ, err := b.pyType(at)
+			if err != nil {
+				return fmt.Errorf("parameter %#v: %s", n, err)
+			}
+			arg := &Arg{Name: pyName(n), Type: typ, Required: params.IsRequired(n)}
+			if arg.Required {
+				m.Args = append(m.Args, arg)
+			} else {
+				arg.Type = "Optional[" + typ + "]"
+				optional = append(optional, arg)
+			}
+			param := &Param{Name: n, Arg: arg.Name}
+			if header {
+				m.Headers = append(m.Headers, param)
+			} else {
+				m.Query = append(m.Query, param)
+			}
+		}
+		return nil
+	}
+	if err := addParams(a.QueryParams, false); err != nil {
+		return nil, err
+	}
+	if err := addParams(a.Headers, true); err != nil {
+		return nil, err
+	}
+	m.Args = append(m.Args, optional...)
+
+	var returns []string
+	err := a.IterateResponses(func(resp *design.ResponseDefinition) error {
+		res, err := b.response(resp)
+		if err != nil {
+			return fmt.Errorf("response %#v: %s", resp.Name, err)
+		}
+		if res.Error == "" && !codegen.ContainsString(returns, res.Type) {
+			returns = append(returns, res.Type)
+		}
+		m.Responses = append(m.Responses, res)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(m.Responses, func(i, j int) bool { return m.Responses[i].Status < m.Responses[j].Status })
+	m.Returns = returnType(returns)
+	return m, nil
+}
+
+// response builds the description of the given action response and registers its exception if
+// the response is not a 2xx response.
+func (b *builder) response(r *design.ResponseDefinition) (*Response, error) {
+	res := &Response{Status: r.Status, Type: "None", Decode: "None"}
+	if r.Status < http.StatusOK || r.Status >= http.StatusMultipleChoices {
+		name := pyClassName(r.Name) + "Error"
+		if e, ok := b.errors[name]; ok && e.Status != r.Status {
+			return nil, fmt.Errorf("responses %#v with status %d and %d use the same name", r.Name, e.Status, r.Status)
+		}
+		b.errors[name] = &Error{Name: name, Response: r.Name, Status: r.Status}
+		res.Error = name
+	}
+	if r.Status == http.StatusNoContent {
+		return res, nil
+	}
+	var at *design.AttributeDefinition
+	if r.MediaType != "" {
+		mt, ok := b.api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]
+		if !ok {
+			res.Type, res.Decode = "str", "resp.text"
+			return res, nil
+		}
+		view := r.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return nil, err
+		}
+		at = &design.AttributeDefinition{Type: p}
+	} else if r.Type != nil {
+		at = &design.AttributeDefinition{Type: r.Type}
+	}
+	if at == nil {
+		return res, nil
+	}
+	typ, err := b.pyType(at)
+	if err != nil {
+		return nil, err
+	}
+	res.Type, res.Decode = typ, decode(at, "resp.json()", 0)
+	return res, nil
+}
+
+// className registers the class that corresponds to the given user type or media type and
+// returns its name.
+func (b *builder) className(t design.DataType) (string, error) {
+	var ut *design.UserTypeDefinition
+	switch actual := t.(type) {
+	case *design.MediaTypeDefinition:
+		if !isProjected(actual) {
+			p, _, err := actual.Project(design.DefaultView)
+			if err != nil {
+				return "", err
+			}
+			actual = p
+			t = p
+		}
+		ut = actual.UserTypeDefinition
+	case *design.UserTypeDefinition:
+		ut = actual
+	default:
+		return "", fmt.Errorf("%s is not a user type", t.Name())
+	}
+	name := pyClassName(codegen.GoTypeName(t, nil, 0, false))
+	if c, ok := b.classes[name]; ok {
+		if c.DataType.Name() != t.Name() {
+			return "", fmt.Errorf("class %s is already defined", name)
+		}
+		return name, nil
+	}
+	c := &Class{Name: name, Description: ut.Description, DataType: t}
+	b.classes[name] = c
+	if !ut.Type.IsObject() {
+		alias, err := b.pyType(ut.AttributeDefinition)
+		if err != nil {
+			delete(b.classes, name)
+			return "", fmt.Errorf("type %s: %s", name, err)
+		}
+		c.Alias = alias
+		return name, nil
+	}
+	var required, optional []*Field
+	obj := ut.Type.ToObject()
+	for _, n := range codegen.SortedNames(obj) {
+		at := obj[n]
+		typ, err := b.pyType(at)
+		if err != nil {
+			delete(b.classes, name)
+			return "", fmt.Errorf("type %s: attribute %#v: %s", name, n, err)
+		}
+		f := &Field{
+			Name:        pyName(n),
+			AttName:     n,
+			Type:        typ,
+			Required:    ut.IsRequired(n),
+			Description: at.Description,
+			Encode:      encode(at, "self."+pyName(n), 0),
+		}
+		key := strconv.Quote(n)
+		if f.Required {
+			f.Decode = decode(at, "data["+key+"]", 0)
+			required = append(required, f)
+			continue
+		}
+		f.Type = "Optional[" + typ + "]"
+		f.Decode = decode(at, "data["+key+"]", 0)
+		if f.Decode == "data["+key+"]" {
+			f.Decode = "data.get(" + key + ")"
+		} else {
+			f.Decode = fmt.Sprintf("%s if data.get(%s) is not None else None", f.Decode, key)
+		}
+		optional = append(optional, f)
+	}
+	c.Fields = append(required, optional...)
+	return name, nil
+}
+
+// pyType returns the type annotation that describes values of the given attribute.
+func (b *builder) pyType(at *design.AttributeDefinition) (string, error) {
+	switch actual := at.Type.(type) {
+	case design.Primitive:
+		return primitiveType(actual), nil
+	case *design.Array:
+		elem, err := b.pyType(actual.ElemType)
+		if err != nil {
+			return "", err
+		}
+		return "List[" + elem + "]", nil
+	case *design.Hash:
+		key, err := b.pyType(actual.KeyType)
+		if err != nil {
+			return "", err
+		}
+		elem, err := b.pyType(actual.ElemType)
+		if err != nil {
+			return "", err
+		}
+		return "Dict[" + key + ", " + elem + "]", nil
+	case design.Object:
+		return "Dict[str, Any]", nil
+	case *design.MediaTypeDefinition:
+		t, err := projected(at)
+		if err != nil {
+			return "", err
+		}
+		return b.className(t)
+	case *design.UserTypeDefinition:
+		return b.className(actual)
+	}
+	return "", fmt.Errorf("unsupported type %s", at.Type.Name())
+}
+
+// decode returns the expression that builds the value of the given attribute from its JSON
+// representation expr.
+func decode(at *design.AttributeDefinition, expr string, depth int) string {
+	switch actual := at.Type.(type) {
+	case *design.Array:
+		v := fmt.Sprintf("v%d", depth)
+		elem := decode(actual.ElemType, v, depth+1)
+		if elem == v {
+			return expr
+		}
+		return fmt.Sprintf("[%s for %s in %s]", elem, v, expr)
+	case *design.Hash:
+		k, v := fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth)
+		elem := decode(actual.ElemType, v, depth+1)
+		if elem == v {
+			return expr
+		}
+		return fmt.Sprintf("{%s: %s for %s, %s in %s.items()}", k, elem, k, v, expr)
+	case *design.MediaTypeDefinition, *design.UserTypeDefinition:
+		t, err := projected(at)
+		if err != nil {
+			return expr
+		}
+		ut := userType(t)
+		if !ut.Type.IsObject() {
+			return decode(ut.AttributeDefinition, expr, depth)
+		}
+		return fmt.Sprintf("%s.from_dict(%s)", pyClassName(codegen.GoTypeName(t, nil, 0, false)), expr)
+	}
+	return expr
+}
+
+// encode returns the expression that builds the JSON representation of the value expr of the
+// given attribute.
+func encode(at *design.AttributeDefinition, expr string, depth int) string {
+	switch actual := at.Type.(type) {
+	case *design.Array:
+		v := fmt.Sprintf("v%d", depth)
+		elem := encode(actual.ElemType, v, depth+1)
+		if elem == v {
+			return expr
+		}
+		return fmt.Sprintf("[%s for %s in %s]", elem, v, expr)
+	case *design.Hash:
+		k, v := fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth)
+		elem := encode(actual.ElemType, v, depth+1)
+		if elem == v {
+			return expr
+		}
+		return fmt.Sprintf("{%s: %s for %s, %s in %s.items()}", k, elem, k, v, expr)
+	case *design.MediaTypeDefinition, *design.UserTypeDefinition:
+		t, err := projected(at)
+		if err != nil {
+			return expr
+		}
+		ut := userType(t)
+		if !ut.Type.IsObject() {
+			return encode(ut.AttributeDefinition, expr, depth)
+		}
+		return expr + ".to_dict()"
+	}
+	return expr
+}
+
+// primitiveType returns the type annotation used to represent values of the given primitive.
+func primitiveType(p design.Primitive) string {
+	switch p.Kind() {
+	case design.BooleanKind:
+		return "bool"
+	case design.IntegerKind:
+		return "int"
+	case design.NumberKind:
+		return "float"
+	case design.StringKind, design.DateTimeKind, design.UUIDKind, design.DecimalKind:
+		return "str"
+	case design.AnyKind, design.FileKind:
+		return "Any"
+	}
+	if c := design.CustomPrimitive(p); c != nil {
+		switch c.JSONType {
+		case "integer":
+			return "int"
+		case "number":
+			return "float"
+		case "boolean":
+			return "bool"
+		}
+		return "str"
+	}
+	return "Any"
+}
+
+// returnType returns the return type annotation of a method whose successful responses have
+// the given types.
+func returnType(types []string) string {
+	var others []string
+	none := false
+	for _, t := range types {
+		if t == "None" {
+			none = true
+			continue
+		}
+		others = append(others, t)
+	}
+	var res string
+	switch len(others) {
+	case 0:
+		return "None"
+	case 1:
+		res = others[0]
+	default:
+		res = "Union[" + strings.Join(others, ", ") + "]"
+	}
+	if none {
+		res = "Optional[" + res + "]"
+	}
+	return res
+}
+
+// projected returns the type of the attribute, media types are projected using the view of the
+// attribute or the default view.
+func projected(at *design.AttributeDefinition) (design.DataType, error) {
+	mt, ok := at.Type.(*design.MediaTypeDefinition)
+	if !ok || isProjected(mt) {
+		return at.Type, nil
+	}
+	view := at.View
+	if view == "" {
+		view = design.DefaultView
+	}
+	p, _, err := mt.Project(view)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// userType returns the user type definition of the given user type or media type.
+func userType(t design.DataType) *design.UserTypeDefinition {
+	if mt, ok := t.(*design.MediaTypeDefinition); ok {
+		return mt.UserTypeDefinition
+	}
+	return t.(*design.UserTypeDefinition)
+}
+
+// isProjected returns true if the media type is the result of a projection.
+func isProjected(mt *design.MediaTypeDefinition) bool {
+	return strings.Contains(mt.Identifier, "view=")
+}
+
+// pyName returns the snake case Python identifier that corresponds to the given name.
+func pyName(n string) string {
+	name := invalidNameChars.ReplaceAllString(n, "_")
+	name = codegen.SnakeCase(camelBoundary.ReplaceAllString(name, "${1}_${2}"))
+	name = strings.Trim(underscores.ReplaceAllString(name, "_"), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	if reservedNames[name] {
+		name += "_"
+	}
+	return name
+}
+
+// pyClassName returns the Python class name that corresponds to the given name.
+func pyClassName(n string) string {
+	name := invalidNameChars.ReplaceAllString(codegen.Goify(n, true), "")
+	if reservedClassNames[name] {
+		name += "Type"
+	}
+	return name
+}
+
+// replaceWildcard replaces the wildcard of the path parameter p with the expression that encodes
+// the value of the argument arg.
+func replaceWildcard(path, p, arg string) string {
+	if strings.Contains(path, "*"+p) {
+		return strings.Replace(path, "*"+p, "{_path("+arg+", safe='/')}", 1)
+	}
+	return regexp.MustCompile(`:`+regexp.QuoteMeta(p)+`\b`).ReplaceAllLiteralString(path, "{_path("+arg+")}")
+}
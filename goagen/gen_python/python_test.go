@@ -0,0 +1,156 @@
+package genpython_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genpython "github.com/kyokomi/goa-v1/goagen/gen_python"
+)
+
+var _ = Describe("New", func() {
+	var pkg *genpython.Package
+	var newErr error
+
+	BeforeEach(func() {
+		pkg = nil
+		newErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		pkg, newErr = genpython.New(Design, "cellar_client", "https://api.example.com")
+	})
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			winery := apidsl.Type("Winery", func() {
+				apidsl.Attribute("name", String)
+				apidsl.Required("name")
+			})
+			bottle := apidsl.MediaType("application/vnd.bottle", func() {
+				apidsl.Description("A bottle of wine")
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer, "ID of bottle")
+					apidsl.Attribute("name", String)
+					apidsl.Attribute("winery", winery)
+					apidsl.Attribute("awards", apidsl.ArrayOf(winery))
+					apidsl.Attribute("createdAt", DateTime)
+					apidsl.Required("id", "name")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+					apidsl.Attribute("winery")
+					apidsl.Attribute("awards")
+					apidsl.Attribute("createdAt")
+				})
+			})
+			apidsl.API("cellar", func() {
+				apidsl.BasePath("/api")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Description("Get bottle by id")
+					apidsl.Routing(apidsl.GET("/:bottleID"))
+					apidsl.Params(func() {
+						apidsl.Param("bottleID", Integer)
+						apidsl.Param("fields", apidsl.ArrayOf(String))
+					})
+					apidsl.Headers(func() {
+						apidsl.Header("X-Request-Id")
+					})
+					apidsl.Response(OK, bottle)
+					apidsl.Response(NotFound)
+					apidsl.Response(BadRequest, ErrorMedia)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(func() {
+						apidsl.Member("name", String)
+						apidsl.Member("winery", winery)
+						apidsl.Required("name")
+					})
+					apidsl.Params(func() {
+						apidsl.Param("dry_run", Boolean)
+						apidsl.Required("dry_run")
+					})
+					apidsl.Response(Created)
+					apidsl.Response(BadRequest, ErrorMedia)
+				})
+			})
+		})
+
+		It("generates the classes", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			var names []string
+			for _, c := range pkg.Classes {
+				names = append(names, c.Name)
+			}
+			Ω(names).Should(Equal([]string{"Bottle", "CreateBottlePayload", "Error", "Winery"}))
+			bottle := pkg.Classes[0]
+			Ω(bottle.Fields).Should(HaveLen(5))
+			Ω(bottle.Fields[0].Name).Should(Equal("id"))
+			Ω(bottle.Fields[0].Type).Should(Equal("int"))
+			Ω(bottle.Fields[1].Name).Should(Equal("name"))
+			Ω(bottle.Fields[2].Name).Should(Equal("awards"))
+			Ω(bottle.Fields[2].Type).Should(Equal("Optional[List[Winery]]"))
+			Ω(bottle.Fields[2].Decode).Should(Equal(`[Winery.from_dict(v0) for v0 in data["awards"]] if data.get("awards") is not None else None`))
+			Ω(bottle.Fields[2].Encode).Should(Equal("[v0.to_dict() for v0 in self.awards]"))
+			Ω(bottle.Fields[3].Name).Should(Equal("created_at"))
+			Ω(bottle.Fields[3].AttName).Should(Equal("createdAt"))
+			Ω(bottle.Fields[3].Decode).Should(Equal(`data.get("createdAt")`))
+			Ω(bottle.Fields[4].Decode).Should(Equal(`Winery.from_dict(data["winery"]) if data.get("winery") is not None else None`))
+		})
+
+		It("generates the errors", func() {
+			Ω(pkg.Errors).Should(HaveLen(2))
+			Ω(pkg.Errors[0].Name).Should(Equal("BadRequestError"))
+			Ω(pkg.Errors[0].Status).Should(Equal(400))
+			Ω(pkg.Errors[1].Name).Should(Equal("NotFoundError"))
+		})
+
+		It("generates the methods", func() {
+			Ω(pkg.Methods).Should(HaveLen(2))
+			create := pkg.Methods[0]
+			Ω(create.Name).Should(Equal("create_bottle"))
+			Ω(create.Signature()).Should(Equal("self, payload: CreateBottlePayload, dry_run: bool"))
+			Ω(create.Payload).Should(Equal("payload.to_dict()"))
+			Ω(create.Returns).Should(Equal("None"))
+			show := pkg.Methods[1]
+			Ω(show.Name).Should(Equal("show_bottle"))
+			Ω(show.Path).Should(Equal(`f"/api/bottles/{_path(bottle_id)}"`))
+			Ω(show.Signature()).Should(Equal("self, bottle_id: int, fields: Optional[List[str]] = None, x_request_id: Optional[str] = None"))
+			Ω(show.Returns).Should(Equal("Bottle"))
+			Ω(show.Responses).Should(HaveLen(3))
+			Ω(show.Responses[1].Error).Should(Equal("BadRequestError"))
+			Ω(show.Responses[1].Decode).Should(Equal("Error.from_dict(resp.json())"))
+		})
+
+		It("renders the modules", func() {
+			models, err := pkg.Write("models.py", "cellar: Python Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(models)).Should(ContainSubstring("@dataclass\nclass Bottle:\n    \"\"\"A bottle of wine (default view)\"\"\"\n\n    id: int\n    \"\"\"ID of bottle\"\"\"\n"))
+			Ω(string(models)).Should(ContainSubstring("        if self.winery is not None:\n            res[\"winery\"] = self.winery.to_dict()\n"))
+			errors, err := pkg.Write("errors.py", "cellar: Python Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(errors)).Should(ContainSubstring("class NotFoundError(APIError):\n"))
+			client, err := pkg.Write("client.py", "cellar: Python Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(client)).Should(ContainSubstring(`DEFAULT_BASE_URL = "https://api.example.com"`))
+			Ω(string(client)).Should(ContainSubstring("    def show_bottle(self, bottle_id: int, fields: Optional[List[str]] = None, x_request_id: Optional[str] = None) -> Bottle:\n"))
+			Ω(string(client)).Should(ContainSubstring(`            query={"fields": fields},` + "\n"))
+			Ω(string(client)).Should(ContainSubstring(`            headers={"X-Request-Id": x_request_id},` + "\n"))
+			Ω(string(client)).Should(ContainSubstring("        if resp.status_code == 404:\n            raise NotFoundError(404, None, resp)\n"))
+			Ω(string(client)).Should(ContainSubstring("        if resp.status_code == 200:\n            return Bottle.from_dict(resp.json())\n"))
+			_, err = pkg.Write("setup.py", "cellar: Python Client")
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,283 @@
+package genpython
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1/version"
+)
+
+// Files lists the names of the generated Python modules in order of generation.
+var Files = []string{"__init__.py", "models.py", "errors.py", "client.py"}
+
+// templates maps the names of the generated Python modules to their template.
+var templates = map[string]*template.Template{
+	"__init__.py": newTemplate("init", initT),
+	"models.py":   newTemplate("models", modelsT),
+	"errors.py":   newTemplate("errors", errorsT),
+	"client.py":   newTemplate("client", clientT),
+}
+
+// newTemplate parses the given template text.
+func newTemplate(name, text string) *template.Template {
+	return template.Must(template.New(name).Funcs(template.FuncMap{
+		"docstring": docstring,
+		"quote":     quote,
+	}).Parse(text))
+}
+
+// Write renders the Python module with the given name, title is written in the header comment.
+func (p *Package) Write(module, title string) ([]byte, error) {
+	tmpl, ok := templates[module]
+	if !ok {
+		return nil, fmt.Errorf("unknown module %s", module)
+	}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"Title":       title,
+		"ToolVersion": version.String(),
+		"Package":     p,
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Exports returns the names exported by the package sorted by name.
+func (p *Package) Exports() []string {
+	names := []string{"APIError", "Client", "UnexpectedResponseError"}
+	for _, c := range p.Classes {
+		names = append(names, c.Name)
+	}
+	for _, e := range p.Errors {
+		names = append(names, e.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Signature returns the list of arguments of the method.
+func (m *Method) Signature() string {
+	args := []string{"self"}
+	for _, a := range m.Args {
+		if a.Required {
+			args = append(args, fmt.Sprintf("%s: %s", a.Name, a.Type))
+		} else {
+			args = append(args, fmt.Sprintf("%s: %s = None", a.Name, a.Type))
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
+// Doc returns the docstring of the method, it lists the exceptions raised for the declared
+// error responses.
+func (m *Method) Doc() string {
+	var raises []string
+	for _, r := range m.Responses {
+		if r.Error != "" {
+			raises = append(raises, fmt.Sprintf("    %s: the API returned the %d %s response, body is %s.",
+				r.Error, r.Status, http.StatusText(r.Status), r.Type))
+		}
+	}
+	if len(raises) > 0 {
+		raises = append([]string{"Raises:"}, raises...)
+	}
+	return docstring("        ", m.Description, raises...)
+}
+
+// docstring renders the given text as a Python docstring, indent is prepended to each line
+// after the first one. extra lines are appended after a blank line.
+func docstring(indent, text string, extra ...string) string {
+	text = strings.TrimSpace(strings.Replace(text, `"""`, `\"\"\"`, -1))
+	lines := strings.Split(text, "\n")
+	if len(extra) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, extra...)
+	}
+	if len(lines) == 1 {
+		return `"""` + lines[0] + `"""`
+	}
+	for i := 1; i < len(lines); i++ {
+		lines[i] = strings.TrimRight(indent+lines[i], " ")
+	}
+	return `"""` + strings.Join(lines, "\n") + "\n" + indent + `"""`
+}
+
+// quote returns the Python string literal of s.
+func quote(s string) string {
+	return fmt.Sprintf("%q", s)
+}
+
+const headerT = `# Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+#
+# {{ .Title }}
+`
+
+const initT = headerT + `
+from .client import Client
+from .errors import APIError, UnexpectedResponseError{{ range .Package.Errors }}, {{ .Name }}{{ end }}
+{{ if .Package.Classes }}from .models import {{ range $i, $c := .Package.Classes }}{{ if $i }}, {{ end }}{{ $c.Name }}{{ end }}
+{{ end }}
+__all__ = [{{ range $i, $n := .Package.Exports }}{{ if $i }}, {{ end }}{{ quote $n }}{{ end }}]
+`
+
+const modelsT = headerT + `
+from __future__ import annotations
+
+from dataclasses import dataclass
+from typing import Any, Dict, List, Optional
+{{ range .Package.Classes }}{{ if .Alias }}
+
+{{ .Name }} = {{ .Alias }}
+{{ if .Description }}{{ docstring "" .Description }}
+{{ end }}{{ else }}
+
+@dataclass
+class {{ .Name }}:
+    {{ if .Description }}{{ docstring "    " .Description }}{{ else }}"""{{ .Name }} is the {{ .Name }} type."""{{ end }}
+{{ range .Fields }}
+    {{ .Name }}: {{ .Type }}{{ if not .Required }} = None{{ end }}{{ if .Description }}
+    {{ docstring "    " .Description }}{{ end }}{{ end }}
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> {{ .Name }}:
+        """from_dict builds a {{ .Name }} from its JSON representation."""
+        return cls({{ range .Fields }}
+            {{ .Name }}={{ .Decode }},{{ end }}
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        """to_dict returns the JSON representation of the {{ .Name }}."""
+        res: Dict[str, Any] = {}{{ range .Fields }}{{ if .Required }}
+        res[{{ quote .AttName }}] = {{ .Encode }}{{ else }}
+        if self.{{ .Name }} is not None:
+            res[{{ quote .AttName }}] = {{ .Encode }}{{ end }}{{ end }}
+        return res
+{{ end }}{{ end }}`
+
+const errorsT = headerT + `
+from typing import Any
+
+
+class APIError(Exception):
+    """APIError is the base class of the exceptions raised by the client.
+
+    The status attribute holds the HTTP status code of the response, body its decoded body and
+    response the response returned by the HTTP session.
+    """
+
+    def __init__(self, status: int, body: Any, response: Any) -> None:
+        super().__init__(f"{type(self).__name__}: HTTP {status}")
+        self.status = status
+        self.body = body
+        self.response = response
+
+
+class UnexpectedResponseError(APIError):
+    """UnexpectedResponseError is raised when the API returns a status that is not described in the design."""
+{{ range .Package.Errors }}
+
+class {{ .Name }}(APIError):
+    """{{ .Name }} is raised when the API returns the {{ .Response }} response ({{ .Status }})."""
+{{ end }}`
+
+const clientT = headerT + `
+from __future__ import annotations
+
+from typing import Any, Dict, List, Optional, Union
+from urllib.parse import quote
+
+from .errors import APIError, UnexpectedResponseError{{ range .Package.Errors }}, {{ .Name }}{{ end }}
+{{ if .Package.Classes }}from .models import {{ range $i, $c := .Package.Classes }}{{ if $i }}, {{ end }}{{ $c.Name }}{{ end }}
+{{ end }}
+DEFAULT_BASE_URL = {{ quote .Package.BaseURL }}
+
+
+class Client:
+    """Client gives access to the API.
+
+    base_url is prepended to the request paths. session is the HTTP session used to send the
+    requests, it defaults to a requests.Session, a httpx.Client may be used instead. headers are
+    sent with every request and timeout is the request timeout in seconds.
+    """
+
+    def __init__(
+        self,
+        base_url: str = DEFAULT_BASE_URL,
+        session: Any = None,
+        headers: Optional[Dict[str, str]] = None,
+        timeout: Optional[float] = 20,
+    ) -> None:
+        if session is None:
+            import requests
+
+            session = requests.Session()
+        self.base_url = base_url.rstrip("/")
+        self.session = session
+        self.headers = dict(headers or {})
+        self.timeout = timeout
+{{ range .Package.Methods }}
+    def {{ .Name }}({{ .Signature }}) -> {{ .Returns }}:
+        {{ .Doc }}
+        resp = self._request(
+            {{ quote .Verb }},
+            {{ .Path }},{{ if .Query }}
+            query={ {{- range $i, $p := .Query }}{{ if $i }}, {{ end }}{{ quote $p.Name }}: {{ $p.Arg }}{{ end -}} },{{ end }}{{ if .Headers }}
+            headers={ {{- range $i, $p := .Headers }}{{ if $i }}, {{ end }}{{ quote $p.Name }}: {{ $p.Arg }}{{ end -}} },{{ end }}{{ if .Payload }}
+            body={{ .Payload }},{{ end }}{{ if .Multipart }}
+            multipart=True,{{ end }}
+        ){{ range .Responses }}
+        if resp.status_code == {{ .Status }}:
+            {{ if .Error }}raise {{ .Error }}({{ .Status }}, {{ .Decode }}, resp){{ else }}return {{ .Decode }}{{ end }}{{ end }}
+        raise UnexpectedResponseError(resp.status_code, resp.text, resp)
+{{ end }}
+    def _request(
+        self,
+        method: str,
+        path: str,
+        query: Optional[Dict[str, Any]] = None,
+        headers: Optional[Dict[str, Any]] = None,
+        body: Any = None,
+        multipart: bool = False,
+    ) -> Any:
+        """_request sends a request and returns the response of the session."""
+        kwargs: Dict[str, Any] = {"timeout": self.timeout}
+        params = {k: _format(v) for k, v in (query or {}).items() if v is not None}
+        if params:
+            kwargs["params"] = params
+        hdrs = dict(self.headers)
+        hdrs.update({k: _format(v) for k, v in (headers or {}).items() if v is not None})
+        kwargs["headers"] = hdrs
+        if multipart:
+            files: Dict[str, Any] = {}
+            fields: Dict[str, Any] = {}
+            for k, v in (body or {}).items():
+                if isinstance(v, (bytes, bytearray)) or hasattr(v, "read"):
+                    files[k] = v
+                elif v is not None:
+                    fields[k] = _format(v)
+            kwargs["files"] = files
+            kwargs["data"] = fields
+        elif body is not None:
+            kwargs["json"] = body
+        return self.session.request(method, self.base_url + path, **kwargs)
+
+
+def _format(value: Any) -> Any:
+    """_format returns the string representation of a parameter value."""
+    if isinstance(value, bool):
+        return "true" if value else "false"
+    if isinstance(value, (list, tuple)):
+        return [_format(v) for v in value]
+    return str(value)
+
+
+def _path(value: Any, safe: str = "") -> str:
+    """_path returns the escaped representation of a path parameter value."""
+    return quote(_format(value), safe=safe)
+`
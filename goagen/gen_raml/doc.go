@@ -0,0 +1,11 @@
+/*
+Package genraml provides a generator for the RAML 1.0 definition of the API. The generated
+document describes the resources and methods of the API actions and file servers together with
+their parameters, headers, payloads and responses. The user types and media types are declared
+as RAML data types, media types once per rendered view, and the security schemes of the design
+are mapped to the corresponding RAML security schemes. WebSocket actions cannot be described in
+RAML and are not included.
+See https://github.com/raml-org/raml-spec/blob/master/versions/raml-10/raml-10.md for more
+information on the RAML specification.
+*/
+package genraml
@@ -0,0 +1,13 @@
+package genraml_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenRAML(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenRAML Suite")
+}
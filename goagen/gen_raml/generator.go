@@ -0,0 +1,100 @@
+package genraml
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of a RAML Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the RAML definition generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, ver string
+
+	set := flag.NewFlagSet("raml", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the RAML document.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	r, err := New(g.API)
+	if err != nil {
+		return nil, err
+	}
+
+	ramlDir := filepath.Join(g.OutDir, "raml")
+	codegen.RemoveAll(ramlDir)
+	if err = os.MkdirAll(ramlDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, ramlDir)
+
+	raw, err := yaml.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	ramlFile := filepath.Join(ramlDir, "api.raml")
+	if err := codegen.WriteFile(ramlFile, append([]byte(Header+"\n"), raw...), 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, ramlFile)
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
@@ -0,0 +1,563 @@
+package genraml
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+// Header is the first line of RAML 1.0 documents.
+const Header = "#%RAML 1.0"
+
+type (
+	// RAML represents an instance of a RAML 1.0 API definition.
+	// See https://github.com/raml-org/raml-spec/blob/master/versions/raml-10/raml-10.md
+	RAML struct {
+		Title           string                     `yaml:"title"`
+		Description     string                     `yaml:"description,omitempty"`
+		Version         string                     `yaml:"version,omitempty"`
+		BaseURI         string                     `yaml:"baseUri,omitempty"`
+		Protocols       []string                   `yaml:"protocols,omitempty"`
+		MediaType       []string                   `yaml:"mediaType,omitempty"`
+		Documentation   []*Documentation           `yaml:"documentation,omitempty"`
+		SecuritySchemes map[string]*SecurityScheme `yaml:"securitySchemes,omitempty"`
+		Types           map[string]*Type           `yaml:"types,omitempty"`
+		// Resources lists the resources indexed by path relative to the base URI.
+		Resources map[string]*Resource `yaml:",inline"`
+	}
+
+	// Documentation is a page of the user documentation.
+	Documentation struct {
+		Title   string `yaml:"title"`
+		Content string `yaml:"content"`
+	}
+
+	// SecurityScheme describes a mechanism used to secure the methods.
+	SecurityScheme struct {
+		// Type is the kind of the scheme, e.g. "Basic Authentication" or "OAuth 2.0".
+		Type string `yaml:"type"`
+		// Description of the scheme.
+		Description string `yaml:"description,omitempty"`
+		// DescribedBy lists the headers and query string parameters the scheme uses.
+		DescribedBy *DescribedBy `yaml:"describedBy,omitempty"`
+		// Settings contains the OAuth 2.0 settings of the scheme.
+		Settings *Settings `yaml:"settings,omitempty"`
+	}
+
+	// DescribedBy describes the headers and query string parameters of a security scheme.
+	DescribedBy struct {
+		Headers         map[string]*Type `yaml:"headers,omitempty"`
+		QueryParameters map[string]*Type `yaml:"queryParameters,omitempty"`
+	}
+
+	// Settings contains the OAuth 2.0 settings of a security scheme.
+	Settings struct {
+		AuthorizationURI    string   `yaml:"authorizationUri,omitempty"`
+		AccessTokenURI      string   `yaml:"accessTokenUri,omitempty"`
+		AuthorizationGrants []string `yaml:"authorizationGrants,omitempty"`
+		Scopes              []string `yaml:"scopes,omitempty"`
+	}
+
+	// Resource describes the methods available on a path.
+	Resource struct {
+		// DisplayName is the name of the resource.
+		DisplayName string `yaml:"displayName,omitempty"`
+		// Description of the resource.
+		Description string `yaml:"description,omitempty"`
+		// URIParameters defines the path parameters indexed by name.
+		URIParameters map[string]*Type `yaml:"uriParameters,omitempty"`
+		// Methods lists the methods indexed by lower case HTTP verb.
+		Methods map[string]*Method `yaml:",inline"`
+	}
+
+	// Method describes the request and responses of an action route.
+	Method struct {
+		DisplayName     string            `yaml:"displayName,omitempty"`
+		Description     string            `yaml:"description,omitempty"`
+		SecuredBy       []interface{}     `yaml:"securedBy,omitempty"`
+		QueryParameters map[string]*Type  `yaml:"queryParameters,omitempty"`
+		Headers         map[string]*Type  `yaml:"headers,omitempty"`
+		Body            map[string]*Type  `yaml:"body,omitempty"`
+		Responses       map[int]*Response `yaml:"responses,omitempty"`
+	}
+
+	// Response describes a response of a method.
+	Response struct {
+		Description string           `yaml:"description,omitempty"`
+		Headers     map[string]*Type `yaml:"headers,omitempty"`
+		Body        map[string]*Type `yaml:"body,omitempty"`
+	}
+
+	// Type is a RAML data type declaration. Type is either the name of a built-in type or
+	// the name of a type declared in the types of the document.
+	Type struct {
+		Type        string           `yaml:"type,omitempty"`
+		Description string           `yaml:"description,omitempty"`
+		Required    *bool            `yaml:"required,omitempty"`
+		Default     interface{}      `yaml:"default,omitempty"`
+		Example     interface{}      `yaml:"example,omitempty"`
+		Enum        []interface{}    `yaml:"enum,omitempty"`
+		Pattern     string           `yaml:"pattern,omitempty"`
+		MinLength   *int             `yaml:"minLength,omitempty"`
+		MaxLength   *int             `yaml:"maxLength,omitempty"`
+		MinItems    *int             `yaml:"minItems,omitempty"`
+		MaxItems    *int             `yaml:"maxItems,omitempty"`
+		Minimum     *float64         `yaml:"minimum,omitempty"`
+		Maximum     *float64         `yaml:"maximum,omitempty"`
+		Items       *Type            `yaml:"items,omitempty"`
+		Properties  map[string]*Type `yaml:"properties,omitempty"`
+	}
+
+	// builder keeps track of the types declared while building the document.
+	builder struct {
+		api   *design.APIDefinition
+		types map[string]*Type
+	}
+)
+
+// New creates a RAML 1.0 document from an API definition. The user types and media types
+// used by the actions are declared in the types section, media types are declared once per
+// rendered view. The security of each method is listed on the method since RAML applies the
+// document securedBy to all methods, including the ones goa does not secure. WebSocket actions
+// cannot be described in RAML and are skipped.
+func New(api *design.APIDefinition) (*RAML, error) {
+	if api == nil {
+		return nil, nil
+	}
+	basePath := api.BasePath
+	if hasAbsoluteRoutes(api) || len(design.ExtractWildcards(basePath)) > 0 {
+		basePath = ""
+	}
+	title := api.Title
+	if title == "" {
+		title = api.Name
+	}
+	b := &builder{api: api, types: make(map[string]*Type)}
+	r := &RAML{
+		Title:           title,
+		Description:     api.Description,
+		Version:         api.Version,
+		BaseURI:         baseURI(api, basePath),
+		MediaType:       consumes(api),
+		SecuritySchemes: securitySchemes(api.SecuritySchemes),
+		Resources:       make(map[string]*Resource),
+	}
+	for _, s := range api.Schemes {
+		if s == "http" || s == "https" {
+			r.Protocols = append(r.Protocols, strings.ToUpper(s))
+		}
+	}
+	if api.Docs != nil {
+		content := api.Docs.Description
+		if api.Docs.URL != "" {
+			if content != "" {
+				content += "\n\n"
+			}
+			content += api.Docs.URL
+		}
+		r.Documentation = []*Documentation{{Title: "Documentation", Content: content}}
+	}
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		err := res.IterateFileServers(func(fs *design.FileServerDefinition) error {
+			m := &Method{
+				DisplayName: fmt.Sprintf("Download %s", fs.FilePath),
+				Description: fs.Description,
+				SecuredBy:   securedBy(fs.Security),
+				Responses: map[int]*Response{
+					200: {Description: "File downloaded", Body: map[string]*Type{"*/*": {Type: "file"}}},
+				},
+			}
+			resource := b.resource(r, res, resourcePath(fs.RequestPath, basePath))
+			for _, w := range design.ExtractWildcards(fs.RequestPath) {
+				if resource.URIParameters == nil {
+					resource.URIParameters = make(map[string]*Type)
+				}
+				resource.URIParameters[w] = &Type{Type: "string"}
+			}
+			if _, ok := resource.Methods["get"]; !ok {
+				resource.Methods["get"] = m
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() {
+				return nil
+			}
+			for _, route := range a.Routes {
+				if err := b.method(r, res, a, route, basePath); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(b.types) > 0 {
+		r.Types = b.types
+	}
+	return r, nil
+}
+
+// method adds the method that describes the given action route to the document.
+func (b *builder) method(r *RAML, res *design.ResourceDefinition, a *design.ActionDefinition, route *design.RouteDefinition, basePath string) error {
+	params := a.AllParams()
+	var obj design.Object
+	if params != nil {
+		obj = params.Type.ToObject()
+	}
+	resource := b.resource(r, res, resourcePath(route.FullPath(), basePath))
+	pathParams := make(map[string]bool)
+	for _, n := range route.Params() {
+		pathParams[n] = true
+		if resource.URIParameters == nil {
+			resource.URIParameters = make(map[string]*Type)
+		}
+		if _, ok := resource.URIParameters[n]; ok {
+			continue
+		}
+		if at, ok := obj[n]; ok {
+			resource.URIParameters[n] = b.typeOf(at)
+		} else {
+			resource.URIParameters[n] = &Type{Type: "string"}
+		}
+	}
+	verb := strings.ToLower(route.Verb)
+	if _, ok := resource.Methods[verb]; ok {
+		return nil
+	}
+	m := &Method{
+		DisplayName: a.Name + " " + res.Name,
+		Description: a.Description,
+		SecuredBy:   securedBy(a.Security),
+	}
+	if a.QueryParams != nil {
+		query := a.QueryParams.Type.ToObject()
+		for _, n := range codegen.SortedNames(query) {
+			if pathParams[n] {
+				continue
+			}
+			if m.QueryParameters == nil {
+				m.QueryParameters = make(map[string]*Type)
+			}
+			m.QueryParameters[n] = b.member(a.QueryParams, n, query[n])
+		}
+	}
+	if a.Headers != nil {
+		headers := a.Headers.Type.ToObject()
+		m.Headers = make(map[string]*Type, len(headers))
+		for n, at := range headers {
+			m.Headers[n] = b.member(a.Headers, n, at)
+		}
+	}
+	if a.Payload != nil {
+		t := &Type{Type: b.typeRef(a.Payload), Description: a.Payload.Description}
+		if a.PayloadOptional {
+			t.Required = boolPtr(false)
+		}
+		mimes := consumes(b.api)
+		if a.PayloadMultipart {
+			mimes = []string{"multipart/form-data"}
+		}
+		m.Body = make(map[string]*Type, len(mimes))
+		for _, mime := range mimes {
+			m.Body[mime] = t
+		}
+	}
+	err := a.IterateResponses(func(resp *design.ResponseDefinition) error {
+		rr, err := b.response(resp)
+		if err != nil {
+			return fmt.Errorf("%s: response %#v: %s", a.Context(), resp.Name, err)
+		}
+		if m.Responses == nil {
+			m.Responses = make(map[int]*Response)
+		}
+		m.Responses[resp.Status] = rr
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	resource.Methods[verb] = m
+	return nil
+}
+
+// resource returns the resource with the given path, creating it if needed.
+func (b *builder) resource(r *RAML, res *design.ResourceDefinition, path string) *Resource {
+	resource, ok := r.Resources[path]
+	if !ok {
+		resource = &Resource{
+			DisplayName: res.Name,
+			Description: res.Description,
+			Methods:     make(map[string]*Method),
+		}
+		r.Resources[path] = resource
+	}
+	return resource
+}
+
+// response builds the description of the given response, media types are rendered with the
+// response view.
+func (b *builder) response(r *design.ResponseDefinition) (*Response, error) {
+	resp := &Response{Description: r.Description}
+	if r.Headers != nil {
+		headers := r.Headers.Type.ToObject()
+		resp.Headers = make(map[string]*Type, len(headers))
+		for n, at := range headers {
+			resp.Headers[n] = b.member(r.Headers, n, at)
+		}
+	}
+	if r.MediaType != "" {
+		mt := b.api.MediaTypeWithIdentifier(r.MediaType)
+		if mt == nil && design.CanonicalIdentifier(r.MediaType) == design.CanonicalIdentifier(design.ErrorMedia.Identifier) {
+			mt = design.ErrorMedia
+		}
+		if mt == nil {
+			resp.Body = map[string]*Type{r.MediaType: {Type: "any"}}
+			return resp, nil
+		}
+		view := r.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = map[string]*Type{mt.Identifier: {Type: b.typeRef(p)}}
+	} else if r.Type != nil {
+		resp.Body = map[string]*Type{"application/json": b.typeOf(&design.AttributeDefinition{Type: r.Type})}
+	}
+	return resp, nil
+}
+
+// member returns the declaration of the member n of the given object, RAML members are
+// required unless stated otherwise.
+func (b *builder) member(parent *design.AttributeDefinition, n string, at *design.AttributeDefinition) *Type {
+	t := b.typeOf(at)
+	if !parent.IsRequired(n) {
+		t.Required = boolPtr(false)
+	}
+	return t
+}
+
+// typeOf returns the declaration of the given attribute. User types and media types are
+// declared in the document types and referenced by name.
+func (b *builder) typeOf(at *design.AttributeDefinition) *Type {
+	t := &Type{
+		Description: at.Description,
+		Default:     at.DefaultValue,
+		Example:     at.Example,
+	}
+	switch actual := at.Type.(type) {
+	case *design.UserTypeDefinition, *design.MediaTypeDefinition:
+		t.Type = b.typeRef(actual)
+		return t
+	case design.Primitive:
+		switch actual.Kind() {
+		case design.BooleanKind:
+			t.Type = "boolean"
+		case design.IntegerKind:
+			t.Type = "integer"
+		case design.NumberKind:
+			t.Type = "number"
+		case design.DateTimeKind:
+			t.Type = "datetime"
+		case design.FileKind:
+			t.Type = "file"
+		case design.AnyKind:
+			t.Type = "any"
+		default:
+			t.Type = "string"
+		}
+	case *design.Array:
+		t.Type = "array"
+		t.Items = b.typeOf(actual.ElemType)
+	case *design.Hash:
+		t.Type = "object"
+		t.Properties = map[string]*Type{"//": b.typeOf(actual.ElemType)}
+	case design.Object:
+		t.Type = "object"
+		t.Properties = make(map[string]*Type, len(actual))
+		for n, child := range actual {
+			t.Properties[n] = b.member(at, n, child)
+		}
+	}
+	if v := at.Validation; v != nil {
+		t.Enum = v.Values
+		switch t.Type {
+		case "string":
+			t.Pattern = v.Pattern
+			t.MinLength, t.MaxLength = v.MinLength, v.MaxLength
+		case "array":
+			t.MinItems, t.MaxItems = v.MinLength, v.MaxLength
+		case "integer", "number":
+			t.Minimum, t.Maximum = v.Minimum, v.Maximum
+		}
+	}
+	return t
+}
+
+// typeRef declares the given user type or media type in the document types if needed and
+// returns its name.
+func (b *builder) typeRef(dt design.DataType) string {
+	var (
+		name string
+		att  *design.AttributeDefinition
+	)
+	switch actual := dt.(type) {
+	case *design.MediaTypeDefinition:
+		name, att = actual.TypeName, actual.AttributeDefinition
+	case *design.UserTypeDefinition:
+		name, att = actual.TypeName, actual.AttributeDefinition
+	default:
+		return b.typeOf(&design.AttributeDefinition{Type: dt}).Type
+	}
+	if _, ok := b.types[name]; ok {
+		return name
+	}
+	// Declare the type before building it to stop the recursion of recursive types.
+	b.types[name] = &Type{}
+	*b.types[name] = *b.typeOf(att)
+	return name
+}
+
+// securitySchemes builds the security schemes of the document.
+func securitySchemes(schemes []*design.SecuritySchemeDefinition) map[string]*SecurityScheme {
+	if len(schemes) == 0 {
+		return nil
+	}
+	res := make(map[string]*SecurityScheme)
+	for _, scheme := range schemes {
+		s := &SecurityScheme{Description: scheme.Description}
+		switch scheme.Kind {
+		case design.BasicAuthSecurityKind:
+			s.Type = "Basic Authentication"
+		case design.APIKeySecurityKind:
+			s.Type = "Pass Through"
+			param := map[string]*Type{scheme.Name: {Type: "string"}}
+			if scheme.In == "query" {
+				s.DescribedBy = &DescribedBy{QueryParameters: param}
+			} else {
+				s.DescribedBy = &DescribedBy{Headers: param}
+			}
+		case design.JWTSecurityKind:
+			s.Type = "x-jwt"
+			header := scheme.Name
+			if header == "" {
+				header = "Authorization"
+			}
+			s.DescribedBy = &DescribedBy{Headers: map[string]*Type{header: {Type: "string"}}}
+			if scheme.TokenURL != "" {
+				s.Settings = &Settings{AccessTokenURI: scheme.TokenURL}
+			}
+		case design.OAuth2SecurityKind:
+			s.Type = "OAuth 2.0"
+			s.DescribedBy = &DescribedBy{Headers: map[string]*Type{"Authorization": {Type: "string"}}}
+			s.Settings = &Settings{
+				AuthorizationURI: scheme.AuthorizationURL,
+				AccessTokenURI:   scheme.TokenURL,
+			}
+			if grant, ok := grants[scheme.Flow]; ok {
+				s.Settings.AuthorizationGrants = []string{grant}
+			}
+			for n := range scheme.Scopes {
+				s.Settings.Scopes = append(s.Settings.Scopes, n)
+			}
+			sort.Strings(s.Settings.Scopes)
+		default:
+			continue
+		}
+		res[scheme.SchemeName] = s
+	}
+	return res
+}
+
+// grants maps the goa OAuth2 flows to the RAML authorization grants.
+var grants = map[string]string{
+	"accessCode":  "authorization_code",
+	"implicit":    "implicit",
+	"password":    "password",
+	"application": "client_credentials",
+}
+
+// securedBy returns the securedBy declaration of the given security, OAuth2 schemes list the
+// required scopes.
+func securedBy(security *design.SecurityDefinition) []interface{} {
+	if security == nil || security.Scheme == nil || security.Scheme.Kind == design.NoSecurityKind {
+		return nil
+	}
+	name := security.Scheme.SchemeName
+	if security.Scheme.Kind == design.OAuth2SecurityKind && len(security.Scopes) > 0 {
+		return []interface{}{map[string]interface{}{name: map[string]interface{}{"scopes": security.Scopes}}}
+	}
+	return []interface{}{name}
+}
+
+// resourcePath returns the RAML path of the given route path relative to the base path.
+func resourcePath(path, basePath string) string {
+	key := design.WildcardRegex.ReplaceAllStringFunc(path, func(w string) string {
+		return fmt.Sprintf("/{%s}", w[2:])
+	})
+	if basePath != "" && basePath != "/" {
+		key = strings.TrimPrefix(key, basePath)
+	}
+	if !strings.HasPrefix(key, "/") {
+		key = "/" + key
+	}
+	return key
+}
+
+// baseURI computes the base URI of the API from its host, first scheme and base path.
+func baseURI(api *design.APIDefinition, basePath string) string {
+	if api.Host == "" {
+		return ""
+	}
+	scheme := "http"
+	if len(api.Schemes) > 0 {
+		scheme = api.Schemes[0]
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, api.Host, basePath)
+}
+
+// consumes returns the MIME types supported by the API decoders.
+func consumes(api *design.APIDefinition) []string {
+	var mimes []string
+	for _, c := range api.Consumes {
+		mimes = append(mimes, c.MIMETypes...)
+	}
+	if len(mimes) == 0 {
+		mimes = []string{"application/json"}
+	}
+	return mimes
+}
+
+// hasAbsoluteRoutes returns true if any action exposed by the API uses an absolute route or if
+// the API has file servers. The base path cannot be used as base URI in this case.
+func hasAbsoluteRoutes(api *design.APIDefinition) bool {
+	for _, res := range api.Resources {
+		if len(res.FileServers) > 0 {
+			return true
+		}
+		for _, a := range res.Actions {
+			for _, ro := range a.Routes {
+				if ro.IsAbsolute() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// boolPtr returns a pointer to the given bool value.
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,203 @@
+package genraml_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genraml "github.com/kyokomi/goa-v1/goagen/gen_raml"
+)
+
+var _ = Describe("New", func() {
+	var raml *genraml.RAML
+	var newErr error
+
+	BeforeEach(func() {
+		raml = nil
+		newErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		raml, newErr = genraml.New(Design)
+	})
+
+	Context("with a basic API", func() {
+		BeforeEach(func() {
+			apidsl.API("cellar", func() {
+				apidsl.Title("Cellar API")
+				apidsl.Version("1.0")
+				apidsl.Host("cellar.example.com")
+				apidsl.Scheme("https")
+				apidsl.BasePath("/api")
+			})
+		})
+
+		It("sets the document information", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(raml.Title).Should(Equal("Cellar API"))
+			Ω(raml.Version).Should(Equal("1.0"))
+			Ω(raml.BaseURI).Should(Equal("https://cellar.example.com/api"))
+			Ω(raml.Protocols).Should(Equal([]string{"HTTPS"}))
+		})
+	})
+
+	Context("with resources", func() {
+		BeforeEach(func() {
+			bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer)
+					apidsl.Attribute("name", String, func() {
+						apidsl.MinLength(2)
+					})
+					apidsl.Attribute("tags", apidsl.ArrayOf(String))
+					apidsl.Required("id")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+					apidsl.Attribute("tags")
+				})
+				apidsl.View("tiny", func() {
+					apidsl.Attribute("id")
+				})
+			})
+			payload := apidsl.Type("BottlePayload", func() {
+				apidsl.Attribute("name", String)
+				apidsl.Required("name")
+			})
+			apidsl.API("cellar", func() {
+				apidsl.BasePath("/api")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", Integer, func() {
+							apidsl.Minimum(1)
+						})
+						apidsl.Param("verbose", Boolean)
+					})
+					apidsl.Response(OK, func() {
+						apidsl.Media(bottle, "tiny")
+					})
+					apidsl.Response(NotFound)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(payload)
+					apidsl.Response(Created, bottle)
+				})
+			})
+		})
+
+		It("describes the resources and methods", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(raml.Resources).Should(HaveLen(2))
+			Ω(raml.Resources).Should(HaveKey("/bottles/{id}"))
+			Ω(raml.Resources).Should(HaveKey("/bottles"))
+
+			show := raml.Resources["/bottles/{id}"]
+			Ω(show.URIParameters).Should(HaveKey("id"))
+			Ω(show.URIParameters["id"].Type).Should(Equal("integer"))
+			Ω(*show.URIParameters["id"].Minimum).Should(Equal(1.0))
+			Ω(show.Methods).Should(HaveKey("get"))
+			get := show.Methods["get"]
+			Ω(get.DisplayName).Should(Equal("show bottle"))
+			Ω(get.QueryParameters).Should(HaveKey("verbose"))
+			Ω(get.QueryParameters).ShouldNot(HaveKey("id"))
+			Ω(*get.QueryParameters["verbose"].Required).Should(BeFalse())
+			Ω(get.Responses).Should(HaveKey(200))
+			Ω(get.Responses[200].Body["application/vnd.bottle+json"].Type).Should(Equal("BottleTiny"))
+			Ω(get.Responses).Should(HaveKey(404))
+			Ω(get.Responses[404].Body).Should(BeEmpty())
+		})
+
+		It("describes the payloads", func() {
+			post := raml.Resources["/bottles"].Methods["post"]
+			Ω(post).ShouldNot(BeNil())
+			Ω(post.Body).Should(HaveKey("application/json"))
+			Ω(post.Body["application/json"].Type).Should(Equal("BottlePayload"))
+		})
+
+		It("declares the types", func() {
+			Ω(raml.Types).Should(HaveKey("BottlePayload"))
+			Ω(raml.Types).Should(HaveKey("BottleTiny"))
+			Ω(raml.Types).Should(HaveKey("Bottle"))
+			b := raml.Types["Bottle"]
+			Ω(b.Type).Should(Equal("object"))
+			Ω(b.Properties["id"].Required).Should(BeNil())
+			Ω(*b.Properties["name"].Required).Should(BeFalse())
+			Ω(*b.Properties["name"].MinLength).Should(Equal(2))
+			Ω(b.Properties["tags"].Type).Should(Equal("array"))
+			Ω(b.Properties["tags"].Items.Type).Should(Equal("string"))
+		})
+	})
+
+	Context("with security schemes", func() {
+		BeforeEach(func() {
+			basic := apidsl.BasicAuthSecurity("basic")
+			key := apidsl.APIKeySecurity("key", func() {
+				apidsl.Query("token")
+			})
+			oauth := apidsl.OAuth2Security("oauth", func() {
+				apidsl.AccessCodeFlow("http://auth.example.com/authorize", "http://auth.example.com/token")
+				apidsl.Scope("api:read")
+			})
+			apidsl.API("cellar", func() {
+				apidsl.Security(basic)
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET("/bottles"))
+					apidsl.Response(OK)
+				})
+				apidsl.Action("show", func() {
+					apidsl.Security(oauth, func() {
+						apidsl.Scope("api:read")
+					})
+					apidsl.Routing(apidsl.GET("/bottles/:id"))
+					apidsl.Response(OK)
+				})
+				apidsl.Action("delete", func() {
+					apidsl.Security(key)
+					apidsl.Routing(apidsl.DELETE("/bottles/:id"))
+					apidsl.Response(NoContent)
+				})
+				apidsl.Action("health", func() {
+					apidsl.NoSecurity()
+					apidsl.Routing(apidsl.GET("/health"))
+					apidsl.Response(OK)
+				})
+			})
+		})
+
+		It("maps the security schemes", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(raml.SecuritySchemes).Should(HaveLen(3))
+			Ω(raml.SecuritySchemes["basic"].Type).Should(Equal("Basic Authentication"))
+			Ω(raml.SecuritySchemes["key"].Type).Should(Equal("Pass Through"))
+			Ω(raml.SecuritySchemes["key"].DescribedBy.QueryParameters).Should(HaveKey("token"))
+			oauth := raml.SecuritySchemes["oauth"]
+			Ω(oauth.Type).Should(Equal("OAuth 2.0"))
+			Ω(oauth.Settings.AuthorizationGrants).Should(Equal([]string{"authorization_code"}))
+			Ω(oauth.Settings.Scopes).Should(Equal([]string{"api:read"}))
+		})
+
+		It("secures the methods", func() {
+			Ω(raml.Resources["/bottles"].Methods["get"].SecuredBy).Should(Equal([]interface{}{"basic"}))
+			show := raml.Resources["/bottles/{id}"].Methods["get"]
+			Ω(show.SecuredBy).Should(Equal([]interface{}{
+				map[string]interface{}{"oauth": map[string]interface{}{"scopes": []string{"api:read"}}},
+			}))
+			Ω(raml.Resources["/bottles/{id}"].Methods["delete"].SecuredBy).Should(Equal([]interface{}{"key"}))
+			Ω(raml.Resources["/health"].Methods["get"].SecuredBy).Should(BeEmpty())
+		})
+	})
+})
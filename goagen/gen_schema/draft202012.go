@@ -0,0 +1,146 @@
+package genschema
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+)
+
+// Draft202012Ref is the JSON Schema 2020-12 meta-schema URI.
+const Draft202012Ref = "https://json-schema.org/draft/2020-12/schema"
+
+// NullableMetadata is the name of the attribute metadata that marks the attribute value as
+// nullable in the JSON Schema 2020-12 and OpenAPI 3.1 documents.
+const NullableMetadata = "schema:nullable"
+
+// ToDraft202012 returns a copy of the given schema that is a valid JSON Schema 2020-12 document:
+//
+//   - the hyper-schema fields (links, media and pathStart) are removed, the media binary
+//     encoding and type are set as contentEncoding and contentMediaType instead,
+//   - the definitions are moved to $defs and references to them are prefixed with defsRef
+//     (e.g. "#/$defs/" or "#/components/schemas/"),
+//   - the example is moved to the examples array,
+//   - enums containing a single value are rendered using const,
+//   - nullable values are described with a type array that includes "null",
+//   - files are described as strings with a binary content.
+//
+// Calling JSON on the result sets the $schema field to Draft202012Ref.
+func ToDraft202012(s *JSONSchema, defsRef string) *JSONSchema {
+	if s == nil {
+		return nil
+	}
+	res := *s
+	res.draft202012 = true
+	res.Schema = ""
+	res.Links = nil
+	res.PathStart = ""
+	res.Media = nil
+	if s.Media != nil {
+		res.ContentEncoding = s.Media.BinaryEncoding
+		res.ContentMediaType = s.Media.Type
+	}
+	if strings.HasPrefix(s.Ref, "#/definitions/") {
+		res.Ref = defsRef + strings.TrimPrefix(s.Ref, "#/definitions/")
+	}
+	if res.Type == JSONFile {
+		res.Type = JSONString
+		if res.ContentMediaType == "" {
+			res.ContentMediaType = "application/octet-stream"
+		}
+	}
+	if s.Example != nil {
+		res.Examples = append([]interface{}{s.Example}, s.Examples...)
+		res.Example = nil
+	}
+	if len(s.Enum) == 1 && !s.Nullable {
+		res.Const = s.Enum[0]
+		res.Enum = nil
+	} else if len(s.Enum) > 0 && s.Nullable {
+		res.Enum = append(append([]interface{}{}, s.Enum...), nil)
+	}
+	res.Items = ToDraft202012(s.Items, defsRef)
+	res.Properties = toDraft202012Map(s.Properties, defsRef)
+	res.Definitions = nil
+	res.Defs = toDraft202012Map(s.Definitions, defsRef)
+	if s.Defs != nil {
+		if res.Defs == nil {
+			res.Defs = make(map[string]*JSONSchema, len(s.Defs))
+		}
+		for n, d := range toDraft202012Map(s.Defs, defsRef) {
+			res.Defs[n] = d
+		}
+	}
+	res.AnyOf = toDraft202012Slice(s.AnyOf, defsRef)
+	res.OneOf = toDraft202012Slice(s.OneOf, defsRef)
+	if res.Nullable && res.Ref != "" {
+		// Type arrays cannot be combined with references, use a union instead.
+		ref := JSONSchema{Ref: res.Ref, draft202012: true}
+		res.Ref = ""
+		res.Nullable = false
+		res.AnyOf = []*JSONSchema{&ref, {Type: JSONNull, draft202012: true}}
+	}
+	return &res
+}
+
+// MarshalJSON renders the type of nullable JSON Schema 2020-12 schemas as a type array and
+// uses $id instead of id. It also renders the specification extensions of the schema.
+func (s JSONSchema) MarshalJSON() ([]byte, error) {
+	type schema JSONSchema
+	doc := struct {
+		*schema
+		ID       string      `json:"id,omitempty"`
+		DollarID string      `json:"$id,omitempty"`
+		Type     interface{} `json:"type,omitempty"`
+	}{schema: (*schema)(&s)}
+	if s.draft202012 {
+		doc.DollarID = s.ID
+	} else {
+		doc.ID = s.ID
+	}
+	if s.Type != "" {
+		doc.Type = s.Type
+		if s.draft202012 && s.Nullable && s.Type != JSONNull {
+			doc.Type = []JSONType{s.Type, JSONNull}
+		}
+	}
+	b, err := json.Marshal(doc)
+	if err != nil || len(s.Extensions) == 0 {
+		return b, err
+	}
+	var merged map[string]interface{}
+	if err := json.Unmarshal(b, &merged); err != nil {
+		return nil, err
+	}
+	for k, v := range s.Extensions {
+		merged[k] = v
+	}
+	return json.Marshal(merged)
+}
+
+// isNullable returns true if the attribute metadata marks its value as nullable.
+func isNullable(at *design.AttributeDefinition) bool {
+	return at.IsNullable()
+}
+
+func toDraft202012Map(schemas map[string]*JSONSchema, defsRef string) map[string]*JSONSchema {
+	if len(schemas) == 0 {
+		return nil
+	}
+	res := make(map[string]*JSONSchema, len(schemas))
+	for n, s := range schemas {
+		res[n] = ToDraft202012(s, defsRef)
+	}
+	return res
+}
+
+func toDraft202012Slice(schemas []*JSONSchema, defsRef string) []*JSONSchema {
+	if schemas == nil {
+		return nil
+	}
+	res := make([]*JSONSchema, len(schemas))
+	for i, s := range schemas {
+		res[i] = ToDraft202012(s, defsRef)
+	}
+	return res
+}
@@ -0,0 +1,86 @@
+package genschema_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genschema "github.com/kyokomi/goa-v1/goagen/gen_schema"
+)
+
+var _ = Describe("ToDraft202012", func() {
+	var s *genschema.JSONSchema
+	var doc map[string]interface{}
+
+	JustBeforeEach(func() {
+		js, err := genschema.ToDraft202012(s, "#/$defs/").JSON()
+		Ω(err).ShouldNot(HaveOccurred())
+		doc = nil
+		Ω(json.Unmarshal(js, &doc)).ShouldNot(HaveOccurred())
+	})
+
+	Context("with a user type", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+			ut := apidsl.Type("Bottle", func() {
+				apidsl.Attribute("kind", design.String, func() {
+					apidsl.Enum("wine")
+				})
+				apidsl.Attribute("name", design.String, func() {
+					apidsl.Example("Chateau")
+					apidsl.Metadata(genschema.NullableMetadata)
+					apidsl.Metadata("swagger:extension:x-order", "1")
+				})
+				apidsl.Attribute("label", design.File)
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			s = genschema.NewJSONSchema()
+			s.ID = "http://example.com/schema"
+			s.Properties["bottle"] = genschema.AttributeSchema(design.Design, ut.AttributeDefinition)
+		})
+
+		It("produces a JSON Schema 2020-12 document", func() {
+			Ω(doc["$schema"]).Should(Equal(genschema.Draft202012Ref))
+			Ω(doc["$id"]).Should(Equal("http://example.com/schema"))
+			Ω(doc).ShouldNot(HaveKey("id"))
+			props := doc["properties"].(map[string]interface{})["bottle"].(map[string]interface{})["properties"].(map[string]interface{})
+			kind := props["kind"].(map[string]interface{})
+			Ω(kind["const"]).Should(Equal("wine"))
+			Ω(kind).ShouldNot(HaveKey("enum"))
+			name := props["name"].(map[string]interface{})
+			Ω(name["type"]).Should(Equal([]interface{}{"string", "null"}))
+			Ω(name["examples"]).Should(Equal([]interface{}{"Chateau"}))
+			Ω(name).ShouldNot(HaveKey("example"))
+			Ω(name["x-order"]).Should(Equal(1.0))
+			label := props["label"].(map[string]interface{})
+			Ω(label["type"]).Should(Equal("string"))
+			Ω(label["contentMediaType"]).Should(Equal("application/octet-stream"))
+		})
+	})
+
+	Context("with hyper-schema fields and definitions", func() {
+		BeforeEach(func() {
+			s = genschema.NewJSONSchema()
+			s.Type = genschema.JSONObject
+			s.Links = []*genschema.JSONLink{{Href: "/", Rel: "self"}}
+			s.Definitions["Bottle"] = &genschema.JSONSchema{Type: genschema.JSONObject}
+			s.Properties["bottle"] = &genschema.JSONSchema{Ref: "#/definitions/Bottle", Nullable: true}
+		})
+
+		It("moves the definitions to $defs and removes the links", func() {
+			Ω(doc).ShouldNot(HaveKey("links"))
+			Ω(doc).ShouldNot(HaveKey("definitions"))
+			Ω(doc["$defs"]).Should(HaveKey("Bottle"))
+			bottle := doc["properties"].(map[string]interface{})["bottle"].(map[string]interface{})
+			Ω(bottle["anyOf"]).Should(Equal([]interface{}{
+				map[string]interface{}{"$ref": "#/$defs/Bottle"},
+				map[string]interface{}{"type": "null"},
+			}))
+		})
+	})
+})
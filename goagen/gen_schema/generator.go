@@ -3,7 +3,6 @@ package genschema
 import (
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 
@@ -27,15 +26,17 @@ func NewGenerator(options ...Option) *Generator {
 type Generator struct {
 	API      *design.APIDefinition // The API definition
 	OutDir   string                // Path to output directory
+	Draft    string                // JSON Schema draft, "2020-12" (default) or "04" for hyper-schema
 	genfiles []string              // Generated files
 }
 
 // Generate is the generator entry point called by the meta generator.
 func Generate() (files []string, err error) {
-	var outDir, ver string
+	var outDir, ver, draft string
 	set := flag.NewFlagSet("app", flag.PanicOnError)
 	set.StringVar(&outDir, "out", "", "")
 	set.StringVar(&ver, "version", "", "")
+	set.StringVar(&draft, "draft", "", "")
 	set.String("design", "", "")
 	set.Parse(os.Args[1:])
 
@@ -43,7 +44,7 @@ func Generate() (files []string, err error) {
 		return nil, err
 	}
 
-	g := &Generator{OutDir: outDir, API: design.Design}
+	g := &Generator{OutDir: outDir, Draft: draft, API: design.Design}
 
 	return g.Generate()
 }
@@ -63,17 +64,24 @@ func (g *Generator) Generate() (_ []string, err error) {
 	}()
 
 	s := APISchema(g.API)
+	switch g.Draft {
+	case "", "2020-12":
+		s = ToDraft202012(s, "#/$defs/")
+	case "04":
+	default:
+		return nil, fmt.Errorf("unsupported JSON schema draft %#v, must be one of 2020-12 or 04", g.Draft)
+	}
 	js, err := s.JSON()
 	if err != nil {
 		return
 	}
 
 	g.OutDir = filepath.Join(g.OutDir, "schema")
-	os.RemoveAll(g.OutDir)
+	codegen.RemoveAll(g.OutDir)
 	os.MkdirAll(g.OutDir, 0755)
 	g.genfiles = append(g.genfiles, g.OutDir)
 	schemaFile := filepath.Join(g.OutDir, "schema.json")
-	if err = ioutil.WriteFile(schemaFile, js, 0644); err != nil {
+	if err = codegen.WriteFile(schemaFile, js, 0644); err != nil {
 		return
 	}
 	g.genfiles = append(g.genfiles, schemaFile)
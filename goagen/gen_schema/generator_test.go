@@ -60,6 +60,24 @@ var _ = Describe("Generate", func() {
 			var s genschema.JSONSchema
 			err = json.Unmarshal(content, &s)
 			Ω(err).ShouldNot(HaveOccurred())
+			Ω(s.Schema).Should(Equal(genschema.Draft202012Ref))
+		})
+
+		Context("with the 04 draft", func() {
+			BeforeEach(func() {
+				os.Args = append(os.Args, "--draft=04")
+			})
+
+			It("generates a hyper-schema", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "schema", "schema.json"))
+				Ω(err).ShouldNot(HaveOccurred())
+				var s genschema.JSONSchema
+				err = json.Unmarshal(content, &s)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(s.Schema).Should(Equal(genschema.SchemaRef))
+				Ω(s.Links).ShouldNot(BeEmpty())
+			})
 		})
 	})
 })
@@ -70,11 +88,13 @@ var _ = Describe("NewGenerator", func() {
 	var args = struct {
 		api    *design.APIDefinition
 		outDir string
+		draft  string
 	}{
 		api: &design.APIDefinition{
 			Name: "test api",
 		},
 		outDir: "out_dir",
+		draft:  "04",
 	}
 
 	Context("with options all options set", func() {
@@ -83,6 +103,7 @@ var _ = Describe("NewGenerator", func() {
 			generator = genschema.NewGenerator(
 				genschema.API(args.api),
 				genschema.OutDir(args.outDir),
+				genschema.Draft(args.draft),
 			)
 		})
 
@@ -90,6 +111,7 @@ var _ = Describe("NewGenerator", func() {
 			Ω(generator).ShouldNot(BeNil())
 			Ω(generator.API.Name).Should(Equal(args.api.Name))
 			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Draft).Should(Equal(args.draft))
 		})
 	})
 })
@@ -26,6 +26,23 @@ type (
 		Description  string                 `json:"description,omitempty"`
 		DefaultValue interface{}            `json:"default,omitempty"`
 		Example      interface{}            `json:"example,omitempty"`
+		// Examples, Const, Defs and the content fields are only set on JSON Schema
+		// 2020-12 documents, see ToDraft202012.
+		Examples         []interface{}          `json:"examples,omitempty"`
+		Const            interface{}            `json:"const,omitempty"`
+		Defs             map[string]*JSONSchema `json:"$defs,omitempty"`
+		ContentMediaType string                 `json:"contentMediaType,omitempty"`
+		ContentEncoding  string                 `json:"contentEncoding,omitempty"`
+		// Nullable is true if the value may be null. It is rendered as a type array in
+		// JSON Schema 2020-12 documents and ignored otherwise.
+		Nullable bool `json:"-"`
+		// Deprecated is true if the attribute should not be used anymore.
+		Deprecated bool `json:"deprecated,omitempty"`
+		// ExternalDocs references additional documentation of the attribute.
+		ExternalDocs *JSONExternalDocs `json:"externalDocs,omitempty"`
+		// Extensions lists the specification extensions set with the swagger:extension
+		// metadata, e.g. "x-foo".
+		Extensions map[string]interface{} `json:"-"`
 
 		// Hyper schema
 		Media     *JSONMedia  `json:"media,omitempty"`
@@ -49,6 +66,10 @@ type (
 
 		// Union
 		AnyOf []*JSONSchema `json:"anyOf,omitempty"`
+		OneOf []*JSONSchema `json:"oneOf,omitempty"`
+
+		// draft202012 is true if the schema was produced by ToDraft202012.
+		draft202012 bool
 	}
 
 	// JSONType is the JSON type enum.
@@ -60,6 +81,12 @@ type (
 		Type           string `json:"type,omitempty"`
 	}
 
+	// JSONExternalDocs represents an "externalDocs" field as defined by OpenAPI.
+	JSONExternalDocs struct {
+		Description string `json:"description,omitempty"`
+		URL         string `json:"url"`
+	}
+
 	// JSONLink represents a "link" field in a JSON hyper schema.
 	JSONLink struct {
 		Title        string      `json:"title,omitempty"`
@@ -121,6 +148,9 @@ func NewJSONSchema() *JSONSchema {
 func (s *JSONSchema) JSON() ([]byte, error) {
 	if s.Ref == "" {
 		s.Schema = SchemaRef
+		if s.draft202012 {
+			s.Schema = Draft202012Ref
+		}
 	}
 	return json.Marshal(s)
 }
@@ -173,9 +203,10 @@ func GenerateResourceDefinition(api *design.APIDefinition, r *design.ResourceDef
 	s.Title = r.Name
 	Definitions[r.Name] = s
 	if mt, ok := api.MediaTypes[r.MediaType]; ok {
-		for _, v := range mt.Views {
+		mt.IterateViews(func(v *design.ViewDefinition) error {
 			buildMediaTypeSchema(api, mt, v.Name, s)
-		}
+			return nil
+		})
 	}
 	r.IterateActions(func(a *design.ActionDefinition) error {
 		var requestSchema *JSONSchema
@@ -194,7 +225,7 @@ func GenerateResourceDefinition(api *design.APIDefinition, r *design.ResourceDef
 		}
 		var targetSchema *JSONSchema
 		var identifier string
-		for _, resp := range a.Responses {
+		a.IterateResponses(func(resp *design.ResponseDefinition) error {
 			if mt, ok := api.MediaTypes[resp.MediaType]; ok {
 				if identifier == "" {
 					identifier = mt.Identifier
@@ -211,7 +242,8 @@ func GenerateResourceDefinition(api *design.APIDefinition, r *design.ResourceDef
 					targetSchema.AnyOf = append(targetSchema.AnyOf, TypeSchema(api, mt))
 				}
 			}
-		}
+			return nil
+		})
 		for i, r := range a.Routes {
 			link := JSONLink{
 				Title:        a.Name,
@@ -292,10 +324,16 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 			s.Format = "uuid"
 		case design.DateTimeKind:
 			s.Format = "date-time"
+		case design.DecimalKind:
+			s.Format = "decimal"
 		case design.NumberKind:
 			s.Format = "double"
 		case design.IntegerKind:
 			s.Format = "int64"
+		default:
+			if c := design.CustomPrimitive(actual); c != nil {
+				s.Format = c.Name
+			}
 		}
 	case *design.Array:
 		s.Type = JSONArray
@@ -320,6 +358,12 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 	return s
 }
 
+// AttributeSchema produces the JSON schema corresponding to the given attribute including its
+// description, default value, example and validations.
+func AttributeSchema(api *design.APIDefinition, at *design.AttributeDefinition) *JSONSchema {
+	return buildAttributeSchema(api, NewJSONSchema(), at)
+}
+
 type mergeItems []struct {
 	a, b   interface{}
 	needed bool
@@ -415,6 +459,10 @@ func (s *JSONSchema) Dup() *JSONSchema {
 		Title:                s.Title,
 		Media:                s.Media,
 		ReadOnly:             s.ReadOnly,
+		Nullable:             s.Nullable,
+		Deprecated:           s.Deprecated,
+		ExternalDocs:         s.ExternalDocs,
+		Extensions:           s.Extensions,
 		PathStart:            s.PathStart,
 		Links:                s.Links,
 		Ref:                  s.Ref,
@@ -459,6 +507,12 @@ func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.A
 	s.Description = at.Description
 	s.Example = at.GenerateExample(api.RandomGenerator(), nil)
 	s.ReadOnly = at.IsReadOnly()
+	s.Nullable = isNullable(at)
+	s.Deprecated = IsDeprecated(at.Metadata)
+	if url, desc := ExternalDocs(at.Metadata); url != "" {
+		s.ExternalDocs = &JSONExternalDocs{Description: desc, URL: url}
+	}
+	s.Extensions = Extensions(at.Metadata, ExtensionMetadataPrefix)
 	val := at.Validation
 	if val == nil {
 		return s
@@ -79,4 +79,31 @@ var _ = Describe("TypeSchema", func() {
 		})
 
 	})
+
+	Context("with deprecated and documented attributes", func() {
+		BeforeEach(func() {
+			genschema.Definitions = make(map[string]*genschema.JSONSchema)
+			typ = apidsl.Type("Bottle", func() {
+				apidsl.Attribute("vintage", design.Integer, func() {
+					apidsl.Deprecated()
+					apidsl.Metadata("swagger:externaldocs:url", "https://docs.example.com/vintage")
+					apidsl.Metadata("swagger:externaldocs:description", "Vintages")
+				})
+				apidsl.Attribute("name", design.String)
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("marks the attribute schemas", func() {
+			Ω(s.Ref).Should(Equal("#/definitions/Bottle"))
+			props := genschema.Definitions["Bottle"].Properties
+			Ω(props["vintage"].Deprecated).Should(BeTrue())
+			Ω(props["vintage"].ExternalDocs).Should(Equal(&genschema.JSONExternalDocs{
+				Description: "Vintages",
+				URL:         "https://docs.example.com/vintage",
+			}))
+			Ω(props["name"].Deprecated).Should(BeFalse())
+			Ω(props["name"].ExternalDocs).Should(BeNil())
+		})
+	})
 })
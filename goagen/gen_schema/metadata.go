@@ -0,0 +1,71 @@
+package genschema
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/dslengine"
+)
+
+const (
+	// DeprecatedMetadata is the name of the action and attribute metadata that marks the
+	// definition as deprecated, the Deprecated DSL sets it.
+	DeprecatedMetadata = "swagger:deprecated"
+
+	// ExternalDocsURLMetadata is the name of the action and attribute metadata that sets the URL
+	// of the external documentation of the definition.
+	ExternalDocsURLMetadata = "swagger:externaldocs:url"
+
+	// ExternalDocsDescriptionMetadata is the name of the action and attribute metadata that sets
+	// the description of the external documentation of the definition.
+	ExternalDocsDescriptionMetadata = "swagger:externaldocs:description"
+
+	// ExtensionMetadataPrefix is the prefix of the metadata that set specification extensions,
+	// e.g. "swagger:extension:x-foo".
+	ExtensionMetadataPrefix = "swagger:extension:"
+)
+
+// IsDeprecated returns true if the metadata marks the definition as deprecated, that is if it
+// sets DeprecatedMetadata to any value but "false".
+func IsDeprecated(mdata dslengine.MetadataDefinition) bool {
+	vals, ok := mdata[DeprecatedMetadata]
+	return ok && (len(vals) == 0 || vals[0] != "false")
+}
+
+// ExternalDocs returns the URL and description of the external documentation set by the metadata,
+// url is empty if the metadata does not set any.
+func ExternalDocs(mdata dslengine.MetadataDefinition) (url, description string) {
+	if vals := mdata[ExternalDocsURLMetadata]; len(vals) > 0 {
+		url = vals[0]
+	}
+	if vals := mdata[ExternalDocsDescriptionMetadata]; len(vals) > 0 {
+		description = vals[0]
+	}
+	return
+}
+
+// Extensions returns the specification extensions set by the metadata whose name is the given
+// prefix followed by the extension name, e.g. "x-foo". The metadata values are parsed as JSON
+// and used as strings if invalid. Extensions returns nil if the metadata sets no extension.
+func Extensions(mdata dslengine.MetadataDefinition, prefix string) map[string]interface{} {
+	var extensions map[string]interface{}
+	for key, value := range mdata {
+		if !strings.HasPrefix(key, prefix) || len(value) == 0 {
+			continue
+		}
+		name := key[len(prefix):]
+		if !strings.HasPrefix(name, "x-") || strings.Contains(name, ":") {
+			continue
+		}
+		if extensions == nil {
+			extensions = make(map[string]interface{})
+		}
+		var ival interface{}
+		if err := json.Unmarshal([]byte(value[0]), &ival); err != nil {
+			extensions[name] = value[0]
+			continue
+		}
+		extensions[name] = ival
+	}
+	return extensions
+}
@@ -18,3 +18,10 @@ func OutDir(outDir string) Option {
 		g.OutDir = outDir
 	}
 }
+
+//Draft JSON Schema draft of the generated document
+func Draft(draft string) Option {
+	return func(g *Generator) {
+		g.Draft = draft
+	}
+}
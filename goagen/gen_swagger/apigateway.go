@@ -0,0 +1,223 @@
+package genswagger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/dslengine"
+)
+
+// apiGatewayExtension is the name of the swagger extension that describes the API Gateway
+// integration of an operation.
+const apiGatewayExtension = "x-amazon-apigateway-integration"
+
+// apiGatewayMetadataPrefix is the prefix of the metadata keys that set the fields of the API
+// Gateway integrations.
+const apiGatewayMetadataPrefix = "apigateway:integration:"
+
+// apiGatewayTypes lists the supported API Gateway integration types.
+var apiGatewayTypes = map[string]bool{
+	"aws":        true,
+	"aws_proxy":  true,
+	"http":       true,
+	"http_proxy": true,
+	"mock":       true,
+}
+
+// APIGatewayURIData is the data used to render the template of the integration URIs.
+type APIGatewayURIData struct {
+	// Resource is the name of the resource.
+	Resource string
+	// Action is the name of the action.
+	Action string
+	// Path is the full path of the route using the swagger syntax for parameters, e.g.
+	// "/bottles/{id}".
+	Path string
+	// Method is the HTTP method of the route.
+	Method string
+}
+
+// AddAPIGatewayIntegrations adds a x-amazon-apigateway-integration extension to the operations
+// of s so that the specification can be imported in API Gateway. integrationType is the default
+// integration type and uri the text/template of the default integration URI rendered with a
+// APIGatewayURIData. The fields of the integration may be overridden with the
+// "apigateway:integration:<field>" metadata of the API, the resources and the actions.
+func AddAPIGatewayIntegrations(s *Swagger, api *design.APIDefinition, integrationType, uri string) error {
+	if !apiGatewayTypes[integrationType] {
+		return fmt.Errorf(`invalid API Gateway integration type %#v, must be one of "aws", "aws_proxy", "http", "http_proxy" or "mock"`, integrationType)
+	}
+	uriTmpl, err := template.New("uri").Parse(uri)
+	if err != nil {
+		return fmt.Errorf("invalid API Gateway integration URI template: %s", err)
+	}
+	return api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if !mustGenerate(a.Metadata) || a.WebSocket() {
+				return nil
+			}
+			for _, route := range a.Routes {
+				op := operation(s, pathKey(route, s.BasePath), route.Verb)
+				if op == nil {
+					continue
+				}
+				integration, err := apiGatewayIntegration(api, a, route, integrationType, uriTmpl)
+				if err != nil {
+					return fmt.Errorf("%s: %s", a.Context(), err)
+				}
+				if op.Extensions == nil {
+					op.Extensions = make(map[string]interface{})
+				}
+				op.Extensions[apiGatewayExtension] = integration
+			}
+			return nil
+		})
+	})
+}
+
+// apiGatewayIntegration builds the integration of the given action route.
+func apiGatewayIntegration(api *design.APIDefinition, a *design.ActionDefinition, route *design.RouteDefinition, integrationType string, uri *template.Template) (map[string]interface{}, error) {
+	integration := make(map[string]interface{})
+	for _, mdata := range []dslengine.MetadataDefinition{api.Metadata, a.Parent.Metadata, a.Metadata} {
+		for k, v := range apiGatewayMetadata(mdata) {
+			integration[k] = v
+		}
+	}
+	if t, ok := integration["type"].(string); ok {
+		integrationType = t
+	} else {
+		integration["type"] = integrationType
+	}
+	if _, ok := integration["uri"]; !ok {
+		path := pathKey(route, "/")
+		data := &APIGatewayURIData{
+			Resource: a.Parent.Name,
+			Action:   a.Name,
+			Path:     path,
+			Method:   route.Verb,
+		}
+		var buf bytes.Buffer
+		if err := uri.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		integration["uri"] = buf.String()
+	}
+	if _, ok := integration["httpMethod"]; !ok {
+		method := route.Verb
+		if integrationType == "aws" || integrationType == "aws_proxy" {
+			// Lambda functions are always invoked with POST
+			method = "POST"
+		}
+		integration["httpMethod"] = method
+	}
+	if _, ok := integration["requestParameters"]; !ok && strings.HasPrefix(integrationType, "http") {
+		if params := requestParameters(a, route, integrationType == "http"); len(params) > 0 {
+			integration["requestParameters"] = params
+		}
+	}
+	if integrationType == "aws" || integrationType == "http" || integrationType == "mock" {
+		status := successStatus(a)
+		if _, ok := integration["passthroughBehavior"]; !ok {
+			integration["passthroughBehavior"] = "when_no_match"
+		}
+		if _, ok := integration["responses"]; !ok {
+			integration["responses"] = map[string]interface{}{
+				"default": map[string]interface{}{"statusCode": fmt.Sprintf("%d", status)},
+			}
+		}
+		if _, ok := integration["requestTemplates"]; !ok && integrationType == "mock" {
+			integration["requestTemplates"] = map[string]interface{}{
+				"application/json": fmt.Sprintf(`{"statusCode": %d}`, status),
+			}
+		}
+	}
+	return integration, nil
+}
+
+// apiGatewayMetadata returns the integration fields set by the given metadata, the values are
+// parsed as JSON when valid.
+func apiGatewayMetadata(mdata dslengine.MetadataDefinition) map[string]interface{} {
+	fields := make(map[string]interface{})
+	for key, value := range mdata {
+		if !strings.HasPrefix(key, apiGatewayMetadataPrefix) || len(value) == 0 {
+			continue
+		}
+		name := strings.TrimPrefix(key, apiGatewayMetadataPrefix)
+		var ival interface{}
+		if err := json.Unmarshal([]byte(value[0]), &ival); err != nil {
+			fields[name] = value[0]
+			continue
+		}
+		fields[name] = ival
+	}
+	return fields
+}
+
+// requestParameters maps the path parameters of the route to the parameters of the integration
+// request, query string parameters and headers are also mapped if all is true.
+func requestParameters(a *design.ActionDefinition, route *design.RouteDefinition, all bool) map[string]interface{} {
+	params := make(map[string]interface{})
+	pathParams := make(map[string]bool)
+	for _, n := range route.Params() {
+		pathParams[n] = true
+		params["integration.request.path."+n] = "method.request.path." + n
+	}
+	if !all {
+		return params
+	}
+	if obj := a.AllParams().Type.ToObject(); obj != nil {
+		for n := range obj {
+			if !pathParams[n] {
+				params["integration.request.querystring."+n] = "method.request.querystring." + n
+			}
+		}
+	}
+	a.IterateHeaders(func(name string, _ bool, _ *design.AttributeDefinition) error {
+		params["integration.request.header."+name] = "method.request.header." + name
+		return nil
+	})
+	return params
+}
+
+// successStatus returns the lowest 2xx status of the action responses, 200 if there is none.
+func successStatus(a *design.ActionDefinition) int {
+	status := 0
+	for _, r := range a.Responses {
+		if r.Status >= 200 && r.Status < 300 && (status == 0 || r.Status < status) {
+			status = r.Status
+		}
+	}
+	if status == 0 {
+		return 200
+	}
+	return status
+}
+
+// operation returns the operation of the path with the given key for the given HTTP method, nil
+// if there is none.
+func operation(s *Swagger, key, verb string) *Operation {
+	p, ok := s.Paths[key].(*Path)
+	if !ok {
+		return nil
+	}
+	switch verb {
+	case "GET":
+		return p.Get
+	case "PUT":
+		return p.Put
+	case "POST":
+		return p.Post
+	case "DELETE":
+		return p.Delete
+	case "OPTIONS":
+		return p.Options
+	case "HEAD":
+		return p.Head
+	case "PATCH":
+		return p.Patch
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package genswagger_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genschema "github.com/kyokomi/goa-v1/goagen/gen_schema"
+	genswagger "github.com/kyokomi/goa-v1/goagen/gen_swagger"
+)
+
+var _ = Describe("AddAPIGatewayIntegrations", func() {
+	var integrationType, uri string
+	var swagger *genswagger.Swagger
+	var addErr error
+
+	// integration returns the integration of the operation with the given path and method.
+	integration := func(path, method string) map[string]interface{} {
+		b, err := json.Marshal(swagger)
+		Ω(err).ShouldNot(HaveOccurred())
+		var doc struct {
+			Paths map[string]map[string]map[string]interface{} `json:"paths"`
+		}
+		Ω(json.Unmarshal(b, &doc)).Should(Succeed())
+		op := doc.Paths[path][method]
+		Ω(op).ShouldNot(BeNil())
+		i, _ := op["x-amazon-apigateway-integration"].(map[string]interface{})
+		return i
+	}
+
+	BeforeEach(func() {
+		integrationType = "aws_proxy"
+		uri = "arn:aws:apigateway:us-east-1:lambda:path/2015-03-31/functions/{{ .Resource }}-{{ .Action }}/invocations"
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		apidsl.API("cellar", func() {
+			apidsl.BasePath("/api")
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.BasePath("/bottles")
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/:id"))
+				apidsl.Params(func() {
+					apidsl.Param("id", design.Integer)
+					apidsl.Param("verbose", design.Boolean)
+				})
+				apidsl.Headers(func() {
+					apidsl.Header("X-Request-Id")
+				})
+				apidsl.Response(design.OK)
+			})
+			apidsl.Action("create", func() {
+				apidsl.Routing(apidsl.POST(""))
+				apidsl.Metadata("apigateway:integration:timeoutInMillis", "3000")
+				apidsl.Metadata("apigateway:integration:uri", "arn:custom")
+				apidsl.Response(design.Created)
+			})
+			apidsl.Action("hidden", func() {
+				apidsl.Routing(apidsl.DELETE("/:id"))
+				apidsl.Metadata("swagger:generate", "false")
+				apidsl.Response(design.NoContent)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		var err error
+		swagger, err = genswagger.New(design.Design)
+		Ω(err).ShouldNot(HaveOccurred())
+		addErr = genswagger.AddAPIGatewayIntegrations(swagger, design.Design, integrationType, uri)
+	})
+
+	It("adds Lambda proxy integrations invoked with POST", func() {
+		Ω(addErr).ShouldNot(HaveOccurred())
+		i := integration("/bottles/{id}", "get")
+		Ω(i).Should(HaveKeyWithValue("type", "aws_proxy"))
+		Ω(i).Should(HaveKeyWithValue("httpMethod", "POST"))
+		Ω(i).Should(HaveKeyWithValue("uri", "arn:aws:apigateway:us-east-1:lambda:path/2015-03-31/functions/bottle-show/invocations"))
+		Ω(i).ShouldNot(HaveKey("requestParameters"))
+		Ω(i).ShouldNot(HaveKey("responses"))
+	})
+
+	It("applies the action metadata", func() {
+		Ω(addErr).ShouldNot(HaveOccurred())
+		i := integration("/bottles", "post")
+		Ω(i).Should(HaveKeyWithValue("uri", "arn:custom"))
+		Ω(i).Should(HaveKeyWithValue("timeoutInMillis", 3000.0))
+	})
+
+	It("skips the operations that are not generated", func() {
+		Ω(addErr).ShouldNot(HaveOccurred())
+		b, err := json.Marshal(swagger)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).ShouldNot(ContainSubstring(`"delete"`))
+	})
+
+	Context("with HTTP integrations", func() {
+		BeforeEach(func() {
+			integrationType = "http"
+			uri = "https://backend.example.com{{ .Path }}"
+		})
+
+		It("maps the request parameters and the default response", func() {
+			Ω(addErr).ShouldNot(HaveOccurred())
+			i := integration("/bottles/{id}", "get")
+			Ω(i).Should(HaveKeyWithValue("type", "http"))
+			Ω(i).Should(HaveKeyWithValue("httpMethod", "GET"))
+			Ω(i).Should(HaveKeyWithValue("uri", "https://backend.example.com/api/bottles/{id}"))
+			Ω(i).Should(HaveKeyWithValue("passthroughBehavior", "when_no_match"))
+			Ω(i["requestParameters"]).Should(Equal(map[string]interface{}{
+				"integration.request.path.id":             "method.request.path.id",
+				"integration.request.querystring.verbose": "method.request.querystring.verbose",
+				"integration.request.header.X-Request-Id": "method.request.header.X-Request-Id",
+			}))
+			Ω(i["responses"]).Should(Equal(map[string]interface{}{
+				"default": map[string]interface{}{"statusCode": "200"},
+			}))
+		})
+	})
+
+	Context("with mock integrations", func() {
+		BeforeEach(func() {
+			integrationType = "mock"
+		})
+
+		It("responds with the success status", func() {
+			Ω(addErr).ShouldNot(HaveOccurred())
+			i := integration("/bottles", "post")
+			Ω(i).Should(HaveKeyWithValue("type", "mock"))
+			Ω(i["requestTemplates"]).Should(Equal(map[string]interface{}{
+				"application/json": `{"statusCode": 201}`,
+			}))
+			Ω(i["responses"]).Should(Equal(map[string]interface{}{
+				"default": map[string]interface{}{"statusCode": "201"},
+			}))
+		})
+	})
+
+	Context("with an unknown integration type", func() {
+		BeforeEach(func() {
+			integrationType = "lambda"
+		})
+
+		It("returns an error", func() {
+			Ω(addErr).Should(HaveOccurred())
+			Ω(addErr.Error()).Should(ContainSubstring(`invalid API Gateway integration type "lambda"`))
+		})
+	})
+})
@@ -5,5 +5,32 @@ This JSON swagger can be used to generate API documentation, ruby and Go API cli
 See the blog post (https://blog.heroku.com/archives/2014/1/8/json_swagger_for_heroku_platform_api)
 describing how Heroku leverages the JSON Hyper-swagger standard (http://json-swagger.org/latest/json-swagger-hypermedia.html)
 for more information.
+
+The generator also produces a "swagger" Go package that embeds the specification files in the
+binary, its MountSwaggerController function mounts a controller serving them, e.g. under
+"GET /swagger.json" and "GET /swagger.yaml", so that the service does not need to ship them.
+
+With --docs the generator also produces a "swagger" Go package whose MountDocsController function
+mounts a controller serving the swagger specification and a Swagger UI (--docs=swagger-ui) or ReDoc
+(--docs=redoc) page rendering it under --docs-path ("/docs" by default). The page loads the
+Swagger UI or ReDoc bundle from its CDN.
+
+With --apigateway the operations of the specification get x-amazon-apigateway-integration
+extensions so that it can be imported directly in AWS API Gateway. The flag value is the default
+integration type: "aws_proxy" or "aws" for Lambda functions, "http_proxy" or "http" for HTTP
+backends and "mock". --apigateway-uri is the default integration URI, a text/template rendered
+with the resource and action names as well as the path and method of the route, e.g.
+
+	goagen swagger -d example.com/design --apigateway http_proxy \
+		--apigateway-uri 'https://backend.example.com{{ .Path }}'
+
+The "apigateway:integration:<field>" metadata of the API, resources and actions override the
+fields of the integrations.
+
+With --split the specification of very large APIs is split into one document per resource
+written under "swagger/resources" and a "swagger/definitions.json" file holding the type
+definitions that the documents reference with "$ref". swagger.json and swagger.yaml become an
+index whose paths reference the paths of the resource documents. --split cannot be combined
+with --docs as the documentation controller only serves swagger.json.
 */
 package genswagger
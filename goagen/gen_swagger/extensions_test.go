@@ -0,0 +1,80 @@
+package genswagger_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genschema "github.com/kyokomi/goa-v1/goagen/gen_schema"
+	genswagger "github.com/kyokomi/goa-v1/goagen/gen_swagger"
+)
+
+var _ = Describe("Extensions", func() {
+	var doc map[string]interface{}
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		bottle := apidsl.MediaType("application/vnd.bottle", func() {
+			apidsl.Metadata("swagger:extension:x-entity", `{"table":"bottles"}`)
+			apidsl.Attributes(func() {
+				apidsl.Attribute("name", design.String, func() {
+					apidsl.Metadata("swagger:extension:x-order", "1")
+				})
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("name")
+			})
+		})
+		apidsl.API("cellar", func() {
+			apidsl.Metadata("swagger:tag:Cellar")
+			apidsl.Metadata("swagger:tag:Cellar:extension:x-displayName", "Wine cellar")
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Response(design.OK, bottle, func() {
+					apidsl.Headers(func() {
+						apidsl.Header("X-Request-Id", func() {
+							apidsl.Metadata("swagger:extension:x-trace", "true")
+						})
+					})
+				})
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		s, err := genswagger.New(design.Design)
+		Ω(err).ShouldNot(HaveOccurred())
+		b, err := json.Marshal(s)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(json.Unmarshal(b, &doc)).ShouldNot(HaveOccurred())
+	})
+
+	It("renders the schema extensions", func() {
+		def := doc["definitions"].(map[string]interface{})["Bottle"].(map[string]interface{})
+		Ω(def).Should(HaveKeyWithValue("x-entity", map[string]interface{}{"table": "bottles"}))
+		name := def["properties"].(map[string]interface{})["name"].(map[string]interface{})
+		Ω(name).Should(HaveKeyWithValue("x-order", 1.0))
+	})
+
+	It("renders the response header extensions", func() {
+		show := doc["paths"].(map[string]interface{})["/bottles/{id}"].(map[string]interface{})["get"].(map[string]interface{})
+		ok := show["responses"].(map[string]interface{})["200"].(map[string]interface{})
+		header := ok["headers"].(map[string]interface{})["X-Request-Id"].(map[string]interface{})
+		Ω(header).Should(HaveKeyWithValue("x-trace", true))
+	})
+
+	It("renders the tag extensions", func() {
+		tags := doc["tags"].([]interface{})
+		Ω(tags).Should(HaveLen(1))
+		Ω(tags[0]).Should(HaveKeyWithValue("x-displayName", "Wine cellar"))
+	})
+})
@@ -4,11 +4,11 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
-
-	yaml "gopkg.in/yaml.v2"
+	"sort"
+	"strings"
+	"text/template"
 
 	"github.com/kyokomi/goa-v1/design"
 	"github.com/kyokomi/goa-v1/goagen/codegen"
@@ -30,14 +30,32 @@ func NewGenerator(options ...Option) *Generator {
 type Generator struct {
 	API      *design.APIDefinition // The API definition
 	OutDir   string                // Path to output directory
-	genfiles []string              // Generated files
+	Docs     string                // Documentation page UI: "swagger-ui", "redoc" or empty for none
+	DocsPath string                // Path of the documentation page, defaults to "/docs"
+	// APIGateway is the default API Gateway integration type: "aws", "aws_proxy", "http",
+	// "http_proxy" or "mock". The operations get x-amazon-apigateway-integration extensions when set.
+	APIGateway string
+	// APIGatewayURI is the text/template of the default integration URI, see APIGatewayURIData.
+	APIGatewayURI string
+	// Split writes one document per resource referencing a shared definitions file in addition
+	// to the index, see SplitByResource.
+	Split    bool
+	genfiles []string // Generated files
+}
+
+// docsPages maps the supported documentation UIs to the template of their HTML page.
+var docsPages = map[string]string{
+	"swagger-ui": swaggerUIT,
+	"redoc":      redocT,
 }
 
 // Generate is the generator entry point called by the meta generator.
 func Generate() (files []string, err error) {
 	var (
 		outDir, toolDir, target, ver string
-		notool, regen                bool
+		docs, docsPath               string
+		apiGateway, apiGatewayURI    string
+		notool, regen, split         bool
 	)
 
 	set := flag.NewFlagSet("swagger", flag.PanicOnError)
@@ -48,6 +66,11 @@ func Generate() (files []string, err error) {
 	set.BoolVar(&notool, "notool", false, "")
 	set.StringVar(&target, "pkg", "app", "")
 	set.BoolVar(&regen, "regen", false, "")
+	set.StringVar(&docs, "docs", "", "")
+	set.StringVar(&docsPath, "docs-path", "/docs", "")
+	set.StringVar(&apiGateway, "apigateway", "", "")
+	set.StringVar(&apiGatewayURI, "apigateway-uri", "", "")
+	set.BoolVar(&split, "split", false, "")
 	set.Bool("force", false, "")
 	set.Bool("notest", false, "")
 	set.Parse(os.Args[1:])
@@ -56,7 +79,15 @@ func Generate() (files []string, err error) {
 		return nil, err
 	}
 
-	g := &Generator{OutDir: outDir, API: design.Design}
+	g := &Generator{
+		OutDir:        outDir,
+		Docs:          docs,
+		DocsPath:      docsPath,
+		APIGateway:    apiGateway,
+		APIGatewayURI: apiGatewayURI,
+		Split:         split,
+		API:           design.Design,
+	}
 
 	return g.Generate()
 }
@@ -66,6 +97,12 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if g.API == nil {
 		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
 	}
+	if _, ok := docsPages[g.Docs]; g.Docs != "" && !ok {
+		return nil, fmt.Errorf(`invalid documentation UI %#v, must be "swagger-ui" or "redoc"`, g.Docs)
+	}
+	if g.Split && g.Docs != "" {
+		return nil, fmt.Errorf("the documentation page cannot serve a split specification, remove --docs or --split")
+	}
 
 	go utils.Catch(nil, func() { g.Cleanup() })
 
@@ -79,21 +116,34 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if err != nil {
 		return nil, err
 	}
+	if g.APIGateway != "" {
+		if err = AddAPIGatewayIntegrations(s, g.API, g.APIGateway, g.APIGatewayURI); err != nil {
+			return nil, err
+		}
+	}
 
 	swaggerDir := filepath.Join(g.OutDir, "swagger")
-	os.RemoveAll(swaggerDir)
+	codegen.RemoveAll(swaggerDir)
 	if err = os.MkdirAll(swaggerDir, 0755); err != nil {
 		return nil, err
 	}
 	g.genfiles = append(g.genfiles, swaggerDir)
 
 	// JSON
-	rawJSON, err := json.Marshal(s)
+	var rawJSON []byte
+	specFiles := []string{"swagger.json", "swagger.yaml"}
+	if g.Split {
+		var splitFiles []string
+		rawJSON, splitFiles, err = g.generateSplit(swaggerDir, s)
+		specFiles = append(specFiles, splitFiles...)
+	} else {
+		rawJSON, err = json.Marshal(s)
+	}
 	if err != nil {
 		return nil, err
 	}
 	swaggerFile := filepath.Join(swaggerDir, "swagger.json")
-	if err := ioutil.WriteFile(swaggerFile, rawJSON, 0644); err != nil {
+	if err := codegen.WriteFile(swaggerFile, rawJSON, 0644); err != nil {
 		return nil, err
 	}
 	g.genfiles = append(g.genfiles, swaggerFile)
@@ -104,14 +154,138 @@ func (g *Generator) Generate() (_ []string, err error) {
 		return nil, err
 	}
 	swaggerFile = filepath.Join(swaggerDir, "swagger.yaml")
-	if err := ioutil.WriteFile(swaggerFile, rawYAML, 0644); err != nil {
+	if err := codegen.WriteFile(swaggerFile, rawYAML, 0644); err != nil {
 		return nil, err
 	}
 	g.genfiles = append(g.genfiles, swaggerFile)
 
+	// Embedded specification controller
+	if err = g.generateSpec(filepath.Join(swaggerDir, "spec.go"), specFiles); err != nil {
+		return nil, err
+	}
+
+	// Documentation controller
+	if g.Docs != "" {
+		if err = g.generateDocs(filepath.Join(swaggerDir, "docs.go")); err != nil {
+			return nil, err
+		}
+	}
+
 	return g.genfiles, nil
 }
 
+// generateSplit writes the resource and definitions files of the split specification and returns
+// the content of the index and the paths of the files relative to the swagger directory.
+func (g *Generator) generateSplit(swaggerDir string, s *Swagger) ([]byte, []string, error) {
+	split, err := SplitByResource(s, g.API)
+	if err != nil {
+		return nil, nil, err
+	}
+	files, err := split.Files()
+	if err != nil {
+		return nil, nil, err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if name != "swagger.json" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		file := filepath.Join(swaggerDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return nil, nil, err
+		}
+		if err := codegen.WriteFile(file, files[name], 0644); err != nil {
+			return nil, nil, err
+		}
+		g.genfiles = append(g.genfiles, file)
+	}
+	return files["swagger.json"], names, nil
+}
+
+// generateSpec generates the controller that serves the given specification files embedded in
+// the binary.
+func (g *Generator) generateSpec(specFile string, specFiles []string) (err error) {
+	file, err := codegen.SourceFileFor(specFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("embed"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("path"),
+		codegen.NewImport("goa", "github.com/kyokomi/goa-v1"),
+	}
+	if err = file.WriteHeader(fmt.Sprintf("%s: Swagger Controller", g.API.Context()), "swagger", imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, specFile)
+
+	data := map[string]interface{}{"Files": specFiles}
+	return file.ExecuteTemplate("spec", specCtrlT, nil, data)
+}
+
+// generateDocs generates the controller that serves the swagger specification and the
+// documentation page.
+func (g *Generator) generateDocs(docsFile string) (err error) {
+	path := g.DocsPath
+	if path == "" {
+		path = "/docs"
+	}
+	path = "/" + strings.Trim(path, "/")
+	title := g.API.Title
+	if title == "" {
+		title = g.API.Name
+	}
+	page, err := template.New("page").Parse(docsPages[g.Docs])
+	if err != nil {
+		return err
+	}
+	var html strings.Builder
+	data := map[string]interface{}{"Title": title, "SpecPath": strings.TrimSuffix(path, "/") + "/swagger.json"}
+	if err = page.Execute(&html, data); err != nil {
+		return err
+	}
+
+	file, err := codegen.SourceFileFor(docsFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.NewImport("_", "embed"),
+		codegen.SimpleImport("net/http"),
+		codegen.NewImport("goa", "github.com/kyokomi/goa-v1"),
+	}
+	if err = file.WriteHeader(fmt.Sprintf("%s: Documentation Controller", g.API.Context()), "swagger", imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, docsFile)
+
+	data = map[string]interface{}{
+		"Path":     path,
+		"SpecPath": data["SpecPath"],
+		"UI":       g.Docs,
+		"Page":     html.String(),
+	}
+	return file.ExecuteTemplate("docs", docsCtrlT, nil, data)
+}
+
 // Cleanup removes all the files generated by this generator during the last invokation of Generate.
 func (g *Generator) Cleanup() {
 	for _, f := range g.genfiles {
@@ -121,10 +295,107 @@ func (g *Generator) Cleanup() {
 }
 
 func jsonToYAML(rawJSON []byte) ([]byte, error) {
-	var yamlSource interface{}
-	if err := yaml.Unmarshal(rawJSON, &yamlSource); err != nil {
-		return nil, err
+	return codegen.JSONToYAML(rawJSON)
+}
+
+const specCtrlT = `// FS holds the swagger specification files.
+//
+//go:embed{{ range .Files }} {{ . }}{{ end }}
+var FS embed.FS
+
+// SpecFiles lists the paths of the specification files in FS, MountSwaggerController serves
+// each file under its path, e.g. "GET /swagger.json".
+var SpecFiles = []string{
+{{ range .Files }}	{{ printf "%q" . }},
+{{ end }}}
+
+// MountSwaggerController mounts the controller that serves the swagger specification files
+// embedded in the binary.
+func MountSwaggerController(service *goa.Service) {
+	ctrl := service.NewController("Swagger")
+	for _, name := range SpecFiles {
+		content, err := FS.ReadFile(name)
+		if err != nil {
+			panic(err) // bug
+		}
+		contentType := "application/json"
+		if path.Ext(name) == ".yaml" {
+			contentType = "application/x-yaml"
+		}
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			rw.Header().Set("Content-Type", contentType)
+			_, err := rw.Write(content)
+			return err
+		}
+		route := "/" + name
+		ctrl.NameRoute("GET", route, "serve")
+		service.Mux.Handle("GET", route, ctrl.MuxHandler("serve", h, nil))
+		service.LogInfo("mount", "ctrl", "Swagger", "files", name, "route", "GET "+route)
 	}
+}
+`
 
-	return yaml.Marshal(yamlSource)
+const docsCtrlT = `// DocsPath is the path of the documentation page.
+const DocsPath = {{ printf "%q" .Path }}
+
+// SpecPath is the path of the swagger specification.
+const SpecPath = {{ printf "%q" .SpecPath }}
+
+//go:embed swagger.json
+var spec []byte
+
+// MountDocsController mounts the controller that serves the swagger specification under
+// SpecPath and the {{ .UI }} documentation page rendering it under DocsPath.
+func MountDocsController(service *goa.Service) {
+	ctrl := service.NewController("Docs")
+	servePage := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, err := rw.Write([]byte(page))
+		return err
+	}
+	serveSpec := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		rw.Header().Set("Content-Type", "application/json")
+		_, err := rw.Write(spec)
+		return err
+	}
+	ctrl.NameRoute("GET", DocsPath, "page")
+	service.Mux.Handle("GET", DocsPath, ctrl.MuxHandler("page", servePage, nil))
+	service.LogInfo("mount", "ctrl", "Docs", "action", "Page", "route", "GET "+DocsPath)
+	ctrl.NameRoute("GET", SpecPath, "spec")
+	service.Mux.Handle("GET", SpecPath, ctrl.MuxHandler("spec", serveSpec, nil))
+	service.LogInfo("mount", "ctrl", "Docs", "action", "Spec", "route", "GET "+SpecPath)
 }
+
+// page is the HTML documentation page.
+const page = {{ printf "%q" .Page }}
+`
+
+const swaggerUIT = `<!doctype html>
+<html>
+  <head>
+    <meta charset="utf-8">
+    <title>{{ html .Title }}</title>
+    <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+  </head>
+  <body>
+    <div id="swagger-ui"></div>
+    <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+    <script>
+      window.ui = SwaggerUIBundle({url: {{ js .SpecPath | printf "'%s'" }}, dom_id: '#swagger-ui'});
+    </script>
+  </body>
+</html>
+`
+
+const redocT = `<!doctype html>
+<html>
+  <head>
+    <meta charset="utf-8">
+    <title>{{ html .Title }}</title>
+  </head>
+  <body>
+    <redoc spec-url="{{ html .SpecPath }}"></redoc>
+    <script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+  </body>
+</html>
+`
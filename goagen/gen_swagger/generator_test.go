@@ -1,10 +1,16 @@
 package genswagger_test
 
 import (
+	"io/ioutil"
+	"path/filepath"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
 	genswagger "github.com/kyokomi/goa-v1/goagen/gen_swagger"
 )
 
@@ -12,13 +18,23 @@ var _ = Describe("NewGenerator", func() {
 	var generator *genswagger.Generator
 
 	var args = struct {
-		api    *design.APIDefinition
-		outDir string
+		api           *design.APIDefinition
+		outDir        string
+		docs          string
+		docsPath      string
+		apiGateway    string
+		apiGatewayURI string
+		split         bool
 	}{
 		api: &design.APIDefinition{
 			Name: "test api",
 		},
-		outDir: "out_dir",
+		outDir:        "out_dir",
+		docs:          "redoc",
+		docsPath:      "/api-docs",
+		apiGateway:    "http_proxy",
+		apiGatewayURI: "https://backend.example.com{{ .Path }}",
+		split:         true,
 	}
 
 	Context("with options all options set", func() {
@@ -27,6 +43,11 @@ var _ = Describe("NewGenerator", func() {
 			generator = genswagger.NewGenerator(
 				genswagger.API(args.api),
 				genswagger.OutDir(args.outDir),
+				genswagger.Docs(args.docs),
+				genswagger.DocsPath(args.docsPath),
+				genswagger.APIGateway(args.apiGateway),
+				genswagger.APIGatewayURI(args.apiGatewayURI),
+				genswagger.Split(args.split),
 			)
 		})
 
@@ -34,6 +55,153 @@ var _ = Describe("NewGenerator", func() {
 			Ω(generator).ShouldNot(BeNil())
 			Ω(generator.API.Name).Should(Equal(args.api.Name))
 			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Docs).Should(Equal(args.docs))
+			Ω(generator.DocsPath).Should(Equal(args.docsPath))
+			Ω(generator.APIGateway).Should(Equal(args.apiGateway))
+			Ω(generator.APIGatewayURI).Should(Equal(args.apiGatewayURI))
+			Ω(generator.Split).Should(Equal(args.split))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir, docs, docsPath string
+	var split bool
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(workspace.Path, "")
+		Ω(err).ShouldNot(HaveOccurred())
+		docs, docsPath = "", ""
+		split = false
+
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {
+			apidsl.Title("Cellar API")
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Response(design.OK)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genswagger.NewGenerator(
+			genswagger.API(design.Design),
+			genswagger.OutDir(outDir),
+			genswagger.Docs(docs),
+			genswagger.DocsPath(docsPath),
+			genswagger.Split(split),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("generates the swagger specification only", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(HaveLen(4))
+		Ω(filepath.Join(outDir, "swagger", "docs.go")).ShouldNot(BeAnExistingFile())
+	})
+
+	It("generates the controller serving the embedded specification", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		specFile := filepath.Join(outDir, "swagger", "spec.go")
+		Ω(files).Should(ContainElement(specFile))
+		content, err := ioutil.ReadFile(specFile)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring("//go:embed swagger.json swagger.yaml\n"))
+		Ω(string(content)).Should(ContainSubstring("var FS embed.FS"))
+		Ω(string(content)).Should(ContainSubstring("func MountSwaggerController(service *goa.Service) {"))
+	})
+
+	Context("with the Swagger UI documentation", func() {
+		BeforeEach(func() {
+			docs = "swagger-ui"
+		})
+
+		It("generates the documentation controller", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			docsFile := filepath.Join(outDir, "swagger", "docs.go")
+			Ω(files).Should(ContainElement(docsFile))
+			content, err := ioutil.ReadFile(docsFile)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("func MountDocsController(service *goa.Service) {"))
+			Ω(string(content)).Should(ContainSubstring(`const DocsPath = "/docs"`))
+			Ω(string(content)).Should(ContainSubstring(`const SpecPath = "/docs/swagger.json"`))
+			Ω(string(content)).Should(ContainSubstring("//go:embed swagger.json"))
+			Ω(string(content)).Should(ContainSubstring("swagger-ui-bundle.js"))
+			Ω(string(content)).Should(ContainSubstring(`<title>Cellar API</title>`))
+		})
+	})
+
+	Context("with the ReDoc documentation and a custom path", func() {
+		BeforeEach(func() {
+			docs = "redoc"
+			docsPath = "/api/docs/"
+		})
+
+		It("generates the documentation controller serving the page under the path", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "swagger", "docs.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`const DocsPath = "/api/docs"`))
+			Ω(string(content)).Should(ContainSubstring(`const SpecPath = "/api/docs/swagger.json"`))
+			Ω(string(content)).Should(ContainSubstring(`<redoc spec-url=\"/api/docs/swagger.json\">`))
+		})
+	})
+
+	Context("with split output", func() {
+		BeforeEach(func() {
+			split = true
+		})
+
+		It("generates the index, resource and definitions files", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			swaggerDir := filepath.Join(outDir, "swagger")
+			Ω(files).Should(ContainElement(filepath.Join(swaggerDir, "definitions.json")))
+			Ω(files).Should(ContainElement(filepath.Join(swaggerDir, "resources", "bottle.json")))
+			Ω(files).Should(ContainElement(filepath.Join(swaggerDir, "swagger.json")))
+			Ω(files).Should(ContainElement(filepath.Join(swaggerDir, "swagger.yaml")))
+			content, err := ioutil.ReadFile(filepath.Join(swaggerDir, "swagger.json"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`"/bottles/{id}":{"$ref":"resources/bottle.json#/paths/~1bottles~1%7Bid%7D"}`))
+			content, err = ioutil.ReadFile(filepath.Join(swaggerDir, "spec.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("//go:embed swagger.json swagger.yaml definitions.json resources/bottle.json\n"))
+		})
+
+		Context("with the documentation", func() {
+			BeforeEach(func() {
+				docs = "swagger-ui"
+			})
+
+			It("returns an error", func() {
+				Ω(genErr).Should(HaveOccurred())
+				Ω(genErr.Error()).Should(ContainSubstring("cannot serve a split specification"))
+			})
+		})
+	})
+
+	Context("with an unknown documentation UI", func() {
+		BeforeEach(func() {
+			docs = "rapidoc"
+		})
+
+		It("returns an error", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(ContainSubstring(`invalid documentation UI "rapidoc"`))
+			Ω(files).Should(BeEmpty())
 		})
 	})
 })
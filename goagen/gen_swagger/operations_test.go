@@ -0,0 +1,80 @@
+package genswagger_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genschema "github.com/kyokomi/goa-v1/goagen/gen_schema"
+	genswagger "github.com/kyokomi/goa-v1/goagen/gen_swagger"
+)
+
+var _ = Describe("Operations", func() {
+	var list, show *genswagger.Operation
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		apidsl.API("cellar", func() {})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("list", func() {
+				apidsl.Deprecated()
+				apidsl.Docs(func() {
+					apidsl.URL("https://docs.example.com")
+				})
+				apidsl.Metadata("swagger:externaldocs:url", "https://docs.example.com/bottles")
+				apidsl.Metadata("swagger:externaldocs:description", "Bottle guide")
+				apidsl.Routing(apidsl.GET("/bottles"))
+				apidsl.Params(func() {
+					apidsl.Param("sort", design.String, func() {
+						apidsl.Deprecated()
+					})
+					apidsl.Param("limit", design.Integer)
+				})
+				apidsl.Response(design.OK)
+			})
+			apidsl.Action("show", func() {
+				apidsl.Docs(func() {
+					apidsl.URL("https://docs.example.com")
+				})
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Response(design.OK)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		s, err := genswagger.New(design.Design)
+		Ω(err).ShouldNot(HaveOccurred())
+		list = s.Paths["/bottles"].(*genswagger.Path).Get
+		show = s.Paths["/bottles/{id}"].(*genswagger.Path).Get
+	})
+
+	It("marks the deprecated operations", func() {
+		Ω(list.Deprecated).Should(BeTrue())
+		Ω(show.Deprecated).Should(BeFalse())
+	})
+
+	It("sets the external docs from the metadata", func() {
+		Ω(list.ExternalDocs).Should(Equal(&genswagger.ExternalDocs{
+			Description: "Bottle guide",
+			URL:         "https://docs.example.com/bottles",
+		}))
+		Ω(show.ExternalDocs).Should(Equal(&genswagger.ExternalDocs{URL: "https://docs.example.com"}))
+	})
+
+	It("marks the deprecated parameters with an extension", func() {
+		for _, p := range list.Parameters {
+			switch p.Name {
+			case "sort":
+				Ω(p.Extensions).Should(HaveKeyWithValue("x-deprecated", true))
+			case "limit":
+				Ω(p.Extensions).ShouldNot(HaveKey("x-deprecated"))
+			}
+		}
+	})
+})
@@ -18,3 +18,38 @@ func OutDir(outDir string) Option {
 		g.OutDir = outDir
 	}
 }
+
+//Docs Documentation page UI, "swagger-ui" or "redoc"
+func Docs(ui string) Option {
+	return func(g *Generator) {
+		g.Docs = ui
+	}
+}
+
+//DocsPath Path of the documentation page
+func DocsPath(path string) Option {
+	return func(g *Generator) {
+		g.DocsPath = path
+	}
+}
+
+//APIGateway Default API Gateway integration type, "aws", "aws_proxy", "http", "http_proxy" or "mock"
+func APIGateway(integrationType string) Option {
+	return func(g *Generator) {
+		g.APIGateway = integrationType
+	}
+}
+
+//APIGatewayURI Template of the default API Gateway integration URI
+func APIGatewayURI(uri string) Option {
+	return func(g *Generator) {
+		g.APIGatewayURI = uri
+	}
+}
+
+//Split Write one document per resource referencing shared definitions
+func Split(split bool) Option {
+	return func(g *Generator) {
+		g.Split = split
+	}
+}
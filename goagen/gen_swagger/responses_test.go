@@ -0,0 +1,110 @@
+package genswagger_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genschema "github.com/kyokomi/goa-v1/goagen/gen_schema"
+	genswagger "github.com/kyokomi/goa-v1/goagen/gen_swagger"
+)
+
+var _ = Describe("Responses", func() {
+	var responses map[string]map[string]interface{}
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		apidsl.API("cellar", func() {
+			apidsl.ResponseTemplate("Throttled", func() {
+				apidsl.Status(429)
+				apidsl.Media(design.ErrorMedia)
+			})
+		})
+		bottle := apidsl.MediaType("application/vnd.bottle", func() {
+			apidsl.Attributes(func() {
+				apidsl.Attribute("id", design.Integer, func() {
+					apidsl.Example(1)
+				})
+				apidsl.Attribute("name", design.String, func() {
+					apidsl.Example("Chateau")
+				})
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("id")
+				apidsl.Attribute("name")
+			})
+			apidsl.View("tiny", func() {
+				apidsl.Attribute("id")
+			})
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Response(design.OK, func() {
+					apidsl.Media(bottle, "tiny")
+					apidsl.Headers(func() {
+						apidsl.Header("X-Expires", design.DateTime)
+						apidsl.Header("X-Tags", apidsl.ArrayOf(design.String), func() {
+							apidsl.MinLength(1)
+						})
+					})
+				})
+				apidsl.Response(design.NotFound)
+				apidsl.Response("Throttled")
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		s, err := genswagger.New(design.Design)
+		Ω(err).ShouldNot(HaveOccurred())
+		b, err := json.Marshal(s)
+		Ω(err).ShouldNot(HaveOccurred())
+		var doc struct {
+			Paths map[string]map[string]struct {
+				Responses map[string]map[string]interface{} `json:"responses"`
+			} `json:"paths"`
+		}
+		Ω(json.Unmarshal(b, &doc)).Should(Succeed())
+		responses = doc.Paths["/bottles/{id}"]["get"].Responses
+	})
+
+	It("describes the header types and validations", func() {
+		headers := responses["200"]["headers"].(map[string]interface{})
+		Ω(headers["X-Expires"]).Should(Equal(map[string]interface{}{
+			"type":   "string",
+			"format": "date-time",
+		}))
+		Ω(headers["X-Tags"]).Should(Equal(map[string]interface{}{
+			"type":             "array",
+			"items":            map[string]interface{}{"type": "string"},
+			"collectionFormat": "csv",
+			"minItems":         1.0,
+		}))
+	})
+
+	It("includes the examples of the response views", func() {
+		Ω(responses["200"]["examples"]).Should(Equal(map[string]interface{}{
+			"application/vnd.bottle": map[string]interface{}{"id": 1.0},
+		}))
+	})
+
+	It("describes error responses with the error media type", func() {
+		Ω(responses["404"]["schema"]).Should(Equal(map[string]interface{}{"$ref": "#/definitions/error"}))
+		examples := responses["404"]["examples"].(map[string]interface{})
+		Ω(examples["application/vnd.goa.error"]).Should(HaveKeyWithValue("status", "404"))
+	})
+
+	It("defaults the descriptions to the status text", func() {
+		Ω(responses["429"]["description"]).Should(Equal("Too Many Requests"))
+		examples := responses["429"]["examples"].(map[string]interface{})
+		Ω(examples["application/vnd.goa.error"]).Should(HaveKeyWithValue("status", "429"))
+	})
+})
@@ -0,0 +1,146 @@
+package genswagger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	genschema "github.com/kyokomi/goa-v1/goagen/gen_schema"
+)
+
+// DefinitionsFile is the name of the file that holds the definitions shared by the documents of
+// a split specification.
+const DefinitionsFile = "definitions.json"
+
+// SplitSwagger is a swagger specification split into one document per resource.
+type SplitSwagger struct {
+	// Index is the document describing the whole API. Its paths reference the paths of the
+	// resource documents, paths shared by several resources are described inline.
+	Index *Swagger
+	// Resources lists the resource documents indexed by the path of their file relative to the
+	// index file, e.g. "resources/bottle.json".
+	Resources map[string]*Swagger
+	// Definitions lists the type definitions referenced by the documents.
+	Definitions map[string]*genschema.JSONSchema
+}
+
+// SplitByResource splits the swagger specification s of the given API into one document per
+// resource. The resource documents only differ from s by their paths and definitions: the
+// definitions are moved to a shared file that the "$ref" properties of the documents point to.
+func SplitByResource(s *Swagger, api *design.APIDefinition) (*SplitSwagger, error) {
+	split := &SplitSwagger{
+		Resources:   make(map[string]*Swagger),
+		Definitions: s.Definitions,
+	}
+	owners := make(map[string][]string)
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		doc := *s
+		doc.Paths = make(map[string]interface{})
+		doc.Definitions = nil
+		for k, v := range extensionsFromDefinition(res.Metadata) {
+			doc.Paths[k] = v
+		}
+		// add copies the operation of the path with the given key to the resource document.
+		add := func(key, verb string) {
+			src, ok := s.Paths[key].(*Path)
+			if !ok {
+				return
+			}
+			dst, ok := doc.Paths[key].(*Path)
+			if !ok {
+				dst = &Path{Parameters: src.Parameters, Extensions: src.Extensions}
+				doc.Paths[key] = dst
+			}
+			setOperation(dst, verb, operation(s, key, verb))
+		}
+		for _, fs := range res.FileServers {
+			if mustGenerate(fs.Metadata) {
+				add(fileServerKey(fs), "GET")
+			}
+		}
+		res.IterateActions(func(a *design.ActionDefinition) error {
+			if !mustGenerate(a.Metadata) {
+				return nil
+			}
+			for _, route := range a.Routes {
+				add(pathKey(route, s.BasePath), route.Verb)
+			}
+			return nil
+		})
+		if len(doc.Paths) == 0 {
+			return nil
+		}
+		file := ResourceFile(res)
+		split.Resources[file] = &doc
+		for key, p := range doc.Paths {
+			if _, ok := p.(*Path); ok {
+				owners[key] = append(owners[key], file)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	index := *s
+	index.Paths = make(map[string]interface{}, len(s.Paths))
+	index.Definitions = nil
+	for key, p := range s.Paths {
+		if files := owners[key]; len(files) == 1 {
+			u := url.URL{Path: files[0], Fragment: "/paths/" + jsonPointerEscape(key)}
+			index.Paths[key] = &Path{Ref: u.String()}
+			continue
+		}
+		index.Paths[key] = p
+	}
+	split.Index = &index
+	return split, nil
+}
+
+// ResourceFile returns the path of the file of the split document of the given resource relative
+// to the index file.
+func ResourceFile(res *design.ResourceDefinition) string {
+	return "resources/" + codegen.SnakeCase(codegen.Goify(res.Name, true)) + ".json"
+}
+
+// Files returns the JSON content of the index, resource and definitions files indexed by their
+// path relative to the index file. The index file path is "swagger.json".
+func (ss *SplitSwagger) Files() (map[string][]byte, error) {
+	files := make(map[string][]byte, len(ss.Resources)+2)
+	index, err := marshalSplit(ss.Index, DefinitionsFile)
+	if err != nil {
+		return nil, err
+	}
+	files["swagger.json"] = index
+	for file, doc := range ss.Resources {
+		defs := strings.Repeat("../", strings.Count(file, "/")) + DefinitionsFile
+		if files[file], err = marshalSplit(doc, defs); err != nil {
+			return nil, err
+		}
+	}
+	defs, err := json.Marshal(map[string]interface{}{"definitions": ss.Definitions})
+	if err != nil {
+		return nil, err
+	}
+	files[DefinitionsFile] = defs
+	return files, nil
+}
+
+// marshalSplit returns the JSON encoding of doc with the references to the definitions rewritten
+// to point to the given definitions file.
+func marshalSplit(doc *Swagger, definitionsFile string) ([]byte, error) {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.Replace(raw, []byte(`"$ref":"#/definitions/`), []byte(`"$ref":"`+definitionsFile+`#/definitions/`), -1), nil
+}
+
+// jsonPointerEscape escapes the given JSON pointer reference token, see RFC 6901.
+func jsonPointerEscape(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
@@ -0,0 +1,100 @@
+package genswagger_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genschema "github.com/kyokomi/goa-v1/goagen/gen_schema"
+	genswagger "github.com/kyokomi/goa-v1/goagen/gen_swagger"
+)
+
+var _ = Describe("SplitByResource", func() {
+	var split *genswagger.SplitSwagger
+	var files map[string][]byte
+
+	// paths returns the paths of the given JSON document as generic values.
+	paths := func(file string) map[string]map[string]interface{} {
+		var doc struct {
+			Paths map[string]map[string]interface{} `json:"paths"`
+		}
+		Ω(json.Unmarshal(files[file], &doc)).Should(Succeed())
+		return doc.Paths
+	}
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		apidsl.API("cellar", func() {})
+		bottle := apidsl.MediaType("application/vnd.bottle", func() {
+			apidsl.Attributes(func() {
+				apidsl.Attribute("id", design.Integer)
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("id")
+			})
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Response(design.OK, bottle)
+			})
+			apidsl.Action("list", func() {
+				apidsl.Routing(apidsl.GET("/items"))
+				apidsl.Response(design.OK)
+			})
+		})
+		apidsl.Resource("wine cellar", func() {
+			apidsl.Action("create", func() {
+				apidsl.Routing(apidsl.POST("/items"))
+				apidsl.Response(design.Created, bottle)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		s, err := genswagger.New(design.Design)
+		Ω(err).ShouldNot(HaveOccurred())
+		split, err = genswagger.SplitByResource(s, design.Design)
+		Ω(err).ShouldNot(HaveOccurred())
+		files, err = split.Files()
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("writes one document per resource", func() {
+		Ω(split.Resources).Should(HaveLen(2))
+		Ω(paths("resources/bottle.json")).Should(HaveKey("/bottles/{id}"))
+		Ω(paths("resources/bottle.json")["/items"]).Should(HaveKey("get"))
+		Ω(paths("resources/bottle.json")["/items"]).ShouldNot(HaveKey("post"))
+		Ω(paths("resources/wine_cellar.json")).Should(HaveLen(1))
+		Ω(paths("resources/wine_cellar.json")["/items"]).Should(HaveKey("post"))
+	})
+
+	It("moves the definitions to the shared file", func() {
+		Ω(string(files["definitions.json"])).Should(ContainSubstring(`"definitions":{"Bottle":`))
+		Ω(string(files["resources/bottle.json"])).ShouldNot(ContainSubstring(`"definitions":{`))
+		Ω(string(files["resources/bottle.json"])).Should(ContainSubstring(`"$ref":"../definitions.json#/definitions/Bottle"`))
+		Ω(string(files["resources/bottle.json"])).ShouldNot(ContainSubstring(`"$ref":"#/definitions/`))
+	})
+
+	It("references the resource paths from the index", func() {
+		index := paths("swagger.json")
+		Ω(index).Should(HaveLen(2))
+		Ω(index["/bottles/{id}"]).Should(Equal(map[string]interface{}{
+			"$ref": "resources/bottle.json#/paths/~1bottles~1%7Bid%7D",
+		}))
+	})
+
+	It("describes the paths shared by several resources in the index", func() {
+		shared := paths("swagger.json")["/items"]
+		Ω(shared).Should(HaveKey("get"))
+		Ω(shared).Should(HaveKey("post"))
+		Ω(string(files["swagger.json"])).Should(ContainSubstring(`"$ref":"definitions.json#/definitions/Bottle"`))
+	})
+})
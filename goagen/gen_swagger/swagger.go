@@ -3,6 +3,7 @@ package genswagger
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"sort"
 	"strconv"
 	"strings"
@@ -165,6 +166,8 @@ type (
 		Schema *genschema.JSONSchema `json:"schema,omitempty"`
 		// Headers is a list of headers that are sent with the response.
 		Headers map[string]*Header `json:"headers,omitempty"`
+		// Examples lists examples of the response body indexed by MIME type.
+		Examples map[string]interface{} `json:"examples,omitempty"`
 		// Ref references a global API response.
 		// This field is exclusive with the other fields of Response.
 		Ref string `json:"$ref,omitempty"`
@@ -202,6 +205,8 @@ type (
 		UniqueItems      bool          `json:"uniqueItems,omitempty"`
 		Enum             []interface{} `json:"enum,omitempty"`
 		MultipleOf       float64       `json:"multipleOf,omitempty"`
+		// Extensions defines the swagger extensions.
+		Extensions map[string]interface{} `json:"-"`
 	}
 
 	// SecurityDefinition allows the definition of a security scheme that can be used by the
@@ -296,6 +301,7 @@ type (
 	_Operation          Operation
 	_Parameter          Parameter
 	_Response           Response
+	_Header             Header
 	_SecurityDefinition SecurityDefinition
 	_Tag                Tag
 )
@@ -348,6 +354,11 @@ func (r Response) MarshalJSON() ([]byte, error) {
 	return marshalJSON(_Response(r), r.Extensions)
 }
 
+// MarshalJSON returns the JSON encoding of h.
+func (h Header) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Header(h), h.Extensions)
+}
+
 // MarshalJSON returns the JSON encoding of s.
 func (s SecurityDefinition) MarshalJSON() ([]byte, error) {
 	return marshalJSON(_SecurityDefinition(s), s.Extensions)
@@ -598,6 +609,12 @@ func tagsFromDefinition(mdata dslengine.MetadataDefinition) (tags []*Tag) {
 		}
 
 		tag.Extensions = extensionsFromDefinition(mdata)
+		for k, v := range genschema.Extensions(mdata, key+":extension:") {
+			if tag.Extensions == nil {
+				tag.Extensions = make(map[string]interface{})
+			}
+			tag.Extensions[k] = v
+		}
 
 		tags = append(tags, tag)
 	}
@@ -625,30 +642,7 @@ func summaryFromDefinition(name string, metadata dslengine.MetadataDefinition) s
 }
 
 func extensionsFromDefinition(mdata dslengine.MetadataDefinition) map[string]interface{} {
-	extensions := make(map[string]interface{})
-	for key, value := range mdata {
-		chunks := strings.Split(key, ":")
-		if len(chunks) != 3 {
-			continue
-		}
-		if chunks[0] != "swagger" || chunks[1] != "extension" {
-			continue
-		}
-		if strings.HasPrefix(chunks[2], "x-") != true {
-			continue
-		}
-		val := value[0]
-		ival := interface{}(val)
-		if err := json.Unmarshal([]byte(val), &ival); err != nil {
-			extensions[chunks[2]] = val
-			continue
-		}
-		extensions[chunks[2]] = ival
-	}
-	if len(extensions) == 0 {
-		return nil
-	}
-	return extensions
+	return genschema.Extensions(mdata, genschema.ExtensionMetadataPrefix)
 }
 
 func paramsFromDefinition(params *design.AttributeDefinition, path string) ([]*Parameter, error) {
@@ -725,6 +719,13 @@ func paramFor(at *design.AttributeDefinition, name, in string, required bool) *P
 		p.CollectionFormat = "multi"
 	}
 	p.Extensions = extensionsFromDefinition(at.Metadata)
+	if genschema.IsDeprecated(at.Metadata) {
+		// Swagger 2.0 parameters cannot be deprecated, use an extension instead.
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]interface{})
+		}
+		p.Extensions["x-deprecated"] = true
+	}
 	initValidations(at, p)
 	return p
 }
@@ -775,25 +776,57 @@ func itemsFromDefinition(at *design.AttributeDefinition) *Items {
 }
 
 func responseSpecFromDefinition(s *Swagger, api *design.APIDefinition, r *design.ResponseDefinition) (*Response, error) {
-	var schema *genschema.JSONSchema
-	if r.MediaType != "" {
-		if mt, ok := api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]; ok {
+	var (
+		schema   *genschema.JSONSchema
+		examples map[string]interface{}
+	)
+	identifier := r.MediaType
+	if identifier == "" && r.Status >= 400 {
+		// Errors returned by the controllers are rendered with the error media type.
+		identifier = design.ErrorMediaIdentifier
+	}
+	if identifier != "" {
+		mt, ok := api.MediaTypes[design.CanonicalIdentifier(identifier)]
+		if !ok && design.CanonicalIdentifier(identifier) == design.CanonicalIdentifier(design.ErrorMediaIdentifier) {
+			mt, ok = design.ErrorMedia, true
+		}
+		if ok {
 			view := r.ViewName
 			if view == "" {
 				view = design.DefaultView
 			}
 			schema = genschema.NewJSONSchema()
 			schema.Ref = genschema.MediaTypeRef(api, mt, view)
+			if p, _, err := mt.Project(view); err == nil {
+				ex := toStringMap(p.GenerateExample(api.RandomGenerator(), nil))
+				if m, ok := ex.(map[string]interface{}); ok && mt == design.ErrorMedia {
+					// The error media type example is shared, copy it before setting the status.
+					errEx := make(map[string]interface{}, len(m))
+					for k, v := range m {
+						errEx[k] = v
+					}
+					errEx["status"] = strconv.Itoa(r.Status)
+					ex = errEx
+				}
+				if ex != nil {
+					examples = map[string]interface{}{identifier: ex}
+				}
+			}
 		}
 	}
-	headers, err := headersFromDefinition(r.Headers)
+	headers, err := headersFromDefinition(api, r.Headers)
 	if err != nil {
 		return nil, err
 	}
+	description := r.Description
+	if description == "" {
+		description = http.StatusText(r.Status)
+	}
 	return &Response{
-		Description: r.Description,
+		Description: description,
 		Schema:      schema,
 		Headers:     headers,
+		Examples:    examples,
 		Extensions:  extensionsFromDefinition(r.Metadata),
 	}, nil
 }
@@ -822,7 +855,7 @@ func responseFromDefinition(s *Swagger, api *design.APIDefinition, r *design.Res
 	return response, nil
 }
 
-func headersFromDefinition(headers *design.AttributeDefinition) (map[string]*Header, error) {
+func headersFromDefinition(api *design.APIDefinition, headers *design.AttributeDefinition) (map[string]*Header, error) {
 	if headers == nil {
 		return nil, nil
 	}
@@ -833,11 +866,19 @@ func headersFromDefinition(headers *design.AttributeDefinition) (map[string]*Hea
 	res := make(map[string]*Header)
 	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
 		header := &Header{
-			Default:     at.DefaultValue,
+			Default:     toStringMap(at.DefaultValue),
 			Description: at.Description,
 			Type:        at.Type.Name(),
 		}
+		if at.Type.IsArray() {
+			header.Items = itemsFromDefinition(at.Type.ToArray().ElemType)
+			header.CollectionFormat = "csv"
+		}
 		initValidations(at, header)
+		if p, ok := at.Type.(design.Primitive); ok && header.Format == "" {
+			header.Format = genschema.TypeSchema(api, p).Format
+		}
+		header.Extensions = extensionsFromDefinition(at.Metadata)
 		res[n] = header
 		return nil
 	})
@@ -856,12 +897,28 @@ func buildPathFromFileServer(s *Swagger, api *design.APIDefinition, fs *design.F
 			Type:        "string",
 		}}
 	}
+	param = append(param, &Parameter{
+		In:          "header",
+		Name:        "Range",
+		Description: "Byte ranges of the file to download, e.g. bytes=0-1023",
+		Type:        "string",
+	}, &Parameter{
+		In:          "header",
+		Name:        "If-Range",
+		Description: "ETag or Last-Modified date of the file, the whole file is downloaded if it changed",
+		Type:        "string",
+	})
 
 	responses := map[string]*Response{
 		"200": {
 			Description: "File downloaded",
 			Schema:      &genschema.JSONSchema{Type: genschema.JSONFile},
 		},
+		"206": {
+			Description: "File ranges downloaded",
+			Schema:      &genschema.JSONSchema{Type: genschema.JSONFile},
+		},
+		"416": {Description: "Requested range not satisfiable"},
 	}
 	if len(wcs) > 0 {
 		schema := genschema.TypeSchema(api, design.ErrorMedia)
@@ -883,15 +940,7 @@ func buildPathFromFileServer(s *Swagger, api *design.APIDefinition, fs *design.F
 
 	applySecurity(operation, fs.Security)
 
-	key := design.WildcardRegex.ReplaceAllStringFunc(
-		fs.RequestPath,
-		func(w string) string {
-			return fmt.Sprintf("/{%s}", w[2:])
-		},
-	)
-	if key == "" {
-		key = "/"
-	}
+	key := fileServerKey(fs)
 	var path interface{}
 	var ok bool
 	if path, ok = s.Paths[key]; !ok {
@@ -968,16 +1017,21 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		schemes = api.Schemes
 	}
 
+	docs := docsFromDefinition(action.Docs)
+	if url, desc := genschema.ExternalDocs(action.Metadata); url != "" {
+		docs = &ExternalDocs{Description: desc, URL: url}
+	}
+
 	operation := &Operation{
 		Tags:         tagNames,
 		Description:  action.Description,
 		Summary:      summaryFromDefinition(action.Name+" "+action.Parent.Name, action.Metadata),
-		ExternalDocs: docsFromDefinition(action.Docs),
+		ExternalDocs: docs,
 		OperationID:  operationID,
 		Parameters:   params,
 		Responses:    responses,
 		Schemes:      schemes,
-		Deprecated:   false,
+		Deprecated:   genschema.IsDeprecated(action.Metadata),
 		Extensions:   extensionsFromDefinition(route.Metadata),
 	}
 
@@ -1026,24 +1080,7 @@ func computeProduces(operation *Operation, s *Swagger, action *design.ActionDefi
 }
 
 func computePaths(operation *Operation, s *Swagger, route *design.RouteDefinition, basePath string) {
-	key := design.WildcardRegex.ReplaceAllStringFunc(
-		route.FullPath(),
-		func(w string) string {
-			return fmt.Sprintf("/{%s}", w[2:])
-		},
-	)
-	bp := design.WildcardRegex.ReplaceAllStringFunc(
-		basePath,
-		func(w string) string {
-			return fmt.Sprintf("/{%s}", w[2:])
-		},
-	)
-	if bp != "/" {
-		key = strings.TrimPrefix(key, bp)
-	}
-	if key == "" {
-		key = "/"
-	}
+	key := pathKey(route, basePath)
 	var path interface{}
 	var ok bool
 	if path, ok = s.Paths[key]; !ok {
@@ -1051,7 +1088,13 @@ func computePaths(operation *Operation, s *Swagger, route *design.RouteDefinitio
 		s.Paths[key] = path
 	}
 	p := path.(*Path)
-	switch route.Verb {
+	setOperation(p, route.Verb, operation)
+	p.Extensions = extensionsFromDefinition(route.Parent.Metadata)
+}
+
+// setOperation sets the operation of the path for the given HTTP method.
+func setOperation(p *Path, verb string, operation *Operation) {
+	switch verb {
 	case "GET":
 		p.Get = operation
 	case "PUT":
@@ -1067,7 +1110,43 @@ func computePaths(operation *Operation, s *Swagger, route *design.RouteDefinitio
 	case "PATCH":
 		p.Patch = operation
 	}
-	p.Extensions = extensionsFromDefinition(route.Parent.Metadata)
+}
+
+// fileServerKey returns the key of the swagger path of the given file server.
+func fileServerKey(fs *design.FileServerDefinition) string {
+	key := design.WildcardRegex.ReplaceAllStringFunc(
+		fs.RequestPath,
+		func(w string) string {
+			return fmt.Sprintf("/{%s}", w[2:])
+		},
+	)
+	if key == "" {
+		key = "/"
+	}
+	return key
+}
+
+// pathKey returns the key of the swagger path of the given route relative to basePath.
+func pathKey(route *design.RouteDefinition, basePath string) string {
+	key := design.WildcardRegex.ReplaceAllStringFunc(
+		route.FullPath(),
+		func(w string) string {
+			return fmt.Sprintf("/{%s}", w[2:])
+		},
+	)
+	bp := design.WildcardRegex.ReplaceAllStringFunc(
+		basePath,
+		func(w string) string {
+			return fmt.Sprintf("/{%s}", w[2:])
+		},
+	)
+	if bp != "/" {
+		key = strings.TrimPrefix(key, bp)
+	}
+	if key == "" {
+		key = "/"
+	}
+	return key
 }
 
 func applySecurity(operation *Operation, security *design.SecurityDefinition) {
@@ -1177,7 +1256,11 @@ func initMinLengthValidation(def interface{}, isArray bool, min *int) {
 			actual.MinLength = min
 		}
 	case *Header:
-		actual.MinLength = min
+		if isArray {
+			actual.MinItems = min
+		} else {
+			actual.MinLength = min
+		}
 	case *Items:
 		actual.MinLength = min
 	}
@@ -1192,7 +1275,11 @@ func initMaxLengthValidation(def interface{}, isArray bool, max *int) {
 			actual.MaxLength = max
 		}
 	case *Header:
-		actual.MaxLength = max
+		if isArray {
+			actual.MaxItems = max
+		} else {
+			actual.MaxLength = max
+		}
 	case *Items:
 		actual.MaxLength = max
 	}
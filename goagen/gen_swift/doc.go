@@ -0,0 +1,15 @@
+/*
+Package genswift provides a generator for a Swift client package of the API. The generator is
+invoked with "goagen client --language=swift" and produces a Swift package that contains:
+
+  - Package.swift: the package manifest that declares a library with the same name as the
+    package, the library supports iOS 15 and macOS 12 and later,
+  - Sources/<Name>/Models.swift: a Codable struct for each user type, media type view and
+    payload and an enum for each attribute that declares an Enum validation,
+  - Sources/<Name>/Client.swift: a Client class with one async method per action route.
+
+The client sends the requests with a URLSession. The methods return the decoded body of the
+successful responses and throw an error enum that describes the declared error responses or
+APIError for the statuses that are not described in the design.
+*/
+package genswift
@@ -0,0 +1,13 @@
+package genswift_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenSwift(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenSwift Suite")
+}
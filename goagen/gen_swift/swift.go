@@ -0,0 +1,767 @@
+package genswift
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+type (
+	// Package describes the content of the generated Swift package.
+	Package struct {
+		// Name of the Swift package, library and target.
+		Name string
+		// BaseURL is the default base URL of the client.
+		BaseURL string
+		// Structs lists the Codable structs and type aliases sorted by name.
+		Structs []*Struct
+		// Enums lists the enums generated for the attributes that declare an Enum validation
+		// sorted by name.
+		Enums []*Enum
+		// Methods lists the client methods sorted by name.
+		Methods []*Method
+	}
+
+	// Struct describes a Codable struct or a type alias.
+	Struct struct {
+		// Name of the struct.
+		Name string
+		// Description of the struct.
+		Description string
+		// Fields lists the struct properties sorted by name.
+		Fields []*Field
+		// Alias is the aliased type, empty for structs.
+		Alias string
+		// DataType is the goa type described by the struct.
+		DataType design.DataType
+	}
+
+	// Field describes a struct property.
+	Field struct {
+		// Name of the property.
+		Name string
+		// AttName is the name of the attribute in the JSON representation.
+		AttName string
+		// Type of the property, optional properties use an optional type.
+		Type string
+		// Required is true if the attribute is required.
+		Required bool
+		// Description of the property.
+		Description string
+	}
+
+	// Enum describes an enum generated for an attribute that declares an Enum validation.
+	Enum struct {
+		// Name of the enum.
+		Name string
+		// Description of the enum.
+		Description string
+		// RawType is the raw value type of the enum, "String" or "Int".
+		RawType string
+		// Cases lists the enum cases in order of declaration.
+		Cases []*EnumCase
+	}
+
+	// EnumCase describes an enum case.
+	EnumCase struct {
+		// Name of the case.
+		Name string
+		// Value is the Swift literal of the raw value.
+		Value string
+	}
+
+	// Method describes a client method that sends requests to an action route.
+	Method struct {
+		// Name of the method.
+		Name string
+		// Description of the method.
+		Description string
+		// Verb is the HTTP method of the requests.
+		Verb string
+		// Path is the Swift string literal that builds the request path.
+		Path string
+		// Args lists the method arguments, required arguments first.
+		Args []*Arg
+		// Query lists the statements that append the query string parameters to _query.
+		Query []string
+		// Headers lists the statements that set the request headers in _headers.
+		Headers []string
+		// Body lists the statements that declare the _body and _contentType constants, empty if
+		// the action has no payload.
+		Body []string
+		// Responses lists the declared responses sorted by status.
+		Responses []*Response
+		// Returns is the return type of the method, empty if it returns nothing.
+		Returns string
+		// Result is the enum returned by the methods that declare successful responses with
+		// different bodies, nil otherwise.
+		Result *ResultEnum
+		// Error is the name of the error enum thrown for the declared error responses, empty if
+		// there is none.
+		Error string
+	}
+
+	// Arg describes a method argument.
+	Arg struct {
+		// Name of the argument.
+		Name string
+		// Type of the argument.
+		Type string
+		// Optional is true if the argument defaults to nil.
+		Optional bool
+	}
+
+	// Response describes a declared response.
+	Response struct {
+		// Status is the HTTP status code of the response.
+		Status int
+		// Case is the name of the case of the error or result enum that describes the response.
+		Case string
+		// Body is the type of the decoded body, empty if the response has no body.
+		Body string
+		// Text is true if the body is read as UTF-8 text instead of being decoded from JSON.
+		Text bool
+		// Error is true if the response is not a 2xx response.
+		Error bool
+	}
+
+	// ResultEnum describes the enum returned by a method with several successful responses.
+	ResultEnum struct {
+		// Name of the enum.
+		Name string
+		// Responses lists the successful responses.
+		Responses []*Response
+	}
+)
+
+// reservedWords lists the Swift keywords, identifiers that use one of these are escaped with
+// backticks.
+var reservedWords = map[string]bool{
+	"associatedtype": true, "class": true, "deinit": true, "enum": true, "extension": true,
+	"fileprivate": true, "func": true, "import": true, "init": true, "inout": true,
+	"internal": true, "let": true, "open": true, "operator": true, "private": true,
+	"protocol": true, "public": true, "rethrows": true, "static": true, "struct": true,
+	"subscript": true, "typealias": true, "var": true, "break": true, "case": true,
+	"continue": true, "default": true, "defer": true, "do": true, "else": true,
+	"fallthrough": true, "for": true, "guard": true, "if": true, "in": true, "repeat": true,
+	"return": true, "switch": true, "where": true, "while": true, "as": true, "Any": true,
+	"catch": true, "false": true, "is": true, "nil": true, "super": true, "self": true,
+	"Self": true, "throw": true, "throws": true, "true": true, "try": true,
+}
+
+// reservedTypeNames lists the names of the Swift standard library and generated types that
+// cannot be used by the generated structs and enums, types that use one of these names get the
+// "Type" suffix.
+var reservedTypeNames = map[string]bool{
+	"Any": true, "APIError": true, "Array": true, "Bool": true, "Client": true, "Codable": true,
+	"Data": true, "Date": true, "Dictionary": true, "Double": true, "Error": true, "Int": true,
+	"JSONValue": true, "MultipartForm": true, "Optional": true, "Result": true, "Set": true,
+	"String": true, "Type": true, "URL": true,
+}
+
+// invalidNameChars matches the characters that cannot be used in Swift identifiers.
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// New builds the description of the Swift client package of the API. The package contains a
+// Codable struct for each user type, media type view and payload, an enum for each attribute
+// that declares an Enum validation and an async client method for each action route.
+func New(api *design.APIDefinition, name, baseURL string) (*Package, error) {
+	b := &builder{api: api, structs: make(map[string]*Struct), enums: make(map[string]*Enum)}
+	err := api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		_, err := b.structName(ut)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() {
+			return nil
+		}
+		return mt.IterateViews(func(v *design.ViewDefinition) error {
+			p, _, err := mt.Project(v.Name)
+			if err != nil {
+				return err
+			}
+			_, err = b.structName(p)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	p := &Package{Name: name, BaseURL: baseURL}
+	err = api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() {
+				return nil
+			}
+			for i, r := range a.Routes {
+				m, err := b.method(a, r, i)
+				if err != nil {
+					return fmt.Errorf("%s: %s", a.Context(), err)
+				}
+				p.Methods = append(p.Methods, m)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(p.Methods, func(i, j int) bool { return p.Methods[i].Name < p.Methods[j].Name })
+	for _, s := range b.structs {
+		p.Structs = append(p.Structs, s)
+	}
+	sort.Slice(p.Structs, func(i, j int) bool { return p.Structs[i].Name < p.Structs[j].Name })
+	for _, e := range b.enums {
+		p.Enums = append(p.Enums, e)
+	}
+	sort.Slice(p.Enums, func(i, j int) bool { return p.Enums[i].Name < p.Enums[j].Name })
+	return p, nil
+}
+
+// builder computes the structs, enums and methods.
+type builder struct {
+	api     *design.APIDefinition
+	structs map[string]*Struct
+	enums   map[string]*Enum
+}
+
+// method builds the client method that sends requests to the i-th route of the action.
+func (b *builder) method(a *design.ActionDefinition, r *design.RouteDefinition, i int) (*Method, error) {
+	name := codegen.Goify(a.Name, false) + codegen.Goify(a.Parent.Name, true)
+	if i > 0 {
+		name += strconv.Itoa(i + 1)
+	}
+	typeName := codegen.Goify(name, true)
+	m := &Method{
+		Name:        name,
+		Description: a.Description,
+		Verb:        r.Verb,
+	}
+	if m.Description == "" {
+		m.Description = fmt.Sprintf("%s calls the %s action of the %s resource.", name, a.Name, a.Parent.Name)
+	}
+
+	var optional []*Arg
+	all := a.AllParams()
+	pathParams := r.Params()
+	interpolations := make(map[string]string)
+	for _, p := range pathParams {
+		var at *design.AttributeDefinition
+		if all != nil {
+			at = all.Type.ToObject()[p]
+		}
+		if at == nil {
+			at = &design.AttributeDefinition{Type: design.String}
+		}
+		typ, err := b.swiftType(at, typeName+codegen.Goify(p, true))
+		if err != nil {
+			return nil, fmt.Errorf("parameter %#v: %s", p, err)
+		}
+		arg := &Arg{Name: identifier(p), Type: typ}
+		m.Args = append(m.Args, arg)
+		interpolations[p] = fmt.Sprintf(`\(pathEscape(%s, keepSlashes: %t))`,
+			paramString(at, arg.Name), strings.Contains(r.FullPath(), "*"+p))
+	}
+	m.Path = pathLiteral(r.FullPath(), interpolations)
+
+	addParams := func(params *design.AttributeDefinition, header bool) error {
+		if params == nil {
+			return nil
+		}
+		for _, n := range codegen.SortedNames(params.Type.ToObject()) {
+			if !header && codegen.ContainsString(pathParams, n) {
+				continue
+			}
+			at := params.Type.ToObject()[n]
+			typ, err := b.swiftType(at, typeName+codegen.Goify(n, true))
+			if err != nil {
+				return fmt.Errorf("parameter %#v: %s", n, err)
+			}
+			arg := &Arg{Name: identifier(n), Type: typ}
+			if params.IsRequired(n) {
+				m.Args = append(m.Args, arg)
+			} else {
+				arg.Type += "?"
+				arg.Optional = true
+				optional = append(optional, arg)
+			}
+			elem, v := at, arg.Name
+			if at.Type.IsArray() {
+				elem, v = at.Type.ToArray().ElemType, "_v"
+			} else if arg.Optional {
+				v = "_v"
+			}
+			var stmt string
+			if header {
+				stmt = fmt.Sprintf("_headers[%s] = %s", quote(n), paramString(elem, v))
+			} else {
+				stmt = fmt.Sprintf("_query.append(URLQueryItem(name: %s, value: %s))", quote(n), paramString(elem, v))
+			}
+			switch {
+			case at.Type.IsArray() && arg.Optional:
+				stmt = fmt.Sprintf("for _v in %s ?? [] { %s }", arg.Name, stmt)
+			case at.Type.IsArray():
+				stmt = fmt.Sprintf("for _v in %s { %s }", arg.Name, stmt)
+			case arg.Optional:
+				stmt = fmt.Sprintf("if let _v = %s { %s }", arg.Name, stmt)
+			}
+			if header {
+				m.Headers = append(m.Headers, stmt)
+			} else {
+				m.Query = append(m.Query, stmt)
+			}
+		}
+		return nil
+	}
+	if err := addParams(a.QueryParams, false); err != nil {
+		return nil, err
+	}
+	if err := addParams(a.Headers, true); err != nil {
+		return nil, err
+	}
+
+	if a.Payload != nil {
+		typ, err := b.structName(a.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("payload: %s", err)
+		}
+		arg := &Arg{Name: "payload", Type: typ}
+		if a.PayloadOptional {
+			arg.Type += "?"
+			arg.Optional = true
+			optional = append([]*Arg{arg}, optional...)
+		} else {
+			m.Args = append(m.Args, arg)
+		}
+		switch {
+		case a.PayloadMultipart:
+			stmts, err := multipart(a.Payload, arg.Optional)
+			if err != nil {
+				return nil, fmt.Errorf("payload: %s", err)
+			}
+			m.Body = stmts
+		case arg.Optional:
+			m.Body = []string{
+				"let _body: Data? = try payload.map { try self.encoder.encode($0) }",
+				`let _contentType: String? = payload == nil ? nil : "application/json"`,
+			}
+		default:
+			m.Body = []string{
+				"let _body: Data? = try self.encoder.encode(payload)",
+				`let _contentType: String? = "application/json"`,
+			}
+		}
+	}
+	m.Args = append(m.Args, optional...)
+
+	err := a.IterateResponses(func(resp *design.ResponseDefinition) error {
+		res, err := b.response(resp)
+		if err != nil {
+			return fmt.Errorf("response %#v: %s", resp.Name, err)
+		}
+		m.Responses = append(m.Responses, res)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(m.Responses, func(i, j int) bool { return m.Responses[i].Status < m.Responses[j].Status })
+	var successes []*Response
+	bodies := make(map[string]bool)
+	for _, res := range m.Responses {
+		if res.Error {
+			m.Error = typeName + "Error"
+			continue
+		}
+		successes = append(successes, res)
+		bodies[res.Body] = true
+	}
+	switch {
+	case len(bodies) > 1:
+		m.Result = &ResultEnum{Name: typeName + "Result", Responses: successes}
+		m.Returns = m.Result.Name
+	case len(successes) > 0:
+		m.Returns = successes[0].Body
+	}
+	return m, nil
+}
+
+// response builds the description of the given action response.
+func (b *builder) response(r *design.ResponseDefinition) (*Response, error) {
+	res := &Response{
+		Status: r.Status,
+		Case:   identifier(r.Name),
+		Error:  r.Status < http.StatusOK || r.Status >= http.StatusMultipleChoices,
+	}
+	if r.Status == http.StatusNoContent {
+		return res, nil
+	}
+	if r.MediaType != "" {
+		mt, ok := b.api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]
+		if !ok {
+			res.Body, res.Text = "String", true
+			return res, nil
+		}
+		view := r.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return nil, err
+		}
+		name, err := b.structName(p)
+		if err != nil {
+			return nil, err
+		}
+		res.Body = name
+		return res, nil
+	}
+	if r.Type != nil {
+		name, err := b.structName(r.Type)
+		if err != nil {
+			return nil, err
+		}
+		res.Body = name
+	}
+	return res, nil
+}
+
+// structName registers the struct that corresponds to the given user type or media type and
+// returns its name.
+func (b *builder) structName(t design.DataType) (string, error) {
+	t, err := projected(&design.AttributeDefinition{Type: t})
+	if err != nil {
+		return "", err
+	}
+	ut, ok := userType(t)
+	if !ok {
+		return "", fmt.Errorf("%s is not a user type", t.Name())
+	}
+	name := typeName(codegen.GoTypeName(t, nil, 0, false))
+	if s, ok := b.structs[name]; ok {
+		if s.DataType.Name() != t.Name() {
+			return "", fmt.Errorf("struct %s is already defined", name)
+		}
+		return name, nil
+	}
+	s := &Struct{Name: name, Description: ut.Description, DataType: t}
+	b.structs[name] = s
+	if !ut.Type.IsObject() {
+		alias, err := b.swiftType(ut.AttributeDefinition, name+"Item")
+		if err != nil {
+			delete(b.structs, name)
+			return "", fmt.Errorf("type %s: %s", name, err)
+		}
+		s.Alias = alias
+		return name, nil
+	}
+	obj := ut.Type.ToObject()
+	for _, n := range codegen.SortedNames(obj) {
+		at := obj[n]
+		typ, err := b.swiftType(at, name+codegen.Goify(n, true))
+		if err != nil {
+			delete(b.structs, name)
+			return "", fmt.Errorf("type %s: attribute %#v: %s", name, n, err)
+		}
+		f := &Field{
+			Name:        identifier(n),
+			AttName:     n,
+			Type:        typ,
+			Required:    ut.IsRequired(n),
+			Description: at.Description,
+		}
+		if !f.Required {
+			f.Type += "?"
+		}
+		s.Fields = append(s.Fields, f)
+	}
+	return name, nil
+}
+
+// swiftType returns the Swift type of the values of the given attribute. enumName is the name
+// of the enum generated if the attribute declares an Enum validation.
+func (b *builder) swiftType(at *design.AttributeDefinition, enumName string) (string, error) {
+	switch actual := at.Type.(type) {
+	case design.Primitive:
+		typ := primitiveType(actual)
+		if at.Validation == nil || len(at.Validation.Values) == 0 || (typ != "String" && typ != "Int") {
+			return typ, nil
+		}
+		return b.enum(typeName(enumName), typ, at)
+	case *design.Array:
+		elem, err := b.swiftType(actual.ElemType, enumName)
+		if err != nil {
+			return "", err
+		}
+		return "[" + elem + "]", nil
+	case *design.Hash:
+		elem, err := b.swiftType(actual.ElemType, enumName)
+		if err != nil {
+			return "", err
+		}
+		return "[String: " + elem + "]", nil
+	case design.Object:
+		return "[String: JSONValue]", nil
+	case *design.MediaTypeDefinition, *design.UserTypeDefinition:
+		t, err := projected(at)
+		if err != nil {
+			return "", err
+		}
+		return b.structName(t)
+	}
+	return "", fmt.Errorf("unsupported type %s", at.Type.Name())
+}
+
+// enum registers the enum that lists the values of the given attribute and returns its name.
+func (b *builder) enum(name, rawType string, at *design.AttributeDefinition) (string, error) {
+	e := &Enum{Name: name, Description: at.Description, RawType: rawType}
+	used := make(map[string]bool)
+	for _, v := range at.Validation.Values {
+		c := &EnumCase{}
+		switch val := v.(type) {
+		case string:
+			c.Name = caseName(val)
+			c.Value = quote(val)
+		case int:
+			c.Name = caseName("value_" + strings.Replace(strconv.Itoa(val), "-", "minus_", 1))
+			c.Value = strconv.Itoa(val)
+		default:
+			return "", fmt.Errorf("unsupported enum value %#v", v)
+		}
+		base := c.Name
+		for i := 2; used[c.Name]; i++ {
+			c.Name = fmt.Sprintf("%s%d", strings.Trim(base, "`"), i)
+		}
+		used[c.Name] = true
+		e.Cases = append(e.Cases, c)
+	}
+	if existing, ok := b.enums[name]; ok {
+		if !sameCases(existing, e) {
+			return "", fmt.Errorf("enum %s is already defined", name)
+		}
+		return name, nil
+	}
+	b.enums[name] = e
+	return name, nil
+}
+
+// sameCases returns true if the two enums define the same cases.
+func sameCases(e1, e2 *Enum) bool {
+	if len(e1.Cases) != len(e2.Cases) || e1.RawType != e2.RawType {
+		return false
+	}
+	for i, c := range e1.Cases {
+		if *c != *e2.Cases[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// multipart returns the statements that encode the given payload as multipart form data.
+func multipart(payload *design.UserTypeDefinition, optional bool) ([]string, error) {
+	if !payload.Type.IsObject() {
+		return nil, fmt.Errorf("multipart payloads must be objects")
+	}
+	var stmts []string
+	obj := payload.Type.ToObject()
+	for _, n := range codegen.SortedNames(obj) {
+		at := obj[n]
+		v := "payload." + identifier(n)
+		if !payload.IsRequired(n) {
+			v = "_f"
+		}
+		var stmt string
+		switch {
+		case at.Type.Kind() == design.FileKind:
+			stmt = fmt.Sprintf("_form.append(name: %s, file: %s)", quote(n), v)
+		case at.Type.IsPrimitive():
+			stmt = fmt.Sprintf("_form.append(name: %s, value: %s)", quote(n), paramString(at, v))
+		default:
+			stmt = fmt.Sprintf("_form.append(name: %s, json: try self.encoder.encode(%s))", quote(n), v)
+		}
+		if !payload.IsRequired(n) {
+			stmt = fmt.Sprintf("if let _f = payload.%s { %s }", identifier(n), stmt)
+		}
+		stmts = append(stmts, stmt)
+	}
+	if optional {
+		for i, stmt := range stmts {
+			stmts[i] = "    " + stmt
+		}
+		stmts = append([]string{"if let payload = payload {"}, stmts...)
+		stmts = append(stmts, "}")
+	}
+	stmts = append([]string{"var _form = MultipartForm()"}, stmts...)
+	return append(stmts, "let _body: Data? = _form.encoded()", "let _contentType: String? = _form.contentType"), nil
+}
+
+// primitiveType returns the Swift type used to represent values of the given primitive.
+// Date times, UUIDs and decimals are represented with their string representation.
+func primitiveType(p design.Primitive) string {
+	switch p.Kind() {
+	case design.BooleanKind:
+		return "Bool"
+	case design.IntegerKind:
+		return "Int"
+	case design.NumberKind:
+		return "Double"
+	case design.StringKind, design.DateTimeKind, design.UUIDKind, design.DecimalKind:
+		return "String"
+	case design.FileKind:
+		return "Data"
+	case design.AnyKind:
+		return "JSONValue"
+	}
+	if c := design.CustomPrimitive(p); c != nil {
+		switch c.JSONType {
+		case "integer":
+			return "Int"
+		case "number":
+			return "Double"
+		case "boolean":
+			return "Bool"
+		}
+		return "String"
+	}
+	return "JSONValue"
+}
+
+// format returns the expression that converts the value v of the given attribute into a value
+// that can be described as a string.
+func format(at *design.AttributeDefinition, v string) string {
+	if at.Validation != nil && len(at.Validation.Values) > 0 {
+		if k := at.Type.Kind(); k == design.StringKind || k == design.IntegerKind {
+			return v + ".rawValue"
+		}
+	}
+	return v
+}
+
+// paramString returns the expression that converts the value v of the given attribute into
+// its string representation.
+func paramString(at *design.AttributeDefinition, v string) string {
+	return "String(describing: " + format(at, v) + ")"
+}
+
+// projected returns the type of the attribute, media types are projected using the view of the
+// attribute or the default view.
+func projected(at *design.AttributeDefinition) (design.DataType, error) {
+	mt, ok := at.Type.(*design.MediaTypeDefinition)
+	if !ok || strings.Contains(mt.Identifier, "view=") {
+		return at.Type, nil
+	}
+	view := at.View
+	if view == "" {
+		view = design.DefaultView
+	}
+	p, _, err := mt.Project(view)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// userType returns the user type definition of the given user type or media type.
+func userType(t design.DataType) (*design.UserTypeDefinition, bool) {
+	switch actual := t.(type) {
+	case *design.MediaTypeDefinition:
+		return actual.UserTypeDefinition, true
+	case *design.UserTypeDefinition:
+		return actual, true
+	}
+	return nil, false
+}
+
+// identifier returns the lower camel case Swift identifier that corresponds to the given name.
+func identifier(n string) string {
+	name := codegen.Goify(invalidNameChars.ReplaceAllString(n, "_"), false)
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	if reservedWords[name] {
+		return "`" + name + "`"
+	}
+	return name
+}
+
+// caseName returns the name of the enum case that corresponds to the given value.
+func caseName(v string) string {
+	v = strings.Trim(invalidNameChars.ReplaceAllString(v, "_"), "_")
+	if v == "" {
+		return "empty"
+	}
+	if v[0] >= '0' && v[0] <= '9' {
+		v = "value_" + v
+	}
+	return identifier(v)
+}
+
+// typeName returns the Swift type name that corresponds to the given name.
+func typeName(n string) string {
+	name := invalidNameChars.ReplaceAllString(codegen.Goify(n, true), "")
+	if reservedTypeNames[name] {
+		name += "Type"
+	}
+	return name
+}
+
+// pathLiteral returns the Swift string literal that builds the given route path, interpolations
+// maps the names of the path parameters to the interpolation that replaces them.
+func pathLiteral(path string, interpolations map[string]string) string {
+	var b strings.Builder
+	b.WriteString(`"`)
+	last := 0
+	for _, loc := range codegen.PathParamRegex.FindAllStringSubmatchIndex(path, -1) {
+		b.WriteString(escape(path[last:loc[0]]))
+		if interpolation, ok := interpolations[path[loc[2]:loc[3]]]; ok {
+			b.WriteString(interpolation)
+		} else {
+			b.WriteString(escape(path[loc[0]:loc[1]]))
+		}
+		last = loc[1]
+	}
+	b.WriteString(escape(path[last:]))
+	b.WriteString(`"`)
+	return b.String()
+}
+
+// quote returns the Swift string literal of s.
+func quote(s string) string {
+	return `"` + escape(s) + `"`
+}
+
+// escape escapes the characters of s that cannot appear as is in a Swift string literal.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u{%x}`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,167 @@
+package genswift_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genswift "github.com/kyokomi/goa-v1/goagen/gen_swift"
+)
+
+var _ = Describe("New", func() {
+	var pkg *genswift.Package
+	var newErr error
+
+	BeforeEach(func() {
+		pkg = nil
+		newErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		pkg, newErr = genswift.New(Design, "CellarClient", "https://api.example.com")
+	})
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			winery := apidsl.Type("Winery", func() {
+				apidsl.Attribute("name", String)
+				apidsl.Required("name")
+			})
+			bottle := apidsl.MediaType("application/vnd.bottle", func() {
+				apidsl.Description("A bottle of wine")
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer, "ID of bottle")
+					apidsl.Attribute("color", String, func() {
+						apidsl.Enum("red", "white", "rosé", "2nd")
+					})
+					apidsl.Attribute("winery", winery)
+					apidsl.Attribute("created_at", DateTime)
+					apidsl.Required("id", "color")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("color")
+					apidsl.Attribute("winery")
+					apidsl.Attribute("created_at")
+				})
+			})
+			apidsl.API("cellar", func() {
+				apidsl.BasePath("/api")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Description("Get bottle by id")
+					apidsl.Routing(apidsl.GET("/:bottleID"))
+					apidsl.Params(func() {
+						apidsl.Param("bottleID", Integer)
+						apidsl.Param("fields", apidsl.ArrayOf(String))
+					})
+					apidsl.Headers(func() {
+						apidsl.Header("X-Request-Id")
+					})
+					apidsl.Response(OK, bottle)
+					apidsl.Response(NotFound)
+					apidsl.Response(BadRequest, ErrorMedia)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(func() {
+						apidsl.Member("name", String)
+						apidsl.Member("winery", winery)
+						apidsl.Required("name")
+					})
+					apidsl.Response(Created, bottle)
+					apidsl.Response(NoContent)
+				})
+			})
+		})
+
+		It("generates the structs", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			var names []string
+			for _, s := range pkg.Structs {
+				names = append(names, s.Name)
+			}
+			Ω(names).Should(Equal([]string{"Bottle", "CreateBottlePayload", "ErrorType", "Winery"}))
+			bottle := pkg.Structs[0]
+			Ω(bottle.Fields).Should(HaveLen(4))
+			Ω(bottle.Fields[0].Name).Should(Equal("color"))
+			Ω(bottle.Fields[0].Type).Should(Equal("BottleColor"))
+			Ω(bottle.Fields[1].Name).Should(Equal("createdAt"))
+			Ω(bottle.Fields[1].AttName).Should(Equal("created_at"))
+			Ω(bottle.Fields[1].Type).Should(Equal("String?"))
+			Ω(bottle.Fields[2].Type).Should(Equal("Int"))
+			Ω(bottle.Fields[3].Type).Should(Equal("Winery?"))
+			Ω(bottle.NeedsCodingKeys()).Should(BeTrue())
+			Ω(bottle.InitSignature()).Should(Equal("color: BottleColor, createdAt: String? = nil, id: Int, winery: Winery? = nil"))
+		})
+
+		It("generates the enums", func() {
+			Ω(pkg.Enums).Should(HaveLen(1))
+			color := pkg.Enums[0]
+			Ω(color.Name).Should(Equal("BottleColor"))
+			Ω(color.RawType).Should(Equal("String"))
+			var cases []string
+			for _, c := range color.Cases {
+				cases = append(cases, c.Name+" = "+c.Value)
+			}
+			Ω(cases).Should(Equal([]string{`red = "red"`, `white = "white"`, `ros = "rosé"`, `value2nd = "2nd"`}))
+		})
+
+		It("generates the methods", func() {
+			Ω(pkg.Methods).Should(HaveLen(2))
+			create := pkg.Methods[0]
+			Ω(create.Name).Should(Equal("createBottle"))
+			Ω(create.Signature()).Should(Equal("payload: CreateBottlePayload"))
+			Ω(create.Returns).Should(Equal("CreateBottleResult"))
+			Ω(create.Error).Should(BeEmpty())
+			Ω(create.Handle(create.Responses[0])).Should(Equal("return .created(try self.decoder.decode(Bottle.self, from: _data))"))
+			Ω(create.Handle(create.Responses[1])).Should(Equal("return .noContent"))
+			show := pkg.Methods[1]
+			Ω(show.Name).Should(Equal("showBottle"))
+			Ω(show.Path).Should(Equal(`"/api/bottles/\(pathEscape(String(describing: bottleID), keepSlashes: false))"`))
+			Ω(show.Signature()).Should(Equal("bottleID: Int, fields: [String]? = nil, xRequestID: String? = nil"))
+			Ω(show.Query).Should(Equal([]string{`for _v in fields ?? [] { _query.append(URLQueryItem(name: "fields", value: String(describing: _v))) }`}))
+			Ω(show.Headers).Should(Equal([]string{`if let _v = xRequestID { _headers["X-Request-Id"] = String(describing: _v) }`}))
+			Ω(show.Returns).Should(Equal("Bottle"))
+			Ω(show.Error).Should(Equal("ShowBottleError"))
+			Ω(show.Responses).Should(HaveLen(3))
+			Ω(show.Handle(show.Responses[0])).Should(Equal("return try self.decoder.decode(Bottle.self, from: _data)"))
+			Ω(show.Handle(show.Responses[1])).Should(Equal("throw ShowBottleError.badRequest(try self.decoder.decode(ErrorType.self, from: _data))"))
+			Ω(show.Handle(show.Responses[2])).Should(Equal("throw ShowBottleError.notFound"))
+		})
+
+		It("renders the files", func() {
+			Ω(pkg.Files()).Should(Equal([]string{"Package.swift", "Sources/CellarClient/Models.swift", "Sources/CellarClient/Client.swift"}))
+			manifest, err := pkg.Write("Package.swift", "cellar: Swift Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(manifest)).Should(HavePrefix("// swift-tools-version:5.5\n"))
+			Ω(string(manifest)).Should(ContainSubstring(`.library(name: "CellarClient", targets: ["CellarClient"]),`))
+			models, err := pkg.Write("Sources/CellarClient/Models.swift", "cellar: Swift Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(models)).Should(ContainSubstring("public enum BottleColor: String, Codable, CaseIterable {\n    case red = \"red\"\n"))
+			Ω(string(models)).Should(ContainSubstring("/// A bottle of wine (default view)\npublic struct Bottle: Codable, Equatable {\n"))
+			Ω(string(models)).Should(ContainSubstring("    /// ID of bottle\n    public var id: Int\n"))
+			Ω(string(models)).Should(ContainSubstring("        case createdAt = \"created_at\"\n"))
+			Ω(string(models)).Should(ContainSubstring("        self.winery = winery\n"))
+			client, err := pkg.Write("Sources/CellarClient/Client.swift", "cellar: Swift Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(client)).Should(ContainSubstring(`public static let defaultBaseURL = URL(string: "https://api.example.com")!`))
+			Ω(string(client)).Should(ContainSubstring("    public func showBottle(bottleID: Int, fields: [String]? = nil, xRequestID: String? = nil) async throws -> Bottle {\n"))
+			Ω(string(client)).Should(ContainSubstring("        let _body: Data? = try self.encoder.encode(payload)\n"))
+			Ω(string(client)).Should(ContainSubstring("        case 404:\n            throw ShowBottleError.notFound\n"))
+			Ω(string(client)).Should(ContainSubstring("public enum ShowBottleError: Error {\n"))
+			Ω(string(client)).Should(ContainSubstring("    case badRequest(ErrorType)\n"))
+			Ω(string(client)).Should(ContainSubstring("public enum CreateBottleResult {\n"))
+			_, err = pkg.Write("Sources/CellarClient/Other.swift", "cellar: Swift Client")
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,420 @@
+package genswift
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1/version"
+)
+
+// Files returns the paths of the generated files relative to the package directory in order of
+// generation.
+func (p *Package) Files() []string {
+	return []string{
+		"Package.swift",
+		filepath.Join("Sources", p.Name, "Models.swift"),
+		filepath.Join("Sources", p.Name, "Client.swift"),
+	}
+}
+
+// templates maps the base names of the generated files to their template.
+var templates = map[string]*template.Template{
+	"Package.swift": newTemplate("package", packageT),
+	"Models.swift":  newTemplate("models", modelsT),
+	"Client.swift":  newTemplate("client", clientT),
+}
+
+// newTemplate parses the given template text.
+func newTemplate(name, text string) *template.Template {
+	return template.Must(template.New(name).Funcs(template.FuncMap{
+		"swiftdoc": swiftdoc,
+		"quote":    quote,
+	}).Parse(text))
+}
+
+// Write renders the file with the given path, title is written in the header comment.
+func (p *Package) Write(file, title string) ([]byte, error) {
+	tmpl, ok := templates[filepath.Base(file)]
+	if !ok {
+		return nil, fmt.Errorf("unknown file %s", file)
+	}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"Title":       title,
+		"ToolVersion": version.String(),
+		"Package":     p,
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// NeedsCodingKeys returns true if the name of a property differs from the name of the attribute
+// in the JSON representation.
+func (s *Struct) NeedsCodingKeys() bool {
+	for _, f := range s.Fields {
+		if f.Name != f.AttName {
+			return true
+		}
+	}
+	return false
+}
+
+// InitSignature returns the list of arguments of the memberwise initializer, optional properties
+// default to nil.
+func (s *Struct) InitSignature() string {
+	args := make([]string, len(s.Fields))
+	for i, f := range s.Fields {
+		args[i] = fmt.Sprintf("%s: %s", f.Name, f.Type)
+		if !f.Required {
+			args[i] += " = nil"
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
+// CodingKey returns the case of the CodingKeys enum that corresponds to the property.
+func (f *Field) CodingKey() string {
+	if f.Name == f.AttName {
+		return f.Name
+	}
+	return fmt.Sprintf("%s = %s", f.Name, quote(f.AttName))
+}
+
+// Signature returns the list of arguments of the method, optional arguments default to nil.
+func (m *Method) Signature() string {
+	args := make([]string, len(m.Args))
+	for i, a := range m.Args {
+		args[i] = fmt.Sprintf("%s: %s", a.Name, a.Type)
+		if a.Optional {
+			args[i] += " = nil"
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
+// Doc returns the documentation comment of the method, it lists the errors thrown by the method.
+func (m *Method) Doc() string {
+	text := m.Description + "\n\n- Throws: "
+	if m.Error != "" {
+		text += m.Error + " for the declared error responses, "
+	}
+	text += "APIError for the statuses that are not described in the design."
+	return swiftdoc("    ", text)
+}
+
+// Handle returns the statement that handles the given response.
+func (m *Method) Handle(r *Response) string {
+	var value string
+	switch {
+	case r.Text:
+		value = "String(decoding: _data, as: UTF8.self)"
+	case r.Body != "":
+		value = fmt.Sprintf("try self.decoder.decode(%s.self, from: _data)", r.Body)
+	}
+	switch {
+	case r.Error && value == "":
+		return fmt.Sprintf("throw %s.%s", m.Error, r.Case)
+	case r.Error:
+		return fmt.Sprintf("throw %s.%s(%s)", m.Error, r.Case, value)
+	case m.Result != nil && value == "":
+		return "return ." + r.Case
+	case m.Result != nil:
+		return fmt.Sprintf("return .%s(%s)", r.Case, value)
+	case value == "":
+		return "return"
+	}
+	return "return " + value
+}
+
+// Errors returns the error responses of the method.
+func (m *Method) Errors() []*Response {
+	var res []*Response
+	for _, r := range m.Responses {
+		if r.Error {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// Declaration returns the declaration of the enum case that describes the response.
+func (r *Response) Declaration() string {
+	if r.Body == "" {
+		return "case " + r.Case
+	}
+	return fmt.Sprintf("case %s(%s)", r.Case, r.Body)
+}
+
+// StatusText returns the text of the response status.
+func (r *Response) StatusText() string {
+	return fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status))
+}
+
+// swiftdoc renders the given text as a documentation comment followed by a new line, indent is
+// prepended to each line.
+func swiftdoc(indent, text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(indent+"/// "+l, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+const headerT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .Title }}
+`
+
+const packageT = `// swift-tools-version:5.5
+` + headerT + `
+import PackageDescription
+
+let package = Package(
+    name: {{ quote .Package.Name }},
+    platforms: [.iOS(.v15), .macOS(.v12)],
+    products: [
+        .library(name: {{ quote .Package.Name }}, targets: [{{ quote .Package.Name }}]),
+    ],
+    targets: [
+        .target(name: {{ quote .Package.Name }}),
+    ]
+)
+`
+
+const modelsT = headerT + `
+import Foundation
+{{ range .Package.Enums }}
+{{ swiftdoc "" .Description }}public enum {{ .Name }}: {{ .RawType }}, Codable, CaseIterable {
+{{ range .Cases }}    case {{ .Name }} = {{ .Value }}
+{{ end }}}
+{{ end }}{{ range .Package.Structs }}{{ if .Alias }}
+{{ swiftdoc "" .Description }}public typealias {{ .Name }} = {{ .Alias }}
+{{ else }}
+{{ swiftdoc "" .Description }}public struct {{ .Name }}: Codable, Equatable {
+{{ range .Fields }}{{ swiftdoc "    " .Description }}    public var {{ .Name }}: {{ .Type }}
+{{ end }}{{ if .NeedsCodingKeys }}
+    enum CodingKeys: String, CodingKey {
+{{ range .Fields }}        case {{ .CodingKey }}
+{{ end }}    }
+{{ end }}
+    public init({{ .InitSignature }}) {
+{{ range .Fields }}        self.{{ .Name }} = {{ .Name }}
+{{ end }}    }
+}
+{{ end }}{{ end }}
+/// JSONValue holds a value of any JSON type.
+public enum JSONValue: Codable, Equatable {
+    case null
+    case bool(Bool)
+    case number(Double)
+    case string(String)
+    case array([JSONValue])
+    case object([String: JSONValue])
+
+    public init(from decoder: Decoder) throws {
+        let container = try decoder.singleValueContainer()
+        if container.decodeNil() {
+            self = .null
+        } else if let v = try? container.decode(Bool.self) {
+            self = .bool(v)
+        } else if let v = try? container.decode(Double.self) {
+            self = .number(v)
+        } else if let v = try? container.decode(String.self) {
+            self = .string(v)
+        } else if let v = try? container.decode([JSONValue].self) {
+            self = .array(v)
+        } else {
+            self = .object(try container.decode([String: JSONValue].self))
+        }
+    }
+
+    public func encode(to encoder: Encoder) throws {
+        var container = encoder.singleValueContainer()
+        switch self {
+        case .null:
+            try container.encodeNil()
+        case .bool(let v):
+            try container.encode(v)
+        case .number(let v):
+            try container.encode(v)
+        case .string(let v):
+            try container.encode(v)
+        case .array(let v):
+            try container.encode(v)
+        case .object(let v):
+            try container.encode(v)
+        }
+    }
+}
+`
+
+const clientT = headerT + `
+import Foundation
+#if canImport(FoundationNetworking)
+import FoundationNetworking
+#endif
+
+/// APIError is thrown when the API returns a response that is not described in the design.
+public enum APIError: Error {
+    /// unexpectedStatus holds the status code and the body of a response whose status is not
+    /// described in the design.
+    case unexpectedStatus(Int, Data)
+    /// invalidResponse is thrown when the session does not return an HTTP response.
+    case invalidResponse
+}
+
+/// Client gives access to the API.
+public final class Client {
+    /// defaultBaseURL is the base URL used when none is given.
+    public static let defaultBaseURL = URL(string: {{ quote .Package.BaseURL }})!
+
+    /// baseURL is prepended to the request paths.
+    public let baseURL: URL
+    /// session sends the requests.
+    public let session: URLSession
+    /// headers are sent with every request.
+    public var headers: [String: String]
+    /// encoder encodes the request bodies.
+    public let encoder: JSONEncoder
+    /// decoder decodes the response bodies.
+    public let decoder: JSONDecoder
+
+    public init(baseURL: URL = Client.defaultBaseURL, session: URLSession = .shared, headers: [String: String] = [:]) {
+        self.baseURL = baseURL
+        self.session = session
+        self.headers = headers
+        self.encoder = JSONEncoder()
+        self.decoder = JSONDecoder()
+    }
+{{ range $m := .Package.Methods }}
+{{ .Doc }}    public func {{ .Name }}({{ .Signature }}) async throws{{ if .Returns }} -> {{ .Returns }}{{ end }} {
+{{ if .Query }}        var _query: [URLQueryItem] = []
+{{ range .Query }}        {{ . }}
+{{ end }}{{ else }}        let _query: [URLQueryItem] = []
+{{ end }}{{ if .Headers }}        var _headers: [String: String] = [:]
+{{ range .Headers }}        {{ . }}
+{{ end }}{{ else }}        let _headers: [String: String] = [:]
+{{ end }}{{ if .Body }}{{ range .Body }}        {{ . }}
+{{ end }}{{ else }}        let _body: Data? = nil
+        let _contentType: String? = nil
+{{ end }}        let (_data, _response) = try await send({{ quote .Verb }}, path: {{ .Path }}, query: _query, headers: _headers, body: _body, contentType: _contentType)
+        switch _response.statusCode {
+{{ range .Responses }}        case {{ .Status }}:
+            {{ $m.Handle . }}
+{{ end }}        default:
+            throw APIError.unexpectedStatus(_response.statusCode, _data)
+        }
+    }
+{{ end }}
+    private func send(_ method: String, path: String, query: [URLQueryItem], headers: [String: String], body: Data?, contentType: String?) async throws -> (Data, HTTPURLResponse) {
+        guard var components = URLComponents(url: baseURL, resolvingAgainstBaseURL: false) else {
+            throw URLError(.badURL)
+        }
+        var prefix = components.percentEncodedPath
+        if prefix.hasSuffix("/") {
+            prefix.removeLast()
+        }
+        components.percentEncodedPath = prefix + path
+        if !query.isEmpty {
+            components.queryItems = query
+        }
+        guard let url = components.url else {
+            throw URLError(.badURL)
+        }
+        var request = URLRequest(url: url)
+        request.httpMethod = method
+        for (key, value) in self.headers {
+            request.setValue(value, forHTTPHeaderField: key)
+        }
+        for (key, value) in headers {
+            request.setValue(value, forHTTPHeaderField: key)
+        }
+        if let body = body {
+            request.httpBody = body
+            if let contentType = contentType {
+                request.setValue(contentType, forHTTPHeaderField: "Content-Type")
+            }
+        }
+        let (data, response) = try await session.data(for: request)
+        guard let http = response as? HTTPURLResponse else {
+            throw APIError.invalidResponse
+        }
+        return (data, http)
+    }
+}
+{{ range .Package.Methods }}{{ $m := . }}{{ with .Result }}
+/// {{ .Name }} lists the successful responses of {{ $m.Name }}.
+public enum {{ .Name }} {
+{{ range .Responses }}    /// {{ .Case }} describes the {{ .StatusText }} response.
+    {{ .Declaration }}
+{{ end }}}
+{{ end }}{{ if .Error }}
+/// {{ .Error }} lists the error responses of {{ .Name }}.
+public enum {{ .Error }}: Error {
+{{ range .Errors }}    /// {{ .Case }} is thrown when the API returns the {{ .StatusText }} response.
+    {{ .Declaration }}
+{{ end }}}
+{{ end }}{{ end }}
+/// pathEscape percent-encodes a path parameter value, slashes are kept for wildcards.
+func pathEscape(_ value: String, keepSlashes: Bool) -> String {
+    var allowed = CharacterSet.urlPathAllowed
+    allowed.remove(charactersIn: "?#;")
+    if !keepSlashes {
+        allowed.remove(charactersIn: "/")
+    }
+    return value.addingPercentEncoding(withAllowedCharacters: allowed) ?? value
+}
+
+/// MultipartForm builds multipart/form-data request bodies.
+struct MultipartForm {
+    let boundary = "goa-" + UUID().uuidString
+    var parts = Data()
+
+    var contentType: String {
+        return "multipart/form-data; boundary=\(boundary)"
+    }
+
+    mutating func append(name: String, value: String) {
+        appendPart(name: name, filename: nil, contentType: nil, data: Data(value.utf8))
+    }
+
+    mutating func append(name: String, file: Data) {
+        appendPart(name: name, filename: name, contentType: "application/octet-stream", data: file)
+    }
+
+    mutating func append(name: String, json: Data) {
+        appendPart(name: name, filename: nil, contentType: "application/json", data: json)
+    }
+
+    func encoded() -> Data {
+        var data = parts
+        data.append(Data("--\(boundary)--\r\n".utf8))
+        return data
+    }
+
+    private mutating func appendPart(name: String, filename: String?, contentType: String?, data: Data) {
+        var head = "--\(boundary)\r\nContent-Disposition: form-data; name=\"\(name)\""
+        if let filename = filename {
+            head += "; filename=\"\(filename)\""
+        }
+        head += "\r\n"
+        if let contentType = contentType {
+            head += "Content-Type: \(contentType)\r\n"
+        }
+        head += "\r\n"
+        parts.append(Data(head.utf8))
+        parts.append(data)
+        parts.append(Data("\r\n".utf8))
+    }
+}
+`
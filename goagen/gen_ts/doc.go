@@ -0,0 +1,13 @@
+/*
+Package gents provides a generator for a typed TypeScript client of the API. The generator
+produces a single module "ts/client.ts" that relies on the standard fetch API and contains:
+
+  - an interface for each user type, media type view and payload,
+  - a Client class with one method per action route,
+  - for each method a discriminated union of the responses described in the design, split into
+    the successful (2xx) and error responses and discriminated by the ok and status properties.
+
+The methods throw an UnexpectedResponseError when the API returns a status that is not described
+in the design. WebSocket actions are not exposed by the client.
+*/
+package gents
@@ -0,0 +1,125 @@
+package gents
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of a TypeScript Client Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the TypeScript client generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Destination directory
+	Scheme   string                // Scheme used by TypeScript client
+	Host     string                // Host addressed by TypeScript client
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, scheme, host, ver string
+
+	set := flag.NewFlagSet("ts", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.String("design", "", "")
+	set.StringVar(&scheme, "scheme", "", "")
+	set.StringVar(&host, "host", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.Parse(os.Args[1:])
+
+	// First check compatibility
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	// Now proceed
+	g := &Generator{OutDir: outDir, Scheme: scheme, Host: host, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the TypeScript client module.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	m, err := New(g.API, g.baseURL())
+	if err != nil {
+		return nil, err
+	}
+
+	outDir := filepath.Join(g.OutDir, "ts")
+	if err = codegen.RemoveAll(outDir); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(outDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, outDir)
+
+	title := fmt.Sprintf("%s: TypeScript Client", g.API.Context())
+	content, err := m.Write(title)
+	if err != nil {
+		return nil, err
+	}
+	clientFile := filepath.Join(outDir, "client.ts")
+	if err = codegen.WriteFile(clientFile, content, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, clientFile)
+
+	return g.genfiles, nil
+}
+
+// baseURL returns the default base URL of the client. The URL is empty if the host is not set in
+// the design or with --host so that the requests target the origin of the page by default.
+func (g *Generator) baseURL() string {
+	host := g.Host
+	if host == "" {
+		host = g.API.Host
+	}
+	if host == "" {
+		return ""
+	}
+	scheme := g.Scheme
+	if scheme == "" && len(g.API.Schemes) > 0 {
+		scheme = g.API.Schemes[0]
+	}
+	if scheme == "" {
+		scheme = "http"
+	}
+	return scheme + "://" + host
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
@@ -0,0 +1,46 @@
+package gents_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	gents "github.com/kyokomi/goa-v1/goagen/gen_ts"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *gents.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+		scheme string
+		host   string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+		scheme: "https",
+		host:   "api.example.com",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = gents.NewGenerator(
+				gents.API(args.api),
+				gents.OutDir(args.outDir),
+				gents.Scheme(args.scheme),
+				gents.Host(args.host),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Scheme).Should(Equal(args.scheme))
+			Ω(generator.Host).Should(Equal(args.host))
+		})
+	})
+})
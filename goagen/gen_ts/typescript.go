@@ -0,0 +1,567 @@
+package gents
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+type (
+	// Module describes the content of the generated TypeScript client module.
+	Module struct {
+		// BaseURL is the default base URL of the client.
+		BaseURL string
+		// Types lists the interfaces and type aliases sorted by name.
+		Types []*Type
+		// Functions lists the client methods sorted by name.
+		Functions []*Function
+	}
+
+	// Type describes a TypeScript interface or type alias.
+	Type struct {
+		// Name of the type.
+		Name string
+		// Description of the type.
+		Description string
+		// Fields lists the interface properties sorted by name, nil for type aliases.
+		Fields []*Field
+		// Alias is the aliased type expression, empty for interfaces.
+		Alias string
+		// DataType is the goa type described by the TypeScript type, nil for the types that
+		// describe action parameters.
+		DataType design.DataType
+	}
+
+	// Field describes a property of a TypeScript interface.
+	Field struct {
+		// Name of the property as it appears in the JSON representation.
+		Name string
+		// Type is the TypeScript type expression of the property.
+		Type string
+		// Optional is true if the property may be omitted.
+		Optional bool
+		// Description of the property.
+		Description string
+		// Header is true if the property describes a request header.
+		Header bool
+	}
+
+	// Function describes a client method that sends requests to an action route.
+	Function struct {
+		// Name of the method.
+		Name string
+		// Description of the method.
+		Description string
+		// Method is the HTTP method of the requests.
+		Method string
+		// Path is the TypeScript template literal that builds the request path.
+		Path string
+		// Args lists the path parameters, payload and parameters arguments in order.
+		Args []*Arg
+		// Payload is the name of the payload argument, empty if the action has no payload.
+		Payload string
+		// Multipart is true if the payload is sent as multipart form data.
+		Multipart bool
+		// Params is the type of the "params" argument that holds the query string and header
+		// parameters, nil if the action has none.
+		Params *Type
+		// Responses lists the responses described in the design sorted by status.
+		Responses []*Response
+		// Action is the goa action called by the method.
+		Action *design.ActionDefinition
+	}
+
+	// Arg describes an argument of a client method.
+	Arg struct {
+		// Name of the argument.
+		Name string
+		// Type is the TypeScript type expression of the argument.
+		Type string
+		// Optional is true if the argument may be omitted.
+		Optional bool
+	}
+
+	// Response describes a response of an action.
+	Response struct {
+		// Status is the HTTP status code of the response.
+		Status int
+		// Body is the TypeScript type of the response body, "undefined" if there is none.
+		Body string
+		// JSON is true if the body is decoded from JSON, false if it is read as text.
+		JSON bool
+	}
+)
+
+// reservedTypeNames lists the type names that cannot be used by the generated types because
+// they are defined by the TypeScript standard library or by the generated module itself. Types
+// that use one of these names get the "Type" suffix.
+var reservedTypeNames = map[string]bool{
+	"Array": true, "Blob": true, "Boolean": true, "Client": true, "ClientOptions": true,
+	"Date": true, "Error": true, "File": true, "FormData": true, "Function": true,
+	"Headers": true, "Map": true, "Number": true, "Object": true, "Promise": true,
+	"Record": true, "Request": true, "RequestOptions": true, "Response": true, "Set": true,
+	"String": true, "Symbol": true, "URL": true, "UnexpectedResponseError": true,
+}
+
+// reservedWords lists the TypeScript reserved words, arguments that use one of these names get
+// a "_" suffix.
+var reservedWords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+	"debugger": true, "default": true, "delete": true, "do": true, "else": true, "enum": true,
+	"export": true, "extends": true, "false": true, "finally": true, "for": true,
+	"function": true, "if": true, "import": true, "in": true, "instanceof": true, "new": true,
+	"null": true, "return": true, "super": true, "switch": true, "this": true, "throw": true,
+	"true": true, "try": true, "typeof": true, "var": true, "void": true, "while": true,
+	"with": true, "options": true, "params": true, "payload": true, "resp": true,
+}
+
+// identifier matches the property names that do not need to be quoted.
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// New builds the description of the TypeScript client module of the API. The module contains
+// an interface for each user type, media type view and action parameters and a client method
+// for each action route. baseURL is the default base URL used by the client.
+func New(api *design.APIDefinition, baseURL string) (*Module, error) {
+	b := &builder{api: api, types: make(map[string]*Type)}
+	err := api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		_, err := b.typeName(ut)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() {
+			return nil
+		}
+		return mt.IterateViews(func(v *design.ViewDefinition) error {
+			p, _, err := mt.Project(v.Name)
+			if err != nil {
+				return err
+			}
+			_, err = b.typeName(p)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	m := &Module{BaseURL: baseURL}
+	err = api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() {
+				return nil
+			}
+			for i, r := range a.Routes {
+				f, err := b.function(a, r, i)
+				if err != nil {
+					return fmt.Errorf("%s: %s", a.Context(), err)
+				}
+				m.Functions = append(m.Functions, f)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(m.Functions, func(i, j int) bool { return m.Functions[i].Name < m.Functions[j].Name })
+	for _, t := range b.types {
+		m.Types = append(m.Types, t)
+	}
+	sort.Slice(m.Types, func(i, j int) bool { return m.Types[i].Name < m.Types[j].Name })
+	return m, nil
+}
+
+// builder computes the types and functions.
+type builder struct {
+	api   *design.APIDefinition
+	types map[string]*Type
+}
+
+// function builds the client method that sends requests to the i-th route of the action.
+func (b *builder) function(a *design.ActionDefinition, r *design.RouteDefinition, i int) (*Function, error) {
+	name := codegen.Goify(a.Name, false) + codegen.Goify(a.Parent.Name, true)
+	if i > 0 {
+		name += strconv.Itoa(i + 1)
+	}
+	f := &Function{
+		Name:        name,
+		Description: a.Description,
+		Method:      r.Verb,
+		Multipart:   a.PayloadMultipart,
+		Action:      a,
+	}
+	if f.Description == "" {
+		f.Description = fmt.Sprintf("%s calls the %s action of the %s resource.", name, a.Name, a.Parent.Name)
+	}
+
+	all := a.AllParams()
+	pathParams := r.Params()
+	path := r.FullPath()
+	for _, p := range pathParams {
+		at := all.Type.ToObject()[p]
+		if at == nil {
+			at = &design.AttributeDefinition{Type: design.String}
+		}
+		typ, err := b.tsType(at)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %#v: %s", p, err)
+		}
+		arg := &Arg{Name: argName(p), Type: typ}
+		f.Args = append(f.Args, arg)
+		path = replaceWildcard(path, p, arg.Name)
+	}
+	f.Path = "`" + path + "`"
+
+	if a.Payload != nil {
+		typ, err := b.typeName(a.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("payload: %s", err)
+		}
+		f.Payload = "payload"
+		f.Args = append(f.Args, &Arg{Name: f.Payload, Type: typ, Optional: a.PayloadOptional})
+	}
+
+	base := codegen.Goify(a.Name, false) + codegen.Goify(a.Parent.Name, true)
+	paramsName := codegen.Goify(base, true) + "Params"
+	if i > 0 {
+		if params, ok := b.types[paramsName]; ok {
+			f.Params = params
+			f.Args = append(f.Args, &Arg{Name: "params", Type: params.Name, Optional: f.paramsOptional()})
+		}
+		return f, b.responses(f, a)
+	}
+	params := &Type{
+		Name:        paramsName,
+		Description: fmt.Sprintf("%s lists the query string and header parameters of %s.", paramsName, base),
+	}
+	if a.QueryParams != nil {
+		for _, n := range codegen.SortedNames(a.QueryParams.Type.ToObject()) {
+			if codegen.ContainsString(pathParams, n) {
+				continue
+			}
+			field, err := b.field(a.QueryParams, n)
+			if err != nil {
+				return nil, fmt.Errorf("parameter %#v: %s", n, err)
+			}
+			params.Fields = append(params.Fields, field)
+		}
+	}
+	if headers := a.Headers; headers != nil {
+		for _, n := range codegen.SortedNames(headers.Type.ToObject()) {
+			field, err := b.field(headers, n)
+			if err != nil {
+				return nil, fmt.Errorf("header %#v: %s", n, err)
+			}
+			field.Header = true
+			params.Fields = append(params.Fields, field)
+		}
+	}
+	if len(params.Fields) > 0 {
+		if err := b.register(params); err != nil {
+			return nil, err
+		}
+		f.Params = params
+		f.Args = append(f.Args, &Arg{Name: "params", Type: params.Name, Optional: f.paramsOptional()})
+	}
+
+	return f, b.responses(f, a)
+}
+
+// responses computes the responses of the client method.
+func (b *builder) responses(f *Function, a *design.ActionDefinition) error {
+	err := a.IterateResponses(func(resp *design.ResponseDefinition) error {
+		res, err := b.response(resp)
+		if err != nil {
+			return fmt.Errorf("response %#v: %s", resp.Name, err)
+		}
+		f.Responses = append(f.Responses, res)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(f.Responses, func(i, j int) bool { return f.Responses[i].Status < f.Responses[j].Status })
+	return nil
+}
+
+// response builds the description of the given action response.
+func (b *builder) response(r *design.ResponseDefinition) (*Response, error) {
+	res := &Response{Status: r.Status, Body: "undefined"}
+	if r.Status == http.StatusNoContent {
+		return res, nil
+	}
+	if r.MediaType != "" {
+		mt, ok := b.api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]
+		if !ok {
+			res.Body = "string"
+			return res, nil
+		}
+		view := r.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return nil, err
+		}
+		name, err := b.typeName(p)
+		if err != nil {
+			return nil, err
+		}
+		res.Body, res.JSON = name, true
+		return res, nil
+	}
+	if r.Type != nil {
+		name, err := b.typeName(r.Type)
+		if err != nil {
+			return nil, err
+		}
+		res.Body, res.JSON = name, true
+	}
+	return res, nil
+}
+
+// typeName registers the type that corresponds to the given user type or media type and returns
+// its name.
+func (b *builder) typeName(t design.DataType) (string, error) {
+	var ut *design.UserTypeDefinition
+	switch actual := t.(type) {
+	case *design.MediaTypeDefinition:
+		if !isProjected(actual) {
+			p, _, err := actual.Project(design.DefaultView)
+			if err != nil {
+				return "", err
+			}
+			actual = p
+			t = p
+		}
+		ut = actual.UserTypeDefinition
+	case *design.UserTypeDefinition:
+		ut = actual
+	default:
+		return "", fmt.Errorf("%s is not a user type", t.Name())
+	}
+	name := typeName(codegen.Goify(codegen.GoTypeName(t, nil, 0, false), true))
+	if typ, ok := b.types[name]; ok {
+		if typ.DataType == nil || typ.DataType.Name() != t.Name() {
+			return "", fmt.Errorf("type %s is already defined", name)
+		}
+		return name, nil
+	}
+	typ := &Type{Name: name, Description: ut.Description, DataType: t}
+	b.types[name] = typ
+	if ut.Type.IsObject() {
+		for _, n := range codegen.SortedNames(ut.Type.ToObject()) {
+			field, err := b.field(ut.AttributeDefinition, n)
+			if err != nil {
+				delete(b.types, name)
+				return "", fmt.Errorf("type %s: attribute %#v: %s", name, n, err)
+			}
+			typ.Fields = append(typ.Fields, field)
+		}
+		return name, nil
+	}
+	alias, err := b.tsType(ut.AttributeDefinition)
+	if err != nil {
+		delete(b.types, name)
+		return "", fmt.Errorf("type %s: %s", name, err)
+	}
+	typ.Alias = alias
+	return name, nil
+}
+
+// register adds a type that describes action parameters.
+func (b *builder) register(typ *Type) error {
+	if _, ok := b.types[typ.Name]; ok {
+		return fmt.Errorf("type %s is already defined", typ.Name)
+	}
+	b.types[typ.Name] = typ
+	return nil
+}
+
+// field builds the interface property that describes the attribute n of the given object.
+func (b *builder) field(parent *design.AttributeDefinition, n string) (*Field, error) {
+	at := parent.Type.ToObject()[n]
+	typ, err := b.tsType(at)
+	if err != nil {
+		return nil, err
+	}
+	return &Field{
+		Name:        n,
+		Type:        typ,
+		Optional:    !parent.IsRequired(n),
+		Description: at.Description,
+	}, nil
+}
+
+// tsType returns the TypeScript type expression that describes values of the given attribute.
+func (b *builder) tsType(at *design.AttributeDefinition) (string, error) {
+	switch actual := at.Type.(type) {
+	case design.Primitive:
+		if at.Validation != nil && len(at.Validation.Values) > 0 && actual.Kind() != design.AnyKind {
+			vals := make([]string, len(at.Validation.Values))
+			for i, v := range at.Validation.Values {
+				js, err := json.Marshal(v)
+				if err != nil {
+					return "", err
+				}
+				vals[i] = string(js)
+			}
+			return strings.Join(vals, " | "), nil
+		}
+		return primitiveType(actual), nil
+	case *design.Array:
+		elem, err := b.tsType(actual.ElemType)
+		if err != nil {
+			return "", err
+		}
+		if identifier.MatchString(elem) || strings.HasSuffix(elem, "[]") {
+			return elem + "[]", nil
+		}
+		return "Array<" + elem + ">", nil
+	case *design.Hash:
+		elem, err := b.tsType(actual.ElemType)
+		if err != nil {
+			return "", err
+		}
+		return "Record<string, " + elem + ">", nil
+	case design.Object:
+		fields := make([]string, 0, len(actual))
+		for _, n := range codegen.SortedNames(actual) {
+			f, err := b.field(at, n)
+			if err != nil {
+				return "", fmt.Errorf("attribute %#v: %s", n, err)
+			}
+			fields = append(fields, f.Declaration())
+		}
+		if len(fields) == 0 {
+			return "Record<string, unknown>", nil
+		}
+		return "{ " + strings.Join(fields, " ") + " }", nil
+	case *design.MediaTypeDefinition:
+		if isProjected(actual) {
+			return b.typeName(actual)
+		}
+		view := at.View
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := actual.Project(view)
+		if err != nil {
+			return "", err
+		}
+		return b.typeName(p)
+	case *design.UserTypeDefinition:
+		return b.typeName(actual)
+	}
+	return "", fmt.Errorf("unsupported type %s", at.Type.Name())
+}
+
+// paramsOptional returns true if none of the query string and header parameters is required.
+func (f *Function) paramsOptional() bool {
+	for _, field := range f.Params.Fields {
+		if !field.Optional {
+			return false
+		}
+	}
+	return true
+}
+
+// Declaration returns the TypeScript declaration of the property.
+func (f *Field) Declaration() string {
+	opt := ""
+	if f.Optional {
+		opt = "?"
+	}
+	return fmt.Sprintf("%s%s: %s;", propertyName(f.Name), opt, f.Type)
+}
+
+// Accessor returns the expression that reads the property of the given object, optional is
+// true if the object may be undefined.
+func (f *Field) Accessor(obj string, optional bool) string {
+	if optional {
+		obj += "?."
+	}
+	if identifier.MatchString(f.Name) {
+		if !optional {
+			obj += "."
+		}
+		return obj + f.Name
+	}
+	return fmt.Sprintf("%s[%q]", obj, f.Name)
+}
+
+// primitiveType returns the TypeScript type used to represent values of the given primitive.
+func primitiveType(p design.Primitive) string {
+	switch p.Kind() {
+	case design.BooleanKind:
+		return "boolean"
+	case design.IntegerKind, design.NumberKind:
+		return "number"
+	case design.StringKind, design.DateTimeKind, design.UUIDKind, design.DecimalKind:
+		return "string"
+	case design.FileKind:
+		return "Blob"
+	case design.AnyKind:
+		return "unknown"
+	}
+	if c := design.CustomPrimitive(p); c != nil {
+		switch c.JSONType {
+		case "integer", "number":
+			return "number"
+		case "boolean":
+			return "boolean"
+		}
+		return "string"
+	}
+	return "unknown"
+}
+
+// isProjected returns true if the media type is the result of a projection.
+func isProjected(mt *design.MediaTypeDefinition) bool {
+	return strings.Contains(mt.Identifier, "view=")
+}
+
+// typeName returns the TypeScript name of the type with the given Go name.
+func typeName(name string) string {
+	if reservedTypeNames[name] {
+		return name + "Type"
+	}
+	return name
+}
+
+// argName returns the name of the argument that holds the value of the given parameter.
+func argName(n string) string {
+	name := codegen.Goify(n, false)
+	if reservedWords[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// propertyName returns the property name quoted if it is not a valid identifier.
+func propertyName(n string) string {
+	if identifier.MatchString(n) {
+		return n
+	}
+	return strconv.Quote(n)
+}
+
+// replaceWildcard replaces the wildcard of the path parameter p with the expression that encodes
+// the value of the argument arg.
+func replaceWildcard(path, p, arg string) string {
+	if strings.Contains(path, "*"+p) {
+		return strings.Replace(path, "*"+p, "${encodeURI(String("+arg+"))}", 1)
+	}
+	return regexp.MustCompile(`:`+regexp.QuoteMeta(p)+`\b`).
+		ReplaceAllLiteralString(path, "${encodeURIComponent(String("+arg+"))}")
+}
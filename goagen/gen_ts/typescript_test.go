@@ -0,0 +1,167 @@
+package gents_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	gents "github.com/kyokomi/goa-v1/goagen/gen_ts"
+)
+
+var _ = Describe("New", func() {
+	var module *gents.Module
+	var newErr error
+
+	BeforeEach(func() {
+		module = nil
+		newErr = nil
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		module, newErr = gents.New(Design, "https://api.example.com")
+	})
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			bottle := apidsl.MediaType("application/vnd.bottle", func() {
+				apidsl.Description("A bottle of wine")
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", Integer, "ID of bottle")
+					apidsl.Attribute("name", String)
+					apidsl.Attribute("color", String, func() {
+						apidsl.Enum("red", "white")
+					})
+					apidsl.Attribute("tags", apidsl.ArrayOf(String))
+					apidsl.Attribute("ratings", apidsl.HashOf(String, Integer))
+					apidsl.Required("id", "name")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+					apidsl.Attribute("color")
+					apidsl.Attribute("tags")
+					apidsl.Attribute("ratings")
+				})
+				apidsl.View("tiny", func() {
+					apidsl.Attribute("id")
+				})
+			})
+			apidsl.API("cellar", func() {
+				apidsl.BasePath("/api")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Description("Get bottle by id")
+					apidsl.Routing(apidsl.GET("/:bottleID"))
+					apidsl.Params(func() {
+						apidsl.Param("bottleID", Integer)
+						apidsl.Param("fields", apidsl.ArrayOf(String))
+					})
+					apidsl.Headers(func() {
+						apidsl.Header("X-Request-Id")
+					})
+					apidsl.Response(OK, bottle)
+					apidsl.Response(NotFound)
+					apidsl.Response(BadRequest, ErrorMedia)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""), apidsl.POST("/new"))
+					apidsl.Payload(func() {
+						apidsl.Member("name", String)
+						apidsl.Required("name")
+					})
+					apidsl.Params(func() {
+						apidsl.Param("dry_run", Boolean)
+						apidsl.Required("dry_run")
+					})
+					apidsl.Response(Created, func() {
+						apidsl.Media(bottle, "tiny")
+					})
+				})
+			})
+		})
+
+		It("generates the types", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			var names []string
+			for _, t := range module.Types {
+				names = append(names, t.Name)
+			}
+			Ω(names).Should(Equal([]string{"Bottle", "BottleTiny", "CreateBottleParams", "CreateBottlePayload", "ErrorType", "ShowBottleParams"}))
+			bottle := module.Types[0]
+			Ω(bottle.Description).Should(Equal("A bottle of wine (default view)"))
+			Ω(bottle.Fields).Should(HaveLen(5))
+			Ω(bottle.Fields[0].Declaration()).Should(Equal(`color?: "red" | "white";`))
+			Ω(bottle.Fields[1].Declaration()).Should(Equal("id: number;"))
+			Ω(bottle.Fields[3].Declaration()).Should(Equal("ratings?: Record<string, number>;"))
+			Ω(bottle.Fields[4].Declaration()).Should(Equal("tags?: string[];"))
+			params := module.Types[5]
+			Ω(params.Fields).Should(HaveLen(2))
+			Ω(params.Fields[0].Declaration()).Should(Equal("fields?: string[];"))
+			Ω(params.Fields[1].Declaration()).Should(Equal(`"X-Request-Id"?: string;`))
+			Ω(params.Fields[1].Header).Should(BeTrue())
+		})
+
+		It("generates the functions", func() {
+			Ω(module.Functions).Should(HaveLen(3))
+			Ω(module.Functions[0].Name).Should(Equal("createBottle"))
+			Ω(module.Functions[1].Name).Should(Equal("createBottle2"))
+			Ω(module.Functions[1].Path).Should(Equal("`/api/bottles/new`"))
+			Ω(module.Functions[0].Signature()).Should(Equal("payload: CreateBottlePayload, params: CreateBottleParams, options: RequestOptions = {}"))
+			Ω(module.Functions[1].Signature()).Should(Equal(module.Functions[0].Signature()))
+			show := module.Functions[2]
+			Ω(show.Name).Should(Equal("showBottle"))
+			Ω(show.Method).Should(Equal("GET"))
+			Ω(show.Path).Should(Equal("`/api/bottles/${encodeURIComponent(String(bottleID))}`"))
+			Ω(show.Signature()).Should(Equal("bottleID: number, params?: ShowBottleParams, options: RequestOptions = {}"))
+			Ω(show.Responses).Should(HaveLen(3))
+			Ω(show.Successes()[0].Member()).Should(Equal("{ ok: true; status: 200; headers: Headers; body: Bottle }"))
+			Ω(show.Errors()).Should(HaveLen(2))
+			Ω(show.Errors()[0].Member()).Should(Equal("{ ok: false; status: 400; headers: Headers; body: ErrorType }"))
+			Ω(show.Errors()[1].Member()).Should(Equal("{ ok: false; status: 404; headers: Headers; body: undefined }"))
+		})
+
+		It("renders the module", func() {
+			content, err := module.Write("cellar: TypeScript Client")
+			Ω(err).ShouldNot(HaveOccurred())
+			ts := string(content)
+			Ω(ts).Should(ContainSubstring("/** A bottle of wine (default view) */\nexport interface Bottle {\n  color?: \"red\" | \"white\";\n  /** ID of bottle */\n  id: number;\n"))
+			Ω(ts).Should(ContainSubstring("export type ShowBottleResponse = ShowBottleSuccess | ShowBottleError;"))
+			Ω(ts).Should(ContainSubstring("export type ShowBottleError =\n  | { ok: false; status: 400; headers: Headers; body: ErrorType }\n  | { ok: false; status: 404; headers: Headers; body: undefined };"))
+			Ω(ts).Should(ContainSubstring("export type CreateBottleError = never;"))
+			Ω(ts).Should(ContainSubstring(`this.baseURL = options.baseURL ?? "https://api.example.com";`))
+			Ω(ts).Should(ContainSubstring("  /** Get bottle by id */\n  async showBottle(bottleID: number, params?: ShowBottleParams, options: RequestOptions = {}): Promise<ShowBottleResponse> {"))
+			Ω(ts).Should(ContainSubstring(`      query: { "fields": params?.fields, },`))
+			Ω(ts).Should(ContainSubstring(`      headers: { "X-Request-Id": params?.["X-Request-Id"], },`))
+			Ω(ts).Should(ContainSubstring("        return { ok: true, status: 200, headers: resp.headers, body: (await resp.json()) as Bottle };"))
+			Ω(ts).Should(ContainSubstring(`      query: { "dry_run": params.dry_run, },`))
+			Ω(ts).Should(ContainSubstring("      body: payload,\n"))
+			Ω(ts).ShouldNot(ContainSubstring("headers: { },"))
+		})
+	})
+
+	Context("with a WebSocket action", func() {
+		BeforeEach(func() {
+			apidsl.API("cellar", nil)
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("watch", func() {
+					apidsl.Routing(apidsl.GET("/watch"))
+					apidsl.Scheme("ws")
+					apidsl.Response(SwitchingProtocols)
+				})
+			})
+		})
+
+		It("does not generate a function", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(module.Functions).Should(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,297 @@
+package gents
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/version"
+)
+
+// moduleTmpl is the template used to render the TypeScript module.
+var moduleTmpl = template.Must(template.New("module").Funcs(template.FuncMap{
+	"jsdoc": jsdoc,
+}).Parse(moduleT))
+
+// Write renders the TypeScript module, title is written in the header comment.
+func (m *Module) Write(title string) ([]byte, error) {
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"Title":       title,
+		"ToolVersion": version.String(),
+		"Module":      m,
+	}
+	if err := moduleTmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// TypeName returns the name of the union of the responses of the method.
+func (f *Function) TypeName() string {
+	return codegen.Goify(f.Name, true) + "Response"
+}
+
+// SuccessTypeName returns the name of the union of the 2xx responses of the method.
+func (f *Function) SuccessTypeName() string {
+	return codegen.Goify(f.Name, true) + "Success"
+}
+
+// ErrorTypeName returns the name of the union of the non 2xx responses of the method.
+func (f *Function) ErrorTypeName() string {
+	return codegen.Goify(f.Name, true) + "Error"
+}
+
+// Successes returns the 2xx responses of the method.
+func (f *Function) Successes() []*Response {
+	var res []*Response
+	for _, r := range f.Responses {
+		if r.OK() {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// Errors returns the non 2xx responses of the method.
+func (f *Function) Errors() []*Response {
+	var res []*Response
+	for _, r := range f.Responses {
+		if !r.OK() {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// Signature returns the list of arguments of the method. Optional arguments that precede a
+// required argument accept undefined instead.
+func (f *Function) Signature() string {
+	args := make([]string, len(f.Args)+1)
+	required := false
+	for i := len(f.Args) - 1; i >= 0; i-- {
+		a := f.Args[i]
+		switch {
+		case !a.Optional:
+			required = true
+			args[i] = fmt.Sprintf("%s: %s", a.Name, a.Type)
+		case required:
+			args[i] = fmt.Sprintf("%s: %s | undefined", a.Name, a.Type)
+		default:
+			args[i] = fmt.Sprintf("%s?: %s", a.Name, a.Type)
+		}
+	}
+	args[len(f.Args)] = "options: RequestOptions = {}"
+	return strings.Join(args, ", ")
+}
+
+// ParamsOptional returns true if the params argument may be undefined.
+func (f *Function) ParamsOptional() bool {
+	for _, a := range f.Args {
+		if a.Name == "params" {
+			return a.Optional
+		}
+	}
+	return false
+}
+
+// QueryFields returns the properties of the params argument that describe query string
+// parameters.
+func (f *Function) QueryFields() []*Field {
+	return f.paramsFields(false)
+}
+
+// HeaderFields returns the properties of the params argument that describe headers.
+func (f *Function) HeaderFields() []*Field {
+	return f.paramsFields(true)
+}
+
+// paramsFields returns the properties of the params argument that describe headers if header
+// is true, query string parameters otherwise.
+func (f *Function) paramsFields(header bool) []*Field {
+	if f.Params == nil {
+		return nil
+	}
+	var res []*Field
+	for _, field := range f.Params.Fields {
+		if field.Header == header {
+			res = append(res, field)
+		}
+	}
+	return res
+}
+
+// OK returns true if the response status is 2xx.
+func (r *Response) OK() bool {
+	return r.Status >= http.StatusOK && r.Status < http.StatusMultipleChoices
+}
+
+// Member returns the member of the response union type that describes the response.
+func (r *Response) Member() string {
+	return fmt.Sprintf("{ ok: %t; status: %d; headers: Headers; body: %s }", r.OK(), r.Status, r.Body)
+}
+
+// Decode returns the expression that reads the response body.
+func (r *Response) Decode() string {
+	switch {
+	case r.JSON:
+		return fmt.Sprintf("(await resp.json()) as %s", r.Body)
+	case r.Body == "string":
+		return "await resp.text()"
+	}
+	return "undefined"
+}
+
+// jsdoc renders the given text as a JSDoc comment followed by a new line, indent is prepended
+// to each line.
+func jsdoc(indent, text string) string {
+	text = strings.TrimSpace(strings.Replace(text, "*/", "*\\/", -1))
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		return indent + "/** " + lines[0] + " */\n"
+	}
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(indent+" * "+l, " ")
+	}
+	return indent + "/**\n" + strings.Join(lines, "\n") + "\n" + indent + " */\n"
+}
+
+const moduleT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .Title }}
+{{ range .Module.Types }}
+{{ jsdoc "" .Description }}{{ if .Fields }}export interface {{ .Name }} {
+{{ range .Fields }}{{ jsdoc "  " .Description }}  {{ .Declaration }}
+{{ end }}}
+{{ else if .Alias }}export type {{ .Name }} = {{ .Alias }};
+{{ else }}export type {{ .Name }} = Record<string, unknown>;
+{{ end }}{{ end }}{{ range .Module.Functions }}
+/** {{ .TypeName }} lists the responses of {{ .Name }}, use the ok or status property to narrow it. */
+export type {{ .TypeName }} = {{ .SuccessTypeName }} | {{ .ErrorTypeName }};
+
+/** {{ .SuccessTypeName }} lists the successful responses of {{ .Name }}. */
+export type {{ .SuccessTypeName }} ={{ range .Successes }}
+  | {{ .Member }}{{ else }} never{{ end }};
+
+/** {{ .ErrorTypeName }} lists the error responses of {{ .Name }}. */
+export type {{ .ErrorTypeName }} ={{ range .Errors }}
+  | {{ .Member }}{{ else }} never{{ end }};
+{{ end }}
+/** ClientOptions configures a Client. */
+export interface ClientOptions {
+  /** baseURL is prepended to the request paths, defaults to {{ printf "%q" .Module.BaseURL }}. */
+  baseURL?: string;
+  /** fetch is the function used to send the requests, defaults to the global fetch. */
+  fetch?: typeof fetch;
+  /** headers are sent with every request. */
+  headers?: Record<string, string>;
+}
+
+/** RequestOptions configures a single request. */
+export interface RequestOptions {
+  /** headers are sent with the request, they override the client headers. */
+  headers?: Record<string, string>;
+  /** signal aborts the request. */
+  signal?: AbortSignal;
+}
+
+/** UnexpectedResponseError is thrown when the API returns a status that is not described in the design. */
+export class UnexpectedResponseError extends Error {
+  readonly response: Response;
+
+  constructor(response: Response) {
+    super(` + "`unexpected response status ${response.status}`" + `);
+    this.name = "UnexpectedResponseError";
+    this.response = response;
+  }
+}
+
+interface RequestData {
+  query?: Record<string, unknown>;
+  headers?: Record<string, unknown>;
+  body?: unknown;
+  multipart?: boolean;
+}
+
+/** Client gives access to the API. */
+export class Client {
+  private readonly baseURL: string;
+  private readonly fetchFn: typeof fetch;
+  private readonly headers: Record<string, string>;
+
+  constructor(options: ClientOptions = {}) {
+    this.baseURL = options.baseURL ?? {{ printf "%q" .Module.BaseURL }};
+    this.fetchFn = options.fetch ?? ((input, init) => fetch(input, init));
+    this.headers = options.headers ?? {};
+  }
+{{ range .Module.Functions }}{{ $opt := .ParamsOptional }}
+{{ jsdoc "  " .Description }}  async {{ .Name }}({{ .Signature }}): Promise<{{ .TypeName }}> {
+    const resp = await this.request("{{ .Method }}", {{ .Path }}, options, {
+{{ with .QueryFields }}      query: { {{ range . }}{{ printf "%q" .Name }}: {{ .Accessor "params" $opt }}, {{ end }}},
+{{ end }}{{ with .HeaderFields }}      headers: { {{ range . }}{{ printf "%q" .Name }}: {{ .Accessor "params" $opt }}, {{ end }}},
+{{ end }}{{ if .Payload }}      body: {{ .Payload }},
+{{ end }}{{ if .Multipart }}      multipart: true,
+{{ end }}    });
+    switch (resp.status) {
+{{ range .Responses }}      case {{ .Status }}:
+        return { ok: {{ .OK }}, status: {{ .Status }}, headers: resp.headers, body: {{ .Decode }} };
+{{ end }}    }
+    throw new UnexpectedResponseError(resp);
+  }
+{{ end }}
+  private async request(method: string, path: string, options: RequestOptions, data: RequestData): Promise<Response> {
+    let url = this.baseURL + path;
+    const query = encodeQuery(data.query);
+    if (query !== "") {
+      url += "?" + query;
+    }
+    const headers: Record<string, string> = { ...this.headers };
+    for (const [key, value] of Object.entries(data.headers ?? {})) {
+      if (value !== undefined && value !== null) {
+        headers[key] = String(value);
+      }
+    }
+    Object.assign(headers, options.headers);
+    let body: BodyInit | undefined;
+    if (data.multipart) {
+      body = encodeForm(data.body);
+    } else if (data.body !== undefined) {
+      headers["Content-Type"] = "application/json";
+      body = JSON.stringify(data.body);
+    }
+    return this.fetchFn(url, { method, headers, body, signal: options.signal });
+  }
+}
+
+function encodeQuery(query: Record<string, unknown> = {}): string {
+  const parts: string[] = [];
+  for (const [key, value] of Object.entries(query)) {
+    for (const v of Array.isArray(value) ? value : [value]) {
+      if (v !== undefined && v !== null) {
+        parts.push(encodeURIComponent(key) + "=" + encodeURIComponent(String(v)));
+      }
+    }
+  }
+  return parts.join("&");
+}
+
+function encodeForm(payload: unknown): FormData {
+  const form = new FormData();
+  for (const [key, value] of Object.entries((payload ?? {}) as Record<string, unknown>)) {
+    for (const v of Array.isArray(value) ? value : [value]) {
+      if (v instanceof Blob) {
+        form.append(key, v);
+      } else if (v !== undefined && v !== null) {
+        form.append(key, typeof v === "object" ? JSON.stringify(v) : String(v));
+      }
+    }
+  }
+  return form;
+}
+`
@@ -0,0 +1,19 @@
+/*
+Package genvalidate implements the goagen validate command. The command evaluates the design and
+runs its validations like any other command, it then runs lint rules checking for problems that
+the validations allow and exits with a non zero status if an error is found. The command does
+not generate any file so that CI pipelines can fail fast on design problems:
+
+	goagen validate -d example.com/design
+
+Rules report errors or warnings. Warnings are printed but only make the command fail with
+--strict. --disable takes a comma separated list of rules not to run, e.g.
+--disable=missing-description. The rules are:
+
+	duplicate-route           actions must not define the same route (error)
+	missing-success-response  actions should define a response with a status lower than 400
+	payload-on-safe-method    GET, HEAD and DELETE actions should not define a payload
+	unknown-media-type        responses should not use undefined vendor media types
+	missing-description       the API, resources and actions should have a description
+*/
+package genvalidate
@@ -0,0 +1,13 @@
+package genvalidate_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenValidate(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenValidate Suite")
+}
@@ -0,0 +1,84 @@
+package genvalidate
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+)
+
+// NewGenerator returns an initialized instance of a design validation Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator runs the lint rules against the design, it does not generate any file.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	Strict   bool                  // Fail on warnings as well as errors
+	Disabled []string              // Names of the lint rules not to run
+	Output   io.Writer             // Writer the warnings are printed to, os.Stderr by default
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, ver, disable string
+		strict               bool
+	)
+
+	set := flag.NewFlagSet("validate", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.BoolVar(&strict, "strict", false, "")
+	set.StringVar(&disable, "disable", "", "")
+	set.String("design", "", "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{Strict: strict, API: design.Design}
+	if disable != "" {
+		g.Disabled = strings.Split(disable, ",")
+	}
+
+	return g.Generate()
+}
+
+// Generate runs the lint rules and returns an error listing the issues that make the validation
+// fail. The other issues are printed to the generator output.
+func (g *Generator) Generate() ([]string, error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+	out := g.Output
+	if out == nil {
+		out = os.Stderr
+	}
+	var failures []string
+	for _, i := range Lint(g.API, g.Disabled...) {
+		if i.Severity == Error || g.Strict {
+			failures = append(failures, i.Error())
+			continue
+		}
+		fmt.Fprintln(out, i.Error())
+	}
+	if len(failures) > 0 {
+		return nil, fmt.Errorf("%s\n%d design issue(s) found", strings.Join(failures, "\n"), len(failures))
+	}
+	return nil, nil
+}
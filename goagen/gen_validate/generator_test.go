@@ -0,0 +1,119 @@
+package genvalidate_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genvalidate "github.com/kyokomi/goa-v1/goagen/gen_validate"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genvalidate.Generator
+
+	var args = struct {
+		api      *design.APIDefinition
+		strict   bool
+		disabled []string
+		output   *bytes.Buffer
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		strict:   true,
+		disabled: []string{"missing-description"},
+		output:   new(bytes.Buffer),
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genvalidate.NewGenerator(
+				genvalidate.API(args.api),
+				genvalidate.Strict(args.strict),
+				genvalidate.Disable(args.disabled...),
+				genvalidate.Output(args.output),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.Strict).Should(Equal(args.strict))
+			Ω(generator.Disabled).Should(Equal(args.disabled))
+			Ω(generator.Output).Should(Equal(args.output))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var strict bool
+	var output *bytes.Buffer
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		strict = false
+		output = new(bytes.Buffer)
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {
+			apidsl.Description("The wine cellar API")
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Description("A wine bottle")
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Response(design.OK)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genvalidate.NewGenerator(
+			genvalidate.API(design.Design),
+			genvalidate.Strict(strict),
+			genvalidate.Output(output),
+		).Generate()
+	})
+
+	It("prints the warnings and does not generate any file", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(BeEmpty())
+		Ω(output.String()).Should(Equal(`warning: resource "bottle" action "show": no description (missing-description)` + "\n"))
+	})
+
+	Context("in strict mode", func() {
+		BeforeEach(func() {
+			strict = true
+		})
+
+		It("fails on warnings", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(Equal(`warning: resource "bottle" action "show": no description (missing-description)` + "\n1 design issue(s) found"))
+			Ω(output.String()).Should(BeEmpty())
+		})
+	})
+
+	Context("with errors", func() {
+		BeforeEach(func() {
+			apidsl.Resource("wine", func() {
+				apidsl.Description("A wine")
+				apidsl.Action("show", func() {
+					apidsl.Description("Show a wine")
+					apidsl.Routing(apidsl.GET("/bottles/:id"))
+					apidsl.Response(design.OK)
+				})
+			})
+		})
+
+		It("fails", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(ContainSubstring(`error: resource "wine" action "show": route GET /bottles/:id duplicates route GET /bottles/:id of resource "bottle" action "show" (duplicate-route)`))
+		})
+	})
+})
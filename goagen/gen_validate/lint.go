@@ -0,0 +1,223 @@
+package genvalidate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kyokomi/goa-v1/design"
+)
+
+// Severity is the severity of the issues reported by a lint rule.
+type Severity string
+
+const (
+	// Error issues make the validation fail.
+	Error Severity = "error"
+	// Warning issues only make the validation fail in strict mode.
+	Warning Severity = "warning"
+)
+
+type (
+	// Rule is a lint rule checking the design for problems that the design validations allow.
+	Rule struct {
+		// Name identifies the rule, it is used to disable the rule.
+		Name string
+		// Description explains what the rule checks.
+		Description string
+		// Severity is the severity of the issues reported by the rule.
+		Severity Severity
+		// Check returns the issues found in the API, Lint sets their Rule and Severity fields.
+		Check func(api *design.APIDefinition) []*Issue
+	}
+
+	// Issue is a problem found by a lint rule.
+	Issue struct {
+		// Rule is the name of the rule that found the issue.
+		Rule string
+		// Severity is the severity of the issue.
+		Severity Severity
+		// Context describes the definition the issue applies to.
+		Context string
+		// Message describes the issue.
+		Message string
+	}
+)
+
+// Rules lists the lint rules run by Lint.
+var Rules = []*Rule{
+	{
+		Name:        "duplicate-route",
+		Description: "actions must not define the same route, the router panics when mounting them",
+		Severity:    Error,
+		Check:       duplicateRoutes,
+	},
+	{
+		Name:        "missing-success-response",
+		Description: "actions should define a response with a status lower than 400",
+		Severity:    Warning,
+		Check:       missingSuccessResponses,
+	},
+	{
+		Name:        "payload-on-safe-method",
+		Description: "GET, HEAD and DELETE actions should not define a payload, many clients and proxies drop it",
+		Severity:    Warning,
+		Check:       payloadsOnSafeMethods,
+	},
+	{
+		Name:        "unknown-media-type",
+		Description: "responses should not use vendor media types that the design does not define",
+		Severity:    Warning,
+		Check:       unknownMediaTypes,
+	},
+	{
+		Name:        "missing-description",
+		Description: "the API, resources and actions should have a description",
+		Severity:    Warning,
+		Check:       missingDescriptions,
+	},
+}
+
+// Lint runs the lint rules that are not disabled against the API and returns the issues found
+// sorted by context.
+func Lint(api *design.APIDefinition, disabled ...string) []*Issue {
+	skip := make(map[string]bool)
+	for _, d := range disabled {
+		skip[d] = true
+	}
+	var issues []*Issue
+	for _, r := range Rules {
+		if skip[r.Name] {
+			continue
+		}
+		for _, i := range r.Check(api) {
+			i.Rule = r.Name
+			i.Severity = r.Severity
+			issues = append(issues, i)
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Context < issues[j].Context })
+	return issues
+}
+
+// Error returns the text of the issue as printed by goagen validate.
+func (i *Issue) Error() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", i.Severity, i.Context, i.Message, i.Rule)
+}
+
+// duplicateRoutes reports the routes that have the same method and path as a route defined
+// before them, the names of the wildcards do not matter.
+func duplicateRoutes(api *design.APIDefinition) []*Issue {
+	var issues []*Issue
+	seen := make(map[string]*design.RouteDefinition)
+	iterateActions(api, func(a *design.ActionDefinition) {
+		for _, r := range a.Routes {
+			key := r.Verb + " " + design.WildcardRegex.ReplaceAllStringFunc(r.FullPath(), func(w string) string {
+				return w[:2]
+			})
+			if other, ok := seen[key]; ok {
+				issues = append(issues, &Issue{
+					Context: a.Context(),
+					Message: fmt.Sprintf("route %s %s duplicates route %s %s of %s", r.Verb, r.FullPath(), other.Verb, other.FullPath(), other.Parent.Context()),
+				})
+				continue
+			}
+			seen[key] = r
+		}
+	})
+	return issues
+}
+
+// missingSuccessResponses reports the actions that only define error responses, WebSocket actions
+// are not checked as they usually define no response.
+func missingSuccessResponses(api *design.APIDefinition) []*Issue {
+	var issues []*Issue
+	iterateActions(api, func(a *design.ActionDefinition) {
+		if a.WebSocket() {
+			return
+		}
+		for _, r := range a.Responses {
+			if r.Status < 400 {
+				return
+			}
+		}
+		issues = append(issues, &Issue{
+			Context: a.Context(),
+			Message: "no success response defined",
+		})
+	})
+	return issues
+}
+
+// payloadsOnSafeMethods reports the GET, HEAD and DELETE routes of actions that define a payload.
+func payloadsOnSafeMethods(api *design.APIDefinition) []*Issue {
+	var issues []*Issue
+	iterateActions(api, func(a *design.ActionDefinition) {
+		if a.Payload == nil {
+			return
+		}
+		for _, r := range a.Routes {
+			switch r.Verb {
+			case "GET", "HEAD", "DELETE":
+				issues = append(issues, &Issue{
+					Context: a.Context(),
+					Message: fmt.Sprintf("payload defined for route %s %s", r.Verb, r.FullPath()),
+				})
+			}
+		}
+	})
+	return issues
+}
+
+// unknownMediaTypes reports the action responses that use a vendor media type identifier, e.g.
+// "application/vnd.bottle", that no media type of the design defines. Such responses are usually
+// typos and are rendered without a body type.
+func unknownMediaTypes(api *design.APIDefinition) []*Issue {
+	var issues []*Issue
+	iterateActions(api, func(a *design.ActionDefinition) {
+		a.IterateResponses(func(r *design.ResponseDefinition) error {
+			id := design.CanonicalIdentifier(r.MediaType)
+			if !strings.HasPrefix(id, "application/vnd.") || id == design.CanonicalIdentifier(design.ErrorMedia.Identifier) {
+				return nil
+			}
+			if api.MediaTypeWithIdentifier(r.MediaType) == nil {
+				issues = append(issues, &Issue{
+					Context: a.Context(),
+					Message: fmt.Sprintf("response %s uses undefined media type %#v", r.Name, r.MediaType),
+				})
+			}
+			return nil
+		})
+	})
+	return issues
+}
+
+// missingDescriptions reports the API, resources and actions that have no description.
+func missingDescriptions(api *design.APIDefinition) []*Issue {
+	var issues []*Issue
+	if strings.TrimSpace(api.Description) == "" {
+		issues = append(issues, &Issue{Context: api.Context(), Message: "no description"})
+	}
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		if strings.TrimSpace(res.Description) == "" {
+			issues = append(issues, &Issue{Context: res.Context(), Message: "no description"})
+		}
+		return nil
+	})
+	iterateActions(api, func(a *design.ActionDefinition) {
+		if strings.TrimSpace(a.Description) == "" {
+			issues = append(issues, &Issue{Context: a.Context(), Message: "no description"})
+		}
+	})
+	return issues
+}
+
+// iterateActions calls it with the actions of the API sorted by resource and action name.
+func iterateActions(api *design.APIDefinition, it func(*design.ActionDefinition)) {
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			it(a)
+			return nil
+		})
+	})
+}
@@ -0,0 +1,107 @@
+package genvalidate_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1/design"
+	"github.com/kyokomi/goa-v1/design/apidsl"
+	"github.com/kyokomi/goa-v1/dslengine"
+	genvalidate "github.com/kyokomi/goa-v1/goagen/gen_validate"
+)
+
+var _ = Describe("Lint", func() {
+	var disabled []string
+	var issues []*genvalidate.Issue
+
+	// messages returns the text of the issues found by the given rule.
+	messages := func(rule string) []string {
+		var msgs []string
+		for _, i := range issues {
+			if i.Rule == rule {
+				msgs = append(msgs, i.Context+": "+i.Message)
+			}
+		}
+		return msgs
+	}
+
+	BeforeEach(func() {
+		disabled = nil
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		apidsl.API("cellar", func() {})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Response(design.OK, "application/vnd.botle")
+			})
+			apidsl.Action("search", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"), apidsl.POST("/bottles/search"))
+				apidsl.Payload(func() {
+					apidsl.Attribute("name")
+				})
+				apidsl.Response(design.NotFound)
+			})
+			apidsl.Action("watch", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id/watch"))
+				apidsl.Scheme("ws")
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		issues = genvalidate.Lint(design.Design, disabled...)
+	})
+
+	It("reports duplicate routes", func() {
+		Ω(messages("duplicate-route")).Should(Equal([]string{
+			`resource "bottle" action "show": route GET /bottles/:id duplicates route GET /bottles/:id of resource "bottle" action "search"`,
+		}))
+		for _, i := range issues {
+			if i.Rule == "duplicate-route" {
+				Ω(i.Severity).Should(Equal(genvalidate.Error))
+			}
+		}
+	})
+
+	It("reports actions without success response", func() {
+		Ω(messages("missing-success-response")).Should(Equal([]string{
+			`resource "bottle" action "search": no success response defined`,
+		}))
+	})
+
+	It("reports payloads on safe methods", func() {
+		Ω(messages("payload-on-safe-method")).Should(Equal([]string{
+			`resource "bottle" action "search": payload defined for route GET /bottles/:id`,
+		}))
+	})
+
+	It("reports undefined vendor media types", func() {
+		Ω(messages("unknown-media-type")).Should(Equal([]string{
+			`resource "bottle" action "show": response OK uses undefined media type "application/vnd.botle"`,
+		}))
+	})
+
+	It("reports missing descriptions", func() {
+		Ω(messages("missing-description")).Should(ConsistOf(
+			`API "cellar": no description`,
+			`resource "bottle": no description`,
+			`resource "bottle" action "search": no description`,
+			`resource "bottle" action "show": no description`,
+			`resource "bottle" action "watch": no description`,
+		))
+	})
+
+	Context("with disabled rules", func() {
+		BeforeEach(func() {
+			disabled = []string{"missing-description", "duplicate-route"}
+		})
+
+		It("does not run them", func() {
+			Ω(messages("missing-description")).Should(BeEmpty())
+			Ω(messages("duplicate-route")).Should(BeEmpty())
+			Ω(messages("payload-on-safe-method")).ShouldNot(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,38 @@
+package genvalidate
+
+import (
+	"io"
+
+	"github.com/kyokomi/goa-v1/design"
+)
+
+// Option a generator option definition
+type Option func(*Generator)
+
+// API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+// Strict Fail on warnings as well as errors
+func Strict(strict bool) Option {
+	return func(g *Generator) {
+		g.Strict = strict
+	}
+}
+
+// Disable Names of the lint rules not to run
+func Disable(rules ...string) Option {
+	return func(g *Generator) {
+		g.Disabled = append(g.Disabled, rules...)
+	}
+}
+
+// Output Writer the warnings are printed to
+func Output(w io.Writer) Option {
+	return func(g *Generator) {
+		g.Output = w
+	}
+}
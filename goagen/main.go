@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/kyokomi/goa-v1/goagen/codegen"
@@ -38,16 +41,29 @@ the "app" command generates the code that supports the service controllers.
 
 The "bootstrap" command runs the "app", "main", "client" and "swagger" commands generating the
 controllers supporting code and main skeleton code (if not already present) as well as a client
-package and tool and the Swagger specification for the API.
+package and tool and the Swagger specification for the API. The commands run concurrently, the
+--jobs flag limits the number of generators running at the same time.
+
+The "validate" command evaluates and lints the design without generating any file.
+
+The "diff" command compares the design with a previous version and reports the breaking and
+compatible changes made to the API contract.
 `}
 	var (
-		designPkg string
-		debug     bool
+		designPkg     string
+		debug         bool
+		watch         bool
+		watchInterval time.Duration
+		jobs          int
 	)
 
 	rootCmd.PersistentFlags().StringP("out", "o", ".", "output directory")
 	rootCmd.PersistentFlags().StringVarP(&designPkg, "design", "d", "", "design package import path")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debug mode, does not cleanup temporary files.")
+	rootCmd.PersistentFlags().BoolVar(&watch, "watch", false, "watch the design package and regenerate the artifacts when it changes")
+	rootCmd.PersistentFlags().DurationVar(&watchInterval, "watch-interval", time.Second, "interval at which the design package is polled in watch mode")
+	rootCmd.PersistentFlags().IntVarP(&jobs, "jobs", "j", runtime.NumCPU(), "maximum number of generators run concurrently by the bootstrap command and of files formatted concurrently by each generator")
+	rootCmd.PersistentFlags().String("templates", "", `directory containing template overrides, e.g. "app/context.tmpl" overrides the "context" template of the "app" command`)
 
 	// versionCmd implements the "version" command
 	versionCmd := &cobra.Command{
@@ -59,57 +75,244 @@ package and tool and the Swagger specification for the API.
 	}
 	rootCmd.AddCommand(versionCmd)
 
+	// validateCmd implements the "validate" command.
+	validateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate and lint the design without generating any file",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genvalidate", c) },
+	}
+	validateCmd.Flags().Bool("strict", false, "Fail on lint warnings as well as errors")
+	validateCmd.Flags().String("disable", "", "Comma separated list of lint rules not to run")
+	rootCmd.AddCommand(validateCmd)
+
+	// diffCmd implements the "diff" command.
+	diffCmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Report the breaking and compatible changes made to the design",
+		Run:   func(c *cobra.Command, _ []string) { files, err = runDiff(c) },
+	}
+	diffCmd.Flags().String("against", "", "git `revision` or path to a snapshot file written with --snapshot to compare the design with")
+	diffCmd.Flags().String("snapshot", "", "write the snapshot of the design contract to the given `file` instead of comparing it")
+	diffCmd.Flags().String("fail-on", "none", `fail if changes of the given kind are found: "none", "breaking" or "any"`)
+	rootCmd.AddCommand(diffCmd)
+
 	// appCmd implements the "app" command.
 	var (
-		pkg    string
-		notest bool
+		pkg                        string
+		notest, otel, fuzz, enums  bool
+		bench, props, validateTags bool
+		contextFirst               bool
 	)
+	runApp := func(c *cobra.Command) ([]string, error) { return run("genapp", c) }
 	appCmd := &cobra.Command{
 		Use:   "app",
 		Short: "Generate application code",
-		Run:   func(c *cobra.Command, _ []string) { files, err = run("genapp", c) },
+		Run:   func(c *cobra.Command, _ []string) { files, err = runApp(c) },
 	}
 	appCmd.Flags().StringVar(&pkg, "pkg", "app", "Name of generated Go package containing controllers supporting code (contexts, media types, user types etc.)")
 	appCmd.Flags().BoolVar(&notest, "notest", false, "Prevent generation of test helpers")
+	appCmd.Flags().BoolVar(&otel, "otel", false, "Wrap the action handlers with OpenTelemetry spans propagating the incoming trace context")
+	appCmd.Flags().BoolVar(&fuzz, "fuzz", false, "Generate fuzz targets checking that the request decoding and validation code never panics")
+	appCmd.Flags().BoolVar(&bench, "bench", false, "Generate benchmarks measuring the request decoding, validation and response encoding code of each action")
+	appCmd.Flags().BoolVar(&props, "props", false, "Generate property tests checking that values built from the design validations pass the generated validation code and that values breaking them fail it")
+	appCmd.Flags().BoolVar(&enums, "enums", false, "Generate named Go types with constants for the enum attributes")
+	appCmd.Flags().BoolVar(&validateTags, "validate-tags", false, "Add go-playground/validator struct tags derived from the design validations to the generated types")
+	appCmd.Flags().BoolVar(&contextFirst, "context-first", false, "Make the controller actions take a context.Context and the action request data (e.g. *app.ShowBottleRequest) instead of an action context embedding the context.Context")
 	rootCmd.AddCommand(appCmd)
 
 	// mainCmd implements the "main" command.
 	var (
 		force, regen bool
 	)
+	runMain := func(c *cobra.Command) ([]string, error) { return run("genmain", c) }
 	mainCmd := &cobra.Command{
 		Use:   "main",
 		Short: "Generate application scaffolding",
-		Run:   func(c *cobra.Command, _ []string) { files, err = run("genmain", c) },
+		Run:   func(c *cobra.Command, _ []string) { files, err = runMain(c) },
 	}
 	mainCmd.Flags().BoolVar(&force, "force", false, "overwrite existing files")
-	mainCmd.Flags().BoolVar(&regen, "regen", false, "regenerate scaffolding, maintaining controller implementations")
+	mainCmd.Flags().BoolVar(&regen, "regen", false, "add the methods of new actions to existing controllers, maintaining controller implementations")
+	mainCmd.Flags().BoolVar(&contextFirst, "context-first", false, "generate actions taking a context.Context and the action request data, see the app command")
 	rootCmd.AddCommand(mainCmd)
 
 	// clientCmd implements the "client" command.
 	var (
-		toolDir, tool string
-		notool        bool
+		toolDir, tool, language string
+		notool, mock            bool
 	)
+	runClient := func(c *cobra.Command) ([]string, error) {
+		switch language {
+		case "go":
+			return run("genclient", c)
+		case "python":
+			return run("genpython", c)
+		case "swift":
+			return run("genswift", c)
+		case "kotlin":
+			return run("genkotlin", c)
+		case "dart":
+			return run("gendart", c)
+		case "java":
+			return run("genjava", c)
+		case "csharp":
+			return run("gencsharp", c)
+		default:
+			return nil, fmt.Errorf(`unsupported client language %#v, must be "go", "python", "swift", "kotlin", "dart", "java" or "csharp"`, language)
+		}
+	}
 	clientCmd := &cobra.Command{
 		Use:   "client",
 		Short: "Generate client package and tool",
-		Run:   func(c *cobra.Command, _ []string) { files, err = run("genclient", c) },
+		Run:   func(c *cobra.Command, _ []string) { files, err = runClient(c) },
 	}
-	clientCmd.Flags().StringVar(&pkg, "pkg", "client", "Name of generated client Go package")
+	clientCmd.Flags().StringVar(&pkg, "pkg", "client", `Name of generated client Go package, the Python package defaults to "<API name>_client", the Swift package to "<API name>Client", the Kotlin and Java packages to "<API name>.client", the Dart package to "<API name>_client" and the C# namespace to "<API name>Client"`)
+	clientCmd.Flags().StringVar(&language, "language", "go", `Language of generated client, "go", "python", "swift", "kotlin", "dart", "java" or "csharp"`)
 	clientCmd.Flags().StringVar(&toolDir, "tooldir", "tool", "Name of generated tool directory")
 	clientCmd.Flags().StringVar(&tool, "tool", "[API-name]-cli", "Name of generated tool")
 	clientCmd.Flags().BoolVar(&notool, "notool", false, "Prevent generation of cli tool")
+	clientCmd.Flags().BoolVar(&enums, "enums", false, "Generate named Go types with constants for the enum attributes")
+	clientCmd.Flags().BoolVar(&validateTags, "validate-tags", false, "Add go-playground/validator struct tags derived from the design validations to the generated types")
+	clientCmd.Flags().BoolVar(&mock, "mock", false, "Generate mock implementations of the resource client interfaces")
 	rootCmd.AddCommand(clientCmd)
 
 	// swaggerCmd implements the "swagger" command.
+	runSwagger := func(c *cobra.Command) ([]string, error) { return run("genswagger", c) }
 	swaggerCmd := &cobra.Command{
 		Use:   "swagger",
 		Short: "Generate Swagger",
-		Run:   func(c *cobra.Command, _ []string) { files, err = run("genswagger", c) },
+		Run:   func(c *cobra.Command, _ []string) { files, err = runSwagger(c) },
 	}
+	swaggerCmd.Flags().String("docs", "", `Generate a controller serving the swagger specification and an interactive documentation page, "swagger-ui" or "redoc"`)
+	swaggerCmd.Flags().String("docs-path", "/docs", "Path of the documentation page mounted by the documentation controller")
+	swaggerCmd.Flags().String("apigateway", "", `Add x-amazon-apigateway-integration extensions of the given default type, "aws", "aws_proxy", "http", "http_proxy" or "mock"`)
+	swaggerCmd.Flags().String("apigateway-uri", "", "Template of the default API Gateway integration URI, e.g. \"https://backend.example.com{{ .Path }}\"")
+	swaggerCmd.Flags().Bool("split", false, "Write one document per resource referencing shared definitions and an index referencing the documents")
 	rootCmd.AddCommand(swaggerCmd)
 
+	// openapiCmd implements the "openapi" command.
+	openapiCmd := &cobra.Command{
+		Use:   "openapi",
+		Short: "Generate OpenAPI 3.0 or 3.1 specification",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genopenapi", c) },
+	}
+	openapiCmd.Flags().String("spec", "3.0", `OpenAPI specification version, "3.0" or "3.1"`)
+	rootCmd.AddCommand(openapiCmd)
+
+	// asyncapiCmd implements the "asyncapi" command.
+	asyncapiCmd := &cobra.Command{
+		Use:   "asyncapi",
+		Short: "Generate AsyncAPI 2.6 specification of the WebSocket, server-sent events and webhook endpoints",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genasyncapi", c) },
+	}
+	rootCmd.AddCommand(asyncapiCmd)
+
+	// ramlCmd implements the "raml" command.
+	ramlCmd := &cobra.Command{
+		Use:   "raml",
+		Short: "Generate RAML 1.0 definition",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genraml", c) },
+	}
+	rootCmd.AddCommand(ramlCmd)
+
+	// postmanCmd implements the "postman" command.
+	postmanCmd := &cobra.Command{
+		Use:   "postman",
+		Short: "Generate Postman collection (v2.1) and environment",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genpostman", c) },
+	}
+	rootCmd.AddCommand(postmanCmd)
+
+	// harCmd implements the "har" command.
+	harCmd := &cobra.Command{
+		Use:   "har",
+		Short: "Generate HAR (HTTP Archive) file with an example request and response per action",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genhar", c) },
+	}
+	rootCmd.AddCommand(harCmd)
+
+	// loadCmd implements the "load" command.
+	loadCmd := &cobra.Command{
+		Use:   "load",
+		Short: "Generate k6 load test scenarios and vegeta targets",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genload", c) },
+	}
+	loadCmd.Flags().Int("samples", 10, "Number of vegeta targets generated per action route")
+	rootCmd.AddCommand(loadCmd)
+
+	// proxyCmd implements the "proxy" command.
+	proxyCmd := &cobra.Command{
+		Use:   "proxy",
+		Short: "Generate Envoy route configuration or nginx location blocks",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genproxy", c) },
+	}
+	proxyCmd.Flags().String("format", "envoy", `Configuration format, "envoy" or "nginx"`)
+	proxyCmd.Flags().String("cluster", "", "Name of the Envoy cluster or nginx upstream serving the API, defaults to the API name")
+	proxyCmd.Flags().Duration("timeout", 0, "Timeout of the routes whose design does not set one")
+	rootCmd.AddCommand(proxyCmd)
+
+	// pactCmd implements the "pact" command.
+	pactCmd := &cobra.Command{
+		Use:   "pact",
+		Short: "Generate Pact contract files and a provider verification test",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genpact", c) },
+	}
+	pactCmd.Flags().StringVar(&pkg, "pkg", "pact", "Name of generated pact directory")
+	pactCmd.Flags().String("consumer", "consumer", "Name of the consumer of the pact")
+	rootCmd.AddCommand(pactCmd)
+
+	// mockCmd implements the "mock" command.
+	mockCmd := &cobra.Command{
+		Use:   "mock",
+		Short: "Generate mock HTTP server answering with the design examples",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genmock", c) },
+	}
+	mockCmd.Flags().StringVar(&pkg, "pkg", "mock", "Name of generated mock server directory")
+	rootCmd.AddCommand(mockCmd)
+
+	// markdownCmd implements the "markdown" command.
+	markdownCmd := &cobra.Command{
+		Use:   "markdown",
+		Short: "Generate Markdown API documentation",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genmarkdown", c) },
+	}
+	markdownCmd.Flags().StringVar(&pkg, "pkg", "docs", "Name of generated documentation directory")
+	rootCmd.AddCommand(markdownCmd)
+
+	// blueprintCmd implements the "blueprint" command.
+	blueprintCmd := &cobra.Command{
+		Use:   "blueprint",
+		Short: "Generate API Blueprint document with example requests and responses",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genblueprint", c) },
+	}
+	rootCmd.AddCommand(blueprintCmd)
+
+	// contractCmd implements the "contract" command.
+	contractCmd := &cobra.Command{
+		Use:   "contract",
+		Short: "Generate contract tests checking a running implementation against the design",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("gencontract", c) },
+	}
+	contractCmd.Flags().StringVar(&pkg, "pkg", "contract", "Name of generated contract test package")
+	rootCmd.AddCommand(contractCmd)
+
+	// protoCmd implements the "proto" command.
+	protoCmd := &cobra.Command{
+		Use:   "proto",
+		Short: "Generate gRPC protocol buffer definitions and adapters",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genproto", c) },
+	}
+	protoCmd.Flags().StringVar(&pkg, "pkg", "app", "Name of generated Go package containing controllers supporting code (contexts, media types, user types etc.)")
+	rootCmd.AddCommand(protoCmd)
+
+	// graphqlCmd implements the "graphql" command.
+	graphqlCmd := &cobra.Command{
+		Use:   "graphql",
+		Short: "Generate GraphQL schema and resolvers serving the queries and mutations with the controllers",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("gengraphql", c) },
+	}
+	graphqlCmd.Flags().StringVar(&pkg, "pkg", "app", "Name of generated Go package containing controllers supporting code (contexts, media types, user types etc.)")
+	rootCmd.AddCommand(graphqlCmd)
+
 	// jsCmd implements the "js" command.
 	var (
 		timeout      = time.Duration(20) * time.Second
@@ -127,12 +330,23 @@ package and tool and the Swagger specification for the API.
 	jsCmd.Flags().BoolVar(&noexample, "noexample", false, `Skip generation of example HTML and controller`)
 	rootCmd.AddCommand(jsCmd)
 
+	// tsCmd implements the "ts" command.
+	tsCmd := &cobra.Command{
+		Use:   "ts",
+		Short: "Generate typed TypeScript client",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("gents", c) },
+	}
+	tsCmd.Flags().StringVar(&scheme, "scheme", "", `the URL scheme used to make requests to the API, defaults to the scheme defined in the API design if any.`)
+	tsCmd.Flags().StringVar(&host, "host", "", `the API hostname, defaults to the hostname defined in the API design if any, requests target the page origin if none`)
+	rootCmd.AddCommand(tsCmd)
+
 	// schemaCmd implements the "schema" command.
 	schemaCmd := &cobra.Command{
 		Use:   "schema",
 		Short: "Generate JSON Schema",
 		Run:   func(c *cobra.Command, _ []string) { files, err = run("genschema", c) },
 	}
+	schemaCmd.Flags().String("draft", "2020-12", `JSON Schema draft, "2020-12" or "04" for the legacy hyper-schema`)
 	rootCmd.AddCommand(schemaCmd)
 
 	// genCmd implements the "gen" command.
@@ -150,31 +364,31 @@ package and tool and the Swagger specification for the API.
 	genCmd.Flags().SetInterspersed(false)
 	rootCmd.AddCommand(genCmd)
 
+	// pluginCmd implements the "plugin" command.
+	pluginCmd := &cobra.Command{
+		Use:   "plugin",
+		Short: "Run prebuilt generator plugin executable",
+		Run:   func(c *cobra.Command, args []string) { files, err = runPlugin(c, args) },
+	}
+	pluginCmd.Flags().String("cmd", "", "Path to the plugin executable, looked up in PATH if it contains no path separator. The plugin reads the serialized design on its standard input and writes the generated files to its standard output.")
+	// stop parsing arguments after -- so that they can be given to the plugin
+	pluginCmd.Flags().SetInterspersed(false)
+	rootCmd.AddCommand(pluginCmd)
+
 	// boostrapCmd implements the "bootstrap" command.
 	bootCmd := &cobra.Command{
 		Use:   "bootstrap",
 		Short: `Equivalent to running the "app", "main", "client" and "swagger" commands.`,
-		Run: func(c *cobra.Command, a []string) {
-			appCmd.Run(c, a)
-			if err != nil {
-				return
-			}
-			prev := files
-
-			mainCmd.Run(c, a)
-			if err != nil {
-				return
-			}
-			prev = append(prev, files...)
-
-			clientCmd.Run(c, a)
-			if err != nil {
-				return
-			}
-			prev = append(prev, files...)
-
-			swaggerCmd.Run(c, a)
-			files = append(prev, files...)
+		Run: func(c *cobra.Command, _ []string) {
+			// Each generator only gets the flags of its own command.
+			appSub, mainSub := subcommand(c, appCmd), subcommand(c, mainCmd)
+			clientSub, swaggerSub := subcommand(c, clientCmd), subcommand(c, swaggerCmd)
+			files, err = runConcurrently(jobs,
+				func() ([]string, error) { return runApp(appSub) },
+				func() ([]string, error) { return runMain(mainSub) },
+				func() ([]string, error) { return runClient(clientSub) },
+				func() ([]string, error) { return runSwagger(swaggerSub) },
+			)
 		},
 	}
 	bootCmd.Flags().AddFlagSet(appCmd.Flags())
@@ -193,10 +407,11 @@ package and tool and the Swagger specification for the API.
 		Run:   func(c *cobra.Command, _ []string) { files, err = run("gencontroller", c) },
 	}
 	controllerCmd.Flags().BoolVar(&force, "force", false, "overwrite existing files")
-	controllerCmd.Flags().BoolVar(&regen, "regen", false, "regenerate scaffolding, maintaining controller implementations")
+	controllerCmd.Flags().BoolVar(&regen, "regen", false, "add the methods of new actions to existing controllers, maintaining controller implementations")
 	controllerCmd.Flags().StringVar(&res, "res", "", "name of the `resource` to generate the controller for, generate all if not specified")
 	controllerCmd.Flags().StringVar(&pkg, "pkg", "main", "name of the generated controller `package`")
 	controllerCmd.Flags().StringVar(&appPkg, "app-pkg", "app", "`import path` of Go package generated with 'goagen app', may be relative to output")
+	controllerCmd.Flags().BoolVar(&contextFirst, "context-first", false, "generate actions taking a context.Context and the action request data, see the app command")
 	rootCmd.AddCommand(controllerCmd)
 
 	// cmdsCmd implements the commands command
@@ -215,26 +430,47 @@ package and tool and the Swagger specification for the API.
 		}
 	}
 
+	stop := make(chan struct{})
 	go utils.Catch(nil, func() {
 		terminatedByUser = true
+		close(stop)
 	})
 
-	rootCmd.Execute()
+	cmd, _ := rootCmd.ExecuteC()
 
 	if terminatedByUser {
 		cleanup()
 		return
 	}
 
+	if watch && isGenerationCommand(cmd) {
+		printFiles(files, err)
+		regen := func() ([]string, error) {
+			files, err = nil, nil
+			cmd.Run(cmd, cmd.Flags().Args())
+			return files, err
+		}
+		if err := watchDesign(designPkg, watchInterval, files, regen, stop); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err != nil {
 		cleanup()
 		fmt.Fprintln(os.Stderr, err.Error())
 		os.Exit(1)
 	}
 
-	rels := make([]string, len(files))
+	fmt.Println(strings.Join(relPaths(files), "\n"))
+}
+
+// relPaths returns the given paths relative to the current working directory when possible.
+func relPaths(paths []string) []string {
+	rels := make([]string, len(paths))
 	cd, _ := os.Getwd()
-	for i, f := range files {
+	for i, f := range paths {
 		r, err := filepath.Rel(cd, f)
 		if err == nil {
 			rels[i] = r
@@ -242,7 +478,63 @@ package and tool and the Swagger specification for the API.
 			rels[i] = f
 		}
 	}
-	fmt.Println(strings.Join(rels, "\n"))
+	return rels
+}
+
+// runConcurrently runs the given generators, at most jobs at a time, and returns the files they
+// generated in the order of the generators. The generators must write to distinct locations. The
+// files generated by the generators that succeeded are returned together with the first error so
+// that they get cleaned up.
+func runConcurrently(jobs int, gens ...func() ([]string, error)) ([]string, error) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	var (
+		results = make([][]string, len(gens))
+		errs    = make([]error, len(gens))
+		sem     = make(chan struct{}, jobs)
+		wg      sync.WaitGroup
+	)
+	for i, gen := range gens {
+		wg.Add(1)
+		go func(i int, gen func() ([]string, error)) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i], errs[i] = gen()
+		}(i, gen)
+	}
+	wg.Wait()
+	var files []string
+	for _, r := range results {
+		files = append(files, r...)
+	}
+	for _, err := range errs {
+		if err != nil {
+			return files, err
+		}
+	}
+	return files, nil
+}
+
+// subcommand returns a command declaring the flags of sub set to the values given to the bootstrap
+// command c. The bootstrap command accepts the flags of all the commands it runs while the generator
+// of each command only defines the flags of that command.
+func subcommand(c, sub *cobra.Command) *cobra.Command {
+	sc := &cobra.Command{Use: sub.Use}
+	declare := func(f *pflag.Flag) {
+		if sc.Flags().Lookup(f.Name) == nil {
+			sc.Flags().StringP(f.Name, f.Shorthand, f.DefValue, f.Usage)
+		}
+	}
+	sub.Flags().VisitAll(declare)
+	sub.InheritedFlags().VisitAll(declare)
+	c.Flags().Visit(func(f *pflag.Flag) {
+		if sc.Flags().Lookup(f.Name) != nil {
+			sc.Flags().Set(f.Name, f.Value.String())
+		}
+	})
+	return sc
 }
 
 func run(pkg string, c *cobra.Command) ([]string, error) {
@@ -271,10 +563,27 @@ func runGen(c *cobra.Command, args []string) ([]string, error) {
 	return generate(pkgName, pkgPath, c, args)
 }
 
+func runPlugin(c *cobra.Command, args []string) ([]string, error) {
+	pkgPath := "github.com/kyokomi/goa-v1/goagen/gen_plugin"
+	pkgSrcPath, err := codegen.PackageSourcePath(pkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plugin package import path: %s", err)
+	}
+	pkgName, err := codegen.PackageName(pkgSrcPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid package import path: %s", err)
+	}
+	if len(args) > 0 {
+		// the plugin arguments may look like flags, make sure the generator does not parse them
+		args = append([]string{"--"}, args...)
+	}
+	return generate(pkgName, pkgPath, c, args)
+}
+
 func generate(pkgName, pkgPath string, c *cobra.Command, args []string) ([]string, error) {
 	m := make(map[string]string)
 	c.Flags().Visit(func(f *pflag.Flag) {
-		if f.Name != "pkg-path" {
+		if f.Name != "pkg-path" && f.Name != "watch" && f.Name != "watch-interval" && f.Name != "jobs" {
 			m[f.Name] = f.Value.String()
 		}
 	})
@@ -287,6 +596,11 @@ func generate(pkgName, pkgPath string, c *cobra.Command, args []string) ([]strin
 	if err != nil {
 		return nil, err
 	}
+	if t, ok := m["templates"]; ok {
+		if m["templates"], err = filepath.Abs(t); err != nil {
+			return nil, err
+		}
+	}
 
 	gen, err := meta.NewGenerator(
 		pkgName+".Generate",
@@ -297,6 +611,9 @@ func generate(pkgName, pkgPath string, c *cobra.Command, args []string) ([]strin
 	if err != nil {
 		return nil, err
 	}
+	if gen.Jobs, err = strconv.Atoi(c.Flag("jobs").Value.String()); err != nil {
+		return nil, fmt.Errorf("invalid jobs flag: %s", err)
+	}
 	return gen.Generate()
 }
 
@@ -353,7 +670,7 @@ func flagJSON(fl *pflag.Flag) *flag {
 	f := &flag{Long: fl.Name, Short: fl.Shorthand, Description: fl.Usage}
 	f.Required = fl.Name == "pkg-path" || fl.Name == "design"
 	switch fl.Name {
-	case "out":
+	case "out", "templates":
 		f.Argument = "$DIR"
 	case "design":
 		f.Argument = "$DESIGN_PKG"
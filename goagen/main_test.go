@@ -0,0 +1,112 @@
+package main
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var _ = Describe("bootstrap", func() {
+	var (
+		args []string
+
+		root, appCmd, clientCmd, swaggerCmd *cobra.Command
+		flags                               map[string]map[string]string
+		defaults                            map[string]map[string]string
+	)
+
+	BeforeEach(func() {
+		args = nil
+		flags = make(map[string]map[string]string)
+		defaults = make(map[string]map[string]string)
+		noop := func(*cobra.Command, []string) {}
+
+		root = &cobra.Command{Use: "goagen"}
+		root.PersistentFlags().StringP("out", "o", ".", "")
+		root.PersistentFlags().StringP("design", "d", "", "")
+
+		appCmd = &cobra.Command{Use: "app", Run: noop}
+		appCmd.Flags().String("pkg", "app", "")
+		appCmd.Flags().Bool("bench", false, "")
+		appCmd.Flags().Bool("props", false, "")
+
+		clientCmd = &cobra.Command{Use: "client", Run: noop}
+		clientCmd.Flags().String("pkg", "client", "")
+		clientCmd.Flags().String("language", "go", "")
+
+		swaggerCmd = &cobra.Command{Use: "swagger", Run: noop}
+		swaggerCmd.Flags().String("docs", "", "")
+		swaggerCmd.Flags().String("docs-path", "/docs", "")
+		swaggerCmd.Flags().String("apigateway", "", "")
+		swaggerCmd.Flags().String("apigateway-uri", "", "")
+		swaggerCmd.Flags().Bool("split", false, "")
+
+		bootCmd := &cobra.Command{
+			Use: "bootstrap",
+			Run: func(c *cobra.Command, _ []string) {
+				for _, sub := range []*cobra.Command{appCmd, clientCmd, swaggerCmd} {
+					sc := subcommand(c, sub)
+					flags[sub.Use] = make(map[string]string)
+					defaults[sub.Use] = make(map[string]string)
+					sc.Flags().Visit(func(f *pflag.Flag) { flags[sub.Use][f.Name] = f.Value.String() })
+					sc.Flags().VisitAll(func(f *pflag.Flag) { defaults[sub.Use][f.Name] = f.DefValue })
+				}
+			},
+		}
+		bootCmd.Flags().AddFlagSet(appCmd.Flags())
+		bootCmd.Flags().AddFlagSet(clientCmd.Flags())
+		bootCmd.Flags().AddFlagSet(swaggerCmd.Flags())
+		root.AddCommand(appCmd, clientCmd, swaggerCmd, bootCmd)
+	})
+
+	JustBeforeEach(func() {
+		root.SetArgs(append([]string{"bootstrap"}, args...))
+		Ω(root.Execute()).ShouldNot(HaveOccurred())
+	})
+
+	Context("with the flags of each command", func() {
+		BeforeEach(func() {
+			args = []string{
+				"--design", "design", "--pkg", "pkg",
+				"--bench", "--props",
+				"--language", "python",
+				"--split", "--docs", "redoc", "--docs-path", "/api",
+				"--apigateway", "aws_proxy", "--apigateway-uri", "https://backend{{ .Path }}",
+			}
+		})
+
+		It("passes each generator the flags of its command only", func() {
+			Ω(flags["app"]).Should(Equal(map[string]string{
+				"design": "design",
+				"pkg":    "pkg",
+				"bench":  "true",
+				"props":  "true",
+			}))
+			Ω(flags["client"]).Should(Equal(map[string]string{
+				"design":   "design",
+				"pkg":      "pkg",
+				"language": "python",
+			}))
+			Ω(flags["swagger"]).Should(Equal(map[string]string{
+				"design":         "design",
+				"split":          "true",
+				"docs":           "redoc",
+				"docs-path":      "/api",
+				"apigateway":     "aws_proxy",
+				"apigateway-uri": "https://backend{{ .Path }}",
+			}))
+		})
+	})
+
+	Context("with no flag", func() {
+		It("keeps the defaults of each command", func() {
+			Ω(flags["app"]).Should(BeEmpty())
+			Ω(flags["client"]).Should(BeEmpty())
+			Ω(flags["swagger"]).Should(BeEmpty())
+			Ω(defaults["app"]).Should(HaveKeyWithValue("pkg", "app"))
+			Ω(defaults["client"]).Should(HaveKeyWithValue("pkg", "client"))
+			Ω(defaults["swagger"]).Should(HaveKeyWithValue("out", "."))
+		})
+	})
+})
@@ -43,6 +43,14 @@ type Generator struct {
 	// DesignPkgPath is the Go import path to the design package.
 	DesignPkgPath string
 
+	// TemplatesDir is the path to the directory containing the template overrides loaded
+	// by the generator, see codegen.LoadTemplates.
+	TemplatesDir string
+
+	// Jobs is the number of source files the generator formats concurrently, the files are
+	// formatted sequentially if lower than 2.
+	Jobs int
+
 	debug bool
 }
 
@@ -50,8 +58,8 @@ type Generator struct {
 // given its factory method and command line flags.
 func NewGenerator(genfunc string, imports []*codegen.ImportSpec, flags map[string]string, customflags []string) (*Generator, error) {
 	var (
-		outDir, designPkgPath string
-		debug                 bool
+		outDir, designPkgPath, templatesDir string
+		debug                               bool
 	)
 
 	if o, ok := flags["out"]; ok {
@@ -60,6 +68,9 @@ func NewGenerator(genfunc string, imports []*codegen.ImportSpec, flags map[strin
 	if d, ok := flags["design"]; ok {
 		designPkgPath = d
 	}
+	if t, ok := flags["templates"]; ok {
+		templatesDir = t
+	}
 	if d, ok := flags["debug"]; ok {
 		var err error
 		debug, err = strconv.ParseBool(d)
@@ -75,6 +86,7 @@ func NewGenerator(genfunc string, imports []*codegen.ImportSpec, flags map[strin
 		CustomFlags:   customflags,
 		OutDir:        outDir,
 		DesignPkgPath: designPkgPath,
+		TemplatesDir:  templatesDir,
 		debug:         debug,
 	}, nil
 }
@@ -155,6 +167,12 @@ func (m *Generator) generateToolSourceCode(pkg *codegen.Package) {
 		codegen.SimpleImport("github.com/kyokomi/goa-v1/dslengine"),
 		codegen.NewImport("_", filepath.ToSlash(m.DesignPkgPath)),
 	)
+	if m.TemplatesDir != "" || m.Jobs > 1 {
+		imports = append(imports, codegen.SimpleImport("github.com/kyokomi/goa-v1/goagen/codegen"))
+	}
+	if m.Jobs > 1 {
+		imports = append(imports, codegen.SimpleImport("os"))
+	}
 	file.WriteHeader("Code Generator", "main", imports)
 	tmpl, err := template.New("generator").Parse(mainTmpl)
 	if err != nil {
@@ -164,10 +182,12 @@ func (m *Generator) generateToolSourceCode(pkg *codegen.Package) {
 	if err != nil {
 		panic(err)
 	}
-	context := map[string]string{
+	context := map[string]interface{}{
 		"Genfunc":       m.Genfunc,
 		"DesignPackage": m.DesignPkgPath,
 		"PkgName":       pkgName,
+		"TemplatesDir":  m.TemplatesDir,
+		"Jobs":          m.Jobs,
 	}
 	if err := tmpl.Execute(file, context); err != nil {
 		panic(err) // bug
@@ -179,7 +199,7 @@ func (m *Generator) generateToolSourceCode(pkg *codegen.Package) {
 func (m *Generator) spawn(genbin string) ([]string, error) {
 	var args []string
 	for k, v := range m.Flags {
-		if k == "debug" {
+		if k == "debug" || k == "templates" {
 			continue
 		}
 		args = append(args, fmt.Sprintf("--%s=%s", k, v))
@@ -206,8 +226,22 @@ func main() {
 
 	// Now run the secondary DSLs
 	dslengine.FailOnError(dslengine.Run())
-
+{{ if .TemplatesDir }}
+	// Load the template overrides
+	dslengine.FailOnError(codegen.LoadTemplates({{ printf "%q" .TemplatesDir }}))
+{{ end }}{{ if gt .Jobs 1 }}
+	// Format the generated files in the background
+	codegen.FormatConcurrently({{ .Jobs }})
+{{ end }}
 	files, err := {{.Genfunc}}()
+{{- if gt .Jobs 1 }}
+	if ferr := codegen.WaitFormat(); err == nil && ferr != nil {
+		for _, f := range files {
+			os.RemoveAll(f)
+		}
+		err = ferr
+	}
+{{- end }}
 	dslengine.FailOnError(err)
 
 	// We're done
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// Watcher polls the Go source files of a directory tree for changes. Test files and
+	// vendored packages are ignored.
+	Watcher struct {
+		// Dir is the root of the watched directory tree.
+		Dir string
+		// Interval is the polling interval.
+		Interval time.Duration
+		files    map[string]*fileState
+	}
+
+	// Artifacts records the content of generated files.
+	Artifacts map[string]*fileState
+
+	// fileState records the modification time, size and content checksum of a file.
+	fileState struct {
+		modTime time.Time
+		size    int64
+		sum     [sha256.Size]byte
+	}
+)
+
+// NewWatcher returns a watcher of the Go source files of the given directory tree.
+func NewWatcher(dir string, interval time.Duration) (*Watcher, error) {
+	w := &Watcher{Dir: dir, Interval: interval}
+	files, err := w.scan()
+	if err != nil {
+		return nil, err
+	}
+	w.files = files
+	return w, nil
+}
+
+// Wait blocks until the content of a watched file changes or a file is added or removed and
+// returns the paths of the affected files sorted alphabetically. Files whose modification time
+// changes without their content changing are not reported. Wait returns nil if stop is closed
+// before a change is detected.
+func (w *Watcher) Wait(stop <-chan struct{}) ([]string, error) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil, nil
+		case <-ticker.C:
+		}
+		files, err := w.scan()
+		if err != nil {
+			return nil, err
+		}
+		var changed []string
+		for p, s := range files {
+			if prev, ok := w.files[p]; !ok || prev.sum != s.sum {
+				changed = append(changed, p)
+			}
+		}
+		for p := range w.files {
+			if _, ok := files[p]; !ok {
+				changed = append(changed, p)
+			}
+		}
+		w.files = files
+		if len(changed) > 0 {
+			sort.Strings(changed)
+			return changed, nil
+		}
+	}
+}
+
+// scan returns the state of the watched files, the content of a file is only read if its
+// modification time or size changed since the last scan.
+func (w *Watcher) scan() (map[string]*fileState, error) {
+	files := make(map[string]*fileState)
+	err := filepath.Walk(w.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			if path != w.Dir && (info.Name() == "vendor" || strings.HasPrefix(info.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		if prev, ok := w.files[path]; ok && prev.modTime.Equal(info.ModTime()) && prev.size == info.Size() {
+			files[path] = prev
+			return nil
+		}
+		s, err := stat(path, info)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		files[path] = s
+		return nil
+	})
+	return files, err
+}
+
+// SnapshotArtifacts records the content of the given generated files, directories are
+// walked recursively.
+func SnapshotArtifacts(paths []string) Artifacts {
+	a := make(Artifacts)
+	for _, p := range paths {
+		filepath.Walk(p, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return nil
+			}
+			if s, err := stat(path, info); err == nil {
+				a[path] = s
+			}
+			return nil
+		})
+	}
+	return a
+}
+
+// Changed compares the given generated files with the snapshot and returns the paths of the
+// files that are new or whose content changed sorted alphabetically. The modification times of
+// the files whose content did not change are restored so that tools relying on them do not
+// consider the files modified.
+func (a Artifacts) Changed(paths []string) []string {
+	var changed []string
+	for p, s := range SnapshotArtifacts(paths) {
+		prev, ok := a[p]
+		if !ok || prev.sum != s.sum {
+			changed = append(changed, p)
+			continue
+		}
+		os.Chtimes(p, prev.modTime, prev.modTime)
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// stat returns the state of the file at the given path.
+func stat(path string, info os.FileInfo) (*fileState, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &fileState{modTime: info.ModTime(), size: info.Size(), sum: sha256.Sum256(b)}, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kyokomi/goa-v1/goagen/codegen"
+	"github.com/kyokomi/goa-v1/goagen/utils"
+	"github.com/spf13/cobra"
+)
+
+// watchDesign polls the Go files of the design package and calls regen each time they change
+// until stop is closed. files lists the artifacts produced by the initial generation. Only the
+// artifacts whose content changed are reported, DSL and compilation errors are printed and do
+// not stop the watch.
+func watchDesign(designPkg string, interval time.Duration, files []string, regen func() ([]string, error), stop <-chan struct{}) error {
+	if designPkg == "" {
+		return fmt.Errorf("missing design package path, specify it with --design")
+	}
+	dir, err := codegen.PackageSourcePath(designPkg)
+	if err != nil {
+		return fmt.Errorf("invalid design package import path: %s", err)
+	}
+	w, err := utils.NewWatcher(dir, interval)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "watching %s for changes, press Ctrl-C to stop\n", dir)
+	for {
+		changed, err := w.Wait(stop)
+		if err != nil {
+			return err
+		}
+		if changed == nil {
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "%s changed, regenerating\n", strings.Join(relPaths(changed), ", "))
+		before := utils.SnapshotArtifacts(files)
+		generated, err := regen()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			continue
+		}
+		files = generated
+		updated := before.Changed(generated)
+		if len(updated) == 0 {
+			fmt.Fprintln(os.Stderr, "no artifact changed")
+			continue
+		}
+		fmt.Println(strings.Join(relPaths(updated), "\n"))
+	}
+}
+
+// isGenerationCommand returns true if the given command generates artifacts.
+func isGenerationCommand(cmd *cobra.Command) bool {
+	if cmd == nil || cmd.Run == nil {
+		return false
+	}
+	switch cmd.Name() {
+	case "version", "commands", "help":
+		return false
+	}
+	return true
+}
+
+// printFiles prints the paths of the generated files or the generation error.
+func printFiles(files []string, err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return
+	}
+	fmt.Println(strings.Join(relPaths(files), "\n"))
+}
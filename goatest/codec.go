@@ -0,0 +1,85 @@
+package goatest
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/kyokomi/goa-v1"
+)
+
+// Codec describes how the generated test helpers encode the request payloads and response media
+// types. By default the helpers hand the payloads to the controllers and return the media types
+// as is, use WithCodec to exercise the encoding of services that consume or produce formats
+// other than JSON, e.g.:
+//
+//	ctx := goatest.WithCodec(context.Background(), &goatest.Codec{
+//		ContentType: "application/msgpack",
+//		NewEncoder:  msgpack.NewEncoder,
+//		NewDecoder:  msgpack.NewDecoder,
+//	})
+//	test.CreateBottleCreated(t, ctx, nil, ctrl, payload)
+type Codec struct {
+	// ContentType is the value of the Content-Type and Accept headers of the requests.
+	ContentType string
+	// NewEncoder creates the encoder used to write the request bodies and to encode the
+	// response media types.
+	NewEncoder goa.EncoderFunc
+	// NewDecoder creates the decoder registered with the service to read the request bodies
+	// and used to decode the response media types.
+	NewDecoder goa.DecoderFunc
+}
+
+// JSONCodec is the codec of the "application/json" content type. The Serve test helpers use it to
+// encode the request payloads when the context holds no codec.
+var JSONCodec = &Codec{
+	ContentType: "application/json",
+	NewEncoder:  goa.NewJSONEncoder,
+	NewDecoder:  goa.NewJSONDecoder,
+}
+
+// codecKey is the context key used to store the codec.
+type codecKey struct{}
+
+// WithCodec returns a context that makes the test helpers called with it use the given codec.
+func WithCodec(ctx context.Context, c *Codec) context.Context {
+	return context.WithValue(ctx, codecKey{}, c)
+}
+
+// ContextCodec returns the codec stored in ctx by WithCodec, nil if ctx is nil or holds no codec.
+func ContextCodec(ctx context.Context) *Codec {
+	if ctx == nil {
+		return nil
+	}
+	c, _ := ctx.Value(codecKey{}).(*Codec)
+	return c
+}
+
+// Register registers the codec decoder with the service for the codec content type.
+func (c *Codec) Register(service *goa.Service) {
+	service.Decoder.Register(c.NewDecoder, c.ContentType)
+}
+
+// EncodeRequest sets the body of req to the encoding of payload and sets its Content-Type and
+// Accept headers to the codec content type.
+func (c *Codec) EncodeRequest(req *http.Request, payload interface{}) error {
+	var buf bytes.Buffer
+	if err := c.NewEncoder(&buf).Encode(payload); err != nil {
+		return err
+	}
+	req.Body = ioutil.NopCloser(&buf)
+	req.ContentLength = int64(buf.Len())
+	req.Header.Set("Content-Type", c.ContentType)
+	req.Header.Set("Accept", c.ContentType)
+	return nil
+}
+
+// RoundTrip encodes v and decodes the result into target.
+func (c *Codec) RoundTrip(v, target interface{}) error {
+	var buf bytes.Buffer
+	if err := c.NewEncoder(&buf).Encode(v); err != nil {
+		return err
+	}
+	return c.NewDecoder(&buf).Decode(target)
+}
@@ -0,0 +1,160 @@
+package goatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/kyokomi/goa-v1"
+)
+
+type (
+	// jsonEmbedded is embedded in jsonValue to check the promotion of the embedded fields.
+	jsonEmbedded struct {
+		Embedded string `json:"embedded"`
+	}
+
+	// jsonValue exercises the struct field tags and types handled by encoding/json.
+	jsonValue struct {
+		jsonEmbedded
+		String   string `json:"string"`
+		Omitted  string `json:"omitted,omitempty"`
+		Ignored  string `json:"-"`
+		Untagged string
+		Int      int64             `json:"int"`
+		Uint     uint8             `json:"uint"`
+		Float    float64           `json:"float"`
+		Bool     bool              `json:"bool"`
+		Quoted   int               `json:"quoted,string"`
+		Pointer  *string           `json:"pointer"`
+		NilSlice []int             `json:"nil_slice"`
+		Bytes    []byte            `json:"bytes"`
+		Time     time.Time         `json:"time"`
+		Map      map[string]int    `json:"map"`
+		Nested   *jsonValue        `json:"nested,omitempty"`
+		Any      interface{}       `json:"any"`
+		Raw      json.RawMessage   `json:"raw"`
+		Strings  map[string]string `json:"strings,omitempty"`
+		private  string
+	}
+)
+
+// jsonUnmarshalCases lists the documents decoded by CheckJSONCodec indexed by description.
+var jsonUnmarshalCases = map[string]string{
+	"object":                `{"embedded":"e","string":"s","Untagged":"u","int":-42,"uint":255,"float":0.1,"bool":true,"quoted":"7","pointer":"p","nil_slice":[1,2],"bytes":"AQID","time":"2020-01-02T03:04:05.123+09:00","map":{"b":2,"a":1},"nested":{"string":"n"},"any":{"a":[1,2.5,"x",true,null,{}]},"raw":{"a" : 1}}`,
+	"case insensitive keys": `{"STRING":"s","Int":1,"untagged":"u"}`,
+	"unknown keys":          `{"unknown":{"a":[1]},"string":"s"}`,
+	"null values":           `{"string":null,"pointer":null,"map":null,"any":null}`,
+	"escaped strings":       `{"string":"\u00e9\u2028\ud83d\ude00\"\\\/\n"}`,
+	"type mismatch":         `{"int":"1"}`,
+	"overflow":              `{"uint":256}`,
+	"invalid quoted":        `{"quoted":7}`,
+	"invalid document":      `{"string":`,
+	"trailing garbage":      `{"string":"s"}}`,
+	"empty document":        ``,
+}
+
+// CheckJSONCodec verifies that the given codec encodes and decodes values like the encoding/json
+// package of the standard library, it reports the differences to t. Use it to check a codec before
+// setting it with goa.SetJSONCodec:
+//
+//	func TestJSONCodec(t *testing.T) {
+//		goatest.CheckJSONCodec(t, jsoniterCodec{})
+//	}
+//
+// The error messages are not compared, only whether an error occurs.
+func CheckJSONCodec(t TInterface, c goa.JSONCodec) {
+	std := goa.NewStdJSONCodec()
+	values := jsonValues()
+
+	for _, v := range values {
+		want, wantErr := std.Marshal(v)
+		got, err := c.Marshal(v)
+		if (err != nil) != (wantErr != nil) {
+			t.Errorf("Marshal(%#v): got error %v, want %v", v, err, wantErr)
+		} else if !bytes.Equal(got, want) {
+			t.Errorf("Marshal(%#v):\ngot  %s\nwant %s", v, got, want)
+		}
+	}
+
+	var want, got bytes.Buffer
+	stdEnc, enc := std.NewEncoder(&want), c.NewEncoder(&got)
+	for _, v := range values {
+		wantErr, err := stdEnc.Encode(v), enc.Encode(v)
+		if (err != nil) != (wantErr != nil) {
+			t.Errorf("Encode(%#v): got error %v, want %v", v, err, wantErr)
+		}
+	}
+	if got.String() != want.String() {
+		t.Errorf("Encode:\ngot  %s\nwant %s", got.String(), want.String())
+	}
+
+	for desc, doc := range jsonUnmarshalCases {
+		var wantV, gotV jsonValue
+		wantErr, err := std.Unmarshal([]byte(doc), &wantV), c.Unmarshal([]byte(doc), &gotV)
+		checkJSONDecoded(t, "Unmarshal "+desc, &gotV, &wantV, err, wantErr)
+
+		var wantI, gotI interface{}
+		wantErr, err = std.Unmarshal([]byte(doc), &wantI), c.Unmarshal([]byte(doc), &gotI)
+		checkJSONDecoded(t, "Unmarshal "+desc+" into interface{}", gotI, wantI, err, wantErr)
+	}
+
+	stream := `{"string":"a"} {"string":"b"}` + "\n" + `[1,"2"]`
+	stdDec, dec := std.NewDecoder(strings.NewReader(stream)), c.NewDecoder(strings.NewReader(stream))
+	for i := 0; i < 2; i++ {
+		var wantV, gotV jsonValue
+		wantErr, err := stdDec.Decode(&wantV), dec.Decode(&gotV)
+		checkJSONDecoded(t, "Decode stream", &gotV, &wantV, err, wantErr)
+	}
+	var wantI, gotI interface{}
+	wantErr, err := stdDec.Decode(&wantI), dec.Decode(&gotI)
+	checkJSONDecoded(t, "Decode stream into interface{}", gotI, wantI, err, wantErr)
+}
+
+// checkJSONDecoded reports the differences between the values decoded by a codec and by
+// encoding/json.
+func checkJSONDecoded(t TInterface, desc string, got, want interface{}, err, wantErr error) {
+	if (err != nil) != (wantErr != nil) {
+		t.Errorf("%s: got error %v, want %v", desc, err, wantErr)
+		return
+	}
+	if wantErr == nil && !reflect.DeepEqual(got, want) {
+		t.Errorf("%s:\ngot  %#v\nwant %#v", desc, got, want)
+	}
+}
+
+// jsonValues returns the values encoded by CheckJSONCodec.
+func jsonValues() []interface{} {
+	p := "<a href=\"x\">&</a>"
+	v := &jsonValue{
+		jsonEmbedded: jsonEmbedded{Embedded: "e"},
+		String:       "é\u2028😀\"\\/\n\t\x01",
+		Ignored:      "ignored",
+		Untagged:     "u",
+		Int:          -1 << 53,
+		Uint:         255,
+		Float:        1e21,
+		Bool:         true,
+		Quoted:       7,
+		Pointer:      &p,
+		Bytes:        []byte{1, 2, 3},
+		Time:         time.Date(2020, 1, 2, 3, 4, 5, 123000000, time.FixedZone("JST", 9*3600)),
+		Map:          map[string]int{"b": 2, "a": 1, "c": 3},
+		Nested:       &jsonValue{String: "nested", Float: 0.1},
+		Any:          map[string]interface{}{"z": []interface{}{1, 2.5, "x", nil}, "a": false},
+		Raw:          json.RawMessage(`{"a" : 1}`),
+		Strings:      map[string]string{},
+		private:      "private",
+	}
+	return []interface{}{
+		v,
+		jsonValue{},
+		map[string]interface{}{"b": 1.5e-7, "a": []byte("x"), "c": nil, "<": ">"},
+		[]interface{}{1, -0.0, 100000000000000000000.0, 1e-6, 1e-7, "", []int(nil), []int{}},
+		nil,
+		"string",
+		&goa.ErrorResponse{ID: "id", Code: "code", Status: 400, Detail: "detail"},
+	}
+}
@@ -0,0 +1,69 @@
+package goatest
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"path/filepath"
+	"sort"
+)
+
+// MultipartBody returns the multipart/form-data encoding of the given form values and files and
+// its content type. files maps the form field names to the content of the files, nil readers are
+// skipped. The part filename is the base name of the reader if it has a Name method, e.g.
+// *os.File, the form field name otherwise.
+func MultipartBody(values url.Values, files map[string]io.Reader) (*bytes.Buffer, string, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, name := range sortedKeys(files) {
+		if files[name] == nil {
+			continue
+		}
+		if err := writeFilePart(w, name, files[name]); err != nil {
+			return nil, "", err
+		}
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range values[name] {
+			if err := w.WriteField(name, v); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return &body, w.FormDataContentType(), nil
+}
+
+// writeFilePart writes the content of r as the file part of the form field name.
+func writeFilePart(w *multipart.Writer, name string, r io.Reader) error {
+	filename := name
+	if n, ok := r.(interface {
+		Name() string
+	}); ok {
+		filename = filepath.Base(n.Name())
+	}
+	part, err := w.CreateFormFile(name, filename)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, r)
+	return err
+}
+
+// sortedKeys returns the keys of files in alphabetical order.
+func sortedKeys(files map[string]io.Reader) []string {
+	keys := make([]string, 0, len(files))
+	for k := range files {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
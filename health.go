@@ -0,0 +1,151 @@
+package goa
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	// HealthCheck is a named check run by the readiness endpoint of a HealthController.
+	HealthCheck struct {
+		// Name identifies the check in the readiness report, e.g. "database".
+		Name string
+		// Check returns an error if the checked dependency is not ready to serve requests.
+		Check func(context.Context) error
+	}
+
+	// HealthController serves the liveness and readiness endpoints of a service. The liveness
+	// endpoint always responds with 200 while the readiness endpoint runs the health checks and
+	// responds with 503 if any of them fails.
+	HealthController struct {
+		// LivenessPath is the path of the liveness endpoint, "/health/live" by default.
+		LivenessPath string
+		// ReadinessPath is the path of the readiness endpoint, "/health/ready" by default.
+		ReadinessPath string
+		// Timeout is the maximum duration of the readiness checks, 5 seconds by default.
+		Timeout time.Duration
+		// Instrumented enables the access logging and metrics middleware for the health
+		// endpoints. It is false by default so that probes do not flood the logs.
+		Instrumented bool
+
+		mu     sync.RWMutex
+		checks []HealthCheck
+	}
+
+	// HealthReport is the body of the responses of the health endpoints.
+	HealthReport struct {
+		// Status is "ok" if the service is healthy, "unavailable" otherwise.
+		Status string `json:"status"`
+		// Checks maps the names of the health checks to "ok" or to the message of the
+		// error they returned.
+		Checks map[string]string `json:"checks,omitempty"`
+	}
+)
+
+const (
+	// HealthOK is the status of a healthy service or check.
+	HealthOK = "ok"
+	// HealthUnavailable is the status of a service with failed health checks.
+	HealthUnavailable = "unavailable"
+)
+
+// NewHealthController creates a controller that serves the liveness and readiness endpoints of
+// a service given the checks run by the readiness endpoint. Mount the controller with:
+//
+//	goa.NewHealthController(
+//		goa.HealthCheck{Name: "database", Check: func(ctx context.Context) error {
+//			return db.PingContext(ctx)
+//		}},
+//	).Mount(service)
+func NewHealthController(checks ...HealthCheck) *HealthController {
+	return &HealthController{
+		LivenessPath:  "/health/live",
+		ReadinessPath: "/health/ready",
+		Timeout:       5 * time.Second,
+		checks:        checks,
+	}
+}
+
+// AddCheck adds a check run by the readiness endpoint.
+func (h *HealthController) AddCheck(check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, check)
+}
+
+// Mount mounts the health endpoints on the given service.
+func (h *HealthController) Mount(service *Service) {
+	ctrl := service.NewController("Health")
+	if !h.Instrumented {
+		ctrl.Context = WithoutInstrumentation(ctrl.Context)
+	}
+	ctrl.NameRoute("GET", h.LivenessPath, "live")
+	service.Mux.Handle("GET", h.LivenessPath, ctrl.MuxHandler("live", h.live, nil))
+	service.LogInfo("mount", "ctrl", "Health", "action", "Live", "route", "GET "+h.LivenessPath)
+	ctrl.NameRoute("GET", h.ReadinessPath, "ready")
+	service.Mux.Handle("GET", h.ReadinessPath, ctrl.MuxHandler("ready", h.ready, nil))
+	service.LogInfo("mount", "ctrl", "Health", "action", "Ready", "route", "GET "+h.ReadinessPath)
+}
+
+// Check runs the health checks concurrently and returns the resulting report.
+func (h *HealthController) Check(ctx context.Context) *HealthReport {
+	h.mu.RLock()
+	checks := h.checks
+	h.mu.RUnlock()
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+	var (
+		wg      sync.WaitGroup
+		results = make([]string, len(checks))
+	)
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c HealthCheck) {
+			defer wg.Done()
+			results[i] = HealthOK
+			if err := c.Check(ctx); err != nil {
+				results[i] = err.Error()
+			}
+		}(i, c)
+	}
+	wg.Wait()
+	report := &HealthReport{Status: HealthOK}
+	if len(checks) > 0 {
+		report.Checks = make(map[string]string, len(checks))
+	}
+	for i, c := range checks {
+		report.Checks[c.Name] = results[i]
+		if results[i] != HealthOK {
+			report.Status = HealthUnavailable
+		}
+	}
+	return report
+}
+
+// live is the handler of the liveness endpoint.
+func (h *HealthController) live(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+	return writeHealthReport(rw, &HealthReport{Status: HealthOK})
+}
+
+// ready is the handler of the readiness endpoint.
+func (h *HealthController) ready(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+	return writeHealthReport(rw, h.Check(ctx))
+}
+
+// writeHealthReport writes the JSON representation of the given report to the response, the
+// response status is 503 if the report status is not ok.
+func writeHealthReport(rw http.ResponseWriter, report *HealthReport) error {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set("Cache-Control", "no-store")
+	status := http.StatusOK
+	if report.Status != HealthOK {
+		status = http.StatusServiceUnavailable
+	}
+	rw.WriteHeader(status)
+	return GetJSONCodec().NewEncoder(rw).Encode(report)
+}
@@ -0,0 +1,82 @@
+package goa_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1"
+)
+
+var _ = Describe("HealthController", func() {
+	var service *goa.Service
+	var health *goa.HealthController
+	var checkErr error
+	var rw *httptest.ResponseRecorder
+	var report goa.HealthReport
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		checkErr = nil
+		health = goa.NewHealthController(goa.HealthCheck{
+			Name:  "database",
+			Check: func(context.Context) error { return checkErr },
+		})
+		health.AddCheck(goa.HealthCheck{
+			Name:  "upstream",
+			Check: func(context.Context) error { return nil },
+		})
+		health.Mount(service)
+	})
+
+	serve := func(path string) {
+		rw = httptest.NewRecorder()
+		service.Mux.ServeHTTP(rw, httptest.NewRequest("GET", path, nil))
+		report = goa.HealthReport{}
+		Ω(json.Unmarshal(rw.Body.Bytes(), &report)).ShouldNot(HaveOccurred())
+	}
+
+	It("serves the liveness endpoint", func() {
+		serve("/health/live")
+		Ω(rw.Code).Should(Equal(200))
+		Ω(rw.Header().Get("Content-Type")).Should(Equal("application/json"))
+		Ω(report.Status).Should(Equal(goa.HealthOK))
+	})
+
+	It("reports the checks in the readiness endpoint", func() {
+		serve("/health/ready")
+		Ω(rw.Code).Should(Equal(200))
+		Ω(report.Status).Should(Equal(goa.HealthOK))
+		Ω(report.Checks).Should(Equal(map[string]string{"database": "ok", "upstream": "ok"}))
+	})
+
+	Context("with a failing check", func() {
+		BeforeEach(func() {
+			checkErr = errors.New("connection refused")
+		})
+
+		It("responds with 503", func() {
+			serve("/health/ready")
+			Ω(rw.Code).Should(Equal(503))
+			Ω(report.Status).Should(Equal(goa.HealthUnavailable))
+			Ω(report.Checks).Should(Equal(map[string]string{"database": "connection refused", "upstream": "ok"}))
+		})
+	})
+
+	It("excludes the requests from instrumentation", func() {
+		var instrumented bool
+		service.Use(func(h goa.Handler) goa.Handler {
+			return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				instrumented = goa.ContextInstrumented(ctx)
+				return h(ctx, rw, req)
+			}
+		})
+		serve("/health/live")
+		Ω(instrumented).Should(BeFalse())
+	})
+})
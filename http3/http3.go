@@ -0,0 +1,87 @@
+/*
+Package http3 serves goa services over HTTP/3 using github.com/quic-go/quic-go. It lives in its own
+module so that the goa module does not depend on quic-go and its requirements.
+
+The integration is provided by the ListenAndServe function rather than by a
+Service.ListenAndServeHTTP3 method built with a build tag: a goa file importing this package would
+create an import cycle and a goa file importing quic-go, even behind a build tag, would add quic-go
+to the requirements of the goa module. Use ListenAndServe in place of the method:
+
+	if err := http3.ListenAndServe(service, ":443", "cert.pem", "key.pem"); err != http.ErrServerClosed {
+		service.LogError("startup", "err", err)
+	}
+*/
+package http3
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+
+	"github.com/kyokomi/goa-v1"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// server adapts the HTTP/3 server to goa.ServerCloser, the HTTP/3 connections are closed
+// immediately on shutdown.
+type server struct {
+	*http3.Server
+}
+
+// ListenAndServe starts a HTTP/3 server listening on the given UDP host/port alongside a HTTPS
+// server listening on the same TCP host/port. The HTTPS responses include the Alt-Svc header that
+// advertises the HTTP/3 endpoint to the clients.
+//
+// Both servers use the handler and the TLS configuration of the service server, see
+// goa.Service.ConfigureTLS, the HTTPS server also uses its timeouts. The servers stop when the
+// service Shutdown method is called or when one of them fails.
+func ListenAndServe(service *goa.Service, addr, certFile, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	service.ConfigureTLS()
+	tlsConfig := service.Server.TLSConfig.Clone()
+	tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+
+	handler := service.Server.Handler
+	h3 := &http3.Server{Addr: addr, Handler: handler, TLSConfig: tlsConfig.Clone()}
+	srv := &http.Server{
+		Addr: addr,
+		Handler: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			h3.SetQuicHeaders(rw.Header())
+			handler.ServeHTTP(rw, req)
+		}),
+		TLSConfig:         tlsConfig,
+		ReadTimeout:       service.Server.ReadTimeout,
+		ReadHeaderTimeout: service.Server.ReadHeaderTimeout,
+		WriteTimeout:      service.Server.WriteTimeout,
+		IdleTimeout:       service.Server.IdleTimeout,
+		MaxHeaderBytes:    service.Server.MaxHeaderBytes,
+		ErrorLog:          service.Server.ErrorLog,
+	}
+
+	return service.ServeServers(func() error {
+		errc := make(chan error, 1)
+		go func() {
+			service.LogInfo("listen", "transport", "http3", "addr", addr)
+			err := h3.ListenAndServe()
+			if err != http.ErrServerClosed {
+				srv.Close()
+			}
+			errc <- err
+		}()
+		service.LogInfo("listen", "transport", "https", "addr", addr)
+		err := srv.ListenAndServeTLS("", "")
+		h3.Close()
+		if h3err := <-errc; err == http.ErrServerClosed && h3err != http.ErrServerClosed {
+			err = h3err
+		}
+		return err
+	}, srv, server{h3})
+}
+
+// Shutdown closes the HTTP/3 server, quic-go does not implement graceful shutdowns yet.
+func (s server) Shutdown(context.Context) error {
+	return s.Close()
+}
@@ -0,0 +1,13 @@
+package http3_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestHTTP3(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "HTTP3 Suite")
+}
@@ -0,0 +1,95 @@
+package http3_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	quichttp3 "github.com/quic-go/quic-go/http3"
+
+	"github.com/kyokomi/goa-v1"
+	"github.com/kyokomi/goa-v1/http3"
+)
+
+var _ = Describe("ListenAndServe", func() {
+	var service *goa.Service
+	var addr, certFile, keyFile string
+	var done chan error
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		ctrl := service.NewController("test")
+		service.Mux.Handle("GET", "/", ctrl.MuxHandler("show", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			rw.WriteHeader(200)
+			_, err := rw.Write([]byte(req.Proto))
+			return err
+		}, nil))
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Ω(err).ShouldNot(HaveOccurred())
+		addr = l.Addr().String()
+		l.Close()
+		certFile, keyFile = writeTestCertificate()
+		done = make(chan error)
+		go func() { done <- http3.ListenAndServe(service, addr, certFile, keyFile) }()
+	})
+
+	AfterEach(func() {
+		Ω(service.Shutdown(context.Background())).ShouldNot(HaveOccurred())
+		Eventually(done).Should(Receive(Equal(http.ErrServerClosed)))
+		os.RemoveAll(filepath.Dir(certFile))
+	})
+
+	It("serves HTTP/3 and advertises it over TCP", func() {
+		tlsConfig := &tls.Config{InsecureSkipVerify: true}
+		var resp *http.Response
+		Eventually(func() error {
+			var err error
+			c := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+			resp, err = c.Get("https://" + addr)
+			return err
+		}).ShouldNot(HaveOccurred())
+		Ω(resp.Header.Get("Alt-Svc")).Should(ContainSubstring("h3="))
+
+		rt := &quichttp3.RoundTripper{TLSClientConfig: tlsConfig}
+		defer rt.Close()
+		resp, err := (&http.Client{Transport: rt}).Get("https://" + addr)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.ProtoMajor).Should(Equal(3))
+	})
+})
+
+// writeTestCertificate writes a self-signed certificate and its key to a temporary directory.
+func writeTestCertificate() (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Ω(err).ShouldNot(HaveOccurred())
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	Ω(err).ShouldNot(HaveOccurred())
+	kder, err := x509.MarshalECPrivateKey(key)
+	Ω(err).ShouldNot(HaveOccurred())
+	dir, err := os.MkdirTemp("", "goa-http3")
+	Ω(err).ShouldNot(HaveOccurred())
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	Ω(os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)).ShouldNot(HaveOccurred())
+	Ω(os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kder}), 0600)).ShouldNot(HaveOccurred())
+	return certFile, keyFile
+}
@@ -0,0 +1,62 @@
+package goa
+
+import (
+	"encoding/json"
+	"io"
+	"sync/atomic"
+)
+
+// JSONCodec is the interface implemented by the JSON implementations used by goa to encode and
+// decode JSON, see SetJSONCodec.
+type JSONCodec interface {
+	// Marshal returns the JSON encoding of v.
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal decodes the JSON data into v.
+	Unmarshal(data []byte, v interface{}) error
+	// NewEncoder returns an encoder that writes the JSON encoding of values to w, each
+	// followed by a newline.
+	NewEncoder(w io.Writer) Encoder
+	// NewDecoder returns a decoder that reads JSON values from r.
+	NewDecoder(r io.Reader) Decoder
+}
+
+// jsonCodec contains the current JSON codec wrapped in a jsonCodecValue so that codecs of
+// different types can be stored.
+var jsonCodec atomic.Value
+
+// jsonCodecValue wraps the codecs stored in jsonCodec.
+type jsonCodecValue struct {
+	JSONCodec
+}
+
+func init() {
+	SetJSONCodec(NewStdJSONCodec())
+}
+
+// stdJSONCodec implements JSONCodec with the encoding/json package.
+type stdJSONCodec struct{}
+
+func (stdJSONCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (stdJSONCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (stdJSONCodec) NewEncoder(w io.Writer) Encoder             { return json.NewEncoder(w) }
+func (stdJSONCodec) NewDecoder(r io.Reader) Decoder             { return json.NewDecoder(r) }
+
+// NewStdJSONCodec returns the JSONCodec implemented with the encoding/json package of the
+// standard library, it is the default codec.
+func NewStdJSONCodec() JSONCodec {
+	return stdJSONCodec{}
+}
+
+// SetJSONCodec sets the JSON codec used by NewJSONEncoder and NewJSONDecoder, and thus by the
+// services that produce or consume "application/json", as well as by the Server-Sent Events and
+// health check responses. Use it to replace encoding/json with a faster implementation such as
+// jsoniter or sonic for all the services of the process, typically in an init function. The
+// codec must behave like encoding/json, goatest.CheckJSONCodec verifies that it does.
+func SetJSONCodec(c JSONCodec) {
+	jsonCodec.Store(jsonCodecValue{c})
+}
+
+// GetJSONCodec returns the JSON codec set with SetJSONCodec.
+func GetJSONCodec() JSONCodec {
+	return jsonCodec.Load().(jsonCodecValue).JSONCodec
+}
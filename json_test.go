@@ -0,0 +1,99 @@
+package goa_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1"
+	"github.com/kyokomi/goa-v1/goatest"
+)
+
+// countingJSONCodec counts the encoders and decoders it creates.
+type countingJSONCodec struct {
+	goa.JSONCodec
+	encoders, decoders int
+}
+
+func (c *countingJSONCodec) NewEncoder(w io.Writer) goa.Encoder {
+	c.encoders++
+	return c.JSONCodec.NewEncoder(w)
+}
+
+func (c *countingJSONCodec) NewDecoder(r io.Reader) goa.Decoder {
+	c.decoders++
+	return c.JSONCodec.NewDecoder(r)
+}
+
+// unescapedJSONCodec does not escape HTML characters unlike encoding/json.
+type unescapedJSONCodec struct {
+	goa.JSONCodec
+}
+
+func (unescapedJSONCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// recordingT records the errors reported by the conformance checks.
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Fatalf(format string, args ...interface{}) {
+	t.Errorf(format, args...)
+}
+
+var _ = Describe("JSONCodec", func() {
+	AfterEach(func() {
+		goa.SetJSONCodec(goa.NewStdJSONCodec())
+	})
+
+	It("defaults to encoding/json", func() {
+		var buf bytes.Buffer
+		Ω(goa.NewJSONEncoder(&buf).Encode(map[string]string{"a": "<b>"})).ShouldNot(HaveOccurred())
+		Ω(buf.String()).Should(Equal(`{"a":"\u003cb\u003e"}` + "\n"))
+	})
+
+	It("is used by the JSON encoders and decoders", func() {
+		codec := &countingJSONCodec{JSONCodec: goa.NewStdJSONCodec()}
+		goa.SetJSONCodec(codec)
+		Ω(goa.GetJSONCodec()).Should(Equal(codec))
+
+		var buf bytes.Buffer
+		Ω(goa.NewJSONEncoder(&buf).Encode(1)).ShouldNot(HaveOccurred())
+		var v, w, u interface{}
+		Ω(goa.NewJSONDecoder(bytes.NewBufferString("1")).Decode(&v)).ShouldNot(HaveOccurred())
+		Ω(goa.NewJSONNumberDecoder(bytes.NewBufferString("1")).Decode(&w)).ShouldNot(HaveOccurred())
+		Ω(goa.NewStrictJSONDecoder(bytes.NewBufferString("1")).Decode(&u)).ShouldNot(HaveOccurred())
+		Ω(codec.encoders).Should(Equal(1))
+		Ω(codec.decoders).Should(Equal(3))
+		Ω(w).Should(Equal(json.Number("1")))
+	})
+
+	It("conforms to encoding/json", func() {
+		t := &recordingT{}
+		goatest.CheckJSONCodec(t, goa.NewStdJSONCodec())
+		Ω(t.errors).Should(BeEmpty())
+	})
+
+	It("reports the differences with encoding/json", func() {
+		t := &recordingT{}
+		goatest.CheckJSONCodec(t, unescapedJSONCodec{goa.NewStdJSONCodec()})
+		Ω(t.errors).ShouldNot(BeEmpty())
+		Ω(t.errors[0]).Should(HavePrefix("Marshal("))
+	})
+})
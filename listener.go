@@ -0,0 +1,238 @@
+package goa
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd, see sd_listen_fds(3).
+const listenFDsStart = 3
+
+// ListenAndServeUnix starts a HTTP server listening on the unix domain socket at the given path.
+// It removes any stale socket file at the path first and sets the socket file permissions to
+// mode, e.g. 0660 to restrict access to the members of the group. The socket file is removed
+// when the server stops.
+func (service *Service) ListenAndServeUnix(path string, mode os.FileMode) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return err
+	}
+	return service.Serve(l)
+}
+
+// SystemdListeners returns the listeners passed to the process by systemd socket activation
+// indexed by the names set with FileDescriptorName in the socket units ("unknown" by default).
+// It returns an empty map if the process was not socket activated. The listeners are typically
+// given to Serve:
+//
+//	ls, err := goa.SystemdListeners()
+//	if err != nil {
+//		return err
+//	}
+//	if l, ok := ls["http"]; ok {
+//		return service.Serve(l)
+//	}
+func SystemdListeners() (map[string]net.Listener, error) {
+	listeners := make(map[string]net.Listener)
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return listeners, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LISTEN_FDS: %s", err)
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	for i := 0; i < n; i++ {
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(listenFDsStart+i), name)
+		l, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("socket %q: %s", name, err)
+		}
+		listeners[name] = l
+	}
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	return listeners, nil
+}
+
+// listener is a listener added with AddListener.
+type (
+	// ServerCloser is the interface implemented by the servers given to ServeServers, e.g.
+	// *http.Server.
+	ServerCloser interface {
+		// Shutdown gracefully shuts down the server.
+		Shutdown(context.Context) error
+		// Close closes the server immediately.
+		Close() error
+	}
+
+	listener struct {
+		name   string
+		l      net.Listener
+		server *http.Server
+	}
+)
+
+// AddListener adds a listener served alongside the main listener by ListenAndServe,
+// ListenAndServeTLS, Serve and Run, e.g. an internal listener for health checks next to the
+// public HTTPS listener. Wrap the listener with tls.NewListener to serve HTTPS. The listener
+// servers use the handler and timeouts of the service server, they are shut down with it.
+// The name identifies the listener in the request contexts, see ContextListener and
+// SkipOnListeners.
+func (service *Service) AddListener(name string, l net.Listener) {
+	service.lmu.Lock()
+	defer service.lmu.Unlock()
+	service.listeners = append(service.listeners, &listener{name: name, l: l})
+}
+
+// ContextListener returns the name of the listener that received the request, empty for the
+// main listener of the service.
+func ContextListener(ctx context.Context) string {
+	if n := ctx.Value(listenerKey); n != nil {
+		return n.(string)
+	}
+	return ""
+}
+
+// SkipOnListeners returns a middleware that invokes m except for the requests received on the
+// listeners with the given names, e.g. to skip authentication on an internal listener:
+//
+//	service.Use(goa.SkipOnListeners(jwt.New(...), "internal"))
+func SkipOnListeners(m Middleware, names ...string) Middleware {
+	return func(h Handler) Handler {
+		mh := m(h)
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			l := ContextListener(ctx)
+			for _, n := range names {
+				if n == l {
+					return h(ctx, rw, req)
+				}
+			}
+			return mh(ctx, rw, req)
+		}
+	}
+}
+
+// serveListeners serves the additional listeners with servers configured like the service
+// server. All the servers are closed if one of them fails. serveListeners returns a function
+// that waits for the servers to stop and returns the first error other than
+// http.ErrServerClosed.
+func (service *Service) serveListeners() func() error {
+	service.lmu.Lock()
+	defer service.lmu.Unlock()
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(service.listeners))
+	)
+	for i, ln := range service.listeners {
+		ln.server = &http.Server{
+			Handler:           service.Server.Handler,
+			ReadTimeout:       service.Server.ReadTimeout,
+			ReadHeaderTimeout: service.Server.ReadHeaderTimeout,
+			WriteTimeout:      service.Server.WriteTimeout,
+			IdleTimeout:       service.Server.IdleTimeout,
+			MaxHeaderBytes:    service.Server.MaxHeaderBytes,
+			ErrorLog:          service.Server.ErrorLog,
+			BaseContext: func(name string) func(net.Listener) context.Context {
+				return func(net.Listener) context.Context {
+					return context.WithValue(context.Background(), listenerKey, name)
+				}
+			}(ln.name),
+		}
+		wg.Add(1)
+		go func(i int, ln *listener) {
+			defer wg.Done()
+			service.LogInfo("listen", "listener", ln.name, "transport", ln.l.Addr().Network(), "addr", ln.l.Addr().String())
+			if err := ln.server.Serve(ln.l); err != http.ErrServerClosed {
+				service.LogError("listener", "name", ln.name, "err", err)
+				errs[i] = err
+				service.closeServers()
+			}
+		}(i, ln)
+	}
+	return func() error {
+		wg.Wait()
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// ServeServers runs the start hooks then serve while serving the listeners added with
+// AddListener, like ListenAndServe does with the service server. serve must run the given servers
+// and return once they stop. Shutdown gracefully shuts the servers down and they are closed when
+// serve or one of the listeners fails.
+// ServeServers makes it possible to serve the service with other servers, see for example the
+// github.com/kyokomi/goa-v1/http3 module.
+func (service *Service) ServeServers(serve func() error, servers ...ServerCloser) error {
+	service.lmu.Lock()
+	service.extServers = servers
+	service.lmu.Unlock()
+	return service.serve(serve)
+}
+
+// servers returns the service server, the servers given to ServeServers and the servers of the
+// additional listeners.
+func (service *Service) servers() []ServerCloser {
+	service.lmu.Lock()
+	defer service.lmu.Unlock()
+	servers := []ServerCloser{service.Server}
+	servers = append(servers, service.extServers...)
+	for _, ln := range service.listeners {
+		if ln.server != nil {
+			servers = append(servers, ln.server)
+		}
+	}
+	return servers
+}
+
+// shutdownServers gracefully shuts down all the servers concurrently and returns the first error.
+func (service *Service) shutdownServers(ctx context.Context) error {
+	servers := service.servers()
+	errs := make([]error, len(servers))
+	var wg sync.WaitGroup
+	for i, s := range servers {
+		wg.Add(1)
+		go func(i int, s ServerCloser) {
+			defer wg.Done()
+			errs[i] = s.Shutdown(ctx)
+		}(i, s)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// closeServers closes all the servers immediately.
+func (service *Service) closeServers() {
+	for _, s := range service.servers() {
+		s.Close()
+	}
+}
@@ -0,0 +1,177 @@
+package goa_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1"
+)
+
+var _ = Describe("ListenAndServeUnix", func() {
+	var service *goa.Service
+	var dir, path string
+	var done chan error
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		ctrl := service.NewController("test")
+		service.Mux.Handle("GET", "/", ctrl.MuxHandler("show", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			rw.WriteHeader(200)
+			_, err := rw.Write([]byte("ok"))
+			return err
+		}, nil))
+		var err error
+		dir, err = ioutil.TempDir("", "goa-unix")
+		Ω(err).ShouldNot(HaveOccurred())
+		path = filepath.Join(dir, "goa.sock")
+		Ω(ioutil.WriteFile(path, nil, 0600)).ShouldNot(HaveOccurred()) // stale socket file
+		done = make(chan error)
+		go func() { done <- service.ListenAndServeUnix(path, 0660) }()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("serves requests on the socket", func() {
+		client := &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		}}
+		var resp *http.Response
+		Eventually(func() error {
+			var err error
+			resp, err = client.Get("http://unix/")
+			return err
+		}).ShouldNot(HaveOccurred())
+		body, err := ioutil.ReadAll(resp.Body)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(body)).Should(Equal("ok"))
+		info, err := os.Stat(path)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(info.Mode().Perm()).Should(Equal(os.FileMode(0660)))
+
+		Ω(service.Shutdown(context.Background())).ShouldNot(HaveOccurred())
+		Eventually(done).Should(Receive(Equal(http.ErrServerClosed)))
+		_, err = os.Stat(path)
+		Ω(os.IsNotExist(err)).Should(BeTrue())
+	})
+})
+
+var _ = Describe("SystemdListeners", func() {
+	AfterEach(func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+	})
+
+	It("returns no listener if the process was not socket activated", func() {
+		os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+		os.Setenv("LISTEN_FDS", "1")
+		ls, err := goa.SystemdListeners()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ls).Should(BeEmpty())
+	})
+
+	It("validates the number of listeners", func() {
+		os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+		os.Setenv("LISTEN_FDS", "foo")
+		_, err := goa.SystemdListeners()
+		Ω(err).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("AddListener", func() {
+	var service *goa.Service
+	var main, internal net.Listener
+	var done chan error
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.Use(goa.SkipOnListeners(func(h goa.Handler) goa.Handler {
+			return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				rw.Header().Set("X-Auth", "checked")
+				return h(ctx, rw, req)
+			}
+		}, "internal"))
+		ctrl := service.NewController("test")
+		service.Mux.Handle("GET", "/", ctrl.MuxHandler("show", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			rw.WriteHeader(200)
+			_, err := rw.Write([]byte(goa.ContextListener(ctx)))
+			return err
+		}, nil))
+		var err error
+		main, err = net.Listen("tcp", "127.0.0.1:0")
+		Ω(err).ShouldNot(HaveOccurred())
+		internal, err = net.Listen("tcp", "127.0.0.1:0")
+		Ω(err).ShouldNot(HaveOccurred())
+		service.AddListener("internal", internal)
+		done = make(chan error)
+		go func() { done <- service.Serve(main) }()
+	})
+
+	get := func(l net.Listener) (*http.Response, string) {
+		var resp *http.Response
+		Eventually(func() error {
+			var err error
+			resp, err = http.Get("http://" + l.Addr().String() + "/")
+			return err
+		}).ShouldNot(HaveOccurred())
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		Ω(err).ShouldNot(HaveOccurred())
+		return resp, string(body)
+	}
+
+	It("serves requests on all the listeners", func() {
+		resp, body := get(main)
+		Ω(body).Should(BeEmpty())
+		Ω(resp.Header.Get("X-Auth")).Should(Equal("checked"))
+
+		resp, body = get(internal)
+		Ω(body).Should(Equal("internal"))
+		Ω(resp.Header.Get("X-Auth")).Should(BeEmpty())
+
+		Ω(service.Shutdown(context.Background())).ShouldNot(HaveOccurred())
+		Eventually(done).Should(Receive(Equal(http.ErrServerClosed)))
+		_, err := http.Get("http://" + internal.Addr().String() + "/")
+		Ω(err).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("ServeServers", func() {
+	var service *goa.Service
+	var started, stopped bool
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		started, stopped = false, false
+		service.OnStart(func(context.Context) error { started = true; return nil })
+		service.OnStop(func(context.Context) error { stopped = true; return nil })
+	})
+
+	It("runs the hooks and shuts the servers down", func() {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Ω(err).ShouldNot(HaveOccurred())
+		srv := &http.Server{Handler: service.Server.Handler}
+		done := make(chan error)
+		go func() { done <- service.ServeServers(func() error { return srv.Serve(l) }, srv) }()
+		Eventually(func() error {
+			_, err := http.Get("http://" + l.Addr().String())
+			return err
+		}).ShouldNot(HaveOccurred())
+		Ω(started).Should(BeTrue())
+
+		Ω(service.Shutdown(context.Background())).ShouldNot(HaveOccurred())
+		Eventually(done).Should(Receive(Equal(http.ErrServerClosed)))
+		Ω(stopped).Should(BeTrue())
+	})
+})
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -11,9 +12,11 @@ import (
 
 // ErrorHandler turns a Go error into an HTTP response. It should be placed in the middleware chain
 // below the logger middleware so the logger properly logs the HTTP response. ErrorHandler
-// understands instances of goa.ServiceError and returns the status and response body embodied in
-// them, it turns other Go error types into a 500 internal error response.
+// understands instances of goa.ServiceError - including errors that wrap them - and returns the
+// status and response body embodied in them, it turns other Go error types into a 500 internal
+// error response.
 // If verbose is false the details of internal errors is not included in HTTP responses.
+// The responses contain problem details (RFC 7807) if the service sets a problem registry.
 // If you use github.com/pkg/errors then wrapping the error will allow a trace to be printed to the logs
 func ErrorHandler(service *goa.Service, verbose bool) goa.Middleware {
 	return func(h goa.Handler) goa.Handler {
@@ -25,7 +28,8 @@ func ErrorHandler(service *goa.Service, verbose bool) goa.Middleware {
 			cause := cause(e)
 			status := http.StatusInternalServerError
 			var respBody interface{}
-			if err, ok := cause.(goa.ServiceError); ok {
+			var err goa.ServiceError
+			if errors.As(cause, &err) {
 				status = err.ResponseStatus()
 				respBody = err
 				goa.ContextResponse(ctx).ErrorCode = err.Token()
@@ -52,6 +56,14 @@ func ErrorHandler(service *goa.Service, verbose bool) goa.Middleware {
 					}
 				}
 			}
+			if service.Problems != nil {
+				err, ok := respBody.(error)
+				if !ok {
+					err = e
+				}
+				rw.Header().Set("Content-Type", goa.ProblemMediaIdentifier)
+				respBody = service.Problems.Problem(err, req.URL.RequestURI())
+			}
 			return service.Send(ctx, status, respBody)
 		}
 	}
@@ -162,6 +162,72 @@ var _ = Describe("ErrorHandler", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(decoded.Error()).Should(Equal(gerr.Error()))
 		})
+
+		Context("wrapped with fmt.Errorf", func() {
+			BeforeEach(func() {
+				h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					return fmt.Errorf("wrapped: %w", gerr)
+				}
+			})
+
+			It("maps the wrapped goa error to the HTTP response", func() {
+				var decoded errorResponse
+				Ω(rw.Status).Should(Equal(418))
+				Ω(rw.ParentHeader["Content-Type"]).Should(Equal([]string{goa.ErrorMediaIdentifier}))
+				err := service.Decoder.Decode(&decoded, bytes.NewBuffer(rw.Body), "application/json")
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(decoded.Error()).Should(Equal(gerr.Error()))
+			})
+		})
+	})
+
+	Context("with a problem registry", func() {
+		var gerr error
+
+		BeforeEach(func() {
+			service = newService(nil)
+			service.Problems = goa.NewProblemRegistry()
+			service.Problems.Register("code", "https://example.com/problems/teapot", "Teapot")
+			gerr = goa.NewErrorClass("code", 418)("teapot", "foobar", 42)
+			h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				return gerr
+			}
+		})
+
+		It("renders problem details", func() {
+			var decoded goa.Problem
+			Ω(rw.Status).Should(Equal(418))
+			Ω(rw.ParentHeader["Content-Type"]).Should(Equal([]string{goa.ProblemMediaIdentifier}))
+			err := service.Decoder.Decode(&decoded, bytes.NewBuffer(rw.Body), "application/json")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(decoded.Type).Should(Equal("https://example.com/problems/teapot"))
+			Ω(decoded.Title).Should(Equal("Teapot"))
+			Ω(decoded.Status).Should(Equal(418))
+			Ω(decoded.Detail).Should(Equal("teapot"))
+			Ω(decoded.Instance).Should(Equal("/foo"))
+			Ω(decoded.ID).Should(Equal(gerr.(goa.ServiceError).Token()))
+			Ω(decoded.Code).Should(Equal("code"))
+			Ω(decoded.Meta).Should(HaveKeyWithValue("foobar", BeNumerically("==", 42)))
+		})
+
+		Context("and a Go error", func() {
+			BeforeEach(func() {
+				h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					return errors.New("boom")
+				}
+			})
+
+			It("renders an internal error problem", func() {
+				var decoded goa.Problem
+				Ω(rw.Status).Should(Equal(500))
+				Ω(rw.ParentHeader["Content-Type"]).Should(Equal([]string{goa.ProblemMediaIdentifier}))
+				err := service.Decoder.Decode(&decoded, bytes.NewBuffer(rw.Body), "application/json")
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(decoded.Type).Should(Equal("about:blank"))
+				Ω(decoded.Title).Should(Equal("Internal Server Error"))
+				Ω(decoded.Detail).Should(Equal("boom"))
+			})
+		})
 	})
 
 	Context("with a handler returning a pkg errors wrapped error", func() {
@@ -104,6 +104,25 @@ func (grw *gzipResponseWriter) WriteHeader(n int) {
 	grw.statusCode = n
 }
 
+// Push calls the underlying writer Push method if it implements http.Pusher.
+func (grw *gzipResponseWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := grw.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Flush flushes the compressed data written so far and calls the underlying writer Flush method
+// if it implements http.Flusher.
+func (grw *gzipResponseWriter) Flush() {
+	if grw.gzw != nil {
+		grw.gzw.Flush()
+	}
+	if f, ok := grw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 type (
 	// Option allows to override default parameters.
 	Option func(*options) error
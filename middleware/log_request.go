@@ -20,6 +20,8 @@ import (
 // This middleware is aware of the RequestID middleware and if registered after it leverages the
 // request ID for logging.
 // If verbose is true then the middlware logs the request and response bodies.
+// The middleware does not log the requests whose context was created with
+// goa.WithoutInstrumentation.
 func LogRequest(verbose bool, sensitiveHeaders ...string) goa.Middleware {
 	var suppressed map[string]struct{}
 	if len(sensitiveHeaders) > 0 {
@@ -31,6 +33,9 @@ func LogRequest(verbose bool, sensitiveHeaders ...string) goa.Middleware {
 
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if !goa.ContextInstrumented(ctx) {
+				return h(ctx, rw, req)
+			}
 			reqID := ctx.Value(reqIDKey)
 			if reqID == nil {
 				reqID = shortID()
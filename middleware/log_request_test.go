@@ -77,6 +77,16 @@ var _ = Describe("LogRequest", func() {
 		Ω(logger.InfoEntries[3].Data[11]).Should(Equal("goo"))
 	})
 
+	It("does not log uninstrumented requests", func() {
+		ctx = goa.WithoutInstrumentation(ctx)
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return service.Send(ctx, 200, "ok")
+		}
+		lg := middleware.LogRequest(true)(h)
+		Ω(lg(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(logger.InfoEntries).Should(BeEmpty())
+	})
+
 	It("logs error codes", func() {
 		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 			return goa.MissingParamError("foo")
@@ -22,11 +22,31 @@ func (lrw *loggingResponseWriter) Write(buf []byte) (int, error) {
 	return lrw.ResponseWriter.Write(buf)
 }
 
+// Push calls the underlying writer Push method if it implements http.Pusher.
+func (lrw *loggingResponseWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := lrw.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Flush calls the underlying writer Flush method if it implements http.Flusher.
+func (lrw *loggingResponseWriter) Flush() {
+	if f, ok := lrw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // LogResponse creates a response logger middleware.
 // Only Logs the raw response data without accumulating any statistics.
+// The middleware does not log the responses to requests whose context was created with
+// goa.WithoutInstrumentation.
 func LogResponse() goa.Middleware {
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if !goa.ContextInstrumented(ctx) {
+				return h(ctx, rw, req)
+			}
 			// chain a new logging writer to the current response writer.
 			resp := goa.ContextResponse(ctx)
 			resp.SwitchWriter(
@@ -1,8 +1,11 @@
 package goa
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 
 	"github.com/dimfeld/httptreemux"
 )
@@ -15,15 +18,20 @@ type (
 	// MethodNotAllowedHandler provides the implementation for an MethodNotAllowed
 	// handler. The values argument includes both the querystring and path parameter
 	// values. The methods argument includes both the allowed method identifier
-	// and the registered handler.
+	// and the registered handler. ServeMux implementations that are not backed by
+	// httptreemux set nil handlers, the keys are the only values that may be relied on.
 	MethodNotAllowedHandler func(http.ResponseWriter, *http.Request, url.Values, map[string]httptreemux.HandlerFunc)
 
 	// ServeMux is the interface implemented by the service request muxes.
 	// It implements http.Handler and makes it possible to register request handlers for
-	// specific HTTP methods and request path via the Handle method.
+	// specific HTTP methods and request path via the Handle method. The paths use the
+	// httptreemux syntax: ":name" for path parameters and "*name" for catch-all parameters.
+	// The sub-packages of the mux package contain adapters for other routers, see
+	// Service.UseMux.
 	ServeMux interface {
 		http.Handler
-		// Handle sets the MuxHandler for a given HTTP method and path.
+		// Handle sets the MuxHandler for a given HTTP method and path. Handle panics with
+		// a *RouteConflictError if the route conflicts with a route registered previously.
 		Handle(method, path string, handle MuxHandler)
 		// HandleNotFound sets the MuxHandler invoked for requests that don't match any
 		// handler registered with Handle. The values argument given to the handler is
@@ -37,25 +45,154 @@ type (
 		Lookup(method, path string) MuxHandler
 	}
 
+	// RouteLister is implemented by the ServeMux implementations that record their routes,
+	// which is the case of the muxes that embed RouteTable. Use a type assertion to list the
+	// routes of a service mux:
+	//
+	//	if l, ok := service.Mux.(goa.RouteLister); ok {
+	//		routes := l.Routes()
+	//	}
+	RouteLister interface {
+		// Routes returns the routes registered with Handle in registration order.
+		Routes() []RouteInfo
+	}
+
+	// RouteInfo describes a route registered with a ServeMux.
+	RouteInfo struct {
+		// Method is the HTTP method of the route.
+		Method string
+		// Path is the path template of the route, e.g. "/bottles/:id".
+		Path string
+		// Resource is the name of the resource that exposes the route, empty if the route
+		// was not registered by a controller.
+		Resource string
+		// Action is the name of the action that handles the route, empty if the route was
+		// not registered by a controller.
+		Action string
+		// Handler is the route handler.
+		Handler MuxHandler
+	}
+
+	// RouteTable records the routes of a ServeMux. ServeMux implementations embed it and call
+	// AddRoute from Handle to implement RouteLister. It is safe for concurrent use.
+	RouteTable struct {
+		mu     sync.RWMutex
+		routes []RouteInfo
+		names  map[string]RouteInfo
+	}
+
+	// RouteConflictError is the error produced when a route conflicts with a route registered
+	// previously: both routes have the same method and path pattern or their path patterns
+	// only differ by the names of their wildcards.
+	RouteConflictError struct {
+		// Route is the route being registered.
+		Route RouteInfo
+		// Existing is the route registered previously.
+		Existing RouteInfo
+	}
+
 	// Muxer implements an adapter that given a request handler can produce a mux handler.
+	// It also names the routes registered by the generated Mount functions, see
+	// Controller.NameRoute.
 	Muxer interface {
 		MuxHandler(string, Handler, Unmarshaler) MuxHandler
+		NameRoute(method, path, action string)
+	}
+
+	// SlashBehavior defines how the mux handles requests whose path differs from the path
+	// of a registered handler only by a trailing slash, by duplicated slashes or by the case
+	// of its static segments.
+	SlashBehavior int
+
+	// MuxOption is a constructor option that makes it possible to customize the mux.
+	MuxOption func(*muxOptions) *muxOptions
+
+	// muxOptions is the struct storing all the options.
+	muxOptions struct {
+		trailingSlash    SlashBehavior
+		duplicateSlashes *SlashBehavior
+		caseInsensitive  SlashBehavior
 	}
 
 	// mux is the default ServeMux implementation.
 	mux struct {
-		router  *httptreemux.TreeMux
-		handles map[string]MuxHandler
+		RouteTable
+		router           *httptreemux.TreeMux
+		handles          map[string]MuxHandler
+		duplicateSlashes *SlashBehavior
+		caseInsensitive  SlashBehavior
 	}
 )
 
+const (
+	// SlashRedirect redirects the requests to the path of the handler with a 301 Moved
+	// Permanently response. This is the default behavior.
+	SlashRedirect SlashBehavior = iota
+	// SlashRedirectPermanent redirects the requests to the path of the handler with a 308
+	// Permanent Redirect response which preserves the request method and body.
+	SlashRedirectPermanent
+	// SlashMatch serves the requests with the handler as if their path was the path of the
+	// handler.
+	SlashMatch
+	// SlashNotFound does not match the requests with the handler.
+	SlashNotFound
+)
+
+// TrailingSlash is a constructor option that sets how the mux handles requests whose path
+// differs from the path of a registered handler only by a trailing slash.
+func TrailingSlash(b SlashBehavior) MuxOption {
+	return func(o *muxOptions) *muxOptions {
+		o.trailingSlash = b
+		return o
+	}
+}
+
+// DuplicateSlashes is a constructor option that sets how the mux handles requests whose path
+// contains duplicated slashes, e.g. "/bottles//1". The mux redirects requests whose path
+// contains "." or ".." segments or duplicated slashes with a 301 response by default, setting
+// this option disables the handling of "." and ".." segments.
+func DuplicateSlashes(b SlashBehavior) MuxOption {
+	return func(o *muxOptions) *muxOptions {
+		o.duplicateSlashes = &b
+		return o
+	}
+}
+
+// CaseInsensitive is a constructor option that sets how the mux handles requests whose path
+// differs from the path of a registered handler only by the case of its static segments, e.g.
+// "/Bottles/1" for "/bottles/:id". The values of path parameters are left untouched. The mux
+// does not match such requests by default (SlashNotFound).
+func CaseInsensitive(b SlashBehavior) MuxOption {
+	return func(o *muxOptions) *muxOptions {
+		o.caseInsensitive = b
+		return o
+	}
+}
+
 // NewMux returns a Mux.
-func NewMux() ServeMux {
+func NewMux(options ...MuxOption) ServeMux {
+	o := &muxOptions{caseInsensitive: SlashNotFound}
+	for _, option := range options {
+		o = option(o)
+	}
 	r := httptreemux.New()
 	r.EscapeAddedRoutes = true
+	switch o.trailingSlash {
+	case SlashRedirectPermanent:
+		r.RedirectBehavior = httptreemux.Redirect308
+	case SlashMatch:
+		r.RedirectBehavior = httptreemux.UseHandler
+	case SlashNotFound:
+		r.RedirectTrailingSlash = false
+	}
+	if o.duplicateSlashes != nil {
+		r.RedirectCleanPath = false
+	}
 	return &mux{
-		router:  r,
-		handles: make(map[string]MuxHandler),
+		router:           r,
+		handles:          make(map[string]MuxHandler),
+		duplicateSlashes: o.duplicateSlashes,
+		caseInsensitive:  o.caseInsensitive,
 	}
 }
 
@@ -68,6 +205,9 @@ func (m *mux) Handle(method, path string, handle MuxHandler) {
 		}
 		handle(rw, req, params)
 	}
+	if err := m.AddRoute(method, path, handle); err != nil {
+		panic(err)
+	}
 	m.handles[method+path] = handle
 	m.router.Handle(method, path, hthandle)
 }
@@ -85,6 +225,17 @@ func (m *mux) HandleNotFound(handle MuxHandler) {
 // the path of a handler but not its HTTP method.
 func (m *mux) HandleMethodNotAllowed(handle MethodNotAllowedHandler) {
 	mna := func(rw http.ResponseWriter, req *http.Request, methods map[string]httptreemux.HandlerFunc) {
+		if h, ok := methods["GET"]; ok && m.router.HeadCanUseGet {
+			if _, ok := methods["HEAD"]; !ok {
+				// The router serves HEAD requests with the GET handler.
+				allowed := make(map[string]httptreemux.HandlerFunc, len(methods)+1)
+				for k, v := range methods {
+					allowed[k] = v
+				}
+				allowed["HEAD"] = h
+				methods = allowed
+			}
+		}
 		handle(rw, req, nil, methods)
 	}
 	m.router.MethodNotAllowedHandler = mna
@@ -97,5 +248,182 @@ func (m *mux) Lookup(method, path string) MuxHandler {
 
 // ServeHTTP is the function called back by the underlying HTTP server to handle incoming requests.
 func (m *mux) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if m.duplicateSlashes != nil && *m.duplicateSlashes != SlashNotFound && strings.Contains(req.URL.Path, "//") {
+		r := req.Clone(req.Context())
+		r.URL.Path = collapseSlashes(req.URL.Path)
+		r.URL.RawPath = collapseSlashes(req.URL.RawPath)
+		r.RequestURI = r.URL.RequestURI()
+		if *m.duplicateSlashes == SlashMatch {
+			req = r
+		} else if res, _ := m.router.Lookup(rw, r); res.StatusCode != http.StatusNotFound {
+			http.Redirect(rw, req, r.URL.RequestURI(), redirectCode(*m.duplicateSlashes))
+			return
+		}
+	}
+	if m.caseInsensitive != SlashNotFound {
+		if res, _ := m.router.Lookup(rw, req); res.StatusCode == http.StatusNotFound {
+			if p, ok := m.canonicalPath(req.URL.EscapedPath()); ok {
+				r := req.Clone(req.Context())
+				r.URL.Path, _ = url.PathUnescape(p)
+				r.URL.RawPath = ""
+				if req.URL.RawPath != "" {
+					r.URL.RawPath = p
+				}
+				r.RequestURI = r.URL.RequestURI()
+				if m.caseInsensitive != SlashMatch {
+					http.Redirect(rw, req, r.URL.RequestURI(), redirectCode(m.caseInsensitive))
+					return
+				}
+				req = r
+			}
+		}
+	}
 	m.router.ServeHTTP(rw, req)
 }
+
+// canonicalPath returns the given escaped request path with its static segments replaced by the
+// segments of the registered route they match case-insensitively. The route with the most static
+// segments wins if several routes match. canonicalPath returns false if no route matches.
+func (m *mux) canonicalPath(path string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	elems := strings.Split(path, "/")
+	var canonical []string
+	best := -1
+	for _, r := range m.routes {
+		if c, static, ok := matchFold(elems, strings.Split(r.Path, "/")); ok && static > best {
+			canonical, best = c, static
+		}
+	}
+	if canonical == nil {
+		return "", false
+	}
+	return strings.Join(canonical, "/"), true
+}
+
+// matchFold matches the escaped request path segments with the route path segments, comparing
+// static segments case-insensitively. It returns the request segments with the static segments
+// replaced by the route segments and the number of static segments.
+func matchFold(elems, relems []string) ([]string, int, bool) {
+	matched := make([]string, 0, len(elems))
+	static := 0
+	for i, re := range relems {
+		if strings.HasPrefix(re, "*") && i <= len(elems) {
+			return append(matched, elems[i:]...), static, true
+		}
+		if i >= len(elems) {
+			return nil, 0, false
+		}
+		if strings.HasPrefix(re, ":") {
+			matched = append(matched, elems[i])
+			continue
+		}
+		re = strings.TrimPrefix(re, "\\")
+		if e, err := url.PathUnescape(elems[i]); err != nil || !strings.EqualFold(e, re) {
+			return nil, 0, false
+		}
+		matched = append(matched, (&url.URL{Path: re}).EscapedPath())
+		static++
+	}
+	if len(elems) != len(relems) {
+		return nil, 0, false
+	}
+	return matched, static, true
+}
+
+// redirectCode returns the status code of the redirect responses for the given behavior.
+func redirectCode(b SlashBehavior) int {
+	if b == SlashRedirectPermanent {
+		return http.StatusPermanentRedirect
+	}
+	return http.StatusMovedPermanently
+}
+
+// collapseSlashes replaces the sequences of slashes in path with a single slash.
+func collapseSlashes(path string) string {
+	for strings.Contains(path, "//") {
+		path = strings.Replace(path, "//", "/", -1)
+	}
+	return path
+}
+
+// AddRoute records the route with the given method, path and handler. It returns a
+// *RouteConflictError and does not record the route if it conflicts with a route recorded
+// previously.
+func (t *RouteTable) AddRoute(method, path string, handler MuxHandler) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	route := RouteInfo{Method: method, Path: path, Handler: handler}
+	if n, ok := t.names[method+" "+path]; ok {
+		route.Resource, route.Action = n.Resource, n.Action
+		delete(t.names, method+" "+path)
+	}
+	pattern, wildcards := routePattern(path)
+	for _, r := range t.routes {
+		p, w := routePattern(r.Path)
+		if p != pattern {
+			continue
+		}
+		if r.Method == method || w != wildcards {
+			return &RouteConflictError{Route: route, Existing: r}
+		}
+	}
+	t.routes = append(t.routes, route)
+	return nil
+}
+
+// NameRoute sets the names of the resource and action of the route with the given method and
+// path if it is registered and not named yet. Otherwise the names apply to the route registered
+// next with the same method and path so that they may be reported in conflict errors, the names
+// of a registered route are never overwritten.
+func (t *RouteTable) NameRoute(method, path, resource, action string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, r := range t.routes {
+		if r.Method == method && r.Path == path && r.Resource == "" && r.Action == "" {
+			t.routes[i].Resource = resource
+			t.routes[i].Action = action
+			return
+		}
+	}
+	if t.names == nil {
+		t.names = make(map[string]RouteInfo)
+	}
+	t.names[method+" "+path] = RouteInfo{Resource: resource, Action: action}
+}
+
+// Routes returns a copy of the recorded routes in registration order.
+func (t *RouteTable) Routes() []RouteInfo {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	routes := make([]RouteInfo, len(t.routes))
+	copy(routes, t.routes)
+	return routes
+}
+
+// Error returns the error message listing the two conflicting routes.
+func (e *RouteConflictError) Error() string {
+	return fmt.Sprintf("route %s conflicts with route %s", routeSource(e.Route), routeSource(e.Existing))
+}
+
+// routePattern returns the given path with the wildcard names removed and the wildcard names.
+func routePattern(path string) (string, string) {
+	elems := strings.Split(path, "/")
+	var wildcards []string
+	for i, e := range elems {
+		if strings.HasPrefix(e, ":") || strings.HasPrefix(e, "*") {
+			wildcards = append(wildcards, e)
+			elems[i] = e[:1]
+		}
+	}
+	return strings.Join(elems, "/"), strings.Join(wildcards, "/")
+}
+
+// routeSource describes the given route for error messages.
+func routeSource(r RouteInfo) string {
+	src := r.Method + " " + r.Path
+	if r.Resource != "" {
+		src += fmt.Sprintf(" (%s action of %s)", r.Action, r.Resource)
+	}
+	return src
+}
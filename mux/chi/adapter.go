@@ -0,0 +1,120 @@
+/*
+Package goachi contains an adapter that makes it possible to configure goa so it uses a chi router
+as request mux.
+Usage:
+
+	router := chi.NewRouter()
+	// Initialize mux using chi router
+	service.UseMux(goachi.New(router))
+	// ... Proceed with mounting the controllers and starting the goa service
+*/
+package goachi
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dimfeld/httptreemux"
+	"github.com/go-chi/chi/v5"
+	"github.com/kyokomi/goa-v1"
+)
+
+// adapter is the chi goa mux adapter.
+type adapter struct {
+	goa.RouteTable
+	router  chi.Router
+	handles map[string]goa.MuxHandler
+	methods []string
+}
+
+// New wraps a chi router into a goa mux. The router may have middleware and routes of its own.
+func New(router chi.Router) goa.ServeMux {
+	return &adapter{router: router, handles: make(map[string]goa.MuxHandler)}
+}
+
+// Handle sets the handler for the given verb and path.
+func (a *adapter) Handle(method, path string, handle goa.MuxHandler) {
+	chiPath, wildcard := convertPath(path)
+	if err := a.AddRoute(method, path, handle); err != nil {
+		panic(err)
+	}
+	a.router.MethodFunc(method, chiPath, func(rw http.ResponseWriter, req *http.Request) {
+		params := req.URL.Query()
+		if rctx := chi.RouteContext(req.Context()); rctx != nil {
+			for i, k := range rctx.URLParams.Keys {
+				v := rctx.URLParams.Values[i]
+				if req.URL.RawPath != "" {
+					if uv, err := url.PathUnescape(v); err == nil {
+						v = uv
+					}
+				}
+				if k == "*" {
+					k = wildcard
+				}
+				params.Set(k, v)
+			}
+		}
+		handle(rw, req, params)
+	})
+	a.handles[method+path] = handle
+	for _, m := range a.methods {
+		if m == method {
+			return
+		}
+	}
+	a.methods = append(a.methods, method)
+}
+
+// HandleNotFound sets the MuxHandler invoked for requests that don't match any
+// handler registered with Handle.
+func (a *adapter) HandleNotFound(handle goa.MuxHandler) {
+	a.router.NotFound(func(rw http.ResponseWriter, req *http.Request) {
+		handle(rw, req, nil)
+	})
+}
+
+// HandleMethodNotAllowed sets the MuxHandler invoked for requests that match
+// the path of a handler but not its HTTP method.
+func (a *adapter) HandleMethodNotAllowed(handle goa.MethodNotAllowedHandler) {
+	a.router.MethodNotAllowed(func(rw http.ResponseWriter, req *http.Request) {
+		path := req.URL.RawPath
+		if path == "" {
+			path = req.URL.Path
+		}
+		methods := make(map[string]httptreemux.HandlerFunc)
+		for _, m := range a.methods {
+			if a.router.Match(chi.NewRouteContext(), m, path) {
+				methods[m] = nil
+			}
+		}
+		handle(rw, req, nil, methods)
+	})
+}
+
+// Lookup returns the MuxHandler associated with the given method and path.
+func (a *adapter) Lookup(method, path string) goa.MuxHandler {
+	return a.handles[method+path]
+}
+
+// ServeHTTP is the function called back by the underlying HTTP server to handle incoming requests.
+func (a *adapter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	a.router.ServeHTTP(rw, req)
+}
+
+// convertPath converts a goa path into a chi path and returns the name of its catch-all
+// parameter if any.
+func convertPath(path string) (string, string) {
+	var wildcard string
+	elems := strings.Split(path, "/")
+	for i, e := range elems {
+		switch {
+		case strings.HasPrefix(e, ":"):
+			elems[i] = "{" + e[1:] + "}"
+		case strings.HasPrefix(e, "*"):
+			wildcard = e[1:]
+			elems[i] = "*"
+		}
+	}
+	return strings.Join(elems, "/"), wildcard
+}
@@ -0,0 +1,92 @@
+package goachi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+
+	"github.com/dimfeld/httptreemux"
+	"github.com/go-chi/chi/v5"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1"
+	goachi "github.com/kyokomi/goa-v1/mux/chi"
+)
+
+var _ = Describe("goachi", func() {
+	var mux goa.ServeMux
+	var params url.Values
+	var allowed []string
+	var notFound bool
+
+	BeforeEach(func() {
+		params, allowed, notFound = nil, nil, false
+		mux = goachi.New(chi.NewRouter())
+		handler := func(rw http.ResponseWriter, req *http.Request, vals url.Values) {
+			params = vals
+		}
+		mux.Handle("GET", "/bottles/:id", handler)
+		mux.Handle("PUT", "/bottles/:id", handler)
+		mux.Handle("GET", "/files/*filepath", handler)
+		mux.HandleNotFound(func(rw http.ResponseWriter, req *http.Request, vals url.Values) {
+			notFound = true
+		})
+		mux.HandleMethodNotAllowed(func(rw http.ResponseWriter, req *http.Request, vals url.Values, methods map[string]httptreemux.HandlerFunc) {
+			for m := range methods {
+				allowed = append(allowed, m)
+			}
+			sort.Strings(allowed)
+		})
+	})
+
+	serve := func(method, path string) {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, path, nil))
+	}
+
+	It("passes the path and querystring parameters", func() {
+		serve("GET", "/bottles/42?view=tiny")
+		Ω(params.Get("id")).Should(Equal("42"))
+		Ω(params.Get("view")).Should(Equal("tiny"))
+	})
+
+	It("unescapes the path parameters", func() {
+		serve("GET", "/bottles/a%2Fb")
+		Ω(params.Get("id")).Should(Equal("a/b"))
+	})
+
+	It("names the catch-all parameter", func() {
+		serve("GET", "/files/css/goa.css")
+		Ω(params.Get("filepath")).Should(Equal("css/goa.css"))
+	})
+
+	It("looks up the handlers", func() {
+		Ω(mux.Lookup("GET", "/bottles/:id")).ShouldNot(BeNil())
+		Ω(mux.Lookup("POST", "/bottles/:id")).Should(BeNil())
+	})
+
+	It("lists the routes", func() {
+		routes := mux.(goa.RouteLister).Routes()
+		Ω(routes).Should(HaveLen(3))
+		Ω(routes[0].Method).Should(Equal("GET"))
+		Ω(routes[0].Path).Should(Equal("/bottles/:id"))
+		Ω(routes[2].Path).Should(Equal("/files/*filepath"))
+	})
+
+	It("rejects conflicting routes", func() {
+		h := func(rw http.ResponseWriter, req *http.Request, vals url.Values) {}
+		Ω(func() { mux.Handle("GET", "/bottles/:bottleID", h) }).Should(PanicWith(BeAssignableToTypeOf(&goa.RouteConflictError{})))
+	})
+
+	It("handles requests that don't match", func() {
+		serve("GET", "/foo")
+		Ω(notFound).Should(BeTrue())
+	})
+
+	It("handles requests with the wrong method", func() {
+		serve("DELETE", "/bottles/42")
+		Ω(params).Should(BeNil())
+		Ω(allowed).Should(Equal([]string{"GET", "PUT"}))
+	})
+})
@@ -0,0 +1,27 @@
+/*
+Package mux contains request mux adapters that make it possible for goa services to route requests
+using existing routers instead of the default httptreemux based mux. Each adapter exists in its own
+sub-package named after the corresponding router package.
+
+Adapters are used by setting the goa service mux with UseMux before mounting the controllers:
+
+	func main() {
+		// ...
+
+		// Create router and service
+		router := chi.NewRouter()
+		router.Use(chimiddleware.RealIP)
+		service := goa.New("my service")
+		service.UseMux(goachi.New(router))
+
+		// Mount controllers
+		app.MountBottleController(service, NewBottleController(service))
+
+		// ...
+	}
+
+The adapters translate the goa path syntax (":name" for path parameters and "*name" for catch-all
+parameters) into the syntax of the router and pass the path parameter values to the goa handlers
+under the names used in the design.
+*/
+package mux
@@ -0,0 +1,101 @@
+/*
+Package goahttprouter contains an adapter that makes it possible to configure goa so it uses a
+httprouter router as request mux.
+Usage:
+
+	router := httprouter.New()
+	// Initialize mux using httprouter router
+	service.UseMux(goahttprouter.New(router))
+	// ... Proceed with mounting the controllers and starting the goa service
+
+Note that httprouter does not accept routes whose path parameters conflict with static path
+segments (e.g. "/bottles/:id" and "/bottles/new") and that it matches the unescaped request path so
+that path parameter values cannot contain escaped slashes.
+*/
+package goahttprouter
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dimfeld/httptreemux"
+	"github.com/julienschmidt/httprouter"
+	"github.com/kyokomi/goa-v1"
+)
+
+// adapter is the httprouter goa mux adapter.
+type adapter struct {
+	goa.RouteTable
+	router  *httprouter.Router
+	handles map[string]goa.MuxHandler
+	methods []string
+}
+
+// New wraps a httprouter router into a goa mux. The router may have routes of its own.
+func New(router *httprouter.Router) goa.ServeMux {
+	return &adapter{router: router, handles: make(map[string]goa.MuxHandler)}
+}
+
+// Handle sets the handler for the given verb and path.
+func (a *adapter) Handle(method, path string, handle goa.MuxHandler) {
+	var wildcard string
+	if i := strings.LastIndex(path, "/*"); i >= 0 {
+		wildcard = path[i+2:]
+	}
+	if err := a.AddRoute(method, path, handle); err != nil {
+		panic(err)
+	}
+	a.router.Handle(method, path, func(rw http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		params := req.URL.Query()
+		for _, p := range ps {
+			v := p.Value
+			if p.Key == wildcard {
+				// httprouter includes the leading slash in catch-all values, httptreemux
+				// does not.
+				v = strings.TrimPrefix(v, "/")
+			}
+			params.Set(p.Key, v)
+		}
+		handle(rw, req, params)
+	})
+	a.handles[method+path] = handle
+	for _, m := range a.methods {
+		if m == method {
+			return
+		}
+	}
+	a.methods = append(a.methods, method)
+}
+
+// HandleNotFound sets the MuxHandler invoked for requests that don't match any
+// handler registered with Handle.
+func (a *adapter) HandleNotFound(handle goa.MuxHandler) {
+	a.router.NotFound = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		handle(rw, req, nil)
+	})
+}
+
+// HandleMethodNotAllowed sets the MuxHandler invoked for requests that match
+// the path of a handler but not its HTTP method.
+func (a *adapter) HandleMethodNotAllowed(handle goa.MethodNotAllowedHandler) {
+	a.router.HandleMethodNotAllowed = true
+	a.router.MethodNotAllowed = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		methods := make(map[string]httptreemux.HandlerFunc)
+		for _, m := range a.methods {
+			if h, _, _ := a.router.Lookup(m, req.URL.Path); h != nil {
+				methods[m] = nil
+			}
+		}
+		handle(rw, req, nil, methods)
+	})
+}
+
+// Lookup returns the MuxHandler associated with the given method and path.
+func (a *adapter) Lookup(method, path string) goa.MuxHandler {
+	return a.handles[method+path]
+}
+
+// ServeHTTP is the function called back by the underlying HTTP server to handle incoming requests.
+func (a *adapter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	a.router.ServeHTTP(rw, req)
+}
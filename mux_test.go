@@ -5,7 +5,9 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"sort"
 
+	"github.com/dimfeld/httptreemux"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -84,4 +86,232 @@ var _ = Describe("Mux", func() {
 		})
 	})
 
+	Context("with a GET handler and a method not allowed handler", func() {
+		var allowed []string
+
+		BeforeEach(func() {
+			var err error
+			req, err = http.NewRequest("POST", "/foo", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			allowed = nil
+			mux.Handle("GET", "/foo", func(rw http.ResponseWriter, req *http.Request, vals url.Values) {})
+			mux.HandleMethodNotAllowed(func(rw http.ResponseWriter, req *http.Request, vals url.Values, methods map[string]httptreemux.HandlerFunc) {
+				for m := range methods {
+					allowed = append(allowed, m)
+				}
+				sort.Strings(allowed)
+			})
+		})
+
+		It("allows HEAD requests", func() {
+			Ω(allowed).Should(Equal([]string{"GET", "HEAD"}))
+		})
+	})
+
+	Context("with registered routes", func() {
+		BeforeEach(func() {
+			var err error
+			req, err = http.NewRequest("GET", "/", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			h := func(rw http.ResponseWriter, req *http.Request, vals url.Values) {}
+			mux.Handle("GET", "/bottles/:id", h)
+			mux.Handle("POST", "/bottles", h)
+		})
+
+		It("lists the routes in registration order", func() {
+			routes := mux.(goa.RouteLister).Routes()
+			Ω(routes).Should(HaveLen(2))
+			Ω(routes[0].Method).Should(Equal("GET"))
+			Ω(routes[0].Path).Should(Equal("/bottles/:id"))
+			Ω(routes[0].Handler).ShouldNot(BeNil())
+			Ω(routes[1].Method).Should(Equal("POST"))
+			Ω(routes[1].Path).Should(Equal("/bottles"))
+		})
+
+		It("names the routes", func() {
+			mux.(interface {
+				NameRoute(method, path, resource, action string)
+			}).NameRoute("POST", "/bottles", "bottle", "create")
+			routes := mux.(goa.RouteLister).Routes()
+			Ω(routes[0].Resource).Should(BeEmpty())
+			Ω(routes[1].Resource).Should(Equal("bottle"))
+			Ω(routes[1].Action).Should(Equal("create"))
+		})
+
+		It("rejects conflicting routes", func() {
+			mux.(interface {
+				NameRoute(method, path, resource, action string)
+			}).NameRoute("GET", "/bottles/:bottleID", "bottle", "show")
+			h := func(rw http.ResponseWriter, req *http.Request, vals url.Values) {}
+			for _, r := range [][2]string{
+				{"GET", "/bottles/:id"},
+				{"GET", "/bottles/:bottleID"},
+				{"PUT", "/bottles/:bottleID"},
+			} {
+				Ω(func() { mux.Handle(r[0], r[1], h) }).Should(PanicWith(BeAssignableToTypeOf(&goa.RouteConflictError{})))
+			}
+			Ω(mux.(goa.RouteLister).Routes()).Should(HaveLen(2))
+		})
+
+		It("describes the conflicting routes", func() {
+			mux.(interface {
+				NameRoute(method, path, resource, action string)
+			}).NameRoute("GET", "/bottles/:bottleID", "bottle", "show")
+			defer func() {
+				err, ok := recover().(error)
+				Ω(ok).Should(BeTrue())
+				Ω(err.Error()).Should(Equal("route GET /bottles/:bottleID (show action of bottle) conflicts with route GET /bottles/:id"))
+			}()
+			mux.Handle("GET", "/bottles/:bottleID", func(rw http.ResponseWriter, req *http.Request, vals url.Values) {})
+		})
+
+		It("accepts routes that differ by method", func() {
+			mux.Handle("PUT", "/bottles/:id", func(rw http.ResponseWriter, req *http.Request, vals url.Values) {})
+			Ω(mux.(goa.RouteLister).Routes()).Should(HaveLen(3))
+		})
+	})
+
+})
+
+var _ = Describe("Mux slash options", func() {
+	var mux goa.ServeMux
+	var req *http.Request
+	var rw *TestResponseWriter
+	var options []goa.MuxOption
+	var path string
+	var served bool
+	var id string
+
+	BeforeEach(func() {
+		options = nil
+		served = false
+		id = ""
+	})
+
+	JustBeforeEach(func() {
+		mux = goa.NewMux(options...)
+		mux.Handle("GET", "/bottles/:id", func(rw http.ResponseWriter, req *http.Request, vals url.Values) {
+			served = true
+			id = vals.Get("id")
+		})
+		var err error
+		req, err = http.NewRequest("GET", path, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = &TestResponseWriter{ParentHeader: http.Header{}}
+		mux.ServeHTTP(rw, req)
+	})
+
+	Context("with a trailing slash", func() {
+		BeforeEach(func() {
+			path = "/bottles/1/"
+		})
+
+		It("redirects with 301 by default", func() {
+			Ω(rw.Status).Should(Equal(301))
+			Ω(rw.ParentHeader.Get("Location")).Should(Equal("/bottles/1"))
+		})
+
+		Context("and SlashRedirectPermanent", func() {
+			BeforeEach(func() {
+				options = []goa.MuxOption{goa.TrailingSlash(goa.SlashRedirectPermanent)}
+			})
+
+			It("redirects with 308", func() {
+				Ω(rw.Status).Should(Equal(308))
+				Ω(rw.ParentHeader.Get("Location")).Should(Equal("/bottles/1"))
+			})
+		})
+
+		Context("and SlashMatch", func() {
+			BeforeEach(func() {
+				options = []goa.MuxOption{goa.TrailingSlash(goa.SlashMatch)}
+			})
+
+			It("serves the request", func() {
+				Ω(served).Should(BeTrue())
+			})
+		})
+
+		Context("and SlashNotFound", func() {
+			BeforeEach(func() {
+				options = []goa.MuxOption{goa.TrailingSlash(goa.SlashNotFound)}
+			})
+
+			It("returns 404", func() {
+				Ω(served).Should(BeFalse())
+				Ω(rw.Status).Should(Equal(404))
+			})
+		})
+	})
+
+	Context("with duplicated slashes", func() {
+		BeforeEach(func() {
+			path = "/bottles//1?view=tiny"
+		})
+
+		Context("and SlashRedirectPermanent", func() {
+			BeforeEach(func() {
+				options = []goa.MuxOption{goa.DuplicateSlashes(goa.SlashRedirectPermanent)}
+			})
+
+			It("redirects with 308", func() {
+				Ω(rw.Status).Should(Equal(308))
+				Ω(rw.ParentHeader.Get("Location")).Should(Equal("/bottles/1?view=tiny"))
+			})
+		})
+
+		Context("and SlashMatch", func() {
+			BeforeEach(func() {
+				options = []goa.MuxOption{goa.DuplicateSlashes(goa.SlashMatch)}
+			})
+
+			It("serves the request", func() {
+				Ω(served).Should(BeTrue())
+			})
+		})
+
+		Context("and SlashNotFound", func() {
+			BeforeEach(func() {
+				options = []goa.MuxOption{goa.DuplicateSlashes(goa.SlashNotFound)}
+			})
+
+			It("returns 404", func() {
+				Ω(served).Should(BeFalse())
+				Ω(rw.Status).Should(Equal(404))
+			})
+		})
+	})
+
+	Context("with mixed case static segments", func() {
+		BeforeEach(func() {
+			path = "/Bottles/AbC?view=tiny"
+		})
+
+		It("returns 404 by default", func() {
+			Ω(served).Should(BeFalse())
+			Ω(rw.Status).Should(Equal(404))
+		})
+
+		Context("and SlashRedirect", func() {
+			BeforeEach(func() {
+				options = []goa.MuxOption{goa.CaseInsensitive(goa.SlashRedirect)}
+			})
+
+			It("redirects to the canonical path", func() {
+				Ω(rw.Status).Should(Equal(301))
+				Ω(rw.ParentHeader.Get("Location")).Should(Equal("/bottles/AbC?view=tiny"))
+			})
+		})
+
+		Context("and SlashMatch", func() {
+			BeforeEach(func() {
+				options = []goa.MuxOption{goa.CaseInsensitive(goa.SlashMatch)}
+			})
+
+			It("serves the request with the parameter values untouched", func() {
+				Ω(served).Should(BeTrue())
+				Ω(id).Should(Equal("AbC"))
+			})
+		})
+	})
 })
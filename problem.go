@@ -0,0 +1,123 @@
+package goa
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// ProblemMediaIdentifier is the media type identifier of the problem details (RFC 7807) used by
+// the error responses of the services that set a ProblemRegistry.
+const ProblemMediaIdentifier = "application/problem+json"
+
+type (
+	// Problem is the problem details (RFC 7807) rendered in the error responses when the
+	// service sets a ProblemRegistry. The id, code, meta and details extension members carry
+	// the corresponding fields of the error.
+	Problem struct {
+		// Type is the URI reference that identifies the problem type, "about:blank" if the
+		// error class is not registered.
+		Type string `json:"type" yaml:"type" xml:"type" form:"type"`
+		// Title is the short summary of the problem type.
+		Title string `json:"title,omitempty" yaml:"title,omitempty" xml:"title,omitempty" form:"title,omitempty"`
+		// Status is the HTTP status code of the response.
+		Status int `json:"status,omitempty" yaml:"status,omitempty" xml:"status,omitempty" form:"status,omitempty"`
+		// Detail describes the specific error occurrence.
+		Detail string `json:"detail,omitempty" yaml:"detail,omitempty" xml:"detail,omitempty" form:"detail,omitempty"`
+		// Instance is the URI reference of the request that produced the error.
+		Instance string `json:"instance,omitempty" yaml:"instance,omitempty" xml:"instance,omitempty" form:"instance,omitempty"`
+		// ID is the unique error instance identifier.
+		ID string `json:"id,omitempty" yaml:"id,omitempty" xml:"id,omitempty" form:"id,omitempty"`
+		// Code identifies the class of errors.
+		Code string `json:"code,omitempty" yaml:"code,omitempty" xml:"code,omitempty" form:"code,omitempty"`
+		// Meta contains additional key/value pairs useful to clients.
+		Meta map[string]interface{} `json:"meta,omitempty" yaml:"meta,omitempty" xml:"meta,omitempty" form:"meta,omitempty"`
+		// Details contains the structured details of the error.
+		Details interface{} `json:"details,omitempty" yaml:"details,omitempty" xml:"details,omitempty" form:"details,omitempty"`
+	}
+
+	// ProblemType describes the problem type of an error class.
+	ProblemType struct {
+		// URI identifies the problem type.
+		URI string
+		// Title is the short summary of the problem type, the problems use the text of the
+		// response status if empty.
+		Title string
+	}
+
+	// ProblemRegistry maps the codes of the error classes to problem types. It is safe for
+	// concurrent use.
+	ProblemRegistry struct {
+		mu    sync.RWMutex
+		types map[string]*ProblemType
+	}
+)
+
+// NewProblemRegistry returns an empty problem registry.
+func NewProblemRegistry() *ProblemRegistry {
+	return &ProblemRegistry{types: make(map[string]*ProblemType)}
+}
+
+// Register maps the given error class code to the problem type identified by uri. title is the
+// optional short summary of the problem type.
+func (r *ProblemRegistry) Register(code, uri string, title ...string) {
+	t := &ProblemType{URI: uri}
+	if len(title) > 0 {
+		t.Title = title[0]
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.types[code] = t
+}
+
+// Lookup returns the problem type registered for the given error class code, nil if there isn't
+// any.
+func (r *ProblemRegistry) Lookup(code string) *ProblemType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.types[code]
+}
+
+// Codes returns the registered error class codes sorted alphabetically.
+func (r *ProblemRegistry) Codes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	codes := make([]string, 0, len(r.types))
+	for code := range r.types {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Problem returns the problem details of the given error. The type and title are given by the
+// problem type registered for the error class, errors that do not implement or wrap a
+// ServiceError produce internal error problems. instance is the URI reference of the request
+// that produced the error.
+func (r *ProblemRegistry) Problem(err error, instance string) *Problem {
+	p := &Problem{Type: "about:blank", Instance: instance}
+	var e *ErrorResponse
+	if se, ok := err.(ServiceError); ok && !errors.As(err, &e) {
+		p.Status, p.Detail, p.ID = se.ResponseStatus(), se.Error(), se.Token()
+	} else {
+		e = asErrorResponse(err)
+		p.Status, p.Detail, p.ID, p.Code, p.Meta, p.Details = e.Status, e.Detail, e.ID, e.Code, e.Meta, e.Details
+	}
+	if t := r.Lookup(p.Code); t != nil && p.Code != "" {
+		p.Type, p.Title = t.URI, t.Title
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	return p
+}
+
+// Error returns the problem details.
+func (p *Problem) Error() string {
+	msg := p.Title
+	if p.Detail != "" {
+		msg += ": " + p.Detail
+	}
+	return msg
+}
@@ -0,0 +1,59 @@
+package goa
+
+import (
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProblemRegistry", func() {
+	var registry *ProblemRegistry
+
+	BeforeEach(func() {
+		registry = NewProblemRegistry()
+		registry.Register("invalid_request", "https://example.com/problems/invalid-request", "Invalid request")
+		registry.Register("not_found", "https://example.com/problems/not-found")
+	})
+
+	It("lists the registered codes", func() {
+		Ω(registry.Codes()).Should(Equal([]string{"invalid_request", "not_found"}))
+		Ω(registry.Lookup("not_found")).Should(Equal(&ProblemType{URI: "https://example.com/problems/not-found"}))
+		Ω(registry.Lookup("unknown")).Should(BeNil())
+	})
+
+	It("builds the problem details of registered error classes", func() {
+		err := ErrInvalidRequest("invalid id", "attribute", "id")
+		p := registry.Problem(err, "/bottles/x")
+		Ω(p).Should(Equal(&Problem{
+			Type:     "https://example.com/problems/invalid-request",
+			Title:    "Invalid request",
+			Status:   400,
+			Detail:   "invalid id",
+			Instance: "/bottles/x",
+			ID:       err.(ServiceError).Token(),
+			Code:     "invalid_request",
+			Meta:     map[string]interface{}{"attribute": "id"},
+		}))
+	})
+
+	It("uses the status text as default title", func() {
+		p := registry.Problem(ErrNotFound("/foo"), "/foo")
+		Ω(p.Type).Should(Equal("https://example.com/problems/not-found"))
+		Ω(p.Title).Should(Equal("Not Found"))
+	})
+
+	It("builds the problem details of other errors", func() {
+		p := registry.Problem(ErrBadRequest("boom"), "")
+		Ω(p.Type).Should(Equal("about:blank"))
+		Ω(p.Title).Should(Equal("Bad Request"))
+
+		p = registry.Problem(errors.New("boom"), "")
+		Ω(p.Status).Should(Equal(500))
+		Ω(p.Detail).Should(Equal("boom"))
+		b, err := json.Marshal(p)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"boom","code":"internal_error"}`))
+	})
+})
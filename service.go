@@ -2,19 +2,27 @@ package goa
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/dimfeld/httptreemux"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 type (
@@ -39,9 +47,39 @@ type (
 		Decoder *HTTPDecoder
 		// Response body encoder
 		Encoder *HTTPEncoder
+		// Problems maps the error classes to problem types, the error handler middleware
+		// and the not found and method not allowed handlers render the error responses as
+		// problem details (RFC 7807) when set.
+		Problems *ProblemRegistry
+		// MethodNotAllowed builds the error returned to requests that match the path of a
+		// registered handler but not its HTTP method given the allowed methods sorted
+		// alphabetically. The error is rendered in the response body which also sets the
+		// Allow header. The default produces a MethodNotAllowedError.
+		MethodNotAllowed func(req *http.Request, allowed []string) error
+		// DrainTimeout is the maximum duration Run waits for the in-flight requests to
+		// complete when shutting down. Defaults to 30 seconds.
+		DrainTimeout time.Duration
+		// AutoTLSCache stores the certificates obtained by ListenAndServeAutoTLS. Defaults
+		// to the "goa/autocert" directory of the user cache directory.
+		AutoTLSCache autocert.Cache
+
+		middleware []Middleware                  // Middleware chain
+		cancel     context.CancelFunc            // Service context cancel signal trigger
+		onStart    []func(context.Context) error // Hooks run before the server starts
+		onStop     []func(context.Context) error // Hooks run after the server stops
+		stopOnce   sync.Once                     // Guards the run of the stop hooks
+		stopErr    error                         // Error returned by the stop hooks
+		inFlight   int32                         // Number of requests being handled
+		listeners  []*listener                   // Additional listeners
+		extServers []ServerCloser                // Servers given to ServeServers
+		lmu        sync.Mutex                    // Guards the listener servers
+	}
 
-		middleware []Middleware       // Middleware chain
-		cancel     context.CancelFunc // Service context cancel signal trigger
+	// ShutdownError is the error returned by Run when the drain timeout expires before all
+	// the in-flight requests complete.
+	ShutdownError struct {
+		// Interrupted is the number of requests that were interrupted.
+		Interrupted int
 	}
 
 	// Controller defines the common fields and behavior of generated controllers.
@@ -67,9 +105,16 @@ type (
 		//			Prefix: dir,
 		//		}
 		//	}
+		//
+		// Use FSFileSystem to serve files bundled in the binary with embed.FS or any other
+		// fs.FS implementation.
 		FileSystem func(string) http.FileSystem
+		// FileCacheControl is the value of the Cache-Control header of the responses of
+		// FileHandler, e.g. "public, max-age=86400". No header is set if empty.
+		FileCacheControl string
 
 		middleware []Middleware // Controller specific middleware if any
+		etags      sync.Map     // ETags of the files with no modification time by path
 	}
 
 	// FileServer is the interface implemented by controllers that can serve static files.
@@ -94,22 +139,39 @@ func New(name string) *Service {
 		stdlog       = log.New(os.Stderr, "", log.LstdFlags)
 		ctx          = WithLogger(context.Background(), NewLogger(stdlog))
 		cctx, cancel = context.WithCancel(ctx)
-		mux          = NewMux()
 		service      = &Service{
 			Name:    name,
 			Context: cctx,
-			Mux:     mux,
-			Server: &http.Server{
-				Handler: mux,
-			},
+			Server:  &http.Server{},
 			Decoder: NewHTTPDecoder(),
 			Encoder: NewHTTPEncoder(),
 
+			DrainTimeout: 30 * time.Second,
+
 			cancel: cancel,
 		}
+	)
+	service.UseMux(NewMux())
+
+	return service
+}
+
+// UseMux sets the request mux of the service, e.g. to use an adapter of an existing router or a
+// mux created by NewMux with options such as TrailingSlash:
+//
+//	service.UseMux(goa.NewMux(goa.TrailingSlash(goa.SlashMatch)))
+//
+// It configures the mux so that requests that don't match any handler produce goa errors and
+// makes it the handler of the service HTTP server. UseMux must be called before the controllers
+// are mounted.
+func (service *Service) UseMux(mux ServeMux) {
+	var (
+		ctx                     = service.Context
 		notFoundHandler         Handler
 		methodNotAllowedHandler Handler
 	)
+	service.Mux = mux
+	service.Server.Handler = mux
 
 	// Setup default NotFound handler
 	mux.HandleNotFound(func(rw http.ResponseWriter, req *http.Request, params url.Values) {
@@ -131,7 +193,7 @@ func New(name string) *Service {
 		ctx := NewContext(service.Context, rw, req, params)
 		err := notFoundHandler(ctx, ContextResponse(ctx), req)
 		if !ContextResponse(ctx).Written() {
-			service.Send(ctx, 404, err)
+			service.sendError(ctx, 404, err)
 		}
 	})
 
@@ -143,15 +205,13 @@ func New(name string) *Service {
 		// Use closure to do lazy computation of middleware chain so all middlewares are
 		// registered.
 		if methodNotAllowedHandler == nil {
-			methodNotAllowedHandler = func(_ context.Context, rw http.ResponseWriter, req *http.Request) error {
-				allowedMethods := make([]string, len(methods))
-				i := 0
-				for k := range methods {
-					allowedMethods[i] = k
-					i++
+			methodNotAllowedHandler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				allowed, _ := ctx.Value(allowedMethodsKey).([]string)
+				rw.Header().Set("Allow", strings.Join(allowed, ", "))
+				if service.MethodNotAllowed != nil {
+					return service.MethodNotAllowed(req, allowed)
 				}
-				rw.Header().Set("Allow", strings.Join(allowedMethods, ", "))
-				return MethodNotAllowedError(req.Method, allowedMethods)
+				return MethodNotAllowedError(req.Method, allowed)
 			}
 			chain := service.middleware
 			ml := len(chain)
@@ -159,14 +219,18 @@ func New(name string) *Service {
 				methodNotAllowedHandler = chain[ml-i-1](methodNotAllowedHandler)
 			}
 		}
+		allowed := make([]string, 0, len(methods))
+		for m := range methods {
+			allowed = append(allowed, m)
+		}
+		sort.Strings(allowed)
 		ctx := NewContext(service.Context, rw, req, params)
+		ctx = context.WithValue(ctx, allowedMethodsKey, allowed)
 		err := methodNotAllowedHandler(ctx, ContextResponse(ctx), req)
 		if !ContextResponse(ctx).Written() {
-			service.Send(ctx, 405, err)
+			service.sendError(ctx, 405, err)
 		}
 	})
-
-	return service
 }
 
 // CancelAll sends a cancel signals to all request handlers via the context.
@@ -197,23 +261,141 @@ func (service *Service) LogError(msg string, keyvals ...interface{}) {
 	LogError(service.Context, msg, keyvals...)
 }
 
+// OnStart registers a function that ListenAndServe, ListenAndServeTLS and Serve run before the
+// server accepts connections, e.g. to open a database pool. The functions run in registration
+// order with the service context, the server does not start if one of them returns an error.
+func (service *Service) OnStart(f func(context.Context) error) {
+	service.onStart = append(service.onStart, f)
+}
+
+// OnStop registers a function that runs once the server has stopped, e.g. to close a database
+// pool. The functions run in registration order when Shutdown is called or when the server stops
+// with an error. All the functions run even if some of them return an error.
+func (service *Service) OnStop(f func(context.Context) error) {
+	service.onStop = append(service.onStop, f)
+}
+
 // ListenAndServe starts a HTTP server and sets up a listener on the given host/port.
 func (service *Service) ListenAndServe(addr string) error {
-	service.LogInfo("listen", "transport", "http", "addr", addr)
-	service.Server.Addr = addr
-	return service.Server.ListenAndServe()
+	return service.serve(func() error {
+		service.LogInfo("listen", "transport", "http", "addr", addr)
+		service.Server.Addr = addr
+		return service.Server.ListenAndServe()
+	})
 }
 
 // ListenAndServeTLS starts a HTTPS server and sets up a listener on the given host/port.
+// The server uses the configuration set with ConfigureTLS or the modern defaults if none.
 func (service *Service) ListenAndServeTLS(addr, certFile, keyFile string) error {
-	service.LogInfo("listen", "transport", "https", "addr", addr)
-	service.Server.Addr = addr
-	return service.Server.ListenAndServeTLS(certFile, keyFile)
+	service.Server.TLSConfig = service.tlsConfig()
+	return service.serve(func() error {
+		service.LogInfo("listen", "transport", "https", "addr", addr)
+		service.Server.Addr = addr
+		return service.Server.ListenAndServeTLS(certFile, keyFile)
+	})
 }
 
 // Serve accepts incoming HTTP connections on the listener l, invoking the service mux handler for each.
+// The listener may be a unix domain socket listener or a listener inherited from the parent
+// process, see ListenAndServeUnix and SystemdListeners.
 func (service *Service) Serve(l net.Listener) error {
-	return service.Server.Serve(l)
+	return service.serve(func() error {
+		service.LogInfo("listen", "transport", l.Addr().Network(), "addr", l.Addr().String())
+		return service.Server.Serve(l)
+	})
+}
+
+// Run starts a HTTP server listening on the given host/port and blocks until the process receives
+// an interrupt or terminate signal or the given context is canceled. It then stops accepting
+// connections, waits for the in-flight requests to complete for up to DrainTimeout and runs the
+// functions registered with OnStop. Run returns a *ShutdownError if the drain timeout expires
+// before all the requests complete, in this case the contexts of the remaining requests are
+// canceled and their connections closed.
+func (service *Service) Run(ctx context.Context, addr string) error {
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() { errc <- service.ListenAndServe(addr) }()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	service.LogInfo("shutdown", "in_flight", atomic.LoadInt32(&service.inFlight),
+		"drain_timeout", service.DrainTimeout.String())
+	dctx := context.Background()
+	if service.DrainTimeout > 0 {
+		var dcancel context.CancelFunc
+		dctx, dcancel = context.WithTimeout(dctx, service.DrainTimeout)
+		defer dcancel()
+	}
+	var err error
+	if serr := service.shutdownServers(dctx); serr != nil {
+		n := int(atomic.LoadInt32(&service.inFlight))
+		service.LogError("shutdown", "err", serr, "interrupted", n)
+		service.CancelAll()
+		service.closeServers()
+		err = &ShutdownError{Interrupted: n}
+	}
+	if serr := service.stop(context.Background()); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// Shutdown gracefully shuts down the server and the servers of the listeners added with
+// AddListener without interrupting the active requests, see http.Server.Shutdown, then runs the
+// functions registered with OnStop. It returns the first error that occurred.
+func (service *Service) Shutdown(ctx context.Context) error {
+	err := service.shutdownServers(ctx)
+	if serr := service.stop(ctx); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// serve runs the start hooks then the given function while serving the additional listeners.
+// It runs the stop hooks if the servers stop with an error other than http.ErrServerClosed.
+func (service *Service) serve(run func() error) error {
+	for _, f := range service.onStart {
+		if err := f(service.Context); err != nil {
+			service.LogError("start", "err", err)
+			return err
+		}
+	}
+	wait := service.serveListeners()
+	err := run()
+	if err != http.ErrServerClosed {
+		service.closeServers()
+	}
+	if lerr := wait(); err == http.ErrServerClosed && lerr != nil {
+		err = lerr
+	}
+	if err != http.ErrServerClosed {
+		service.stop(service.Context)
+	}
+	return err
+}
+
+// stop runs the stop hooks once and returns the first error they returned.
+func (service *Service) stop(ctx context.Context) error {
+	service.stopOnce.Do(func() {
+		for _, f := range service.onStop {
+			if err := f(ctx); err != nil {
+				service.LogError("stop", "err", err)
+				if service.stopErr == nil {
+					service.stopErr = err
+				}
+			}
+		}
+	})
+	return service.stopErr
+}
+
+// Error returns the error message.
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("shutdown interrupted %d in-flight requests", e.Interrupted)
 }
 
 // NewController returns a controller for the given resource. This method is mainly intended for
@@ -241,19 +423,41 @@ func (service *Service) Send(ctx context.Context, code int, body interface{}) er
 	return service.EncodeResponse(ctx, body)
 }
 
+// sendError sends the error, or its problem details if the service sets a problem registry.
+func (service *Service) sendError(ctx context.Context, code int, err error) error {
+	if service.Problems == nil {
+		return service.Send(ctx, code, err)
+	}
+	ContextResponse(ctx).Header().Set("Content-Type", ProblemMediaIdentifier)
+	return service.Send(ctx, code, service.Problems.Problem(err, ContextRequest(ctx).URL.RequestURI()))
+}
+
 // ServeFiles create a "FileServer" controller and calls ServerFiles on it.
 func (service *Service) ServeFiles(path, filename string) error {
 	ctrl := service.NewController("FileServer")
 	return ctrl.ServeFiles(path, filename)
 }
 
+// ServeFilesFS creates a "FileServer" controller that serves the files of the given file system
+// and calls ServerFiles on it. filename is the slash-separated path of the files in fsys, e.g.:
+//
+//	//go:embed public
+//	var public embed.FS
+//
+//	service.ServeFilesFS("/assets/*filepath", public, "public/assets")
+func (service *Service) ServeFilesFS(path string, fsys fs.FS, filename string) error {
+	ctrl := service.NewController("FileServer")
+	ctrl.FileSystem = FSFileSystem(fsys)
+	return ctrl.ServeFiles(path, filename)
+}
+
 // DecodeRequest uses the HTTP decoder to unmarshal the request body into the provided value based
 // on the request Content-Type header.
 func (service *Service) DecodeRequest(req *http.Request, v interface{}) error {
 	body, contentType := req.Body, req.Header.Get("Content-Type")
 	defer body.Close()
 
-	if err := service.Decoder.Decode(v, body, contentType); err != nil {
+	if err := service.Decoder.decode(v, body, contentType, req); err != nil {
 		return fmt.Errorf("failed to decode request body with content type %#v: %s", contentType, err)
 	}
 
@@ -268,8 +472,9 @@ func (service *Service) EncodeResponse(ctx context.Context, v interface{}) error
 }
 
 // ServeFiles replies to the request with the contents of the named file or directory. See
-// FileHandler for details.
-func (ctrl *Controller) ServeFiles(path, filename string) error {
+// FileHandler for details. ServeFiles returns a *RouteConflictError if the route conflicts with a
+// route registered previously.
+func (ctrl *Controller) ServeFiles(path, filename string) (err error) {
 	if strings.Contains(path, ":") {
 		return fmt.Errorf("path may only include wildcards that match the entire end of the URL (e.g. *filepath)")
 	}
@@ -280,10 +485,35 @@ func (ctrl *Controller) ServeFiles(path, filename string) error {
 		}
 		return nil
 	}
+	defer func() {
+		if r := recover(); r != nil {
+			conflict, ok := r.(*RouteConflictError)
+			if !ok {
+				panic(r)
+			}
+			err = conflict
+		}
+	}()
+	ctrl.NameRoute("GET", path, "serve")
 	ctrl.Service.Mux.Handle("GET", path, ctrl.MuxHandler("serve", handler, nil))
 	return nil
 }
 
+// NameRoute records the controller name and the given action name as the resource and action
+// names of the route registered with the service mux for the given method and path. The names
+// are listed by the mux Routes method if the mux implements RouteLister, e.g. by embedding a
+// RouteTable. NameRoute may be called before the route is registered so that the names appear in
+// the RouteConflictError produced when the route conflicts with another one.
+// This function is intended for the controller generated code. User code should not need to call
+// it directly.
+func (ctrl *Controller) NameRoute(method, path, action string) {
+	if n, ok := ctrl.Service.Mux.(interface {
+		NameRoute(method, path, resource, action string)
+	}); ok {
+		n.NameRoute(method, path, ctrl.Name, action)
+	}
+}
+
 // Use adds a middleware to the controller.
 // Service-wide middleware should be added via the Service Use method instead.
 func (ctrl *Controller) Use(m Middleware) {
@@ -318,8 +548,14 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 			}
 		})
 
+		atomic.AddInt32(&ctrl.Service.inFlight, 1)
+		defer atomic.AddInt32(&ctrl.Service.inFlight, -1)
+
 		// Build context
 		ctx := NewContext(WithAction(ctrl.Context, name), rw, req, params)
+		if l := ContextListener(req.Context()); l != "" {
+			ctx = context.WithValue(ctx, listenerKey, l)
+		}
 
 		// Protect against request bodies with unreasonable length
 		if ctrl.MaxRequestBodyLength > 0 {
@@ -363,6 +599,16 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 //
 // returns the content of the file "/www/data/assets/x/y/z" when requests are sent to
 // "/assets/x/y/z".
+//
+// The handler honors the Range and If-Range request headers: it responds with 206 Partial
+// Content and the requested byte ranges so that downloads of large files can be resumed, or
+// with 416 Requested Range Not Satisfiable if none of the ranges overlaps the file. Note that
+// the gzip middleware removes the Range header of the requests unless it is created with the
+// IgnoreRange(false) option.
+//
+// The responses include the Last-Modified and ETag headers of the file and the Cache-Control
+// header set with FileCacheControl. The handler responds with 304 Not Modified to the
+// conditional requests whose If-None-Match or If-Modified-Since header matches the file.
 func (ctrl *Controller) FileHandler(path, filename string) Handler {
 	var wc string
 	if idx := strings.LastIndex(path, "/*"); idx > -1 && idx < len(path)-1 {
@@ -414,11 +660,69 @@ func (ctrl *Controller) FileHandler(path, filename string) Handler {
 		if d.IsDir() {
 			return dirList(rw, f)
 		}
+		if ctrl.FileCacheControl != "" {
+			rw.Header().Set("Cache-Control", ctrl.FileCacheControl)
+		}
+		if rw.Header().Get("Etag") == "" {
+			etag, err := ctrl.fileETag(filepath.Join(dir, name), d, f)
+			if err != nil {
+				return ErrInvalidFile(err)
+			}
+			rw.Header().Set("Etag", etag)
+		}
 		http.ServeContent(rw, req, d.Name(), d.ModTime(), f)
 		return nil
 	}
 }
 
+// fileETag returns the strong entity tag of the given file. The tag is computed from the file
+// size and modification time if the file has one, from the file content otherwise, e.g. for the
+// files of an embed.FS. The tags computed from the contents are cached by file path.
+func (ctrl *Controller) fileETag(name string, d os.FileInfo, f io.ReadSeeker) (string, error) {
+	if !d.ModTime().IsZero() {
+		return fmt.Sprintf(`"%x-%x"`, d.ModTime().UnixNano(), d.Size()), nil
+	}
+	if etag, ok := ctrl.etags.Load(name); ok {
+		return etag.(string), nil
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := fmt.Sprintf(`"%x"`, h.Sum(nil)[:16])
+	ctrl.etags.Store(name, etag)
+	return etag, nil
+}
+
+// FSFileSystem returns a controller FileSystem function that opens the files of the given
+// file system, e.g. an embed.FS. The file names given to FileHandler are slash-separated paths
+// relative to the root of fsys. This makes it possible to serve the assets bundled in the
+// binary through the file servers defined in the design:
+//
+//	ctrl := NewPublicController(service)
+//	ctrl.FileSystem = goa.FSFileSystem(public)
+//	app.MountPublicController(service, ctrl)
+func FSFileSystem(fsys fs.FS) func(string) http.FileSystem {
+	hfs := http.FS(fsys)
+	return func(dir string) http.FileSystem {
+		return &prefixFileSystem{FileSystem: hfs, prefix: path.Join("/", filepath.ToSlash(dir))}
+	}
+}
+
+// prefixFileSystem opens the files of a sub-directory of a http.FileSystem.
+type prefixFileSystem struct {
+	http.FileSystem
+	prefix string
+}
+
+// Open opens the named file in the sub-directory.
+func (p *prefixFileSystem) Open(name string) (http.File, error) {
+	return p.FileSystem.Open(path.Join(p.prefix, name))
+}
+
 func attemptsPathTraversal(req string, path string) bool {
 	if !strings.Contains(req, "..") {
 		return false
@@ -3,12 +3,19 @@ package goa_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 
 	"sync"
+	"testing/fstest"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -38,6 +45,190 @@ var _ = Describe("Service", func() {
 		})
 	})
 
+	Describe("UseMux", func() {
+		var mux goa.ServeMux
+
+		BeforeEach(func() {
+			mux = goa.NewMux()
+			s.UseMux(mux)
+		})
+
+		It("sets the service mux", func() {
+			Ω(s.Mux).Should(Equal(mux))
+			Ω(s.Server.Handler).Should(Equal(mux))
+		})
+
+		It("handles requests with no registered handlers", func() {
+			rw := &TestResponseWriter{ParentHeader: make(http.Header)}
+			req, _ := http.NewRequest("GET", "/foo", nil)
+			s.Mux.ServeHTTP(rw, req)
+			Ω(rw.Status).Should(Equal(404))
+			Ω(string(rw.Body)).Should(MatchRegexp(`"code":"not_found"`))
+		})
+
+		It("accepts muxes that do not list their routes", func() {
+			s.UseMux(struct{ goa.ServeMux }{goa.NewMux()})
+			_, ok := s.Mux.(goa.RouteLister)
+			Ω(ok).Should(BeFalse())
+			Ω(s.ServeFiles("/files/*filepath", "/tmp")).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Describe("NameRoute", func() {
+		var ctrl *goa.Controller
+
+		BeforeEach(func() {
+			ctrl = s.NewController("test")
+			h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error { return nil }
+			s.Mux.Handle("GET", "/foo/:id", ctrl.MuxHandler("show", h, nil))
+			ctrl.NameRoute("GET", "/foo/:id", "show")
+			Ω(ctrl.ServeFiles("/files/*filepath", "/tmp")).ShouldNot(HaveOccurred())
+		})
+
+		It("names the mux routes", func() {
+			routes := s.Mux.(goa.RouteLister).Routes()
+			Ω(routes).Should(HaveLen(2))
+			Ω(routes[0].Path).Should(Equal("/foo/:id"))
+			Ω(routes[0].Resource).Should(Equal("test"))
+			Ω(routes[0].Action).Should(Equal("show"))
+			Ω(routes[1].Path).Should(Equal("/files/*filepath"))
+			Ω(routes[1].Resource).Should(Equal("test"))
+			Ω(routes[1].Action).Should(Equal("serve"))
+		})
+
+		It("does not rename the routes registered by other controllers", func() {
+			other := s.NewController("other")
+			h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error { return nil }
+			other.NameRoute("GET", "/foo/:id", "get")
+			var conflict *goa.RouteConflictError
+			func() {
+				defer func() { conflict, _ = recover().(*goa.RouteConflictError) }()
+				s.Mux.Handle("GET", "/foo/:id", other.MuxHandler("get", h, nil))
+			}()
+			Ω(conflict).ShouldNot(BeNil())
+			Ω(conflict.Route.Resource).Should(Equal("other"))
+			Ω(conflict.Route.Action).Should(Equal("get"))
+			Ω(conflict.Existing.Resource).Should(Equal("test"))
+			Ω(conflict.Existing.Action).Should(Equal("show"))
+			routes := s.Mux.(goa.RouteLister).Routes()
+			Ω(routes).Should(HaveLen(2))
+			Ω(routes[0].Resource).Should(Equal("test"))
+			Ω(routes[0].Action).Should(Equal("show"))
+		})
+
+		It("returns route conflicts as errors", func() {
+			err := s.ServeFiles("/files/*name", "/tmp")
+			Ω(err).Should(BeAssignableToTypeOf(&goa.RouteConflictError{}))
+			Ω(s.Mux.(goa.RouteLister).Routes()).Should(HaveLen(2))
+		})
+	})
+
+	Describe("OnStart and OnStop", func() {
+		var calls []string
+		var l net.Listener
+
+		hook := func(name string, err error) func(context.Context) error {
+			return func(context.Context) error {
+				calls = append(calls, name)
+				return err
+			}
+		}
+
+		BeforeEach(func() {
+			calls = nil
+			var err error
+			l, err = net.Listen("tcp", "127.0.0.1:0")
+			Ω(err).ShouldNot(HaveOccurred())
+			s.OnStart(hook("start1", nil))
+			s.OnStart(hook("start2", nil))
+			s.OnStop(hook("stop1", fmt.Errorf("stop1")))
+			s.OnStop(hook("stop2", nil))
+		})
+
+		It("runs the hooks around the server in registration order", func() {
+			done := make(chan error)
+			go func() { done <- s.Serve(l) }()
+			Eventually(func() error {
+				_, err := http.Get("http://" + l.Addr().String())
+				return err
+			}).ShouldNot(HaveOccurred())
+			Ω(s.Shutdown(context.Background())).Should(MatchError("stop1"))
+			Ω(<-done).Should(Equal(http.ErrServerClosed))
+			Ω(s.Shutdown(context.Background())).Should(MatchError("stop1"))
+			Ω(calls).Should(Equal([]string{"start1", "start2", "stop1", "stop2"}))
+		})
+
+		It("does not start the server if a start hook fails", func() {
+			s.OnStart(hook("start3", fmt.Errorf("start3")))
+			s.OnStart(hook("start4", nil))
+			Ω(s.Serve(l)).Should(MatchError("start3"))
+			Ω(calls).Should(Equal([]string{"start1", "start2", "start3"}))
+			l.Close()
+		})
+	})
+
+	Describe("Run", func() {
+		var addr string
+		var stopped bool
+		var ctx context.Context
+		var cancel context.CancelFunc
+		var done chan error
+
+		BeforeEach(func() {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			Ω(err).ShouldNot(HaveOccurred())
+			addr = l.Addr().String()
+			l.Close()
+			stopped = false
+			s.OnStop(func(context.Context) error {
+				stopped = true
+				return nil
+			})
+			ctx, cancel = context.WithCancel(context.Background())
+			done = make(chan error)
+		})
+
+		JustBeforeEach(func() {
+			go func() { done <- s.Run(ctx, addr) }()
+			Eventually(func() error {
+				_, err := http.Get("http://" + addr + "/health")
+				return err
+			}).ShouldNot(HaveOccurred())
+		})
+
+		It("shuts down when the context is canceled", func() {
+			cancel()
+			Eventually(done).Should(Receive(BeNil()))
+			Ω(stopped).Should(BeTrue())
+		})
+
+		Context("with a request that outlives the drain timeout", func() {
+			var started chan struct{}
+
+			BeforeEach(func() {
+				s.DrainTimeout = 50 * time.Millisecond
+				started = make(chan struct{})
+				ctrl := s.NewController("test")
+				h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					close(started)
+					<-ctx.Done()
+					return nil
+				}
+				s.Mux.Handle("GET", "/slow", ctrl.MuxHandler("slow", h, nil))
+			})
+
+			It("reports the interrupted requests", func() {
+				go http.Get("http://" + addr + "/slow")
+				Eventually(started).Should(BeClosed())
+				cancel()
+				var err error
+				Eventually(done).Should(Receive(&err))
+				Ω(err).Should(Equal(&goa.ShutdownError{Interrupted: 1}))
+				Ω(stopped).Should(BeTrue())
+			})
+		})
+	})
+
 	Describe("NotFound", func() {
 		var rw *TestResponseWriter
 		var req *http.Request
@@ -55,6 +246,17 @@ var _ = Describe("Service", func() {
 			Ω(string(rw.Body)).Should(MatchRegexp(`{"id":".*","code":"not_found","status":404,"detail":"/foo"}` + "\n"))
 		})
 
+		Context("with a problem registry", func() {
+			BeforeEach(func() {
+				s.Problems = goa.NewProblemRegistry()
+			})
+
+			It("renders problem details", func() {
+				Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal(goa.ProblemMediaIdentifier))
+				Ω(string(rw.Body)).Should(MatchRegexp(`{"type":"about:blank","title":"Not Found","status":404,"detail":"/foo","instance":"/foo","id":".*","code":"not_found"}` + "\n"))
+			})
+		})
+
 		Context("with middleware", func() {
 			middlewareCalled := false
 
@@ -103,9 +305,37 @@ var _ = Describe("Service", func() {
 
 		It("handles requests with wrong method but existing endpoint", func() {
 			Ω(rw.Status).Should(Equal(405))
-			Ω(rw.Header().Get("Allow")).Should(Or(Equal("POST, PUT"), Equal("PUT, POST")))
+			Ω(rw.Header().Get("Allow")).Should(Equal("POST, PUT"))
 			Ω(string(rw.Body)).Should(MatchRegexp(`{"id":".*","code":"method_not_allowed","status":405,"detail":".*","meta":{.*}}` + "\n"))
 		})
+
+		Context("with multiple paths", func() {
+			BeforeEach(func() {
+				s.Mux.Handle("DELETE", "/bar", func(rw http.ResponseWriter, req *http.Request, vals url.Values) {})
+				first := &TestResponseWriter{ParentHeader: http.Header{}}
+				s.Mux.ServeHTTP(first, req)
+				req, _ = http.NewRequest("GET", "/bar", nil)
+			})
+
+			It("uses the allowed methods of the request path", func() {
+				Ω(rw.Status).Should(Equal(405))
+				Ω(rw.Header().Get("Allow")).Should(Equal("DELETE"))
+			})
+		})
+
+		Context("with a custom error", func() {
+			BeforeEach(func() {
+				s.MethodNotAllowed = func(req *http.Request, allowed []string) error {
+					return goa.ErrBadRequest(fmt.Sprintf("use %v", allowed))
+				}
+			})
+
+			It("uses the custom error", func() {
+				Ω(rw.Status).Should(Equal(405))
+				Ω(rw.Header().Get("Allow")).Should(Equal("POST, PUT"))
+				Ω(string(rw.Body)).Should(MatchRegexp(`"detail":"use \[POST PUT\]"`))
+			})
+		})
 	})
 
 	Describe("MaxRequestBodyLength", func() {
@@ -360,6 +590,59 @@ var _ = Describe("Service", func() {
 					})
 				})
 
+				Context("with the strict JSON decoder", func() {
+					type payload struct {
+						Name string `json:"name"`
+					}
+
+					BeforeEach(func() {
+						s.Decoder.Register(goa.NewStrictJSONDecoder, "application/json")
+						unmarshaler = func(c context.Context, service *goa.Service, req *http.Request) error {
+							ctx = c
+							var p payload
+							if err := service.DecodeRequest(req, &p); err != nil {
+								return err
+							}
+							goa.ContextRequest(ctx).Payload = p
+							return nil
+						}
+					})
+
+					It("rejects the unknown keys", func() {
+						tw := rw.(*TestResponseWriter)
+						Ω(tw.Status).Should(Equal(400))
+						Ω(string(tw.Body)).Should(ContainSubstring(`unknown field "hello"`))
+					})
+
+					Context("and a payload with known keys only", func() {
+						BeforeEach(func() {
+							content := []byte(`{"name": "goa"}`)
+							r.Body = ioutil.NopCloser(bytes.NewReader(content))
+							r.ContentLength = int64(len(content))
+						})
+
+						It("decodes the payload", func() {
+							Ω(goa.ContextRequest(ctx).Payload).Should(Equal(payload{Name: "goa"}))
+						})
+					})
+				})
+
+				Context("with the json.Number decoder", func() {
+					BeforeEach(func() {
+						s.Decoder.Register(goa.NewJSONNumberDecoder, "application/json")
+						content := []byte(`{"id": 9007199254740993, "amount": 12.10}`)
+						r.Body = ioutil.NopCloser(bytes.NewReader(content))
+						r.ContentLength = int64(len(content))
+					})
+
+					It("preserves the precision of the numbers", func() {
+						Ω(goa.ContextRequest(ctx).Payload).Should(Equal(map[string]interface{}{
+							"id":     json.Number("9007199254740993"),
+							"amount": json.Number("12.10"),
+						}))
+					})
+				})
+
 				Context("with a Content-Type of 'application/octet-stream' or any other and no default decoder", func() {
 					BeforeEach(func() {
 						s = goa.New("test")
@@ -375,6 +658,141 @@ var _ = Describe("Service", func() {
 		})
 	})
 
+	Describe("FileHandler", func() {
+		var dir string
+		var req *http.Request
+		var rw *httptest.ResponseRecorder
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "goa-files")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(ioutil.WriteFile(filepath.Join(dir, "video.bin"), []byte("0123456789"), 0644)).ShouldNot(HaveOccurred())
+			ctrl := s.NewController("files")
+			ctrl.FileCacheControl = "public, max-age=60"
+			Ω(ctrl.ServeFiles("/files/*filepath", dir)).ShouldNot(HaveOccurred())
+			req = httptest.NewRequest("GET", "/files/video.bin", nil)
+			rw = httptest.NewRecorder()
+		})
+
+		JustBeforeEach(func() {
+			s.Mux.ServeHTTP(rw, req)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		Context("with a Range header", func() {
+			BeforeEach(func() {
+				req.Header.Set("Range", "bytes=2-4")
+			})
+
+			It("serves the range", func() {
+				Ω(rw.Code).Should(Equal(http.StatusPartialContent))
+				Ω(rw.Header().Get("Content-Range")).Should(Equal("bytes 2-4/10"))
+				Ω(rw.Body.String()).Should(Equal("234"))
+			})
+		})
+
+		Context("with an unsatisfiable Range header", func() {
+			BeforeEach(func() {
+				req.Header.Set("Range", "bytes=20-30")
+			})
+
+			It("responds with 416", func() {
+				Ω(rw.Code).Should(Equal(http.StatusRequestedRangeNotSatisfiable))
+			})
+		})
+
+		It("sets the cache headers", func() {
+			Ω(rw.Code).Should(Equal(http.StatusOK))
+			Ω(rw.Header().Get("Cache-Control")).Should(Equal("public, max-age=60"))
+			Ω(rw.Header().Get("Last-Modified")).ShouldNot(BeEmpty())
+			Ω(rw.Header().Get("Etag")).ShouldNot(BeEmpty())
+		})
+
+		Context("with the file ETag", func() {
+			BeforeEach(func() {
+				first := httptest.NewRecorder()
+				s.Mux.ServeHTTP(first, httptest.NewRequest("GET", "/files/video.bin", nil))
+				Ω(first.Header().Get("Etag")).ShouldNot(BeEmpty())
+				req.Header.Set("If-None-Match", first.Header().Get("Etag"))
+			})
+
+			It("responds with 304", func() {
+				Ω(rw.Code).Should(Equal(http.StatusNotModified))
+				Ω(rw.Body.Len()).Should(BeZero())
+			})
+		})
+
+		Context("with the file modification date", func() {
+			BeforeEach(func() {
+				req.Header.Set("If-Modified-Since", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+			})
+
+			It("responds with 304", func() {
+				Ω(rw.Code).Should(Equal(http.StatusNotModified))
+			})
+		})
+
+		Context("with an outdated If-Range header", func() {
+			BeforeEach(func() {
+				req.Header.Set("Range", "bytes=2-4")
+				req.Header.Set("If-Range", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+			})
+
+			It("serves the whole file", func() {
+				Ω(rw.Code).Should(Equal(http.StatusOK))
+				Ω(rw.Body.String()).Should(Equal("0123456789"))
+			})
+		})
+	})
+
+	Describe("ServeFilesFS", func() {
+		var rw *httptest.ResponseRecorder
+		var path string
+
+		BeforeEach(func() {
+			fsys := fstest.MapFS{
+				"public/assets/app.css":         {Data: []byte("body{}")},
+				"public/assets/docs/index.html": {Data: []byte("<html></html>")},
+			}
+			Ω(s.ServeFilesFS("/assets/*filepath", fsys, "public/assets")).ShouldNot(HaveOccurred())
+			rw = httptest.NewRecorder()
+		})
+
+		JustBeforeEach(func() {
+			s.Mux.ServeHTTP(rw, httptest.NewRequest("GET", path, nil))
+		})
+
+		Context("with a file path", func() {
+			BeforeEach(func() {
+				path = "/assets/app.css"
+			})
+
+			It("serves the file", func() {
+				Ω(rw.Code).Should(Equal(200))
+				Ω(rw.Body.String()).Should(Equal("body{}"))
+			})
+
+			It("sets the ETag from the file content", func() {
+				Ω(rw.Header().Get("Etag")).Should(MatchRegexp(`^"[0-9a-f]{32}"$`))
+			})
+		})
+
+		Context("with a directory path", func() {
+			BeforeEach(func() {
+				path = "/assets/docs"
+			})
+
+			It("serves the index", func() {
+				Ω(rw.Code).Should(Equal(200))
+				Ω(rw.Body.String()).Should(Equal("<html></html>"))
+			})
+		})
+	})
+
 	//Describe("FileHandler", func() {
 	//	const publicPath = "github.com/kyokomi/goa-v1/public"
 	//
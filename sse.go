@@ -0,0 +1,201 @@
+package goa
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// SSEEvent is an event sent by a SSEWriter.
+	SSEEvent struct {
+		// ID sets the last event ID of the client, it is sent back by the client in the
+		// Last-Event-ID header when it reconnects.
+		ID string
+		// Event is the event type, the client dispatches the event to the listeners of that
+		// type. Empty means "message".
+		Event string
+		// Data is the event payload. Strings and byte slices are sent as is, other values
+		// are encoded to JSON with the codec set with SetJSONCodec.
+		Data interface{}
+		// Retry sets the reconnection delay of the client if not zero.
+		Retry time.Duration
+	}
+
+	// SSEWriter writes Server-Sent Events to a response, see
+	// https://html.spec.whatwg.org/multipage/server-sent-events.html. It takes care of the
+	// event framing and flushes the response after each event so that the client receives
+	// the events as they are sent. A SSEWriter is safe for concurrent use.
+	SSEWriter struct {
+		rw        http.ResponseWriter
+		done      <-chan struct{}
+		heartbeat time.Duration
+		mu        sync.Mutex
+		err       error
+		closed    chan struct{}
+		closeOnce sync.Once
+	}
+
+	// SSEOption is a SSEWriter constructor option.
+	SSEOption func(*SSEWriter) *SSEWriter
+)
+
+// SSEHeartbeat makes the SSEWriter send a comment line every interval d so that proxies do not
+// close idle streams and disconnected clients are detected. The default is 15 seconds, zero
+// disables the heartbeat.
+func SSEHeartbeat(d time.Duration) SSEOption {
+	return func(w *SSEWriter) *SSEWriter {
+		w.heartbeat = d
+		return w
+	}
+}
+
+// NewSSEWriter writes the headers of a Server-Sent Events response with the given status code to
+// the response of the given request context and returns a writer for the events. The generated
+// response helpers of the actions whose responses use the "text/event-stream" media type call
+// NewSSEWriter so that controllers only have to send the events:
+//
+//	stream, err := ctx.OK()
+//	if err != nil {
+//		return err
+//	}
+//	defer stream.Close()
+//	for n := range notifications {
+//		if err := stream.Send(&goa.SSEEvent{Event: "notification", Data: n}); err != nil {
+//			return err
+//		}
+//	}
+//
+// NewSSEWriter returns an error if the response writer cannot be flushed.
+func NewSSEWriter(ctx context.Context, status int, options ...SSEOption) (*SSEWriter, error) {
+	resp := ContextResponse(ctx)
+	if resp == nil {
+		return nil, fmt.Errorf("no response in context")
+	}
+	if _, ok := resp.ResponseWriter.(http.Flusher); !ok {
+		return nil, fmt.Errorf("response writer does not support flushing")
+	}
+	w := &SSEWriter{
+		rw:        resp,
+		done:      ctx.Done(),
+		heartbeat: 15 * time.Second,
+		closed:    make(chan struct{}),
+	}
+	if req := ContextRequest(ctx); req != nil {
+		w.done = req.Context().Done()
+	}
+	for _, option := range options {
+		w = option(w)
+	}
+	h := resp.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no") // disables nginx buffering
+	h.Del("Content-Length")
+	resp.WriteHeader(status)
+	resp.Flush()
+	if w.heartbeat > 0 {
+		go w.beat()
+	}
+	return w, nil
+}
+
+// Send writes the given event and flushes the response. It returns the context error if the
+// client disconnected and the first write error if any.
+func (w *SSEWriter) Send(e *SSEEvent) error {
+	var buf bytes.Buffer
+	if e.ID != "" {
+		writeSSEField(&buf, "id", e.ID)
+	}
+	if e.Event != "" {
+		writeSSEField(&buf, "event", e.Event)
+	}
+	if e.Retry > 0 {
+		writeSSEField(&buf, "retry", strconv.FormatInt(int64(e.Retry/time.Millisecond), 10))
+	}
+	var data string
+	switch d := e.Data.(type) {
+	case nil:
+	case string:
+		data = d
+	case []byte:
+		data = string(d)
+	default:
+		b, err := GetJSONCodec().Marshal(d)
+		if err != nil {
+			return err
+		}
+		data = string(b)
+	}
+	if e.Data != nil {
+		for _, line := range strings.Split(strings.Replace(data, "\r\n", "\n", -1), "\n") {
+			writeSSEField(&buf, "data", line)
+		}
+	}
+	buf.WriteByte('\n')
+	return w.write(buf.Bytes())
+}
+
+// Close stops the heartbeat, no event may be sent after Close returns. It does not write to the
+// response, the stream ends when the action handler returns.
+func (w *SSEWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closeOnce.Do(func() { close(w.closed) })
+	return nil
+}
+
+// write writes the given bytes and flushes the response.
+func (w *SSEWriter) write(b []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	select {
+	case <-w.done:
+		w.err = context.Canceled
+		return w.err
+	case <-w.closed:
+		w.err = fmt.Errorf("write on closed SSE writer")
+		return w.err
+	default:
+	}
+	if _, err := w.rw.Write(b); err != nil {
+		w.err = err
+		return err
+	}
+	w.rw.(http.Flusher).Flush()
+	return nil
+}
+
+// beat sends the heartbeat comments until the writer is closed or the client disconnects.
+func (w *SSEWriter) beat() {
+	ticker := time.NewTicker(w.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.write([]byte(": heartbeat\n\n")); err != nil {
+				return
+			}
+		case <-w.done:
+			return
+		case <-w.closed:
+			return
+		}
+	}
+}
+
+// writeSSEField writes a field line of an event.
+func writeSSEField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	buf.WriteString(": ")
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
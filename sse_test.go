@@ -0,0 +1,95 @@
+package goa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1"
+)
+
+// syncRecorder is a response recorder safe for concurrent use.
+type syncRecorder struct {
+	mu sync.Mutex
+	*httptest.ResponseRecorder
+}
+
+func (r *syncRecorder) Write(b []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ResponseRecorder.Write(b)
+}
+
+func (r *syncRecorder) body() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.Body.String()
+}
+
+var _ = Describe("SSEWriter", func() {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	var rw *syncRecorder
+	var options []goa.SSEOption
+	var stream *goa.SSEWriter
+
+	BeforeEach(func() {
+		options = nil
+		rw = &syncRecorder{ResponseRecorder: httptest.NewRecorder()}
+	})
+
+	JustBeforeEach(func() {
+		var rctx context.Context
+		rctx, cancel = context.WithCancel(context.Background())
+		req, _ := http.NewRequest("GET", "/events", nil)
+		req = req.WithContext(rctx)
+		ctx = goa.NewContext(context.Background(), rw, req, nil)
+		var err error
+		stream, err = goa.NewSSEWriter(ctx, 200, options...)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		stream.Close()
+		cancel()
+	})
+
+	It("writes the stream headers", func() {
+		Ω(rw.Code).Should(Equal(200))
+		Ω(rw.Header().Get("Content-Type")).Should(Equal("text/event-stream"))
+		Ω(rw.Header().Get("Cache-Control")).Should(Equal("no-cache"))
+		Ω(rw.Flushed).Should(BeTrue())
+	})
+
+	It("frames the events", func() {
+		Ω(stream.Send(&goa.SSEEvent{ID: "1", Event: "update", Data: "a\nb", Retry: 2 * time.Second})).ShouldNot(HaveOccurred())
+		Ω(stream.Send(&goa.SSEEvent{Data: map[string]int{"n": 1}})).ShouldNot(HaveOccurred())
+		Ω(rw.body()).Should(Equal("id: 1\nevent: update\nretry: 2000\ndata: a\ndata: b\n\ndata: {\"n\":1}\n\n"))
+	})
+
+	It("returns an error once the client disconnected", func() {
+		cancel()
+		Ω(stream.Send(&goa.SSEEvent{Data: "x"})).Should(Equal(context.Canceled))
+		Ω(rw.body()).Should(BeEmpty())
+	})
+
+	It("refuses to send after Close", func() {
+		stream.Close()
+		Ω(stream.Send(&goa.SSEEvent{Data: "x"})).Should(HaveOccurred())
+	})
+
+	Context("with a heartbeat", func() {
+		BeforeEach(func() {
+			options = []goa.SSEOption{goa.SSEHeartbeat(10 * time.Millisecond)}
+		})
+
+		It("sends comments periodically", func() {
+			Eventually(rw.body).Should(ContainSubstring(": heartbeat\n\n"))
+		})
+	})
+})
@@ -0,0 +1,198 @@
+package goa
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type (
+	// StreamWriter encodes a sequence of values to a response with the encoder negotiated from
+	// the request Accept header, e.g. one JSON document per line with the default JSON encoder,
+	// or as the elements of a JSON array, see StreamArray. The encoded values are buffered and
+	// flushed to the client in batches, see Stream. A StreamWriter is not safe for concurrent
+	// use.
+	StreamWriter struct {
+		rw        http.ResponseWriter
+		buf       bytes.Buffer
+		enc       Encoder
+		done      <-chan struct{}
+		err       error
+		size      int
+		interval  time.Duration
+		lastFlush time.Time
+		array     bool
+		count     int
+		status    int
+		started   bool
+		closed    bool
+	}
+
+	// StreamOption is a Stream option.
+	StreamOption func(*StreamWriter) *StreamWriter
+)
+
+// StreamFlushSize sets the amount of encoded data in bytes that causes the stream to be
+// flushed, 32KB by default.
+func StreamFlushSize(n int) StreamOption {
+	return func(s *StreamWriter) *StreamWriter {
+		s.size = n
+		return s
+	}
+}
+
+// StreamFlushInterval sets the maximum duration between two flushes of the stream while values
+// are being encoded, 1 second by default.
+func StreamFlushInterval(d time.Duration) StreamOption {
+	return func(s *StreamWriter) *StreamWriter {
+		s.interval = d
+		return s
+	}
+}
+
+// StreamArray makes the stream encode the values as the elements of a JSON array with the codec
+// set with SetJSONCodec regardless of the request Accept header, so that large collections are
+// sent without building the whole slice and its encoding in memory. The array is terminated by
+// Close, the response is "[]" if no value is encoded.
+func StreamArray() StreamOption {
+	return func(s *StreamWriter) *StreamWriter {
+		s.array = true
+		return s
+	}
+}
+
+// StreamStatus sets the status code of the response, it is written with the first flush. The
+// response status is 200 by default.
+func StreamStatus(code int) StreamOption {
+	return func(s *StreamWriter) *StreamWriter {
+		s.status = code
+		return s
+	}
+}
+
+// Stream returns a writer that encodes values to the given response writer using the service
+// encoder negotiated for the request of the given context. It sets the response Content-Type
+// header to the negotiated content type unless it is already set. The encoded data is flushed
+// to the client when its size reaches StreamFlushSize or when a value is encoded more than
+// StreamFlushInterval after the last flush. The writer stops encoding and returns the context
+// error as soon as the client disconnects so that large exports abort cleanly:
+//
+//	s, err := goa.Stream(ctx, ctx.ResponseData)
+//	if err != nil {
+//		return err
+//	}
+//	for rows.Next() {
+//		...
+//		if err := s.Encode(row); err != nil {
+//			return err
+//		}
+//	}
+//	return s.Close()
+func Stream(ctx context.Context, w http.ResponseWriter, options ...StreamOption) (*StreamWriter, error) {
+	resp := ContextResponse(ctx)
+	if resp == nil || resp.Service == nil {
+		return nil, fmt.Errorf("no response data in context")
+	}
+	s := &StreamWriter{
+		rw:        w,
+		done:      ctx.Done(),
+		size:      32 * 1024,
+		interval:  time.Second,
+		lastFlush: time.Now(),
+	}
+	var accept string
+	if req := ContextRequest(ctx); req != nil {
+		accept = req.Header.Get("Accept")
+		s.done = req.Context().Done()
+	}
+	for _, option := range options {
+		s = option(s)
+	}
+	if s.array {
+		s.enc = GetJSONCodec().NewEncoder(&s.buf)
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		return s, nil
+	}
+	enc, contentType, err := resp.Service.Encoder.NewEncoder(&s.buf, accept)
+	if err != nil {
+		return nil, err
+	}
+	s.enc = enc
+	if w.Header().Get("Content-Type") == "" && !strings.Contains(contentType, "*") {
+		w.Header().Set("Content-Type", contentType)
+	}
+	return s, nil
+}
+
+// Encode encodes the given value to the stream. It returns the context error if the client
+// disconnected and the first encoding or write error if any.
+func (s *StreamWriter) Encode(v interface{}) error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.closed {
+		return fmt.Errorf("encode on closed stream")
+	}
+	select {
+	case <-s.done:
+		s.err = context.Canceled
+		return s.err
+	default:
+	}
+	if s.array {
+		if s.count == 0 {
+			s.buf.WriteByte('[')
+		} else {
+			s.buf.WriteByte(',')
+		}
+		s.count++
+	}
+	if err := s.enc.Encode(v); err != nil {
+		s.err = err
+		return err
+	}
+	if s.buf.Len() >= s.size || time.Since(s.lastFlush) >= s.interval {
+		return s.Flush()
+	}
+	return nil
+}
+
+// Flush writes the buffered data to the client.
+func (s *StreamWriter) Flush() error {
+	if s.err != nil {
+		return s.err
+	}
+	if !s.started {
+		if s.status != 0 {
+			s.rw.WriteHeader(s.status)
+		}
+		s.started = true
+	}
+	if _, err := s.buf.WriteTo(s.rw); err != nil {
+		s.err = err
+		return err
+	}
+	if f, ok := s.rw.(http.Flusher); ok {
+		f.Flush()
+	}
+	s.lastFlush = time.Now()
+	return nil
+}
+
+// Close terminates the JSON array if any and flushes the remaining buffered data, the stream ends
+// when the action handler returns.
+func (s *StreamWriter) Close() error {
+	if s.array && !s.closed && s.err == nil {
+		if s.count == 0 {
+			s.buf.WriteByte('[')
+		}
+		s.buf.WriteByte(']')
+	}
+	s.closed = true
+	return s.Flush()
+}
@@ -0,0 +1,114 @@
+package goa_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1"
+)
+
+var _ = Describe("Stream", func() {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	var rw *httptest.ResponseRecorder
+	var options []goa.StreamOption
+	var s *goa.StreamWriter
+
+	BeforeEach(func() {
+		options = nil
+		rw = httptest.NewRecorder()
+	})
+
+	JustBeforeEach(func() {
+		var rctx context.Context
+		rctx, cancel = context.WithCancel(context.Background())
+		req, _ := http.NewRequest("GET", "/export", nil)
+		req.Header.Set("Accept", "application/json")
+		req = req.WithContext(rctx)
+		ctx = goa.NewContext(context.Background(), rw, req, nil)
+		service := goa.New("test")
+		service.Encoder.Register(goa.NewJSONEncoder, "application/json")
+		goa.ContextResponse(ctx).Service = service
+		var err error
+		s, err = goa.Stream(ctx, goa.ContextResponse(ctx), options...)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("encodes the values with the negotiated encoder", func() {
+		Ω(s.Encode(map[string]int{"n": 1})).ShouldNot(HaveOccurred())
+		Ω(s.Encode(map[string]int{"n": 2})).ShouldNot(HaveOccurred())
+		Ω(rw.Body.String()).Should(BeEmpty())
+		Ω(s.Close()).ShouldNot(HaveOccurred())
+		Ω(rw.Header().Get("Content-Type")).Should(Equal("application/json"))
+		Ω(rw.Body.String()).Should(Equal("{\"n\":1}\n{\"n\":2}\n"))
+		Ω(rw.Flushed).Should(BeTrue())
+	})
+
+	It("aborts once the client disconnected", func() {
+		cancel()
+		Ω(s.Encode(1)).Should(Equal(context.Canceled))
+		Ω(s.Close()).Should(Equal(context.Canceled))
+		Ω(rw.Body.String()).Should(BeEmpty())
+	})
+
+	Context("with a flush size", func() {
+		BeforeEach(func() {
+			options = []goa.StreamOption{goa.StreamFlushSize(6)}
+		})
+
+		It("flushes when the size is reached", func() {
+			Ω(s.Encode("a")).ShouldNot(HaveOccurred())
+			Ω(rw.Body.String()).Should(BeEmpty())
+			Ω(s.Encode("b")).ShouldNot(HaveOccurred())
+			Ω(rw.Body.String()).Should(Equal("\"a\"\n\"b\"\n"))
+		})
+	})
+
+	Context("with a flush interval", func() {
+		BeforeEach(func() {
+			options = []goa.StreamOption{goa.StreamFlushInterval(10 * time.Millisecond)}
+		})
+
+		It("flushes when the interval elapsed", func() {
+			time.Sleep(20 * time.Millisecond)
+			Ω(s.Encode("a")).ShouldNot(HaveOccurred())
+			Ω(rw.Body.String()).Should(Equal("\"a\"\n"))
+		})
+	})
+
+	Context("with the array option", func() {
+		BeforeEach(func() {
+			options = []goa.StreamOption{goa.StreamArray(), goa.StreamStatus(206), goa.StreamFlushSize(8)}
+		})
+
+		It("encodes the values as a JSON array", func() {
+			Ω(s.Encode(map[string]int{"n": 1})).ShouldNot(HaveOccurred())
+			Ω(rw.Body.String()).Should(Equal("[{\"n\":1}\n"))
+			Ω(rw.Code).Should(Equal(206))
+			Ω(s.Encode(map[string]int{"n": 2})).ShouldNot(HaveOccurred())
+			Ω(s.Close()).ShouldNot(HaveOccurred())
+			Ω(rw.Header().Get("Content-Type")).Should(Equal("application/json"))
+			var v []map[string]int
+			Ω(json.Unmarshal(rw.Body.Bytes(), &v)).ShouldNot(HaveOccurred())
+			Ω(v).Should(Equal([]map[string]int{{"n": 1}, {"n": 2}}))
+			Ω(s.Encode(3)).Should(HaveOccurred())
+		})
+
+		It("writes an empty array when no value is encoded", func() {
+			Ω(s.Close()).ShouldNot(HaveOccurred())
+			Ω(s.Close()).ShouldNot(HaveOccurred())
+			Ω(rw.Body.String()).Should(Equal("[]"))
+			Ω(rw.Code).Should(Equal(206))
+		})
+	})
+})
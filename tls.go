@@ -0,0 +1,125 @@
+package goa
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLSOption is a TLS configuration option, see ConfigureTLS.
+type TLSOption func(*tls.Config) *tls.Config
+
+// TLSMinVersion sets the minimum TLS version accepted by the server, e.g. tls.VersionTLS13.
+// The default is TLS 1.2.
+func TLSMinVersion(v uint16) TLSOption {
+	return func(c *tls.Config) *tls.Config {
+		c.MinVersion = v
+		return c
+	}
+}
+
+// TLSCipherSuites sets the cipher suites accepted by the server for TLS 1.2 connections. The
+// default is the list of ECDHE suites with AEAD ciphers. The TLS 1.3 suites are not configurable.
+func TLSCipherSuites(ids ...uint16) TLSOption {
+	return func(c *tls.Config) *tls.Config {
+		c.CipherSuites = ids
+		return c
+	}
+}
+
+// TLSClientCAs requires the clients to present a certificate signed by one of the authorities
+// in the given pool (mutual TLS).
+func TLSClientCAs(pool *x509.CertPool) TLSOption {
+	return func(c *tls.Config) *tls.Config {
+		c.ClientCAs = pool
+		c.ClientAuth = tls.RequireAndVerifyClientCert
+		return c
+	}
+}
+
+// ConfigureTLS applies the given options to the TLS configuration of the service server.
+// The configuration starts with modern defaults: TLS 1.2 minimum, ECDHE key exchanges and AEAD
+// ciphers only. The configuration is used by ListenAndServeTLS and ListenAndServeAutoTLS.
+func (service *Service) ConfigureTLS(options ...TLSOption) {
+	c := service.tlsConfig()
+	for _, option := range options {
+		c = option(c)
+	}
+	service.Server.TLSConfig = c
+}
+
+// ListenAndServeAutoTLS starts a HTTPS server listening on port 443 with certificates obtained
+// from Let's Encrypt for the given domains. The certificates are cached in AutoTLSCache and
+// renewed automatically. The domain ownership is verified with the TLS-ALPN-01 challenge so that
+// the server does not need to listen on port 80.
+func (service *Service) ListenAndServeAutoTLS(domains ...string) error {
+	cache := service.AutoTLSCache
+	if cache == nil {
+		cache = autocert.DirCache(defaultAutoTLSCacheDir())
+	}
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Cache:      cache,
+	}
+	service.Server.TLSConfig = service.autoTLSConfig(m.GetCertificate)
+	return service.serve(func() error {
+		service.LogInfo("listen", "transport", "https", "addr", ":443", "domains", domains)
+		service.Server.Addr = ":443"
+		return service.Server.ListenAndServeTLS("", "")
+	})
+}
+
+// autoTLSConfig returns a copy of the TLS configuration of the server that obtains the
+// certificates with getCertificate and accepts the TLS-ALPN-01 challenge.
+func (service *Service) autoTLSConfig(getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)) *tls.Config {
+	c := service.tlsConfig()
+	c.GetCertificate = getCertificate
+	for _, proto := range []string{"h2", "http/1.1", acme.ALPNProto} {
+		found := false
+		for _, p := range c.NextProtos {
+			if p == proto {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.NextProtos = append(c.NextProtos, proto)
+		}
+	}
+	return c
+}
+
+// tlsConfig returns a copy of the TLS configuration of the server if any or the default
+// configuration.
+func (service *Service) tlsConfig() *tls.Config {
+	if service.Server.TLSConfig != nil {
+		return service.Server.TLSConfig.Clone()
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+	}
+}
+
+// defaultAutoTLSCacheDir returns the directory used to cache the certificates obtained by
+// ListenAndServeAutoTLS when AutoTLSCache is not set.
+func defaultAutoTLSCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "autocert"
+	}
+	return filepath.Join(dir, "goa", "autocert")
+}
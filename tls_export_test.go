@@ -0,0 +1,4 @@
+package goa
+
+// Export internal functions for testing.
+var AutoTLSConfig = (*Service).autoTLSConfig
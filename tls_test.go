@@ -0,0 +1,90 @@
+package goa_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kyokomi/goa-v1"
+)
+
+var _ = Describe("ConfigureTLS", func() {
+	var service *goa.Service
+	var options []goa.TLSOption
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		options = nil
+	})
+
+	JustBeforeEach(func() {
+		service.ConfigureTLS(options...)
+	})
+
+	It("uses modern defaults", func() {
+		c := service.Server.TLSConfig
+		Ω(c).ShouldNot(BeNil())
+		Ω(c.MinVersion).Should(Equal(uint16(tls.VersionTLS12)))
+		Ω(c.CipherSuites).ShouldNot(ContainElement(tls.TLS_RSA_WITH_AES_128_CBC_SHA))
+		Ω(c.CipherSuites).Should(ContainElement(tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256))
+	})
+
+	Context("with options", func() {
+		var pool *x509.CertPool
+
+		BeforeEach(func() {
+			pool = x509.NewCertPool()
+			options = []goa.TLSOption{
+				goa.TLSMinVersion(tls.VersionTLS13),
+				goa.TLSCipherSuites(tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384),
+				goa.TLSClientCAs(pool),
+			}
+		})
+
+		It("applies the options", func() {
+			c := service.Server.TLSConfig
+			Ω(c.MinVersion).Should(Equal(uint16(tls.VersionTLS13)))
+			Ω(c.CipherSuites).Should(Equal([]uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}))
+			Ω(c.ClientCAs).Should(BeIdenticalTo(pool))
+			Ω(c.ClientAuth).Should(Equal(tls.RequireAndVerifyClientCert))
+		})
+
+		It("keeps the configuration when called again", func() {
+			service.ConfigureTLS(goa.TLSMinVersion(tls.VersionTLS12))
+			c := service.Server.TLSConfig
+			Ω(c.MinVersion).Should(Equal(uint16(tls.VersionTLS12)))
+			Ω(c.ClientCAs).Should(BeIdenticalTo(pool))
+		})
+
+		It("does not modify the configuration set previously", func() {
+			c := service.Server.TLSConfig
+			service.ConfigureTLS(goa.TLSMinVersion(tls.VersionTLS12))
+			Ω(service.Server.TLSConfig).ShouldNot(BeIdenticalTo(c))
+			Ω(c.MinVersion).Should(Equal(uint16(tls.VersionTLS13)))
+		})
+	})
+})
+
+var _ = Describe("AutoTLSConfig", func() {
+	var service *goa.Service
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.Server.TLSConfig = &tls.Config{NextProtos: []string{"h2", "acme"}}
+	})
+
+	It("adds the missing protocols to a copy of the configuration", func() {
+		c := goa.AutoTLSConfig(service, nil)
+		Ω(c).ShouldNot(BeIdenticalTo(service.Server.TLSConfig))
+		Ω(c.NextProtos).Should(Equal([]string{"h2", "acme", "http/1.1", "acme-tls/1"}))
+		Ω(service.Server.TLSConfig.NextProtos).Should(Equal([]string{"h2", "acme"}))
+	})
+
+	It("does not duplicate the protocols when called again", func() {
+		service.Server.TLSConfig = goa.AutoTLSConfig(service, nil)
+		c := goa.AutoTLSConfig(service, nil)
+		Ω(c.NextProtos).Should(Equal([]string{"h2", "acme", "http/1.1", "acme-tls/1"}))
+	})
+})
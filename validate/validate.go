@@ -0,0 +1,111 @@
+/*
+Package validate implements the format and decimal validations shared by the goa runtime and the
+design package. It only depends on the standard library and on the goa uuid package so that it can
+be imported from both without the design package depending on the runtime.
+*/
+package validate
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kyokomi/goa-v1/uuid"
+)
+
+var (
+	// Regular expression used to validate RFC1035 hostnames*/
+	hostnameRegex = regexp.MustCompile(`^[[:alnum:]][[:alnum:]\-]{0,61}[[:alnum:]]|[[:alpha:]]$`)
+
+	// Simple regular expression for IPv4 values, more rigorous checking is done via net.ParseIP
+	ipv4Regex = regexp.MustCompile(`^(?:[0-9]{1,3}\.){3}[0-9]{1,3}$`)
+
+	// Regular expression used to validate decimal values
+	decimalRegex = regexp.MustCompile(`^[-+]?([0-9]+)(?:\.([0-9]+))?$`)
+)
+
+// KnownFormat returns true if f is one of the formats supported by Format.
+func KnownFormat(f string) bool {
+	switch f {
+	case "date", "date-time", "uuid", "email", "hostname", "ipv4", "ipv6", "ip", "uri", "mac",
+		"cidr", "regexp", "rfc1123", "decimal":
+		return true
+	}
+	return false
+}
+
+// Format validates a string against a standard format. It returns nil if the string conforms to
+// the format, an error otherwise. See goa.ValidateFormat for the list of supported formats.
+func Format(f string, val string) error {
+	var err error
+	switch f {
+	case "date":
+		_, err = time.Parse("2006-01-02", val)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, val)
+	case "uuid":
+		_, err = uuid.FromString(val)
+	case "email":
+		_, err = mail.ParseAddress(val)
+	case "hostname":
+		if !hostnameRegex.MatchString(val) {
+			err = fmt.Errorf("hostname value '%s' does not match %s",
+				val, hostnameRegex.String())
+		}
+	case "ipv4", "ipv6", "ip":
+		ip := net.ParseIP(val)
+		if ip == nil {
+			err = fmt.Errorf("\"%s\" is an invalid %s value", val, f)
+		}
+		if f == "ipv4" {
+			if !ipv4Regex.MatchString(val) {
+				err = fmt.Errorf("\"%s\" is an invalid ipv4 value", val)
+			}
+		}
+		if f == "ipv6" {
+			if ipv4Regex.MatchString(val) {
+				err = fmt.Errorf("\"%s\" is an invalid ipv6 value", val)
+			}
+		}
+	case "uri":
+		_, err = url.ParseRequestURI(val)
+	case "mac":
+		_, err = net.ParseMAC(val)
+	case "cidr":
+		_, _, err = net.ParseCIDR(val)
+	case "regexp":
+		_, err = regexp.Compile(val)
+	case "rfc1123":
+		_, err = time.Parse(time.RFC1123, val)
+	case "decimal":
+		err = Decimal(val, -1, -1)
+	default:
+		return fmt.Errorf("unknown format %#v", f)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s value, %s", f, err)
+	}
+	return nil
+}
+
+// Decimal returns an error if val is not a decimal number value or if it has more than precision
+// significant digits or more than scale digits after the decimal point.
+// A negative precision or scale means no limit.
+func Decimal(val string, precision, scale int) error {
+	m := decimalRegex.FindStringSubmatch(val)
+	if m == nil {
+		return fmt.Errorf("\"%s\" is an invalid decimal value", val)
+	}
+	intPart, fracPart := strings.TrimLeft(m[1], "0"), m[2]
+	if scale >= 0 && len(fracPart) > scale {
+		return fmt.Errorf("\"%s\" has more than %d digits after the decimal point", val, scale)
+	}
+	if precision >= 0 && len(intPart)+len(fracPart) > precision {
+		return fmt.Errorf("\"%s\" has more than %d significant digits", val, precision)
+	}
+	return nil
+}
@@ -1,15 +1,10 @@
 package goa
 
 import (
-	"fmt"
-	"net"
-	"net/mail"
-	"net/url"
 	"regexp"
 	"sync"
-	"time"
 
-	"github.com/kyokomi/goa-v1/uuid"
+	"github.com/kyokomi/goa-v1/validate"
 )
 
 // Format defines a validation format.
@@ -54,14 +49,9 @@ const (
 
 	// FormatRFC1123 defines RFC1123 date time values.
 	FormatRFC1123 = "rfc1123"
-)
-
-var (
-	// Regular expression used to validate RFC1035 hostnames*/
-	hostnameRegex = regexp.MustCompile(`^[[:alnum:]][[:alnum:]\-]{0,61}[[:alnum:]]|[[:alpha:]]$`)
 
-	// Simple regular expression for IPv4 values, more rigorous checking is done via net.ParseIP
-	ipv4Regex = regexp.MustCompile(`^(?:[0-9]{1,3}\.){3}[0-9]{1,3}$`)
+	// FormatDecimal defines arbitrary precision decimal values (e.g. "-12.345").
+	FormatDecimal = "decimal"
 )
 
 // ValidateFormat validates a string against a standard format.
@@ -80,55 +70,20 @@ var (
 //     - "cidr": RFC4632 and RFC4291 CIDR notation IP address value
 //     - "regexp": Regular expression syntax accepted by RE2
 //     - "rfc1123": RFC1123 date time value
+//     - "decimal": decimal number value (e.g. "-12.345")
 func ValidateFormat(f Format, val string) error {
-	var err error
-	switch f {
-	case FormatDate:
-		_, err = time.Parse("2006-01-02", val)
-	case FormatDateTime:
-		_, err = time.Parse(time.RFC3339, val)
-	case FormatUUID:
-		_, err = uuid.FromString(val)
-	case FormatEmail:
-		_, err = mail.ParseAddress(val)
-	case FormatHostname:
-		if !hostnameRegex.MatchString(val) {
-			err = fmt.Errorf("hostname value '%s' does not match %s",
-				val, hostnameRegex.String())
-		}
-	case FormatIPv4, FormatIPv6, FormatIP:
-		ip := net.ParseIP(val)
-		if ip == nil {
-			err = fmt.Errorf("\"%s\" is an invalid %s value", val, f)
-		}
-		if f == FormatIPv4 {
-			if !ipv4Regex.MatchString(val) {
-				err = fmt.Errorf("\"%s\" is an invalid ipv4 value", val)
-			}
-		}
-		if f == FormatIPv6 {
-			if ipv4Regex.MatchString(val) {
-				err = fmt.Errorf("\"%s\" is an invalid ipv6 value", val)
-			}
-		}
-	case FormatURI:
-		_, err = url.ParseRequestURI(val)
-	case FormatMAC:
-		_, err = net.ParseMAC(val)
-	case FormatCIDR:
-		_, _, err = net.ParseCIDR(val)
-	case FormatRegexp:
-		_, err = regexp.Compile(val)
-	case FormatRFC1123:
-		_, err = time.Parse(time.RFC1123, val)
-	default:
-		return fmt.Errorf("unknown format %#v", f)
-	}
-	if err != nil {
+	err := validate.Format(string(f), val)
+	if err != nil && validate.KnownFormat(string(f)) {
 		go IncrCounter([]string{"goa", "validation", "error", string(f)}, 1.0)
-		return fmt.Errorf("invalid %s value, %s", f, err)
 	}
-	return nil
+	return err
+}
+
+// ValidateDecimal returns an error if val is not a decimal number value or if it has more than
+// precision significant digits or more than scale digits after the decimal point.
+// A negative precision or scale means no limit.
+func ValidateDecimal(val string, precision, scale int) error {
+	return validate.Decimal(val, precision, scale)
 }
 
 // knownPatterns records the compiled patterns.
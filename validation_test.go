@@ -396,4 +396,80 @@ var _ = Describe("ValidateFormat", func() {
 			})
 		})
 	})
+
+	Context("Decimal", func() {
+		BeforeEach(func() {
+			f = goa.FormatDecimal
+		})
+
+		Context("with an invalid value", func() {
+			BeforeEach(func() {
+				val = "12.3e5"
+			})
+
+			It("does not validates", func() {
+				Ω(valErr).Should(HaveOccurred())
+			})
+		})
+
+		Context("with a valid value", func() {
+			BeforeEach(func() {
+				val = "-0012.3450"
+			})
+
+			It("validates", func() {
+				Ω(valErr).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+})
+
+var _ = Describe("ValidateDecimal", func() {
+	var val string
+	var precision, scale int
+	var valErr error
+
+	BeforeEach(func() {
+		val = "123.45"
+		precision = -1
+		scale = -1
+	})
+
+	JustBeforeEach(func() {
+		valErr = goa.ValidateDecimal(val, precision, scale)
+	})
+
+	It("validates values without constraints", func() {
+		Ω(valErr).ShouldNot(HaveOccurred())
+	})
+
+	Context("with a precision", func() {
+		BeforeEach(func() {
+			precision = 4
+		})
+
+		It("does not validate values with too many digits", func() {
+			Ω(valErr).Should(HaveOccurred())
+		})
+
+		Context("and a value with leading zeros", func() {
+			BeforeEach(func() {
+				val = "0012.34"
+			})
+
+			It("validates", func() {
+				Ω(valErr).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a scale", func() {
+		BeforeEach(func() {
+			scale = 1
+		})
+
+		It("does not validate values with too many fractional digits", func() {
+			Ω(valErr).Should(HaveOccurred())
+		})
+	})
 })